@@ -0,0 +1,311 @@
+package bifrost
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+const (
+	defaultAgentMaxIterations = 10
+	defaultAgentToolTimeout   = 30 * time.Second
+	maxAgentToolOutputBytes   = 1 << 20 // tool results above 1 MB are truncated before they are sent to the model
+)
+
+// agentToolClient calls the endpoints of HTTP tools, the tool timeout is applied through the request context
+var agentToolClient = &http.Client{}
+
+// chatCompletionFunc sends a chat completion request, it is the model call of the agent loop
+type chatCompletionFunc func(ctx context.Context, req *schemas.BifrostChatRequest) (*schemas.BifrostChatResponse, *schemas.BifrostError)
+
+// AgentRequest runs the tool-call loop of a chat request: the model is called with the registered HTTP tools and the
+// connected MCP tools, the tools it calls are executed by Bifrost and their results sent back to it, until it answers
+// without calling tools or the iteration limit is reached. Tool calls of tools defined in the request are not executed,
+// the loop stops and returns them to the caller instead.
+//
+// Parameters:
+//   - ctx: Request context, also used for the MCP tool filtering of the request
+//   - req: Chat request and optional iteration limit
+//
+// Returns:
+//   - schemas.BifrostAgentResponse: Final answer of the model with the trace of the loop
+//   - schemas.BifrostError: Error of a model call, or of an invalid or disabled agent request
+func (bifrost *Bifrost) AgentRequest(ctx context.Context, req *schemas.BifrostAgentRequest) (*schemas.BifrostAgentResponse, *schemas.BifrostError) {
+	config := bifrost.agent.Load()
+	if config == nil {
+		return nil, &schemas.BifrostError{
+			IsBifrostError: true,
+			StatusCode:     schemas.Ptr(http.StatusBadRequest),
+			Error: &schemas.ErrorField{
+				Message: "agent requests are not enabled",
+			},
+			ExtraFields: schemas.BifrostErrorExtraFields{
+				RequestType: schemas.ChatCompletionRequest,
+			},
+		}
+	}
+	if req == nil || req.ChatRequest == nil {
+		return nil, &schemas.BifrostError{
+			IsBifrostError: false,
+			Error: &schemas.ErrorField{
+				Message: "agent request is nil",
+			},
+			ExtraFields: schemas.BifrostErrorExtraFields{
+				RequestType: schemas.ChatCompletionRequest,
+			},
+		}
+	}
+
+	return bifrost.runAgent(ctx, req, config, bifrost.ChatCompletionRequest)
+}
+
+// runAgent runs the tool-call loop of an agent request, calling the model with complete
+func (bifrost *Bifrost) runAgent(ctx context.Context, req *schemas.BifrostAgentRequest, config *schemas.AgentConfig, complete chatCompletionFunc) (*schemas.BifrostAgentResponse, *schemas.BifrostError) {
+	maxIterations := config.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = defaultAgentMaxIterations
+	}
+	if req.MaxIterations > 0 && req.MaxIterations < maxIterations {
+		maxIterations = req.MaxIterations
+	}
+	toolTimeout := defaultAgentToolTimeout
+	if config.ToolTimeoutSeconds > 0 {
+		toolTimeout = time.Duration(config.ToolTimeoutSeconds) * time.Second
+	}
+
+	// The tools of the request are executed by the caller, HTTP tools with the same name are not offered
+	chatReq := *req.ChatRequest
+	params := schemas.ChatParameters{}
+	if chatReq.Params != nil {
+		params = *chatReq.Params
+	}
+	params.Tools = slices.Clone(params.Tools)
+	callerTools := make(map[string]bool, len(params.Tools))
+	for _, tool := range params.Tools {
+		if tool.Function != nil {
+			callerTools[tool.Function.Name] = true
+		}
+	}
+	httpTools := make(map[string]*schemas.AgentHTTPTool, len(config.HTTPTools))
+	for i := range config.HTTPTools {
+		tool := &config.HTTPTools[i]
+		if callerTools[tool.Name] {
+			continue
+		}
+		httpTools[tool.Name] = tool
+		function := &schemas.ChatToolFunction{Name: tool.Name, Parameters: tool.Parameters}
+		if tool.Description != "" {
+			function.Description = schemas.Ptr(tool.Description)
+		}
+		params.Tools = append(params.Tools, schemas.ChatTool{Type: schemas.ChatToolTypeFunction, Function: function})
+	}
+	chatReq.Params = &params
+
+	// MCP tools are added to every model call, except those shadowed by the tools above
+	mcpTools := make(map[string]bool)
+	if bifrost.mcpManager != nil {
+		for _, tool := range bifrost.mcpManager.getAvailableTools(ctx) {
+			if tool.Function != nil && !callerTools[tool.Function.Name] && httpTools[tool.Function.Name] == nil {
+				mcpTools[tool.Function.Name] = true
+			}
+		}
+	}
+
+	resp := &schemas.BifrostAgentResponse{}
+	messages := slices.Clone(chatReq.Input)
+	for resp.Iterations < maxIterations {
+		iterationReq := chatReq
+		iterationReq.Input = messages
+
+		start := time.Now()
+		chatResp, bifrostErr := complete(ctx, &iterationReq)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+		resp.Iterations++
+		resp.Response = chatResp
+		resp.Usage = addAgentUsage(resp.Usage, chatResp.Usage)
+
+		if len(chatResp.Choices) == 0 || chatResp.Choices[0].ChatNonStreamResponseChoice == nil || chatResp.Choices[0].Message == nil {
+			return nil, newBifrostErrorFromMsg("model response has no message")
+		}
+		message := *chatResp.Choices[0].Message
+		messages = append(messages, message)
+		resp.Messages = append(resp.Messages, message)
+		step := schemas.AgentTraceStep{
+			Iteration: resp.Iterations,
+			Message:   message,
+			Usage:     chatResp.Usage,
+			LatencyMs: time.Since(start).Milliseconds(),
+		}
+
+		var toolCalls []schemas.ChatAssistantMessageToolCall
+		if message.ChatAssistantMessage != nil {
+			toolCalls = message.ChatAssistantMessage.ToolCalls
+		}
+		if len(toolCalls) == 0 {
+			resp.Trace = append(resp.Trace, step)
+			resp.StopReason = schemas.AgentStopReasonCompleted
+			return resp, nil
+		}
+		executable := !slices.ContainsFunc(toolCalls, func(toolCall schemas.ChatAssistantMessageToolCall) bool {
+			return toolCall.Function.Name == nil || (httpTools[*toolCall.Function.Name] == nil && !mcpTools[*toolCall.Function.Name])
+		})
+		if !executable {
+			resp.Trace = append(resp.Trace, step)
+			resp.StopReason = schemas.AgentStopReasonToolCalls
+			return resp, nil
+		}
+
+		for _, toolCall := range toolCalls {
+			execution, result := bifrost.executeAgentTool(ctx, toolCall, httpTools[*toolCall.Function.Name], toolTimeout)
+			step.ToolExecutions = append(step.ToolExecutions, execution)
+			messages = append(messages, result)
+			resp.Messages = append(resp.Messages, result)
+		}
+		resp.Trace = append(resp.Trace, step)
+		if ctx.Err() != nil {
+			return nil, newBifrostError(ctx.Err())
+		}
+	}
+
+	resp.StopReason = schemas.AgentStopReasonMaxIterations
+	return resp, nil
+}
+
+// executeAgentTool executes a tool call with an HTTP tool, or with the MCP client providing the tool when httpTool is nil.
+// Execution errors are sent to the model as the tool result, so that it can recover from them.
+func (bifrost *Bifrost) executeAgentTool(ctx context.Context, toolCall schemas.ChatAssistantMessageToolCall, httpTool *schemas.AgentHTTPTool, timeout time.Duration) (schemas.AgentToolExecution, schemas.ChatMessage) {
+	execution := schemas.AgentToolExecution{
+		Name:      *toolCall.Function.Name,
+		Arguments: toolCall.Function.Arguments,
+	}
+	if toolCall.ID != nil {
+		execution.ToolCallID = *toolCall.ID
+	}
+
+	toolCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	var output string
+	var err error
+	if httpTool != nil {
+		execution.Source = schemas.AgentToolSourceHTTP
+		output, err = callAgentHTTPTool(toolCtx, httpTool, toolCall.Function.Arguments)
+	} else {
+		execution.Source = schemas.AgentToolSourceMCP
+		var result *schemas.ChatMessage
+		result, err = bifrost.mcpManager.executeTool(toolCtx, toolCall)
+		if err == nil && result.Content != nil && result.Content.ContentStr != nil {
+			output = *result.Content.ContentStr
+		}
+	}
+	execution.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil && errors.Is(toolCtx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("tool timed out after %s", timeout)
+	}
+
+	content := output
+	if err != nil {
+		execution.Error = err.Error()
+		content = "Error: " + err.Error()
+	} else {
+		execution.Output = output
+	}
+	return execution, schemas.ChatMessage{
+		Role:            schemas.ChatMessageRoleTool,
+		Content:         &schemas.ChatMessageContent{ContentStr: &content},
+		ChatToolMessage: &schemas.ChatToolMessage{ToolCallID: toolCall.ID},
+	}
+}
+
+// callAgentHTTPTool calls the endpoint of an HTTP tool with the JSON arguments of a tool call and returns the response body.
+// POST requests send the arguments as the body, GET requests send the top-level arguments as query parameters.
+func callAgentHTTPTool(ctx context.Context, tool *schemas.AgentHTTPTool, arguments string) (string, error) {
+	if strings.TrimSpace(arguments) == "" {
+		arguments = "{}"
+	}
+
+	method := strings.ToUpper(tool.Method)
+	target := tool.URL
+	var body io.Reader
+	switch method {
+	case "", http.MethodPost:
+		method = http.MethodPost
+		body = strings.NewReader(arguments)
+	case http.MethodGet:
+		var args map[string]interface{}
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+			return "", fmt.Errorf("failed to parse tool arguments: %v", err)
+		}
+		u, err := url.Parse(tool.URL)
+		if err != nil {
+			return "", err
+		}
+		query := u.Query()
+		for name, value := range args {
+			if str, ok := value.(string); ok {
+				query.Set(name, str)
+				continue
+			}
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return "", err
+			}
+			query.Set(name, string(encoded))
+		}
+		u.RawQuery = query.Encode()
+		target = u.String()
+	default:
+		return "", fmt.Errorf("unsupported method %s", tool.Method)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return "", err
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range tool.Headers {
+		httpReq.Header.Set(name, value)
+	}
+
+	httpResp, err := agentToolClient.Do(httpReq)
+	if err != nil {
+		return "", err
+	}
+	defer httpResp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(httpResp.Body, maxAgentToolOutputBytes))
+	if err != nil {
+		return "", err
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		return "", fmt.Errorf("tool endpoint returned status %d: %s", httpResp.StatusCode, data)
+	}
+	return string(data), nil
+}
+
+// addAgentUsage adds the usage of a model call to the usage of an agent request
+func addAgentUsage(total, usage *schemas.BifrostLLMUsage) *schemas.BifrostLLMUsage {
+	if usage == nil {
+		return total
+	}
+	if total == nil {
+		total = &schemas.BifrostLLMUsage{}
+	}
+	total.PromptTokens += usage.PromptTokens
+	total.CompletionTokens += usage.CompletionTokens
+	total.TotalTokens += usage.TotalTokens
+	return total
+}
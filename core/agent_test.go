@@ -0,0 +1,128 @@
+package bifrost
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// scriptedModel returns a chatCompletionFunc answering with the given messages in order, recording the requests it receives
+func scriptedModel(requests *[]*schemas.BifrostChatRequest, messages ...schemas.ChatMessage) chatCompletionFunc {
+	return func(ctx context.Context, req *schemas.BifrostChatRequest) (*schemas.BifrostChatResponse, *schemas.BifrostError) {
+		*requests = append(*requests, req)
+		message := messages[len(*requests)-1]
+		return &schemas.BifrostChatResponse{
+			Choices: []schemas.BifrostResponseChoice{{ChatNonStreamResponseChoice: &schemas.ChatNonStreamResponseChoice{Message: &message}}},
+			Usage:   &schemas.BifrostLLMUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		}, nil
+	}
+}
+
+func toolCallMessage(id, name, arguments string) schemas.ChatMessage {
+	return schemas.ChatMessage{
+		Role: schemas.ChatMessageRoleAssistant,
+		ChatAssistantMessage: &schemas.ChatAssistantMessage{ToolCalls: []schemas.ChatAssistantMessageToolCall{{
+			ID:       schemas.Ptr(id),
+			Function: schemas.ChatAssistantMessageToolCallFunction{Name: schemas.Ptr(name), Arguments: arguments},
+		}}},
+	}
+}
+
+func agentRequest() *schemas.BifrostAgentRequest {
+	return &schemas.BifrostAgentRequest{ChatRequest: &schemas.BifrostChatRequest{
+		Provider: schemas.OpenAI,
+		Model:    "gpt-4o",
+		Input:    []schemas.ChatMessage{{Role: schemas.ChatMessageRoleUser, Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr("Weather in Paris?")}}},
+	}}
+}
+
+// TestRunAgentExecutesHTTPTools tests that HTTP tool calls are executed and their results sent back to the model
+func TestRunAgentExecutesHTTPTools(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var args map[string]string
+		json.NewDecoder(r.Body).Decode(&args)
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("sunny in " + args["city"]))
+	}))
+	defer server.Close()
+
+	config := &schemas.AgentConfig{HTTPTools: []schemas.AgentHTTPTool{{
+		Name:    "get_weather",
+		URL:     server.URL,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	}}}
+	final := schemas.ChatMessage{Role: schemas.ChatMessageRoleAssistant, Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr("It's sunny.")}}
+	var requests []*schemas.BifrostChatRequest
+	bifrost := &Bifrost{}
+
+	resp, err := bifrost.runAgent(context.Background(), agentRequest(), config, scriptedModel(&requests, toolCallMessage("call_1", "get_weather", `{"city":"Paris"}`), final))
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+	if resp.StopReason != schemas.AgentStopReasonCompleted || resp.Iterations != 2 {
+		t.Fatalf("expected completion after 2 iterations, got %s after %d", resp.StopReason, resp.Iterations)
+	}
+	if len(requests[0].Params.Tools) != 1 || requests[0].Params.Tools[0].Function.Name != "get_weather" {
+		t.Errorf("expected the HTTP tool to be offered to the model, got %+v", requests[0].Params.Tools)
+	}
+	if len(requests[1].Input) != 3 || *requests[1].Input[2].Content.ContentStr != "sunny in Paris" || *requests[1].Input[2].ToolCallID != "call_1" {
+		t.Errorf("expected the tool result to be sent to the model, got %+v", requests[1].Input)
+	}
+	execution := resp.Trace[0].ToolExecutions[0]
+	if execution.Source != schemas.AgentToolSourceHTTP || execution.Output != "sunny in Paris" || execution.Error != "" {
+		t.Errorf("unexpected tool execution %+v", execution)
+	}
+	if len(resp.Messages) != 3 || resp.Usage.TotalTokens != 30 {
+		t.Errorf("expected 3 messages and summed usage, got %d messages and %d tokens", len(resp.Messages), resp.Usage.TotalTokens)
+	}
+}
+
+// TestRunAgentLimits tests tool timeouts, the iteration limit and the tool calls returned to the caller
+func TestRunAgentLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	config := &schemas.AgentConfig{
+		MaxIterations:      2,
+		ToolTimeoutSeconds: 1,
+		HTTPTools:          []schemas.AgentHTTPTool{{Name: "slow", URL: server.URL}},
+	}
+	bifrost := &Bifrost{}
+
+	// The model keeps calling a tool that times out
+	var requests []*schemas.BifrostChatRequest
+	call := toolCallMessage("call_1", "slow", `{}`)
+	resp, err := bifrost.runAgent(context.Background(), agentRequest(), config, scriptedModel(&requests, call, call, call))
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+	if resp.StopReason != schemas.AgentStopReasonMaxIterations || resp.Iterations != 2 {
+		t.Errorf("expected the iteration limit to stop the loop after 2 iterations, got %s after %d", resp.StopReason, resp.Iterations)
+	}
+	if execution := resp.Trace[0].ToolExecutions[0]; execution.Error != "tool timed out after 1s" {
+		t.Errorf("expected a tool timeout, got %+v", execution)
+	}
+
+	// Tools Bifrost can't execute are returned to the caller
+	requests = nil
+	resp, err = bifrost.runAgent(context.Background(), agentRequest(), config, scriptedModel(&requests, toolCallMessage("call_1", "client_tool", `{}`)))
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+	if resp.StopReason != schemas.AgentStopReasonToolCalls || len(resp.Trace[0].ToolExecutions) != 0 {
+		t.Errorf("expected the tool calls to be returned to the caller, got %s", resp.StopReason)
+	}
+}
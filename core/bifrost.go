@@ -21,9 +21,12 @@ import (
 	"github.com/maximhq/bifrost/core/providers/cerebras"
 	"github.com/maximhq/bifrost/core/providers/cohere"
 	"github.com/maximhq/bifrost/core/providers/elevenlabs"
+	"github.com/maximhq/bifrost/core/providers/fireworks"
 	"github.com/maximhq/bifrost/core/providers/gemini"
 	"github.com/maximhq/bifrost/core/providers/groq"
+	"github.com/maximhq/bifrost/core/providers/huggingface"
 	"github.com/maximhq/bifrost/core/providers/mistral"
+	"github.com/maximhq/bifrost/core/providers/mock"
 	"github.com/maximhq/bifrost/core/providers/ollama"
 	"github.com/maximhq/bifrost/core/providers/openai"
 	"github.com/maximhq/bifrost/core/providers/openrouter"
@@ -32,6 +35,7 @@ import (
 	"github.com/maximhq/bifrost/core/providers/sgl"
 	providerUtils "github.com/maximhq/bifrost/core/providers/utils"
 	"github.com/maximhq/bifrost/core/providers/vertex"
+	"github.com/maximhq/bifrost/core/providers/xai"
 	schemas "github.com/maximhq/bifrost/core/schemas"
 )
 
@@ -43,6 +47,11 @@ type ChannelMessage struct {
 	Response       chan *schemas.BifrostResponse
 	ResponseStream chan chan *schemas.BifrostStream
 	Err            chan schemas.BifrostError
+
+	// Plugins of the request once its pipeline was applied, and the number of them whose PreHook ran.
+	// The PostHooks of the chunks of a stream run for these plugins only.
+	plugins          []schemas.Plugin
+	executedPreHooks int
 }
 
 // Bifrost manages providers and maintains specified open channels for concurrent processing.
@@ -53,8 +62,10 @@ type Bifrost struct {
 	account             schemas.Account                    // account interface
 	plugins             atomic.Pointer[[]schemas.Plugin]   // list of plugins
 	providers           atomic.Pointer[[]schemas.Provider] // list of providers
-	requestQueues       sync.Map                           // provider request queues (thread-safe)
+	requestQueues       sync.Map                           // provider request queues per priority pool (thread-safe)
+	keyProviders        sync.Map                           // provider instances created with the proxies of keys (thread-safe)
 	waitGroups          sync.Map                           // wait groups for each provider (thread-safe)
+	retiringWorkers     sync.WaitGroup                     // workers of previous provider configs still finishing their requests
 	providerMutexes     sync.Map                           // mutexes for each provider to prevent concurrent updates (thread-safe)
 	channelMessagePool  sync.Pool                          // Pool for ChannelMessage objects, initial pool size is set in Init
 	responseChannelPool sync.Pool                          // Pool for response channels, initial pool size is set in Init
@@ -66,12 +77,49 @@ type Bifrost struct {
 	mcpManager          *MCPManager                        // MCP integration manager (nil if MCP not configured)
 	dropExcessRequests  atomic.Bool                        // If true, in cases where the queue is full, requests will not wait for the queue to be empty and will be dropped instead.
 	keySelector         schemas.KeySelector                // Custom key selector function
+	modelCapabilities   schemas.ModelCapabilityRegistry    // Capabilities of models, nil sends requests without validating them
+
+	pluginExecutors   atomic.Pointer[map[string]*pluginExecutor] // execution limits of plugins keyed by plugin name, plugins without an entry run inline
+	pluginExecutorsMu sync.Mutex                                 // serializes updates of pluginExecutors
+	pluginCircuits    schemas.PluginCircuitStore                 // circuit breakers shared with the other replicas, nil keeps them local
+
+	directKeyPolicy    atomic.Pointer[schemas.DirectKeyPolicy]          // constraints on requests carrying a direct key, nil means unrestricted
+	pipelines          atomic.Pointer[pipelineSet]                      // transformation pipelines attached to models and virtual keys, nil runs all plugins
+	imageInputs        atomic.Pointer[schemas.ImageInputConfig]         // fetching and transcoding of image inputs, nil sends images as they are
+	agent              atomic.Pointer[schemas.AgentConfig]              // tool-call loop of agent requests, nil disables agent requests
+	transforms         atomic.Pointer[transformRuleSet]                 // transform rules rewriting requests of models and virtual keys, nil sends requests as they are
+	fallbackChains     atomic.Pointer[fallbackChainSet]                 // fallback chains of model aliases, nil sends requests with their own fallbacks
+	experiments        atomic.Pointer[experimentSet]                    // experiments splitting the requests of models between variants, nil enrolls no request
+	contextWindow      atomic.Pointer[schemas.ContextWindowConfig]      // pre-flight context window check, nil sends requests without counting their tokens
+	promptCompression  atomic.Pointer[schemas.PromptCompressionConfig]  // token budgets of conversations per model alias, nil sends conversations as they are
+	streamFailover     atomic.Pointer[schemas.StreamFailoverConfig]     // restart of streams failing mid-generation on the next fallback, nil ends them with the error
+	streamBackpressure atomic.Pointer[schemas.StreamBackpressureConfig] // bounded buffers of streams and slow consumer policy, nil uses the default buffer and blocks
+	responseBuffering  atomic.Pointer[schemas.ResponseBufferingConfig]  // non-stream requests served from provider streams, nil sends requests as they are
+	idempotency        atomic.Pointer[schemas.IdempotencyConfig]        // replay window of requests with an idempotency key, nil uses the defaults
+	idempotencyStore   *idempotencyStore                                // requests in flight and recent results per idempotency key
+	deprecations       atomic.Pointer[modelDeprecationSet]              // deprecated models and their replacements, nil sends requests as they are
+	chaos              atomic.Pointer[schemas.ChaosConfig]              // faults injected into provider requests, nil injects none
+	listModelsCache    atomic.Pointer[schemas.ListModelsCacheConfig]    // refresh of the cached model listings, nil lists models on every request
+	listModelsEntries  *listModelsCache                                 // cached model listings per provider and set of keys
+	responsesState     atomic.Pointer[schemas.ResponsesStateConfig]     // storage of responses continued with previous_response_id, nil leaves it to the providers
+	responsesEntries   *responsesStateStore                             // recent responses per caller and response ID
+	conversations      atomic.Pointer[schemas.ConversationConfig]       // history truncation of stored conversations, nil ignores conversation IDs
+	requestTags        atomic.Pointer[schemas.RequestTagsConfig]        // allow-list of the tags of requests, nil records no tags
+	partialFailures    atomic.Pointer[schemas.PartialFailureConfig]     // partial or strict results of requests of many inputs, nil returns partial results
+	slos               atomic.Pointer[sloTracker]                       // compliance of the service level objectives of the providers, nil tracks none
+	conversationStore  schemas.ConversationStore                        // storage of the conversations, nil ignores conversation IDs
 }
 
 // PluginPipeline encapsulates the execution of plugin PreHooks and PostHooks, tracks how many plugins ran, and manages short-circuiting and error aggregation.
 type PluginPipeline struct {
-	plugins []schemas.Plugin
-	logger  schemas.Logger
+	plugins   []schemas.Plugin
+	executors map[string]*pluginExecutor
+	logger    schemas.Logger
+
+	// Plugins that must run for requests carrying a direct key, their hooks fail closed for such requests
+	directKeyRequiredPlugins []string
+	// Transformation pipelines, the plugins of a request are narrowed to its pipeline when the first managed plugin is reached
+	pipelines *pipelineSet
 
 	// Number of PreHooks that were executed (used to determine which PostHooks to run in reverse order)
 	executedPreHooks int
@@ -101,21 +149,44 @@ func Init(ctx context.Context, config schemas.BifrostConfig) (*Bifrost, error) {
 	providerUtils.SetLogger(config.Logger)
 	bifrostCtx, cancel := context.WithCancel(ctx)
 	bifrost := &Bifrost{
-		ctx:           bifrostCtx,
-		cancel:        cancel,
-		account:       config.Account,
-		plugins:       atomic.Pointer[[]schemas.Plugin]{},
-		requestQueues: sync.Map{},
-		waitGroups:    sync.Map{},
-		keySelector:   config.KeySelector,
-		logger:        config.Logger,
+		ctx:               bifrostCtx,
+		cancel:            cancel,
+		account:           config.Account,
+		plugins:           atomic.Pointer[[]schemas.Plugin]{},
+		requestQueues:     sync.Map{},
+		waitGroups:        sync.Map{},
+		keySelector:       config.KeySelector,
+		modelCapabilities: config.ModelCapabilities,
+		idempotencyStore:  newIdempotencyStore(),
+		listModelsEntries: newListModelsCache(),
+		responsesEntries:  newResponsesStateStore(),
+		conversationStore: config.ConversationStore,
+		pluginCircuits:    config.PluginCircuits,
+		logger:            config.Logger,
 	}
 	bifrost.plugins.Store(&config.Plugins)
+	bifrost.UpdatePluginExecutionConfigs(config.PluginExecution)
 
 	// Initialize providers slice
 	bifrost.providers.Store(&[]schemas.Provider{})
 
 	bifrost.dropExcessRequests.Store(config.DropExcessRequests)
+	bifrost.directKeyPolicy.Store(config.DirectKeyPolicy)
+	bifrost.imageInputs.Store(config.ImageInputs)
+	bifrost.agent.Store(config.Agent)
+	bifrost.contextWindow.Store(config.ContextWindow)
+	bifrost.promptCompression.Store(config.PromptCompression)
+	bifrost.streamFailover.Store(config.StreamFailover)
+	bifrost.streamBackpressure.Store(config.StreamBackpressure)
+	bifrost.responseBuffering.Store(config.ResponseBuffering)
+	bifrost.idempotency.Store(config.Idempotency)
+	bifrost.chaos.Store(config.Chaos)
+	bifrost.listModelsCache.Store(config.ListModelsCache)
+	bifrost.responsesState.Store(config.ResponsesState)
+	bifrost.conversations.Store(config.Conversations)
+	bifrost.requestTags.Store(config.RequestTags)
+	bifrost.partialFailures.Store(config.PartialFailures)
+	bifrost.updateSLOs(config.SLOs)
 
 	if bifrost.keySelector == nil {
 		bifrost.keySelector = WeightedRandomKeySelector
@@ -220,10 +291,26 @@ func Init(ctx context.Context, config schemas.BifrostConfig) (*Bifrost, error) {
 }
 
 // ReloadConfig reloads the config from DB
-// Currently we only update account and drop excess requests
+// Currently we only update drop excess requests, the direct key policy, the image input config and the agent config
 // We will keep on adding other aspects as required
 func (bifrost *Bifrost) ReloadConfig(config schemas.BifrostConfig) error {
 	bifrost.dropExcessRequests.Store(config.DropExcessRequests)
+	bifrost.directKeyPolicy.Store(config.DirectKeyPolicy)
+	bifrost.imageInputs.Store(config.ImageInputs)
+	bifrost.agent.Store(config.Agent)
+	bifrost.contextWindow.Store(config.ContextWindow)
+	bifrost.promptCompression.Store(config.PromptCompression)
+	bifrost.streamFailover.Store(config.StreamFailover)
+	bifrost.streamBackpressure.Store(config.StreamBackpressure)
+	bifrost.responseBuffering.Store(config.ResponseBuffering)
+	bifrost.idempotency.Store(config.Idempotency)
+	bifrost.chaos.Store(config.Chaos)
+	bifrost.listModelsCache.Store(config.ListModelsCache)
+	bifrost.responsesState.Store(config.ResponsesState)
+	bifrost.conversations.Store(config.Conversations)
+	bifrost.requestTags.Store(config.RequestTags)
+	bifrost.partialFailures.Store(config.PartialFailures)
+	bifrost.updateSLOs(config.SLOs)
 	return nil
 }
 
@@ -301,6 +388,14 @@ func (bifrost *Bifrost) ListModelsRequest(ctx context.Context, req *schemas.Bifr
 		baseProvider = config.CustomProviderConfig.BaseProviderType
 	}
 
+	if policyErr := bifrost.checkDirectKeyPolicy(ctx, req.Provider, "", *bifrost.plugins.Load()); policyErr != nil {
+		policyErr.ExtraFields = schemas.BifrostErrorExtraFields{
+			RequestType: schemas.ListModelsRequest,
+			Provider:    req.Provider,
+		}
+		return nil, policyErr
+	}
+
 	var keys []schemas.Key
 	if providerRequiresKey(baseProvider, config.CustomProviderConfig) {
 		keys, err = bifrost.getAllSupportedKeys(&ctx, req.Provider, baseProvider)
@@ -314,6 +409,31 @@ func (bifrost *Bifrost) ListModelsRequest(ctx context.Context, req *schemas.Bifr
 		}
 	}
 
+	// Listings are cached per set of keys, requests with provider specific params or a direct key go to the provider
+	if cacheConfig := bifrost.listModelsCache.Load(); cacheConfig != nil && len(req.ExtraParams) == 0 && ctx.Value(schemas.BifrostContextKeyDirectKey) == nil {
+		startTime := time.Now()
+		models, bifrostErr := bifrost.listModelsEntries.get(ctx, bifrost.ctx, listModelsCacheKey(req.Provider, keys), cacheConfig, func(ctx context.Context) ([]schemas.Model, *schemas.BifrostError) {
+			return bifrost.listAllProviderModels(ctx, provider, config, keys, req.Provider)
+		})
+		if bifrostErr != nil {
+			bifrostErr.ExtraFields = schemas.BifrostErrorExtraFields{
+				RequestType: schemas.ListModelsRequest,
+				Provider:    req.Provider,
+			}
+			return nil, bifrostErr
+		}
+		// The cached listing is shared, callers get their own copy of the models
+		response := &schemas.BifrostListModelsResponse{
+			Data: slices.Clone(models),
+			ExtraFields: schemas.BifrostResponseExtraFields{
+				RequestType: schemas.ListModelsRequest,
+				Provider:    req.Provider,
+				Latency:     time.Since(startTime).Milliseconds(),
+			},
+		}
+		return response.ApplyPagination(req.PageSize, req.PageToken), nil
+	}
+
 	response, bifrostErr := executeRequestWithRetries(&ctx, config, func() (*schemas.BifrostListModelsResponse, *schemas.BifrostError) {
 		return provider.ListModels(ctx, keys, request)
 	}, schemas.ListModelsRequest, req.Provider, "")
@@ -496,7 +616,12 @@ func (bifrost *Bifrost) TextCompletionRequest(ctx context.Context, req *schemas.
 	bifrostReq.RequestType = schemas.TextCompletionRequest
 	bifrostReq.TextCompletionRequest = req
 
-	response, err := bifrost.handleRequest(ctx, bifrostReq)
+	response, err := bifrost.handleIdempotentRequest(ctx, bifrostReq, func() (*schemas.BifrostResponse, *schemas.BifrostError) {
+		if bifrost.shouldStreamUpstream(ctx, req.Provider) {
+			return bifrost.handleBufferedRequest(ctx, bifrostReq)
+		}
+		return bifrost.handleRequest(ctx, bifrostReq)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -563,14 +688,41 @@ func (bifrost *Bifrost) ChatCompletionRequest(ctx context.Context, req *schemas.
 		}
 	}
 
+	conversation, sent, err := bifrost.beginConversation(ctx, req, schemas.ChatCompletionRequest)
+	if err != nil {
+		return nil, err
+	}
+
 	bifrostReq := bifrost.getBifrostRequest()
 	bifrostReq.RequestType = schemas.ChatCompletionRequest
-	bifrostReq.ChatRequest = req
+	bifrostReq.ChatRequest = sent
 
-	response, err := bifrost.handleRequest(ctx, bifrostReq)
+	response, err := bifrost.handleIdempotentRequest(ctx, bifrostReq, func() (*schemas.BifrostResponse, *schemas.BifrostError) {
+		var response *schemas.BifrostResponse
+		var err *schemas.BifrostError
+		if bifrost.shouldStreamUpstream(ctx, req.Provider) {
+			response, err = bifrost.handleBufferedRequest(ctx, bifrostReq)
+		} else {
+			response, err = bifrost.handleRequest(ctx, bifrostReq)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if retries, ok := structuredOutputRetries(ctx); ok {
+			chatResponse, err := bifrost.validateStructuredOutput(ctx, sent, response.ChatResponse, retries)
+			if err != nil {
+				return nil, err
+			}
+			return &schemas.BifrostResponse{ChatResponse: chatResponse}, nil
+		}
+		return response, nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	if conversation != nil {
+		bifrost.saveConversation(ctx, conversation, req, response.ChatResponse)
+	}
 	//TODO: Release the response
 	return response.ChatResponse, nil
 }
@@ -602,11 +754,20 @@ func (bifrost *Bifrost) ChatCompletionStreamRequest(ctx context.Context, req *sc
 		}
 	}
 
+	conversation, sent, err := bifrost.beginConversation(ctx, req, schemas.ChatCompletionStreamRequest)
+	if err != nil {
+		return nil, err
+	}
+
 	bifrostReq := bifrost.getBifrostRequest()
 	bifrostReq.RequestType = schemas.ChatCompletionStreamRequest
-	bifrostReq.ChatRequest = req
+	bifrostReq.ChatRequest = sent
 
-	return bifrost.handleStreamRequest(ctx, bifrostReq)
+	stream, err := bifrost.handleStreamRequest(ctx, bifrostReq)
+	if err != nil || conversation == nil {
+		return stream, err
+	}
+	return bifrost.withConversation(ctx, conversation, req, stream), nil
 }
 
 // ResponsesRequest sends a responses request to the specified provider.
@@ -636,14 +797,19 @@ func (bifrost *Bifrost) ResponsesRequest(ctx context.Context, req *schemas.Bifro
 		}
 	}
 
+	expanded, parent := bifrost.expandResponsesRequest(ctx, req)
+
 	bifrostReq := bifrost.getBifrostRequest()
 	bifrostReq.RequestType = schemas.ResponsesRequest
-	bifrostReq.ResponsesRequest = req
+	bifrostReq.ResponsesRequest = expanded
 
-	response, err := bifrost.handleRequest(ctx, bifrostReq)
+	response, err := bifrost.handleIdempotentRequest(ctx, bifrostReq, func() (*schemas.BifrostResponse, *schemas.BifrostError) {
+		return bifrost.handleRequest(ctx, bifrostReq)
+	})
 	if err != nil {
 		return nil, err
 	}
+	bifrost.storeResponsesState(ctx, req, parent, response.ResponsesResponse)
 	//TODO: Release the response
 	return response.ResponsesResponse, nil
 }
@@ -675,11 +841,17 @@ func (bifrost *Bifrost) ResponsesStreamRequest(ctx context.Context, req *schemas
 		}
 	}
 
+	expanded, parent := bifrost.expandResponsesRequest(ctx, req)
+
 	bifrostReq := bifrost.getBifrostRequest()
 	bifrostReq.RequestType = schemas.ResponsesStreamRequest
-	bifrostReq.ResponsesRequest = req
+	bifrostReq.ResponsesRequest = expanded
 
-	return bifrost.handleStreamRequest(ctx, bifrostReq)
+	stream, err := bifrost.handleStreamRequest(ctx, bifrostReq)
+	if err != nil || ctx == nil || bifrost.responsesState.Load() == nil {
+		return stream, err
+	}
+	return bifrost.withResponsesState(ctx, req, parent, stream), nil
 }
 
 // EmbeddingRequest sends an embedding request to the specified provider.
@@ -713,7 +885,27 @@ func (bifrost *Bifrost) EmbeddingRequest(ctx context.Context, req *schemas.Bifro
 	bifrostReq.RequestType = schemas.EmbeddingRequest
 	bifrostReq.EmbeddingRequest = req
 
-	response, err := bifrost.handleRequest(ctx, bifrostReq)
+	response, err := bifrost.handleIdempotentRequest(ctx, bifrostReq, func() (*schemas.BifrostResponse, *schemas.BifrostError) {
+		var response *schemas.BifrostResponse
+		var err *schemas.BifrostError
+		// Inputs larger than the batch size of the provider are split into batches sent as requests of their own
+		if batches, concurrency := bifrost.embeddingBatches(ctx, req); len(batches) > 1 {
+			bifrost.releaseBifrostRequest(bifrostReq)
+			var embeddingResponse *schemas.BifrostEmbeddingResponse
+			if embeddingResponse, err = bifrost.handleBatchedEmbeddingRequest(ctx, req, batches, concurrency); err == nil {
+				response = &schemas.BifrostResponse{EmbeddingResponse: embeddingResponse}
+			}
+		} else {
+			response, err = bifrost.handleRequest(ctx, bifrostReq)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := conformEmbeddings(req, response.EmbeddingResponse); err != nil {
+			return nil, err
+		}
+		return response, nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -752,7 +944,9 @@ func (bifrost *Bifrost) SpeechRequest(ctx context.Context, req *schemas.BifrostS
 	bifrostReq.RequestType = schemas.SpeechRequest
 	bifrostReq.SpeechRequest = req
 
-	response, err := bifrost.handleRequest(ctx, bifrostReq)
+	response, err := bifrost.handleIdempotentRequest(ctx, bifrostReq, func() (*schemas.BifrostResponse, *schemas.BifrostError) {
+		return bifrost.handleRequest(ctx, bifrostReq)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -825,7 +1019,9 @@ func (bifrost *Bifrost) TranscriptionRequest(ctx context.Context, req *schemas.B
 	bifrostReq.RequestType = schemas.TranscriptionRequest
 	bifrostReq.TranscriptionRequest = req
 
-	response, err := bifrost.handleRequest(ctx, bifrostReq)
+	response, err := bifrost.handleIdempotentRequest(ctx, bifrostReq, func() (*schemas.BifrostResponse, *schemas.BifrostError) {
+		return bifrost.handleRequest(ctx, bifrostReq)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -977,8 +1173,8 @@ func (bifrost *Bifrost) GetConfiguredProviders() ([]schemas.ModelProvider, error
 
 // UpdateProvider dynamically updates a provider with new configuration.
 // This method gracefully recreates the provider instance with updated settings,
-// stops existing workers, creates a new queue with updated settings,
-// and starts new workers with the updated provider and concurrency configuration.
+// starts new workers on new queues with the updated provider and concurrency configuration,
+// and retires the existing workers once their requests are done.
 //
 // Parameters:
 //   - providerKey: The provider to update
@@ -986,9 +1182,10 @@ func (bifrost *Bifrost) GetConfiguredProviders() ([]schemas.ModelProvider, error
 // Returns:
 //   - error: Any error that occurred during the update process
 //
-// Note: This operation will temporarily pause request processing for the specified provider
-// while the transition occurs. In-flight requests will complete before workers are stopped.
+// Note: Request processing is never paused. New requests go to the new workers as soon as they are started,
+// while in-flight requests and streams complete on the existing workers, which stop in the background.
 // Buffered requests in the old queue will be transferred to the new queue to prevent loss.
+// If the new provider instance cannot be created, the existing workers keep serving the provider.
 func (bifrost *Bifrost) UpdateProvider(providerKey schemas.ModelProvider) error {
 	bifrost.logger.Info(fmt.Sprintf("Updating provider configuration for provider %s", providerKey))
 
@@ -1006,6 +1203,11 @@ func (bifrost *Bifrost) UpdateProvider(providerKey schemas.ModelProvider) error
 	providerMutex.Lock()
 	defer providerMutex.Unlock()
 
+	// Instances created with the proxies of keys are created again from the new configuration
+	bifrost.removeKeyProviders(providerKey)
+	// Models listed with the previous configuration are listed again
+	bifrost.listModelsEntries.invalidate(providerKey)
+
 	// Check if provider currently exists
 	oldQueueValue, exists := bifrost.requestQueues.Load(providerKey)
 	if !exists {
@@ -1014,93 +1216,26 @@ func (bifrost *Bifrost) UpdateProvider(providerKey schemas.ModelProvider) error
 		return bifrost.prepareProvider(providerKey, providerConfig)
 	}
 
-	oldQueue := oldQueueValue.(chan *ChannelMessage)
-
-	bifrost.logger.Debug("gracefully stopping existing workers for provider %s", providerKey)
-
-	// Step 1: Create new queue with updated buffer size
-	newQueue := make(chan *ChannelMessage, providerConfig.ConcurrencyAndBufferSize.BufferSize)
-
-	// Step 2: Transfer any buffered requests from old queue to new queue
-	// This prevents request loss during the transition
-	transferredCount := 0
-	var transferWaitGroup sync.WaitGroup
-	for {
-		select {
-		case msg := <-oldQueue:
-			select {
-			case newQueue <- msg:
-				transferredCount++
-			default:
-				// New queue is full, handle this request in a goroutine
-				// This is unlikely with proper buffer sizing but provides safety
-				transferWaitGroup.Add(1)
-				go func(m *ChannelMessage) {
-					defer transferWaitGroup.Done()
-					select {
-					case newQueue <- m:
-						// Message successfully transferred
-					case <-time.After(5 * time.Second):
-						bifrost.logger.Warn("Failed to transfer buffered request to new queue within timeout")
-						// Send error response to avoid hanging the client
-						provider, model, _ := m.BifrostRequest.GetRequestFields()
-						select {
-						case m.Err <- schemas.BifrostError{
-							IsBifrostError: false,
-							Error: &schemas.ErrorField{
-								Message: "request failed during provider concurrency update",
-							},
-							ExtraFields: schemas.BifrostErrorExtraFields{
-								RequestType:    m.RequestType,
-								Provider:       provider,
-								ModelRequested: model,
-							},
-						}:
-						case <-time.After(1 * time.Second):
-							// If we can't send the error either, just log and continue
-							bifrost.logger.Warn("Failed to send error response during transfer timeout")
-						}
-					}
-				}(msg)
-				goto transferComplete
-			}
-		default:
-			// No more buffered messages
-			goto transferComplete
-		}
-	}
-
-transferComplete:
-	// Wait for all transfer goroutines to complete
-	transferWaitGroup.Wait()
-	if transferredCount > 0 {
-		bifrost.logger.Info("transferred %d buffered requests to new queue for provider %s", transferredCount, providerKey)
-	}
-
-	// Step 3: Close the old queue to signal workers to stop
-	close(oldQueue)
-
-	// Step 4: Atomically replace the queue
-	bifrost.requestQueues.Store(providerKey, newQueue)
-
-	// Step 5: Wait for all existing workers to finish processing in-flight requests
-	waitGroup, exists := bifrost.waitGroups.Load(providerKey)
-	if exists {
-		waitGroup.(*sync.WaitGroup).Wait()
-		bifrost.logger.Debug("all workers for provider %s have stopped", providerKey)
-	}
-
-	// Step 6: Create new wait group for the updated workers
-	bifrost.waitGroups.Store(providerKey, &sync.WaitGroup{})
+	oldQueues := oldQueueValue.(*providerQueues)
+	oldWaitGroupValue, _ := bifrost.waitGroups.Load(providerKey)
 
-	// Step 7: Create provider instance
+	// Step 1: Create provider instance, before touching the existing workers so that they keep serving on failure
 	provider, err := bifrost.createBaseProvider(providerKey, providerConfig)
 	if err != nil {
 		return fmt.Errorf("failed to create provider instance for %s: %v", providerKey, err)
 	}
 
-	// Step 7.5: Atomically replace the provider in the providers slice
-	// This must happen before starting new workers to prevent stale reads
+	// Step 2: Start new workers with updated concurrency on new queues with updated buffer sizes and priority pools
+	bifrost.logger.Debug("starting %d new workers for provider %s with buffer size %d",
+		providerConfig.ConcurrencyAndBufferSize.Concurrency,
+		providerKey,
+		providerConfig.ConcurrencyAndBufferSize.BufferSize)
+
+	newQueues := newProviderQueues(providerConfig.ConcurrencyAndBufferSize)
+	newWaitGroup := &sync.WaitGroup{}
+	bifrost.startWorkers(provider, providerConfig, newQueues, newWaitGroup)
+
+	// Step 3: Atomically replace the provider in the providers slice
 	bifrost.logger.Debug("atomically replacing provider instance in providers slice for %s", providerKey)
 
 	replacementAttempts := 0
@@ -1109,6 +1244,7 @@ transferComplete:
 	for {
 		replacementAttempts++
 		if replacementAttempts > maxReplacementAttempts {
+			newQueues.close()
 			return fmt.Errorf("failed to replace provider %s in providers slice after %d attempts", providerKey, maxReplacementAttempts)
 		}
 
@@ -1145,19 +1281,28 @@ transferComplete:
 		// Retrying as swapping did not work (likely due to concurrent modification)
 	}
 
-	// Step 8: Start new workers with updated concurrency
-	bifrost.logger.Debug("starting %d new workers for provider %s with buffer size %d",
-		providerConfig.ConcurrencyAndBufferSize.Concurrency,
-		providerKey,
-		providerConfig.ConcurrencyAndBufferSize.BufferSize)
+	// Step 4: Atomically replace the queues and wait group, new requests are sent to the new workers
+	bifrost.requestQueues.Store(providerKey, newQueues)
+	bifrost.waitGroups.Store(providerKey, newWaitGroup)
 
-	waitGroupValue, _ := bifrost.waitGroups.Load(providerKey)
-	currentWaitGroup := waitGroupValue.(*sync.WaitGroup)
+	// Step 5: Transfer any buffered requests from old queues to new queues
+	// This prevents request loss during the transition
+	var transferWaitGroup sync.WaitGroup
+	transferredCount := bifrost.transferQueuedRequests(oldQueues, newQueues, &transferWaitGroup)
+
+	// Wait for all transfer goroutines to complete
+	transferWaitGroup.Wait()
+	if transferredCount > 0 {
+		bifrost.logger.Info("transferred %d buffered requests to new queue for provider %s", transferredCount, providerKey)
+	}
 
-	for range providerConfig.ConcurrencyAndBufferSize.Concurrency {
-		currentWaitGroup.Add(1)
-		go bifrost.requestWorker(provider, providerConfig, newQueue)
+	// Step 6: Stop the existing workers in the background once their in-flight requests are done
+	var oldWaitGroup *sync.WaitGroup
+	if oldWaitGroupValue != nil {
+		oldWaitGroup = oldWaitGroupValue.(*sync.WaitGroup)
 	}
+	bifrost.retiringWorkers.Add(1)
+	go bifrost.retireProviderQueues(providerKey, oldQueues, oldWaitGroup)
 
 	bifrost.logger.Info("successfully updated provider configuration for provider %s", providerKey)
 	return nil
@@ -1451,6 +1596,14 @@ func (bifrost *Bifrost) createBaseProvider(providerKey schemas.ModelProvider, co
 		return openrouter.NewOpenRouterProvider(config, bifrost.logger), nil
 	case schemas.Elevenlabs:
 		return elevenlabs.NewElevenlabsProvider(config, bifrost.logger), nil
+	case schemas.Fireworks:
+		return fireworks.NewFireworksProvider(config, bifrost.logger)
+	case schemas.XAI:
+		return xai.NewXAIProvider(config, bifrost.logger)
+	case schemas.HuggingFace:
+		return huggingface.NewHuggingFaceProvider(config, bifrost.logger)
+	case schemas.Mock:
+		return mock.NewMockProvider(config, bifrost.logger)
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", targetProviderKey)
 	}
@@ -1468,9 +1621,9 @@ func (bifrost *Bifrost) prepareProvider(providerKey schemas.ModelProvider, confi
 		return fmt.Errorf("config is nil for provider %s", providerKey)
 	}
 
-	queue := make(chan *ChannelMessage, providerConfig.ConcurrencyAndBufferSize.BufferSize) // Buffered channel per provider
+	queues := newProviderQueues(providerConfig.ConcurrencyAndBufferSize) // Buffered channels per provider and priority pool
 
-	bifrost.requestQueues.Store(providerKey, queue)
+	bifrost.requestQueues.Store(providerKey, queues)
 
 	// Start specified number of workers
 	bifrost.waitGroups.Store(providerKey, &sync.WaitGroup{})
@@ -1498,27 +1651,28 @@ func (bifrost *Bifrost) prepareProvider(providerKey schemas.ModelProvider, confi
 		}
 	}
 
-	for range providerConfig.ConcurrencyAndBufferSize.Concurrency {
-		currentWaitGroup.Add(1)
-		go bifrost.requestWorker(provider, providerConfig, queue)
-	}
+	bifrost.startWorkers(provider, providerConfig, queues, currentWaitGroup)
 
 	return nil
 }
 
-// getProviderQueue returns the request queue for a given provider key.
+// getProviderQueue returns the request queue of the priority pool of the request for a given provider key.
 // If the queue doesn't exist, it creates one at runtime and initializes the provider,
 // given the provider config is provided in the account interface implementation.
 // This function uses read locks to prevent race conditions during provider updates.
-func (bifrost *Bifrost) getProviderQueue(providerKey schemas.ModelProvider) (chan *ChannelMessage, error) {
+// The returned release function must be called once the request is done with the queue, a provider update
+// only closes the queue once every request that got it has released it.
+func (bifrost *Bifrost) getProviderQueue(ctx context.Context, providerKey schemas.ModelProvider) (chan *ChannelMessage, func(), error) {
+	priority := requestPriority(ctx)
+
 	// Use read lock to allow concurrent reads but prevent concurrent updates
 	providerMutex := bifrost.getProviderMutex(providerKey)
 	providerMutex.RLock()
 
 	if queueValue, exists := bifrost.requestQueues.Load(providerKey); exists {
-		queue := queueValue.(chan *ChannelMessage)
+		queue, release := queueValue.(*providerQueues).acquire(priority)
 		providerMutex.RUnlock()
-		return queue, nil
+		return queue, release, nil
 	}
 
 	// Provider doesn't exist, need to create it
@@ -1529,28 +1683,28 @@ func (bifrost *Bifrost) getProviderQueue(providerKey schemas.ModelProvider) (cha
 
 	// Double-check after acquiring write lock (another goroutine might have created it)
 	if queueValue, exists := bifrost.requestQueues.Load(providerKey); exists {
-		queue := queueValue.(chan *ChannelMessage)
-		return queue, nil
+		queue, release := queueValue.(*providerQueues).acquire(priority)
+		return queue, release, nil
 	}
 
 	bifrost.logger.Debug(fmt.Sprintf("Creating new request queue for provider %s at runtime", providerKey))
 
 	config, err := bifrost.account.GetConfigForProvider(providerKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get config for provider: %v", err)
+		return nil, nil, fmt.Errorf("failed to get config for provider: %v", err)
 	}
 	if config == nil {
-		return nil, fmt.Errorf("config is nil for provider %s", providerKey)
+		return nil, nil, fmt.Errorf("config is nil for provider %s", providerKey)
 	}
 
 	if err := bifrost.prepareProvider(providerKey, config); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	queueValue, _ := bifrost.requestQueues.Load(providerKey)
-	queue := queueValue.(chan *ChannelMessage)
+	queue, release := queueValue.(*providerQueues).acquire(priority)
 
-	return queue, nil
+	return queue, release, nil
 }
 
 // getProviderByKey retrieves a provider instance from the providers array by its provider key.
@@ -1624,12 +1778,17 @@ func (bifrost *Bifrost) shouldTryFallbacks(req *schemas.BifrostRequest, primaryE
 	}
 
 	// If no fallbacks configured, return primary error
-	_, _, fallbacks := req.GetRequestFields()
+	provider, _, fallbacks := req.GetRequestFields()
 	if len(fallbacks) == 0 {
 		bifrost.logger.Debug("No fallbacks configured, we should not try fallbacks")
 		return false
 	}
 
+	// Check if the fallback policy of the provider fails fast on this error
+	if !bifrost.fallbackAllowed(provider, primaryErr) {
+		return false
+	}
+
 	// Should proceed with fallbacks
 	return true
 }
@@ -1704,6 +1863,11 @@ func (bifrost *Bifrost) shouldContinueWithFallbacks(fallback schemas.Fallback, f
 		return false
 	}
 
+	// Check if the fallback policy of the fallback provider fails fast on this error
+	if !bifrost.fallbackAllowed(fallback.Provider, fallbackErr) {
+		return false
+	}
+
 	bifrost.logger.Debug(fmt.Sprintf("Fallback provider %s failed: %s", fallback.Provider, fallbackErr.Error.Message))
 	return true
 }
@@ -1712,9 +1876,17 @@ func (bifrost *Bifrost) shouldContinueWithFallbacks(fallback schemas.Fallback, f
 // It handles plugin hooks, request validation, response processing, and fallback providers.
 // If the primary provider fails, it will try each fallback provider in order until one succeeds.
 // It is the wrapper for all non-streaming public API methods.
-func (bifrost *Bifrost) handleRequest(ctx context.Context, req *schemas.BifrostRequest) (*schemas.BifrostResponse, *schemas.BifrostError) {
+func (bifrost *Bifrost) handleRequest(ctx context.Context, req *schemas.BifrostRequest) (response *schemas.BifrostResponse, bifrostErr *schemas.BifrostError) {
 	defer bifrost.releaseBifrostRequest(req)
 
+	// Handle nil context early to prevent blocking
+	if ctx == nil {
+		ctx = bifrost.ctx
+	}
+	ctx, requestID := withRequestID(ctx)
+	defer func() { setRequestID(requestID, response, bifrostErr) }()
+	logger := newRequestLogger(bifrost.logger, ctx)
+
 	provider, model, fallbacks := req.GetRequestFields()
 
 	if err := validateRequest(req); err != nil {
@@ -1726,24 +1898,42 @@ func (bifrost *Bifrost) handleRequest(ctx context.Context, req *schemas.BifrostR
 		return nil, err
 	}
 
-	// Handle nil context early to prevent blocking
-	if ctx == nil {
-		ctx = bifrost.ctx
+	ctx = withEndUser(ctx, req)
+	ctx, err := bifrost.withRequestTags(ctx, req)
+	if err != nil {
+		err.ExtraFields = schemas.BifrostErrorExtraFields{
+			RequestType:    req.RequestType,
+			Provider:       provider,
+			ModelRequested: model,
+		}
+		return nil, err
 	}
+	ctx, req, err = bifrost.applyExperiment(ctx, req)
+	if err != nil {
+		err.ExtraFields = schemas.BifrostErrorExtraFields{
+			RequestType:    req.RequestType,
+			Provider:       provider,
+			ModelRequested: model,
+		}
+		return nil, err
+	}
+	ctx, req = bifrost.applyFallbackChain(ctx, req)
+	provider, model, fallbacks = req.GetRequestFields()
 
-	bifrost.logger.Debug(fmt.Sprintf("Primary provider %s with model %s and %d fallbacks", provider, model, len(fallbacks)))
+	logger.Debug(fmt.Sprintf("Primary provider %s with model %s and %d fallbacks", provider, model, len(fallbacks)))
 
 	// Try the primary provider first
 	ctx = context.WithValue(ctx, schemas.BifrostContextKeyFallbackIndex, 0)
 	primaryResult, primaryErr := bifrost.tryRequest(ctx, req)
+	setErrorCode(primaryErr)
 	if primaryErr != nil {
 		if primaryErr.Error != nil {
-			bifrost.logger.Debug(fmt.Sprintf("Primary provider %s with model %s returned error: %s", provider, model, primaryErr.Error.Message))
+			logger.Debug(fmt.Sprintf("Primary provider %s with model %s returned error: %s", provider, model, primaryErr.Error.Message))
 		} else {
-			bifrost.logger.Debug(fmt.Sprintf("Primary provider %s with model %s returned error: %v", provider, model, primaryErr))
+			logger.Debug(fmt.Sprintf("Primary provider %s with model %s returned error: %v", provider, model, primaryErr))
 		}
 		if len(fallbacks) > 0 {
-			bifrost.logger.Debug(fmt.Sprintf("Check if we should try %d fallbacks", len(fallbacks)))
+			logger.Debug(fmt.Sprintf("Check if we should try %d fallbacks", len(fallbacks)))
 		}
 	}
 
@@ -1761,21 +1951,30 @@ func (bifrost *Bifrost) handleRequest(ctx context.Context, req *schemas.BifrostR
 	}
 
 	// Try fallbacks in order
+	var skippedEmbeddingFallbacks []string
 	for i, fallback := range fallbacks {
+		// Embeddings of another model would be silently mixed with the ones of the primary model
+		if req.RequestType == schemas.EmbeddingRequest && !embeddingFallbackAllowed(ctx, model, fallback.Model) {
+			logger.Warn(fmt.Sprintf("skipping fallback %s/%s, its embeddings are incompatible with the ones of %s/%s", fallback.Provider, fallback.Model, provider, model))
+			skippedEmbeddingFallbacks = append(skippedEmbeddingFallbacks, string(fallback.Provider)+"/"+fallback.Model)
+			continue
+		}
+
 		ctx = context.WithValue(ctx, schemas.BifrostContextKeyFallbackIndex, i+1)
-		bifrost.logger.Debug(fmt.Sprintf("Trying fallback provider %s with model %s", fallback.Provider, fallback.Model))
+		logger.Debug(fmt.Sprintf("Trying fallback provider %s with model %s", fallback.Provider, fallback.Model))
 		ctx = context.WithValue(ctx, schemas.BifrostContextKeyFallbackRequestID, uuid.New().String())
 
 		fallbackReq := bifrost.prepareFallbackRequest(req, fallback)
 		if fallbackReq == nil {
-			bifrost.logger.Debug(fmt.Sprintf("Fallback provider %s with model %s is nil", fallback.Provider, fallback.Model))
+			logger.Debug(fmt.Sprintf("Fallback provider %s with model %s is nil", fallback.Provider, fallback.Model))
 			continue
 		}
 
 		// Try the fallback provider
 		result, fallbackErr := bifrost.tryRequest(ctx, fallbackReq)
+		setErrorCode(fallbackErr)
 		if fallbackErr == nil {
-			bifrost.logger.Debug(fmt.Sprintf("Successfully used fallback provider %s with model %s", fallback.Provider, fallback.Model))
+			logger.Debug(fmt.Sprintf("Successfully used fallback provider %s with model %s", fallback.Provider, fallback.Model))
 			return result, nil
 		}
 
@@ -1796,6 +1995,9 @@ func (bifrost *Bifrost) handleRequest(ctx context.Context, req *schemas.BifrostR
 			Provider:       provider,
 			ModelRequested: model,
 		}
+		if len(skippedEmbeddingFallbacks) > 0 && primaryErr.Error != nil {
+			primaryErr.Error.Message += fmt.Sprintf(" (fallbacks %s were skipped, their embeddings are incompatible with the ones of %s/%s unless mixed embeddings are allowed)", strings.Join(skippedEmbeddingFallbacks, ", "), provider, model)
+		}
 	}
 
 	// All providers failed, return the original error
@@ -1809,6 +2011,32 @@ func (bifrost *Bifrost) handleRequest(ctx context.Context, req *schemas.BifrostR
 func (bifrost *Bifrost) handleStreamRequest(ctx context.Context, req *schemas.BifrostRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
 	defer bifrost.releaseBifrostRequest(req)
 
+	if ctx == nil {
+		ctx = bifrost.ctx
+	}
+	ctx, requestID := withRequestID(ctx)
+
+	config := bifrost.streamBackpressure.Load()
+	if config == nil {
+		stream, err := bifrost.routeStreamRequest(ctx, req)
+		setRequestID(requestID, nil, err)
+		return stream, err
+	}
+
+	// The request is cancelled when its consumer is dropped, and the providers size their channels after the config
+	ctx, cancel := context.WithCancel(context.WithValue(ctx, schemas.BifrostContextKeyStreamBufferSize, streamBufferSize(config)))
+	stream, err := bifrost.routeStreamRequest(ctx, req)
+	if err != nil {
+		cancel()
+		setRequestID(requestID, nil, err)
+		return nil, err
+	}
+	return bifrost.withStreamBackpressure(ctx, cancel, req, stream, config), nil
+}
+
+// routeStreamRequest sends the stream request to the primary provider, and to its fallbacks if it fails
+func (bifrost *Bifrost) routeStreamRequest(ctx context.Context, req *schemas.BifrostRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+
 	provider, model, fallbacks := req.GetRequestFields()
 
 	if err := validateRequest(req); err != nil {
@@ -1824,10 +2052,32 @@ func (bifrost *Bifrost) handleStreamRequest(ctx context.Context, req *schemas.Bi
 	if ctx == nil {
 		ctx = bifrost.ctx
 	}
+	ctx = withEndUser(ctx, req)
+	ctx, err := bifrost.withRequestTags(ctx, req)
+	if err != nil {
+		err.ExtraFields = schemas.BifrostErrorExtraFields{
+			RequestType:    req.RequestType,
+			Provider:       provider,
+			ModelRequested: model,
+		}
+		return nil, err
+	}
+	ctx, req, err = bifrost.applyExperiment(ctx, req)
+	if err != nil {
+		err.ExtraFields = schemas.BifrostErrorExtraFields{
+			RequestType:    req.RequestType,
+			Provider:       provider,
+			ModelRequested: model,
+		}
+		return nil, err
+	}
+	ctx, req = bifrost.applyFallbackChain(ctx, req)
+	provider, model, fallbacks = req.GetRequestFields()
 
 	// Try the primary provider first
 	ctx = context.WithValue(ctx, schemas.BifrostContextKeyFallbackIndex, 0)
 	primaryResult, primaryErr := bifrost.tryStreamRequest(ctx, req)
+	setErrorCode(primaryErr)
 
 	// Check if we should proceed with fallbacks
 	shouldTryFallbacks := bifrost.shouldTryFallbacks(req, primaryErr)
@@ -1838,8 +2088,9 @@ func (bifrost *Bifrost) handleStreamRequest(ctx context.Context, req *schemas.Bi
 				Provider:       provider,
 				ModelRequested: model,
 			}
+			return nil, primaryErr
 		}
-		return primaryResult, primaryErr
+		return bifrost.withStreamChunkHooks(ctx, req, bifrost.withStreamFailover(ctx, req, primaryResult, 0)), nil
 	}
 
 	// Try fallbacks in order
@@ -1854,9 +2105,10 @@ func (bifrost *Bifrost) handleStreamRequest(ctx context.Context, req *schemas.Bi
 
 		// Try the fallback provider
 		result, fallbackErr := bifrost.tryStreamRequest(ctx, fallbackReq)
+		setErrorCode(fallbackErr)
 		if fallbackErr == nil {
-			bifrost.logger.Debug(fmt.Sprintf("Successfully used fallback provider %s with model %s", fallback.Provider, fallback.Model))
-			return result, nil
+			newRequestLogger(bifrost.logger, ctx).Debug(fmt.Sprintf("Successfully used fallback provider %s with model %s", fallback.Provider, fallback.Model))
+			return bifrost.withStreamChunkHooks(ctx, req, bifrost.withStreamFailover(ctx, req, result, i+1)), nil
 		}
 
 		// Check if we should continue with more fallbacks
@@ -1885,8 +2137,16 @@ func (bifrost *Bifrost) handleStreamRequest(ctx context.Context, req *schemas.Bi
 // tryRequest is a generic function that handles common request processing logic
 // It consolidates queue setup, plugin pipeline execution, enqueue logic, and response handling
 func (bifrost *Bifrost) tryRequest(ctx context.Context, req *schemas.BifrostRequest) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	ctx, req, hopErr := applyFallbackHop(ctx, req)
+	if hopErr != nil {
+		return nil, hopErr
+	}
+	req, remappedFrom := bifrost.remapDeprecatedModel(req)
+	if remappedFrom != "" {
+		ctx = context.WithValue(ctx, schemas.BifrostContextKeyModelRemappedFrom, remappedFrom)
+	}
 	provider, model, _ := req.GetRequestFields()
-	queue, err := bifrost.getProviderQueue(provider)
+	queue, releaseQueue, err := bifrost.getProviderQueue(ctx, provider)
 	if err != nil {
 		bifrostErr := newBifrostError(err)
 		bifrostErr.ExtraFields = schemas.BifrostErrorExtraFields{
@@ -1896,6 +2156,7 @@ func (bifrost *Bifrost) tryRequest(ctx context.Context, req *schemas.BifrostRequ
 		}
 		return nil, bifrostErr
 	}
+	defer releaseQueue()
 
 	// Add MCP tools to request if MCP is configured and requested
 	if req.RequestType != schemas.EmbeddingRequest &&
@@ -1936,6 +2197,96 @@ func (bifrost *Bifrost) tryRequest(ctx context.Context, req *schemas.BifrostRequ
 		}
 		return nil, bifrostErr
 	}
+	preReq, transformRules, parameterViolations, transformErr := bifrost.applyTransformRules(ctx, preReq)
+	if transformErr != nil {
+		transformErr.ExtraFields = schemas.BifrostErrorExtraFields{
+			RequestType:    req.RequestType,
+			Provider:       provider,
+			ModelRequested: model,
+		}
+		resp, bifrostErr := pipeline.RunPostHooks(&ctx, nil, transformErr, preCount)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+		return resp, nil
+	}
+	preProvider, preModel, _ := preReq.GetRequestFields()
+	if len(transformRules) > 0 {
+		ctx = context.WithValue(ctx, schemas.BifrostContextKeyTransformRules, transformRules)
+		if len(parameterViolations) > 0 {
+			ctx = context.WithValue(ctx, schemas.BifrostContextKeyParameterViolations, parameterViolations)
+		}
+		// A renamed model can belong to another provider
+		if preProvider != provider {
+			if queue, releaseQueue, err = bifrost.getProviderQueue(ctx, preProvider); err != nil {
+				queueErr := newBifrostError(err)
+				queueErr.ExtraFields = schemas.BifrostErrorExtraFields{
+					RequestType:    req.RequestType,
+					Provider:       preProvider,
+					ModelRequested: model,
+				}
+				resp, bifrostErr := pipeline.RunPostHooks(&ctx, nil, queueErr, preCount)
+				if bifrostErr != nil {
+					return nil, bifrostErr
+				}
+				return resp, nil
+			}
+			defer releaseQueue()
+		}
+	}
+	if policyErr := bifrost.checkDirectKeyPolicy(ctx, preProvider, preModel, pipeline.plugins); policyErr != nil {
+		policyErr.ExtraFields = schemas.BifrostErrorExtraFields{
+			RequestType:    req.RequestType,
+			Provider:       provider,
+			ModelRequested: model,
+		}
+		resp, bifrostErr := pipeline.RunPostHooks(&ctx, nil, policyErr, preCount)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+		return resp, nil
+	}
+	if imageErr := bifrost.normalizeImageInputs(ctx, preReq); imageErr != nil {
+		imageErr.ExtraFields = schemas.BifrostErrorExtraFields{
+			RequestType:    req.RequestType,
+			Provider:       provider,
+			ModelRequested: model,
+		}
+		resp, bifrostErr := pipeline.RunPostHooks(&ctx, nil, imageErr, preCount)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+		return resp, nil
+	}
+	if capabilityErr := bifrost.validateModelCapabilities(preReq); capabilityErr != nil {
+		capabilityErr.ExtraFields = schemas.BifrostErrorExtraFields{
+			RequestType:    req.RequestType,
+			Provider:       provider,
+			ModelRequested: model,
+		}
+		resp, bifrostErr := pipeline.RunPostHooks(&ctx, nil, capabilityErr, preCount)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+		return resp, nil
+	}
+	preReq, compressed := bifrost.compressPrompt(ctx, preReq)
+	if compressed > 0 {
+		ctx = context.WithValue(ctx, schemas.BifrostContextKeyPromptCompressed, compressed)
+	}
+	preReq, windowErr := bifrost.enforceContextWindow(preReq)
+	if windowErr != nil {
+		windowErr.ExtraFields = schemas.BifrostErrorExtraFields{
+			RequestType:    req.RequestType,
+			Provider:       provider,
+			ModelRequested: model,
+		}
+		resp, bifrostErr := pipeline.RunPostHooks(&ctx, nil, windowErr, preCount)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+		return resp, nil
+	}
 
 	msg := bifrost.getChannelMessage(*preReq)
 	msg.Context = ctx
@@ -1954,7 +2305,7 @@ func (bifrost *Bifrost) tryRequest(ctx context.Context, req *schemas.BifrostRequ
 	default:
 		if bifrost.dropExcessRequests.Load() {
 			bifrost.releaseChannelMessage(msg)
-			bifrost.logger.Warn("Request dropped: queue is full, please increase the queue size or set dropExcessRequests to false")
+			newRequestLogger(bifrost.logger, ctx).Warn("Request dropped: queue is full, please increase the queue size or set dropExcessRequests to false")
 			bifrostErr := newBifrostErrorFromMsg("request dropped: queue is full")
 			bifrostErr.ExtraFields = schemas.BifrostErrorExtraFields{
 				RequestType:    req.RequestType,
@@ -1980,10 +2331,9 @@ func (bifrost *Bifrost) tryRequest(ctx context.Context, req *schemas.BifrostRequ
 
 	var result *schemas.BifrostResponse
 	var resp *schemas.BifrostResponse
-	pluginCount := len(*bifrost.plugins.Load())
 	select {
 	case result = <-msg.Response:
-		resp, bifrostErr := pipeline.RunPostHooks(&msg.Context, result, nil, pluginCount)
+		resp, bifrostErr := pipeline.RunPostHooks(&msg.Context, result, nil, preCount)
 		if bifrostErr != nil {
 			bifrost.releaseChannelMessage(msg)
 			return nil, bifrostErr
@@ -1992,7 +2342,7 @@ func (bifrost *Bifrost) tryRequest(ctx context.Context, req *schemas.BifrostRequ
 		return resp, nil
 	case bifrostErrVal := <-msg.Err:
 		bifrostErrPtr := &bifrostErrVal
-		resp, bifrostErrPtr = pipeline.RunPostHooks(&msg.Context, nil, bifrostErrPtr, pluginCount)
+		resp, bifrostErrPtr = pipeline.RunPostHooks(&msg.Context, nil, bifrostErrPtr, preCount)
 		bifrost.releaseChannelMessage(msg)
 		if bifrostErrPtr != nil {
 			return nil, bifrostErrPtr
@@ -2004,8 +2354,16 @@ func (bifrost *Bifrost) tryRequest(ctx context.Context, req *schemas.BifrostRequ
 // tryStreamRequest is a generic function that handles common request processing logic
 // It consolidates queue setup, plugin pipeline execution, enqueue logic, and response handling
 func (bifrost *Bifrost) tryStreamRequest(ctx context.Context, req *schemas.BifrostRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	ctx, req, hopErr := applyFallbackHop(ctx, req)
+	if hopErr != nil {
+		return nil, hopErr
+	}
+	req, remappedFrom := bifrost.remapDeprecatedModel(req)
+	if remappedFrom != "" {
+		ctx = context.WithValue(ctx, schemas.BifrostContextKeyModelRemappedFrom, remappedFrom)
+	}
 	provider, model, _ := req.GetRequestFields()
-	queue, err := bifrost.getProviderQueue(provider)
+	queue, releaseQueue, err := bifrost.getProviderQueue(ctx, provider)
 	if err != nil {
 		bifrostErr := newBifrostError(err)
 		bifrostErr.ExtraFields = schemas.BifrostErrorExtraFields{
@@ -2015,6 +2373,7 @@ func (bifrost *Bifrost) tryStreamRequest(ctx context.Context, req *schemas.Bifro
 		}
 		return nil, bifrostErr
 	}
+	defer releaseQueue()
 
 	// Add MCP tools to request if MCP is configured and requested
 	if req.RequestType != schemas.SpeechStreamRequest && req.RequestType != schemas.TranscriptionStreamRequest && bifrost.mcpManager != nil {
@@ -2071,17 +2430,7 @@ func (bifrost *Bifrost) tryStreamRequest(ctx context.Context, req *schemas.Bifro
 					// Run post hooks on the stream message
 					processedResponse, processedError := pipelinePostHookRunner(&ctx, bifrostResponse, streamMsg.BifrostError)
 
-					streamResponse := &schemas.BifrostStream{}
-					if processedResponse != nil {
-						streamResponse.BifrostTextCompletionResponse = processedResponse.TextCompletionResponse
-						streamResponse.BifrostChatResponse = processedResponse.ChatResponse
-						streamResponse.BifrostResponsesStreamResponse = processedResponse.ResponsesStreamResponse
-						streamResponse.BifrostSpeechStreamResponse = processedResponse.SpeechStreamResponse
-						streamResponse.BifrostTranscriptionStreamResponse = processedResponse.TranscriptionStreamResponse
-					}
-					if processedError != nil {
-						streamResponse.BifrostError = processedError
-					}
+					streamResponse := schemas.AcquireBifrostStream(processedResponse, processedError)
 
 					// Send the processed message to the output stream
 					outputStream <- streamResponse
@@ -2110,9 +2459,101 @@ func (bifrost *Bifrost) tryStreamRequest(ctx context.Context, req *schemas.Bifro
 		}
 		return nil, bifrostErr
 	}
+	preReq, transformRules, parameterViolations, transformErr := bifrost.applyTransformRules(ctx, preReq)
+	if transformErr != nil {
+		transformErr.ExtraFields = schemas.BifrostErrorExtraFields{
+			RequestType:    req.RequestType,
+			Provider:       provider,
+			ModelRequested: model,
+		}
+		resp, bifrostErr := pipeline.RunPostHooks(&ctx, nil, transformErr, preCount)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+		return newBifrostMessageChan(resp), nil
+	}
+	preProvider, preModel, _ := preReq.GetRequestFields()
+	if len(transformRules) > 0 {
+		ctx = context.WithValue(ctx, schemas.BifrostContextKeyTransformRules, transformRules)
+		if len(parameterViolations) > 0 {
+			ctx = context.WithValue(ctx, schemas.BifrostContextKeyParameterViolations, parameterViolations)
+		}
+		// A renamed model can belong to another provider
+		if preProvider != provider {
+			if queue, releaseQueue, err = bifrost.getProviderQueue(ctx, preProvider); err != nil {
+				queueErr := newBifrostError(err)
+				queueErr.ExtraFields = schemas.BifrostErrorExtraFields{
+					RequestType:    req.RequestType,
+					Provider:       preProvider,
+					ModelRequested: model,
+				}
+				resp, bifrostErr := pipeline.RunPostHooks(&ctx, nil, queueErr, preCount)
+				if bifrostErr != nil {
+					return nil, bifrostErr
+				}
+				return newBifrostMessageChan(resp), nil
+			}
+			defer releaseQueue()
+		}
+	}
+	if policyErr := bifrost.checkDirectKeyPolicy(ctx, preProvider, preModel, pipeline.plugins); policyErr != nil {
+		policyErr.ExtraFields = schemas.BifrostErrorExtraFields{
+			RequestType:    req.RequestType,
+			Provider:       provider,
+			ModelRequested: model,
+		}
+		resp, bifrostErr := pipeline.RunPostHooks(&ctx, nil, policyErr, preCount)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+		return newBifrostMessageChan(resp), nil
+	}
+	if imageErr := bifrost.normalizeImageInputs(ctx, preReq); imageErr != nil {
+		imageErr.ExtraFields = schemas.BifrostErrorExtraFields{
+			RequestType:    req.RequestType,
+			Provider:       provider,
+			ModelRequested: model,
+		}
+		resp, bifrostErr := pipeline.RunPostHooks(&ctx, nil, imageErr, preCount)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+		return newBifrostMessageChan(resp), nil
+	}
+	if capabilityErr := bifrost.validateModelCapabilities(preReq); capabilityErr != nil {
+		capabilityErr.ExtraFields = schemas.BifrostErrorExtraFields{
+			RequestType:    req.RequestType,
+			Provider:       provider,
+			ModelRequested: model,
+		}
+		resp, bifrostErr := pipeline.RunPostHooks(&ctx, nil, capabilityErr, preCount)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+		return newBifrostMessageChan(resp), nil
+	}
+	preReq, compressed := bifrost.compressPrompt(ctx, preReq)
+	if compressed > 0 {
+		ctx = context.WithValue(ctx, schemas.BifrostContextKeyPromptCompressed, compressed)
+	}
+	preReq, windowErr := bifrost.enforceContextWindow(preReq)
+	if windowErr != nil {
+		windowErr.ExtraFields = schemas.BifrostErrorExtraFields{
+			RequestType:    req.RequestType,
+			Provider:       provider,
+			ModelRequested: model,
+		}
+		resp, bifrostErr := pipeline.RunPostHooks(&ctx, nil, windowErr, preCount)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+		return newBifrostMessageChan(resp), nil
+	}
 
 	msg := bifrost.getChannelMessage(*preReq)
 	msg.Context = ctx
+	msg.plugins = pipeline.plugins
+	msg.executedPreHooks = preCount
 
 	select {
 	case queue <- msg:
@@ -2129,7 +2570,7 @@ func (bifrost *Bifrost) tryStreamRequest(ctx context.Context, req *schemas.Bifro
 	default:
 		if bifrost.dropExcessRequests.Load() {
 			bifrost.releaseChannelMessage(msg)
-			bifrost.logger.Warn("Request dropped: queue is full, please increase the queue size or set dropExcessRequests to false")
+			newRequestLogger(bifrost.logger, ctx).Warn("Request dropped: queue is full, please increase the queue size or set dropExcessRequests to false")
 			bifrostErr := newBifrostErrorFromMsg("request dropped: queue is full")
 			bifrostErr.ExtraFields = schemas.BifrostErrorExtraFields{
 				RequestType:    req.RequestType,
@@ -2159,14 +2600,14 @@ func (bifrost *Bifrost) tryStreamRequest(ctx context.Context, req *schemas.Bifro
 		return stream, nil
 	case bifrostErrVal := <-msg.Err:
 		if bifrostErrVal.Error != nil {
-			bifrost.logger.Debug("error while executing stream request: %s", bifrostErrVal.Error.Message)
+			newRequestLogger(bifrost.logger, ctx).Debug("error while executing stream request: %s", bifrostErrVal.Error.Message)
 		} else {
-			bifrost.logger.Debug("error while executing stream request: %+v", bifrostErrVal)
+			newRequestLogger(bifrost.logger, ctx).Debug("error while executing stream request: %+v", bifrostErrVal)
 		}
 		// Marking final chunk
 		ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
 		// On error we will complete post-hooks
-		recoveredResp, recoveredErr := pipeline.RunPostHooks(&ctx, nil, &bifrostErrVal, len(*bifrost.plugins.Load()))
+		recoveredResp, recoveredErr := pipeline.RunPostHooks(&ctx, nil, &bifrostErrVal, preCount)
 		bifrost.releaseChannelMessage(msg)
 		if recoveredErr != nil {
 			return nil, recoveredErr
@@ -2180,6 +2621,7 @@ func (bifrost *Bifrost) tryStreamRequest(ctx context.Context, req *schemas.Bifro
 
 // executeRequestWithRetries is a generic function that handles common request processing logic
 // It consolidates retry logic, backoff calculation, and error handling
+// Failed requests are retried according to the retry policy of the provider, see schemas.RetryPolicy
 // It is not a bifrost method because interface methods in go cannot be generic
 func executeRequestWithRetries[T any](
 	ctx *context.Context,
@@ -2192,27 +2634,18 @@ func executeRequestWithRetries[T any](
 	var result T
 	var bifrostError *schemas.BifrostError
 	var attempts int
+	logger := newRequestLogger(logger, *ctx)
 
-	for attempts = 0; attempts <= config.NetworkConfig.MaxRetries; attempts++ {
-		*ctx = context.WithValue(*ctx, schemas.BifrostContextKeyNumberOfRetries, attempts)
-		if attempts > 0 {
-			// Log retry attempt
-			var retryMsg string
-			if bifrostError != nil && bifrostError.Error != nil {
-				retryMsg = bifrostError.Error.Message
-			} else if bifrostError != nil && bifrostError.StatusCode != nil {
-				retryMsg = fmt.Sprintf("status=%d", *bifrostError.StatusCode)
-				if bifrostError.Type != nil {
-					retryMsg += ", type=" + *bifrostError.Type
-				}
-			}
-			logger.Debug("retrying request (attempt %d/%d) for model %s: %s", attempts, config.NetworkConfig.MaxRetries, model, retryMsg)
+	policy := config.NetworkConfig.RetryPolicy
+	if policy == nil {
+		policy = defaultRetryPolicy(config.NetworkConfig)
+	}
+	budget := getRetryBudget(providerKey, config.NetworkConfig.RetryPolicy)
+	budget.recordRequest()
+	retriesByRule := make([]int, len(policy.Rules))
 
-			// Calculate and apply backoff
-			backoff := calculateBackoff(attempts-1, config)
-			logger.Debug("sleeping for %s", backoff)
-			time.Sleep(backoff)
-		}
+	for attempts = 0; ; attempts++ {
+		*ctx = context.WithValue(*ctx, schemas.BifrostContextKeyNumberOfRetries, attempts)
 
 		logger.Debug("attempting %s request for provider %s", requestType, providerKey)
 
@@ -2221,32 +2654,55 @@ func executeRequestWithRetries[T any](
 
 		logger.Debug("request %s for provider %s completed", requestType, providerKey)
 
-		// Check if successful or if we should retry
-		if bifrostError == nil ||
-			bifrostError.IsBifrostError ||
-			(bifrostError.Error != nil && bifrostError.Error.Type != nil && *bifrostError.Error.Type == schemas.RequestCancelled) {
+		if bifrostError == nil {
 			break
 		}
 
-		// Check if we should retry based on status code or error message
-		shouldRetry := false
-
-		if bifrostError.Error != nil && bifrostError.Error.Message == schemas.ErrProviderDoRequest {
-			shouldRetry = true
-			logger.Debug("detected request HTTP error, will retry: %s", bifrostError.Error.Message)
+		// Check if the error matches a rule with retries left
+		ruleIndex := matchRetryRule(policy, bifrostError)
+		if ruleIndex < 0 {
+			break
+		}
+		rule := policy.Rules[ruleIndex]
+		maxRetries := rule.MaxRetries
+		// The hop of a fallback chain can override the retries of the provider
+		if hopRetries, ok := (*ctx).Value(fallbackHopMaxRetriesContextKey).(int); ok {
+			maxRetries = hopRetries
 		}
+		if retriesByRule[ruleIndex] >= maxRetries {
+			break
+		}
+		delay, ok := retryDelay(policy, rule, config.NetworkConfig, retriesByRule[ruleIndex], bifrostError)
+		if !ok {
+			logger.Debug("provider %s asked to retry after %s, longer than allowed, not retrying", providerKey, *bifrostError.RetryAfter)
+			break
+		}
+		if !budget.tryRetry() {
+			logger.Debug("retry budget of provider %s exhausted, not retrying", providerKey)
+			break
+		}
+		retriesByRule[ruleIndex]++
 
-		// Retry if status code or error object indicates rate limiting
-		if (bifrostError.StatusCode != nil && retryableStatusCodes[*bifrostError.StatusCode]) ||
-			(bifrostError.Error != nil &&
-				(IsRateLimitErrorMessage(bifrostError.Error.Message) ||
-					(bifrostError.Error.Type != nil && IsRateLimitErrorMessage(*bifrostError.Error.Type)))) {
-			shouldRetry = true
-			logger.Debug("detected rate limit error in message, will retry: %s", bifrostError.Error.Message)
+		// Log retry attempt
+		var retryMsg string
+		if bifrostError.Error != nil && bifrostError.Error.Message != "" {
+			retryMsg = bifrostError.Error.Message
+		} else if bifrostError.StatusCode != nil {
+			retryMsg = fmt.Sprintf("status=%d", *bifrostError.StatusCode)
+			if bifrostError.Type != nil {
+				retryMsg += ", type=" + *bifrostError.Type
+			}
 		}
+		logger.Debug("retrying request (attempt %d/%d of rule %s) for model %s: %s", retriesByRule[ruleIndex], maxRetries, rule.Name, model, retryMsg)
 
-		if !shouldRetry {
-			break
+		// Apply backoff, unless the request is cancelled meanwhile
+		logger.Debug("sleeping for %s", delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-(*ctx).Done():
+			timer.Stop()
+			return result, bifrostError
 		}
 	}
 
@@ -2259,17 +2715,15 @@ func executeRequestWithRetries[T any](
 }
 
 // requestWorker handles incoming requests from the queue for a specific provider.
-// It manages retries, error handling, and response processing.
-func (bifrost *Bifrost) requestWorker(provider schemas.Provider, config *schemas.ProviderConfig, queue chan *ChannelMessage) {
-	defer func() {
-		if waitGroupValue, ok := bifrost.waitGroups.Load(provider.GetProviderKey()); ok {
-			waitGroup := waitGroupValue.(*sync.WaitGroup)
-			waitGroup.Done()
-		}
-	}()
+// It manages retries, error handling, and response processing. busy counts the workers of its pool handling a request.
+func (bifrost *Bifrost) requestWorker(provider schemas.Provider, config *schemas.ProviderConfig, queue chan *ChannelMessage, busy *atomic.Int64, waitGroup *sync.WaitGroup) {
+	defer waitGroup.Done()
 
 	for req := range queue {
+		busy.Add(1)
 		_, model, _ := req.BifrostRequest.GetRequestFields()
+		requestID, _ := req.Context.Value(schemas.BifrostContextKeyRequestID).(string)
+		logger := newRequestLogger(bifrost.logger, req.Context)
 
 		var result *schemas.BifrostResponse
 		var stream chan *schemas.BifrostStream
@@ -2287,7 +2741,7 @@ func (bifrost *Bifrost) requestWorker(provider schemas.Provider, config *schemas
 			// Use the custom provider name for actual key selection, but pass base provider type for key validation
 			key, err = bifrost.selectKeyFromProviderForModel(&req.Context, req.RequestType, provider.GetProviderKey(), model, baseProvider)
 			if err != nil {
-				bifrost.logger.Debug("error selecting key for model %s: %v", model, err)
+				logger.Debug("error selecting key for model %s: %v", model, err)
 				req.Err <- schemas.BifrostError{
 					IsBifrostError: false,
 					Error: &schemas.ErrorField{
@@ -2298,20 +2752,58 @@ func (bifrost *Bifrost) requestWorker(provider schemas.Provider, config *schemas
 						Provider:       provider.GetProviderKey(),
 						ModelRequested: model,
 						RequestType:    req.RequestType,
+						RequestID:      requestID,
 					},
 				}
+				busy.Add(-1)
 				continue
 			}
 			req.Context = context.WithValue(req.Context, schemas.BifrostContextKeySelectedKeyID, key.ID)
 			req.Context = context.WithValue(req.Context, schemas.BifrostContextKeySelectedKeyName, key.Name)
+			if key.IsTest {
+				req.Context = context.WithValue(req.Context, schemas.BifrostContextKeySelectedKeyIsTest, true)
+				if key.SpendLimit != nil {
+					req.Context = context.WithValue(req.Context, schemas.BifrostContextKeySelectedKeySpendLimit, *key.SpendLimit)
+				}
+			}
 		}
+		// The ID of the request is forwarded to the providers accepting one
+		req.Context = withProviderRequestID(req.Context, baseProvider)
+
+		// Keys overriding the proxy of the provider send their requests through it
+		keyProvider, err := bifrost.providerForKey(provider, config, key)
+		if err != nil {
+			req.Err <- schemas.BifrostError{
+				IsBifrostError: false,
+				Error: &schemas.ErrorField{
+					Message: err.Error(),
+					Error:   err,
+				},
+				ExtraFields: schemas.BifrostErrorExtraFields{
+					Provider:       provider.GetProviderKey(),
+					ModelRequested: model,
+					RequestType:    req.RequestType,
+					RequestID:      requestID,
+				},
+			}
+			busy.Add(-1)
+			continue
+		}
+
 		// Create plugin pipeline for streaming requests outside retry loop to prevent leaks
 		var postHookRunner schemas.PostHookRunner
 		var pipeline *PluginPipeline
 		if IsStreamRequestType(req.RequestType) {
+			// The PostHooks run for the plugins of the pipeline of the request whose PreHook ran, as for other requests
 			pipeline = bifrost.getPluginPipeline()
+			pipeline.plugins = req.plugins
+			executedPreHooks := req.executedPreHooks
 			postHookRunner = func(ctx *context.Context, result *schemas.BifrostResponse, err *schemas.BifrostError) (*schemas.BifrostResponse, *schemas.BifrostError) {
-				resp, bifrostErr := pipeline.RunPostHooks(ctx, result, err, len(*bifrost.plugins.Load()))
+				if key.IsTest && result != nil {
+					result.GetExtraFields().TestKey = true
+				}
+				setRequestID(requestID, result, err)
+				resp, bifrostErr := pipeline.RunPostHooks(ctx, result, err, executedPreHooks)
 				if bifrostErr != nil {
 					return nil, bifrostErr
 				}
@@ -2319,26 +2811,89 @@ func (bifrost *Bifrost) requestWorker(provider schemas.Provider, config *schemas
 			}
 		}
 
-		// Execute request with retries
+		// Non-stream requests asking for it record the HTTP request rendered by the provider
+		var capture *providerUtils.ProviderRequestCapture
+		if captureRequested, _ := req.Context.Value(schemas.BifrostContextKeyCaptureProviderRequest).(bool); captureRequested && !IsStreamRequestType(req.RequestType) {
+			req.Context, capture = providerUtils.WithProviderRequestCapture(req.Context)
+		}
+
+		// Execute request with retries, every attempt rolls the chaos faults again
+		startedAt := time.Now()
 		if IsStreamRequestType(req.RequestType) {
+			startStream := func(req *ChannelMessage) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+				faults := rollChaosFaults(bifrost.chaos.Load(), provider.GetProviderKey(), model)
+				if faults == nil {
+					return bifrost.handleProviderStreamRequest(keyProvider, req, key, postHookRunner)
+				}
+				if bifrostError := faults.beforeRequest(req.Context, provider.GetProviderKey(), true); bifrostError != nil {
+					return nil, bifrostError
+				}
+				if faults.disconnect == nil {
+					return bifrost.handleProviderStreamRequest(keyProvider, req, key, postHookRunner)
+				}
+				// The provider streams with its own context, cancelled when the connection is lost
+				providerCtx, cancelProvider := context.WithCancel(req.Context)
+				providerReq := *req
+				providerReq.Context = providerCtx
+				providerStream, bifrostError := bifrost.handleProviderStreamRequest(keyProvider, &providerReq, key, postHookRunner)
+				if bifrostError != nil {
+					cancelProvider()
+					return nil, bifrostError
+				}
+				return faults.disconnectStream(req.Context, cancelProvider, providerStream, postHookRunner, req.RequestType, provider.GetProviderKey(), model, logger), nil
+			}
 			stream, bifrostError = executeRequestWithRetries(&req.Context, config, func() (chan *schemas.BifrostStream, *schemas.BifrostError) {
-				return bifrost.handleProviderStreamRequest(provider, req, key, postHookRunner)
+				timeout := firstChunkTimeout(config)
+				if timeout <= 0 {
+					return startStream(req)
+				}
+				// The provider streams with its own context, cancelled when the first chunk takes too long
+				providerCtx, cancelProvider := context.WithCancel(req.Context)
+				providerReq := *req
+				providerReq.Context = providerCtx
+				providerStream, bifrostError := startStream(&providerReq)
+				if bifrostError != nil {
+					cancelProvider()
+					return nil, bifrostError
+				}
+				return awaitFirstChunk(req.Context, cancelProvider, providerStream, timeout, req.RequestType, provider.GetProviderKey(), model)
 			}, req.RequestType, provider.GetProviderKey(), model)
 		} else {
 			result, bifrostError = executeRequestWithRetries(&req.Context, config, func() (*schemas.BifrostResponse, *schemas.BifrostError) {
-				return bifrost.handleProviderRequest(provider, req, key)
+				if faults := rollChaosFaults(bifrost.chaos.Load(), provider.GetProviderKey(), model); faults != nil {
+					if bifrostError := faults.beforeRequest(req.Context, provider.GetProviderKey(), false); bifrostError != nil {
+						return nil, bifrostError
+					}
+				}
+				return bifrost.handleProviderRequest(keyProvider, req, key)
 			}, req.RequestType, provider.GetProviderKey(), model)
 		}
+		bifrost.recordSLO(req.Context, provider.GetProviderKey(), model, time.Since(startedAt), bifrostError)
+		if key.IsTest && result != nil {
+			result.GetExtraFields().TestKey = true
+		}
+		setRequestID(requestID, result, nil)
+		if capture != nil && result != nil {
+			result.GetExtraFields().ProviderRequest = capture.Request()
+		}
+		if violations, ok := req.Context.Value(schemas.BifrostContextKeyParameterViolations).([]schemas.BifrostParameterViolation); ok && result != nil {
+			result.GetExtraFields().ParameterViolations = violations
+		}
+		if result != nil {
+			result.GetExtraFields().Moderation = responseModeration(result)
+		}
 
 		if pipeline != nil {
 			bifrost.releasePluginPipeline(pipeline)
 		}
 
 		if bifrostError != nil {
+			setErrorCode(bifrostError)
 			bifrostError.ExtraFields = schemas.BifrostErrorExtraFields{
 				Provider:       provider.GetProviderKey(),
 				ModelRequested: model,
 				RequestType:    req.RequestType,
+				RequestID:      requestID,
 			}
 
 			// Send error with context awareness to prevent deadlock
@@ -2347,10 +2902,10 @@ func (bifrost *Bifrost) requestWorker(provider schemas.Provider, config *schemas
 				// Error sent successfully
 			case <-req.Context.Done():
 				// Client no longer listening, log and continue
-				bifrost.logger.Debug("Client context cancelled while sending error response")
+				logger.Debug("Client context cancelled while sending error response")
 			case <-time.After(5 * time.Second):
 				// Timeout to prevent indefinite blocking
-				bifrost.logger.Warn("Timeout while sending error response, client may have disconnected")
+				logger.Warn("Timeout while sending error response, client may have disconnected")
 			}
 		} else {
 			if IsStreamRequestType(req.RequestType) {
@@ -2360,10 +2915,10 @@ func (bifrost *Bifrost) requestWorker(provider schemas.Provider, config *schemas
 					// Stream sent successfully
 				case <-req.Context.Done():
 					// Client no longer listening, log and continue
-					bifrost.logger.Debug("Client context cancelled while sending stream response")
+					logger.Debug("Client context cancelled while sending stream response")
 				case <-time.After(5 * time.Second):
 					// Timeout to prevent indefinite blocking
-					bifrost.logger.Warn("Timeout while sending stream response, client may have disconnected")
+					logger.Warn("Timeout while sending stream response, client may have disconnected")
 				}
 			} else {
 				// Send response with context awareness to prevent deadlock
@@ -2372,13 +2927,14 @@ func (bifrost *Bifrost) requestWorker(provider schemas.Provider, config *schemas
 					// Response sent successfully
 				case <-req.Context.Done():
 					// Client no longer listening, log and continue
-					bifrost.logger.Debug("Client context cancelled while sending response")
+					logger.Debug("Client context cancelled while sending response")
 				case <-time.After(5 * time.Second):
 					// Timeout to prevent indefinite blocking
-					bifrost.logger.Warn("Timeout while sending response, client may have disconnected")
+					logger.Warn("Timeout while sending response, client may have disconnected")
 				}
 			}
 		}
+		busy.Add(-1)
 	}
 
 	// bifrost.logger.Debug("worker for provider %s exiting...", provider.GetProviderKey())
@@ -2481,9 +3037,21 @@ func (p *PluginPipeline) RunPreHooks(ctx *context.Context, req *schemas.BifrostR
 	defer func() {
 		*ctx = pluginCtx.GetParentCtxWithUserValues()
 	}()
-	for i, plugin := range p.plugins {
+	pipelineResolved := p.pipelines == nil
+	for i := 0; i < len(p.plugins); i++ {
+		plugin := p.plugins[i]
+		if !pipelineResolved && p.pipelines.manages(plugin.GetName()) {
+			pipelineResolved = true
+			if shortCircuit = p.applyPipeline(pluginCtx, req, i); shortCircuit != nil {
+				return req, shortCircuit, p.executedPreHooks
+			}
+			if i >= len(p.plugins) {
+				break
+			}
+			plugin = p.plugins[i]
+		}
 		p.logger.Debug("running pre-hook for plugin %s", plugin.GetName())
-		req, shortCircuit, err = plugin.PreHook(pluginCtx, req)
+		req, shortCircuit, err = p.runPreHook(pluginCtx, plugin, req)
 		if err != nil {
 			p.preHookErrors = append(p.preHookErrors, err)
 			p.logger.Warn("error in PreHook for plugin %s: %v", plugin.GetName(), err)
@@ -2508,22 +3076,25 @@ func (p *PluginPipeline) RunPostHooks(ctx *context.Context, resp *schemas.Bifros
 	if runFrom > len(p.plugins) {
 		runFrom = len(p.plugins)
 	}
-	var err error
-	pluginCtx, cancel := schemas.NewBifrostContextWithTimeout(*ctx, 10*time.Second)
-	defer cancel()
-	for i := runFrom - 1; i >= 0; i-- {
-		plugin := p.plugins[i]
-		p.logger.Debug("running post-hook for plugin %s", plugin.GetName())
-		resp, bifrostErr, err = plugin.PostHook(pluginCtx, resp, bifrostErr)
-		if err != nil {
-			p.postHookErrors = append(p.postHookErrors, err)
-			p.logger.Warn("error in PostHook for plugin %s: %v", plugin.GetName(), err)
+	// Without post hooks to run, e.g. for every chunk of a stream without plugins, the plugin context is not created
+	if runFrom > 0 {
+		var err error
+		pluginCtx, cancel := schemas.NewBifrostContextWithTimeout(*ctx, 10*time.Second)
+		defer cancel()
+		for i := runFrom - 1; i >= 0; i-- {
+			plugin := p.plugins[i]
+			p.logger.Debug("running post-hook for plugin %s", plugin.GetName())
+			resp, bifrostErr, err = p.runPostHook(pluginCtx, plugin, resp, bifrostErr)
+			if err != nil {
+				p.postHookErrors = append(p.postHookErrors, err)
+				p.logger.Warn("error in PostHook for plugin %s: %v", plugin.GetName(), err)
+			}
+			// If a plugin recovers from an error (sets bifrostErr to nil and sets resp), allow that
+			// If a plugin invalidates a response (sets resp to nil and sets bifrostErr), allow that
 		}
-		// If a plugin recovers from an error (sets bifrostErr to nil and sets resp), allow that
-		// If a plugin invalidates a response (sets resp to nil and sets bifrostErr), allow that
+		// Capturing plugin ctx values and putting them in the request context
+		*ctx = pluginCtx.GetParentCtxWithUserValues()
 	}
-	// Capturing plugin ctx values and putting them in the request context
-	*ctx = pluginCtx.GetParentCtxWithUserValues()
 	// Final logic: if both are set, error takes precedence, unless error is nil
 	if bifrostErr != nil {
 		if resp != nil && bifrostErr.StatusCode == nil && bifrostErr.Error != nil && bifrostErr.Error.Type == nil &&
@@ -2539,6 +3110,9 @@ func (p *PluginPipeline) RunPostHooks(ctx *context.Context, resp *schemas.Bifros
 // resetPluginPipeline resets a PluginPipeline instance for reuse
 func (p *PluginPipeline) resetPluginPipeline() {
 	p.executedPreHooks = 0
+	p.executors = nil
+	p.directKeyRequiredPlugins = nil
+	p.pipelines = nil
 	p.preHookErrors = p.preHookErrors[:0]
 	p.postHookErrors = p.postHookErrors[:0]
 }
@@ -2547,6 +3121,13 @@ func (p *PluginPipeline) resetPluginPipeline() {
 func (bifrost *Bifrost) getPluginPipeline() *PluginPipeline {
 	pipeline := bifrost.pluginPipelinePool.Get().(*PluginPipeline)
 	pipeline.plugins = *bifrost.plugins.Load()
+	if executors := bifrost.pluginExecutors.Load(); executors != nil {
+		pipeline.executors = *executors
+	}
+	if policy := bifrost.directKeyPolicy.Load(); policy != nil {
+		pipeline.directKeyRequiredPlugins = policy.RequiredPlugins
+	}
+	pipeline.pipelines = bifrost.pipelines.Load()
 	pipeline.logger = bifrost.logger
 	return pipeline
 }
@@ -2618,6 +3199,8 @@ func (bifrost *Bifrost) releaseChannelMessage(msg *ChannelMessage) {
 	msg.Response = nil
 	msg.ResponseStream = nil
 	msg.Err = nil
+	msg.plugins = nil
+	msg.executedPreHooks = 0
 	bifrost.channelMessagePool.Put(msg)
 }
 
@@ -2798,16 +3381,17 @@ func (bifrost *Bifrost) Shutdown() {
 	}
 	// Close all provider queues to signal workers to stop
 	bifrost.requestQueues.Range(func(key, value interface{}) bool {
-		close(value.(chan *ChannelMessage))
+		value.(*providerQueues).close()
 		return true
 	})
 
-	// Wait for all workers to exit
+	// Wait for all workers to exit, including the workers of previous provider configs
 	bifrost.waitGroups.Range(func(key, value interface{}) bool {
 		waitGroup := value.(*sync.WaitGroup)
 		waitGroup.Wait()
 		return true
 	})
+	bifrost.retiringWorkers.Wait()
 
 	// Cleanup MCP manager
 	if bifrost.mcpManager != nil {
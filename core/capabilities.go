@@ -0,0 +1,117 @@
+package bifrost
+
+import (
+	"fmt"
+	"net/http"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// capabilityRequirements are the capabilities used by a request
+type capabilityRequirements struct {
+	tools           bool
+	vision          bool
+	jsonMode        bool
+	maxOutputTokens int
+}
+
+// validateModelCapabilities rejects chat, responses and text completion requests using tools, image inputs, JSON
+// response formats or more output tokens than their model supports, according to the model capability registry.
+// Requests to models unknown to the registry are sent as they are. The error is not a Bifrost error, so fallbacks to
+// models supporting the request are still tried.
+func (bifrost *Bifrost) validateModelCapabilities(req *schemas.BifrostRequest) *schemas.BifrostError {
+	if bifrost.modelCapabilities == nil {
+		return nil
+	}
+	provider, model, _ := req.GetRequestFields()
+	capabilities := bifrost.modelCapabilities.GetModelCapabilities(provider, model)
+	if capabilities == nil {
+		return nil
+	}
+
+	var used capabilityRequirements
+	switch {
+	case req.ChatRequest != nil:
+		used = chatCapabilityRequirements(req.ChatRequest)
+	case req.ResponsesRequest != nil:
+		used = responsesCapabilityRequirements(req.ResponsesRequest)
+	case req.TextCompletionRequest != nil:
+		if params := req.TextCompletionRequest.Params; params != nil && params.MaxTokens != nil {
+			used.maxOutputTokens = *params.MaxTokens
+		}
+	default:
+		return nil
+	}
+
+	var unsupported string
+	switch {
+	case used.tools && !capabilities.SupportsTools:
+		unsupported = "tools"
+	case used.vision && !capabilities.SupportsVision:
+		unsupported = "image inputs"
+	case used.jsonMode && !capabilities.SupportsJSONMode:
+		unsupported = "JSON response formats"
+	case capabilities.MaxOutputTokens > 0 && used.maxOutputTokens > capabilities.MaxOutputTokens:
+		unsupported = fmt.Sprintf("%d output tokens, its maximum is %d", used.maxOutputTokens, capabilities.MaxOutputTokens)
+	default:
+		return nil
+	}
+	return &schemas.BifrostError{
+		IsBifrostError: false,
+		StatusCode:     schemas.Ptr(http.StatusBadRequest),
+		Type:           schemas.Ptr("unsupported_model_capability"),
+		Error: &schemas.ErrorField{
+			Message: fmt.Sprintf("model %s of provider %s does not support %s", model, provider, unsupported),
+		},
+	}
+}
+
+// chatCapabilityRequirements returns the capabilities used by a chat request
+func chatCapabilityRequirements(req *schemas.BifrostChatRequest) capabilityRequirements {
+	var used capabilityRequirements
+	for _, message := range req.Input {
+		if message.Content == nil {
+			continue
+		}
+		for _, block := range message.Content.ContentBlocks {
+			if block.ImageURLStruct != nil {
+				used.vision = true
+			}
+		}
+	}
+	if params := req.Params; params != nil {
+		used.tools = len(params.Tools) > 0
+		if format := schemas.ParseResponseFormat(params.ResponseFormat); format != nil {
+			used.jsonMode = format.Type == schemas.ResponseFormatTypeJSONObject || format.Type == schemas.ResponseFormatTypeJSONSchema
+		}
+		if params.MaxCompletionTokens != nil {
+			used.maxOutputTokens = *params.MaxCompletionTokens
+		}
+	}
+	return used
+}
+
+// responsesCapabilityRequirements returns the capabilities used by a responses request
+func responsesCapabilityRequirements(req *schemas.BifrostResponsesRequest) capabilityRequirements {
+	var used capabilityRequirements
+	for _, message := range req.Input {
+		if message.Content == nil {
+			continue
+		}
+		for _, block := range message.Content.ContentBlocks {
+			if block.Type == schemas.ResponsesInputMessageContentBlockTypeImage {
+				used.vision = true
+			}
+		}
+	}
+	if params := req.Params; params != nil {
+		used.tools = len(params.Tools) > 0
+		if params.Text != nil && params.Text.Format != nil {
+			used.jsonMode = params.Text.Format.Type == schemas.ResponseFormatTypeJSONObject || params.Text.Format.Type == schemas.ResponseFormatTypeJSONSchema
+		}
+		if params.MaxOutputTokens != nil {
+			used.maxOutputTokens = *params.MaxOutputTokens
+		}
+	}
+	return used
+}
@@ -0,0 +1,66 @@
+package bifrost
+
+import (
+	"strings"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// staticCapabilities is a model capability registry of fixed models
+type staticCapabilities map[string]*schemas.ModelCapabilities
+
+func (c staticCapabilities) GetModelCapabilities(provider schemas.ModelProvider, model string) *schemas.ModelCapabilities {
+	return c[model]
+}
+
+// TestValidateModelCapabilities tests that requests using capabilities their model lacks are rejected
+func TestValidateModelCapabilities(t *testing.T) {
+	bifrost := &Bifrost{modelCapabilities: staticCapabilities{
+		"text-model": {ContextWindow: 8192, MaxOutputTokens: 1024},
+		"full-model": {ContextWindow: 128000, MaxOutputTokens: 16384, SupportsVision: true, SupportsTools: true, SupportsJSONMode: true},
+	}}
+	tools := []schemas.ChatTool{{Type: schemas.ChatToolTypeFunction, Function: &schemas.ChatToolFunction{Name: "search"}}}
+	var jsonFormat interface{} = map[string]interface{}{"type": "json_object"}
+	image := schemas.ChatMessage{Role: schemas.ChatMessageRoleUser, Content: &schemas.ChatMessageContent{ContentBlocks: []schemas.ChatContentBlock{{
+		Type:           schemas.ChatContentBlockTypeImage,
+		ImageURLStruct: &schemas.ChatInputImage{URL: "https://example.com/cat.png"},
+	}}}}
+	chat := func(model string, input []schemas.ChatMessage, params *schemas.ChatParameters) *schemas.BifrostRequest {
+		return &schemas.BifrostRequest{
+			RequestType: schemas.ChatCompletionRequest,
+			ChatRequest: &schemas.BifrostChatRequest{Provider: schemas.OpenAI, Model: model, Input: input, Params: params},
+		}
+	}
+	hello := []schemas.ChatMessage{textMessage(schemas.ChatMessageRoleUser, "Hello")}
+
+	tests := map[string]struct {
+		req         *schemas.BifrostRequest
+		unsupported string
+	}{
+		"plain chat":             {chat("text-model", hello, nil), ""},
+		"unknown model":          {chat("other-model", hello, &schemas.ChatParameters{Tools: tools}), ""},
+		"tools":                  {chat("text-model", hello, &schemas.ChatParameters{Tools: tools}), "tools"},
+		"supported tools":        {chat("full-model", hello, &schemas.ChatParameters{Tools: tools}), ""},
+		"images":                 {chat("text-model", []schemas.ChatMessage{image}, nil), "image inputs"},
+		"json mode":              {chat("text-model", hello, &schemas.ChatParameters{ResponseFormat: &jsonFormat}), "JSON response formats"},
+		"max output tokens":      {chat("text-model", hello, &schemas.ChatParameters{MaxCompletionTokens: schemas.Ptr(4096)}), "4096 output tokens"},
+		"output tokens in range": {chat("text-model", hello, &schemas.ChatParameters{MaxCompletionTokens: schemas.Ptr(512)}), ""},
+		"responses tools": {&schemas.BifrostRequest{
+			RequestType:      schemas.ResponsesRequest,
+			ResponsesRequest: &schemas.BifrostResponsesRequest{Provider: schemas.OpenAI, Model: "text-model", Params: &schemas.ResponsesParameters{Tools: []schemas.ResponsesTool{{}}}},
+		}, "tools"},
+	}
+	for name, test := range tests {
+		err := bifrost.validateModelCapabilities(test.req)
+		if test.unsupported == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %s", name, err.Error.Message)
+			}
+			continue
+		}
+		if err == nil || *err.Type != "unsupported_model_capability" || !strings.Contains(err.Error.Message, test.unsupported) {
+			t.Errorf("%s: expected the request to be rejected for %s, got %+v", name, test.unsupported, err)
+		}
+	}
+}
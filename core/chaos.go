@@ -0,0 +1,136 @@
+package bifrost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+
+	providerUtils "github.com/maximhq/bifrost/core/providers/utils"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// errChaosDisconnect is the error of the connections lost by disconnect faults
+var errChaosDisconnect = errors.New("connection closed by chaos injection")
+
+// chaosFaults are the faults rolled for an attempt of a provider request
+type chaosFaults struct {
+	latency    time.Duration      // Sum of the latency faults rolled
+	err        *schemas.ChaosRule // First error fault rolled
+	disconnect *schemas.ChaosRule // First disconnect fault rolled
+}
+
+// rollChaosFaults rolls the percentage of every rule of the config matching the provider and model.
+// It returns nil when no fault is injected.
+func rollChaosFaults(config *schemas.ChaosConfig, provider schemas.ModelProvider, model string) *chaosFaults {
+	if config == nil || !config.Enabled {
+		return nil
+	}
+	var faults *chaosFaults
+	for i := range config.Rules {
+		rule := &config.Rules[i]
+		if !rule.Matches(provider, model) || rand.Float64()*100 >= rule.Percentage {
+			continue
+		}
+		if faults == nil {
+			faults = &chaosFaults{}
+		}
+		switch rule.Fault {
+		case schemas.ChaosFaultLatency:
+			faults.latency += time.Duration(rule.LatencyMs) * time.Millisecond
+		case schemas.ChaosFaultError:
+			if faults.err == nil {
+				faults.err = rule
+			}
+		case schemas.ChaosFaultDisconnect:
+			if faults.disconnect == nil {
+				faults.disconnect = rule
+			}
+		}
+	}
+	return faults
+}
+
+// beforeRequest applies the faults injected before the request is sent to the provider: it waits the injected
+// latency, then fails the request with the error fault, or with a connection error for non-stream requests
+// losing their connection.
+func (f *chaosFaults) beforeRequest(ctx context.Context, provider schemas.ModelProvider, isStream bool) *schemas.BifrostError {
+	if f.latency > 0 {
+		timer := time.NewTimer(f.latency)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return &schemas.BifrostError{
+				IsBifrostError: false,
+				Error: &schemas.ErrorField{
+					Type:    schemas.Ptr(schemas.RequestCancelled),
+					Message: schemas.ErrRequestCancelled,
+					Error:   ctx.Err(),
+				},
+			}
+		}
+	}
+	if f.err != nil {
+		statusCode := f.err.StatusCode
+		if statusCode == 0 {
+			statusCode = http.StatusInternalServerError
+		}
+		return providerUtils.NewProviderAPIError(
+			fmt.Sprintf("%d %s injected by chaos injection", statusCode, http.StatusText(statusCode)),
+			nil,
+			statusCode,
+			provider,
+			schemas.Ptr("chaos_fault"),
+			nil,
+		)
+	}
+	if f.disconnect != nil && !isStream {
+		return providerUtils.NewBifrostOperationError(schemas.ErrProviderDoRequest, errChaosDisconnect, provider)
+	}
+	return nil
+}
+
+// disconnectStream forwards the first chunks of the stream set by the disconnect fault, then cancels the
+// provider stream and ends the stream with a read error, as a connection lost mid-stream would.
+// Streams ending before are forwarded whole.
+func (f *chaosFaults) disconnectStream(
+	ctx context.Context,
+	cancelProvider context.CancelFunc,
+	stream chan *schemas.BifrostStream,
+	postHookRunner schemas.PostHookRunner,
+	requestType schemas.RequestType,
+	provider schemas.ModelProvider,
+	model string,
+	logger schemas.Logger,
+) chan *schemas.BifrostStream {
+	out := make(chan *schemas.BifrostStream, cap(stream))
+	go func() {
+		for forwarded := 0; forwarded < f.disconnect.AfterChunks; forwarded++ {
+			chunk, ok := <-stream
+			if !ok {
+				cancelProvider()
+				close(out)
+				return
+			}
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				schemas.ReleaseBifrostStream(chunk)
+			}
+		}
+
+		cancelProvider()
+		endCtx := context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+		providerUtils.ProcessAndSendError(endCtx, postHookRunner, errChaosDisconnect, out, requestType, provider, model, logger)
+		close(out)
+
+		// Drain the chunks the provider sent before noticing the cancellation
+		for chunk := range stream {
+			schemas.ReleaseBifrostStream(chunk)
+		}
+	}()
+	return out
+}
@@ -0,0 +1,90 @@
+package bifrost
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestRollChaosFaults tests that only the enabled rules matching the provider and model inject faults
+func TestRollChaosFaults(t *testing.T) {
+	config := &schemas.ChaosConfig{
+		Enabled: true,
+		Rules: []schemas.ChaosRule{
+			{Provider: schemas.OpenAI, Percentage: 100, Fault: schemas.ChaosFaultLatency, LatencyMs: 10},
+			{Provider: schemas.OpenAI, Model: "gpt-4o", Percentage: 100, Fault: schemas.ChaosFaultError, StatusCode: 429},
+			{Provider: schemas.Anthropic, Percentage: 100, Fault: schemas.ChaosFaultDisconnect},
+			{Percentage: 0, Fault: schemas.ChaosFaultDisconnect},
+		},
+	}
+
+	faults := rollChaosFaults(config, schemas.OpenAI, "gpt-4o")
+	if faults == nil || faults.latency == 0 || faults.err == nil || faults.disconnect != nil {
+		t.Fatalf("expected the latency and error faults of the openai rules, got %+v", faults)
+	}
+	faults = rollChaosFaults(config, schemas.OpenAI, "gpt-4o-mini")
+	if faults == nil || faults.err != nil {
+		t.Errorf("expected the error fault to target gpt-4o only, got %+v", faults)
+	}
+	if faults := rollChaosFaults(config, schemas.Gemini, "gemini-2.5-pro"); faults != nil {
+		t.Errorf("expected no fault for an untargeted provider, got %+v", faults)
+	}
+
+	config.Enabled = false
+	if faults := rollChaosFaults(config, schemas.OpenAI, "gpt-4o"); faults != nil {
+		t.Errorf("expected no fault while disabled, got %+v", faults)
+	}
+}
+
+// TestChaosBeforeRequest tests the errors of the faults injected before the request is sent
+func TestChaosBeforeRequest(t *testing.T) {
+	faults := &chaosFaults{err: &schemas.ChaosRule{Fault: schemas.ChaosFaultError, StatusCode: 429}}
+	bifrostErr := faults.beforeRequest(context.Background(), schemas.OpenAI, false)
+	if bifrostErr == nil || bifrostErr.StatusCode == nil || *bifrostErr.StatusCode != 429 {
+		t.Fatalf("expected a 429 error, got %+v", bifrostErr)
+	}
+
+	faults = &chaosFaults{disconnect: &schemas.ChaosRule{Fault: schemas.ChaosFaultDisconnect}}
+	bifrostErr = faults.beforeRequest(context.Background(), schemas.OpenAI, false)
+	if bifrostErr == nil || bifrostErr.Error.Message != schemas.ErrProviderDoRequest {
+		t.Fatalf("expected a connection error for non-stream requests, got %+v", bifrostErr)
+	}
+	if bifrostErr := faults.beforeRequest(context.Background(), schemas.OpenAI, true); bifrostErr != nil {
+		t.Errorf("expected stream requests to disconnect mid-stream, got %+v", bifrostErr)
+	}
+}
+
+// TestChaosDisconnectStream tests that streams end with a read error after the chunks of the disconnect fault
+func TestChaosDisconnectStream(t *testing.T) {
+	stream := make(chan *schemas.BifrostStream, 5)
+	for range 5 {
+		stream <- &schemas.BifrostStream{BifrostChatResponse: &schemas.BifrostChatResponse{}}
+	}
+	close(stream)
+
+	var cancelled atomic.Bool
+	passThrough := func(ctx *context.Context, result *schemas.BifrostResponse, err *schemas.BifrostError) (*schemas.BifrostResponse, *schemas.BifrostError) {
+		return result, err
+	}
+	faults := &chaosFaults{disconnect: &schemas.ChaosRule{Fault: schemas.ChaosFaultDisconnect, AfterChunks: 2}}
+	out := faults.disconnectStream(context.Background(), func() { cancelled.Store(true) }, stream, passThrough,
+		schemas.ChatCompletionStreamRequest, schemas.OpenAI, "gpt-4o", NewDefaultLogger(schemas.LogLevelError))
+
+	var chunks []*schemas.BifrostStream
+	for chunk := range out {
+		chunks = append(chunks, chunk)
+	}
+	if len(chunks) != 3 {
+		t.Fatalf("expected two chunks and the error, got %d chunks", len(chunks))
+	}
+	last := chunks[2].BifrostError
+	if last == nil || !errors.Is(last.Error.Error, errChaosDisconnect) || !isMidStreamFailure(last) {
+		t.Errorf("expected the stream to end with a mid-stream failure, got %+v", last)
+	}
+	if !cancelled.Load() {
+		t.Error("expected the provider stream to be cancelled")
+	}
+}
@@ -0,0 +1,198 @@
+package bifrost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+const (
+	defaultPromptCompressionKeepRecent    = 4
+	defaultPromptCompressionSummaryTokens = 512
+)
+
+// promptCompressionSummaryContextKey marks the requests writing summaries, they are never compressed themselves
+const promptCompressionSummaryContextKey schemas.BifrostContextKey = "bifrost-prompt-compression-summary"
+
+// promptCompressionSummaryInstructions is the system prompt of the requests summarizing the oldest turns of a conversation
+const promptCompressionSummaryInstructions = "You compress conversations between a user and an assistant. Summarize the conversation below in a few " +
+	"short paragraphs, keeping the facts, decisions, open questions, tool results and user preferences needed to continue it. " +
+	"Answer with the summary only."
+
+// promptCompressionBudgetForModel returns the token budget of the model, by "provider/model", then "model", then "*"
+func promptCompressionBudgetForModel(config *schemas.PromptCompressionConfig, provider schemas.ModelProvider, model string) (schemas.PromptCompressionBudget, bool) {
+	for _, alias := range []string{string(provider) + "/" + model, model, "*"} {
+		if budget, ok := config.Budgets[alias]; ok {
+			return budget, true
+		}
+	}
+	return schemas.PromptCompressionBudget{}, false
+}
+
+// compressPrompt compresses the oldest turns of chat requests exceeding the token budget of their model, see compressChatRequest
+func (bifrost *Bifrost) compressPrompt(ctx context.Context, req *schemas.BifrostRequest) (*schemas.BifrostRequest, int) {
+	config := bifrost.promptCompression.Load()
+	if config == nil || req.ChatRequest == nil {
+		return req, 0
+	}
+	if summary, _ := ctx.Value(promptCompressionSummaryContextKey).(bool); summary {
+		return req, 0
+	}
+	return bifrost.compressChatRequest(ctx, req, config, bifrost.ChatCompletionRequest)
+}
+
+// compressChatRequest drops the oldest turns of the conversation until its input tokens fit the token budget of the
+// model, keeping the system and developer messages and the latest messages. With the summarize strategy the dropped
+// turns are replaced by a system message summarizing them, written by the summary model through complete, and they
+// are only dropped when the summary fails. Conversations that still exceed the budget are sent with the turns that could
+// be compressed, the context window check decides whether they fit. It returns a copy of the request when messages were
+// compressed, with their number.
+func (bifrost *Bifrost) compressChatRequest(ctx context.Context, req *schemas.BifrostRequest, config *schemas.PromptCompressionConfig, complete chatCompletionFunc) (*schemas.BifrostRequest, int) {
+	provider, model := req.ChatRequest.Provider, req.ChatRequest.Model
+	budget, ok := promptCompressionBudgetForModel(config, provider, model)
+	if !ok {
+		return req, 0
+	}
+	tokenizer := tokenizerForModel(provider, model)
+
+	messages := req.ChatRequest.Input
+	counts := make([]int, len(messages))
+	tokens := tokensReplyPriming
+	for i := range messages {
+		counts[i] = countChatMessageTokens(&messages[i], tokenizer)
+		tokens += counts[i]
+	}
+	if params := req.ChatRequest.Params; params != nil && len(params.Tools) > 0 {
+		tokens += countToolTokens(params.Tools, tokenizer)
+	}
+	if tokens <= budget.TokenBudget {
+		return req, 0
+	}
+
+	keepRecent := budget.KeepRecentMessages
+	if keepRecent <= 0 {
+		keepRecent = defaultPromptCompressionKeepRecent
+	}
+	var input []schemas.ChatMessage
+	compressed := 0
+	if budget.Strategy == schemas.PromptCompressionSummarize {
+		summaryTokens := budget.SummaryMaxTokens
+		if summaryTokens <= 0 {
+			summaryTokens = defaultPromptCompressionSummaryTokens
+		}
+		// Room is made for the summary replacing the dropped turns
+		dropped, _ := selectOldestChatMessages(messages, counts, tokens-budget.TokenBudget+summaryTokens+tokensPerMessage, keepRecent)
+		if compressed = countDropped(dropped); compressed > 0 {
+			summary, err := summarizeChatMessages(ctx, budget, provider, model, summaryTokens, messages, dropped, complete)
+			if err != nil {
+				bifrost.logger.Warn(fmt.Sprintf("failed to summarize the oldest turns of a conversation with %s, truncating them instead: %v", model, err))
+			} else {
+				// The summary takes the place of the first dropped message
+				input = make([]schemas.ChatMessage, 0, len(messages)-compressed+1)
+				summarized := false
+				for i := range messages {
+					if !dropped[i] {
+						input = append(input, messages[i])
+					} else if !summarized {
+						input = append(input, schemas.ChatMessage{
+							Role:    schemas.ChatMessageRoleSystem,
+							Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr("Summary of the earlier conversation:\n" + summary)},
+						})
+						summarized = true
+					}
+				}
+			}
+		}
+	}
+	if input == nil {
+		var dropped []bool
+		dropped, _ = selectOldestChatMessages(messages, counts, tokens-budget.TokenBudget, keepRecent)
+		if compressed = countDropped(dropped); compressed == 0 {
+			return req, 0
+		}
+		input = keptChatMessages(messages, dropped)
+	}
+
+	chatReq := *req.ChatRequest
+	chatReq.Input = input
+	compressedReq := *req
+	compressedReq.ChatRequest = &chatReq
+	return &compressedReq, compressed
+}
+
+// countDropped returns the number of messages marked as dropped
+func countDropped(dropped []bool) int {
+	count := 0
+	for _, drop := range dropped {
+		if drop {
+			count++
+		}
+	}
+	return count
+}
+
+// summarizeChatMessages asks the summary model of the budget, or the model of the request, to summarize the dropped messages
+func summarizeChatMessages(ctx context.Context, budget schemas.PromptCompressionBudget, provider schemas.ModelProvider, model string, maxTokens int, messages []schemas.ChatMessage, dropped []bool, complete chatCompletionFunc) (string, error) {
+	if budget.SummaryModel != "" {
+		summaryProvider, summaryModel, _ := strings.Cut(budget.SummaryModel, "/")
+		provider, model = schemas.ModelProvider(summaryProvider), summaryModel
+	}
+
+	var transcript strings.Builder
+	for i := range messages {
+		if !dropped[i] {
+			continue
+		}
+		message := &messages[i]
+		text := chatMessageText(message)
+		if message.ChatAssistantMessage != nil {
+			for _, toolCall := range message.ChatAssistantMessage.ToolCalls {
+				if toolCall.Function.Name != nil {
+					text += fmt.Sprintf("\n[called %s with %s]", *toolCall.Function.Name, toolCall.Function.Arguments)
+				}
+			}
+		}
+		fmt.Fprintf(&transcript, "%s: %s\n\n", message.Role, strings.TrimSpace(text))
+	}
+
+	resp, bifrostErr := complete(context.WithValue(ctx, promptCompressionSummaryContextKey, true), &schemas.BifrostChatRequest{
+		Provider: provider,
+		Model:    model,
+		Input: []schemas.ChatMessage{
+			{Role: schemas.ChatMessageRoleSystem, Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr(promptCompressionSummaryInstructions)}},
+			{Role: schemas.ChatMessageRoleUser, Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr(transcript.String())}},
+		},
+		Params: &schemas.ChatParameters{MaxCompletionTokens: schemas.Ptr(maxTokens)},
+	})
+	if bifrostErr != nil {
+		return "", errors.New(GetErrorMessage(bifrostErr))
+	}
+	if len(resp.Choices) == 0 || resp.Choices[0].ChatNonStreamResponseChoice == nil || resp.Choices[0].Message == nil {
+		return "", fmt.Errorf("summary response has no message")
+	}
+	summary := strings.TrimSpace(chatMessageText(resp.Choices[0].Message))
+	if summary == "" {
+		return "", fmt.Errorf("summary is empty")
+	}
+	return summary, nil
+}
+
+// chatMessageText returns the text of a chat message, joining its text blocks
+func chatMessageText(message *schemas.ChatMessage) string {
+	if message.Content == nil {
+		return ""
+	}
+	if message.Content.ContentStr != nil {
+		return *message.Content.ContentStr
+	}
+	texts := make([]string, 0, len(message.Content.ContentBlocks))
+	for _, block := range message.Content.ContentBlocks {
+		if block.Text != nil {
+			texts = append(texts, *block.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,88 @@
+package bifrost
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestCompressChatRequest tests that conversations exceeding the token budget of their model lose or summarize their oldest turns
+func TestCompressChatRequest(t *testing.T) {
+	bifrost := &Bifrost{logger: NewDefaultLogger(schemas.LogLevelError)}
+	filler := strings.Repeat("lorem ipsum dolor sit amet ", 20) // about 100 tokens
+	input := []schemas.ChatMessage{
+		textMessage(schemas.ChatMessageRoleSystem, "Be brief."),
+		textMessage(schemas.ChatMessageRoleUser, filler),
+		toolCallMessage("call_1", "search", `{}`),
+		{Role: schemas.ChatMessageRoleTool, Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr(filler)}, ChatToolMessage: &schemas.ChatToolMessage{ToolCallID: schemas.Ptr("call_1")}},
+		textMessage(schemas.ChatMessageRoleAssistant, filler),
+		textMessage(schemas.ChatMessageRoleUser, "And now?"),
+	}
+	req := &schemas.BifrostRequest{
+		RequestType: schemas.ChatCompletionRequest,
+		ChatRequest: &schemas.BifrostChatRequest{Provider: schemas.OpenAI, Model: "gpt-4o", Input: input},
+	}
+	var summaryReqs []*schemas.BifrostChatRequest
+	complete := func(ctx context.Context, req *schemas.BifrostChatRequest) (*schemas.BifrostChatResponse, *schemas.BifrostError) {
+		if ctx.Value(promptCompressionSummaryContextKey) != true {
+			t.Error("summary requests must not be compressed")
+		}
+		summaryReqs = append(summaryReqs, req)
+		return &schemas.BifrostChatResponse{Choices: []schemas.BifrostResponseChoice{{
+			ChatNonStreamResponseChoice: &schemas.ChatNonStreamResponseChoice{
+				Message: &schemas.ChatMessage{Role: schemas.ChatMessageRoleAssistant, Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr("The user searched.")}},
+			},
+		}}}, nil
+	}
+	roles := func(messages []schemas.ChatMessage) []schemas.ChatMessageRole {
+		roles := []schemas.ChatMessageRole{}
+		for _, message := range messages {
+			roles = append(roles, message.Role)
+		}
+		return roles
+	}
+
+	// Conversations within the budget or of models without a budget are sent as they are
+	config := &schemas.PromptCompressionConfig{Budgets: map[string]schemas.PromptCompressionBudget{
+		"gpt-4o": {TokenBudget: 1000, Strategy: schemas.PromptCompressionTruncate},
+	}}
+	if compressedReq, compressed := bifrost.compressChatRequest(context.Background(), req, config, complete); compressed != 0 || compressedReq != req {
+		t.Fatal("expected the conversation to be sent as it is")
+	}
+
+	// Truncation drops the oldest turns outside of the kept messages, with the tool results of the tool calls
+	config.Budgets = map[string]schemas.PromptCompressionBudget{
+		"openai/gpt-4o": {TokenBudget: 150, Strategy: schemas.PromptCompressionTruncate, KeepRecentMessages: 2},
+	}
+	compressedReq, compressed := bifrost.compressChatRequest(context.Background(), req, config, complete)
+	if got := roles(compressedReq.ChatRequest.Input); compressed != 3 || len(got) != 3 || got[0] != schemas.ChatMessageRoleSystem || got[1] != schemas.ChatMessageRoleAssistant {
+		t.Errorf("expected 3 messages to be dropped, got %d and %v", compressed, got)
+	}
+	if len(req.ChatRequest.Input) != len(input) {
+		t.Error("the original request must not be modified")
+	}
+
+	// Summaries take the place of the dropped turns and are written by the summary model
+	config.Budgets = map[string]schemas.PromptCompressionBudget{
+		"*": {TokenBudget: 150, Strategy: schemas.PromptCompressionSummarize, KeepRecentMessages: 2, SummaryModel: "openai/gpt-4o-mini", SummaryMaxTokens: 20},
+	}
+	compressedReq, compressed = bifrost.compressChatRequest(context.Background(), req, config, complete)
+	got := compressedReq.ChatRequest.Input
+	if compressed != 3 || len(got) != 4 || got[1].Role != schemas.ChatMessageRoleSystem || !strings.HasSuffix(*got[1].Content.ContentStr, "The user searched.") {
+		t.Fatalf("expected the dropped turns to be summarized, got %d and %v", compressed, roles(got))
+	}
+	if len(summaryReqs) != 1 || summaryReqs[0].Model != "gpt-4o-mini" || !strings.Contains(*summaryReqs[0].Input[1].Content.ContentStr, "[called search with {}]") {
+		t.Errorf("expected one summary request to the summary model with the transcript, got %+v", summaryReqs)
+	}
+
+	// Failed summaries fall back to truncation
+	failing := func(ctx context.Context, req *schemas.BifrostChatRequest) (*schemas.BifrostChatResponse, *schemas.BifrostError) {
+		return nil, &schemas.BifrostError{Error: &schemas.ErrorField{Message: "rate limited"}}
+	}
+	compressedReq, compressed = bifrost.compressChatRequest(context.Background(), req, config, failing)
+	if got := roles(compressedReq.ChatRequest.Input); compressed != 3 || len(got) != 3 {
+		t.Errorf("expected the oldest turns to be truncated, got %d and %v", compressed, got)
+	}
+}
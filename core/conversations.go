@@ -0,0 +1,145 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	providerUtils "github.com/maximhq/bifrost/core/providers/utils"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// beginConversation returns the conversation continued by a chat request and the request to send for it. When the
+// context carries a conversation ID, the returned copy of the request is prefixed with the history of the
+// conversation, truncated to the limits of the config; unknown conversations are started with the request.
+// Requests without a conversation ID are returned as they are, with a nil conversation.
+func (bifrost *Bifrost) beginConversation(ctx context.Context, req *schemas.BifrostChatRequest, requestType schemas.RequestType) (*schemas.Conversation, *schemas.BifrostChatRequest, *schemas.BifrostError) {
+	config := bifrost.conversations.Load()
+	if ctx == nil || config == nil || bifrost.conversationStore == nil {
+		return nil, req, nil
+	}
+	id, ok := ctx.Value(schemas.BifrostContextKeyConversationID).(string)
+	if !ok || id == "" {
+		return nil, req, nil
+	}
+
+	owner := idempotencyScope(ctx)
+	conversation, err := bifrost.conversationStore.GetConversation(ctx, id)
+	if err != nil {
+		return nil, nil, newConversationError(req, requestType, http.StatusInternalServerError, fmt.Sprintf("failed to load conversation %s: %v", id, err))
+	}
+	if conversation == nil {
+		now := time.Now()
+		conversation = &schemas.Conversation{ID: id, Owner: owner, CreatedAt: now, UpdatedAt: now}
+		if req.Params != nil && req.Params.User != nil {
+			conversation.User = *req.Params.User
+		}
+		return conversation, req, nil
+	}
+	if conversation.Owner != owner {
+		return nil, nil, newConversationError(req, requestType, http.StatusConflict, fmt.Sprintf("conversation %s belongs to another caller", id))
+	}
+
+	history := truncateConversationHistory(conversation.Messages, config, req.Provider, req.Model)
+	expanded := *req
+	expanded.Input = make([]schemas.ChatMessage, 0, len(history)+len(req.Input))
+	expanded.Input = append(append(expanded.Input, history...), req.Input...)
+	// The raw body only carries the new messages
+	expanded.RawRequestBody = nil
+	return conversation, &expanded, nil
+}
+
+// truncateConversationHistory drops the oldest messages of the history above the message and token limits of the
+// config, keeping the system and developer messages and the tool calls answered by the remaining tool results
+func truncateConversationHistory(history []schemas.ChatMessage, config *schemas.ConversationConfig, provider schemas.ModelProvider, model string) []schemas.ChatMessage {
+	if config.MaxMessages > 0 && len(history) > config.MaxMessages {
+		counts := make([]int, len(history))
+		for i := range counts {
+			counts[i] = 1
+		}
+		dropped, _ := selectOldestChatMessages(history, counts, len(history)-config.MaxMessages, config.MaxMessages)
+		history = keptChatMessages(history, dropped)
+	}
+	if config.MaxHistoryTokens > 0 {
+		tokenizer := tokenizerForModel(provider, model)
+		counts := make([]int, len(history))
+		total := 0
+		for i := range history {
+			counts[i] = countChatMessageTokens(&history[i], tokenizer)
+			total += counts[i]
+		}
+		if total > config.MaxHistoryTokens {
+			dropped, _ := selectOldestChatMessages(history, counts, total-config.MaxHistoryTokens, 1)
+			history = keptChatMessages(history, dropped)
+		}
+	}
+	return history
+}
+
+// saveConversation appends the new messages of the request and the first choice of the response to the
+// conversation and stores it. The response is already generated, so a failure to store it is only logged.
+func (bifrost *Bifrost) saveConversation(ctx context.Context, conversation *schemas.Conversation, req *schemas.BifrostChatRequest, response *schemas.BifrostChatResponse) {
+	if response == nil || len(response.Choices) == 0 || response.Choices[0].ChatNonStreamResponseChoice == nil ||
+		response.Choices[0].ChatNonStreamResponseChoice.Message == nil {
+		return
+	}
+	conversation.Messages = append(conversation.Messages, req.Input...)
+	conversation.Messages = append(conversation.Messages, *response.Choices[0].ChatNonStreamResponseChoice.Message)
+	conversation.UpdatedAt = time.Now()
+	// The conversation is saved after the response was sent to a caller that may be gone
+	if err := bifrost.conversationStore.SaveConversation(context.WithoutCancel(ctx), conversation); err != nil {
+		bifrost.logger.Warn(fmt.Sprintf("failed to save conversation %s: %v", conversation.ID, err))
+	}
+}
+
+// withConversation saves the conversation once the chat stream completed, with the message aggregated from its
+// chunks. Streams ending with an error or before a finish reason are not saved.
+func (bifrost *Bifrost) withConversation(ctx context.Context, conversation *schemas.Conversation, req *schemas.BifrostChatRequest, stream chan *schemas.BifrostStream) chan *schemas.BifrostStream {
+	out := make(chan *schemas.BifrostStream, providerUtils.GetStreamBufferSize(ctx))
+	go func() {
+		defer close(out)
+		aggregator := newStreamAggregator(schemas.ChatCompletionRequest)
+		failed := false
+		for chunk := range stream {
+			if chunk != nil {
+				if chunk.BifrostError != nil {
+					failed = true
+				} else {
+					aggregator.add(chunk)
+				}
+			}
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				// The client is gone, drain the upstream stream so that its provider is not blocked
+				for range stream {
+				}
+				return
+			}
+		}
+		if failed || len(aggregator.choices) == 0 || aggregator.choices[0].finishReason == nil {
+			return
+		}
+		bifrost.saveConversation(ctx, conversation, req, aggregator.response(0).ChatResponse)
+	}()
+	return out
+}
+
+// newConversationError returns the error of a chat request whose conversation cannot be continued
+func newConversationError(req *schemas.BifrostChatRequest, requestType schemas.RequestType, statusCode int, message string) *schemas.BifrostError {
+	return &schemas.BifrostError{
+		IsBifrostError: true,
+		StatusCode:     schemas.Ptr(statusCode),
+		Type:           schemas.Ptr("conversation_error"),
+		Error: &schemas.ErrorField{
+			Message: message,
+		},
+		AllowFallbacks: schemas.Ptr(false),
+		ExtraFields: schemas.BifrostErrorExtraFields{
+			RequestType:    requestType,
+			Provider:       req.Provider,
+			ModelRequested: req.Model,
+		},
+	}
+}
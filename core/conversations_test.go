@@ -0,0 +1,118 @@
+package bifrost
+
+import (
+	"context"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// memoryConversationStore keeps conversations in a map
+type memoryConversationStore map[string]schemas.Conversation
+
+func (s memoryConversationStore) GetConversation(ctx context.Context, id string) (*schemas.Conversation, error) {
+	conversation, ok := s[id]
+	if !ok {
+		return nil, nil
+	}
+	return &conversation, nil
+}
+
+func (s memoryConversationStore) SaveConversation(ctx context.Context, conversation *schemas.Conversation) error {
+	s[conversation.ID] = *conversation
+	return nil
+}
+
+func (s memoryConversationStore) ListConversations(ctx context.Context, filter schemas.ConversationFilter) ([]schemas.Conversation, error) {
+	return nil, nil
+}
+
+func (s memoryConversationStore) DeleteConversation(ctx context.Context, id string) (bool, error) {
+	_, ok := s[id]
+	delete(s, id)
+	return ok, nil
+}
+
+func (s memoryConversationStore) DeleteConversations(ctx context.Context, filter schemas.ConversationFilter) (int, error) {
+	return 0, nil
+}
+
+func conversationTestMessage(role schemas.ChatMessageRole, text string) schemas.ChatMessage {
+	return schemas.ChatMessage{Role: role, Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr(text)}}
+}
+
+func conversationTestResponse(text string) *schemas.BifrostChatResponse {
+	message := conversationTestMessage(schemas.ChatMessageRoleAssistant, text)
+	return &schemas.BifrostChatResponse{Choices: []schemas.BifrostResponseChoice{{
+		ChatNonStreamResponseChoice: &schemas.ChatNonStreamResponseChoice{Message: &message},
+	}}}
+}
+
+// TestConversations_HistoryIsStoredAndPrepended tests that a conversation is started by its first request and that
+// the next requests are sent with its history, for its owner only
+func TestConversations_HistoryIsStoredAndPrepended(t *testing.T) {
+	store := memoryConversationStore{}
+	bifrost := &Bifrost{conversationStore: store, logger: NewDefaultLogger(schemas.LogLevelError)}
+	bifrost.conversations.Store(&schemas.ConversationConfig{})
+	ctx := context.WithValue(context.Background(), schemas.BifrostContextKeyConversationID, "support-42")
+	ctx = context.WithValue(ctx, schemas.BifrostContextKeyVirtualKey, "sk-bf-a")
+
+	first := &schemas.BifrostChatRequest{
+		Provider: schemas.OpenAI,
+		Model:    "gpt-4o",
+		Input:    []schemas.ChatMessage{conversationTestMessage(schemas.ChatMessageRoleUser, "hi")},
+		Params:   &schemas.ChatParameters{User: schemas.Ptr("user-7")},
+	}
+	conversation, sent, err := bifrost.beginConversation(ctx, first, schemas.ChatCompletionRequest)
+	if err != nil || conversation == nil || sent != first {
+		t.Fatalf("expected a new conversation sent as it is, got %+v %v", conversation, err)
+	}
+	bifrost.saveConversation(ctx, conversation, first, conversationTestResponse("hello"))
+	if stored := store["support-42"]; len(stored.Messages) != 2 || stored.Owner != "vk:sk-bf-a" || stored.User != "user-7" {
+		t.Fatalf("expected the request and response to be stored for the caller and user, got %+v", stored)
+	}
+
+	second := &schemas.BifrostChatRequest{Provider: schemas.OpenAI, Model: "gpt-4o", Input: []schemas.ChatMessage{conversationTestMessage(schemas.ChatMessageRoleUser, "again")}}
+	conversation, sent, err = bifrost.beginConversation(ctx, second, schemas.ChatCompletionRequest)
+	if err != nil || len(sent.Input) != 3 || len(second.Input) != 1 {
+		t.Fatalf("expected a copy of the request with the history, got %+v %v", sent, err)
+	}
+	bifrost.saveConversation(ctx, conversation, second, conversationTestResponse("hello again"))
+	if stored := store["support-42"]; len(stored.Messages) != 4 || *stored.Messages[3].Content.ContentStr != "hello again" {
+		t.Fatalf("expected only the new messages to be appended, got %+v", stored.Messages)
+	}
+
+	other := context.WithValue(ctx, schemas.BifrostContextKeyVirtualKey, "sk-bf-b")
+	if _, _, err := bifrost.beginConversation(other, second, schemas.ChatCompletionRequest); err == nil || *err.StatusCode != 409 {
+		t.Errorf("expected the conversation of another caller to be rejected, got %v", err)
+	}
+}
+
+// TestTruncateConversationHistory tests that the oldest messages are dropped above the limits, keeping system
+// messages and tool calls with their results
+func TestTruncateConversationHistory(t *testing.T) {
+	history := []schemas.ChatMessage{
+		conversationTestMessage(schemas.ChatMessageRoleSystem, "be brief"),
+		conversationTestMessage(schemas.ChatMessageRoleUser, "one"),
+		conversationTestMessage(schemas.ChatMessageRoleAssistant, "two"),
+		{Role: schemas.ChatMessageRoleAssistant, ChatAssistantMessage: &schemas.ChatAssistantMessage{ToolCalls: []schemas.ChatAssistantMessageToolCall{{Function: schemas.ChatAssistantMessageToolCallFunction{Name: schemas.Ptr("lookup")}}}}},
+		conversationTestMessage(schemas.ChatMessageRoleTool, "result"),
+		conversationTestMessage(schemas.ChatMessageRoleAssistant, "three"),
+	}
+
+	kept := truncateConversationHistory(history, &schemas.ConversationConfig{MaxMessages: 1}, schemas.OpenAI, "gpt-4o")
+	if len(kept) != 2 || kept[0].Role != schemas.ChatMessageRoleSystem || *kept[1].Content.ContentStr != "three" {
+		t.Errorf("expected the system message and the last message, got %+v", kept)
+	}
+	kept = truncateConversationHistory(history, &schemas.ConversationConfig{MaxMessages: 2}, schemas.OpenAI, "gpt-4o")
+	if len(kept) != 4 || kept[1].ChatAssistantMessage == nil {
+		t.Errorf("expected the tool result to be kept with its tool call, got %+v", kept)
+	}
+	kept = truncateConversationHistory(history, &schemas.ConversationConfig{MaxHistoryTokens: 1}, schemas.OpenAI, "gpt-4o")
+	if len(kept) != 2 || kept[0].Role != schemas.ChatMessageRoleSystem {
+		t.Errorf("expected the history to be cut down to the system message and the last message, got %+v", kept)
+	}
+	if kept := truncateConversationHistory(history, &schemas.ConversationConfig{}, schemas.OpenAI, "gpt-4o"); len(kept) != len(history) {
+		t.Errorf("expected the whole history without limits, got %d messages", len(kept))
+	}
+}
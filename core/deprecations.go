@@ -0,0 +1,60 @@
+package bifrost
+
+import (
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// modelDeprecationSet is the set of deprecated models, keyed by "model" and "provider/model"
+type modelDeprecationSet map[string]*schemas.ModelDeprecation
+
+// UpdateModelDeprecations replaces the model deprecations, an empty list sends requests as they are.
+// Returns an error and keeps the current deprecations if the deprecations are invalid.
+func (bifrost *Bifrost) UpdateModelDeprecations(deprecations []schemas.ModelDeprecation) error {
+	if len(deprecations) == 0 {
+		bifrost.deprecations.Store(nil)
+		return nil
+	}
+	if err := schemas.ValidateModelDeprecations(deprecations); err != nil {
+		return err
+	}
+	set := make(modelDeprecationSet, len(deprecations))
+	for i := range deprecations {
+		deprecation := deprecations[i]
+		set[deprecation.Model] = &deprecation
+	}
+	bifrost.deprecations.Store(&set)
+	return nil
+}
+
+// GetModelDeprecation returns the deprecation of the model of the provider, nil when the model is not deprecated.
+// Deprecations of the model on the provider take precedence over deprecations of the model on every provider.
+func (bifrost *Bifrost) GetModelDeprecation(provider schemas.ModelProvider, model string) *schemas.ModelDeprecation {
+	set := bifrost.deprecations.Load()
+	if set == nil {
+		return nil
+	}
+	if deprecation, ok := (*set)[string(provider)+"/"+model]; ok {
+		return deprecation
+	}
+	return (*set)[model]
+}
+
+// remapDeprecatedModel sends requests to a model past its sunset date to its replacement. The request is copied before
+// its model is changed, so that fallbacks start from the request of the caller, and the "provider/model" it was sent to
+// is returned. Returns the request itself when its model is not sunset.
+func (bifrost *Bifrost) remapDeprecatedModel(req *schemas.BifrostRequest) (*schemas.BifrostRequest, string) {
+	provider, model, _ := req.GetRequestFields()
+	deprecation := bifrost.GetModelDeprecation(provider, model)
+	if deprecation == nil || time.Now().Before(deprecation.Sunset()) {
+		return req, ""
+	}
+	remapped := copyRequest(req)
+	newProvider, newModel := schemas.ParseModelString(deprecation.Replacement, "")
+	if newProvider != "" {
+		remapped.SetProvider(newProvider)
+	}
+	remapped.SetModel(newModel)
+	return &remapped, string(provider) + "/" + model
+}
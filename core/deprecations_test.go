@@ -0,0 +1,65 @@
+package bifrost
+
+import (
+	"testing"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestRemapDeprecatedModel tests that requests to deprecated models are remapped to their replacement from the sunset date on
+func TestRemapDeprecatedModel(t *testing.T) {
+	bifrost := &Bifrost{}
+	past := time.Now().AddDate(0, 0, -1).Format(schemas.ModelDeprecationDateLayout)
+	future := time.Now().AddDate(0, 1, 0).Format(schemas.ModelDeprecationDateLayout)
+	if err := bifrost.UpdateModelDeprecations([]schemas.ModelDeprecation{
+		{Model: "gpt-4-0613", Replacement: "gpt-4o", SunsetDate: past},
+		{Model: "openai/gpt-3.5-turbo", Replacement: "anthropic/claude-3-5-haiku", SunsetDate: past},
+		{Model: "gpt-4o-mini", Replacement: "gpt-4.1-mini", SunsetDate: future},
+	}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	chat := func(provider schemas.ModelProvider, model string) *schemas.BifrostRequest {
+		return &schemas.BifrostRequest{
+			RequestType: schemas.ChatCompletionRequest,
+			ChatRequest: &schemas.BifrostChatRequest{Provider: provider, Model: model},
+		}
+	}
+
+	tests := map[string]struct {
+		req          *schemas.BifrostRequest
+		provider     schemas.ModelProvider
+		model        string
+		remappedFrom string
+	}{
+		"sunset on every provider": {chat(schemas.Azure, "gpt-4-0613"), schemas.Azure, "gpt-4o", "azure/gpt-4-0613"},
+		"sunset on a provider":     {chat(schemas.OpenAI, "gpt-3.5-turbo"), schemas.Anthropic, "claude-3-5-haiku", "openai/gpt-3.5-turbo"},
+		"other provider":           {chat(schemas.Azure, "gpt-3.5-turbo"), schemas.Azure, "gpt-3.5-turbo", ""},
+		"grace period":             {chat(schemas.OpenAI, "gpt-4o-mini"), schemas.OpenAI, "gpt-4o-mini", ""},
+		"not deprecated":           {chat(schemas.OpenAI, "gpt-4o"), schemas.OpenAI, "gpt-4o", ""},
+	}
+	for name, test := range tests {
+		remapped, remappedFrom := bifrost.remapDeprecatedModel(test.req)
+		provider, model, _ := remapped.GetRequestFields()
+		if provider != test.provider || model != test.model || remappedFrom != test.remappedFrom {
+			t.Errorf("%s: expected %s/%s remapped from %q, got %s/%s remapped from %q", name, test.provider, test.model, test.remappedFrom, provider, model, remappedFrom)
+		}
+		if remappedFrom != "" && remapped == test.req {
+			t.Errorf("%s: the original request must not be modified", name)
+		}
+	}
+	if deprecation := bifrost.GetModelDeprecation(schemas.OpenAI, "gpt-4o-mini"); deprecation == nil || deprecation.Replacement != "gpt-4.1-mini" {
+		t.Errorf("expected the deprecation of gpt-4o-mini, got %+v", deprecation)
+	}
+
+	// Chained and duplicated deprecations are rejected
+	if err := bifrost.UpdateModelDeprecations([]schemas.ModelDeprecation{
+		{Model: "a", Replacement: "b", SunsetDate: past},
+		{Model: "b", Replacement: "c", SunsetDate: past},
+	}); err == nil {
+		t.Error("expected chained deprecations to be rejected")
+	}
+	if err := bifrost.UpdateModelDeprecations([]schemas.ModelDeprecation{{Model: "a", Replacement: "b", SunsetDate: "June 1"}}); err == nil {
+		t.Error("expected an invalid sunset date to be rejected")
+	}
+}
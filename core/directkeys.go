@@ -0,0 +1,57 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// hasDirectKey reports whether the request carries a caller-supplied provider key
+func hasDirectKey(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	_, ok := ctx.Value(schemas.BifrostContextKeyDirectKey).(schemas.Key)
+	return ok
+}
+
+// newDirectKeyPolicyError creates the error returned when a direct key request violates the direct key policy
+func newDirectKeyPolicyError(message string) *schemas.BifrostError {
+	return &schemas.BifrostError{
+		IsBifrostError: false,
+		StatusCode:     schemas.Ptr(403),
+		Type:           schemas.Ptr("direct_key_policy_violation"),
+		Error: &schemas.ErrorField{
+			Message: message,
+		},
+	}
+}
+
+// checkDirectKeyPolicy validates a request carrying a direct key against the direct key policy.
+// It runs after the PreHooks, so it also covers the provider and model a plugin routed the request to.
+// Returns nil if the request carries no direct key or no policy is configured.
+func (bifrost *Bifrost) checkDirectKeyPolicy(ctx context.Context, provider schemas.ModelProvider, model string, plugins []schemas.Plugin) *schemas.BifrostError {
+	policy := bifrost.directKeyPolicy.Load()
+	if policy == nil || !hasDirectKey(ctx) {
+		return nil
+	}
+	if !policy.IsProviderAllowed(provider) {
+		return newDirectKeyPolicyError(fmt.Sprintf("direct keys are not allowed for provider %s", provider))
+	}
+	if policy.IsModelBlocked(provider, model) {
+		return newDirectKeyPolicyError(fmt.Sprintf("direct keys are not allowed for model %s", model))
+	}
+	for _, required := range policy.RequiredPlugins {
+		if !slices.ContainsFunc(plugins, func(plugin schemas.Plugin) bool { return plugin.GetName() == required }) {
+			return newDirectKeyPolicyError(fmt.Sprintf("direct keys require the %s plugin, which is not loaded", required))
+		}
+	}
+	return nil
+}
+
+// UpdateDirectKeyPolicy replaces the policy applied to requests carrying a direct key, nil removes all constraints
+func (bifrost *Bifrost) UpdateDirectKeyPolicy(policy *schemas.DirectKeyPolicy) {
+	bifrost.directKeyPolicy.Store(policy)
+}
@@ -0,0 +1,146 @@
+package bifrost
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestCheckDirectKeyPolicy tests the provider, model and plugin constraints on direct key requests
+func TestCheckDirectKeyPolicy(t *testing.T) {
+	bifrost := &Bifrost{}
+	plugins := []schemas.Plugin{&slowPlugin{name: "logging"}}
+	directKeyCtx := context.WithValue(context.Background(), schemas.BifrostContextKeyDirectKey, schemas.Key{Value: "sk-caller"})
+
+	if err := bifrost.checkDirectKeyPolicy(directKeyCtx, schemas.Anthropic, "claude-3-opus", plugins); err != nil {
+		t.Fatalf("expected no constraints without a policy, got %+v", err)
+	}
+
+	bifrost.UpdateDirectKeyPolicy(&schemas.DirectKeyPolicy{
+		AllowedProviders: []schemas.ModelProvider{schemas.OpenAI, schemas.Anthropic},
+		BlockedModels:    []string{"gpt-4.5-preview", "anthropic/claude-3-opus"},
+		RequiredPlugins:  []string{"logging"},
+	})
+
+	tests := map[string]struct {
+		ctx      context.Context
+		provider schemas.ModelProvider
+		model    string
+		plugins  []schemas.Plugin
+		allowed  bool
+	}{
+		"allowed provider and model":   {directKeyCtx, schemas.OpenAI, "gpt-4o", plugins, true},
+		"provider not allowed":         {directKeyCtx, schemas.Bedrock, "gpt-4o", plugins, false},
+		"blocked model":                {directKeyCtx, schemas.OpenAI, "gpt-4.5-preview", plugins, false},
+		"blocked provider/model":       {directKeyCtx, schemas.Anthropic, "claude-3-opus", plugins, false},
+		"model blocked for other only": {directKeyCtx, schemas.OpenAI, "claude-3-opus", plugins, true},
+		"required plugin not loaded":   {directKeyCtx, schemas.OpenAI, "gpt-4o", nil, false},
+		"no direct key":                {context.Background(), schemas.Bedrock, "gpt-4.5-preview", nil, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := bifrost.checkDirectKeyPolicy(tt.ctx, tt.provider, tt.model, tt.plugins)
+			if tt.allowed && err != nil {
+				t.Fatalf("expected request to be allowed, got %s", err.Error.Message)
+			}
+			if !tt.allowed && (err == nil || err.StatusCode == nil || *err.StatusCode != 403) {
+				t.Fatalf("expected a 403 policy violation, got %+v", err)
+			}
+		})
+	}
+}
+
+// TestDirectKeyRequiredPluginFailsClosed tests that a fail-open plugin required by the policy blocks direct key requests it could not process
+func TestDirectKeyRequiredPluginFailsClosed(t *testing.T) {
+	plugin := &slowPlugin{name: "logging", delay: time.Second}
+	pipeline := newTestPipeline(plugin, schemas.PluginExecutionConfig{TimeoutMs: 20})
+	pipeline.directKeyRequiredPlugins = []string{"logging"}
+
+	ctx := context.Background()
+	if _, shortCircuit, _ := pipeline.RunPreHooks(&ctx, &schemas.BifrostRequest{}); shortCircuit != nil {
+		t.Fatalf("expected requests without a direct key to fail open, got %+v", shortCircuit)
+	}
+
+	ctx = context.WithValue(context.Background(), schemas.BifrostContextKeyDirectKey, schemas.Key{Value: "sk-caller"})
+	_, shortCircuit, _ := pipeline.RunPreHooks(&ctx, &schemas.BifrostRequest{})
+	if shortCircuit == nil || shortCircuit.Error == nil {
+		t.Fatal("expected direct key request to fail closed when a required plugin times out")
+	}
+}
+
+// failingPlugin is a test plugin whose hooks return an error, optionally after a delay
+type failingPlugin struct {
+	name  string
+	delay time.Duration
+	err   error
+}
+
+func (p *failingPlugin) GetName() string { return p.name }
+
+func (p *failingPlugin) TransportInterceptor(ctx *schemas.BifrostContext, url string, headers map[string]string, body map[string]any) (map[string]string, map[string]any, error) {
+	return headers, body, nil
+}
+
+func (p *failingPlugin) PreHook(ctx *schemas.BifrostContext, req *schemas.BifrostRequest) (*schemas.BifrostRequest, *schemas.PluginShortCircuit, error) {
+	p.wait(ctx)
+	return req, nil, p.err
+}
+
+func (p *failingPlugin) PostHook(ctx *schemas.BifrostContext, result *schemas.BifrostResponse, err *schemas.BifrostError) (*schemas.BifrostResponse, *schemas.BifrostError, error) {
+	p.wait(ctx)
+	return result, err, p.err
+}
+
+func (p *failingPlugin) wait(ctx *schemas.BifrostContext) {
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+	}
+}
+
+func (p *failingPlugin) Cleanup() error { return nil }
+
+// TestDirectKeyRequiredPluginHookErrors tests that a required plugin fails closed on direct key requests, with or
+// without execution limits, whether its hooks could not run or returned an error
+func TestDirectKeyRequiredPluginHookErrors(t *testing.T) {
+	hookErr := errors.New("audit sink unavailable")
+	tests := map[string]struct {
+		plugin   *failingPlugin
+		executor *schemas.PluginExecutionConfig
+	}{
+		"hook error without execution limits": {plugin: &failingPlugin{name: "logging", err: hookErr}},
+		"hook error with execution limits":    {plugin: &failingPlugin{name: "logging", err: hookErr}, executor: &schemas.PluginExecutionConfig{TimeoutMs: 1000}},
+		"hook timeout":                        {plugin: &failingPlugin{name: "logging", delay: time.Second}, executor: &schemas.PluginExecutionConfig{TimeoutMs: 20}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			pipeline := &PluginPipeline{plugins: []schemas.Plugin{tt.plugin}, directKeyRequiredPlugins: []string{"logging"}, logger: logger}
+			if tt.executor != nil {
+				pipeline.executors = map[string]*pluginExecutor{"logging": newPluginExecutor(*tt.executor)}
+			}
+			response := &schemas.BifrostResponse{}
+
+			// Requests without a direct key fail open
+			ctx := context.Background()
+			if _, shortCircuit, _ := pipeline.RunPreHooks(&ctx, &schemas.BifrostRequest{}); shortCircuit != nil {
+				t.Fatalf("expected the pre-hook to fail open without a direct key, got %+v", shortCircuit)
+			}
+			if resp, bifrostErr := pipeline.RunPostHooks(&ctx, response, nil, 1); bifrostErr != nil || resp != response {
+				t.Fatalf("expected the post-hook to fail open without a direct key, got %+v", bifrostErr)
+			}
+
+			ctx = context.WithValue(context.Background(), schemas.BifrostContextKeyDirectKey, schemas.Key{Value: "sk-caller"})
+			_, shortCircuit, _ := pipeline.RunPreHooks(&ctx, &schemas.BifrostRequest{})
+			if shortCircuit == nil || shortCircuit.Error == nil || *shortCircuit.Error.StatusCode != 503 {
+				t.Fatalf("expected the pre-hook to fail closed with a 503, got %+v", shortCircuit)
+			}
+			resp, bifrostErr := pipeline.RunPostHooks(&ctx, response, nil, 1)
+			if resp != nil || bifrostErr == nil || *bifrostErr.StatusCode != 503 {
+				t.Fatalf("expected the post-hook to replace the response with a 503, got %+v, %+v", resp, bifrostErr)
+			}
+		})
+	}
+}
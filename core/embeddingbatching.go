@@ -0,0 +1,247 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maximhq/bifrost/core/providers/bedrock"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// embeddingBatchSize returns the number of inputs the provider embeds in one request with the model,
+// 0 when the provider has no documented limit and inputs are sent in a single request
+func embeddingBatchSize(provider schemas.ModelProvider, model string) int {
+	switch provider {
+	case schemas.OpenAI, schemas.Azure:
+		return 2048
+	case schemas.Cohere:
+		return 96
+	case schemas.Gemini:
+		return 100
+	case schemas.Vertex:
+		// Gemini embedding models take a single input per request on Vertex
+		if strings.Contains(model, "gemini-embedding") {
+			return 1
+		}
+		return 250
+	case schemas.Bedrock:
+		// Titan embeds a single text per request
+		if modelType, err := bedrock.DetermineEmbeddingModelType(model); err == nil && modelType == "titan" {
+			return 1
+		}
+		return 96
+	}
+	return 0
+}
+
+// embeddingBatch is a range of the inputs of an embedding request, sent in its own request
+type embeddingBatch struct {
+	start    int
+	end      int
+	response *schemas.BifrostEmbeddingResponse
+	err      *schemas.BifrostError
+}
+
+// splitEmbeddingRequest splits the input array of the request into batches of at most batchSize inputs,
+// it returns nil when the input fits in a single request
+func splitEmbeddingRequest(req *schemas.BifrostEmbeddingRequest, batchSize int) []*embeddingBatch {
+	if batchSize <= 0 || req.Input == nil {
+		return nil
+	}
+	size := len(req.Input.Texts)
+	if req.Input.Texts == nil {
+		size = len(req.Input.Embeddings)
+	}
+	if size <= batchSize {
+		return nil
+	}
+	batches := make([]*embeddingBatch, 0, (size+batchSize-1)/batchSize)
+	for start := 0; start < size; start += batchSize {
+		batches = append(batches, &embeddingBatch{start: start, end: min(start+batchSize, size)})
+	}
+	return batches
+}
+
+// batchRequest returns a copy of the request embedding the inputs of the batch
+func (batch *embeddingBatch) batchRequest(req *schemas.BifrostEmbeddingRequest) *schemas.BifrostEmbeddingRequest {
+	batchReq := *req
+	batchReq.RawRequestBody = nil
+	if req.Input.Texts != nil {
+		batchReq.Input = &schemas.EmbeddingInput{Texts: req.Input.Texts[batch.start:batch.end]}
+	} else {
+		batchReq.Input = &schemas.EmbeddingInput{Embeddings: req.Input.Embeddings[batch.start:batch.end]}
+	}
+	return &batchReq
+}
+
+// embeddingBatches returns the batches of the request when its input is larger than the batch size of its provider,
+// nil when it is sent in a single request. Custom providers are batched as their base provider, and raw request
+// bodies are sent as they are.
+func (bifrost *Bifrost) embeddingBatches(ctx context.Context, req *schemas.BifrostEmbeddingRequest) ([]*embeddingBatch, int) {
+	if ctx != nil {
+		if rawBody, ok := ctx.Value(schemas.BifrostContextKeyUseRawRequestBody).(bool); ok && rawBody {
+			return nil, 0
+		}
+	}
+	baseProvider := req.Provider
+	config, err := bifrost.account.GetConfigForProvider(req.Provider)
+	if err != nil || config == nil {
+		return nil, 0
+	}
+	if config.CustomProviderConfig != nil && config.CustomProviderConfig.BaseProviderType != "" {
+		baseProvider = config.CustomProviderConfig.BaseProviderType
+	}
+	batches := splitEmbeddingRequest(req, embeddingBatchSize(baseProvider, req.Model))
+	return batches, config.ConcurrencyAndBufferSize.Concurrency
+}
+
+// partialFailureMode returns whether a request of many inputs returns partial results or fails when some inputs fail,
+// from the x-bf-partial-failures header of the request or the partial failure config
+func (bifrost *Bifrost) partialFailureMode(ctx context.Context) schemas.PartialFailureMode {
+	if mode, ok := ctx.Value(schemas.BifrostContextKeyPartialFailureMode).(schemas.PartialFailureMode); ok && mode != "" {
+		return mode
+	}
+	if config := bifrost.partialFailures.Load(); config != nil && config.Mode != "" {
+		return config.Mode
+	}
+	return schemas.PartialFailureModePartial
+}
+
+// handleBatchedEmbeddingRequest sends the batches of the request in parallel, at most concurrency at a time so that
+// the queue of the provider is not flooded, and reassembles their embeddings in the order of the inputs.
+// Batches are logged as requests of their own. In strict mode, the first failed batch cancels the others and fails
+// the request.
+func (bifrost *Bifrost) handleBatchedEmbeddingRequest(ctx context.Context, req *schemas.BifrostEmbeddingRequest, batches []*embeddingBatch, concurrency int) (*schemas.BifrostEmbeddingResponse, *schemas.BifrostError) {
+	if ctx == nil {
+		ctx = bifrost.ctx
+	}
+	if concurrency <= 0 {
+		concurrency = schemas.DefaultConcurrency
+	}
+	strict := bifrost.partialFailureMode(ctx).Strict()
+	ctx, requestID := withRequestID(ctx)
+	startTime := time.Now()
+
+	batchesCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var failOnce sync.Once
+	var strictErr *schemas.BifrostError
+
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, batch := range batches {
+		wg.Add(1)
+		go func(i int, batch *embeddingBatch) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			// Batches waiting for a slot are not sent once a strict request failed
+			if strict && batchesCtx.Err() != nil {
+				batch.err = newBifrostErrorFromMsg(schemas.ErrRequestCancelled)
+				return
+			}
+
+			batchCtx := batchesCtx
+			if i > 0 {
+				batchCtx = context.WithValue(batchesCtx, schemas.BifrostContextKeyRequestID, fmt.Sprintf("%s-%d", requestID, i))
+			}
+			bifrostReq := bifrost.getBifrostRequest()
+			bifrostReq.RequestType = schemas.EmbeddingRequest
+			bifrostReq.EmbeddingRequest = batch.batchRequest(req)
+			response, err := bifrost.handleRequest(batchCtx, bifrostReq)
+			if err != nil {
+				batch.err = err
+				if strict {
+					failOnce.Do(func() {
+						strictErr = err
+						cancel()
+					})
+				}
+				return
+			}
+			batch.response = response.EmbeddingResponse
+		}(i, batch)
+	}
+	wg.Wait()
+
+	if strictErr != nil {
+		return nil, strictErr
+	}
+	merged, err := mergeEmbeddingBatches(batches, strict)
+	if err != nil {
+		return nil, err
+	}
+	merged.ExtraFields.RawResponse = nil
+	merged.ExtraFields.Latency = time.Since(startTime).Milliseconds()
+	return merged, nil
+}
+
+// mergeEmbeddingBatches reassembles the embeddings of the batches in the order of the inputs. When some batches
+// failed, the response holds the embeddings of the others, the failed batches and the status of every input, unless
+// strict is set and the error of the first failed batch is returned. When all of them failed, the error of the first
+// batch is returned.
+func mergeEmbeddingBatches(batches []*embeddingBatch, strict bool) (*schemas.BifrostEmbeddingResponse, *schemas.BifrostError) {
+	var merged *schemas.BifrostEmbeddingResponse
+	var firstErr *schemas.BifrostError
+	for _, batch := range batches {
+		if batch.err != nil && firstErr == nil {
+			firstErr = batch.err
+		}
+		if batch.err != nil || batch.response == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &schemas.BifrostEmbeddingResponse{
+				Model:       batch.response.Model,
+				Object:      batch.response.Object,
+				ExtraFields: batch.response.ExtraFields,
+			}
+		}
+		for _, data := range batch.response.Data {
+			data.Index += batch.start
+			merged.Data = append(merged.Data, data)
+		}
+		if usage := batch.response.Usage; usage != nil {
+			if merged.Usage == nil {
+				merged.Usage = &schemas.BifrostLLMUsage{}
+			}
+			merged.Usage.PromptTokens += usage.PromptTokens
+			merged.Usage.CompletionTokens += usage.CompletionTokens
+			merged.Usage.TotalTokens += usage.TotalTokens
+		}
+	}
+	if firstErr != nil && (merged == nil || strict) {
+		return nil, firstErr
+	}
+	if merged == nil {
+		return nil, newBifrostErrorFromMsg("embedding batches returned no response")
+	}
+	if firstErr == nil {
+		return merged, nil
+	}
+
+	for _, batch := range batches {
+		if batch.err != nil {
+			merged.FailedBatches = append(merged.FailedBatches, schemas.EmbeddingBatchError{
+				StartIndex: batch.start,
+				EndIndex:   batch.end,
+				Error:      batch.err,
+			})
+		}
+		for index := batch.start; index < batch.end; index++ {
+			status := schemas.EmbeddingItemStatus{Index: index, Status: schemas.ItemStatusSucceeded}
+			if batch.err != nil {
+				status.Status = schemas.ItemStatusFailed
+				status.ErrorCode = errorCodeOf(batch.err)
+				if batch.err.Error != nil {
+					status.Message = batch.err.Error.Message
+				}
+			}
+			merged.ItemStatuses = append(merged.ItemStatuses, status)
+		}
+	}
+	return merged, nil
+}
@@ -0,0 +1,97 @@
+package bifrost
+
+import (
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestSplitEmbeddingRequest tests that input arrays larger than the batch size are split into batches
+func TestSplitEmbeddingRequest(t *testing.T) {
+	req := &schemas.BifrostEmbeddingRequest{
+		Provider: schemas.Cohere,
+		Model:    "embed-english-v3.0",
+		Input:    &schemas.EmbeddingInput{Texts: []string{"a", "b", "c", "d", "e"}},
+	}
+
+	if batches := splitEmbeddingRequest(req, 5); batches != nil {
+		t.Errorf("expected inputs fitting in a request not to be split, got %d batches", len(batches))
+	}
+	if batches := splitEmbeddingRequest(req, 0); batches != nil {
+		t.Errorf("expected providers without a batch size not to be split, got %d batches", len(batches))
+	}
+
+	batches := splitEmbeddingRequest(req, 2)
+	if len(batches) != 3 || batches[2].start != 4 || batches[2].end != 5 {
+		t.Fatalf("expected 3 batches ending with the last input, got %+v", batches)
+	}
+	batchReq := batches[1].batchRequest(req)
+	if len(batchReq.Input.Texts) != 2 || batchReq.Input.Texts[0] != "c" || batchReq.Model != req.Model {
+		t.Errorf("expected the second batch to embed c and d with the model of the request, got %+v", batchReq)
+	}
+	if len(req.Input.Texts) != 5 {
+		t.Errorf("expected the request to be left untouched, got %v", req.Input.Texts)
+	}
+
+	tokens := &schemas.BifrostEmbeddingRequest{Input: &schemas.EmbeddingInput{Embeddings: [][]int{{1}, {2}, {3}}}}
+	if batches := splitEmbeddingRequest(tokens, 1); len(batches) != 3 {
+		t.Errorf("expected token inputs to be split, got %d batches", len(batches))
+	}
+}
+
+// TestEmbeddingBatchSize tests the batch sizes of the providers
+func TestEmbeddingBatchSize(t *testing.T) {
+	tests := []struct {
+		provider schemas.ModelProvider
+		model    string
+		expected int
+	}{
+		{schemas.OpenAI, "text-embedding-3-small", 2048},
+		{schemas.Cohere, "embed-english-v3.0", 96},
+		{schemas.Bedrock, "amazon.titan-embed-text-v2:0", 1},
+		{schemas.Bedrock, "cohere.embed-english-v3", 96},
+		{schemas.Vertex, "gemini-embedding-001", 1},
+		{schemas.Ollama, "nomic-embed-text", 0},
+	}
+	for _, test := range tests {
+		if got := embeddingBatchSize(test.provider, test.model); got != test.expected {
+			t.Errorf("expected a batch size of %d for %s/%s, got %d", test.expected, test.provider, test.model, got)
+		}
+	}
+}
+
+// TestMergeEmbeddingBatches tests that partially failed batches return the embeddings of the others with the status of
+// every input, and fail the request in strict mode
+func TestMergeEmbeddingBatches(t *testing.T) {
+	newBatches := func() []*embeddingBatch {
+		return []*embeddingBatch{
+			{start: 0, end: 2, response: &schemas.BifrostEmbeddingResponse{Model: "embed", Data: []schemas.EmbeddingData{{Index: 0}, {Index: 1}}}},
+			{start: 2, end: 3, err: &schemas.BifrostError{StatusCode: schemas.Ptr(429), Error: &schemas.ErrorField{Message: "Rate limit reached"}}},
+		}
+	}
+
+	merged, err := mergeEmbeddingBatches(newBatches(), false)
+	if err != nil {
+		t.Fatalf("expected partial results, got %v", err)
+	}
+	if len(merged.Data) != 2 || len(merged.FailedBatches) != 1 || len(merged.ItemStatuses) != 3 {
+		t.Fatalf("expected 2 embeddings, 1 failed batch and 3 item statuses, got %+v", merged)
+	}
+	failed := merged.ItemStatuses[2]
+	if failed.Status != schemas.ItemStatusFailed || failed.ErrorCode != schemas.ErrorCodeRateLimited || failed.Message != "Rate limit reached" {
+		t.Errorf("expected the third input to fail as rate limited, got %+v", failed)
+	}
+	if merged.ItemStatuses[0].Status != schemas.ItemStatusSucceeded {
+		t.Errorf("expected the first input to succeed, got %+v", merged.ItemStatuses[0])
+	}
+
+	if _, err := mergeEmbeddingBatches(newBatches(), true); err == nil || err.StatusCode == nil || *err.StatusCode != 429 {
+		t.Errorf("expected strict mode to return the error of the failed batch, got %v", err)
+	}
+
+	succeeded := newBatches()
+	succeeded[1] = &embeddingBatch{start: 2, end: 3, response: &schemas.BifrostEmbeddingResponse{Data: []schemas.EmbeddingData{{Index: 0}}}}
+	if merged, err := mergeEmbeddingBatches(succeeded, true); err != nil || len(merged.Data) != 3 || merged.ItemStatuses != nil {
+		t.Errorf("expected successful batches to be merged without item statuses, got %+v, %v", merged, err)
+	}
+}
@@ -0,0 +1,37 @@
+package bifrost
+
+import (
+	"context"
+	"strings"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// embeddingModelFamily returns the name identifying the embedding space of a model across providers,
+// e.g. "us.amazon.titan-embed-text-v2:0" becomes "titan-embed-text-v2" and "text-embedding-3-small" is kept as is
+func embeddingModelFamily(model string) string {
+	name := strings.ToLower(model)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	for _, vendor := range []string{"amazon.", "cohere.", "mistral.", "google."} {
+		if i := strings.Index(name, vendor); i >= 0 {
+			name = name[i+len(vendor):]
+		}
+	}
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// embeddingFallbackAllowed reports whether the embeddings of the fallback model can be mixed with the ones of the
+// primary model. Embeddings of different models live in different spaces, often of different sizes, and mixing them
+// corrupts vector indexes, so fallbacks must embed with the same model on another provider unless the request
+// allows mixed embeddings with BifrostContextKeyAllowMixedEmbeddings.
+func embeddingFallbackAllowed(ctx context.Context, primaryModel string, fallbackModel string) bool {
+	if allow, ok := ctx.Value(schemas.BifrostContextKeyAllowMixedEmbeddings).(bool); ok && allow {
+		return true
+	}
+	return embeddingModelFamily(primaryModel) == embeddingModelFamily(fallbackModel)
+}
@@ -0,0 +1,28 @@
+package bifrost
+
+import (
+	"context"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestEmbeddingFallbackAllowed tests that embedding fallbacks must use the same model unless mixed embeddings are allowed
+func TestEmbeddingFallbackAllowed(t *testing.T) {
+	ctx := context.Background()
+
+	if !embeddingFallbackAllowed(ctx, "text-embedding-3-small", "text-embedding-3-small") {
+		t.Error("expected the same model on another provider to be allowed")
+	}
+	if !embeddingFallbackAllowed(ctx, "amazon.titan-embed-text-v2:0", "us.amazon.titan-embed-text-v2:0") {
+		t.Error("expected the regional Bedrock model to be allowed")
+	}
+	if embeddingFallbackAllowed(ctx, "text-embedding-3-small", "text-embedding-005") {
+		t.Error("expected another embedding model to be refused")
+	}
+
+	allowed := context.WithValue(ctx, schemas.BifrostContextKeyAllowMixedEmbeddings, true)
+	if !embeddingFallbackAllowed(allowed, "text-embedding-3-small", "text-embedding-005") {
+		t.Error("expected mixed embeddings to be allowed explicitly")
+	}
+}
@@ -0,0 +1,121 @@
+package bifrost
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net/http"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// conformEmbeddings applies the dimensions and normalize options of the request to the embeddings of the response,
+// so that every provider of a request and its fallbacks returns vectors of the same shape. Embeddings longer than the
+// requested dimensions are truncated, as Matryoshka embeddings are, and embeddings are scaled to unit length when
+// normalization is requested, including base64 encoded float32 embeddings. Embeddings shorter than the requested
+// dimensions cannot be conformed and fail the request.
+func conformEmbeddings(req *schemas.BifrostEmbeddingRequest, response *schemas.BifrostEmbeddingResponse) *schemas.BifrostError {
+	if req.Params == nil || response == nil {
+		return nil
+	}
+	dimensions := 0
+	if req.Params.Dimensions != nil {
+		dimensions = *req.Params.Dimensions
+	}
+	normalize := req.Params.Normalize != nil && *req.Params.Normalize
+	if dimensions <= 0 && !normalize {
+		return nil
+	}
+
+	conform := func(vector []float32) ([]float32, error) {
+		if dimensions > 0 {
+			if len(vector) < dimensions {
+				return nil, fmt.Errorf("provider returned embeddings of %d dimensions, %d were requested", len(vector), dimensions)
+			}
+			vector = vector[:dimensions]
+		}
+		if normalize {
+			normalizeVector(vector)
+		}
+		return vector, nil
+	}
+
+	for i := range response.Data {
+		embedding := &response.Data[i].Embedding
+		var err error
+		switch {
+		case embedding.EmbeddingStr != nil:
+			var vector []float32
+			if vector, err = decodeBase64Embedding(*embedding.EmbeddingStr); err == nil {
+				if vector, err = conform(vector); err == nil {
+					embedding.EmbeddingStr = schemas.Ptr(encodeBase64Embedding(vector))
+				}
+			}
+		case embedding.EmbeddingArray != nil:
+			embedding.EmbeddingArray, err = conform(embedding.EmbeddingArray)
+		case embedding.Embedding2DArray != nil:
+			for j := range embedding.Embedding2DArray {
+				if embedding.Embedding2DArray[j], err = conform(embedding.Embedding2DArray[j]); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			return &schemas.BifrostError{
+				IsBifrostError: true,
+				StatusCode:     schemas.Ptr(http.StatusBadGateway),
+				Error: &schemas.ErrorField{
+					Message: err.Error(),
+					Error:   err,
+				},
+				ExtraFields: schemas.BifrostErrorExtraFields{
+					RequestType:    schemas.EmbeddingRequest,
+					Provider:       response.ExtraFields.Provider,
+					ModelRequested: req.Model,
+				},
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeVector scales the vector to unit length in place, zero vectors are left as they are
+func normalizeVector(vector []float32) {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return
+	}
+	norm := math.Sqrt(sum)
+	for i, v := range vector {
+		vector[i] = float32(float64(v) / norm)
+	}
+}
+
+// decodeBase64Embedding decodes an embedding encoded as base64 little-endian float32 values, as OpenAI encodes them
+func decodeBase64Embedding(encoded string) ([]float32, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64 embedding: %w", err)
+	}
+	if len(data)%4 != 0 {
+		return nil, fmt.Errorf("base64 embedding of %d bytes is not made of float32 values", len(data))
+	}
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	}
+	return vector, nil
+}
+
+// encodeBase64Embedding encodes an embedding as base64 little-endian float32 values
+func encodeBase64Embedding(vector []float32) string {
+	data := make([]byte, len(vector)*4)
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(v))
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
@@ -0,0 +1,37 @@
+package bifrost
+
+import (
+	"math"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestConformEmbeddings tests that embeddings are truncated to the requested dimensions and normalized
+func TestConformEmbeddings(t *testing.T) {
+	req := &schemas.BifrostEmbeddingRequest{
+		Model:  "nomic-embed-text",
+		Params: &schemas.EmbeddingParameters{Dimensions: schemas.Ptr(2), Normalize: schemas.Ptr(true)},
+	}
+	response := &schemas.BifrostEmbeddingResponse{Data: []schemas.EmbeddingData{
+		{Embedding: schemas.EmbeddingStruct{EmbeddingArray: []float32{3, 4, 12}}},
+		{Embedding: schemas.EmbeddingStruct{EmbeddingStr: schemas.Ptr(encodeBase64Embedding([]float32{0, 2, 5}))}},
+	}}
+
+	if err := conformEmbeddings(req, response); err != nil {
+		t.Fatalf("failed to conform the embeddings: %v", err.Error.Message)
+	}
+	if vector := response.Data[0].Embedding.EmbeddingArray; len(vector) != 2 || math.Abs(float64(vector[0])-0.6) > 1e-6 || math.Abs(float64(vector[1])-0.8) > 1e-6 {
+		t.Errorf("expected the embedding to be truncated and normalized to [0.6 0.8], got %v", vector)
+	}
+	vector, err := decodeBase64Embedding(*response.Data[1].Embedding.EmbeddingStr)
+	if err != nil || len(vector) != 2 || vector[1] != 1 {
+		t.Errorf("expected the base64 embedding to be truncated and normalized to [0 1], got %v (%v)", vector, err)
+	}
+
+	req.Params.Dimensions = schemas.Ptr(4)
+	response = &schemas.BifrostEmbeddingResponse{Data: []schemas.EmbeddingData{{Embedding: schemas.EmbeddingStruct{EmbeddingArray: []float32{1, 2}}}}}
+	if err := conformEmbeddings(req, response); err == nil {
+		t.Error("expected embeddings shorter than the requested dimensions to fail the request")
+	}
+}
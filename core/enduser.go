@@ -0,0 +1,72 @@
+package bifrost
+
+import (
+	"context"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// withEndUser reconciles the end user of the context with the user parameter of the request. The end user of the
+// context is sent to the provider as the user parameter, replacing the one of the request, and requests sent with
+// only a user parameter get it as the end user of their context, for the plugins limiting and recording end users.
+func withEndUser(ctx context.Context, req *schemas.BifrostRequest) context.Context {
+	if endUser, ok := ctx.Value(schemas.BifrostContextKeyEndUser).(string); ok && endUser != "" {
+		setRequestUser(req, endUser)
+		return ctx
+	}
+	if user := requestUser(req); user != "" {
+		return context.WithValue(ctx, schemas.BifrostContextKeyEndUser, user)
+	}
+	return ctx
+}
+
+// requestUser returns the user parameter of the request, or an empty string if it has none
+func requestUser(req *schemas.BifrostRequest) string {
+	var user *string
+	switch {
+	case req.ChatRequest != nil && req.ChatRequest.Params != nil:
+		user = req.ChatRequest.Params.User
+	case req.ResponsesRequest != nil && req.ResponsesRequest.Params != nil:
+		user = req.ResponsesRequest.Params.User
+	case req.TextCompletionRequest != nil && req.TextCompletionRequest.Params != nil:
+		user = req.TextCompletionRequest.Params.User
+	}
+	if user == nil {
+		return ""
+	}
+	return *user
+}
+
+// setRequestUser sets the user parameter of the requests that have one, on copies of the request and parameters of
+// the caller
+func setRequestUser(req *schemas.BifrostRequest, user string) {
+	switch {
+	case req.ChatRequest != nil:
+		chatReq := *req.ChatRequest
+		params := schemas.ChatParameters{}
+		if chatReq.Params != nil {
+			params = *chatReq.Params
+		}
+		params.User = schemas.Ptr(user)
+		chatReq.Params = &params
+		req.ChatRequest = &chatReq
+	case req.ResponsesRequest != nil:
+		responsesReq := *req.ResponsesRequest
+		params := schemas.ResponsesParameters{}
+		if responsesReq.Params != nil {
+			params = *responsesReq.Params
+		}
+		params.User = schemas.Ptr(user)
+		responsesReq.Params = &params
+		req.ResponsesRequest = &responsesReq
+	case req.TextCompletionRequest != nil:
+		textReq := *req.TextCompletionRequest
+		params := schemas.TextCompletionParameters{}
+		if textReq.Params != nil {
+			params = *textReq.Params
+		}
+		params.User = schemas.Ptr(user)
+		textReq.Params = &params
+		req.TextCompletionRequest = &textReq
+	}
+}
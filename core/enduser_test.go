@@ -0,0 +1,83 @@
+package bifrost
+
+import (
+	"context"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestWithEndUser tests that the end user of the context (the x-bf-user header) is sent to the provider as the user
+// parameter, and that the user parameter becomes the end user of the context when there is none
+func TestWithEndUser(t *testing.T) {
+	tests := map[string]struct {
+		endUser  string
+		req      func(user *string) *schemas.BifrostRequest
+		user     *string
+		expected string
+	}{
+		"header over body user": {
+			endUser: "alice",
+			req: func(user *string) *schemas.BifrostRequest {
+				return &schemas.BifrostRequest{ChatRequest: &schemas.BifrostChatRequest{Params: &schemas.ChatParameters{User: user}}}
+			},
+			user:     schemas.Ptr("bob"),
+			expected: "alice",
+		},
+		"header without params": {
+			endUser: "alice",
+			req: func(user *string) *schemas.BifrostRequest {
+				return &schemas.BifrostRequest{ChatRequest: &schemas.BifrostChatRequest{}}
+			},
+			expected: "alice",
+		},
+		"body user": {
+			req: func(user *string) *schemas.BifrostRequest {
+				return &schemas.BifrostRequest{ChatRequest: &schemas.BifrostChatRequest{Params: &schemas.ChatParameters{User: user}}}
+			},
+			user:     schemas.Ptr("bob"),
+			expected: "bob",
+		},
+		"responses": {
+			endUser: "alice",
+			req: func(user *string) *schemas.BifrostRequest {
+				return &schemas.BifrostRequest{ResponsesRequest: &schemas.BifrostResponsesRequest{Params: &schemas.ResponsesParameters{User: user}}}
+			},
+			user:     schemas.Ptr("bob"),
+			expected: "alice",
+		},
+		"text completion": {
+			req: func(user *string) *schemas.BifrostRequest {
+				return &schemas.BifrostRequest{TextCompletionRequest: &schemas.BifrostTextCompletionRequest{Params: &schemas.TextCompletionParameters{User: user}}}
+			},
+			user:     schemas.Ptr("bob"),
+			expected: "bob",
+		},
+		"no end user": {
+			req: func(user *string) *schemas.BifrostRequest {
+				return &schemas.BifrostRequest{ChatRequest: &schemas.BifrostChatRequest{}}
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.endUser != "" {
+				ctx = context.WithValue(ctx, schemas.BifrostContextKeyEndUser, test.endUser)
+			}
+			req := test.req(test.user)
+			original := *req
+
+			ctx = withEndUser(ctx, req)
+			if endUser, _ := ctx.Value(schemas.BifrostContextKeyEndUser).(string); endUser != test.expected {
+				t.Errorf("expected end user %q, got %q", test.expected, endUser)
+			}
+			if user := requestUser(req); user != test.expected {
+				t.Errorf("expected the provider user parameter %q, got %q", test.expected, user)
+			}
+			if test.user != nil && requestUser(&original) != *test.user {
+				t.Errorf("expected the request of the caller to be left untouched, got %q", requestUser(&original))
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package bifrost
+
+import (
+	"slices"
+	"strings"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// contextLengthErrorCodes are the error codes and types of the providers for inputs exceeding the context window
+var contextLengthErrorCodes = []string{
+	"context_length_exceeded",
+	"string_above_max_length",
+	"model_context_window_exceeded",
+}
+
+// contextLengthPatterns are the substrings of the error messages of the providers for inputs exceeding the context
+// window of the model
+var contextLengthPatterns = []string{
+	"maximum context length",
+	"context length",
+	"context window",
+	"prompt is too long",
+	"input is too long",
+	"exceeds the maximum number of tokens",
+	"too many input tokens",
+}
+
+// overloadedErrorCodes are the error codes and types of the providers for requests rejected for lack of capacity
+var overloadedErrorCodes = []string{
+	"overloaded_error",
+	"server_is_overloaded",
+	"ServiceUnavailableException",
+	"ModelNotReadyException",
+	"UNAVAILABLE",
+}
+
+// overloadedPatterns are the substrings of the error messages of the providers for requests rejected for lack of
+// capacity
+var overloadedPatterns = []string{
+	"overloaded",
+	"over capacity",
+	"at capacity",
+}
+
+// errorCodesByStatus are the canonical codes of the status codes of the provider responses
+var errorCodesByStatus = map[int]schemas.ErrorCode{
+	400: schemas.ErrorCodeInvalidRequest,
+	401: schemas.ErrorCodeAuthFailed,
+	403: schemas.ErrorCodePermission,
+	404: schemas.ErrorCodeNotFound,
+	408: schemas.ErrorCodeTimeout,
+	413: schemas.ErrorCodeContextLength,
+	422: schemas.ErrorCodeInvalidRequest,
+	429: schemas.ErrorCodeRateLimited,
+	503: schemas.ErrorCodeOverloaded,
+	504: schemas.ErrorCodeTimeout,
+	529: schemas.ErrorCodeOverloaded,
+}
+
+// errorCodesByProviderCode are the canonical codes of the error codes and types of the providers, for the errors
+// without a telling status code, e.g. the ones of event streams
+var errorCodesByProviderCode = map[string]schemas.ErrorCode{
+	"rate_limit_exceeded":            schemas.ErrorCodeRateLimited,
+	"rate_limit_error":               schemas.ErrorCodeRateLimited,
+	"insufficient_quota":             schemas.ErrorCodeRateLimited,
+	"ThrottlingException":            schemas.ErrorCodeRateLimited,
+	"RESOURCE_EXHAUSTED":             schemas.ErrorCodeRateLimited,
+	"authentication_error":           schemas.ErrorCodeAuthFailed,
+	"invalid_api_key":                schemas.ErrorCodeAuthFailed,
+	"UnrecognizedClientException":    schemas.ErrorCodeAuthFailed,
+	"UNAUTHENTICATED":                schemas.ErrorCodeAuthFailed,
+	"permission_error":               schemas.ErrorCodePermission,
+	"AccessDeniedException":          schemas.ErrorCodePermission,
+	"PERMISSION_DENIED":              schemas.ErrorCodePermission,
+	"not_found_error":                schemas.ErrorCodeNotFound,
+	"model_not_found":                schemas.ErrorCodeNotFound,
+	"ResourceNotFoundException":      schemas.ErrorCodeNotFound,
+	"NOT_FOUND":                      schemas.ErrorCodeNotFound,
+	"invalid_request_error":          schemas.ErrorCodeInvalidRequest,
+	"ValidationException":            schemas.ErrorCodeInvalidRequest,
+	"INVALID_ARGUMENT":               schemas.ErrorCodeInvalidRequest,
+	"parameter_policy_violation":     schemas.ErrorCodeInvalidRequest,
+	"api_error":                      schemas.ErrorCodeProviderError,
+	"server_error":                   schemas.ErrorCodeProviderError,
+	"InternalServerException":        schemas.ErrorCodeProviderError,
+	"INTERNAL":                       schemas.ErrorCodeProviderError,
+	"timeout_error":                  schemas.ErrorCodeTimeout,
+	"ModelTimeoutException":          schemas.ErrorCodeTimeout,
+	"DEADLINE_EXCEEDED":              schemas.ErrorCodeTimeout,
+	schemas.FirstChunkTimedOut:       schemas.ErrorCodeTimeout,
+	schemas.RequestCancelled:         schemas.ErrorCodeCancelled,
+	"ServiceQuotaExceededException":  schemas.ErrorCodeRateLimited,
+	"ModelStreamErrorException":      schemas.ErrorCodeProviderError,
+	"content_policy_violation_error": schemas.ErrorCodeContentFiltered,
+}
+
+// classifyError returns the canonical code of the error. The specific classes matched on the codes and messages of
+// the providers, such as content filters and context lengths, take precedence over the status code, which providers
+// share between several classes (400 for both invalid parameters and too long inputs).
+func classifyError(err *schemas.BifrostError) schemas.ErrorCode {
+	if err == nil {
+		return ""
+	}
+	message, errorType, errorCode := errorFields(err)
+	switch {
+	case errorType == schemas.RequestCancelled:
+		return schemas.ErrorCodeCancelled
+	case isConnectionError(err):
+		return schemas.ErrorCodeConnectionFailed
+	case message == strings.ToLower(schemas.ErrProviderRequestTimedOut):
+		return schemas.ErrorCodeTimeout
+	case isContentFilterError(err):
+		return schemas.ErrorCodeContentFiltered
+	case matchesError(message, errorType, errorCode, contextLengthErrorCodes, contextLengthPatterns):
+		return schemas.ErrorCodeContextLength
+	case matchesError(message, errorType, errorCode, overloadedErrorCodes, overloadedPatterns):
+		return schemas.ErrorCodeOverloaded
+	}
+	if code, ok := errorCodesByProviderCode[errorCode]; ok {
+		return code
+	}
+	if code, ok := errorCodesByProviderCode[errorType]; ok {
+		return code
+	}
+	if err.StatusCode != nil {
+		if code, ok := errorCodesByStatus[*err.StatusCode]; ok {
+			return code
+		}
+		if *err.StatusCode >= 500 {
+			return schemas.ErrorCodeProviderError
+		}
+	}
+	if IsRateLimitErrorMessage(message) {
+		return schemas.ErrorCodeRateLimited
+	}
+	return schemas.ErrorCodeUnknown
+}
+
+// matchesError reports whether the code or type of the error is one of the codes, or its message contains one of the
+// patterns
+func matchesError(message, errorType, errorCode string, codes, patterns []string) bool {
+	if (errorCode != "" && slices.Contains(codes, errorCode)) || (errorType != "" && slices.Contains(codes, errorType)) {
+		return true
+	}
+	if message == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		if strings.Contains(message, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// setErrorCode sets the canonical code of the error, keeping the one set by the provider or a plugin
+func setErrorCode(err *schemas.BifrostError) {
+	if err != nil && err.ErrorCode == "" {
+		err.ErrorCode = classifyError(err)
+	}
+}
+
+// errorCodeOf returns the canonical code of the error, classifying it when it is not set yet
+func errorCodeOf(err *schemas.BifrostError) schemas.ErrorCode {
+	if err.ErrorCode != "" {
+		return err.ErrorCode
+	}
+	return classifyError(err)
+}
@@ -0,0 +1,133 @@
+package bifrost
+
+import (
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestClassifyError tests that the error shapes of the providers map to their canonical error code
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *schemas.BifrostError
+		want schemas.ErrorCode
+	}{
+		{
+			name: "openai context length",
+			err: &schemas.BifrostError{StatusCode: schemas.Ptr(400), Error: &schemas.ErrorField{
+				Type: schemas.Ptr("invalid_request_error"), Code: schemas.Ptr("context_length_exceeded"),
+				Message: "This model's maximum context length is 8192 tokens",
+			}},
+			want: schemas.ErrorCodeContextLength,
+		},
+		{
+			name: "anthropic context length",
+			err: &schemas.BifrostError{StatusCode: schemas.Ptr(400), Error: &schemas.ErrorField{
+				Type: schemas.Ptr("invalid_request_error"), Message: "prompt is too long: 210000 tokens > 200000 maximum",
+			}},
+			want: schemas.ErrorCodeContextLength,
+		},
+		{
+			name: "anthropic overloaded",
+			err: &schemas.BifrostError{StatusCode: schemas.Ptr(529), Error: &schemas.ErrorField{
+				Type: schemas.Ptr("overloaded_error"), Message: "Overloaded",
+			}},
+			want: schemas.ErrorCodeOverloaded,
+		},
+		{
+			name: "openai rate limit",
+			err: &schemas.BifrostError{StatusCode: schemas.Ptr(429), Error: &schemas.ErrorField{
+				Code: schemas.Ptr("rate_limit_exceeded"), Message: "Rate limit reached for requests",
+			}},
+			want: schemas.ErrorCodeRateLimited,
+		},
+		{
+			name: "bedrock throttling without status",
+			err:  &schemas.BifrostError{Error: &schemas.ErrorField{Type: schemas.Ptr("ThrottlingException"), Message: "Too many tokens, please wait"}},
+			want: schemas.ErrorCodeRateLimited,
+		},
+		{
+			name: "gemini invalid argument",
+			err:  &schemas.BifrostError{StatusCode: schemas.Ptr(400), Error: &schemas.ErrorField{Message: "Invalid value at 'generation_config.temperature'"}},
+			want: schemas.ErrorCodeInvalidRequest,
+		},
+		{
+			name: "azure content filter",
+			err: &schemas.BifrostError{StatusCode: schemas.Ptr(400), Error: &schemas.ErrorField{
+				Code: schemas.Ptr("content_filter"), Message: "The response was filtered due to the prompt triggering the content management policy",
+			}},
+			want: schemas.ErrorCodeContentFiltered,
+		},
+		{
+			name: "invalid api key",
+			err:  &schemas.BifrostError{StatusCode: schemas.Ptr(401), Error: &schemas.ErrorField{Message: "Incorrect API key provided"}},
+			want: schemas.ErrorCodeAuthFailed,
+		},
+		{
+			name: "unknown model",
+			err:  &schemas.BifrostError{StatusCode: schemas.Ptr(404), Error: &schemas.ErrorField{Message: "The model does not exist"}},
+			want: schemas.ErrorCodeNotFound,
+		},
+		{
+			name: "provider internal error",
+			err:  &schemas.BifrostError{StatusCode: schemas.Ptr(500), Error: &schemas.ErrorField{Message: "Internal server error"}},
+			want: schemas.ErrorCodeProviderError,
+		},
+		{
+			name: "connection error",
+			err:  &schemas.BifrostError{IsBifrostError: true, Error: &schemas.ErrorField{Message: schemas.ErrProviderDoRequest}},
+			want: schemas.ErrorCodeConnectionFailed,
+		},
+		{
+			name: "request timeout",
+			err:  &schemas.BifrostError{IsBifrostError: true, Error: &schemas.ErrorField{Message: schemas.ErrProviderRequestTimedOut}},
+			want: schemas.ErrorCodeTimeout,
+		},
+		{
+			name: "first chunk timeout",
+			err:  &schemas.BifrostError{Error: &schemas.ErrorField{Type: schemas.Ptr(schemas.FirstChunkTimedOut), Message: schemas.ErrProviderFirstChunkTimedOut}},
+			want: schemas.ErrorCodeTimeout,
+		},
+		{
+			name: "cancelled",
+			err:  &schemas.BifrostError{Error: &schemas.ErrorField{Type: schemas.Ptr(schemas.RequestCancelled), Message: schemas.ErrRequestCancelled}},
+			want: schemas.ErrorCodeCancelled,
+		},
+		{
+			name: "unclassified",
+			err:  &schemas.BifrostError{Error: &schemas.ErrorField{Message: "something went wrong"}},
+			want: schemas.ErrorCodeUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyError(tt.err); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestErrorCodeRules tests that retry and fallback rules match the canonical code of the errors, and that a code set
+// by a plugin is kept
+func TestErrorCodeRules(t *testing.T) {
+	overloaded := &schemas.BifrostError{StatusCode: schemas.Ptr(529), Error: &schemas.ErrorField{Type: schemas.Ptr("overloaded_error"), Message: "Overloaded"}}
+	retryPolicy := &schemas.RetryPolicy{Rules: []schemas.RetryRule{{Name: "overloaded", ErrorCodes: []string{"overloaded"}}}}
+	if i := matchRetryRule(retryPolicy, overloaded); i != 0 {
+		t.Errorf("expected the canonical code to match the retry rule, got %d", i)
+	}
+
+	contextLength := &schemas.BifrostError{StatusCode: schemas.Ptr(400), Error: &schemas.ErrorField{Message: "input is too long for requested model"}}
+	fallbackPolicy := &schemas.FallbackPolicy{Rules: []schemas.FallbackRule{{Name: "context", ErrorCodes: []string{"context_length"}, Fallback: true}}}
+	if rule := matchFallbackRule(fallbackPolicy, contextLength); rule == nil || rule.Name != "context" {
+		t.Errorf("expected the canonical code to match the fallback rule, got %+v", rule)
+	}
+
+	budget := &schemas.BifrostError{ErrorCode: schemas.ErrorCodeRateLimited, StatusCode: schemas.Ptr(400), Error: &schemas.ErrorField{Message: "budget exceeded"}}
+	setErrorCode(budget)
+	if budget.ErrorCode != schemas.ErrorCodeRateLimited {
+		t.Errorf("expected the code set by the plugin to be kept, got %q", budget.ErrorCode)
+	}
+}
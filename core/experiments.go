@@ -0,0 +1,144 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// experimentSet is the set of enabled experiments in name order
+type experimentSet struct {
+	experiments []schemas.Experiment
+}
+
+// match returns the first experiment in name order enrolling the requested model, a request is enrolled in a
+// single experiment so that its feedback is attributed to one variant
+func (s *experimentSet) match(provider schemas.ModelProvider, model string) *schemas.Experiment {
+	for i := range s.experiments {
+		experiment := &s.experiments[i]
+		if slices.ContainsFunc(experiment.Models, func(m string) bool {
+			return m == "*" || m == model || m == string(provider)+"/"+model
+		}) {
+			return experiment
+		}
+	}
+	return nil
+}
+
+// UpdateExperiments replaces the experiments, disabled experiments are kept out of the set. An empty list enrolls no
+// request. Returns an error and keeps the current experiments if the experiments are invalid.
+func (bifrost *Bifrost) UpdateExperiments(experiments []schemas.Experiment) error {
+	if err := schemas.ValidateExperiments(experiments); err != nil {
+		return err
+	}
+	set := &experimentSet{}
+	for _, experiment := range experiments {
+		if experiment.Enabled {
+			set.experiments = append(set.experiments, experiment)
+		}
+	}
+	if len(set.experiments) == 0 {
+		bifrost.experiments.Store(nil)
+		return nil
+	}
+	sort.Slice(set.experiments, func(i, j int) bool { return set.experiments[i].Name < set.experiments[j].Name })
+	bifrost.experiments.Store(set)
+	return nil
+}
+
+// assignVariant returns the variant of the experiment of the unit, the same unit always gets the same variant as long
+// as the variants and their weights do not change
+func assignVariant(experiment *schemas.Experiment, unit string) *schemas.ExperimentVariant {
+	totalWeight := 0.0
+	for _, variant := range experiment.Variants {
+		totalWeight += variant.Weight
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(experiment.Name + ":" + unit))
+	// The hash is mapped to [0, 1) with the 53 bits a float64 holds exactly
+	point := float64(hash.Sum64()>>11) / (1 << 53) * totalWeight
+	currentWeight := 0.0
+	for i := range experiment.Variants {
+		currentWeight += experiment.Variants[i].Weight
+		if point < currentWeight {
+			return &experiment.Variants[i]
+		}
+	}
+	return &experiment.Variants[len(experiment.Variants)-1]
+}
+
+// applyExperiment assigns the request to a variant of the experiment enrolling its requested model, and applies the
+// model, parameters and system prompt of the variant to a copy of the request. The experiment and variant are set on
+// the context and added to the tags of the request, so that the logs and feedback of the request can be grouped by
+// variant. Returns the request itself when it is not enrolled.
+func (bifrost *Bifrost) applyExperiment(ctx context.Context, req *schemas.BifrostRequest) (context.Context, *schemas.BifrostRequest, *schemas.BifrostError) {
+	set := bifrost.experiments.Load()
+	if set == nil {
+		return ctx, req, nil
+	}
+	provider, model, _ := req.GetRequestFields()
+	experiment := set.match(provider, model)
+	if experiment == nil {
+		return ctx, req, nil
+	}
+	var unit string
+	switch experiment.GetAssignBy() {
+	case schemas.ExperimentAssignByConversation:
+		unit, _ = ctx.Value(schemas.BifrostContextKeyConversationID).(string)
+	default:
+		unit, _ = ctx.Value(schemas.BifrostContextKeyEndUser).(string)
+	}
+	if unit == "" {
+		return ctx, req, nil
+	}
+	variant := assignVariant(experiment, unit)
+
+	assigned := copyRequest(req)
+	if variant.Model != "" {
+		newProvider, newModel := schemas.ParseModelString(variant.Model, "")
+		if newProvider != "" {
+			assigned.SetProvider(newProvider)
+		}
+		assigned.SetModel(newModel)
+	}
+	if variant.SystemPrompt != "" {
+		injectSystemPrompt(&assigned, variant.SystemPrompt)
+	}
+	if actions := variant.ParamActions(); len(actions) > 0 {
+		// Variant parameters are not a policy of the client values, they are set without recording violations
+		ruleActions := make([]ruleAction, 0, len(actions))
+		for _, action := range actions {
+			ruleActions = append(ruleActions, ruleAction{action: action})
+		}
+		if _, err := transformRequestParams(&assigned, ruleActions); err != nil {
+			return ctx, req, &schemas.BifrostError{
+				IsBifrostError: true,
+				StatusCode:     schemas.Ptr(http.StatusBadRequest),
+				Type:           schemas.Ptr("experiment_failed"),
+				AllowFallbacks: schemas.Ptr(false),
+				Error: &schemas.ErrorField{
+					Message: fmt.Sprintf("failed to apply variant %s of experiment %s: %v", variant.Name, experiment.Name, err),
+					Error:   err,
+				},
+			}
+		}
+	}
+
+	tags := make(map[string]string)
+	if callerTags, ok := ctx.Value(schemas.BifrostContextKeyRequestTags).(map[string]string); ok {
+		maps.Copy(tags, callerTags)
+	}
+	tags[schemas.ExperimentTag] = experiment.Name
+	tags[schemas.ExperimentVariantTag] = variant.Name
+	ctx = context.WithValue(ctx, schemas.BifrostContextKeyRequestTags, tags)
+	ctx = context.WithValue(ctx, schemas.BifrostContextKeyExperiment, experiment.Name)
+	ctx = context.WithValue(ctx, schemas.BifrostContextKeyExperimentVariant, variant.Name)
+	bifrost.logger.Debug(fmt.Sprintf("request for %s/%s assigned to variant %s of experiment %s", provider, model, variant.Name, experiment.Name))
+	return ctx, &assigned, nil
+}
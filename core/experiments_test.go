@@ -0,0 +1,113 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// testExperiment compares gpt-4o with a cheaper model and a lower temperature
+var testExperiment = schemas.Experiment{
+	Name:    "cheaper-model",
+	Enabled: true,
+	Models:  []string{"openai/gpt-4o"},
+	Variants: []schemas.ExperimentVariant{
+		{Name: "control", Weight: 1},
+		{
+			Name:         "mini",
+			Weight:       1,
+			Model:        "openai/gpt-4o-mini",
+			Params:       map[string]interface{}{"temperature": 0.2},
+			SystemPrompt: "Answer concisely.",
+		},
+	},
+}
+
+// TestExperiments_AssignDeterministically tests that the requests of a user are always assigned the same variant, and
+// that the variant rewrites the request and tags it
+func TestExperiments_AssignDeterministically(t *testing.T) {
+	bifrost := &Bifrost{logger: NewDefaultLogger(schemas.LogLevelError)}
+	if err := bifrost.UpdateExperiments([]schemas.Experiment{testExperiment}); err != nil {
+		t.Fatalf("expected the experiment to be valid, got %v", err)
+	}
+
+	counts := map[string]int{}
+	for i := range 200 {
+		user := fmt.Sprintf("user-%d", i)
+		ctx := context.WithValue(context.Background(), schemas.BifrostContextKeyEndUser, user)
+		_, _, err := bifrost.applyExperiment(ctx, transformChatRequest())
+		if err != nil {
+			t.Fatalf("expected the variant to apply, got %v", err)
+		}
+		variant := assignVariant(&testExperiment, user)
+		if again := assignVariant(&testExperiment, user); again.Name != variant.Name {
+			t.Fatalf("expected user %s to keep variant %s, got %s", user, variant.Name, again.Name)
+		}
+		counts[variant.Name]++
+	}
+	if counts["control"] < 60 || counts["mini"] < 60 {
+		t.Errorf("expected the users to be split between the variants by weight, got %v", counts)
+	}
+
+	// Find a user of the mini variant and check the rewritten request
+	user := ""
+	for i := 0; user == ""; i++ {
+		if candidate := fmt.Sprintf("user-%d", i); assignVariant(&testExperiment, candidate).Name == "mini" {
+			user = candidate
+		}
+	}
+	ctx := context.WithValue(context.Background(), schemas.BifrostContextKeyEndUser, user)
+	ctx = context.WithValue(ctx, schemas.BifrostContextKeyRequestTags, map[string]string{"feature": "search"})
+	req := transformChatRequest()
+	ctx, assigned, err := bifrost.applyExperiment(ctx, req)
+	if err != nil || assigned == req {
+		t.Fatalf("expected a copy of the request, got %v", err)
+	}
+	if assigned.ChatRequest.Model != "gpt-4o-mini" || *assigned.ChatRequest.Params.Temperature != 0.2 || len(assigned.ChatRequest.Input) != 2 {
+		t.Errorf("expected the model, parameters and system prompt of the variant, got %+v", assigned.ChatRequest)
+	}
+	if req.ChatRequest.Model != "gpt-4o" || *req.ChatRequest.Params.Temperature != 1.8 {
+		t.Errorf("expected the request of the caller unchanged")
+	}
+	tags, _ := ctx.Value(schemas.BifrostContextKeyRequestTags).(map[string]string)
+	if tags["experiment"] != "cheaper-model" || tags["experiment_variant"] != "mini" || tags["feature"] != "search" {
+		t.Errorf("expected the experiment tags next to the request tags, got %v", tags)
+	}
+
+	// Requests without a user or for other models are not enrolled
+	if _, same, _ := bifrost.applyExperiment(context.Background(), req); same != req {
+		t.Errorf("expected requests without a user to be sent as they are")
+	}
+	other := transformChatRequest()
+	other.ChatRequest.Model = "gpt-4.1"
+	if _, same, _ := bifrost.applyExperiment(ctx, other); same != other {
+		t.Errorf("expected requests to other models to be sent as they are")
+	}
+}
+
+// TestValidateExperiments tests the validation of experiments and variants
+func TestValidateExperiments(t *testing.T) {
+	tests := map[string]schemas.Experiment{
+		"no models":         {Name: "a", Variants: testExperiment.Variants},
+		"single variant":    {Name: "a", Models: []string{"*"}, Variants: testExperiment.Variants[:1]},
+		"invalid assign by": {Name: "a", Models: []string{"*"}, AssignBy: "session", Variants: testExperiment.Variants},
+		"zero weight": {Name: "a", Models: []string{"*"}, Variants: []schemas.ExperimentVariant{
+			{Name: "a", Weight: 1}, {Name: "b"},
+		}},
+		"duplicate variant": {Name: "a", Models: []string{"*"}, Variants: []schemas.ExperimentVariant{
+			{Name: "a", Weight: 1}, {Name: "a", Weight: 1},
+		}},
+	}
+	for name, experiment := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := experiment.Validate(); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+	if err := schemas.ValidateExperiments([]schemas.Experiment{testExperiment, testExperiment}); err == nil {
+		t.Error("expected duplicate experiments to be rejected")
+	}
+}
@@ -0,0 +1,165 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+const (
+	// fallbackChainHopsContextKey holds the hops of the fallback chain of the request in the order they are tried
+	fallbackChainHopsContextKey schemas.BifrostContextKey = "bifrost-fallback-chain-hops"
+	// fallbackHopMaxRetriesContextKey holds the retries of the current hop for each retry rule of the provider
+	fallbackHopMaxRetriesContextKey schemas.BifrostContextKey = "bifrost-fallback-hop-max-retries"
+)
+
+// fallbackChainSet is the set of configured fallback chains by alias
+type fallbackChainSet struct {
+	chains map[string]schemas.FallbackChain
+}
+
+// match returns the chain of the requested model, preferring a chain of the provider and model over a chain of the model
+func (s *fallbackChainSet) match(provider schemas.ModelProvider, model string) *schemas.FallbackChain {
+	if chain, ok := s.chains[string(provider)+"/"+model]; ok {
+		return &chain
+	}
+	if chain, ok := s.chains[model]; ok {
+		return &chain
+	}
+	return nil
+}
+
+// UpdateFallbackChains replaces the fallback chains, an empty list sends requests with their own fallbacks.
+// Returns an error and keeps the current chains if the chains are invalid.
+func (bifrost *Bifrost) UpdateFallbackChains(chains []schemas.FallbackChain) error {
+	if len(chains) == 0 {
+		bifrost.fallbackChains.Store(nil)
+		return nil
+	}
+	if err := schemas.ValidateFallbackChains(chains); err != nil {
+		return err
+	}
+	set := &fallbackChainSet{chains: make(map[string]schemas.FallbackChain, len(chains))}
+	for _, chain := range chains {
+		set.chains[chain.Alias] = chain
+	}
+	bifrost.fallbackChains.Store(set)
+	return nil
+}
+
+// orderFallbackHops returns the hops in the order they are tried: a hop picked by weight among the hops with a weight,
+// then the other hops in the order of the chain. Chains without weights are tried in their order.
+func orderFallbackHops(hops []schemas.FallbackHop) []schemas.FallbackHop {
+	totalWeight := 0.0
+	for _, hop := range hops {
+		totalWeight += hop.Weight
+	}
+	if totalWeight == 0 {
+		return hops
+	}
+	first := -1
+	randomValue := rand.Float64() * totalWeight
+	currentWeight := 0.0
+	for i, hop := range hops {
+		if hop.Weight == 0 {
+			continue
+		}
+		currentWeight += hop.Weight
+		first = i
+		if randomValue <= currentWeight {
+			break
+		}
+	}
+	ordered := make([]schemas.FallbackHop, 0, len(hops))
+	ordered = append(ordered, hops[first])
+	ordered = append(ordered, hops[:first]...)
+	return append(ordered, hops[first+1:]...)
+}
+
+// applyFallbackChain sends the request through the fallback chain of its requested model: the first hop replaces the
+// provider and model of the request and the next hops replace its fallbacks. Returns the request itself when no chain
+// matches, otherwise a copy of the request and the context carrying the hops.
+func (bifrost *Bifrost) applyFallbackChain(ctx context.Context, req *schemas.BifrostRequest) (context.Context, *schemas.BifrostRequest) {
+	set := bifrost.fallbackChains.Load()
+	if set == nil {
+		return ctx, req
+	}
+	provider, model, _ := req.GetRequestFields()
+	chain := set.match(provider, model)
+	if chain == nil {
+		return ctx, req
+	}
+
+	hops := bifrost.deprioritizeBurningHops(orderFallbackHops(chain.Hops), chain.Alias)
+	chained := copyRequest(req)
+	chained.SetProvider(hops[0].Provider)
+	chained.SetModel(hops[0].Model)
+	fallbacks := make([]schemas.Fallback, 0, len(hops)-1)
+	for _, hop := range hops[1:] {
+		fallbacks = append(fallbacks, schemas.Fallback{Provider: hop.Provider, Model: hop.Model})
+	}
+	chained.SetFallbacks(fallbacks)
+
+	bifrost.logger.Debug(fmt.Sprintf("sending request for %s/%s through fallback chain %s starting at %s/%s", provider, model, chain.Alias, hops[0].Provider, hops[0].Model))
+	ctx = context.WithValue(ctx, schemas.BifrostContextKeyFallbackChain, chain.Alias)
+	ctx = context.WithValue(ctx, fallbackChainHopsContextKey, hops)
+	return ctx, &chained
+}
+
+// applyFallbackHop applies the overrides of the hop of the fallback chain the request is sent to: its parameters are
+// set on a copy of the request and its retries are carried by the context. Returns the request itself when the request
+// is not sent through a chain or the hop has no parameters.
+func applyFallbackHop(ctx context.Context, req *schemas.BifrostRequest) (context.Context, *schemas.BifrostRequest, *schemas.BifrostError) {
+	hops, ok := ctx.Value(fallbackChainHopsContextKey).([]schemas.FallbackHop)
+	if !ok {
+		return ctx, req, nil
+	}
+	index, _ := ctx.Value(schemas.BifrostContextKeyFallbackIndex).(int)
+	if index < 0 || index >= len(hops) {
+		return ctx, req, nil
+	}
+	hop := hops[index]
+	// Requests rewritten since, e.g. responses sent as chat requests, keep their own settings
+	if provider, model, _ := req.GetRequestFields(); provider != hop.Provider || model != hop.Model {
+		return ctx, req, nil
+	}
+
+	if hop.MaxRetries != nil {
+		ctx = context.WithValue(ctx, fallbackHopMaxRetriesContextKey, *hop.MaxRetries)
+	}
+	actions := hop.ParamActions()
+	if len(actions) == 0 {
+		return ctx, req, nil
+	}
+	// Hop parameters are not a policy of the client values, they are set without recording violations
+	ruleActions := make([]ruleAction, 0, len(actions))
+	for _, action := range actions {
+		ruleActions = append(ruleActions, ruleAction{action: action})
+	}
+	overridden := copyRequest(req)
+	if _, err := transformRequestParams(&overridden, ruleActions); err != nil {
+		return ctx, nil, &schemas.BifrostError{
+			IsBifrostError: true,
+			StatusCode:     schemas.Ptr(http.StatusBadRequest),
+			Type:           schemas.Ptr("fallback_hop_failed"),
+			Error: &schemas.ErrorField{
+				Message: fmt.Sprintf("failed to apply the parameters of fallback hop %s/%s: %v", hop.Provider, hop.Model, err),
+				Error:   err,
+			},
+		}
+	}
+	return ctx, &overridden, nil
+}
+
+// GetFallbackChain returns the fallback chain the requests for the provider and model are sent through, nil when they
+// are sent with their own fallbacks
+func (bifrost *Bifrost) GetFallbackChain(provider schemas.ModelProvider, model string) *schemas.FallbackChain {
+	set := bifrost.fallbackChains.Load()
+	if set == nil {
+		return nil
+	}
+	return set.match(provider, model)
+}
@@ -0,0 +1,74 @@
+package bifrost
+
+import (
+	"context"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestFallbackChains_RewriteRequestAndHops tests that a request to an alias is sent through its chain, with the
+// parameters and retries of each hop
+func TestFallbackChains_RewriteRequestAndHops(t *testing.T) {
+	bifrost := &Bifrost{logger: NewDefaultLogger(schemas.LogLevelError)}
+	err := bifrost.UpdateFallbackChains([]schemas.FallbackChain{{
+		Alias: "gpt-4o",
+		Hops: []schemas.FallbackHop{
+			{Provider: schemas.OpenAI, Model: "gpt-4o", MaxRetries: schemas.Ptr(0)},
+			{Provider: schemas.Azure, Model: "gpt-4o-mini", Params: map[string]interface{}{"max_completion_tokens": 256}},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("expected the chain to be valid, got %v", err)
+	}
+
+	req := transformChatRequest()
+	req.ChatRequest.Fallbacks = []schemas.Fallback{{Provider: schemas.Anthropic, Model: "claude-sonnet-4-5"}}
+	ctx, chained := bifrost.applyFallbackChain(context.Background(), req)
+	if chained == req || len(chained.ChatRequest.Fallbacks) != 1 || chained.ChatRequest.Fallbacks[0].Provider != schemas.Azure {
+		t.Fatalf("expected a copy of the request with the fallbacks of the chain, got %+v", chained.ChatRequest.Fallbacks)
+	}
+	if alias, _ := ctx.Value(schemas.BifrostContextKeyFallbackChain).(string); alias != "gpt-4o" || len(req.ChatRequest.Fallbacks) != 1 || req.ChatRequest.Fallbacks[0].Provider != schemas.Anthropic {
+		t.Fatalf("expected the chain in the context and the request of the caller unchanged, got %q", alias)
+	}
+
+	primaryCtx, primary, hopErr := applyFallbackHop(context.WithValue(ctx, schemas.BifrostContextKeyFallbackIndex, 0), chained)
+	if hopErr != nil || primary != chained || primaryCtx.Value(fallbackHopMaxRetriesContextKey) != 0 {
+		t.Fatalf("expected the primary hop to be sent as it is without retries, got %v", hopErr)
+	}
+	fallbackReq := copyRequest(chained)
+	fallbackReq.SetProvider(schemas.Azure)
+	fallbackReq.SetModel("gpt-4o-mini")
+	_, hopped, hopErr := applyFallbackHop(context.WithValue(ctx, schemas.BifrostContextKeyFallbackIndex, 1), &fallbackReq)
+	if hopErr != nil || hopped.ChatRequest.Params.MaxCompletionTokens == nil || *hopped.ChatRequest.Params.MaxCompletionTokens != 256 {
+		t.Fatalf("expected the parameters of the fallback hop to be set, got %+v %v", hopped.ChatRequest.Params, hopErr)
+	}
+	if chained.ChatRequest.Params.MaxCompletionTokens != nil {
+		t.Errorf("expected the parameters of the hop to be set on a copy of the request")
+	}
+
+	other := transformChatRequest()
+	other.ChatRequest.Model = "gpt-4.1"
+	if _, same := bifrost.applyFallbackChain(context.Background(), other); same != other {
+		t.Errorf("expected requests to other models to be sent as they are")
+	}
+}
+
+// TestOrderFallbackHops tests that the first hop is picked among the weighted hops and the others keep their order
+func TestOrderFallbackHops(t *testing.T) {
+	hops := []schemas.FallbackHop{
+		{Provider: schemas.OpenAI, Model: "a"},
+		{Provider: schemas.Azure, Model: "b", Weight: 1},
+		{Provider: schemas.Anthropic, Model: "c"},
+	}
+	ordered := orderFallbackHops(hops)
+	if len(ordered) != 3 || ordered[0].Model != "b" || ordered[1].Model != "a" || ordered[2].Model != "c" {
+		t.Errorf("expected the only weighted hop first and the others in order, got %+v", ordered)
+	}
+	if ordered := orderFallbackHops(hops[:1]); ordered[0].Model != "a" {
+		t.Errorf("expected chains without weights in their order, got %+v", ordered)
+	}
+	if err := schemas.ValidateFallbackChains([]schemas.FallbackChain{{Alias: "x", Hops: []schemas.FallbackHop{{Provider: schemas.OpenAI}}}}); err == nil {
+		t.Errorf("expected a hop without model to be rejected")
+	}
+}
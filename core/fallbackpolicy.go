@@ -0,0 +1,97 @@
+package bifrost
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// contentFilterErrorCodes are the error codes and types of the providers for requests rejected by their content filter
+var contentFilterErrorCodes = []string{
+	"content_filter",
+	"content_policy_violation",
+	"responsible_ai_policy_violation",
+}
+
+// contentFilterPatterns are the substrings of the error messages of the providers for requests rejected by their
+// content filter or safety system
+var contentFilterPatterns = []string{
+	"content filter",
+	"content management policy",
+	"content_policy",
+	"safety system",
+}
+
+// isContentFilterError reports whether the provider rejected the request with its content filter
+func isContentFilterError(err *schemas.BifrostError) bool {
+	message, errorType, errorCode := errorFields(err)
+	if slices.Contains(contentFilterErrorCodes, errorCode) || slices.Contains(contentFilterErrorCodes, errorType) {
+		return true
+	}
+	if message == "" {
+		return false
+	}
+	for _, pattern := range contentFilterPatterns {
+		if strings.Contains(message, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFallbackRule returns the first rule of the policy matching the error, or nil if none matches.
+// Errors raised by Bifrost itself, other than connection errors, and cancellations never match.
+func matchFallbackRule(policy *schemas.FallbackPolicy, err *schemas.BifrostError) *schemas.FallbackRule {
+	if err.Error != nil && err.Error.Type != nil && *err.Error.Type == schemas.RequestCancelled {
+		return nil
+	}
+	connectionError := isConnectionError(err)
+	if err.IsBifrostError && !connectionError {
+		return nil
+	}
+
+	message, errorType, errorCode := errorFields(err)
+	canonicalCode := errorCodeOf(err)
+	for i, rule := range policy.Rules {
+		if rule.ConnectionErrors && connectionError {
+			return &policy.Rules[i]
+		}
+		if rule.ContentFilter && isContentFilterError(err) {
+			return &policy.Rules[i]
+		}
+		if err.StatusCode != nil && slices.Contains(rule.StatusCodes, *err.StatusCode) {
+			return &policy.Rules[i]
+		}
+		if (errorCode != "" && slices.Contains(rule.ErrorCodes, errorCode)) || (errorType != "" && slices.Contains(rule.ErrorCodes, errorType)) || slices.Contains(rule.ErrorCodes, string(canonicalCode)) {
+			return &policy.Rules[i]
+		}
+		for _, pattern := range rule.MessagePatterns {
+			if message != "" && strings.Contains(message, strings.ToLower(pattern)) {
+				return &policy.Rules[i]
+			}
+		}
+	}
+	return nil
+}
+
+// fallbackAllowed reports whether the fallback policy of the provider lets the error be tried on the fallbacks.
+// Providers without a policy and errors matching no rule are tried on the fallbacks.
+func (bifrost *Bifrost) fallbackAllowed(provider schemas.ModelProvider, err *schemas.BifrostError) bool {
+	if bifrost.account == nil {
+		return true
+	}
+	config, configErr := bifrost.account.GetConfigForProvider(provider)
+	if configErr != nil || config == nil || config.NetworkConfig.FallbackPolicy == nil {
+		return true
+	}
+	rule := matchFallbackRule(config.NetworkConfig.FallbackPolicy, err)
+	if rule == nil {
+		return true
+	}
+	if !rule.Fallback {
+		bifrost.logger.Debug(fmt.Sprintf("error of provider %s matches fallback rule %s, not trying fallbacks", provider, rule.Name))
+	}
+	return rule.Fallback
+}
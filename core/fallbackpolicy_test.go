@@ -0,0 +1,46 @@
+package bifrost
+
+import (
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestMatchFallbackRule tests that content filter rejections, validation errors and unmatched errors get the fallback
+// behavior of their rule
+func TestMatchFallbackRule(t *testing.T) {
+	policy := &schemas.FallbackPolicy{Rules: []schemas.FallbackRule{
+		{Name: "content_filter", ContentFilter: true, Fallback: true},
+		{Name: "validation", StatusCodes: []int{400}, Fallback: false},
+	}}
+
+	contentFilter := &schemas.BifrostError{
+		StatusCode: schemas.Ptr(400),
+		Error: &schemas.ErrorField{
+			Code:    schemas.Ptr("content_filter"),
+			Message: "The response was filtered due to the prompt triggering the content management policy",
+		},
+	}
+	if rule := matchFallbackRule(policy, contentFilter); rule == nil || !rule.Fallback {
+		t.Errorf("expected content filter rejections to be tried on the fallbacks, got %+v", rule)
+	}
+
+	validation := &schemas.BifrostError{StatusCode: schemas.Ptr(400), Error: &schemas.ErrorField{Message: "invalid value for temperature"}}
+	if rule := matchFallbackRule(policy, validation); rule == nil || rule.Name != "validation" || rule.Fallback {
+		t.Errorf("expected validation errors to fail fast, got %+v", rule)
+	}
+
+	rateLimited := &schemas.BifrostError{StatusCode: schemas.Ptr(429), Error: &schemas.ErrorField{Message: "rate limit exceeded"}}
+	if rule := matchFallbackRule(policy, rateLimited); rule != nil {
+		t.Errorf("expected errors matching no rule to keep the default behavior, got %+v", rule)
+	}
+
+	internal := &schemas.BifrostError{IsBifrostError: true, StatusCode: schemas.Ptr(400), Error: &schemas.ErrorField{Message: "blocked"}}
+	if rule := matchFallbackRule(policy, internal); rule != nil {
+		t.Errorf("expected errors raised by bifrost to never match, got %+v", rule)
+	}
+
+	if err := (&schemas.FallbackPolicy{Rules: []schemas.FallbackRule{{Name: "empty", Fallback: true}}}).Validate(); err == nil {
+		t.Errorf("expected a rule matching no error to be rejected")
+	}
+}
@@ -0,0 +1,96 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// firstChunkTimeout returns the longest wait for the first chunk of a stream of the provider, 0 when streams only
+// have the request timeout
+func firstChunkTimeout(config *schemas.ProviderConfig) time.Duration {
+	if config.NetworkConfig.FirstChunkTimeoutInSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(config.NetworkConfig.FirstChunkTimeoutInSeconds) * time.Second
+}
+
+// awaitFirstChunk waits for the first chunk of a provider stream. When it comes in time, the stream is forwarded as it
+// is, however long it lasts. Otherwise the provider stream is cancelled and an error is returned, so that the request
+// falls back while the client has not received anything yet.
+func awaitFirstChunk(
+	ctx context.Context,
+	cancelProvider context.CancelFunc,
+	stream chan *schemas.BifrostStream,
+	timeout time.Duration,
+	requestType schemas.RequestType,
+	provider schemas.ModelProvider,
+	model string,
+) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case first, ok := <-stream:
+		out := make(chan *schemas.BifrostStream, cap(stream))
+		go func() {
+			defer cancelProvider()
+			defer close(out)
+			if !ok {
+				return
+			}
+			for chunk := first; ; {
+				select {
+				case out <- chunk:
+				case <-ctx.Done():
+					schemas.ReleaseBifrostStream(chunk)
+				}
+				if chunk, ok = <-stream; !ok {
+					return
+				}
+			}
+		}()
+		return out, nil
+	case <-timer.C:
+		cancelProvider()
+		go drainStream(stream)
+		return nil, &schemas.BifrostError{
+			IsBifrostError: false,
+			Error: &schemas.ErrorField{
+				Type:    schemas.Ptr(schemas.FirstChunkTimedOut),
+				Message: schemas.ErrProviderFirstChunkTimedOut,
+				Error:   fmt.Errorf("no chunk received within %s", timeout),
+			},
+			ExtraFields: schemas.BifrostErrorExtraFields{
+				RequestType:    requestType,
+				Provider:       provider,
+				ModelRequested: model,
+			},
+		}
+	case <-ctx.Done():
+		cancelProvider()
+		go drainStream(stream)
+		return nil, &schemas.BifrostError{
+			IsBifrostError: false,
+			Error: &schemas.ErrorField{
+				Type:    schemas.Ptr(schemas.RequestCancelled),
+				Message: schemas.ErrRequestCancelled,
+				Error:   ctx.Err(),
+			},
+			ExtraFields: schemas.BifrostErrorExtraFields{
+				RequestType:    requestType,
+				Provider:       provider,
+				ModelRequested: model,
+			},
+		}
+	}
+}
+
+// drainStream releases the chunks a cancelled provider stream sends before noticing the cancellation
+func drainStream(stream chan *schemas.BifrostStream) {
+	for chunk := range stream {
+		schemas.ReleaseBifrostStream(chunk)
+	}
+}
@@ -0,0 +1,64 @@
+package bifrost
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestAwaitFirstChunk tests that slow streams are abandoned before their first chunk and timely ones forwarded whole
+func TestAwaitFirstChunk(t *testing.T) {
+	t.Run("first chunk in time", func(t *testing.T) {
+		stream := make(chan *schemas.BifrostStream, 2)
+		stream <- &schemas.BifrostStream{}
+		stream <- &schemas.BifrostStream{}
+		close(stream)
+		cancelled := make(chan struct{})
+		out, bifrostError := awaitFirstChunk(context.Background(), func() { close(cancelled) }, stream, time.Second, schemas.ChatCompletionStreamRequest, schemas.OpenAI, "gpt-4o")
+		if bifrostError != nil {
+			t.Fatalf("unexpected error: %v", bifrostError.Error.Message)
+		}
+		count := 0
+		for range out {
+			count++
+		}
+		if count != 2 {
+			t.Errorf("expected 2 chunks, got %d", count)
+		}
+		<-cancelled
+	})
+
+	t.Run("no first chunk", func(t *testing.T) {
+		stream := make(chan *schemas.BifrostStream)
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-ctx.Done()
+			close(stream)
+		}()
+		_, bifrostError := awaitFirstChunk(context.Background(), cancel, stream, 10*time.Millisecond, schemas.ChatCompletionStreamRequest, schemas.OpenAI, "gpt-4o")
+		if bifrostError == nil || bifrostError.Error.Type == nil || *bifrostError.Error.Type != schemas.FirstChunkTimedOut {
+			t.Fatalf("expected a first chunk timeout, got %+v", bifrostError)
+		}
+		policy := &schemas.FallbackPolicy{Rules: []schemas.FallbackRule{{Name: "slow", ErrorCodes: []string{schemas.FirstChunkTimedOut}}}}
+		if matchFallbackRule(policy, bifrostError) == nil {
+			t.Error("expected the timeout to match its error code in fallback policies")
+		}
+		if ctx.Err() == nil {
+			t.Error("expected the provider stream to be cancelled")
+		}
+	})
+}
+
+// TestFirstChunkTimeout tests that the first chunk timeout is only applied when configured
+func TestFirstChunkTimeout(t *testing.T) {
+	config := &schemas.ProviderConfig{}
+	if got := firstChunkTimeout(config); got != 0 {
+		t.Errorf("expected no timeout, got %s", got)
+	}
+	config.NetworkConfig.FirstChunkTimeoutInSeconds = 5
+	if got := firstChunkTimeout(config); got != 5*time.Second {
+		t.Errorf("expected 5s, got %s", got)
+	}
+}
@@ -0,0 +1,215 @@
+package bifrost
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// idempotencySweepInterval is the minimum interval between two sweeps of expired idempotent results
+const idempotencySweepInterval = time.Minute
+
+// idempotentCall is a request sent with an idempotency key, in flight until done is closed
+type idempotentCall struct {
+	fingerprint string        // Hash of the request, duplicates must send the same request
+	done        chan struct{} // Closed when the request completed, response and err are set before
+	response    []byte        // JSON of the response, nil if the request failed or its response cannot be replayed
+	err         *schemas.BifrostError
+	expiresAt   time.Time // Zero while in flight
+}
+
+// idempotencyStore holds the requests in flight and the recent results per idempotency key
+type idempotencyStore struct {
+	mu        sync.Mutex
+	calls     map[string]*idempotentCall
+	lastSweep time.Time
+}
+
+// newIdempotencyStore creates a new, empty idempotency store
+func newIdempotencyStore() *idempotencyStore {
+	return &idempotencyStore{
+		calls:     make(map[string]*idempotentCall),
+		lastSweep: time.Now(),
+	}
+}
+
+// begin returns the call registered for the key and false, or registers a new call for the request and
+// returns it with true when the key is unknown or its result expired. The caller owning a new call must complete it.
+func (s *idempotencyStore) begin(key string, fingerprint string) (*idempotentCall, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= idempotencySweepInterval {
+		s.sweep(now)
+	}
+	if call, ok := s.calls[key]; ok && (call.expiresAt.IsZero() || now.Before(call.expiresAt)) {
+		return call, false
+	}
+	call := &idempotentCall{fingerprint: fingerprint, done: make(chan struct{})}
+	s.calls[key] = call
+	return call, true
+}
+
+// complete sets the result of the call and wakes up its duplicates. Successful results are kept for the ttl,
+// unless the store holds more than capacity calls; failed ones are forgotten so that a retry is sent again.
+// The response is kept as JSON, so that every duplicate gets a copy of its own.
+func (s *idempotencyStore) complete(key string, call *idempotentCall, response *schemas.BifrostResponse, err *schemas.BifrostError, ttl time.Duration, capacity int) {
+	var data []byte
+	if err == nil && response != nil {
+		data, _ = json.Marshal(response)
+	}
+
+	s.mu.Lock()
+	call.response, call.err = data, err
+	if err != nil || data == nil || len(s.calls) > capacity {
+		if s.calls[key] == call {
+			delete(s.calls, key)
+		}
+	} else {
+		call.expiresAt = time.Now().Add(ttl)
+	}
+	s.mu.Unlock()
+	close(call.done)
+}
+
+// sweep removes expired results, the caller must hold the lock
+func (s *idempotencyStore) sweep(now time.Time) {
+	for key, call := range s.calls {
+		if !call.expiresAt.IsZero() && !now.Before(call.expiresAt) {
+			delete(s.calls, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+// idempotencyScope returns the caller an idempotency key belongs to: its virtual key, or its direct provider key
+func idempotencyScope(ctx context.Context) string {
+	if virtualKey, ok := ctx.Value(schemas.BifrostContextKeyVirtualKey).(string); ok && virtualKey != "" {
+		return "vk:" + virtualKey
+	}
+	if directKey, ok := ctx.Value(schemas.BifrostContextKeyDirectKey).(schemas.Key); ok && directKey.Value != "" {
+		hash := sha256.Sum256([]byte(directKey.Value))
+		return "dk:" + hex.EncodeToString(hash[:])
+	}
+	return ""
+}
+
+// requestFingerprint returns a hash of the request, or an empty string if it cannot be serialized
+func requestFingerprint(req *schemas.BifrostRequest) string {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return ""
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// handleIdempotentRequest sends the request with handle, once per idempotency key of the context.
+// Duplicates sent while the request is in flight wait for its result, duplicates sent after it succeeded get its
+// response replayed. If the request owning the key is cancelled by its caller, a waiting duplicate sends it again.
+// Requests without an idempotency key are sent as they are. The request is released by handle, or here when a
+// duplicate does not send it.
+func (bifrost *Bifrost) handleIdempotentRequest(ctx context.Context, req *schemas.BifrostRequest, handle func() (*schemas.BifrostResponse, *schemas.BifrostError)) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	if ctx == nil {
+		return handle()
+	}
+	idempotencyKey, ok := ctx.Value(schemas.BifrostContextKeyIdempotencyKey).(string)
+	if !ok || idempotencyKey == "" {
+		return handle()
+	}
+
+	provider, model, _ := req.GetRequestFields()
+	requestType := req.RequestType
+	key := idempotencyScope(ctx) + "\x00" + string(requestType) + "\x00" + idempotencyKey
+	// The fingerprint is computed before handle, which releases the request
+	fingerprint := requestFingerprint(req)
+	config := bifrost.idempotency.Load()
+
+	handled := false
+	defer func() {
+		if !handled {
+			bifrost.releaseBifrostRequest(req)
+		}
+	}()
+
+	for {
+		call, owner := bifrost.idempotencyStore.begin(key, fingerprint)
+		if owner {
+			handled = true
+			response, err := handle()
+			bifrost.idempotencyStore.complete(key, call, response, err, config.TTL(), config.Capacity())
+			return response, err
+		}
+
+		if call.fingerprint != fingerprint {
+			return nil, &schemas.BifrostError{
+				IsBifrostError: true,
+				StatusCode:     schemas.Ptr(422),
+				Type:           schemas.Ptr("idempotency_key_reused"),
+				Error: &schemas.ErrorField{
+					Message: fmt.Sprintf("idempotency key %s was already used for a different request", idempotencyKey),
+				},
+				AllowFallbacks: schemas.Ptr(false),
+				ExtraFields: schemas.BifrostErrorExtraFields{
+					RequestType:    requestType,
+					Provider:       provider,
+					ModelRequested: model,
+				},
+			}
+		}
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, &schemas.BifrostError{
+				IsBifrostError: false,
+				Error: &schemas.ErrorField{
+					Type:    schemas.Ptr(schemas.RequestCancelled),
+					Message: schemas.ErrRequestCancelled,
+					Error:   ctx.Err(),
+				},
+				ExtraFields: schemas.BifrostErrorExtraFields{
+					RequestType:    requestType,
+					Provider:       provider,
+					ModelRequested: model,
+				},
+			}
+		}
+
+		if call.err != nil {
+			if call.err.Error != nil && call.err.Error.Type != nil && *call.err.Error.Type == schemas.RequestCancelled {
+				continue
+			}
+			return nil, call.err
+		}
+		replay := replayResponse(call.response)
+		if replay == nil {
+			// The response of the request owning the key cannot be replayed, the duplicate sends it again
+			continue
+		}
+		bifrost.logger.Debug(fmt.Sprintf("replaying response of idempotency key %s", idempotencyKey))
+		return replay, nil
+	}
+}
+
+// replayResponse decodes a copy of the response of an idempotent request marked as replayed, nil if there is
+// none to replay. Every duplicate gets a copy of its own, so that changes made by the caller of a duplicate cannot
+// alter the responses of the others.
+func replayResponse(data []byte) *schemas.BifrostResponse {
+	if data == nil {
+		return nil
+	}
+	var replay schemas.BifrostResponse
+	if err := json.Unmarshal(data, &replay); err != nil {
+		return nil
+	}
+	replay.GetExtraFields().IdempotentReplay = true
+	return &replay
+}
@@ -0,0 +1,135 @@
+package bifrost
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+func newIdempotencyTestRequest(content string) *schemas.BifrostRequest {
+	return &schemas.BifrostRequest{
+		RequestType: schemas.ChatCompletionRequest,
+		ChatRequest: &schemas.BifrostChatRequest{
+			Provider: schemas.OpenAI,
+			Model:    "gpt-4o",
+			Input:    []schemas.ChatMessage{{Role: schemas.ChatMessageRoleUser, Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr(content)}}},
+		},
+	}
+}
+
+// Test handleIdempotentRequest - concurrent duplicates share one call and later ones get its response replayed
+func TestHandleIdempotentRequest_CoalescesAndReplays(t *testing.T) {
+	bifrost := &Bifrost{idempotencyStore: newIdempotencyStore(), logger: NewDefaultLogger(schemas.LogLevelError)}
+	ctx := context.WithValue(context.Background(), schemas.BifrostContextKeyIdempotencyKey, "retry-storm")
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	handle := func() (*schemas.BifrostResponse, *schemas.BifrostError) {
+		calls.Add(1)
+		<-release
+		return &schemas.BifrostResponse{ChatResponse: &schemas.BifrostChatResponse{ID: "chatcmpl-1"}}, nil
+	}
+
+	var wg sync.WaitGroup
+	responses := make([]*schemas.BifrostResponse, 5)
+	for i := range responses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i], _ = bifrost.handleIdempotentRequest(ctx, newIdempotencyTestRequest("hello"), handle)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Fatalf("Expected 1 call for concurrent duplicates, got %d", calls.Load())
+	}
+	replays := 0
+	for _, response := range responses {
+		if response == nil || response.ChatResponse.ID != "chatcmpl-1" {
+			t.Fatalf("Expected every duplicate to get the response, got %+v", response)
+		}
+		if response.ChatResponse.ExtraFields.IdempotentReplay {
+			replays++
+		}
+	}
+	if replays != len(responses)-1 {
+		t.Errorf("Expected %d replayed responses, got %d", len(responses)-1, replays)
+	}
+
+	response, err := bifrost.handleIdempotentRequest(ctx, newIdempotencyTestRequest("hello"), handle)
+	if err != nil || calls.Load() != 1 || !response.ChatResponse.ExtraFields.IdempotentReplay {
+		t.Errorf("Expected the completed response to be replayed, got %+v, %v after %d calls", response, err, calls.Load())
+	}
+}
+
+// Test handleIdempotentRequest - a key reused for a different request is rejected
+func TestHandleIdempotentRequest_DifferentRequest(t *testing.T) {
+	bifrost := &Bifrost{idempotencyStore: newIdempotencyStore(), logger: NewDefaultLogger(schemas.LogLevelError)}
+	ctx := context.WithValue(context.Background(), schemas.BifrostContextKeyIdempotencyKey, "reused")
+	handle := func() (*schemas.BifrostResponse, *schemas.BifrostError) {
+		return &schemas.BifrostResponse{ChatResponse: &schemas.BifrostChatResponse{}}, nil
+	}
+
+	if _, err := bifrost.handleIdempotentRequest(ctx, newIdempotencyTestRequest("hello"), handle); err != nil {
+		t.Fatalf("Expected the first request to succeed, got %v", err)
+	}
+	_, err := bifrost.handleIdempotentRequest(ctx, newIdempotencyTestRequest("goodbye"), handle)
+	if err == nil || err.StatusCode == nil || *err.StatusCode != 422 {
+		t.Errorf("Expected a 422 error for a different request, got %+v", err)
+	}
+}
+
+// Test handleIdempotentRequest - failed requests are not replayed
+func TestHandleIdempotentRequest_FailuresAreNotKept(t *testing.T) {
+	bifrost := &Bifrost{idempotencyStore: newIdempotencyStore(), logger: NewDefaultLogger(schemas.LogLevelError)}
+	ctx := context.WithValue(context.Background(), schemas.BifrostContextKeyIdempotencyKey, "failing")
+
+	calls := 0
+	handle := func() (*schemas.BifrostResponse, *schemas.BifrostError) {
+		calls++
+		return nil, createBifrostError("service unavailable", Ptr(503), nil, false)
+	}
+	for range 2 {
+		_, _ = bifrost.handleIdempotentRequest(ctx, newIdempotencyTestRequest("hello"), handle)
+	}
+	if calls != 2 {
+		t.Errorf("Expected the retry of a failed request to be sent, got %d calls", calls)
+	}
+}
+
+// Test handleIdempotentRequest - every duplicate gets a copy of the response, unaffected by changes made to the
+// response of the request or of the other duplicates, and the requests of the duplicates are released
+func TestHandleIdempotentRequest_ReplaysCopies(t *testing.T) {
+	bifrost := &Bifrost{idempotencyStore: newIdempotencyStore(), logger: NewDefaultLogger(schemas.LogLevelError)}
+	ctx := context.WithValue(context.Background(), schemas.BifrostContextKeyIdempotencyKey, "copies")
+	handle := func() (*schemas.BifrostResponse, *schemas.BifrostError) {
+		return &schemas.BifrostResponse{ChatResponse: &schemas.BifrostChatResponse{
+			ID:          "chatcmpl-1",
+			ExtraFields: schemas.BifrostResponseExtraFields{Provider: schemas.OpenAI},
+		}}, nil
+	}
+
+	original, _ := bifrost.handleIdempotentRequest(ctx, newIdempotencyTestRequest("hello"), handle)
+	original.ChatResponse.ID = "changed"
+	first, _ := bifrost.handleIdempotentRequest(ctx, newIdempotencyTestRequest("hello"), handle)
+	if first == nil || first.ChatResponse.ID != "chatcmpl-1" || !first.ChatResponse.ExtraFields.IdempotentReplay {
+		t.Fatalf("Expected the response to be replayed, got %+v", first)
+	}
+	first.ChatResponse.ExtraFields.Provider = schemas.Anthropic
+
+	req := newIdempotencyTestRequest("hello")
+	second, _ := bifrost.handleIdempotentRequest(ctx, req, handle)
+	if req.ChatRequest != nil {
+		t.Error("Expected the request of the duplicate to be released")
+	}
+	if second == first || second.ChatResponse.ID != "chatcmpl-1" || second.ChatResponse.ExtraFields.Provider != schemas.OpenAI {
+		t.Errorf("Expected a copy of the response, got %+v", second.ChatResponse)
+	}
+}
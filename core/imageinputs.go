@@ -0,0 +1,322 @@
+package bifrost
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // registers the GIF decoder for image.Decode
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+const (
+	defaultMaxImageBytes       = 20 << 20 // 20 MB, the inline image limit of OpenAI and Gemini
+	defaultImageFetchTimeout   = 10 * time.Second
+	maxImageFetchRedirects     = 3
+	maxImageDownscaleSteps     = 5
+	transcodedImageJPEGQuality = 85
+)
+
+// imageInputLimits describes the image inputs a provider accepts
+type imageInputLimits struct {
+	acceptsURLs bool     // whether images can be sent as http(s) URLs
+	maxBytes    int      // largest image the provider accepts
+	mediaTypes  []string // image formats the provider accepts
+}
+
+var commonImageMediaTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+// imageInputLimitsForProvider returns the image requirements of a provider type
+func imageInputLimitsForProvider(provider schemas.ModelProvider) imageInputLimits {
+	switch provider {
+	case schemas.Anthropic:
+		return imageInputLimits{acceptsURLs: true, maxBytes: 5 << 20, mediaTypes: commonImageMediaTypes}
+	case schemas.Bedrock:
+		return imageInputLimits{acceptsURLs: false, maxBytes: 3_750_000, mediaTypes: commonImageMediaTypes}
+	case schemas.Gemini, schemas.Vertex:
+		return imageInputLimits{acceptsURLs: false, maxBytes: defaultMaxImageBytes, mediaTypes: []string{"image/jpeg", "image/png", "image/webp", "image/heic", "image/heif"}}
+	default:
+		return imageInputLimits{acceptsURLs: true, maxBytes: defaultMaxImageBytes, mediaTypes: commonImageMediaTypes}
+	}
+}
+
+// imageFetchClients fetch image URLs, the first one only connects to public addresses
+var imageFetchClients = [2]*http.Client{newImageFetchClient(false), newImageFetchClient(true)}
+
+// newImageFetchClient creates the HTTP client fetching image URLs. Unless private networks are allowed, connections
+// to loopback, private and link-local addresses are refused once the host is resolved, so that image URLs can't
+// be used to reach internal services.
+func newImageFetchClient(allowPrivateNetworks bool) *http.Client {
+	dialer := &net.Dialer{Timeout: defaultImageFetchTimeout}
+	if !allowPrivateNetworks {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+				return fmt.Errorf("image URL resolves to the non-public address %s", host)
+			}
+			return nil
+		}
+	}
+	return &http.Client{
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxImageFetchRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxImageFetchRedirects)
+			}
+			return nil
+		},
+	}
+}
+
+// normalizeImageInputs applies the image input config to the images of a chat request, once the plugins have chosen
+// the provider it is sent to. Image URLs are fetched and inlined for providers that don't accept URLs, and images are
+// transcoded to a format and size the provider accepts. The messages are copied before they are modified, so the
+// original request keeps its images for fallbacks. Returns a 400 error when an image can't be fetched or is too large.
+func (bifrost *Bifrost) normalizeImageInputs(ctx context.Context, req *schemas.BifrostRequest) *schemas.BifrostError {
+	config := bifrost.imageInputs.Load()
+	if config == nil || (!config.FetchURLs && !config.Transcode) || req.ChatRequest == nil {
+		return nil
+	}
+
+	provider := req.ChatRequest.Provider
+	limits := imageInputLimitsForProvider(bifrost.baseProviderType(provider))
+	fetch := config.FetchURLs && (slices.Contains(config.FetchProviders, provider) || len(config.FetchProviders) == 0 && !limits.acceptsURLs)
+
+	var input []schemas.ChatMessage
+	for i, msg := range req.ChatRequest.Input {
+		if msg.Content == nil {
+			continue
+		}
+		var blocks []schemas.ChatContentBlock
+		for j, block := range msg.Content.ContentBlocks {
+			if block.ImageURLStruct == nil {
+				continue
+			}
+			imageURL, err := normalizeImageURL(ctx, block.ImageURLStruct.URL, config, limits, fetch)
+			if err != nil {
+				return &schemas.BifrostError{
+					IsBifrostError: true,
+					StatusCode:     schemas.Ptr(http.StatusBadRequest),
+					Type:           schemas.Ptr("invalid_image_input"),
+					Error: &schemas.ErrorField{
+						Message: err.Error(),
+						Error:   err,
+					},
+				}
+			}
+			if imageURL == block.ImageURLStruct.URL {
+				continue
+			}
+			if blocks == nil {
+				blocks = append([]schemas.ChatContentBlock(nil), msg.Content.ContentBlocks...)
+			}
+			imageURLStruct := *block.ImageURLStruct
+			imageURLStruct.URL = imageURL
+			blocks[j].ImageURLStruct = &imageURLStruct
+		}
+		if blocks == nil {
+			continue
+		}
+		if input == nil {
+			input = append([]schemas.ChatMessage(nil), req.ChatRequest.Input...)
+		}
+		content := *msg.Content
+		content.ContentBlocks = blocks
+		input[i].Content = &content
+	}
+
+	if input != nil {
+		chatReq := *req.ChatRequest
+		chatReq.Input = input
+		req.ChatRequest = &chatReq
+	}
+	return nil
+}
+
+// baseProviderType returns the provider type of custom providers, and the provider itself otherwise
+func (bifrost *Bifrost) baseProviderType(provider schemas.ModelProvider) schemas.ModelProvider {
+	config, err := bifrost.account.GetConfigForProvider(provider)
+	if err == nil && config != nil && config.CustomProviderConfig != nil {
+		return config.CustomProviderConfig.BaseProviderType
+	}
+	return provider
+}
+
+// normalizeImageURL returns the image to send to the provider: the fetched image as a data URL when fetch is set,
+// transcoded when transcoding is enabled. Other images are returned as they are.
+func normalizeImageURL(ctx context.Context, imageURL string, config *schemas.ImageInputConfig, limits imageInputLimits, fetch bool) (string, error) {
+	maxBytes := defaultMaxImageBytes
+	if config.MaxImageBytes > 0 {
+		maxBytes = int(config.MaxImageBytes)
+	}
+
+	var data []byte
+	var mediaType string
+	if strings.HasPrefix(imageURL, "http://") || strings.HasPrefix(imageURL, "https://") {
+		if !fetch {
+			return imageURL, nil
+		}
+		var err error
+		data, mediaType, err = fetchImage(ctx, imageURL, config, maxBytes)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		if !config.Transcode {
+			return imageURL, nil
+		}
+		sanitizedURL, err := schemas.SanitizeImageURL(imageURL)
+		if err != nil {
+			return imageURL, nil
+		}
+		urlTypeInfo := schemas.ExtractURLTypeInfo(sanitizedURL)
+		if urlTypeInfo.Type != schemas.ImageContentTypeBase64 || urlTypeInfo.DataURLWithoutPrefix == nil {
+			return imageURL, nil
+		}
+		data, err = base64.StdEncoding.DecodeString(*urlTypeInfo.DataURLWithoutPrefix)
+		if err != nil {
+			return "", fmt.Errorf("invalid base64 image data: %w", err)
+		}
+		if urlTypeInfo.MediaType != nil {
+			mediaType = *urlTypeInfo.MediaType
+		}
+		if len(data) > maxBytes {
+			return "", fmt.Errorf("image of %d bytes exceeds the limit of %d bytes", len(data), maxBytes)
+		}
+	}
+
+	if config.Transcode {
+		var err error
+		data, mediaType, err = transcodeImage(data, mediaType, limits)
+		if err != nil {
+			return "", err
+		}
+	}
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// fetchImage downloads an image URL, returning its content and media type
+func fetchImage(ctx context.Context, imageURL string, config *schemas.ImageInputConfig, maxBytes int) ([]byte, string, error) {
+	timeout := defaultImageFetchTimeout
+	if config.FetchTimeoutSeconds > 0 {
+		timeout = time.Duration(config.FetchTimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid image URL: %w", err)
+	}
+	client := imageFetchClients[0]
+	if config.AllowPrivateNetworks {
+		client = imageFetchClients[1]
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch image: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to fetch image %s: status %d", imageURL, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxBytes)+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read image: %w", err)
+	}
+	if len(data) > maxBytes {
+		return nil, "", fmt.Errorf("image %s exceeds the limit of %d bytes", imageURL, maxBytes)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !strings.HasPrefix(mediaType, "image/") {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(data))
+	}
+	if !strings.HasPrefix(mediaType, "image/") {
+		return nil, "", fmt.Errorf("%s is not an image", imageURL)
+	}
+	return data, mediaType, nil
+}
+
+// transcodeImage converts an image the provider doesn't accept, because of its format or size, to JPEG
+// (PNG for images with transparency), halving its dimensions until it fits. Formats that can't be decoded
+// (e.g. WebP) are returned as they are, and animated GIFs are reduced to their first frame.
+func transcodeImage(data []byte, mediaType string, limits imageInputLimits) ([]byte, string, error) {
+	if mediaType == "" {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(data))
+	}
+	if slices.Contains(limits.mediaTypes, mediaType) && len(data) <= limits.maxBytes {
+		return data, mediaType, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return data, mediaType, nil
+	}
+	for range maxImageDownscaleSteps {
+		encoded, encodedType, err := encodeImage(img)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to transcode image: %w", err)
+		}
+		if len(encoded) <= limits.maxBytes {
+			return encoded, encodedType, nil
+		}
+		img = downscaleImage(img)
+	}
+	return nil, "", fmt.Errorf("image can't be reduced below the provider limit of %d bytes", limits.maxBytes)
+}
+
+// encodeImage encodes an image as JPEG, or as PNG when it has transparency
+func encodeImage(img image.Image) ([]byte, string, error) {
+	var buf bytes.Buffer
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && !opaque.Opaque() {
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/png", nil
+	}
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: transcodedImageJPEGQuality}); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "image/jpeg", nil
+}
+
+// downscaleImage halves the dimensions of an image, averaging each 2x2 block of pixels
+func downscaleImage(img image.Image) image.Image {
+	bounds := img.Bounds()
+	width, height := max(bounds.Dx()/2, 1), max(bounds.Dy()/2, 1)
+	scaled := image.NewRGBA64(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			var r, g, b, a uint32
+			for dy := range 2 {
+				for dx := range 2 {
+					sx := min(bounds.Min.X+2*x+dx, bounds.Max.X-1)
+					sy := min(bounds.Min.Y+2*y+dy, bounds.Max.Y-1)
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, b, a = r+pr, g+pg, b+pb, a+pa
+				}
+			}
+			scaled.SetRGBA64(x, y, color.RGBA64{R: uint16(r / 4), G: uint16(g / 4), B: uint16(b / 4), A: uint16(a / 4)})
+		}
+	}
+	return scaled
+}
@@ -0,0 +1,120 @@
+package bifrost
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/gif"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// testImage returns a width x height image filled with a gradient
+func testImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: uint8(x * y), A: 255})
+		}
+	}
+	return img
+}
+
+func imageChatRequest(provider schemas.ModelProvider, imageURL string) *schemas.BifrostRequest {
+	return &schemas.BifrostRequest{
+		RequestType: schemas.ChatCompletionRequest,
+		ChatRequest: &schemas.BifrostChatRequest{
+			Provider: provider,
+			Model:    "model",
+			Input: []schemas.ChatMessage{{
+				Role: schemas.ChatMessageRoleUser,
+				Content: &schemas.ChatMessageContent{ContentBlocks: []schemas.ChatContentBlock{
+					{Type: schemas.ChatContentBlockTypeText, Text: schemas.Ptr("What is in this image?")},
+					{Type: schemas.ChatContentBlockTypeImage, ImageURLStruct: &schemas.ChatInputImage{URL: imageURL}},
+				}},
+			}},
+		},
+	}
+}
+
+// TestNormalizeImageInputsFetch tests that image URLs are fetched for providers that don't accept them
+func TestNormalizeImageInputsFetch(t *testing.T) {
+	var gifData bytes.Buffer
+	if err := gif.Encode(&gifData, testImage(8, 8), nil); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/gif")
+		w.Write(gifData.Bytes())
+	}))
+	defer server.Close()
+
+	bifrost := &Bifrost{account: NewMockAccount()}
+	bifrost.imageInputs.Store(&schemas.ImageInputConfig{FetchURLs: true, Transcode: true, AllowPrivateNetworks: true})
+
+	// Anthropic accepts image URLs, they are sent as they are
+	req := imageChatRequest(schemas.Anthropic, server.URL+"/cat.gif")
+	if err := bifrost.normalizeImageInputs(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+	if url := req.ChatRequest.Input[0].Content.ContentBlocks[1].ImageURLStruct.URL; url != server.URL+"/cat.gif" {
+		t.Errorf("expected the URL to be kept for Anthropic, got %s", url)
+	}
+
+	// Gemini needs inline images and doesn't accept GIF
+	req = imageChatRequest(schemas.Gemini, server.URL+"/cat.gif")
+	original := req.ChatRequest
+	if err := bifrost.normalizeImageInputs(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+	url := req.ChatRequest.Input[0].Content.ContentBlocks[1].ImageURLStruct.URL
+	if !strings.HasPrefix(url, "data:image/jpeg;base64,") {
+		t.Errorf("expected a JPEG data URL for Gemini, got %.40s", url)
+	}
+	if original.Input[0].Content.ContentBlocks[1].ImageURLStruct.URL != server.URL+"/cat.gif" {
+		t.Error("the original request must keep its image URL")
+	}
+
+	// Images on private networks are refused unless allowed
+	bifrost.imageInputs.Store(&schemas.ImageInputConfig{FetchURLs: true})
+	if err := bifrost.normalizeImageInputs(context.Background(), imageChatRequest(schemas.Bedrock, server.URL+"/cat.gif")); err == nil || *err.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected a 400 error for a loopback image URL, got %+v", err)
+	}
+}
+
+// TestTranscodeImage tests that images above the provider limit are downscaled until they fit
+func TestTranscodeImage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := gif.Encode(&buf, testImage(256, 256), nil); err != nil {
+		t.Fatal(err)
+	}
+	limits := imageInputLimits{maxBytes: 4096, mediaTypes: commonImageMediaTypes}
+
+	data, mediaType, err := transcodeImage(buf.Bytes(), "image/gif", limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mediaType != "image/jpeg" || len(data) > limits.maxBytes {
+		t.Errorf("expected a JPEG of at most %d bytes, got %s of %d bytes", limits.maxBytes, mediaType, len(data))
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Bounds().Dx() >= 256 {
+		t.Errorf("expected the image to be downscaled, got a width of %d", decoded.Bounds().Dx())
+	}
+
+	// Images the provider accepts are left as they are
+	small := base64.StdEncoding.EncodeToString(buf.Bytes())
+	data, _, err = transcodeImage(buf.Bytes(), "image/gif", imageInputLimits{maxBytes: defaultMaxImageBytes, mediaTypes: commonImageMediaTypes})
+	if err != nil || base64.StdEncoding.EncodeToString(data) != small {
+		t.Error("expected an accepted image to be returned as is")
+	}
+}
@@ -0,0 +1,286 @@
+// Package vcr records the HTTP interactions of providers to cassettes and replays them, so that provider
+// converters can be tested against real provider responses without API keys.
+//
+// A cassette server stands in for the provider: tests point the base URL of the provider to it. In record mode
+// (BIFROST_VCR_MODE=record) the server forwards the requests to the upstream API and records the interactions,
+// sanitized, to the cassette when the test ends. In replay mode, the default, it serves the recorded responses
+// to the matching requests, and fails the test on requests that were not recorded.
+package vcr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// ModeEnvVar is the environment variable selecting the mode of the cassette servers
+const ModeEnvVar = "BIFROST_VCR_MODE"
+
+// Mode is the mode of a cassette server
+type Mode string
+
+const (
+	ModeReplay Mode = "replay" // Serve the recorded responses, the default
+	ModeRecord Mode = "record" // Forward the requests to the upstream API and record them
+)
+
+// redactedValue replaces the secrets of the recorded interactions
+const redactedValue = "<redacted>"
+
+// sensitiveQueryParams are the query parameters carrying credentials, never recorded
+var sensitiveQueryParams = []string{"key", "api_key", "api-key", "access_token"}
+
+// recordedResponseHeaders are the only response headers recorded, the others may identify the account
+var recordedResponseHeaders = []string{"Content-Type"}
+
+// Request is a recorded request. Headers are not recorded since they carry the credentials.
+type Request struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Query  string `json:"query,omitempty"`
+	Body   string `json:"body,omitempty"`
+}
+
+// Response is a recorded response, stream bodies are recorded whole
+type Response struct {
+	StatusCode int               `json:"status_code"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body"`
+}
+
+// Interaction is a request to the provider and the response it got
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Cassette holds the interactions recorded for a test
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// ModeFromEnv returns the mode set in ModeEnvVar, replay by default
+func ModeFromEnv() Mode {
+	if Mode(strings.ToLower(strings.TrimSpace(os.Getenv(ModeEnvVar)))) == ModeRecord {
+		return ModeRecord
+	}
+	return ModeReplay
+}
+
+// Load reads the cassette at path
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %w", path, err)
+	}
+	return &cassette, nil
+}
+
+// Save writes the cassette to path, creating its directory
+func (c *Cassette) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Server is a cassette server standing in for a provider
+type Server struct {
+	*httptest.Server
+
+	t        testing.TB
+	mode     Mode
+	path     string
+	upstream string
+	secrets  []string
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// NewServer starts the cassette server of the cassette at path, in the mode set in ModeEnvVar. In record mode,
+// requests are forwarded to upstream (e.g. https://api.openai.com) and the secrets, typically the API keys
+// used, are replaced in the recorded bodies. In replay mode, the test is skipped when the cassette does not exist.
+// The server is closed, and the cassette saved in record mode, when the test ends.
+func NewServer(t testing.TB, path string, upstream string, secrets ...string) *Server {
+	t.Helper()
+	s := &Server{t: t, mode: ModeFromEnv(), path: path, upstream: strings.TrimSuffix(upstream, "/"), cassette: &Cassette{}}
+	for _, secret := range secrets {
+		if secret != "" {
+			s.secrets = append(s.secrets, secret)
+		}
+	}
+
+	if s.mode == ModeReplay {
+		cassette, err := Load(path)
+		if os.IsNotExist(err) {
+			t.Skipf("cassette %s not recorded, run with %s=record to record it", path, ModeEnvVar)
+		}
+		if err != nil {
+			t.Fatalf("failed to load cassette: %v", err)
+		}
+		s.cassette = cassette
+		s.used = make([]bool, len(cassette.Interactions))
+		s.Server = httptest.NewServer(http.HandlerFunc(s.replay))
+	} else {
+		s.Server = httptest.NewServer(http.HandlerFunc(s.record))
+	}
+
+	t.Cleanup(func() {
+		s.Close()
+		if s.mode == ModeRecord {
+			if err := s.cassette.Save(path); err != nil {
+				t.Errorf("failed to save cassette %s: %v", path, err)
+			}
+		}
+	})
+	return s
+}
+
+// replay serves the first unused interaction matching the request
+func (s *Server) replay(w http.ResponseWriter, r *http.Request) {
+	request, err := s.newRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	var response *Response
+	for i, interaction := range s.cassette.Interactions {
+		if !s.used[i] && matches(interaction.Request, request) {
+			s.used[i] = true
+			response = &s.cassette.Interactions[i].Response
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if response == nil {
+		s.t.Errorf("no recorded interaction in cassette %s matches %s %s %s", s.path, request.Method, request.Path, request.Body)
+		http.Error(w, "no recorded interaction matches the request", http.StatusNotImplemented)
+		return
+	}
+	for key, value := range response.Headers {
+		w.Header().Set(key, value)
+	}
+	w.WriteHeader(response.StatusCode)
+	io.WriteString(w, response.Body)
+}
+
+// record forwards the request to the upstream API and records the interaction
+func (s *Server) record(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	upstreamReq, err := http.NewRequestWithContext(r.Context(), r.Method, s.upstream+r.URL.RequestURI(), bytes.NewReader(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	upstreamReq.Header = r.Header.Clone()
+	upstreamReq.Header.Del("Accept-Encoding")
+	resp, err := http.DefaultClient.Do(upstreamReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	request, err := s.newRequest(r)
+	if err == nil {
+		response := Response{StatusCode: resp.StatusCode, Headers: make(map[string]string), Body: s.sanitize(string(respBody))}
+		for _, header := range recordedResponseHeaders {
+			if value := resp.Header.Get(header); value != "" {
+				response.Headers[header] = value
+			}
+		}
+		s.mu.Lock()
+		s.cassette.Interactions = append(s.cassette.Interactions, Interaction{Request: request, Response: response})
+		s.mu.Unlock()
+	}
+
+	for _, header := range recordedResponseHeaders {
+		if value := resp.Header.Get(header); value != "" {
+			w.Header().Set(header, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	w.Write(respBody)
+}
+
+// newRequest returns the sanitized recording of the request
+func (s *Server) newRequest(r *http.Request) (Request, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return Request{}, err
+	}
+	query := r.URL.Query()
+	for _, param := range sensitiveQueryParams {
+		if query.Has(param) {
+			query.Set(param, redactedValue)
+		}
+	}
+	return Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  query.Encode(),
+		Body:   s.sanitize(string(body)),
+	}, nil
+}
+
+// sanitize replaces the secrets in the value
+func (s *Server) sanitize(value string) string {
+	for _, secret := range s.secrets {
+		value = strings.ReplaceAll(value, secret, redactedValue)
+	}
+	return value
+}
+
+// matches reports whether the recorded request matches the request, JSON bodies are compared by value
+func matches(recorded Request, request Request) bool {
+	if recorded.Method != request.Method || recorded.Path != request.Path {
+		return false
+	}
+	recordedQuery, _ := url.ParseQuery(recorded.Query)
+	requestQuery, _ := url.ParseQuery(request.Query)
+	if recordedQuery.Encode() != requestQuery.Encode() {
+		return false
+	}
+	if recorded.Body == request.Body {
+		return true
+	}
+	var recordedBody, requestBody any
+	if json.Unmarshal([]byte(recorded.Body), &recordedBody) != nil || json.Unmarshal([]byte(request.Body), &requestBody) != nil {
+		return false
+	}
+	recordedJSON, _ := json.Marshal(recordedBody)
+	requestJSON, _ := json.Marshal(requestBody)
+	return bytes.Equal(recordedJSON, requestJSON)
+}
@@ -0,0 +1,21 @@
+package vcr
+
+import "testing"
+
+// TestMatches tests that requests match the recorded ones regardless of the key order of their JSON bodies
+func TestMatches(t *testing.T) {
+	recorded := Request{Method: "POST", Path: "/v1/chat/completions", Body: `{"model":"gpt-4o-mini","stream":false}`}
+
+	if !matches(recorded, Request{Method: "POST", Path: "/v1/chat/completions", Body: `{"stream":false,"model":"gpt-4o-mini"}`}) {
+		t.Error("expected bodies with the same JSON value to match")
+	}
+	if matches(recorded, Request{Method: "POST", Path: "/v1/chat/completions", Body: `{"model":"gpt-4o"}`}) {
+		t.Error("expected different bodies not to match")
+	}
+	if matches(recorded, Request{Method: "POST", Path: "/v1/responses", Body: recorded.Body}) {
+		t.Error("expected different paths not to match")
+	}
+	if !matches(Request{Method: "GET", Path: "/v1/models", Query: "limit=10&key=<redacted>"}, Request{Method: "GET", Path: "/v1/models", Query: "key=<redacted>&limit=10"}) {
+		t.Error("expected queries with the same parameters to match")
+	}
+}
@@ -0,0 +1,77 @@
+package bifrost
+
+import (
+	"fmt"
+	"net/url"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// keyProviderID identifies the provider instances sending the requests of keys with the same proxy override
+type keyProviderID struct {
+	provider schemas.ModelProvider
+	proxy    schemas.ProxyConfig
+}
+
+// keyProviderEntry is a provider instance created with the proxy of a key, from the config of the provider
+type keyProviderEntry struct {
+	config   *schemas.ProviderConfig
+	provider schemas.Provider
+}
+
+// providerForKey returns the provider sending the requests of the key: the provider itself, or an instance of the
+// provider created with the proxy of the key when the key overrides the proxy of its provider. Instances are
+// shared by the keys with the same proxy, and created again once the config of the provider changed.
+// Returns an error when the instance cannot be created, the requests of the key are never sent without its proxy.
+func (bifrost *Bifrost) providerForKey(provider schemas.Provider, config *schemas.ProviderConfig, key schemas.Key) (schemas.Provider, error) {
+	if key.ProxyConfig == nil {
+		return provider, nil
+	}
+	id := keyProviderID{provider: provider.GetProviderKey(), proxy: *key.ProxyConfig}
+	if value, ok := bifrost.keyProviders.Load(id); ok && value.(*keyProviderEntry).config == config {
+		return value.(*keyProviderEntry).provider, nil
+	}
+
+	keyConfig := *config
+	keyConfig.ProxyConfig = key.ProxyConfig
+	// The providers ignore an invalid proxy, which would send the requests of the key without it
+	err := validateKeyProxy(key.ProxyConfig)
+	var instance schemas.Provider
+	if err == nil {
+		instance, err = bifrost.createBaseProvider(provider.GetProviderKey(), &keyConfig)
+	}
+	if err != nil {
+		bifrost.logger.Warn("failed to create provider %s with the proxy of key %s: %v", provider.GetProviderKey(), key.Name, err)
+		return nil, fmt.Errorf("failed to create provider %s with the proxy of key %s: %w", provider.GetProviderKey(), key.Name, err)
+	}
+	bifrost.keyProviders.Store(id, &keyProviderEntry{config: config, provider: instance})
+	return instance, nil
+}
+
+// validateKeyProxy checks that the proxy of a key is one the providers can send requests through
+func validateKeyProxy(proxy *schemas.ProxyConfig) error {
+	switch proxy.Type {
+	case schemas.NoProxy, schemas.EnvProxy:
+		return nil
+	case schemas.HTTPProxy, schemas.Socks5Proxy:
+		if proxy.URL == "" {
+			return fmt.Errorf("%s proxy url is required", proxy.Type)
+		}
+		if _, err := url.Parse(proxy.URL); err != nil {
+			return fmt.Errorf("invalid %s proxy url: %w", proxy.Type, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported proxy type: %s", proxy.Type)
+	}
+}
+
+// removeKeyProviders removes the instances of the provider created with the proxies of its keys
+func (bifrost *Bifrost) removeKeyProviders(providerKey schemas.ModelProvider) {
+	bifrost.keyProviders.Range(func(id, _ any) bool {
+		if id.(keyProviderID).provider == providerKey {
+			bifrost.keyProviders.Delete(id)
+		}
+		return true
+	})
+}
@@ -0,0 +1,71 @@
+package bifrost
+
+import (
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestProviderForKey tests that keys overriding the proxy get their own provider instance, shared by the keys
+// with the same proxy and created again when the config of the provider changes
+func TestProviderForKey(t *testing.T) {
+	bifrost := &Bifrost{logger: NewDefaultLogger(schemas.LogLevelError)}
+	config := &schemas.ProviderConfig{}
+	provider, err := bifrost.createBaseProvider(schemas.OpenAI, config)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	if mustProviderForKey(t, bifrost, provider, config, schemas.Key{Value: "sk-direct"}) != provider {
+		t.Error("expected keys without a proxy to use the provider")
+	}
+
+	jumpProxy := &schemas.ProxyConfig{Type: schemas.HTTPProxy, URL: "http://jump.internal:3128"}
+	keyProvider := mustProviderForKey(t, bifrost, provider, config, schemas.Key{Value: "sk-first", ProxyConfig: jumpProxy})
+	if keyProvider == provider {
+		t.Fatal("expected keys with a proxy to use their own provider instance")
+	}
+	sameProxy := *jumpProxy
+	if mustProviderForKey(t, bifrost, provider, config, schemas.Key{Value: "sk-second", ProxyConfig: &sameProxy}) != keyProvider {
+		t.Error("expected keys with the same proxy to share the provider instance")
+	}
+
+	updatedConfig := &schemas.ProviderConfig{}
+	if mustProviderForKey(t, bifrost, provider, updatedConfig, schemas.Key{Value: "sk-first", ProxyConfig: jumpProxy}) == keyProvider {
+		t.Error("expected the provider instance to be created again after a config change")
+	}
+}
+
+// mustProviderForKey returns the provider of the key, failing the test when it cannot be created
+func mustProviderForKey(t *testing.T, bifrost *Bifrost, provider schemas.Provider, config *schemas.ProviderConfig, key schemas.Key) schemas.Provider {
+	t.Helper()
+	keyProvider, err := bifrost.providerForKey(provider, config, key)
+	if err != nil {
+		t.Fatalf("failed to get the provider of the key: %v", err)
+	}
+	return keyProvider
+}
+
+// TestProviderForKey_InvalidProxyFailsClosed tests that the requests of a key whose proxy cannot be used are refused
+// instead of being sent without the proxy
+func TestProviderForKey_InvalidProxyFailsClosed(t *testing.T) {
+	bifrost := &Bifrost{logger: NewDefaultLogger(schemas.LogLevelError)}
+	config := &schemas.ProviderConfig{}
+	provider, err := bifrost.createBaseProvider(schemas.OpenAI, config)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	for name, proxy := range map[string]*schemas.ProxyConfig{
+		"missing url":  {Type: schemas.HTTPProxy},
+		"invalid url":  {Type: schemas.Socks5Proxy, URL: "socks5://jump.internal:%zz"},
+		"unknown type": {Type: "tunnel", URL: "http://jump.internal:3128"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			keyProvider, err := bifrost.providerForKey(provider, config, schemas.Key{Value: "sk-proxied", ProxyConfig: proxy})
+			if err == nil || keyProvider != nil {
+				t.Fatalf("expected an error and no provider, got %v, %v", keyProvider, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// keyValidationTimeout bounds the live call made to validate a key
+const keyValidationTimeout = 30 * time.Second
+
+// ValidateKey checks with a live call that the provider accepts a key, so that broken credentials are reported
+// before they are saved. The call is sent by an instance of the provider created from config, which validates keys
+// of providers not added yet and bypasses the queues, plugins and retries of the configured providers.
+// It lists the models of the provider, or completes a single token with the first model of the key when the
+// provider cannot list its models.
+func (bifrost *Bifrost) ValidateKey(ctx context.Context, providerKey schemas.ModelProvider, config schemas.ProviderConfig, key schemas.Key) *schemas.BifrostError {
+	if config.CustomProviderConfig != nil {
+		// createBaseProvider sets the key of the custom provider, the config of the caller is left untouched
+		customProviderConfig := *config.CustomProviderConfig
+		config.CustomProviderConfig = &customProviderConfig
+	}
+	if key.ProxyConfig != nil {
+		config.ProxyConfig = key.ProxyConfig
+	}
+	provider, err := bifrost.createBaseProvider(providerKey, &config)
+	if err != nil {
+		return newBifrostError(err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, keyValidationTimeout)
+	defer cancel()
+
+	_, bifrostErr := provider.ListModels(ctx, []schemas.Key{key}, &schemas.BifrostListModelsRequest{Provider: providerKey})
+	if bifrostErr == nil || !isUnsupportedOperationError(bifrostErr) {
+		return bifrostErr
+	}
+	if len(key.Models) == 0 {
+		return newBifrostErrorFromMsg(fmt.Sprintf("%s cannot list its models, add a model to the key to validate it with a completion", providerKey))
+	}
+
+	prompt := "ping"
+	_, bifrostErr = provider.ChatCompletion(ctx, key, &schemas.BifrostChatRequest{
+		Provider: providerKey,
+		Model:    key.Models[0],
+		Input: []schemas.ChatMessage{{
+			Role:    schemas.ChatMessageRoleUser,
+			Content: &schemas.ChatMessageContent{ContentStr: &prompt},
+		}},
+		Params: &schemas.ChatParameters{MaxCompletionTokens: schemas.Ptr(1)},
+	})
+	return bifrostErr
+}
+
+// isUnsupportedOperationError reports whether the error was returned by a provider not supporting the request,
+// without calling the provider
+func isUnsupportedOperationError(bifrostErr *schemas.BifrostError) bool {
+	return bifrostErr.StatusCode == nil && bifrostErr.Error != nil && strings.Contains(bifrostErr.Error.Message, " is not supported by ")
+}
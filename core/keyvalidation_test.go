@@ -0,0 +1,24 @@
+package bifrost
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestValidateKey tests that keys are validated by listing models, and that keys of providers unable to list their
+// models need a model to be validated with a completion
+func TestValidateKey(t *testing.T) {
+	bifrost := &Bifrost{logger: NewDefaultLogger(schemas.LogLevelError)}
+
+	if bifrostErr := bifrost.ValidateKey(context.Background(), schemas.Mock, schemas.ProviderConfig{}, schemas.Key{}); bifrostErr != nil {
+		t.Errorf("expected the mock key to be valid, got %v", bifrostErr.Error.Message)
+	}
+
+	bifrostErr := bifrost.ValidateKey(context.Background(), schemas.Perplexity, schemas.ProviderConfig{}, schemas.Key{Value: "pplx-key"})
+	if bifrostErr == nil || !strings.Contains(bifrostErr.Error.Message, "add a model to the key") {
+		t.Errorf("expected keys without models of providers unable to list models to be rejected, got %+v", bifrostErr)
+	}
+}
@@ -0,0 +1,151 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// listModelsFetch is a fetch of the models of a provider, in flight until done is closed
+type listModelsFetch struct {
+	done   chan struct{} // Closed when the fetch completed, models and err are set before
+	models []schemas.Model
+	err    *schemas.BifrostError
+}
+
+// listModelsCacheEntry is the cached listing of a provider with a set of keys
+type listModelsCacheEntry struct {
+	models    []schemas.Model
+	fetchedAt time.Time        // Zero until the first successful fetch
+	fetch     *listModelsFetch // Non-nil while the listing is fetched
+}
+
+// listModelsCache holds the models listed by the providers, per provider and set of keys
+type listModelsCache struct {
+	mu      sync.Mutex
+	entries map[string]*listModelsCacheEntry
+}
+
+// newListModelsCache creates a new, empty list models cache
+func newListModelsCache() *listModelsCache {
+	return &listModelsCache{entries: make(map[string]*listModelsCacheEntry)}
+}
+
+// listModelsCacheKey identifies the listing of a provider with a set of keys, keys are identified by their ID
+func listModelsCacheKey(provider schemas.ModelProvider, keys []schemas.Key) string {
+	ids := make([]string, len(keys))
+	for i, key := range keys {
+		ids[i] = key.ID
+	}
+	slices.Sort(ids)
+	return string(provider) + "/" + strings.Join(ids, ",")
+}
+
+// get returns the cached listing of the key. Fresh listings are returned as they are, stale ones are returned while
+// fetch refreshes them with backgroundCtx, and missing or expired ones are fetched with ctx before they are returned.
+// Concurrent callers share the fetch in flight. A failed refresh keeps the stale listing.
+func (c *listModelsCache) get(ctx context.Context, backgroundCtx context.Context, key string, config *schemas.ListModelsCacheConfig, fetch func(ctx context.Context) ([]schemas.Model, *schemas.BifrostError)) ([]schemas.Model, *schemas.BifrostError) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &listModelsCacheEntry{}
+		c.entries[key] = entry
+	}
+	if !entry.fetchedAt.IsZero() {
+		age := time.Since(entry.fetchedAt)
+		if age < config.RefreshInterval() {
+			models := entry.models
+			c.mu.Unlock()
+			return models, nil
+		}
+		if age < config.RefreshInterval()+config.MaxStale() {
+			if entry.fetch == nil {
+				refresh := c.startFetch(entry)
+				go c.runFetch(backgroundCtx, entry, refresh, fetch)
+			}
+			models := entry.models
+			c.mu.Unlock()
+			return models, nil
+		}
+	}
+	inFlight := entry.fetch
+	if inFlight == nil {
+		inFlight = c.startFetch(entry)
+		c.mu.Unlock()
+		c.runFetch(ctx, entry, inFlight, fetch)
+	} else {
+		c.mu.Unlock()
+		select {
+		case <-inFlight.done:
+		case <-ctx.Done():
+			return nil, newBifrostError(ctx.Err())
+		}
+	}
+	return inFlight.models, inFlight.err
+}
+
+// startFetch registers a fetch in flight for the entry, the caller must hold the lock
+func (c *listModelsCache) startFetch(entry *listModelsCacheEntry) *listModelsFetch {
+	entry.fetch = &listModelsFetch{done: make(chan struct{})}
+	return entry.fetch
+}
+
+// runFetch fetches the listing of the entry, stores it when the fetch succeeds and wakes up the callers waiting on it
+func (c *listModelsCache) runFetch(ctx context.Context, entry *listModelsCacheEntry, inFlight *listModelsFetch, fetch func(ctx context.Context) ([]schemas.Model, *schemas.BifrostError)) {
+	models, err := fetch(ctx)
+	c.mu.Lock()
+	if err == nil {
+		entry.models = models
+		entry.fetchedAt = time.Now()
+	}
+	entry.fetch = nil
+	c.mu.Unlock()
+	inFlight.models, inFlight.err = models, err
+	close(inFlight.done)
+}
+
+// invalidate drops the cached listings of the provider, fetches in flight still complete
+func (c *listModelsCache) invalidate(provider schemas.ModelProvider) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if strings.HasPrefix(key, string(provider)+"/") {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// listAllProviderModels lists the models of the provider, following the pages of the provider
+func (bifrost *Bifrost) listAllProviderModels(ctx context.Context, provider schemas.Provider, config *schemas.ProviderConfig, keys []schemas.Key, providerKey schemas.ModelProvider) ([]schemas.Model, *schemas.BifrostError) {
+	request := &schemas.BifrostListModelsRequest{
+		Provider: providerKey,
+		PageSize: schemas.DefaultPageSize,
+	}
+	models := make([]schemas.Model, 0)
+	for iterations := 1; ; iterations++ {
+		if iterations > schemas.MaxPaginationRequests {
+			bifrost.logger.Warn(fmt.Sprintf("reached maximum pagination requests (%d) while caching the models of provider %s", schemas.MaxPaginationRequests, providerKey))
+			break
+		}
+		response, bifrostErr := executeRequestWithRetries(&ctx, config, func() (*schemas.BifrostListModelsResponse, *schemas.BifrostError) {
+			return provider.ListModels(ctx, keys, request)
+		}, schemas.ListModelsRequest, providerKey, "")
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+		if response == nil {
+			break
+		}
+		models = append(models, response.Data...)
+		if response.NextPageToken == "" || len(response.Data) == 0 {
+			break
+		}
+		request.PageToken = response.NextPageToken
+	}
+	return models, nil
+}
@@ -0,0 +1,67 @@
+package bifrost
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestListModelsCache tests that fresh listings are served from the cache, stale ones are served while refreshed in
+// the background and expired ones are fetched again
+func TestListModelsCache(t *testing.T) {
+	cache := newListModelsCache()
+	config := &schemas.ListModelsCacheConfig{RefreshIntervalSeconds: 60, MaxStaleSeconds: 60}
+	key := listModelsCacheKey(schemas.OpenAI, []schemas.Key{{ID: "b"}, {ID: "a"}})
+
+	var fetches atomic.Int32
+	fetch := func(ctx context.Context) ([]schemas.Model, *schemas.BifrostError) {
+		fetches.Add(1)
+		return []schemas.Model{{ID: "openai/gpt-4o"}}, nil
+	}
+	// waitForRefresh waits for the fetch in flight to be stored
+	waitForRefresh := func() {
+		for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+			cache.mu.Lock()
+			done := cache.entries[key].fetch == nil
+			cache.mu.Unlock()
+			if done {
+				return
+			}
+		}
+		t.Fatal("expected the listing to be refreshed in the background")
+	}
+
+	models, err := cache.get(context.Background(), context.Background(), key, config, fetch)
+	if err != nil || len(models) != 1 || fetches.Load() != 1 {
+		t.Fatalf("expected the first listing to be fetched, got %v and %d fetches", err, fetches.Load())
+	}
+	if _, err := cache.get(context.Background(), context.Background(), key, config, fetch); err != nil || fetches.Load() != 1 {
+		t.Fatalf("expected the fresh listing to be served from the cache, got %d fetches", fetches.Load())
+	}
+
+	// Stale listings are served while they are refreshed
+	cache.entries[key].fetchedAt = time.Now().Add(-90 * time.Second)
+	if models, err := cache.get(context.Background(), context.Background(), key, config, fetch); err != nil || len(models) != 1 {
+		t.Fatalf("expected the stale listing to be served, got %v", err)
+	}
+	waitForRefresh()
+	if fetches.Load() != 2 {
+		t.Fatalf("expected the stale listing to be refreshed, got %d fetches", fetches.Load())
+	}
+
+	// Expired listings are fetched before they are served
+	cache.mu.Lock()
+	cache.entries[key].fetchedAt = time.Now().Add(-3 * time.Minute)
+	cache.mu.Unlock()
+	if _, err := cache.get(context.Background(), context.Background(), key, config, fetch); err != nil || fetches.Load() != 3 {
+		t.Fatalf("expected the expired listing to be fetched, got %d fetches", fetches.Load())
+	}
+
+	cache.invalidate(schemas.OpenAI)
+	if len(cache.entries) != 0 {
+		t.Errorf("expected the listings of the provider to be dropped, got %d", len(cache.entries))
+	}
+}
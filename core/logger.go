@@ -2,7 +2,11 @@
 package bifrost
 
 import (
+	"errors"
 	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	schemas "github.com/maximhq/bifrost/core/schemas"
@@ -10,13 +14,51 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-// DefaultLogger implements the Logger interface with stdout/stderr printing.
-// It provides a simple logging implementation that writes to standard output
-// and error streams with formatted timestamps and log levels.
+// DefaultLogger implements the StructuredLogger interface with zerolog.
+// It writes JSON entries with formatted timestamps and log levels to the sinks of its
+// LoggingConfig, or to stdout and stderr when it has no sinks. The loggers of the modules
+// returned by WithModule share its sinks and levels, and tag their entries with their module.
 // It is used as the default logger if no logger is provided in the BifrostConfig.
 type DefaultLogger struct {
-	stderrLogger zerolog.Logger
-	stdoutLogger zerolog.Logger
+	state  *loggerState
+	module string
+}
+
+// loggerState holds the sinks and levels shared by a DefaultLogger and the loggers of its modules
+type loggerState struct {
+	levels  atomic.Pointer[loggerLevels]
+	output  atomic.Pointer[loggerOutput]
+	modules sync.Map // modules that have a logger
+
+	mu         sync.Mutex // serializes the replacements of the output
+	outputType schemas.LoggerOutputType
+	sinks      []schemas.LogSinkConfig // configured sinks, stdout and stderr when empty
+}
+
+// loggerLevels is a snapshot of the levels of a logger, replaced as a whole when they change
+type loggerLevels struct {
+	level   schemas.LogLevel
+	modules map[string]schemas.LogLevel
+}
+
+// loggerOutput is the zerolog logger writing to the sinks of a logger
+type loggerOutput struct {
+	logger zerolog.Logger
+	sinks  []leveledLogSink
+}
+
+// logLevelRank orders the log levels by severity, unknown levels rank as info
+func logLevelRank(level schemas.LogLevel) int {
+	switch level {
+	case schemas.LogLevelDebug:
+		return 0
+	case schemas.LogLevelWarn:
+		return 2
+	case schemas.LogLevelError:
+		return 3
+	default:
+		return 1
+	}
 }
 
 // toZerologLevel converts a Bifrost log level to a Zerolog level.
@@ -35,44 +77,118 @@ func toZerologLevel(l schemas.LogLevel) zerolog.Level {
 	}
 }
 
+// fromZerologLevel converts a Zerolog level to a Bifrost log level, fatal and panic entries have the error level.
+func fromZerologLevel(l zerolog.Level) schemas.LogLevel {
+	switch l {
+	case zerolog.TraceLevel, zerolog.DebugLevel:
+		return schemas.LogLevelDebug
+	case zerolog.WarnLevel:
+		return schemas.LogLevelWarn
+	case zerolog.ErrorLevel, zerolog.FatalLevel, zerolog.PanicLevel:
+		return schemas.LogLevelError
+	default:
+		return schemas.LogLevelInfo
+	}
+}
+
+// enabled reports whether the entries of the level are logged for the module
+func (l *loggerLevels) enabled(module string, level schemas.LogLevel) bool {
+	threshold := l.level
+	if moduleLevel, ok := l.modules[module]; ok {
+		threshold = moduleLevel
+	}
+	return logLevelRank(level) >= logLevelRank(threshold)
+}
+
 // NewDefaultLogger creates a new DefaultLogger instance with the specified log level.
 // The log level determines which messages will be output based on their severity.
 func NewDefaultLogger(level schemas.LogLevel) *DefaultLogger {
-	zerolog.SetGlobalLevel(toZerologLevel(level))
 	zerolog.DisableSampling(true)
 	zerolog.TimeFieldFormat = time.RFC3339
 	log.Logger = zerolog.New(os.Stdout).With().Timestamp().Logger()
-	return &DefaultLogger{
-		stderrLogger: zerolog.New(os.Stderr).With().Timestamp().Logger(),
-		stdoutLogger: zerolog.New(os.Stdout).With().Timestamp().Logger(),
+	logger := &DefaultLogger{state: &loggerState{outputType: schemas.LoggerOutputTypeJSON}}
+	logger.SetLevels(level, nil)
+	// The console output has no sink to open, so it cannot fail
+	output, _ := newLoggerOutput(nil, schemas.LoggerOutputTypeJSON)
+	logger.state.output.Store(output)
+	return logger
+}
+
+// newLoggerOutput opens the sinks of the configs, and returns the zerolog logger writing to them
+func newLoggerOutput(configs []schemas.LogSinkConfig, outputType schemas.LoggerOutputType) (*loggerOutput, error) {
+	sinks, err := newLogSinks(configs, outputType)
+	if err != nil {
+		return nil, err
+	}
+	return &loggerOutput{
+		logger: zerolog.New(logSinksWriter(sinks)).With().Timestamp().Logger(),
+		sinks:  sinks,
+	}, nil
+}
+
+// close closes the sinks of the output
+func (o *loggerOutput) close() error {
+	var errs []error
+	for _, sink := range o.sinks {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// replaceOutput swaps the output of the logger and closes the sinks of the previous one, the caller holds the lock.
+// Entries being written to the previous sinks while they are closed are lost.
+func (s *loggerState) replaceOutput(output *loggerOutput) {
+	if previous := s.output.Swap(output); previous != nil {
+		if err := previous.close(); err != nil {
+			output.logger.Warn().Err(err).Msg("failed to close the previous log sinks")
+		}
+	}
+}
+
+// log writes an entry of the level, if the level of the module of the logger allows it
+func (logger *DefaultLogger) log(level zerolog.Level, msg string, args []any) {
+	if !logger.state.levels.Load().enabled(logger.module, fromZerologLevel(level)) {
+		return
 	}
+	logger.event(level).Msgf(msg, args...)
+}
+
+// event starts an entry of the level, tagged with the module of the logger
+func (logger *DefaultLogger) event(level zerolog.Level) *zerolog.Event {
+	event := logger.state.output.Load().logger.WithLevel(level)
+	if logger.module != "" {
+		event = event.Str("module", logger.module)
+	}
+	return event
 }
 
 // Debug logs a debug level message to stdout.
 // Messages are only output if the logger's level is set to LogLevelDebug.
 func (logger *DefaultLogger) Debug(msg string, args ...any) {
-	logger.stdoutLogger.Debug().Msgf(msg, args...)
+	logger.log(zerolog.DebugLevel, msg, args)
 }
 
 // Info logs an info level message to stdout.
 // Messages are output if the logger's level is LogLevelDebug or LogLevelInfo.
 func (logger *DefaultLogger) Info(msg string, args ...any) {
-	logger.stdoutLogger.Info().Msgf(msg, args...)
+	logger.log(zerolog.InfoLevel, msg, args)
 }
 
 // Warn logs a warning level message to stdout.
 // Messages are output if the logger's level is LogLevelDebug, LogLevelInfo, or LogLevelWarn.
 func (logger *DefaultLogger) Warn(msg string, args ...any) {
-	logger.stdoutLogger.Warn().Msgf(msg, args...)
+	logger.log(zerolog.WarnLevel, msg, args)
 }
 
 // Error logs an error level message to stderr.
 // Error messages are always output regardless of the logger's level.
 func (logger *DefaultLogger) Error(msg string, args ...any) {
-	logger.stderrLogger.Error().Msgf(msg, args...)
+	logger.log(zerolog.ErrorLevel, msg, args)
 }
 
-// Fatal logs a fatal-level message to stderr.
+// Fatal logs a fatal-level message to stderr, flushes the sinks and exits.
 // Fatal messages are always output regardless of the logger's level.
 func (logger *DefaultLogger) Fatal(msg string, args ...any) {
 	// Check if any of the args is an error and exit with non-zero code if found
@@ -85,33 +201,124 @@ func (logger *DefaultLogger) Fatal(msg string, args ...any) {
 		}
 	}
 	if errToPass != nil {
-		logger.stderrLogger.Fatal().Msgf(msg, errToPass)
+		logger.event(zerolog.FatalLevel).Msgf(msg, errToPass)
 	} else {
-		logger.stderrLogger.Fatal().Msgf(msg, args...)
+		logger.event(zerolog.FatalLevel).Msgf(msg, args...)
 	}
+	logger.Close()
+	os.Exit(1)
 }
 
 // SetLevel sets the logging level for the logger.
 // This determines which messages will be output based on their severity.
+// On the logger of a module, it sets the level of the module.
 func (logger *DefaultLogger) SetLevel(level schemas.LogLevel) {
-	zerolog.SetGlobalLevel(toZerologLevel(level))
+	defaultLevel, moduleLevels := logger.GetLevels()
+	if logger.module == "" {
+		defaultLevel = level
+	} else {
+		moduleLevels[logger.module] = level
+	}
+	logger.SetLevels(defaultLevel, moduleLevels)
 }
 
 // SetOutputType sets the output type for the logger.
-// This determines the format of the log output.
+// This determines the format of the log output to stdout and stderr.
 // If the output type is unknown, it defaults to JSON
 func (logger *DefaultLogger) SetOutputType(outputType schemas.LoggerOutputType) {
-	switch outputType {
-	case schemas.LoggerOutputTypePretty:
-		logger.stdoutLogger = zerolog.New(zerolog.ConsoleWriter{Out: os.Stdout}).With().Timestamp().Logger()
-		logger.stderrLogger = zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).With().Timestamp().Logger()
-	case schemas.LoggerOutputTypeJSON:
-		logger.stdoutLogger = zerolog.New(os.Stdout).With().Timestamp().Logger()
-		logger.stderrLogger = zerolog.New(os.Stderr).With().Timestamp().Logger()
-	default:
-		logger.stderrLogger.Warn().
+	state := logger.state
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if outputType != schemas.LoggerOutputTypePretty && outputType != schemas.LoggerOutputTypeJSON {
+		state.output.Load().logger.Warn().
 			Str("outputType", string(outputType)).
 			Msg("unknown logger output type; defaulting to JSON")
-		logger.stdoutLogger = zerolog.New(os.Stdout).With().Timestamp().Logger()
+		outputType = schemas.LoggerOutputTypeJSON
+	}
+	output, err := newLoggerOutput(state.sinks, outputType)
+	if err != nil {
+		state.output.Load().logger.Warn().Err(err).Msg("failed to reopen the log sinks; keeping the previous output type")
+		return
+	}
+	state.outputType = outputType
+	state.replaceOutput(output)
+}
+
+// Configure replaces the sinks and levels of the logger with the ones of the config.
+// The level of the logger is kept when the config has none.
+func (logger *DefaultLogger) Configure(config *schemas.LoggingConfig) error {
+	if config == nil {
+		return nil
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	state := logger.state
+	state.mu.Lock()
+	output, err := newLoggerOutput(config.Sinks, state.outputType)
+	if err != nil {
+		state.mu.Unlock()
+		return err
 	}
+	state.sinks = config.Sinks
+	state.replaceOutput(output)
+	state.mu.Unlock()
+
+	level := state.levels.Load().level
+	if config.Level != "" {
+		level = config.Level
+	}
+	logger.SetLevels(level, config.ModuleLevels)
+	return nil
+}
+
+// WithModule returns the logger of a module. It shares the sinks and levels of the logger, tags its entries with the
+// module, and filters them with the level of the module when it has one.
+func (logger *DefaultLogger) WithModule(module string) schemas.Logger {
+	logger.state.modules.Store(module, struct{}{})
+	return &DefaultLogger{state: logger.state, module: module}
+}
+
+// Modules returns the sorted modules that have a logger
+func (logger *DefaultLogger) Modules() []string {
+	modules := []string{}
+	logger.state.modules.Range(func(key, value any) bool {
+		modules = append(modules, key.(string))
+		return true
+	})
+	sort.Strings(modules)
+	return modules
+}
+
+// GetLevels returns the level of the modules without a level of their own, and a copy of the levels by module
+func (logger *DefaultLogger) GetLevels() (schemas.LogLevel, map[string]schemas.LogLevel) {
+	levels := logger.state.levels.Load()
+	moduleLevels := make(map[string]schemas.LogLevel, len(levels.modules))
+	for module, level := range levels.modules {
+		moduleLevels[module] = level
+	}
+	return levels.level, moduleLevels
+}
+
+// SetLevels sets the level of the modules without a level of their own, and replaces the levels by module.
+// The change applies to the entries logged from then on, by all the loggers of the modules.
+func (logger *DefaultLogger) SetLevels(level schemas.LogLevel, moduleLevels map[string]schemas.LogLevel) {
+	levels := &loggerLevels{level: level, modules: make(map[string]schemas.LogLevel, len(moduleLevels))}
+	lowest := level
+	for module, moduleLevel := range moduleLevels {
+		levels.modules[module] = moduleLevel
+		if logLevelRank(moduleLevel) < logLevelRank(lowest) {
+			lowest = moduleLevel
+		}
+	}
+	// Entries are filtered by module before reaching zerolog, whose global level only lets the lowest level through
+	zerolog.SetGlobalLevel(toZerologLevel(lowest))
+	logger.state.levels.Store(levels)
+}
+
+// Close flushes and closes the sinks of the logger, entries logged afterwards to closed sinks are lost
+func (logger *DefaultLogger) Close() error {
+	logger.state.mu.Lock()
+	defer logger.state.mu.Unlock()
+	return logger.state.output.Load().close()
 }
@@ -0,0 +1,108 @@
+package bifrost
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// captureLogSink keeps the entries written to it
+type captureLogSink struct {
+	mu      sync.Mutex
+	entries []map[string]any
+}
+
+func (s *captureLogSink) WriteEntry(level schemas.LogLevel, entry []byte) error {
+	var fields map[string]any
+	if err := json.Unmarshal(entry, &fields); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, fields)
+	return nil
+}
+
+func (s *captureLogSink) Close() error { return nil }
+
+// TestDefaultLogger_ModuleLevels tests that the entries of the modules are tagged with their module and filtered with
+// their level, that levels can be changed at runtime, and that sinks only get the entries of their level
+func TestDefaultLogger_ModuleLevels(t *testing.T) {
+	capture := &captureLogSink{}
+	errorsCapture := &captureLogSink{}
+	sinks := map[schemas.LogSinkType]*captureLogSink{"capture": capture, "capture-errors": errorsCapture}
+	for sinkType, sink := range sinks {
+		RegisterLogSink(sinkType, func(config schemas.LogSinkConfig) (schemas.LogSink, error) { return sink, nil })
+	}
+
+	logger := NewDefaultLogger(schemas.LogLevelInfo)
+	err := logger.Configure(&schemas.LoggingConfig{
+		Level:        schemas.LogLevelWarn,
+		ModuleLevels: map[string]schemas.LogLevel{"core": schemas.LogLevelDebug},
+		Sinks: []schemas.LogSinkConfig{
+			{Type: "capture"},
+			{Type: "capture-errors", Level: schemas.LogLevelError},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to configure logger: %v", err)
+	}
+	core := logger.WithModule("core")
+	governance := logger.WithModule("governance")
+
+	core.Debug("core %s", "debug")
+	governance.Info("governance info")
+	governance.Error("governance error")
+
+	if len(capture.entries) != 2 {
+		t.Fatalf("expected the core debug and governance error entries, got %v", capture.entries)
+	}
+	if capture.entries[0]["module"] != "core" || capture.entries[0]["message"] != "core debug" || capture.entries[0]["level"] != "debug" {
+		t.Errorf("expected a debug entry of the core module, got %v", capture.entries[0])
+	}
+	if len(errorsCapture.entries) != 1 || errorsCapture.entries[0]["module"] != "governance" {
+		t.Errorf("expected only the governance error in the error sink, got %v", errorsCapture.entries)
+	}
+
+	logger.SetLevels(schemas.LogLevelInfo, nil)
+	core.Debug("core debug after change")
+	governance.Info("governance info after change")
+	if len(capture.entries) != 3 || capture.entries[2]["message"] != "governance info after change" {
+		t.Errorf("expected the levels change to apply to the loggers of the modules, got %v", capture.entries)
+	}
+	if modules := logger.Modules(); len(modules) != 2 || modules[0] != "core" || modules[1] != "governance" {
+		t.Errorf("expected the core and governance modules, got %v", modules)
+	}
+}
+
+// TestFileLogSink_Rotation tests that the log file is rotated once it reaches its max size, keeping the max backups
+func TestFileLogSink_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "bifrost.log")
+	sink, err := newFileLogSink(schemas.LogSinkConfig{Type: schemas.LogSinkTypeFile, File: &schemas.FileLogSinkConfig{Path: path, MaxBackups: 2}})
+	if err != nil {
+		t.Fatalf("failed to create file log sink: %v", err)
+	}
+	defer sink.Close()
+	// A small max size rotates on every entry
+	sink.(*fileLogSink).maxSize = 10
+	for _, entry := range []string{"first entry\n", "second entry\n", "third entry\n", "fourth entry\n"} {
+		if err := sink.WriteEntry(schemas.LogLevelInfo, []byte(entry)); err != nil {
+			t.Fatalf("failed to write entry: %v", err)
+		}
+	}
+
+	expected := map[string]string{path: "fourth entry\n", path + ".1": "third entry\n", path + ".2": "second entry\n"}
+	for file, content := range expected {
+		data, err := os.ReadFile(file)
+		if err != nil || string(data) != content {
+			t.Errorf("expected %s to contain %q, got %q (%v)", file, content, data, err)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 backups to be kept")
+	}
+}
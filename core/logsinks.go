@@ -0,0 +1,479 @@
+package bifrost
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/rs/zerolog"
+)
+
+// LogSinkFactory creates a log sink from its config
+type LogSinkFactory func(config schemas.LogSinkConfig) (schemas.LogSink, error)
+
+// logSinkFactories are the factories of the log sinks by type, stdout and stderr are handled by the logger itself
+var logSinkFactories = struct {
+	sync.RWMutex
+	factories map[schemas.LogSinkType]LogSinkFactory
+}{
+	factories: map[schemas.LogSinkType]LogSinkFactory{
+		schemas.LogSinkTypeFile:   newFileLogSink,
+		schemas.LogSinkTypeSyslog: newSyslogLogSink,
+		schemas.LogSinkTypeOTLP:   newOTLPLogSink,
+	},
+}
+
+// errLogSinkClosed is returned by the sinks written to once closed
+var errLogSinkClosed = errors.New("log sink is closed")
+
+// RegisterLogSink registers the factory of a type of log sinks, so that plugins can add destinations to the logs.
+// The sinks of the type are created when a LoggingConfig using it is applied. Registering a built-in type replaces it.
+func RegisterLogSink(sinkType schemas.LogSinkType, factory LogSinkFactory) {
+	logSinkFactories.Lock()
+	defer logSinkFactories.Unlock()
+	logSinkFactories.factories[sinkType] = factory
+}
+
+// leveledLogSink is a sink with the lowest level of the entries written to it
+type leveledLogSink struct {
+	schemas.LogSink
+	level schemas.LogLevel
+}
+
+// newLogSinks creates the sinks of the configs, and closes the ones already created if one fails.
+// Without configs, entries are written to stdout and errors to stderr.
+func newLogSinks(configs []schemas.LogSinkConfig, outputType schemas.LoggerOutputType) ([]leveledLogSink, error) {
+	if len(configs) == 0 {
+		return []leveledLogSink{{LogSink: &consoleLogSink{
+			stdout: consoleWriter(os.Stdout, outputType),
+			stderr: consoleWriter(os.Stderr, outputType),
+		}}}, nil
+	}
+	sinks := make([]leveledLogSink, 0, len(configs))
+	for _, config := range configs {
+		sink, err := newLogSink(config, outputType)
+		if err != nil {
+			for _, created := range sinks {
+				created.Close()
+			}
+			return nil, fmt.Errorf("failed to create %s log sink: %w", config.Type, err)
+		}
+		sinks = append(sinks, leveledLogSink{LogSink: sink, level: config.Level})
+	}
+	return sinks, nil
+}
+
+// newLogSink creates the sink of a config
+func newLogSink(config schemas.LogSinkConfig, outputType schemas.LoggerOutputType) (schemas.LogSink, error) {
+	switch config.Type {
+	case schemas.LogSinkTypeStdout:
+		return &writerLogSink{writer: consoleWriter(os.Stdout, outputType)}, nil
+	case schemas.LogSinkTypeStderr:
+		return &writerLogSink{writer: consoleWriter(os.Stderr, outputType)}, nil
+	}
+	logSinkFactories.RLock()
+	factory, ok := logSinkFactories.factories[config.Type]
+	logSinkFactories.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown log sink type %q", config.Type)
+	}
+	return factory(config)
+}
+
+// consoleWriter returns the writer of a console output in the output type
+func consoleWriter(out *os.File, outputType schemas.LoggerOutputType) io.Writer {
+	if outputType == schemas.LoggerOutputTypePretty {
+		return zerolog.ConsoleWriter{Out: out}
+	}
+	return out
+}
+
+// logSinksWriter writes the entries of a zerolog logger to the sinks whose level they reach
+type logSinksWriter []leveledLogSink
+
+// Write writes an entry without level, as an info entry
+func (w logSinksWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel writes the entry to every sink whose level it reaches, a failing sink does not keep the entry from the
+// others
+func (w logSinksWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	entryLevel := fromZerologLevel(level)
+	var errs []error
+	for _, sink := range w {
+		if sink.level != "" && logLevelRank(entryLevel) < logLevelRank(sink.level) {
+			continue
+		}
+		if err := sink.WriteEntry(entryLevel, p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(p), errors.Join(errs...)
+}
+
+// writerLogSink writes the entries to a writer it does not own, such as stdout
+type writerLogSink struct {
+	writer io.Writer
+}
+
+func (s *writerLogSink) WriteEntry(level schemas.LogLevel, entry []byte) error {
+	_, err := s.writer.Write(entry)
+	return err
+}
+
+func (s *writerLogSink) Close() error { return nil }
+
+// consoleLogSink writes the error entries to stderr and the others to stdout, it is the sink of loggers without sinks
+type consoleLogSink struct {
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func (s *consoleLogSink) WriteEntry(level schemas.LogLevel, entry []byte) error {
+	writer := s.stdout
+	if level == schemas.LogLevelError {
+		writer = s.stderr
+	}
+	_, err := writer.Write(entry)
+	return err
+}
+
+func (s *consoleLogSink) Close() error { return nil }
+
+// fileLogSink appends the entries to a file, rotated to <path>.1 once it reaches its max size. The previous rotated
+// files are shifted to <path>.2 and so on, up to the max backups.
+type fileLogSink struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// newFileLogSink opens the log file of the config, creating its directory if needed
+func newFileLogSink(config schemas.LogSinkConfig) (schemas.LogSink, error) {
+	if config.File == nil || config.File.Path == "" {
+		return nil, fmt.Errorf("file log sink requires a path")
+	}
+	sink := &fileLogSink{
+		path:       config.File.Path,
+		maxSize:    int64(config.File.MaxSizeMB) << 20,
+		maxBackups: config.File.MaxBackups,
+	}
+	if sink.maxSize <= 0 {
+		sink.maxSize = schemas.DefaultLogFileMaxSizeMB << 20
+	}
+	if sink.maxBackups <= 0 {
+		sink.maxBackups = schemas.DefaultLogFileMaxBackups
+	}
+	if err := os.MkdirAll(filepath.Dir(sink.path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	if err := sink.open(); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+// open opens the log file for appending, the caller holds the lock
+func (s *fileLogSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// backupPath returns the path of the i-th rotated file
+func (s *fileLogSink) backupPath(i int) string {
+	return s.path + "." + strconv.Itoa(i)
+}
+
+// rotate shifts the rotated files, drops the oldest one, and moves the log file to the first backup, the caller
+// holds the lock
+func (s *fileLogSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	s.file = nil
+	os.Remove(s.backupPath(s.maxBackups))
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		os.Rename(s.backupPath(i), s.backupPath(i+1))
+	}
+	if err := os.Rename(s.path, s.backupPath(1)); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	return s.open()
+}
+
+func (s *fileLogSink) WriteEntry(level schemas.LogLevel, entry []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return errLogSinkClosed
+	}
+	if s.size > 0 && s.size+int64(len(entry)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.file.Write(entry)
+	s.size += int64(n)
+	return err
+}
+
+func (s *fileLogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// Batching of the OTLP log export
+const (
+	otlpLogQueueSize     = 8192 // Entries waiting for export, entries logged while the queue is full are dropped
+	otlpLogBatchSize     = 512
+	otlpLogFlushInterval = time.Second
+	otlpLogExportTimeout = 10 * time.Second
+)
+
+// otlpSeverityNumbers are the OpenTelemetry severity numbers of the zerolog levels
+var otlpSeverityNumbers = map[string]int{
+	"trace": 1,
+	"debug": 5,
+	"info":  9,
+	"warn":  13,
+	"error": 17,
+	"fatal": 21,
+	"panic": 21,
+}
+
+// otlpLogSink exports the entries to an OpenTelemetry collector with OTLP/HTTP in JSON, in batches sent from a
+// goroutine so that logging never waits for the collector
+type otlpLogSink struct {
+	config  schemas.OTLPLogSinkConfig
+	client  *http.Client
+	mu      sync.RWMutex // guards the queue against writes once closed
+	closed  bool
+	queue   chan []byte
+	done    chan struct{}
+	dropped atomic.Int64
+}
+
+// otlpAnyValue is an attribute or body value of OTLP
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// otlpKeyValue is an attribute of OTLP
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+// otlpLogRecord is a log record of OTLP
+type otlpLogRecord struct {
+	TimeUnixNano   string         `json:"timeUnixNano"`
+	SeverityNumber int            `json:"severityNumber,omitempty"`
+	SeverityText   string         `json:"severityText,omitempty"`
+	Body           otlpAnyValue   `json:"body"`
+	Attributes     []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+// newOTLPLogSink starts the export of the entries to the endpoint of the config
+func newOTLPLogSink(config schemas.LogSinkConfig) (schemas.LogSink, error) {
+	if config.OTLP == nil || config.OTLP.Endpoint == "" {
+		return nil, fmt.Errorf("otlp log sink requires an endpoint")
+	}
+	sink := &otlpLogSink{
+		config: *config.OTLP,
+		client: &http.Client{Timeout: otlpLogExportTimeout},
+		queue:  make(chan []byte, otlpLogQueueSize),
+		done:   make(chan struct{}),
+	}
+	if sink.config.ServiceName == "" {
+		sink.config.ServiceName = "bifrost"
+	}
+	go sink.run()
+	return sink, nil
+}
+
+func (s *otlpLogSink) WriteEntry(level schemas.LogLevel, entry []byte) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return errLogSinkClosed
+	}
+	select {
+	case s.queue <- bytes.Clone(entry):
+	default:
+		s.dropped.Add(1)
+	}
+	return nil
+}
+
+// Close exports the queued entries and stops the export
+func (s *otlpLogSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.queue)
+	s.mu.Unlock()
+	<-s.done
+	return nil
+}
+
+// run exports the queued entries once a batch is full or the flush interval elapsed, until the queue is closed
+func (s *otlpLogSink) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(otlpLogFlushInterval)
+	defer ticker.Stop()
+	batch := make([][]byte, 0, otlpLogBatchSize)
+	for {
+		select {
+		case entry, ok := <-s.queue:
+			if !ok {
+				s.export(batch)
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= otlpLogBatchSize {
+				s.export(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			s.export(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// export sends a batch of entries to the collector. Failures are reported on stderr, since logging them would queue
+// more entries to the failing collector.
+func (s *otlpLogSink) export(batch [][]byte) {
+	if dropped := s.dropped.Swap(0); dropped > 0 {
+		fmt.Fprintf(os.Stderr, "otlp log sink: dropped %d log entries, the export queue was full\n", dropped)
+	}
+	if len(batch) == 0 {
+		return
+	}
+	records := make([]otlpLogRecord, 0, len(batch))
+	for _, entry := range batch {
+		records = append(records, otlpLogRecordFromEntry(entry))
+	}
+	serviceName := s.config.ServiceName
+	payload := map[string]any{
+		"resourceLogs": []map[string]any{{
+			"resource": map[string]any{
+				"attributes": []otlpKeyValue{{Key: "service.name", Value: otlpAnyValue{StringValue: &serviceName}}},
+			},
+			"scopeLogs": []map[string]any{{
+				"scope":      map[string]any{"name": "bifrost"},
+				"logRecords": records,
+			}},
+		}},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "otlp log sink: failed to encode %d log entries: %v\n", len(batch), err)
+		return
+	}
+	req, err := http.NewRequest(http.MethodPost, s.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "otlp log sink: failed to create export request: %v\n", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range s.config.Headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "otlp log sink: failed to export %d log entries: %v\n", len(batch), err)
+		return
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		fmt.Fprintf(os.Stderr, "otlp log sink: failed to export %d log entries: collector returned status %d\n", len(batch), resp.StatusCode)
+	}
+}
+
+// otlpLogRecordFromEntry converts a JSON entry of zerolog to a log record. The time, level and message of the entry
+// are the fields of the record, its other fields are attributes. Entries that are not JSON are kept as the body.
+func otlpLogRecordFromEntry(entry []byte) otlpLogRecord {
+	record := otlpLogRecord{TimeUnixNano: strconv.FormatInt(time.Now().UnixNano(), 10)}
+	var fields map[string]any
+	if err := json.Unmarshal(entry, &fields); err != nil {
+		body := strings.TrimRight(string(entry), "\n")
+		record.Body = otlpAnyValue{StringValue: &body}
+		return record
+	}
+	for key, value := range fields {
+		switch key {
+		case zerolog.TimestampFieldName:
+			if text, ok := value.(string); ok {
+				if timestamp, err := time.Parse(time.RFC3339, text); err == nil {
+					record.TimeUnixNano = strconv.FormatInt(timestamp.UnixNano(), 10)
+				}
+			}
+		case zerolog.LevelFieldName:
+			if text, ok := value.(string); ok {
+				record.SeverityText = strings.ToUpper(text)
+				record.SeverityNumber = otlpSeverityNumbers[text]
+			}
+		case zerolog.MessageFieldName:
+			record.Body = otlpValue(value)
+		default:
+			record.Attributes = append(record.Attributes, otlpKeyValue{Key: key, Value: otlpValue(value)})
+		}
+	}
+	sort.Slice(record.Attributes, func(i, j int) bool { return record.Attributes[i].Key < record.Attributes[j].Key })
+	return record
+}
+
+// otlpValue converts a JSON value to an OTLP value, objects and arrays are kept as JSON strings
+func otlpValue(value any) otlpAnyValue {
+	switch v := value.(type) {
+	case string:
+		return otlpAnyValue{StringValue: &v}
+	case bool:
+		return otlpAnyValue{BoolValue: &v}
+	case float64:
+		return otlpAnyValue{DoubleValue: &v}
+	default:
+		encoded, _ := json.Marshal(v)
+		text := string(encoded)
+		return otlpAnyValue{StringValue: &text}
+	}
+}
@@ -0,0 +1,50 @@
+//go:build !windows && !plan9
+
+package bifrost
+
+import (
+	"bytes"
+	"fmt"
+	"log/syslog"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// syslogLogSink writes the entries to syslog, with the priority of their level
+type syslogLogSink struct {
+	writer *syslog.Writer
+}
+
+// newSyslogLogSink connects to the syslog of the config, the local syslog daemon when it has no address
+func newSyslogLogSink(config schemas.LogSinkConfig) (schemas.LogSink, error) {
+	options := schemas.SyslogLogSinkConfig{}
+	if config.Syslog != nil {
+		options = *config.Syslog
+	}
+	if options.Tag == "" {
+		options.Tag = "bifrost"
+	}
+	writer, err := syslog.Dial(options.Network, options.Address, syslog.LOG_INFO|syslog.LOG_DAEMON, options.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	return &syslogLogSink{writer: writer}, nil
+}
+
+func (s *syslogLogSink) WriteEntry(level schemas.LogLevel, entry []byte) error {
+	message := string(bytes.TrimRight(entry, "\n"))
+	switch level {
+	case schemas.LogLevelDebug:
+		return s.writer.Debug(message)
+	case schemas.LogLevelWarn:
+		return s.writer.Warning(message)
+	case schemas.LogLevelError:
+		return s.writer.Err(message)
+	default:
+		return s.writer.Info(message)
+	}
+}
+
+func (s *syslogLogSink) Close() error {
+	return s.writer.Close()
+}
@@ -0,0 +1,15 @@
+//go:build windows || plan9
+
+package bifrost
+
+import (
+	"fmt"
+	"runtime"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// newSyslogLogSink fails, syslog is not available on this platform
+func newSyslogLogSink(config schemas.LogSinkConfig) (schemas.LogSink, error) {
+	return nil, fmt.Errorf("syslog log sink is not supported on %s", runtime.GOOS)
+}
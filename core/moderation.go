@@ -0,0 +1,216 @@
+package bifrost
+
+import (
+	"sort"
+	"strings"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// moderationFinishReasons are the finish reasons of the providers for outputs stopped by their content filter or
+// safety system, with the category they stand for. OpenAI and Azure use content_filter, Bedrock content_filtered,
+// Gemini and Vertex their safety reasons.
+var moderationFinishReasons = map[string]string{
+	"content_filter":     "content_filter",
+	"content_filtered":   "content_filter",
+	"SAFETY":             "safety",
+	"IMAGE_SAFETY":       "image_safety",
+	"BLOCKLIST":          "blocklist",
+	"PROHIBITED_CONTENT": "prohibited_content",
+	"SPII":               "sensitive_information",
+}
+
+// refusalFinishReason is the finish reason of Anthropic models refusing to answer
+const refusalFinishReason = "refusal"
+
+// moderationActionRank orders the actions from the weakest to the strongest, the strongest action of a response is
+// the one reported
+var moderationActionRank = map[schemas.ModerationAction]int{
+	"":                               0,
+	schemas.ModerationActionMasked:   1,
+	schemas.ModerationActionRefused:  2,
+	schemas.ModerationActionFiltered: 3,
+	schemas.ModerationActionBlocked:  4,
+}
+
+// responseModeration returns the moderation outcome of a text completion, chat or responses response, from the content
+// filter results, refusals, finish reasons and guardrail traces the providers return, nil when the provider reported
+// none of them
+func responseModeration(response *schemas.BifrostResponse) *schemas.BifrostModeration {
+	moderation := &schemas.BifrostModeration{}
+	reported := false
+	switch {
+	case response.TextCompletionResponse != nil:
+		reported = addChoicesModeration(moderation, response.TextCompletionResponse.Choices)
+	case response.ChatResponse != nil:
+		reported = addChoicesModeration(moderation, response.ChatResponse.Choices)
+		for _, prompt := range response.ChatResponse.PromptFilterResults {
+			addContentFilterResults(moderation, "input", prompt.ContentFilterResults)
+			reported = true
+		}
+	case response.ResponsesResponse != nil:
+		reported = addResponsesModeration(moderation, response.ResponsesResponse)
+	default:
+		return nil
+	}
+	if guardrail := response.GetExtraFields().Guardrail; guardrail != nil {
+		addGuardrailModeration(moderation, guardrail)
+		reported = true
+	}
+	if !reported {
+		return nil
+	}
+	for _, category := range moderation.Categories {
+		if category.Flagged {
+			moderation.Flagged = true
+		}
+	}
+	if moderation.Action != "" {
+		moderation.Flagged = true
+	}
+	return moderation
+}
+
+// addChoicesModeration adds the content filter results, finish reasons and refusals of the choices, and reports
+// whether any of them was found
+func addChoicesModeration(moderation *schemas.BifrostModeration, choices []schemas.BifrostResponseChoice) bool {
+	reported := false
+	for _, choice := range choices {
+		if len(choice.ContentFilterResults) > 0 {
+			addContentFilterResults(moderation, "output", choice.ContentFilterResults)
+			reported = true
+		}
+		if choice.FinishReason != nil {
+			if category, ok := moderationFinishReasons[*choice.FinishReason]; ok {
+				setModerationAction(moderation, schemas.ModerationActionFiltered)
+				addModerationCategory(moderation, schemas.BifrostModerationCategory{Source: "output", Category: category, Flagged: true, Action: string(schemas.ModerationActionFiltered)})
+				reported = true
+			}
+			if *choice.FinishReason == refusalFinishReason {
+				setModerationAction(moderation, schemas.ModerationActionRefused)
+				reported = true
+			}
+		}
+		if message := choice.ChatNonStreamResponseChoice; message != nil && message.Message != nil && message.Message.ChatAssistantMessage != nil {
+			if refusal := message.Message.ChatAssistantMessage.Refusal; refusal != nil && *refusal != "" {
+				setModerationAction(moderation, schemas.ModerationActionRefused)
+				if moderation.Reason == "" {
+					moderation.Reason = *refusal
+				}
+				reported = true
+			}
+		}
+	}
+	return reported
+}
+
+// addResponsesModeration adds the refusals of the output messages of a responses response and its content filter
+// incomplete reason, and reports whether any of them was found
+func addResponsesModeration(moderation *schemas.BifrostModeration, response *schemas.BifrostResponsesResponse) bool {
+	reported := false
+	if response.IncompleteDetails != nil && response.IncompleteDetails.Reason == "content_filter" {
+		setModerationAction(moderation, schemas.ModerationActionFiltered)
+		addModerationCategory(moderation, schemas.BifrostModerationCategory{Source: "output", Category: "content_filter", Flagged: true, Action: string(schemas.ModerationActionFiltered)})
+		reported = true
+	}
+	for _, message := range response.Output {
+		if message.Content == nil {
+			continue
+		}
+		for _, block := range message.Content.ContentBlocks {
+			if block.Type != schemas.ResponsesOutputMessageContentTypeRefusal || block.ResponsesOutputMessageContentRefusal == nil {
+				continue
+			}
+			setModerationAction(moderation, schemas.ModerationActionRefused)
+			if moderation.Reason == "" {
+				moderation.Reason = block.ResponsesOutputMessageContentRefusal.Refusal
+			}
+			reported = true
+		}
+	}
+	return reported
+}
+
+// addContentFilterResults adds the categories of Azure content filter results, in the order of their names
+func addContentFilterResults(moderation *schemas.BifrostModeration, source string, results map[string]schemas.ContentFilterResult) {
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		result := results[name]
+		category := schemas.BifrostModerationCategory{
+			Source:   source,
+			Category: name,
+			Flagged:  result.Filtered || (result.Detected != nil && *result.Detected),
+			Severity: result.Severity,
+		}
+		if result.Filtered {
+			category.Action = string(schemas.ModerationActionFiltered)
+			setModerationAction(moderation, schemas.ModerationActionFiltered)
+		}
+		addModerationCategory(moderation, category)
+	}
+}
+
+// addGuardrailModeration adds the findings of a provider guardrail, such as Bedrock Guardrails. Interventions masking
+// content let the response through, the others block it.
+func addGuardrailModeration(moderation *schemas.BifrostModeration, guardrail *schemas.BifrostGuardrail) {
+	blocked, masked := false, false
+	for _, finding := range guardrail.Findings {
+		category := finding.Type
+		if category == "" {
+			category = finding.Policy
+		}
+		flagged := finding.Action != "" && finding.Action != "NONE"
+		if flagged && finding.Action == "ANONYMIZED" {
+			masked = true
+		} else if flagged {
+			blocked = true
+		}
+		addModerationCategory(moderation, schemas.BifrostModerationCategory{
+			Source:   finding.Source,
+			Category: strings.ToLower(category),
+			Flagged:  flagged,
+			Severity: finding.Confidence,
+			Action:   finding.Action,
+		})
+	}
+	if guardrail.Intervened {
+		if masked && !blocked {
+			setModerationAction(moderation, schemas.ModerationActionMasked)
+		} else {
+			setModerationAction(moderation, schemas.ModerationActionBlocked)
+		}
+	}
+	if moderation.Reason == "" {
+		moderation.Reason = guardrail.Reason
+	}
+}
+
+// addModerationCategory adds a category, merging it with the category of the same source and name already added
+func addModerationCategory(moderation *schemas.BifrostModeration, category schemas.BifrostModerationCategory) {
+	for i := range moderation.Categories {
+		existing := &moderation.Categories[i]
+		if existing.Source != category.Source || existing.Category != category.Category {
+			continue
+		}
+		existing.Flagged = existing.Flagged || category.Flagged
+		if existing.Severity == "" {
+			existing.Severity = category.Severity
+		}
+		if existing.Action == "" {
+			existing.Action = category.Action
+		}
+		return
+	}
+	moderation.Categories = append(moderation.Categories, category)
+}
+
+// setModerationAction sets the action of the moderation when it is stronger than the current one
+func setModerationAction(moderation *schemas.BifrostModeration, action schemas.ModerationAction) {
+	if moderationActionRank[action] > moderationActionRank[moderation.Action] {
+		moderation.Action = action
+	}
+}
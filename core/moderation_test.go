@@ -0,0 +1,138 @@
+package bifrost
+
+import (
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestResponseModeration tests that the content filter results, refusals, finish reasons and guardrail traces of the
+// providers are normalized into the same moderation shape
+func TestResponseModeration(t *testing.T) {
+	chatResponse := func(finishReason string, message *schemas.ChatMessage) *schemas.BifrostResponse {
+		return &schemas.BifrostResponse{ChatResponse: &schemas.BifrostChatResponse{Choices: []schemas.BifrostResponseChoice{{
+			FinishReason:                schemas.Ptr(finishReason),
+			ChatNonStreamResponseChoice: &schemas.ChatNonStreamResponseChoice{Message: message},
+		}}}}
+	}
+
+	tests := []struct {
+		name         string
+		response     *schemas.BifrostResponse
+		wantNil      bool
+		wantAction   schemas.ModerationAction
+		wantFlagged  bool
+		wantCategory string
+		wantReason   string
+	}{
+		{
+			name:     "no moderation",
+			response: chatResponse("stop", nil),
+			wantNil:  true,
+		},
+		{
+			name: "azure content filter results",
+			response: &schemas.BifrostResponse{ChatResponse: &schemas.BifrostChatResponse{
+				Choices: []schemas.BifrostResponseChoice{{
+					FinishReason: schemas.Ptr("content_filter"),
+					ContentFilterResults: map[string]schemas.ContentFilterResult{
+						"hate":     {Filtered: false, Severity: "safe"},
+						"violence": {Filtered: true, Severity: "high"},
+					},
+				}},
+				PromptFilterResults: []schemas.PromptFilterResult{{ContentFilterResults: map[string]schemas.ContentFilterResult{
+					"jailbreak": {Detected: schemas.Ptr(false)},
+				}}},
+			}},
+			wantAction:   schemas.ModerationActionFiltered,
+			wantFlagged:  true,
+			wantCategory: "violence",
+		},
+		{
+			name: "openai refusal",
+			response: chatResponse("stop", &schemas.ChatMessage{ChatAssistantMessage: &schemas.ChatAssistantMessage{
+				Refusal: schemas.Ptr("I can't help with that."),
+			}}),
+			wantAction:  schemas.ModerationActionRefused,
+			wantFlagged: true,
+			wantReason:  "I can't help with that.",
+		},
+		{
+			name:        "anthropic refusal stop reason",
+			response:    chatResponse("refusal", nil),
+			wantAction:  schemas.ModerationActionRefused,
+			wantFlagged: true,
+		},
+		{
+			name:         "gemini safety finish reason",
+			response:     chatResponse("SAFETY", nil),
+			wantAction:   schemas.ModerationActionFiltered,
+			wantFlagged:  true,
+			wantCategory: "safety",
+		},
+		{
+			name: "bedrock guardrail",
+			response: &schemas.BifrostResponse{ChatResponse: &schemas.BifrostChatResponse{ExtraFields: schemas.BifrostResponseExtraFields{
+				Guardrail: &schemas.BifrostGuardrail{Intervened: true, Findings: []schemas.BifrostGuardrailFinding{
+					{Source: "input", Policy: "content", Type: "VIOLENCE", Confidence: "HIGH", Action: "BLOCKED"},
+				}},
+			}}},
+			wantAction:   schemas.ModerationActionBlocked,
+			wantFlagged:  true,
+			wantCategory: "violence",
+		},
+		{
+			name: "bedrock guardrail masking",
+			response: &schemas.BifrostResponse{ChatResponse: &schemas.BifrostChatResponse{ExtraFields: schemas.BifrostResponseExtraFields{
+				Guardrail: &schemas.BifrostGuardrail{Intervened: true, Findings: []schemas.BifrostGuardrailFinding{
+					{Source: "output", Policy: "sensitive_information", Type: "EMAIL", Action: "ANONYMIZED"},
+				}},
+			}}},
+			wantAction:   schemas.ModerationActionMasked,
+			wantFlagged:  true,
+			wantCategory: "email",
+		},
+		{
+			name: "responses refusal",
+			response: &schemas.BifrostResponse{ResponsesResponse: &schemas.BifrostResponsesResponse{Output: []schemas.ResponsesMessage{{
+				Content: &schemas.ResponsesMessageContent{ContentBlocks: []schemas.ResponsesMessageContentBlock{{
+					Type:                                 schemas.ResponsesOutputMessageContentTypeRefusal,
+					ResponsesOutputMessageContentRefusal: &schemas.ResponsesOutputMessageContentRefusal{Refusal: "No."},
+				}}},
+			}}}},
+			wantAction:  schemas.ModerationActionRefused,
+			wantFlagged: true,
+			wantReason:  "No.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			moderation := responseModeration(tt.response)
+			if tt.wantNil {
+				if moderation != nil {
+					t.Fatalf("expected no moderation, got %+v", moderation)
+				}
+				return
+			}
+			if moderation == nil {
+				t.Fatal("expected a moderation")
+			}
+			if moderation.Action != tt.wantAction || moderation.Flagged != tt.wantFlagged {
+				t.Errorf("expected action %q flagged %v, got %+v", tt.wantAction, tt.wantFlagged, moderation)
+			}
+			if tt.wantReason != "" && moderation.Reason != tt.wantReason {
+				t.Errorf("expected reason %q, got %q", tt.wantReason, moderation.Reason)
+			}
+			if tt.wantCategory == "" {
+				return
+			}
+			for _, category := range moderation.Categories {
+				if category.Category == tt.wantCategory && category.Flagged {
+					return
+				}
+			}
+			t.Errorf("expected category %q to be flagged, got %+v", tt.wantCategory, moderation.Categories)
+		})
+	}
+}
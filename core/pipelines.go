@@ -0,0 +1,159 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// pipelineSet is the set of configured pipelines indexed for lookup on the request path
+type pipelineSet struct {
+	byModel      map[string]*schemas.Pipeline
+	byVirtualKey map[string]*schemas.Pipeline
+	managed      map[string]struct{} // Plugins referenced by any pipeline stage
+}
+
+// newPipelineSet validates and indexes the pipelines
+func newPipelineSet(pipelines []schemas.Pipeline) (*pipelineSet, error) {
+	if err := schemas.ValidatePipelines(pipelines); err != nil {
+		return nil, err
+	}
+	set := &pipelineSet{
+		byModel:      make(map[string]*schemas.Pipeline),
+		byVirtualKey: make(map[string]*schemas.Pipeline),
+		managed:      make(map[string]struct{}),
+	}
+	for i := range pipelines {
+		pipeline := pipelines[i]
+		for _, stage := range pipeline.Stages {
+			set.managed[stage.Plugin] = struct{}{}
+		}
+		for _, model := range pipeline.Models {
+			set.byModel[model] = &pipeline
+		}
+		for _, vkID := range pipeline.VirtualKeyIDs {
+			set.byVirtualKey[vkID] = &pipeline
+		}
+	}
+	return set, nil
+}
+
+// manages reports whether the plugin is referenced by a pipeline stage
+func (s *pipelineSet) manages(pluginName string) bool {
+	_, ok := s.managed[pluginName]
+	return ok
+}
+
+// resolve returns the pipeline attached to the virtual key of the request, or else to its requested model
+func (s *pipelineSet) resolve(ctx *schemas.BifrostContext, req *schemas.BifrostRequest) *schemas.Pipeline {
+	if vkID, ok := ctx.Value(schemas.BifrostContextKeyGovernanceVirtualKeyID).(string); ok && vkID != "" {
+		if pipeline, ok := s.byVirtualKey[vkID]; ok {
+			return pipeline
+		}
+	}
+	if req == nil {
+		return nil
+	}
+	provider, model, _ := req.GetRequestFields()
+	if pipeline, ok := s.byModel[string(provider)+"/"+model]; ok {
+		return pipeline
+	}
+	return s.byModel[model]
+}
+
+// newPipelineStageUnavailableError creates the error returned when a guardrails stage cannot run
+func newPipelineStageUnavailableError(pipelineName string, pluginName string) *schemas.BifrostError {
+	return &schemas.BifrostError{
+		IsBifrostError: false,
+		StatusCode:     schemas.Ptr(503),
+		Type:           schemas.Ptr("pipeline_stage_unavailable"),
+		AllowFallbacks: schemas.Ptr(false),
+		Error: &schemas.ErrorField{
+			Message: fmt.Sprintf("guardrails plugin %s of pipeline %s is not loaded", pluginName, pipelineName),
+		},
+	}
+}
+
+// applyPipeline replaces the managed plugins, from index from on, with the stages of the pipeline attached
+// to the request. It runs when the first managed plugin is reached, so plugins registered before it, such as
+// governance resolving the virtual key, can influence the selection. Stage plugins that are not loaded are
+// skipped, except guardrails stages, which short-circuit the request with an error.
+func (p *PluginPipeline) applyPipeline(ctx *schemas.BifrostContext, req *schemas.BifrostRequest, from int) *schemas.PluginShortCircuit {
+	pipeline := p.pipelines.resolve(ctx, req)
+	if pipeline == nil {
+		return nil
+	}
+	ctx.SetValue(schemas.BifrostContextKeyPipeline, pipeline.Name)
+
+	plugins := make([]schemas.Plugin, 0, len(p.plugins))
+	plugins = append(plugins, p.plugins[:from]...)
+	for _, stage := range pipeline.Stages {
+		index := slices.IndexFunc(p.plugins, func(plugin schemas.Plugin) bool { return plugin.GetName() == stage.Plugin })
+		if index < 0 {
+			if stage.Type == schemas.PipelineStageGuardrails {
+				return &schemas.PluginShortCircuit{Error: newPipelineStageUnavailableError(pipeline.Name, stage.Plugin)}
+			}
+			p.logger.Warn("skipping %s stage of pipeline %s: plugin %s is not loaded", stage.Type, pipeline.Name, stage.Plugin)
+			continue
+		}
+		plugins = append(plugins, p.plugins[index])
+	}
+	for _, plugin := range p.plugins[from:] {
+		if !p.pipelines.manages(plugin.GetName()) {
+			plugins = append(plugins, plugin)
+		}
+	}
+	p.plugins = plugins
+	return nil
+}
+
+// UpdatePipelines replaces the transformation pipelines, an empty list runs all plugins for every request.
+// Returns an error and keeps the current pipelines if the pipelines are invalid.
+func (bifrost *Bifrost) UpdatePipelines(pipelines []schemas.Pipeline) error {
+	if len(pipelines) == 0 {
+		bifrost.pipelines.Store(nil)
+		return nil
+	}
+	set, err := newPipelineSet(pipelines)
+	if err != nil {
+		return err
+	}
+	bifrost.pipelines.Store(set)
+	return nil
+}
+
+// GetEffectivePlugins returns the names of the plugins that run, in order, for a request to the model
+// with the virtual key, and the name of the pipeline applied to it, if any.
+// It assumes the pipeline is selected before any plugin modifies the request, and returns an error
+// if the request would be rejected because a guardrails stage plugin is not loaded.
+func (bifrost *Bifrost) GetEffectivePlugins(provider schemas.ModelProvider, model string, virtualKeyID string) ([]string, string, error) {
+	pipeline := bifrost.getPluginPipeline()
+	defer bifrost.releasePluginPipeline(pipeline)
+
+	var pipelineName string
+	var err error
+	if pipeline.pipelines != nil {
+		ctx := schemas.NewBifrostContext(context.Background(), schemas.NoDeadline)
+		if virtualKeyID != "" {
+			ctx.SetValue(schemas.BifrostContextKeyGovernanceVirtualKeyID, virtualKeyID)
+		}
+		req := &schemas.BifrostRequest{
+			RequestType: schemas.ChatCompletionRequest,
+			ChatRequest: &schemas.BifrostChatRequest{Provider: provider, Model: model},
+		}
+		if index := slices.IndexFunc(pipeline.plugins, func(plugin schemas.Plugin) bool { return pipeline.pipelines.manages(plugin.GetName()) }); index >= 0 {
+			if shortCircuit := pipeline.applyPipeline(ctx, req, index); shortCircuit != nil {
+				pipeline.plugins = pipeline.plugins[:index]
+				err = fmt.Errorf("%s", shortCircuit.Error.Error.Message)
+			}
+			pipelineName, _ = ctx.Value(schemas.BifrostContextKeyPipeline).(string)
+		}
+	}
+	names := make([]string, 0, len(pipeline.plugins))
+	for _, plugin := range pipeline.plugins {
+		names = append(names, plugin.GetName())
+	}
+	return names, pipelineName, err
+}
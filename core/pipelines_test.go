@@ -0,0 +1,219 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// orderPlugin is a test plugin recording the order in which PreHooks run, optionally setting the virtual key ID like governance does
+type orderPlugin struct {
+	name         string
+	order        *[]string
+	postOrder    *[]string // Records the PostHooks when set
+	virtualKeyID string
+}
+
+func (p *orderPlugin) GetName() string { return p.name }
+
+func (p *orderPlugin) TransportInterceptor(ctx *schemas.BifrostContext, url string, headers map[string]string, body map[string]any) (map[string]string, map[string]any, error) {
+	return headers, body, nil
+}
+
+func (p *orderPlugin) PreHook(ctx *schemas.BifrostContext, req *schemas.BifrostRequest) (*schemas.BifrostRequest, *schemas.PluginShortCircuit, error) {
+	*p.order = append(*p.order, p.name)
+	if p.virtualKeyID != "" {
+		ctx.SetValue(schemas.BifrostContextKeyGovernanceVirtualKeyID, p.virtualKeyID)
+	}
+	return req, nil, nil
+}
+
+func (p *orderPlugin) PostHook(ctx *schemas.BifrostContext, result *schemas.BifrostResponse, err *schemas.BifrostError) (*schemas.BifrostResponse, *schemas.BifrostError, error) {
+	if p.postOrder != nil {
+		*p.postOrder = append(*p.postOrder, p.name)
+	}
+	return result, err, nil
+}
+
+func (p *orderPlugin) Cleanup() error { return nil }
+
+var testPipelines = []schemas.Pipeline{
+	{
+		Name: "strict",
+		Stages: []schemas.PipelineStage{
+			{Type: schemas.PipelineStageGuardrails, Plugin: "guard"},
+			{Type: schemas.PipelineStageCache, Plugin: "cache"},
+		},
+		VirtualKeyIDs: []string{"vk-strict"},
+	},
+	{
+		Name:   "fast",
+		Stages: []schemas.PipelineStage{{Type: schemas.PipelineStageCache, Plugin: "cache"}},
+		Models: []string{"gpt-4o"},
+	},
+}
+
+// TestValidatePipelines tests stage ordering and attachment validation
+func TestValidatePipelines(t *testing.T) {
+	if err := schemas.ValidatePipelines(testPipelines); err != nil {
+		t.Fatalf("expected test pipelines to be valid, got %v", err)
+	}
+	tests := map[string][]schemas.Pipeline{
+		"misordered stages": {{Name: "p", Stages: []schemas.PipelineStage{{Type: schemas.PipelineStageRoute, Plugin: "router"}, {Type: schemas.PipelineStageGuardrails, Plugin: "guard"}}}},
+		"unknown stage":     {{Name: "p", Stages: []schemas.PipelineStage{{Type: "rerank", Plugin: "reranker"}}}},
+		"duplicate plugin":  {{Name: "p", Stages: []schemas.PipelineStage{{Type: schemas.PipelineStageGuardrails, Plugin: "guard"}, {Type: schemas.PipelineStageGuardrails, Plugin: "guard"}}}},
+		"no stages":         {{Name: "p"}},
+		"duplicate model": {
+			{Name: "a", Stages: testPipelines[1].Stages, Models: []string{"gpt-4o"}},
+			{Name: "b", Stages: testPipelines[1].Stages, Models: []string{"gpt-4o"}},
+		},
+	}
+	for name, pipelines := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := schemas.ValidatePipelines(pipelines); err == nil {
+				t.Fatal("expected pipelines to be invalid")
+			}
+		})
+	}
+}
+
+// TestRunPreHooksAppliesPipeline tests that managed plugins are replaced by the stages of the attached pipeline
+func TestRunPreHooksAppliesPipeline(t *testing.T) {
+	set, err := newPipelineSet(testPipelines)
+	if err != nil {
+		t.Fatalf("failed to create pipeline set: %v", err)
+	}
+
+	tests := map[string]struct {
+		virtualKeyID string
+		model        string
+		expected     []string
+	}{
+		"virtual key pipeline": {"vk-strict", "gpt-4o", []string{"governance", "guard", "cache", "logging"}},
+		"model pipeline":       {"vk-other", "gpt-4o", []string{"governance", "cache", "logging"}},
+		"no pipeline":          {"", "claude-3-opus", []string{"governance", "cache", "guard", "logging"}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var order []string
+			pipeline := &PluginPipeline{
+				plugins: []schemas.Plugin{
+					&orderPlugin{name: "governance", order: &order, virtualKeyID: tt.virtualKeyID},
+					&orderPlugin{name: "cache", order: &order},
+					&orderPlugin{name: "guard", order: &order},
+					&orderPlugin{name: "logging", order: &order},
+				},
+				pipelines: set,
+				logger:    logger,
+			}
+			ctx := context.Background()
+			req := &schemas.BifrostRequest{RequestType: schemas.ChatCompletionRequest, ChatRequest: &schemas.BifrostChatRequest{Provider: schemas.OpenAI, Model: tt.model}}
+			if _, shortCircuit, count := pipeline.RunPreHooks(&ctx, req); shortCircuit != nil || count != len(tt.expected) {
+				t.Fatalf("expected %d pre-hooks to run without short-circuit, got %d, %+v", len(tt.expected), count, shortCircuit)
+			}
+			if !slices.Equal(order, tt.expected) {
+				t.Fatalf("expected plugins to run in order %v, got %v", tt.expected, order)
+			}
+		})
+	}
+}
+
+// TestPipelineMissingGuardrailsFailsClosed tests that a pipeline whose guardrails plugin is not loaded rejects requests
+func TestPipelineMissingGuardrailsFailsClosed(t *testing.T) {
+	set, err := newPipelineSet(testPipelines)
+	if err != nil {
+		t.Fatalf("failed to create pipeline set: %v", err)
+	}
+	var order []string
+	pipeline := &PluginPipeline{
+		plugins: []schemas.Plugin{
+			&orderPlugin{name: "governance", order: &order, virtualKeyID: "vk-strict"},
+			&orderPlugin{name: "cache", order: &order},
+		},
+		pipelines: set,
+		logger:    logger,
+	}
+	ctx := context.Background()
+	_, shortCircuit, count := pipeline.RunPreHooks(&ctx, &schemas.BifrostRequest{})
+	if shortCircuit == nil || shortCircuit.Error == nil || *shortCircuit.Error.StatusCode != 503 {
+		t.Fatalf("expected a 503 short-circuit, got %+v", shortCircuit)
+	}
+	if count != 1 || ctx.Value(schemas.BifrostContextKeyPipeline) != "strict" {
+		t.Fatalf("expected only governance to run under the strict pipeline, got %d pre-hooks and pipeline %v", count, ctx.Value(schemas.BifrostContextKeyPipeline))
+	}
+}
+
+// TestStreamPostHooksFollowPipeline tests that the PostHooks of the chunks of a stream run for the plugins of the
+// pipeline of the request only, in reverse order of their PreHooks
+func TestStreamPostHooksFollowPipeline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"id":"chatcmpl-1","object":"chat.completion.chunk","created":1,"model":"gpt-4o","choices":[{"index":0,"delta":{"content":"hi"},"finish_reason":null}]}`,
+			`{"id":"chatcmpl-1","object":"chat.completion.chunk","created":1,"model":"gpt-4o","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+			`[DONE]`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+	}))
+	defer server.Close()
+
+	account := NewMockAccount()
+	account.AddProvider(schemas.OpenAI, 1, 10)
+	account.configs[schemas.OpenAI].NetworkConfig.BaseURL = server.URL
+	account.configs[schemas.OpenAI].NetworkConfig.MaxRetries = 0
+
+	var order, postOrder []string
+	client, err := Init(context.Background(), schemas.BifrostConfig{
+		Account: account,
+		Plugins: []schemas.Plugin{
+			&orderPlugin{name: "governance", order: &order, postOrder: &postOrder},
+			&orderPlugin{name: "cache", order: &order, postOrder: &postOrder},
+			&orderPlugin{name: "guard", order: &order, postOrder: &postOrder},
+			&orderPlugin{name: "logging", order: &order, postOrder: &postOrder},
+		},
+		Logger: NewDefaultLogger(schemas.LogLevelError),
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize bifrost: %v", err)
+	}
+	defer client.Shutdown()
+	// The fast pipeline of gpt-4o runs cache and leaves guard out
+	if err := client.UpdatePipelines(testPipelines); err != nil {
+		t.Fatalf("failed to update pipelines: %v", err)
+	}
+
+	stream, bifrostErr := client.ChatCompletionStreamRequest(context.Background(), &schemas.BifrostChatRequest{
+		Provider: schemas.OpenAI,
+		Model:    "gpt-4o",
+		Input:    []schemas.ChatMessage{{Role: schemas.ChatMessageRoleUser, Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr("hello")}}},
+	})
+	if bifrostErr != nil {
+		t.Fatalf("stream request failed: %v", bifrostErr.Error.Message)
+	}
+	chunks := 0
+	for chunk := range stream {
+		if chunk.BifrostError != nil {
+			t.Fatalf("unexpected stream error: %v", chunk.BifrostError.Error.Message)
+		}
+		chunks++
+	}
+
+	if !slices.Equal(order, []string{"governance", "cache", "logging"}) {
+		t.Fatalf("expected the pre-hooks of the pipeline, got %v", order)
+	}
+	if chunks == 0 || len(postOrder) != 3*chunks {
+		t.Fatalf("expected the post-hooks of 3 plugins for each of the %d chunks, got %v", chunks, postOrder)
+	}
+	if slices.Contains(postOrder, "guard") {
+		t.Errorf("expected the plugin left out of the pipeline not to run its post-hook, got %v", postOrder)
+	}
+	if !slices.Equal(postOrder[:3], []string{"logging", "cache", "governance"}) {
+		t.Errorf("expected the post-hooks to run in reverse order, got %v", postOrder[:3])
+	}
+}
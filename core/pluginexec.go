@@ -0,0 +1,302 @@
+package bifrost
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// DefaultPluginCircuitCooldown is the time a plugin's circuit stays open when no cooldown is configured
+const DefaultPluginCircuitCooldown = 30 * time.Second
+
+var (
+	errPluginCircuitOpen = errors.New("plugin circuit breaker is open")
+	errPluginOverloaded  = errors.New("plugin has no free execution slot")
+	errPluginTimeout     = errors.New("plugin hook timed out")
+)
+
+// pluginExecutor enforces the execution config of a single plugin: bounded concurrency,
+// per hook timeouts and a consecutive failure circuit breaker.
+// Hooks that time out are abandoned but keep their slot until they actually return,
+// so a hung dependency can never accumulate more than MaxConcurrency goroutines.
+type pluginExecutor struct {
+	config   schemas.PluginExecutionConfig
+	timeout  time.Duration
+	cooldown time.Duration
+	slots    chan struct{} // nil when concurrency is unbounded
+
+	name     string                     // name of the plugin in the shared circuit store
+	circuits schemas.PluginCircuitStore // circuit breakers shared with the other replicas, nil keeps the circuit local
+
+	mu                  sync.Mutex
+	consecutiveFailures int
+	openUntil           time.Time
+	trialInFlight       bool // true while the single half-open trial hook is running
+}
+
+// newPluginExecutor creates an executor for the given execution config
+func newPluginExecutor(config schemas.PluginExecutionConfig) *pluginExecutor {
+	executor := &pluginExecutor{
+		config:   config,
+		timeout:  time.Duration(config.TimeoutMs) * time.Millisecond,
+		cooldown: time.Duration(config.CooldownMs) * time.Millisecond,
+	}
+	if executor.cooldown <= 0 {
+		executor.cooldown = DefaultPluginCircuitCooldown
+	}
+	if config.MaxConcurrency > 0 {
+		executor.slots = make(chan struct{}, config.MaxConcurrency)
+	}
+	return executor
+}
+
+// newSharedPluginExecutor creates the executor of a plugin whose circuit is shared through the circuit store of Bifrost
+func (bifrost *Bifrost) newSharedPluginExecutor(name string, config schemas.PluginExecutionConfig) *pluginExecutor {
+	executor := newPluginExecutor(config)
+	executor.name = name
+	executor.circuits = bifrost.pluginCircuits
+	return executor
+}
+
+// failClosed reports whether requests must fail when a hook of the plugin cannot be executed
+func (e *pluginExecutor) failClosed() bool {
+	return e.config.FailureMode == schemas.PluginFailureModeClosed
+}
+
+// allow checks the circuit breaker. Once the cooldown has passed, a single trial hook is let through
+// to probe the plugin: its success closes the circuit, its failure opens it for another cooldown.
+// A circuit opened by another replica is adopted as if it had opened locally.
+func (e *pluginExecutor) allow() bool {
+	if e.config.FailureThreshold <= 0 {
+		return true
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.circuits != nil {
+		if openUntil := e.circuits.OpenUntil(e.name); openUntil.After(e.openUntil) {
+			e.openUntil = openUntil
+			e.consecutiveFailures = max(e.consecutiveFailures, e.config.FailureThreshold)
+		}
+	}
+	if e.consecutiveFailures < e.config.FailureThreshold {
+		return true
+	}
+	if time.Now().Before(e.openUntil) || e.trialInFlight {
+		return false
+	}
+	e.trialInFlight = true
+	return true
+}
+
+// record updates the circuit breaker with the outcome of a hook
+func (e *pluginExecutor) record(success bool) {
+	if e.config.FailureThreshold <= 0 {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.trialInFlight = false
+	if success {
+		e.consecutiveFailures = 0
+		return
+	}
+	e.consecutiveFailures++
+	if e.consecutiveFailures >= e.config.FailureThreshold {
+		e.openUntil = time.Now().Add(e.cooldown)
+		if e.circuits != nil {
+			e.circuits.Open(e.name, e.openUntil)
+		}
+	}
+}
+
+// runPluginHook executes a hook under the limits of the executor.
+// hookErr is the error returned by the hook itself, execErr is set when the hook was not executed
+// or did not finish in time, in which case result is the zero value and must not be used.
+// When a timeout is configured, the hook runs with a derived context carrying the timeout and the
+// values it sets are copied back to ctx only if it finishes in time.
+func runPluginHook[T any](e *pluginExecutor, ctx *schemas.BifrostContext, hook func(*schemas.BifrostContext) (T, error)) (result T, hookErr error, execErr error) {
+	if !e.allow() {
+		return result, nil, errPluginCircuitOpen
+	}
+
+	hookCtx := ctx
+	if e.timeout > 0 {
+		var cancel func()
+		hookCtx, cancel = schemas.NewBifrostContextWithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+
+	if e.slots != nil {
+		select {
+		case e.slots <- struct{}{}:
+		case <-hookCtx.Done():
+			e.record(false)
+			return result, nil, errPluginOverloaded
+		}
+	}
+
+	if e.timeout <= 0 {
+		if e.slots != nil {
+			defer func() { <-e.slots }()
+		}
+		result, hookErr = hook(hookCtx)
+		e.record(hookErr == nil)
+		return result, hookErr, nil
+	}
+
+	type hookOutcome struct {
+		result T
+		err    error
+	}
+	done := make(chan hookOutcome, 1)
+	go func() {
+		if e.slots != nil {
+			defer func() { <-e.slots }()
+		}
+		result, err := hook(hookCtx)
+		done <- hookOutcome{result: result, err: err}
+	}()
+
+	select {
+	case outcome := <-done:
+		e.record(outcome.err == nil)
+		for key, value := range hookCtx.GetUserValues() {
+			ctx.SetValue(key, value)
+		}
+		return outcome.result, outcome.err, nil
+	case <-hookCtx.Done():
+		e.record(false)
+		return result, nil, errPluginTimeout
+	}
+}
+
+// newPluginUnavailableError creates the error returned to the caller when a fail-closed plugin could not run
+func newPluginUnavailableError(pluginName string, err error) *schemas.BifrostError {
+	return &schemas.BifrostError{
+		IsBifrostError: false,
+		StatusCode:     schemas.Ptr(503),
+		Type:           schemas.Ptr("plugin_unavailable"),
+		AllowFallbacks: schemas.Ptr(false),
+		Error: &schemas.ErrorField{
+			Message: fmt.Sprintf("plugin %s is unavailable: %v", pluginName, err),
+			Error:   err,
+		},
+	}
+}
+
+// preHookResult bundles the return values of a PreHook so it can run through runPluginHook
+type preHookResult struct {
+	req          *schemas.BifrostRequest
+	shortCircuit *schemas.PluginShortCircuit
+}
+
+// postHookResult bundles the return values of a PostHook so it can run through runPluginHook
+type postHookResult struct {
+	resp       *schemas.BifrostResponse
+	bifrostErr *schemas.BifrostError
+}
+
+// mustRun reports whether the request cannot proceed without the hooks of the plugin,
+// which is the case for plugins required by the direct key policy on requests carrying a direct key
+func (p *PluginPipeline) mustRun(ctx *schemas.BifrostContext, pluginName string) bool {
+	return slices.Contains(p.directKeyRequiredPlugins, pluginName) && hasDirectKey(ctx)
+}
+
+// runPreHook runs the PreHook of a plugin, applying the plugin's execution limits if it has any.
+// If the hook cannot be executed, the request continues unchanged (fail-open) or is short-circuited
+// with an error (fail-closed). Plugins the request cannot proceed without also fail closed when their hook fails.
+func (p *PluginPipeline) runPreHook(ctx *schemas.BifrostContext, plugin schemas.Plugin, req *schemas.BifrostRequest) (*schemas.BifrostRequest, *schemas.PluginShortCircuit, error) {
+	var result preHookResult
+	var hookErr error
+	if executor := p.executors[plugin.GetName()]; executor != nil {
+		var execErr error
+		result, hookErr, execErr = runPluginHook(executor, ctx, func(hookCtx *schemas.BifrostContext) (preHookResult, error) {
+			newReq, shortCircuit, err := plugin.PreHook(hookCtx, req)
+			return preHookResult{req: newReq, shortCircuit: shortCircuit}, err
+		})
+		if execErr != nil {
+			if executor.failClosed() || p.mustRun(ctx, plugin.GetName()) {
+				return req, &schemas.PluginShortCircuit{Error: newPluginUnavailableError(plugin.GetName(), execErr)}, execErr
+			}
+			return req, nil, execErr
+		}
+	} else {
+		result.req, result.shortCircuit, hookErr = plugin.PreHook(ctx, req)
+	}
+	if hookErr != nil && result.shortCircuit == nil && p.mustRun(ctx, plugin.GetName()) {
+		return req, &schemas.PluginShortCircuit{Error: newPluginUnavailableError(plugin.GetName(), hookErr)}, hookErr
+	}
+	return result.req, result.shortCircuit, hookErr
+}
+
+// runPostHook runs the PostHook of a plugin, applying the plugin's execution limits if it has any.
+// If the hook cannot be executed, the response passes through unchanged (fail-open) or is replaced
+// by an error (fail-closed). Plugins the request cannot proceed without also fail closed when their hook fails.
+func (p *PluginPipeline) runPostHook(ctx *schemas.BifrostContext, plugin schemas.Plugin, resp *schemas.BifrostResponse, bifrostErr *schemas.BifrostError) (*schemas.BifrostResponse, *schemas.BifrostError, error) {
+	var result postHookResult
+	var hookErr error
+	if executor := p.executors[plugin.GetName()]; executor != nil {
+		var execErr error
+		result, hookErr, execErr = runPluginHook(executor, ctx, func(hookCtx *schemas.BifrostContext) (postHookResult, error) {
+			newResp, newErr, err := plugin.PostHook(hookCtx, resp, bifrostErr)
+			return postHookResult{resp: newResp, bifrostErr: newErr}, err
+		})
+		if execErr != nil {
+			if executor.failClosed() || p.mustRun(ctx, plugin.GetName()) {
+				return nil, newPluginUnavailableError(plugin.GetName(), execErr), execErr
+			}
+			return resp, bifrostErr, execErr
+		}
+	} else {
+		result.resp, result.bifrostErr, hookErr = plugin.PostHook(ctx, resp, bifrostErr)
+	}
+	if hookErr != nil && p.mustRun(ctx, plugin.GetName()) {
+		return nil, newPluginUnavailableError(plugin.GetName(), hookErr), hookErr
+	}
+	return result.resp, result.bifrostErr, hookErr
+}
+
+// UpdatePluginExecutionConfigs replaces the execution limits of the plugins.
+// Executors of plugins whose config did not change are kept, so their circuit state survives the update.
+// Plugins missing from configs run inline without limits.
+func (bifrost *Bifrost) UpdatePluginExecutionConfigs(configs map[string]schemas.PluginExecutionConfig) {
+	bifrost.pluginExecutorsMu.Lock()
+	defer bifrost.pluginExecutorsMu.Unlock()
+
+	oldExecutors := bifrost.pluginExecutors.Load()
+	newExecutors := make(map[string]*pluginExecutor, len(configs))
+	for name, config := range configs {
+		if oldExecutors != nil {
+			if executor, ok := (*oldExecutors)[name]; ok && executor.config == config {
+				newExecutors[name] = executor
+				continue
+			}
+		}
+		newExecutors[name] = bifrost.newSharedPluginExecutor(name, config)
+	}
+	bifrost.pluginExecutors.Store(&newExecutors)
+}
+
+// UpdatePluginExecutionConfig sets the execution limits of a single plugin, a nil config removes them
+func (bifrost *Bifrost) UpdatePluginExecutionConfig(name string, config *schemas.PluginExecutionConfig) {
+	bifrost.pluginExecutorsMu.Lock()
+	defer bifrost.pluginExecutorsMu.Unlock()
+
+	oldExecutors := bifrost.pluginExecutors.Load()
+	newExecutors := make(map[string]*pluginExecutor)
+	if oldExecutors != nil {
+		for existingName, executor := range *oldExecutors {
+			newExecutors[existingName] = executor
+		}
+	}
+	if config == nil {
+		delete(newExecutors, name)
+	} else if executor, ok := newExecutors[name]; !ok || executor.config != *config {
+		newExecutors[name] = bifrost.newSharedPluginExecutor(name, *config)
+	}
+	bifrost.pluginExecutors.Store(&newExecutors)
+}
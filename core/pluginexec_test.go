@@ -0,0 +1,250 @@
+package bifrost
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// slowPlugin is a test plugin whose PreHook blocks for the configured delay or until its context is done
+type slowPlugin struct {
+	name  string
+	delay time.Duration
+	err   error
+	calls atomic.Int32
+}
+
+func (p *slowPlugin) GetName() string { return p.name }
+
+func (p *slowPlugin) TransportInterceptor(ctx *schemas.BifrostContext, url string, headers map[string]string, body map[string]any) (map[string]string, map[string]any, error) {
+	return headers, body, nil
+}
+
+func (p *slowPlugin) PreHook(ctx *schemas.BifrostContext, req *schemas.BifrostRequest) (*schemas.BifrostRequest, *schemas.PluginShortCircuit, error) {
+	p.calls.Add(1)
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+	}
+	ctx.SetValue(schemas.BifrostContextKey("slow-plugin-ran"), true)
+	return req, nil, p.err
+}
+
+func (p *slowPlugin) PostHook(ctx *schemas.BifrostContext, result *schemas.BifrostResponse, err *schemas.BifrostError) (*schemas.BifrostResponse, *schemas.BifrostError, error) {
+	return result, err, nil
+}
+
+func (p *slowPlugin) Cleanup() error { return nil }
+
+func newTestPipeline(plugin schemas.Plugin, config schemas.PluginExecutionConfig) *PluginPipeline {
+	return &PluginPipeline{
+		plugins:   []schemas.Plugin{plugin},
+		executors: map[string]*pluginExecutor{plugin.GetName(): newPluginExecutor(config)},
+		logger:    logger,
+	}
+}
+
+// TestPluginExecution_TimeoutFailOpen tests that a slow hook is abandoned and the request continues
+func TestPluginExecution_TimeoutFailOpen(t *testing.T) {
+	plugin := &slowPlugin{name: "guardrail", delay: time.Second}
+	pipeline := newTestPipeline(plugin, schemas.PluginExecutionConfig{TimeoutMs: 20})
+
+	ctx := context.Background()
+	req := &schemas.BifrostRequest{}
+	start := time.Now()
+	result, shortCircuit, _ := pipeline.RunPreHooks(&ctx, req)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected hook to be abandoned after the timeout, took %v", elapsed)
+	}
+	if shortCircuit != nil {
+		t.Fatalf("expected no short-circuit in fail-open mode, got %+v", shortCircuit)
+	}
+	if result != req {
+		t.Error("expected the original request to be passed through")
+	}
+	if len(pipeline.preHookErrors) != 1 || !errors.Is(pipeline.preHookErrors[0], errPluginTimeout) {
+		t.Errorf("expected a timeout error to be recorded, got %v", pipeline.preHookErrors)
+	}
+	if ctx.Value(schemas.BifrostContextKey("slow-plugin-ran")) != nil {
+		t.Error("expected values set by an abandoned hook not to leak into the request context")
+	}
+}
+
+// TestPluginExecution_TimeoutFailClosed tests that a slow hook short-circuits the request with an error
+func TestPluginExecution_TimeoutFailClosed(t *testing.T) {
+	plugin := &slowPlugin{name: "guardrail", delay: time.Second}
+	pipeline := newTestPipeline(plugin, schemas.PluginExecutionConfig{
+		TimeoutMs:   20,
+		FailureMode: schemas.PluginFailureModeClosed,
+	})
+
+	ctx := context.Background()
+	_, shortCircuit, _ := pipeline.RunPreHooks(&ctx, &schemas.BifrostRequest{})
+	if shortCircuit == nil || shortCircuit.Error == nil {
+		t.Fatal("expected an error short-circuit in fail-closed mode")
+	}
+	if shortCircuit.Error.StatusCode == nil || *shortCircuit.Error.StatusCode != 503 {
+		t.Errorf("expected status code 503, got %v", shortCircuit.Error.StatusCode)
+	}
+	if shortCircuit.Error.AllowFallbacks == nil || *shortCircuit.Error.AllowFallbacks {
+		t.Error("expected fallbacks to be disabled")
+	}
+}
+
+// TestPluginExecution_FastHookKeepsValues tests that a hook finishing in time behaves as if run inline
+func TestPluginExecution_FastHookKeepsValues(t *testing.T) {
+	plugin := &slowPlugin{name: "guardrail"}
+	pipeline := newTestPipeline(plugin, schemas.PluginExecutionConfig{TimeoutMs: 1000, MaxConcurrency: 1})
+
+	ctx := context.Background()
+	_, shortCircuit, _ := pipeline.RunPreHooks(&ctx, &schemas.BifrostRequest{})
+	if shortCircuit != nil {
+		t.Fatalf("expected no short-circuit, got %+v", shortCircuit)
+	}
+	if ctx.Value(schemas.BifrostContextKey("slow-plugin-ran")) != true {
+		t.Error("expected values set by the hook to be propagated to the request context")
+	}
+}
+
+// TestPluginExecution_BoundedConcurrency tests that hooks beyond the concurrency limit are rejected
+func TestPluginExecution_BoundedConcurrency(t *testing.T) {
+	plugin := &slowPlugin{name: "guardrail", delay: 200 * time.Millisecond}
+	executor := newPluginExecutor(schemas.PluginExecutionConfig{MaxConcurrency: 1, TimeoutMs: 50})
+	hook := func(ctx *schemas.BifrostContext) (struct{}, error) {
+		_, _, err := plugin.PreHook(schemas.NewBifrostContext(context.Background(), schemas.NoDeadline), nil)
+		return struct{}{}, err
+	}
+
+	// The first hook times out but keeps its slot until it returns
+	if _, _, err := runPluginHook(executor, schemas.NewBifrostContext(context.Background(), schemas.NoDeadline), hook); !errors.Is(err, errPluginTimeout) {
+		t.Fatalf("expected first hook to time out, got %v", err)
+	}
+	if _, _, err := runPluginHook(executor, schemas.NewBifrostContext(context.Background(), schemas.NoDeadline), hook); !errors.Is(err, errPluginOverloaded) {
+		t.Fatalf("expected second hook to be rejected while the slot is busy, got %v", err)
+	}
+	if calls := plugin.calls.Load(); calls != 1 {
+		t.Errorf("expected the plugin to be called once, got %d", calls)
+	}
+}
+
+// TestPluginExecution_CircuitBreaker tests that the circuit opens after consecutive failures and recovers after the cooldown
+func TestPluginExecution_CircuitBreaker(t *testing.T) {
+	plugin := &slowPlugin{name: "guardrail", err: errors.New("guardrail service unavailable")}
+	pipeline := newTestPipeline(plugin, schemas.PluginExecutionConfig{FailureThreshold: 2, CooldownMs: 50})
+
+	for range 2 {
+		ctx := context.Background()
+		pipeline.RunPreHooks(&ctx, &schemas.BifrostRequest{})
+		pipeline.preHookErrors = pipeline.preHookErrors[:0]
+	}
+
+	ctx := context.Background()
+	pipeline.RunPreHooks(&ctx, &schemas.BifrostRequest{})
+	if calls := plugin.calls.Load(); calls != 2 {
+		t.Fatalf("expected the open circuit to skip the plugin, got %d calls", calls)
+	}
+	if len(pipeline.preHookErrors) != 1 || !errors.Is(pipeline.preHookErrors[0], errPluginCircuitOpen) {
+		t.Errorf("expected a circuit open error to be recorded, got %v", pipeline.preHookErrors)
+	}
+	pipeline.preHookErrors = pipeline.preHookErrors[:0]
+
+	// After the cooldown a successful trial closes the circuit again
+	time.Sleep(60 * time.Millisecond)
+	plugin.err = nil
+	ctx = context.Background()
+	pipeline.RunPreHooks(&ctx, &schemas.BifrostRequest{})
+	ctx = context.Background()
+	pipeline.RunPreHooks(&ctx, &schemas.BifrostRequest{})
+	if calls := plugin.calls.Load(); calls != 4 {
+		t.Errorf("expected the plugin to be called again after the cooldown, got %d calls", calls)
+	}
+}
+
+// memoryCircuitStore is a PluginCircuitStore shared by the replicas of a test
+type memoryCircuitStore struct {
+	mu        sync.Mutex
+	openUntil map[string]time.Time
+}
+
+func (s *memoryCircuitStore) OpenUntil(pluginName string) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.openUntil[pluginName]
+}
+
+func (s *memoryCircuitStore) Open(pluginName string, until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.openUntil[pluginName] = until
+}
+
+// TestPluginExecution_SharedCircuitBreaker tests that a circuit opened by a replica skips the plugin on the others
+// until the cooldown has passed
+func TestPluginExecution_SharedCircuitBreaker(t *testing.T) {
+	circuits := &memoryCircuitStore{openUntil: make(map[string]time.Time)}
+	config := map[string]schemas.PluginExecutionConfig{"guardrail": {FailureThreshold: 2, CooldownMs: 50}}
+	replicas := []*Bifrost{{pluginCircuits: circuits}, {pluginCircuits: circuits}}
+	plugins := make([]*slowPlugin, len(replicas))
+	pipelines := make([]*PluginPipeline, len(replicas))
+	for i, replica := range replicas {
+		replica.UpdatePluginExecutionConfigs(config)
+		plugins[i] = &slowPlugin{name: "guardrail", err: errors.New("guardrail service unavailable")}
+		pipelines[i] = &PluginPipeline{plugins: []schemas.Plugin{plugins[i]}, executors: *replica.pluginExecutors.Load(), logger: logger}
+	}
+
+	for range 2 {
+		ctx := context.Background()
+		pipelines[0].RunPreHooks(&ctx, &schemas.BifrostRequest{})
+	}
+	if circuits.OpenUntil("guardrail").IsZero() {
+		t.Fatal("expected the opened circuit to be shared")
+	}
+
+	ctx := context.Background()
+	pipelines[1].RunPreHooks(&ctx, &schemas.BifrostRequest{})
+	if calls := plugins[1].calls.Load(); calls != 0 {
+		t.Fatalf("expected the circuit opened by the other replica to skip the plugin, got %d calls", calls)
+	}
+	if len(pipelines[1].preHookErrors) != 1 || !errors.Is(pipelines[1].preHookErrors[0], errPluginCircuitOpen) {
+		t.Errorf("expected a circuit open error to be recorded, got %v", pipelines[1].preHookErrors)
+	}
+
+	// After the cooldown the replica probes the plugin with a trial, whose success closes its circuit
+	time.Sleep(60 * time.Millisecond)
+	plugins[1].err = nil
+	for range 2 {
+		ctx = context.Background()
+		pipelines[1].RunPreHooks(&ctx, &schemas.BifrostRequest{})
+	}
+	if calls := plugins[1].calls.Load(); calls != 2 {
+		t.Errorf("expected the plugin to be called again after the cooldown, got %d calls", calls)
+	}
+}
+
+// TestUpdatePluginExecutionConfigs tests that unchanged executors are kept across updates
+func TestUpdatePluginExecutionConfigs(t *testing.T) {
+	bifrost := &Bifrost{}
+	config := schemas.PluginExecutionConfig{TimeoutMs: 100}
+	bifrost.UpdatePluginExecutionConfigs(map[string]schemas.PluginExecutionConfig{"guardrail": config})
+	executor := (*bifrost.pluginExecutors.Load())["guardrail"]
+
+	bifrost.UpdatePluginExecutionConfigs(map[string]schemas.PluginExecutionConfig{"guardrail": config})
+	if (*bifrost.pluginExecutors.Load())["guardrail"] != executor {
+		t.Error("expected executor with an unchanged config to be kept")
+	}
+
+	bifrost.UpdatePluginExecutionConfig("guardrail", &schemas.PluginExecutionConfig{TimeoutMs: 200})
+	if (*bifrost.pluginExecutors.Load())["guardrail"] == executor {
+		t.Error("expected executor to be replaced when its config changes")
+	}
+
+	bifrost.UpdatePluginExecutionConfig("guardrail", nil)
+	if _, ok := (*bifrost.pluginExecutors.Load())["guardrail"]; ok {
+		t.Error("expected executor to be removed")
+	}
+}
@@ -0,0 +1,173 @@
+package bifrost
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// providerQueues holds the request queues of a provider, one per priority pool
+type providerQueues struct {
+	pools   []schemas.PriorityPoolSize // the first pool receives requests without a pool of their priority
+	queues  map[string]chan *ChannelMessage
+	busy    map[string]*atomic.Int64 // workers of each pool handling a request
+	senders sync.WaitGroup           // requests that got a queue and may still send to it
+}
+
+// newProviderQueues creates the queues of the priority pools of the provider config
+func newProviderQueues(config schemas.ConcurrencyAndBufferSize) *providerQueues {
+	pools := config.PoolSizes()
+	queues := make(map[string]chan *ChannelMessage, len(pools))
+	busy := make(map[string]*atomic.Int64, len(pools))
+	for _, pool := range pools {
+		queues[pool.Name] = make(chan *ChannelMessage, pool.BufferSize)
+		busy[pool.Name] = &atomic.Int64{}
+	}
+	return &providerQueues{pools: pools, queues: queues, busy: busy}
+}
+
+// queue returns the queue of the pool of the priority, or the queue of the first pool if the priority has none
+func (q *providerQueues) queue(priority string) chan *ChannelMessage {
+	if queue, ok := q.queues[priority]; ok {
+		return queue
+	}
+	return q.queues[q.pools[0].Name]
+}
+
+// acquire returns the queue of the pool of the priority, and the function releasing it once the request is done
+// sending to it. The queues are only closed once every request that acquired them has released them.
+func (q *providerQueues) acquire(priority string) (chan *ChannelMessage, func()) {
+	q.senders.Add(1)
+	return q.queue(priority), q.senders.Done
+}
+
+// close closes the queues to signal the workers of the pools to stop
+func (q *providerQueues) close() {
+	for _, queue := range q.queues {
+		close(queue)
+	}
+}
+
+// requestPriority returns the priority of the request set in its context, or an empty string if it has none
+func requestPriority(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	priority, _ := ctx.Value(schemas.BifrostContextKeyPriority).(string)
+	return priority
+}
+
+// startWorkers starts the workers of each priority pool of the provider, reading from the queue of their pool
+func (bifrost *Bifrost) startWorkers(provider schemas.Provider, config *schemas.ProviderConfig, queues *providerQueues, waitGroup *sync.WaitGroup) {
+	for _, pool := range queues.pools {
+		queue := queues.queues[pool.Name]
+		busy := queues.busy[pool.Name]
+		for range pool.Concurrency {
+			waitGroup.Add(1)
+			go bifrost.requestWorker(provider, config, queue, busy, waitGroup)
+		}
+	}
+}
+
+// stats returns the load of the priority pools of the provider
+func (q *providerQueues) stats(provider schemas.ModelProvider) schemas.ProviderRuntimeStats {
+	stats := schemas.ProviderRuntimeStats{Provider: provider, Pools: make([]schemas.PriorityPoolRuntimeStats, 0, len(q.pools))}
+	for _, pool := range q.pools {
+		queue := q.queues[pool.Name]
+		stats.Pools = append(stats.Pools, schemas.PriorityPoolRuntimeStats{
+			Name:          pool.Name,
+			Workers:       pool.Concurrency,
+			BusyWorkers:   q.busy[pool.Name].Load(),
+			QueueDepth:    len(queue),
+			QueueCapacity: cap(queue),
+		})
+	}
+	return stats
+}
+
+// GetProviderRuntimeStats returns the load of the workers and queues of the initialized providers, sorted by
+// provider. It reads the counters without locking, so the figures of a provider may be a few requests apart.
+func (bifrost *Bifrost) GetProviderRuntimeStats() []schemas.ProviderRuntimeStats {
+	stats := []schemas.ProviderRuntimeStats{}
+	bifrost.requestQueues.Range(func(key, value interface{}) bool {
+		stats = append(stats, value.(*providerQueues).stats(key.(schemas.ModelProvider)))
+		return true
+	})
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Provider < stats[j].Provider })
+	return stats
+}
+
+// retireProviderQueues stops the workers of the previous config of a provider, once the requests that acquired its
+// queues have released them, and waits for the workers to finish the requests left in the queues and in flight.
+func (bifrost *Bifrost) retireProviderQueues(providerKey schemas.ModelProvider, queues *providerQueues, workers *sync.WaitGroup) {
+	defer bifrost.retiringWorkers.Done()
+	queues.senders.Wait()
+	queues.close()
+	if workers != nil {
+		workers.Wait()
+	}
+	bifrost.logger.Debug("all previous workers for provider %s have stopped", providerKey)
+}
+
+// transferQueuedRequests moves the requests buffered in the old queues of a provider to the queues of the same
+// pools of its new config, and returns how many were moved. If a new queue is full, its request is sent from a
+// goroutine waiting for space and the rest of the old queue is left to the old workers, which drain it once closed.
+func (bifrost *Bifrost) transferQueuedRequests(oldQueues *providerQueues, newQueues *providerQueues, transferWaitGroup *sync.WaitGroup) int {
+	transferredCount := 0
+	for name, oldQueue := range oldQueues.queues {
+		newQueue := newQueues.queue(name)
+	drain:
+		for {
+			select {
+			case msg := <-oldQueue:
+				select {
+				case newQueue <- msg:
+					transferredCount++
+				default:
+					// New queue is full, handle this request in a goroutine
+					// This is unlikely with proper buffer sizing but provides safety
+					transferWaitGroup.Add(1)
+					go bifrost.transferQueuedRequest(msg, newQueue, transferWaitGroup)
+					break drain
+				}
+			default:
+				// No more buffered messages
+				break drain
+			}
+		}
+	}
+	return transferredCount
+}
+
+// transferQueuedRequest waits for space in the new queue for a request, and fails the request if none frees up
+func (bifrost *Bifrost) transferQueuedRequest(m *ChannelMessage, newQueue chan *ChannelMessage, transferWaitGroup *sync.WaitGroup) {
+	defer transferWaitGroup.Done()
+	select {
+	case newQueue <- m:
+		// Message successfully transferred
+	case <-time.After(5 * time.Second):
+		bifrost.logger.Warn("Failed to transfer buffered request to new queue within timeout")
+		// Send error response to avoid hanging the client
+		provider, model, _ := m.BifrostRequest.GetRequestFields()
+		select {
+		case m.Err <- schemas.BifrostError{
+			IsBifrostError: false,
+			Error: &schemas.ErrorField{
+				Message: "request failed during provider concurrency update",
+			},
+			ExtraFields: schemas.BifrostErrorExtraFields{
+				RequestType:    m.RequestType,
+				Provider:       provider,
+				ModelRequested: model,
+			},
+		}:
+		case <-time.After(1 * time.Second):
+			// If we can't send the error either, just log and continue
+			bifrost.logger.Warn("Failed to send error response during transfer timeout")
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package bifrost
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestProviderQueues_PriorityPools tests that the concurrency and buffer are split between the priority pools,
+// and that requests are queued in the pool of their priority
+func TestProviderQueues_PriorityPools(t *testing.T) {
+	queues := newProviderQueues(schemas.ConcurrencyAndBufferSize{
+		Concurrency: 10,
+		BufferSize:  100,
+		PriorityPools: []schemas.PriorityPool{
+			{Name: "interactive", Share: 0.7},
+			{Name: "batch", Share: 0.3},
+		},
+	})
+
+	expected := []schemas.PriorityPoolSize{
+		{Name: "interactive", Concurrency: 7, BufferSize: 70},
+		{Name: "batch", Concurrency: 3, BufferSize: 30},
+	}
+	for i, pool := range queues.pools {
+		if pool != expected[i] {
+			t.Errorf("expected pool %+v, got %+v", expected[i], pool)
+		}
+		if cap(queues.queues[pool.Name]) != expected[i].BufferSize {
+			t.Errorf("expected a queue of %d requests for pool %s, got %d", expected[i].BufferSize, pool.Name, cap(queues.queues[pool.Name]))
+		}
+	}
+
+	batchCtx := context.WithValue(context.Background(), schemas.BifrostContextKeyPriority, "batch")
+	if queues.queue(requestPriority(batchCtx)) != queues.queues["batch"] {
+		t.Error("expected batch requests to be queued in the batch pool")
+	}
+	unknownCtx := context.WithValue(context.Background(), schemas.BifrostContextKeyPriority, "realtime")
+	for _, ctx := range []context.Context{context.Background(), unknownCtx} {
+		if queues.queue(requestPriority(ctx)) != queues.queues["interactive"] {
+			t.Error("expected requests without a pool to be queued in the first pool")
+		}
+	}
+}
+
+// TestProviderQueues_NoPriorityPools tests that a provider without priority pools has a single queue
+func TestProviderQueues_NoPriorityPools(t *testing.T) {
+	queues := newProviderQueues(schemas.ConcurrencyAndBufferSize{Concurrency: 4, BufferSize: 20})
+	if len(queues.pools) != 1 || queues.pools[0].Concurrency != 4 || cap(queues.queue("batch")) != 20 {
+		t.Errorf("expected a single pool with the whole concurrency and buffer, got %+v", queues.pools)
+	}
+}
+
+// TestRetireProviderQueues tests that the queues of a previous provider config stay open until the requests that
+// acquired them release them, so that they can still be sent, and that its workers then stop
+func TestRetireProviderQueues(t *testing.T) {
+	bifrost := &Bifrost{logger: NewDefaultLogger(schemas.LogLevelError)}
+	queues := newProviderQueues(schemas.ConcurrencyAndBufferSize{Concurrency: 1, BufferSize: 1})
+	queue, release := queues.acquire("")
+
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		for range queue {
+		}
+	}()
+
+	bifrost.retiringWorkers.Add(1)
+	go bifrost.retireProviderQueues(schemas.OpenAI, queues, &workers)
+
+	// The queue is still open for the request that acquired it
+	queue <- &ChannelMessage{}
+	release()
+
+	done := make(chan struct{})
+	go func() {
+		bifrost.retiringWorkers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the previous workers to stop once the queue was released")
+	}
+}
+
+// TestGetProviderRuntimeStats tests that the runtime stats report the workers, busy workers and queue depth of each
+// pool of the providers, sorted by provider
+func TestGetProviderRuntimeStats(t *testing.T) {
+	bifrost := &Bifrost{logger: NewDefaultLogger(schemas.LogLevelError)}
+	openAIQueues := newProviderQueues(schemas.ConcurrencyAndBufferSize{
+		Concurrency:   4,
+		BufferSize:    10,
+		PriorityPools: []schemas.PriorityPool{{Name: "interactive", Share: 0.5}, {Name: "batch", Share: 0.5}},
+	})
+	openAIQueues.queues["batch"] <- &ChannelMessage{}
+	openAIQueues.busy["interactive"].Add(2)
+	bifrost.requestQueues.Store(schemas.OpenAI, openAIQueues)
+	bifrost.requestQueues.Store(schemas.Anthropic, newProviderQueues(schemas.ConcurrencyAndBufferSize{Concurrency: 1, BufferSize: 1}))
+
+	stats := bifrost.GetProviderRuntimeStats()
+	if len(stats) != 2 || stats[0].Provider != schemas.Anthropic || stats[1].Provider != schemas.OpenAI {
+		t.Fatalf("expected the stats of anthropic and openai, got %+v", stats)
+	}
+	expected := []schemas.PriorityPoolRuntimeStats{
+		{Name: "interactive", Workers: 2, BusyWorkers: 2, QueueDepth: 0, QueueCapacity: 5},
+		{Name: "batch", Workers: 2, BusyWorkers: 0, QueueDepth: 1, QueueCapacity: 5},
+	}
+	for i, pool := range stats[1].Pools {
+		if pool != expected[i] {
+			t.Errorf("expected pool stats %+v, got %+v", expected[i], pool)
+		}
+	}
+}
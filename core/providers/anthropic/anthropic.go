@@ -75,13 +75,7 @@ func releaseAnthropicTextResponse(resp *AnthropicTextResponse) {
 func NewAnthropicProvider(config *schemas.ProviderConfig, logger schemas.Logger) *AnthropicProvider {
 	config.CheckAndSetDefaults()
 
-	client := &fasthttp.Client{
-		ReadTimeout:         time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
-		WriteTimeout:        time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
-		MaxConnsPerHost:     5000,
-		MaxIdleConnDuration: 60 * time.Second,
-		MaxConnWaitTimeout:  10 * time.Second,
-	}
+	client := providerUtils.NewHTTPClient(config, logger)
 
 	// Pre-warm response pools
 	for i := 0; i < config.ConcurrencyAndBufferSize.Concurrency; i++ {
@@ -89,15 +83,14 @@ func NewAnthropicProvider(config *schemas.ProviderConfig, logger schemas.Logger)
 		anthropicMessageResponsePool.Put(&AnthropicMessageResponse{})
 	}
 
-	// Configure proxy if provided
-	client = providerUtils.ConfigureProxy(client, config.ProxyConfig, logger)
-
 	// Set default BaseURL if not provided
 	if config.NetworkConfig.BaseURL == "" {
 		config.NetworkConfig.BaseURL = "https://api.anthropic.com"
 	}
 	config.NetworkConfig.BaseURL = strings.TrimRight(config.NetworkConfig.BaseURL, "/")
 
+	providerUtils.WarmUpConnections(client, config.NetworkConfig, logger)
+
 	return &AnthropicProvider{
 		logger:               logger,
 		client:               client,
@@ -315,7 +308,12 @@ func (provider *AnthropicProvider) ChatCompletion(ctx context.Context, key schem
 	jsonData, err := providerUtils.CheckContextAndGetRequestBody(
 		ctx,
 		request,
-		func() (any, error) { return ToAnthropicChatRequest(request), nil },
+		func() (any, error) {
+			if err := CheckChatInput(request.Input); err != nil {
+				return nil, err
+			}
+			return ToAnthropicChatRequest(request), nil
+		},
 		provider.GetProviderKey())
 	if err != nil {
 		return nil, err
@@ -366,6 +364,9 @@ func (provider *AnthropicProvider) ChatCompletionStream(ctx context.Context, pos
 		ctx,
 		request,
 		func() (any, error) {
+			if err := CheckChatInput(request.Input); err != nil {
+				return nil, err
+			}
 			reqBody := ToAnthropicChatRequest(request)
 			if reqBody != nil {
 				reqBody.Stream = schemas.Ptr(true)
@@ -439,7 +440,7 @@ func HandleAnthropicChatCompletionStreaming(
 	// Make the request
 	err := client.Do(req, resp)
 	if err != nil {
-		defer providerUtils.ReleaseStreamingResponse(resp)
+		defer providerUtils.ReleaseStreamingResponse(ctx, resp)
 		if errors.Is(err, context.Canceled) {
 			return nil, &schemas.BifrostError{
 				IsBifrostError: false,
@@ -458,17 +459,17 @@ func HandleAnthropicChatCompletionStreaming(
 
 	// Check for HTTP errors
 	if resp.StatusCode() != fasthttp.StatusOK {
-		defer providerUtils.ReleaseStreamingResponse(resp)
+		defer providerUtils.ReleaseStreamingResponse(ctx, resp)
 		return nil, parseStreamAnthropicError(resp, providerName)
 	}
 
 	// Create response channel
-	responseChan := make(chan *schemas.BifrostStream, schemas.DefaultStreamBufferSize)
+	responseChan := make(chan *schemas.BifrostStream, providerUtils.GetStreamBufferSize(ctx))
 
 	// Start streaming in a goroutine
 	go func() {
 		defer close(responseChan)
-		defer providerUtils.ReleaseStreamingResponse(resp)
+		defer providerUtils.ReleaseStreamingResponse(ctx, resp)
 
 		if resp.BodyStream() == nil {
 			bifrostErr := providerUtils.NewBifrostOperationError(
@@ -500,6 +501,7 @@ func HandleAnthropicChatCompletionStreaming(
 		// Track SSE event parsing state
 		var eventType string
 		var eventData string
+		structuredOutputIndex := -1
 
 		for scanner.Scan() {
 			line := scanner.Text()
@@ -534,6 +536,9 @@ func HandleAnthropicChatCompletionStreaming(
 				messageID = event.Message.ID
 			}
 
+			// Stream the forced structured output tool call as text content
+			rewriteStructuredOutputStreamEvent(&event, &structuredOutputIndex)
+
 			// Check for usage in both top-level event.Usage and nested event.Message.Usage
 			// message_start events have usage nested in message.usage, while message_delta has it at top level
 			var usageToProcess *AnthropicUsage
@@ -787,7 +792,7 @@ func HandleAnthropicResponsesStream(
 	// Make the request
 	err := client.Do(req, resp)
 	if err != nil {
-		defer providerUtils.ReleaseStreamingResponse(resp)
+		defer providerUtils.ReleaseStreamingResponse(ctx, resp)
 		if errors.Is(err, context.Canceled) {
 			return nil, &schemas.BifrostError{
 				IsBifrostError: false,
@@ -806,16 +811,16 @@ func HandleAnthropicResponsesStream(
 
 	// Check for HTTP errors
 	if resp.StatusCode() != fasthttp.StatusOK {
-		defer providerUtils.ReleaseStreamingResponse(resp)
+		defer providerUtils.ReleaseStreamingResponse(ctx, resp)
 		return nil, parseStreamAnthropicError(resp, providerName)
 	}
 
 	// Create response channel
-	responseChan := make(chan *schemas.BifrostStream, schemas.DefaultStreamBufferSize)
+	responseChan := make(chan *schemas.BifrostStream, providerUtils.GetStreamBufferSize(ctx))
 
 	// Start streaming in a goroutine
 	go func() {
-		defer providerUtils.ReleaseStreamingResponse(resp)
+		defer providerUtils.ReleaseStreamingResponse(ctx, resp)
 		defer close(responseChan)
 
 		if resp.BodyStream() == nil {
@@ -34,8 +34,9 @@ func (request *AnthropicMessageRequest) ToBifrostChatRequest() *schemas.BifrostC
 			for _, block := range request.System.ContentBlocks {
 				if block.Text != nil { // System messages will only have text content
 					contentBlocks = append(contentBlocks, schemas.ChatContentBlock{
-						Type: schemas.ChatContentBlockTypeText,
-						Text: block.Text,
+						Type:         schemas.ChatContentBlockTypeText,
+						Text:         block.Text,
+						CacheControl: block.CacheControl,
 					})
 				}
 			}
@@ -88,8 +89,9 @@ func (request *AnthropicMessageRequest) ToBifrostChatRequest() *schemas.BifrostC
 							for _, block := range toolResult.Content.ContentBlocks {
 								if block.Text != nil {
 									contentBlocks = append(contentBlocks, schemas.ChatContentBlock{
-										Type: schemas.ChatContentBlockTypeText,
-										Text: block.Text,
+										Type:         schemas.ChatContentBlockTypeText,
+										Text:         block.Text,
+										CacheControl: block.CacheControl,
 									})
 								} else if block.Source != nil {
 									contentBlocks = append(contentBlocks, block.ToBifrostContentImageBlock())
@@ -118,14 +120,19 @@ func (request *AnthropicMessageRequest) ToBifrostChatRequest() *schemas.BifrostC
 
 				var toolCalls []schemas.ChatAssistantMessageToolCall
 				var contentBlocks []schemas.ChatContentBlock
+				var thought, thoughtSignature *string
 
 				for _, content := range nonToolContent {
 					switch content.Type {
+					case AnthropicContentBlockTypeThinking:
+						thought = content.Thinking
+						thoughtSignature = content.Signature
 					case AnthropicContentBlockTypeText:
 						if content.Text != nil {
 							contentBlocks = append(contentBlocks, schemas.ChatContentBlock{
-								Type: schemas.ChatContentBlockTypeText,
-								Text: content.Text,
+								Type:         schemas.ChatContentBlockTypeText,
+								Text:         content.Text,
+								CacheControl: content.CacheControl,
 							})
 						}
 					case AnthropicContentBlockTypeImage:
@@ -155,10 +162,12 @@ func (request *AnthropicMessageRequest) ToBifrostChatRequest() *schemas.BifrostC
 					}
 				}
 
-				// Set tool calls for assistant messages
-				if len(toolCalls) > 0 && msg.Role == AnthropicMessageRoleAssistant {
+				// Set tool calls and reasoning for assistant messages
+				if (len(toolCalls) > 0 || thought != nil) && msg.Role == AnthropicMessageRoleAssistant {
 					bifrostMsg.ChatAssistantMessage = &schemas.ChatAssistantMessage{
-						ToolCalls: toolCalls,
+						ToolCalls:        toolCalls,
+						Thought:          thought,
+						ThoughtSignature: thoughtSignature,
 					}
 				}
 
@@ -191,12 +200,28 @@ func (request *AnthropicMessageRequest) ToBifrostChatRequest() *schemas.BifrostC
 			params.Stop = request.StopSequences
 		}
 		if request.OutputFormat != nil {
-			params.ResponseFormat = &request.OutputFormat
+			params.ResponseFormat = toBifrostResponseFormat(request.OutputFormat)
 		}
 
 		bifrostReq.Params = params
 	}
 
+	// Convert the end user
+	if request.Metadata != nil && request.Metadata.UserID != nil {
+		if bifrostReq.Params == nil {
+			bifrostReq.Params = &schemas.ChatParameters{}
+		}
+		bifrostReq.Params.User = request.Metadata.UserID
+	}
+
+	// Convert extended thinking
+	if request.Thinking != nil && request.Thinking.Type == "enabled" && request.Thinking.BudgetTokens != nil {
+		if bifrostReq.Params == nil {
+			bifrostReq.Params = &schemas.ChatParameters{}
+		}
+		bifrostReq.Params.ReasoningMaxTokens = request.Thinking.BudgetTokens
+	}
+
 	// Convert tools
 	if request.Tools != nil {
 		tools := []schemas.ChatTool{}
@@ -209,6 +234,9 @@ func (request *AnthropicMessageRequest) ToBifrostChatRequest() *schemas.BifrostC
 				params.Type = tool.InputSchema.Type
 				params.Required = tool.InputSchema.Required
 				params.Properties = tool.InputSchema.Properties
+				params.Enum = tool.InputSchema.Enum
+				params.AdditionalProperties = tool.InputSchema.AdditionalProperties
+				params.Defs = tool.InputSchema.Defs
 			}
 
 			tools = append(tools, schemas.ChatTool{
@@ -218,6 +246,7 @@ func (request *AnthropicMessageRequest) ToBifrostChatRequest() *schemas.BifrostC
 					Description: tool.Description,
 					Parameters:  &params,
 				},
+				CacheControl: tool.CacheControl,
 			})
 		}
 		if bifrostReq.Params == nil {
@@ -231,22 +260,23 @@ func (request *AnthropicMessageRequest) ToBifrostChatRequest() *schemas.BifrostC
 		if bifrostReq.Params == nil {
 			bifrostReq.Params = &schemas.ChatParameters{}
 		}
-		toolChoice := &schemas.ChatToolChoice{
-			ChatToolChoiceStruct: &schemas.ChatToolChoiceStruct{
-				Type: func() schemas.ChatToolChoiceType {
-					if request.ToolChoice.Type == "tool" {
-						return schemas.ChatToolChoiceTypeFunction
-					}
-					return schemas.ChatToolChoiceType(request.ToolChoice.Type)
-				}(),
-			},
-		}
-		if request.ToolChoice.Type == "tool" && request.ToolChoice.Name != "" {
-			toolChoice.ChatToolChoiceStruct.Function = schemas.ChatToolChoiceFunction{
-				Name: request.ToolChoice.Name,
+		// A named tool is the struct form, "any" is "required" and "auto" and "none" are the same in Bifrost
+		toolChoice := &schemas.ChatToolChoice{}
+		switch request.ToolChoice.Type {
+		case "tool":
+			toolChoice.ChatToolChoiceStruct = &schemas.ChatToolChoiceStruct{
+				Type:     schemas.ChatToolChoiceTypeFunction,
+				Function: schemas.ChatToolChoiceFunction{Name: request.ToolChoice.Name},
 			}
+		case "any":
+			toolChoice.ChatToolChoiceStr = schemas.Ptr(string(schemas.ChatToolChoiceTypeRequired))
+		default:
+			toolChoice.ChatToolChoiceStr = schemas.Ptr(request.ToolChoice.Type)
 		}
 		bifrostReq.Params.ToolChoice = toolChoice
+		if request.ToolChoice.DisableParallelToolUse != nil {
+			bifrostReq.Params.ParallelToolCalls = schemas.Ptr(!*request.ToolChoice.DisableParallelToolUse)
+		}
 	}
 
 	return bifrostReq
@@ -273,6 +303,8 @@ func (response *AnthropicMessageResponse) ToBifrostChatResponse() *schemas.Bifro
 	var toolCalls []schemas.ChatAssistantMessageToolCall
 	var contentBlocks []schemas.ChatContentBlock
 	var contentStr *string
+	var thought, thoughtSignature *string
+	structuredOutput := false
 
 	// Process content and tool calls
 	if response.Content != nil {
@@ -288,7 +320,21 @@ func (response *AnthropicMessageResponse) ToBifrostChatResponse() *schemas.Bifro
 							Text: c.Text,
 						})
 					}
+				case AnthropicContentBlockTypeThinking:
+					thought = c.Thinking
+					thoughtSignature = c.Signature
 				case AnthropicContentBlockTypeToolUse:
+					// The forced structured output tool call is the content of the response
+					if isStructuredOutputToolUse(&c) {
+						structuredOutput = true
+						if output, err := json.Marshal(c.Input); err == nil {
+							contentBlocks = append(contentBlocks, schemas.ChatContentBlock{
+								Type: schemas.ChatContentBlockTypeText,
+								Text: schemas.Ptr(string(output)),
+							})
+						}
+						continue
+					}
 					if c.ID != nil && c.Name != nil {
 						function := schemas.ChatAssistantMessageToolCallFunction{
 							Name: c.Name,
@@ -318,6 +364,12 @@ func (response *AnthropicMessageResponse) ToBifrostChatResponse() *schemas.Bifro
 		}
 	}
 
+	// A structured output is returned as plain text content, like a json_schema response of OpenAI
+	if structuredOutput && len(contentBlocks) == 1 {
+		contentStr = contentBlocks[0].Text
+		contentBlocks = nil
+	}
+
 	// Create a single choice with the collected content
 	// Create message content
 	messageContent := schemas.ChatMessageContent{
@@ -329,9 +381,11 @@ func (response *AnthropicMessageResponse) ToBifrostChatResponse() *schemas.Bifro
 	var assistantMessage *schemas.ChatAssistantMessage
 
 	// Create AssistantMessage if we have tool calls or thinking
-	if len(toolCalls) > 0 {
+	if len(toolCalls) > 0 || thought != nil {
 		assistantMessage = &schemas.ChatAssistantMessage{
-			ToolCalls: toolCalls,
+			ToolCalls:        toolCalls,
+			Thought:          thought,
+			ThoughtSignature: thoughtSignature,
 		}
 	}
 
@@ -351,6 +405,9 @@ func (response *AnthropicMessageResponse) ToBifrostChatResponse() *schemas.Bifro
 		},
 		FinishReason: func() *string {
 			if response.StopReason != "" {
+				if structuredOutput && response.StopReason == AnthropicStopReasonToolUse && len(toolCalls) == 0 {
+					return schemas.Ptr("stop")
+				}
 				mapped := ConvertAnthropicFinishReasonToBifrost(response.StopReason)
 				return &mapped
 			}
@@ -365,7 +422,7 @@ func (response *AnthropicMessageResponse) ToBifrostChatResponse() *schemas.Bifro
 		bifrostResponse.Usage = &schemas.BifrostLLMUsage{
 			PromptTokens: response.Usage.InputTokens,
 			PromptTokensDetails: &schemas.ChatPromptTokensDetails{
-				CachedTokens: response.Usage.CacheCreationInputTokens + response.Usage.CacheReadInputTokens,
+				CachedTokens: response.Usage.CacheReadInputTokens,
 			},
 			CompletionTokens: response.Usage.OutputTokens,
 			TotalTokens:      response.Usage.InputTokens + response.Usage.OutputTokens,
@@ -381,6 +438,25 @@ func (response *AnthropicMessageResponse) ToBifrostChatResponse() *schemas.Bifro
 	return bifrostResponse
 }
 
+// CheckChatInput returns an error when the messages contain content Anthropic doesn't accept (audio and video),
+// instead of the content being left out of the request
+func CheckChatInput(messages []schemas.ChatMessage) error {
+	for _, message := range messages {
+		if message.Content == nil {
+			continue
+		}
+		for _, block := range message.Content.ContentBlocks {
+			if block.InputAudio != nil {
+				return fmt.Errorf("audio input is not supported by Anthropic models")
+			}
+			if block.VideoURLStruct != nil {
+				return fmt.Errorf("video input is not supported by Anthropic models")
+			}
+		}
+	}
+	return nil
+}
+
 // ToAnthropicChatRequest converts a Bifrost request to Anthropic format
 // This is the reverse of ConvertChatRequestToBifrost for provider-side usage
 func ToAnthropicChatRequest(bifrostReq *schemas.BifrostChatRequest) *AnthropicMessageRequest {
@@ -388,7 +464,7 @@ func ToAnthropicChatRequest(bifrostReq *schemas.BifrostChatRequest) *AnthropicMe
 		return nil
 	}
 
-	messages := bifrostReq.Input
+	messages := schemas.NormalizeToolCalls(bifrostReq.Input)
 	anthropicReq := &AnthropicMessageRequest{
 		Model:     bifrostReq.Model,
 		MaxTokens: AnthropicDefaultMaxTokens,
@@ -403,12 +479,27 @@ func ToAnthropicChatRequest(bifrostReq *schemas.BifrostChatRequest) *AnthropicMe
 		anthropicReq.Temperature = bifrostReq.Params.Temperature
 		anthropicReq.TopP = bifrostReq.Params.TopP
 		anthropicReq.StopSequences = bifrostReq.Params.Stop
+		if bifrostReq.Params.User != nil {
+			anthropicReq.Metadata = &AnthropicMetaData{
+				UserID: bifrostReq.Params.User,
+			}
+		}
 		topK, ok := schemas.SafeExtractIntPointer(bifrostReq.Params.ExtraParams["top_k"])
 		if ok {
 			anthropicReq.TopK = topK
 		}
-		if bifrostReq.Params.ResponseFormat != nil {
-			anthropicReq.OutputFormat = bifrostReq.Params.ResponseFormat
+		// Convert reasoning to extended thinking, the budget must stay below max_tokens
+		if budget := schemas.ReasoningBudget(bifrostReq.Params); budget > 0 {
+			anthropicReq.Thinking = &AnthropicThinking{
+				Type:         "enabled",
+				BudgetTokens: schemas.Ptr(budget),
+			}
+			if anthropicReq.MaxTokens <= budget {
+				anthropicReq.MaxTokens = budget + AnthropicDefaultMaxTokens
+			}
+			// Extended thinking isn't compatible with temperature and top_k modifications
+			anthropicReq.Temperature = nil
+			anthropicReq.TopK = nil
 		}
 
 		// Convert tools
@@ -419,7 +510,8 @@ func ToAnthropicChatRequest(bifrostReq *schemas.BifrostChatRequest) *AnthropicMe
 					continue
 				}
 				anthropicTool := AnthropicTool{
-					Name: tool.Function.Name,
+					Name:         tool.Function.Name,
+					CacheControl: tool.CacheControl,
 				}
 				if tool.Function.Description != nil {
 					anthropicTool.Description = tool.Function.Description
@@ -428,9 +520,12 @@ func ToAnthropicChatRequest(bifrostReq *schemas.BifrostChatRequest) *AnthropicMe
 				// Convert function parameters to input_schema
 				if tool.Function.Parameters != nil && (tool.Function.Parameters.Type != "" || tool.Function.Parameters.Properties != nil) {
 					anthropicTool.InputSchema = &schemas.ToolFunctionParameters{
-						Type:       tool.Function.Parameters.Type,
-						Properties: tool.Function.Parameters.Properties,
-						Required:   tool.Function.Parameters.Required,
+						Type:                 tool.Function.Parameters.Type,
+						Properties:           tool.Function.Parameters.Properties,
+						Required:             tool.Function.Parameters.Required,
+						Enum:                 tool.Function.Parameters.Enum,
+						AdditionalProperties: tool.Function.Parameters.AdditionalProperties,
+						Defs:                 tool.Function.Parameters.Defs,
 					}
 				}
 
@@ -439,8 +534,8 @@ func ToAnthropicChatRequest(bifrostReq *schemas.BifrostChatRequest) *AnthropicMe
 			anthropicReq.Tools = tools
 		}
 
-		// Convert tool choice
-		if bifrostReq.Params.ToolChoice != nil {
+		// Convert tool choice, Anthropic rejects it without tools
+		if bifrostReq.Params.ToolChoice != nil && len(anthropicReq.Tools) > 0 {
 			toolChoice := &AnthropicToolChoice{}
 			if bifrostReq.Params.ToolChoice.ChatToolChoiceStr != nil {
 				switch schemas.ChatToolChoiceType(*bifrostReq.Params.ToolChoice.ChatToolChoiceStr) {
@@ -458,6 +553,10 @@ func ToAnthropicChatRequest(bifrostReq *schemas.BifrostChatRequest) *AnthropicMe
 				case schemas.ChatToolChoiceTypeFunction:
 					toolChoice.Type = "tool"
 					toolChoice.Name = bifrostReq.Params.ToolChoice.ChatToolChoiceStruct.Function.Name
+				case schemas.ChatToolChoiceTypeAny, schemas.ChatToolChoiceTypeRequired:
+					toolChoice.Type = "any"
+				case schemas.ChatToolChoiceTypeNone:
+					toolChoice.Type = "none"
 				case schemas.ChatToolChoiceTypeAllowedTools:
 					toolChoice.Type = "any"
 				case schemas.ChatToolChoiceTypeCustom:
@@ -466,8 +565,26 @@ func ToAnthropicChatRequest(bifrostReq *schemas.BifrostChatRequest) *AnthropicMe
 					toolChoice.Type = "auto"
 				}
 			}
+			// Extended thinking doesn't allow forcing tool use
+			if anthropicReq.Thinking != nil && (toolChoice.Type == "any" || toolChoice.Type == "tool") {
+				toolChoice.Type = "auto"
+				toolChoice.Name = ""
+			}
 			anthropicReq.ToolChoice = toolChoice
 		}
+
+		// Parallel tool calls are disabled through the tool choice
+		if bifrostReq.Params.ParallelToolCalls != nil && !*bifrostReq.Params.ParallelToolCalls && len(anthropicReq.Tools) > 0 {
+			if anthropicReq.ToolChoice == nil {
+				anthropicReq.ToolChoice = &AnthropicToolChoice{Type: "auto"}
+			}
+			if anthropicReq.ToolChoice.Type != "none" {
+				anthropicReq.ToolChoice.DisableParallelToolUse = schemas.Ptr(true)
+			}
+		}
+
+		// Structured outputs are forced through a tool, after the request's own tools and tool choice
+		applyStructuredOutput(anthropicReq, bifrostReq.Params.ResponseFormat)
 	}
 
 	// Convert messages - group consecutive tool messages into single user messages
@@ -489,8 +606,9 @@ func ToAnthropicChatRequest(bifrostReq *schemas.BifrostChatRequest) *AnthropicMe
 					for _, block := range msg.Content.ContentBlocks {
 						if block.Text != nil {
 							blocks = append(blocks, AnthropicContentBlock{
-								Type: "text",
-								Text: block.Text,
+								Type:         "text",
+								Text:         block.Text,
+								CacheControl: block.CacheControl,
 							})
 						}
 					}
@@ -523,8 +641,9 @@ func ToAnthropicChatRequest(bifrostReq *schemas.BifrostChatRequest) *AnthropicMe
 							for _, block := range toolMsg.Content.ContentBlocks {
 								if block.Text != nil {
 									blocks = append(blocks, AnthropicContentBlock{
-										Type: "text",
-										Text: block.Text,
+										Type:         "text",
+										Text:         block.Text,
+										CacheControl: block.CacheControl,
 									})
 								} else if block.ImageURLStruct != nil {
 									blocks = append(blocks, ConvertToAnthropicImageBlock(block))
@@ -557,6 +676,15 @@ func ToAnthropicChatRequest(bifrostReq *schemas.BifrostChatRequest) *AnthropicMe
 
 			var content []AnthropicContentBlock
 
+			// Signed reasoning is sent back first, Anthropic requires it to continue tool use with extended thinking
+			if msg.ChatAssistantMessage != nil && msg.ChatAssistantMessage.Thought != nil && msg.ChatAssistantMessage.ThoughtSignature != nil {
+				content = append(content, AnthropicContentBlock{
+					Type:      AnthropicContentBlockTypeThinking,
+					Thinking:  msg.ChatAssistantMessage.Thought,
+					Signature: msg.ChatAssistantMessage.ThoughtSignature,
+				})
+			}
+
 			if msg.Content != nil {
 				// Convert text content
 				if msg.Content.ContentStr != nil {
@@ -568,8 +696,9 @@ func ToAnthropicChatRequest(bifrostReq *schemas.BifrostChatRequest) *AnthropicMe
 					for _, block := range msg.Content.ContentBlocks {
 						if block.Text != nil {
 							content = append(content, AnthropicContentBlock{
-								Type: AnthropicContentBlockTypeText,
-								Text: block.Text,
+								Type:         AnthropicContentBlockTypeText,
+								Text:         block.Text,
+								CacheControl: block.CacheControl,
 							})
 						} else if block.ImageURLStruct != nil {
 							content = append(content, ConvertToAnthropicImageBlock(block))
@@ -600,8 +729,8 @@ func ToAnthropicChatRequest(bifrostReq *schemas.BifrostChatRequest) *AnthropicMe
 			}
 
 			// Set content
-			if len(content) == 1 && content[0].Type == AnthropicContentBlockTypeText {
-				// Single text content can be string
+			if len(content) == 1 && content[0].Type == AnthropicContentBlockTypeText && content[0].CacheControl == nil {
+				// Single text content can be string, unless it carries a cache breakpoint
 				anthropicMsg.Content = AnthropicContent{ContentStr: content[0].Text}
 			} else if len(content) > 0 {
 				// Multiple content blocks
@@ -660,6 +789,15 @@ func ToAnthropicChatCompletionResponse(bifrostResp *schemas.BifrostChatResponse)
 			anthropicResp.StopSequence = choice.StopString
 		}
 
+		// Add reasoning as thinking content
+		if choice.Message.ChatAssistantMessage != nil && choice.Message.ChatAssistantMessage.Thought != nil {
+			content = append(content, AnthropicContentBlock{
+				Type:      AnthropicContentBlockTypeThinking,
+				Thinking:  choice.Message.ChatAssistantMessage.Thought,
+				Signature: choice.Message.ChatAssistantMessage.ThoughtSignature,
+			})
+		}
+
 		// Add text content
 		if choice.Message.Content.ContentStr != nil && *choice.Message.Content.ContentStr != "" {
 			content = append(content, AnthropicContentBlock{
@@ -823,8 +961,24 @@ func (chunk *AnthropicStreamEvent) ToBifrostChatCompletionStream() (*schemas.Bif
 				}
 
 			case AnthropicStreamDeltaTypeSignature:
-				// Handle signature verification for thinking content
-				// This is used to verify the integrity of thinking content
+				// Handle signature of the thinking content, needed to send the reasoning back in later turns
+				if chunk.Delta.Signature != nil && *chunk.Delta.Signature != "" {
+					streamResponse := &schemas.BifrostChatResponse{
+						Object: "chat.completion.chunk",
+						Choices: []schemas.BifrostResponseChoice{
+							{
+								Index: 0,
+								ChatStreamResponseChoice: &schemas.ChatStreamResponseChoice{
+									Delta: &schemas.ChatStreamResponseChoiceDelta{
+										ThoughtSignature: chunk.Delta.Signature,
+									},
+								},
+							},
+						},
+					}
+
+					return streamResponse, nil, false
+				}
 
 			}
 		}
@@ -890,6 +1044,14 @@ func ToAnthropicChatCompletionStreamResponse(bifrostResp *schemas.BifrostChatRes
 					Type:     AnthropicStreamDeltaTypeThinking,
 					Thinking: delta.Thought,
 				}
+			} else if delta.ThoughtSignature != nil {
+				// Handle thinking signature deltas
+				streamResp.Type = "content_block_delta"
+				streamResp.Index = &choice.Index
+				streamResp.Delta = &AnthropicStreamDelta{
+					Type:      AnthropicStreamDeltaTypeSignature,
+					Signature: delta.ThoughtSignature,
+				}
 			} else if len(delta.ToolCalls) > 0 {
 				// Handle tool call deltas
 				toolCall := delta.ToolCalls[0] // Take first tool call
@@ -0,0 +1,212 @@
+package anthropic
+
+import (
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToAnthropicChatRequestCacheControl(t *testing.T) {
+	cacheControl := &schemas.CacheControl{Type: schemas.CacheControlTypeEphemeral}
+	bifrostReq := &schemas.BifrostChatRequest{
+		Provider: schemas.Anthropic,
+		Model:    "claude-sonnet-4-5",
+		Input: []schemas.ChatMessage{
+			{
+				Role: schemas.ChatMessageRoleSystem,
+				Content: &schemas.ChatMessageContent{ContentBlocks: []schemas.ChatContentBlock{
+					{Type: schemas.ChatContentBlockTypeText, Text: schemas.Ptr("You are a helpful assistant."), CacheControl: cacheControl},
+				}},
+			},
+			{
+				Role: schemas.ChatMessageRoleUser,
+				Content: &schemas.ChatMessageContent{ContentBlocks: []schemas.ChatContentBlock{
+					{Type: schemas.ChatContentBlockTypeText, Text: schemas.Ptr("Long document"), CacheControl: cacheControl},
+				}},
+			},
+			{
+				Role:    schemas.ChatMessageRoleUser,
+				Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr("Summarize it")},
+			},
+		},
+		Params: &schemas.ChatParameters{
+			Tools: []schemas.ChatTool{{
+				Type:         schemas.ChatToolTypeFunction,
+				Function:     &schemas.ChatToolFunction{Name: "get_weather"},
+				CacheControl: cacheControl,
+			}},
+		},
+	}
+
+	anthropicReq := ToAnthropicChatRequest(bifrostReq)
+	require.NotNil(t, anthropicReq)
+	require.NotNil(t, anthropicReq.System)
+	require.Len(t, anthropicReq.System.ContentBlocks, 1)
+	assert.Equal(t, cacheControl, anthropicReq.System.ContentBlocks[0].CacheControl)
+
+	require.Len(t, anthropicReq.Messages, 2)
+	require.Len(t, anthropicReq.Messages[0].Content.ContentBlocks, 1, "blocks with a breakpoint must not collapse to a string")
+	assert.Equal(t, cacheControl, anthropicReq.Messages[0].Content.ContentBlocks[0].CacheControl)
+	assert.NotNil(t, anthropicReq.Messages[1].Content.ContentStr)
+
+	require.Len(t, anthropicReq.Tools, 1)
+	assert.Equal(t, cacheControl, anthropicReq.Tools[0].CacheControl)
+
+	roundTrip := anthropicReq.ToBifrostChatRequest()
+	require.Len(t, roundTrip.Input, 3)
+	assert.Equal(t, cacheControl, roundTrip.Input[0].Content.ContentBlocks[0].CacheControl)
+	assert.Equal(t, cacheControl, roundTrip.Input[1].Content.ContentBlocks[0].CacheControl)
+	assert.Equal(t, cacheControl, roundTrip.Params.Tools[0].CacheControl)
+}
+
+func TestToAnthropicChatRequestThinking(t *testing.T) {
+	bifrostReq := &schemas.BifrostChatRequest{
+		Provider: schemas.Anthropic,
+		Model:    "claude-sonnet-4-5",
+		Input: []schemas.ChatMessage{
+			{Role: schemas.ChatMessageRoleUser, Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr("What's the weather in Paris?")}},
+			{
+				Role: schemas.ChatMessageRoleAssistant,
+				ChatAssistantMessage: &schemas.ChatAssistantMessage{
+					Thought:          schemas.Ptr("I should call the weather tool."),
+					ThoughtSignature: schemas.Ptr("signature"),
+					ToolCalls: []schemas.ChatAssistantMessageToolCall{{
+						ID:       schemas.Ptr("toolu_1"),
+						Function: schemas.ChatAssistantMessageToolCallFunction{Name: schemas.Ptr("get_weather"), Arguments: `{"city":"Paris"}`},
+					}},
+				},
+			},
+		},
+		Params: &schemas.ChatParameters{
+			ReasoningEffort: schemas.Ptr("medium"),
+			Temperature:     schemas.Ptr(0.2),
+		},
+	}
+
+	anthropicReq := ToAnthropicChatRequest(bifrostReq)
+	require.NotNil(t, anthropicReq.Thinking)
+	assert.Equal(t, "enabled", anthropicReq.Thinking.Type)
+	assert.Equal(t, schemas.ReasoningBudgetMedium, *anthropicReq.Thinking.BudgetTokens)
+	assert.Greater(t, anthropicReq.MaxTokens, schemas.ReasoningBudgetMedium)
+	assert.Nil(t, anthropicReq.Temperature)
+
+	require.Len(t, anthropicReq.Messages, 2)
+	blocks := anthropicReq.Messages[1].Content.ContentBlocks
+	require.Len(t, blocks, 2)
+	assert.Equal(t, AnthropicContentBlockTypeThinking, blocks[0].Type)
+	assert.Equal(t, "signature", *blocks[0].Signature)
+	assert.Equal(t, AnthropicContentBlockTypeToolUse, blocks[1].Type)
+
+	bifrostReq.Params.ReasoningMaxTokens = schemas.Ptr(2048)
+	assert.Equal(t, 2048, *ToAnthropicChatRequest(bifrostReq).Thinking.BudgetTokens)
+
+	roundTrip := anthropicReq.ToBifrostChatRequest()
+	assert.Equal(t, schemas.ReasoningBudgetMedium, *roundTrip.Params.ReasoningMaxTokens)
+	assert.Equal(t, "I should call the weather tool.", *roundTrip.Input[1].ChatAssistantMessage.Thought)
+}
+
+func TestToAnthropicChatRequestStructuredOutput(t *testing.T) {
+	var responseFormat interface{} = map[string]interface{}{
+		"type": "json_schema",
+		"json_schema": map[string]interface{}{
+			"name": "person",
+			"schema": map[string]interface{}{
+				"type":                 "object",
+				"properties":           map[string]interface{}{"name": map[string]interface{}{"type": "string"}},
+				"required":             []interface{}{"name"},
+				"additionalProperties": false,
+			},
+		},
+	}
+	bifrostReq := &schemas.BifrostChatRequest{
+		Provider: schemas.Anthropic,
+		Model:    "claude-sonnet-4-5",
+		Input: []schemas.ChatMessage{
+			{Role: schemas.ChatMessageRoleUser, Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr("Jane is 30 years old.")}},
+		},
+		Params: &schemas.ChatParameters{ResponseFormat: &responseFormat},
+	}
+
+	anthropicReq := ToAnthropicChatRequest(bifrostReq)
+	assert.Nil(t, anthropicReq.OutputFormat)
+	require.Len(t, anthropicReq.Tools, 1)
+	assert.Equal(t, AnthropicStructuredOutputToolName, anthropicReq.Tools[0].Name)
+	assert.Equal(t, []string{"name"}, anthropicReq.Tools[0].InputSchema.Required)
+	require.NotNil(t, anthropicReq.ToolChoice)
+	assert.Equal(t, "tool", anthropicReq.ToolChoice.Type)
+	assert.Equal(t, AnthropicStructuredOutputToolName, anthropicReq.ToolChoice.Name)
+
+	response := &AnthropicMessageResponse{
+		ID:   "msg_1",
+		Type: "message",
+		Role: "assistant",
+		Content: []AnthropicContentBlock{{
+			Type:  AnthropicContentBlockTypeToolUse,
+			ID:    schemas.Ptr("toolu_1"),
+			Name:  schemas.Ptr(AnthropicStructuredOutputToolName),
+			Input: map[string]interface{}{"name": "Jane"},
+		}},
+		StopReason: AnthropicStopReasonToolUse,
+	}
+	bifrostResp := response.ToBifrostChatResponse()
+	message := bifrostResp.Choices[0].Message
+	require.NotNil(t, message.Content.ContentStr)
+	assert.JSONEq(t, `{"name":"Jane"}`, *message.Content.ContentStr)
+	assert.Nil(t, message.ChatAssistantMessage)
+	assert.Equal(t, "stop", *bifrostResp.Choices[0].FinishReason)
+}
+
+func TestToAnthropicChatRequestToolChoice(t *testing.T) {
+	bifrostReq := &schemas.BifrostChatRequest{
+		Provider: schemas.Anthropic,
+		Model:    "claude-sonnet-4-5",
+		Input: []schemas.ChatMessage{
+			{Role: schemas.ChatMessageRoleUser, Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr("Weather in Paris and Rome?")}},
+			{
+				Role: schemas.ChatMessageRoleAssistant,
+				ChatAssistantMessage: &schemas.ChatAssistantMessage{
+					ToolCalls: []schemas.ChatAssistantMessageToolCall{
+						{ID: schemas.Ptr("function-call.1"), Function: schemas.ChatAssistantMessageToolCallFunction{Name: schemas.Ptr("get_weather"), Arguments: `{"city":"Paris"}`}},
+						{Index: 1, Function: schemas.ChatAssistantMessageToolCallFunction{Name: schemas.Ptr("get_weather"), Arguments: `{"city":"Rome"}`}},
+					},
+				},
+			},
+			{Role: schemas.ChatMessageRoleTool, Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr("sunny")}, ChatToolMessage: &schemas.ChatToolMessage{ToolCallID: schemas.Ptr("function-call.1")}},
+			{Role: schemas.ChatMessageRoleTool, Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr("rainy")}},
+		},
+		Params: &schemas.ChatParameters{
+			Tools: []schemas.ChatTool{{
+				Type:     schemas.ChatToolTypeFunction,
+				Function: &schemas.ChatToolFunction{Name: "get_weather"},
+			}},
+			ToolChoice:        &schemas.ChatToolChoice{ChatToolChoiceStr: schemas.Ptr("required")},
+			ParallelToolCalls: schemas.Ptr(false),
+		},
+	}
+
+	anthropicReq := ToAnthropicChatRequest(bifrostReq)
+	require.NotNil(t, anthropicReq.ToolChoice)
+	assert.Equal(t, "any", anthropicReq.ToolChoice.Type)
+	assert.True(t, *anthropicReq.ToolChoice.DisableParallelToolUse)
+
+	// Tool call IDs are made valid for Anthropic and the results of the parallel calls are grouped
+	require.Len(t, anthropicReq.Messages, 3)
+	toolUses := anthropicReq.Messages[1].Content.ContentBlocks
+	require.Len(t, toolUses, 2)
+	assert.Equal(t, "function-call_1", *toolUses[0].ID)
+	assert.Equal(t, "call_1_1", *toolUses[1].ID)
+	toolResults := anthropicReq.Messages[2].Content.ContentBlocks
+	require.Len(t, toolResults, 2)
+	assert.Equal(t, "function-call_1", *toolResults[0].ToolUseID)
+	assert.Equal(t, "call_1_1", *toolResults[1].ToolUseID)
+
+	roundTrip := anthropicReq.ToBifrostChatRequest()
+	assert.Equal(t, "required", *roundTrip.Params.ToolChoice.ChatToolChoiceStr)
+	assert.False(t, *roundTrip.Params.ParallelToolCalls)
+
+	// Thinking doesn't allow forcing tool use
+	bifrostReq.Params.ReasoningEffort = schemas.Ptr("low")
+	assert.Equal(t, "auto", ToAnthropicChatRequest(bifrostReq).ToolChoice.Type)
+}
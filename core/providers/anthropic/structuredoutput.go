@@ -0,0 +1,113 @@
+package anthropic
+
+import (
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// AnthropicStructuredOutputToolName is the name of the tool that structured outputs are forced through.
+// Its input is returned as the content of the response instead of a tool call.
+const AnthropicStructuredOutputToolName = "bifrost_structured_output"
+
+// applyStructuredOutput translates a json_schema or json_object response format to a forced tool call,
+// which works on every Claude model, unlike the output_format beta. The tool's input schema is the schema
+// of the response format, or any object for json_object.
+func applyStructuredOutput(anthropicReq *AnthropicMessageRequest, responseFormat *interface{}) {
+	format := schemas.ParseResponseFormat(responseFormat)
+	if format == nil || (format.Type != schemas.ResponseFormatTypeJSONSchema && format.Type != schemas.ResponseFormatTypeJSONObject) {
+		return
+	}
+
+	inputSchema := &schemas.ToolFunctionParameters{Type: "object"}
+	description := "Respond with the final answer as the input of this tool."
+	if format.JSONSchema != nil {
+		if format.JSONSchema.Description != nil {
+			description += " " + *format.JSONSchema.Description
+		}
+		if schema := format.JSONSchema.Schema; schema != nil {
+			if schemaType, ok := schema["type"].(string); ok {
+				inputSchema.Type = schemaType
+			}
+			if properties, ok := schemas.SafeExtractOrderedMap(schema["properties"]); ok {
+				inputSchema.Properties = &properties
+			}
+			if required, ok := schemas.SafeExtractStringSlice(schema["required"]); ok {
+				inputSchema.Required = required
+			}
+			if additionalProperties, ok := schema["additionalProperties"].(bool); ok {
+				inputSchema.AdditionalProperties = &additionalProperties
+			}
+			if defs, ok := schemas.SafeExtractOrderedMap(schema["$defs"]); ok {
+				inputSchema.Defs = &defs
+			}
+		}
+	}
+
+	anthropicReq.Tools = append(anthropicReq.Tools, AnthropicTool{
+		Name:        AnthropicStructuredOutputToolName,
+		Description: &description,
+		InputSchema: inputSchema,
+	})
+	// Extended thinking only allows the auto tool choice
+	if anthropicReq.Thinking == nil {
+		anthropicReq.ToolChoice = &AnthropicToolChoice{
+			Type: "tool",
+			Name: AnthropicStructuredOutputToolName,
+		}
+	}
+}
+
+// isStructuredOutputToolUse reports whether a content block is the forced structured output tool call.
+func isStructuredOutputToolUse(block *AnthropicContentBlock) bool {
+	return block != nil && block.Type == AnthropicContentBlockTypeToolUse && block.Name != nil && *block.Name == AnthropicStructuredOutputToolName
+}
+
+// rewriteStructuredOutputStreamEvent turns the stream events of the structured output tool into text events,
+// so that the JSON is streamed as content. structuredOutputIndex holds the content block index of the tool,
+// -1 until it starts.
+func rewriteStructuredOutputStreamEvent(event *AnthropicStreamEvent, structuredOutputIndex *int) {
+	// The tool call is the answer, so the message ends like a regular one
+	if *structuredOutputIndex >= 0 && event.Delta != nil && event.Delta.StopReason != nil && *event.Delta.StopReason == AnthropicStopReasonToolUse {
+		event.Delta.StopReason = schemas.Ptr(AnthropicStopReasonEndTurn)
+	}
+	if event.Index == nil {
+		return
+	}
+	switch event.Type {
+	case AnthropicStreamEventTypeContentBlockStart:
+		if isStructuredOutputToolUse(event.ContentBlock) {
+			*structuredOutputIndex = *event.Index
+			event.ContentBlock = &AnthropicContentBlock{Type: AnthropicContentBlockTypeText, Text: schemas.Ptr("")}
+		}
+	case AnthropicStreamEventTypeContentBlockDelta:
+		if *event.Index == *structuredOutputIndex && event.Delta != nil && event.Delta.Type == AnthropicStreamDeltaTypeInputJSON {
+			event.Delta = &AnthropicStreamDelta{Type: AnthropicStreamDeltaTypeText, Text: event.Delta.PartialJSON}
+		}
+	}
+}
+
+// toBifrostResponseFormat converts an Anthropic output_format to the response_format of Bifrost.
+func toBifrostResponseFormat(outputFormat interface{}) *interface{} {
+	formatMap, ok := outputFormat.(map[string]interface{})
+	if !ok {
+		return &outputFormat
+	}
+	formatType, _ := formatMap["type"].(string)
+	if formatType != schemas.ResponseFormatTypeJSONSchema {
+		return &outputFormat
+	}
+	jsonSchema := map[string]interface{}{
+		"name":   "response",
+		"schema": formatMap["schema"],
+	}
+	if name, ok := formatMap["name"].(string); ok && name != "" {
+		jsonSchema["name"] = name
+	}
+	if strict, ok := formatMap["strict"].(bool); ok {
+		jsonSchema["strict"] = strict
+	}
+	var responseFormat interface{} = map[string]interface{}{
+		"type":        schemas.ResponseFormatTypeJSONSchema,
+		"json_schema": jsonSchema,
+	}
+	return &responseFormat
+}
@@ -155,6 +155,8 @@ type AnthropicContentBlock struct {
 	ServerName *string                   `json:"server_name,omitempty"` // For mcp_tool_use content
 	Content    *AnthropicContent         `json:"content,omitempty"`     // For tool_result content
 	Source     *AnthropicImageSource     `json:"source,omitempty"`      // For image content
+
+	CacheControl *schemas.CacheControl `json:"cache_control,omitempty"` // Prompt caching breakpoint
 }
 
 // AnthropicImageSource represents image source in Anthropic format
@@ -221,6 +223,8 @@ type AnthropicTool struct {
 	Description *string                         `json:"description,omitempty"`
 	InputSchema *schemas.ToolFunctionParameters `json:"input_schema,omitempty"`
 
+	CacheControl *schemas.CacheControl `json:"cache_control,omitempty"` // Prompt caching breakpoint
+
 	*AnthropicToolComputerUse
 	*AnthropicToolWebSearch
 }
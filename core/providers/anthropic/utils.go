@@ -43,8 +43,9 @@ func ConvertBifrostFinishReasonToAnthropic(bifrostReason string) AnthropicStopRe
 // Uses the same pattern as the original buildAnthropicImageSourceMap function
 func ConvertToAnthropicImageBlock(block schemas.ChatContentBlock) AnthropicContentBlock {
 	imageBlock := AnthropicContentBlock{
-		Type:   "image",
-		Source: &AnthropicImageSource{},
+		Type:         "image",
+		Source:       &AnthropicImageSource{},
+		CacheControl: block.CacheControl,
 	}
 
 	if block.ImageURLStruct == nil {
@@ -101,6 +102,7 @@ func (block AnthropicContentBlock) ToBifrostContentImageBlock() schemas.ChatCont
 		ImageURLStruct: &schemas.ChatInputImage{
 			URL: getImageURLFromBlock(block),
 		},
+		CacheControl: block.CacheControl,
 	}
 }
 
@@ -32,16 +32,9 @@ type AzureProvider struct {
 func NewAzureProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*AzureProvider, error) {
 	config.CheckAndSetDefaults()
 
-	client := &fasthttp.Client{
-		ReadTimeout:         time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
-		WriteTimeout:        time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
-		MaxConnsPerHost:     5000,
-		MaxIdleConnDuration: 60 * time.Second,
-		MaxConnWaitTimeout:  10 * time.Second,
-	}
+	client := providerUtils.NewHTTPClient(config, logger)
 
-	// Configure proxy if provided
-	client = providerUtils.ConfigureProxy(client, config.ProxyConfig, logger)
+	providerUtils.WarmUpConnections(client, config.NetworkConfig, logger)
 
 	return &AzureProvider{
 		logger:              logger,
@@ -80,7 +73,10 @@ func (provider *AzureProvider) completeRequest(
 	req.Header.SetContentType("application/json")
 
 	var url string
-	if schemas.IsAnthropicModel(deployment) {
+	if isServerlessKey(key) {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", key.Value))
+		url = serverlessURL(key, path)
+	} else if schemas.IsAnthropicModel(deployment) {
 		req.Header.Set("x-api-key", key.Value)
 		req.Header.Set("anthropic-version", AzureAnthropicAPIVersionDefault)
 		url = fmt.Sprintf("%s/%s", key.AzureKeyConfig.Endpoint, path)
@@ -142,6 +138,10 @@ func (provider *AzureProvider) listModelsByKey(ctx context.Context, key schemas.
 		return nil, providerUtils.NewConfigurationError("endpoint not set", schemas.Azure)
 	}
 
+	if isServerlessKey(key) {
+		return provider.listServerlessModelsByKey(ctx, key)
+	}
+
 	// Get API version
 	apiVersion := key.AzureKeyConfig.APIVersion
 	if apiVersion == nil {
@@ -233,6 +233,11 @@ func (provider *AzureProvider) TextCompletion(ctx context.Context, key schemas.K
 		return nil, err
 	}
 
+	// The Azure AI model inference API has no text completions
+	if isServerlessKey(key) {
+		return nil, providerUtils.NewUnsupportedOperationError(schemas.TextCompletionRequest, provider.GetProviderKey())
+	}
+
 	deployment, err := provider.getModelDeployment(key, request.Model)
 	if err != nil {
 		return nil, err
@@ -290,6 +295,11 @@ func (provider *AzureProvider) TextCompletionStream(ctx context.Context, postHoo
 		return nil, err
 	}
 
+	// The Azure AI model inference API has no text completions
+	if isServerlessKey(key) {
+		return nil, providerUtils.NewUnsupportedOperationError(schemas.TextCompletionStreamRequest, provider.GetProviderKey())
+	}
+
 	deployment := key.AzureKeyConfig.Deployments[request.Model]
 	if deployment == "" {
 		return nil, providerUtils.NewConfigurationError(fmt.Sprintf("deployment not found for model %s", request.Model), provider.GetProviderKey())
@@ -340,6 +350,10 @@ func (provider *AzureProvider) ChatCompletion(ctx context.Context, key schemas.K
 		return nil, err
 	}
 
+	if isServerlessKey(key) {
+		return provider.serverlessChatCompletion(ctx, key, request)
+	}
+
 	deployment, err := provider.getModelDeployment(key, request.Model)
 	if err != nil {
 		return nil, err
@@ -350,6 +364,9 @@ func (provider *AzureProvider) ChatCompletion(ctx context.Context, key schemas.K
 		request,
 		func() (any, error) {
 			if schemas.IsAnthropicModel(deployment) {
+				if err := anthropic.CheckChatInput(request.Input); err != nil {
+					return nil, err
+				}
 				reqBody := anthropic.ToAnthropicChatRequest(request)
 				if reqBody != nil {
 					reqBody.Model = deployment
@@ -425,6 +442,10 @@ func (provider *AzureProvider) ChatCompletionStream(ctx context.Context, postHoo
 		return nil, err
 	}
 
+	if isServerlessKey(key) {
+		return provider.serverlessChatCompletionStream(ctx, postHookRunner, key, request)
+	}
+
 	deployment, err := provider.getModelDeployment(key, request.Model)
 	if err != nil {
 		return nil, err
@@ -446,6 +467,9 @@ func (provider *AzureProvider) ChatCompletionStream(ctx context.Context, postHoo
 			ctx,
 			request,
 			func() (any, error) {
+				if err := anthropic.CheckChatInput(request.Input); err != nil {
+					return nil, err
+				}
 				reqBody := anthropic.ToAnthropicChatRequest(request)
 				if reqBody != nil {
 					reqBody.Model = deployment
@@ -512,6 +536,10 @@ func (provider *AzureProvider) Responses(ctx context.Context, key schemas.Key, r
 		return nil, err
 	}
 
+	if isServerlessKey(key) {
+		return provider.serverlessResponses(ctx, key, request)
+	}
+
 	deployment, err := provider.getModelDeployment(key, request.Model)
 	if err != nil {
 		return nil, err
@@ -598,6 +626,10 @@ func (provider *AzureProvider) ResponsesStream(ctx context.Context, postHookRunn
 		return nil, err
 	}
 
+	if isServerlessKey(key) {
+		return provider.serverlessResponsesStream(ctx, postHookRunner, key, request)
+	}
+
 	deployment, err := provider.getModelDeployment(key, request.Model)
 	if err != nil {
 		return nil, err
@@ -685,6 +717,10 @@ func (provider *AzureProvider) Embedding(ctx context.Context, key schemas.Key, r
 		return nil, err
 	}
 
+	if isServerlessKey(key) {
+		return provider.serverlessEmbedding(ctx, key, request)
+	}
+
 	deployment, err := provider.getModelDeployment(key, request.Model)
 	if err != nil {
 		return nil, err
@@ -734,9 +770,56 @@ func (provider *AzureProvider) Embedding(ctx context.Context, key schemas.Key, r
 	return response, nil
 }
 
-// Speech is not supported by the Azure provider.
+// Speech performs a text to speech request to an Azure OpenAI TTS deployment.
+// Azure OpenAI shares the voices and output formats of OpenAI.
 func (provider *AzureProvider) Speech(ctx context.Context, key schemas.Key, request *schemas.BifrostSpeechRequest) (*schemas.BifrostSpeechResponse, *schemas.BifrostError) {
-	return nil, providerUtils.NewUnsupportedOperationError(schemas.SpeechRequest, provider.GetProviderKey())
+	if err := provider.validateKeyConfig(key); err != nil {
+		return nil, err
+	}
+
+	if isServerlessKey(key) {
+		return nil, providerUtils.NewUnsupportedOperationError(schemas.SpeechRequest, provider.GetProviderKey())
+	}
+
+	deployment, err := provider.getModelDeployment(key, request.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonData, bifrostErr := providerUtils.CheckContextAndGetRequestBody(
+		ctx,
+		request,
+		func() (any, error) { return openai.ToOpenAISpeechRequest(request), nil },
+		provider.GetProviderKey())
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	audio, deployment, latency, err := provider.completeRequest(
+		ctx,
+		jsonData,
+		fmt.Sprintf("openai/deployments/%s/audio/speech", deployment),
+		key,
+		deployment,
+		request.Model,
+		schemas.SpeechRequest,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.BifrostSpeechResponse{
+		Audio:      audio,
+		Format:     openai.SpeechResponseFormat(request),
+		SampleRate: schemas.DefaultSpeechSampleRate,
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			RequestType:     schemas.SpeechRequest,
+			Provider:        provider.GetProviderKey(),
+			ModelRequested:  request.Model,
+			ModelDeployment: deployment,
+			Latency:         latency.Milliseconds(),
+		},
+	}, nil
 }
 
 // SpeechStream is not supported by the Azure provider.
@@ -766,7 +849,8 @@ func (provider *AzureProvider) validateKeyConfig(key schemas.Key) *schemas.Bifro
 		return providerUtils.NewConfigurationError("endpoint not set", provider.GetProviderKey())
 	}
 
-	if key.AzureKeyConfig.Deployments == nil {
+	// Serverless endpoints serve the requested model when it has no deployment
+	if key.AzureKeyConfig.Deployments == nil && !isServerlessKey(key) {
 		return providerUtils.NewConfigurationError("deployments not set", provider.GetProviderKey())
 	}
 
@@ -783,5 +867,8 @@ func (provider *AzureProvider) getModelDeployment(key schemas.Key, model string)
 			return deployment, nil
 		}
 	}
+	if isServerlessKey(key) {
+		return model, nil
+	}
 	return "", providerUtils.NewConfigurationError(fmt.Sprintf("deployment not found for model %s", model), provider.GetProviderKey())
 }
@@ -1,13 +1,20 @@
 package azure_test
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
 
+	bifrost "github.com/maximhq/bifrost/core"
 	"github.com/maximhq/bifrost/core/internal/testutil"
-
+	"github.com/maximhq/bifrost/core/providers/azure"
 	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAzure(t *testing.T) {
@@ -58,3 +65,55 @@ func TestAzure(t *testing.T) {
 	})
 	client.Shutdown()
 }
+
+func TestAzureServerlessChatCompletion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/models/chat/completions", r.URL.Path)
+		assert.Equal(t, azure.AzureServerlessAPIVersionDefault, r.URL.Query().Get("api-version"))
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		assert.Empty(t, r.Header.Get("api-key"))
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "Meta-Llama-3.1-70B-Instruct", body["model"])
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","model":"Meta-Llama-3.1-70B-Instruct","choices":[{"index":0,"message":{"role":"assistant","content":"Hello!"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	provider, err := azure.NewAzureProvider(&schemas.ProviderConfig{}, bifrost.NewDefaultLogger(schemas.LogLevelError))
+	require.NoError(t, err)
+
+	key := schemas.Key{
+		Value: "test-key",
+		AzureKeyConfig: &schemas.AzureKeyConfig{
+			Endpoint:     server.URL + "/models",
+			EndpointType: schemas.AzureEndpointTypeServerless,
+			Deployments:  map[string]string{"llama-3.1-70b": "Meta-Llama-3.1-70B-Instruct"},
+		},
+	}
+	request := &schemas.BifrostChatRequest{
+		Provider: schemas.Azure,
+		Model:    "llama-3.1-70b",
+		Input: []schemas.ChatMessage{
+			{
+				Role:    schemas.ChatMessageRoleUser,
+				Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr("Hi")},
+			},
+		},
+	}
+
+	response, bifrostErr := provider.ChatCompletion(context.Background(), key, request)
+	require.Nil(t, bifrostErr)
+	require.NotNil(t, response)
+	assert.Equal(t, "llama-3.1-70b", response.ExtraFields.ModelRequested)
+	assert.Equal(t, "Meta-Llama-3.1-70B-Instruct", response.ExtraFields.ModelDeployment)
+
+	_, bifrostErr = provider.TextCompletion(context.Background(), key, &schemas.BifrostTextCompletionRequest{
+		Provider: schemas.Azure,
+		Model:    "llama-3.1-70b",
+		Input:    &schemas.TextCompletionInput{PromptStr: schemas.Ptr("Hi")},
+	})
+	require.NotNil(t, bifrostErr)
+}
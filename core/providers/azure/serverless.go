@@ -0,0 +1,266 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/maximhq/bifrost/core/providers/openai"
+	providerUtils "github.com/maximhq/bifrost/core/providers/utils"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+// This file implements Azure AI Foundry serverless (model-as-a-service) endpoints, selected per key with
+// AzureKeyConfig.EndpointType. They are served by the Azure AI model inference API, which differs from
+// Azure OpenAI: routes are not scoped to a deployment, the model is sent in the body and keys use bearer auth.
+
+// isServerlessKey returns true if the key points to an Azure AI Foundry serverless endpoint.
+func isServerlessKey(key schemas.Key) bool {
+	return key.AzureKeyConfig != nil && key.AzureKeyConfig.EndpointType == schemas.AzureEndpointTypeServerless
+}
+
+// serverlessURL builds the URL of a route of the Azure AI model inference API for the key's endpoint.
+func serverlessURL(key schemas.Key, path string) string {
+	apiVersion := AzureServerlessAPIVersionDefault
+	if key.AzureKeyConfig.APIVersion != nil && *key.AzureKeyConfig.APIVersion != "" {
+		apiVersion = *key.AzureKeyConfig.APIVersion
+	}
+	return fmt.Sprintf("%s/%s?api-version=%s", strings.TrimRight(key.AzureKeyConfig.Endpoint, "/"), path, apiVersion)
+}
+
+// listServerlessModelsByKey lists the models of a serverless key.
+// Keys with deployments list their mapped models, other keys list the model served by the endpoint, from its /info route.
+func (provider *AzureProvider) listServerlessModelsByKey(ctx context.Context, key schemas.Key) (*schemas.BifrostListModelsResponse, *schemas.BifrostError) {
+	response := &schemas.BifrostListModelsResponse{}
+	if len(key.AzureKeyConfig.Deployments) > 0 {
+		for model, deployment := range key.AzureKeyConfig.Deployments {
+			response.Data = append(response.Data, schemas.Model{
+				ID:         string(schemas.Azure) + "/" + model,
+				Deployment: schemas.Ptr(deployment),
+			})
+		}
+		return response, nil
+	}
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	// Set any extra headers from network config
+	providerUtils.SetExtraHeaders(ctx, req, provider.networkConfig.ExtraHeaders, nil)
+
+	req.SetRequestURI(serverlessURL(key, "info"))
+	req.Header.SetMethod(http.MethodGet)
+	req.Header.SetContentType("application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", key.Value))
+
+	// Send the request and measure latency
+	latency, bifrostErr := providerUtils.MakeRequestWithContext(ctx, provider.client, req, resp)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	// Handle error response
+	if resp.StatusCode() != fasthttp.StatusOK {
+		return nil, openai.ParseOpenAIError(resp, schemas.ListModelsRequest, provider.GetProviderKey(), "")
+	}
+
+	body, err := providerUtils.CheckAndDecodeBody(resp)
+	if err != nil {
+		return nil, providerUtils.NewBifrostOperationError(schemas.ErrProviderResponseDecode, err, provider.GetProviderKey())
+	}
+
+	info := &AzureServerlessInfoResponse{}
+	rawResponse, bifrostErr := providerUtils.HandleProviderResponse(body, info, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	response.Data = []schemas.Model{
+		{
+			ID:      string(schemas.Azure) + "/" + info.ModelName,
+			OwnedBy: schemas.Ptr(info.ModelProviderName),
+		},
+	}
+	response.ExtraFields.Latency = latency.Milliseconds()
+
+	if providerUtils.ShouldSendBackRawResponse(ctx, provider.sendBackRawResponse) {
+		response.ExtraFields.RawResponse = rawResponse
+	}
+
+	return response, nil
+}
+
+// serverlessChatCompletion performs a chat completion request to a serverless endpoint.
+func (provider *AzureProvider) serverlessChatCompletion(ctx context.Context, key schemas.Key, request *schemas.BifrostChatRequest) (*schemas.BifrostChatResponse, *schemas.BifrostError) {
+	deployment, err := provider.getModelDeployment(key, request.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonData, bifrostErr := providerUtils.CheckContextAndGetRequestBody(
+		ctx,
+		request,
+		func() (any, error) {
+			reqBody := openai.ToOpenAIChatRequest(request)
+			if reqBody != nil {
+				reqBody.Model = deployment
+			}
+			return reqBody, nil
+		},
+		provider.GetProviderKey())
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	responseBody, deployment, latency, err := provider.completeRequest(
+		ctx,
+		jsonData,
+		"chat/completions",
+		key,
+		deployment,
+		request.Model,
+		schemas.ChatCompletionRequest,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &schemas.BifrostChatResponse{}
+	rawResponse, bifrostErr := providerUtils.HandleProviderResponse(responseBody, response, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	response.ExtraFields.Provider = provider.GetProviderKey()
+	response.ExtraFields.ModelRequested = request.Model
+	response.ExtraFields.ModelDeployment = deployment
+	response.ExtraFields.Latency = latency.Milliseconds()
+	response.ExtraFields.RequestType = schemas.ChatCompletionRequest
+
+	// Set raw response if enabled
+	if providerUtils.ShouldSendBackRawResponse(ctx, provider.sendBackRawResponse) {
+		response.ExtraFields.RawResponse = rawResponse
+	}
+
+	return response, nil
+}
+
+// serverlessChatCompletionStream performs a streaming chat completion request to a serverless endpoint.
+func (provider *AzureProvider) serverlessChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, key schemas.Key, request *schemas.BifrostChatRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	deployment, err := provider.getModelDeployment(key, request.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	postRequestConverter := func(req *openai.OpenAIChatRequest) *openai.OpenAIChatRequest {
+		req.Model = deployment
+		return req
+	}
+
+	postResponseConverter := func(response *schemas.BifrostChatResponse) *schemas.BifrostChatResponse {
+		response.ExtraFields.ModelDeployment = deployment
+		return response
+	}
+
+	// Use shared streaming logic from OpenAI
+	return openai.HandleOpenAIChatCompletionStreaming(
+		ctx,
+		provider.client,
+		serverlessURL(key, "chat/completions"),
+		request,
+		map[string]string{"Authorization": fmt.Sprintf("Bearer %s", key.Value)},
+		provider.networkConfig.ExtraHeaders,
+		providerUtils.ShouldSendBackRawResponse(ctx, provider.sendBackRawResponse),
+		provider.GetProviderKey(),
+		postHookRunner,
+		nil,
+		postRequestConverter,
+		postResponseConverter,
+		provider.logger,
+	)
+}
+
+// serverlessResponses performs a responses request to a serverless endpoint through its chat completions route.
+func (provider *AzureProvider) serverlessResponses(ctx context.Context, key schemas.Key, request *schemas.BifrostResponsesRequest) (*schemas.BifrostResponsesResponse, *schemas.BifrostError) {
+	chatResponse, err := provider.serverlessChatCompletion(ctx, key, request.ToChatRequest())
+	if err != nil {
+		return nil, err
+	}
+
+	response := chatResponse.ToBifrostResponsesResponse()
+	response.ExtraFields.RequestType = schemas.ResponsesRequest
+	response.ExtraFields.Provider = provider.GetProviderKey()
+	response.ExtraFields.ModelRequested = request.Model
+	response.ExtraFields.ModelDeployment = chatResponse.ExtraFields.ModelDeployment
+
+	return response, nil
+}
+
+// serverlessResponsesStream performs a streaming responses request to a serverless endpoint through its chat completions route.
+func (provider *AzureProvider) serverlessResponsesStream(ctx context.Context, postHookRunner schemas.PostHookRunner, key schemas.Key, request *schemas.BifrostResponsesRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	ctx = context.WithValue(ctx, schemas.BifrostContextKeyIsResponsesToChatCompletionFallback, true)
+	return provider.serverlessChatCompletionStream(
+		ctx,
+		postHookRunner,
+		key,
+		request.ToChatRequest(),
+	)
+}
+
+// serverlessEmbedding generates embeddings with a serverless endpoint.
+func (provider *AzureProvider) serverlessEmbedding(ctx context.Context, key schemas.Key, request *schemas.BifrostEmbeddingRequest) (*schemas.BifrostEmbeddingResponse, *schemas.BifrostError) {
+	deployment, err := provider.getModelDeployment(key, request.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonData, bifrostErr := providerUtils.CheckContextAndGetRequestBody(
+		ctx,
+		request,
+		func() (any, error) {
+			reqBody := openai.ToOpenAIEmbeddingRequest(request)
+			if reqBody != nil {
+				reqBody.Model = deployment
+			}
+			return reqBody, nil
+		},
+		provider.GetProviderKey())
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	responseBody, deployment, latency, err := provider.completeRequest(
+		ctx,
+		jsonData,
+		"embeddings",
+		key,
+		deployment,
+		request.Model,
+		schemas.EmbeddingRequest,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &schemas.BifrostEmbeddingResponse{}
+	rawResponse, bifrostErr := providerUtils.HandleProviderResponse(responseBody, response, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	response.ExtraFields.Provider = provider.GetProviderKey()
+	response.ExtraFields.Latency = latency.Milliseconds()
+	response.ExtraFields.ModelRequested = request.Model
+	response.ExtraFields.ModelDeployment = deployment
+	response.ExtraFields.RequestType = schemas.EmbeddingRequest
+
+	if providerUtils.ShouldSendBackRawResponse(ctx, provider.sendBackRawResponse) {
+		response.ExtraFields.RawResponse = rawResponse
+	}
+
+	return response, nil
+}
@@ -5,6 +5,9 @@ const AzureAPIVersionDefault = "2024-10-21"
 const AzureAPIVersionPreview = "preview"
 const AzureAnthropicAPIVersionDefault = "2023-06-01"
 
+// AzureServerlessAPIVersionDefault is the default Azure AI model inference API version for serverless endpoints.
+const AzureServerlessAPIVersionDefault = "2024-05-01-preview"
+
 type AzureModelCapabilities struct {
 	FineTune       bool `json:"fine_tune"`
 	Inference      bool `json:"inference"`
@@ -33,3 +36,10 @@ type AzureListModelsResponse struct {
 	Object string       `json:"object"`
 	Data   []AzureModel `json:"data"`
 }
+
+// AzureServerlessInfoResponse is the response of the /info route of a serverless endpoint, describing the served model
+type AzureServerlessInfoResponse struct {
+	ModelName         string `json:"model_name"`
+	ModelType         string `json:"model_type"`
+	ModelProviderName string `json:"model_provider_name"`
+}
@@ -13,10 +13,8 @@ import (
 	"sync"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
 	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/bytedance/sonic"
 	"github.com/maximhq/bifrost/core/providers/anthropic"
 	"github.com/maximhq/bifrost/core/providers/cohere"
@@ -60,7 +58,10 @@ func releaseBedrockChatResponse(resp *BedrockConverseResponse) {
 func NewBedrockProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*BedrockProvider, error) {
 	config.CheckAndSetDefaults()
 
-	client := &http.Client{Timeout: time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds)}
+	client := &http.Client{
+		Timeout:   time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		Transport: providerUtils.NewNetHTTPTransport(config, logger),
+	}
 
 	// Pre-warm response pools
 	for i := 0; i < config.ConcurrencyAndBufferSize.Concurrency; i++ {
@@ -86,11 +87,7 @@ func (provider *BedrockProvider) GetProviderKey() schemas.ModelProvider {
 // Returns the response body, request latency, or an error if the request fails.
 func (provider *BedrockProvider) completeRequest(ctx context.Context, jsonData []byte, path string, key schemas.Key) ([]byte, time.Duration, *schemas.BifrostError) {
 	config := key.BedrockKeyConfig
-
-	region := DefaultBedrockRegion
-	if config.Region != nil {
-		region = *config.Region
-	}
+	region := keyRegion(key)
 
 	// Create the request with the JSON body
 	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com/model/%s", region, path), bytes.NewBuffer(jsonData))
@@ -112,12 +109,13 @@ func (provider *BedrockProvider) completeRequest(ctx context.Context, jsonData [
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", key.Value))
 	} else {
 		// Sign the request using either explicit credentials or IAM role authentication
-		if err := signAWSRequest(ctx, req, config.AccessKey, config.SecretKey, config.SessionToken, region, "bedrock", provider.GetProviderKey()); err != nil {
+		if err := signAWSRequest(ctx, req, config, region, "bedrock", provider.GetProviderKey()); err != nil {
 			return nil, 0, err
 		}
 	}
 
 	// Execute the request and measure latency
+	providerUtils.CaptureHTTPProviderRequest(ctx, req, jsonData)
 	startTime := time.Now()
 	resp, err := provider.client.Do(req)
 	latency := time.Since(startTime)
@@ -195,10 +193,7 @@ func (provider *BedrockProvider) makeStreamingRequest(ctx context.Context, jsonD
 	// Format the path with proper model identifier for streaming
 	path, deployment := provider.getModelPath(action, model, key)
 
-	region := DefaultBedrockRegion
-	if key.BedrockKeyConfig.Region != nil {
-		region = *key.BedrockKeyConfig.Region
-	}
+	region := keyRegion(key)
 
 	// Create HTTP request for streaming
 	req, reqErr := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com/model/%s", region, path), bytes.NewReader(jsonData))
@@ -216,7 +211,7 @@ func (provider *BedrockProvider) makeStreamingRequest(ctx context.Context, jsonD
 	} else {
 		req.Header.Set("Accept", "application/vnd.amazon.eventstream")
 		// Sign the request using either explicit credentials or IAM role authentication
-		if err := signAWSRequest(ctx, req, key.BedrockKeyConfig.AccessKey, key.BedrockKeyConfig.SecretKey, key.BedrockKeyConfig.SessionToken, region, "bedrock", providerName); err != nil {
+		if err := signAWSRequest(ctx, req, key.BedrockKeyConfig, region, "bedrock", providerName); err != nil {
 			return nil, deployment, err
 		}
 	}
@@ -252,7 +247,7 @@ func (provider *BedrockProvider) makeStreamingRequest(ctx context.Context, jsonD
 // It sets required headers, calculates the request body hash, and signs the request
 // using the provided AWS credentials.
 // Returns a BifrostError if signing fails.
-func signAWSRequest(ctx context.Context, req *http.Request, accessKey, secretKey string, sessionToken *string, region, service string, providerName schemas.ModelProvider) *schemas.BifrostError {
+func signAWSRequest(ctx context.Context, req *http.Request, keyConfig *schemas.BedrockKeyConfig, region, service string, providerName schemas.ModelProvider) *schemas.BifrostError {
 	// Set required headers before signing
 	req.Header.Set("Content-Type", "application/json")
 	if req.Header.Get("Accept") == "" {
@@ -277,44 +272,15 @@ func signAWSRequest(ctx context.Context, req *http.Request, accessKey, secretKey
 		bodyHash = hex.EncodeToString(hash[:])
 	}
 
-	var cfg aws.Config
-	var err error
-
-	// If both accessKey and secretKey are empty, use the default credential provider chain
-	// This will automatically use IAM roles, environment variables, shared credentials, etc.
-	if accessKey == "" && secretKey == "" {
-		cfg, err = config.LoadDefaultConfig(ctx,
-			config.WithRegion(region),
-		)
-	} else {
-		// Use explicit credentials when provided
-		cfg, err = config.LoadDefaultConfig(ctx,
-			config.WithRegion(region),
-			config.WithCredentialsProvider(aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
-				creds := aws.Credentials{
-					AccessKeyID:     accessKey,
-					SecretAccessKey: secretKey,
-				}
-				if sessionToken != nil && *sessionToken != "" {
-					creds.SessionToken = *sessionToken
-				}
-				return creds, nil
-			})),
-		)
-	}
+	// Get credentials
+	creds, err := getAWSCredentials(ctx, keyConfig, region)
 	if err != nil {
-		return providerUtils.NewBifrostOperationError("failed to load aws config", err, providerName)
+		return providerUtils.NewBifrostOperationError("failed to retrieve aws credentials", err, providerName)
 	}
 
 	// Create the AWS signer
 	signer := v4.NewSigner()
 
-	// Get credentials
-	creds, err := cfg.Credentials.Retrieve(ctx)
-	if err != nil {
-		return providerUtils.NewBifrostOperationError("failed to retrieve aws credentials", err, providerName)
-	}
-
 	// Sign the request with AWS Signature V4
 	if err := signer.SignHTTP(ctx, creds, req, bodyHash, service, region, time.Now()); err != nil {
 		return providerUtils.NewBifrostOperationError("failed to sign request", err, providerName)
@@ -333,11 +299,7 @@ func (provider *BedrockProvider) listModelsByKey(ctx context.Context, key schema
 	}
 
 	config := key.BedrockKeyConfig
-
-	region := DefaultBedrockRegion
-	if config.Region != nil {
-		region = *config.Region
-	}
+	region := keyRegion(key)
 
 	// Build query parameters
 	params := url.Values{}
@@ -379,7 +341,7 @@ func (provider *BedrockProvider) listModelsByKey(ctx context.Context, key schema
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", key.Value))
 	} else {
 		// Sign the request using either explicit credentials or IAM role authentication
-		if err := signAWSRequest(ctx, req, config.AccessKey, config.SecretKey, config.SessionToken, region, "bedrock", providerName); err != nil {
+		if err := signAWSRequest(ctx, req, config, region, "bedrock", providerName); err != nil {
 			return nil, err
 		}
 	}
@@ -485,7 +447,15 @@ func (provider *BedrockProvider) ListModels(ctx context.Context, keys []schemas.
 // TextCompletion performs a text completion request to Bedrock's API.
 // It formats the request, sends it to Bedrock, and processes the response.
 // Returns a BifrostResponse containing the completion results or an error if the request fails.
+// Requests failing with a regional error are retried in the fallback regions of the key, see withRegionFallback.
 func (provider *BedrockProvider) TextCompletion(ctx context.Context, key schemas.Key, request *schemas.BifrostTextCompletionRequest) (*schemas.BifrostTextCompletionResponse, *schemas.BifrostError) {
+	return withRegionFallback(ctx, provider.logger, key, func(key schemas.Key) (*schemas.BifrostTextCompletionResponse, *schemas.BifrostError) {
+		return provider.textCompletion(ctx, key, request)
+	})
+}
+
+// textCompletion performs a text completion request in the region of the key.
+func (provider *BedrockProvider) textCompletion(ctx context.Context, key schemas.Key, request *schemas.BifrostTextCompletionRequest) (*schemas.BifrostTextCompletionResponse, *schemas.BifrostError) {
 	if err := providerUtils.CheckOperationAllowed(schemas.Bedrock, provider.customProviderConfig, schemas.TextCompletionRequest); err != nil {
 		return nil, err
 	}
@@ -536,6 +506,7 @@ func (provider *BedrockProvider) TextCompletion(ctx context.Context, key schemas
 	bifrostResponse.ExtraFields.Provider = providerName
 	bifrostResponse.ExtraFields.ModelRequested = request.Model
 	bifrostResponse.ExtraFields.ModelDeployment = deployment
+	bifrostResponse.ExtraFields.Region = keyRegion(key)
 	bifrostResponse.ExtraFields.RequestType = schemas.TextCompletionRequest
 	bifrostResponse.ExtraFields.Latency = latency.Milliseconds()
 
@@ -554,7 +525,15 @@ func (provider *BedrockProvider) TextCompletion(ctx context.Context, key schemas
 // TextCompletionStream performs a streaming text completion request to Bedrock's API.
 // It formats the request, sends it to Bedrock, and processes the response.
 // Returns a channel of BifrostStream objects or an error if the request fails.
+// Streams failing to open with a regional error are retried in the fallback regions of the key, see withRegionFallback.
 func (provider *BedrockProvider) TextCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, key schemas.Key, request *schemas.BifrostTextCompletionRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return withRegionFallback(ctx, provider.logger, key, func(key schemas.Key) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+		return provider.textCompletionStream(ctx, postHookRunner, key, request)
+	})
+}
+
+// textCompletionStream performs a streaming text completion request in the region of the key.
+func (provider *BedrockProvider) textCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, key schemas.Key, request *schemas.BifrostTextCompletionRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
 	if err := providerUtils.CheckOperationAllowed(schemas.Bedrock, provider.customProviderConfig, schemas.TextCompletionStreamRequest); err != nil {
 		return nil, err
 	}
@@ -580,7 +559,7 @@ func (provider *BedrockProvider) TextCompletionStream(ctx context.Context, postH
 	}
 
 	// Create response channel
-	responseChan := make(chan *schemas.BifrostStream, schemas.DefaultStreamBufferSize)
+	responseChan := make(chan *schemas.BifrostStream, providerUtils.GetStreamBufferSize(ctx))
 
 	// Start streaming in a goroutine
 	go func() {
@@ -644,6 +623,7 @@ func (provider *BedrockProvider) TextCompletionStream(ctx context.Context, postH
 						Provider:        providerName,
 						ModelRequested:  request.Model,
 						ModelDeployment: deployment,
+						Region:          keyRegion(key),
 						Latency:         time.Since(startTime).Milliseconds(),
 						// Pass the raw JSON string from the chunk bytes
 						RawResponse: string(chunkPayload.Bytes),
@@ -661,7 +641,15 @@ func (provider *BedrockProvider) TextCompletionStream(ctx context.Context, postH
 // ChatCompletion performs a chat completion request to Bedrock's API.
 // It formats the request, sends it to Bedrock, and processes the response.
 // Returns a BifrostResponse containing the completion results or an error if the request fails.
+// Requests failing with a regional error are retried in the fallback regions of the key, see withRegionFallback.
 func (provider *BedrockProvider) ChatCompletion(ctx context.Context, key schemas.Key, request *schemas.BifrostChatRequest) (*schemas.BifrostChatResponse, *schemas.BifrostError) {
+	return withRegionFallback(ctx, provider.logger, key, func(key schemas.Key) (*schemas.BifrostChatResponse, *schemas.BifrostError) {
+		return provider.chatCompletion(ctx, key, request)
+	})
+}
+
+// chatCompletion performs a chat completion request in the region of the key.
+func (provider *BedrockProvider) chatCompletion(ctx context.Context, key schemas.Key, request *schemas.BifrostChatRequest) (*schemas.BifrostChatResponse, *schemas.BifrostError) {
 	if err := providerUtils.CheckOperationAllowed(schemas.Bedrock, provider.customProviderConfig, schemas.ChatCompletionRequest); err != nil {
 		return nil, err
 	}
@@ -676,7 +664,14 @@ func (provider *BedrockProvider) ChatCompletion(ctx context.Context, key schemas
 	jsonData, bifrostErr := providerUtils.CheckContextAndGetRequestBody(
 		ctx,
 		request,
-		func() (any, error) { return ToBedrockChatCompletionRequest(request) },
+		func() (any, error) {
+			reqBody, err := ToBedrockChatCompletionRequest(request)
+			if err != nil {
+				return nil, err
+			}
+			applyKeyGuardrail(reqBody, key)
+			return reqBody, nil
+		},
 		provider.GetProviderKey())
 	if bifrostErr != nil {
 		return nil, bifrostErr
@@ -710,6 +705,8 @@ func (provider *BedrockProvider) ChatCompletion(ctx context.Context, key schemas
 	bifrostResponse.ExtraFields.Provider = providerName
 	bifrostResponse.ExtraFields.ModelRequested = request.Model
 	bifrostResponse.ExtraFields.ModelDeployment = deployment
+	bifrostResponse.ExtraFields.Region = keyRegion(key)
+	bifrostResponse.ExtraFields.Guardrail = toBifrostGuardrail(bedrockResponse.StopReason, bedrockResponse.Trace)
 	bifrostResponse.ExtraFields.RequestType = schemas.ChatCompletionRequest
 	bifrostResponse.ExtraFields.Latency = latency.Milliseconds()
 
@@ -727,7 +724,15 @@ func (provider *BedrockProvider) ChatCompletion(ctx context.Context, key schemas
 // ChatCompletionStream performs a streaming chat completion request to Bedrock's API.
 // It formats the request, sends it to Bedrock, and processes the streaming response.
 // Returns a channel for streaming BifrostResponse objects or an error if the request fails.
+// Streams failing to open with a regional error are retried in the fallback regions of the key, see withRegionFallback.
 func (provider *BedrockProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, key schemas.Key, request *schemas.BifrostChatRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return withRegionFallback(ctx, provider.logger, key, func(key schemas.Key) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+		return provider.chatCompletionStream(ctx, postHookRunner, key, request)
+	})
+}
+
+// chatCompletionStream performs a streaming chat completion request in the region of the key.
+func (provider *BedrockProvider) chatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, key schemas.Key, request *schemas.BifrostChatRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
 	if err := providerUtils.CheckOperationAllowed(schemas.Bedrock, provider.customProviderConfig, schemas.ChatCompletionStreamRequest); err != nil {
 		return nil, err
 	}
@@ -737,7 +742,14 @@ func (provider *BedrockProvider) ChatCompletionStream(ctx context.Context, postH
 	jsonData, bifrostErr := providerUtils.CheckContextAndGetRequestBody(
 		ctx,
 		request,
-		func() (any, error) { return ToBedrockChatCompletionRequest(request) },
+		func() (any, error) {
+			reqBody, err := ToBedrockChatCompletionRequest(request)
+			if err != nil {
+				return nil, err
+			}
+			applyKeyGuardrail(reqBody, key)
+			return reqBody, nil
+		},
 		provider.GetProviderKey())
 	if bifrostErr != nil {
 		return nil, bifrostErr
@@ -749,7 +761,7 @@ func (provider *BedrockProvider) ChatCompletionStream(ctx context.Context, postH
 	}
 
 	// Create response channel
-	responseChan := make(chan *schemas.BifrostStream, schemas.DefaultStreamBufferSize)
+	responseChan := make(chan *schemas.BifrostStream, providerUtils.GetStreamBufferSize(ctx))
 
 	// Start streaming in a goroutine
 	go func() {
@@ -760,6 +772,8 @@ func (provider *BedrockProvider) ChatCompletionStream(ctx context.Context, postH
 		var messageID string
 		usage := &schemas.BifrostLLMUsage{}
 		var finishReason *string
+		var stopReason string
+		var trace *BedrockConverseTrace
 		chunkIndex := 0
 
 		// Process AWS Event Stream format using proper decoder
@@ -838,8 +852,12 @@ func (provider *BedrockProvider) ChatCompletionStream(ctx context.Context, postH
 				}
 
 				if streamEvent.StopReason != nil {
+					stopReason = *streamEvent.StopReason
 					finishReason = schemas.Ptr(anthropic.ConvertAnthropicFinishReasonToBifrost(anthropic.AnthropicStopReason(*streamEvent.StopReason)))
 				}
+				if streamEvent.Trace != nil {
+					trace = streamEvent.Trace
+				}
 
 				response, bifrostErr, _ := streamEvent.ToBifrostChatCompletionStream()
 				if bifrostErr != nil {
@@ -860,6 +878,7 @@ func (provider *BedrockProvider) ChatCompletionStream(ctx context.Context, postH
 						Provider:        providerName,
 						ModelRequested:  request.Model,
 						ModelDeployment: deployment,
+						Region:          keyRegion(key),
 						ChunkIndex:      chunkIndex,
 						Latency:         time.Since(lastChunkTime).Milliseconds(),
 					}
@@ -878,6 +897,8 @@ func (provider *BedrockProvider) ChatCompletionStream(ctx context.Context, postH
 		// Send final response
 		response := providerUtils.CreateBifrostChatCompletionChunkResponse(messageID, usage, finishReason, chunkIndex, schemas.ChatCompletionStreamRequest, providerName, request.Model)
 		response.ExtraFields.ModelDeployment = deployment
+		response.ExtraFields.Region = keyRegion(key)
+		response.ExtraFields.Guardrail = toBifrostGuardrail(stopReason, trace)
 		response.ExtraFields.Latency = time.Since(startTime).Milliseconds()
 		ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
 		providerUtils.ProcessAndSendResponse(ctx, postHookRunner, providerUtils.GetBifrostResponseForStreamResponse(nil, response, nil, nil, nil), responseChan)
@@ -889,7 +910,15 @@ func (provider *BedrockProvider) ChatCompletionStream(ctx context.Context, postH
 // Responses performs a chat completion request to Anthropic's API.
 // It formats the request, sends it to Anthropic, and processes the response.
 // Returns a BifrostResponse containing the completion results or an error if the request fails.
+// Requests failing with a regional error are retried in the fallback regions of the key, see withRegionFallback.
 func (provider *BedrockProvider) Responses(ctx context.Context, key schemas.Key, request *schemas.BifrostResponsesRequest) (*schemas.BifrostResponsesResponse, *schemas.BifrostError) {
+	return withRegionFallback(ctx, provider.logger, key, func(key schemas.Key) (*schemas.BifrostResponsesResponse, *schemas.BifrostError) {
+		return provider.responses(ctx, key, request)
+	})
+}
+
+// responses performs a responses request in the region of the key.
+func (provider *BedrockProvider) responses(ctx context.Context, key schemas.Key, request *schemas.BifrostResponsesRequest) (*schemas.BifrostResponsesResponse, *schemas.BifrostError) {
 	if err := providerUtils.CheckOperationAllowed(schemas.Bedrock, provider.customProviderConfig, schemas.ResponsesRequest); err != nil {
 		return nil, err
 	}
@@ -904,7 +933,14 @@ func (provider *BedrockProvider) Responses(ctx context.Context, key schemas.Key,
 	jsonData, bifrostErr := providerUtils.CheckContextAndGetRequestBody(
 		ctx,
 		request,
-		func() (any, error) { return ToBedrockResponsesRequest(request) },
+		func() (any, error) {
+			reqBody, err := ToBedrockResponsesRequest(request)
+			if err != nil {
+				return nil, err
+			}
+			applyKeyGuardrail(reqBody, key)
+			return reqBody, nil
+		},
 		provider.GetProviderKey())
 	if bifrostErr != nil {
 		return nil, bifrostErr
@@ -940,6 +976,8 @@ func (provider *BedrockProvider) Responses(ctx context.Context, key schemas.Key,
 	bifrostResponse.ExtraFields.Provider = providerName
 	bifrostResponse.ExtraFields.ModelRequested = request.Model
 	bifrostResponse.ExtraFields.ModelDeployment = deployment
+	bifrostResponse.ExtraFields.Region = keyRegion(key)
+	bifrostResponse.ExtraFields.Guardrail = toBifrostGuardrail(bedrockResponse.StopReason, bedrockResponse.Trace)
 	bifrostResponse.ExtraFields.RequestType = schemas.ResponsesRequest
 	bifrostResponse.ExtraFields.Latency = latency.Milliseconds()
 
@@ -957,7 +995,15 @@ func (provider *BedrockProvider) Responses(ctx context.Context, key schemas.Key,
 // ResponsesStream performs a streaming chat completion request to Bedrock's API.
 // It formats the request, sends it to Bedrock, and processes the streaming response.
 // Returns a channel for streaming BifrostResponse objects or an error if the request fails.
+// Streams failing to open with a regional error are retried in the fallback regions of the key, see withRegionFallback.
 func (provider *BedrockProvider) ResponsesStream(ctx context.Context, postHookRunner schemas.PostHookRunner, key schemas.Key, request *schemas.BifrostResponsesRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return withRegionFallback(ctx, provider.logger, key, func(key schemas.Key) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+		return provider.responsesStream(ctx, postHookRunner, key, request)
+	})
+}
+
+// responsesStream performs a streaming responses request in the region of the key.
+func (provider *BedrockProvider) responsesStream(ctx context.Context, postHookRunner schemas.PostHookRunner, key schemas.Key, request *schemas.BifrostResponsesRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
 	if err := providerUtils.CheckOperationAllowed(schemas.Bedrock, provider.customProviderConfig, schemas.ResponsesStreamRequest); err != nil {
 		return nil, err
 	}
@@ -967,7 +1013,14 @@ func (provider *BedrockProvider) ResponsesStream(ctx context.Context, postHookRu
 	jsonData, bifrostErr := providerUtils.CheckContextAndGetRequestBody(
 		ctx,
 		request,
-		func() (any, error) { return ToBedrockResponsesRequest(request) },
+		func() (any, error) {
+			reqBody, err := ToBedrockResponsesRequest(request)
+			if err != nil {
+				return nil, err
+			}
+			applyKeyGuardrail(reqBody, key)
+			return reqBody, nil
+		},
 		provider.GetProviderKey())
 	if bifrostErr != nil {
 		return nil, bifrostErr
@@ -979,7 +1032,7 @@ func (provider *BedrockProvider) ResponsesStream(ctx context.Context, postHookRu
 	}
 
 	// Create response channel
-	responseChan := make(chan *schemas.BifrostStream, schemas.DefaultStreamBufferSize)
+	responseChan := make(chan *schemas.BifrostStream, providerUtils.GetStreamBufferSize(ctx))
 
 	// Start streaming in a goroutine
 	go func() {
@@ -988,6 +1041,8 @@ func (provider *BedrockProvider) ResponsesStream(ctx context.Context, postHookRu
 
 		// Process AWS Event Stream format
 		usage := &schemas.ResponsesResponseUsage{}
+		var stopReason string
+		var trace *BedrockConverseTrace
 		chunkIndex := 0
 
 		// Create stream state for stateful conversions
@@ -1015,6 +1070,7 @@ func (provider *BedrockProvider) ResponsesStream(ctx context.Context, postHookRu
 							Provider:        providerName,
 							ModelRequested:  request.Model,
 							ModelDeployment: deployment,
+							Region:          keyRegion(key),
 							ChunkIndex:      chunkIndex,
 							Latency:         time.Since(lastChunkTime).Milliseconds(),
 						}
@@ -1027,6 +1083,7 @@ func (provider *BedrockProvider) ResponsesStream(ctx context.Context, postHookRu
 
 						if i == len(finalResponses)-1 {
 							finalResponse.ExtraFields.Latency = time.Since(startTime).Milliseconds()
+							finalResponse.ExtraFields.Guardrail = toBifrostGuardrail(stopReason, trace)
 						}
 
 						providerUtils.ProcessAndSendResponse(ctx, postHookRunner, providerUtils.GetBifrostResponseForStreamResponse(nil, nil, finalResponse, nil, nil), responseChan)
@@ -1094,6 +1151,13 @@ func (provider *BedrockProvider) ResponsesStream(ctx context.Context, postHookRu
 					}
 				}
 
+				if streamEvent.StopReason != nil {
+					stopReason = *streamEvent.StopReason
+				}
+				if streamEvent.Trace != nil {
+					trace = streamEvent.Trace
+				}
+
 				responses, bifrostErr, _ := streamEvent.ToBifrostResponsesStream(chunkIndex, streamState)
 				if bifrostErr != nil {
 					bifrostErr.ExtraFields = schemas.BifrostErrorExtraFields{
@@ -1112,6 +1176,7 @@ func (provider *BedrockProvider) ResponsesStream(ctx context.Context, postHookRu
 							Provider:        providerName,
 							ModelRequested:  request.Model,
 							ModelDeployment: deployment,
+							Region:          keyRegion(key),
 							ChunkIndex:      chunkIndex,
 							Latency:         time.Since(lastChunkTime).Milliseconds(),
 						}
@@ -1134,7 +1199,15 @@ func (provider *BedrockProvider) ResponsesStream(ctx context.Context, postHookRu
 
 // Embedding generates embeddings for the given input text(s) using Amazon Bedrock.
 // Supports Titan and Cohere embedding models. Returns a BifrostResponse containing the embedding(s) and any error that occurred.
+// Requests failing with a regional error are retried in the fallback regions of the key, see withRegionFallback.
 func (provider *BedrockProvider) Embedding(ctx context.Context, key schemas.Key, request *schemas.BifrostEmbeddingRequest) (*schemas.BifrostEmbeddingResponse, *schemas.BifrostError) {
+	return withRegionFallback(ctx, provider.logger, key, func(key schemas.Key) (*schemas.BifrostEmbeddingResponse, *schemas.BifrostError) {
+		return provider.embedding(ctx, key, request)
+	})
+}
+
+// embedding performs an embedding request in the region of the key.
+func (provider *BedrockProvider) embedding(ctx context.Context, key schemas.Key, request *schemas.BifrostEmbeddingRequest) (*schemas.BifrostEmbeddingResponse, *schemas.BifrostError) {
 	if err := providerUtils.CheckOperationAllowed(schemas.Bedrock, provider.customProviderConfig, schemas.EmbeddingRequest); err != nil {
 		return nil, err
 	}
@@ -1214,6 +1287,7 @@ func (provider *BedrockProvider) Embedding(ctx context.Context, key schemas.Key,
 	bifrostResponse.ExtraFields.Provider = providerName
 	bifrostResponse.ExtraFields.ModelRequested = request.Model
 	bifrostResponse.ExtraFields.ModelDeployment = deployment
+	bifrostResponse.ExtraFields.Region = keyRegion(key)
 	bifrostResponse.ExtraFields.RequestType = schemas.EmbeddingRequest
 	bifrostResponse.ExtraFields.Latency = latency.Milliseconds()
 
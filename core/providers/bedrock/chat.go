@@ -25,7 +25,7 @@ func ToBedrockChatCompletionRequest(bifrostReq *schemas.BifrostChatRequest) (*Be
 	}
 
 	// Convert messages and system messages
-	messages, systemMessages, err := convertMessages(bifrostReq.Input)
+	messages, systemMessages, err := convertMessages(schemas.NormalizeToolCalls(bifrostReq.Input))
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert messages: %w", err)
 	}
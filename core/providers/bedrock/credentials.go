@@ -0,0 +1,95 @@
+package bedrock
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// DefaultBedrockRoleSessionName is the session name of assumed roles when the key doesn't set one.
+const DefaultBedrockRoleSessionName = "bifrost"
+
+// credentialsProviders caches the AWS credentials provider of each key configuration, so that credentials
+// loaded from the environment or obtained by assuming a role are reused across requests and refreshed
+// before they expire instead of being fetched for every request.
+var credentialsProviders sync.Map // map[string]aws.CredentialsProvider
+
+// getAWSCredentials returns the AWS credentials of a Bedrock key config.
+// The base credentials are the static access and secret keys of the config, or, when both are empty,
+// the default credential chain, which covers environment variables, shared config, EKS Pod Identity,
+// IRSA (web identity tokens) and instance roles. If RoleARN is set, the base credentials assume the role.
+func getAWSCredentials(ctx context.Context, keyConfig *schemas.BedrockKeyConfig, region string) (aws.Credentials, error) {
+	cacheKey := credentialsCacheKey(keyConfig, region)
+	if provider, ok := credentialsProviders.Load(cacheKey); ok {
+		return provider.(aws.CredentialsProvider).Retrieve(ctx)
+	}
+
+	provider, err := newCredentialsProvider(ctx, keyConfig, region)
+	if err != nil {
+		return aws.Credentials{}, err
+	}
+	actual, _ := credentialsProviders.LoadOrStore(cacheKey, provider)
+	return actual.(aws.CredentialsProvider).Retrieve(ctx)
+}
+
+// newCredentialsProvider creates the caching credentials provider of a Bedrock key config.
+func newCredentialsProvider(ctx context.Context, keyConfig *schemas.BedrockKeyConfig, region string) (aws.CredentialsProvider, error) {
+	options := []func(*config.LoadOptions) error{config.WithRegion(region)}
+	if keyConfig.AccessKey != "" || keyConfig.SecretKey != "" {
+		// Use explicit credentials when provided
+		creds := aws.Credentials{
+			AccessKeyID:     keyConfig.AccessKey,
+			SecretAccessKey: keyConfig.SecretKey,
+		}
+		if keyConfig.SessionToken != nil && *keyConfig.SessionToken != "" {
+			creds.SessionToken = *keyConfig.SessionToken
+		}
+		options = append(options, config.WithCredentialsProvider(aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return creds, nil
+		})))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyConfig.RoleARN == nil || *keyConfig.RoleARN == "" {
+		return cfg.Credentials, nil
+	}
+
+	// Assume the role with the base credentials, the cache refreshes the session before it expires
+	assumeRoleProvider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), *keyConfig.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = DefaultBedrockRoleSessionName
+		if keyConfig.RoleSessionName != nil && *keyConfig.RoleSessionName != "" {
+			o.RoleSessionName = *keyConfig.RoleSessionName
+		}
+		if keyConfig.ExternalID != nil && *keyConfig.ExternalID != "" {
+			o.ExternalID = keyConfig.ExternalID
+		}
+	})
+	return aws.NewCredentialsCache(assumeRoleProvider), nil
+}
+
+// credentialsCacheKey returns the key of a Bedrock key config in credentialsProviders.
+// The fields are hashed so the cache doesn't hold secrets in its keys.
+func credentialsCacheKey(keyConfig *schemas.BedrockKeyConfig, region string) string {
+	hash := sha256.Sum256([]byte(strings.Join([]string{
+		keyConfig.AccessKey,
+		keyConfig.SecretKey,
+		derefString(keyConfig.SessionToken),
+		derefString(keyConfig.RoleARN),
+		derefString(keyConfig.ExternalID),
+		derefString(keyConfig.RoleSessionName),
+		region,
+	}, "\x00")))
+	return hex.EncodeToString(hash[:])
+}
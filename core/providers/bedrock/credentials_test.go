@@ -0,0 +1,42 @@
+package bedrock
+
+import (
+	"context"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAWSCredentials(t *testing.T) {
+	keyConfig := &schemas.BedrockKeyConfig{
+		AccessKey:    "AKIAEXAMPLE",
+		SecretKey:    "secret",
+		SessionToken: schemas.Ptr("token"),
+	}
+
+	creds, err := getAWSCredentials(context.Background(), keyConfig, "us-east-1")
+	require.NoError(t, err)
+	assert.Equal(t, "AKIAEXAMPLE", creds.AccessKeyID)
+	assert.Equal(t, "secret", creds.SecretAccessKey)
+	assert.Equal(t, "token", creds.SessionToken)
+
+	_, ok := credentialsProviders.Load(credentialsCacheKey(keyConfig, "us-east-1"))
+	assert.True(t, ok, "credentials provider must be cached")
+}
+
+func TestCredentialsCacheKey(t *testing.T) {
+	keyConfig := &schemas.BedrockKeyConfig{AccessKey: "AKIAEXAMPLE", SecretKey: "secret"}
+	roleConfig := &schemas.BedrockKeyConfig{
+		AccessKey:  "AKIAEXAMPLE",
+		SecretKey:  "secret",
+		RoleARN:    schemas.Ptr("arn:aws:iam::123456789012:role/BedrockRole"),
+		ExternalID: schemas.Ptr("external"),
+	}
+
+	assert.Equal(t, credentialsCacheKey(keyConfig, "us-east-1"), credentialsCacheKey(&schemas.BedrockKeyConfig{AccessKey: "AKIAEXAMPLE", SecretKey: "secret"}, "us-east-1"))
+	assert.NotEqual(t, credentialsCacheKey(keyConfig, "us-east-1"), credentialsCacheKey(keyConfig, "us-west-2"))
+	assert.NotEqual(t, credentialsCacheKey(keyConfig, "us-east-1"), credentialsCacheKey(roleConfig, "us-east-1"))
+	assert.NotContains(t, credentialsCacheKey(keyConfig, "us-east-1"), "secret")
+}
@@ -19,13 +19,22 @@ func ToBedrockTitanEmbeddingRequest(bifrostReq *schemas.BifrostEmbeddingRequest)
 		return nil, fmt.Errorf("no input text provided for embedding")
 	}
 
-	// Validate dimensions parameter - Titan models do not support it
-	if bifrostReq.Params != nil && bifrostReq.Params.Dimensions != nil {
-		return nil, fmt.Errorf("amazon Titan embedding models do not support custom dimensions parameter")
-	}
-
 	titanReq := &BedrockTitanEmbeddingRequest{}
 
+	// Dimensions and normalization are only supported from Titan Text Embeddings V2
+	if bifrostReq.Params != nil {
+		isV2 := strings.Contains(bifrostReq.Model, "amazon.titan-embed-text-v2")
+		if bifrostReq.Params.Dimensions != nil {
+			if !isV2 {
+				return nil, fmt.Errorf("amazon Titan embedding models before v2 do not support custom dimensions parameter")
+			}
+			titanReq.Dimensions = bifrostReq.Params.Dimensions
+		}
+		if isV2 {
+			titanReq.Normalize = bifrostReq.Params.Normalize
+		}
+	}
+
 	// Set input text
 	if bifrostReq.Input.Text != nil {
 		titanReq.InputText = *bifrostReq.Input.Text
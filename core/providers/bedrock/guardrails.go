@@ -0,0 +1,136 @@
+package bedrock
+
+import (
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// BedrockStopReasonGuardrailIntervened is the stop reason of Converse responses blocked by a guardrail.
+const BedrockStopReasonGuardrailIntervened = "guardrail_intervened"
+
+// applyKeyGuardrail attaches the guardrail configured on the key to a Converse request.
+// Guardrails set on the request take precedence over the key's guardrail.
+func applyKeyGuardrail(bedrockReq *BedrockConverseRequest, key schemas.Key) {
+	if bedrockReq == nil || bedrockReq.GuardrailConfig != nil || key.BedrockKeyConfig == nil {
+		return
+	}
+	config := key.BedrockKeyConfig
+	if config.GuardrailID == nil || *config.GuardrailID == "" || config.GuardrailVersion == nil || *config.GuardrailVersion == "" {
+		return
+	}
+	bedrockReq.GuardrailConfig = &BedrockGuardrailConfig{
+		GuardrailIdentifier: *config.GuardrailID,
+		GuardrailVersion:    *config.GuardrailVersion,
+		// Tracing is required for Bedrock to return the assessments
+		Trace: schemas.Ptr("enabled"),
+	}
+}
+
+// toBifrostGuardrail converts the stop reason and guardrail trace of a Converse response to a BifrostGuardrail.
+// Returns nil if no guardrail was applied to the request.
+func toBifrostGuardrail(stopReason string, trace *BedrockConverseTrace) *schemas.BifrostGuardrail {
+	intervened := stopReason == BedrockStopReasonGuardrailIntervened
+	if !intervened && (trace == nil || trace.Guardrail == nil) {
+		return nil
+	}
+
+	guardrail := &schemas.BifrostGuardrail{Intervened: intervened}
+	if trace == nil || trace.Guardrail == nil {
+		return guardrail
+	}
+	if trace.Guardrail.ActionReason != nil {
+		guardrail.Reason = *trace.Guardrail.ActionReason
+	}
+	for _, assessment := range trace.Guardrail.InputAssessment {
+		guardrail.Findings = append(guardrail.Findings, assessment.toBifrostGuardrailFindings("input")...)
+	}
+	for _, assessments := range trace.Guardrail.OutputAssessments {
+		for _, assessment := range assessments {
+			guardrail.Findings = append(guardrail.Findings, assessment.toBifrostGuardrailFindings("output")...)
+		}
+	}
+	// Masking sensitive information doesn't stop the response, so findings with an action count as interventions too
+	for _, finding := range guardrail.Findings {
+		if finding.Action != "" && finding.Action != "NONE" {
+			guardrail.Intervened = true
+			break
+		}
+	}
+	return guardrail
+}
+
+// toBifrostGuardrailFindings flattens the policy matches of a guardrail assessment into findings.
+func (assessment BedrockGuardrailAssessment) toBifrostGuardrailFindings(source string) []schemas.BifrostGuardrailFinding {
+	var findings []schemas.BifrostGuardrailFinding
+	if assessment.TopicPolicy != nil {
+		for _, topic := range assessment.TopicPolicy.Topics {
+			findings = append(findings, schemas.BifrostGuardrailFinding{
+				Source: source,
+				Policy: "topic",
+				Type:   derefString(topic.Type),
+				Name:   derefString(topic.Name),
+				Action: derefString(topic.Action),
+			})
+		}
+	}
+	if assessment.ContentPolicy != nil {
+		for _, filter := range assessment.ContentPolicy.Filters {
+			findings = append(findings, schemas.BifrostGuardrailFinding{
+				Source:     source,
+				Policy:     "content",
+				Type:       derefString(filter.Type),
+				Confidence: derefString(filter.Confidence),
+				Action:     derefString(filter.Action),
+			})
+		}
+	}
+	if assessment.WordPolicy != nil {
+		for _, word := range assessment.WordPolicy.CustomWords {
+			findings = append(findings, schemas.BifrostGuardrailFinding{
+				Source: source,
+				Policy: "word",
+				Type:   "CUSTOM",
+				Match:  derefString(word.Match),
+				Action: derefString(word.Action),
+			})
+		}
+		for _, word := range assessment.WordPolicy.ManagedWordLists {
+			findings = append(findings, schemas.BifrostGuardrailFinding{
+				Source: source,
+				Policy: "word",
+				Type:   derefString(word.Type),
+				Match:  derefString(word.Match),
+				Action: derefString(word.Action),
+			})
+		}
+	}
+	if assessment.SensitiveInfoPolicy != nil {
+		for _, entity := range assessment.SensitiveInfoPolicy.PIIEntities {
+			findings = append(findings, schemas.BifrostGuardrailFinding{
+				Source: source,
+				Policy: "sensitive_information",
+				Type:   derefString(entity.Type),
+				Match:  derefString(entity.Match),
+				Action: derefString(entity.Action),
+			})
+		}
+		for _, regex := range assessment.SensitiveInfoPolicy.Regexes {
+			findings = append(findings, schemas.BifrostGuardrailFinding{
+				Source: source,
+				Policy: "sensitive_information",
+				Type:   "REGEX",
+				Name:   derefString(regex.Name),
+				Match:  derefString(regex.Match),
+				Action: derefString(regex.Action),
+			})
+		}
+	}
+	return findings
+}
+
+// derefString returns the value of a string pointer, or an empty string if it is nil.
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,96 @@
+package bedrock
+
+import (
+	"testing"
+
+	"github.com/bytedance/sonic"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyKeyGuardrail(t *testing.T) {
+	key := schemas.Key{
+		BedrockKeyConfig: &schemas.BedrockKeyConfig{
+			GuardrailID:      schemas.Ptr("gr-123"),
+			GuardrailVersion: schemas.Ptr("1"),
+		},
+	}
+
+	t.Run("AttachesKeyGuardrail", func(t *testing.T) {
+		bedrockReq := &BedrockConverseRequest{}
+		applyKeyGuardrail(bedrockReq, key)
+		require.NotNil(t, bedrockReq.GuardrailConfig)
+		assert.Equal(t, "gr-123", bedrockReq.GuardrailConfig.GuardrailIdentifier)
+		assert.Equal(t, "1", bedrockReq.GuardrailConfig.GuardrailVersion)
+		assert.Equal(t, schemas.Ptr("enabled"), bedrockReq.GuardrailConfig.Trace)
+	})
+
+	t.Run("KeepsRequestGuardrail", func(t *testing.T) {
+		bedrockReq := &BedrockConverseRequest{
+			GuardrailConfig: &BedrockGuardrailConfig{GuardrailIdentifier: "gr-request", GuardrailVersion: "DRAFT"},
+		}
+		applyKeyGuardrail(bedrockReq, key)
+		assert.Equal(t, "gr-request", bedrockReq.GuardrailConfig.GuardrailIdentifier)
+	})
+
+	t.Run("IgnoresKeysWithoutGuardrail", func(t *testing.T) {
+		bedrockReq := &BedrockConverseRequest{}
+		applyKeyGuardrail(bedrockReq, schemas.Key{BedrockKeyConfig: &schemas.BedrockKeyConfig{GuardrailID: schemas.Ptr("gr-123")}})
+		assert.Nil(t, bedrockReq.GuardrailConfig)
+	})
+}
+
+func TestToBifrostGuardrail(t *testing.T) {
+	t.Run("NoGuardrail", func(t *testing.T) {
+		assert.Nil(t, toBifrostGuardrail("end_turn", nil))
+	})
+
+	t.Run("Intervention", func(t *testing.T) {
+		var response BedrockConverseResponse
+		require.NoError(t, sonic.Unmarshal([]byte(`{
+			"output": {"message": {"role": "assistant", "content": [{"text": "Sorry, I can't help with that."}]}},
+			"stopReason": "guardrail_intervened",
+			"usage": {"inputTokens": 10, "outputTokens": 0, "totalTokens": 10},
+			"metrics": {"latencyMs": 100},
+			"trace": {
+				"guardrail": {
+					"actionReason": "Guardrail blocked.",
+					"inputAssessment": {
+						"gr-123": {
+							"topicPolicy": {"topics": [{"name": "Investments", "type": "DENY", "action": "BLOCKED"}]},
+							"contentPolicy": {"filters": [{"type": "HATE", "confidence": "NONE", "action": "NONE"}]},
+							"sensitiveInformationPolicy": {"piiEntities": [{"type": "EMAIL", "match": "jane@example.com", "action": "ANONYMIZED"}]}
+						}
+					}
+				}
+			}
+		}`), &response))
+
+		guardrail := toBifrostGuardrail(response.StopReason, response.Trace)
+		require.NotNil(t, guardrail)
+		assert.True(t, guardrail.Intervened)
+		assert.Equal(t, "Guardrail blocked.", guardrail.Reason)
+		assert.ElementsMatch(t, []schemas.BifrostGuardrailFinding{
+			{Source: "input", Policy: "topic", Type: "DENY", Name: "Investments", Action: "BLOCKED"},
+			{Source: "input", Policy: "content", Type: "HATE", Confidence: "NONE", Action: "NONE"},
+			{Source: "input", Policy: "sensitive_information", Type: "EMAIL", Match: "jane@example.com", Action: "ANONYMIZED"},
+		}, guardrail.Findings)
+	})
+
+	t.Run("NoIntervention", func(t *testing.T) {
+		guardrail := toBifrostGuardrail("end_turn", &BedrockConverseTrace{
+			Guardrail: &BedrockGuardrailTrace{
+				OutputAssessments: map[string][]BedrockGuardrailAssessment{
+					"gr-123": {{ContentPolicy: &BedrockGuardrailContentPolicy{Filters: []BedrockGuardrailContentFilter{
+						{Type: schemas.Ptr("VIOLENCE"), Confidence: schemas.Ptr("LOW"), Action: schemas.Ptr("NONE")},
+					}}}},
+				},
+			},
+		})
+		require.NotNil(t, guardrail)
+		assert.False(t, guardrail.Intervened)
+		require.Len(t, guardrail.Findings, 1)
+		assert.Equal(t, "output", guardrail.Findings[0].Source)
+	})
+}
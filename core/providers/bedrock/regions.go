@@ -0,0 +1,91 @@
+package bedrock
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// regionalErrorStatusCodes are the status codes of errors that can be specific to a region:
+// throttling (429) and regional outages (5xx).
+var regionalErrorStatusCodes = map[int]bool{
+	http.StatusTooManyRequests:     true,
+	http.StatusInternalServerError: true,
+	http.StatusBadGateway:          true,
+	http.StatusServiceUnavailable:  true,
+	http.StatusGatewayTimeout:      true,
+}
+
+// keyRegion returns the region the key sends requests to, DefaultBedrockRegion if none is configured.
+func keyRegion(key schemas.Key) string {
+	if key.BedrockKeyConfig != nil && key.BedrockKeyConfig.Region != nil && *key.BedrockKeyConfig.Region != "" {
+		return *key.BedrockKeyConfig.Region
+	}
+	return DefaultBedrockRegion
+}
+
+// keyRegions returns the regions of the key in the order they are tried, the region first and then the fallback regions.
+func keyRegions(key schemas.Key) []string {
+	if key.BedrockKeyConfig == nil {
+		return nil
+	}
+	regions := make([]string, 0, 1+len(key.BedrockKeyConfig.Regions))
+	seen := make(map[string]bool, 1+len(key.BedrockKeyConfig.Regions))
+	for _, region := range append([]string{keyRegion(key)}, key.BedrockKeyConfig.Regions...) {
+		if region == "" || seen[region] {
+			continue
+		}
+		seen[region] = true
+		regions = append(regions, region)
+	}
+	return regions
+}
+
+// isRegionalError returns true if the request may succeed in another region.
+// Besides throttling and server errors, requests that could not reach the regional endpoint are retried.
+func isRegionalError(err *schemas.BifrostError) bool {
+	if err == nil {
+		return false
+	}
+	if err.StatusCode != nil {
+		return regionalErrorStatusCodes[*err.StatusCode]
+	}
+	return err.Error != nil && err.Error.Message == schemas.ErrProviderDoRequest
+}
+
+// withRegionFallback runs the request with the key in each of its regions, until the request succeeds
+// or fails with an error that is not regional. In each region, the inference profile ARN configured for
+// the region in ARNs replaces ARN. Keys without fallback regions run the request once.
+func withRegionFallback[T any](ctx context.Context, logger schemas.Logger, key schemas.Key, request func(key schemas.Key) (T, *schemas.BifrostError)) (T, *schemas.BifrostError) {
+	regions := keyRegions(key)
+	if len(regions) <= 1 {
+		return request(regionKey(key, keyRegion(key)))
+	}
+
+	var result T
+	var bifrostErr *schemas.BifrostError
+	for i, region := range regions {
+		result, bifrostErr = request(regionKey(key, region))
+		if !isRegionalError(bifrostErr) || i == len(regions)-1 || ctx.Err() != nil {
+			return result, bifrostErr
+		}
+		logger.Debug(fmt.Sprintf("bedrock request failed in region %s, falling back to region %s", region, regions[i+1]))
+	}
+	return result, bifrostErr
+}
+
+// regionKey returns a copy of the key targeting the region, so the configured key is left untouched.
+func regionKey(key schemas.Key, region string) schemas.Key {
+	if key.BedrockKeyConfig == nil {
+		return key
+	}
+	regionConfig := *key.BedrockKeyConfig
+	regionConfig.Region = schemas.Ptr(region)
+	if arn, ok := regionConfig.ARNs[region]; ok && arn != "" {
+		regionConfig.ARN = schemas.Ptr(arn)
+	}
+	key.BedrockKeyConfig = &regionConfig
+	return key
+}
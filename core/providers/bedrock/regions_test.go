@@ -0,0 +1,72 @@
+package bedrock
+
+import (
+	"context"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/stretchr/testify/assert"
+)
+
+type noopLogger struct{}
+
+func (noopLogger) Debug(string, ...any)                   {}
+func (noopLogger) Info(string, ...any)                    {}
+func (noopLogger) Warn(string, ...any)                    {}
+func (noopLogger) Error(string, ...any)                   {}
+func (noopLogger) Fatal(string, ...any)                   {}
+func (noopLogger) SetLevel(schemas.LogLevel)              {}
+func (noopLogger) SetOutputType(schemas.LoggerOutputType) {}
+
+func TestWithRegionFallback(t *testing.T) {
+	key := schemas.Key{
+		BedrockKeyConfig: &schemas.BedrockKeyConfig{
+			Region:  schemas.Ptr("us-east-1"),
+			ARN:     schemas.Ptr("arn:aws:bedrock:us-east-1:123456789012:inference-profile"),
+			Regions: []string{"us-west-2", "us-east-1", "eu-central-1"},
+			ARNs: map[string]string{
+				"eu-central-1": "arn:aws:bedrock:eu-central-1:123456789012:inference-profile",
+			},
+		},
+	}
+	assert.Equal(t, []string{"us-east-1", "us-west-2", "eu-central-1"}, keyRegions(key))
+	assert.Equal(t, []string{DefaultBedrockRegion}, keyRegions(schemas.Key{BedrockKeyConfig: &schemas.BedrockKeyConfig{}}))
+
+	statusError := func(statusCode int) *schemas.BifrostError {
+		return &schemas.BifrostError{StatusCode: &statusCode, Error: &schemas.ErrorField{Message: "error"}}
+	}
+
+	t.Run("FallsBackOnRegionalErrors", func(t *testing.T) {
+		var tried, arns []string
+		region, err := withRegionFallback(context.Background(), noopLogger{}, key, func(key schemas.Key) (string, *schemas.BifrostError) {
+			tried = append(tried, *key.BedrockKeyConfig.Region)
+			arns = append(arns, *key.BedrockKeyConfig.ARN)
+			switch *key.BedrockKeyConfig.Region {
+			case "us-east-1":
+				return "", statusError(429)
+			case "us-west-2":
+				return "", &schemas.BifrostError{Error: &schemas.ErrorField{Message: schemas.ErrProviderDoRequest}}
+			}
+			return keyRegion(key), nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "eu-central-1", region)
+		assert.Equal(t, []string{"us-east-1", "us-west-2", "eu-central-1"}, tried)
+		assert.Equal(t, []string{
+			"arn:aws:bedrock:us-east-1:123456789012:inference-profile",
+			"arn:aws:bedrock:us-east-1:123456789012:inference-profile",
+			"arn:aws:bedrock:eu-central-1:123456789012:inference-profile",
+		}, arns)
+		assert.Equal(t, "us-east-1", *key.BedrockKeyConfig.Region, "configured key must not be modified")
+	})
+
+	t.Run("StopsOnOtherErrors", func(t *testing.T) {
+		var tried []string
+		_, err := withRegionFallback(context.Background(), noopLogger{}, key, func(key schemas.Key) (string, *schemas.BifrostError) {
+			tried = append(tried, *key.BedrockKeyConfig.Region)
+			return "", statusError(400)
+		})
+		assert.NotNil(t, err)
+		assert.Equal(t, []string{"us-east-1"}, tried)
+	})
+}
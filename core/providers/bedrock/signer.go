@@ -12,7 +12,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/smithy-go/encoding/httpbinding"
 	providerUtils "github.com/maximhq/bifrost/core/providers/utils"
 	schemas "github.com/maximhq/bifrost/core/schemas"
@@ -277,28 +276,18 @@ func signAWSRequestFastHTTP(
 	ctx context.Context,
 	req *fasthttp.Request,
 	body []byte,
-	accessKey, secretKey string,
-	sessionToken *string,
+	keyConfig *schemas.BedrockKeyConfig,
 	region, service string,
 	providerName schemas.ModelProvider,
 ) *schemas.BifrostError {
-	// Get AWS credentials if not provided
-	if accessKey == "" && secretKey == "" {
-		cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
-		if err != nil {
-			return providerUtils.NewBifrostOperationError("failed to load aws config", err, providerName)
-		}
-		creds, err := cfg.Credentials.Retrieve(ctx)
-		if err != nil {
-			return providerUtils.NewBifrostOperationError("failed to retrieve aws credentials", err, providerName)
-		}
-		accessKey = creds.AccessKeyID
-		secretKey = creds.SecretAccessKey
-		if creds.SessionToken != "" {
-			st := creds.SessionToken
-			sessionToken = &st
-		}
+	// Get AWS credentials of the key
+	creds, err := getAWSCredentials(ctx, keyConfig, region)
+	if err != nil {
+		return providerUtils.NewBifrostOperationError("failed to retrieve aws credentials", err, providerName)
 	}
+	accessKey := creds.AccessKeyID
+	secretKey := creds.SecretAccessKey
+	sessionToken := creds.SessionToken
 
 	// Get current time
 	now := time.Now().UTC()
@@ -325,8 +314,8 @@ func signAWSRequestFastHTTP(
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set(amzDateKey, amzDate)
-	if sessionToken != nil && *sessionToken != "" {
-		req.Header.Set(amzSecurityToken, *sessionToken)
+	if sessionToken != "" {
+		req.Header.Set(amzSecurityToken, sessionToken)
 	}
 
 	// Build canonical headers
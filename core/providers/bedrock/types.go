@@ -284,18 +284,18 @@ type BedrockConverseTrace struct {
 
 // BedrockGuardrailTrace represents detailed guardrail trace information
 type BedrockGuardrailTrace struct {
-	Action            *string                      `json:"action,omitempty"`            // Action taken by guardrail
-	InputAssessments  []BedrockGuardrailAssessment `json:"inputAssessments,omitempty"`  // Input assessments
-	OutputAssessments []BedrockGuardrailAssessment `json:"outputAssessments,omitempty"` // Output assessments
-	Trace             *BedrockGuardrailTraceDetail `json:"trace,omitempty"`             // Detailed trace information
+	ActionReason      *string                                 `json:"actionReason,omitempty"`      // Reason for the action taken by the guardrail
+	ModelOutput       []string                                `json:"modelOutput,omitempty"`       // Output of the model before the guardrail intervened
+	InputAssessment   map[string]BedrockGuardrailAssessment   `json:"inputAssessment,omitempty"`   // Input assessments keyed by guardrail ID
+	OutputAssessments map[string][]BedrockGuardrailAssessment `json:"outputAssessments,omitempty"` // Output assessments keyed by guardrail ID
 }
 
 // BedrockGuardrailAssessment represents a guardrail assessment
 type BedrockGuardrailAssessment struct {
-	TopicPolicy         *BedrockGuardrailTopicPolicy         `json:"topicPolicy,omitempty"`         // Topic policy assessment
-	ContentPolicy       *BedrockGuardrailContentPolicy       `json:"contentPolicy,omitempty"`       // Content policy assessment
-	WordPolicy          *BedrockGuardrailWordPolicy          `json:"wordPolicy,omitempty"`          // Word policy assessment
-	SensitiveInfoPolicy *BedrockGuardrailSensitiveInfoPolicy `json:"sensitiveInfoPolicy,omitempty"` // Sensitive information policy assessment
+	TopicPolicy         *BedrockGuardrailTopicPolicy         `json:"topicPolicy,omitempty"`                // Topic policy assessment
+	ContentPolicy       *BedrockGuardrailContentPolicy       `json:"contentPolicy,omitempty"`              // Content policy assessment
+	WordPolicy          *BedrockGuardrailWordPolicy          `json:"wordPolicy,omitempty"`                 // Word policy assessment
+	SensitiveInfoPolicy *BedrockGuardrailSensitiveInfoPolicy `json:"sensitiveInformationPolicy,omitempty"` // Sensitive information policy assessment
 }
 
 // BedrockGuardrailTopicPolicy represents topic policy assessment
@@ -361,11 +361,6 @@ type BedrockGuardrailRegex struct {
 	Action *string `json:"action,omitempty"` // Action taken
 }
 
-// BedrockGuardrailTraceDetail represents detailed guardrail trace
-type BedrockGuardrailTraceDetail struct {
-	Trace *string `json:"trace,omitempty"` // Detailed trace information
-}
-
 // ==================== ERROR TYPES ====================
 
 // BedrockError represents a Bedrock API error response
@@ -449,8 +444,9 @@ type BedrockMetadataEvent struct {
 
 // BedrockTitanEmbeddingRequest represents a Bedrock Titan embedding request
 type BedrockTitanEmbeddingRequest struct {
-	InputText string `json:"inputText"` // Required: Text to embed
-	// Note: Titan models have fixed dimensions and don't support the dimensions parameter
+	InputText  string `json:"inputText"`            // Required: Text to embed
+	Dimensions *int   `json:"dimensions,omitempty"` // Optional: 256, 512 or 1024, Titan Text Embeddings V2 only
+	Normalize  *bool  `json:"normalize,omitempty"`  // Optional: Titan Text Embeddings V2 only
 	// ExtraParams can be used for any additional model-specific parameters
 }
 
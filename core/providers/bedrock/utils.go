@@ -126,7 +126,7 @@ func convertMessages(bifrostMessages []schemas.ChatMessage) ([]BedrockMessage, [
 	var messages []BedrockMessage
 	var systemMessages []BedrockSystemMessage
 
-	for _, msg := range bifrostMessages {
+	for i, msg := range bifrostMessages {
 		switch msg.Role {
 		case schemas.ChatMessageRoleSystem:
 			// Convert system message
@@ -150,6 +150,11 @@ func convertMessages(bifrostMessages []schemas.ChatMessage) ([]BedrockMessage, [
 			if err != nil {
 				return nil, nil, fmt.Errorf("failed to convert tool message: %w", err)
 			}
+			// Results of parallel tool calls must be sent together in a single user message
+			if i > 0 && bifrostMessages[i-1].Role == schemas.ChatMessageRoleTool && len(messages) > 0 {
+				messages[len(messages)-1].Content = append(messages[len(messages)-1].Content, bedrockMsg.Content...)
+				continue
+			}
 			messages = append(messages, bedrockMsg)
 
 		default:
@@ -398,6 +403,11 @@ func convertToolConfig(params *schemas.ChatParameters) *BedrockToolConfig {
 	if len(params.Tools) == 0 {
 		return nil
 	}
+	// Bedrock has no "none" tool choice, the tools are left out instead so that they can't be called.
+	// They are added back by ensureChatToolConfigForConversation when the conversation has tool content.
+	if isToolChoiceNone(params.ToolChoice) {
+		return nil
+	}
 
 	var bedrockTools []BedrockTool
 	for _, tool := range params.Tools {
@@ -414,6 +424,16 @@ func convertToolConfig(params *schemas.ChatParameters) *BedrockToolConfig {
 				if len(tool.Function.Parameters.Required) > 0 {
 					schemaObject.(map[string]interface{})["required"] = tool.Function.Parameters.Required
 				}
+				// Keep the rest of the JSON schema, so that tools behave the same as on other providers
+				if len(tool.Function.Parameters.Enum) > 0 {
+					schemaObject.(map[string]interface{})["enum"] = tool.Function.Parameters.Enum
+				}
+				if tool.Function.Parameters.AdditionalProperties != nil {
+					schemaObject.(map[string]interface{})["additionalProperties"] = *tool.Function.Parameters.AdditionalProperties
+				}
+				if tool.Function.Parameters.Defs != nil {
+					schemaObject.(map[string]interface{})["$defs"] = tool.Function.Parameters.Defs
+				}
 			} else {
 				// Fallback to empty object schema if no parameters
 				schemaObject = map[string]interface{}{
@@ -461,6 +481,8 @@ func convertToolChoice(toolChoice schemas.ChatToolChoice) *BedrockToolChoice {
 	// String variant
 	if toolChoice.ChatToolChoiceStr != nil {
 		switch schemas.ChatToolChoiceType(*toolChoice.ChatToolChoiceStr) {
+		case schemas.ChatToolChoiceTypeAuto:
+			return &BedrockToolChoice{Auto: &BedrockToolChoiceAuto{}}
 		case schemas.ChatToolChoiceTypeAny, schemas.ChatToolChoiceTypeRequired:
 			return &BedrockToolChoice{Any: &BedrockToolChoiceAny{}}
 		case schemas.ChatToolChoiceTypeNone:
@@ -482,6 +504,8 @@ func convertToolChoice(toolChoice schemas.ChatToolChoice) *BedrockToolChoice {
 				}
 			}
 			return nil
+		case schemas.ChatToolChoiceTypeAuto:
+			return &BedrockToolChoice{Auto: &BedrockToolChoiceAuto{}}
 		case schemas.ChatToolChoiceTypeAny, schemas.ChatToolChoiceTypeRequired:
 			return &BedrockToolChoice{Any: &BedrockToolChoiceAny{}}
 		case schemas.ChatToolChoiceTypeNone:
@@ -491,6 +515,17 @@ func convertToolChoice(toolChoice schemas.ChatToolChoice) *BedrockToolChoice {
 	return nil
 }
 
+// isToolChoiceNone reports whether the tool choice forbids tool calls
+func isToolChoiceNone(toolChoice *schemas.ChatToolChoice) bool {
+	if toolChoice == nil {
+		return false
+	}
+	if toolChoice.ChatToolChoiceStr != nil {
+		return schemas.ChatToolChoiceType(*toolChoice.ChatToolChoiceStr) == schemas.ChatToolChoiceTypeNone
+	}
+	return toolChoice.ChatToolChoiceStruct != nil && toolChoice.ChatToolChoiceStruct.Type == schemas.ChatToolChoiceTypeNone
+}
+
 // extractToolsFromConversationHistory analyzes conversation history for tool content
 func extractToolsFromConversationHistory(messages []schemas.ChatMessage) (bool, []BedrockTool) {
 	hasToolContent := false
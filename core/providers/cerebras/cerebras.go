@@ -4,7 +4,6 @@ package cerebras
 import (
 	"context"
 	"strings"
-	"time"
 
 	"github.com/maximhq/bifrost/core/providers/openai"
 	providerUtils "github.com/maximhq/bifrost/core/providers/utils"
@@ -26,16 +25,7 @@ type CerebrasProvider struct {
 func NewCerebrasProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*CerebrasProvider, error) {
 	config.CheckAndSetDefaults()
 
-	client := &fasthttp.Client{
-		ReadTimeout:         time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
-		WriteTimeout:        time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
-		MaxConnsPerHost:     5000,
-		MaxIdleConnDuration: 60 * time.Second,
-		MaxConnWaitTimeout:  10 * time.Second,
-	}
-
-	// Configure proxy if provided
-	client = providerUtils.ConfigureProxy(client, config.ProxyConfig, logger)
+	client := providerUtils.NewHTTPClient(config, logger)
 
 	// Set default BaseURL if not provided
 	if config.NetworkConfig.BaseURL == "" {
@@ -43,6 +33,8 @@ func NewCerebrasProvider(config *schemas.ProviderConfig, logger schemas.Logger)
 	}
 	config.NetworkConfig.BaseURL = strings.TrimRight(config.NetworkConfig.BaseURL, "/")
 
+	providerUtils.WarmUpConnections(client, config.NetworkConfig, logger)
+
 	return &CerebrasProvider{
 		logger:              logger,
 		client:              client,
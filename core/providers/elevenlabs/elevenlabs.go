@@ -3,6 +3,7 @@ package elevenlabs
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
@@ -34,16 +35,7 @@ type ElevenlabsProvider struct {
 func NewElevenlabsProvider(config *schemas.ProviderConfig, logger schemas.Logger) *ElevenlabsProvider {
 	config.CheckAndSetDefaults()
 
-	client := &fasthttp.Client{
-		ReadTimeout:         time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
-		WriteTimeout:        time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
-		MaxConnsPerHost:     5000,
-		MaxIdleConnDuration: 60 * time.Second,
-		MaxConnWaitTimeout:  10 * time.Second,
-	}
-
-	// Configure proxy if provided
-	client = providerUtils.ConfigureProxy(client, config.ProxyConfig, logger)
+	client := providerUtils.NewHTTPClient(config, logger)
 
 	// Set default BaseURL if not provided
 	if config.NetworkConfig.BaseURL == "" {
@@ -51,6 +43,8 @@ func NewElevenlabsProvider(config *schemas.ProviderConfig, logger schemas.Logger
 	}
 	config.NetworkConfig.BaseURL = strings.TrimRight(config.NetworkConfig.BaseURL, "/")
 
+	providerUtils.WarmUpConnections(client, config.NetworkConfig, logger)
+
 	return &ElevenlabsProvider{
 		logger:               logger,
 		client:               client,
@@ -185,7 +179,7 @@ func (provider *ElevenlabsProvider) Speech(ctx context.Context, key schemas.Key,
 
 	var endpoint string
 	if request.Params != nil && request.Params.VoiceConfig != nil && request.Params.VoiceConfig.Voice != nil {
-		voice := *request.Params.VoiceConfig.Voice
+		voice := schemas.ResolveSpeechVoice(schemas.Elevenlabs, *request.Params.VoiceConfig.Voice)
 		// Determine if timestamps are requested
 		if withTimestampsRequest {
 			endpoint = "/v1/text-to-speech/" + voice + "/with-timestamps"
@@ -235,8 +229,18 @@ func (provider *ElevenlabsProvider) Speech(ctx context.Context, key schemas.Key,
 		return nil, providerUtils.NewBifrostOperationError(schemas.ErrProviderResponseDecode, err, providerName)
 	}
 
+	var responseFormat string
+	var sampleRate *int
+	if request.Params != nil {
+		responseFormat = request.Params.ResponseFormat
+		sampleRate = request.Params.SampleRate
+	}
+	_, format, rate := negotiateElevenlabsSpeechFormat(responseFormat, sampleRate)
+
 	// Create response based on whether timestamps were requested
 	bifrostResponse := &schemas.BifrostSpeechResponse{
+		Format:     format,
+		SampleRate: rate,
 		ExtraFields: schemas.BifrostResponseExtraFields{
 			RequestType:    schemas.SpeechRequest,
 			Provider:       providerName,
@@ -252,6 +256,17 @@ func (provider *ElevenlabsProvider) Speech(ctx context.Context, key schemas.Key,
 		}
 
 		bifrostResponse.AudioBase64 = &timestampResponse.AudioBase64
+		if format == schemas.SpeechFormatWAV {
+			pcmData, err := base64.StdEncoding.DecodeString(timestampResponse.AudioBase64)
+			if err != nil {
+				return nil, providerUtils.NewBifrostOperationError("failed to decode with-timestamps audio", err, providerName)
+			}
+			wavData, err := providerUtils.ConvertPCMToWAV(pcmData, providerUtils.PCMConfig{SampleRate: rate, NumChannels: 1, BitsPerSample: 16})
+			if err != nil {
+				return nil, providerUtils.NewBifrostOperationError("failed to convert pcm audio to wav", err, providerName)
+			}
+			bifrostResponse.AudioBase64 = schemas.Ptr(base64.StdEncoding.EncodeToString(wavData))
+		}
 
 		if timestampResponse.Alignment != nil {
 			bifrostResponse.Alignment = &schemas.SpeechAlignment{
@@ -273,6 +288,14 @@ func (provider *ElevenlabsProvider) Speech(ctx context.Context, key schemas.Key,
 	}
 
 	bifrostResponse.Audio = body
+	if format == schemas.SpeechFormatWAV {
+		// Elevenlabs returns WAV as raw PCM, signed 16-bit little-endian mono
+		wavData, err := providerUtils.ConvertPCMToWAV(body, providerUtils.PCMConfig{SampleRate: rate, NumChannels: 1, BitsPerSample: 16})
+		if err != nil {
+			return nil, providerUtils.NewBifrostOperationError("failed to convert pcm audio to wav", err, providerName)
+		}
+		bifrostResponse.Audio = wavData
+	}
 	return bifrostResponse, nil
 }
 
@@ -312,7 +335,8 @@ func (provider *ElevenlabsProvider) SpeechStream(ctx context.Context, postHookRu
 		return nil, providerUtils.NewBifrostOperationError("voice parameter is required", nil, providerName)
 	}
 
-	req.SetRequestURI(provider.buildBaseSpeechRequestURL(ctx, "/v1/text-to-speech/"+*request.Params.VoiceConfig.Voice+"/stream", schemas.SpeechStreamRequest, request))
+	voice := schemas.ResolveSpeechVoice(schemas.Elevenlabs, *request.Params.VoiceConfig.Voice)
+	req.SetRequestURI(provider.buildBaseSpeechRequestURL(ctx, "/v1/text-to-speech/"+voice+"/stream", schemas.SpeechStreamRequest, request))
 
 	req.Header.SetMethod(http.MethodPost)
 	req.Header.SetContentType("application/json")
@@ -326,7 +350,7 @@ func (provider *ElevenlabsProvider) SpeechStream(ctx context.Context, postHookRu
 	startTime := time.Now()
 	err := provider.client.Do(req, resp)
 	if err != nil {
-		defer providerUtils.ReleaseStreamingResponse(resp)
+		defer providerUtils.ReleaseStreamingResponse(ctx, resp)
 		if errors.Is(err, context.Canceled) {
 			return nil, &schemas.BifrostError{
 				IsBifrostError: false,
@@ -345,15 +369,15 @@ func (provider *ElevenlabsProvider) SpeechStream(ctx context.Context, postHookRu
 
 	// Check for HTTP errors
 	if resp.StatusCode() != fasthttp.StatusOK {
-		defer providerUtils.ReleaseStreamingResponse(resp)
+		defer providerUtils.ReleaseStreamingResponse(ctx, resp)
 		return nil, parseElevenlabsError(providerName, resp)
 	}
 
 	// Create response channel
-	responseChan := make(chan *schemas.BifrostStream, schemas.DefaultStreamBufferSize)
+	responseChan := make(chan *schemas.BifrostStream, providerUtils.GetStreamBufferSize(ctx))
 
 	go func() {
-		defer providerUtils.ReleaseStreamingResponse(resp)
+		defer providerUtils.ReleaseStreamingResponse(ctx, resp)
 		defer close(responseChan)
 
 		// read binary audio chunks from the stream
@@ -670,7 +694,7 @@ func (provider *ElevenlabsProvider) buildBaseSpeechRequestURL(ctx context.Contex
 			q.Set("enable_logging", strconv.FormatBool(*request.Params.EnableLogging))
 		}
 
-		convertedFormat := ConvertBifrostSpeechFormatToElevenlabs(request.Params.ResponseFormat)
+		convertedFormat, _, _ := negotiateElevenlabsSpeechFormat(request.Params.ResponseFormat, request.Params.SampleRate)
 		if convertedFormat != "" {
 			q.Set("output_format", convertedFormat)
 		}
@@ -1,13 +1,17 @@
 package elevenlabs
 
+import (
+	"fmt"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
 var (
-	// Maps provider-specific finish reasons to Bifrost format
-	bifrostToElevenlabsSpeechFormat = map[string]string{
-		"":     "mp3_44100_128",
-		"mp3":  "mp3_44100_128",
-		"opus": "opus_48000_128",
-		"wav":  "pcm_44100",
-		"pcm":  "pcm_44100",
+	// Sample rates Elevenlabs synthesizes each unified format at, the first one is the default
+	elevenlabsSpeechSampleRates = map[string][]int{
+		schemas.SpeechFormatMP3:  {44100, 22050},
+		schemas.SpeechFormatOpus: {48000},
+		schemas.SpeechFormatPCM:  {44100, 8000, 16000, 22050, 24000, 48000},
 	}
 
 	// Maps Bifrost finish reasons to provider-specific format
@@ -18,12 +22,54 @@ var (
 	}
 )
 
+// negotiateElevenlabsSpeechFormat returns the Elevenlabs output format for the unified format and sample rate of a
+// request, along with the format and sample rate of the audio Elevenlabs returns. Elevenlabs synthesizes at the
+// supported sample rate closest to the requested one. WAV is synthesized as PCM, which the caller wraps in a WAV
+// header. Elevenlabs formats, such as "ulaw_8000", are passed as they are with an unknown sample rate.
+func negotiateElevenlabsSpeechFormat(format string, sampleRate *int) (string, string, int) {
+	if format == "" {
+		format = schemas.SpeechFormatMP3
+	}
+	synthesized := format
+	if format == schemas.SpeechFormatWAV {
+		synthesized = schemas.SpeechFormatPCM
+	}
+	rates, ok := elevenlabsSpeechSampleRates[synthesized]
+	if !ok {
+		return format, format, 0
+	}
+	rate := rates[0]
+	if sampleRate != nil && *sampleRate > 0 {
+		for _, candidate := range rates {
+			if abs(candidate-*sampleRate) < abs(rate-*sampleRate) {
+				rate = candidate
+			}
+		}
+	}
+	switch synthesized {
+	case schemas.SpeechFormatMP3:
+		if rate == 22050 {
+			// 22.05kHz MP3 is only available at 32kbps
+			return "mp3_22050_32", format, rate
+		}
+		return fmt.Sprintf("mp3_%d_128", rate), format, rate
+	case schemas.SpeechFormatOpus:
+		return fmt.Sprintf("opus_%d_128", rate), format, rate
+	}
+	return fmt.Sprintf("pcm_%d", rate), format, rate
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
 // ConvertBifrostSpeechFormatToElevenlabs converts Bifrost speech format to Elevenlabs format
 func ConvertBifrostSpeechFormatToElevenlabs(format string) string {
-	if elevenlabsFormat, ok := bifrostToElevenlabsSpeechFormat[format]; ok {
-		return elevenlabsFormat
-	}
-	return format
+	elevenlabsFormat, _, _ := negotiateElevenlabsSpeechFormat(format, nil)
+	return elevenlabsFormat
 }
 
 // ConvertElevenlabsSpeechFormatToBifrost converts Elevenlabs speech format to Bifrost format
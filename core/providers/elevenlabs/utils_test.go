@@ -0,0 +1,41 @@
+package elevenlabs
+
+import (
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateElevenlabsSpeechFormat(t *testing.T) {
+	tests := []struct {
+		name         string
+		format       string
+		sampleRate   *int
+		outputFormat string
+		audioFormat  string
+		rate         int
+	}{
+		{name: "default", format: "", outputFormat: "mp3_44100_128", audioFormat: "mp3", rate: 44100},
+		{name: "low rate mp3", format: "mp3", sampleRate: schemas.Ptr(16000), outputFormat: "mp3_22050_32", audioFormat: "mp3", rate: 22050},
+		{name: "opus", format: "opus", sampleRate: schemas.Ptr(24000), outputFormat: "opus_48000_128", audioFormat: "opus", rate: 48000},
+		{name: "closest pcm rate", format: "pcm", sampleRate: schemas.Ptr(23000), outputFormat: "pcm_22050", audioFormat: "pcm", rate: 22050},
+		{name: "wav is synthesized as pcm", format: "wav", sampleRate: schemas.Ptr(16000), outputFormat: "pcm_16000", audioFormat: "wav", rate: 16000},
+		{name: "elevenlabs format", format: "ulaw_8000", outputFormat: "ulaw_8000", audioFormat: "ulaw_8000", rate: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputFormat, audioFormat, rate := negotiateElevenlabsSpeechFormat(tt.format, tt.sampleRate)
+			assert.Equal(t, tt.outputFormat, outputFormat)
+			assert.Equal(t, tt.audioFormat, audioFormat)
+			assert.Equal(t, tt.rate, rate)
+		})
+	}
+}
+
+func TestResolveSpeechVoice(t *testing.T) {
+	assert.Equal(t, "21m00Tcm4TlvDq8ikWAM", schemas.ResolveSpeechVoice(schemas.Elevenlabs, "Alloy"))
+	assert.Equal(t, "alloy", schemas.ResolveSpeechVoice(schemas.OpenAI, "alloy"))
+	// Provider voice IDs are passed as they are
+	assert.Equal(t, "pNInz6obpgDQGcFmaJgB", schemas.ResolveSpeechVoice(schemas.Elevenlabs, "pNInz6obpgDQGcFmaJgB"))
+}
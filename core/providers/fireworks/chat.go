@@ -0,0 +1,135 @@
+package fireworks
+
+import (
+	"fmt"
+
+	"github.com/bytedance/sonic"
+	"github.com/maximhq/bifrost/core/providers/openai"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ToFireworksChatRequest converts a Bifrost request to a Fireworks chat completion request.
+// OpenAI structured output options are mapped to the response_format of Fireworks, and the
+// "grammar" extra parameter, a GBNF grammar, constrains generation in grammar mode.
+func ToFireworksChatRequest(bifrostReq *schemas.BifrostChatRequest) (*FireworksChatRequest, error) {
+	openaiReq := openai.ToOpenAIChatRequest(bifrostReq)
+	if openaiReq == nil {
+		return nil, nil
+	}
+
+	fireworksReq := &FireworksChatRequest{
+		OpenAIChatRequest: *openaiReq,
+	}
+
+	// Fireworks uses max_tokens instead of max_completion_tokens
+	if fireworksReq.MaxCompletionTokens != nil {
+		fireworksReq.MaxTokens = fireworksReq.MaxCompletionTokens
+		fireworksReq.MaxCompletionTokens = nil
+	}
+
+	if bifrostReq.Params == nil {
+		return fireworksReq, nil
+	}
+
+	responseFormat, err := toFireworksResponseFormat(bifrostReq.Params.ResponseFormat, bifrostReq.Params.ExtraParams["grammar"])
+	if err != nil {
+		return nil, err
+	}
+	fireworksReq.ResponseFormat = nil
+	if responseFormat != nil {
+		var format interface{} = responseFormat
+		fireworksReq.ResponseFormat = &format
+	}
+
+	// Handle extra parameters for Fireworks-specific fields
+	if bifrostReq.Params.ExtraParams != nil {
+		if topK, ok := schemas.SafeExtractIntPointer(bifrostReq.Params.ExtraParams["top_k"]); ok {
+			fireworksReq.TopK = topK
+		}
+
+		if minP, ok := schemas.SafeExtractFloat64Pointer(bifrostReq.Params.ExtraParams["min_p"]); ok {
+			fireworksReq.MinP = minP
+		}
+
+		if repetitionPenalty, ok := schemas.SafeExtractFloat64Pointer(bifrostReq.Params.ExtraParams["repetition_penalty"]); ok {
+			fireworksReq.RepetitionPenalty = repetitionPenalty
+		}
+
+		if contextLengthExceededBehavior, ok := schemas.SafeExtractStringPointer(bifrostReq.Params.ExtraParams["context_length_exceeded_behavior"]); ok {
+			fireworksReq.ContextLengthExceededBehavior = contextLengthExceededBehavior
+		}
+	}
+
+	return fireworksReq, nil
+}
+
+// toFireworksResponseFormat maps the OpenAI response_format and the grammar extra parameter to a Fireworks response format.
+// Returns nil if neither is set, in which case the request is sent without a response format.
+func toFireworksResponseFormat(responseFormat *interface{}, grammarParam interface{}) (*FireworksResponseFormat, error) {
+	grammar, hasGrammar := schemas.SafeExtractString(grammarParam)
+	if hasGrammar && grammar == "" {
+		return nil, fmt.Errorf("grammar must not be empty")
+	}
+
+	if responseFormat == nil || *responseFormat == nil {
+		if hasGrammar {
+			return &FireworksResponseFormat{Type: FireworksResponseFormatGrammar, Grammar: grammar}, nil
+		}
+		return nil, nil
+	}
+
+	format, err := toResponseFormatMap(*responseFormat)
+	if err != nil {
+		return nil, err
+	}
+	formatType, _ := schemas.SafeExtractString(format["type"])
+	if hasGrammar && formatType != FireworksResponseFormatText && formatType != FireworksResponseFormatGrammar {
+		return nil, fmt.Errorf("grammar cannot be combined with response_format type %s", formatType)
+	}
+
+	switch formatType {
+	case FireworksResponseFormatText:
+		if hasGrammar {
+			return &FireworksResponseFormat{Type: FireworksResponseFormatGrammar, Grammar: grammar}, nil
+		}
+		return nil, nil
+	case FireworksResponseFormatJSONObject:
+		// Fireworks accepts an optional schema alongside json_object
+		schema, _ := format["schema"].(map[string]any)
+		return &FireworksResponseFormat{Type: FireworksResponseFormatJSONObject, Schema: schema}, nil
+	case FireworksResponseFormatJSONSchema:
+		// OpenAI nests the schema as {"json_schema": {"name": ..., "schema": {...}, "strict": ...}}
+		jsonSchema, _ := format["json_schema"].(map[string]any)
+		schema, ok := jsonSchema["schema"].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("response_format of type json_schema requires json_schema.schema")
+		}
+		return &FireworksResponseFormat{Type: FireworksResponseFormatJSONObject, Schema: schema}, nil
+	case FireworksResponseFormatGrammar:
+		if !hasGrammar {
+			grammar, _ = schemas.SafeExtractString(format["grammar"])
+		}
+		if grammar == "" {
+			return nil, fmt.Errorf("response_format of type grammar requires a grammar")
+		}
+		return &FireworksResponseFormat{Type: FireworksResponseFormatGrammar, Grammar: grammar}, nil
+	default:
+		return nil, fmt.Errorf("unsupported response_format type %q", formatType)
+	}
+}
+
+// toResponseFormatMap returns the response format as a map, response formats not decoded from JSON are converted through JSON
+func toResponseFormatMap(responseFormat interface{}) (map[string]any, error) {
+	if format, ok := responseFormat.(map[string]any); ok {
+		return format, nil
+	}
+	data, err := sonic.Marshal(responseFormat)
+	if err != nil {
+		return nil, fmt.Errorf("invalid response_format: %w", err)
+	}
+	var format map[string]any
+	if err := sonic.Unmarshal(data, &format); err != nil {
+		return nil, fmt.Errorf("invalid response_format: %w", err)
+	}
+	return format, nil
+}
@@ -0,0 +1,151 @@
+package fireworks_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/maximhq/bifrost/core/internal/testutil"
+	"github.com/maximhq/bifrost/core/providers/fireworks"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFireworks(t *testing.T) {
+	t.Parallel()
+	if strings.TrimSpace(os.Getenv("FIREWORKS_API_KEY")) == "" {
+		t.Skip("Skipping Fireworks tests because FIREWORKS_API_KEY is not set")
+	}
+
+	client, ctx, cancel, err := testutil.SetupTest()
+	if err != nil {
+		t.Fatalf("Error initializing test setup: %v", err)
+	}
+	defer cancel()
+
+	testConfig := testutil.ComprehensiveTestConfig{
+		Provider:  schemas.Fireworks,
+		ChatModel: "accounts/fireworks/models/llama-v3p3-70b-instruct",
+		Fallbacks: []schemas.Fallback{
+			{Provider: schemas.Fireworks, Model: "accounts/fireworks/models/llama-v3p1-8b-instruct"},
+		},
+		TextModel:      "accounts/fireworks/models/llama-v3p1-8b-instruct",
+		EmbeddingModel: "nomic-ai/nomic-embed-text-v1.5",
+		Scenarios: testutil.TestScenarios{
+			TextCompletion:        true,
+			TextCompletionStream:  true,
+			SimpleChat:            true,
+			CompletionStream:      true,
+			MultiTurnConversation: true,
+			ToolCalls:             true,
+			ToolCallsStreaming:    true,
+			MultipleToolCalls:     true,
+			End2EndToolCalling:    true,
+			AutomaticFunctionCall: true,
+			ImageURL:              false,
+			ImageBase64:           false,
+			MultipleImages:        false,
+			CompleteEnd2End:       true,
+			Embedding:             true,
+			ListModels:            true,
+		},
+	}
+
+	t.Run("FireworksTests", func(t *testing.T) {
+		testutil.RunAllComprehensiveTests(t, client, ctx, testConfig)
+	})
+	client.Shutdown()
+}
+
+func TestToFireworksChatRequestResponseFormat(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"city": map[string]any{"type": "string"},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		responseFormat any
+		extraParams    map[string]interface{}
+		expected       *fireworks.FireworksResponseFormat
+		wantErr        bool
+	}{
+		{
+			name:     "NoResponseFormat",
+			expected: nil,
+		},
+		{
+			name:           "JSONSchema",
+			responseFormat: map[string]any{"type": "json_schema", "json_schema": map[string]any{"name": "city", "schema": schema, "strict": true}},
+			expected:       &fireworks.FireworksResponseFormat{Type: "json_object", Schema: schema},
+		},
+		{
+			name:           "JSONObject",
+			responseFormat: map[string]any{"type": "json_object"},
+			expected:       &fireworks.FireworksResponseFormat{Type: "json_object"},
+		},
+		{
+			name:        "GrammarExtraParam",
+			extraParams: map[string]interface{}{"grammar": `root ::= "yes" | "no"`},
+			expected:    &fireworks.FireworksResponseFormat{Type: "grammar", Grammar: `root ::= "yes" | "no"`},
+		},
+		{
+			name:           "GrammarResponseFormat",
+			responseFormat: map[string]any{"type": "grammar", "grammar": `root ::= "yes" | "no"`},
+			expected:       &fireworks.FireworksResponseFormat{Type: "grammar", Grammar: `root ::= "yes" | "no"`},
+		},
+		{
+			name:           "GrammarWithJSONSchema",
+			responseFormat: map[string]any{"type": "json_schema", "json_schema": map[string]any{"name": "city", "schema": schema}},
+			extraParams:    map[string]interface{}{"grammar": `root ::= "yes" | "no"`},
+			wantErr:        true,
+		},
+		{
+			name:           "JSONSchemaWithoutSchema",
+			responseFormat: map[string]any{"type": "json_schema", "json_schema": map[string]any{"name": "city"}},
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := &schemas.ChatParameters{
+				MaxCompletionTokens: schemas.Ptr(100),
+				ExtraParams:         tt.extraParams,
+			}
+			if tt.responseFormat != nil {
+				params.ResponseFormat = &tt.responseFormat
+			}
+			request := &schemas.BifrostChatRequest{
+				Provider: schemas.Fireworks,
+				Model:    "accounts/fireworks/models/llama-v3p3-70b-instruct",
+				Input: []schemas.ChatMessage{
+					{
+						Role:    schemas.ChatMessageRoleUser,
+						Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr("Where is the Eiffel Tower?")},
+					},
+				},
+				Params: params,
+			}
+
+			actual, err := fireworks.ToFireworksChatRequest(request)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, actual)
+			assert.Equal(t, schemas.Ptr(100), actual.MaxTokens)
+			assert.Nil(t, actual.MaxCompletionTokens)
+			if tt.expected == nil {
+				assert.Nil(t, actual.ResponseFormat)
+				return
+			}
+			require.NotNil(t, actual.ResponseFormat)
+			assert.Equal(t, tt.expected, *actual.ResponseFormat)
+		})
+	}
+}
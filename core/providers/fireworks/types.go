@@ -0,0 +1,31 @@
+package fireworks
+
+import (
+	"github.com/maximhq/bifrost/core/providers/openai"
+)
+
+// Fireworks response format types, Fireworks constrains generation with a JSON schema or a GBNF grammar
+const (
+	FireworksResponseFormatText       = "text"
+	FireworksResponseFormatJSONObject = "json_object"
+	FireworksResponseFormatJSONSchema = "json_schema" // OpenAI structured output type, mapped to json_object with a schema
+	FireworksResponseFormatGrammar    = "grammar"
+)
+
+// FireworksChatRequest represents a Fireworks chat completion request.
+// It is the OpenAI chat request with the Fireworks specific sampling parameters.
+type FireworksChatRequest struct {
+	openai.OpenAIChatRequest
+	TopK                          *int     `json:"top_k,omitempty"`                            // Optional: Top-k sampling
+	MinP                          *float64 `json:"min_p,omitempty"`                            // Optional: Min-p sampling
+	RepetitionPenalty             *float64 `json:"repetition_penalty,omitempty"`               // Optional: Repetition penalty
+	ContextLengthExceededBehavior *string  `json:"context_length_exceeded_behavior,omitempty"` // Optional: "truncate" | "error"
+}
+
+// FireworksResponseFormat is the response_format parameter of Fireworks.
+// Schema is set for the json_object type and Grammar for the grammar type.
+type FireworksResponseFormat struct {
+	Type    string         `json:"type"`
+	Schema  map[string]any `json:"schema,omitempty"`
+	Grammar string         `json:"grammar,omitempty"`
+}
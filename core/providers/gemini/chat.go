@@ -133,7 +133,7 @@ func (request *GeminiGenerationRequest) ToBifrostChatRequest() *schemas.BifrostC
 				messages = append(messages, toolResponseMsg)
 
 			case part.InlineData != nil:
-				// Handle inline images/media - only append if it's actually an image
+				// Handle inline images and audio, other media is left out
 				if isImageMimeType(part.InlineData.MIMEType) {
 					contentBlocks = append(contentBlocks, schemas.ChatContentBlock{
 						Type: schemas.ChatContentBlockTypeImage,
@@ -141,10 +141,26 @@ func (request *GeminiGenerationRequest) ToBifrostChatRequest() *schemas.BifrostC
 							URL: fmt.Sprintf("data:%s;base64,%s", part.InlineData.MIMEType, base64.StdEncoding.EncodeToString(part.InlineData.Data)),
 						},
 					})
+				} else if strings.HasPrefix(part.InlineData.MIMEType, "audio/") {
+					audio := &schemas.ChatInputAudio{
+						Data: base64.StdEncoding.EncodeToString(part.InlineData.Data),
+					}
+					if format := schemas.AudioFormatFromMediaType(part.InlineData.MIMEType); format != "" {
+						audio.Format = &format
+					}
+					contentBlocks = append(contentBlocks, schemas.ChatContentBlock{
+						Type:       schemas.ChatContentBlockTypeInputAudio,
+						InputAudio: audio,
+					})
+				} else if strings.HasPrefix(part.InlineData.MIMEType, "video/") {
+					contentBlocks = append(contentBlocks, schemas.ChatContentBlock{
+						Type:           schemas.ChatContentBlockTypeVideo,
+						VideoURLStruct: convertGeminiVideoToBifrost(part, fmt.Sprintf("data:%s;base64,%s", part.InlineData.MIMEType, base64.StdEncoding.EncodeToString(part.InlineData.Data)), part.InlineData.MIMEType),
+					})
 				}
 
 			case part.FileData != nil:
-				// Handle file data - only append if it's actually an image
+				// Handle file data - only append if it's an image or a video
 				if isImageMimeType(part.FileData.MIMEType) {
 					contentBlocks = append(contentBlocks, schemas.ChatContentBlock{
 						Type: schemas.ChatContentBlockTypeImage,
@@ -152,6 +168,11 @@ func (request *GeminiGenerationRequest) ToBifrostChatRequest() *schemas.BifrostC
 							URL: part.FileData.FileURI,
 						},
 					})
+				} else if strings.HasPrefix(part.FileData.MIMEType, "video/") {
+					contentBlocks = append(contentBlocks, schemas.ChatContentBlock{
+						Type:           schemas.ChatContentBlockTypeVideo,
+						VideoURLStruct: convertGeminiVideoToBifrost(part, part.FileData.FileURI, part.FileData.MIMEType),
+					})
 				}
 
 			case part.ExecutableCode != nil:
@@ -195,6 +216,9 @@ func (request *GeminiGenerationRequest) ToBifrostChatRequest() *schemas.BifrostC
 			if content.Role == string(schemas.ChatMessageRoleAssistant) || content.Role == string(RoleModel) {
 				if len(toolCalls) > 0 || thoughtStr != "" {
 					bifrostMsg.ChatAssistantMessage = &schemas.ChatAssistantMessage{}
+					if thoughtStr != "" {
+						bifrostMsg.ChatAssistantMessage.Thought = schemas.Ptr(strings.TrimSuffix(thoughtStr, "\n"))
+					}
 					if len(toolCalls) > 0 {
 						bifrostMsg.ChatAssistantMessage.ToolCalls = toolCalls
 						// Track these tool calls for future function response correlation
@@ -276,6 +300,7 @@ func (request *GeminiGenerationRequest) ToBifrostChatRequest() *schemas.BifrostC
 	if request.ToolConfig.FunctionCallingConfig != nil || request.ToolConfig.RetrievalConfig != nil {
 		ensureExtraParams(bifrostReq)
 		bifrostReq.Params.ExtraParams["tool_config"] = request.ToolConfig
+		bifrostReq.Params.ToolChoice = convertFunctionCallingConfigToToolChoice(request.ToolConfig.FunctionCallingConfig)
 	}
 
 	return bifrostReq
@@ -329,8 +354,31 @@ func ToGeminiChatCompletionRequest(bifrostReq *schemas.BifrostChatRequest, respo
 		}
 	}
 
+	// System messages are sent as the system instruction, Gemini contents only have user and model turns
+	messages := make([]schemas.ChatMessage, 0, len(bifrostReq.Input))
+	for _, message := range bifrostReq.Input {
+		if message.Role != schemas.ChatMessageRoleSystem {
+			messages = append(messages, message)
+			continue
+		}
+		if message.Content == nil {
+			continue
+		}
+		if geminiReq.SystemInstruction == nil {
+			geminiReq.SystemInstruction = &Content{}
+		}
+		if message.Content.ContentStr != nil {
+			geminiReq.SystemInstruction.Parts = append(geminiReq.SystemInstruction.Parts, &Part{Text: *message.Content.ContentStr})
+		}
+		for _, block := range message.Content.ContentBlocks {
+			if block.Text != nil {
+				geminiReq.SystemInstruction.Parts = append(geminiReq.SystemInstruction.Parts, &Part{Text: *block.Text})
+			}
+		}
+	}
+
 	// Convert chat completion messages to Gemini format
-	geminiReq.Contents = convertBifrostMessagesToGemini(bifrostReq.Input)
+	geminiReq.Contents = convertBifrostMessagesToGemini(messages)
 
 	return geminiReq
 }
@@ -355,16 +403,21 @@ func (response *GenerateContentResponse) ToBifrostChatResponse() *schemas.Bifros
 	if len(response.Candidates) > 0 {
 		candidate := response.Candidates[0]
 		if candidate.Content != nil && len(candidate.Content.Parts) > 0 {
-			var textContent string
+			var textContent, thoughtContent string
 
-			// Extract text content from all parts
+			// Extract text and thought content from all parts
 			for _, part := range candidate.Content.Parts {
-				if part.Text != "" {
+				if part.Text == "" {
+					continue
+				}
+				if part.Thought {
+					thoughtContent += part.Text
+				} else {
 					textContent += part.Text
 				}
 			}
 
-			if textContent != "" {
+			if textContent != "" || thoughtContent != "" {
 				// Create choice from the candidate
 				choice := schemas.BifrostResponseChoice{
 					Index: 0,
@@ -377,6 +430,11 @@ func (response *GenerateContentResponse) ToBifrostChatResponse() *schemas.Bifros
 						},
 					},
 				}
+				if thoughtContent != "" {
+					choice.ChatNonStreamResponseChoice.Message.ChatAssistantMessage = &schemas.ChatAssistantMessage{
+						Thought: &thoughtContent,
+					}
+				}
 
 				// Set finish reason if available
 				if candidate.FinishReason != "" {
@@ -448,6 +506,11 @@ func ToGeminiChatResponse(bifrostResp *schemas.BifrostChatResponse) *GenerateCon
 					role = "model" // Default role for streaming responses
 				}
 
+				// Handle thought text
+				if delta.Thought != nil && *delta.Thought != "" {
+					parts = append(parts, &Part{Text: *delta.Thought, Thought: true})
+				}
+
 				// Handle content text
 				if delta.Content != nil && *delta.Content != "" {
 					parts = append(parts, &Part{Text: *delta.Content})
@@ -481,6 +544,9 @@ func ToGeminiChatResponse(bifrostResp *schemas.BifrostChatResponse) *GenerateCon
 				}
 			} else if choice.ChatNonStreamResponseChoice != nil && choice.ChatNonStreamResponseChoice.Message != nil {
 				// Handle non-streaming responses
+				if choice.ChatNonStreamResponseChoice.Message.ChatAssistantMessage != nil && choice.ChatNonStreamResponseChoice.Message.ChatAssistantMessage.Thought != nil {
+					parts = append(parts, &Part{Text: *choice.ChatNonStreamResponseChoice.Message.ChatAssistantMessage.Thought, Thought: true})
+				}
 				if choice.ChatNonStreamResponseChoice.Message.Content != nil {
 					if choice.ChatNonStreamResponseChoice.Message.Content.ContentStr != nil && *choice.ChatNonStreamResponseChoice.Message.Content.ContentStr != "" {
 						parts = append(parts, &Part{Text: *choice.ChatNonStreamResponseChoice.Message.Content.ContentStr})
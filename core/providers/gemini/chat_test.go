@@ -0,0 +1,71 @@
+package gemini
+
+import (
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToGeminiChatCompletionRequestVideo(t *testing.T) {
+	bifrostReq := &schemas.BifrostChatRequest{
+		Provider: schemas.Gemini,
+		Model:    "gemini-2.5-flash",
+		Input: []schemas.ChatMessage{
+			{
+				Role:    schemas.ChatMessageRoleSystem,
+				Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr("Describe videos briefly.")},
+			},
+			{
+				Role: schemas.ChatMessageRoleUser,
+				Content: &schemas.ChatMessageContent{ContentBlocks: []schemas.ChatContentBlock{
+					{Type: schemas.ChatContentBlockTypeText, Text: schemas.Ptr("What happens in these videos?")},
+					{Type: schemas.ChatContentBlockTypeVideo, VideoURLStruct: &schemas.ChatInputVideo{URL: "data:video/mp4;base64,AAAAGGZ0eXBtcDQy"}},
+					{Type: schemas.ChatContentBlockTypeVideo, VideoURLStruct: &schemas.ChatInputVideo{
+						URL:         "gs://bucket/clip.mov",
+						StartOffset: schemas.Ptr("10s"),
+						EndOffset:   schemas.Ptr("20s"),
+						FPS:         schemas.Ptr(2.0),
+					}},
+				}},
+			},
+		},
+	}
+	require.True(t, schemas.HasInputVideo(bifrostReq.Input))
+
+	geminiReq := ToGeminiChatCompletionRequest(bifrostReq, nil)
+	require.NotNil(t, geminiReq.SystemInstruction)
+	assert.Equal(t, "Describe videos briefly.", geminiReq.SystemInstruction.Parts[0].Text)
+
+	require.Len(t, geminiReq.Contents, 1, "system messages must not be sent as contents")
+	parts := geminiReq.Contents[0].Parts
+	require.Len(t, parts, 3)
+	require.NotNil(t, parts[1].InlineData)
+	assert.Equal(t, "video/mp4", parts[1].InlineData.MIMEType)
+	assert.Equal(t, []byte("\x00\x00\x00\x18ftypmp42"), parts[1].InlineData.Data)
+
+	require.NotNil(t, parts[2].FileData)
+	assert.Equal(t, "gs://bucket/clip.mov", parts[2].FileData.FileURI)
+	assert.Equal(t, "video/mov", parts[2].FileData.MIMEType)
+	require.NotNil(t, parts[2].VideoMetadata)
+	assert.Equal(t, "10s", parts[2].VideoMetadata.StartOffset)
+	assert.Equal(t, "20s", parts[2].VideoMetadata.EndOffset)
+
+	roundTrip := geminiReq.ToBifrostChatRequest()
+	var videos []*schemas.ChatInputVideo
+	for _, message := range roundTrip.Input {
+		if message.Content == nil {
+			continue
+		}
+		for _, block := range message.Content.ContentBlocks {
+			if block.VideoURLStruct != nil {
+				videos = append(videos, block.VideoURLStruct)
+			}
+		}
+	}
+	require.Len(t, videos, 2)
+	assert.Equal(t, "data:video/mp4;base64,AAAAGGZ0eXBtcDQy", videos[0].URL)
+	assert.Equal(t, "gs://bucket/clip.mov", videos[1].URL)
+	assert.Equal(t, schemas.Ptr("10s"), videos[1].StartOffset)
+}
@@ -0,0 +1,131 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	providerUtils "github.com/maximhq/bifrost/core/providers/utils"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// maxInlineVideoBytes is the largest video sent inline, Gemini rejects requests above 20 MB (15 MB once base64 encoded)
+	maxInlineVideoBytes = 15 << 20
+	// fileProcessingPollInterval is how often the state of an uploaded video is checked until it can be used
+	fileProcessingPollInterval = 2 * time.Second
+)
+
+// uploadLargeVideos uploads the inline videos of a request that are too large to be sent inline with the Files API,
+// and replaces them with references to the uploaded files.
+func (provider *GeminiProvider) uploadLargeVideos(ctx context.Context, key schemas.Key, geminiReq *GeminiGenerationRequest) error {
+	for _, content := range geminiReq.Contents {
+		for _, part := range content.Parts {
+			if part.InlineData == nil || !strings.HasPrefix(part.InlineData.MIMEType, "video/") || len(part.InlineData.Data) <= maxInlineVideoBytes {
+				continue
+			}
+			file, err := provider.uploadFile(ctx, key, part.InlineData.Data, part.InlineData.MIMEType)
+			if err != nil {
+				return fmt.Errorf("failed to upload video to the Files API: %w", err)
+			}
+			part.FileData = &FileData{FileURI: file.URI, MIMEType: part.InlineData.MIMEType}
+			part.InlineData = nil
+		}
+	}
+	return nil
+}
+
+// uploadFile uploads data with a resumable Files API upload and waits until the file is processed.
+func (provider *GeminiProvider) uploadFile(ctx context.Context, key schemas.Key, data []byte, mimeType string) (*GeminiFile, error) {
+	baseURL, err := url.Parse(provider.networkConfig.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	baseURL.Path = "/upload" + baseURL.Path + "/files"
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	// Start the upload session
+	providerUtils.SetExtraHeaders(ctx, req, provider.networkConfig.ExtraHeaders, nil)
+	req.SetRequestURI(baseURL.String())
+	req.Header.SetMethod(http.MethodPost)
+	req.Header.SetContentType("application/json")
+	req.Header.Set("x-goog-api-key", key.Value)
+	req.Header.Set("X-Goog-Upload-Protocol", "resumable")
+	req.Header.Set("X-Goog-Upload-Command", "start")
+	req.Header.Set("X-Goog-Upload-Header-Content-Length", strconv.Itoa(len(data)))
+	req.Header.Set("X-Goog-Upload-Header-Content-Type", mimeType)
+	req.SetBodyString(`{"file": {}}`)
+	if _, bifrostErr := providerUtils.MakeRequestWithContext(ctx, provider.client, req, resp); bifrostErr != nil {
+		return nil, errors.New(bifrostErr.Error.Message)
+	}
+	if resp.StatusCode() != fasthttp.StatusOK {
+		return nil, errors.New(parseGeminiError(provider.GetProviderKey(), resp).Error.Message)
+	}
+	uploadURL := string(resp.Header.Peek("X-Goog-Upload-URL"))
+	if uploadURL == "" {
+		return nil, errors.New("no upload URL returned")
+	}
+
+	// Upload the data and finalize the upload
+	req.Reset()
+	resp.Reset()
+	req.SetRequestURI(uploadURL)
+	req.Header.SetMethod(http.MethodPost)
+	req.Header.Set("X-Goog-Upload-Offset", "0")
+	req.Header.Set("X-Goog-Upload-Command", "upload, finalize")
+	req.SetBody(data)
+	if _, bifrostErr := providerUtils.MakeRequestWithContext(ctx, provider.client, req, resp); bifrostErr != nil {
+		return nil, errors.New(bifrostErr.Error.Message)
+	}
+	if resp.StatusCode() != fasthttp.StatusOK {
+		return nil, errors.New(parseGeminiError(provider.GetProviderKey(), resp).Error.Message)
+	}
+	var uploaded GeminiFileUploadResponse
+	if err := sonic.Unmarshal(resp.Body(), &uploaded); err != nil {
+		return nil, err
+	}
+	file := uploaded.File
+
+	// Videos are processed before they can be used
+	for file.State == GeminiFileStateProcessing {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(fileProcessingPollInterval):
+		}
+
+		req.Reset()
+		resp.Reset()
+		providerUtils.SetExtraHeaders(ctx, req, provider.networkConfig.ExtraHeaders, nil)
+		req.SetRequestURI(provider.networkConfig.BaseURL + "/" + file.Name)
+		req.Header.SetMethod(http.MethodGet)
+		req.Header.Set("x-goog-api-key", key.Value)
+		if _, bifrostErr := providerUtils.MakeRequestWithContext(ctx, provider.client, req, resp); bifrostErr != nil {
+			return nil, errors.New(bifrostErr.Error.Message)
+		}
+		if resp.StatusCode() != fasthttp.StatusOK {
+			return nil, errors.New(parseGeminiError(provider.GetProviderKey(), resp).Error.Message)
+		}
+		if err := sonic.Unmarshal(resp.Body(), &file); err != nil {
+			return nil, err
+		}
+	}
+	if file.State == GeminiFileStateFailed {
+		if file.Error != nil {
+			return nil, fmt.Errorf("file processing failed: %s", file.Error.Message)
+		}
+		return nil, errors.New("file processing failed")
+	}
+	return &file, nil
+}
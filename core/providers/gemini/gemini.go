@@ -34,16 +34,7 @@ type GeminiProvider struct {
 func NewGeminiProvider(config *schemas.ProviderConfig, logger schemas.Logger) *GeminiProvider {
 	config.CheckAndSetDefaults()
 
-	client := &fasthttp.Client{
-		ReadTimeout:         time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
-		WriteTimeout:        time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
-		MaxConnsPerHost:     5000,
-		MaxIdleConnDuration: 60 * time.Second,
-		MaxConnWaitTimeout:  10 * time.Second,
-	}
-
-	// Configure proxy if provided
-	client = providerUtils.ConfigureProxy(client, config.ProxyConfig, logger)
+	client := providerUtils.NewHTTPClient(config, logger)
 
 	// Set default BaseURL if not provided
 	if config.NetworkConfig.BaseURL == "" {
@@ -51,6 +42,8 @@ func NewGeminiProvider(config *schemas.ProviderConfig, logger schemas.Logger) *G
 	}
 	config.NetworkConfig.BaseURL = strings.TrimRight(config.NetworkConfig.BaseURL, "/")
 
+	providerUtils.WarmUpConnections(client, config.NetworkConfig, logger)
+
 	return &GeminiProvider{
 		logger:               logger,
 		client:               client,
@@ -213,6 +206,11 @@ func (provider *GeminiProvider) ChatCompletion(ctx context.Context, key schemas.
 		return nil, err
 	}
 
+	// Videos are only understood through the native generateContent API
+	if schemas.HasInputVideo(request.Input) {
+		return provider.generateContentChatCompletion(ctx, key, request)
+	}
+
 	providerName := provider.GetProviderKey()
 
 	jsonData, err := providerUtils.CheckContextAndGetRequestBody(
@@ -299,6 +297,45 @@ func (provider *GeminiProvider) ChatCompletion(ctx context.Context, key schemas.
 	return response, nil
 }
 
+// generateContentChatCompletion performs a chat completion request with Gemini's native generateContent API,
+// uploading inline videos too large for the request with the Files API.
+func (provider *GeminiProvider) generateContentChatCompletion(ctx context.Context, key schemas.Key, request *schemas.BifrostChatRequest) (*schemas.BifrostChatResponse, *schemas.BifrostError) {
+	jsonData, bifrostErr := providerUtils.CheckContextAndGetRequestBody(
+		ctx,
+		request,
+		func() (any, error) {
+			geminiReq := ToGeminiChatCompletionRequest(request, nil)
+			geminiReq.Model = ""
+			if err := provider.uploadLargeVideos(ctx, key, geminiReq); err != nil {
+				return nil, err
+			}
+			return geminiReq, nil
+		},
+		provider.GetProviderKey())
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	geminiResponse, rawResponse, latency, bifrostErr := provider.completeRequest(ctx, request.Model, key, jsonData, ":generateContent")
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	response := geminiResponse.ToBifrostChatResponse()
+
+	// Set ExtraFields
+	response.ExtraFields.Provider = provider.GetProviderKey()
+	response.ExtraFields.ModelRequested = request.Model
+	response.ExtraFields.RequestType = schemas.ChatCompletionRequest
+	response.ExtraFields.Latency = latency.Milliseconds()
+
+	if providerUtils.ShouldSendBackRawResponse(ctx, provider.sendBackRawResponse) {
+		response.ExtraFields.RawResponse = rawResponse
+	}
+
+	return response, nil
+}
+
 // ChatCompletionStream performs a streaming chat completion request to the Gemini API.
 // It supports real-time streaming of responses using Server-Sent Events (SSE).
 // Uses Gemini's OpenAI-compatible streaming format.
@@ -309,6 +346,10 @@ func (provider *GeminiProvider) ChatCompletionStream(ctx context.Context, postHo
 		return nil, err
 	}
 
+	if schemas.HasInputVideo(request.Input) {
+		return nil, providerUtils.NewBifrostOperationError("video input is only supported in non-streaming chat completions", nil, provider.GetProviderKey())
+	}
+
 	var authHeader map[string]string
 	if key.Value != "" {
 		authHeader = map[string]string{"Authorization": "Bearer " + key.Value}
@@ -465,7 +506,7 @@ func (provider *GeminiProvider) SpeechStream(ctx context.Context, postHookRunner
 	// Make the request
 	err := provider.client.Do(req, resp)
 	if err != nil {
-		defer providerUtils.ReleaseStreamingResponse(resp)
+		defer providerUtils.ReleaseStreamingResponse(ctx, resp)
 		if errors.Is(err, context.Canceled) {
 			return nil, &schemas.BifrostError{
 				IsBifrostError: false,
@@ -484,16 +525,16 @@ func (provider *GeminiProvider) SpeechStream(ctx context.Context, postHookRunner
 
 	// Check for HTTP errors
 	if resp.StatusCode() != fasthttp.StatusOK {
-		defer providerUtils.ReleaseStreamingResponse(resp)
+		defer providerUtils.ReleaseStreamingResponse(ctx, resp)
 		return nil, parseStreamGeminiError(providerName, resp)
 	}
 
 	// Create response channel
-	responseChan := make(chan *schemas.BifrostStream, schemas.DefaultStreamBufferSize)
+	responseChan := make(chan *schemas.BifrostStream, providerUtils.GetStreamBufferSize(ctx))
 
 	// Start streaming in a goroutine
 	go func() {
-		defer providerUtils.ReleaseStreamingResponse(resp)
+		defer providerUtils.ReleaseStreamingResponse(ctx, resp)
 		defer close(responseChan)
 
 		scanner := bufio.NewScanner(resp.BodyStream())
@@ -713,7 +754,7 @@ func (provider *GeminiProvider) TranscriptionStream(ctx context.Context, postHoo
 	// Make the request
 	err := provider.client.Do(req, resp)
 	if err != nil {
-		defer providerUtils.ReleaseStreamingResponse(resp)
+		defer providerUtils.ReleaseStreamingResponse(ctx, resp)
 		if errors.Is(err, context.Canceled) {
 			return nil, &schemas.BifrostError{
 				IsBifrostError: false,
@@ -732,17 +773,17 @@ func (provider *GeminiProvider) TranscriptionStream(ctx context.Context, postHoo
 
 	// Check for HTTP errors
 	if resp.StatusCode() != fasthttp.StatusOK {
-		defer providerUtils.ReleaseStreamingResponse(resp)
+		defer providerUtils.ReleaseStreamingResponse(ctx, resp)
 		return nil, parseStreamGeminiError(providerName, resp)
 	}
 
 	// Create response channel
-	responseChan := make(chan *schemas.BifrostStream, schemas.DefaultStreamBufferSize)
+	responseChan := make(chan *schemas.BifrostStream, providerUtils.GetStreamBufferSize(ctx))
 
 	// Start streaming in a goroutine
 	go func() {
 		defer close(responseChan)
-		defer providerUtils.ReleaseStreamingResponse(resp)
+		defer providerUtils.ReleaseStreamingResponse(ctx, resp)
 
 		scanner := bufio.NewScanner(resp.BodyStream())
 		// Increase buffer size to handle large chunks (especially for audio data)
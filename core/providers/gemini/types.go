@@ -1034,10 +1034,10 @@ type VideoMetadata struct {
 	// Optional. The frame rate of the video sent to the model. If not specified, the
 	// default value will be 1.0. The FPS range is (0.0, 24.0].
 	FPS *float64 `json:"fps,omitempty"`
-	// Optional. The end offset of the video.
-	EndOffset time.Duration `json:"endOffset,omitempty"`
-	// Optional. The start offset of the video.
-	StartOffset time.Duration `json:"startOffset,omitempty"`
+	// Optional. The end offset of the video, as a duration string (e.g. "90s").
+	EndOffset string `json:"endOffset,omitempty"`
+	// Optional. The start offset of the video, as a duration string (e.g. "10s").
+	StartOffset string `json:"startOffset,omitempty"`
 }
 
 // CodeExecutionResult represents the result of executing the [ExecutableCode]. Only generated when using the [CodeExecution]
@@ -1387,3 +1387,29 @@ type GeminiListModelsResponse struct {
 	Models        []GeminiModel `json:"models"`
 	NextPageToken string        `json:"nextPageToken"`
 }
+
+// GeminiFileState is the processing state of a file uploaded with the Files API.
+type GeminiFileState string
+
+const (
+	GeminiFileStateProcessing GeminiFileState = "PROCESSING"
+	GeminiFileStateActive     GeminiFileState = "ACTIVE"
+	GeminiFileStateFailed     GeminiFileState = "FAILED"
+)
+
+// GeminiFile represents a file uploaded with the Files API.
+type GeminiFile struct {
+	Name      string          `json:"name"` // Resource name, e.g. "files/abc-123"
+	URI       string          `json:"uri"`  // URI to reference the file in file data parts
+	MIMEType  string          `json:"mimeType"`
+	SizeBytes string          `json:"sizeBytes,omitempty"`
+	State     GeminiFileState `json:"state"`
+	Error     *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// GeminiFileUploadResponse is the response of a Files API upload.
+type GeminiFileUploadResponse struct {
+	File GeminiFile `json:"file"`
+}
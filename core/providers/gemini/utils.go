@@ -2,6 +2,8 @@ package gemini
 
 import (
 	"bytes"
+	"encoding/base64"
+	"path"
 	"strings"
 
 	"github.com/bytedance/sonic"
@@ -44,6 +46,9 @@ func (r *GeminiGenerationRequest) convertGenerationConfigToChatParameters() *sch
 	if config.Seed != nil {
 		params.Seed = schemas.Ptr(int(*config.Seed))
 	}
+	if config.ThinkingConfig != nil && config.ThinkingConfig.ThinkingBudget != nil && *config.ThinkingConfig.ThinkingBudget > 0 {
+		params.ReasoningMaxTokens = schemas.Ptr(int(*config.ThinkingConfig.ThinkingBudget))
+	}
 	if config.ResponseMIMEType != "" {
 		params.ExtraParams["response_mime_type"] = config.ResponseMIMEType
 
@@ -258,6 +263,12 @@ func convertParamsToGenerationConfig(params *schemas.ChatParameters, responseMod
 		penalty := float64(*params.FrequencyPenalty)
 		config.FrequencyPenalty = &penalty
 	}
+	if budget := schemas.ReasoningBudget(params); budget > 0 {
+		config.ThinkingConfig = &GenerationConfigThinkingConfig{
+			IncludeThoughts: true,
+			ThinkingBudget:  schemas.Ptr(int32(budget)),
+		}
+	}
 
 	// Handle response_format to response_schema conversion
 	if params.ResponseFormat != nil {
@@ -393,7 +404,7 @@ func convertToolChoiceToToolConfig(toolChoice *schemas.ChatToolChoice) ToolConfi
 			functionCallingConfig.Mode = FunctionCallingConfigModeNone
 		case schemas.ChatToolChoiceTypeFunction:
 			functionCallingConfig.Mode = FunctionCallingConfigModeAny
-		case schemas.ChatToolChoiceTypeRequired:
+		case schemas.ChatToolChoiceTypeAny, schemas.ChatToolChoiceTypeRequired:
 			functionCallingConfig.Mode = FunctionCallingConfigModeAny
 		default:
 			functionCallingConfig.Mode = FunctionCallingConfigModeAuto
@@ -409,6 +420,31 @@ func convertToolChoiceToToolConfig(toolChoice *schemas.ChatToolChoice) ToolConfi
 	return config
 }
 
+// convertFunctionCallingConfigToToolChoice converts a Gemini function calling config to the Bifrost tool choice,
+// so that it applies when the request falls back to another provider
+func convertFunctionCallingConfigToToolChoice(config *FunctionCallingConfig) *schemas.ChatToolChoice {
+	if config == nil {
+		return nil
+	}
+	switch config.Mode {
+	case FunctionCallingConfigModeNone:
+		return &schemas.ChatToolChoice{ChatToolChoiceStr: schemas.Ptr(string(schemas.ChatToolChoiceTypeNone))}
+	case FunctionCallingConfigModeAuto:
+		return &schemas.ChatToolChoice{ChatToolChoiceStr: schemas.Ptr(string(schemas.ChatToolChoiceTypeAuto))}
+	case FunctionCallingConfigModeAny:
+		if len(config.AllowedFunctionNames) == 1 {
+			return &schemas.ChatToolChoice{
+				ChatToolChoiceStruct: &schemas.ChatToolChoiceStruct{
+					Type:     schemas.ChatToolChoiceTypeFunction,
+					Function: schemas.ChatToolChoiceFunction{Name: config.AllowedFunctionNames[0]},
+				},
+			}
+		}
+		return &schemas.ChatToolChoice{ChatToolChoiceStr: schemas.Ptr(string(schemas.ChatToolChoiceTypeRequired))}
+	}
+	return nil
+}
+
 // addSpeechConfigToGenerationConfig adds speech configuration to the generation config
 func addSpeechConfigToGenerationConfig(config *GenerationConfig, voiceConfig *schemas.SpeechVoiceInput) {
 	speechConfig := SpeechConfig{}
@@ -444,15 +480,107 @@ func addSpeechConfigToGenerationConfig(config *GenerationConfig, voiceConfig *sc
 	config.SpeechConfig = &speechConfig
 }
 
+// audioMIMEType returns the MIME type Gemini expects for an input_audio format, WAV when the format is unknown
+func audioMIMEType(format *string) string {
+	if format == nil || *format == "" {
+		return "audio/wav"
+	}
+	switch strings.ToLower(*format) {
+	case "mp3", "mpeg", "mpga":
+		return "audio/mp3"
+	case "m4a":
+		return "audio/mp4"
+	default:
+		return "audio/" + strings.ToLower(*format)
+	}
+}
+
+// videoExtensionToMIMEType maps the extensions of video URIs to the MIME types Gemini accepts
+var videoExtensionToMIMEType = map[string]string{
+	".mp4":  "video/mp4",
+	".mpeg": "video/mpeg",
+	".mpg":  "video/mpg",
+	".mov":  "video/mov",
+	".avi":  "video/avi",
+	".flv":  "video/x-flv",
+	".webm": "video/webm",
+	".wmv":  "video/wmv",
+	".3gp":  "video/3gpp",
+}
+
+// convertVideoToGeminiPart converts a video content block to an inline data part (data URLs) or a file data part
+// (Cloud Storage and Files API URIs), with the clip and frame rate of the block as video metadata.
+// Returns nil when the data URL is not valid base64.
+func convertVideoToGeminiPart(video *schemas.ChatInputVideo) *Part {
+	part := &Part{}
+	if video.StartOffset != nil || video.EndOffset != nil || video.FPS != nil {
+		part.VideoMetadata = &VideoMetadata{FPS: video.FPS}
+		if video.StartOffset != nil {
+			part.VideoMetadata.StartOffset = *video.StartOffset
+		}
+		if video.EndOffset != nil {
+			part.VideoMetadata.EndOffset = *video.EndOffset
+		}
+	}
+
+	mimeType := ""
+	if video.MimeType != nil {
+		mimeType = *video.MimeType
+	}
+	if header, data, ok := strings.Cut(video.URL, ","); ok && strings.HasPrefix(header, "data:") && strings.HasSuffix(header, ";base64") {
+		decoded, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil
+		}
+		if mimeType == "" {
+			mimeType = strings.TrimSuffix(strings.TrimPrefix(header, "data:"), ";base64")
+		}
+		part.InlineData = &Blob{MIMEType: mimeType, Data: decoded}
+		return part
+	}
+
+	if mimeType == "" {
+		uriPath, _, _ := strings.Cut(video.URL, "?")
+		mimeType = videoExtensionToMIMEType[strings.ToLower(path.Ext(uriPath))]
+		if mimeType == "" {
+			mimeType = "video/mp4"
+		}
+	}
+	part.FileData = &FileData{FileURI: video.URL, MIMEType: mimeType}
+	return part
+}
+
+// convertGeminiVideoToBifrost converts a video part to a video content block with the given URL
+func convertGeminiVideoToBifrost(part *Part, url string, mimeType string) *schemas.ChatInputVideo {
+	video := &schemas.ChatInputVideo{
+		URL:      url,
+		MimeType: &mimeType,
+	}
+	if part.VideoMetadata != nil {
+		video.FPS = part.VideoMetadata.FPS
+		if part.VideoMetadata.StartOffset != "" {
+			video.StartOffset = &part.VideoMetadata.StartOffset
+		}
+		if part.VideoMetadata.EndOffset != "" {
+			video.EndOffset = &part.VideoMetadata.EndOffset
+		}
+	}
+	return video
+}
+
 // convertBifrostMessagesToGemini converts Bifrost messages to Gemini format
 func convertBifrostMessagesToGemini(messages []schemas.ChatMessage) []Content {
 	var contents []Content
 
-	for _, message := range messages {
+	// Function responses are correlated by function name, which tool messages only reference through the tool call ID
+	messages = schemas.NormalizeInputAudio(schemas.NormalizeToolCalls(messages))
+	toolCallNames := make(map[string]string)
+
+	for i, message := range messages {
 		var parts []*Part
 
-		// Handle content
-		if message.Content != nil {
+		// Handle content, the content of tool messages is sent as the function response
+		if message.Content != nil && message.Role != schemas.ChatMessageRoleTool {
 			if message.Content.ContentStr != nil && *message.Content.ContentStr != "" {
 				parts = append(parts, &Part{
 					Text: *message.Content.ContentStr,
@@ -464,6 +592,21 @@ func convertBifrostMessagesToGemini(messages []schemas.ChatMessage) []Content {
 							Text: *block.Text,
 						})
 					}
+					if block.VideoURLStruct != nil {
+						if part := convertVideoToGeminiPart(block.VideoURLStruct); part != nil {
+							parts = append(parts, part)
+						}
+					}
+					if block.InputAudio != nil {
+						if data, err := base64.StdEncoding.DecodeString(block.InputAudio.Data); err == nil {
+							parts = append(parts, &Part{
+								InlineData: &Blob{
+									MIMEType: audioMIMEType(block.InputAudio.Format),
+									Data:     data,
+								},
+							})
+						}
+					}
 					// Handle other content block types as needed
 				}
 			}
@@ -484,6 +627,7 @@ func convertBifrostMessagesToGemini(messages []schemas.ChatMessage) []Content {
 					if toolCall.ID != nil && strings.TrimSpace(*toolCall.ID) != "" {
 						callID = *toolCall.ID
 					}
+					toolCallNames[callID] = *toolCall.Function.Name
 
 					part := &Part{
 						FunctionCall: &FunctionCall{
@@ -550,20 +694,37 @@ func convertBifrostMessagesToGemini(messages []schemas.ChatMessage) []Content {
 			if message.ChatToolMessage.ToolCallID != nil {
 				callID = *message.ChatToolMessage.ToolCallID
 			}
+			// Gemini uses the function name for correlation
+			name, ok := toolCallNames[callID]
+			if !ok {
+				name = callID
+			}
 
 			parts = append(parts, &Part{
 				FunctionResponse: &FunctionResponse{
 					ID:       callID,
-					Name:     callID, // Gemini uses name for correlation
+					Name:     name,
 					Response: responseData,
 				},
 			})
 		}
 
 		if len(parts) > 0 {
+			// Responses to parallel function calls are sent together in a single user turn
+			if message.Role == schemas.ChatMessageRoleTool && i > 0 && messages[i-1].Role == schemas.ChatMessageRoleTool && len(contents) > 0 {
+				contents[len(contents)-1].Parts = append(contents[len(contents)-1].Parts, parts...)
+				continue
+			}
+			role := string(message.Role)
+			switch message.Role {
+			case schemas.ChatMessageRoleAssistant:
+				role = RoleModel
+			case schemas.ChatMessageRoleTool:
+				role = RoleUser
+			}
 			content := Content{
 				Parts: parts,
-				Role:  string(message.Role),
+				Role:  role,
 			}
 			contents = append(contents, content)
 		}
@@ -0,0 +1,87 @@
+package huggingface
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/valyala/fasthttp"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ErrorTypeModelLoading is the error type of the 503 responses returned while an endpoint cold starts
+const ErrorTypeModelLoading = "model_loading"
+
+var (
+	// coldStartTimeout is how long a request waits for a cold starting endpoint before its 503 is returned
+	coldStartTimeout = 5 * time.Minute
+	// coldStartPollInterval is the wait between two attempts while an endpoint cold starts
+	coldStartPollInterval = 5 * time.Second
+)
+
+// parseHuggingFaceError parses an error response of TGI or Inference Endpoints.
+// A 503 means the endpoint is scaled to zero or still loading the model, and is typed as ErrorTypeModelLoading.
+func parseHuggingFaceError(resp *fasthttp.Response, requestType schemas.RequestType, model string) *schemas.BifrostError {
+	statusCode := resp.StatusCode()
+	body := resp.Body()
+
+	var errorResp HuggingFaceError
+	message := ""
+	var errorType *string
+	if err := sonic.Unmarshal(body, &errorResp); err == nil && errorResp.Error != "" {
+		message = errorResp.Error
+		if errorResp.ErrorType != "" {
+			errorType = schemas.Ptr(errorResp.ErrorType)
+		}
+	} else {
+		message = fmt.Sprintf("provider API error: %s", string(body))
+	}
+
+	if statusCode == fasthttp.StatusServiceUnavailable {
+		errorType = schemas.Ptr(ErrorTypeModelLoading)
+		if errorResp.EstimatedTime != nil {
+			message = fmt.Sprintf("%s (estimated time %.0fs)", message, *errorResp.EstimatedTime)
+		}
+	}
+
+	return &schemas.BifrostError{
+		IsBifrostError: false,
+		StatusCode:     &statusCode,
+		Type:           errorType,
+		Error: &schemas.ErrorField{
+			Message: message,
+			Type:    errorType,
+		},
+		ExtraFields: schemas.BifrostErrorExtraFields{
+			Provider:       schemas.HuggingFace,
+			ModelRequested: model,
+			RequestType:    requestType,
+		},
+	}
+}
+
+// isColdStartError reports whether the error is the 503 of an endpoint that is not ready to serve yet
+func isColdStartError(err *schemas.BifrostError) bool {
+	return err != nil && !err.IsBifrostError && err.StatusCode != nil && *err.StatusCode == fasthttp.StatusServiceUnavailable
+}
+
+// withColdStartRetry runs the request, repeating it while the endpoint cold starts.
+// Cold start waits follow coldStartPollInterval for up to coldStartTimeout and do not count
+// towards the retries of the network config, which apply to the error returned afterwards.
+func withColdStartRetry[T any](ctx context.Context, logger schemas.Logger, request func() (T, *schemas.BifrostError)) (T, *schemas.BifrostError) {
+	deadline := time.Now().Add(coldStartTimeout)
+	for {
+		result, bifrostErr := request()
+		if !isColdStartError(bifrostErr) || time.Now().Add(coldStartPollInterval).After(deadline) {
+			return result, bifrostErr
+		}
+		logger.Debug(fmt.Sprintf("huggingface endpoint is cold starting, retrying in %s: %s", coldStartPollInterval, bifrostErr.Error.Message))
+		select {
+		case <-ctx.Done():
+			return result, bifrostErr
+		case <-time.After(coldStartPollInterval):
+		}
+	}
+}
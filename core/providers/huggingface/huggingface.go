@@ -0,0 +1,517 @@
+// Package huggingface implements the Hugging Face Inference Endpoints and self-hosted TGI provider.
+package huggingface
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/maximhq/bifrost/core/providers/openai"
+	providerUtils "github.com/maximhq/bifrost/core/providers/utils"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+// HuggingFaceProvider implements the Provider interface for Hugging Face Inference Endpoints and TGI servers.
+// The base URL is the URL of the endpoint, which serves a single model.
+type HuggingFaceProvider struct {
+	logger              schemas.Logger        // Logger for provider operations
+	client              *fasthttp.Client      // HTTP client for API requests
+	networkConfig       schemas.NetworkConfig // Network configuration including extra headers
+	sendBackRawResponse bool                  // Whether to include raw response in BifrostResponse
+}
+
+// NewHuggingFaceProvider creates a new Hugging Face provider instance.
+// It initializes the HTTP client with the provided configuration and sets up response pools.
+// The client is configured with timeouts, concurrency limits, and optional proxy settings.
+func NewHuggingFaceProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*HuggingFaceProvider, error) {
+	config.CheckAndSetDefaults()
+
+	client := providerUtils.NewHTTPClient(config, logger)
+
+	config.NetworkConfig.BaseURL = strings.TrimRight(config.NetworkConfig.BaseURL, "/")
+
+	// BaseURL is required, it is the URL of the inference endpoint or TGI server
+	if config.NetworkConfig.BaseURL == "" {
+		return nil, fmt.Errorf("base_url is required for huggingface provider")
+	}
+
+	providerUtils.WarmUpConnections(client, config.NetworkConfig, logger)
+
+	return &HuggingFaceProvider{
+		logger:              logger,
+		client:              client,
+		networkConfig:       config.NetworkConfig,
+		sendBackRawResponse: config.SendBackRawResponse,
+	}, nil
+}
+
+// GetProviderKey returns the provider identifier for Hugging Face.
+func (provider *HuggingFaceProvider) GetProviderKey() schemas.ModelProvider {
+	return schemas.HuggingFace
+}
+
+// authHeader returns the token auth header of the key, self-hosted TGI servers are used with an empty key
+func authHeader(key schemas.Key) map[string]string {
+	if key.Value == "" {
+		return nil
+	}
+	return map[string]string{"Authorization": "Bearer " + key.Value}
+}
+
+// completeRequest sends a request to the endpoint and handles the response.
+// Returns a copy of the response body or an error if the request fails.
+func (provider *HuggingFaceProvider) completeRequest(ctx context.Context, method string, url string, jsonData []byte, key schemas.Key, requestType schemas.RequestType, model string) ([]byte, time.Duration, *schemas.BifrostError) {
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	// Set any extra headers from network config
+	providerUtils.SetExtraHeaders(ctx, req, provider.networkConfig.ExtraHeaders, nil)
+
+	req.SetRequestURI(url)
+	req.Header.SetMethod(method)
+	req.Header.SetContentType("application/json")
+	for header, value := range authHeader(key) {
+		req.Header.Set(header, value)
+	}
+
+	if jsonData != nil {
+		req.SetBody(jsonData)
+	}
+
+	// Send the request
+	latency, bifrostErr := providerUtils.MakeRequestWithContext(ctx, provider.client, req, resp)
+	if bifrostErr != nil {
+		return nil, latency, bifrostErr
+	}
+
+	// Handle error response
+	if resp.StatusCode() != fasthttp.StatusOK {
+		provider.logger.Debug(fmt.Sprintf("error from %s provider: %s", provider.GetProviderKey(), string(resp.Body())))
+		return nil, latency, parseHuggingFaceError(resp, requestType, model)
+	}
+
+	body, err := providerUtils.CheckAndDecodeBody(resp)
+	if err != nil {
+		return nil, latency, providerUtils.NewBifrostOperationError(schemas.ErrProviderResponseDecode, err, provider.GetProviderKey())
+	}
+
+	// Copy the body before releasing the response
+	// to avoid use-after-free since resp.Body() references fasthttp's internal buffer
+	bodyCopy := append([]byte(nil), body...)
+
+	return bodyCopy, latency, nil
+}
+
+// listModelsByKey returns the model served by the endpoint, from its /info API.
+func (provider *HuggingFaceProvider) listModelsByKey(ctx context.Context, key schemas.Key, request *schemas.BifrostListModelsRequest) (*schemas.BifrostListModelsResponse, *schemas.BifrostError) {
+	return withColdStartRetry(ctx, provider.logger, func() (*schemas.BifrostListModelsResponse, *schemas.BifrostError) {
+		responseBody, latency, bifrostErr := provider.completeRequest(ctx, http.MethodGet, provider.networkConfig.BaseURL+providerUtils.GetPathFromContext(ctx, "/info"), nil, key, schemas.ListModelsRequest, "")
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+
+		var info HuggingFaceInfoResponse
+		rawResponse, bifrostErr := providerUtils.HandleProviderResponse(responseBody, &info, providerUtils.ShouldSendBackRawResponse(ctx, provider.sendBackRawResponse))
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+
+		response := &schemas.BifrostListModelsResponse{
+			Data: []schemas.Model{
+				{
+					ID:             string(schemas.HuggingFace) + "/" + info.ModelID,
+					HuggingFaceID:  schemas.Ptr(info.ModelID),
+					MaxInputTokens: info.MaxInputTokens,
+					ContextLength:  info.MaxTotalTokens,
+				},
+			},
+		}
+		response.ExtraFields.Latency = latency.Milliseconds()
+
+		// Set raw response if enabled
+		if providerUtils.ShouldSendBackRawResponse(ctx, provider.sendBackRawResponse) {
+			response.ExtraFields.RawResponse = rawResponse
+		}
+
+		return response, nil
+	})
+}
+
+// ListModels returns the model served by each endpoint key.
+func (provider *HuggingFaceProvider) ListModels(ctx context.Context, keys []schemas.Key, request *schemas.BifrostListModelsRequest) (*schemas.BifrostListModelsResponse, *schemas.BifrostError) {
+	return providerUtils.HandleMultipleListModelsRequests(
+		ctx,
+		keys,
+		request,
+		provider.listModelsByKey,
+		provider.logger,
+	)
+}
+
+// TextCompletion performs a text completion request with the native /generate API.
+// Requests sent while the endpoint cold starts are repeated until it is ready, see withColdStartRetry.
+func (provider *HuggingFaceProvider) TextCompletion(ctx context.Context, key schemas.Key, request *schemas.BifrostTextCompletionRequest) (*schemas.BifrostTextCompletionResponse, *schemas.BifrostError) {
+	jsonBody, bifrostErr := providerUtils.CheckContextAndGetRequestBody(
+		ctx,
+		request,
+		func() (any, error) {
+			reqBody, err := ToHuggingFaceGenerateRequest(request)
+			if reqBody == nil || err != nil {
+				return nil, err
+			}
+			return reqBody, nil
+		},
+		provider.GetProviderKey())
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	return withColdStartRetry(ctx, provider.logger, func() (*schemas.BifrostTextCompletionResponse, *schemas.BifrostError) {
+		responseBody, latency, bifrostErr := provider.completeRequest(ctx, http.MethodPost, provider.networkConfig.BaseURL+providerUtils.GetPathFromContext(ctx, "/generate"), jsonBody, key, schemas.TextCompletionRequest, request.Model)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+
+		var response HuggingFaceGenerateResponse
+		rawResponse, bifrostErr := providerUtils.HandleProviderResponse(responseBody, &response, providerUtils.ShouldSendBackRawResponse(ctx, provider.sendBackRawResponse))
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+
+		bifrostResponse := response.ToBifrostTextCompletionResponse(request.Model)
+
+		// Set ExtraFields
+		bifrostResponse.ExtraFields.ModelRequested = request.Model
+		bifrostResponse.ExtraFields.Latency = latency.Milliseconds()
+
+		// Set raw response if enabled
+		if providerUtils.ShouldSendBackRawResponse(ctx, provider.sendBackRawResponse) {
+			bifrostResponse.ExtraFields.RawResponse = rawResponse
+		}
+
+		return bifrostResponse, nil
+	})
+}
+
+// TextCompletionStream performs a streaming text completion request with the native /generate_stream API.
+// Returns a channel of BifrostStream objects or an error if the request fails.
+func (provider *HuggingFaceProvider) TextCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, key schemas.Key, request *schemas.BifrostTextCompletionRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	jsonBody, bifrostErr := providerUtils.CheckContextAndGetRequestBody(
+		ctx,
+		request,
+		func() (any, error) {
+			reqBody, err := ToHuggingFaceGenerateRequest(request)
+			if reqBody == nil || err != nil {
+				return nil, err
+			}
+			return reqBody, nil
+		},
+		provider.GetProviderKey())
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	return withColdStartRetry(ctx, provider.logger, func() (chan *schemas.BifrostStream, *schemas.BifrostError) {
+		return provider.generateStream(ctx, postHookRunner, key, request.Model, jsonBody)
+	})
+}
+
+// generateStream opens a /generate_stream request and streams its token events as text completion chunks.
+func (provider *HuggingFaceProvider) generateStream(ctx context.Context, postHookRunner schemas.PostHookRunner, key schemas.Key, model string, jsonBody []byte) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	providerName := provider.GetProviderKey()
+	sendBackRawResponse := providerUtils.ShouldSendBackRawResponse(ctx, provider.sendBackRawResponse)
+
+	// Create HTTP request for streaming
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	resp.StreamBody = true
+	defer fasthttp.ReleaseRequest(req)
+
+	req.Header.SetMethod(http.MethodPost)
+	req.SetRequestURI(provider.networkConfig.BaseURL + "/generate_stream")
+	req.Header.SetContentType("application/json")
+
+	// Set any extra headers from network config
+	providerUtils.SetExtraHeaders(ctx, req, provider.networkConfig.ExtraHeaders, nil)
+
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	for header, value := range authHeader(key) {
+		req.Header.Set(header, value)
+	}
+
+	req.SetBody(jsonBody)
+
+	// Make the request
+	err := provider.client.Do(req, resp)
+	if err != nil {
+		defer providerUtils.ReleaseStreamingResponse(ctx, resp)
+		if errors.Is(err, context.Canceled) {
+			return nil, &schemas.BifrostError{
+				IsBifrostError: false,
+				Error: &schemas.ErrorField{
+					Type:    schemas.Ptr(schemas.RequestCancelled),
+					Message: schemas.ErrRequestCancelled,
+					Error:   err,
+				},
+			}
+		}
+		if errors.Is(err, fasthttp.ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, providerUtils.NewBifrostOperationError(schemas.ErrProviderRequestTimedOut, err, providerName)
+		}
+		return nil, providerUtils.NewBifrostOperationError(schemas.ErrProviderDoRequest, err, providerName)
+	}
+
+	// Check for HTTP errors
+	if resp.StatusCode() != fasthttp.StatusOK {
+		defer providerUtils.ReleaseStreamingResponse(ctx, resp)
+		return nil, parseHuggingFaceError(resp, schemas.TextCompletionStreamRequest, model)
+	}
+
+	// Create response channel
+	responseChan := make(chan *schemas.BifrostStream, providerUtils.GetStreamBufferSize(ctx))
+
+	// Start streaming in a goroutine
+	go func() {
+		defer close(responseChan)
+		defer providerUtils.ReleaseStreamingResponse(ctx, resp)
+
+		scanner := bufio.NewScanner(resp.BodyStream())
+		buf := make([]byte, 0, 1024*1024)
+		scanner.Buffer(buf, 10*1024*1024)
+
+		chunkIndex := -1
+		var usage *schemas.BifrostLLMUsage
+		var finishReason *string
+		startTime := time.Now()
+		lastChunkTime := startTime
+
+		for scanner.Scan() {
+			// Check if context is done before processing
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			line := scanner.Text()
+
+			// Skip empty lines and comments
+			if line == "" || strings.HasPrefix(line, ":") {
+				continue
+			}
+
+			// TGI sends "data:" without a space, raw JSON lines are errors
+			jsonData := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			if jsonData == "" {
+				continue
+			}
+
+			// First, check if this is an error event
+			var errorResp HuggingFaceError
+			if err := sonic.Unmarshal([]byte(jsonData), &errorResp); err == nil && errorResp.Error != "" {
+				var errorType *string
+				if errorResp.ErrorType != "" {
+					errorType = schemas.Ptr(errorResp.ErrorType)
+				}
+				bifrostErr := &schemas.BifrostError{
+					IsBifrostError: false,
+					Type:           errorType,
+					Error: &schemas.ErrorField{
+						Message: errorResp.Error,
+						Type:    errorType,
+					},
+					ExtraFields: schemas.BifrostErrorExtraFields{
+						Provider:       providerName,
+						ModelRequested: model,
+						RequestType:    schemas.TextCompletionStreamRequest,
+					},
+				}
+				ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+				providerUtils.ProcessAndSendBifrostError(ctx, postHookRunner, bifrostErr, responseChan, provider.logger)
+				return
+			}
+
+			var event HuggingFaceStreamResponse
+			if err := sonic.Unmarshal([]byte(jsonData), &event); err != nil {
+				provider.logger.Warn(fmt.Sprintf("Failed to parse stream response: %v", err))
+				continue
+			}
+
+			// The last event carries the details of the generation
+			if event.Details != nil {
+				finishReason = toBifrostFinishReason(event.Details.FinishReason)
+				usage = event.Details.toBifrostUsage()
+			}
+
+			// Special tokens such as the end of sequence token are not part of the text
+			if !event.Token.Special && event.Token.Text != "" {
+				chunkIndex++
+
+				response := schemas.BifrostTextCompletionResponse{
+					Model:  model,
+					Object: "text_completion",
+					Choices: []schemas.BifrostResponseChoice{
+						{
+							Index: 0,
+							TextCompletionResponseChoice: &schemas.TextCompletionResponseChoice{
+								Text: schemas.Ptr(event.Token.Text),
+							},
+						},
+					},
+				}
+				response.ExtraFields.RequestType = schemas.TextCompletionStreamRequest
+				response.ExtraFields.Provider = providerName
+				response.ExtraFields.ModelRequested = model
+				response.ExtraFields.ChunkIndex = chunkIndex
+				response.ExtraFields.Latency = time.Since(lastChunkTime).Milliseconds()
+				lastChunkTime = time.Now()
+
+				if sendBackRawResponse {
+					response.ExtraFields.RawResponse = jsonData
+				}
+
+				providerUtils.ProcessAndSendResponse(ctx, postHookRunner, providerUtils.GetBifrostResponseForStreamResponse(&response, nil, nil, nil, nil), responseChan)
+			}
+
+			if event.Details != nil || event.GeneratedText != nil {
+				break
+			}
+		}
+
+		// Handle scanner errors first
+		if err := scanner.Err(); err != nil {
+			provider.logger.Warn(fmt.Sprintf("Error reading stream: %v", err))
+			providerUtils.ProcessAndSendError(ctx, postHookRunner, err, responseChan, schemas.TextCompletionStreamRequest, providerName, model, provider.logger)
+			return
+		}
+		response := providerUtils.CreateBifrostTextCompletionChunkResponse("", usage, finishReason, chunkIndex, schemas.TextCompletionStreamRequest, providerName, model)
+		response.ExtraFields.Latency = time.Since(startTime).Milliseconds()
+		ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+		providerUtils.ProcessAndSendResponse(ctx, postHookRunner, providerUtils.GetBifrostResponseForStreamResponse(response, nil, nil, nil, nil), responseChan)
+	}()
+
+	return responseChan, nil
+}
+
+// ChatCompletion performs a chat completion request with the OpenAI-compatible messages API of TGI.
+// Requests sent while the endpoint cold starts are repeated until it is ready, see withColdStartRetry.
+func (provider *HuggingFaceProvider) ChatCompletion(ctx context.Context, key schemas.Key, request *schemas.BifrostChatRequest) (*schemas.BifrostChatResponse, *schemas.BifrostError) {
+	jsonBody, bifrostErr := providerUtils.CheckContextAndGetRequestBody(
+		ctx,
+		request,
+		func() (any, error) { return openai.ToOpenAIChatRequest(request), nil },
+		provider.GetProviderKey())
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	return withColdStartRetry(ctx, provider.logger, func() (*schemas.BifrostChatResponse, *schemas.BifrostError) {
+		responseBody, latency, bifrostErr := provider.completeRequest(ctx, http.MethodPost, provider.networkConfig.BaseURL+providerUtils.GetPathFromContext(ctx, "/v1/chat/completions"), jsonBody, key, schemas.ChatCompletionRequest, request.Model)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+
+		// TGI responses follow the OpenAI chat completion format
+		response := &schemas.BifrostChatResponse{}
+		rawResponse, bifrostErr := providerUtils.HandleProviderResponse(responseBody, response, providerUtils.ShouldSendBackRawResponse(ctx, provider.sendBackRawResponse))
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+
+		// Set raw response if enabled
+		if providerUtils.ShouldSendBackRawResponse(ctx, provider.sendBackRawResponse) {
+			response.ExtraFields.RawResponse = rawResponse
+		}
+
+		response.ExtraFields.Provider = provider.GetProviderKey()
+		response.ExtraFields.ModelRequested = request.Model
+		response.ExtraFields.RequestType = schemas.ChatCompletionRequest
+		response.ExtraFields.Latency = latency.Milliseconds()
+
+		return response, nil
+	})
+}
+
+// ChatCompletionStream performs a streaming chat completion request with the OpenAI-compatible messages API of TGI.
+// It supports real-time streaming of responses using Server-Sent Events (SSE).
+// Returns a channel containing BifrostResponse objects representing the stream or an error if the request fails.
+func (provider *HuggingFaceProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, key schemas.Key, request *schemas.BifrostChatRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return withColdStartRetry(ctx, provider.logger, func() (chan *schemas.BifrostStream, *schemas.BifrostError) {
+		// Use shared OpenAI-compatible streaming logic
+		return openai.HandleOpenAIChatCompletionStreaming(
+			ctx,
+			provider.client,
+			provider.networkConfig.BaseURL+"/v1/chat/completions",
+			request,
+			authHeader(key),
+			provider.networkConfig.ExtraHeaders,
+			providerUtils.ShouldSendBackRawResponse(ctx, provider.sendBackRawResponse),
+			provider.GetProviderKey(),
+			postHookRunner,
+			nil,
+			nil,
+			nil,
+			provider.logger,
+		)
+	})
+}
+
+// Responses performs a responses request with the messages API of TGI.
+func (provider *HuggingFaceProvider) Responses(ctx context.Context, key schemas.Key, request *schemas.BifrostResponsesRequest) (*schemas.BifrostResponsesResponse, *schemas.BifrostError) {
+	chatResponse, err := provider.ChatCompletion(ctx, key, request.ToChatRequest())
+	if err != nil {
+		return nil, err
+	}
+
+	response := chatResponse.ToBifrostResponsesResponse()
+	response.ExtraFields.RequestType = schemas.ResponsesRequest
+	response.ExtraFields.Provider = provider.GetProviderKey()
+	response.ExtraFields.ModelRequested = request.Model
+
+	return response, nil
+}
+
+// ResponsesStream performs a streaming responses request with the messages API of TGI.
+func (provider *HuggingFaceProvider) ResponsesStream(ctx context.Context, postHookRunner schemas.PostHookRunner, key schemas.Key, request *schemas.BifrostResponsesRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	ctx = context.WithValue(ctx, schemas.BifrostContextKeyIsResponsesToChatCompletionFallback, true)
+	return provider.ChatCompletionStream(
+		ctx,
+		postHookRunner,
+		key,
+		request.ToChatRequest(),
+	)
+}
+
+// Embedding is not supported by the Hugging Face provider.
+func (provider *HuggingFaceProvider) Embedding(ctx context.Context, key schemas.Key, request *schemas.BifrostEmbeddingRequest) (*schemas.BifrostEmbeddingResponse, *schemas.BifrostError) {
+	return nil, providerUtils.NewUnsupportedOperationError(schemas.EmbeddingRequest, provider.GetProviderKey())
+}
+
+// Speech is not supported by the Hugging Face provider.
+func (provider *HuggingFaceProvider) Speech(ctx context.Context, key schemas.Key, request *schemas.BifrostSpeechRequest) (*schemas.BifrostSpeechResponse, *schemas.BifrostError) {
+	return nil, providerUtils.NewUnsupportedOperationError(schemas.SpeechRequest, provider.GetProviderKey())
+}
+
+// SpeechStream is not supported by the Hugging Face provider.
+func (provider *HuggingFaceProvider) SpeechStream(ctx context.Context, postHookRunner schemas.PostHookRunner, key schemas.Key, request *schemas.BifrostSpeechRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, providerUtils.NewUnsupportedOperationError(schemas.SpeechStreamRequest, provider.GetProviderKey())
+}
+
+// Transcription is not supported by the Hugging Face provider.
+func (provider *HuggingFaceProvider) Transcription(ctx context.Context, key schemas.Key, request *schemas.BifrostTranscriptionRequest) (*schemas.BifrostTranscriptionResponse, *schemas.BifrostError) {
+	return nil, providerUtils.NewUnsupportedOperationError(schemas.TranscriptionRequest, provider.GetProviderKey())
+}
+
+// TranscriptionStream is not supported by the Hugging Face provider.
+func (provider *HuggingFaceProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, key schemas.Key, request *schemas.BifrostTranscriptionRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, providerUtils.NewUnsupportedOperationError(schemas.TranscriptionStreamRequest, provider.GetProviderKey())
+}
@@ -0,0 +1,161 @@
+package huggingface_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/internal/testutil"
+	"github.com/maximhq/bifrost/core/providers/huggingface"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHuggingFace(t *testing.T) {
+	t.Parallel()
+	if os.Getenv("HUGGINGFACE_BASE_URL") == "" {
+		t.Skip("Skipping Hugging Face tests because HUGGINGFACE_BASE_URL is not set")
+	}
+
+	client, ctx, cancel, err := testutil.SetupTest()
+	if err != nil {
+		t.Fatalf("Error initializing test setup: %v", err)
+	}
+	defer cancel()
+
+	testConfig := testutil.ComprehensiveTestConfig{
+		Provider:       schemas.HuggingFace,
+		ChatModel:      "tgi", // TGI serves a single model and ignores the model name
+		TextModel:      "tgi",
+		EmbeddingModel: "", // TGI doesn't support embedding
+		Scenarios: testutil.TestScenarios{
+			TextCompletion:        true,
+			TextCompletionStream:  true,
+			SimpleChat:            true,
+			CompletionStream:      true,
+			MultiTurnConversation: true,
+			ToolCalls:             true,
+			ToolCallsStreaming:    true,
+			MultipleToolCalls:     false,
+			End2EndToolCalling:    true,
+			AutomaticFunctionCall: true,
+			ImageURL:              false,
+			ImageBase64:           false,
+			MultipleImages:        false,
+			CompleteEnd2End:       true,
+			Embedding:             false,
+			ListModels:            true,
+		},
+	}
+
+	t.Run("HuggingFaceTests", func(t *testing.T) {
+		testutil.RunAllComprehensiveTests(t, client, ctx, testConfig)
+	})
+	client.Shutdown()
+}
+
+func TestToHuggingFaceGenerateRequest(t *testing.T) {
+	t.Run("MapsParameters", func(t *testing.T) {
+		request := &schemas.BifrostTextCompletionRequest{
+			Provider: schemas.HuggingFace,
+			Model:    "tgi",
+			Input:    &schemas.TextCompletionInput{PromptStr: schemas.Ptr("Once upon a time")},
+			Params: &schemas.TextCompletionParameters{
+				MaxTokens:   schemas.Ptr(64),
+				Temperature: schemas.Ptr(0.7),
+				Stop:        []string{"\n\n"},
+				ExtraParams: map[string]interface{}{
+					"top_k":              10,
+					"repetition_penalty": 1.2,
+				},
+			},
+		}
+
+		actual, err := huggingface.ToHuggingFaceGenerateRequest(request)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.Equal(t, "Once upon a time", actual.Inputs)
+		require.NotNil(t, actual.Parameters)
+		assert.Equal(t, schemas.Ptr(64), actual.Parameters.MaxNewTokens)
+		assert.Equal(t, schemas.Ptr(true), actual.Parameters.DoSample)
+		assert.Equal(t, schemas.Ptr(10), actual.Parameters.TopK)
+		assert.Equal(t, schemas.Ptr(1.2), actual.Parameters.RepetitionPenalty)
+		assert.Equal(t, []string{"\n\n"}, actual.Parameters.Stop)
+		assert.True(t, actual.Parameters.Details)
+	})
+
+	t.Run("RejectsMultiplePrompts", func(t *testing.T) {
+		request := &schemas.BifrostTextCompletionRequest{
+			Provider: schemas.HuggingFace,
+			Model:    "tgi",
+			Input:    &schemas.TextCompletionInput{PromptArray: []string{"a", "b"}},
+		}
+
+		_, err := huggingface.ToHuggingFaceGenerateRequest(request)
+		assert.Error(t, err)
+	})
+}
+
+func TestHuggingFaceColdStart(t *testing.T) {
+	newProvider := func(t *testing.T, handler http.HandlerFunc) *huggingface.HuggingFaceProvider {
+		server := httptest.NewServer(handler)
+		t.Cleanup(server.Close)
+
+		provider, err := huggingface.NewHuggingFaceProvider(&schemas.ProviderConfig{
+			NetworkConfig: schemas.NetworkConfig{BaseURL: server.URL},
+		}, bifrost.NewDefaultLogger(schemas.LogLevelError))
+		require.NoError(t, err)
+		return provider
+	}
+	request := &schemas.BifrostTextCompletionRequest{
+		Provider: schemas.HuggingFace,
+		Model:    "tgi",
+		Input:    &schemas.TextCompletionInput{PromptStr: schemas.Ptr("Hello")},
+	}
+
+	t.Run("WaitsForEndpoint", func(t *testing.T) {
+		var calls atomic.Int32
+		provider := newProvider(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if calls.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte(`{"error":"Model is loading","estimated_time":2.0}`))
+				return
+			}
+			w.Write([]byte(`{"generated_text":" world","details":{"finish_reason":"eos_token","generated_tokens":2}}`))
+		})
+
+		response, bifrostErr := provider.TextCompletion(context.Background(), schemas.Key{}, request)
+		require.Nil(t, bifrostErr)
+		require.NotNil(t, response)
+		assert.Equal(t, int32(2), calls.Load())
+		require.Len(t, response.Choices, 1)
+		assert.Equal(t, " world", *response.Choices[0].TextCompletionResponseChoice.Text)
+		assert.Equal(t, schemas.Ptr("stop"), response.Choices[0].FinishReason)
+		assert.Equal(t, 2, response.Usage.CompletionTokens)
+	})
+
+	t.Run("ReturnsModelLoadingError", func(t *testing.T) {
+		provider := newProvider(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error":"Model is loading","estimated_time":30.0}`))
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+
+		_, bifrostErr := provider.TextCompletion(ctx, schemas.Key{}, request)
+		require.NotNil(t, bifrostErr)
+		require.NotNil(t, bifrostErr.StatusCode)
+		assert.Equal(t, http.StatusServiceUnavailable, *bifrostErr.StatusCode)
+		assert.Equal(t, schemas.Ptr(huggingface.ErrorTypeModelLoading), bifrostErr.Type)
+		assert.Contains(t, bifrostErr.Error.Message, "estimated time 30s")
+	})
+}
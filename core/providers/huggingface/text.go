@@ -0,0 +1,126 @@
+package huggingface
+
+import (
+	"fmt"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ToHuggingFaceGenerateRequest converts a Bifrost text completion request to a TGI generate request.
+// TGI generates a single completion for a single prompt.
+func ToHuggingFaceGenerateRequest(bifrostReq *schemas.BifrostTextCompletionRequest) (*HuggingFaceGenerateRequest, error) {
+	if bifrostReq == nil || bifrostReq.Input == nil {
+		return nil, nil
+	}
+
+	hfReq := &HuggingFaceGenerateRequest{
+		Parameters: &HuggingFaceGenerateParameters{
+			Details: true,
+		},
+	}
+	switch {
+	case bifrostReq.Input.PromptStr != nil:
+		hfReq.Inputs = *bifrostReq.Input.PromptStr
+	case len(bifrostReq.Input.PromptArray) == 1:
+		hfReq.Inputs = bifrostReq.Input.PromptArray[0]
+	default:
+		return nil, fmt.Errorf("huggingface text completion supports a single prompt, got %d", len(bifrostReq.Input.PromptArray))
+	}
+
+	// Map parameters if they exist
+	if bifrostReq.Params != nil {
+		params := bifrostReq.Params
+		if params.N != nil && *params.N > 1 {
+			return nil, fmt.Errorf("huggingface text completion supports a single completion, got n=%d", *params.N)
+		}
+		hfReq.Parameters.MaxNewTokens = params.MaxTokens
+		hfReq.Parameters.Temperature = params.Temperature
+		hfReq.Parameters.TopP = params.TopP
+		hfReq.Parameters.FrequencyPenalty = params.FrequencyPenalty
+		hfReq.Parameters.Stop = params.Stop
+		hfReq.Parameters.Seed = params.Seed
+		if params.Echo != nil {
+			hfReq.Parameters.ReturnFullText = params.Echo
+		}
+		// TGI decodes greedily unless sampling is enabled
+		if params.Temperature != nil || params.TopP != nil || params.Seed != nil {
+			hfReq.Parameters.DoSample = schemas.Ptr(true)
+		}
+
+		// Handle extra parameters for TGI-specific fields
+		if params.ExtraParams != nil {
+			if topK, ok := schemas.SafeExtractIntPointer(params.ExtraParams["top_k"]); ok {
+				hfReq.Parameters.TopK = topK
+			}
+
+			if typicalP, ok := schemas.SafeExtractFloat64Pointer(params.ExtraParams["typical_p"]); ok {
+				hfReq.Parameters.TypicalP = typicalP
+			}
+
+			if repetitionPenalty, ok := schemas.SafeExtractFloat64Pointer(params.ExtraParams["repetition_penalty"]); ok {
+				hfReq.Parameters.RepetitionPenalty = repetitionPenalty
+			}
+
+			if doSample, ok := schemas.SafeExtractBoolPointer(params.ExtraParams["do_sample"]); ok {
+				hfReq.Parameters.DoSample = doSample
+			}
+
+			if truncate, ok := schemas.SafeExtractIntPointer(params.ExtraParams["truncate"]); ok {
+				hfReq.Parameters.Truncate = truncate
+			}
+		}
+	}
+
+	return hfReq, nil
+}
+
+// toBifrostFinishReason maps the finish reason of TGI to the OpenAI finish reasons
+func toBifrostFinishReason(finishReason string) *string {
+	switch finishReason {
+	case "":
+		return nil
+	case "eos_token", "stop_sequence":
+		return schemas.Ptr("stop")
+	default:
+		return schemas.Ptr(finishReason)
+	}
+}
+
+// toBifrostUsage maps the generation details to usage, TGI only reports the generated tokens
+func (details *HuggingFaceGenerationDetails) toBifrostUsage() *schemas.BifrostLLMUsage {
+	if details == nil {
+		return nil
+	}
+	return &schemas.BifrostLLMUsage{
+		CompletionTokens: details.GeneratedTokens,
+		TotalTokens:      details.GeneratedTokens,
+	}
+}
+
+// ToBifrostTextCompletionResponse converts a TGI generate response to Bifrost format
+func (response *HuggingFaceGenerateResponse) ToBifrostTextCompletionResponse(model string) *schemas.BifrostTextCompletionResponse {
+	if response == nil {
+		return nil
+	}
+
+	choice := schemas.BifrostResponseChoice{
+		Index: 0,
+		TextCompletionResponseChoice: &schemas.TextCompletionResponseChoice{
+			Text: schemas.Ptr(response.GeneratedText),
+		},
+	}
+	if response.Details != nil {
+		choice.FinishReason = toBifrostFinishReason(response.Details.FinishReason)
+	}
+
+	return &schemas.BifrostTextCompletionResponse{
+		Model:   model,
+		Object:  "text_completion",
+		Choices: []schemas.BifrostResponseChoice{choice},
+		Usage:   response.Details.toBifrostUsage(),
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			RequestType: schemas.TextCompletionRequest,
+			Provider:    schemas.HuggingFace,
+		},
+	}
+}
@@ -0,0 +1,69 @@
+package huggingface
+
+// HuggingFaceGenerateRequest represents a request to the native /generate and /generate_stream APIs of TGI
+type HuggingFaceGenerateRequest struct {
+	Inputs     string                         `json:"inputs"`               // Required: Prompt to complete
+	Parameters *HuggingFaceGenerateParameters `json:"parameters,omitempty"` // Optional: Generation parameters
+}
+
+// HuggingFaceGenerateParameters represents the generation parameters of TGI
+type HuggingFaceGenerateParameters struct {
+	MaxNewTokens      *int     `json:"max_new_tokens,omitempty"`     // Optional: Maximum tokens to generate
+	Temperature       *float64 `json:"temperature,omitempty"`        // Optional: Sampling temperature
+	TopP              *float64 `json:"top_p,omitempty"`              // Optional: Top-p sampling
+	TopK              *int     `json:"top_k,omitempty"`              // Optional: Top-k sampling
+	TypicalP          *float64 `json:"typical_p,omitempty"`          // Optional: Typical decoding mass
+	RepetitionPenalty *float64 `json:"repetition_penalty,omitempty"` // Optional: Repetition penalty, 1.0 means no penalty
+	FrequencyPenalty  *float64 `json:"frequency_penalty,omitempty"`  // Optional: Frequency penalty
+	Stop              []string `json:"stop,omitempty"`               // Optional: Stop sequences
+	Seed              *int     `json:"seed,omitempty"`               // Optional: Random sampling seed
+	DoSample          *bool    `json:"do_sample,omitempty"`          // Optional: Sample instead of greedy decoding
+	Truncate          *int     `json:"truncate,omitempty"`           // Optional: Truncate inputs to this many tokens
+	ReturnFullText    *bool    `json:"return_full_text,omitempty"`   // Optional: Prepend the prompt to the generated text
+	Details           bool     `json:"details"`                      // Return the finish reason and token counts
+}
+
+// HuggingFaceGenerateResponse represents a response of the native /generate API
+type HuggingFaceGenerateResponse struct {
+	GeneratedText string                        `json:"generated_text"`
+	Details       *HuggingFaceGenerationDetails `json:"details,omitempty"`
+}
+
+// HuggingFaceGenerationDetails represents the details of a generation
+type HuggingFaceGenerationDetails struct {
+	FinishReason    string `json:"finish_reason"` // "length" | "eos_token" | "stop_sequence"
+	GeneratedTokens int    `json:"generated_tokens"`
+	Seed            *int   `json:"seed,omitempty"`
+}
+
+// HuggingFaceStreamResponse represents an event of the native /generate_stream API.
+// GeneratedText and Details are only set on the last event.
+type HuggingFaceStreamResponse struct {
+	Index         int                           `json:"index"`
+	Token         HuggingFaceToken              `json:"token"`
+	GeneratedText *string                       `json:"generated_text,omitempty"`
+	Details       *HuggingFaceGenerationDetails `json:"details,omitempty"`
+}
+
+// HuggingFaceToken represents a generated token
+type HuggingFaceToken struct {
+	ID      int     `json:"id"`
+	Text    string  `json:"text"`
+	LogProb float64 `json:"logprob"`
+	Special bool    `json:"special"`
+}
+
+// HuggingFaceInfoResponse represents a response of the /info API, describing the served model
+type HuggingFaceInfoResponse struct {
+	ModelID        string `json:"model_id"`
+	MaxInputTokens *int   `json:"max_input_tokens,omitempty"`
+	MaxTotalTokens *int   `json:"max_total_tokens,omitempty"`
+	Version        string `json:"version,omitempty"`
+}
+
+// HuggingFaceError represents an error response of TGI and Inference Endpoints
+type HuggingFaceError struct {
+	Error         string   `json:"error"`
+	ErrorType     string   `json:"error_type,omitempty"`
+	EstimatedTime *float64 `json:"estimated_time,omitempty"` // Seconds until a loading model is ready, only set on cold starts
+}
@@ -0,0 +1,520 @@
+// Package mock implements a provider generating synthetic responses, used to load test Bifrost,
+// its plugins and governance without sending requests to real providers.
+package mock
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	providerUtils "github.com/maximhq/bifrost/core/providers/utils"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// defaultResponse is the text of the responses when the mock config sets none
+const defaultResponse = "This is a synthetic response generated by the Bifrost mock provider for load testing."
+
+// defaultModel is the model listed when the mock config sets none
+const defaultModel = "mock-model"
+
+// defaultEmbeddingDimensions is the size of the embeddings when the request does not set one
+const defaultEmbeddingDimensions = 256
+
+// MockProvider implements the Provider interface with synthetic responses.
+type MockProvider struct {
+	logger schemas.Logger     // Logger for provider operations
+	config schemas.MockConfig // Synthetic responses settings
+}
+
+// NewMockProvider creates a new mock provider instance.
+func NewMockProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*MockProvider, error) {
+	config.CheckAndSetDefaults()
+
+	provider := &MockProvider{logger: logger}
+	if config.MockConfig != nil {
+		if err := config.MockConfig.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid mock config: %w", err)
+		}
+		provider.config = *config.MockConfig
+	}
+	if provider.config.ErrorStatusCode == 0 {
+		provider.config.ErrorStatusCode = http.StatusInternalServerError
+	}
+	return provider, nil
+}
+
+// GetProviderKey returns the provider identifier for the mock provider.
+func (provider *MockProvider) GetProviderKey() schemas.ModelProvider {
+	return schemas.Mock
+}
+
+// ListModels lists the models of the mock config.
+func (provider *MockProvider) ListModels(ctx context.Context, keys []schemas.Key, request *schemas.BifrostListModelsRequest) (*schemas.BifrostListModelsResponse, *schemas.BifrostError) {
+	models := provider.config.Models
+	if len(models) == 0 {
+		models = []string{defaultModel}
+	}
+	response := &schemas.BifrostListModelsResponse{
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider:    provider.GetProviderKey(),
+			RequestType: schemas.ListModelsRequest,
+		},
+	}
+	for _, model := range models {
+		response.Data = append(response.Data, schemas.Model{ID: string(provider.GetProviderKey()) + "/" + model})
+	}
+	return response, nil
+}
+
+// TextCompletion generates a synthetic text completion.
+func (provider *MockProvider) TextCompletion(ctx context.Context, key schemas.Key, request *schemas.BifrostTextCompletionRequest) (*schemas.BifrostTextCompletionResponse, *schemas.BifrostError) {
+	prompt := textCompletionPrompt(request.Input)
+	tokens := provider.responseTokens(prompt)
+	startTime := time.Now()
+	if bifrostErr := provider.simulate(ctx, len(tokens), schemas.TextCompletionRequest, request.Model); bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	return &schemas.BifrostTextCompletionResponse{
+		ID:     uuid.NewString(),
+		Model:  request.Model,
+		Object: "text_completion",
+		Choices: []schemas.BifrostResponseChoice{{
+			FinishReason:                 schemas.Ptr("stop"),
+			TextCompletionResponseChoice: &schemas.TextCompletionResponseChoice{Text: schemas.Ptr(strings.Join(tokens, ""))},
+		}},
+		Usage: usage(countTokens(prompt), len(tokens)),
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider:       provider.GetProviderKey(),
+			ModelRequested: request.Model,
+			RequestType:    schemas.TextCompletionRequest,
+			Latency:        time.Since(startTime).Milliseconds(),
+		},
+	}, nil
+}
+
+// TextCompletionStream streams a synthetic text completion at the token rate of the mock config.
+func (provider *MockProvider) TextCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, key schemas.Key, request *schemas.BifrostTextCompletionRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	prompt := textCompletionPrompt(request.Input)
+	tokens := provider.responseTokens(prompt)
+	if bifrostErr := provider.failRequest(schemas.TextCompletionStreamRequest, request.Model); bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	responseChan := make(chan *schemas.BifrostStream, providerUtils.GetStreamBufferSize(ctx))
+	go func() {
+		defer close(responseChan)
+
+		id := uuid.NewString()
+		startTime := time.Now()
+		lastChunkTime := startTime
+		for i, token := range tokens {
+			if !provider.wait(ctx, i) {
+				return
+			}
+			response := &schemas.BifrostTextCompletionResponse{
+				ID:     id,
+				Model:  request.Model,
+				Object: "text_completion",
+				Choices: []schemas.BifrostResponseChoice{{
+					TextCompletionResponseChoice: &schemas.TextCompletionResponseChoice{Text: schemas.Ptr(token)},
+				}},
+				ExtraFields: schemas.BifrostResponseExtraFields{
+					Provider:       provider.GetProviderKey(),
+					ModelRequested: request.Model,
+					RequestType:    schemas.TextCompletionStreamRequest,
+					ChunkIndex:     i,
+					Latency:        time.Since(lastChunkTime).Milliseconds(),
+				},
+			}
+			lastChunkTime = time.Now()
+			providerUtils.ProcessAndSendResponse(ctx, postHookRunner, providerUtils.GetBifrostResponseForStreamResponse(response, nil, nil, nil, nil), responseChan)
+		}
+
+		response := providerUtils.CreateBifrostTextCompletionChunkResponse(id, usage(countTokens(prompt), len(tokens)), schemas.Ptr("stop"), len(tokens)-1, schemas.TextCompletionStreamRequest, provider.GetProviderKey(), request.Model)
+		response.ExtraFields.Latency = time.Since(startTime).Milliseconds()
+		ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+		providerUtils.ProcessAndSendResponse(ctx, postHookRunner, providerUtils.GetBifrostResponseForStreamResponse(response, nil, nil, nil, nil), responseChan)
+	}()
+
+	return responseChan, nil
+}
+
+// ChatCompletion generates a synthetic chat completion.
+func (provider *MockProvider) ChatCompletion(ctx context.Context, key schemas.Key, request *schemas.BifrostChatRequest) (*schemas.BifrostChatResponse, *schemas.BifrostError) {
+	prompt := chatPrompt(request.Input)
+	tokens := provider.responseTokens(lastChatMessageText(request.Input))
+	startTime := time.Now()
+	if bifrostErr := provider.simulate(ctx, len(tokens), schemas.ChatCompletionRequest, request.Model); bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	return &schemas.BifrostChatResponse{
+		ID:      uuid.NewString(),
+		Created: int(startTime.Unix()),
+		Model:   request.Model,
+		Object:  "chat.completion",
+		Choices: []schemas.BifrostResponseChoice{{
+			FinishReason: schemas.Ptr("stop"),
+			ChatNonStreamResponseChoice: &schemas.ChatNonStreamResponseChoice{
+				Message: &schemas.ChatMessage{
+					Role:    schemas.ChatMessageRoleAssistant,
+					Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr(strings.Join(tokens, ""))},
+				},
+			},
+		}},
+		Usage: usage(countTokens(prompt), len(tokens)),
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider:       provider.GetProviderKey(),
+			ModelRequested: request.Model,
+			RequestType:    schemas.ChatCompletionRequest,
+			Latency:        time.Since(startTime).Milliseconds(),
+		},
+	}, nil
+}
+
+// ChatCompletionStream streams a synthetic chat completion at the token rate of the mock config.
+// The chunks are converted to Responses API events when the request is a responses stream fallback.
+func (provider *MockProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, key schemas.Key, request *schemas.BifrostChatRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	prompt := chatPrompt(request.Input)
+	tokens := provider.responseTokens(lastChatMessageText(request.Input))
+	isResponsesStream, _ := ctx.Value(schemas.BifrostContextKeyIsResponsesToChatCompletionFallback).(bool)
+	requestType := schemas.ChatCompletionStreamRequest
+	if isResponsesStream {
+		requestType = schemas.ResponsesStreamRequest
+	}
+	if bifrostErr := provider.failRequest(requestType, request.Model); bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	responseChan := make(chan *schemas.BifrostStream, providerUtils.GetStreamBufferSize(ctx))
+	go func() {
+		defer close(responseChan)
+
+		var responsesStreamState *schemas.ChatToResponsesStreamState
+		if isResponsesStream {
+			responsesStreamState = schemas.AcquireChatToResponsesStreamState()
+			defer schemas.ReleaseChatToResponsesStreamState(responsesStreamState)
+		}
+
+		id := uuid.NewString()
+		startTime := time.Now()
+		lastChunkTime := startTime
+		send := func(response *schemas.BifrostChatResponse, last bool) {
+			response.Model = request.Model
+			if !isResponsesStream {
+				if last {
+					ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+				}
+				providerUtils.ProcessAndSendResponse(ctx, postHookRunner, providerUtils.GetBifrostResponseForStreamResponse(nil, response, nil, nil, nil), responseChan)
+				return
+			}
+			for _, event := range response.ToBifrostResponsesStreamResponse(responsesStreamState) {
+				event.ExtraFields.Provider = provider.GetProviderKey()
+				event.ExtraFields.ModelRequested = request.Model
+				event.ExtraFields.ChunkIndex = event.SequenceNumber
+				if event.Type == schemas.ResponsesStreamResponseTypeCompleted {
+					event.ExtraFields.Latency = time.Since(startTime).Milliseconds()
+					ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+				}
+				providerUtils.ProcessAndSendResponse(ctx, postHookRunner, providerUtils.GetBifrostResponseForStreamResponse(nil, nil, event, nil, nil), responseChan)
+			}
+		}
+
+		for i, token := range tokens {
+			if !provider.wait(ctx, i) {
+				return
+			}
+			delta := &schemas.ChatStreamResponseChoiceDelta{Content: schemas.Ptr(token)}
+			if i == 0 {
+				delta.Role = schemas.Ptr(string(schemas.ChatMessageRoleAssistant))
+			}
+			send(&schemas.BifrostChatResponse{
+				ID:      id,
+				Created: int(startTime.Unix()),
+				Object:  "chat.completion.chunk",
+				Choices: []schemas.BifrostResponseChoice{{
+					ChatStreamResponseChoice: &schemas.ChatStreamResponseChoice{Delta: delta},
+				}},
+				ExtraFields: schemas.BifrostResponseExtraFields{
+					Provider:       provider.GetProviderKey(),
+					ModelRequested: request.Model,
+					RequestType:    schemas.ChatCompletionStreamRequest,
+					ChunkIndex:     i,
+					Latency:        time.Since(lastChunkTime).Milliseconds(),
+				},
+			}, false)
+			lastChunkTime = time.Now()
+		}
+
+		response := providerUtils.CreateBifrostChatCompletionChunkResponse(id, usage(countTokens(prompt), len(tokens)), schemas.Ptr("stop"), len(tokens)-1, schemas.ChatCompletionStreamRequest, provider.GetProviderKey(), request.Model)
+		response.ExtraFields.Latency = time.Since(startTime).Milliseconds()
+		send(response, true)
+	}()
+
+	return responseChan, nil
+}
+
+// Responses generates a synthetic responses API response from a synthetic chat completion.
+func (provider *MockProvider) Responses(ctx context.Context, key schemas.Key, request *schemas.BifrostResponsesRequest) (*schemas.BifrostResponsesResponse, *schemas.BifrostError) {
+	chatResponse, err := provider.ChatCompletion(ctx, key, request.ToChatRequest())
+	if err != nil {
+		err.ExtraFields.RequestType = schemas.ResponsesRequest
+		return nil, err
+	}
+
+	response := chatResponse.ToBifrostResponsesResponse()
+	response.ExtraFields.RequestType = schemas.ResponsesRequest
+	response.ExtraFields.Provider = provider.GetProviderKey()
+	response.ExtraFields.ModelRequested = request.Model
+
+	return response, nil
+}
+
+// ResponsesStream streams a synthetic responses API response.
+func (provider *MockProvider) ResponsesStream(ctx context.Context, postHookRunner schemas.PostHookRunner, key schemas.Key, request *schemas.BifrostResponsesRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	ctx = context.WithValue(ctx, schemas.BifrostContextKeyIsResponsesToChatCompletionFallback, true)
+	return provider.ChatCompletionStream(
+		ctx,
+		postHookRunner,
+		key,
+		request.ToChatRequest(),
+	)
+}
+
+// Embedding generates deterministic synthetic embeddings, the same text always gets the same embedding.
+func (provider *MockProvider) Embedding(ctx context.Context, key schemas.Key, request *schemas.BifrostEmbeddingRequest) (*schemas.BifrostEmbeddingResponse, *schemas.BifrostError) {
+	var texts []string
+	if request.Input != nil {
+		if request.Input.Text != nil {
+			texts = append(texts, *request.Input.Text)
+		}
+		texts = append(texts, request.Input.Texts...)
+	}
+	dimensions := defaultEmbeddingDimensions
+	if request.Params != nil && request.Params.Dimensions != nil && *request.Params.Dimensions > 0 {
+		dimensions = *request.Params.Dimensions
+	}
+	startTime := time.Now()
+	if bifrostErr := provider.simulate(ctx, 0, schemas.EmbeddingRequest, request.Model); bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	response := &schemas.BifrostEmbeddingResponse{
+		Model:  request.Model,
+		Object: "list",
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider:       provider.GetProviderKey(),
+			ModelRequested: request.Model,
+			RequestType:    schemas.EmbeddingRequest,
+		},
+	}
+	promptTokens := 0
+	for i, text := range texts {
+		response.Data = append(response.Data, schemas.EmbeddingData{
+			Index:     i,
+			Object:    "embedding",
+			Embedding: schemas.EmbeddingStruct{EmbeddingArray: embedding(text, dimensions)},
+		})
+		promptTokens += countTokens(text)
+	}
+	response.Usage = usage(promptTokens, 0)
+	response.ExtraFields.Latency = time.Since(startTime).Milliseconds()
+	return response, nil
+}
+
+// Speech is not supported by the mock provider.
+func (provider *MockProvider) Speech(ctx context.Context, key schemas.Key, request *schemas.BifrostSpeechRequest) (*schemas.BifrostSpeechResponse, *schemas.BifrostError) {
+	return nil, providerUtils.NewUnsupportedOperationError(schemas.SpeechRequest, provider.GetProviderKey())
+}
+
+// SpeechStream is not supported by the mock provider.
+func (provider *MockProvider) SpeechStream(ctx context.Context, postHookRunner schemas.PostHookRunner, key schemas.Key, request *schemas.BifrostSpeechRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, providerUtils.NewUnsupportedOperationError(schemas.SpeechStreamRequest, provider.GetProviderKey())
+}
+
+// Transcription is not supported by the mock provider.
+func (provider *MockProvider) Transcription(ctx context.Context, key schemas.Key, request *schemas.BifrostTranscriptionRequest) (*schemas.BifrostTranscriptionResponse, *schemas.BifrostError) {
+	return nil, providerUtils.NewUnsupportedOperationError(schemas.TranscriptionRequest, provider.GetProviderKey())
+}
+
+// TranscriptionStream is not supported by the mock provider.
+func (provider *MockProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, key schemas.Key, request *schemas.BifrostTranscriptionRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, providerUtils.NewUnsupportedOperationError(schemas.TranscriptionStreamRequest, provider.GetProviderKey())
+}
+
+// responseTokens returns the tokens of the response, the words of the configured response or of the input when echoing.
+// Every token but the first keeps its leading space so that the tokens concatenate to the response.
+func (provider *MockProvider) responseTokens(input string) []string {
+	text := provider.config.Response
+	if provider.config.EchoInput && strings.TrimSpace(input) != "" {
+		text = input
+	}
+	if strings.TrimSpace(text) == "" {
+		text = defaultResponse
+	}
+	words := strings.Fields(text)
+	for i := 1; i < len(words); i++ {
+		words[i] = " " + words[i]
+	}
+	return words
+}
+
+// failRequest returns the synthetic error of the requests failing at the error rate of the mock config, nil otherwise
+func (provider *MockProvider) failRequest(requestType schemas.RequestType, model string) *schemas.BifrostError {
+	if provider.config.ErrorRate <= 0 || rand.Float64() >= provider.config.ErrorRate {
+		return nil
+	}
+	bifrostErr := providerUtils.NewProviderAPIError(
+		fmt.Sprintf("synthetic error of the mock provider (status %d)", provider.config.ErrorStatusCode),
+		nil,
+		provider.config.ErrorStatusCode,
+		provider.GetProviderKey(),
+		schemas.Ptr("mock_error"),
+		nil,
+	)
+	bifrostErr.ExtraFields.RequestType = requestType
+	bifrostErr.ExtraFields.ModelRequested = model
+	return bifrostErr
+}
+
+// simulate waits the time the mock config takes to generate the tokens, or fails the request at the error rate
+func (provider *MockProvider) simulate(ctx context.Context, tokens int, requestType schemas.RequestType, model string) *schemas.BifrostError {
+	if bifrostErr := provider.failRequest(requestType, model); bifrostErr != nil {
+		return bifrostErr
+	}
+	delay := time.Duration(provider.config.LatencyMs) * time.Millisecond
+	if provider.config.TokensPerSecond > 0 {
+		delay += time.Duration(float64(tokens) / provider.config.TokensPerSecond * float64(time.Second))
+	}
+	if !sleep(ctx, delay) {
+		return &schemas.BifrostError{
+			IsBifrostError: false,
+			Error: &schemas.ErrorField{
+				Type:    schemas.Ptr(schemas.RequestCancelled),
+				Message: schemas.ErrRequestCancelled,
+				Error:   ctx.Err(),
+			},
+			ExtraFields: schemas.BifrostErrorExtraFields{
+				Provider:       provider.GetProviderKey(),
+				ModelRequested: model,
+				RequestType:    requestType,
+			},
+		}
+	}
+	return nil
+}
+
+// wait waits before the stream chunk of the token at index, the latency before the first one and the token
+// interval before the others. It returns false when the context is done.
+func (provider *MockProvider) wait(ctx context.Context, index int) bool {
+	if index == 0 {
+		return sleep(ctx, time.Duration(provider.config.LatencyMs)*time.Millisecond)
+	}
+	if provider.config.TokensPerSecond <= 0 {
+		return ctx.Err() == nil
+	}
+	return sleep(ctx, time.Duration(float64(time.Second)/provider.config.TokensPerSecond))
+}
+
+// sleep waits for the duration, it returns false when the context is done before
+func sleep(ctx context.Context, duration time.Duration) bool {
+	if duration <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// usage returns the usage of a synthetic response
+func usage(promptTokens, completionTokens int) *schemas.BifrostLLMUsage {
+	return &schemas.BifrostLLMUsage{
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      promptTokens + completionTokens,
+	}
+}
+
+// countTokens approximates the tokens of the text by its words
+func countTokens(text string) int {
+	return len(strings.Fields(text))
+}
+
+// textCompletionPrompt returns the text of the prompts
+func textCompletionPrompt(input *schemas.TextCompletionInput) string {
+	if input == nil {
+		return ""
+	}
+	if input.PromptStr != nil {
+		return *input.PromptStr
+	}
+	return strings.Join(input.PromptArray, "\n")
+}
+
+// chatPrompt returns the text of the messages
+func chatPrompt(messages []schemas.ChatMessage) string {
+	texts := make([]string, 0, len(messages))
+	for _, message := range messages {
+		texts = append(texts, chatMessageText(message))
+	}
+	return strings.Join(texts, "\n")
+}
+
+// lastChatMessageText returns the text of the last message, echoed in echo mode
+func lastChatMessageText(messages []schemas.ChatMessage) string {
+	if len(messages) == 0 {
+		return ""
+	}
+	return chatMessageText(messages[len(messages)-1])
+}
+
+// chatMessageText returns the text content of the message
+func chatMessageText(message schemas.ChatMessage) string {
+	if message.Content == nil {
+		return ""
+	}
+	if message.Content.ContentStr != nil {
+		return *message.Content.ContentStr
+	}
+	var texts []string
+	for _, block := range message.Content.ContentBlocks {
+		if block.Text != nil {
+			texts = append(texts, *block.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// embedding returns a unit vector of the dimensions seeded by the hash of the text
+func embedding(text string, dimensions int) []float32 {
+	hash := fnv.New64a()
+	hash.Write([]byte(text))
+	generator := rand.New(rand.NewSource(int64(hash.Sum64())))
+
+	vector := make([]float32, dimensions)
+	var norm float64
+	for i := range vector {
+		value := generator.NormFloat64()
+		vector[i] = float32(value)
+		norm += value * value
+	}
+	if norm > 0 {
+		scale := float32(1 / math.Sqrt(norm))
+		for i := range vector {
+			vector[i] *= scale
+		}
+	}
+	return vector
+}
@@ -0,0 +1,100 @@
+package mock_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/providers/mock"
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+func newMockProvider(t *testing.T, config *schemas.MockConfig) *mock.MockProvider {
+	t.Helper()
+	provider, err := mock.NewMockProvider(&schemas.ProviderConfig{MockConfig: config}, bifrost.NewDefaultLogger(schemas.LogLevelError))
+	if err != nil {
+		t.Fatalf("failed to create the mock provider: %v", err)
+	}
+	return provider
+}
+
+func chatRequest(text string) *schemas.BifrostChatRequest {
+	return &schemas.BifrostChatRequest{
+		Provider: schemas.Mock,
+		Model:    "mock-model",
+		Input: []schemas.ChatMessage{{
+			Role:    schemas.ChatMessageRoleUser,
+			Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr(text)},
+		}},
+	}
+}
+
+func passThroughPostHooks(ctx *context.Context, result *schemas.BifrostResponse, err *schemas.BifrostError) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return result, err
+}
+
+func TestMockChatCompletion(t *testing.T) {
+	provider := newMockProvider(t, &schemas.MockConfig{EchoInput: true})
+
+	resp, bifrostErr := provider.ChatCompletion(context.Background(), schemas.Key{}, chatRequest("echo these four words"))
+	if bifrostErr != nil {
+		t.Fatalf("chat completion failed: %v", bifrostErr.Error.Message)
+	}
+	content := resp.Choices[0].ChatNonStreamResponseChoice.Message.Content.ContentStr
+	if content == nil || *content != "echo these four words" {
+		t.Errorf("expected the input to be echoed, got %v", content)
+	}
+	if resp.Usage.PromptTokens != 4 || resp.Usage.CompletionTokens != 4 || resp.Usage.TotalTokens != 8 {
+		t.Errorf("expected the usage to count the words, got %+v", resp.Usage)
+	}
+}
+
+func TestMockChatCompletionStream(t *testing.T) {
+	provider := newMockProvider(t, &schemas.MockConfig{Response: "one two three", TokensPerSecond: 100})
+
+	startTime := time.Now()
+	stream, bifrostErr := provider.ChatCompletionStream(context.Background(), passThroughPostHooks, schemas.Key{}, chatRequest("hello"))
+	if bifrostErr != nil {
+		t.Fatalf("chat completion stream failed: %v", bifrostErr.Error.Message)
+	}
+	var content strings.Builder
+	var chunks int
+	var usage *schemas.BifrostLLMUsage
+	for chunk := range stream {
+		if chunk.BifrostError != nil {
+			t.Fatalf("unexpected stream error: %v", chunk.BifrostError.Error.Message)
+		}
+		chunks++
+		if delta := chunk.BifrostChatResponse.Choices[0].ChatStreamResponseChoice.Delta; delta.Content != nil {
+			content.WriteString(*delta.Content)
+		}
+		if chunk.BifrostChatResponse.Usage != nil {
+			usage = chunk.BifrostChatResponse.Usage
+		}
+	}
+	if content.String() != "one two three" || chunks != 4 {
+		t.Errorf("expected three token chunks and a final chunk, got %d chunks of %q", chunks, content.String())
+	}
+	if usage == nil || usage.CompletionTokens != 3 {
+		t.Errorf("expected the usage in the final chunk, got %+v", usage)
+	}
+	// Two token intervals at 100 tokens per second
+	if elapsed := time.Since(startTime); elapsed < 20*time.Millisecond {
+		t.Errorf("expected the tokens to be generated at the token rate, the stream took %v", elapsed)
+	}
+}
+
+func TestMockErrorRate(t *testing.T) {
+	provider := newMockProvider(t, &schemas.MockConfig{ErrorRate: 1, ErrorStatusCode: 429})
+
+	_, bifrostErr := provider.ChatCompletion(context.Background(), schemas.Key{}, chatRequest("hello"))
+	if bifrostErr == nil || bifrostErr.StatusCode == nil || *bifrostErr.StatusCode != 429 {
+		t.Fatalf("expected a synthetic 429 error, got %+v", bifrostErr)
+	}
+
+	if _, err := mock.NewMockProvider(&schemas.ProviderConfig{MockConfig: &schemas.MockConfig{ErrorRate: 1.5}}, nil); err == nil {
+		t.Error("expected an error rate above 1 to be rejected")
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/maximhq/bifrost/core/providers/openai"
 	providerUtils "github.com/maximhq/bifrost/core/providers/utils"
@@ -28,22 +27,13 @@ type OllamaProvider struct {
 func NewOllamaProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*OllamaProvider, error) {
 	config.CheckAndSetDefaults()
 
-	client := &fasthttp.Client{
-		ReadTimeout:         time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
-		WriteTimeout:        time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
-		MaxConnsPerHost:     5000,
-		MaxIdleConnDuration: 60 * time.Second,
-		MaxConnWaitTimeout:  10 * time.Second,
-	}
+	client := providerUtils.NewHTTPClient(config, logger)
 
 	// // Pre-warm response pools
 	// for range config.ConcurrencyAndBufferSize.Concurrency {
 	// 	ollamaResponsePool.Put(&schemas.BifrostResponse{})
 	// }
 
-	// Configure proxy if provided
-	client = providerUtils.ConfigureProxy(client, config.ProxyConfig, logger)
-
 	config.NetworkConfig.BaseURL = strings.TrimRight(config.NetworkConfig.BaseURL, "/")
 
 	// BaseURL is required for Ollama
@@ -51,6 +41,8 @@ func NewOllamaProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*
 		return nil, fmt.Errorf("base_url is required for ollama provider")
 	}
 
+	providerUtils.WarmUpConnections(client, config.NetworkConfig, logger)
+
 	return &OllamaProvider{
 		logger:              logger,
 		client:              client,
@@ -1,6 +1,11 @@
 package openai
 
 import (
+	"bytes"
+	"hash/fnv"
+	"strings"
+
+	"github.com/bytedance/sonic"
 	"github.com/maximhq/bifrost/core/schemas"
 )
 
@@ -25,18 +30,29 @@ func ToOpenAIChatRequest(bifrostReq *schemas.BifrostChatRequest) *OpenAIChatRequ
 
 	openaiReq := &OpenAIChatRequest{
 		Model:    bifrostReq.Model,
-		Messages: bifrostReq.Input,
+		Messages: schemas.NormalizeInputAudio(schemas.NormalizeToolCalls(bifrostReq.Input)),
 	}
 
 	if bifrostReq.Params != nil {
 		openaiReq.ChatParameters = *bifrostReq.Params
 	}
+	openaiReq.normalizeToolChoice()
+
+	// OpenRouter forwards cache breakpoints to the providers with explicit prompt caching
+	if bifrostReq.Provider != schemas.OpenRouter {
+		openaiReq.filterCacheControl()
+	}
+
+	// Reasoning budgets aren't part of the OpenAI API, only Gemini models accept them through extra_body
+	reasoningMaxTokens := openaiReq.ReasoningMaxTokens
+	openaiReq.ReasoningMaxTokens = nil
 
 	switch bifrostReq.Provider {
 	case schemas.OpenAI:
 		return openaiReq
 	case schemas.Gemini:
 		openaiReq.filterOpenAISpecificParameters()
+		openaiReq.applyGeminiThinkingBudget(reasoningMaxTokens)
 		// Removing extra parameters that are not supported by Gemini
 		openaiReq.ServiceTier = nil
 		return openaiReq
@@ -51,6 +67,9 @@ func ToOpenAIChatRequest(bifrostReq *schemas.BifrostChatRequest) *OpenAIChatRequ
 		if schemas.IsMistralModel(bifrostReq.Model) {
 			openaiReq.applyMistralCompatibility()
 		}
+		if strings.Contains(strings.ToLower(bifrostReq.Model), "gemini") {
+			openaiReq.applyGeminiThinkingBudget(reasoningMaxTokens)
+		}
 		return openaiReq
 	default:
 		openaiReq.filterOpenAISpecificParameters()
@@ -74,6 +93,113 @@ func (request *OpenAIChatRequest) filterOpenAISpecificParameters() {
 	}
 }
 
+// filterCacheControl removes the prompt caching breakpoints, which OpenAI compatible APIs don't accept
+// (OpenAI caches prompts automatically). The messages and tools are copied before they are modified,
+// so the Bifrost request keeps its breakpoints for fallbacks to providers supporting them.
+func (request *OpenAIChatRequest) filterCacheControl() {
+	copied := false
+	for i, msg := range request.Messages {
+		if msg.Content == nil || !hasCacheControl(msg.Content.ContentBlocks) {
+			continue
+		}
+		if !copied {
+			request.Messages = append([]schemas.ChatMessage(nil), request.Messages...)
+			copied = true
+		}
+		blocks := make([]schemas.ChatContentBlock, len(msg.Content.ContentBlocks))
+		for j, block := range msg.Content.ContentBlocks {
+			block.CacheControl = nil
+			blocks[j] = block
+		}
+		content := *msg.Content
+		content.ContentBlocks = blocks
+		request.Messages[i].Content = &content
+	}
+
+	for i, tool := range request.Tools {
+		if tool.CacheControl == nil {
+			continue
+		}
+		tools := make([]schemas.ChatTool, len(request.Tools))
+		copy(tools, request.Tools)
+		for j := i; j < len(tools); j++ {
+			tools[j].CacheControl = nil
+		}
+		request.Tools = tools
+		break
+	}
+}
+
+// hasCacheControl reports whether any of the content blocks is a prompt caching breakpoint.
+func hasCacheControl(blocks []schemas.ChatContentBlock) bool {
+	for _, block := range blocks {
+		if block.CacheControl != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// applyGeminiThinkingBudget sets the thinking budget of Gemini models, which the OpenAI compatible
+// API of Gemini accepts in extra_body instead of reasoning_effort.
+func (request *OpenAIChatRequest) applyGeminiThinkingBudget(budget *int) {
+	if budget == nil {
+		return
+	}
+	// reasoning_effort and thinking_budget can't be set together
+	request.ReasoningEffort = nil
+	request.ExtraBody = map[string]interface{}{
+		"google": map[string]interface{}{
+			"thinking_config": map[string]interface{}{
+				"thinking_budget": *budget,
+			},
+		},
+	}
+}
+
+// applyReasoningContent sets the thought of the choices from the reasoning_content or reasoning fields
+// of OpenAI compatible reasoning models. The body is only parsed again when it carries reasoning.
+func applyReasoningContent(body []byte, response *schemas.BifrostChatResponse) {
+	if !bytes.Contains(body, []byte(`"reasoning_content"`)) && !bytes.Contains(body, []byte(`"reasoning":`)) {
+		return
+	}
+	var reasoningResponse openAIReasoningResponse
+	if err := sonic.Unmarshal(body, &reasoningResponse); err != nil {
+		return
+	}
+	for i, choice := range reasoningResponse.Choices {
+		if i >= len(response.Choices) {
+			break
+		}
+		target := &response.Choices[i]
+		if thought := choice.Message.thought(); thought != nil && target.ChatNonStreamResponseChoice != nil && target.Message != nil {
+			if target.Message.ChatAssistantMessage == nil {
+				target.Message.ChatAssistantMessage = &schemas.ChatAssistantMessage{}
+			}
+			if target.Message.ChatAssistantMessage.Thought == nil {
+				target.Message.ChatAssistantMessage.Thought = thought
+			}
+		}
+		if thought := choice.Delta.thought(); thought != nil && target.ChatStreamResponseChoice != nil && target.Delta != nil && target.Delta.Thought == nil {
+			target.Delta.Thought = thought
+		}
+	}
+}
+
+// thought returns the reasoning of the fields, nil if there is none.
+func (fields *openAIReasoningFields) thought() *string {
+	if fields == nil {
+		return nil
+	}
+	if fields.ReasoningContent != nil && *fields.ReasoningContent != "" {
+		return fields.ReasoningContent
+	}
+	if fields.Reasoning != nil && *fields.Reasoning != "" {
+		return fields.Reasoning
+	}
+	return nil
+}
+
 // applyMistralCompatibility applies Mistral-specific transformations to the request
 func (request *OpenAIChatRequest) applyMistralCompatibility() {
 	// Mistral uses max_tokens instead of max_completion_tokens
@@ -82,9 +208,81 @@ func (request *OpenAIChatRequest) applyMistralCompatibility() {
 		request.MaxCompletionTokens = nil
 	}
 
-	// Mistral does not support ToolChoiceStruct, only simple tool choice strings are supported
+	// Mistral does not support ToolChoiceStruct, only simple tool choice strings are supported.
+	// The tool choice is shared with the Bifrost request, so it is replaced rather than modified.
 	if request.ToolChoice != nil && request.ToolChoice.ChatToolChoiceStruct != nil {
-		request.ToolChoice.ChatToolChoiceStr = schemas.Ptr("any")
-		request.ToolChoice.ChatToolChoiceStruct = nil
+		request.ToolChoice = &schemas.ChatToolChoice{ChatToolChoiceStr: schemas.Ptr("any")}
+	}
+
+	// Mistral only accepts tool call IDs of 9 alphanumeric characters
+	copied := false
+	for i, msg := range request.Messages {
+		if msg.ChatAssistantMessage != nil && len(msg.ChatAssistantMessage.ToolCalls) > 0 {
+			if !copied {
+				request.Messages = append([]schemas.ChatMessage(nil), request.Messages...)
+				copied = true
+			}
+			assistantMessage := *msg.ChatAssistantMessage
+			assistantMessage.ToolCalls = append([]schemas.ChatAssistantMessageToolCall(nil), msg.ChatAssistantMessage.ToolCalls...)
+			for j, toolCall := range assistantMessage.ToolCalls {
+				if toolCall.ID != nil {
+					assistantMessage.ToolCalls[j].ID = schemas.Ptr(mistralToolCallID(*toolCall.ID))
+				}
+			}
+			request.Messages[i].ChatAssistantMessage = &assistantMessage
+		} else if msg.ChatToolMessage != nil && msg.ChatToolMessage.ToolCallID != nil {
+			if !copied {
+				request.Messages = append([]schemas.ChatMessage(nil), request.Messages...)
+				copied = true
+			}
+			request.Messages[i].ChatToolMessage = &schemas.ChatToolMessage{ToolCallID: schemas.Ptr(mistralToolCallID(*msg.ChatToolMessage.ToolCallID))}
+		}
+	}
+}
+
+// mistralToolCallID maps a tool call ID to 9 alphanumeric characters, keeping the IDs that already are.
+// The mapping is deterministic, so a tool call and its result keep matching IDs.
+func mistralToolCallID(id string) string {
+	const alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	if len(id) == 9 && strings.Trim(id, alphabet) == "" {
+		return id
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(id))
+	sum := hash.Sum64()
+	mapped := make([]byte, 9)
+	for i := range mapped {
+		mapped[i] = alphabet[sum%uint64(len(alphabet))]
+		sum /= uint64(len(alphabet))
+	}
+	return string(mapped)
+}
+
+// normalizeToolChoice converts the tool choice to the semantics of OpenAI: "any" is "required", the struct form
+// is only used to name a tool, and tool_choice and parallel_tool_calls are left out without tools since OpenAI
+// rejects them. The tool choice is replaced rather than modified, it is shared with the Bifrost request.
+func (request *OpenAIChatRequest) normalizeToolChoice() {
+	if len(request.Tools) == 0 {
+		request.ToolChoice = nil
+		request.ParallelToolCalls = nil
+		return
+	}
+	if request.ToolChoice == nil {
+		return
+	}
+
+	var choiceType schemas.ChatToolChoiceType
+	if request.ToolChoice.ChatToolChoiceStr != nil {
+		choiceType = schemas.ChatToolChoiceType(*request.ToolChoice.ChatToolChoiceStr)
+	} else if request.ToolChoice.ChatToolChoiceStruct != nil {
+		choiceType = request.ToolChoice.ChatToolChoiceStruct.Type
+	}
+	switch choiceType {
+	case schemas.ChatToolChoiceTypeAny:
+		request.ToolChoice = &schemas.ChatToolChoice{ChatToolChoiceStr: schemas.Ptr(string(schemas.ChatToolChoiceTypeRequired))}
+	case schemas.ChatToolChoiceTypeNone, schemas.ChatToolChoiceTypeAuto, schemas.ChatToolChoiceTypeRequired:
+		if request.ToolChoice.ChatToolChoiceStruct != nil {
+			request.ToolChoice = &schemas.ChatToolChoice{ChatToolChoiceStr: schemas.Ptr(string(choiceType))}
+		}
 	}
 }
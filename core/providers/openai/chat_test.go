@@ -0,0 +1,116 @@
+package openai
+
+import (
+	"testing"
+
+	"github.com/bytedance/sonic"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyReasoningContent(t *testing.T) {
+	t.Run("Message", func(t *testing.T) {
+		body := []byte(`{"id":"1","choices":[{"index":0,"message":{"role":"assistant","content":"4","reasoning_content":"2+2=4"}}]}`)
+		var response schemas.BifrostChatResponse
+		require.NoError(t, sonic.Unmarshal(body, &response))
+
+		applyReasoningContent(body, &response)
+		require.NotNil(t, response.Choices[0].Message.ChatAssistantMessage)
+		assert.Equal(t, "2+2=4", *response.Choices[0].Message.ChatAssistantMessage.Thought)
+	})
+
+	t.Run("Delta", func(t *testing.T) {
+		body := []byte(`{"id":"1","choices":[{"index":0,"delta":{"reasoning":"thinking"}}]}`)
+		var response schemas.BifrostChatResponse
+		require.NoError(t, sonic.Unmarshal(body, &response))
+
+		applyReasoningContent(body, &response)
+		assert.Equal(t, "thinking", *response.Choices[0].Delta.Thought)
+	})
+}
+
+func TestToOpenAIChatRequestReasoningMaxTokens(t *testing.T) {
+	bifrostReq := &schemas.BifrostChatRequest{
+		Model: "gemini-2.5-flash",
+		Input: []schemas.ChatMessage{{Role: schemas.ChatMessageRoleUser, Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr("Hello")}}},
+		Params: &schemas.ChatParameters{
+			ReasoningEffort:    schemas.Ptr("high"),
+			ReasoningMaxTokens: schemas.Ptr(2048),
+		},
+	}
+
+	bifrostReq.Provider = schemas.OpenAI
+	openaiReq := ToOpenAIChatRequest(bifrostReq)
+	assert.Nil(t, openaiReq.ReasoningMaxTokens)
+	assert.Equal(t, "high", *openaiReq.ReasoningEffort)
+	assert.Nil(t, openaiReq.ExtraBody)
+
+	bifrostReq.Provider = schemas.Gemini
+	openaiReq = ToOpenAIChatRequest(bifrostReq)
+	assert.Nil(t, openaiReq.ReasoningMaxTokens)
+	assert.Nil(t, openaiReq.ReasoningEffort)
+	assert.Equal(t, map[string]interface{}{
+		"google": map[string]interface{}{"thinking_config": map[string]interface{}{"thinking_budget": 2048}},
+	}, openaiReq.ExtraBody)
+	assert.Equal(t, 2048, *bifrostReq.Params.ReasoningMaxTokens, "the Bifrost request must not be modified")
+}
+
+func TestToOpenAIChatRequestToolChoice(t *testing.T) {
+	tools := []schemas.ChatTool{{
+		Type:     schemas.ChatToolTypeFunction,
+		Function: &schemas.ChatToolFunction{Name: "get_weather"},
+	}}
+	input := []schemas.ChatMessage{{Role: schemas.ChatMessageRoleUser, Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr("Weather?")}}}
+
+	t.Run("Any", func(t *testing.T) {
+		toolChoice := &schemas.ChatToolChoice{ChatToolChoiceStruct: &schemas.ChatToolChoiceStruct{Type: schemas.ChatToolChoiceTypeAny}}
+		openaiReq := ToOpenAIChatRequest(&schemas.BifrostChatRequest{
+			Provider: schemas.OpenAI,
+			Input:    input,
+			Params:   &schemas.ChatParameters{Tools: tools, ToolChoice: toolChoice},
+		})
+		require.NotNil(t, openaiReq.ToolChoice.ChatToolChoiceStr)
+		assert.Equal(t, "required", *openaiReq.ToolChoice.ChatToolChoiceStr)
+		assert.NotNil(t, toolChoice.ChatToolChoiceStruct, "the bifrost request must not be modified")
+	})
+
+	t.Run("WithoutTools", func(t *testing.T) {
+		openaiReq := ToOpenAIChatRequest(&schemas.BifrostChatRequest{
+			Provider: schemas.OpenAI,
+			Input:    input,
+			Params: &schemas.ChatParameters{
+				ToolChoice:        &schemas.ChatToolChoice{ChatToolChoiceStr: schemas.Ptr("auto")},
+				ParallelToolCalls: schemas.Ptr(false),
+			},
+		})
+		assert.Nil(t, openaiReq.ToolChoice)
+		assert.Nil(t, openaiReq.ParallelToolCalls)
+	})
+}
+
+func TestApplyMistralCompatibilityToolCallIDs(t *testing.T) {
+	input := []schemas.ChatMessage{
+		{
+			Role: schemas.ChatMessageRoleAssistant,
+			ChatAssistantMessage: &schemas.ChatAssistantMessage{
+				ToolCalls: []schemas.ChatAssistantMessageToolCall{{
+					ID:       schemas.Ptr("toolu_01A09q90qw90lq917835lq9"),
+					Function: schemas.ChatAssistantMessageToolCallFunction{Name: schemas.Ptr("get_weather"), Arguments: "{}"},
+				}},
+			},
+		},
+		{
+			Role:            schemas.ChatMessageRoleTool,
+			Content:         &schemas.ChatMessageContent{ContentStr: schemas.Ptr("sunny")},
+			ChatToolMessage: &schemas.ChatToolMessage{ToolCallID: schemas.Ptr("toolu_01A09q90qw90lq917835lq9")},
+		},
+	}
+	openaiReq := ToOpenAIChatRequest(&schemas.BifrostChatRequest{Provider: schemas.Mistral, Input: input})
+
+	id := *openaiReq.Messages[0].ChatAssistantMessage.ToolCalls[0].ID
+	assert.Len(t, id, 9)
+	assert.Equal(t, id, *openaiReq.Messages[1].ChatToolMessage.ToolCallID)
+	assert.Equal(t, "toolu_01A09q90qw90lq917835lq9", *input[0].ChatAssistantMessage.ToolCalls[0].ID, "the bifrost request must not be modified")
+	assert.Equal(t, "abcDEF123", mistralToolCallID("abcDEF123"))
+}
@@ -33,6 +33,8 @@ func ToOpenAIEmbeddingRequest(bifrostReq *schemas.BifrostEmbeddingRequest) *Open
 	// Map parameters
 	if params != nil {
 		openaiReq.EmbeddingParameters = *params
+		// OpenAI embeddings are normalized, the option is applied by Bifrost for other providers of the API
+		openaiReq.EmbeddingParameters.Normalize = nil
 	}
 
 	return openaiReq
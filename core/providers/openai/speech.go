@@ -33,7 +33,21 @@ func ToOpenAISpeechRequest(bifrostReq *schemas.BifrostSpeechRequest) *OpenAISpee
 
 	if params != nil {
 		openaiReq.SpeechParameters = *params
+		// OpenAI synthesizes at a fixed sample rate
+		openaiReq.SampleRate = nil
+		if params.VoiceConfig != nil && params.VoiceConfig.Voice != nil {
+			voice := schemas.ResolveSpeechVoice(schemas.OpenAI, *params.VoiceConfig.Voice)
+			openaiReq.VoiceConfig = &schemas.SpeechVoiceInput{Voice: &voice}
+		}
 	}
 
 	return openaiReq
 }
+
+// SpeechResponseFormat returns the format of the audio OpenAI returns for the request, "mp3" by default
+func SpeechResponseFormat(bifrostReq *schemas.BifrostSpeechRequest) string {
+	if bifrostReq.Params == nil || bifrostReq.Params.ResponseFormat == "" {
+		return schemas.SpeechFormatMP3
+	}
+	return bifrostReq.Params.ResponseFormat
+}
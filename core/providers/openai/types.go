@@ -49,6 +49,10 @@ type OpenAIChatRequest struct {
 	// This Field is populated only for such providers and is NOT to be used externally.
 	MaxTokens *int `json:"max_tokens,omitempty"`
 
+	//NOTE: ExtraBody carries provider specific options of OpenAI compatible APIs (the Gemini thinking budget).
+	// This Field is populated only for such providers and is NOT to be used externally.
+	ExtraBody map[string]interface{} `json:"extra_body,omitempty"`
+
 	// Bifrost specific field (only parsed when converting from Provider -> Bifrost request)
 	Fallbacks []string `json:"fallbacks,omitempty"`
 }
@@ -58,6 +62,20 @@ func (r *OpenAIChatRequest) IsStreamingRequested() bool {
 	return r.Stream != nil && *r.Stream
 }
 
+// openAIReasoningResponse captures the reasoning of OpenAI compatible reasoning models, which return it
+// in reasoning_content (DeepSeek, vLLM) or reasoning (OpenRouter, Groq) instead of the thought field.
+type openAIReasoningResponse struct {
+	Choices []struct {
+		Message *openAIReasoningFields `json:"message,omitempty"`
+		Delta   *openAIReasoningFields `json:"delta,omitempty"`
+	} `json:"choices"`
+}
+
+type openAIReasoningFields struct {
+	ReasoningContent *string `json:"reasoning_content,omitempty"`
+	Reasoning        *string `json:"reasoning,omitempty"`
+}
+
 // ResponsesRequestInput is a union of string and array of responses messages
 type OpenAIResponsesRequestInput struct {
 	OpenAIResponsesRequestInputStr   *string
@@ -0,0 +1,85 @@
+package openai_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/internal/vcr"
+	"github.com/maximhq/bifrost/core/providers/openai"
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestOpenAIChatCompletionCassette runs chat completions against the recorded responses of the OpenAI API.
+// Record the cassette again with BIFROST_VCR_MODE=record and OPENAI_API_KEY set.
+func TestOpenAIChatCompletionCassette(t *testing.T) {
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	server := vcr.NewServer(t, "testdata/cassettes/chat_completion.json", "https://api.openai.com", apiKey)
+	if vcr.ModeFromEnv() == vcr.ModeReplay {
+		apiKey = "sk-replay"
+	}
+
+	provider := openai.NewOpenAIProvider(&schemas.ProviderConfig{
+		NetworkConfig: schemas.NetworkConfig{BaseURL: server.URL},
+	}, bifrost.NewDefaultLogger(schemas.LogLevelError))
+	key := schemas.Key{Value: apiKey}
+
+	resp, bifrostErr := provider.ChatCompletion(context.Background(), key, &schemas.BifrostChatRequest{
+		Provider: schemas.OpenAI,
+		Model:    "gpt-4o-mini",
+		Input: []schemas.ChatMessage{{
+			Role:    schemas.ChatMessageRoleUser,
+			Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr("What is the capital of France?")},
+		}},
+	})
+	if bifrostErr != nil {
+		t.Fatalf("chat completion failed: %v", bifrostErr.Error.Message)
+	}
+	if len(resp.Choices) != 1 || resp.Choices[0].ChatNonStreamResponseChoice == nil {
+		t.Fatalf("expected a single non-stream choice, got %+v", resp.Choices)
+	}
+	message := resp.Choices[0].ChatNonStreamResponseChoice.Message
+	if message == nil || message.Content == nil || message.Content.ContentStr == nil || *message.Content.ContentStr == "" {
+		t.Errorf("expected a text answer, got %+v", message)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens == 0 {
+		t.Errorf("expected the usage to be converted, got %+v", resp.Usage)
+	}
+
+	resp, bifrostErr = provider.ChatCompletion(context.Background(), key, &schemas.BifrostChatRequest{
+		Provider: schemas.OpenAI,
+		Model:    "gpt-4o-mini",
+		Input: []schemas.ChatMessage{{
+			Role:    schemas.ChatMessageRoleUser,
+			Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr("What is the weather in Paris?")},
+		}},
+		Params: &schemas.ChatParameters{
+			Tools: []schemas.ChatTool{{
+				Type: schemas.ChatToolTypeFunction,
+				Function: &schemas.ChatToolFunction{
+					Name: "get_weather",
+					Parameters: &schemas.ToolFunctionParameters{
+						Type:       "object",
+						Properties: &schemas.OrderedMap{"location": map[string]interface{}{"type": "string"}},
+						Required:   []string{"location"},
+					},
+				},
+			}},
+		},
+	})
+	if bifrostErr != nil {
+		t.Fatalf("chat completion with tools failed: %v", bifrostErr.Error.Message)
+	}
+	if len(resp.Choices) != 1 || resp.Choices[0].ChatNonStreamResponseChoice == nil {
+		t.Fatalf("expected a single non-stream choice, got %+v", resp.Choices)
+	}
+	message = resp.Choices[0].ChatNonStreamResponseChoice.Message
+	if message == nil || message.ChatAssistantMessage == nil || len(message.ChatAssistantMessage.ToolCalls) != 1 {
+		t.Fatalf("expected a single tool call, got %+v", message)
+	}
+	toolCall := message.ChatAssistantMessage.ToolCalls[0]
+	if toolCall.Function.Name == nil || *toolCall.Function.Name != "get_weather" || toolCall.Function.Arguments == "" {
+		t.Errorf("expected a get_weather call with arguments, got %+v", toolCall.Function)
+	}
+}
@@ -185,9 +185,11 @@ func (response *PerplexityChatResponse) ToBifrostChatResponse(model string) *sch
 		ExtraFields: schemas.BifrostResponseExtraFields{
 			RequestType: schemas.ChatCompletionRequest,
 			Provider:    schemas.Perplexity,
+			Sources:     ToBifrostSources(response.Citations, response.SearchResults),
 		},
 		SearchResults: response.SearchResults,
 		Videos:        response.Videos,
+		Citations:     response.Citations,
 	}
 
 	// Map all response fields
@@ -235,3 +237,47 @@ func (response *PerplexityChatResponse) ToBifrostChatResponse(model string) *sch
 
 	return bifrostResponse
 }
+
+// ToBifrostSources merges the citations and search results of a Perplexity response into sources.
+// Cited sources come first, in citation order, enriched with the search result of the same URL.
+// Search results that are not cited follow with a zero index.
+func ToBifrostSources(citations []string, searchResults []schemas.SearchResult) []schemas.BifrostSource {
+	if len(citations) == 0 && len(searchResults) == 0 {
+		return nil
+	}
+
+	resultsByURL := make(map[string]*schemas.SearchResult, len(searchResults))
+	for i := range searchResults {
+		if _, ok := resultsByURL[searchResults[i].URL]; !ok {
+			resultsByURL[searchResults[i].URL] = &searchResults[i]
+		}
+	}
+
+	sources := make([]schemas.BifrostSource, 0, max(len(citations), len(searchResults)))
+	cited := make(map[string]bool, len(citations))
+	for i, url := range citations {
+		source := schemas.BifrostSource{Index: i + 1, URL: url}
+		if result, ok := resultsByURL[url]; ok {
+			source.Title = result.Title
+			source.Snippet = result.Snippet
+			source.Date = result.Date
+			source.LastUpdated = result.LastUpdated
+		}
+		cited[url] = true
+		sources = append(sources, source)
+	}
+	for _, result := range searchResults {
+		if cited[result.URL] {
+			continue
+		}
+		cited[result.URL] = true
+		sources = append(sources, schemas.BifrostSource{
+			URL:         result.URL,
+			Title:       result.Title,
+			Snippet:     result.Snippet,
+			Date:        result.Date,
+			LastUpdated: result.LastUpdated,
+		})
+	}
+	return sources
+}
@@ -29,16 +29,7 @@ type PerplexityProvider struct {
 func NewPerplexityProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*PerplexityProvider, error) {
 	config.CheckAndSetDefaults()
 
-	client := &fasthttp.Client{
-		ReadTimeout:         time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
-		WriteTimeout:        time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
-		MaxConnsPerHost:     5000,
-		MaxIdleConnDuration: 60 * time.Second,
-		MaxConnWaitTimeout:  10 * time.Second,
-	}
-
-	// Configure proxy if provided
-	client = providerUtils.ConfigureProxy(client, config.ProxyConfig, logger)
+	client := providerUtils.NewHTTPClient(config, logger)
 
 	// Set default BaseURL if not provided
 	if config.NetworkConfig.BaseURL == "" {
@@ -46,6 +37,8 @@ func NewPerplexityProvider(config *schemas.ProviderConfig, logger schemas.Logger
 	}
 	config.NetworkConfig.BaseURL = strings.TrimRight(config.NetworkConfig.BaseURL, "/")
 
+	providerUtils.WarmUpConnections(client, config.NetworkConfig, logger)
+
 	return &PerplexityProvider{
 		logger:              logger,
 		client:              client,
@@ -175,6 +168,18 @@ func (provider *PerplexityProvider) ChatCompletionStream(ctx context.Context, po
 		reqBody.Stream = schemas.Ptr(true)
 		return reqBody, nil
 	}
+	// Perplexity sends the citations and search results with the content chunks, they are
+	// mapped to sources on those chunks and repeated on the final chunk of the stream
+	var sources []schemas.BifrostSource
+	postResponseConverter := func(response *schemas.BifrostChatResponse) *schemas.BifrostChatResponse {
+		if chunkSources := ToBifrostSources(response.Citations, response.SearchResults); chunkSources != nil {
+			sources = chunkSources
+			response.ExtraFields.Sources = chunkSources
+		} else if len(response.Choices) > 0 && response.Choices[0].FinishReason != nil {
+			response.ExtraFields.Sources = sources
+		}
+		return response
+	}
 	// Use shared OpenAI-compatible streaming logic
 	return openai.HandleOpenAIChatCompletionStreaming(
 		ctx,
@@ -188,7 +193,7 @@ func (provider *PerplexityProvider) ChatCompletionStream(ctx context.Context, po
 		postHookRunner,
 		customRequestConverter,
 		nil,
-		nil,
+		postResponseConverter,
 		provider.logger,
 	)
 }
@@ -6,6 +6,9 @@ import (
 	"testing"
 
 	"github.com/maximhq/bifrost/core/internal/testutil"
+	"github.com/maximhq/bifrost/core/providers/perplexity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/maximhq/bifrost/core/schemas"
 )
@@ -50,3 +53,25 @@ func TestPerplexity(t *testing.T) {
 	})
 	client.Shutdown()
 }
+
+func TestPerplexityResponseSources(t *testing.T) {
+	response := &perplexity.PerplexityChatResponse{
+		ID:        "resp-1",
+		Citations: []string{"https://example.com/a", "https://example.com/b"},
+		SearchResults: []schemas.SearchResult{
+			{Title: "Page C", URL: "https://example.com/c"},
+			{Title: "Page A", URL: "https://example.com/a", Snippet: schemas.Ptr("About A"), Date: schemas.Ptr("2025-01-01")},
+		},
+	}
+
+	bifrostResponse := response.ToBifrostChatResponse("sonar")
+	require.NotNil(t, bifrostResponse)
+	assert.Equal(t, response.Citations, bifrostResponse.Citations)
+	assert.Equal(t, []schemas.BifrostSource{
+		{Index: 1, URL: "https://example.com/a", Title: "Page A", Snippet: schemas.Ptr("About A"), Date: schemas.Ptr("2025-01-01")},
+		{Index: 2, URL: "https://example.com/b"},
+		{URL: "https://example.com/c", Title: "Page C"},
+	}, bifrostResponse.ExtraFields.Sources)
+
+	assert.Nil(t, (&perplexity.PerplexityChatResponse{ID: "resp-2"}).ToBifrostChatResponse("sonar").ExtraFields.Sources)
+}
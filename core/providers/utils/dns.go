@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// dnsCacheEntry holds the addresses resolved for a host
+type dnsCacheEntry struct {
+	addrs     []net.IPAddr
+	expiresAt time.Time
+}
+
+// dnsResolver resolves host names with the static hosts of the DNS config first, then with the system resolver.
+// Resolved addresses are reused for the cache TTL, and still used once expired if resolving them again fails.
+type dnsResolver struct {
+	staticHosts map[string][]net.IPAddr
+	ttl         time.Duration
+
+	mu    sync.Mutex
+	cache map[string]dnsCacheEntry
+}
+
+// newDNSResolver creates the resolver of the DNS config
+func newDNSResolver(config *schemas.DNSConfig) *dnsResolver {
+	staticHosts := make(map[string][]net.IPAddr, len(config.StaticHosts))
+	for host, addresses := range config.StaticHosts {
+		for _, address := range addresses {
+			if ip := net.ParseIP(address); ip != nil {
+				staticHosts[host] = append(staticHosts[host], net.IPAddr{IP: ip})
+			}
+		}
+	}
+	return &dnsResolver{
+		staticHosts: staticHosts,
+		ttl:         config.GetCacheTTL(),
+		cache:       make(map[string]dnsCacheEntry),
+	}
+}
+
+// LookupIPAddr returns the addresses of the host, it implements fasthttp.Resolver
+func (r *dnsResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	if addrs, ok := r.staticHosts[host]; ok {
+		return addrs, nil
+	}
+
+	now := time.Now()
+	r.mu.Lock()
+	entry, cached := r.cache[host]
+	r.mu.Unlock()
+	if cached && now.Before(entry.expiresAt) {
+		return entry.addrs, nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		if cached {
+			// Keep using the last known addresses while the DNS is failing
+			return entry.addrs, nil
+		}
+		if err == nil {
+			err = &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.cache[host] = dnsCacheEntry{addrs: addrs, expiresAt: now.Add(r.ttl)}
+	r.mu.Unlock()
+	return addrs, nil
+}
+
+// dialContext returns a net/http dial function resolving host names with the resolver. Without happy eyeballs,
+// the addresses are dialed one after the other; otherwise IPv6 and IPv4 addresses are raced as net.Dialer does.
+func (r *dnsResolver) dialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		if net.ParseIP(host) != nil {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		addrs, err := r.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		if dialer.FallbackDelay >= 0 {
+			if conn, ok := dialHappyEyeballs(ctx, dialer, network, addrs, port); ok {
+				return conn, nil
+			}
+		}
+		var errs []error
+		for _, ipAddr := range addrs {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ipAddr.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			errs = append(errs, err)
+		}
+		return nil, errors.Join(errs...)
+	}
+}
+
+// dialHappyEyeballs races a connection to the first IPv6 address with a connection to the first IPv4 address
+// started after the fallback delay, and returns the first established one. It returns false if both fail or
+// if the addresses are of a single family, leaving the addresses to be dialed in order.
+func dialHappyEyeballs(ctx context.Context, dialer *net.Dialer, network string, addrs []net.IPAddr, port string) (net.Conn, bool) {
+	var primary, fallback *net.IPAddr
+	for i := range addrs {
+		if addrs[i].IP.To4() == nil && primary == nil {
+			primary = &addrs[i]
+		} else if addrs[i].IP.To4() != nil && fallback == nil {
+			fallback = &addrs[i]
+		}
+	}
+	if primary == nil || fallback == nil {
+		return nil, false
+	}
+	fallbackDelay := dialer.FallbackDelay
+	if fallbackDelay == 0 {
+		fallbackDelay = 300 * time.Millisecond
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type dialResult struct {
+		conn net.Conn
+		err  error
+	}
+	results := make(chan dialResult, 2)
+	dial := func(ipAddr *net.IPAddr, delay time.Duration) {
+		if delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				results <- dialResult{err: ctx.Err()}
+				return
+			}
+		}
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ipAddr.String(), port))
+		results <- dialResult{conn: conn, err: err}
+	}
+	go dial(primary, 0)
+	go dial(fallback, fallbackDelay)
+
+	for received := 1; received <= 2; received++ {
+		result := <-results
+		if result.err == nil {
+			cancel()
+			if received == 1 {
+				// Close the other connection if it gets established too
+				go func() {
+					if other := <-results; other.conn != nil {
+						other.conn.Close()
+					}
+				}()
+			}
+			return result.conn, true
+		}
+	}
+	return nil, false
+}
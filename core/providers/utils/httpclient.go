@@ -0,0 +1,206 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+// NewHTTPClient creates the HTTP client of a provider, tuned by the http client config of its network config,
+// with its proxy configured. With the http2 client type, the requests are sent by a net/http transport
+// negotiating HTTP/2, behind the same fasthttp client API.
+func NewHTTPClient(config *schemas.ProviderConfig, logger schemas.Logger) *fasthttp.Client {
+	timeout := time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds)
+	httpClientConfig := config.NetworkConfig.HTTPClient
+
+	client := &fasthttp.Client{
+		ReadTimeout:         timeout,
+		WriteTimeout:        timeout,
+		MaxConnsPerHost:     httpClientConfig.GetMaxConnsPerHost(),
+		MaxIdleConnDuration: httpClientConfig.GetMaxIdleConnDuration(),
+		MaxConnWaitTimeout:  httpClientConfig.GetMaxConnWaitTimeout(),
+	}
+	if httpClientConfig != nil {
+		client.ReadBufferSize = httpClientConfig.ReadBufferSize
+		client.WriteBufferSize = httpClientConfig.WriteBufferSize
+	}
+
+	if httpClientConfig != nil && httpClientConfig.DNS != nil {
+		dialer := &fasthttp.TCPDialer{
+			Resolver:         newDNSResolver(httpClientConfig.DNS),
+			DNSCacheDuration: httpClientConfig.DNS.GetCacheTTL(),
+		}
+		client.Dial = dialer.Dial
+	}
+
+	if httpClientConfig.IsHTTP2() {
+		client.Transport = &netHTTPRoundTripper{transport: NewNetHTTPTransport(config, logger)}
+		return client
+	}
+
+	// Configure proxy if provided
+	return ConfigureProxy(client, config.ProxyConfig, logger)
+}
+
+// WarmUpConnections establishes the warm connections of the http client config to the base URL in the background,
+// so that the first burst of requests after the provider is created does not pay for the TCP and TLS handshakes.
+// The connections are opened by concurrent HEAD requests and stay in the pool of the client until they have been
+// idle for the max idle connection duration. Providers without a base URL, whose URL depends on the key, are skipped.
+func WarmUpConnections(client *fasthttp.Client, networkConfig schemas.NetworkConfig, logger schemas.Logger) {
+	count := networkConfig.HTTPClient.GetWarmConnections()
+	if count == 0 || networkConfig.BaseURL == "" {
+		return
+	}
+	timeout := time.Second * time.Duration(networkConfig.DefaultRequestTimeoutInSeconds)
+
+	go func() {
+		start := time.Now()
+		var wg sync.WaitGroup
+		var failed atomic.Int32
+		for range count {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req := fasthttp.AcquireRequest()
+				resp := fasthttp.AcquireResponse()
+				defer fasthttp.ReleaseRequest(req)
+				defer fasthttp.ReleaseResponse(resp)
+
+				req.SetRequestURI(networkConfig.BaseURL)
+				req.Header.SetMethod(http.MethodHead)
+				if err := client.DoTimeout(req, resp, timeout); err != nil {
+					failed.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+		if failed.Load() > 0 {
+			logger.Warn(fmt.Sprintf("failed to establish %d of %d warm connections to %s", failed.Load(), count, networkConfig.BaseURL))
+			return
+		}
+		logger.Debug(fmt.Sprintf("established %d warm connections to %s in %s", count, networkConfig.BaseURL, time.Since(start)))
+	}()
+}
+
+// NewNetHTTPTransport creates a net/http transport tuned by the http client config of the provider,
+// which negotiates HTTP/2 with the servers supporting it, with its proxy configured
+func NewNetHTTPTransport(config *schemas.ProviderConfig, logger schemas.Logger) *http.Transport {
+	httpClientConfig := config.NetworkConfig.HTTPClient
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ForceAttemptHTTP2 = true
+	transport.MaxConnsPerHost = httpClientConfig.GetMaxConnsPerHost()
+	transport.MaxIdleConnsPerHost = httpClientConfig.GetMaxConnsPerHost()
+	transport.IdleConnTimeout = httpClientConfig.GetMaxIdleConnDuration()
+	transport.ResponseHeaderTimeout = time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds)
+	if httpClientConfig != nil {
+		transport.ReadBufferSize = httpClientConfig.ReadBufferSize
+		transport.WriteBufferSize = httpClientConfig.WriteBufferSize
+		if httpClientConfig.DNS != nil {
+			dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+			if httpClientConfig.DNS.DisableHappyEyeballs {
+				dialer.FallbackDelay = -1
+			}
+			transport.DialContext = newDNSResolver(httpClientConfig.DNS).dialContext(dialer)
+		}
+	}
+
+	proxyConfig := config.ProxyConfig
+	if proxyConfig == nil {
+		return transport
+	}
+	switch proxyConfig.Type {
+	case schemas.NoProxy:
+		transport.Proxy = nil
+	case schemas.EnvProxy:
+		transport.Proxy = http.ProxyFromEnvironment
+	case schemas.HTTPProxy, schemas.Socks5Proxy:
+		if proxyConfig.URL == "" {
+			logger.Warn(fmt.Sprintf("Warning: %s proxy URL is required for setting up proxy", proxyConfig.Type))
+			return transport
+		}
+		proxyURL, err := url.Parse(proxyConfig.URL)
+		if err != nil {
+			logger.Warn(fmt.Sprintf("Invalid proxy configuration: invalid %s proxy URL", proxyConfig.Type))
+			return transport
+		}
+		if proxyConfig.Type == schemas.Socks5Proxy {
+			proxyURL.Scheme = "socks5"
+		}
+		if proxyConfig.Username != "" && proxyConfig.Password != "" {
+			proxyURL.User = url.UserPassword(proxyConfig.Username, proxyConfig.Password)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	default:
+		logger.Warn(fmt.Sprintf("Invalid proxy configuration: unsupported proxy type: %s", proxyConfig.Type))
+	}
+	return transport
+}
+
+// netHTTPRoundTripper sends the requests of a fasthttp client with a net/http transport
+type netHTTPRoundTripper struct {
+	transport *http.Transport
+}
+
+// RoundTrip converts the fasthttp request to a net/http request, sends it, and copies the net/http response
+// to the fasthttp response. Streamed responses keep the body of the net/http response as their body stream,
+// closed when the fasthttp response is released.
+func (t *netHTTPRoundTripper) RoundTrip(hc *fasthttp.HostClient, req *fasthttp.Request, resp *fasthttp.Response) (bool, error) {
+	var body io.Reader
+	if req.IsBodyStream() {
+		body = req.BodyStream()
+	} else if len(req.Body()) > 0 {
+		body = bytes.NewReader(req.Body())
+	}
+
+	httpReq, err := http.NewRequest(string(req.Header.Method()), req.URI().String(), body)
+	if err != nil {
+		return false, err
+	}
+	req.Header.VisitAll(func(key, value []byte) {
+		switch {
+		case strings.EqualFold(string(key), fasthttp.HeaderHost):
+			httpReq.Host = string(value)
+		case strings.EqualFold(string(key), fasthttp.HeaderContentLength):
+			// Set from the body by net/http
+		default:
+			httpReq.Header.Add(string(key), string(value))
+		}
+	})
+
+	httpResp, err := t.transport.RoundTrip(httpReq)
+	if err != nil {
+		return false, err
+	}
+
+	resp.SetStatusCode(httpResp.StatusCode)
+	for key, values := range httpResp.Header {
+		if key == fasthttp.HeaderContentLength {
+			// Set from the body below
+			continue
+		}
+		for _, value := range values {
+			resp.Header.Add(key, value)
+		}
+	}
+
+	if resp.StreamBody {
+		resp.SetBodyStream(httpResp.Body, int(httpResp.ContentLength))
+		return false, nil
+	}
+	defer httpResp.Body.Close()
+	if _, err := io.Copy(resp.BodyWriter(), httpResp.Body); err != nil {
+		return false, err
+	}
+	return false, nil
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestWarmUpConnections tests that the warm connections are established to the base URL
+func TestWarmUpConnections(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Hold the requests so that each one needs its own connection
+		time.Sleep(50 * time.Millisecond)
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	config := &schemas.ProviderConfig{
+		NetworkConfig: schemas.NetworkConfig{
+			BaseURL:    server.URL,
+			HTTPClient: &schemas.HTTPClientConfig{WarmConnections: 4},
+		},
+	}
+	config.CheckAndSetDefaults()
+	logger := &noopLogger{}
+	WarmUpConnections(NewHTTPClient(config, logger), config.NetworkConfig, logger)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for connections.Load() < 4 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if connections.Load() != 4 {
+		t.Errorf("expected 4 warm connections, got %d", connections.Load())
+	}
+}
+
+// noopLogger discards the logs of the tests
+type noopLogger struct{}
+
+func (l *noopLogger) Debug(msg string, args ...any)                     {}
+func (l *noopLogger) Info(msg string, args ...any)                      {}
+func (l *noopLogger) Warn(msg string, args ...any)                      {}
+func (l *noopLogger) Error(msg string, args ...any)                     {}
+func (l *noopLogger) Fatal(msg string, args ...any)                     {}
+func (l *noopLogger) SetLevel(level schemas.LogLevel)                   {}
+func (l *noopLogger) SetOutputType(outputType schemas.LoggerOutputType) {}
+
+// TestDNSResolver tests that static hosts are used instead of DNS, and that the last addresses of a host
+// are used when resolving it again fails
+func TestDNSResolver(t *testing.T) {
+	resolver := newDNSResolver(&schemas.DNSConfig{
+		StaticHosts: map[string][]string{"api.openai.com": {"10.0.0.1", "10.0.0.2"}},
+	})
+
+	addrs, err := resolver.LookupIPAddr(context.Background(), "api.openai.com")
+	if err != nil || len(addrs) != 2 || addrs[0].IP.String() != "10.0.0.1" {
+		t.Errorf("expected the static addresses, got %v, %v", addrs, err)
+	}
+
+	stale := []net.IPAddr{{IP: net.ParseIP("10.0.0.3")}}
+	resolver.cache["flapping.invalid"] = dnsCacheEntry{addrs: stale, expiresAt: time.Now().Add(-time.Second)}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	addrs, err = resolver.LookupIPAddr(ctx, "flapping.invalid")
+	if err != nil || len(addrs) != 1 || !addrs[0].IP.Equal(stale[0].IP) {
+		t.Errorf("expected the last known addresses, got %v, %v", addrs, err)
+	}
+}
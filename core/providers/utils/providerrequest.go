@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+// redactedValue replaces the credentials of captured requests
+const redactedValue = "<redacted>"
+
+// sensitiveHeaders are the headers carrying credentials, redacted from captured requests
+var sensitiveHeaders = []string{"authorization", "proxy-authorization", "x-api-key", "api-key", "x-goog-api-key", "cookie", "x-amz-security-token"}
+
+// sensitiveQueryParams are the query parameters carrying credentials, redacted from captured requests
+var sensitiveQueryParams = []string{"key", "api_key", "api-key", "access_token"}
+
+// providerRequestCaptureKey is the context key of the capture of the requests sent to the provider
+type providerRequestCaptureKey struct{}
+
+// ProviderRequestCapture holds the last HTTP request sent to the provider for a request, retries included
+type ProviderRequestCapture struct {
+	mu      sync.Mutex
+	request *schemas.BifrostProviderRequest
+}
+
+// WithProviderRequestCapture returns a context in which the requests sent by providers are recorded in the capture
+func WithProviderRequestCapture(ctx context.Context) (context.Context, *ProviderRequestCapture) {
+	capture := &ProviderRequestCapture{}
+	return context.WithValue(ctx, providerRequestCaptureKey{}, capture), capture
+}
+
+// Request returns the last request recorded, or nil if the provider did not send any
+func (c *ProviderRequestCapture) Request() *schemas.BifrostProviderRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.request
+}
+
+func (c *ProviderRequestCapture) set(request *schemas.BifrostProviderRequest) {
+	c.mu.Lock()
+	c.request = request
+	c.mu.Unlock()
+}
+
+// CaptureProviderRequest records the request in the capture of the context, if any
+func CaptureProviderRequest(ctx context.Context, req *fasthttp.Request) {
+	capture, ok := ctx.Value(providerRequestCaptureKey{}).(*ProviderRequestCapture)
+	if !ok {
+		return
+	}
+	headers := make(map[string]string)
+	req.Header.VisitAll(func(key, value []byte) {
+		headers[string(key)] = string(value)
+	})
+	capture.set(newProviderRequest(string(req.Header.Method()), req.URI().String(), headers, string(req.Header.ContentType()), req.Body()))
+}
+
+// CaptureHTTPProviderRequest records the net/http request and its body in the capture of the context, if any
+func CaptureHTTPProviderRequest(ctx context.Context, req *http.Request, body []byte) {
+	capture, ok := ctx.Value(providerRequestCaptureKey{}).(*ProviderRequestCapture)
+	if !ok {
+		return
+	}
+	headers := make(map[string]string, len(req.Header))
+	for key := range req.Header {
+		headers[key] = req.Header.Get(key)
+	}
+	capture.set(newProviderRequest(req.Method, req.URL.String(), headers, req.Header.Get("Content-Type"), body))
+}
+
+// newProviderRequest builds the captured request, redacting its credentials
+func newProviderRequest(method, rawURL string, headers map[string]string, contentType string, body []byte) *schemas.BifrostProviderRequest {
+	for key := range headers {
+		for _, sensitive := range sensitiveHeaders {
+			if strings.EqualFold(key, sensitive) {
+				headers[key] = redactedValue
+			}
+		}
+	}
+	if parsed, err := url.Parse(rawURL); err == nil && parsed.RawQuery != "" {
+		query := parsed.Query()
+		for _, param := range sensitiveQueryParams {
+			if query.Has(param) {
+				query.Set(param, redactedValue)
+			}
+		}
+		parsed.RawQuery = query.Encode()
+		rawURL = parsed.String()
+	}
+
+	request := &schemas.BifrostProviderRequest{
+		Method:   method,
+		URL:      rawURL,
+		Headers:  headers,
+		BodySize: len(body),
+	}
+	if contentType == "" || strings.Contains(contentType, "json") || strings.HasPrefix(contentType, "text/") {
+		request.Body = string(body)
+	}
+	return request
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+// TestCaptureProviderRequest tests that the requests are only recorded in a capture context, with their credentials redacted
+func TestCaptureProviderRequest(t *testing.T) {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("https://generativelanguage.googleapis.com/v1beta/models/gemini-2.0-flash:generateContent?key=secret&alt=json")
+	req.Header.SetMethod(fasthttp.MethodPost)
+	req.Header.SetContentType("application/json")
+	req.Header.Set("Authorization", "Bearer sk-secret")
+	req.Header.Set("X-Request-Source", "replay")
+	req.SetBodyString(`{"contents":[]}`)
+
+	// Without a capture in the context the request is not recorded
+	CaptureProviderRequest(context.Background(), req)
+
+	ctx, capture := WithProviderRequestCapture(context.Background())
+	if capture.Request() != nil {
+		t.Fatal("expected no request before the provider sends one")
+	}
+	CaptureProviderRequest(ctx, req)
+
+	captured := capture.Request()
+	if captured == nil {
+		t.Fatal("expected the request to be recorded")
+	}
+	if captured.Method != fasthttp.MethodPost || captured.Body != `{"contents":[]}` || captured.BodySize != 15 {
+		t.Errorf("unexpected method or body: %+v", captured)
+	}
+	if strings.Contains(captured.URL, "secret") || !strings.Contains(captured.URL, "alt=json") {
+		t.Errorf("expected the key to be redacted from the url, got %s", captured.URL)
+	}
+	if captured.Headers["Authorization"] != redactedValue || captured.Headers["X-Request-Source"] != "replay" {
+		t.Errorf("expected only the authorization header to be redacted, got %v", captured.Headers)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"net/url"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -37,6 +38,7 @@ var UnsupportedSpeechStreamModels = []string{"tts-1", "tts-1-hd"}
 // fasthttp call and returns an error related to the context.
 // Returns the request latency and any error that occurred.
 func MakeRequestWithContext(ctx context.Context, client *fasthttp.Client, req *fasthttp.Request, resp *fasthttp.Response) (time.Duration, *schemas.BifrostError) {
+	CaptureProviderRequest(ctx, req)
 	startTime := time.Now()
 	errChan := make(chan error, 1)
 
@@ -215,6 +217,15 @@ func GetPathFromContext(ctx context.Context, defaultPath string) string {
 	return defaultPath
 }
 
+// GetStreamBufferSize gets the size of the chunk channels of a stream from the context, if it exists.
+// Otherwise it returns DefaultStreamBufferSize.
+func GetStreamBufferSize(ctx context.Context) int {
+	if size, ok := ctx.Value(schemas.BifrostContextKeyStreamBufferSize).(int); ok && size > 0 {
+		return size
+	}
+	return schemas.DefaultStreamBufferSize
+}
+
 // GetRequestPath gets the request path from the context, if it exists, checking for path overrides in the custom provider config.
 func GetRequestPath(ctx context.Context, defaultPath string, customProviderConfig *schemas.CustomProviderConfig, requestType schemas.RequestType) string {
 	// If path set in context, return it
@@ -323,6 +334,7 @@ func HandleProviderAPIError(resp *fasthttp.Response, errorResp any) *schemas.Bif
 		return &schemas.BifrostError{
 			IsBifrostError: false,
 			StatusCode:     &statusCode,
+			RetryAfter:     ParseRetryAfter(resp),
 			Error: &schemas.ErrorField{
 				Message: message,
 			},
@@ -332,10 +344,36 @@ func HandleProviderAPIError(resp *fasthttp.Response, errorResp any) *schemas.Bif
 	return &schemas.BifrostError{
 		IsBifrostError: false,
 		StatusCode:     &statusCode,
+		RetryAfter:     ParseRetryAfter(resp),
 		Error:          &schemas.ErrorField{},
 	}
 }
 
+// ParseRetryAfter parses the delay requested by the provider before retrying, from the retry-after-ms header
+// sent by OpenAI and Anthropic, or from the Retry-After header as seconds or as an HTTP date.
+// It returns nil when the response has no valid delay.
+func ParseRetryAfter(resp *fasthttp.Response) *time.Duration {
+	if value := strings.TrimSpace(string(resp.Header.Peek("retry-after-ms"))); value != "" {
+		if ms, err := strconv.ParseFloat(value, 64); err == nil && ms >= 0 {
+			delay := time.Duration(ms * float64(time.Millisecond))
+			return &delay
+		}
+	}
+	value := strings.TrimSpace(string(resp.Header.Peek("Retry-After")))
+	if value == "" {
+		return nil
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+		delay := time.Duration(seconds * float64(time.Second))
+		return &delay
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := max(time.Until(date), 0)
+		return &delay
+	}
+	return nil
+}
+
 // HandleProviderResponse handles common response parsing logic for provider responses.
 // It attempts to parse the response body into the provided response type
 // and returns either the parsed response or a BifrostError if parsing fails.
@@ -545,22 +583,12 @@ func ProcessAndSendResponse(
 		return
 	}
 
-	streamResponse := &schemas.BifrostStream{}
-	if processedResponse != nil {
-		streamResponse.BifrostTextCompletionResponse = processedResponse.TextCompletionResponse
-		streamResponse.BifrostChatResponse = processedResponse.ChatResponse
-		streamResponse.BifrostResponsesStreamResponse = processedResponse.ResponsesStreamResponse
-		streamResponse.BifrostSpeechStreamResponse = processedResponse.SpeechStreamResponse
-		streamResponse.BifrostTranscriptionStreamResponse = processedResponse.TranscriptionStreamResponse
-	}
-	if processedError != nil {
-		streamResponse.BifrostError = processedError
-	}
+	streamResponse := schemas.AcquireBifrostStream(processedResponse, processedError)
 
 	select {
 	case responseChan <- streamResponse:
 	case <-ctx.Done():
-		return
+		schemas.ReleaseBifrostStream(streamResponse)
 	}
 }
 
@@ -582,21 +610,12 @@ func ProcessAndSendBifrostError(
 		return
 	}
 
-	streamResponse := &schemas.BifrostStream{}
-	if processedResponse != nil {
-		streamResponse.BifrostTextCompletionResponse = processedResponse.TextCompletionResponse
-		streamResponse.BifrostChatResponse = processedResponse.ChatResponse
-		streamResponse.BifrostResponsesStreamResponse = processedResponse.ResponsesStreamResponse
-		streamResponse.BifrostSpeechStreamResponse = processedResponse.SpeechStreamResponse
-		streamResponse.BifrostTranscriptionStreamResponse = processedResponse.TranscriptionStreamResponse
-	}
-	if processedError != nil {
-		streamResponse.BifrostError = processedError
-	}
+	streamResponse := schemas.AcquireBifrostStream(processedResponse, processedError)
 
 	select {
 	case responseChan <- streamResponse:
 	case <-ctx.Done():
+		schemas.ReleaseBifrostStream(streamResponse)
 	}
 }
 
@@ -634,21 +653,12 @@ func ProcessAndSendError(
 		return
 	}
 
-	streamResponse := &schemas.BifrostStream{}
-	if processedResponse != nil {
-		streamResponse.BifrostTextCompletionResponse = processedResponse.TextCompletionResponse
-		streamResponse.BifrostChatResponse = processedResponse.ChatResponse
-		streamResponse.BifrostResponsesStreamResponse = processedResponse.ResponsesStreamResponse
-		streamResponse.BifrostSpeechStreamResponse = processedResponse.SpeechStreamResponse
-		streamResponse.BifrostTranscriptionStreamResponse = processedResponse.TranscriptionStreamResponse
-	}
-	if processedError != nil {
-		streamResponse.BifrostError = processedError
-	}
+	streamResponse := schemas.AcquireBifrostStream(processedResponse, processedError)
 
 	select {
 	case responseChan <- streamResponse:
 	case <-ctx.Done():
+		schemas.ReleaseBifrostStream(streamResponse)
 	}
 }
 
@@ -764,11 +774,19 @@ func ProviderIsResponsesAPINative(providerName schemas.ModelProvider) bool {
 }
 
 // ReleaseStreamingResponse releases a streaming response by draining the body stream and releasing the response.
-func ReleaseStreamingResponse(resp *fasthttp.Response) {
-	// Drain any remaining data from the body stream before releasing
-	// This prevents "whitespace in header" errors when the response is reused
+// When the context is cancelled, e.g. because the client disconnected, the connection is closed instead of
+// drained, so that the provider stops generating the rest of the abandoned stream.
+func ReleaseStreamingResponse(ctx context.Context, resp *fasthttp.Response) {
 	if resp.BodyStream() != nil {
-		io.Copy(io.Discard, resp.BodyStream())
+		if ctx.Err() != nil {
+			// Closing the body stream of a "Connection: close" response closes the connection instead of returning it to the pool
+			resp.SetConnectionClose()
+			resp.CloseBodyStream()
+		} else {
+			// Drain any remaining data from the body stream before releasing
+			// This prevents "whitespace in header" errors when the response is reused
+			io.Copy(io.Discard, resp.BodyStream())
+		}
 	}
 	fasthttp.ReleaseResponse(resp)
 }
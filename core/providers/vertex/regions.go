@@ -0,0 +1,68 @@
+package vertex
+
+import (
+	"context"
+	"fmt"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+// regionalErrorStatusCodes are the status codes of errors that can be specific to a region:
+// models or quota not available in the region (404, 429) and regional outages (5xx).
+var regionalErrorStatusCodes = map[int]bool{
+	fasthttp.StatusNotFound:            true,
+	fasthttp.StatusTooManyRequests:     true,
+	fasthttp.StatusInternalServerError: true,
+	fasthttp.StatusBadGateway:          true,
+	fasthttp.StatusServiceUnavailable:  true,
+	fasthttp.StatusGatewayTimeout:      true,
+}
+
+// keyRegions returns the regions of the key in the order they are tried, the region first and then the fallback regions.
+func keyRegions(key schemas.Key) []string {
+	if key.VertexKeyConfig == nil {
+		return nil
+	}
+	regions := make([]string, 0, 1+len(key.VertexKeyConfig.Regions))
+	seen := make(map[string]bool, 1+len(key.VertexKeyConfig.Regions))
+	for _, region := range append([]string{key.VertexKeyConfig.Region}, key.VertexKeyConfig.Regions...) {
+		if region == "" || seen[region] {
+			continue
+		}
+		seen[region] = true
+		regions = append(regions, region)
+	}
+	return regions
+}
+
+// isRegionalError returns true if the request may succeed in another region.
+func isRegionalError(err *schemas.BifrostError) bool {
+	return err != nil && !err.IsBifrostError && err.StatusCode != nil && regionalErrorStatusCodes[*err.StatusCode]
+}
+
+// withRegionFallback runs the request with the key in each of its regions, until the request succeeds
+// or fails with an error that is not regional. Keys without fallback regions run the request once.
+func withRegionFallback[T any](ctx context.Context, logger schemas.Logger, key schemas.Key, request func(key schemas.Key) (T, *schemas.BifrostError)) (T, *schemas.BifrostError) {
+	regions := keyRegions(key)
+	if len(regions) <= 1 {
+		return request(key)
+	}
+
+	var result T
+	var bifrostErr *schemas.BifrostError
+	for i, region := range regions {
+		// Copy the key config so the configured key is left untouched
+		regionConfig := *key.VertexKeyConfig
+		regionConfig.Region = region
+		regionKey := key
+		regionKey.VertexKeyConfig = &regionConfig
+
+		result, bifrostErr = request(regionKey)
+		if !isRegionalError(bifrostErr) || i == len(regions)-1 || ctx.Err() != nil {
+			return result, bifrostErr
+		}
+		logger.Debug(fmt.Sprintf("vertex request failed in region %s with status %d, falling back to region %s", region, *bifrostErr.StatusCode, regions[i+1]))
+	}
+	return result, bifrostErr
+}
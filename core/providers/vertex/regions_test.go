@@ -0,0 +1,65 @@
+package vertex
+
+import (
+	"context"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/stretchr/testify/assert"
+)
+
+type noopLogger struct{}
+
+func (noopLogger) Debug(string, ...any)                   {}
+func (noopLogger) Info(string, ...any)                    {}
+func (noopLogger) Warn(string, ...any)                    {}
+func (noopLogger) Error(string, ...any)                   {}
+func (noopLogger) Fatal(string, ...any)                   {}
+func (noopLogger) SetLevel(schemas.LogLevel)              {}
+func (noopLogger) SetOutputType(schemas.LoggerOutputType) {}
+
+func TestWithRegionFallback(t *testing.T) {
+	key := schemas.Key{
+		VertexKeyConfig: &schemas.VertexKeyConfig{
+			ProjectID: "project",
+			Region:    "us-east5",
+			Regions:   []string{"europe-west1", "us-east5", "global"},
+		},
+	}
+	assert.Equal(t, []string{"us-east5", "europe-west1", "global"}, keyRegions(key))
+
+	statusError := func(statusCode int) *schemas.BifrostError {
+		return &schemas.BifrostError{StatusCode: &statusCode, Error: &schemas.ErrorField{Message: "error"}}
+	}
+
+	t.Run("FallsBackOnRegionalErrors", func(t *testing.T) {
+		var tried []string
+		region, err := withRegionFallback(context.Background(), noopLogger{}, key, func(key schemas.Key) (string, *schemas.BifrostError) {
+			tried = append(tried, key.VertexKeyConfig.Region)
+			if key.VertexKeyConfig.Region == "global" {
+				return key.VertexKeyConfig.Region, nil
+			}
+			return "", statusError(429)
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "global", region)
+		assert.Equal(t, []string{"us-east5", "europe-west1", "global"}, tried)
+		assert.Equal(t, "us-east5", key.VertexKeyConfig.Region, "configured key must not be modified")
+	})
+
+	t.Run("StopsOnOtherErrors", func(t *testing.T) {
+		var tried []string
+		_, err := withRegionFallback(context.Background(), noopLogger{}, key, func(key schemas.Key) (string, *schemas.BifrostError) {
+			tried = append(tried, key.VertexKeyConfig.Region)
+			return "", statusError(400)
+		})
+		assert.NotNil(t, err)
+		assert.Equal(t, []string{"us-east5"}, tried)
+	})
+}
+
+func TestNormalizeDeployment(t *testing.T) {
+	assert.Equal(t, "claude-sonnet-4@20250514", normalizeDeployment("publishers/anthropic/models/claude-sonnet-4@20250514"))
+	assert.Equal(t, "1234567890", normalizeDeployment("endpoints/1234567890"))
+	assert.Equal(t, "gemini-2.0-flash", normalizeDeployment("gemini-2.0-flash"))
+}
@@ -9,7 +9,6 @@ import (
 	"net/url"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/valyala/fasthttp"
 	"golang.org/x/oauth2"
@@ -17,6 +16,7 @@ import (
 
 	"github.com/bytedance/sonic"
 	"github.com/maximhq/bifrost/core/providers/anthropic"
+	"github.com/maximhq/bifrost/core/providers/gemini"
 	"github.com/maximhq/bifrost/core/providers/openai"
 	providerUtils "github.com/maximhq/bifrost/core/providers/utils"
 	schemas "github.com/maximhq/bifrost/core/schemas"
@@ -66,14 +66,9 @@ type VertexProvider struct {
 // The client is configured with timeouts, concurrency limits, and optional proxy settings.
 func NewVertexProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*VertexProvider, error) {
 	config.CheckAndSetDefaults()
-	client := &fasthttp.Client{
-		ReadTimeout:         time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
-		WriteTimeout:        time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
-		MaxConnsPerHost:     5000,
-		MaxIdleConnDuration: 60 * time.Second,
-		MaxConnWaitTimeout:  10 * time.Second,
-	}
-	client = providerUtils.ConfigureProxy(client, config.ProxyConfig, logger)
+	client := providerUtils.NewHTTPClient(config, logger)
+	providerUtils.WarmUpConnections(client, config.NetworkConfig, logger)
+
 	return &VertexProvider{
 		logger:              logger,
 		client:              client,
@@ -261,7 +256,15 @@ func (provider *VertexProvider) TextCompletionStream(ctx context.Context, postHo
 // ChatCompletion performs a chat completion request to the Vertex API.
 // It supports both text and image content in messages.
 // Returns a BifrostResponse containing the completion results or an error if the request fails.
+// Requests failing with a regional error are retried in the fallback regions of the key, see withRegionFallback.
 func (provider *VertexProvider) ChatCompletion(ctx context.Context, key schemas.Key, request *schemas.BifrostChatRequest) (*schemas.BifrostChatResponse, *schemas.BifrostError) {
+	return withRegionFallback(ctx, provider.logger, key, func(key schemas.Key) (*schemas.BifrostChatResponse, *schemas.BifrostError) {
+		return provider.chatCompletion(ctx, key, request)
+	})
+}
+
+// chatCompletion performs a chat completion request in the region of the key.
+func (provider *VertexProvider) chatCompletion(ctx context.Context, key schemas.Key, request *schemas.BifrostChatRequest) (*schemas.BifrostChatResponse, *schemas.BifrostError) {
 	providerName := provider.GetProviderKey()
 
 	if key.VertexKeyConfig == nil {
@@ -270,6 +273,9 @@ func (provider *VertexProvider) ChatCompletion(ctx context.Context, key schemas.
 
 	deployment := provider.getModelDeployment(key, request.Model)
 
+	// Videos are only understood by Gemini models through the native generateContent API
+	generateContent := schemas.HasInputVideo(request.Input) && !schemas.IsAnthropicModel(deployment) && !schemas.IsMistralModel(deployment) && !schemas.IsAllDigitsASCII(deployment)
+
 	jsonBody, bifrostErr := providerUtils.CheckContextAndGetRequestBody(
 		ctx,
 		request,
@@ -278,7 +284,24 @@ func (provider *VertexProvider) ChatCompletion(ctx context.Context, key schemas.
 			// Format messages for Vertex API
 			var requestBody map[string]interface{}
 
-			if schemas.IsAnthropicModel(deployment) {
+			if generateContent {
+				reqBody := gemini.ToGeminiChatCompletionRequest(request, nil)
+				if reqBody == nil {
+					return nil, fmt.Errorf("chat completion input is not provided")
+				}
+				reqBody.Model = ""
+				// Convert struct to map for Vertex API
+				reqBytes, err := sonic.Marshal(reqBody)
+				if err != nil {
+					return nil, fmt.Errorf("failed to marshal request body: %w", err)
+				}
+				if err := sonic.Unmarshal(reqBytes, &requestBody); err != nil {
+					return nil, fmt.Errorf("failed to unmarshal request body: %w", err)
+				}
+			} else if schemas.IsAnthropicModel(deployment) {
+				if err := anthropic.CheckChatInput(request.Input); err != nil {
+					return nil, err
+				}
 				// Use centralized Anthropic converter
 				reqBody := anthropic.ToAnthropicChatRequest(request)
 				if reqBody == nil {
@@ -366,6 +389,16 @@ func (provider *VertexProvider) ChatCompletion(ctx context.Context, key schemas.
 		} else {
 			completeURL = fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/publishers/mistralai/models/%s:rawPredict", region, projectID, region, deployment)
 		}
+	} else if generateContent {
+		// Gemini models with video inputs use the google publisher with generateContent
+		if key.Value != "" {
+			authQuery = fmt.Sprintf("key=%s", url.QueryEscape(key.Value))
+		}
+		if region == "global" {
+			completeURL = fmt.Sprintf("https://aiplatform.googleapis.com/v1/projects/%s/locations/global/publishers/google/models/%s:generateContent", projectID, deployment)
+		} else {
+			completeURL = fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/publishers/google/models/%s:generateContent", region, projectID, region, deployment)
+		}
 	} else {
 		// Other models use OpenAPI endpoint for gemini models
 		if key.Value != "" {
@@ -422,7 +455,30 @@ func (provider *VertexProvider) ChatCompletion(ctx context.Context, key schemas.
 		return nil, parseVertexError(providerName, resp)
 	}
 
-	if schemas.IsAnthropicModel(deployment) {
+	if generateContent {
+		var geminiResponse gemini.GenerateContentResponse
+		rawResponse, bifrostErr := providerUtils.HandleProviderResponse(resp.Body(), &geminiResponse, provider.sendBackRawResponse)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+
+		response := geminiResponse.ToBifrostChatResponse()
+		response.ExtraFields = schemas.BifrostResponseExtraFields{
+			RequestType:    schemas.ChatCompletionRequest,
+			Provider:       providerName,
+			ModelRequested: request.Model,
+			Latency:        latency.Milliseconds(),
+		}
+		if request.Model != deployment {
+			response.ExtraFields.ModelDeployment = deployment
+		}
+
+		if providerUtils.ShouldSendBackRawResponse(ctx, provider.sendBackRawResponse) {
+			response.ExtraFields.RawResponse = rawResponse
+		}
+
+		return response, nil
+	} else if schemas.IsAnthropicModel(deployment) {
 		// Create response object from pool
 		anthropicResponse := anthropic.AcquireAnthropicMessageResponse()
 		defer anthropic.ReleaseAnthropicMessageResponse(anthropicResponse)
@@ -480,7 +536,15 @@ func (provider *VertexProvider) ChatCompletion(ctx context.Context, key schemas.
 // ChatCompletionStream performs a streaming chat completion request to the Vertex API.
 // It supports both OpenAI-style streaming (for non-Claude models) and Anthropic-style streaming (for Claude models).
 // Returns a channel of BifrostResponse objects for streaming results or an error if the request fails.
+// Streams failing to open with a regional error are retried in the fallback regions of the key, see withRegionFallback.
 func (provider *VertexProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, key schemas.Key, request *schemas.BifrostChatRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return withRegionFallback(ctx, provider.logger, key, func(key schemas.Key) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+		return provider.chatCompletionStream(ctx, postHookRunner, key, request)
+	})
+}
+
+// chatCompletionStream performs a streaming chat completion request in the region of the key.
+func (provider *VertexProvider) chatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, key schemas.Key, request *schemas.BifrostChatRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
 	providerName := provider.GetProviderKey()
 	if key.VertexKeyConfig == nil {
 		return nil, providerUtils.NewConfigurationError("vertex key config is not set", providerName)
@@ -506,12 +570,19 @@ func (provider *VertexProvider) ChatCompletionStream(ctx context.Context, postHo
 		return response
 	}
 
+	if schemas.HasInputVideo(request.Input) && !schemas.IsAnthropicModel(deployment) {
+		return nil, providerUtils.NewBifrostOperationError("video input is only supported in non-streaming chat completions", nil, providerName)
+	}
+
 	if schemas.IsAnthropicModel(deployment) {
 		// Use Anthropic-style streaming for Claude models
 		jsonData, bifrostErr := providerUtils.CheckContextAndGetRequestBody(
 			ctx,
 			request,
 			func() (any, error) {
+				if err := anthropic.CheckChatInput(request.Input); err != nil {
+					return nil, err
+				}
 				reqBody := anthropic.ToAnthropicChatRequest(request)
 				if reqBody == nil {
 					return nil, fmt.Errorf("chat completion input is not provided")
@@ -663,7 +734,15 @@ func (provider *VertexProvider) ChatCompletionStream(ctx context.Context, postHo
 }
 
 // Responses performs a responses request to the Vertex API.
+// Requests failing with a regional error are retried in the fallback regions of the key, see withRegionFallback.
 func (provider *VertexProvider) Responses(ctx context.Context, key schemas.Key, request *schemas.BifrostResponsesRequest) (*schemas.BifrostResponsesResponse, *schemas.BifrostError) {
+	return withRegionFallback(ctx, provider.logger, key, func(key schemas.Key) (*schemas.BifrostResponsesResponse, *schemas.BifrostError) {
+		return provider.responses(ctx, key, request)
+	})
+}
+
+// responses performs a responses request in the region of the key.
+func (provider *VertexProvider) responses(ctx context.Context, key schemas.Key, request *schemas.BifrostResponsesRequest) (*schemas.BifrostResponsesResponse, *schemas.BifrostError) {
 	providerName := provider.GetProviderKey()
 
 	if key.VertexKeyConfig == nil {
@@ -795,7 +874,7 @@ func (provider *VertexProvider) Responses(ctx context.Context, key schemas.Key,
 
 		return response, nil
 	} else {
-		chatResponse, err := provider.ChatCompletion(ctx, key, request.ToChatRequest())
+		chatResponse, err := provider.chatCompletion(ctx, key, request.ToChatRequest())
 		if err != nil {
 			return nil, err
 		}
@@ -815,7 +894,15 @@ func (provider *VertexProvider) Responses(ctx context.Context, key schemas.Key,
 }
 
 // ResponsesStream performs a streaming responses request to the Vertex API.
+// Streams failing to open with a regional error are retried in the fallback regions of the key, see withRegionFallback.
 func (provider *VertexProvider) ResponsesStream(ctx context.Context, postHookRunner schemas.PostHookRunner, key schemas.Key, request *schemas.BifrostResponsesRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return withRegionFallback(ctx, provider.logger, key, func(key schemas.Key) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+		return provider.responsesStream(ctx, postHookRunner, key, request)
+	})
+}
+
+// responsesStream performs a streaming responses request in the region of the key.
+func (provider *VertexProvider) responsesStream(ctx context.Context, postHookRunner schemas.PostHookRunner, key schemas.Key, request *schemas.BifrostResponsesRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
 	providerName := provider.GetProviderKey()
 
 	if key.VertexKeyConfig == nil {
@@ -920,7 +1007,7 @@ func (provider *VertexProvider) ResponsesStream(ctx context.Context, postHookRun
 		)
 	} else {
 		ctx = context.WithValue(ctx, schemas.BifrostContextKeyIsResponsesToChatCompletionFallback, true)
-		return provider.ChatCompletionStream(
+		return provider.chatCompletionStream(
 			ctx,
 			postHookRunner,
 			key,
@@ -932,7 +1019,15 @@ func (provider *VertexProvider) ResponsesStream(ctx context.Context, postHookRun
 // Embedding generates embeddings for the given input text(s) using Vertex AI.
 // All Vertex AI embedding models use the same response format regardless of the model type.
 // Returns a BifrostResponse containing the embedding(s) and any error that occurred.
+// Requests failing with a regional error are retried in the fallback regions of the key, see withRegionFallback.
 func (provider *VertexProvider) Embedding(ctx context.Context, key schemas.Key, request *schemas.BifrostEmbeddingRequest) (*schemas.BifrostEmbeddingResponse, *schemas.BifrostError) {
+	return withRegionFallback(ctx, provider.logger, key, func(key schemas.Key) (*schemas.BifrostEmbeddingResponse, *schemas.BifrostError) {
+		return provider.embedding(ctx, key, request)
+	})
+}
+
+// embedding performs a embedding request in the region of the key.
+func (provider *VertexProvider) embedding(ctx context.Context, key schemas.Key, request *schemas.BifrostEmbeddingRequest) (*schemas.BifrostEmbeddingResponse, *schemas.BifrostError) {
 	providerName := provider.GetProviderKey()
 
 	if key.VertexKeyConfig == nil {
@@ -1090,8 +1185,18 @@ func (provider *VertexProvider) getModelDeployment(key schemas.Key, model string
 
 	if key.VertexKeyConfig.Deployments != nil {
 		if deployment, ok := key.VertexKeyConfig.Deployments[model]; ok {
-			return deployment
+			return normalizeDeployment(deployment)
 		}
 	}
 	return model
 }
+
+// normalizeDeployment strips the resource name prefixes of Vertex deployments, so Anthropic publisher models
+// (publishers/anthropic/models/<model>) and Model Garden endpoints (endpoints/<id>) route like their plain names.
+func normalizeDeployment(deployment string) string {
+	deployment = strings.TrimPrefix(deployment, "publishers/anthropic/models/")
+	if endpointID, ok := strings.CutPrefix(deployment, "endpoints/"); ok && schemas.IsAllDigitsASCII(endpointID) {
+		return endpointID
+	}
+	return deployment
+}
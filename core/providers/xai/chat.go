@@ -0,0 +1,68 @@
+package xai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/maximhq/bifrost/core/providers/openai"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// supportsReasoningEffort reports whether the Grok model accepts the reasoning_effort parameter.
+// Only the grok-3-mini models accept it, the other reasoning models reject requests carrying it.
+func supportsReasoningEffort(model string) bool {
+	return strings.Contains(model, "grok-3-mini")
+}
+
+// toXAIReasoningEffort maps the Bifrost reasoning effort to the "low" and "high" efforts of Grok
+func toXAIReasoningEffort(effort string) string {
+	switch effort {
+	case "minimal", "low":
+		return "low"
+	default:
+		return "high"
+	}
+}
+
+// ToXAIChatRequest converts a Bifrost request to an xAI chat completion request.
+// The reasoning effort is mapped to the efforts of Grok, or dropped for models without it, and the
+// "search_parameters" extra parameter configures live search.
+func ToXAIChatRequest(bifrostReq *schemas.BifrostChatRequest) (*XAIChatRequest, error) {
+	openaiReq := openai.ToOpenAIChatRequest(bifrostReq)
+	if openaiReq == nil {
+		return nil, nil
+	}
+
+	xaiReq := &XAIChatRequest{
+		OpenAIChatRequest: *openaiReq,
+	}
+
+	if bifrostReq.Params == nil {
+		return xaiReq, nil
+	}
+
+	// Handle reasoning effort mapping
+	if bifrostReq.Params.ReasoningEffort != nil {
+		if supportsReasoningEffort(bifrostReq.Model) {
+			xaiReq.ReasoningEffort = schemas.Ptr(toXAIReasoningEffort(*bifrostReq.Params.ReasoningEffort))
+		} else {
+			xaiReq.ReasoningEffort = nil
+		}
+	}
+
+	// Handle extra parameters for xAI-specific fields
+	if searchParametersParam, ok := schemas.SafeExtractFromMap(bifrostReq.Params.ExtraParams, "search_parameters"); ok {
+		data, err := sonic.Marshal(searchParametersParam)
+		if err != nil {
+			return nil, fmt.Errorf("invalid search_parameters: %w", err)
+		}
+		var searchParameters XAISearchParameters
+		if err := sonic.Unmarshal(data, &searchParameters); err != nil {
+			return nil, fmt.Errorf("invalid search_parameters: %w", err)
+		}
+		xaiReq.SearchParameters = &searchParameters
+	}
+
+	return xaiReq, nil
+}
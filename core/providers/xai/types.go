@@ -0,0 +1,33 @@
+package xai
+
+import (
+	"github.com/maximhq/bifrost/core/providers/openai"
+)
+
+// XAIChatRequest represents an xAI chat completion request.
+// It is the OpenAI chat request with the Grok specific parameters.
+type XAIChatRequest struct {
+	openai.OpenAIChatRequest
+	SearchParameters *XAISearchParameters `json:"search_parameters,omitempty"` // Optional: Live search over web, X and news sources
+}
+
+// XAISearchParameters configures the live search of Grok
+type XAISearchParameters struct {
+	Mode             *string           `json:"mode,omitempty"` // "off" | "auto" | "on"
+	ReturnCitations  *bool             `json:"return_citations,omitempty"`
+	FromDate         *string           `json:"from_date,omitempty"` // ISO-8601 date, e.g. "2025-01-01"
+	ToDate           *string           `json:"to_date,omitempty"`
+	MaxSearchResults *int              `json:"max_search_results,omitempty"`
+	Sources          []XAISearchSource `json:"sources,omitempty"`
+}
+
+// XAISearchSource is a source of the live search of Grok
+type XAISearchSource struct {
+	Type             string   `json:"type"` // "web" | "x" | "news" | "rss"
+	Country          *string  `json:"country,omitempty"`
+	ExcludedWebsites []string `json:"excluded_websites,omitempty"`
+	AllowedWebsites  []string `json:"allowed_websites,omitempty"`
+	SafeSearch       *bool    `json:"safe_search,omitempty"`
+	XHandles         []string `json:"x_handles,omitempty"`
+	Links            []string `json:"links,omitempty"`
+}
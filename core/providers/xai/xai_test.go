@@ -0,0 +1,141 @@
+package xai_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/maximhq/bifrost/core/internal/testutil"
+	"github.com/maximhq/bifrost/core/providers/xai"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestXAI(t *testing.T) {
+	t.Parallel()
+	if strings.TrimSpace(os.Getenv("XAI_API_KEY")) == "" {
+		t.Skip("Skipping xAI tests because XAI_API_KEY is not set")
+	}
+
+	client, ctx, cancel, err := testutil.SetupTest()
+	if err != nil {
+		t.Fatalf("Error initializing test setup: %v", err)
+	}
+	defer cancel()
+
+	testConfig := testutil.ComprehensiveTestConfig{
+		Provider:    schemas.XAI,
+		ChatModel:   "grok-3",
+		VisionModel: "grok-2-vision-1212",
+		Fallbacks: []schemas.Fallback{
+			{Provider: schemas.XAI, Model: "grok-3-mini"},
+		},
+		TextModel:      "", // xAI doesn't support text completion
+		EmbeddingModel: "", // xAI doesn't support embedding
+		Scenarios: testutil.TestScenarios{
+			TextCompletion:        false,
+			SimpleChat:            true,
+			CompletionStream:      true,
+			MultiTurnConversation: true,
+			ToolCalls:             true,
+			ToolCallsStreaming:    true,
+			MultipleToolCalls:     true,
+			End2EndToolCalling:    true,
+			AutomaticFunctionCall: true,
+			ImageURL:              true,
+			ImageBase64:           true,
+			MultipleImages:        true,
+			CompleteEnd2End:       true,
+			Embedding:             false,
+			ListModels:            true,
+		},
+	}
+
+	t.Run("XAITests", func(t *testing.T) {
+		testutil.RunAllComprehensiveTests(t, client, ctx, testConfig)
+	})
+	client.Shutdown()
+}
+
+func TestToXAIChatRequest(t *testing.T) {
+	tests := []struct {
+		name                     string
+		model                    string
+		reasoningEffort          *string
+		extraParams              map[string]interface{}
+		expectedReasoningEffort  *string
+		expectedSearchParameters *xai.XAISearchParameters
+		wantErr                  bool
+	}{
+		{
+			name:                    "ReasoningEffortMinimal",
+			model:                   "grok-3-mini",
+			reasoningEffort:         schemas.Ptr("minimal"),
+			expectedReasoningEffort: schemas.Ptr("low"),
+		},
+		{
+			name:                    "ReasoningEffortMedium",
+			model:                   "grok-3-mini-fast",
+			reasoningEffort:         schemas.Ptr("medium"),
+			expectedReasoningEffort: schemas.Ptr("high"),
+		},
+		{
+			name:                    "ReasoningEffortUnsupportedModel",
+			model:                   "grok-4",
+			reasoningEffort:         schemas.Ptr("high"),
+			expectedReasoningEffort: nil,
+		},
+		{
+			name:  "SearchParameters",
+			model: "grok-3",
+			extraParams: map[string]interface{}{
+				"search_parameters": map[string]interface{}{
+					"mode":             "on",
+					"return_citations": true,
+					"sources":          []interface{}{map[string]interface{}{"type": "x", "x_handles": []interface{}{"xai"}}},
+				},
+			},
+			expectedSearchParameters: &xai.XAISearchParameters{
+				Mode:            schemas.Ptr("on"),
+				ReturnCitations: schemas.Ptr(true),
+				Sources:         []xai.XAISearchSource{{Type: "x", XHandles: []string{"xai"}}},
+			},
+		},
+		{
+			name:        "InvalidSearchParameters",
+			model:       "grok-3",
+			extraParams: map[string]interface{}{"search_parameters": "on"},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &schemas.BifrostChatRequest{
+				Provider: schemas.XAI,
+				Model:    tt.model,
+				Input: []schemas.ChatMessage{
+					{
+						Role:    schemas.ChatMessageRoleUser,
+						Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr("What is new on X today?")},
+					},
+				},
+				Params: &schemas.ChatParameters{
+					ReasoningEffort: tt.reasoningEffort,
+					ExtraParams:     tt.extraParams,
+				},
+			}
+
+			actual, err := xai.ToXAIChatRequest(request)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, actual)
+			assert.Equal(t, tt.expectedReasoningEffort, actual.ReasoningEffort)
+			assert.Equal(t, tt.expectedSearchParameters, actual.SearchParameters)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package bifrost
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// requestIDHeaders are the headers the providers accept to tag a request with an ID of the client, shown in their
+// logs and support tools. The ID of the request is forwarded in them so that a request can be followed from the
+// gateway to the provider.
+var requestIDHeaders = map[schemas.ModelProvider]string{
+	schemas.OpenAI: "X-Client-Request-Id",
+	schemas.Azure:  "X-Ms-Client-Request-Id",
+}
+
+// withRequestID returns the context with the ID of the request, a new ID is generated for requests without one
+func withRequestID(ctx context.Context) (context.Context, string) {
+	if requestID, ok := ctx.Value(schemas.BifrostContextKeyRequestID).(string); ok && requestID != "" {
+		return ctx, requestID
+	}
+	requestID := uuid.New().String()
+	return context.WithValue(ctx, schemas.BifrostContextKeyRequestID, requestID), requestID
+}
+
+// withProviderRequestID adds the ID of the request to the extra headers of the request to the provider, in the
+// request ID header of the provider. Headers of the same name sent by the client are kept.
+func withProviderRequestID(ctx context.Context, provider schemas.ModelProvider) context.Context {
+	header, ok := requestIDHeaders[provider]
+	if !ok {
+		return ctx
+	}
+	requestID, _ := ctx.Value(schemas.BifrostContextKeyRequestID).(string)
+	if requestID == "" {
+		return ctx
+	}
+	extraHeaders, _ := ctx.Value(schemas.BifrostContextKeyExtraHeaders).(map[string][]string)
+	headers := make(map[string][]string, len(extraHeaders)+1)
+	for key, values := range extraHeaders {
+		if strings.EqualFold(key, header) {
+			return ctx
+		}
+		headers[key] = values
+	}
+	headers[header] = []string{requestID}
+	return context.WithValue(ctx, schemas.BifrostContextKeyExtraHeaders, headers)
+}
+
+// setRequestID sets the ID of the request on the extra fields of its response or error
+func setRequestID(requestID string, response *schemas.BifrostResponse, bifrostErr *schemas.BifrostError) {
+	if response != nil {
+		response.GetExtraFields().RequestID = requestID
+	}
+	if bifrostErr != nil {
+		bifrostErr.ExtraFields.RequestID = requestID
+	}
+}
+
+// requestLogger prefixes the log lines of a request with its ID
+type requestLogger struct {
+	schemas.Logger
+	prefix string
+}
+
+// newRequestLogger returns the logger of the request of the context, the logger is returned as it is for contexts
+// without request ID
+func newRequestLogger(logger schemas.Logger, ctx context.Context) schemas.Logger {
+	requestID, _ := ctx.Value(schemas.BifrostContextKeyRequestID).(string)
+	if requestID == "" {
+		return logger
+	}
+	// The prefix is part of the format of the log line
+	return &requestLogger{Logger: logger, prefix: "[request_id=" + strings.ReplaceAll(requestID, "%", "%%") + "] "}
+}
+
+func (l *requestLogger) Debug(msg string, args ...any) { l.Logger.Debug(l.prefix+msg, args...) }
+func (l *requestLogger) Info(msg string, args ...any)  { l.Logger.Info(l.prefix+msg, args...) }
+func (l *requestLogger) Warn(msg string, args ...any)  { l.Logger.Warn(l.prefix+msg, args...) }
+func (l *requestLogger) Error(msg string, args ...any) { l.Logger.Error(l.prefix+msg, args...) }
+func (l *requestLogger) Fatal(msg string, args ...any) { l.Logger.Fatal(l.prefix+msg, args...) }
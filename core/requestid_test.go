@@ -0,0 +1,61 @@
+package bifrost
+
+import (
+	"context"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestWithProviderRequestID tests that the ID of the request is forwarded in the request ID header of the providers
+// accepting one, without overriding the header sent by the client
+func TestWithProviderRequestID(t *testing.T) {
+	tests := []struct {
+		name         string
+		provider     schemas.ModelProvider
+		extraHeaders map[string][]string
+		wantHeader   string
+		wantValue    string
+	}{
+		{name: "openai", provider: schemas.OpenAI, wantHeader: "X-Client-Request-Id", wantValue: "req-1"},
+		{name: "azure", provider: schemas.Azure, wantHeader: "X-Ms-Client-Request-Id", wantValue: "req-1"},
+		{
+			name:         "client header kept",
+			provider:     schemas.OpenAI,
+			extraHeaders: map[string][]string{"x-client-request-id": {"client-id"}},
+			wantHeader:   "x-client-request-id",
+			wantValue:    "client-id",
+		},
+		{name: "provider without request ID header", provider: schemas.Anthropic},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), schemas.BifrostContextKeyRequestID, "req-1")
+			if tt.extraHeaders != nil {
+				ctx = context.WithValue(ctx, schemas.BifrostContextKeyExtraHeaders, tt.extraHeaders)
+			}
+			headers, _ := withProviderRequestID(ctx, tt.provider).Value(schemas.BifrostContextKeyExtraHeaders).(map[string][]string)
+			if tt.wantHeader == "" {
+				if len(headers) != 0 {
+					t.Errorf("expected no extra headers, got %v", headers)
+				}
+				return
+			}
+			if len(headers) != 1 || len(headers[tt.wantHeader]) != 1 || headers[tt.wantHeader][0] != tt.wantValue {
+				t.Errorf("expected %s: %s, got %v", tt.wantHeader, tt.wantValue, headers)
+			}
+		})
+	}
+}
+
+// TestWithRequestID tests that requests without ID get one and that the ID of the client is kept
+func TestWithRequestID(t *testing.T) {
+	ctx, requestID := withRequestID(context.Background())
+	if requestID == "" || ctx.Value(schemas.BifrostContextKeyRequestID) != requestID {
+		t.Errorf("expected a generated request ID in the context, got %q", requestID)
+	}
+	if _, again := withRequestID(ctx); again != requestID {
+		t.Errorf("expected the request ID %q to be kept, got %q", requestID, again)
+	}
+}
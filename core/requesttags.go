@@ -0,0 +1,71 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// withRequestTags replaces the tags of the request in the context with the tags of the allow-list, from the tags set
+// by the caller and the string values of the metadata parameter of the request. Tags set by the caller take
+// precedence over the metadata. Tags set by the caller outside the allow-list are dropped, or rejected when the
+// config rejects unknown tags.
+func (bifrost *Bifrost) withRequestTags(ctx context.Context, req *schemas.BifrostRequest) (context.Context, *schemas.BifrostError) {
+	callerTags, _ := ctx.Value(schemas.BifrostContextKeyRequestTags).(map[string]string)
+	config := bifrost.requestTags.Load()
+	if config == nil {
+		if callerTags != nil {
+			return context.WithValue(ctx, schemas.BifrostContextKeyRequestTags, map[string]string(nil)), nil
+		}
+		return ctx, nil
+	}
+
+	tags := make(map[string]string)
+	for name, value := range requestMetadata(req) {
+		if str, ok := value.(string); ok && config.Allows(name, str) {
+			tags[name] = str
+		}
+	}
+	var rejected []string
+	for name, value := range callerTags {
+		if !config.Allows(name, value) {
+			rejected = append(rejected, name)
+			continue
+		}
+		tags[name] = value
+	}
+	if len(rejected) > 0 && config.RejectUnknown {
+		sort.Strings(rejected)
+		return ctx, &schemas.BifrostError{
+			IsBifrostError: true,
+			StatusCode:     schemas.Ptr(http.StatusBadRequest),
+			Type:           schemas.Ptr("invalid_request_tags"),
+			Error: &schemas.ErrorField{
+				Message: fmt.Sprintf("request tags not in the allow-list: %s", strings.Join(rejected, ", ")),
+			},
+		}
+	}
+	if len(tags) == 0 {
+		tags = nil
+	}
+	return context.WithValue(ctx, schemas.BifrostContextKeyRequestTags, tags), nil
+}
+
+// requestMetadata returns the metadata parameter of the request, or nil if it has none
+func requestMetadata(req *schemas.BifrostRequest) map[string]any {
+	var metadata *map[string]any
+	switch {
+	case req.ChatRequest != nil && req.ChatRequest.Params != nil:
+		metadata = req.ChatRequest.Params.Metadata
+	case req.ResponsesRequest != nil && req.ResponsesRequest.Params != nil:
+		metadata = req.ResponsesRequest.Params.Metadata
+	}
+	if metadata == nil {
+		return nil
+	}
+	return *metadata
+}
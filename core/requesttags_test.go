@@ -0,0 +1,42 @@
+package bifrost
+
+import (
+	"context"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestWithRequestTags tests that header and metadata tags are kept when in the allow-list, header tags taking
+// precedence, and that unknown header tags are dropped or rejected
+func TestWithRequestTags(t *testing.T) {
+	bifrost := &Bifrost{logger: NewDefaultLogger(schemas.LogLevelError)}
+	bifrost.requestTags.Store(&schemas.RequestTagsConfig{Tags: []schemas.RequestTagRule{
+		{Name: "feature", Values: []string{"search", "chat"}},
+		{Name: "team"},
+	}})
+
+	req := transformChatRequest()
+	req.ChatRequest.Params = &schemas.ChatParameters{Metadata: &map[string]any{"feature": "chat", "team": "growth", "trace": "abc"}}
+	ctx := context.WithValue(context.Background(), schemas.BifrostContextKeyRequestTags, map[string]string{"feature": "search", "env": "prod"})
+	ctx, err := bifrost.withRequestTags(ctx, req)
+	if err != nil {
+		t.Fatalf("expected unknown header tags to be dropped, got %v", err)
+	}
+	tags, _ := ctx.Value(schemas.BifrostContextKeyRequestTags).(map[string]string)
+	if len(tags) != 2 || tags["feature"] != "search" || tags["team"] != "growth" {
+		t.Errorf("expected the allowed header and metadata tags, got %v", tags)
+	}
+
+	bifrost.requestTags.Store(&schemas.RequestTagsConfig{Tags: []schemas.RequestTagRule{{Name: "feature", Values: []string{"chat"}}}, RejectUnknown: true})
+	ctx = context.WithValue(context.Background(), schemas.BifrostContextKeyRequestTags, map[string]string{"feature": "search"})
+	if _, err := bifrost.withRequestTags(ctx, transformChatRequest()); err == nil || err.StatusCode == nil || *err.StatusCode != 400 {
+		t.Errorf("expected a value outside the allow-list to be rejected, got %v", err)
+	}
+
+	bifrost.requestTags.Store(nil)
+	ctx, _ = bifrost.withRequestTags(ctx, transformChatRequest())
+	if tags, _ := ctx.Value(schemas.BifrostContextKeyRequestTags).(map[string]string); tags != nil {
+		t.Errorf("expected no tags without an allow-list, got %v", tags)
+	}
+}
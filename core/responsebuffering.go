@@ -0,0 +1,301 @@
+package bifrost
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// shouldStreamUpstream reports whether a non-stream request to the provider is served from a provider stream,
+// from the x-bf-stream-upstream header of the request or the response buffering config
+func (bifrost *Bifrost) shouldStreamUpstream(ctx context.Context, provider schemas.ModelProvider) bool {
+	if ctx != nil {
+		if streamUpstream, ok := ctx.Value(schemas.BifrostContextKeyStreamUpstream).(bool); ok {
+			return streamUpstream
+		}
+	}
+	return bifrost.responseBuffering.Load().Applies(provider)
+}
+
+// handleBufferedRequest sends a non-stream chat or text completion request to its provider as a stream, and
+// aggregates the stream into the response of the non-stream request. Failures before the first chunk go through
+// the fallbacks like any stream, failures after it are returned as the error of the request.
+func (bifrost *Bifrost) handleBufferedRequest(ctx context.Context, req *schemas.BifrostRequest) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	requestType := req.RequestType
+	provider, model, _ := req.GetRequestFields()
+	switch requestType {
+	case schemas.ChatCompletionRequest:
+		req.RequestType = schemas.ChatCompletionStreamRequest
+	case schemas.TextCompletionRequest:
+		req.RequestType = schemas.TextCompletionStreamRequest
+	default:
+		return bifrost.handleRequest(ctx, req)
+	}
+
+	startTime := time.Now()
+	stream, err := bifrost.handleStreamRequest(ctx, req)
+	if err != nil {
+		err.ExtraFields.RequestType = requestType
+		return nil, err
+	}
+
+	aggregator := newStreamAggregator(requestType)
+	var streamErr *schemas.BifrostError
+	for chunk := range stream {
+		if chunk == nil || streamErr != nil {
+			// Keep reading after an error, so that the provider is not blocked on the stream
+			schemas.ReleaseBifrostStream(chunk)
+			continue
+		}
+		if chunk.BifrostError != nil {
+			streamErr = chunk.BifrostError
+			schemas.ReleaseBifrostStream(chunk)
+			continue
+		}
+		if aggregator.chunks == 0 {
+			aggregator.timeToFirstToken = time.Since(startTime).Milliseconds()
+		}
+		// The aggregator keeps the responses of the chunk, not the chunk itself
+		aggregator.add(chunk)
+		schemas.ReleaseBifrostStream(chunk)
+	}
+	if streamErr != nil {
+		streamErr.ExtraFields.RequestType = requestType
+		return nil, streamErr
+	}
+	if aggregator.chunks == 0 {
+		return nil, &schemas.BifrostError{
+			IsBifrostError: true,
+			StatusCode:     schemas.Ptr(502),
+			Error: &schemas.ErrorField{
+				Message: "provider stream ended without any chunk",
+			},
+			ExtraFields: schemas.BifrostErrorExtraFields{
+				RequestType:    requestType,
+				Provider:       provider,
+				ModelRequested: model,
+			},
+		}
+	}
+	return aggregator.response(time.Since(startTime).Milliseconds()), nil
+}
+
+// aggregatedChoice accumulates the deltas of a choice of a stream
+type aggregatedChoice struct {
+	index        int
+	finishReason *string
+	logProbs     *schemas.BifrostLogProbs
+	role         schemas.ChatMessageRole
+	content      strings.Builder
+	hasContent   bool
+	assistant    *schemas.ChatAssistantMessage
+}
+
+// streamAggregator builds the non-stream response of a chat or text completion request from the chunks of its stream
+type streamAggregator struct {
+	requestType      schemas.RequestType
+	chunks           int
+	timeToFirstToken int64
+	choices          []*aggregatedChoice
+
+	// Fields of the chunks, the last value sent wins
+	id                string
+	model             string
+	created           int
+	serviceTier       string
+	systemFingerprint string
+	usage             *schemas.BifrostLLMUsage
+	extraFields       schemas.BifrostResponseExtraFields
+	searchResults     []schemas.SearchResult
+	videos            []schemas.VideoResult
+	citations         []string
+}
+
+// newStreamAggregator creates the aggregator of a stream served to a request of the given non-stream type
+func newStreamAggregator(requestType schemas.RequestType) *streamAggregator {
+	return &streamAggregator{requestType: requestType}
+}
+
+// choice returns the accumulated choice with the given index
+func (a *streamAggregator) choice(index int) *aggregatedChoice {
+	for _, choice := range a.choices {
+		if choice.index == index {
+			return choice
+		}
+	}
+	choice := &aggregatedChoice{index: index, role: schemas.ChatMessageRoleAssistant}
+	a.choices = append(a.choices, choice)
+	return choice
+}
+
+// add accumulates a chunk of the stream
+func (a *streamAggregator) add(chunk *schemas.BifrostStream) {
+	a.chunks++
+	var choices []schemas.BifrostResponseChoice
+	switch {
+	case chunk.BifrostChatResponse != nil:
+		resp := chunk.BifrostChatResponse
+		choices = resp.Choices
+		a.addMetadata(resp.ID, resp.Model, resp.SystemFingerprint, resp.Usage, resp.ExtraFields)
+		if resp.Created != 0 {
+			a.created = resp.Created
+		}
+		if resp.ServiceTier != "" {
+			a.serviceTier = resp.ServiceTier
+		}
+		if len(resp.SearchResults) > 0 {
+			a.searchResults = resp.SearchResults
+		}
+		if len(resp.Videos) > 0 {
+			a.videos = resp.Videos
+		}
+		if len(resp.Citations) > 0 {
+			a.citations = resp.Citations
+		}
+	case chunk.BifrostTextCompletionResponse != nil:
+		resp := chunk.BifrostTextCompletionResponse
+		choices = resp.Choices
+		a.addMetadata(resp.ID, resp.Model, resp.SystemFingerprint, resp.Usage, resp.ExtraFields)
+	default:
+		return
+	}
+
+	for _, streamChoice := range choices {
+		choice := a.choice(streamChoice.Index)
+		if streamChoice.FinishReason != nil {
+			choice.finishReason = streamChoice.FinishReason
+		}
+		if streamChoice.LogProbs != nil {
+			if choice.logProbs == nil {
+				choice.logProbs = &schemas.BifrostLogProbs{}
+			}
+			choice.logProbs.Content = append(choice.logProbs.Content, streamChoice.LogProbs.Content...)
+			choice.logProbs.Refusal = append(choice.logProbs.Refusal, streamChoice.LogProbs.Refusal...)
+		}
+		if streamChoice.TextCompletionResponseChoice != nil && streamChoice.Text != nil {
+			choice.content.WriteString(*streamChoice.Text)
+			choice.hasContent = true
+		}
+		if streamChoice.ChatStreamResponseChoice != nil && streamChoice.Delta != nil {
+			choice.addDelta(streamChoice.Delta)
+		}
+	}
+}
+
+// addMetadata keeps the fields of the response sent with the chunks
+func (a *streamAggregator) addMetadata(id string, model string, systemFingerprint string, usage *schemas.BifrostLLMUsage, extraFields schemas.BifrostResponseExtraFields) {
+	if id != "" {
+		a.id = id
+	}
+	if model != "" {
+		a.model = model
+	}
+	if systemFingerprint != "" {
+		a.systemFingerprint = systemFingerprint
+	}
+	if usage != nil {
+		a.usage = usage
+	}
+	a.extraFields = extraFields
+}
+
+// addDelta accumulates the delta of a chat stream choice
+func (c *aggregatedChoice) addDelta(delta *schemas.ChatStreamResponseChoiceDelta) {
+	if delta.Role != nil && *delta.Role != "" {
+		c.role = schemas.ChatMessageRole(*delta.Role)
+	}
+	if delta.Content != nil {
+		c.content.WriteString(*delta.Content)
+		c.hasContent = true
+	}
+	if delta.Refusal == nil && delta.Thought == nil && delta.ThoughtSignature == nil && len(delta.ToolCalls) == 0 {
+		return
+	}
+	if c.assistant == nil {
+		c.assistant = &schemas.ChatAssistantMessage{}
+	}
+	if delta.Refusal != nil {
+		c.assistant.Refusal = schemas.Ptr(stringValue(c.assistant.Refusal) + *delta.Refusal)
+	}
+	if delta.Thought != nil {
+		c.assistant.Thought = schemas.Ptr(stringValue(c.assistant.Thought) + *delta.Thought)
+	}
+	if delta.ThoughtSignature != nil {
+		c.assistant.ThoughtSignature = delta.ThoughtSignature
+	}
+	for _, toolCall := range delta.ToolCalls {
+		// A delta with a name starts a new tool call, the others continue the arguments of the last one
+		if toolCall.Function.Name != nil || len(c.assistant.ToolCalls) == 0 {
+			toolCall.Index = uint16(len(c.assistant.ToolCalls))
+			c.assistant.ToolCalls = append(c.assistant.ToolCalls, toolCall)
+			continue
+		}
+		c.assistant.ToolCalls[len(c.assistant.ToolCalls)-1].Function.Arguments += toolCall.Function.Arguments
+	}
+}
+
+// stringValue returns the value of a string pointer, or an empty string if it is nil
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// response builds the non-stream response from the accumulated chunks
+func (a *streamAggregator) response(latency int64) *schemas.BifrostResponse {
+	extraFields := a.extraFields
+	extraFields.RequestType = a.requestType
+	extraFields.Latency = latency
+	extraFields.ChunkIndex = 0
+	extraFields.StreamFailover = nil
+	extraFields.TimeToFirstToken = a.timeToFirstToken
+
+	choices := make([]schemas.BifrostResponseChoice, 0, len(a.choices))
+	for _, choice := range a.choices {
+		responseChoice := schemas.BifrostResponseChoice{
+			Index:        choice.index,
+			FinishReason: choice.finishReason,
+			LogProbs:     choice.logProbs,
+		}
+		content := choice.content.String()
+		if a.requestType == schemas.TextCompletionRequest {
+			responseChoice.TextCompletionResponseChoice = &schemas.TextCompletionResponseChoice{Text: &content}
+		} else {
+			message := &schemas.ChatMessage{Role: choice.role, ChatAssistantMessage: choice.assistant}
+			if choice.hasContent || choice.assistant == nil {
+				message.Content = &schemas.ChatMessageContent{ContentStr: &content}
+			}
+			responseChoice.ChatNonStreamResponseChoice = &schemas.ChatNonStreamResponseChoice{Message: message}
+		}
+		choices = append(choices, responseChoice)
+	}
+
+	if a.requestType == schemas.TextCompletionRequest {
+		return &schemas.BifrostResponse{TextCompletionResponse: &schemas.BifrostTextCompletionResponse{
+			ID:                a.id,
+			Choices:           choices,
+			Model:             a.model,
+			Object:            "text_completion",
+			SystemFingerprint: a.systemFingerprint,
+			Usage:             a.usage,
+			ExtraFields:       extraFields,
+		}}
+	}
+	return &schemas.BifrostResponse{ChatResponse: &schemas.BifrostChatResponse{
+		ID:                a.id,
+		Choices:           choices,
+		Created:           a.created,
+		Model:             a.model,
+		Object:            "chat.completion",
+		ServiceTier:       a.serviceTier,
+		SystemFingerprint: a.systemFingerprint,
+		Usage:             a.usage,
+		ExtraFields:       extraFields,
+		SearchResults:     a.searchResults,
+		Videos:            a.videos,
+		Citations:         a.citations,
+	}}
+}
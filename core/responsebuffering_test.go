@@ -0,0 +1,79 @@
+package bifrost
+
+import (
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestStreamAggregator_Chat tests that the deltas of a chat stream are aggregated into a chat completion
+func TestStreamAggregator_Chat(t *testing.T) {
+	delta := func(delta schemas.ChatStreamResponseChoiceDelta, finishReason *string) *schemas.BifrostStream {
+		return &schemas.BifrostStream{BifrostChatResponse: &schemas.BifrostChatResponse{
+			ID:     "chatcmpl-1",
+			Model:  "gpt-4o",
+			Object: "chat.completion.chunk",
+			Choices: []schemas.BifrostResponseChoice{{
+				FinishReason:             finishReason,
+				ChatStreamResponseChoice: &schemas.ChatStreamResponseChoice{Delta: &delta},
+			}},
+			ExtraFields: schemas.BifrostResponseExtraFields{RequestType: schemas.ChatCompletionStreamRequest, Provider: schemas.OpenAI, ChunkIndex: 3},
+		}}
+	}
+
+	aggregator := newStreamAggregator(schemas.ChatCompletionRequest)
+	aggregator.add(delta(schemas.ChatStreamResponseChoiceDelta{Role: schemas.Ptr("assistant"), Content: schemas.Ptr("Let me ")}, nil))
+	aggregator.add(delta(schemas.ChatStreamResponseChoiceDelta{Content: schemas.Ptr("check.")}, nil))
+	aggregator.add(delta(schemas.ChatStreamResponseChoiceDelta{ToolCalls: []schemas.ChatAssistantMessageToolCall{{
+		ID:       schemas.Ptr("call_1"),
+		Function: schemas.ChatAssistantMessageToolCallFunction{Name: schemas.Ptr("get_weather"), Arguments: `{"city":`},
+	}}}, nil))
+	aggregator.add(delta(schemas.ChatStreamResponseChoiceDelta{ToolCalls: []schemas.ChatAssistantMessageToolCall{{
+		Function: schemas.ChatAssistantMessageToolCallFunction{Arguments: `"Paris"}`},
+	}}}, schemas.Ptr("tool_calls")))
+	aggregator.add(&schemas.BifrostStream{BifrostChatResponse: &schemas.BifrostChatResponse{
+		Choices: []schemas.BifrostResponseChoice{},
+		Usage:   &schemas.BifrostLLMUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+	}})
+
+	response := aggregator.response(120).ChatResponse
+	if response == nil || response.Object != "chat.completion" || response.ID != "chatcmpl-1" {
+		t.Fatalf("expected a chat completion, got %+v", response)
+	}
+	if response.ExtraFields.RequestType != schemas.ChatCompletionRequest || response.ExtraFields.ChunkIndex != 0 || response.ExtraFields.Latency != 120 {
+		t.Errorf("expected the extra fields of a non-stream response, got %+v", response.ExtraFields)
+	}
+	if response.Usage == nil || response.Usage.TotalTokens != 15 {
+		t.Errorf("expected the usage of the last chunk, got %+v", response.Usage)
+	}
+	if len(response.Choices) != 1 || response.Choices[0].ChatNonStreamResponseChoice == nil {
+		t.Fatalf("expected a single message choice, got %+v", response.Choices)
+	}
+	choice := response.Choices[0]
+	if choice.FinishReason == nil || *choice.FinishReason != "tool_calls" {
+		t.Errorf("expected the tool_calls finish reason, got %v", choice.FinishReason)
+	}
+	message := choice.Message
+	if message.Role != schemas.ChatMessageRoleAssistant || *message.Content.ContentStr != "Let me check." {
+		t.Errorf("expected the concatenated content, got %q", *message.Content.ContentStr)
+	}
+	if message.ChatAssistantMessage == nil || len(message.ToolCalls) != 1 || message.ToolCalls[0].Function.Arguments != `{"city":"Paris"}` {
+		t.Errorf("expected a single tool call with its concatenated arguments, got %+v", message.ChatAssistantMessage)
+	}
+}
+
+// TestStreamAggregator_Text tests that the chunks of a text completion stream are aggregated into a text completion
+func TestStreamAggregator_Text(t *testing.T) {
+	aggregator := newStreamAggregator(schemas.TextCompletionRequest)
+	for _, text := range []string{"Once ", "upon ", "a time"} {
+		aggregator.add(&schemas.BifrostStream{BifrostTextCompletionResponse: &schemas.BifrostTextCompletionResponse{
+			Model:   "gpt-3.5-turbo-instruct",
+			Choices: []schemas.BifrostResponseChoice{{TextCompletionResponseChoice: &schemas.TextCompletionResponseChoice{Text: schemas.Ptr(text)}}},
+		}})
+	}
+
+	response := aggregator.response(50).TextCompletionResponse
+	if response == nil || len(response.Choices) != 1 || *response.Choices[0].Text != "Once upon a time" {
+		t.Fatalf("expected the concatenated text, got %+v", response)
+	}
+}
@@ -0,0 +1,195 @@
+package bifrost
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	providerUtils "github.com/maximhq/bifrost/core/providers/utils"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// responsesStateEntry is a stored response, with the input it was generated from and the response it continued
+type responsesStateEntry struct {
+	parent    *responsesStateEntry
+	input     []schemas.ResponsesMessage
+	output    []schemas.ResponsesMessage
+	expiresAt time.Time
+}
+
+// responsesStateStore holds the recent responses per caller and response ID, dropping the oldest above its capacity
+type responsesStateStore struct {
+	mu      sync.Mutex
+	entries map[string]*responsesStateEntry
+	order   []string // Keys in the order they were stored, the oldest first
+}
+
+// newResponsesStateStore creates a new, empty responses state store
+func newResponsesStateStore() *responsesStateStore {
+	return &responsesStateStore{
+		entries: make(map[string]*responsesStateEntry),
+	}
+}
+
+// responsesStateKey returns the key of a response of the caller of the context
+func responsesStateKey(ctx context.Context, responseID string) string {
+	return idempotencyScope(ctx) + "\x00" + responseID
+}
+
+// get returns the entry stored for the key, or nil if it is unknown or expired
+func (s *responsesStateStore) get(key string) *responsesStateEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok || !time.Now().Before(entry.expiresAt) {
+		return nil
+	}
+	return entry
+}
+
+// put stores the entry for the key, then drops expired entries and the oldest ones above capacity
+func (s *responsesStateStore) put(key string, entry *responsesStateEntry, capacity int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[key]; !ok {
+		s.order = append(s.order, key)
+	}
+	s.entries[key] = entry
+
+	now := time.Now()
+	for len(s.order) > 0 {
+		oldest, ok := s.entries[s.order[0]]
+		if ok && len(s.entries) <= capacity && now.Before(oldest.expiresAt) {
+			break
+		}
+		delete(s.entries, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+// history returns the conversation up to and including the entry, oldest messages first
+func (e *responsesStateEntry) history() []schemas.ResponsesMessage {
+	var chain []*responsesStateEntry
+	for entry := e; entry != nil; entry = entry.parent {
+		chain = append(chain, entry)
+	}
+	var messages []schemas.ResponsesMessage
+	for i := len(chain) - 1; i >= 0; i-- {
+		messages = append(messages, chain[i].input...)
+		messages = append(messages, chain[i].output...)
+	}
+	return messages
+}
+
+// responsesHistoryItems returns the output of a response as input items of the next request. Item IDs are
+// dropped since they are only known to the provider that generated them, and so is reasoning that cannot be sent
+// back without its encrypted content.
+func responsesHistoryItems(output []schemas.ResponsesMessage) []schemas.ResponsesMessage {
+	items := make([]schemas.ResponsesMessage, 0, len(output))
+	for _, item := range output {
+		if item.Type != nil && *item.Type == schemas.ResponsesMessageTypeReasoning &&
+			(item.ResponsesReasoning == nil || item.ResponsesReasoning.EncryptedContent == nil) {
+			continue
+		}
+		item.ID = nil
+		items = append(items, item)
+	}
+	return items
+}
+
+// expandResponsesRequest returns the request to send for req and the stored response it continues. When the
+// previous_response_id of the request is stored for its caller, the returned copy carries the whole conversation
+// instead, so that any key or provider can serve it. Otherwise the request is returned as it is and the parent is nil.
+func (bifrost *Bifrost) expandResponsesRequest(ctx context.Context, req *schemas.BifrostResponsesRequest) (*schemas.BifrostResponsesRequest, *responsesStateEntry) {
+	if ctx == nil || bifrost.responsesState.Load() == nil || req.Params == nil || req.Params.PreviousResponseID == nil {
+		return req, nil
+	}
+	parent := bifrost.responsesEntries.get(responsesStateKey(ctx, *req.Params.PreviousResponseID))
+	if parent == nil {
+		return req, nil
+	}
+
+	expanded := *req
+	params := *req.Params
+	params.PreviousResponseID = nil
+	expanded.Params = &params
+	expanded.Input = append(parent.history(), req.Input...)
+	// The raw body still references the previous response
+	expanded.RawRequestBody = nil
+	return &expanded, parent
+}
+
+// storeResponsesState stores the response generated for input as the continuation of parent. Responses without an
+// ID are given one, and responses requested with store set to false are not stored.
+func (bifrost *Bifrost) storeResponsesState(ctx context.Context, req *schemas.BifrostResponsesRequest, parent *responsesStateEntry, response *schemas.BifrostResponsesResponse) {
+	config := bifrost.responsesState.Load()
+	if ctx == nil || config == nil || response == nil {
+		return
+	}
+	if response.ID == nil {
+		response.ID = schemas.Ptr(newResponseID())
+	}
+	if req.Params != nil && req.Params.Store != nil && !*req.Params.Store {
+		return
+	}
+	// A previous_response_id left to the provider cannot be expanded later on another provider
+	if parent == nil && req.Params != nil && req.Params.PreviousResponseID != nil {
+		return
+	}
+	bifrost.responsesEntries.put(responsesStateKey(ctx, *response.ID), &responsesStateEntry{
+		parent:    parent,
+		input:     req.Input,
+		output:    responsesHistoryItems(response.Output),
+		expiresAt: time.Now().Add(config.TTL()),
+	}, config.Capacity())
+}
+
+// newResponseID returns an ID for a response generated by a provider that does not return one
+func newResponseID() string {
+	return "resp_" + uuid.NewString()
+}
+
+// withResponsesState stores the response of a responses stream once it completed. Responses without an ID are
+// given one on every chunk, and the output items are collected from the output_item.done events.
+func (bifrost *Bifrost) withResponsesState(ctx context.Context, req *schemas.BifrostResponsesRequest, parent *responsesStateEntry, stream chan *schemas.BifrostStream) chan *schemas.BifrostStream {
+	out := make(chan *schemas.BifrostStream, providerUtils.GetStreamBufferSize(ctx))
+	go func() {
+		defer close(out)
+		responseID := newResponseID()
+		var output []schemas.ResponsesMessage
+		for chunk := range stream {
+			if chunk != nil && chunk.BifrostResponsesStreamResponse != nil {
+				event := chunk.BifrostResponsesStreamResponse
+				if event.Response != nil {
+					if event.Response.ID == nil {
+						event.Response.ID = schemas.Ptr(responseID)
+					} else {
+						responseID = *event.Response.ID
+					}
+				}
+				switch event.Type {
+				case schemas.ResponsesStreamResponseTypeOutputItemDone:
+					if event.Item != nil {
+						output = append(output, *event.Item)
+					}
+				case schemas.ResponsesStreamResponseTypeCompleted:
+					completed := &schemas.BifrostResponsesResponse{ID: schemas.Ptr(responseID), Output: output}
+					if event.Response != nil && len(event.Response.Output) > 0 {
+						completed.Output = event.Response.Output
+					}
+					bifrost.storeResponsesState(ctx, req, parent, completed)
+				}
+			}
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				// The client is gone, drain the upstream stream so that its provider is not blocked
+				for range stream {
+				}
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,131 @@
+package bifrost
+
+import (
+	"context"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+func newResponsesStateTestBifrost() *Bifrost {
+	bifrost := &Bifrost{responsesEntries: newResponsesStateStore()}
+	bifrost.responsesState.Store(&schemas.ResponsesStateConfig{})
+	return bifrost
+}
+
+func responsesStateTestMessage(role schemas.ResponsesMessageRoleType, text string) schemas.ResponsesMessage {
+	return schemas.ResponsesMessage{
+		ID:      schemas.Ptr("msg_" + text),
+		Type:    schemas.Ptr(schemas.ResponsesMessageTypeMessage),
+		Role:    schemas.Ptr(role),
+		Content: &schemas.ResponsesMessageContent{ContentStr: schemas.Ptr(text)},
+	}
+}
+
+// TestResponsesState_ExpandsStoredConversations tests that a request continuing a stored response is sent with the
+// whole conversation, and only for the caller that stored it
+func TestResponsesState_ExpandsStoredConversations(t *testing.T) {
+	bifrost := newResponsesStateTestBifrost()
+	ctx := context.WithValue(context.Background(), schemas.BifrostContextKeyVirtualKey, "sk-bf-a")
+
+	first := &schemas.BifrostResponsesRequest{
+		Provider: schemas.OpenAI,
+		Model:    "gpt-4o",
+		Input:    []schemas.ResponsesMessage{responsesStateTestMessage(schemas.ResponsesInputMessageRoleUser, "hi")},
+	}
+	expanded, parent := bifrost.expandResponsesRequest(ctx, first)
+	if expanded != first || parent != nil {
+		t.Fatal("expected a request without previous_response_id to be sent as it is")
+	}
+	response := &schemas.BifrostResponsesResponse{Output: []schemas.ResponsesMessage{
+		{Type: schemas.Ptr(schemas.ResponsesMessageTypeReasoning), ResponsesReasoning: &schemas.ResponsesReasoning{}},
+		responsesStateTestMessage(schemas.ResponsesInputMessageRoleAssistant, "hello"),
+	}}
+	bifrost.storeResponsesState(ctx, first, parent, response)
+	if response.ID == nil {
+		t.Fatal("expected a response without ID to be given one")
+	}
+
+	second := &schemas.BifrostResponsesRequest{
+		Provider: schemas.Anthropic,
+		Model:    "claude-sonnet-4",
+		Input:    []schemas.ResponsesMessage{responsesStateTestMessage(schemas.ResponsesInputMessageRoleUser, "again")},
+		Params:   &schemas.ResponsesParameters{PreviousResponseID: response.ID},
+	}
+	expanded, parent = bifrost.expandResponsesRequest(ctx, second)
+	if parent == nil || expanded.Params.PreviousResponseID != nil || second.Params.PreviousResponseID == nil {
+		t.Fatal("expected a copy of the request without previous_response_id")
+	}
+	if len(expanded.Input) != 3 || *expanded.Input[1].Content.ContentStr != "hello" || expanded.Input[1].ID != nil {
+		t.Fatalf("expected the conversation without reasoning and item IDs, got %+v", expanded.Input)
+	}
+	bifrost.storeResponsesState(ctx, second, parent, &schemas.BifrostResponsesResponse{
+		ID:     schemas.Ptr("resp_2"),
+		Output: []schemas.ResponsesMessage{responsesStateTestMessage(schemas.ResponsesInputMessageRoleAssistant, "hello again")},
+	})
+
+	third := &schemas.BifrostResponsesRequest{Params: &schemas.ResponsesParameters{PreviousResponseID: schemas.Ptr("resp_2")}}
+	if expanded, _ := bifrost.expandResponsesRequest(ctx, third); len(expanded.Input) != 4 {
+		t.Errorf("expected the conversation of every previous response, got %d items", len(expanded.Input))
+	}
+	other := context.WithValue(context.Background(), schemas.BifrostContextKeyVirtualKey, "sk-bf-b")
+	if _, parent := bifrost.expandResponsesRequest(other, third); parent != nil {
+		t.Error("expected responses of other callers to be left to the provider")
+	}
+}
+
+// TestResponsesState_Storage tests that responses requested with store false are not stored and the oldest responses
+// are dropped above capacity
+func TestResponsesState_Storage(t *testing.T) {
+	bifrost := newResponsesStateTestBifrost()
+	bifrost.responsesState.Store(&schemas.ResponsesStateConfig{MaxEntries: 2})
+	ctx := context.Background()
+
+	bifrost.storeResponsesState(ctx, &schemas.BifrostResponsesRequest{Params: &schemas.ResponsesParameters{Store: schemas.Ptr(false)}},
+		nil, &schemas.BifrostResponsesResponse{ID: schemas.Ptr("resp_private")})
+	if bifrost.responsesEntries.get(responsesStateKey(ctx, "resp_private")) != nil {
+		t.Error("expected a response requested with store false not to be stored")
+	}
+
+	for _, id := range []string{"resp_1", "resp_2", "resp_3"} {
+		bifrost.storeResponsesState(ctx, &schemas.BifrostResponsesRequest{}, nil, &schemas.BifrostResponsesResponse{ID: schemas.Ptr(id)})
+	}
+	if bifrost.responsesEntries.get(responsesStateKey(ctx, "resp_1")) != nil || bifrost.responsesEntries.get(responsesStateKey(ctx, "resp_3")) == nil {
+		t.Error("expected the oldest response to be dropped above capacity")
+	}
+}
+
+// TestResponsesState_Stream tests that a stream gets a response ID and is stored once completed
+func TestResponsesState_Stream(t *testing.T) {
+	bifrost := newResponsesStateTestBifrost()
+	ctx := context.Background()
+	req := &schemas.BifrostResponsesRequest{Input: []schemas.ResponsesMessage{responsesStateTestMessage(schemas.ResponsesInputMessageRoleUser, "hi")}}
+
+	stream := make(chan *schemas.BifrostStream, 3)
+	item := responsesStateTestMessage(schemas.ResponsesInputMessageRoleAssistant, "hello")
+	stream <- &schemas.BifrostStream{BifrostResponsesStreamResponse: &schemas.BifrostResponsesStreamResponse{
+		Type: schemas.ResponsesStreamResponseTypeCreated, Response: &schemas.BifrostResponsesResponse{},
+	}}
+	stream <- &schemas.BifrostStream{BifrostResponsesStreamResponse: &schemas.BifrostResponsesStreamResponse{
+		Type: schemas.ResponsesStreamResponseTypeOutputItemDone, Item: &item,
+	}}
+	stream <- &schemas.BifrostStream{BifrostResponsesStreamResponse: &schemas.BifrostResponsesStreamResponse{
+		Type: schemas.ResponsesStreamResponseTypeCompleted, Response: &schemas.BifrostResponsesResponse{},
+	}}
+	close(stream)
+
+	var id string
+	for chunk := range bifrost.withResponsesState(ctx, req, nil, stream) {
+		if chunk.Response == nil || chunk.Response.ID == nil {
+			continue
+		}
+		if id != "" && *chunk.Response.ID != id {
+			t.Fatalf("expected every chunk to carry the same response ID, got %s and %s", id, *chunk.Response.ID)
+		}
+		id = *chunk.Response.ID
+	}
+	entry := bifrost.responsesEntries.get(responsesStateKey(ctx, id))
+	if entry == nil || len(entry.history()) != 2 {
+		t.Fatalf("expected the completed stream to be stored with its output items, got %+v", entry)
+	}
+}
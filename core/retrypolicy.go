@@ -0,0 +1,184 @@
+package bifrost
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// retryBudgetWindow is the number of one second buckets over which retry budgets are computed
+const retryBudgetWindow = 10
+
+// defaultRetryPolicy returns the policy of providers without one: requests that could not be sent, 5xx, 429
+// and rate limit errors are retried up to MaxRetries times with the backoff of the network config
+func defaultRetryPolicy(network schemas.NetworkConfig) *schemas.RetryPolicy {
+	statusCodes := make([]int, 0, len(retryableStatusCodes))
+	for code := range retryableStatusCodes {
+		statusCodes = append(statusCodes, code)
+	}
+	return &schemas.RetryPolicy{Rules: []schemas.RetryRule{{
+		Name:             "default",
+		StatusCodes:      statusCodes,
+		MessagePatterns:  rateLimitPatterns,
+		ConnectionErrors: true,
+		MaxRetries:       network.MaxRetries,
+	}}}
+}
+
+// isConnectionError reports whether the request could not be sent to the provider
+func isConnectionError(err *schemas.BifrostError) bool {
+	return err.Error != nil && err.Error.Message == schemas.ErrProviderDoRequest
+}
+
+// errorFields returns the lowercased message, the type and the code of the error returned by the provider
+func errorFields(err *schemas.BifrostError) (message, errorType, errorCode string) {
+	if err.Error != nil {
+		message = strings.ToLower(err.Error.Message)
+		if err.Error.Type != nil {
+			errorType = *err.Error.Type
+		}
+		if err.Error.Code != nil {
+			errorCode = *err.Error.Code
+		}
+	}
+	if errorType == "" && err.Type != nil {
+		errorType = *err.Type
+	}
+	return message, errorType, errorCode
+}
+
+// matchRetryRule returns the index of the first rule of the policy matching the error, or -1 if none matches.
+// Errors raised by Bifrost itself, other than connection errors, and cancellations never match.
+func matchRetryRule(policy *schemas.RetryPolicy, err *schemas.BifrostError) int {
+	if err.Error != nil && err.Error.Type != nil && *err.Error.Type == schemas.RequestCancelled {
+		return -1
+	}
+	connectionError := isConnectionError(err)
+	if err.IsBifrostError && !connectionError {
+		return -1
+	}
+
+	message, errorType, errorCode := errorFields(err)
+	canonicalCode := errorCodeOf(err)
+	for i, rule := range policy.Rules {
+		if rule.ConnectionErrors && connectionError {
+			return i
+		}
+		if err.StatusCode != nil && slices.Contains(rule.StatusCodes, *err.StatusCode) {
+			return i
+		}
+		if (errorCode != "" && slices.Contains(rule.ErrorCodes, errorCode)) || (errorType != "" && slices.Contains(rule.ErrorCodes, errorType)) || slices.Contains(rule.ErrorCodes, string(canonicalCode)) {
+			return i
+		}
+		for _, pattern := range rule.MessagePatterns {
+			pattern = strings.ToLower(pattern)
+			if (message != "" && strings.Contains(message, pattern)) || (errorType != "" && strings.Contains(strings.ToLower(errorType), pattern)) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// retryDelay returns the time to wait before the given retry (0 for the first) of an error matching the rule.
+// The Retry-After delay of the provider is used when the policy honors it and it is longer than the backoff.
+// It returns false when the provider asks to wait longer than the policy allows.
+func retryDelay(policy *schemas.RetryPolicy, rule schemas.RetryRule, network schemas.NetworkConfig, retry int, err *schemas.BifrostError) (time.Duration, bool) {
+	initial, maxBackoff := network.RetryBackoffInitial, network.RetryBackoffMax
+	if rule.BackoffInitialMs > 0 {
+		initial = time.Duration(rule.BackoffInitialMs) * time.Millisecond
+	}
+	if rule.BackoffMaxMs > 0 {
+		maxBackoff = time.Duration(rule.BackoffMaxMs) * time.Millisecond
+	}
+	delay := exponentialBackoff(retry, initial, max(initial, maxBackoff))
+
+	if policy.HonorRetryAfter && err.RetryAfter != nil {
+		maxRetryAfter := schemas.DefaultMaxRetryAfter
+		if policy.MaxRetryAfterMs > 0 {
+			maxRetryAfter = time.Duration(policy.MaxRetryAfterMs) * time.Millisecond
+		}
+		if *err.RetryAfter > maxRetryAfter {
+			return 0, false
+		}
+		delay = max(delay, *err.RetryAfter)
+	}
+	return delay, true
+}
+
+// retryBudget limits the retries of a provider to a share of its requests over the last retryBudgetWindow seconds
+type retryBudget struct {
+	mu       sync.Mutex
+	config   schemas.RetryBudget
+	seconds  [retryBudgetWindow]int64 // unix second of each bucket
+	requests [retryBudgetWindow]int
+	retries  [retryBudgetWindow]int
+}
+
+// bucket returns the index of the bucket of the current second, resetting it if it belongs to an older second
+func (b *retryBudget) bucket(now int64) int {
+	i := int(now % retryBudgetWindow)
+	if b.seconds[i] != now {
+		b.seconds[i] = now
+		b.requests[i] = 0
+		b.retries[i] = 0
+	}
+	return i
+}
+
+// recordRequest accounts for a request sent to the provider
+func (b *retryBudget) recordRequest() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.requests[b.bucket(time.Now().Unix())]++
+}
+
+// tryRetry reports whether a retry fits in the budget, and accounts for it if so
+func (b *retryBudget) tryRetry() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now().Unix()
+	current := b.bucket(now)
+	requests, retries := 0, 0
+	for i := range b.seconds {
+		if now-b.seconds[i] < retryBudgetWindow {
+			requests += b.requests[i]
+			retries += b.retries[i]
+		}
+	}
+	allowed := float64(b.config.MinRetriesPerSecond*retryBudgetWindow) + b.config.RetryRatio*float64(requests)
+	if float64(retries+1) > allowed {
+		return false
+	}
+	b.retries[current]++
+	return true
+}
+
+// retryBudgets holds the retry budget of each provider whose policy has one
+var retryBudgets sync.Map // schemas.ModelProvider -> *retryBudget
+
+// getRetryBudget returns the retry budget of the provider, or nil if its policy has none.
+// The budget is reset when its config changes.
+func getRetryBudget(provider schemas.ModelProvider, policy *schemas.RetryPolicy) *retryBudget {
+	if policy == nil || policy.Budget == nil {
+		retryBudgets.Delete(provider)
+		return nil
+	}
+	if value, ok := retryBudgets.Load(provider); ok {
+		if budget := value.(*retryBudget); budget.config == *policy.Budget {
+			return budget
+		}
+	}
+	budget := &retryBudget{config: *policy.Budget}
+	retryBudgets.Store(provider, budget)
+	return budget
+}
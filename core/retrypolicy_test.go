@@ -0,0 +1,109 @@
+package bifrost
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// Test executeRequestWithRetries - retries per error class of a retry policy
+func TestExecuteRequestWithRetries_RetryPolicyRules(t *testing.T) {
+	config := createTestConfig(0, time.Millisecond, 10*time.Millisecond)
+	config.NetworkConfig.RetryPolicy = &schemas.RetryPolicy{Rules: []schemas.RetryRule{
+		{Name: "overloaded", ErrorCodes: []string{"overloaded_error"}, MaxRetries: 3},
+		{Name: "server", StatusCodes: []int{500}, MaxRetries: 1},
+	}}
+	ctx := context.Background()
+
+	testCases := []struct {
+		name          string
+		error         *schemas.BifrostError
+		expectedCalls int
+	}{
+		{name: "ErrorCode", error: createBifrostError("overloaded", Ptr(529), Ptr("overloaded_error"), false), expectedCalls: 4},
+		{name: "StatusCode", error: createBifrostError("internal error", Ptr(500), nil, false), expectedCalls: 2},
+		{name: "NoMatchingRule", error: createBifrostError("rate limit exceeded", Ptr(429), nil, false), expectedCalls: 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			callCount := 0
+			handler := func() (string, *schemas.BifrostError) {
+				callCount++
+				return "", tc.error
+			}
+			_, err := executeRequestWithRetries(&ctx, config, handler, schemas.ChatCompletionRequest, "retry-policy-rules", "gpt-4")
+			if callCount != tc.expectedCalls {
+				t.Errorf("Expected %d calls, got %d", tc.expectedCalls, callCount)
+			}
+			if err != tc.error {
+				t.Error("Expected original error to be returned")
+			}
+		})
+	}
+}
+
+// Test executeRequestWithRetries - Retry-After delays of the provider
+func TestExecuteRequestWithRetries_RetryAfter(t *testing.T) {
+	config := createTestConfig(0, time.Millisecond, time.Millisecond)
+	config.NetworkConfig.RetryPolicy = &schemas.RetryPolicy{
+		Rules:           []schemas.RetryRule{{Name: "rate_limit", StatusCodes: []int{429}, MaxRetries: 1}},
+		HonorRetryAfter: true,
+		MaxRetryAfterMs: 1000,
+	}
+	ctx := context.Background()
+
+	t.Run("WaitsForRetryAfter", func(t *testing.T) {
+		retryAfter := 50 * time.Millisecond
+		bifrostErr := createBifrostError("too many requests", Ptr(429), nil, false)
+		bifrostErr.RetryAfter = &retryAfter
+
+		start := time.Now()
+		callCount := 0
+		_, _ = executeRequestWithRetries(&ctx, config, func() (string, *schemas.BifrostError) {
+			callCount++
+			return "", bifrostErr
+		}, schemas.ChatCompletionRequest, "retry-after", "gpt-4")
+		if callCount != 2 {
+			t.Errorf("Expected 2 calls, got %d", callCount)
+		}
+		if elapsed := time.Since(start); elapsed < retryAfter {
+			t.Errorf("Expected to wait at least %s, waited %s", retryAfter, elapsed)
+		}
+	})
+
+	t.Run("DoesNotRetryLongerDelays", func(t *testing.T) {
+		retryAfter := time.Minute
+		bifrostErr := createBifrostError("too many requests", Ptr(429), nil, false)
+		bifrostErr.RetryAfter = &retryAfter
+
+		callCount := 0
+		_, _ = executeRequestWithRetries(&ctx, config, func() (string, *schemas.BifrostError) {
+			callCount++
+			return "", bifrostErr
+		}, schemas.ChatCompletionRequest, "retry-after", "gpt-4")
+		if callCount != 1 {
+			t.Errorf("Expected 1 call, got %d", callCount)
+		}
+	})
+}
+
+// Test retryBudget - retries are capped to a share of the requests
+func TestRetryBudget(t *testing.T) {
+	budget := getRetryBudget("retry-budget", &schemas.RetryPolicy{Budget: &schemas.RetryBudget{RetryRatio: 0.5}})
+	for range 4 {
+		budget.recordRequest()
+	}
+	for i := range 2 {
+		if !budget.tryRetry() {
+			t.Fatalf("Expected retry %d to fit in the budget", i+1)
+		}
+	}
+	if budget.tryRetry() {
+		t.Error("Expected the third retry for four requests to exceed the budget")
+	}
+	if getRetryBudget("retry-budget", &schemas.RetryPolicy{Budget: &schemas.RetryBudget{RetryRatio: 0.5}}) != budget {
+		t.Error("Expected the budget to be kept while its config is unchanged")
+	}
+}
@@ -1,7 +1,10 @@
 // Package schemas defines the core schemas and types used by the Bifrost system.
 package schemas
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Key represents an API key and its associated configuration for a provider.
 // It contains the key value, supported models, and a weight for load balancing.
@@ -14,40 +17,95 @@ type Key struct {
 	AzureKeyConfig   *AzureKeyConfig   `json:"azure_key_config,omitempty"`   // Azure-specific key configuration
 	VertexKeyConfig  *VertexKeyConfig  `json:"vertex_key_config,omitempty"`  // Vertex-specific key configuration
 	BedrockKeyConfig *BedrockKeyConfig `json:"bedrock_key_config,omitempty"` // AWS Bedrock-specific key configuration
+	ProxyConfig      *ProxyConfig      `json:"proxy_config,omitempty"`       // Proxy of the requests sent with this key, overriding the proxy of the provider
+	Region           string            `json:"region,omitempty"`             // Residency region the requests sent with this key are processed in, e.g. "eu" or "us"
+
+	// Test keys are labeled in responses and logs, and are disabled by governance once their spend reaches SpendLimit
+	IsTest     bool     `json:"is_test,omitempty"`
+	SpendLimit *float64 `json:"spend_limit,omitempty"` // Absolute spend hard-stop in dollars, required for test keys
+
+	LastValidation *KeyValidation `json:"last_validation,omitempty"` // Result of the last live validation call made with the key
+}
+
+// KeyValidationStatus is the outcome of the live validation call made with a key
+type KeyValidationStatus string
+
+const (
+	KeyValidationValid   KeyValidationStatus = "valid"   // The provider accepted the key
+	KeyValidationInvalid KeyValidationStatus = "invalid" // The provider rejected the key, or could not be reached with it
+)
+
+// KeyValidation records the live validation call made with a key by the management API
+type KeyValidation struct {
+	Status      KeyValidationStatus `json:"status"`
+	Error       string              `json:"error,omitempty"` // What to fix when the key is invalid
+	ValidatedAt time.Time           `json:"validated_at"`
 }
 
 // AzureKeyConfig represents the Azure-specific configuration.
 // It contains Azure-specific settings required for service access and deployment management.
 type AzureKeyConfig struct {
-	Endpoint    string            `json:"endpoint"`              // Azure service endpoint URL
-	Deployments map[string]string `json:"deployments,omitempty"` // Mapping of model names to deployment names
-	APIVersion  *string           `json:"api_version,omitempty"` // Azure API version to use; defaults to "2024-10-21"
+	Endpoint     string            `json:"endpoint"`                // Azure service endpoint URL
+	Deployments  map[string]string `json:"deployments,omitempty"`   // Mapping of model names to deployment names
+	APIVersion   *string           `json:"api_version,omitempty"`   // Azure API version to use; defaults to "2024-10-21"
+	EndpointType AzureEndpointType `json:"endpoint_type,omitempty"` // Type of the endpoint; defaults to "openai"
 }
 
+// AzureEndpointType represents the kind of Azure endpoint a key points to.
+type AzureEndpointType string
+
+const (
+	// AzureEndpointTypeOpenAI is an Azure OpenAI resource, with per-model deployments and api-key auth
+	AzureEndpointTypeOpenAI AzureEndpointType = "openai"
+	// AzureEndpointTypeServerless is an Azure AI Foundry serverless (model-as-a-service) endpoint,
+	// such as Llama, Mistral or Phi, served by the Azure AI model inference API with bearer auth
+	AzureEndpointTypeServerless AzureEndpointType = "serverless"
+)
+
+// NOTE: Deployments are optional for serverless endpoints, the requested model is sent as is when it has no mapping.
+
 // VertexKeyConfig represents the Vertex-specific configuration.
 // It contains Vertex-specific settings required for authentication and service access.
 type VertexKeyConfig struct {
 	ProjectID       string            `json:"project_id,omitempty"`
 	ProjectNumber   string            `json:"project_number,omitempty"`
 	Region          string            `json:"region,omitempty"`
+	Regions         []string          `json:"regions,omitempty"` // Fallback regions tried in order when a request fails in Region with a regional error
 	AuthCredentials string            `json:"auth_credentials,omitempty"`
 	Deployments     map[string]string `json:"deployments,omitempty"` // Mapping of model identifiers to inference profiles
 }
 
+// NOTE: Vertex deployments can be publisher model names (e.g. claude-sonnet-4@20250514 for Anthropic models),
+// or Model Garden endpoint IDs; resource names such as publishers/anthropic/models/<model> and endpoints/<id> are accepted too.
+
 // NOTE: To use Vertex IAM role authentication, set AuthCredentials to empty string.
 
 // BedrockKeyConfig represents the AWS Bedrock-specific configuration.
 // It contains AWS-specific settings required for authentication and service access.
 type BedrockKeyConfig struct {
-	AccessKey    string            `json:"access_key,omitempty"`    // AWS access key for authentication
-	SecretKey    string            `json:"secret_key,omitempty"`    // AWS secret access key for authentication
-	SessionToken *string           `json:"session_token,omitempty"` // AWS session token for temporary credentials
-	Region       *string           `json:"region,omitempty"`        // AWS region for service access
-	ARN          *string           `json:"arn,omitempty"`           // Amazon Resource Name for resource identification
-	Deployments  map[string]string `json:"deployments,omitempty"`   // Mapping of model identifiers to inference profiles
+	AccessKey        string            `json:"access_key,omitempty"`        // AWS access key for authentication
+	SecretKey        string            `json:"secret_key,omitempty"`        // AWS secret access key for authentication
+	SessionToken     *string           `json:"session_token,omitempty"`     // AWS session token for temporary credentials
+	Region           *string           `json:"region,omitempty"`            // AWS region for service access
+	ARN              *string           `json:"arn,omitempty"`               // Amazon Resource Name for resource identification
+	Deployments      map[string]string `json:"deployments,omitempty"`       // Mapping of model identifiers to inference profiles
+	Regions          []string          `json:"regions,omitempty"`           // Fallback regions, tried in order when the region is throttled or unavailable
+	ARNs             map[string]string `json:"arns,omitempty"`              // Inference profile ARNs per region, overriding ARN in that region
+	GuardrailID      *string           `json:"guardrail_id,omitempty"`      // Bedrock Guardrail identifier attached to Converse requests
+	GuardrailVersion *string           `json:"guardrail_version,omitempty"` // Bedrock Guardrail version, e.g. "1" or "DRAFT"
+	RoleARN          *string           `json:"role_arn,omitempty"`          // IAM role assumed with the key's credentials before signing requests
+	ExternalID       *string           `json:"external_id,omitempty"`       // External ID required by the trust policy of the role
+	RoleSessionName  *string           `json:"role_session_name,omitempty"` // Session name of the assumed role, defaults to "bifrost"
 }
 
+// NOTE: Requests are sent to Region first, then to each of Regions on throttling (429), server errors (5xx)
+// or connection failures. The region that served a request is reported in ExtraFields.Region.
+// A guardrail set with GuardrailID and GuardrailVersion is attached to Converse requests that don't specify a
+// guardrailConfig of their own, and its intervention results are reported in ExtraFields.Guardrail.
+
 // NOTE: To use Bedrock IAM role authentication, set both AccessKey and SecretKey to empty strings.
+// The default AWS credential chain is then used, which includes EKS Pod Identity and IRSA web identity tokens.
+// Set RoleARN to assume a role with either kind of credentials; assumed role sessions are refreshed automatically.
 // To use Bedrock API Key authentication, set Value in Key struct instead.
 
 // Account defines the interface for managing provider accounts and their configurations.
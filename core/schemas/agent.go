@@ -0,0 +1,105 @@
+package schemas
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// AgentConfig configures agent requests, where Bifrost runs the tool-call loop itself: the model proposes tool calls,
+// Bifrost executes the registered HTTP tools and the connected MCP tools, and sends the results back to the model
+// until it answers without calling tools.
+type AgentConfig struct {
+	MaxIterations      int             `json:"max_iterations,omitempty"`       // Most model calls of an agent request (default 10)
+	ToolTimeoutSeconds int             `json:"tool_timeout_seconds,omitempty"` // Timeout of a single tool execution (default 30 seconds)
+	HTTPTools          []AgentHTTPTool `json:"http_tools,omitempty"`           // Tools executed by calling an HTTP endpoint
+}
+
+// AgentHTTPTool is a tool executed by calling an HTTP endpoint with the arguments of the tool call.
+// POST requests send the arguments as a JSON body, GET requests as query parameters, and the response body
+// is sent back to the model as the tool result.
+type AgentHTTPTool struct {
+	Name        string                  `json:"name"`                  // Name of the tool, as seen by the model
+	Description string                  `json:"description,omitempty"` // Description of the tool, as seen by the model
+	Parameters  *ToolFunctionParameters `json:"parameters,omitempty"`  // JSON schema of the arguments of the tool
+	URL         string                  `json:"url"`                   // Endpoint called to execute the tool
+	Method      string                  `json:"method,omitempty"`      // GET or POST (default POST)
+	Headers     map[string]string       `json:"headers,omitempty"`     // Headers sent with every call, e.g. authentication
+}
+
+// Validate checks the agent config for negative limits and incomplete or duplicate HTTP tools
+func (c *AgentConfig) Validate() error {
+	if c.MaxIterations < 0 || c.ToolTimeoutSeconds < 0 {
+		return fmt.Errorf("agent limits cannot be negative")
+	}
+	names := make(map[string]bool, len(c.HTTPTools))
+	for _, tool := range c.HTTPTools {
+		if tool.Name == "" {
+			return fmt.Errorf("agent HTTP tool name is required")
+		}
+		if names[tool.Name] {
+			return fmt.Errorf("agent HTTP tool %s is defined more than once", tool.Name)
+		}
+		names[tool.Name] = true
+		if u, err := url.Parse(tool.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("agent HTTP tool %s must have an http(s) URL", tool.Name)
+		}
+		if method := strings.ToUpper(tool.Method); method != "" && method != "GET" && method != "POST" {
+			return fmt.Errorf("agent HTTP tool %s has unsupported method %s, expected GET or POST", tool.Name, tool.Method)
+		}
+	}
+	return nil
+}
+
+// AgentStopReason is the reason an agent request ended
+type AgentStopReason string
+
+const (
+	AgentStopReasonCompleted     AgentStopReason = "completed"      // The model answered without calling tools
+	AgentStopReasonToolCalls     AgentStopReason = "tool_calls"     // The model called tools Bifrost can't execute, the caller has to run them
+	AgentStopReasonMaxIterations AgentStopReason = "max_iterations" // The iteration limit was reached while the model was still calling tools
+)
+
+// AgentToolSource is where a tool executed by an agent request comes from
+type AgentToolSource string
+
+const (
+	AgentToolSourceHTTP AgentToolSource = "http"
+	AgentToolSourceMCP  AgentToolSource = "mcp"
+)
+
+// BifrostAgentRequest is a chat request whose tool calls are executed by Bifrost until the model answers
+type BifrostAgentRequest struct {
+	ChatRequest   *BifrostChatRequest `json:"chat_request"`
+	MaxIterations int                 `json:"max_iterations,omitempty"` // Optional: Lower iteration limit than the configured one
+}
+
+// BifrostAgentResponse is the final answer of an agent request with the trace of the loop that produced it
+type BifrostAgentResponse struct {
+	Response   *BifrostChatResponse `json:"response"`   // Last response of the model
+	Messages   []ChatMessage        `json:"messages"`   // Conversation including the tool calls and their results, without the input messages
+	Trace      []AgentTraceStep     `json:"trace"`      // One step per model call
+	Iterations int                  `json:"iterations"` // Number of model calls
+	StopReason AgentStopReason      `json:"stop_reason"`
+	Usage      *BifrostLLMUsage     `json:"usage,omitempty"` // Token usage summed over all model calls
+}
+
+// AgentTraceStep records a model call of an agent request and the tools executed for its tool calls
+type AgentTraceStep struct {
+	Iteration      int                  `json:"iteration"`
+	Message        ChatMessage          `json:"message"` // Message returned by the model
+	Usage          *BifrostLLMUsage     `json:"usage,omitempty"`
+	LatencyMs      int64                `json:"latency_ms"`
+	ToolExecutions []AgentToolExecution `json:"tool_executions,omitempty"`
+}
+
+// AgentToolExecution records the execution of a tool call
+type AgentToolExecution struct {
+	ToolCallID string          `json:"tool_call_id,omitempty"`
+	Name       string          `json:"name"`
+	Arguments  string          `json:"arguments"`
+	Source     AgentToolSource `json:"source"`
+	Output     string          `json:"output,omitempty"`
+	Error      string          `json:"error,omitempty"` // Execution error, also sent to the model as the tool result
+	LatencyMs  int64           `json:"latency_ms"`
+}
@@ -5,6 +5,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/bytedance/sonic"
 )
@@ -22,32 +25,90 @@ type BifrostConfig struct {
 	Account            Account
 	Plugins            []Plugin
 	Logger             Logger
-	InitialPoolSize    int         // Initial pool size for sync pools in Bifrost. Higher values will reduce memory allocations but will increase memory usage.
-	DropExcessRequests bool        // If true, in cases where the queue is full, requests will not wait for the queue to be empty and will be dropped instead.
-	MCPConfig          *MCPConfig  // MCP (Model Context Protocol) configuration for tool integration
-	KeySelector        KeySelector // Custom key selector function
+	InitialPoolSize    int                     // Initial pool size for sync pools in Bifrost. Higher values will reduce memory allocations but will increase memory usage.
+	DropExcessRequests bool                    // If true, in cases where the queue is full, requests will not wait for the queue to be empty and will be dropped instead.
+	MCPConfig          *MCPConfig              // MCP (Model Context Protocol) configuration for tool integration
+	KeySelector        KeySelector             // Custom key selector function
+	ModelCapabilities  ModelCapabilityRegistry // Optional: Capabilities of models, requests using features their model lacks are rejected
+
+	PluginExecution    map[string]PluginExecutionConfig // Optional: Execution limits of plugins, keyed by plugin name
+	PluginCircuits     PluginCircuitStore               // Optional: Circuit breakers of the plugins shared with the other replicas of the gateway
+	DirectKeyPolicy    *DirectKeyPolicy                 // Optional: Constraints on requests carrying a caller-supplied key
+	ImageInputs        *ImageInputConfig                // Optional: Gateway-side fetching and transcoding of the image inputs of chat requests
+	Agent              *AgentConfig                     // Optional: Tool-call loop run by Bifrost, nil disables agent requests
+	ContextWindow      *ContextWindowConfig             // Optional: Pre-flight check of the input tokens against the context window of the model
+	PromptCompression  *PromptCompressionConfig         // Optional: Compression of the oldest turns of conversations exceeding the token budget of their model
+	StreamFailover     *StreamFailoverConfig            // Optional: Restart of streams failing mid-generation on the next fallback
+	StreamBackpressure *StreamBackpressureConfig        // Optional: Bounded buffers of streams and policy applied to slow consumers
+	ResponseBuffering  *ResponseBufferingConfig         // Optional: Non-stream requests served from a provider stream aggregated by Bifrost
+	Idempotency        *IdempotencyConfig               // Optional: Replay window of the responses of requests sent with an idempotency key
+	Chaos              *ChaosConfig                     // Optional: Faults injected into provider requests for resilience testing
+	ListModelsCache    *ListModelsCacheConfig           // Optional: Cache of the models listed by the providers, refreshed in the background
+	ResponsesState     *ResponsesStateConfig            // Optional: Storage of Responses API conversations, so that previous_response_id works across keys and providers
+	RequestTags        *RequestTagsConfig               // Optional: Allow-list of the tags recorded on the logs and metrics of requests, nil records no tags
+	PartialFailures    *PartialFailureConfig            // Optional: Whether requests of many inputs return partial results or fail when some inputs fail
+	SLOs               *SLOConfig                       // Optional: Service level objectives of the providers tracked over a rolling window, and whether they feed routing
+	Conversations      *ConversationConfig              // Optional: History truncation of the conversations stored by Bifrost, requires ConversationStore
+	ConversationStore  ConversationStore                // Optional: Storage of the conversations continued by chat requests with a conversation ID
+}
+
+// DirectKeyPolicy constrains requests that carry a caller-supplied provider key (BifrostContextKeyDirectKey)
+// instead of one of the configured keys. A nil policy leaves direct key requests unrestricted.
+type DirectKeyPolicy struct {
+	AllowedProviders []ModelProvider `json:"allowed_providers,omitempty"` // Providers that may be targeted with a direct key, empty means all providers
+	BlockedModels    []string        `json:"blocked_models,omitempty"`    // Models that may not be requested with a direct key, as "model" or "provider/model"
+	RequiredPlugins  []string        `json:"required_plugins,omitempty"`  // Plugins that must be loaded and run for direct key requests, e.g. "logging"
+}
+
+// ImageInputConfig configures the normalization of the image inputs of chat requests before they are sent to a provider,
+// so that a request written for OpenAI vision also works on providers with other image requirements.
+// A nil config sends images as they are.
+type ImageInputConfig struct {
+	FetchURLs            bool            `json:"fetch_urls"`                       // Fetch image URLs and send the images inline to providers that don't accept URLs (Bedrock, Gemini, Vertex)
+	FetchProviders       []ModelProvider `json:"fetch_providers,omitempty"`        // Providers to fetch image URLs for instead of the ones that don't accept URLs
+	Transcode            bool            `json:"transcode"`                        // Convert images to a format the provider accepts and downscale them below its size limit
+	MaxImageBytes        int64           `json:"max_image_bytes,omitempty"`        // Largest image accepted, fetched or inline, in bytes (default 20 MB)
+	FetchTimeoutSeconds  int             `json:"fetch_timeout_seconds,omitempty"`  // Timeout of fetching an image (default 10 seconds)
+	AllowPrivateNetworks bool            `json:"allow_private_networks,omitempty"` // Allow fetching images from private and loopback addresses
+}
+
+// IsProviderAllowed reports whether the provider may be targeted with a direct key
+func (p *DirectKeyPolicy) IsProviderAllowed(provider ModelProvider) bool {
+	return len(p.AllowedProviders) == 0 || slices.Contains(p.AllowedProviders, provider)
+}
+
+// IsModelBlocked reports whether the model may not be requested with a direct key
+func (p *DirectKeyPolicy) IsModelBlocked(provider ModelProvider, model string) bool {
+	if model == "" {
+		return false
+	}
+	return slices.Contains(p.BlockedModels, model) || slices.Contains(p.BlockedModels, string(provider)+"/"+model)
 }
 
 // ModelProvider represents the different AI model providers supported by Bifrost.
 type ModelProvider string
 
 const (
-	OpenAI     ModelProvider = "openai"
-	Azure      ModelProvider = "azure"
-	Anthropic  ModelProvider = "anthropic"
-	Bedrock    ModelProvider = "bedrock"
-	Cohere     ModelProvider = "cohere"
-	Vertex     ModelProvider = "vertex"
-	Mistral    ModelProvider = "mistral"
-	Ollama     ModelProvider = "ollama"
-	Groq       ModelProvider = "groq"
-	SGL        ModelProvider = "sgl"
-	Parasail   ModelProvider = "parasail"
-	Perplexity ModelProvider = "perplexity"
-	Cerebras   ModelProvider = "cerebras"
-	Gemini     ModelProvider = "gemini"
-	OpenRouter ModelProvider = "openrouter"
-	Elevenlabs ModelProvider = "elevenlabs"
+	OpenAI      ModelProvider = "openai"
+	Azure       ModelProvider = "azure"
+	Anthropic   ModelProvider = "anthropic"
+	Bedrock     ModelProvider = "bedrock"
+	Cohere      ModelProvider = "cohere"
+	Vertex      ModelProvider = "vertex"
+	Mistral     ModelProvider = "mistral"
+	Ollama      ModelProvider = "ollama"
+	Groq        ModelProvider = "groq"
+	SGL         ModelProvider = "sgl"
+	Parasail    ModelProvider = "parasail"
+	Perplexity  ModelProvider = "perplexity"
+	Cerebras    ModelProvider = "cerebras"
+	Gemini      ModelProvider = "gemini"
+	OpenRouter  ModelProvider = "openrouter"
+	Elevenlabs  ModelProvider = "elevenlabs"
+	Fireworks   ModelProvider = "fireworks"
+	XAI         ModelProvider = "xai"
+	HuggingFace ModelProvider = "huggingface"
+	Mock        ModelProvider = "mock"
 )
 
 // SupportedBaseProviders is the list of base providers allowed for custom providers.
@@ -77,6 +138,10 @@ var StandardProviders = []ModelProvider{
 	Vertex,
 	OpenRouter,
 	Elevenlabs,
+	Fireworks,
+	XAI,
+	HuggingFace,
+	Mock,
 }
 
 // RequestType represents the type of request being made to a provider.
@@ -117,6 +182,33 @@ const (
 	BifrostContextKeyUseRawRequestBody                   BifrostContextKey = "bifrost-use-raw-request-body"                     // bool
 	BifrostContextKeySendBackRawResponse                 BifrostContextKey = "bifrost-send-back-raw-response"                   // bool
 	BifrostContextKeyIsResponsesToChatCompletionFallback BifrostContextKey = "bifrost-is-responses-to-chat-completion-fallback" // bool (set by bifrost)
+	BifrostContextKeyEventID                             BifrostContextKey = "x-bf-event-id"                                    // string (client-supplied event ID used for request deduplication)
+	BifrostContextKeySelectedKeyIsTest                   BifrostContextKey = "bifrost-selected-key-is-test"                     // bool (set by bifrost when the selected key is a test key)
+	BifrostContextKeySelectedKeySpendLimit               BifrostContextKey = "bifrost-selected-key-spend-limit"                 // float64 (spend limit of the selected test key (set by bifrost))
+	BifrostContextKeyGovernanceVirtualKeyID              BifrostContextKey = "bf-governance-virtual-key-id"                     // string (ID of the virtual key of the request (set by the governance plugin))
+	BifrostContextKeyPipeline                            BifrostContextKey = "bifrost-pipeline"                                 // string (name of the transformation pipeline applied to the request (set by bifrost))
+	BifrostContextKeyStructuredOutputRetries             BifrostContextKey = "x-bf-structured-output-retries"                   // int (validate json_schema responses and retry up to this many times to repair them)
+	BifrostContextKeyTransformRules                      BifrostContextKey = "bifrost-transform-rules"                          // []string (names of the transform rules applied to the request (set by bifrost))
+	BifrostContextKeyParameterViolations                 BifrostContextKey = "bifrost-parameter-violations"                     // []BifrostParameterViolation (parameters rewritten by transform rules (set by bifrost))
+	BifrostContextKeyFallbackChain                       BifrostContextKey = "bifrost-fallback-chain"                           // string (alias of the fallback chain the request was sent through (set by bifrost))
+	BifrostContextKeyPromptCompressed                    BifrostContextKey = "bifrost-prompt-compressed"                        // int (number of messages compressed to fit the token budget of the model (set by bifrost))
+	BifrostContextKeyModelRemappedFrom                   BifrostContextKey = "bifrost-model-remapped-from"                      // string (provider/model of a sunset model the request was remapped from (set by bifrost))
+	BifrostContextKeyStreamBufferSize                    BifrostContextKey = "bifrost-stream-buffer-size"                       // int (size of the chunk channels of the stream (set by bifrost))
+	BifrostContextKeyStreamUpstream                      BifrostContextKey = "x-bf-stream-upstream"                             // bool (serve a non-stream request from a provider stream, overrides the response buffering config)
+	BifrostContextKeyIdempotencyKey                      BifrostContextKey = "idempotency-key"                                  // string (client-supplied key deduplicating retries of a non-stream request)
+	BifrostContextKeyPriority                            BifrostContextKey = "x-bf-priority"                                    // string (priority pool of the provider the request is queued in, e.g. "batch")
+	BifrostContextKeyCaptureProviderRequest              BifrostContextKey = "x-bf-capture-provider-request"                    // bool (record the HTTP request sent to the provider in the provider_request extra field of non-stream responses)
+	BifrostContextKeyAllowMixedEmbeddings                BifrostContextKey = "x-bf-allow-mixed-embeddings"                      // bool (let embedding requests fall back to other embedding models than the primary model)
+	BifrostContextKeyConversationID                      BifrostContextKey = "x-bf-conversation-id"                             // string (ID of the stored conversation continued by a chat request, only its new messages are sent)
+	BifrostContextKeyEndUser                             BifrostContextKey = "x-bf-user"                                        // string (end user of the request, sent to the provider as its user parameter (set by bifrost from the user parameter when absent))
+	BifrostContextKeyRoutingProvider                     BifrostContextKey = "x-bf-provider"                                    // ModelProvider (provider the request is pinned to, within the providers allowed by governance)
+	BifrostContextKeyRoutingExcludeProviders             BifrostContextKey = "x-bf-exclude-providers"                           // []ModelProvider (providers the request and its fallbacks are never sent to)
+	BifrostContextKeyRoutingStrategy                     BifrostContextKey = "x-bf-routing-strategy"                            // string (how the provider of the request is picked among the allowed providers: "weighted", "priority" or "cheapest")
+	BifrostContextKeyRoutingMaxCost                      BifrostContextKey = "x-bf-max-cost"                                    // float64 (highest estimated cost in dollars of the request on the provider it is sent to)
+	BifrostContextKeyRequestTags                         BifrostContextKey = "x-bf-tags"                                        // map[string]string (tags of the request, replaced by bifrost with the tags of the request in the allow-list, including its metadata)
+	BifrostContextKeyPartialFailureMode                  BifrostContextKey = "x-bf-partial-failures"                            // PartialFailureMode (whether a request of many inputs returns partial results or fails when some inputs fail, overrides the partial failure config)
+	BifrostContextKeyExperiment                          BifrostContextKey = "bifrost-experiment"                               // string (experiment the request is enrolled in (set by bifrost))
+	BifrostContextKeyExperimentVariant                   BifrostContextKey = "bifrost-experiment-variant"                       // string (variant of the experiment the request is assigned to (set by bifrost))
 )
 
 // NOTE: for custom plugin implementation dealing with streaming short circuit,
@@ -282,14 +374,80 @@ func (r *BifrostResponse) GetExtraFields() *BifrostResponseExtraFields {
 
 // BifrostResponseExtraFields contains additional fields in a response.
 type BifrostResponseExtraFields struct {
-	RequestType     RequestType        `json:"request_type"`
-	Provider        ModelProvider      `json:"provider,omitempty"`
-	ModelRequested  string             `json:"model_requested,omitempty"`
-	ModelDeployment string             `json:"model_deployment,omitempty"` // only present for providers which use model deployments (e.g. Azure, Bedrock)
-	Latency         int64              `json:"latency"`                    // in milliseconds (for streaming responses this will be each chunk latency, and the last chunk latency will be the total latency)
-	ChunkIndex      int                `json:"chunk_index"`                // used for streaming responses to identify the chunk index, will be 0 for non-streaming responses
-	RawResponse     interface{}        `json:"raw_response,omitempty"`
-	CacheDebug      *BifrostCacheDebug `json:"cache_debug,omitempty"`
+	RequestType         RequestType                 `json:"request_type"`
+	Provider            ModelProvider               `json:"provider,omitempty"`
+	ModelRequested      string                      `json:"model_requested,omitempty"`
+	ModelDeployment     string                      `json:"model_deployment,omitempty"` // only present for providers which use model deployments (e.g. Azure, Bedrock)
+	Region              string                      `json:"region,omitempty"`           // only present for providers with regional endpoints, the region that served the request (e.g. Bedrock)
+	Latency             int64                       `json:"latency"`                    // in milliseconds (for streaming responses this will be each chunk latency, and the last chunk latency will be the total latency)
+	ChunkIndex          int                         `json:"chunk_index"`                // used for streaming responses to identify the chunk index, will be 0 for non-streaming responses
+	RawResponse         interface{}                 `json:"raw_response,omitempty"`
+	CacheDebug          *BifrostCacheDebug          `json:"cache_debug,omitempty"`
+	TestKey             bool                        `json:"test_key,omitempty"`             // true when the response was served with a test key
+	Sources             []BifrostSource             `json:"sources,omitempty"`              // sources the response is grounded on, e.g. Perplexity citations and search results
+	Guardrail           *BifrostGuardrail           `json:"guardrail,omitempty"`            // result of the provider-side guardrail applied to the request, e.g. Bedrock Guardrails
+	SystemPromptPolicy  *BifrostSystemPromptPolicy  `json:"system_prompt_policy,omitempty"` // system prompt policy governance enforced on the request
+	StreamFailover      *BifrostStreamFailover      `json:"stream_failover,omitempty"`      // set on the marker chunk of a stream that failed over to a fallback mid-generation
+	TimeToFirstToken    int64                       `json:"time_to_first_token,omitempty"`  // in milliseconds, set on non-stream responses aggregated from a provider stream
+	IdempotentReplay    bool                        `json:"idempotent_replay,omitempty"`    // set on responses served from the result of an earlier request with the same idempotency key
+	ProviderRequest     *BifrostProviderRequest     `json:"provider_request,omitempty"`     // HTTP request sent to the provider, set when BifrostContextKeyCaptureProviderRequest is true
+	ParameterViolations []BifrostParameterViolation `json:"parameter_violations,omitempty"` // parameters of the request rewritten by the clamp and set actions of transform rules
+	Moderation          *BifrostModeration          `json:"moderation,omitempty"`           // content filter, refusal and guardrail outcome of the response, normalized across providers
+	RequestID           string                      `json:"request_id,omitempty"`           // ID of the request, generated by bifrost when the client did not send one
+}
+
+// BifrostParameterViolation records a parameter sent by the client that violated a clamp or set action of a transform rule.
+type BifrostParameterViolation struct {
+	Rule      string      `json:"rule"`              // Name of the transform rule
+	Path      string      `json:"path"`              // Path of the parameter, e.g. "$.temperature"
+	Requested interface{} `json:"requested"`         // Value sent by the client
+	Applied   interface{} `json:"applied,omitempty"` // Value the request was sent with, unset when the request was rejected
+}
+
+// BifrostProviderRequest is the HTTP request rendered by a provider for a request, as sent on the wire.
+// Credentials in the headers and the query are redacted, and bodies that are neither JSON nor text are omitted.
+type BifrostProviderRequest struct {
+	Method   string            `json:"method"`
+	URL      string            `json:"url"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Body     string            `json:"body,omitempty"`
+	BodySize int               `json:"body_size"` // in bytes, also set when the body is omitted
+}
+
+// BifrostSystemPromptPolicy records the system prompt policy of a virtual key or team that was enforced on a request.
+type BifrostSystemPromptPolicy struct {
+	Source               string `json:"source"`                           // "virtual_key" or "team"
+	SourceID             string `json:"source_id"`                        // ID of the virtual key or team owning the policy
+	Mode                 string `json:"mode"`                             // "prepend" or "replace"
+	ClientPromptReplaced bool   `json:"client_prompt_replaced,omitempty"` // true when the system prompt sent by the client was dropped
+}
+
+// BifrostGuardrail represents the result of a provider-side guardrail evaluation of a request and its response.
+type BifrostGuardrail struct {
+	Intervened bool                      `json:"intervened"`       // true when the guardrail blocked or masked content
+	Reason     string                    `json:"reason,omitempty"` // reason given by the provider for the intervention
+	Findings   []BifrostGuardrailFinding `json:"findings,omitempty"`
+}
+
+// BifrostGuardrailFinding represents a single policy match of a guardrail evaluation.
+type BifrostGuardrailFinding struct {
+	Source     string `json:"source"`               // "input" or "output"
+	Policy     string `json:"policy"`               // e.g. "topic", "content", "word", "sensitive_information"
+	Type       string `json:"type,omitempty"`       // e.g. topic type, content filter category or PII entity type
+	Name       string `json:"name,omitempty"`       // name of the matched topic or regex
+	Match      string `json:"match,omitempty"`      // matched text
+	Confidence string `json:"confidence,omitempty"` // confidence of content filter matches
+	Action     string `json:"action,omitempty"`     // action taken, e.g. "BLOCKED" or "ANONYMIZED"
+}
+
+// BifrostSource represents a source a response is grounded on, such as a web page found by a search.
+type BifrostSource struct {
+	Index       int     `json:"index,omitempty"` // 1-based citation number, matching the [n] markers in the response text, 0 if the source is not cited
+	URL         string  `json:"url"`
+	Title       string  `json:"title,omitempty"`
+	Snippet     *string `json:"snippet,omitempty"`
+	Date        *string `json:"date,omitempty"`
+	LastUpdated *string `json:"last_updated,omitempty"`
 }
 
 // BifrostCacheDebug represents debug information about the cache.
@@ -310,7 +468,8 @@ type BifrostCacheDebug struct {
 }
 
 const (
-	RequestCancelled = "request_cancelled"
+	RequestCancelled   = "request_cancelled"
+	FirstChunkTimedOut = "first_chunk_timeout" // Type of the errors of streams aborted by the first chunk timeout of the provider
 )
 
 // BifrostStream represents a stream of responses from the Bifrost system.
@@ -327,23 +486,65 @@ type BifrostStream struct {
 // MarshalJSON implements custom JSON marshaling for BifrostStream.
 // This ensures that only the non-nil embedded struct is marshaled,
 func (bs BifrostStream) MarshalJSON() ([]byte, error) {
-	if bs.BifrostTextCompletionResponse != nil {
-		return sonic.Marshal(bs.BifrostTextCompletionResponse)
-	} else if bs.BifrostChatResponse != nil {
-		return sonic.Marshal(bs.BifrostChatResponse)
-	} else if bs.BifrostResponsesStreamResponse != nil {
-		return sonic.Marshal(bs.BifrostResponsesStreamResponse)
-	} else if bs.BifrostSpeechStreamResponse != nil {
-		return sonic.Marshal(bs.BifrostSpeechStreamResponse)
-	} else if bs.BifrostTranscriptionStreamResponse != nil {
-		return sonic.Marshal(bs.BifrostTranscriptionStreamResponse)
-	} else if bs.BifrostError != nil {
-		return sonic.Marshal(bs.BifrostError)
+	if payload := bs.Payload(); payload != nil {
+		return sonic.Marshal(payload)
 	}
 	// Return empty object if both are nil (shouldn't happen in practice)
 	return []byte("{}"), nil
 }
 
+// Payload returns the non-nil embedded response or error of the chunk, which is what the chunk marshals to.
+// Encoders marshalling the payload directly skip the intermediate buffer of MarshalJSON.
+func (bs *BifrostStream) Payload() interface{} {
+	switch {
+	case bs.BifrostTextCompletionResponse != nil:
+		return bs.BifrostTextCompletionResponse
+	case bs.BifrostChatResponse != nil:
+		return bs.BifrostChatResponse
+	case bs.BifrostResponsesStreamResponse != nil:
+		return bs.BifrostResponsesStreamResponse
+	case bs.BifrostSpeechStreamResponse != nil:
+		return bs.BifrostSpeechStreamResponse
+	case bs.BifrostTranscriptionStreamResponse != nil:
+		return bs.BifrostTranscriptionStreamResponse
+	case bs.BifrostError != nil:
+		return bs.BifrostError
+	}
+	return nil
+}
+
+// bifrostStreamPool holds the chunks of streams, which are allocated for every token of every stream
+var bifrostStreamPool = sync.Pool{
+	New: func() interface{} {
+		return &BifrostStream{}
+	},
+}
+
+// AcquireBifrostStream returns a chunk from the pool holding the stream variant of the response and the error.
+// Chunks are returned to the pool by their last reader with ReleaseBifrostStream.
+func AcquireBifrostStream(response *BifrostResponse, err *BifrostError) *BifrostStream {
+	stream := bifrostStreamPool.Get().(*BifrostStream)
+	if response != nil {
+		stream.BifrostTextCompletionResponse = response.TextCompletionResponse
+		stream.BifrostChatResponse = response.ChatResponse
+		stream.BifrostResponsesStreamResponse = response.ResponsesStreamResponse
+		stream.BifrostSpeechStreamResponse = response.SpeechStreamResponse
+		stream.BifrostTranscriptionStreamResponse = response.TranscriptionStreamResponse
+	}
+	stream.BifrostError = err
+	return stream
+}
+
+// ReleaseBifrostStream returns a chunk to the pool. The chunk must not be used afterwards, while the responses
+// it held remain valid. It must only be called by the last reader of the chunk, e.g. the transport writing it.
+func ReleaseBifrostStream(stream *BifrostStream) {
+	if stream == nil {
+		return
+	}
+	*stream = BifrostStream{}
+	bifrostStreamPool.Put(stream)
+}
+
 // BifrostError represents an error from the Bifrost system.
 //
 // PLUGIN DEVELOPERS: When creating BifrostError in PreHook or PostHook, you can set AllowFallbacks:
@@ -355,9 +556,11 @@ type BifrostError struct {
 	Type           *string                 `json:"type,omitempty"`
 	IsBifrostError bool                    `json:"is_bifrost_error"`
 	StatusCode     *int                    `json:"status_code,omitempty"`
+	ErrorCode      ErrorCode               `json:"error_code,omitempty"` // Canonical code of the error, independent of the provider
 	Error          *ErrorField             `json:"error"`
 	AllowFallbacks *bool                   `json:"-"` // Optional: Controls fallback behavior (nil = true by default)
 	StreamControl  *StreamControl          `json:"-"` // Optional: Controls stream behavior
+	RetryAfter     *time.Duration          `json:"-"` // Optional: Delay before retrying requested by the provider (Retry-After header)
 	ExtraFields    BifrostErrorExtraFields `json:"extra_fields,omitempty"`
 }
 
@@ -421,4 +624,5 @@ type BifrostErrorExtraFields struct {
 	Provider       ModelProvider `json:"provider"`
 	ModelRequested string        `json:"model_requested"`
 	RequestType    RequestType   `json:"request_type"`
+	RequestID      string        `json:"request_id,omitempty"` // ID of the request, also sent in the x-request-id response header
 }
@@ -0,0 +1,18 @@
+package schemas
+
+// ModelCapabilities describes what a model supports. Requests to a model are validated against its capabilities
+// before they reach the provider.
+type ModelCapabilities struct {
+	ContextWindow    int  `json:"context_window,omitempty"`    // Input and output tokens, 0 when unknown
+	MaxOutputTokens  int  `json:"max_output_tokens,omitempty"` // Output tokens of a response, 0 when unknown
+	SupportsVision   bool `json:"supports_vision"`             // Image inputs
+	SupportsTools    bool `json:"supports_tools"`              // Tool definitions and tool calls
+	SupportsJSONMode bool `json:"supports_json_mode"`          // json_object and json_schema response formats
+}
+
+// ModelCapabilityRegistry looks up the capabilities of models, it is implemented by the model catalog of the framework
+type ModelCapabilityRegistry interface {
+	// GetModelCapabilities returns the capabilities of the model of the provider, nil when the model is unknown.
+	// Requests to unknown models are not validated.
+	GetModelCapabilities(provider ModelProvider, model string) *ModelCapabilities
+}
@@ -0,0 +1,62 @@
+package schemas
+
+import "fmt"
+
+// ChaosFault is a fault injected into provider requests
+type ChaosFault string
+
+const (
+	ChaosFaultLatency    ChaosFault = "latency"    // Delay the request before it is sent to the provider
+	ChaosFaultError      ChaosFault = "error"      // Fail the request with a provider error
+	ChaosFaultDisconnect ChaosFault = "disconnect" // Lose the connection, mid-stream for stream requests
+)
+
+// ChaosConfig configures the injection of faults into provider requests, to verify that retries, fallbacks and
+// stream failover behave as configured. Faults are injected below the retries, so every attempt rolls again.
+// Faults are only injected while enabled, a nil config injects none.
+type ChaosConfig struct {
+	Enabled bool        `json:"enabled"`
+	Rules   []ChaosRule `json:"rules,omitempty"` // Every matching rule rolls its percentage independently
+}
+
+// ChaosRule injects a fault into a percentage of the requests to a provider and model
+type ChaosRule struct {
+	Provider    ModelProvider `json:"provider,omitempty"`     // Provider targeted, empty targets every provider
+	Model       string        `json:"model,omitempty"`        // Model targeted, empty targets every model
+	Percentage  float64       `json:"percentage"`             // Percentage of the matching requests getting the fault, from 0 to 100
+	Fault       ChaosFault    `json:"fault"`                  // Fault injected
+	LatencyMs   int           `json:"latency_ms,omitempty"`   // Delay added by latency faults
+	StatusCode  int           `json:"status_code,omitempty"`  // Status code of error faults, 500 by default
+	AfterChunks int           `json:"after_chunks,omitempty"` // Chunks streamed before disconnect faults end streams
+}
+
+// Validate checks the faults and percentages of the rules
+func (c *ChaosConfig) Validate() error {
+	for i, rule := range c.Rules {
+		if rule.Percentage < 0 || rule.Percentage > 100 {
+			return fmt.Errorf("rule %d: percentage must be between 0 and 100, got %v", i, rule.Percentage)
+		}
+		switch rule.Fault {
+		case ChaosFaultLatency:
+			if rule.LatencyMs <= 0 {
+				return fmt.Errorf("rule %d: latency faults need a positive latency_ms", i)
+			}
+		case ChaosFaultError:
+			if rule.StatusCode != 0 && (rule.StatusCode < 400 || rule.StatusCode > 599) {
+				return fmt.Errorf("rule %d: status code must be an HTTP error status code, got %d", i, rule.StatusCode)
+			}
+		case ChaosFaultDisconnect:
+			if rule.AfterChunks < 0 {
+				return fmt.Errorf("rule %d: after_chunks cannot be negative, got %d", i, rule.AfterChunks)
+			}
+		default:
+			return fmt.Errorf("rule %d: unknown fault %q", i, rule.Fault)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the rule targets the provider and model
+func (r *ChaosRule) Matches(provider ModelProvider, model string) bool {
+	return (r.Provider == "" || r.Provider == provider) && (r.Model == "" || r.Model == model)
+}
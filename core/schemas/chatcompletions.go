@@ -38,6 +38,9 @@ type BifrostChatResponse struct {
 	SearchResults []SearchResult `json:"search_results,omitempty"`
 	Videos        []VideoResult  `json:"videos,omitempty"`
 	Citations     []string       `json:"citations,omitempty"`
+
+	// Azure-specific fields
+	PromptFilterResults []PromptFilterResult `json:"prompt_filter_results,omitempty"`
 }
 
 // ToTextCompletionResponse converts a BifrostChatResponse to a BifrostTextCompletionResponse
@@ -160,11 +163,12 @@ type ChatParameters struct {
 	Metadata            *map[string]any     `json:"metadata,omitempty"`              // Metadata to be returned with the response
 	Modalities          []string            `json:"modalities,omitempty"`            // Modalities to be returned with the response
 	ParallelToolCalls   *bool               `json:"parallel_tool_calls,omitempty"`
-	PresencePenalty     *float64            `json:"presence_penalty,omitempty"`  // Penalizes repeated tokens
-	PromptCacheKey      *string             `json:"prompt_cache_key,omitempty"`  // Prompt cache key
-	ReasoningEffort     *string             `json:"reasoning_effort,omitempty"`  // "minimal" | "low" | "medium" | "high"
-	ResponseFormat      *interface{}        `json:"response_format,omitempty"`   // Format for the response
-	SafetyIdentifier    *string             `json:"safety_identifier,omitempty"` // Safety identifier
+	PresencePenalty     *float64            `json:"presence_penalty,omitempty"`     // Penalizes repeated tokens
+	PromptCacheKey      *string             `json:"prompt_cache_key,omitempty"`     // Prompt cache key
+	ReasoningEffort     *string             `json:"reasoning_effort,omitempty"`     // "minimal" | "low" | "medium" | "high"
+	ReasoningMaxTokens  *int                `json:"reasoning_max_tokens,omitempty"` // Token budget for reasoning, takes precedence over ReasoningEffort for providers with budgets
+	ResponseFormat      *interface{}        `json:"response_format,omitempty"`      // Format for the response
+	SafetyIdentifier    *string             `json:"safety_identifier,omitempty"`    // Safety identifier
 	Seed                *int                `json:"seed,omitempty"`
 	ServiceTier         *string             `json:"service_tier,omitempty"`
 	StreamOptions       *ChatStreamOptions  `json:"stream_options,omitempty"`
@@ -183,6 +187,44 @@ type ChatParameters struct {
 	ExtraParams map[string]interface{} `json:"-"`
 }
 
+// ResponseFormatType values
+const (
+	ResponseFormatTypeText       = "text"
+	ResponseFormatTypeJSONObject = "json_object"
+	ResponseFormatTypeJSONSchema = "json_schema"
+)
+
+// ResponseFormat is the typed form of ChatParameters.ResponseFormat, which follows the response_format of OpenAI.
+// Providers without native structured outputs translate it to their own mechanism.
+type ResponseFormat struct {
+	Type       string                    `json:"type"`
+	JSONSchema *ResponseFormatJSONSchema `json:"json_schema,omitempty"`
+}
+
+// ResponseFormatJSONSchema is the JSON schema of a json_schema response format.
+type ResponseFormatJSONSchema struct {
+	Name        string         `json:"name"`
+	Description *string        `json:"description,omitempty"`
+	Schema      map[string]any `json:"schema,omitempty"`
+	Strict      *bool          `json:"strict,omitempty"`
+}
+
+// ParseResponseFormat returns the typed form of a response format, or nil if it is not set or malformed.
+func ParseResponseFormat(responseFormat *interface{}) *ResponseFormat {
+	if responseFormat == nil || *responseFormat == nil {
+		return nil
+	}
+	data, err := sonic.Marshal(*responseFormat)
+	if err != nil {
+		return nil
+	}
+	var format ResponseFormat
+	if err := sonic.Unmarshal(data, &format); err != nil || format.Type == "" {
+		return nil
+	}
+	return &format
+}
+
 // ChatStreamOptions represents the stream options for a chat completion.
 type ChatStreamOptions struct {
 	IncludeObfuscation *bool `json:"include_obfuscation,omitempty"`
@@ -203,6 +245,8 @@ type ChatTool struct {
 	Type     ChatToolType      `json:"type"`
 	Function *ChatToolFunction `json:"function,omitempty"` // Function definition
 	Custom   *ChatToolCustom   `json:"custom,omitempty"`   // Custom tool definition
+
+	CacheControl *CacheControl `json:"cache_control,omitempty"` // Caches the tool definitions up to and including this tool
 }
 
 // ChatToolFunction represents a function definition.
@@ -221,6 +265,7 @@ type ToolFunctionParameters struct {
 	Properties           *OrderedMap `json:"properties,omitempty"`           // Parameter properties
 	Enum                 []string    `json:"enum,omitempty"`                 // Enum values for the parameters
 	AdditionalProperties *bool       `json:"additionalProperties,omitempty"` // Whether to allow additional properties
+	Defs                 *OrderedMap `json:"$defs,omitempty"`                // Definitions referenced by the properties
 }
 
 type OrderedMap map[string]interface{}
@@ -325,6 +370,7 @@ type ChatToolChoiceType string
 // ChatToolChoiceType values
 const (
 	ChatToolChoiceTypeNone     ChatToolChoiceType = "none"
+	ChatToolChoiceTypeAuto     ChatToolChoiceType = "auto"
 	ChatToolChoiceTypeAny      ChatToolChoiceType = "any"
 	ChatToolChoiceTypeRequired ChatToolChoiceType = "required"
 	// ChatToolChoiceTypeFunction means a specific tool must be called
@@ -497,6 +543,7 @@ const (
 	ChatContentBlockTypeText       ChatContentBlockType = "text"
 	ChatContentBlockTypeImage      ChatContentBlockType = "image_url"
 	ChatContentBlockTypeInputAudio ChatContentBlockType = "input_audio"
+	ChatContentBlockTypeVideo      ChatContentBlockType = "video_url"
 	ChatContentBlockTypeFile       ChatContentBlockType = "input_file"
 	ChatContentBlockTypeRefusal    ChatContentBlockType = "refusal"
 )
@@ -508,7 +555,27 @@ type ChatContentBlock struct {
 	Refusal        *string              `json:"refusal,omitempty"`
 	ImageURLStruct *ChatInputImage      `json:"image_url,omitempty"`
 	InputAudio     *ChatInputAudio      `json:"input_audio,omitempty"`
+	VideoURLStruct *ChatInputVideo      `json:"video_url,omitempty"`
 	File           *ChatInputFile       `json:"file,omitempty"`
+
+	CacheControl *CacheControl `json:"cache_control,omitempty"` // Caches the prompt up to and including this block
+}
+
+// CacheControlType represents the type of a prompt cache breakpoint.
+type CacheControlType string
+
+// CacheControlType values
+const (
+	CacheControlTypeEphemeral CacheControlType = "ephemeral"
+)
+
+// CacheControl marks a prompt caching breakpoint on a content block or tool.
+// Providers with explicit prompt caching (Anthropic) cache the prompt prefix ending at the breakpoint,
+// providers with automatic caching (OpenAI) ignore it. Cache reads are reported in
+// Usage.PromptTokensDetails.CachedTokens and cache writes in Usage.CompletionTokensDetails.CachedTokens.
+type CacheControl struct {
+	Type CacheControlType `json:"type"`
+	TTL  *string          `json:"ttl,omitempty"` // "5m" or "1h", provider default when nil
 }
 
 // ChatInputImage represents image data in a message.
@@ -525,6 +592,17 @@ type ChatInputAudio struct {
 	Format *string `json:"format,omitempty"`
 }
 
+// ChatInputVideo represents a video in a message, supported by Gemini and Vertex.
+// URL is a base64 data URL (data:video/mp4;base64,...), a Cloud Storage URI (gs://...) or a Gemini Files API URI.
+// Inline videos too large to be sent inline are uploaded with the Gemini Files API, Vertex only accepts them inline.
+type ChatInputVideo struct {
+	URL         string   `json:"url"`
+	MimeType    *string  `json:"mime_type,omitempty"`    // e.g. "video/mp4", taken from the data URL or the URI extension when nil
+	StartOffset *string  `json:"start_offset,omitempty"` // Start of the clip to use, as a duration (e.g. "10s")
+	EndOffset   *string  `json:"end_offset,omitempty"`   // End of the clip to use, as a duration (e.g. "1m30s")
+	FPS         *float64 `json:"fps,omitempty"`          // Frames sampled per second, provider default (1) when nil
+}
+
 // ChatInputFile represents a file in a message.
 type ChatInputFile struct {
 	FileData *string `json:"file_data,omitempty"` // Base64 encoded file data
@@ -542,6 +620,13 @@ type ChatAssistantMessage struct {
 	Refusal     *string                          `json:"refusal,omitempty"`
 	Annotations []ChatAssistantMessageAnnotation `json:"annotations,omitempty"`
 	ToolCalls   []ChatAssistantMessageToolCall   `json:"tool_calls,omitempty"`
+
+	// Reasoning of the model, returned by reasoning models which expose it (Anthropic extended thinking,
+	// Gemini thinking, DeepSeek and other OpenAI compatible reasoning models)
+	Thought *string `json:"thought,omitempty"`
+	// ThoughtSignature verifies the reasoning when it is sent back to the provider in later turns
+	// (required by Anthropic to continue tool use with extended thinking)
+	ThoughtSignature *string `json:"thought_signature,omitempty"`
 }
 
 // ChatAssistantMessageAnnotation represents an annotation in a response.
@@ -584,6 +669,8 @@ type BifrostResponseChoice struct {
 	FinishReason *string          `json:"finish_reason,omitempty"`
 	LogProbs     *BifrostLogProbs `json:"log_probs,omitempty"`
 
+	ContentFilterResults map[string]ContentFilterResult `json:"content_filter_results,omitempty"` // Azure content filter results of the output
+
 	*TextCompletionResponseChoice
 	*ChatNonStreamResponseChoice
 	*ChatStreamResponseChoice
@@ -614,11 +701,12 @@ type ChatStreamResponseChoice struct {
 
 // ChatStreamResponseChoiceDelta represents a delta in the stream response
 type ChatStreamResponseChoiceDelta struct {
-	Role      *string                        `json:"role,omitempty"`       // Only in the first chunk
-	Content   *string                        `json:"content,omitempty"`    // May be empty string or null
-	Thought   *string                        `json:"thought,omitempty"`    // May be empty string or null
-	Refusal   *string                        `json:"refusal,omitempty"`    // Refusal content if any
-	ToolCalls []ChatAssistantMessageToolCall `json:"tool_calls,omitempty"` // If tool calls used (supports incremental updates)
+	Role             *string                        `json:"role,omitempty"`              // Only in the first chunk
+	Content          *string                        `json:"content,omitempty"`           // May be empty string or null
+	Thought          *string                        `json:"thought,omitempty"`           // May be empty string or null
+	ThoughtSignature *string                        `json:"thought_signature,omitempty"` // Signature of the reasoning, sent once it is complete
+	Refusal          *string                        `json:"refusal,omitempty"`           // Refusal content if any
+	ToolCalls        []ChatAssistantMessageToolCall `json:"tool_calls,omitempty"`        // If tool calls used (supports incremental updates)
 }
 
 // LogProb represents the log probability of a token.
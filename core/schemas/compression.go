@@ -0,0 +1,51 @@
+package schemas
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PromptCompressionStrategy is how the oldest turns of a conversation exceeding its token budget are compressed
+type PromptCompressionStrategy string
+
+const (
+	PromptCompressionTruncate  PromptCompressionStrategy = "truncate"  // Drops the oldest turns
+	PromptCompressionSummarize PromptCompressionStrategy = "summarize" // Replaces the oldest turns with a summary written by a model, truncates when it fails
+)
+
+// PromptCompressionBudget is the token budget of the conversations sent to a model alias
+type PromptCompressionBudget struct {
+	TokenBudget        int                       `json:"token_budget"`                   // Input tokens of the messages above which the oldest turns are compressed
+	Strategy           PromptCompressionStrategy `json:"strategy"`                       // truncate or summarize
+	KeepRecentMessages int                       `json:"keep_recent_messages,omitempty"` // Latest messages never compressed, defaults to 4
+	SummaryModel       string                    `json:"summary_model,omitempty"`        // "provider/model" writing summaries, defaults to the model of the request
+	SummaryMaxTokens   int                       `json:"summary_max_tokens,omitempty"`   // Output tokens of summaries, defaults to 512
+}
+
+// PromptCompressionConfig configures the compression of the oldest turns of chat requests exceeding the token budget
+// of their model, so that long-running conversations keep working instead of failing on the context window.
+// System and developer messages are never compressed.
+type PromptCompressionConfig struct {
+	Budgets map[string]PromptCompressionBudget `json:"budgets"` // Budgets by model alias: "model", "provider/model" or "*" for all models
+}
+
+// Validate checks the budgets, strategies and summary models
+func (c *PromptCompressionConfig) Validate() error {
+	for alias, budget := range c.Budgets {
+		if budget.TokenBudget <= 0 {
+			return fmt.Errorf("token budget of %s must be positive", alias)
+		}
+		if budget.Strategy != PromptCompressionTruncate && budget.Strategy != PromptCompressionSummarize {
+			return fmt.Errorf("prompt compression strategy of %s must be truncate or summarize, got %q", alias, budget.Strategy)
+		}
+		if budget.KeepRecentMessages < 0 || budget.SummaryMaxTokens < 0 {
+			return fmt.Errorf("kept messages and summary tokens of %s can't be negative", alias)
+		}
+		if budget.SummaryModel != "" {
+			if provider, model, ok := strings.Cut(budget.SummaryModel, "/"); !ok || provider == "" || model == "" {
+				return fmt.Errorf("summary model of %s must be in the provider/model format, got %q", alias, budget.SummaryModel)
+			}
+		}
+	}
+	return nil
+}
@@ -16,6 +16,8 @@ var reservedKeys = []any{
 	BifrostContextKeyDirectKey,
 	BifrostContextKeySelectedKeyID,
 	BifrostContextKeySelectedKeyName,
+	BifrostContextKeySelectedKeyIsTest,
+	BifrostContextKeySelectedKeySpendLimit,
 	BifrostContextKeyNumberOfRetries,
 	BifrostContextKeyFallbackIndex,
 	BifrostContextKeyStreamEndIndicator,
@@ -0,0 +1,59 @@
+package schemas
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Conversation is the history of a chat conversation stored by Bifrost
+type Conversation struct {
+	ID        string        `json:"id"`
+	Owner     string        `json:"owner,omitempty"` // Caller the conversation belongs to: "vk:<virtual key>", "dk:<hash of the direct key>", or empty
+	User      string        `json:"user,omitempty"`  // End user of the conversation, from the user parameter of its first request
+	Messages  []ChatMessage `json:"messages"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// ConversationFilter selects conversations by their owner, end user and last update, empty fields match every
+// conversation
+type ConversationFilter struct {
+	Owner         string    `json:"owner,omitempty"`
+	User          string    `json:"user,omitempty"`
+	UpdatedBefore time.Time `json:"updated_before,omitempty"` // Conversations last updated before it, e.g. past their retention
+}
+
+// ConversationStore persists the conversations of Bifrost
+type ConversationStore interface {
+	// GetConversation returns the conversation with the ID, or nil if it is unknown
+	GetConversation(ctx context.Context, id string) (*Conversation, error)
+	// SaveConversation creates or replaces the conversation
+	SaveConversation(ctx context.Context, conversation *Conversation) error
+	// ListConversations returns the conversations matching the filter, with their messages
+	ListConversations(ctx context.Context, filter ConversationFilter) ([]Conversation, error)
+	// DeleteConversation deletes the conversation with the ID, and reports false if it is unknown
+	DeleteConversation(ctx context.Context, id string) (bool, error)
+	// DeleteConversations deletes the conversations matching the filter and returns how many were deleted
+	DeleteConversations(ctx context.Context, filter ConversationFilter) (int, error)
+}
+
+// ConversationConfig configures the conversations stored by Bifrost. Chat requests sent with a conversation ID
+// (BifrostContextKeyConversationID) only carry their new messages: Bifrost prepends the history of the conversation,
+// truncated to the limits below, and appends the new messages and the response to it. The stored history is never
+// truncated. A nil config disables conversations.
+type ConversationConfig struct {
+	MaxMessages      int `json:"max_messages,omitempty"`       // History messages sent with a request, the oldest are dropped above it. 0 sends them all
+	MaxHistoryTokens int `json:"max_history_tokens,omitempty"` // Estimated tokens of the history sent with a request, the oldest messages are dropped above it. 0 sends them all
+}
+
+// Validate checks the limits of the config
+func (c *ConversationConfig) Validate() error {
+	if c.MaxMessages < 0 {
+		return fmt.Errorf("conversations max messages cannot be negative, got %d", c.MaxMessages)
+	}
+	if c.MaxHistoryTokens < 0 {
+		return fmt.Errorf("conversations max history tokens cannot be negative, got %d", c.MaxHistoryTokens)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package schemas
+
+import (
+	"fmt"
+	"time"
+)
+
+// ModelDeprecationDateLayout is the layout of the sunset dates of model deprecations
+const ModelDeprecationDateLayout = "2006-01-02"
+
+// ModelDeprecation marks a model as deprecated in favor of a replacement. Until the sunset date requests to the model
+// are served as they are and clients are warned, from the sunset date they are transparently sent to the replacement.
+type ModelDeprecation struct {
+	Model       string `json:"model"`             // "model" on every provider or "provider/model"
+	Replacement string `json:"replacement"`       // "model" on the same provider or "provider/model"
+	SunsetDate  string `json:"sunset_date"`       // YYYY-MM-DD in UTC, requests are remapped from this date on
+	Message     string `json:"message,omitempty"` // Optional note for the clients of the model
+}
+
+// Sunset returns the start of the sunset date in UTC
+func (d *ModelDeprecation) Sunset() time.Time {
+	sunset, _ := time.Parse(ModelDeprecationDateLayout, d.SunsetDate)
+	return sunset
+}
+
+// Validate checks the model, replacement and sunset date of the deprecation
+func (d *ModelDeprecation) Validate() error {
+	if d.Model == "" || d.Replacement == "" {
+		return fmt.Errorf("model and replacement are required")
+	}
+	if d.Model == d.Replacement {
+		return fmt.Errorf("model %s cannot replace itself", d.Model)
+	}
+	if _, err := time.Parse(ModelDeprecationDateLayout, d.SunsetDate); err != nil {
+		return fmt.Errorf("sunset date of %s must be in the YYYY-MM-DD format, got %q", d.Model, d.SunsetDate)
+	}
+	return nil
+}
+
+// ValidateModelDeprecations validates each deprecation and checks that models are deprecated once and that
+// replacements are not deprecated themselves, so that requests are remapped at most once
+func ValidateModelDeprecations(deprecations []ModelDeprecation) error {
+	models := make(map[string]bool, len(deprecations))
+	for i := range deprecations {
+		if err := deprecations[i].Validate(); err != nil {
+			return err
+		}
+		if models[deprecations[i].Model] {
+			return fmt.Errorf("model %s is deprecated more than once", deprecations[i].Model)
+		}
+		models[deprecations[i].Model] = true
+	}
+	for i := range deprecations {
+		if models[deprecations[i].Replacement] {
+			return fmt.Errorf("replacement %s of %s is deprecated itself", deprecations[i].Replacement, deprecations[i].Model)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package schemas
+
+import "fmt"
+
+// Defaults of the diagnostics capture
+const (
+	DefaultDiagnosticsCapacity     = 100
+	DefaultDiagnosticsMaxBodyBytes = 1 << 20 // 1 MiB
+)
+
+// DiagnosticsConfig configures the capture of the slow and large inference requests of the HTTP transport. The
+// bodies of the requests exceeding a threshold are kept in a ring buffer, to debug tail latency without logging the
+// content of every request.
+type DiagnosticsConfig struct {
+	Enabled            bool    `json:"enabled"`
+	LatencyThresholdMs int64   `json:"latency_threshold_ms,omitempty"` // Requests taking longer are captured, 0 disables the latency threshold
+	SizeThresholdBytes int     `json:"size_threshold_bytes,omitempty"` // Requests whose request or response body is larger are captured, 0 disables the size threshold
+	SampleRate         float64 `json:"sample_rate,omitempty"`          // Share of the requests exceeding a threshold that are captured, from 0 to 1, 0 captures all of them
+	Capacity           int     `json:"capacity,omitempty"`             // Number of captures kept, the oldest are dropped first, defaults to DefaultDiagnosticsCapacity
+	MaxBodyBytes       int     `json:"max_body_bytes,omitempty"`       // Size above which captured bodies are truncated, defaults to DefaultDiagnosticsMaxBodyBytes
+}
+
+// Validate checks that the thresholds and sizes are not negative, that the sample rate is a share, and that an
+// enabled capture has a threshold
+func (c *DiagnosticsConfig) Validate() error {
+	if c.LatencyThresholdMs < 0 {
+		return fmt.Errorf("latency threshold cannot be negative, got %d", c.LatencyThresholdMs)
+	}
+	if c.SizeThresholdBytes < 0 {
+		return fmt.Errorf("size threshold cannot be negative, got %d", c.SizeThresholdBytes)
+	}
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		return fmt.Errorf("sample rate must be between 0 and 1, got %g", c.SampleRate)
+	}
+	if c.Capacity < 0 {
+		return fmt.Errorf("capacity cannot be negative, got %d", c.Capacity)
+	}
+	if c.MaxBodyBytes < 0 {
+		return fmt.Errorf("max body bytes cannot be negative, got %d", c.MaxBodyBytes)
+	}
+	if c.Enabled && c.LatencyThresholdMs == 0 && c.SizeThresholdBytes == 0 {
+		return fmt.Errorf("a latency or size threshold is required to enable diagnostics")
+	}
+	return nil
+}
+
+// GetCapacity returns the number of captures kept
+func (c *DiagnosticsConfig) GetCapacity() int {
+	if c.Capacity <= 0 {
+		return DefaultDiagnosticsCapacity
+	}
+	return c.Capacity
+}
+
+// GetMaxBodyBytes returns the size above which captured bodies are truncated
+func (c *DiagnosticsConfig) GetMaxBodyBytes() int {
+	if c.MaxBodyBytes <= 0 {
+		return DefaultDiagnosticsMaxBodyBytes
+	}
+	return c.MaxBodyBytes
+}
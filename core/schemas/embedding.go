@@ -25,6 +25,18 @@ type BifrostEmbeddingResponse struct {
 	Object      string                     `json:"object"` // "list"
 	Usage       *BifrostLLMUsage           `json:"usage"`
 	ExtraFields BifrostResponseExtraFields `json:"extra_fields"`
+
+	// Batches that failed when the input was split into batches, the embeddings of their inputs are missing from Data
+	FailedBatches []EmbeddingBatchError `json:"failed_batches,omitempty"`
+	// Status of every input when some batches failed, in the order of the inputs
+	ItemStatuses []EmbeddingItemStatus `json:"item_statuses,omitempty"`
+}
+
+// EmbeddingBatchError is a batch of the inputs of an embedding request that failed
+type EmbeddingBatchError struct {
+	StartIndex int           `json:"start_index"` // Index of the first input of the batch
+	EndIndex   int           `json:"end_index"`   // Index after the last input of the batch
+	Error      *BifrostError `json:"error"`
 }
 
 // EmbeddingInput represents the input for an embedding request.
@@ -109,6 +121,7 @@ func (e *EmbeddingInput) UnmarshalJSON(data []byte) error {
 type EmbeddingParameters struct {
 	EncodingFormat *string `json:"encoding_format,omitempty"` // Format for embedding output (e.g., "float", "base64")
 	Dimensions     *int    `json:"dimensions,omitempty"`      // Number of dimensions for embedding output
+	Normalize      *bool   `json:"normalize,omitempty"`       // Scale embeddings to unit length, by Bifrost when the provider cannot
 
 	// Dynamic parameters that can be provider-specific, they are directly
 	// added to the request as is.
@@ -0,0 +1,175 @@
+package schemas
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultEndpointResolveTimeout bounds the resolution of the host names of the endpoints being checked
+const DefaultEndpointResolveTimeout = 5 * time.Second
+
+// metadataHosts are the host names of the instance metadata services of the cloud providers
+var metadataHosts = []string{"metadata", "metadata.google.internal", "metadata.azure.internal", "instance-data"}
+
+// EndpointPolicy is the egress policy of the custom endpoints of providers and keys, the base URLs of
+// network configs and the endpoints of Azure keys. Endpoints matching a denied rule are rejected, endpoints
+// matching an allowed rule are accepted, and other endpoints are rejected when AllowOnlyListed is set or
+// when their host resolves to a loopback, link-local (which includes the cloud metadata services),
+// unspecified or multicast address, or to a private address unless AllowPrivateNetworks is set.
+//
+// Rules are host names, wildcard host names (*.example.com, matching the subdomains only) or CIDRs,
+// matched against the addresses the host resolves to. A nil policy accepts every endpoint.
+type EndpointPolicy struct {
+	AllowedHosts         []string `json:"allowed_hosts,omitempty"`          // Host names, wildcards or CIDRs always accepted, e.g. localhost for a local Ollama
+	DeniedHosts          []string `json:"denied_hosts,omitempty"`           // Host names, wildcards or CIDRs always rejected
+	AllowOnlyListed      bool     `json:"allow_only_listed,omitempty"`      // Reject endpoints not matching an allowed rule
+	AllowPrivateNetworks bool     `json:"allow_private_networks,omitempty"` // Accept endpoints resolving to private addresses (10.0.0.0/8, 172.16.0.0/12, 192.168.0.0/16, fc00::/7)
+}
+
+// Validate checks that the CIDR rules of the policy are valid
+func (p *EndpointPolicy) Validate() error {
+	for _, rule := range append(append([]string{}, p.AllowedHosts...), p.DeniedHosts...) {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			return fmt.Errorf("endpoint policy rules cannot be empty")
+		}
+		if strings.Contains(rule, "/") {
+			if _, err := netip.ParsePrefix(rule); err != nil {
+				return fmt.Errorf("invalid endpoint policy cidr %s: %v", rule, err)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckEndpoint returns an error if the policy rejects the endpoint. The host of the endpoint is resolved
+// with the system resolver unless a host name rule matches it; endpoints whose host cannot be resolved
+// are rejected.
+func (p *EndpointPolicy) CheckEndpoint(ctx context.Context, endpoint string) error {
+	if p == nil {
+		return nil
+	}
+	host, err := endpointHost(endpoint)
+	if err != nil {
+		return err
+	}
+
+	var addrs []netip.Addr
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = []netip.Addr{addr.Unmap()}
+	} else if !p.matchesHostName(p.AllowedHosts, host) && !p.matchesHostName(p.DeniedHosts, host) {
+		ctx, cancel := context.WithTimeout(ctx, DefaultEndpointResolveTimeout)
+		defer cancel()
+		ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return fmt.Errorf("endpoint %s is not allowed: cannot resolve %s: %v", endpoint, host, err)
+		}
+		for _, ip := range ips {
+			addrs = append(addrs, ip.Unmap())
+		}
+	}
+
+	if p.matches(p.DeniedHosts, host, addrs) {
+		return fmt.Errorf("endpoint %s is denied by the endpoint policy", endpoint)
+	}
+	if p.matches(p.AllowedHosts, host, addrs) {
+		return nil
+	}
+	if p.AllowOnlyListed {
+		return fmt.Errorf("endpoint %s is not in the allowed hosts of the endpoint policy", endpoint)
+	}
+	for _, metadataHost := range metadataHosts {
+		if strings.EqualFold(host, metadataHost) {
+			return fmt.Errorf("endpoint %s is not allowed: %s is a cloud metadata service", endpoint, host)
+		}
+	}
+	for _, addr := range addrs {
+		if reason := p.blockedReason(addr); reason != "" {
+			return fmt.Errorf("endpoint %s is not allowed: %s resolves to %s address %s", endpoint, host, reason, addr)
+		}
+	}
+	return nil
+}
+
+// blockedReason returns the kind of the address if it is rejected by default, or an empty string
+func (p *EndpointPolicy) blockedReason(addr netip.Addr) string {
+	switch {
+	case addr.IsLoopback():
+		return "loopback"
+	case addr.IsLinkLocalUnicast(), addr.IsLinkLocalMulticast():
+		return "link-local"
+	case addr.IsUnspecified():
+		return "unspecified"
+	case addr.IsMulticast():
+		return "multicast"
+	case addr == netip.MustParseAddr("fd00:ec2::254"):
+		return "cloud metadata"
+	case addr.IsPrivate() && !p.AllowPrivateNetworks:
+		return "private"
+	}
+	return ""
+}
+
+// matches reports whether one of the rules matches the host name or one of its addresses
+func (p *EndpointPolicy) matches(rules []string, host string, addrs []netip.Addr) bool {
+	if p.matchesHostName(rules, host) {
+		return true
+	}
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		var prefix netip.Prefix
+		if strings.Contains(rule, "/") {
+			parsed, err := netip.ParsePrefix(rule)
+			if err != nil {
+				continue
+			}
+			prefix = parsed.Masked()
+		} else if addr, err := netip.ParseAddr(rule); err == nil {
+			prefix = netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen())
+		} else {
+			continue
+		}
+		for _, addr := range addrs {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesHostName reports whether one of the host name rules matches the host name
+func (p *EndpointPolicy) matchesHostName(rules []string, host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, rule := range rules {
+		rule = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(rule)), ".")
+		if suffix, ok := strings.CutPrefix(rule, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if rule == host {
+			return true
+		}
+	}
+	return false
+}
+
+// endpointHost returns the host of the endpoint, which must be an http or https URL
+func endpointHost(endpoint string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(endpoint))
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint %s: %v", endpoint, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", fmt.Errorf("invalid endpoint %s: the scheme must be http or https", endpoint)
+	}
+	if parsed.Hostname() == "" {
+		return "", fmt.Errorf("invalid endpoint %s: missing host", endpoint)
+	}
+	return parsed.Hostname(), nil
+}
@@ -0,0 +1,51 @@
+package schemas
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEndpointPolicyCheckEndpoint(t *testing.T) {
+	policy := &EndpointPolicy{
+		AllowedHosts: []string{"localhost", "127.0.0.1", "10.1.0.0/16", "*.openai.azure.com"},
+		DeniedHosts:  []string{"10.1.2.0/24", "blocked.openai.azure.com"},
+	}
+	tests := []struct {
+		endpoint string
+		allowed  bool
+	}{
+		{"http://169.254.169.254/latest/meta-data", false},
+		{"http://[fd00:ec2::254]/latest", false},
+		{"http://metadata.google.internal/computeMetadata/v1", false},
+		{"http://[::1]:11434", false},
+		{"http://0.0.0.0:8080", false},
+		{"http://192.168.1.10", false},
+		{"http://127.0.0.1:11434", true},
+		{"http://localhost:11434", true},
+		{"http://10.1.5.5", true},
+		{"http://10.1.2.5", false},
+		{"https://team.openai.azure.com", true},
+		{"https://blocked.openai.azure.com", false},
+		{"https://8.8.8.8", true},
+		{"ftp://8.8.8.8", false},
+	}
+	for _, tt := range tests {
+		err := policy.CheckEndpoint(context.Background(), tt.endpoint)
+		if (err == nil) != tt.allowed {
+			t.Errorf("CheckEndpoint(%q) = %v, expected allowed %v", tt.endpoint, err, tt.allowed)
+		}
+	}
+
+	private := &EndpointPolicy{AllowPrivateNetworks: true}
+	if err := private.CheckEndpoint(context.Background(), "http://192.168.1.10"); err != nil {
+		t.Errorf("expected private addresses to be allowed, got %v", err)
+	}
+	listed := &EndpointPolicy{AllowOnlyListed: true, AllowedHosts: []string{"1.1.1.1"}}
+	if err := listed.CheckEndpoint(context.Background(), "https://8.8.8.8"); err == nil {
+		t.Error("expected endpoints not in the allowed hosts to be rejected")
+	}
+	var none *EndpointPolicy
+	if err := none.CheckEndpoint(context.Background(), "http://169.254.169.254"); err != nil {
+		t.Errorf("expected a nil policy to accept every endpoint, got %v", err)
+	}
+}
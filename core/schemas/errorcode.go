@@ -0,0 +1,21 @@
+package schemas
+
+// ErrorCode is the canonical code of an error, the same whichever provider returned it. The raw type, code and message
+// of the provider are kept on the ErrorField of the error.
+type ErrorCode string
+
+const (
+	ErrorCodeRateLimited      ErrorCode = "rate_limited"      // Too many requests or tokens for the rate limits or quota of the key
+	ErrorCodeContextLength    ErrorCode = "context_length"    // The input and output tokens exceed the context window of the model
+	ErrorCodeContentFiltered  ErrorCode = "content_filtered"  // Rejected by the content filter or safety system of the provider
+	ErrorCodeAuthFailed       ErrorCode = "auth_failed"       // Missing, invalid or expired credentials
+	ErrorCodePermission       ErrorCode = "permission_denied" // Valid credentials without access to the model or resource
+	ErrorCodeNotFound         ErrorCode = "not_found"         // Unknown model or resource
+	ErrorCodeInvalidRequest   ErrorCode = "invalid_request"   // Malformed request or unsupported parameters
+	ErrorCodeOverloaded       ErrorCode = "overloaded"        // The provider is temporarily out of capacity
+	ErrorCodeTimeout          ErrorCode = "timeout"           // The provider did not answer in time
+	ErrorCodeConnectionFailed ErrorCode = "connection_failed" // The request could not be sent to the provider
+	ErrorCodeCancelled        ErrorCode = "cancelled"         // The request was cancelled by the caller
+	ErrorCodeProviderError    ErrorCode = "provider_error"    // Internal error of the provider
+	ErrorCodeUnknown          ErrorCode = "unknown"
+)
@@ -0,0 +1,120 @@
+package schemas
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ExperimentAssignment is the identifier of the requests an experiment assigns to its variants, the requests sharing
+// it are always assigned the same variant
+type ExperimentAssignment string
+
+const (
+	ExperimentAssignByUser         ExperimentAssignment = "user"         // End user of the request (BifrostContextKeyEndUser), the default
+	ExperimentAssignByConversation ExperimentAssignment = "conversation" // Stored conversation of the request (BifrostContextKeyConversationID)
+)
+
+// Tags recorded on the requests of an experiment, next to the request tags of the allow-list
+const (
+	ExperimentTag        = "experiment"
+	ExperimentVariantTag = "experiment_variant"
+)
+
+// ExperimentVariant is an arm of an experiment, and the changes it makes to the requests assigned to it. A variant
+// without changes is the control arm.
+type ExperimentVariant struct {
+	Name         string                 `json:"name"`
+	Weight       float64                `json:"weight"`                  // Share of the traffic of the experiment among the variants
+	Model        string                 `json:"model,omitempty"`         // Model the requests are sent to, as "model", "provider/model" or a fallback chain alias, empty keeps the requested model
+	Params       map[string]interface{} `json:"params,omitempty"`        // Parameters set on the requests, e.g. {"temperature": 0.2}
+	SystemPrompt string                 `json:"system_prompt,omitempty"` // Prompt template added as a system prompt before the messages of the requests
+}
+
+// Experiment splits the requests of models between variants, deterministically by user or conversation, so that the
+// quality of the variants can be compared with the feedback of the clients. Requests without the identifier the
+// experiment assigns by are not enrolled.
+type Experiment struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description,omitempty"`
+	Enabled     bool                 `json:"enabled"`
+	Models      []string             `json:"models"`              // Requested models enrolled, as "model", "provider/model" or "*" for all models
+	AssignBy    ExperimentAssignment `json:"assign_by,omitempty"` // Identifier the requests are assigned by, defaults to ExperimentAssignByUser
+	Variants    []ExperimentVariant  `json:"variants"`
+}
+
+// ParamActions returns the parameter overrides of the variant as set transform actions, sorted by parameter
+func (v *ExperimentVariant) ParamActions() []TransformAction {
+	if len(v.Params) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(v.Params))
+	for name := range v.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	actions := make([]TransformAction, 0, len(names))
+	for _, name := range names {
+		actions = append(actions, TransformAction{Type: TransformActionSet, Path: "$." + name, Value: v.Params[name]})
+	}
+	return actions
+}
+
+// GetAssignBy returns the identifier the requests are assigned by
+func (e *Experiment) GetAssignBy() ExperimentAssignment {
+	if e.AssignBy == "" {
+		return ExperimentAssignByUser
+	}
+	return e.AssignBy
+}
+
+// Validate checks the experiment for missing fields and invalid variants
+func (e *Experiment) Validate() error {
+	if e.Name == "" {
+		return fmt.Errorf("experiment name is required")
+	}
+	if len(e.Models) == 0 {
+		return fmt.Errorf("experiment %s must apply to at least one model, use \"*\" for all models", e.Name)
+	}
+	switch e.AssignBy {
+	case "", ExperimentAssignByUser, ExperimentAssignByConversation:
+	default:
+		return fmt.Errorf("experiment %s assign_by must be %q or %q", e.Name, ExperimentAssignByUser, ExperimentAssignByConversation)
+	}
+	if len(e.Variants) < 2 {
+		return fmt.Errorf("experiment %s requires at least two variants", e.Name)
+	}
+	names := make(map[string]bool, len(e.Variants))
+	for i, variant := range e.Variants {
+		if variant.Name == "" {
+			return fmt.Errorf("experiment %s variant %d requires a name", e.Name, i)
+		}
+		if names[variant.Name] {
+			return fmt.Errorf("experiment %s has duplicate variant %s", e.Name, variant.Name)
+		}
+		names[variant.Name] = true
+		if variant.Weight <= 0 {
+			return fmt.Errorf("experiment %s variant %s requires a positive weight", e.Name, variant.Name)
+		}
+		for _, action := range variant.ParamActions() {
+			if err := action.Validate(); err != nil {
+				return fmt.Errorf("experiment %s variant %s: %v", e.Name, variant.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateExperiments validates each experiment and checks that names are unique
+func ValidateExperiments(experiments []Experiment) error {
+	names := make(map[string]bool, len(experiments))
+	for i := range experiments {
+		if err := experiments[i].Validate(); err != nil {
+			return err
+		}
+		if names[experiments[i].Name] {
+			return fmt.Errorf("duplicate experiment name %s", experiments[i].Name)
+		}
+		names[experiments[i].Name] = true
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package schemas
+
+import (
+	"fmt"
+	"sort"
+)
+
+// FallbackHop is a provider and model of a fallback chain, with the overrides of the requests sent to it
+type FallbackHop struct {
+	Provider   ModelProvider          `json:"provider"`
+	Model      string                 `json:"model"`                 // Model sent to the provider, the requested model is rewritten to it
+	Weight     float64                `json:"weight,omitempty"`      // Share of the requests starting at this hop among the hops with a weight, hops without one only serve as fallbacks
+	Params     map[string]interface{} `json:"params,omitempty"`      // Parameters set on the requests of the hop, e.g. {"max_tokens": 512}
+	MaxRetries *int                   `json:"max_retries,omitempty"` // Retries of the hop for each retry rule of the provider, nil keeps the retry policy of the provider
+}
+
+// FallbackChain is the ordered list of providers and models the requests of a model alias are sent to. A request to
+// the alias starts at a hop picked by weight and then falls back to the other hops in their order, the fallbacks sent
+// with the request are replaced by the chain.
+type FallbackChain struct {
+	Alias       string        `json:"alias"` // Requested model the chain applies to, as "model" or "provider/model"
+	Description string        `json:"description,omitempty"`
+	Hops        []FallbackHop `json:"hops"`
+}
+
+// ParamActions returns the parameter overrides of the hop as set transform actions, sorted by parameter
+func (h *FallbackHop) ParamActions() []TransformAction {
+	if len(h.Params) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(h.Params))
+	for name := range h.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	actions := make([]TransformAction, 0, len(names))
+	for _, name := range names {
+		actions = append(actions, TransformAction{Type: TransformActionSet, Path: "$." + name, Value: h.Params[name]})
+	}
+	return actions
+}
+
+// Validate checks the chain for missing fields and invalid hops
+func (c *FallbackChain) Validate() error {
+	if c.Alias == "" {
+		return fmt.Errorf("fallback chain alias is required")
+	}
+	if len(c.Hops) == 0 {
+		return fmt.Errorf("fallback chain %s requires at least one hop", c.Alias)
+	}
+	for i, hop := range c.Hops {
+		if hop.Provider == "" || hop.Model == "" {
+			return fmt.Errorf("fallback chain %s hop %d requires a provider and a model", c.Alias, i)
+		}
+		if hop.Weight < 0 {
+			return fmt.Errorf("fallback chain %s hop %d has a negative weight", c.Alias, i)
+		}
+		if hop.MaxRetries != nil && *hop.MaxRetries < 0 {
+			return fmt.Errorf("fallback chain %s hop %d has negative max retries", c.Alias, i)
+		}
+		for _, action := range hop.ParamActions() {
+			if err := action.Validate(); err != nil {
+				return fmt.Errorf("fallback chain %s hop %d: %v", c.Alias, i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateFallbackChains validates each chain and checks that aliases are unique
+func ValidateFallbackChains(chains []FallbackChain) error {
+	aliases := make(map[string]bool, len(chains))
+	for i := range chains {
+		if err := chains[i].Validate(); err != nil {
+			return err
+		}
+		if aliases[chains[i].Alias] {
+			return fmt.Errorf("duplicate fallback chain alias %s", chains[i].Alias)
+		}
+		aliases[chains[i].Alias] = true
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package schemas
+
+import "fmt"
+
+// FallbackPolicy decides which errors of a provider are tried on the fallbacks of the request, and which fail fast.
+// It applies to the errors returned by the provider, once its retries are exhausted. Errors raised by Bifrost, such
+// as plugin rejections, keep their own fallback behavior. A provider without a policy tries every error on the
+// fallbacks.
+type FallbackPolicy struct {
+	Rules []FallbackRule `json:"rules"` // Evaluated in order, the first rule matching an error decides. Errors matching no rule are tried on the fallbacks
+}
+
+// FallbackRule is the fallback behavior of a class of errors.
+// An error matches a rule when it matches any of its conditions.
+type FallbackRule struct {
+	Name             string   `json:"name"`                        // Name of the error class, used in logs
+	StatusCodes      []int    `json:"status_codes,omitempty"`      // HTTP status codes of the provider response
+	ErrorCodes       []string `json:"error_codes,omitempty"`       // Error codes or types returned by the provider, e.g. "context_length_exceeded", or canonical error codes, e.g. "context_length"
+	MessagePatterns  []string `json:"message_patterns,omitempty"`  // Case-insensitive substrings of the error message
+	ConnectionErrors bool     `json:"connection_errors,omitempty"` // Requests that could not be sent to the provider
+	ContentFilter    bool     `json:"content_filter,omitempty"`    // Requests rejected by the content filter or safety system of the provider
+	Fallback         bool     `json:"fallback"`                    // Whether errors of this class are tried on the fallbacks, false returns them right away
+}
+
+// Validate checks that every rule of the policy matches some errors
+func (p *FallbackPolicy) Validate() error {
+	for i, rule := range p.Rules {
+		if len(rule.StatusCodes) == 0 && len(rule.ErrorCodes) == 0 && len(rule.MessagePatterns) == 0 && !rule.ConnectionErrors && !rule.ContentFilter {
+			return fmt.Errorf("rule %d does not match any error", i)
+		}
+	}
+	return nil
+}
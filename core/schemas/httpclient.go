@@ -0,0 +1,122 @@
+package schemas
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// HTTPClientType is the HTTP client used to send the requests of a provider
+type HTTPClientType string
+
+const (
+	HTTPClientFastHTTP HTTPClientType = "fasthttp" // HTTP/1.1 client with its own connection pool, the default
+	HTTPClientHTTP2    HTTPClientType = "http2"    // net/http client negotiating HTTP/2 with the servers supporting it
+)
+
+// Default tuning of the HTTP clients of providers
+const (
+	DefaultMaxConnsPerHost     = 5000
+	DefaultMaxIdleConnDuration = 60 * time.Second
+	DefaultMaxConnWaitTimeout  = 10 * time.Second
+	DefaultDNSCacheTTL         = time.Minute
+)
+
+// HTTPClientConfig tunes the HTTP client of a provider. Zero values keep the defaults.
+// Bedrock always uses a net/http client, for which Type and MaxConnWaitTimeoutInSeconds are ignored.
+type HTTPClientConfig struct {
+	Type                         HTTPClientType `json:"type,omitempty"`                              // Client to use, fasthttp (default) or http2
+	MaxConnsPerHost              int            `json:"max_conns_per_host,omitempty"`                // Maximum number of connections per host
+	MaxIdleConnDurationInSeconds int            `json:"max_idle_conn_duration_in_seconds,omitempty"` // Idle connections are closed after this duration
+	MaxConnWaitTimeoutInSeconds  int            `json:"max_conn_wait_timeout_in_seconds,omitempty"`  // Maximum wait for a free connection when all are busy
+	ReadBufferSize               int            `json:"read_buffer_size,omitempty"`                  // Per-connection read buffer size in bytes, also limits the response header size
+	WriteBufferSize              int            `json:"write_buffer_size,omitempty"`                 // Per-connection write buffer size in bytes
+	WarmConnections              int            `json:"warm_connections,omitempty"`                  // Connections to the base URL established when the provider is created, capped to MaxConnsPerHost
+	DNS                          *DNSConfig     `json:"dns,omitempty"`                               // Resolution of the host names of the provider (optional)
+}
+
+// DNSConfig controls how the HTTP client of a provider resolves host names. Static hosts are used instead of DNS,
+// other host names are resolved by the system resolver and cached. When a lookup fails, the last addresses
+// resolved for the host are used, so that a flapping DNS does not fail requests.
+type DNSConfig struct {
+	CacheTTLInSeconds    int                 `json:"cache_ttl_in_seconds,omitempty"`   // How long resolved addresses are reused, regardless of the TTL of the records (default 60)
+	StaticHosts          map[string][]string `json:"static_hosts,omitempty"`           // IP addresses of host names, used instead of DNS
+	DisableHappyEyeballs bool                `json:"disable_happy_eyeballs,omitempty"` // Try the addresses one after the other instead of racing IPv6 and IPv4 connections
+}
+
+// GetMaxConnsPerHost returns the maximum number of connections per host, or the default
+func (c *HTTPClientConfig) GetMaxConnsPerHost() int {
+	if c == nil || c.MaxConnsPerHost <= 0 {
+		return DefaultMaxConnsPerHost
+	}
+	return c.MaxConnsPerHost
+}
+
+// GetMaxIdleConnDuration returns the duration after which idle connections are closed, or the default
+func (c *HTTPClientConfig) GetMaxIdleConnDuration() time.Duration {
+	if c == nil || c.MaxIdleConnDurationInSeconds <= 0 {
+		return DefaultMaxIdleConnDuration
+	}
+	return time.Duration(c.MaxIdleConnDurationInSeconds) * time.Second
+}
+
+// GetMaxConnWaitTimeout returns the maximum wait for a free connection, or the default
+func (c *HTTPClientConfig) GetMaxConnWaitTimeout() time.Duration {
+	if c == nil || c.MaxConnWaitTimeoutInSeconds <= 0 {
+		return DefaultMaxConnWaitTimeout
+	}
+	return time.Duration(c.MaxConnWaitTimeoutInSeconds) * time.Second
+}
+
+// GetWarmConnections returns the number of connections to establish when the provider is created,
+// at most the maximum number of connections per host
+func (c *HTTPClientConfig) GetWarmConnections() int {
+	if c == nil || c.WarmConnections <= 0 {
+		return 0
+	}
+	return min(c.WarmConnections, c.GetMaxConnsPerHost())
+}
+
+// GetCacheTTL returns how long resolved addresses are reused, or the default
+func (c *DNSConfig) GetCacheTTL() time.Duration {
+	if c == nil || c.CacheTTLInSeconds <= 0 {
+		return DefaultDNSCacheTTL
+	}
+	return time.Duration(c.CacheTTLInSeconds) * time.Second
+}
+
+// IsHTTP2 reports whether the provider uses the net/http client negotiating HTTP/2
+func (c *HTTPClientConfig) IsHTTP2() bool {
+	return c != nil && c.Type == HTTPClientHTTP2
+}
+
+// Validate checks the client type and that the sizes and durations are not negative
+func (c *HTTPClientConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	switch c.Type {
+	case "", HTTPClientFastHTTP, HTTPClientHTTP2:
+	default:
+		return fmt.Errorf("unsupported http client type %s, expected %s or %s", c.Type, HTTPClientFastHTTP, HTTPClientHTTP2)
+	}
+	if c.MaxConnsPerHost < 0 || c.MaxIdleConnDurationInSeconds < 0 || c.MaxConnWaitTimeoutInSeconds < 0 || c.ReadBufferSize < 0 || c.WriteBufferSize < 0 || c.WarmConnections < 0 {
+		return fmt.Errorf("http client connection limits, durations, buffer sizes and warm connections cannot be negative")
+	}
+	if c.DNS != nil {
+		if c.DNS.CacheTTLInSeconds < 0 {
+			return fmt.Errorf("dns cache ttl cannot be negative")
+		}
+		for host, addresses := range c.DNS.StaticHosts {
+			if len(addresses) == 0 {
+				return fmt.Errorf("static host %s must have at least one address", host)
+			}
+			for _, address := range addresses {
+				if net.ParseIP(address) == nil {
+					return fmt.Errorf("static host %s has an invalid IP address %s", host, address)
+				}
+			}
+		}
+	}
+	return nil
+}
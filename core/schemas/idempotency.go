@@ -0,0 +1,48 @@
+package schemas
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultIdempotencyTTL is how long the results of requests with an Idempotency-Key are replayed when the config does not set it
+const DefaultIdempotencyTTL = 5 * time.Minute
+
+// DefaultIdempotencyMaxEntries is the number of results kept for replay when the config does not set it
+const DefaultIdempotencyMaxEntries = 10000
+
+// IdempotencyConfig configures the deduplication of non-stream requests sent with an idempotency key
+// (BifrostContextKeyIdempotencyKey). Duplicates of a request in flight wait for its result instead of calling
+// the provider again, and duplicates of a completed request get its response replayed until the TTL expires.
+// Failed requests are not kept, so that their retries go to the provider. A nil config uses the defaults.
+type IdempotencyConfig struct {
+	TTLSeconds int `json:"ttl_seconds,omitempty"` // How long successful responses are replayed. 0 uses DefaultIdempotencyTTL
+	MaxEntries int `json:"max_entries,omitempty"` // Responses kept for replay, responses of requests completing above it are not kept. 0 uses DefaultIdempotencyMaxEntries
+}
+
+// TTL returns how long successful responses are replayed
+func (c *IdempotencyConfig) TTL() time.Duration {
+	if c == nil || c.TTLSeconds <= 0 {
+		return DefaultIdempotencyTTL
+	}
+	return time.Duration(c.TTLSeconds) * time.Second
+}
+
+// Capacity returns the number of responses kept for replay
+func (c *IdempotencyConfig) Capacity() int {
+	if c == nil || c.MaxEntries <= 0 {
+		return DefaultIdempotencyMaxEntries
+	}
+	return c.MaxEntries
+}
+
+// Validate checks the TTL and the capacity of the config
+func (c *IdempotencyConfig) Validate() error {
+	if c.TTLSeconds < 0 {
+		return fmt.Errorf("idempotency ttl cannot be negative, got %d", c.TTLSeconds)
+	}
+	if c.MaxEntries < 0 {
+		return fmt.Errorf("idempotency max entries cannot be negative, got %d", c.MaxEntries)
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package schemas
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidateJSONSchema validates a decoded JSON value (as produced by encoding/json or sonic into interface{})
+// against a JSON schema. It supports the keywords used by structured outputs: type, enum, const, properties,
+// required, additionalProperties, items, minItems, maxItems, minLength, maxLength, pattern, minimum, maximum,
+// anyOf, oneOf, allOf and local $ref pointers to $defs or definitions. Unknown keywords are ignored.
+// Returns an error describing the first violation, prefixed with its path in the value.
+func ValidateJSONSchema(value any, schema map[string]any) error {
+	return validateJSONSchema(value, schema, schema, "$")
+}
+
+func validateJSONSchema(value any, schema, root map[string]any, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, err := resolveJSONSchemaRef(ref, root)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return validateJSONSchema(value, resolved, root, path)
+	}
+
+	if types, ok := jsonSchemaTypes(schema["type"]); ok {
+		matched := false
+		for _, schemaType := range types {
+			if jsonValueHasType(value, schemaType) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("%s: expected %s, got %s", path, strings.Join(types, " or "), jsonValueType(value))
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, candidate := range enum {
+			if jsonValuesEqual(value, candidate) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: value is not one of the allowed values", path)
+		}
+	}
+	if constValue, ok := schema["const"]; ok && !jsonValuesEqual(value, constValue) {
+		return fmt.Errorf("%s: value does not match the constant", path)
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		if err := validateJSONSchemaObject(v, schema, root, path); err != nil {
+			return err
+		}
+	case []any:
+		if err := validateJSONSchemaArray(v, schema, root, path); err != nil {
+			return err
+		}
+	case string:
+		if minLength, ok := jsonSchemaNumber(schema["minLength"]); ok && float64(utf8.RuneCountInString(v)) < minLength {
+			return fmt.Errorf("%s: string is shorter than %v characters", path, minLength)
+		}
+		if maxLength, ok := jsonSchemaNumber(schema["maxLength"]); ok && float64(utf8.RuneCountInString(v)) > maxLength {
+			return fmt.Errorf("%s: string is longer than %v characters", path, maxLength)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err == nil && !re.MatchString(v) {
+				return fmt.Errorf("%s: string does not match the pattern %q", path, pattern)
+			}
+		}
+	default:
+		if number, ok := jsonSchemaNumber(value); ok {
+			if minimum, ok := jsonSchemaNumber(schema["minimum"]); ok && number < minimum {
+				return fmt.Errorf("%s: %v is less than the minimum %v", path, number, minimum)
+			}
+			if maximum, ok := jsonSchemaNumber(schema["maximum"]); ok && number > maximum {
+				return fmt.Errorf("%s: %v is greater than the maximum %v", path, number, maximum)
+			}
+		}
+	}
+
+	if allOf, ok := schema["allOf"].([]any); ok {
+		for _, subschema := range allOf {
+			if sub, ok := subschema.(map[string]any); ok {
+				if err := validateJSONSchema(value, sub, root, path); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok && countJSONSchemaMatches(value, anyOf, root, path) == 0 {
+		return fmt.Errorf("%s: value does not match any of the allowed schemas", path)
+	}
+	if oneOf, ok := schema["oneOf"].([]any); ok && countJSONSchemaMatches(value, oneOf, root, path) != 1 {
+		return fmt.Errorf("%s: value must match exactly one of the allowed schemas", path)
+	}
+	return nil
+}
+
+func validateJSONSchemaObject(value map[string]any, schema, root map[string]any, path string) error {
+	if required, ok := schema["required"].([]any); ok {
+		for _, name := range required {
+			if key, ok := name.(string); ok {
+				if _, present := value[key]; !present {
+					return fmt.Errorf("%s: missing required property %q", path, key)
+				}
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	for key, propertyValue := range value {
+		if propertySchema, ok := properties[key].(map[string]any); ok {
+			if err := validateJSONSchema(propertyValue, propertySchema, root, path+"."+key); err != nil {
+				return err
+			}
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				return fmt.Errorf("%s: unexpected property %q", path, key)
+			}
+		case map[string]any:
+			if err := validateJSONSchema(propertyValue, additional, root, path+"."+key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateJSONSchemaArray(value []any, schema, root map[string]any, path string) error {
+	if minItems, ok := jsonSchemaNumber(schema["minItems"]); ok && float64(len(value)) < minItems {
+		return fmt.Errorf("%s: array has fewer than %v items", path, minItems)
+	}
+	if maxItems, ok := jsonSchemaNumber(schema["maxItems"]); ok && float64(len(value)) > maxItems {
+		return fmt.Errorf("%s: array has more than %v items", path, maxItems)
+	}
+	if items, ok := schema["items"].(map[string]any); ok {
+		for i, item := range value {
+			if err := validateJSONSchema(item, items, root, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// countJSONSchemaMatches returns the number of subschemas the value is valid against.
+func countJSONSchemaMatches(value any, subschemas []any, root map[string]any, path string) int {
+	matches := 0
+	for _, subschema := range subschemas {
+		if sub, ok := subschema.(map[string]any); ok && validateJSONSchema(value, sub, root, path) == nil {
+			matches++
+		}
+	}
+	return matches
+}
+
+// resolveJSONSchemaRef resolves a local reference such as "#/$defs/Address" against the root schema.
+func resolveJSONSchemaRef(ref string, root map[string]any) (map[string]any, error) {
+	if ref == "#" {
+		return root, nil
+	}
+	pointer, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return nil, fmt.Errorf("unsupported schema reference %q", ref)
+	}
+	var current any = root
+	for _, token := range strings.Split(pointer, "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unresolvable schema reference %q", ref)
+		}
+		current = object[token]
+	}
+	resolved, ok := current.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unresolvable schema reference %q", ref)
+	}
+	return resolved, nil
+}
+
+// jsonSchemaTypes returns the types allowed by a type keyword, which is a string or an array of strings.
+func jsonSchemaTypes(schemaType any) ([]string, bool) {
+	switch t := schemaType.(type) {
+	case string:
+		return []string{t}, true
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types, len(types) > 0
+	}
+	return nil, false
+}
+
+func jsonValueHasType(value any, schemaType string) bool {
+	switch schemaType {
+	case "integer":
+		number, ok := jsonSchemaNumber(value)
+		return ok && number == math.Trunc(number)
+	case "number":
+		_, ok := jsonSchemaNumber(value)
+		return ok
+	default:
+		return jsonValueType(value) == schemaType
+	}
+}
+
+// jsonValueType returns the JSON type name of a decoded value.
+func jsonValueType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	if _, ok := jsonSchemaNumber(value); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// jsonSchemaNumber returns the value as a float64 if it is a number.
+func jsonSchemaNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case interface{ Float64() (float64, error) }:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// jsonValuesEqual compares decoded JSON values, treating numbers of different Go types as equal when their values are.
+func jsonValuesEqual(a, b any) bool {
+	if x, ok := jsonSchemaNumber(a); ok {
+		y, ok := jsonSchemaNumber(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,84 @@
+package schemas
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestValidateJSONSchema(t *testing.T) {
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "minLength": 1},
+			"age": {"type": "integer", "minimum": 0},
+			"status": {"enum": ["active", "inactive"]},
+			"address": {"$ref": "#/$defs/address"},
+			"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2}
+		},
+		"required": ["name", "age"],
+		"additionalProperties": false,
+		"$defs": {
+			"address": {
+				"type": "object",
+				"properties": {"city": {"type": "string"}},
+				"required": ["city"]
+			}
+		}
+	}`), &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		valid bool
+	}{
+		{"Valid", `{"name": "Jane", "age": 30, "status": "active", "address": {"city": "Paris"}, "tags": ["a"]}`, true},
+		{"MissingRequired", `{"name": "Jane"}`, false},
+		{"WrongType", `{"name": "Jane", "age": "30"}`, false},
+		{"NotInteger", `{"name": "Jane", "age": 30.5}`, false},
+		{"BelowMinimum", `{"name": "Jane", "age": -1}`, false},
+		{"NotInEnum", `{"name": "Jane", "age": 30, "status": "deleted"}`, false},
+		{"InvalidRef", `{"name": "Jane", "age": 30, "address": {}}`, false},
+		{"TooManyItems", `{"name": "Jane", "age": 30, "tags": ["a", "b", "c"]}`, false},
+		{"AdditionalProperty", `{"name": "Jane", "age": 30, "email": "jane@example.com"}`, false},
+		{"NotAnObject", `["Jane"]`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value any
+			if err := json.Unmarshal([]byte(tt.value), &value); err != nil {
+				t.Fatal(err)
+			}
+			err := ValidateJSONSchema(value, schema)
+			if tt.valid && err != nil {
+				t.Errorf("expected valid, got %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("expected a validation error")
+			}
+		})
+	}
+}
+
+func TestParseResponseFormat(t *testing.T) {
+	var responseFormat interface{} = map[string]interface{}{
+		"type": "json_schema",
+		"json_schema": map[string]interface{}{
+			"name":   "person",
+			"strict": true,
+			"schema": map[string]interface{}{"type": "object"},
+		},
+	}
+	format := ParseResponseFormat(&responseFormat)
+	if format == nil || format.Type != ResponseFormatTypeJSONSchema || format.JSONSchema == nil {
+		t.Fatalf("unexpected response format %+v", format)
+	}
+	if format.JSONSchema.Name != "person" || format.JSONSchema.Schema["type"] != "object" {
+		t.Errorf("unexpected json schema %+v", format.JSONSchema)
+	}
+	if ParseResponseFormat(nil) != nil {
+		t.Error("expected nil for a missing response format")
+	}
+}
@@ -0,0 +1,49 @@
+package schemas
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultListModelsCacheRefreshInterval is the age of a cached model listing before it is refreshed when the config does not set it
+const DefaultListModelsCacheRefreshInterval = 5 * time.Minute
+
+// DefaultListModelsCacheMaxStale is how long past the refresh interval a cached model listing is served when the config does not set it
+const DefaultListModelsCacheMaxStale = time.Hour
+
+// ListModelsCacheConfig configures the cache of the models listed by the providers. A listing is served from the cache
+// until it is older than the refresh interval; it is then still served, stale, while it is refreshed in the background,
+// until it is older than the refresh interval and the max stale duration together and is fetched again before it is
+// served. Listings are cached per provider and set of keys, and paginated by Bifrost. Requests with extra params or
+// a direct key are not cached. A nil config lists the models of the providers on every request.
+type ListModelsCacheConfig struct {
+	RefreshIntervalSeconds int `json:"refresh_interval_seconds,omitempty"` // Age of a listing before it is refreshed. 0 uses DefaultListModelsCacheRefreshInterval
+	MaxStaleSeconds        int `json:"max_stale_seconds,omitempty"`        // How long past the refresh interval a listing is served while refreshed. 0 uses DefaultListModelsCacheMaxStale
+}
+
+// RefreshInterval returns the age of a cached listing before it is refreshed
+func (c *ListModelsCacheConfig) RefreshInterval() time.Duration {
+	if c == nil || c.RefreshIntervalSeconds <= 0 {
+		return DefaultListModelsCacheRefreshInterval
+	}
+	return time.Duration(c.RefreshIntervalSeconds) * time.Second
+}
+
+// MaxStale returns how long past the refresh interval a cached listing is served while it is refreshed
+func (c *ListModelsCacheConfig) MaxStale() time.Duration {
+	if c == nil || c.MaxStaleSeconds <= 0 {
+		return DefaultListModelsCacheMaxStale
+	}
+	return time.Duration(c.MaxStaleSeconds) * time.Second
+}
+
+// Validate checks the refresh interval and the max stale duration of the config
+func (c *ListModelsCacheConfig) Validate() error {
+	if c.RefreshIntervalSeconds < 0 {
+		return fmt.Errorf("list models cache refresh interval cannot be negative, got %d", c.RefreshIntervalSeconds)
+	}
+	if c.MaxStaleSeconds < 0 {
+		return fmt.Errorf("list models cache max stale cannot be negative, got %d", c.MaxStaleSeconds)
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 // Package schemas defines the core schemas and types used by the Bifrost system.
 package schemas
 
+import "fmt"
+
 // LogLevel represents the severity level of a log message.
 // Internally it maps to zerolog.Level for interoperability.
 type LogLevel string
@@ -13,6 +15,15 @@ const (
 	LogLevelError LogLevel = "error"
 )
 
+// IsValid reports whether the level is one of the log levels
+func (l LogLevel) IsValid() bool {
+	switch l {
+	case LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError:
+		return true
+	}
+	return false
+}
+
 // LoggerOutputType represents the output type of a logger.
 type LoggerOutputType string
 
@@ -53,3 +64,138 @@ type Logger interface {
 	// SetOutputType sets the output type for the logger.
 	SetOutputType(outputType LoggerOutputType)
 }
+
+// LogSinkType is the type of a log sink
+type LogSinkType string
+
+// Built-in log sink types, other types can be registered by plugins with bifrost.RegisterLogSink
+const (
+	LogSinkTypeStdout LogSinkType = "stdout"
+	LogSinkTypeStderr LogSinkType = "stderr"
+	LogSinkTypeFile   LogSinkType = "file"
+	LogSinkTypeSyslog LogSinkType = "syslog"
+	LogSinkTypeOTLP   LogSinkType = "otlp"
+)
+
+// LoggingConfig configures the structured logging: the destinations of the log entries and the levels of the
+// modules of the gateway. Without sinks, entries are written to stdout, and errors to stderr.
+type LoggingConfig struct {
+	Level        LogLevel            `json:"level,omitempty"`         // Level of the modules without a level of their own
+	ModuleLevels map[string]LogLevel `json:"module_levels,omitempty"` // Levels by module, e.g. {"core": "debug", "governance": "warn"}
+	Sinks        []LogSinkConfig     `json:"sinks,omitempty"`
+}
+
+// LogSinkConfig configures a destination of the log entries. The options of the built-in sinks are in the field of
+// their type, custom sinks read their Options.
+type LogSinkConfig struct {
+	Type    LogSinkType          `json:"type"`
+	Level   LogLevel             `json:"level,omitempty"` // Only entries of this level or above are written to the sink
+	File    *FileLogSinkConfig   `json:"file,omitempty"`
+	Syslog  *SyslogLogSinkConfig `json:"syslog,omitempty"`
+	OTLP    *OTLPLogSinkConfig   `json:"otlp,omitempty"`
+	Options map[string]any       `json:"options,omitempty"`
+}
+
+// FileLogSinkConfig configures a log file, rotated once it reaches its max size
+type FileLogSinkConfig struct {
+	Path       string `json:"path"`
+	MaxSizeMB  int    `json:"max_size_mb,omitempty"` // Size at which the file is rotated, defaults to DefaultLogFileMaxSizeMB
+	MaxBackups int    `json:"max_backups,omitempty"` // Rotated files kept as <path>.1 to <path>.<max_backups>, defaults to DefaultLogFileMaxBackups
+}
+
+// Defaults of the file log sink
+const (
+	DefaultLogFileMaxSizeMB  = 100
+	DefaultLogFileMaxBackups = 5
+)
+
+// SyslogLogSinkConfig configures a syslog destination, the local syslog daemon when no address is set
+type SyslogLogSinkConfig struct {
+	Network string `json:"network,omitempty"` // "udp", "tcp" or "unix", empty for the local syslog daemon
+	Address string `json:"address,omitempty"` // e.g. "syslog.internal:514"
+	Tag     string `json:"tag,omitempty"`     // Defaults to "bifrost"
+}
+
+// OTLPLogSinkConfig configures the export of the log entries to an OpenTelemetry collector, with OTLP/HTTP in JSON
+type OTLPLogSinkConfig struct {
+	Endpoint    string            `json:"endpoint"` // e.g. "http://otel-collector:4318/v1/logs"
+	Headers     map[string]string `json:"headers,omitempty"`
+	ServiceName string            `json:"service_name,omitempty"` // service.name of the resource, defaults to "bifrost"
+}
+
+// Validate checks the levels of the config and the options of its built-in sinks
+func (c *LoggingConfig) Validate() error {
+	if c.Level != "" && !c.Level.IsValid() {
+		return fmt.Errorf("invalid log level %q", c.Level)
+	}
+	for module, level := range c.ModuleLevels {
+		if !level.IsValid() {
+			return fmt.Errorf("invalid log level %q for module %s", level, module)
+		}
+	}
+	for i, sink := range c.Sinks {
+		if sink.Type == "" {
+			return fmt.Errorf("log sink %d must have a type", i)
+		}
+		if sink.Level != "" && !sink.Level.IsValid() {
+			return fmt.Errorf("invalid log level %q for log sink %d", sink.Level, i)
+		}
+		switch sink.Type {
+		case LogSinkTypeFile:
+			if sink.File == nil || sink.File.Path == "" {
+				return fmt.Errorf("file log sink %d must have a path", i)
+			}
+			if sink.File.MaxSizeMB < 0 || sink.File.MaxBackups < 0 {
+				return fmt.Errorf("file log sink %d cannot have a negative max size or backups", i)
+			}
+		case LogSinkTypeOTLP:
+			if sink.OTLP == nil || sink.OTLP.Endpoint == "" {
+				return fmt.Errorf("otlp log sink %d must have an endpoint", i)
+			}
+		}
+	}
+	return nil
+}
+
+// LogSink is a destination of the structured log entries. Entries are JSON objects with the time, level, module and
+// message of the entry. Sinks of other types than the built-in ones are registered with bifrost.RegisterLogSink.
+type LogSink interface {
+	// WriteEntry writes an entry, fatal entries have the error level. The entry is reused once the call returns, sinks
+	// keeping it must copy it.
+	WriteEntry(level LogLevel, entry []byte) error
+
+	// Close flushes the buffered entries and releases the sink
+	Close() error
+}
+
+// StructuredLogger is a Logger writing structured entries to sinks, with levels per module that can be changed at
+// runtime. The DefaultLogger of bifrost implements it.
+type StructuredLogger interface {
+	Logger
+
+	// Configure replaces the sinks and levels of the logger
+	Configure(config *LoggingConfig) error
+
+	// WithModule returns a logger tagging its entries with the module, and filtering them with the level of the module
+	WithModule(module string) Logger
+
+	// GetLevels returns the level of the modules without a level of their own, and the levels by module
+	GetLevels() (LogLevel, map[string]LogLevel)
+
+	// SetLevels sets the level of the modules without a level of their own, and replaces the levels by module
+	SetLevels(level LogLevel, moduleLevels map[string]LogLevel)
+
+	// Modules returns the modules that have a logger
+	Modules() []string
+
+	// Close flushes and closes the sinks
+	Close() error
+}
+
+// ModuleLogger returns the logger of a module, or the logger itself if it does not support modules
+func ModuleLogger(logger Logger, module string) Logger {
+	if structured, ok := logger.(StructuredLogger); ok {
+		return structured.WithModule(module)
+	}
+	return logger
+}
@@ -126,6 +126,10 @@ type Model struct {
 
 	OwnedBy          *string  `json:"owned_by,omitempty"`
 	SupportedMethods []string `json:"supported_methods,omitempty"`
+
+	// Model catalog fields, set on the models listed across all providers
+	Capabilities *ModelCapabilities `json:"capabilities,omitempty"`
+	Providers    []ModelProvider    `json:"providers,omitempty"` // Providers serving the model
 }
 
 type Architecture struct {
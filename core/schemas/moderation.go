@@ -0,0 +1,43 @@
+package schemas
+
+// ModerationAction is what the content filter, guardrail or model did to a response it flagged
+type ModerationAction string
+
+const (
+	ModerationActionFiltered ModerationAction = "filtered" // The content filter of the provider cut the output
+	ModerationActionRefused  ModerationAction = "refused"  // The model refused to answer
+	ModerationActionBlocked  ModerationAction = "blocked"  // A guardrail replaced the response
+	ModerationActionMasked   ModerationAction = "masked"   // A guardrail masked parts of the input or output
+)
+
+// BifrostModeration is the outcome of the content moderation of a response, in the same shape whatever the provider:
+// Azure content filter results, OpenAI refusals, Anthropic refusal stop reasons, Gemini safety finish reasons and
+// Bedrock guardrail traces.
+type BifrostModeration struct {
+	Flagged    bool                        `json:"flagged"`              // true when a category was flagged or an action was taken
+	Action     ModerationAction            `json:"action,omitempty"`     // Strongest action taken on the response, unset when the response went through
+	Reason     string                      `json:"reason,omitempty"`     // Refusal message or reason given by the provider
+	Categories []BifrostModerationCategory `json:"categories,omitempty"` // Categories evaluated by the provider, flagged or not
+}
+
+// BifrostModerationCategory is a category of content evaluated on the input or the output of a request
+type BifrostModerationCategory struct {
+	Source   string `json:"source"`             // "input" or "output"
+	Category string `json:"category"`           // e.g. "hate", "violence", "jailbreak", "sensitive_information"
+	Flagged  bool   `json:"flagged"`            // Content of the category was detected or filtered
+	Severity string `json:"severity,omitempty"` // Severity or confidence reported by the provider, e.g. "safe", "medium", "HIGH"
+	Action   string `json:"action,omitempty"`   // Action taken by the provider on the category, e.g. "filtered", "BLOCKED"
+}
+
+// ContentFilterResult is the result of a category of the Azure content filter
+type ContentFilterResult struct {
+	Filtered bool   `json:"filtered"`
+	Severity string `json:"severity,omitempty"` // "safe", "low", "medium" or "high"
+	Detected *bool  `json:"detected,omitempty"` // Set for the detection categories, e.g. jailbreak and protected material
+}
+
+// PromptFilterResult is the result of the Azure content filter on a prompt of the request
+type PromptFilterResult struct {
+	PromptIndex          int                            `json:"prompt_index"`
+	ContentFilterResults map[string]ContentFilterResult `json:"content_filter_results,omitempty"`
+}
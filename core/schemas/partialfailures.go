@@ -0,0 +1,45 @@
+package schemas
+
+import "fmt"
+
+// PartialFailureMode decides what a request of many inputs returns when only some of them fail
+type PartialFailureMode string
+
+const (
+	PartialFailureModePartial PartialFailureMode = "partial" // Return the results of the inputs that succeeded with the status of every input (default)
+	PartialFailureModeStrict  PartialFailureMode = "strict"  // Fail the whole request as soon as one input fails
+)
+
+// PartialFailureConfig configures the requests of many inputs sent in several provider requests, such as embedding
+// requests split into batches. The x-bf-partial-failures header overrides the mode for a single request.
+// A nil config returns partial results.
+type PartialFailureConfig struct {
+	Mode PartialFailureMode `json:"mode"`
+}
+
+// Strict reports whether the mode fails the whole request when one input fails
+func (m PartialFailureMode) Strict() bool {
+	return m == PartialFailureModeStrict
+}
+
+// Validate checks the mode of the config
+func (c *PartialFailureConfig) Validate() error {
+	switch c.Mode {
+	case "", PartialFailureModePartial, PartialFailureModeStrict:
+		return nil
+	}
+	return fmt.Errorf("unknown partial failure mode %q, expected partial or strict", c.Mode)
+}
+
+// EmbeddingItemStatus is the outcome of an input of an embedding request that partially failed
+type EmbeddingItemStatus struct {
+	Index     int       `json:"index"`  // Index of the input in the request
+	Status    string    `json:"status"` // "succeeded" or "failed"
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
+	Message   string    `json:"message,omitempty"` // Error message of the provider for failed inputs
+}
+
+const (
+	ItemStatusSucceeded = "succeeded"
+	ItemStatusFailed    = "failed"
+)
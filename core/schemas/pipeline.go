@@ -0,0 +1,107 @@
+package schemas
+
+import (
+	"fmt"
+	"slices"
+)
+
+// PipelineStageType is the kind of transformation a stage of a pipeline performs.
+// The stages of a pipeline must follow the order of their types: guardrails, template, cache, route, post_process.
+type PipelineStageType string
+
+const (
+	PipelineStageGuardrails  PipelineStageType = "guardrails"   // Validates or blocks the request, fails closed if its plugin is not loaded
+	PipelineStageTemplate    PipelineStageType = "template"     // Rewrites the request, e.g. prompt templates
+	PipelineStageCache       PipelineStageType = "cache"        // Answers the request from a cache
+	PipelineStageRoute       PipelineStageType = "route"        // Chooses the provider and model of the request
+	PipelineStagePostProcess PipelineStageType = "post_process" // Transforms the response
+)
+
+// PipelineStageTypes lists the stage types in the order they must appear in a pipeline
+var PipelineStageTypes = []PipelineStageType{
+	PipelineStageGuardrails,
+	PipelineStageTemplate,
+	PipelineStageCache,
+	PipelineStageRoute,
+	PipelineStagePostProcess,
+}
+
+// PipelineStage is a stage of a pipeline, run by a loaded plugin
+type PipelineStage struct {
+	Type   PipelineStageType `json:"type"`
+	Plugin string            `json:"plugin"` // Name of the plugin running the stage
+}
+
+// Pipeline is a named transformation pipeline attached to requested models or virtual keys.
+// Plugins referenced by any pipeline are managed by pipelines: for a request with an attached pipeline,
+// only the managed plugins of its stages run, in stage order, where the first managed plugin would have run.
+// Plugins not referenced by any pipeline, e.g. governance and logging, always run.
+// Requests without an attached pipeline run all plugins in registration order.
+type Pipeline struct {
+	Name          string          `json:"name"`
+	Description   string          `json:"description,omitempty"`
+	Stages        []PipelineStage `json:"stages"`
+	Models        []string        `json:"models,omitempty"`          // Requested models the pipeline applies to, as "model" or "provider/model"
+	VirtualKeyIDs []string        `json:"virtual_key_ids,omitempty"` // Virtual keys the pipeline applies to, taking precedence over models
+}
+
+// Validate checks the pipeline for missing fields, unknown stage types and misordered stages
+func (p *Pipeline) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("pipeline name is required")
+	}
+	if len(p.Stages) == 0 {
+		return fmt.Errorf("pipeline %s requires at least one stage", p.Name)
+	}
+	previous := -1
+	plugins := make(map[string]bool, len(p.Stages))
+	for i, stage := range p.Stages {
+		position := slices.Index(PipelineStageTypes, stage.Type)
+		if position < 0 {
+			return fmt.Errorf("pipeline %s stage %d has unknown type %q", p.Name, i, stage.Type)
+		}
+		if position < previous {
+			return fmt.Errorf("pipeline %s stage %d: %s stages must come before %s stages", p.Name, i, stage.Type, PipelineStageTypes[previous])
+		}
+		previous = position
+		if stage.Plugin == "" {
+			return fmt.Errorf("pipeline %s stage %d requires a plugin", p.Name, i)
+		}
+		if plugins[stage.Plugin] {
+			return fmt.Errorf("pipeline %s runs plugin %s more than once", p.Name, stage.Plugin)
+		}
+		plugins[stage.Plugin] = true
+	}
+	return nil
+}
+
+// ValidatePipelines validates each pipeline and checks that names are unique and that
+// every model and virtual key is attached to at most one pipeline
+func ValidatePipelines(pipelines []Pipeline) error {
+	names := make(map[string]bool, len(pipelines))
+	models := make(map[string]string)
+	virtualKeys := make(map[string]string)
+	for i := range pipelines {
+		pipeline := &pipelines[i]
+		if err := pipeline.Validate(); err != nil {
+			return err
+		}
+		if names[pipeline.Name] {
+			return fmt.Errorf("duplicate pipeline name %s", pipeline.Name)
+		}
+		names[pipeline.Name] = true
+		for _, model := range pipeline.Models {
+			if other, ok := models[model]; ok {
+				return fmt.Errorf("model %s is attached to pipelines %s and %s", model, other, pipeline.Name)
+			}
+			models[model] = pipeline.Name
+		}
+		for _, vkID := range pipeline.VirtualKeyIDs {
+			if other, ok := virtualKeys[vkID]; ok {
+				return fmt.Errorf("virtual key %s is attached to pipelines %s and %s", vkID, other, pipeline.Name)
+			}
+			virtualKeys[vkID] = pipeline.Name
+		}
+	}
+	return nil
+}
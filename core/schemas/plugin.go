@@ -1,6 +1,8 @@
 // Package schemas defines the core schemas and types used by the Bifrost system.
 package schemas
 
+import "time"
+
 // PluginShortCircuit represents a plugin's decision to short-circuit the normal flow.
 // It can contain either a response (success short-circuit), a stream (streaming short-circuit), or an error (error short-circuit).
 type PluginShortCircuit struct {
@@ -86,6 +88,32 @@ type Plugin interface {
 	Cleanup() error
 }
 
+// StreamChunkPlugin is implemented by plugins transforming the chunks of streams on their way to the client, e.g. to
+// redact streamed text, sanitize markdown or insert watermarks, without forking the streaming handlers.
+// Chunk hooks run on the chunks returned by the PostHooks, in the order of the PostHooks, for every stream request.
+type StreamChunkPlugin interface {
+	Plugin
+
+	// StreamChunkHook is called for every chunk of a stream, including error chunks, with the state of the stream kept
+	// for the plugin. It returns the chunks sent in place of the chunk: the chunk itself, possibly modified, none to
+	// suppress or hold it back, or several to release chunks held back earlier. Once the stream ends, it is called a
+	// last time with a nil chunk and state.Done set, so that the chunks still held back are released.
+	// On error, the error is logged and the chunk is sent as it is.
+	StreamChunkHook(ctx *BifrostContext, chunk *BifrostStream, state *StreamChunkState) ([]*BifrostStream, error)
+}
+
+// StreamChunkState is the state of a stream kept for a plugin across its chunk hooks
+type StreamChunkState struct {
+	RequestType RequestType
+	Provider    ModelProvider
+	Model       string
+	ChunkIndex  int              // Index of the chunk among the chunks the plugin received, starting at 0
+	Text        string           // Text streamed to the plugin so far, including the text of the current chunk
+	Done        bool             // Set on the last call, with a nil chunk, once the stream ended
+	Held        []*BifrostStream // Chunks held back by the plugin, for the plugin to use
+	Values      map[string]any   // Values of the plugin carried across the chunks of the stream
+}
+
 // PluginConfig is the configuration for a plugin.
 // It contains the name of the plugin, whether it is enabled, and the configuration for the plugin.
 type PluginConfig struct {
@@ -94,4 +122,38 @@ type PluginConfig struct {
 	Path    *string `json:"path,omitempty"`
 	Version *int16  `json:"version,omitempty"`
 	Config  any     `json:"config,omitempty"`
+
+	Execution *PluginExecutionConfig `json:"execution,omitempty"` // Optional: Bounds the execution of the plugin's hooks
+}
+
+// PluginFailureMode controls what happens to a request when a plugin hook cannot be executed
+// because it timed out, all its concurrency slots are busy, or its circuit breaker is open.
+type PluginFailureMode string
+
+const (
+	PluginFailureModeOpen   PluginFailureMode = "fail_open"   // Skip the hook and continue processing the request (default)
+	PluginFailureModeClosed PluginFailureMode = "fail_closed" // Fail the request
+)
+
+// PluginExecutionConfig bounds the execution of a plugin's PreHook and PostHook, so a slow
+// or unavailable dependency of the plugin (e.g. an external guardrail service) degrades
+// gracefully instead of stalling every request.
+// Plugins without an execution config run inline without any limits.
+// A hook that times out is abandoned and the pipeline moves on with the values it was called with,
+// so hooks of plugins with a timeout must stop touching the request and response once their context is done.
+type PluginExecutionConfig struct {
+	MaxConcurrency   int               `json:"max_concurrency,omitempty"`   // Maximum number of hooks of the plugin running at once, 0 means unbounded
+	TimeoutMs        int               `json:"timeout_ms,omitempty"`        // Maximum time a hook may take, including the wait for a free slot, 0 means no timeout
+	FailureThreshold int               `json:"failure_threshold,omitempty"` // Consecutive failures (timeouts and hook errors) after which the circuit opens, 0 disables the circuit breaker
+	CooldownMs       int               `json:"cooldown_ms,omitempty"`       // Time the circuit stays open before a trial hook is let through, defaults to 30s
+	FailureMode      PluginFailureMode `json:"failure_mode,omitempty"`      // What to do when a hook cannot be executed, defaults to fail_open
+}
+
+// PluginCircuitStore shares the circuit breakers of the plugins between the replicas of a gateway, so that a circuit
+// opened by a replica opens on all of them. Its methods are called on the request path and must not block.
+type PluginCircuitStore interface {
+	// OpenUntil returns the time until which the circuit of a plugin was opened by any replica, zero when it never was
+	OpenUntil(pluginName string) time.Time
+	// Open shares that the circuit of a plugin is open until the given time
+	Open(pluginName string, until time.Time)
 }
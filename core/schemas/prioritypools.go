@@ -0,0 +1,84 @@
+package schemas
+
+import (
+	"fmt"
+	"math"
+)
+
+// PriorityPool is a share of the workers and queue of a provider reserved to the requests of a priority
+// (BifrostContextKeyPriority). Requests only wait for the workers of their pool, so that the requests of a pool,
+// e.g. batch jobs, cannot starve the others. Requests without a priority, or with a priority without a pool,
+// use the first pool.
+type PriorityPool struct {
+	Name  string  `json:"name"`  // Priority of the requests of the pool, e.g. "interactive" or "batch"
+	Share float64 `json:"share"` // Share of the concurrency and buffer size of the provider, relative to the shares of all pools
+}
+
+// PriorityPoolSize is the number of workers and the queue size of a priority pool
+type PriorityPoolSize struct {
+	Name        string
+	Concurrency int
+	BufferSize  int
+}
+
+// PoolSizes splits the concurrency and buffer size between the priority pools, in the order of the pools.
+// Each pool gets at least one worker and one buffered request. Without priority pools, a single unnamed
+// pool gets the whole concurrency and buffer size.
+func (c ConcurrencyAndBufferSize) PoolSizes() []PriorityPoolSize {
+	if len(c.PriorityPools) == 0 {
+		return []PriorityPoolSize{{Concurrency: c.Concurrency, BufferSize: c.BufferSize}}
+	}
+	total := 0.0
+	for _, pool := range c.PriorityPools {
+		total += pool.Share
+	}
+	sizes := make([]PriorityPoolSize, 0, len(c.PriorityPools))
+	for _, pool := range c.PriorityPools {
+		share := 1 / float64(len(c.PriorityPools))
+		if total > 0 {
+			share = pool.Share / total
+		}
+		sizes = append(sizes, PriorityPoolSize{
+			Name:        pool.Name,
+			Concurrency: max(1, int(math.Round(share*float64(c.Concurrency)))),
+			BufferSize:  max(1, int(math.Round(share*float64(c.BufferSize)))),
+		})
+	}
+	return sizes
+}
+
+// ValidatePriorityPools checks that the priority pools are named, unique and have a positive share
+func (c ConcurrencyAndBufferSize) ValidatePriorityPools() error {
+	seen := make(map[string]bool, len(c.PriorityPools))
+	for i, pool := range c.PriorityPools {
+		if pool.Name == "" {
+			return fmt.Errorf("priority pool %d must have a name", i)
+		}
+		if seen[pool.Name] {
+			return fmt.Errorf("duplicate priority pool %s", pool.Name)
+		}
+		seen[pool.Name] = true
+		if pool.Share <= 0 {
+			return fmt.Errorf("share of priority pool %s must be greater than 0, got %v", pool.Name, pool.Share)
+		}
+	}
+	if len(c.PriorityPools) > c.Concurrency {
+		return fmt.Errorf("concurrency %d is too low for %d priority pools", c.Concurrency, len(c.PriorityPools))
+	}
+	return nil
+}
+
+// PriorityPoolRuntimeStats is the load of the workers and queue of a priority pool
+type PriorityPoolRuntimeStats struct {
+	Name          string `json:"name"`
+	Workers       int    `json:"workers"`
+	BusyWorkers   int64  `json:"busy_workers"`   // Workers handling a request, each holding a connection to the provider
+	QueueDepth    int    `json:"queue_depth"`    // Requests waiting for a worker
+	QueueCapacity int    `json:"queue_capacity"` // Requests the queue can buffer before requests are dropped or wait
+}
+
+// ProviderRuntimeStats is the load of the priority pools of a provider
+type ProviderRuntimeStats struct {
+	Provider ModelProvider              `json:"provider"`
+	Pools    []PriorityPoolRuntimeStats `json:"pools"`
+}
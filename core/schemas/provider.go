@@ -4,6 +4,7 @@ package schemas
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"maps"
 	"time"
 )
@@ -21,6 +22,7 @@ const (
 // Pre-defined errors for provider operations
 const (
 	ErrProviderRequestTimedOut      = "request timed out (default is 30 seconds). You can increase it by setting the default_request_timeout_in_seconds in the network_config or in UI - Providers > Provider Name > Network Config."
+	ErrProviderFirstChunkTimedOut   = "provider did not stream a first chunk within first_chunk_timeout_in_seconds of the network_config"
 	ErrRequestCancelled             = "request cancelled by caller"
 	ErrRequestBodyConversion        = "failed to convert bifrost request to the expected provider request body"
 	ErrProviderRequestMarshal       = "failed to marshal request body to JSON"
@@ -44,12 +46,16 @@ const (
 //   - When marshaling to JSON: a time.Duration is converted to milliseconds
 type NetworkConfig struct {
 	// BaseURL is supported for OpenAI, Anthropic, Cohere, Mistral, and Ollama providers (required for Ollama)
-	BaseURL                        string            `json:"base_url,omitempty"`                 // Base URL for the provider (optional)
-	ExtraHeaders                   map[string]string `json:"extra_headers,omitempty"`            // Additional headers to include in requests (optional)
-	DefaultRequestTimeoutInSeconds int               `json:"default_request_timeout_in_seconds"` // Default timeout for requests
-	MaxRetries                     int               `json:"max_retries"`                        // Maximum number of retries
-	RetryBackoffInitial            time.Duration     `json:"retry_backoff_initial"`              // Initial backoff duration (stored as nanoseconds, JSON as milliseconds)
-	RetryBackoffMax                time.Duration     `json:"retry_backoff_max"`                  // Maximum backoff duration (stored as nanoseconds, JSON as milliseconds)
+	BaseURL                        string            `json:"base_url,omitempty"`                       // Base URL for the provider (optional)
+	ExtraHeaders                   map[string]string `json:"extra_headers,omitempty"`                  // Additional headers to include in requests (optional)
+	DefaultRequestTimeoutInSeconds int               `json:"default_request_timeout_in_seconds"`       // Default timeout for requests
+	FirstChunkTimeoutInSeconds     int               `json:"first_chunk_timeout_in_seconds,omitempty"` // Timeout for the first chunk of streams, the stream is aborted and falls back when exceeded. 0 disables it (optional)
+	MaxRetries                     int               `json:"max_retries"`                              // Maximum number of retries
+	RetryBackoffInitial            time.Duration     `json:"retry_backoff_initial"`                    // Initial backoff duration (stored as nanoseconds, JSON as milliseconds)
+	RetryBackoffMax                time.Duration     `json:"retry_backoff_max"`                        // Maximum backoff duration (stored as nanoseconds, JSON as milliseconds)
+	RetryPolicy                    *RetryPolicy      `json:"retry_policy,omitempty"`                   // Retry rules per error class, replaces the default retries of MaxRetries (optional)
+	FallbackPolicy                 *FallbackPolicy   `json:"fallback_policy,omitempty"`                // Errors of the provider tried on the fallbacks per error class, all errors are by default (optional)
+	HTTPClient                     *HTTPClientConfig `json:"http_client,omitempty"`                    // Connection pool, buffer sizes and client type of the HTTP client (optional)
 }
 
 // UnmarshalJSON customizes JSON unmarshaling for NetworkConfig.
@@ -61,9 +67,13 @@ func (nc *NetworkConfig) UnmarshalJSON(data []byte) error {
 		BaseURL                        string            `json:"base_url,omitempty"`
 		ExtraHeaders                   map[string]string `json:"extra_headers,omitempty"`
 		DefaultRequestTimeoutInSeconds int               `json:"default_request_timeout_in_seconds"`
+		FirstChunkTimeoutInSeconds     int               `json:"first_chunk_timeout_in_seconds,omitempty"`
 		MaxRetries                     int               `json:"max_retries"`
 		RetryBackoffInitial            int64             `json:"retry_backoff_initial"` // milliseconds in JSON
 		RetryBackoffMax                int64             `json:"retry_backoff_max"`     // milliseconds in JSON
+		RetryPolicy                    *RetryPolicy      `json:"retry_policy,omitempty"`
+		FallbackPolicy                 *FallbackPolicy   `json:"fallback_policy,omitempty"`
+		HTTPClient                     *HTTPClientConfig `json:"http_client,omitempty"`
 	}
 
 	var alias NetworkConfigAlias
@@ -75,7 +85,11 @@ func (nc *NetworkConfig) UnmarshalJSON(data []byte) error {
 	nc.BaseURL = alias.BaseURL
 	nc.ExtraHeaders = alias.ExtraHeaders
 	nc.DefaultRequestTimeoutInSeconds = alias.DefaultRequestTimeoutInSeconds
+	nc.FirstChunkTimeoutInSeconds = alias.FirstChunkTimeoutInSeconds
 	nc.MaxRetries = alias.MaxRetries
+	nc.RetryPolicy = alias.RetryPolicy
+	nc.FallbackPolicy = alias.FallbackPolicy
+	nc.HTTPClient = alias.HTTPClient
 
 	// Convert milliseconds to time.Duration (nanoseconds)
 	// Only convert if value is greater than 0
@@ -98,16 +112,24 @@ func (nc NetworkConfig) MarshalJSON() ([]byte, error) {
 		BaseURL                        string            `json:"base_url,omitempty"`
 		ExtraHeaders                   map[string]string `json:"extra_headers,omitempty"`
 		DefaultRequestTimeoutInSeconds int               `json:"default_request_timeout_in_seconds"`
+		FirstChunkTimeoutInSeconds     int               `json:"first_chunk_timeout_in_seconds,omitempty"`
 		MaxRetries                     int               `json:"max_retries"`
 		RetryBackoffInitial            int64             `json:"retry_backoff_initial"` // milliseconds in JSON
 		RetryBackoffMax                int64             `json:"retry_backoff_max"`     // milliseconds in JSON
+		RetryPolicy                    *RetryPolicy      `json:"retry_policy,omitempty"`
+		FallbackPolicy                 *FallbackPolicy   `json:"fallback_policy,omitempty"`
+		HTTPClient                     *HTTPClientConfig `json:"http_client,omitempty"`
 	}
 
 	alias := NetworkConfigAlias{
 		BaseURL:                        nc.BaseURL,
 		ExtraHeaders:                   nc.ExtraHeaders,
 		DefaultRequestTimeoutInSeconds: nc.DefaultRequestTimeoutInSeconds,
+		FirstChunkTimeoutInSeconds:     nc.FirstChunkTimeoutInSeconds,
 		MaxRetries:                     nc.MaxRetries,
+		RetryPolicy:                    nc.RetryPolicy,
+		FallbackPolicy:                 nc.FallbackPolicy,
+		HTTPClient:                     nc.HTTPClient,
 		// Convert time.Duration (nanoseconds) to milliseconds
 		RetryBackoffInitial: int64(nc.RetryBackoffInitial / time.Millisecond),
 		RetryBackoffMax:     int64(nc.RetryBackoffMax / time.Millisecond),
@@ -126,8 +148,9 @@ var DefaultNetworkConfig = NetworkConfig{
 
 // ConcurrencyAndBufferSize represents configuration for concurrent operations and buffer sizes.
 type ConcurrencyAndBufferSize struct {
-	Concurrency int `json:"concurrency"` // Number of concurrent operations. Also used as the initial pool size for the provider reponses.
-	BufferSize  int `json:"buffer_size"` // Size of the buffer
+	Concurrency   int            `json:"concurrency"`              // Number of concurrent operations. Also used as the initial pool size for the provider reponses.
+	BufferSize    int            `json:"buffer_size"`              // Size of the buffer
+	PriorityPools []PriorityPool `json:"priority_pools,omitempty"` // Optional: Partition of the concurrency and buffer between request priorities
 }
 
 // DefaultConcurrencyAndBufferSize is the default concurrency and buffer size for provider operations.
@@ -228,6 +251,37 @@ func (cpc *CustomProviderConfig) IsOperationAllowed(operation RequestType) bool
 	return cpc.AllowedRequests.IsOperationAllowed(operation)
 }
 
+// MockConfig configures the synthetic responses of the mock provider, used to load test Bifrost without provider calls.
+type MockConfig struct {
+	Response        string   `json:"response,omitempty"`          // Text of the responses, a fixed text by default
+	EchoInput       bool     `json:"echo_input,omitempty"`        // Respond with the text of the last input message instead of Response
+	Models          []string `json:"models,omitempty"`            // Models listed by the provider, any model is accepted
+	LatencyMs       int      `json:"latency_ms,omitempty"`        // Delay before the first token
+	TokensPerSecond float64  `json:"tokens_per_second,omitempty"` // Rate at which the tokens of the responses are generated, instant when 0
+	ErrorRate       float64  `json:"error_rate,omitempty"`        // Ratio of the requests failing, between 0 and 1
+	ErrorStatusCode int      `json:"error_status_code,omitempty"` // Status code of the failed requests, 500 by default
+}
+
+// Validate checks that the rates of the mock config are in range
+func (mc *MockConfig) Validate() error {
+	if mc == nil {
+		return nil
+	}
+	if mc.LatencyMs < 0 {
+		return fmt.Errorf("latency_ms must not be negative")
+	}
+	if mc.TokensPerSecond < 0 {
+		return fmt.Errorf("tokens_per_second must not be negative")
+	}
+	if mc.ErrorRate < 0 || mc.ErrorRate > 1 {
+		return fmt.Errorf("error_rate must be between 0 and 1")
+	}
+	if mc.ErrorStatusCode != 0 && (mc.ErrorStatusCode < 400 || mc.ErrorStatusCode > 599) {
+		return fmt.Errorf("error_status_code must be an HTTP error status code")
+	}
+	return nil
+}
+
 // ProviderConfig represents the complete configuration for a provider.
 // An array of ProviderConfig needs to be provided in GetConfigForProvider
 // in your account interface implementation.
@@ -239,6 +293,7 @@ type ProviderConfig struct {
 	ProxyConfig          *ProxyConfig          `json:"proxy_config,omitempty"` // Proxy configuration
 	SendBackRawResponse  bool                  `json:"send_back_raw_response"` // Send raw response back in the bifrost response (default: false)
 	CustomProviderConfig *CustomProviderConfig `json:"custom_provider_config,omitempty"`
+	MockConfig           *MockConfig           `json:"mock_config,omitempty"` // Synthetic responses of the mock provider
 }
 
 func (config *ProviderConfig) CheckAndSetDefaults() {
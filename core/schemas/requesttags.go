@@ -0,0 +1,56 @@
+package schemas
+
+import (
+	"fmt"
+	"slices"
+)
+
+// MaxRequestTagValueLength is the longest value of a tag whose rule does not list its values
+const MaxRequestTagValueLength = 128
+
+// RequestTagsConfig is the allow-list of the tags clients can attach to their requests, with the x-bf-tags header
+// (BifrostContextKeyRequestTags) or the metadata parameter of the request. Tags of the allow-list are recorded on the
+// logs and metrics of the request, so that its cost can be attributed to a feature within one application. Metadata
+// keys outside the allow-list are ignored. A nil config records no tags.
+type RequestTagsConfig struct {
+	Tags          []RequestTagRule `json:"tags"`                     // Tags clients can set
+	RejectUnknown bool             `json:"reject_unknown,omitempty"` // Reject requests with header tags outside the allow-list instead of dropping the tags
+}
+
+// RequestTagRule is a tag of the allow-list
+type RequestTagRule struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values,omitempty"` // Values allowed for the tag, empty allows any value up to MaxRequestTagValueLength. Tags used as Prometheus labels should list their values to bound their cardinality
+}
+
+// Allows reports whether the tag and its value are in the allow-list
+func (c *RequestTagsConfig) Allows(name, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, rule := range c.Tags {
+		if rule.Name != name {
+			continue
+		}
+		if len(rule.Values) == 0 {
+			return len(value) <= MaxRequestTagValueLength
+		}
+		return slices.Contains(rule.Values, value)
+	}
+	return false
+}
+
+// Validate checks that the tags of the allow-list are named and unique
+func (c *RequestTagsConfig) Validate() error {
+	names := make(map[string]bool, len(c.Tags))
+	for i, rule := range c.Tags {
+		if rule.Name == "" {
+			return fmt.Errorf("request tag %d requires a name", i)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicate request tag %s", rule.Name)
+		}
+		names[rule.Name] = true
+	}
+	return nil
+}
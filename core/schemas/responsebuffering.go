@@ -0,0 +1,26 @@
+package schemas
+
+// ResponseBufferingConfig configures the chat and text completion requests that are sent to their provider as
+// streams, although their client did not ask for streaming. The stream is aggregated by Bifrost into a single
+// response, so that failures are detected as soon as the provider fails and the time to first token is measured.
+// The x-bf-stream-upstream header overrides the config for a single request. A nil config sends requests as they are.
+type ResponseBufferingConfig struct {
+	Enabled   bool            `json:"enabled"`
+	Providers []ModelProvider `json:"providers,omitempty"` // Providers whose requests are streamed, empty means all providers
+}
+
+// Applies reports whether the non-stream requests of the provider are streamed
+func (c *ResponseBufferingConfig) Applies(provider ModelProvider) bool {
+	if c == nil || !c.Enabled {
+		return false
+	}
+	if len(c.Providers) == 0 {
+		return true
+	}
+	for _, p := range c.Providers {
+		if p == provider {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package schemas
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultResponsesStateTTL is how long a stored response can be continued when the config does not set it
+const DefaultResponsesStateTTL = 24 * time.Hour
+
+// DefaultResponsesStateMaxEntries is the number of responses stored when the config does not set it
+const DefaultResponsesStateMaxEntries = 10000
+
+// ResponsesStateConfig configures the storage of Responses API conversations by Bifrost, so that previous_response_id
+// works whichever key, provider or fallback served the previous response. The input and output of responses are
+// stored per caller, and requests continuing a stored response are sent with the whole conversation instead of
+// previous_response_id. Responses requested with store set to false are not stored, and unknown previous response IDs
+// are left to the provider. A nil config disables the storage.
+type ResponsesStateConfig struct {
+	TTLSeconds int `json:"ttl_seconds,omitempty"` // How long a response can be continued. 0 uses DefaultResponsesStateTTL
+	MaxEntries int `json:"max_entries,omitempty"` // Responses stored, the oldest are dropped above it. 0 uses DefaultResponsesStateMaxEntries
+}
+
+// TTL returns how long a stored response can be continued
+func (c *ResponsesStateConfig) TTL() time.Duration {
+	if c == nil || c.TTLSeconds <= 0 {
+		return DefaultResponsesStateTTL
+	}
+	return time.Duration(c.TTLSeconds) * time.Second
+}
+
+// Capacity returns the number of responses stored
+func (c *ResponsesStateConfig) Capacity() int {
+	if c == nil || c.MaxEntries <= 0 {
+		return DefaultResponsesStateMaxEntries
+	}
+	return c.MaxEntries
+}
+
+// Validate checks the TTL and the capacity of the config
+func (c *ResponsesStateConfig) Validate() error {
+	if c.TTLSeconds < 0 {
+		return fmt.Errorf("responses state ttl cannot be negative, got %d", c.TTLSeconds)
+	}
+	if c.MaxEntries < 0 {
+		return fmt.Errorf("responses state max entries cannot be negative, got %d", c.MaxEntries)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package schemas
+
+import (
+	"fmt"
+	"time"
+)
+
+// RetryPolicy decides which failed provider requests are retried, and how long to wait before each retry.
+// It applies to non-streaming requests and to the initial request of streams; failures of a stream after
+// its first chunk are not retried. A provider without a policy retries connection errors, 5xx and rate limit
+// errors up to MaxRetries times with the backoff of its network config.
+type RetryPolicy struct {
+	Rules           []RetryRule  `json:"rules"`                        // Evaluated in order, the first rule matching an error decides. Errors matching no rule are not retried
+	Budget          *RetryBudget `json:"budget,omitempty"`             // Optional: Limits the share of retries across all requests of the provider
+	HonorRetryAfter bool         `json:"honor_retry_after,omitempty"`  // Wait for the delay of the Retry-After header of the provider, when it is longer than the backoff
+	MaxRetryAfterMs int          `json:"max_retry_after_ms,omitempty"` // Longest Retry-After delay waited for, longer delays are not retried. 0 uses DefaultMaxRetryAfter
+}
+
+// RetryRule is the retry behavior of a class of errors.
+// An error matches a rule when it matches any of its conditions.
+type RetryRule struct {
+	Name             string   `json:"name"`                         // Name of the error class, used in logs
+	StatusCodes      []int    `json:"status_codes,omitempty"`       // HTTP status codes of the provider response
+	ErrorCodes       []string `json:"error_codes,omitempty"`        // Error codes or types returned by the provider, e.g. "overloaded_error", or canonical error codes, e.g. "overloaded"
+	MessagePatterns  []string `json:"message_patterns,omitempty"`   // Case-insensitive substrings of the error message
+	ConnectionErrors bool     `json:"connection_errors,omitempty"`  // Requests that could not be sent to the provider
+	MaxRetries       int      `json:"max_retries"`                  // Retries of a request for errors of this class, 0 does not retry them
+	BackoffInitialMs int      `json:"backoff_initial_ms,omitempty"` // Backoff before the first retry, doubled on each retry with 20% jitter. 0 uses the backoff of the network config
+	BackoffMaxMs     int      `json:"backoff_max_ms,omitempty"`     // Longest backoff. 0 uses the backoff of the network config
+}
+
+// RetryBudget caps the retries of a provider to a share of its requests, so that retries do not amplify
+// the load of a provider that is already failing. The budget is computed over the last 10 seconds.
+type RetryBudget struct {
+	RetryRatio          float64 `json:"retry_ratio"`                      // Retries allowed per request, e.g. 0.2 allows one retry for five requests
+	MinRetriesPerSecond int     `json:"min_retries_per_second,omitempty"` // Retries always allowed, so that providers with few requests can retry
+}
+
+// DefaultMaxRetryAfter is the longest Retry-After delay waited for when the policy does not set one
+const DefaultMaxRetryAfter = 60 * time.Second
+
+// Validate checks the rules and the budget of the policy
+func (p *RetryPolicy) Validate() error {
+	for i, rule := range p.Rules {
+		if rule.MaxRetries < 0 {
+			return fmt.Errorf("max retries of rule %d cannot be negative, got %d", i, rule.MaxRetries)
+		}
+		if rule.BackoffInitialMs < 0 || rule.BackoffMaxMs < 0 {
+			return fmt.Errorf("backoff of rule %d cannot be negative", i)
+		}
+		if rule.BackoffInitialMs > 0 && rule.BackoffMaxMs > 0 && rule.BackoffInitialMs > rule.BackoffMaxMs {
+			return fmt.Errorf("initial backoff of rule %d must be less than or equal to its max backoff", i)
+		}
+		if len(rule.StatusCodes) == 0 && len(rule.ErrorCodes) == 0 && len(rule.MessagePatterns) == 0 && !rule.ConnectionErrors {
+			return fmt.Errorf("rule %d does not match any error", i)
+		}
+	}
+	if p.MaxRetryAfterMs < 0 {
+		return fmt.Errorf("max retry after cannot be negative, got %d", p.MaxRetryAfterMs)
+	}
+	if p.Budget != nil && (p.Budget.RetryRatio < 0 || p.Budget.MinRetriesPerSecond < 0) {
+		return fmt.Errorf("retry budget cannot be negative")
+	}
+	return nil
+}
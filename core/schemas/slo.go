@@ -0,0 +1,122 @@
+package schemas
+
+import (
+	"fmt"
+	"time"
+)
+
+// Defaults of the SLO tracking
+const (
+	DefaultSLOWindowMinutes     = 60
+	DefaultSLOMinRequests       = 20
+	DefaultSLOBurnRateThreshold = 2.0
+)
+
+// SLOObjective is a service level objective of a provider, a model of a provider, or a model alias. A request is
+// counted by every objective it matches.
+type SLOObjective struct {
+	Name               string        `json:"name"`                           // Unique name of the objective, e.g. "openai-availability"
+	Provider           ModelProvider `json:"provider,omitempty"`             // Provider the objective applies to, any provider when empty
+	Model              string        `json:"model,omitempty"`                // Model or alias of a fallback chain the objective applies to, any model when empty
+	Availability       float64       `json:"availability,omitempty"`         // Share of the requests that do not fail on the provider, e.g. 0.995, 0 tracks no availability objective
+	LatencyThresholdMs int64         `json:"latency_threshold_ms,omitempty"` // Latency under which a successful request is fast, until the stream started for streams
+	Latency            float64       `json:"latency,omitempty"`              // Share of the successful requests faster than the threshold, e.g. 0.95, 0 tracks no latency objective
+}
+
+// SLOConfig configures the tracking of the service level objectives of the providers, over a rolling window. The
+// error budget of an objective is the share of requests allowed to miss it, and its burn rate is how fast the
+// requests of the window consume it: a burn rate of 1 uses up the budget exactly, above 1 the objective is missed.
+type SLOConfig struct {
+	Objectives        []SLOObjective `json:"objectives"`
+	WindowMinutes     int            `json:"window_minutes,omitempty"`      // Rolling window of the compliance, defaults to DefaultSLOWindowMinutes
+	MinRequests       int64          `json:"min_requests,omitempty"`        // Requests in the window below which an objective is not considered burning, defaults to DefaultSLOMinRequests
+	BurnRateThreshold float64        `json:"burn_rate_threshold,omitempty"` // Burn rate above which an objective is burning its budget, defaults to DefaultSLOBurnRateThreshold
+	Routing           bool           `json:"routing,omitempty"`             // Move the hops of fallback chains burning the budget of an objective after the other hops
+}
+
+// SLOStatus is the compliance of an objective over the rolling window
+type SLOStatus struct {
+	Objective SLOObjective `json:"objective"`
+	Window    string       `json:"window"` // e.g. "1h0m0s"
+	Requests  int64        `json:"requests"`
+	Failures  int64        `json:"failures"`      // Requests that failed on the provider
+	Slow      int64        `json:"slow_requests"` // Successful requests slower than the latency threshold
+
+	AvailabilityCompliance      *float64 `json:"availability_compliance,omitempty"`       // Share of the requests that did not fail
+	AvailabilityBurnRate        *float64 `json:"availability_burn_rate,omitempty"`        // Failure rate over the allowed failure rate
+	AvailabilityBudgetRemaining *float64 `json:"availability_budget_remaining,omitempty"` // 1 - burn rate, negative once the budget is exceeded
+	LatencyCompliance           *float64 `json:"latency_compliance,omitempty"`            // Share of the successful requests faster than the threshold
+	LatencyBurnRate             *float64 `json:"latency_burn_rate,omitempty"`
+	LatencyBudgetRemaining      *float64 `json:"latency_budget_remaining,omitempty"`
+
+	Burning bool `json:"burning"` // The burn rate of an objective is above the threshold, with enough requests in the window
+}
+
+// Validate checks that the objectives are named uniquely and have a target share
+func (c *SLOConfig) Validate() error {
+	if c.WindowMinutes < 0 {
+		return fmt.Errorf("window minutes cannot be negative, got %d", c.WindowMinutes)
+	}
+	if c.MinRequests < 0 {
+		return fmt.Errorf("min requests cannot be negative, got %d", c.MinRequests)
+	}
+	if c.BurnRateThreshold < 0 {
+		return fmt.Errorf("burn rate threshold cannot be negative, got %g", c.BurnRateThreshold)
+	}
+	names := make(map[string]bool, len(c.Objectives))
+	for i, objective := range c.Objectives {
+		if objective.Name == "" {
+			return fmt.Errorf("objective %d must have a name", i)
+		}
+		if names[objective.Name] {
+			return fmt.Errorf("duplicate objective %s", objective.Name)
+		}
+		names[objective.Name] = true
+		if objective.Availability < 0 || objective.Availability >= 1 {
+			return fmt.Errorf("availability of objective %s must be between 0 and 1 excluded, got %g", objective.Name, objective.Availability)
+		}
+		if objective.Latency < 0 || objective.Latency >= 1 {
+			return fmt.Errorf("latency of objective %s must be between 0 and 1 excluded, got %g", objective.Name, objective.Latency)
+		}
+		if objective.Latency > 0 && objective.LatencyThresholdMs <= 0 {
+			return fmt.Errorf("latency objective %s requires a latency threshold", objective.Name)
+		}
+		if objective.Availability == 0 && objective.Latency == 0 {
+			return fmt.Errorf("objective %s must have an availability or latency target", objective.Name)
+		}
+	}
+	return nil
+}
+
+// GetWindow returns the rolling window of the compliance
+func (c *SLOConfig) GetWindow() time.Duration {
+	if c.WindowMinutes <= 0 {
+		return DefaultSLOWindowMinutes * time.Minute
+	}
+	return time.Duration(c.WindowMinutes) * time.Minute
+}
+
+// GetMinRequests returns the requests in the window below which an objective is not considered burning
+func (c *SLOConfig) GetMinRequests() int64 {
+	if c.MinRequests <= 0 {
+		return DefaultSLOMinRequests
+	}
+	return c.MinRequests
+}
+
+// GetBurnRateThreshold returns the burn rate above which an objective is burning its budget
+func (c *SLOConfig) GetBurnRateThreshold() float64 {
+	if c.BurnRateThreshold <= 0 {
+		return DefaultSLOBurnRateThreshold
+	}
+	return c.BurnRateThreshold
+}
+
+// Matches reports whether the requests of the provider and model, sent for the alias of a fallback chain if any,
+// count for the objective
+func (o *SLOObjective) Matches(provider ModelProvider, model, alias string) bool {
+	if o.Provider != "" && o.Provider != provider {
+		return false
+	}
+	return o.Model == "" || o.Model == model || (alias != "" && o.Model == alias)
+}
@@ -25,6 +25,8 @@ type BifrostSpeechResponse struct {
 	Alignment           *SpeechAlignment           `json:"alignment,omitempty"`            // Character-level timing information
 	NormalizedAlignment *SpeechAlignment           `json:"normalized_alignment,omitempty"` // Character-level timing information for normalized text
 	AudioBase64         *string                    `json:"audio_base64,omitempty"`         // Base64-encoded audio (when timestamps are requested)
+	Format              string                     `json:"format,omitempty"`               // Format of the audio, e.g. "mp3" or "wav"
+	SampleRate          int                        `json:"sample_rate,omitempty"`          // Sample rate of the audio in Hz, when known
 	ExtraFields         BifrostResponseExtraFields `json:"extra_fields"`
 }
 
@@ -44,6 +46,7 @@ type SpeechParameters struct {
 	VoiceConfig    *SpeechVoiceInput `json:"voice"`
 	Instructions   string            `json:"instructions,omitempty"`
 	ResponseFormat string            `json:"response_format,omitempty"` // Default is "mp3"
+	SampleRate     *int              `json:"sample_rate,omitempty"`     // Hz, providers use the closest rate they support
 	Speed          *float64          `json:"speed,omitempty"`
 
 	LanguageCode                    *string                                `json:"language_code,omitempty"`
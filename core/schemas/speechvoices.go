@@ -0,0 +1,70 @@
+package schemas
+
+import "strings"
+
+// Unified speech output formats, providers are asked for the closest format they support
+const (
+	SpeechFormatMP3  = "mp3"
+	SpeechFormatOpus = "opus"
+	SpeechFormatPCM  = "pcm"
+	SpeechFormatWAV  = "wav"
+)
+
+// DefaultSpeechSampleRate is the sample rate of OpenAI and Azure OpenAI speech, which cannot be changed
+const DefaultSpeechSampleRate = 24000
+
+// SpeechVoice is a voice of the unified voice catalog, available under the same name on every speech provider
+type SpeechVoice struct {
+	Name      string                   `json:"name"`
+	Gender    string                   `json:"gender"`
+	Providers map[ModelProvider]string `json:"providers"` // Voice of each provider, by provider
+}
+
+// SpeechVoices is the unified voice catalog. The names are the ones of the OpenAI voices, which Azure OpenAI shares,
+// and every voice is mapped to the closest premade ElevenLabs voice.
+var SpeechVoices = []SpeechVoice{
+	{Name: "alloy", Gender: "neutral", Providers: map[ModelProvider]string{OpenAI: "alloy", Azure: "alloy", Elevenlabs: "21m00Tcm4TlvDq8ikWAM"}},      // Rachel
+	{Name: "ash", Gender: "male", Providers: map[ModelProvider]string{OpenAI: "ash", Azure: "ash", Elevenlabs: "nPczCjzI2devNBz1zQrb"}},               // Brian
+	{Name: "ballad", Gender: "male", Providers: map[ModelProvider]string{OpenAI: "ballad", Azure: "ballad", Elevenlabs: "JBFqnCBsd6RMkjVDRZzb"}},      // George
+	{Name: "coral", Gender: "female", Providers: map[ModelProvider]string{OpenAI: "coral", Azure: "coral", Elevenlabs: "EXAVITQu4vr4xnSDxMaL"}},       // Sarah
+	{Name: "echo", Gender: "male", Providers: map[ModelProvider]string{OpenAI: "echo", Azure: "echo", Elevenlabs: "ErXwobaYiN019PkySvjV"}},            // Antoni
+	{Name: "fable", Gender: "male", Providers: map[ModelProvider]string{OpenAI: "fable", Azure: "fable", Elevenlabs: "onwK4e9ZLuTAKqWW03F9"}},         // Daniel
+	{Name: "onyx", Gender: "male", Providers: map[ModelProvider]string{OpenAI: "onyx", Azure: "onyx", Elevenlabs: "pNInz6obpgDQGcFmaJgB"}},            // Adam
+	{Name: "nova", Gender: "female", Providers: map[ModelProvider]string{OpenAI: "nova", Azure: "nova", Elevenlabs: "XB0fDUnXU5powFXDhCwa"}},          // Charlotte
+	{Name: "sage", Gender: "female", Providers: map[ModelProvider]string{OpenAI: "sage", Azure: "sage", Elevenlabs: "Xb7hH8MSUJpSbSDYk0k2"}},          // Alice
+	{Name: "shimmer", Gender: "female", Providers: map[ModelProvider]string{OpenAI: "shimmer", Azure: "shimmer", Elevenlabs: "MF3mGyEYCl7XYWbV9V6O"}}, // Elli
+	{Name: "verse", Gender: "male", Providers: map[ModelProvider]string{OpenAI: "verse", Azure: "verse", Elevenlabs: "TxGEqnHWrfWFTfGW9XjX"}},         // Josh
+}
+
+// ResolveSpeechVoice returns the voice of the provider for a voice of the unified catalog, matched case-insensitively.
+// Voices that are not in the catalog, such as provider voice IDs, are returned as they are.
+func ResolveSpeechVoice(provider ModelProvider, voice string) string {
+	for _, speechVoice := range SpeechVoices {
+		if strings.EqualFold(speechVoice.Name, voice) {
+			if providerVoice, ok := speechVoice.Providers[provider]; ok {
+				return providerVoice
+			}
+			return voice
+		}
+	}
+	return voice
+}
+
+// SpeechFormatContentType returns the content type of audio in the speech format
+func SpeechFormatContentType(format string) string {
+	switch format {
+	case "", SpeechFormatMP3:
+		return "audio/mpeg"
+	case SpeechFormatOpus:
+		return "audio/ogg"
+	case SpeechFormatWAV:
+		return "audio/wav"
+	case SpeechFormatPCM:
+		return "audio/pcm"
+	case "aac":
+		return "audio/aac"
+	case "flac":
+		return "audio/flac"
+	}
+	return "application/octet-stream"
+}
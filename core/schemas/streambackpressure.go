@@ -0,0 +1,47 @@
+package schemas
+
+import "fmt"
+
+// StreamBackpressurePolicy is what happens to a stream whose consumer does not keep up with its provider
+type StreamBackpressurePolicy string
+
+const (
+	StreamBackpressureBlock StreamBackpressurePolicy = "block" // Stop reading from the provider until the consumer catches up
+	StreamBackpressureDrop  StreamBackpressurePolicy = "drop"  // Cancel the stream and end it with an error
+	StreamBackpressureDisk  StreamBackpressurePolicy = "disk"  // Spill the chunks the consumer did not read yet to a temporary file
+)
+
+const (
+	DefaultStreamSlowConsumerTimeoutMs = 10000
+	DefaultStreamMaxDiskBytes          = 64 << 20
+)
+
+// StreamBackpressureConfig bounds the chunks held in memory for each stream, so that slow consumers cannot grow the
+// memory of the gateway. A stream is slow when its buffer stays full for SlowConsumerTimeoutMs, its policy is then
+// applied. A nil config buffers up to DefaultStreamBufferSize chunks per stream and blocks the provider when full.
+type StreamBackpressureConfig struct {
+	BufferSize            int                      `json:"buffer_size,omitempty"`              // Chunks buffered between the provider and the consumer, 0 uses DefaultStreamBufferSize
+	Policy                StreamBackpressurePolicy `json:"policy,omitempty"`                   // Policy applied to slow consumers, defaults to block
+	SlowConsumerTimeoutMs int                      `json:"slow_consumer_timeout_ms,omitempty"` // Time the buffer may stay full before the consumer is slow, 0 uses DefaultStreamSlowConsumerTimeoutMs
+	DiskDir               string                   `json:"disk_dir,omitempty"`                 // Directory of the spill files of the disk policy, defaults to the temporary directory
+	MaxDiskBytes          int64                    `json:"max_disk_bytes,omitempty"`           // Size of the spill file of a stream before it is dropped, 0 uses DefaultStreamMaxDiskBytes
+}
+
+// Validate checks the policy and that the sizes are not negative
+func (c *StreamBackpressureConfig) Validate() error {
+	switch c.Policy {
+	case "", StreamBackpressureBlock, StreamBackpressureDrop, StreamBackpressureDisk:
+	default:
+		return fmt.Errorf("unknown policy %q, expected block, drop or disk", c.Policy)
+	}
+	if c.BufferSize < 0 {
+		return fmt.Errorf("buffer size cannot be negative, got %d", c.BufferSize)
+	}
+	if c.SlowConsumerTimeoutMs < 0 {
+		return fmt.Errorf("slow consumer timeout cannot be negative, got %d", c.SlowConsumerTimeoutMs)
+	}
+	if c.MaxDiskBytes < 0 {
+		return fmt.Errorf("max disk bytes cannot be negative, got %d", c.MaxDiskBytes)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package schemas
+
+import "fmt"
+
+// StreamFailoverConfig configures the failover of chat and text completion streams dying mid-generation.
+// When the provider fails with a 5xx error or the connection is lost, the generation is restarted on the next
+// fallback of the request with the partial output prepended as assistant context, instead of ending the stream
+// with the error. A nil config ends failed streams with their error.
+type StreamFailoverConfig struct {
+	Enabled      bool `json:"enabled"`
+	MaxFailovers int  `json:"max_failovers,omitempty"` // Restarts of a single stream, 0 allows one per fallback
+}
+
+// Validate checks that the number of failovers is not negative
+func (c *StreamFailoverConfig) Validate() error {
+	if c.MaxFailovers < 0 {
+		return fmt.Errorf("max failovers cannot be negative, got %d", c.MaxFailovers)
+	}
+	return nil
+}
+
+// BifrostStreamFailover is sent in the extra fields of the marker chunk emitted when a stream fails over to a fallback.
+// The chunks following the marker are generated by the fallback and continue the partial output.
+type BifrostStreamFailover struct {
+	FromProvider      ModelProvider `json:"from_provider"`
+	FromModel         string        `json:"from_model"`
+	ToProvider        ModelProvider `json:"to_provider"`
+	ToModel           string        `json:"to_model"`
+	Error             string        `json:"error"`               // Error that ended the stream of the failed provider
+	PartialOutputSize int           `json:"partial_output_size"` // Characters generated before the failure and prepended to the fallback request
+}
@@ -0,0 +1,21 @@
+package schemas
+
+import "fmt"
+
+// StreamingConfig configures the Server-Sent Events streams of the HTTP transport. Idle streams are kept open with
+// keep-alive comments, and resumable streams can be continued by clients reconnecting with the Last-Event-ID header.
+type StreamingConfig struct {
+	HeartbeatIntervalSeconds int `json:"heartbeat_interval_seconds,omitempty"` // Interval of the keep-alive comments on idle streams, 0 disables them
+	ResumeBufferSeconds      int `json:"resume_buffer_seconds,omitempty"`      // How long the events of a finished stream can be replayed, 0 disables resumption
+}
+
+// Validate checks that the intervals are not negative
+func (c *StreamingConfig) Validate() error {
+	if c.HeartbeatIntervalSeconds < 0 {
+		return fmt.Errorf("heartbeat interval cannot be negative, got %d", c.HeartbeatIntervalSeconds)
+	}
+	if c.ResumeBufferSeconds < 0 {
+		return fmt.Errorf("resume buffer cannot be negative, got %d", c.ResumeBufferSeconds)
+	}
+	return nil
+}
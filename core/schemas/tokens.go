@@ -0,0 +1,63 @@
+package schemas
+
+import "fmt"
+
+// TokenizerType is the tokenizer family counting the tokens of a model
+type TokenizerType string
+
+const (
+	TokenizerO200k  TokenizerType = "o200k_base"  // GPT-4o, GPT-4.1, GPT-5 and o-series models
+	TokenizerCL100k TokenizerType = "cl100k_base" // GPT-4, GPT-3.5 and embedding models, and models of unknown families
+	TokenizerClaude TokenizerType = "claude"      // Anthropic Claude models
+	TokenizerLlama  TokenizerType = "llama"       // Meta Llama models
+)
+
+// ContextWindowAction is what the pre-flight check does with requests exceeding the context window of their model
+type ContextWindowAction string
+
+const (
+	ContextWindowActionReject ContextWindowAction = "reject" // Fails the request before it reaches the provider
+	ContextWindowActionTrim   ContextWindowAction = "trim"   // Drops the oldest messages of chat requests until they fit, other requests are rejected
+)
+
+// ContextWindowConfig configures the pre-flight check counting the input tokens of chat, responses and text completion
+// requests against the context window of the target model, before the provider round trip. Requests to models without a
+// known context window are sent as they are.
+type ContextWindowConfig struct {
+	Action         ContextWindowAction `json:"action"`                    // reject or trim
+	ContextWindows map[string]int      `json:"context_windows,omitempty"` // Context windows by "model" or "provider/model", overriding the built-in ones
+}
+
+// Validate checks the action and that the context windows are positive
+func (c *ContextWindowConfig) Validate() error {
+	if c.Action != ContextWindowActionReject && c.Action != ContextWindowActionTrim {
+		return fmt.Errorf("context window action must be reject or trim, got %q", c.Action)
+	}
+	for model, window := range c.ContextWindows {
+		if window <= 0 {
+			return fmt.Errorf("context window of %s must be positive", model)
+		}
+	}
+	return nil
+}
+
+// BifrostTokenCountRequest is a request counting the input tokens of messages or of a prompt for a model,
+// without sending it to the provider
+type BifrostTokenCountRequest struct {
+	Provider ModelProvider `json:"provider"`
+	Model    string        `json:"model"`
+	Messages []ChatMessage `json:"messages,omitempty"`
+	Prompt   *string       `json:"prompt,omitempty"` // Counted as plain text, after the messages
+	Tools    []ChatTool    `json:"tools,omitempty"`  // Tool definitions sent with the messages
+}
+
+// BifrostTokenCountResponse is the input token count of a token count request
+type BifrostTokenCountResponse struct {
+	Provider             ModelProvider `json:"provider"`
+	Model                string        `json:"model"`
+	Tokenizer            TokenizerType `json:"tokenizer"`
+	InputTokens          int           `json:"input_tokens"`
+	ContextWindow        int           `json:"context_window,omitempty"`   // 0 when the context window of the model is unknown
+	RemainingTokens      *int          `json:"remaining_tokens,omitempty"` // Tokens left in the context window for the output
+	ExceedsContextWindow bool          `json:"exceeds_context_window,omitempty"`
+}
@@ -0,0 +1,156 @@
+package schemas
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TransformActionType is the kind of rewrite a transform action performs
+type TransformActionType string
+
+const (
+	TransformActionSet                TransformActionType = "set"                  // Sets the parameter at Path to Value
+	TransformActionSetDefault         TransformActionType = "set_default"          // Sets the parameter at Path to Value when the request doesn't set it
+	TransformActionRemove             TransformActionType = "remove"               // Removes the parameter at Path
+	TransformActionClamp              TransformActionType = "clamp"                // Bounds the numeric parameter at Path to Min and Max
+	TransformActionRenameModel        TransformActionType = "rename_model"         // Replaces the model with Value, as "model" or "provider/model"
+	TransformActionInjectSystemPrompt TransformActionType = "inject_system_prompt" // Adds Value as a system prompt before the messages of chat requests and the instructions of responses requests
+)
+
+// TransformEnforcement is how the clamp and set actions of a rule treat the parameters sent by the client
+type TransformEnforcement string
+
+const (
+	TransformEnforcementRewrite TransformEnforcement = "rewrite" // Values out of the bounds of clamp actions or differing from set actions are rewritten (default)
+	TransformEnforcementReject  TransformEnforcement = "reject"  // Requests with such values are rejected
+)
+
+// TransformAction is a single rewrite of a request. Paths are JSONPath expressions over the parameters of the request,
+// where provider-specific extra parameters appear next to the known ones, e.g. "$.temperature" or "$.metadata.tier".
+type TransformAction struct {
+	Type  TransformActionType `json:"type"`
+	Path  string              `json:"path,omitempty"`  // Parameter the action applies to (set, set_default, remove, clamp)
+	Value interface{}         `json:"value,omitempty"` // Value set (set, set_default), model (rename_model) or prompt (inject_system_prompt)
+	Min   *float64            `json:"min,omitempty"`   // Lower bound (clamp)
+	Max   *float64            `json:"max,omitempty"`   // Upper bound (clamp)
+}
+
+// TransformRule is a named list of actions rewriting the requests of virtual keys or requested models.
+// All rules matching a request apply after the plugin PreHooks, in ascending priority and then name order,
+// so that the virtual key resolved by governance is known and plugins see the request as sent by the caller.
+type TransformRule struct {
+	Name          string               `json:"name"`
+	Description   string               `json:"description,omitempty"`
+	Priority      int                  `json:"priority,omitempty"`        // Rules with a lower priority apply first
+	Models        []string             `json:"models,omitempty"`          // Requested models the rule applies to, as "model", "provider/model" or "*" for all models
+	VirtualKeyIDs []string             `json:"virtual_key_ids,omitempty"` // Virtual keys the rule applies to
+	Enforcement   TransformEnforcement `json:"enforcement,omitempty"`     // How values sent by the client that violate the clamp and set actions are handled
+	Actions       []TransformAction    `json:"actions"`
+}
+
+// TransformPathSegment is a step of a parsed transform path, an object key or an array index
+type TransformPathSegment struct {
+	Key   string
+	Index int
+	IsKey bool
+}
+
+// ParseTransformPath parses a JSONPath expression made of keys and array indexes, such as "$.metadata.tags[0]".
+// The leading "$." is optional.
+func ParseTransformPath(path string) ([]TransformPathSegment, error) {
+	rest := strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if rest == "" {
+		return nil, fmt.Errorf("path %q selects no parameter", path)
+	}
+	var segments []TransformPathSegment
+	for _, part := range strings.Split(rest, ".") {
+		key, indexes, _ := strings.Cut(part, "[")
+		if key == "" && len(segments) == 0 {
+			return nil, fmt.Errorf("path %q must start with a key", path)
+		}
+		if key != "" {
+			segments = append(segments, TransformPathSegment{Key: key, IsKey: true})
+		} else if indexes == "" {
+			return nil, fmt.Errorf("path %q has an empty key", path)
+		}
+		if indexes == "" {
+			continue
+		}
+		for _, index := range strings.Split(strings.TrimSuffix(indexes, "]"), "][") {
+			n, err := strconv.Atoi(index)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("path %q has an invalid array index %q", path, index)
+			}
+			segments = append(segments, TransformPathSegment{Index: n})
+		}
+		if !strings.HasSuffix(indexes, "]") {
+			return nil, fmt.Errorf("path %q has an unterminated array index", path)
+		}
+	}
+	return segments, nil
+}
+
+// Validate checks that the action has the fields its type requires
+func (a *TransformAction) Validate() error {
+	switch a.Type {
+	case TransformActionSet, TransformActionSetDefault, TransformActionRemove, TransformActionClamp:
+		if _, err := ParseTransformPath(a.Path); err != nil {
+			return err
+		}
+		if a.Type == TransformActionClamp {
+			if a.Min == nil && a.Max == nil {
+				return fmt.Errorf("clamp action requires min or max")
+			}
+			if a.Min != nil && a.Max != nil && *a.Min > *a.Max {
+				return fmt.Errorf("clamp action has min above max")
+			}
+		}
+	case TransformActionRenameModel, TransformActionInjectSystemPrompt:
+		if value, ok := a.Value.(string); !ok || value == "" {
+			return fmt.Errorf("%s action requires a string value", a.Type)
+		}
+	default:
+		return fmt.Errorf("unknown action type %q", a.Type)
+	}
+	return nil
+}
+
+// Validate checks the rule for missing fields and invalid actions
+func (r *TransformRule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("transform rule name is required")
+	}
+	if len(r.Models) == 0 && len(r.VirtualKeyIDs) == 0 {
+		return fmt.Errorf("transform rule %s must apply to at least one model or virtual key, use \"*\" for all models", r.Name)
+	}
+	if len(r.Actions) == 0 {
+		return fmt.Errorf("transform rule %s requires at least one action", r.Name)
+	}
+	switch r.Enforcement {
+	case "", TransformEnforcementRewrite, TransformEnforcementReject:
+	default:
+		return fmt.Errorf("transform rule %s enforcement must be %q or %q", r.Name, TransformEnforcementRewrite, TransformEnforcementReject)
+	}
+	for i := range r.Actions {
+		if err := r.Actions[i].Validate(); err != nil {
+			return fmt.Errorf("transform rule %s action %d: %v", r.Name, i, err)
+		}
+	}
+	return nil
+}
+
+// ValidateTransformRules validates each rule and checks that names are unique
+func ValidateTransformRules(rules []TransformRule) error {
+	names := make(map[string]bool, len(rules))
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return err
+		}
+		if names[rules[i].Name] {
+			return fmt.Errorf("duplicate transform rule name %s", rules[i].Name)
+		}
+		names[rules[i].Name] = true
+	}
+	return nil
+}
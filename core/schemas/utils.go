@@ -1,10 +1,13 @@
 package schemas
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/url"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -611,6 +614,16 @@ func DeepCopyChatMessage(original ChatMessage) ChatMessage {
 			copy.ChatAssistantMessage.Refusal = &copyRefusal
 		}
 
+		if original.ChatAssistantMessage.Thought != nil {
+			copyThought := *original.ChatAssistantMessage.Thought
+			copy.ChatAssistantMessage.Thought = &copyThought
+		}
+
+		if original.ChatAssistantMessage.ThoughtSignature != nil {
+			copyThoughtSignature := *original.ChatAssistantMessage.ThoughtSignature
+			copy.ChatAssistantMessage.ThoughtSignature = &copyThoughtSignature
+		}
+
 		// Deep copy Annotations
 		if original.ChatAssistantMessage.Annotations != nil {
 			copy.ChatAssistantMessage.Annotations = make([]ChatAssistantMessageAnnotation, len(original.ChatAssistantMessage.Annotations))
@@ -707,6 +720,27 @@ func deepCopyChatContentBlock(original ChatContentBlock) ChatContentBlock {
 		copy.InputAudio = &copyAudio
 	}
 
+	if original.VideoURLStruct != nil {
+		copyVideo := *original.VideoURLStruct
+		if original.VideoURLStruct.MimeType != nil {
+			copyMimeType := *original.VideoURLStruct.MimeType
+			copyVideo.MimeType = &copyMimeType
+		}
+		if original.VideoURLStruct.StartOffset != nil {
+			copyStartOffset := *original.VideoURLStruct.StartOffset
+			copyVideo.StartOffset = &copyStartOffset
+		}
+		if original.VideoURLStruct.EndOffset != nil {
+			copyEndOffset := *original.VideoURLStruct.EndOffset
+			copyVideo.EndOffset = &copyEndOffset
+		}
+		if original.VideoURLStruct.FPS != nil {
+			copyFPS := *original.VideoURLStruct.FPS
+			copyVideo.FPS = &copyFPS
+		}
+		copy.VideoURLStruct = &copyVideo
+	}
+
 	if original.File != nil {
 		copyFile := ChatInputFile{}
 		if original.File.FileData != nil {
@@ -724,6 +758,15 @@ func deepCopyChatContentBlock(original ChatContentBlock) ChatContentBlock {
 		copy.File = &copyFile
 	}
 
+	if original.CacheControl != nil {
+		copyCacheControl := *original.CacheControl
+		if original.CacheControl.TTL != nil {
+			copyTTL := *original.CacheControl.TTL
+			copyCacheControl.TTL = &copyTTL
+		}
+		copy.CacheControl = &copyCacheControl
+	}
+
 	return copy
 }
 
@@ -1039,6 +1082,256 @@ func deepCopyResponsesMessageContentBlock(original ResponsesMessageContentBlock)
 	return copy
 }
 
+// Thinking budgets of the reasoning efforts, for providers configuring reasoning with a token budget
+// (Anthropic extended thinking, Gemini thinking). 1024 is the minimum budget accepted by Anthropic.
+const (
+	ReasoningBudgetMinimal = 1024
+	ReasoningBudgetLow     = 4096
+	ReasoningBudgetMedium  = 8192
+	ReasoningBudgetHigh    = 16384
+)
+
+// ReasoningBudget returns the thinking budget of the reasoning parameters, ReasoningMaxTokens if it is set,
+// or the budget of ReasoningEffort. Returns 0 when reasoning isn't requested.
+func ReasoningBudget(params *ChatParameters) int {
+	if params == nil {
+		return 0
+	}
+	if params.ReasoningMaxTokens != nil {
+		return *params.ReasoningMaxTokens
+	}
+	if params.ReasoningEffort == nil {
+		return 0
+	}
+	switch *params.ReasoningEffort {
+	case "minimal":
+		return ReasoningBudgetMinimal
+	case "low":
+		return ReasoningBudgetLow
+	case "medium":
+		return ReasoningBudgetMedium
+	case "high":
+		return ReasoningBudgetHigh
+	}
+	return 0
+}
+
+// maxToolCallIDLength is the longest tool call ID accepted by every provider (Anthropic and Bedrock allow 64 characters)
+const maxToolCallIDLength = 64
+
+// NormalizeToolCallID returns the tool call ID with the characters rejected by Anthropic and Bedrock
+// (anything but letters, digits, '_' and '-') replaced with '_', truncated to 64 characters.
+// The mapping is deterministic, so a tool call and its result keep matching IDs.
+func NormalizeToolCallID(id string) string {
+	normalized := []byte(id)
+	for i, c := range normalized {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+			normalized[i] = '_'
+		}
+	}
+	if len(normalized) > maxToolCallIDLength {
+		normalized = normalized[:maxToolCallIDLength]
+	}
+	return string(normalized)
+}
+
+// NormalizeToolCalls prepares the tool calls of a conversation for any provider, so that a conversation started
+// with one provider can continue on another (e.g. in a fallback chain). Tool call IDs are normalized with
+// NormalizeToolCallID, tool calls without an ID get a generated one, and tool messages without a tool_call_id
+// are matched with the unanswered tool calls in order. Tool call indexes are renumbered from 0 in each message.
+// The messages are returned as is when nothing changes, otherwise the changed messages are copied.
+func NormalizeToolCalls(messages []ChatMessage) []ChatMessage {
+	var normalized []ChatMessage
+	var pending []string // IDs of the tool calls not answered yet, in order
+	for i, msg := range messages {
+		switch {
+		case msg.ChatAssistantMessage != nil && len(msg.ChatAssistantMessage.ToolCalls) > 0:
+			pending = pending[:0]
+			var toolCalls []ChatAssistantMessageToolCall
+			for j, toolCall := range msg.ChatAssistantMessage.ToolCalls {
+				id := ""
+				if toolCall.ID != nil {
+					id = NormalizeToolCallID(*toolCall.ID)
+				}
+				if id == "" {
+					id = fmt.Sprintf("call_%d_%d", i, j)
+				}
+				pending = append(pending, id)
+				if toolCall.ID != nil && *toolCall.ID == id && toolCall.Index == uint16(j) {
+					continue
+				}
+				if toolCalls == nil {
+					toolCalls = append([]ChatAssistantMessageToolCall{}, msg.ChatAssistantMessage.ToolCalls...)
+				}
+				toolCalls[j].ID = Ptr(id)
+				toolCalls[j].Index = uint16(j)
+			}
+			if toolCalls != nil {
+				assistantMessage := *msg.ChatAssistantMessage
+				assistantMessage.ToolCalls = toolCalls
+				msg.ChatAssistantMessage = &assistantMessage
+				normalized = copyOnWrite(normalized, messages, i, msg)
+			}
+
+		case msg.Role == ChatMessageRoleTool:
+			id := ""
+			if msg.ChatToolMessage != nil && msg.ChatToolMessage.ToolCallID != nil {
+				id = NormalizeToolCallID(*msg.ChatToolMessage.ToolCallID)
+			}
+			if id == "" && len(pending) > 0 {
+				id = pending[0]
+			}
+			if index := slices.Index(pending, id); index >= 0 {
+				pending = slices.Delete(pending, index, index+1)
+			}
+			if id == "" || (msg.ChatToolMessage != nil && msg.ChatToolMessage.ToolCallID != nil && *msg.ChatToolMessage.ToolCallID == id) {
+				continue
+			}
+			toolMessage := ChatToolMessage{}
+			if msg.ChatToolMessage != nil {
+				toolMessage = *msg.ChatToolMessage
+			}
+			toolMessage.ToolCallID = Ptr(id)
+			msg.ChatToolMessage = &toolMessage
+			normalized = copyOnWrite(normalized, messages, i, msg)
+		}
+	}
+	if normalized == nil {
+		return messages
+	}
+	return normalized
+}
+
+// copyOnWrite sets the message at index i, copying the original messages on the first write
+func copyOnWrite(normalized, messages []ChatMessage, i int, msg ChatMessage) []ChatMessage {
+	if normalized == nil {
+		normalized = append([]ChatMessage{}, messages...)
+	}
+	normalized[i] = msg
+	return normalized
+}
+
+//* AUDIO UTILS *//
+
+// audioMediaTypeToFormat maps the media types of audio data URLs to input_audio formats
+var audioMediaTypeToFormat = map[string]string{
+	"audio/wav":    "wav",
+	"audio/wave":   "wav",
+	"audio/x-wav":  "wav",
+	"audio/mpeg":   "mp3",
+	"audio/mp3":    "mp3",
+	"audio/flac":   "flac",
+	"audio/x-flac": "flac",
+	"audio/ogg":    "ogg",
+	"audio/aac":    "aac",
+	"audio/aiff":   "aiff",
+	"audio/x-aiff": "aiff",
+	"audio/webm":   "webm",
+	"audio/mp4":    "m4a",
+	"audio/x-m4a":  "m4a",
+}
+
+// AudioFormatFromMediaType returns the input_audio format of an audio media type (e.g. "audio/mpeg" is "mp3"),
+// or an empty string when it is unknown
+func AudioFormatFromMediaType(mediaType string) string {
+	mediaType, _, _ = strings.Cut(strings.ToLower(mediaType), ";")
+	return audioMediaTypeToFormat[strings.TrimSpace(mediaType)]
+}
+
+// detectAudioFormatFromBase64 detects the format of base64 encoded audio from its header,
+// returning an empty string when it is not recognized
+func detectAudioFormatFromBase64(data string) string {
+	if len(data) > 16 {
+		data = data[:16]
+	}
+	header, _ := base64.StdEncoding.DecodeString(data)
+	switch {
+	case len(header) >= 12 && string(header[:4]) == "RIFF" && string(header[8:12]) == "WAVE":
+		return "wav"
+	case bytes.HasPrefix(header, []byte("ID3")), len(header) >= 2 && header[0] == 0xFF && header[1]&0xE0 == 0xE0:
+		return "mp3"
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		return "flac"
+	case bytes.HasPrefix(header, []byte("OggS")):
+		return "ogg"
+	}
+	return ""
+}
+
+// NormalizeInputAudio prepares the input_audio blocks of the messages for providers, which expect plain base64
+// data with a format. Data URLs (data:audio/wav;base64,...) are stripped of their prefix, formats given as a media
+// type (audio/mpeg) are converted, and a missing format is taken from the media type of the data URL or detected
+// from the audio header (wav, mp3, flac and ogg).
+// The messages are returned as is when nothing changes, otherwise the changed messages are copied.
+func NormalizeInputAudio(messages []ChatMessage) []ChatMessage {
+	var normalized []ChatMessage
+	for i, msg := range messages {
+		if msg.Content == nil {
+			continue
+		}
+		var blocks []ChatContentBlock
+		for j, block := range msg.Content.ContentBlocks {
+			if block.InputAudio == nil {
+				continue
+			}
+			audio := *block.InputAudio
+			if header, data, ok := strings.Cut(audio.Data, ","); ok && strings.HasPrefix(header, "data:") && strings.HasSuffix(header, ";base64") {
+				audio.Data = data
+				if audio.Format == nil {
+					if format := AudioFormatFromMediaType(strings.TrimSuffix(strings.TrimPrefix(header, "data:"), ";base64")); format != "" {
+						audio.Format = Ptr(format)
+					}
+				}
+			}
+			if audio.Format != nil && strings.Contains(*audio.Format, "/") {
+				if format := AudioFormatFromMediaType(*audio.Format); format != "" {
+					audio.Format = Ptr(format)
+				}
+			}
+			if audio.Format == nil {
+				if format := detectAudioFormatFromBase64(audio.Data); format != "" {
+					audio.Format = Ptr(format)
+				}
+			}
+			if audio.Data == block.InputAudio.Data && audio.Format == block.InputAudio.Format {
+				continue
+			}
+			if blocks == nil {
+				blocks = append([]ChatContentBlock{}, msg.Content.ContentBlocks...)
+			}
+			blocks[j].InputAudio = &audio
+		}
+		if blocks != nil {
+			content := *msg.Content
+			content.ContentBlocks = blocks
+			msg.Content = &content
+			normalized = copyOnWrite(normalized, messages, i, msg)
+		}
+	}
+	if normalized == nil {
+		return messages
+	}
+	return normalized
+}
+
+//* VIDEO UTILS *//
+
+// HasInputVideo reports whether any of the messages contains a video, which only Gemini and Vertex accept
+// through their native generateContent API
+func HasInputVideo(messages []ChatMessage) bool {
+	for _, msg := range messages {
+		if msg.Content == nil {
+			continue
+		}
+		for _, block := range msg.Content.ContentBlocks {
+			if block.VideoURLStruct != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // IsAnthropicModel checks if the model is an Anthropic model in Vertex.
 func IsAnthropicModel(model string) bool {
 	return strings.Contains(model, "anthropic.") || strings.Contains(model, "claude")
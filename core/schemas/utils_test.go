@@ -0,0 +1,97 @@
+package schemas
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeToolCallID(t *testing.T) {
+	tests := []struct {
+		id       string
+		expected string
+	}{
+		{"call_abc-123", "call_abc-123"},
+		{"function-call.1", "function-call_1"},
+		{"tooluse:a b", "tooluse_a_b"},
+		{strings.Repeat("a", 80), strings.Repeat("a", maxToolCallIDLength)},
+	}
+	for _, tt := range tests {
+		if got := NormalizeToolCallID(tt.id); got != tt.expected {
+			t.Errorf("NormalizeToolCallID(%q) = %q, expected %q", tt.id, got, tt.expected)
+		}
+	}
+}
+
+func TestNormalizeToolCalls(t *testing.T) {
+	messages := []ChatMessage{
+		{Role: ChatMessageRoleUser, Content: &ChatMessageContent{ContentStr: Ptr("Weather in Paris and Rome?")}},
+		{
+			Role: ChatMessageRoleAssistant,
+			ChatAssistantMessage: &ChatAssistantMessage{
+				ToolCalls: []ChatAssistantMessageToolCall{
+					{ID: Ptr("call.1"), Function: ChatAssistantMessageToolCallFunction{Name: Ptr("get_weather")}},
+					{Index: 5, Function: ChatAssistantMessageToolCallFunction{Name: Ptr("get_weather")}},
+				},
+			},
+		},
+		{Role: ChatMessageRoleTool, ChatToolMessage: &ChatToolMessage{ToolCallID: Ptr("call.1")}},
+		{Role: ChatMessageRoleTool},
+	}
+
+	normalized := NormalizeToolCalls(messages)
+	toolCalls := normalized[1].ChatAssistantMessage.ToolCalls
+	if *toolCalls[0].ID != "call_1" || *toolCalls[1].ID != "call_1_1" || toolCalls[1].Index != 1 {
+		t.Errorf("unexpected tool calls %+v", toolCalls)
+	}
+	if *normalized[2].ChatToolMessage.ToolCallID != "call_1" {
+		t.Errorf("expected the tool message to reference call_1, got %s", *normalized[2].ChatToolMessage.ToolCallID)
+	}
+	if normalized[3].ChatToolMessage == nil || *normalized[3].ChatToolMessage.ToolCallID != "call_1_1" {
+		t.Error("expected the tool message without ID to answer the unanswered tool call")
+	}
+	if *messages[1].ChatAssistantMessage.ToolCalls[0].ID != "call.1" || messages[3].ChatToolMessage != nil {
+		t.Error("the original messages must not be modified")
+	}
+
+	if unchanged := NormalizeToolCalls(normalized); &unchanged[0] != &normalized[0] {
+		t.Error("expected normalized messages to be returned as is")
+	}
+}
+
+func TestNormalizeInputAudio(t *testing.T) {
+	wav := "UklGRiQAAABXQVZFZm10IBAAAAABAAEA" // RIFF....WAVEfmt header
+	messages := []ChatMessage{
+		{Role: ChatMessageRoleSystem, Content: &ChatMessageContent{ContentStr: Ptr("Transcribe the audio")}},
+		{
+			Role: ChatMessageRoleUser,
+			Content: &ChatMessageContent{ContentBlocks: []ChatContentBlock{
+				{Type: ChatContentBlockTypeInputAudio, InputAudio: &ChatInputAudio{Data: "data:audio/mpeg;base64,SUQzBAAAAAAA"}},
+				{Type: ChatContentBlockTypeInputAudio, InputAudio: &ChatInputAudio{Data: wav}},
+				{Type: ChatContentBlockTypeInputAudio, InputAudio: &ChatInputAudio{Data: wav, Format: Ptr("wav")}},
+				{Type: ChatContentBlockTypeInputAudio, InputAudio: &ChatInputAudio{Data: wav, Format: Ptr("audio/x-wav")}},
+			}},
+		},
+	}
+
+	normalized := NormalizeInputAudio(messages)
+	blocks := normalized[1].Content.ContentBlocks
+	if blocks[0].InputAudio.Data != "SUQzBAAAAAAA" || blocks[0].InputAudio.Format == nil || *blocks[0].InputAudio.Format != "mp3" {
+		t.Errorf("expected the data URL to be stripped with the mp3 format, got %+v", blocks[0].InputAudio)
+	}
+	if blocks[1].InputAudio.Format == nil || *blocks[1].InputAudio.Format != "wav" {
+		t.Errorf("expected the wav format to be detected, got %+v", blocks[1].InputAudio)
+	}
+	if blocks[2].InputAudio != messages[1].Content.ContentBlocks[2].InputAudio {
+		t.Error("expected audio with a format to be kept as is")
+	}
+	if blocks[3].InputAudio.Format == nil || *blocks[3].InputAudio.Format != "wav" {
+		t.Errorf("expected the media type to be converted to the wav format, got %+v", blocks[3].InputAudio)
+	}
+	if messages[1].Content.ContentBlocks[0].InputAudio.Format != nil {
+		t.Error("the original messages must not be modified")
+	}
+
+	if unchanged := NormalizeInputAudio(normalized); &unchanged[0] != &normalized[0] {
+		t.Error("expected normalized messages to be returned as is")
+	}
+}
@@ -0,0 +1,226 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// sloBucketSeconds is the duration of the buckets counting the requests of an objective
+const sloBucketSeconds = 60
+
+// sloFailureCodes are the errors counted against the availability of a provider, the other errors are caused by the
+// request or its credentials and count as available
+var sloFailureCodes = map[schemas.ErrorCode]bool{
+	schemas.ErrorCodeRateLimited:      true,
+	schemas.ErrorCodeOverloaded:       true,
+	schemas.ErrorCodeTimeout:          true,
+	schemas.ErrorCodeConnectionFailed: true,
+	schemas.ErrorCodeProviderError:    true,
+	schemas.ErrorCodeUnknown:          true,
+}
+
+// sloBucket counts the requests of an objective during a minute
+type sloBucket struct {
+	minute    int64
+	requests  int64
+	failures  int64
+	successes int64
+	slow      int64
+}
+
+// sloWindow counts the requests of an objective over the rolling window, in buckets of a minute
+type sloWindow struct {
+	mu      sync.Mutex
+	buckets []sloBucket
+}
+
+// sloTracker holds the windows of the objectives of the SLO config, in the order of the objectives
+type sloTracker struct {
+	config  *schemas.SLOConfig
+	windows []*sloWindow
+}
+
+func newSLOWindow(window time.Duration) *sloWindow {
+	return &sloWindow{buckets: make([]sloBucket, max(1, int(window.Seconds())/sloBucketSeconds))}
+}
+
+// record counts a request in the bucket of its minute, resetting the bucket if it belongs to an older minute
+func (w *sloWindow) record(now time.Time, failed, succeeded, slow bool) {
+	minute := now.Unix() / sloBucketSeconds
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	bucket := &w.buckets[minute%int64(len(w.buckets))]
+	if bucket.minute != minute {
+		*bucket = sloBucket{minute: minute}
+	}
+	bucket.requests++
+	if failed {
+		bucket.failures++
+	}
+	if succeeded {
+		bucket.successes++
+	}
+	if slow {
+		bucket.slow++
+	}
+}
+
+// totals returns the counts of the buckets of the window
+func (w *sloWindow) totals(now time.Time) sloBucket {
+	minute := now.Unix() / sloBucketSeconds
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var totals sloBucket
+	for _, bucket := range w.buckets {
+		if minute-bucket.minute < int64(len(w.buckets)) {
+			totals.requests += bucket.requests
+			totals.failures += bucket.failures
+			totals.successes += bucket.successes
+			totals.slow += bucket.slow
+		}
+	}
+	return totals
+}
+
+// updateSLOs replaces the tracked objectives. The counts of an objective are kept when it is unchanged and the window
+// has the same duration, so that reloading the config does not reset the compliance.
+func (bifrost *Bifrost) updateSLOs(config *schemas.SLOConfig) {
+	if config == nil || len(config.Objectives) == 0 {
+		bifrost.slos.Store(nil)
+		return
+	}
+	previous := bifrost.slos.Load()
+	window := config.GetWindow()
+	tracker := &sloTracker{config: config, windows: make([]*sloWindow, len(config.Objectives))}
+	for i, objective := range config.Objectives {
+		tracker.windows[i] = previous.window(objective, window)
+		if tracker.windows[i] == nil {
+			tracker.windows[i] = newSLOWindow(window)
+		}
+	}
+	bifrost.slos.Store(tracker)
+}
+
+// window returns the window of the objective if the tracker has it with a window of the duration, nil otherwise
+func (t *sloTracker) window(objective schemas.SLOObjective, window time.Duration) *sloWindow {
+	if t == nil || t.config.GetWindow() != window {
+		return nil
+	}
+	for i, tracked := range t.config.Objectives {
+		if tracked == objective {
+			return t.windows[i]
+		}
+	}
+	return nil
+}
+
+// recordSLO counts the outcome of a request sent to a provider in the objectives it matches. Cancelled requests are
+// not counted, and the latency only counts for the requests that succeeded.
+func (bifrost *Bifrost) recordSLO(ctx context.Context, provider schemas.ModelProvider, model string, latency time.Duration, err *schemas.BifrostError) {
+	tracker := bifrost.slos.Load()
+	if tracker == nil {
+		return
+	}
+	failed := false
+	if err != nil {
+		code := errorCodeOf(err)
+		if code == schemas.ErrorCodeCancelled {
+			return
+		}
+		failed = sloFailureCodes[code]
+	}
+	alias, _ := ctx.Value(schemas.BifrostContextKeyFallbackChain).(string)
+	now := time.Now()
+	for i, objective := range tracker.config.Objectives {
+		if !objective.Matches(provider, model, alias) {
+			continue
+		}
+		slow := err == nil && objective.LatencyThresholdMs > 0 && latency.Milliseconds() > objective.LatencyThresholdMs
+		tracker.windows[i].record(now, failed, err == nil, slow)
+	}
+}
+
+// status returns the compliance of the i-th objective over the window
+func (t *sloTracker) status(i int, now time.Time) schemas.SLOStatus {
+	objective := t.config.Objectives[i]
+	totals := t.windows[i].totals(now)
+	status := schemas.SLOStatus{
+		Objective: objective,
+		Window:    t.config.GetWindow().String(),
+		Requests:  totals.requests,
+		Failures:  totals.failures,
+		Slow:      totals.slow,
+	}
+	threshold := t.config.GetBurnRateThreshold()
+	enoughRequests := totals.requests >= t.config.GetMinRequests()
+	if objective.Availability > 0 && totals.requests > 0 {
+		failureRate := float64(totals.failures) / float64(totals.requests)
+		burnRate := failureRate / (1 - objective.Availability)
+		status.AvailabilityCompliance = schemas.Ptr(1 - failureRate)
+		status.AvailabilityBurnRate = schemas.Ptr(burnRate)
+		status.AvailabilityBudgetRemaining = schemas.Ptr(1 - burnRate)
+		status.Burning = status.Burning || (enoughRequests && burnRate > threshold)
+	}
+	if objective.Latency > 0 && totals.successes > 0 {
+		slowRate := float64(totals.slow) / float64(totals.successes)
+		burnRate := slowRate / (1 - objective.Latency)
+		status.LatencyCompliance = schemas.Ptr(1 - slowRate)
+		status.LatencyBurnRate = schemas.Ptr(burnRate)
+		status.LatencyBudgetRemaining = schemas.Ptr(1 - burnRate)
+		status.Burning = status.Burning || (enoughRequests && burnRate > threshold)
+	}
+	return status
+}
+
+// burning reports whether the requests of the provider and model burn the budget of an objective they match
+func (t *sloTracker) burning(provider schemas.ModelProvider, model, alias string, now time.Time) bool {
+	for i, objective := range t.config.Objectives {
+		if objective.Matches(provider, model, alias) && t.status(i, now).Burning {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSLOStatus returns the compliance and error budget of each objective of the SLO config, in the order of the
+// config. It returns an empty list when no objective is tracked.
+func (bifrost *Bifrost) GetSLOStatus() []schemas.SLOStatus {
+	tracker := bifrost.slos.Load()
+	if tracker == nil {
+		return []schemas.SLOStatus{}
+	}
+	now := time.Now()
+	statuses := make([]schemas.SLOStatus, 0, len(tracker.windows))
+	for i := range tracker.windows {
+		statuses = append(statuses, tracker.status(i, now))
+	}
+	return statuses
+}
+
+// deprioritizeBurningHops moves the hops of a fallback chain burning the budget of an objective after the other hops,
+// keeping the order of both, when the SLO config feeds routing. The hops are kept as they are when all of them burn.
+func (bifrost *Bifrost) deprioritizeBurningHops(hops []schemas.FallbackHop, alias string) []schemas.FallbackHop {
+	tracker := bifrost.slos.Load()
+	if tracker == nil || !tracker.config.Routing {
+		return hops
+	}
+	now := time.Now()
+	healthy := make([]schemas.FallbackHop, 0, len(hops))
+	var burning []schemas.FallbackHop
+	for _, hop := range hops {
+		if tracker.burning(hop.Provider, hop.Model, alias, now) {
+			burning = append(burning, hop)
+		} else {
+			healthy = append(healthy, hop)
+		}
+	}
+	if len(burning) == 0 || len(healthy) == 0 {
+		return hops
+	}
+	bifrost.logger.Debug(fmt.Sprintf("fallback chain %s: %d hops burning their error budget tried last", alias, len(burning)))
+	return append(healthy, burning...)
+}
@@ -0,0 +1,81 @@
+package bifrost
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestSLOStatus tests that the failures of the provider and the slow requests burn the budgets of the objectives they
+// match, that client errors and cancellations do not, and that the counts survive a reload of the same objectives
+func TestSLOStatus(t *testing.T) {
+	bifrost := &Bifrost{logger: NewDefaultLogger(schemas.LogLevelError)}
+	config := &schemas.SLOConfig{
+		MinRequests: 10,
+		Objectives: []schemas.SLOObjective{
+			{Name: "openai", Provider: schemas.OpenAI, Availability: 0.9, LatencyThresholdMs: 1000, Latency: 0.5},
+			{Name: "anthropic", Provider: schemas.Anthropic, Availability: 0.9},
+		},
+	}
+	bifrost.updateSLOs(config)
+
+	ctx := context.Background()
+	for range 6 {
+		bifrost.recordSLO(ctx, schemas.OpenAI, "gpt-4o", 100*time.Millisecond, nil)
+	}
+	for range 2 {
+		bifrost.recordSLO(ctx, schemas.OpenAI, "gpt-4o", 2*time.Second, nil)
+	}
+	bifrost.recordSLO(ctx, schemas.OpenAI, "gpt-4o", time.Second, &schemas.BifrostError{StatusCode: schemas.Ptr(503), Error: &schemas.ErrorField{Message: "unavailable"}})
+	bifrost.recordSLO(ctx, schemas.OpenAI, "gpt-4o", time.Second, &schemas.BifrostError{StatusCode: schemas.Ptr(400), Error: &schemas.ErrorField{Message: "bad request"}})
+	bifrost.recordSLO(ctx, schemas.OpenAI, "gpt-4o", time.Second, &schemas.BifrostError{ErrorCode: schemas.ErrorCodeCancelled, Error: &schemas.ErrorField{Message: "cancelled"}})
+
+	// Reloading the same objectives keeps their counts
+	bifrost.updateSLOs(&schemas.SLOConfig{MinRequests: 10, Objectives: config.Objectives})
+
+	statuses := bifrost.GetSLOStatus()
+	if len(statuses) != 2 {
+		t.Fatalf("expected the status of both objectives, got %+v", statuses)
+	}
+	openai := statuses[0]
+	if openai.Requests != 10 || openai.Failures != 1 || openai.Slow != 2 {
+		t.Fatalf("expected 10 requests with 1 failure and 2 slow requests, got %+v", openai)
+	}
+	if *openai.AvailabilityCompliance != 0.9 || *openai.AvailabilityBurnRate < 0.99 || *openai.AvailabilityBurnRate > 1.01 {
+		t.Errorf("expected 90%% availability burning the budget at a rate of 1, got %g and %g", *openai.AvailabilityCompliance, *openai.AvailabilityBurnRate)
+	}
+	if *openai.LatencyCompliance != 0.75 || openai.Burning {
+		t.Errorf("expected 75%% of the successful requests to be fast without burning, got %g (burning %t)", *openai.LatencyCompliance, openai.Burning)
+	}
+	if statuses[1].Requests != 0 || statuses[1].AvailabilityCompliance != nil {
+		t.Errorf("expected no request for anthropic, got %+v", statuses[1])
+	}
+}
+
+// TestDeprioritizeBurningHops tests that the hops burning their budget are tried last when the SLOs feed routing
+func TestDeprioritizeBurningHops(t *testing.T) {
+	bifrost := &Bifrost{logger: NewDefaultLogger(schemas.LogLevelError)}
+	bifrost.updateSLOs(&schemas.SLOConfig{
+		MinRequests: 5,
+		Routing:     true,
+		Objectives:  []schemas.SLOObjective{{Name: "openai", Provider: schemas.OpenAI, Availability: 0.99}},
+	})
+	for range 5 {
+		bifrost.recordSLO(context.Background(), schemas.OpenAI, "gpt-4o", time.Second, &schemas.BifrostError{StatusCode: schemas.Ptr(500), Error: &schemas.ErrorField{Message: "internal error"}})
+	}
+
+	hops := []schemas.FallbackHop{
+		{Provider: schemas.OpenAI, Model: "gpt-4o"},
+		{Provider: schemas.Azure, Model: "gpt-4o"},
+		{Provider: schemas.Anthropic, Model: "claude-sonnet-4-5"},
+	}
+	ordered := bifrost.deprioritizeBurningHops(hops, "gpt-4o")
+	if len(ordered) != 3 || ordered[0].Provider != schemas.Azure || ordered[1].Provider != schemas.Anthropic || ordered[2].Provider != schemas.OpenAI {
+		t.Errorf("expected the openai hop to be tried last, got %+v", ordered)
+	}
+	if only := bifrost.deprioritizeBurningHops(hops[:1], "gpt-4o"); only[0].Provider != schemas.OpenAI {
+		t.Errorf("expected the hops to be kept when all of them burn their budget")
+	}
+}
@@ -0,0 +1,339 @@
+package bifrost
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// streamBufferSize returns the number of chunks buffered per stream by the config
+func streamBufferSize(config *schemas.StreamBackpressureConfig) int {
+	if config.BufferSize > 0 {
+		return config.BufferSize
+	}
+	return schemas.DefaultStreamBufferSize
+}
+
+// newSlowConsumerError creates the error ending the stream of a consumer that does not keep up with its provider
+func newSlowConsumerError(provider schemas.ModelProvider, model string, requestType schemas.RequestType, reason string) *schemas.BifrostStream {
+	return &schemas.BifrostStream{BifrostError: &schemas.BifrostError{
+		IsBifrostError: true,
+		StatusCode:     schemas.Ptr(503),
+		Type:           schemas.Ptr("slow_consumer"),
+		AllowFallbacks: schemas.Ptr(false),
+		Error: &schemas.ErrorField{
+			Message: fmt.Sprintf("stream dropped because the client is not reading it fast enough: %s", reason),
+		},
+		ExtraFields: schemas.BifrostErrorExtraFields{
+			RequestType:    requestType,
+			Provider:       provider,
+			ModelRequested: model,
+		},
+	}}
+}
+
+// spilledChunk is the JSON line of a chunk in the spill file of a stream.
+// The variants are named, since BifrostStream embeds them and cannot be unmarshaled.
+type spilledChunk struct {
+	TextCompletion *schemas.BifrostTextCompletionResponse      `json:"text_completion,omitempty"`
+	Chat           *schemas.BifrostChatResponse                `json:"chat,omitempty"`
+	Responses      *schemas.BifrostResponsesStreamResponse     `json:"responses,omitempty"`
+	Speech         *schemas.BifrostSpeechStreamResponse        `json:"speech,omitempty"`
+	Transcription  *schemas.BifrostTranscriptionStreamResponse `json:"transcription,omitempty"`
+	Error          *schemas.BifrostError                       `json:"error,omitempty"`
+}
+
+// streamSpill is the on-disk queue of the chunks a slow consumer did not read yet.
+// The file is truncated whenever the consumer catches up, so it only holds the backlog.
+type streamSpill struct {
+	writer  *os.File
+	reader  *os.File
+	buf     *bufio.Reader
+	pending int   // chunks written and not read yet
+	size    int64 // bytes written since the last truncation
+	maxSize int64
+}
+
+// newStreamSpill creates the spill file of a stream in dir, the temporary directory if empty
+func newStreamSpill(dir string, maxSize int64) (*streamSpill, error) {
+	writer, err := os.CreateTemp(dir, "bifrost-stream-*.jsonl")
+	if err != nil {
+		return nil, err
+	}
+	reader, err := os.Open(writer.Name())
+	if err != nil {
+		writer.Close()
+		os.Remove(writer.Name())
+		return nil, err
+	}
+	return &streamSpill{writer: writer, reader: reader, buf: bufio.NewReader(reader), maxSize: maxSize}, nil
+}
+
+// push appends a chunk to the spill file, it fails when the file would exceed its maximum size
+func (s *streamSpill) push(chunk *schemas.BifrostStream) error {
+	data, err := json.Marshal(spilledChunk{
+		TextCompletion: chunk.BifrostTextCompletionResponse,
+		Chat:           chunk.BifrostChatResponse,
+		Responses:      chunk.BifrostResponsesStreamResponse,
+		Speech:         chunk.BifrostSpeechStreamResponse,
+		Transcription:  chunk.BifrostTranscriptionStreamResponse,
+		Error:          chunk.BifrostError,
+	})
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if s.size+int64(len(data)) > s.maxSize {
+		return fmt.Errorf("spill file exceeds %d bytes", s.maxSize)
+	}
+	if _, err := s.writer.Write(data); err != nil {
+		return err
+	}
+	s.size += int64(len(data))
+	s.pending++
+	return nil
+}
+
+// pop reads the oldest chunk of the spill file
+func (s *streamSpill) pop() (*schemas.BifrostStream, error) {
+	line, err := s.buf.ReadBytes('\n')
+	if err != nil {
+		return nil, err
+	}
+	var spilled spilledChunk
+	if err := json.Unmarshal(line, &spilled); err != nil {
+		return nil, err
+	}
+	s.pending--
+	if s.pending == 0 {
+		if err := s.reset(); err != nil {
+			return nil, err
+		}
+	}
+	return &schemas.BifrostStream{
+		BifrostTextCompletionResponse:      spilled.TextCompletion,
+		BifrostChatResponse:                spilled.Chat,
+		BifrostResponsesStreamResponse:     spilled.Responses,
+		BifrostSpeechStreamResponse:        spilled.Speech,
+		BifrostTranscriptionStreamResponse: spilled.Transcription,
+		BifrostError:                       spilled.Error,
+	}, nil
+}
+
+// reset truncates the spill file once all its chunks were read
+func (s *streamSpill) reset() error {
+	if err := s.writer.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := s.writer.Seek(0, 0); err != nil {
+		return err
+	}
+	if _, err := s.reader.Seek(0, 0); err != nil {
+		return err
+	}
+	s.buf.Reset(s.reader)
+	s.size = 0
+	return nil
+}
+
+// close closes and removes the spill file
+func (s *streamSpill) close() {
+	s.reader.Close()
+	s.writer.Close()
+	os.Remove(s.writer.Name())
+}
+
+// streamBackpressure forwards the chunks of a provider stream to its consumer through a bounded buffer,
+// and applies the policy of the config when the consumer does not keep up.
+type streamBackpressure struct {
+	bifrost     *Bifrost
+	config      *schemas.StreamBackpressureConfig
+	ctx         context.Context
+	cancel      context.CancelFunc // cancels the upstream request when the stream is dropped
+	in          chan *schemas.BifrostStream
+	out         chan *schemas.BifrostStream
+	timeout     time.Duration
+	provider    schemas.ModelProvider
+	model       string
+	requestType schemas.RequestType
+	slow        bool // whether the consumer was reported as slow
+}
+
+// withStreamBackpressure returns the stream read by the consumer, buffering at most the configured number of chunks
+// in memory. With the block policy, the provider is not read while the buffer is full. With the drop policy, a stream
+// whose buffer stays full for the slow consumer timeout is cancelled and ended with an error. With the disk policy,
+// the chunks that do not fit in the buffer are spilled to a temporary file until it reaches its maximum size, the
+// stream is then dropped. cancel is called once the stream ends.
+func (bifrost *Bifrost) withStreamBackpressure(ctx context.Context, cancel context.CancelFunc, req *schemas.BifrostRequest, stream chan *schemas.BifrostStream, config *schemas.StreamBackpressureConfig) chan *schemas.BifrostStream {
+	provider, model, _ := req.GetRequestFields()
+	b := &streamBackpressure{
+		bifrost:     bifrost,
+		config:      config,
+		ctx:         ctx,
+		cancel:      cancel,
+		in:          stream,
+		out:         make(chan *schemas.BifrostStream, streamBufferSize(config)),
+		timeout:     time.Duration(config.SlowConsumerTimeoutMs) * time.Millisecond,
+		provider:    provider,
+		model:       model,
+		requestType: req.RequestType,
+	}
+	if b.timeout == 0 {
+		b.timeout = schemas.DefaultStreamSlowConsumerTimeoutMs * time.Millisecond
+	}
+	go func() {
+		defer close(b.out)
+		defer cancel()
+		if config.Policy == schemas.StreamBackpressureDisk {
+			b.runWithSpill()
+		} else {
+			b.run()
+		}
+	}()
+	return b.out
+}
+
+// run forwards the chunks with the block or drop policy
+func (b *streamBackpressure) run() {
+	timer := time.NewTimer(b.timeout)
+	timer.Stop()
+	defer timer.Stop()
+
+	for chunk := range b.in {
+		select {
+		case b.out <- chunk:
+			continue
+		default:
+		}
+
+		// The buffer is full, wait for the consumer up to the slow consumer timeout
+		timer.Reset(b.timeout)
+		select {
+		case b.out <- chunk:
+			timer.Stop()
+			continue
+		case <-b.ctx.Done():
+			b.drainUpstream()
+			return
+		case <-timer.C:
+		}
+
+		if b.config.Policy == schemas.StreamBackpressureDrop {
+			b.drop(fmt.Sprintf("buffer of %d chunks full for %s", cap(b.out), b.timeout))
+			return
+		}
+		b.reportSlowConsumer("blocking the provider until it catches up")
+		select {
+		case b.out <- chunk:
+		case <-b.ctx.Done():
+			b.drainUpstream()
+			return
+		}
+	}
+}
+
+// runWithSpill forwards the chunks with the disk policy
+func (b *streamBackpressure) runWithSpill() {
+	var spill *streamSpill
+	defer func() {
+		if spill != nil {
+			spill.close()
+		}
+	}()
+
+	in := b.in
+	var head *schemas.BifrostStream // oldest spilled chunk, sent before anything else
+	for in != nil || head != nil || (spill != nil && spill.pending > 0) {
+		if head == nil && spill != nil && spill.pending > 0 {
+			var err error
+			if head, err = spill.pop(); err != nil {
+				b.drop(fmt.Sprintf("failed to read spill file: %v", err))
+				return
+			}
+		}
+		var out chan *schemas.BifrostStream
+		if head != nil {
+			out = b.out
+		}
+
+		select {
+		case chunk, ok := <-in:
+			if !ok {
+				in = nil
+				continue
+			}
+			if head == nil {
+				// Nothing is spilled, the chunk goes to the buffer if it fits
+				select {
+				case b.out <- chunk:
+					continue
+				default:
+				}
+			}
+			if spill == nil {
+				var err error
+				if spill, err = newStreamSpill(b.config.DiskDir, b.maxDiskBytes()); err != nil {
+					b.drop(fmt.Sprintf("failed to create spill file: %v", err))
+					return
+				}
+				b.reportSlowConsumer(fmt.Sprintf("spilling chunks to %s", spill.writer.Name()))
+			}
+			if err := spill.push(chunk); err != nil {
+				b.drop(err.Error())
+				return
+			}
+		case out <- head:
+			head = nil
+		case <-b.ctx.Done():
+			b.drainUpstream()
+			return
+		}
+	}
+}
+
+// maxDiskBytes returns the maximum size of the spill file of the stream
+func (b *streamBackpressure) maxDiskBytes() int64 {
+	if b.config.MaxDiskBytes > 0 {
+		return b.config.MaxDiskBytes
+	}
+	return schemas.DefaultStreamMaxDiskBytes
+}
+
+// reportSlowConsumer logs the first time the consumer does not keep up
+func (b *streamBackpressure) reportSlowConsumer(action string) {
+	if b.slow {
+		return
+	}
+	b.slow = true
+	requestID, _ := b.ctx.Value(schemas.BifrostContextKeyRequestID).(string)
+	b.bifrost.logger.Warn(fmt.Sprintf("slow consumer on stream %s of provider %s with model %s, %s", requestID, b.provider, b.model, action))
+}
+
+// drop cancels the upstream request, discards the buffered chunks and ends the stream with an error
+func (b *streamBackpressure) drop(reason string) {
+	requestID, _ := b.ctx.Value(schemas.BifrostContextKeyRequestID).(string)
+	b.bifrost.logger.Warn(fmt.Sprintf("dropping stream %s of provider %s with model %s: %s", requestID, b.provider, b.model, reason))
+	b.cancel()
+	b.drainUpstream()
+	for len(b.out) > 0 {
+		select {
+		case chunk := <-b.out:
+			schemas.ReleaseBifrostStream(chunk)
+		default:
+		}
+	}
+	// The buffer is empty and this goroutine is its only writer, the error cannot block
+	b.out <- newSlowConsumerError(b.provider, b.model, b.requestType, reason)
+}
+
+// drainUpstream discards the chunks left in the provider stream so that its goroutine can exit
+func (b *streamBackpressure) drainUpstream() {
+	for chunk := range b.in {
+		schemas.ReleaseBifrostStream(chunk)
+	}
+}
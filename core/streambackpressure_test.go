@@ -0,0 +1,96 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// backpressureTestStream creates a closed provider stream of chat chunks numbered from 0
+func backpressureTestStream(chunks int) chan *schemas.BifrostStream {
+	stream := make(chan *schemas.BifrostStream, chunks)
+	for i := 0; i < chunks; i++ {
+		content := fmt.Sprintf("chunk %d", i)
+		stream <- &schemas.BifrostStream{BifrostChatResponse: &schemas.BifrostChatResponse{
+			Choices: []schemas.BifrostResponseChoice{{
+				ChatStreamResponseChoice: &schemas.ChatStreamResponseChoice{Delta: &schemas.ChatStreamResponseChoiceDelta{Content: &content}},
+			}},
+		}}
+	}
+	close(stream)
+	return stream
+}
+
+// backpressureTestRequest creates the chat stream request of the backpressure tests
+func backpressureTestRequest() *schemas.BifrostRequest {
+	return &schemas.BifrostRequest{
+		RequestType: schemas.ChatCompletionStreamRequest,
+		ChatRequest: &schemas.BifrostChatRequest{Provider: schemas.OpenAI, Model: "gpt-4o"},
+	}
+}
+
+// TestStreamBackpressure_DropsSlowConsumer tests that a stream whose buffer stays full is cancelled and ended with an error
+func TestStreamBackpressure_DropsSlowConsumer(t *testing.T) {
+	bifrost := &Bifrost{logger: NewDefaultLogger(schemas.LogLevelError)}
+	ctx, cancel := context.WithCancel(context.Background())
+	config := &schemas.StreamBackpressureConfig{BufferSize: 1, Policy: schemas.StreamBackpressureDrop, SlowConsumerTimeoutMs: 10}
+
+	out := bifrost.withStreamBackpressure(ctx, cancel, backpressureTestRequest(), backpressureTestStream(5), config)
+	time.Sleep(50 * time.Millisecond)
+
+	var received []*schemas.BifrostStream
+	for chunk := range out {
+		received = append(received, chunk)
+	}
+	if len(received) != 1 || received[0].BifrostError == nil || *received[0].BifrostError.Type != "slow_consumer" {
+		t.Fatalf("expected the buffered chunks to be replaced by a slow consumer error, got %d chunks", len(received))
+	}
+	if ctx.Err() == nil {
+		t.Error("expected the upstream request to be cancelled")
+	}
+}
+
+// TestStreamBackpressure_SpillsToDisk tests that the chunks exceeding the buffer are spilled and delivered in order
+func TestStreamBackpressure_SpillsToDisk(t *testing.T) {
+	bifrost := &Bifrost{logger: NewDefaultLogger(schemas.LogLevelError)}
+	ctx, cancel := context.WithCancel(context.Background())
+	config := &schemas.StreamBackpressureConfig{BufferSize: 1, Policy: schemas.StreamBackpressureDisk, DiskDir: t.TempDir()}
+
+	out := bifrost.withStreamBackpressure(ctx, cancel, backpressureTestRequest(), backpressureTestStream(20), config)
+	time.Sleep(20 * time.Millisecond)
+
+	i := 0
+	for chunk := range out {
+		if chunk.BifrostChatResponse == nil {
+			t.Fatalf("expected chunk %d, got an error", i)
+		}
+		if content := *chunk.BifrostChatResponse.Choices[0].Delta.Content; content != fmt.Sprintf("chunk %d", i) {
+			t.Fatalf("expected chunk %d, got %q", i, content)
+		}
+		i++
+	}
+	if i != 20 {
+		t.Errorf("expected 20 chunks, got %d", i)
+	}
+}
+
+// TestStreamBackpressure_DropsWhenSpillIsFull tests that the disk policy drops streams exceeding the spill size
+func TestStreamBackpressure_DropsWhenSpillIsFull(t *testing.T) {
+	bifrost := &Bifrost{logger: NewDefaultLogger(schemas.LogLevelError)}
+	ctx, cancel := context.WithCancel(context.Background())
+	config := &schemas.StreamBackpressureConfig{BufferSize: 1, Policy: schemas.StreamBackpressureDisk, DiskDir: t.TempDir(), MaxDiskBytes: 256}
+
+	out := bifrost.withStreamBackpressure(ctx, cancel, backpressureTestRequest(), backpressureTestStream(20), config)
+	time.Sleep(20 * time.Millisecond)
+
+	var last *schemas.BifrostStream
+	for chunk := range out {
+		last = chunk
+	}
+	if last == nil || last.BifrostError == nil || *last.BifrostError.Type != "slow_consumer" {
+		t.Fatal("expected the stream to end with a slow consumer error")
+	}
+}
@@ -0,0 +1,163 @@
+package bifrost
+
+import (
+	"context"
+	"time"
+
+	providerUtils "github.com/maximhq/bifrost/core/providers/utils"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// streamChunkHookTimeout bounds the plugin context of the chunk hooks of a stream
+const streamChunkHookTimeout = 30 * time.Minute
+
+// streamChunkHooks runs the chunk hooks of the plugins on the chunks of a stream, each plugin with its own state
+type streamChunkHooks struct {
+	ctx     *schemas.BifrostContext
+	plugins []schemas.StreamChunkPlugin
+	states  []*schemas.StreamChunkState
+	logger  schemas.Logger
+}
+
+// streamChunkPlugins returns the plugins implementing chunk hooks, in the order of the PostHooks
+func streamChunkPlugins(plugins []schemas.Plugin) []schemas.StreamChunkPlugin {
+	var chunkPlugins []schemas.StreamChunkPlugin
+	for i := len(plugins) - 1; i >= 0; i-- {
+		if plugin, ok := plugins[i].(schemas.StreamChunkPlugin); ok {
+			chunkPlugins = append(chunkPlugins, plugin)
+		}
+	}
+	return chunkPlugins
+}
+
+// newStreamChunkHooks returns the chunk hooks of a stream of the request
+func newStreamChunkHooks(ctx *schemas.BifrostContext, plugins []schemas.StreamChunkPlugin, req *schemas.BifrostRequest, logger schemas.Logger) *streamChunkHooks {
+	provider, model, _ := req.GetRequestFields()
+	hooks := &streamChunkHooks{ctx: ctx, plugins: plugins, logger: logger}
+	for range plugins {
+		hooks.states = append(hooks.states, &schemas.StreamChunkState{
+			RequestType: req.RequestType,
+			Provider:    provider,
+			Model:       model,
+			Values:      make(map[string]any),
+		})
+	}
+	return hooks
+}
+
+// run passes the chunks through the hooks of the plugins from the given one, the chunks returned by a plugin are
+// passed to the next one
+func (h *streamChunkHooks) run(from int, chunks []*schemas.BifrostStream) []*schemas.BifrostStream {
+	for i := from; i < len(h.plugins) && len(chunks) > 0; i++ {
+		var next []*schemas.BifrostStream
+		for _, chunk := range chunks {
+			next = append(next, h.call(i, chunk)...)
+		}
+		chunks = next
+	}
+	return chunks
+}
+
+// flush calls the hooks of the plugins a last time once the stream ended, in order, so that the chunks a plugin
+// releases still go through the plugins after it
+func (h *streamChunkHooks) flush() []*schemas.BifrostStream {
+	var chunks []*schemas.BifrostStream
+	for i := range h.plugins {
+		h.states[i].Done = true
+		chunks = append(chunks, h.run(i+1, h.call(i, nil))...)
+	}
+	return chunks
+}
+
+// call runs the chunk hook of a plugin, the chunk is sent as it is when the hook fails
+func (h *streamChunkHooks) call(i int, chunk *schemas.BifrostStream) []*schemas.BifrostStream {
+	state := h.states[i]
+	if chunk != nil {
+		state.Text += streamChunkText(chunk)
+	}
+	chunks, err := h.plugins[i].StreamChunkHook(h.ctx, chunk, state)
+	if chunk != nil {
+		state.ChunkIndex++
+	}
+	if err != nil {
+		h.logger.Warn("error in StreamChunkHook for plugin %s: %v", h.plugins[i].GetName(), err)
+		if chunk == nil {
+			return nil
+		}
+		return []*schemas.BifrostStream{chunk}
+	}
+	return chunks
+}
+
+// streamChunkText returns the text streamed by the first choice or the output text of a chunk
+func streamChunkText(chunk *schemas.BifrostStream) string {
+	var choices []schemas.BifrostResponseChoice
+	switch {
+	case chunk.BifrostChatResponse != nil:
+		choices = chunk.BifrostChatResponse.Choices
+	case chunk.BifrostTextCompletionResponse != nil:
+		choices = chunk.BifrostTextCompletionResponse.Choices
+	case chunk.BifrostResponsesStreamResponse != nil:
+		if chunk.BifrostResponsesStreamResponse.Type == schemas.ResponsesStreamResponseTypeOutputTextDelta && chunk.BifrostResponsesStreamResponse.Delta != nil {
+			return *chunk.BifrostResponsesStreamResponse.Delta
+		}
+		return ""
+	}
+	for _, choice := range choices {
+		if choice.Index != 0 {
+			continue
+		}
+		if choice.TextCompletionResponseChoice != nil && choice.Text != nil {
+			return *choice.Text
+		}
+		if choice.ChatStreamResponseChoice != nil && choice.Delta != nil && choice.Delta.Content != nil {
+			return *choice.Delta.Content
+		}
+	}
+	return ""
+}
+
+// withStreamChunkHooks passes the chunks of the stream through the chunk hooks of the plugins, the stream is returned as
+// it is when no plugin has chunk hooks
+func (bifrost *Bifrost) withStreamChunkHooks(ctx context.Context, req *schemas.BifrostRequest, stream chan *schemas.BifrostStream) chan *schemas.BifrostStream {
+	plugins := streamChunkPlugins(*bifrost.plugins.Load())
+	if len(plugins) == 0 {
+		return stream
+	}
+	pluginCtx := schemas.NewBifrostContext(ctx, time.Now().Add(streamChunkHookTimeout))
+	hooks := newStreamChunkHooks(pluginCtx, plugins, req, bifrost.logger)
+
+	out := make(chan *schemas.BifrostStream, providerUtils.GetStreamBufferSize(ctx))
+	go func() {
+		defer close(out)
+		defer pluginCtx.Cancel()
+
+		send := func(chunks []*schemas.BifrostStream) bool {
+			for _, chunk := range chunks {
+				if chunk == nil {
+					continue
+				}
+				select {
+				case out <- chunk:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		}
+
+		for chunk := range stream {
+			if chunk == nil {
+				continue
+			}
+			if !send(hooks.run(0, []*schemas.BifrostStream{chunk})) {
+				// The client is gone, drain the upstream stream so that its provider is not blocked
+				for range stream {
+				}
+				return
+			}
+		}
+		send(hooks.flush())
+	}()
+	return out
+}
@@ -0,0 +1,77 @@
+package bifrost
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// holdBackPlugin holds every chunk back and releases the streamed text in a single chunk once the stream ended
+type holdBackPlugin struct {
+	slowPlugin
+}
+
+func (p *holdBackPlugin) StreamChunkHook(ctx *schemas.BifrostContext, chunk *schemas.BifrostStream, state *schemas.StreamChunkState) ([]*schemas.BifrostStream, error) {
+	if !state.Done {
+		return nil, nil
+	}
+	return []*schemas.BifrostStream{newTextChunk(state.Text)}, nil
+}
+
+// upperPlugin upper-cases the streamed text
+type upperPlugin struct {
+	slowPlugin
+	text string
+}
+
+func (p *upperPlugin) StreamChunkHook(ctx *schemas.BifrostContext, chunk *schemas.BifrostStream, state *schemas.StreamChunkState) ([]*schemas.BifrostStream, error) {
+	p.text = state.Text
+	if chunk == nil {
+		return nil, nil
+	}
+	return []*schemas.BifrostStream{newTextChunk(strings.ToUpper(streamChunkText(chunk)))}, nil
+}
+
+func newTextChunk(text string) *schemas.BifrostStream {
+	return &schemas.BifrostStream{BifrostChatResponse: &schemas.BifrostChatResponse{Choices: []schemas.BifrostResponseChoice{{
+		ChatStreamResponseChoice: &schemas.ChatStreamResponseChoice{Delta: &schemas.ChatStreamResponseChoiceDelta{Content: schemas.Ptr(text)}},
+	}}}}
+}
+
+// TestStreamChunkHooks tests that chunk hooks run in the order of the PostHooks, that held back chunks are released
+// once the stream ended and go through the next plugins, and that streams without chunk hooks are left as they are
+func TestStreamChunkHooks(t *testing.T) {
+	upper := &upperPlugin{slowPlugin: slowPlugin{name: "upper"}}
+	holdBack := &holdBackPlugin{slowPlugin: slowPlugin{name: "hold-back"}}
+	bifrost := &Bifrost{logger: NewDefaultLogger(schemas.LogLevelError)}
+	req := &schemas.BifrostRequest{RequestType: schemas.ChatCompletionStreamRequest, ChatRequest: &schemas.BifrostChatRequest{Provider: schemas.OpenAI, Model: "gpt-4o"}}
+
+	plainPlugins := []schemas.Plugin{&slowPlugin{name: "plain"}}
+	bifrost.plugins.Store(&plainPlugins)
+	stream := make(chan *schemas.BifrostStream)
+	if got := bifrost.withStreamChunkHooks(context.Background(), req, stream); got != stream {
+		t.Errorf("expected streams without chunk hooks to be returned as they are")
+	}
+
+	// PostHooks run in reverse order, so hold-back sees the chunks before upper
+	plugins := []schemas.Plugin{upper, holdBack}
+	bifrost.plugins.Store(&plugins)
+	stream = make(chan *schemas.BifrostStream, 3)
+	for _, text := range []string{"he", "ll", "o"} {
+		stream <- newTextChunk(text)
+	}
+	close(stream)
+
+	var chunks []string
+	for chunk := range bifrost.withStreamChunkHooks(context.Background(), req, stream) {
+		chunks = append(chunks, streamChunkText(chunk))
+	}
+	if len(chunks) != 1 || chunks[0] != "HELLO" {
+		t.Errorf("expected a single released chunk upper-cased, got %q", chunks)
+	}
+	if upper.text != "hello" {
+		t.Errorf("expected the state of the plugin to accumulate the text it received, got %q", upper.text)
+	}
+}
@@ -0,0 +1,253 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	providerUtils "github.com/maximhq/bifrost/core/providers/utils"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// isMidStreamFailure reports whether an error ending a stream is worth restarting the generation on a fallback:
+// a 5xx error of the provider or a lost connection. Cancellations and errors disallowing fallbacks are not.
+func isMidStreamFailure(err *schemas.BifrostError) bool {
+	if err == nil || err.Error == nil {
+		return false
+	}
+	if err.Error.Type != nil && *err.Error.Type == schemas.RequestCancelled {
+		return false
+	}
+	if err.AllowFallbacks != nil && !*err.AllowFallbacks {
+		return false
+	}
+	if err.StatusCode != nil {
+		return *err.StatusCode >= 500
+	}
+	// Errors reading the stream, e.g. connection reset, carry the underlying error without a status code
+	return err.Error.Error != nil
+}
+
+// streamPartialOutput accumulates the text generated by a stream before it fails
+type streamPartialOutput struct {
+	text      strings.Builder
+	resumable bool // false once the stream produced output that cannot be continued from text, e.g. tool calls or several choices
+}
+
+// add accumulates the text of a chunk
+func (p *streamPartialOutput) add(chunk *schemas.BifrostStream) {
+	var choices []schemas.BifrostResponseChoice
+	switch {
+	case chunk.BifrostChatResponse != nil:
+		choices = chunk.BifrostChatResponse.Choices
+	case chunk.BifrostTextCompletionResponse != nil:
+		choices = chunk.BifrostTextCompletionResponse.Choices
+	default:
+		return
+	}
+	for _, choice := range choices {
+		if choice.Index != 0 {
+			p.resumable = false
+			continue
+		}
+		if choice.TextCompletionResponseChoice != nil && choice.Text != nil {
+			p.text.WriteString(*choice.Text)
+		}
+		if choice.ChatStreamResponseChoice != nil && choice.Delta != nil {
+			if len(choice.Delta.ToolCalls) > 0 {
+				p.resumable = false
+			}
+			if choice.Delta.Content != nil {
+				p.text.WriteString(*choice.Delta.Content)
+			}
+		}
+	}
+}
+
+// streamContinuationRequest builds the request restarting a failed stream on a fallback, with the partial output
+// prepended as assistant context. It returns nil when the request cannot carry the partial output.
+func streamContinuationRequest(req *schemas.BifrostRequest, fallback schemas.Fallback, partial string) *schemas.BifrostRequest {
+	continuation := &schemas.BifrostRequest{RequestType: req.RequestType}
+	switch req.RequestType {
+	case schemas.ChatCompletionStreamRequest:
+		chatReq := *req.ChatRequest
+		chatReq.Provider = fallback.Provider
+		chatReq.Model = fallback.Model
+		chatReq.Fallbacks = nil
+		chatReq.RawRequestBody = nil
+		if partial != "" {
+			chatReq.Input = append(append(make([]schemas.ChatMessage, 0, len(req.ChatRequest.Input)+1), req.ChatRequest.Input...), schemas.ChatMessage{
+				Role:    schemas.ChatMessageRoleAssistant,
+				Content: &schemas.ChatMessageContent{ContentStr: &partial},
+			})
+		}
+		continuation.ChatRequest = &chatReq
+	case schemas.TextCompletionStreamRequest:
+		textReq := *req.TextCompletionRequest
+		textReq.Provider = fallback.Provider
+		textReq.Model = fallback.Model
+		textReq.Fallbacks = nil
+		textReq.RawRequestBody = nil
+		if partial != "" {
+			if textReq.Input == nil || textReq.Input.PromptStr == nil {
+				return nil
+			}
+			prompt := *textReq.Input.PromptStr + partial
+			textReq.Input = &schemas.TextCompletionInput{PromptStr: &prompt}
+		}
+		continuation.TextCompletionRequest = &textReq
+	default:
+		return nil
+	}
+	return continuation
+}
+
+// newStreamFailoverMarker creates the chunk telling the client that the stream continues on a fallback
+func newStreamFailoverMarker(requestType schemas.RequestType, failover *schemas.BifrostStreamFailover) *schemas.BifrostStream {
+	extraFields := schemas.BifrostResponseExtraFields{
+		RequestType:    requestType,
+		Provider:       failover.ToProvider,
+		ModelRequested: failover.ToModel,
+		StreamFailover: failover,
+	}
+	if requestType == schemas.TextCompletionStreamRequest {
+		return &schemas.BifrostStream{BifrostTextCompletionResponse: &schemas.BifrostTextCompletionResponse{
+			Object:      "text_completion",
+			Model:       failover.ToModel,
+			Choices:     []schemas.BifrostResponseChoice{},
+			ExtraFields: extraFields,
+		}}
+	}
+	return &schemas.BifrostStream{BifrostChatResponse: &schemas.BifrostChatResponse{
+		Object:      "chat.completion.chunk",
+		Model:       failover.ToModel,
+		Choices:     []schemas.BifrostResponseChoice{},
+		ExtraFields: extraFields,
+	}}
+}
+
+// withStreamFailover forwards the chunks of a chat or text completion stream served by the provider at the given
+// fallback index (0 for the primary). When the stream fails mid-generation, the generation is restarted on the next
+// fallbacks with the partial output prepended, after a marker chunk. Other streams are returned as they are.
+// The request is copied, since it is released once the stream is returned.
+func (bifrost *Bifrost) withStreamFailover(ctx context.Context, req *schemas.BifrostRequest, stream chan *schemas.BifrostStream, fallbackIndex int) chan *schemas.BifrostStream {
+	config := bifrost.streamFailover.Load()
+	if config == nil || !config.Enabled {
+		return stream
+	}
+	if req.RequestType != schemas.ChatCompletionStreamRequest && req.RequestType != schemas.TextCompletionStreamRequest {
+		return stream
+	}
+	provider, model, fallbacks := req.GetRequestFields()
+	if fallbackIndex >= len(fallbacks) {
+		return stream
+	}
+	if fallbackIndex > 0 {
+		provider, model = fallbacks[fallbackIndex-1].Provider, fallbacks[fallbackIndex-1].Model
+	}
+	original := &schemas.BifrostRequest{
+		RequestType:           req.RequestType,
+		ChatRequest:           req.ChatRequest,
+		TextCompletionRequest: req.TextCompletionRequest,
+	}
+	maxFailovers := config.MaxFailovers
+	if maxFailovers == 0 {
+		maxFailovers = len(fallbacks)
+	}
+
+	out := make(chan *schemas.BifrostStream, providerUtils.GetStreamBufferSize(ctx))
+	go func() {
+		defer close(out)
+
+		send := func(chunk *schemas.BifrostStream) bool {
+			select {
+			case out <- chunk:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		partial := &streamPartialOutput{resumable: true}
+		failovers := 0
+		current := stream
+		for {
+			var failure *schemas.BifrostStream
+			for chunk := range current {
+				if chunk == nil {
+					continue
+				}
+				if chunk.BifrostError != nil && partial.resumable && failovers < maxFailovers && fallbackIndex < len(fallbacks) &&
+					ctx.Err() == nil && isMidStreamFailure(chunk.BifrostError) {
+					failure = chunk
+					continue
+				}
+				partial.add(chunk)
+				if !send(chunk) {
+					// The client is gone, drain the upstream stream so that its provider is not blocked
+					for range current {
+					}
+					return
+				}
+			}
+			if failure == nil {
+				return
+			}
+
+			// Restart the generation on the next fallback accepting the continuation
+			var next chan *schemas.BifrostStream
+			for next == nil && fallbackIndex < len(fallbacks) {
+				fallback := fallbacks[fallbackIndex]
+				fallbackIndex++
+				continuation := streamContinuationRequest(original, fallback, partial.text.String())
+				if continuation == nil {
+					break
+				}
+				if _, err := bifrost.account.GetConfigForProvider(fallback.Provider); err != nil {
+					bifrost.logger.Warn(fmt.Sprintf("Config not found for provider %s, skipping stream failover: %v", fallback.Provider, err))
+					continue
+				}
+				hopCtx := context.WithValue(ctx, schemas.BifrostContextKeyFallbackIndex, fallbackIndex)
+				hopCtx = context.WithValue(hopCtx, schemas.BifrostContextKeyFallbackRequestID, uuid.New().String())
+				hopCtx = context.WithValue(hopCtx, schemas.BifrostContextKeyUseRawRequestBody, false)
+
+				hopReq := bifrost.getBifrostRequest()
+				hopReq.RequestType = continuation.RequestType
+				hopReq.ChatRequest = continuation.ChatRequest
+				hopReq.TextCompletionRequest = continuation.TextCompletionRequest
+				result, hopErr := bifrost.tryStreamRequest(hopCtx, hopReq)
+				bifrost.releaseBifrostRequest(hopReq)
+				if hopErr == nil {
+					next = result
+					failovers++
+					bifrost.logger.Debug(fmt.Sprintf("Stream of provider %s failed mid-generation, continuing on fallback provider %s with model %s", provider, fallback.Provider, fallback.Model))
+					if !send(newStreamFailoverMarker(original.RequestType, &schemas.BifrostStreamFailover{
+						FromProvider:      provider,
+						FromModel:         model,
+						ToProvider:        fallback.Provider,
+						ToModel:           fallback.Model,
+						Error:             failure.BifrostError.Error.Message,
+						PartialOutputSize: partial.text.Len(),
+					})) {
+						for range next {
+						}
+						return
+					}
+					provider, model = fallback.Provider, fallback.Model
+					break
+				}
+				if !bifrost.shouldContinueWithFallbacks(fallback, hopErr) {
+					break
+				}
+			}
+			if next == nil {
+				// No fallback could continue the stream, end it with the original error
+				send(failure)
+				return
+			}
+			current = next
+		}
+	}()
+	return out
+}
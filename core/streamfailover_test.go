@@ -0,0 +1,84 @@
+package bifrost
+
+import (
+	"errors"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestIsMidStreamFailure tests that only 5xx errors and lost connections restart streams on a fallback
+func TestIsMidStreamFailure(t *testing.T) {
+	status := func(code int) *int { return &code }
+	cancelled := schemas.RequestCancelled
+
+	tests := map[string]struct {
+		err      *schemas.BifrostError
+		expected bool
+	}{
+		"server error":      {err: &schemas.BifrostError{StatusCode: status(502), Error: &schemas.ErrorField{Message: "bad gateway"}}, expected: true},
+		"connection reset":  {err: &schemas.BifrostError{IsBifrostError: true, Error: &schemas.ErrorField{Message: "Error reading stream", Error: errors.New("connection reset by peer")}}, expected: true},
+		"client error":      {err: &schemas.BifrostError{StatusCode: status(400), Error: &schemas.ErrorField{Message: "bad request"}}, expected: false},
+		"cancelled":         {err: &schemas.BifrostError{Error: &schemas.ErrorField{Type: &cancelled, Error: errors.New("context canceled")}}, expected: false},
+		"fallbacks refused": {err: &schemas.BifrostError{StatusCode: status(500), AllowFallbacks: new(bool), Error: &schemas.ErrorField{Message: "blocked"}}, expected: false},
+		"provider message":  {err: &schemas.BifrostError{Error: &schemas.ErrorField{Message: "content filtered"}}, expected: false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := isMidStreamFailure(test.err); got != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+// TestStreamContinuationRequest tests that the partial output is prepended as assistant context on the fallback
+func TestStreamContinuationRequest(t *testing.T) {
+	question := "Tell me a story"
+	chunk := func(content string) *schemas.BifrostStream {
+		return &schemas.BifrostStream{BifrostChatResponse: &schemas.BifrostChatResponse{
+			Choices: []schemas.BifrostResponseChoice{{
+				ChatStreamResponseChoice: &schemas.ChatStreamResponseChoice{Delta: &schemas.ChatStreamResponseChoiceDelta{Content: &content}},
+			}},
+		}}
+	}
+	partial := &streamPartialOutput{resumable: true}
+	partial.add(chunk("Once upon "))
+	partial.add(chunk("a time"))
+
+	original := &schemas.BifrostRequest{
+		RequestType: schemas.ChatCompletionStreamRequest,
+		ChatRequest: &schemas.BifrostChatRequest{
+			Provider:  schemas.OpenAI,
+			Model:     "gpt-4o",
+			Input:     []schemas.ChatMessage{{Role: schemas.ChatMessageRoleUser, Content: &schemas.ChatMessageContent{ContentStr: &question}}},
+			Fallbacks: []schemas.Fallback{{Provider: schemas.Anthropic, Model: "claude-sonnet-4"}},
+		},
+	}
+	continuation := streamContinuationRequest(original, original.ChatRequest.Fallbacks[0], partial.text.String())
+	if continuation == nil || continuation.ChatRequest == nil {
+		t.Fatal("expected a continuation request")
+	}
+	chatReq := continuation.ChatRequest
+	if chatReq.Provider != schemas.Anthropic || chatReq.Model != "claude-sonnet-4" || chatReq.Fallbacks != nil {
+		t.Errorf("expected the continuation to target the fallback only, got %s/%s with %d fallbacks", chatReq.Provider, chatReq.Model, len(chatReq.Fallbacks))
+	}
+	if len(chatReq.Input) != 2 || chatReq.Input[1].Role != schemas.ChatMessageRoleAssistant || *chatReq.Input[1].Content.ContentStr != "Once upon a time" {
+		t.Fatalf("expected the partial output as last assistant message, got %+v", chatReq.Input)
+	}
+	if len(original.ChatRequest.Input) != 1 {
+		t.Errorf("expected the original request to be left untouched, got %d messages", len(original.ChatRequest.Input))
+	}
+
+	toolCallName := "get_weather"
+	partial.add(&schemas.BifrostStream{BifrostChatResponse: &schemas.BifrostChatResponse{
+		Choices: []schemas.BifrostResponseChoice{{
+			ChatStreamResponseChoice: &schemas.ChatStreamResponseChoice{Delta: &schemas.ChatStreamResponseChoiceDelta{
+				ToolCalls: []schemas.ChatAssistantMessageToolCall{{Function: schemas.ChatAssistantMessageToolCallFunction{Name: &toolCallName}}},
+			}},
+		}},
+	}})
+	if partial.resumable {
+		t.Error("expected streams with tool calls not to be resumable")
+	}
+}
@@ -0,0 +1,121 @@
+package bifrost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// structuredOutputValidationErrorType is the error type returned when a response does not match its json_schema
+const structuredOutputValidationErrorType = "structured_output_validation_error"
+
+// structuredOutputRetries returns the number of repair retries requested for structured outputs,
+// and whether server-side validation is enabled at all
+func structuredOutputRetries(ctx context.Context) (int, bool) {
+	retries, ok := ctx.Value(schemas.BifrostContextKeyStructuredOutputRetries).(int)
+	return retries, ok && retries >= 0
+}
+
+// validateStructuredOutput validates the content of a chat response against the json_schema response format
+// of the request. When it does not match, the request is retried with the invalid answer and the validation
+// error appended to the conversation, up to retries times, before failing with a 422 error.
+// Requests without a json_schema response format are returned unchanged.
+func (bifrost *Bifrost) validateStructuredOutput(ctx context.Context, req *schemas.BifrostChatRequest, response *schemas.BifrostChatResponse, retries int) (*schemas.BifrostChatResponse, *schemas.BifrostError) {
+	if req.Params == nil {
+		return response, nil
+	}
+	format := schemas.ParseResponseFormat(req.Params.ResponseFormat)
+	if format == nil || format.Type != schemas.ResponseFormatTypeJSONSchema || format.JSONSchema == nil || format.JSONSchema.Schema == nil {
+		return response, nil
+	}
+
+	repairReq := *req
+	repairReq.Input = append([]schemas.ChatMessage{}, req.Input...)
+	for attempt := 0; ; attempt++ {
+		content := structuredOutputContent(response)
+		validationErr := validateStructuredOutputContent(content, format.JSONSchema.Schema)
+		if validationErr == nil {
+			return response, nil
+		}
+		if attempt >= retries {
+			return nil, &schemas.BifrostError{
+				IsBifrostError: true,
+				StatusCode:     schemas.Ptr(http.StatusUnprocessableEntity),
+				Error: &schemas.ErrorField{
+					Type:    schemas.Ptr(structuredOutputValidationErrorType),
+					Message: fmt.Sprintf("response does not match the json schema after %d retries: %v", attempt, validationErr),
+					Error:   validationErr,
+				},
+				ExtraFields: schemas.BifrostErrorExtraFields{
+					RequestType:    schemas.ChatCompletionRequest,
+					Provider:       req.Provider,
+					ModelRequested: req.Model,
+				},
+			}
+		}
+
+		bifrost.logger.Debug("structured output did not match the json schema, retrying (%d/%d): %v", attempt+1, retries, validationErr)
+		repairReq.Input = append(repairReq.Input,
+			schemas.ChatMessage{
+				Role:    schemas.ChatMessageRoleAssistant,
+				Content: &schemas.ChatMessageContent{ContentStr: &content},
+			},
+			schemas.ChatMessage{
+				Role: schemas.ChatMessageRoleUser,
+				Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr(fmt.Sprintf(
+					"Your previous response does not match the required JSON schema: %v. Respond again with only the corrected JSON.", validationErr,
+				))},
+			},
+		)
+
+		bifrostReq := bifrost.getBifrostRequest()
+		bifrostReq.RequestType = schemas.ChatCompletionRequest
+		bifrostReq.ChatRequest = &repairReq
+		result, err := bifrost.handleRequest(ctx, bifrostReq)
+		if err != nil {
+			return nil, err
+		}
+		response = result.ChatResponse
+	}
+}
+
+// structuredOutputContent returns the text content of the first choice of a chat response
+func structuredOutputContent(response *schemas.BifrostChatResponse) string {
+	if response == nil || len(response.Choices) == 0 || response.Choices[0].ChatNonStreamResponseChoice == nil {
+		return ""
+	}
+	message := response.Choices[0].ChatNonStreamResponseChoice.Message
+	if message == nil || message.Content == nil {
+		return ""
+	}
+	if message.Content.ContentStr != nil {
+		return *message.Content.ContentStr
+	}
+	var builder strings.Builder
+	for _, block := range message.Content.ContentBlocks {
+		if block.Type == schemas.ChatContentBlockTypeText && block.Text != nil {
+			builder.WriteString(*block.Text)
+		}
+	}
+	return builder.String()
+}
+
+// validateStructuredOutputContent parses the content as JSON, tolerating a markdown code fence around it,
+// and validates it against the schema
+func validateStructuredOutputContent(content string, schema map[string]any) error {
+	content = strings.TrimSpace(content)
+	if fenced, ok := strings.CutPrefix(content, "```"); ok {
+		fenced = strings.TrimPrefix(fenced, "json")
+		content = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(fenced), "```"))
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(content), &value); err != nil {
+		return fmt.Errorf("response is not valid JSON: %w", err)
+	}
+	return schemas.ValidateJSONSchema(value, schema)
+}
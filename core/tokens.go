@@ -0,0 +1,415 @@
+package bifrost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"unicode"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+const (
+	tokensPerMessage   = 3   // role and delimiters added around every chat message
+	tokensPerName      = 1   // added by the name of a chat message
+	tokensReplyPriming = 3   // tokens priming the reply of the assistant
+	tokensPerImage     = 765 // a high detail 1024x1024 image, the most common size
+)
+
+// charsPerToken is the average length of the word pieces of each tokenizer family, larger vocabularies merge longer pieces
+var charsPerToken = map[schemas.TokenizerType]float64{
+	schemas.TokenizerO200k:  4.4,
+	schemas.TokenizerCL100k: 4.0,
+	schemas.TokenizerClaude: 3.7,
+	schemas.TokenizerLlama:  3.5,
+}
+
+// defaultContextWindows are the context windows of known model families, most specific prefixes first
+var defaultContextWindows = []struct {
+	prefix string
+	tokens int
+}{
+	{"gpt-5", 400000},
+	{"gpt-4.1", 1047576},
+	{"gpt-4o", 128000},
+	{"chatgpt-4o", 128000},
+	{"gpt-4-turbo", 128000},
+	{"gpt-4-32k", 32768},
+	{"gpt-4", 8192},
+	{"gpt-3.5-turbo", 16385},
+	{"o1-mini", 128000},
+	{"o1", 200000},
+	{"o3", 200000},
+	{"o4", 200000},
+	{"claude", 200000},
+	{"gemini-1.5-pro", 2097152},
+	{"gemini", 1048576},
+	{"llama-3.1", 131072},
+	{"llama-3.2", 131072},
+	{"llama-3.3", 131072},
+	{"llama-4", 131072},
+	{"mistral-large", 131072},
+	{"command-r", 128000},
+}
+
+// normalizedModelName strips the provider and vendor prefixes of a model, e.g. "us.anthropic.claude-3-5-sonnet" becomes "claude-3-5-sonnet"
+func normalizedModelName(model string) string {
+	name := strings.ToLower(model)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	for _, vendor := range []string{"anthropic.", "meta.", "mistral.", "cohere."} {
+		if i := strings.Index(name, vendor); i >= 0 {
+			name = name[i+len(vendor):]
+		}
+	}
+	return name
+}
+
+// tokenizerForModel returns the tokenizer family of the model
+func tokenizerForModel(provider schemas.ModelProvider, model string) schemas.TokenizerType {
+	name := normalizedModelName(model)
+	switch {
+	case provider == schemas.Anthropic || strings.HasPrefix(name, "claude"):
+		return schemas.TokenizerClaude
+	case strings.Contains(name, "llama"):
+		return schemas.TokenizerLlama
+	case strings.HasPrefix(name, "gpt-4o"), strings.HasPrefix(name, "chatgpt-4o"), strings.HasPrefix(name, "gpt-4.1"),
+		strings.HasPrefix(name, "gpt-5"), strings.HasPrefix(name, "o1"), strings.HasPrefix(name, "o3"), strings.HasPrefix(name, "o4"):
+		return schemas.TokenizerO200k
+	}
+	return schemas.TokenizerCL100k
+}
+
+// contextWindowForModel returns the context window of the model, from the configured ones first, 0 when it is unknown
+func contextWindowForModel(config *schemas.ContextWindowConfig, provider schemas.ModelProvider, model string) int {
+	if config != nil {
+		if window, ok := config.ContextWindows[string(provider)+"/"+model]; ok {
+			return window
+		}
+		if window, ok := config.ContextWindows[model]; ok {
+			return window
+		}
+	}
+	name := normalizedModelName(model)
+	for _, known := range defaultContextWindows {
+		if strings.HasPrefix(name, known.prefix) {
+			return known.tokens
+		}
+	}
+	return 0
+}
+
+// countTextTokens estimates the tokens of the text for the tokenizer family. The text is split the way BPE tokenizers
+// pre-tokenize it, into words with their leading space, digit groups, punctuation runs and line breaks, and every word
+// is counted as the word pieces of the average length of the family. Characters outside of the Latin alphabets are
+// counted as one token each.
+func countTextTokens(text string, tokenizer schemas.TokenizerType) int {
+	pieceLength := charsPerToken[tokenizer]
+	if pieceLength == 0 {
+		pieceLength = charsPerToken[schemas.TokenizerCL100k]
+	}
+	runes := []rune(text)
+	tokens := 0
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == ' ' && i+1 < len(runes) && unicode.IsLetter(runes[i+1]) && runes[i+1] < unicode.MaxLatin1:
+			// The space before a word belongs to it
+			i++
+		case unicode.IsLetter(r) && r < unicode.MaxLatin1:
+			start := i
+			for i < len(runes) && unicode.IsLetter(runes[i]) && runes[i] < unicode.MaxLatin1 {
+				i++
+			}
+			tokens += int(math.Max(1, math.Round(float64(i-start)/pieceLength)))
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+			if tokenizer == schemas.TokenizerLlama {
+				tokens += i - start // digits are split one by one
+			} else {
+				tokens += (i - start + 2) / 3 // digits are split in groups of up to 3
+			}
+		case unicode.IsSpace(r):
+			start := i
+			for i < len(runes) && unicode.IsSpace(runes[i]) {
+				i++
+			}
+			if strings.ContainsRune(string(runes[start:i]), '\n') || i-start > 1 {
+				tokens += (i - start + 3) / 4
+			}
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			start := i
+			for i < len(runes) && runes[i] == r {
+				i++
+			}
+			tokens += (i - start + 3) / 4
+		default:
+			tokens++
+			i++
+		}
+	}
+	return tokens
+}
+
+// countChatMessageTokens estimates the tokens of a chat message, including the tokens framing it
+func countChatMessageTokens(message *schemas.ChatMessage, tokenizer schemas.TokenizerType) int {
+	tokens := tokensPerMessage + countTextTokens(string(message.Role), tokenizer)
+	if message.Name != nil {
+		tokens += tokensPerName + countTextTokens(*message.Name, tokenizer)
+	}
+	if message.Content != nil {
+		if message.Content.ContentStr != nil {
+			tokens += countTextTokens(*message.Content.ContentStr, tokenizer)
+		}
+		for _, block := range message.Content.ContentBlocks {
+			switch {
+			case block.Text != nil:
+				tokens += countTextTokens(*block.Text, tokenizer)
+			case block.Refusal != nil:
+				tokens += countTextTokens(*block.Refusal, tokenizer)
+			case block.ImageURLStruct != nil:
+				tokens += tokensPerImage
+			}
+		}
+	}
+	if message.ChatAssistantMessage != nil {
+		for _, toolCall := range message.ChatAssistantMessage.ToolCalls {
+			if toolCall.Function.Name != nil {
+				tokens += countTextTokens(*toolCall.Function.Name, tokenizer)
+			}
+			tokens += countTextTokens(toolCall.Function.Arguments, tokenizer)
+		}
+	}
+	return tokens
+}
+
+// countResponsesMessageTokens estimates the tokens of a responses input item, including the tokens framing it
+func countResponsesMessageTokens(message *schemas.ResponsesMessage, tokenizer schemas.TokenizerType) int {
+	tokens := tokensPerMessage
+	if message.Role != nil {
+		tokens += countTextTokens(string(*message.Role), tokenizer)
+	}
+	if message.Content != nil {
+		if message.Content.ContentStr != nil {
+			tokens += countTextTokens(*message.Content.ContentStr, tokenizer)
+		}
+		for _, block := range message.Content.ContentBlocks {
+			switch {
+			case block.Text != nil:
+				tokens += countTextTokens(*block.Text, tokenizer)
+			case block.ResponsesInputMessageContentBlockImage != nil:
+				tokens += tokensPerImage
+			}
+		}
+	}
+	if message.ResponsesToolMessage != nil {
+		toolMessage := message.ResponsesToolMessage
+		if toolMessage.Name != nil {
+			tokens += countTextTokens(*toolMessage.Name, tokenizer)
+		}
+		if toolMessage.Arguments != nil {
+			tokens += countTextTokens(*toolMessage.Arguments, tokenizer)
+		}
+		if toolMessage.Output != nil && toolMessage.Output.ResponsesToolCallOutputStr != nil {
+			tokens += countTextTokens(*toolMessage.Output.ResponsesToolCallOutputStr, tokenizer)
+		}
+	}
+	return tokens
+}
+
+// countToolTokens estimates the tokens of tool definitions from their JSON form
+func countToolTokens(tools any, tokenizer schemas.TokenizerType) int {
+	data, err := json.Marshal(tools)
+	if err != nil {
+		return 0
+	}
+	return countTextTokens(string(data), tokenizer)
+}
+
+// CountTokens estimates the input tokens of messages or of a prompt for a model, without calling the provider,
+// and compares them to the context window of the model.
+// Parameters:
+//   - ctx: Context of the request
+//   - req: Messages, prompt and tools to count, and the model they are counted for
+//
+// Returns:
+//   - schemas.BifrostTokenCountResponse: Input tokens, tokenizer family and context window of the model
+//   - schemas.BifrostError: Error of an invalid request
+func (bifrost *Bifrost) CountTokens(ctx context.Context, req *schemas.BifrostTokenCountRequest) (*schemas.BifrostTokenCountResponse, *schemas.BifrostError) {
+	if req == nil || req.Model == "" || (len(req.Messages) == 0 && req.Prompt == nil) {
+		return nil, &schemas.BifrostError{
+			IsBifrostError: false,
+			StatusCode:     schemas.Ptr(http.StatusBadRequest),
+			Error: &schemas.ErrorField{
+				Message: "token count request requires a model and messages or a prompt",
+			},
+		}
+	}
+	tokenizer := tokenizerForModel(req.Provider, req.Model)
+	tokens := 0
+	for i := range req.Messages {
+		tokens += countChatMessageTokens(&req.Messages[i], tokenizer)
+	}
+	if len(req.Messages) > 0 {
+		tokens += tokensReplyPriming
+	}
+	if req.Prompt != nil {
+		tokens += countTextTokens(*req.Prompt, tokenizer)
+	}
+	if len(req.Tools) > 0 {
+		tokens += countToolTokens(req.Tools, tokenizer)
+	}
+	resp := &schemas.BifrostTokenCountResponse{
+		Provider:      req.Provider,
+		Model:         req.Model,
+		Tokenizer:     tokenizer,
+		InputTokens:   tokens,
+		ContextWindow: contextWindowForModel(bifrost.contextWindow.Load(), req.Provider, req.Model),
+	}
+	if resp.ContextWindow > 0 {
+		resp.RemainingTokens = schemas.Ptr(max(resp.ContextWindow-tokens, 0))
+		resp.ExceedsContextWindow = tokens > resp.ContextWindow
+	}
+	return resp, nil
+}
+
+// enforceContextWindow counts the input tokens of chat, responses and text completion requests, and the output tokens
+// they ask for, against the context window of their model. Requests that don't fit are rejected, or with the trim action
+// chat requests lose their oldest messages until they fit. System and developer messages and the last message are kept.
+// The returned request is a copy when messages were dropped.
+func (bifrost *Bifrost) enforceContextWindow(req *schemas.BifrostRequest) (*schemas.BifrostRequest, *schemas.BifrostError) {
+	config := bifrost.contextWindow.Load()
+	if config == nil {
+		return req, nil
+	}
+	provider, model, _ := req.GetRequestFields()
+	window := contextWindowForModel(config, provider, model)
+	if window <= 0 {
+		return req, nil
+	}
+	tokenizer := tokenizerForModel(provider, model)
+
+	tokens := 0
+	switch {
+	case req.ChatRequest != nil:
+		counts := make([]int, len(req.ChatRequest.Input))
+		tokens = tokensReplyPriming
+		for i := range req.ChatRequest.Input {
+			counts[i] = countChatMessageTokens(&req.ChatRequest.Input[i], tokenizer)
+			tokens += counts[i]
+		}
+		if params := req.ChatRequest.Params; params != nil {
+			if len(params.Tools) > 0 {
+				tokens += countToolTokens(params.Tools, tokenizer)
+			}
+			if params.MaxCompletionTokens != nil {
+				tokens += *params.MaxCompletionTokens
+			}
+		}
+		if tokens > window && config.Action == schemas.ContextWindowActionTrim {
+			if input, trimmed := trimChatMessages(req.ChatRequest.Input, counts, tokens-window); trimmed {
+				chatReq := *req.ChatRequest
+				chatReq.Input = input
+				trimmedReq := *req
+				trimmedReq.ChatRequest = &chatReq
+				return &trimmedReq, nil
+			}
+		}
+	case req.ResponsesRequest != nil:
+		for i := range req.ResponsesRequest.Input {
+			tokens += countResponsesMessageTokens(&req.ResponsesRequest.Input[i], tokenizer)
+		}
+		if params := req.ResponsesRequest.Params; params != nil {
+			if params.Instructions != nil {
+				tokens += countTextTokens(*params.Instructions, tokenizer)
+			}
+			if len(params.Tools) > 0 {
+				tokens += countToolTokens(params.Tools, tokenizer)
+			}
+			if params.MaxOutputTokens != nil {
+				tokens += *params.MaxOutputTokens
+			}
+		}
+	case req.TextCompletionRequest != nil:
+		if input := req.TextCompletionRequest.Input; input != nil {
+			if input.PromptStr != nil {
+				tokens += countTextTokens(*input.PromptStr, tokenizer)
+			}
+			for _, prompt := range input.PromptArray {
+				tokens = max(tokens, countTextTokens(prompt, tokenizer))
+			}
+		}
+		if params := req.TextCompletionRequest.Params; params != nil && params.MaxTokens != nil {
+			tokens += *params.MaxTokens
+		}
+	default:
+		return req, nil
+	}
+	if tokens <= window {
+		return req, nil
+	}
+	return nil, &schemas.BifrostError{
+		IsBifrostError: false,
+		StatusCode:     schemas.Ptr(http.StatusBadRequest),
+		Type:           schemas.Ptr("context_length_exceeded"),
+		Error: &schemas.ErrorField{
+			Message: fmt.Sprintf("request needs about %d tokens with its output, above the %d tokens context window of %s", tokens, window, model),
+		},
+	}
+}
+
+// trimChatMessages drops the oldest messages until excess tokens are freed, keeping the system and developer messages
+// and the last message, with the tool call it answers when it is a tool result. Tool results of a dropped assistant
+// message are dropped with it. It reports false when the messages can't be trimmed enough.
+func trimChatMessages(messages []schemas.ChatMessage, counts []int, excess int) ([]schemas.ChatMessage, bool) {
+	dropped, freed := selectOldestChatMessages(messages, counts, excess, 1)
+	if freed < excess {
+		return nil, false
+	}
+	return keptChatMessages(messages, dropped), true
+}
+
+// selectOldestChatMessages marks the oldest messages to drop until excess tokens are freed, keeping the system and
+// developer messages and the last keepRecent messages, extended back to the tool call answered by the first of them.
+// Tool results are marked with the assistant message calling them. It returns the marks and the tokens freed, which
+// are below excess when not enough messages can be dropped.
+func selectOldestChatMessages(messages []schemas.ChatMessage, counts []int, excess int, keepRecent int) ([]bool, int) {
+	keepFrom := max(len(messages)-max(keepRecent, 1), 0)
+	for keepFrom > 0 && messages[keepFrom].Role == schemas.ChatMessageRoleTool {
+		keepFrom--
+	}
+	dropped := make([]bool, len(messages))
+	freed := 0
+	for i := 0; i < keepFrom && freed < excess; i++ {
+		if messages[i].Role == schemas.ChatMessageRoleSystem || messages[i].Role == schemas.ChatMessageRoleDeveloper {
+			continue
+		}
+		dropped[i] = true
+		freed += counts[i]
+		// Tool results can't be sent without the tool calls they answer
+		for i+1 < keepFrom && messages[i+1].Role == schemas.ChatMessageRoleTool {
+			i++
+			dropped[i] = true
+			freed += counts[i]
+		}
+	}
+	return dropped, freed
+}
+
+// keptChatMessages returns the messages that are not marked as dropped
+func keptChatMessages(messages []schemas.ChatMessage, dropped []bool) []schemas.ChatMessage {
+	kept := make([]schemas.ChatMessage, 0, len(messages))
+	for i := range messages {
+		if !dropped[i] {
+			kept = append(kept, messages[i])
+		}
+	}
+	return kept
+}
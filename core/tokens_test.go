@@ -0,0 +1,116 @@
+package bifrost
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+func textMessage(role schemas.ChatMessageRole, text string) schemas.ChatMessage {
+	return schemas.ChatMessage{Role: role, Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr(text)}}
+}
+
+// TestCountTokens tests the tokenizer families and context windows of models, and that counts grow with the text
+func TestCountTokens(t *testing.T) {
+	tests := map[string]struct {
+		provider  schemas.ModelProvider
+		model     string
+		tokenizer schemas.TokenizerType
+		window    int
+	}{
+		"gpt-4o":          {schemas.OpenAI, "gpt-4o-mini", schemas.TokenizerO200k, 128000},
+		"gpt-4":           {schemas.OpenAI, "gpt-4-0613", schemas.TokenizerCL100k, 8192},
+		"bedrock claude":  {schemas.Bedrock, "us.anthropic.claude-3-5-sonnet-20241022-v2:0", schemas.TokenizerClaude, 200000},
+		"groq llama":      {schemas.Groq, "llama-3.3-70b-versatile", schemas.TokenizerLlama, 131072},
+		"unknown model":   {schemas.Ollama, "my-model", schemas.TokenizerCL100k, 0},
+		"configured size": {schemas.OpenAI, "ft:gpt-4o:acme", schemas.TokenizerCL100k, 1000},
+	}
+	bifrost := &Bifrost{}
+	bifrost.contextWindow.Store(&schemas.ContextWindowConfig{
+		Action:         schemas.ContextWindowActionReject,
+		ContextWindows: map[string]int{"openai/ft:gpt-4o:acme": 1000},
+	})
+	for name, test := range tests {
+		resp, err := bifrost.CountTokens(context.Background(), &schemas.BifrostTokenCountRequest{
+			Provider: test.provider,
+			Model:    test.model,
+			Messages: []schemas.ChatMessage{textMessage(schemas.ChatMessageRoleUser, "Hello, how are you today?")},
+		})
+		if err != nil {
+			t.Fatalf("%s: unexpected error %+v", name, err)
+		}
+		if resp.Tokenizer != test.tokenizer || resp.ContextWindow != test.window {
+			t.Errorf("%s: expected %s and a %d tokens window, got %s and %d", name, test.tokenizer, test.window, resp.Tokenizer, resp.ContextWindow)
+		}
+		// 7 pre-tokens, the role and 6 tokens framing the message and the reply
+		if resp.InputTokens < 10 || resp.InputTokens > 18 {
+			t.Errorf("%s: expected about 14 tokens, got %d", name, resp.InputTokens)
+		}
+	}
+
+	short := countTextTokens("The quick brown fox", schemas.TokenizerCL100k)
+	long := countTextTokens(strings.Repeat("The quick brown fox ", 100), schemas.TokenizerCL100k)
+	if short != 4 || long < 350 || long > 450 {
+		t.Errorf("expected 4 tokens and about 400 tokens, got %d and %d", short, long)
+	}
+	if digits := countTextTokens("1234567", schemas.TokenizerCL100k); digits != 3 {
+		t.Errorf("expected digits to be counted in groups of 3, got %d", digits)
+	}
+}
+
+// TestEnforceContextWindow tests that requests exceeding the context window are rejected, or trimmed of their oldest messages
+func TestEnforceContextWindow(t *testing.T) {
+	bifrost := &Bifrost{}
+	filler := strings.Repeat("lorem ipsum dolor sit amet ", 20) // about 100 tokens
+	input := []schemas.ChatMessage{
+		textMessage(schemas.ChatMessageRoleSystem, "Be brief."),
+		textMessage(schemas.ChatMessageRoleUser, filler),
+		toolCallMessage("call_1", "search", `{}`),
+		{Role: schemas.ChatMessageRoleTool, Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr(filler)}, ChatToolMessage: &schemas.ChatToolMessage{ToolCallID: schemas.Ptr("call_1")}},
+		textMessage(schemas.ChatMessageRoleAssistant, filler),
+		textMessage(schemas.ChatMessageRoleUser, "And now?"),
+	}
+	req := &schemas.BifrostRequest{
+		RequestType: schemas.ChatCompletionRequest,
+		ChatRequest: &schemas.BifrostChatRequest{Provider: schemas.OpenAI, Model: "small-model", Input: input},
+	}
+
+	// Requests are sent as they are without a config or with a large enough window
+	if checked, err := bifrost.enforceContextWindow(req); err != nil || checked != req {
+		t.Fatalf("expected the request to be sent as it is, got %+v", err)
+	}
+	bifrost.contextWindow.Store(&schemas.ContextWindowConfig{Action: schemas.ContextWindowActionReject, ContextWindows: map[string]int{"small-model": 1000}})
+	if checked, err := bifrost.enforceContextWindow(req); err != nil || checked != req {
+		t.Fatalf("expected the request to fit, got %+v", err)
+	}
+
+	bifrost.contextWindow.Store(&schemas.ContextWindowConfig{Action: schemas.ContextWindowActionReject, ContextWindows: map[string]int{"small-model": 200}})
+	if _, err := bifrost.enforceContextWindow(req); err == nil || *err.Type != "context_length_exceeded" {
+		t.Fatalf("expected the request to be rejected, got %+v", err)
+	}
+
+	// Trimming drops the first user message and the tool call with its result, keeping the system prompt
+	bifrost.contextWindow.Store(&schemas.ContextWindowConfig{Action: schemas.ContextWindowActionTrim, ContextWindows: map[string]int{"small-model": 200}})
+	trimmed, err := bifrost.enforceContextWindow(req)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+	roles := []schemas.ChatMessageRole{}
+	for _, message := range trimmed.ChatRequest.Input {
+		roles = append(roles, message.Role)
+	}
+	if len(roles) != 3 || roles[0] != schemas.ChatMessageRoleSystem || roles[1] != schemas.ChatMessageRoleAssistant || roles[2] != schemas.ChatMessageRoleUser {
+		t.Errorf("expected the system prompt and the last two messages to be kept, got %v", roles)
+	}
+	if len(req.ChatRequest.Input) != len(input) {
+		t.Error("the original request must not be modified")
+	}
+
+	// Requests that can't fit even with a single message are rejected
+	bifrost.contextWindow.Store(&schemas.ContextWindowConfig{Action: schemas.ContextWindowActionTrim, ContextWindows: map[string]int{"small-model": 10}})
+	if _, err := bifrost.enforceContextWindow(req); err == nil {
+		t.Error("expected the request to be rejected")
+	}
+}
@@ -0,0 +1,472 @@
+package bifrost
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// transformRuleSet is the set of configured transform rules in the order they apply
+type transformRuleSet struct {
+	rules []schemas.TransformRule
+}
+
+// newTransformRuleSet validates the rules and sorts them by priority and name
+func newTransformRuleSet(rules []schemas.TransformRule) (*transformRuleSet, error) {
+	if err := schemas.ValidateTransformRules(rules); err != nil {
+		return nil, err
+	}
+	sorted := slices.Clone(rules)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Priority != sorted[j].Priority {
+			return sorted[i].Priority < sorted[j].Priority
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return &transformRuleSet{rules: sorted}, nil
+}
+
+// ruleAction is a parameter action and the rule it belongs to, nil for the parameters of fallback hops
+type ruleAction struct {
+	rule   *schemas.TransformRule
+	action schemas.TransformAction
+}
+
+// parameterViolationError rejects a request whose parameter violates a rule with the reject enforcement
+type parameterViolationError struct {
+	violation schemas.BifrostParameterViolation
+}
+
+func (e *parameterViolationError) Error() string {
+	return fmt.Sprintf("%s value %v violates transform rule %s", e.violation.Path, e.violation.Requested, e.violation.Rule)
+}
+
+// match returns the rules attached to the virtual key of the request or to its requested model
+func (s *transformRuleSet) match(ctx context.Context, provider schemas.ModelProvider, model string) []*schemas.TransformRule {
+	vkID, _ := ctx.Value(schemas.BifrostContextKeyGovernanceVirtualKeyID).(string)
+	var matched []*schemas.TransformRule
+	for i := range s.rules {
+		rule := &s.rules[i]
+		if (vkID != "" && slices.Contains(rule.VirtualKeyIDs, vkID)) || slices.ContainsFunc(rule.Models, func(m string) bool {
+			return m == "*" || m == model || m == string(provider)+"/"+model
+		}) {
+			matched = append(matched, rule)
+		}
+	}
+	return matched
+}
+
+// UpdateTransformRules replaces the transform rules, an empty list sends requests as they are.
+// Returns an error and keeps the current rules if the rules are invalid.
+func (bifrost *Bifrost) UpdateTransformRules(rules []schemas.TransformRule) error {
+	if len(rules) == 0 {
+		bifrost.transforms.Store(nil)
+		return nil
+	}
+	set, err := newTransformRuleSet(rules)
+	if err != nil {
+		return err
+	}
+	bifrost.transforms.Store(set)
+	return nil
+}
+
+// applyTransformRules rewrites the request with the transform rules matching it, once the plugin PreHooks have run.
+// The request is copied before it is rewritten, so that fallbacks start from the request of the caller, and the names
+// of the applied rules and the parameters of the client they rewrote are returned. Returns the request itself when no
+// rule matches.
+func (bifrost *Bifrost) applyTransformRules(ctx context.Context, req *schemas.BifrostRequest) (*schemas.BifrostRequest, []string, []schemas.BifrostParameterViolation, *schemas.BifrostError) {
+	set := bifrost.transforms.Load()
+	if set == nil {
+		return req, nil, nil, nil
+	}
+	provider, model, _ := req.GetRequestFields()
+	rules := set.match(ctx, provider, model)
+	if len(rules) == 0 {
+		return req, nil, nil, nil
+	}
+
+	transformed := copyRequest(req)
+
+	names := make([]string, 0, len(rules))
+	var paramActions []ruleAction
+	for _, rule := range rules {
+		names = append(names, rule.Name)
+		for _, action := range rule.Actions {
+			switch action.Type {
+			case schemas.TransformActionRenameModel:
+				newProvider, newModel := schemas.ParseModelString(action.Value.(string), "")
+				if newProvider != "" {
+					transformed.SetProvider(newProvider)
+				}
+				transformed.SetModel(newModel)
+			case schemas.TransformActionInjectSystemPrompt:
+				injectSystemPrompt(&transformed, action.Value.(string))
+			default:
+				paramActions = append(paramActions, ruleAction{rule: rule, action: action})
+			}
+		}
+	}
+	var violations []schemas.BifrostParameterViolation
+	if len(paramActions) > 0 {
+		var err error
+		violations, err = transformRequestParams(&transformed, paramActions)
+		var violationErr *parameterViolationError
+		if errors.As(err, &violationErr) {
+			return nil, nil, nil, &schemas.BifrostError{
+				IsBifrostError: true,
+				StatusCode:     schemas.Ptr(http.StatusBadRequest),
+				Type:           schemas.Ptr("parameter_policy_violation"),
+				AllowFallbacks: schemas.Ptr(false),
+				Error: &schemas.ErrorField{
+					Message: violationErr.Error(),
+					Param:   strings.TrimPrefix(violationErr.violation.Path, "$."),
+					Error:   err,
+				},
+			}
+		}
+		if err != nil {
+			return nil, nil, nil, &schemas.BifrostError{
+				IsBifrostError: true,
+				StatusCode:     schemas.Ptr(http.StatusBadRequest),
+				Type:           schemas.Ptr("transform_rule_failed"),
+				AllowFallbacks: schemas.Ptr(false),
+				Error: &schemas.ErrorField{
+					Message: fmt.Sprintf("failed to apply transform rules %s: %v", strings.Join(names, ", "), err),
+					Error:   err,
+				},
+			}
+		}
+	}
+	return &transformed, names, violations, nil
+}
+
+// copyRequest returns a copy of the request and of its typed request, whose fields can be set without changing the request
+func copyRequest(req *schemas.BifrostRequest) schemas.BifrostRequest {
+	copied := *req
+	switch {
+	case req.TextCompletionRequest != nil:
+		textReq := *req.TextCompletionRequest
+		copied.TextCompletionRequest = &textReq
+	case req.ChatRequest != nil:
+		chatReq := *req.ChatRequest
+		copied.ChatRequest = &chatReq
+	case req.ResponsesRequest != nil:
+		responsesReq := *req.ResponsesRequest
+		copied.ResponsesRequest = &responsesReq
+	case req.EmbeddingRequest != nil:
+		embeddingReq := *req.EmbeddingRequest
+		copied.EmbeddingRequest = &embeddingReq
+	case req.SpeechRequest != nil:
+		speechReq := *req.SpeechRequest
+		copied.SpeechRequest = &speechReq
+	case req.TranscriptionRequest != nil:
+		transcriptionReq := *req.TranscriptionRequest
+		copied.TranscriptionRequest = &transcriptionReq
+	}
+	return copied
+}
+
+// injectSystemPrompt adds the prompt as a system message before the messages of chat requests,
+// and before the instructions of responses requests
+func injectSystemPrompt(req *schemas.BifrostRequest, prompt string) {
+	switch {
+	case req.ChatRequest != nil:
+		input := make([]schemas.ChatMessage, 0, len(req.ChatRequest.Input)+1)
+		input = append(input, schemas.ChatMessage{
+			Role:    schemas.ChatMessageRoleSystem,
+			Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr(prompt)},
+		})
+		req.ChatRequest.Input = append(input, req.ChatRequest.Input...)
+	case req.ResponsesRequest != nil:
+		params := schemas.ResponsesParameters{}
+		if req.ResponsesRequest.Params != nil {
+			params = *req.ResponsesRequest.Params
+		}
+		if params.Instructions != nil && *params.Instructions != "" {
+			prompt = prompt + "\n\n" + *params.Instructions
+		}
+		params.Instructions = &prompt
+		req.ResponsesRequest.Params = &params
+	}
+}
+
+// transformRequestParams applies the parameter actions to the parameters of the request, and returns the parameters
+// of the client violating clamp and set actions
+func transformRequestParams(req *schemas.BifrostRequest, actions []ruleAction) ([]schemas.BifrostParameterViolation, error) {
+	var violations []schemas.BifrostParameterViolation
+	var err error
+	switch {
+	case req.TextCompletionRequest != nil:
+		req.TextCompletionRequest.Params, violations, err = transformParams(req.TextCompletionRequest.Params, actions)
+	case req.ChatRequest != nil:
+		req.ChatRequest.Params, violations, err = transformParams(req.ChatRequest.Params, actions)
+	case req.ResponsesRequest != nil:
+		req.ResponsesRequest.Params, violations, err = transformParams(req.ResponsesRequest.Params, actions)
+	case req.EmbeddingRequest != nil:
+		req.EmbeddingRequest.Params, violations, err = transformParams(req.EmbeddingRequest.Params, actions)
+	case req.SpeechRequest != nil:
+		req.SpeechRequest.Params, violations, err = transformParams(req.SpeechRequest.Params, actions)
+	case req.TranscriptionRequest != nil:
+		req.TranscriptionRequest.Params, violations, err = transformParams(req.TranscriptionRequest.Params, actions)
+	}
+	return violations, err
+}
+
+// transformParams applies the parameter actions to the JSON form of the parameters, in which extra params appear next
+// to the known ones, and returns new parameters. Keys that are not fields of the parameters end up in ExtraParams.
+// A violation of an action of a rule with the reject enforcement returns a *parameterViolationError.
+func transformParams[T any](params *T, actions []ruleAction) (*T, []schemas.BifrostParameterViolation, error) {
+	doc := make(map[string]interface{})
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, nil, err
+		}
+		// Extra params are copied through JSON as well, so that nested values of the caller are not modified
+		if extra, ok := reflect.ValueOf(params).Elem().FieldByName("ExtraParams").Interface().(map[string]interface{}); ok && len(extra) > 0 {
+			data, err := json.Marshal(extra)
+			if err != nil {
+				return nil, nil, err
+			}
+			var extraDoc map[string]interface{}
+			if err := json.Unmarshal(data, &extraDoc); err != nil {
+				return nil, nil, err
+			}
+			for key, value := range extraDoc {
+				if _, exists := doc[key]; !exists {
+					doc[key] = value
+				}
+			}
+		}
+	}
+
+	var violations []schemas.BifrostParameterViolation
+	for _, ra := range actions {
+		violation, err := applyParamAction(doc, ra.action)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s %s: %v", ra.action.Type, ra.action.Path, err)
+		}
+		if violation == nil || ra.rule == nil {
+			continue
+		}
+		violation.Rule = ra.rule.Name
+		if ra.rule.Enforcement == schemas.TransformEnforcementReject {
+			violation.Applied = nil
+			return nil, nil, &parameterViolationError{violation: *violation}
+		}
+		violations = append(violations, *violation)
+	}
+
+	result := new(T)
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, nil, fmt.Errorf("invalid parameters after transformation: %v", err)
+	}
+	known := jsonFieldNames(reflect.TypeOf(result).Elem())
+	extra := make(map[string]interface{})
+	for key, value := range doc {
+		if !known[key] {
+			extra[key] = value
+		}
+	}
+	if len(extra) > 0 {
+		if field := reflect.ValueOf(result).Elem().FieldByName("ExtraParams"); field.IsValid() {
+			field.Set(reflect.ValueOf(extra))
+		}
+	}
+	return result, violations, nil
+}
+
+// applyParamAction applies a set, set_default, remove or clamp action to the JSON form of the parameters. It returns
+// the violation of the action by a value of the client, when a set action replaces it or a clamp action bounds it.
+func applyParamAction(doc map[string]interface{}, action schemas.TransformAction) (*schemas.BifrostParameterViolation, error) {
+	path, err := schemas.ParseTransformPath(action.Path)
+	if err != nil {
+		return nil, err
+	}
+	var violation *schemas.BifrostParameterViolation
+	switch action.Type {
+	case schemas.TransformActionSet:
+		value, valueErr := jsonValue(action.Value)
+		if valueErr != nil {
+			return nil, valueErr
+		}
+		if requested, exists := lookupPathValue(doc, path); exists && !reflect.DeepEqual(requested, value) {
+			violation = &schemas.BifrostParameterViolation{Path: action.Path, Requested: requested, Applied: value}
+		}
+		_, err = setPathValue(doc, path, value)
+	case schemas.TransformActionSetDefault:
+		if _, exists := lookupPathValue(doc, path); !exists {
+			_, err = setPathValue(doc, path, action.Value)
+		}
+	case schemas.TransformActionRemove:
+		removePathValue(doc, path)
+	case schemas.TransformActionClamp:
+		value, exists := lookupPathValue(doc, path)
+		if !exists || value == nil {
+			return nil, nil
+		}
+		requested, ok := value.(float64)
+		if !ok {
+			return nil, fmt.Errorf("value is not a number")
+		}
+		number := requested
+		if action.Min != nil && number < *action.Min {
+			number = *action.Min
+		}
+		if action.Max != nil && number > *action.Max {
+			number = *action.Max
+		}
+		if number != requested {
+			violation = &schemas.BifrostParameterViolation{Path: action.Path, Requested: requested, Applied: number}
+		}
+		_, err = setPathValue(doc, path, number)
+	}
+	return violation, err
+}
+
+// jsonValue returns the value as decoded from JSON, so that it compares with the values of the parameters
+func jsonValue(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// lookupPathValue returns the value at the path, and whether it exists
+func lookupPathValue(value interface{}, path []schemas.TransformPathSegment) (interface{}, bool) {
+	for _, segment := range path {
+		if segment.IsKey {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if value, ok = object[segment.Key]; !ok {
+				return nil, false
+			}
+			continue
+		}
+		array, ok := value.([]interface{})
+		if !ok || segment.Index >= len(array) {
+			return nil, false
+		}
+		value = array[segment.Index]
+	}
+	return value, true
+}
+
+// setPathValue sets the value at the path inside container, creating missing objects, and returns the updated container
+func setPathValue(container interface{}, path []schemas.TransformPathSegment, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	segment := path[0]
+	if segment.IsKey {
+		object, ok := container.(map[string]interface{})
+		if container == nil {
+			object = make(map[string]interface{})
+		} else if !ok {
+			return nil, fmt.Errorf("%s is inside a value that is not an object", segment.Key)
+		}
+		child, err := setPathValue(object[segment.Key], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		object[segment.Key] = child
+		return object, nil
+	}
+	array, ok := container.([]interface{})
+	if !ok || segment.Index >= len(array) {
+		return nil, fmt.Errorf("array index %d is out of range", segment.Index)
+	}
+	child, err := setPathValue(array[segment.Index], path[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	array[segment.Index] = child
+	return array, nil
+}
+
+// removePathValue removes the value at the path inside container, missing values are ignored
+func removePathValue(container interface{}, path []schemas.TransformPathSegment) interface{} {
+	segment := path[0]
+	if segment.IsKey {
+		object, ok := container.(map[string]interface{})
+		if !ok {
+			return container
+		}
+		if len(path) == 1 {
+			delete(object, segment.Key)
+		} else if child, exists := object[segment.Key]; exists {
+			object[segment.Key] = removePathValue(child, path[1:])
+		}
+		return object
+	}
+	array, ok := container.([]interface{})
+	if !ok || segment.Index >= len(array) {
+		return container
+	}
+	if len(path) == 1 {
+		return slices.Delete(array, segment.Index, segment.Index+1)
+	}
+	array[segment.Index] = removePathValue(array[segment.Index], path[1:])
+	return array
+}
+
+// jsonFieldNamesCache caches the JSON field names of the parameter types
+var jsonFieldNamesCache sync.Map
+
+// jsonFieldNames returns the names of the fields of a struct type in its JSON form, including embedded structs
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	if names, ok := jsonFieldNamesCache.Load(t); ok {
+		return names.(map[string]bool)
+	}
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for embeddedName := range jsonFieldNames(embedded) {
+					names[embeddedName] = true
+				}
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names[name] = true
+	}
+	jsonFieldNamesCache.Store(t, names)
+	return names
+}
@@ -0,0 +1,169 @@
+package bifrost
+
+import (
+	"context"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+var testTransformRules = []schemas.TransformRule{
+	{
+		Name:   "defaults",
+		Models: []string{"*"},
+		Actions: []schemas.TransformAction{
+			{Type: schemas.TransformActionClamp, Path: "$.temperature", Max: schemas.Ptr(1.0)},
+			{Type: schemas.TransformActionSetDefault, Path: "$.max_completion_tokens", Value: 1024},
+		},
+	},
+	{
+		Name:          "support",
+		Priority:      -1,
+		VirtualKeyIDs: []string{"vk-support"},
+		Actions: []schemas.TransformAction{
+			{Type: schemas.TransformActionInjectSystemPrompt, Value: "You are a support agent."},
+			{Type: schemas.TransformActionRemove, Path: "$.top_k"},
+			{Type: schemas.TransformActionSet, Path: "$.metadata.team", Value: "support"},
+			{Type: schemas.TransformActionRenameModel, Value: "anthropic/claude-sonnet-4-5"},
+		},
+	},
+}
+
+func transformChatRequest() *schemas.BifrostRequest {
+	return &schemas.BifrostRequest{
+		RequestType: schemas.ChatCompletionRequest,
+		ChatRequest: &schemas.BifrostChatRequest{
+			Provider: schemas.OpenAI,
+			Model:    "gpt-4o",
+			Input:    []schemas.ChatMessage{{Role: schemas.ChatMessageRoleUser, Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr("Hi")}}},
+			Params: &schemas.ChatParameters{
+				Temperature: schemas.Ptr(1.8),
+				Metadata:    &map[string]any{"user": "u1"},
+				ExtraParams: map[string]interface{}{"top_k": 40},
+			},
+		},
+	}
+}
+
+// TestValidateTransformRules tests the validation of rules, actions and paths
+func TestValidateTransformRules(t *testing.T) {
+	if err := schemas.ValidateTransformRules(testTransformRules); err != nil {
+		t.Fatalf("expected test rules to be valid, got %v", err)
+	}
+	tests := map[string]schemas.TransformRule{
+		"no attachment":     {Name: "r", Actions: []schemas.TransformAction{{Type: schemas.TransformActionRemove, Path: "$.user"}}},
+		"unknown action":    {Name: "r", Models: []string{"*"}, Actions: []schemas.TransformAction{{Type: "rewrite", Path: "$.user"}}},
+		"invalid path":      {Name: "r", Models: []string{"*"}, Actions: []schemas.TransformAction{{Type: schemas.TransformActionRemove, Path: "$.tags[x]"}}},
+		"clamp bounds":      {Name: "r", Models: []string{"*"}, Actions: []schemas.TransformAction{{Type: schemas.TransformActionClamp, Path: "$.temperature"}}},
+		"rename to nothing": {Name: "r", Models: []string{"*"}, Actions: []schemas.TransformAction{{Type: schemas.TransformActionRenameModel}}},
+	}
+	for name, rule := range tests {
+		if err := rule.Validate(); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
+
+// TestApplyTransformRules tests that matching rules rewrite a copy of the request in priority order
+func TestApplyTransformRules(t *testing.T) {
+	bifrost := &Bifrost{}
+	if err := bifrost.UpdateTransformRules(testTransformRules); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the rule attached to all models applies without the virtual key
+	req := transformChatRequest()
+	transformed, names, violations, err := bifrost.applyTransformRules(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+	if len(names) != 1 || names[0] != "defaults" {
+		t.Errorf("expected the defaults rule to apply, got %v", names)
+	}
+	if len(violations) != 1 || violations[0].Rule != "defaults" || violations[0].Requested != 1.8 || violations[0].Applied != 1.0 {
+		t.Errorf("expected the clamped temperature to be recorded, got %+v", violations)
+	}
+	params := transformed.ChatRequest.Params
+	if *params.Temperature != 1.0 || *params.MaxCompletionTokens != 1024 {
+		t.Errorf("expected temperature clamped to 1 and default max tokens, got %v and %v", *params.Temperature, params.MaxCompletionTokens)
+	}
+	if params.ExtraParams["top_k"] != float64(40) {
+		t.Errorf("expected extra params to be kept, got %v", params.ExtraParams)
+	}
+	if *req.ChatRequest.Params.Temperature != 1.8 {
+		t.Error("the original request must not be modified")
+	}
+
+	// The virtual key rule applies first
+	ctx := context.WithValue(context.Background(), schemas.BifrostContextKeyGovernanceVirtualKeyID, "vk-support")
+	transformed, names, _, err = bifrost.applyTransformRules(ctx, req)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+	if len(names) != 2 || names[0] != "support" {
+		t.Errorf("expected the support rule to apply before the defaults rule, got %v", names)
+	}
+	chatReq := transformed.ChatRequest
+	if chatReq.Provider != schemas.Anthropic || chatReq.Model != "claude-sonnet-4-5" {
+		t.Errorf("expected the model to be renamed, got %s/%s", chatReq.Provider, chatReq.Model)
+	}
+	if len(chatReq.Input) != 2 || chatReq.Input[0].Role != schemas.ChatMessageRoleSystem {
+		t.Errorf("expected a system prompt before the messages, got %+v", chatReq.Input)
+	}
+	if _, ok := chatReq.Params.ExtraParams["top_k"]; ok {
+		t.Error("expected top_k to be removed")
+	}
+	if metadata := *chatReq.Params.Metadata; metadata["team"] != "support" || metadata["user"] != "u1" {
+		t.Errorf("expected the team to be added to the metadata, got %v", metadata)
+	}
+	if _, ok := (*req.ChatRequest.Params.Metadata)["team"]; ok || len(req.ChatRequest.Input) != 1 {
+		t.Error("the original request must not be modified")
+	}
+
+	// Setting a key inside a value that isn't an object fails the request
+	bifrost.UpdateTransformRules([]schemas.TransformRule{{
+		Name:    "broken",
+		Models:  []string{"openai/gpt-4o"},
+		Actions: []schemas.TransformAction{{Type: schemas.TransformActionSet, Path: "$.temperature.value", Value: 1}},
+	}})
+	if _, _, _, err := bifrost.applyTransformRules(context.Background(), req); err == nil || *err.StatusCode != 400 {
+		t.Errorf("expected a 400 error, got %+v", err)
+	}
+}
+
+// TestTransformRuleEnforcement tests that rules with the reject enforcement reject the values violating their actions
+func TestTransformRuleEnforcement(t *testing.T) {
+	bifrost := &Bifrost{}
+	if err := bifrost.UpdateTransformRules([]schemas.TransformRule{{
+		Name:        "sampling",
+		Models:      []string{"gpt-4o"},
+		Enforcement: schemas.TransformEnforcementReject,
+		Actions: []schemas.TransformAction{
+			{Type: schemas.TransformActionClamp, Path: "$.temperature", Min: schemas.Ptr(0.0), Max: schemas.Ptr(2.0)},
+			{Type: schemas.TransformActionSet, Path: "$.top_p", Value: 1},
+		},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Values within the policy pass, and the forced parameter is set
+	req := transformChatRequest()
+	transformed, _, violations, err := bifrost.applyTransformRules(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+	if len(violations) != 0 || *transformed.ChatRequest.Params.TopP != 1 {
+		t.Errorf("expected top_p to be forced without violations, got %+v", violations)
+	}
+
+	// A differing value of a forced parameter is rejected
+	req.ChatRequest.Params.TopP = schemas.Ptr(0.5)
+	_, _, _, err = bifrost.applyTransformRules(context.Background(), req)
+	if err == nil || *err.Type != "parameter_policy_violation" || *err.StatusCode != 400 || err.Error.Param != "top_p" {
+		t.Fatalf("expected a parameter policy violation, got %+v", err)
+	}
+
+	if err := (&schemas.TransformRule{Name: "r", Models: []string{"*"}, Enforcement: "warn", Actions: []schemas.TransformAction{{Type: schemas.TransformActionRemove, Path: "$.user"}}}).Validate(); err == nil {
+		t.Error("expected an unknown enforcement to be invalid")
+	}
+}
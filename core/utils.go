@@ -53,19 +53,20 @@ func Ptr[T any](v T) *T {
 }
 
 // providerRequiresKey returns true if the given provider requires an API key for authentication.
-// Some providers like Ollama and SGL are keyless and don't require API keys.
+// Some providers like Ollama, SGL and the mock provider are keyless and don't require API keys.
 func providerRequiresKey(providerKey schemas.ModelProvider, customConfig *schemas.CustomProviderConfig) bool {
 	// Keyless custom providers are not allowed for Bedrock.
 	if customConfig != nil && customConfig.IsKeyLess && customConfig.BaseProviderType != schemas.Bedrock {
 		return false
 	}
-	return providerKey != schemas.Ollama && providerKey != schemas.SGL
+	return providerKey != schemas.Ollama && providerKey != schemas.SGL && providerKey != schemas.Mock
 }
 
 // canProviderKeyValueBeEmpty returns true if the given provider allows the API key to be empty.
 // Some providers like Vertex and Bedrock have their credentials in additional key configs..
+// Hugging Face keys can be empty for self-hosted TGI servers that do not require a token.
 func canProviderKeyValueBeEmpty(providerKey schemas.ModelProvider) bool {
-	return providerKey == schemas.Vertex || providerKey == schemas.Bedrock
+	return providerKey == schemas.Vertex || providerKey == schemas.Bedrock || providerKey == schemas.HuggingFace
 }
 
 func isKeySkippingAllowed(providerKey schemas.ModelProvider) bool {
@@ -74,13 +75,18 @@ func isKeySkippingAllowed(providerKey schemas.ModelProvider) bool {
 
 // calculateBackoff implements exponential backoff with jitter for retry attempts.
 func calculateBackoff(attempt int, config *schemas.ProviderConfig) time.Duration {
+	return exponentialBackoff(attempt, config.NetworkConfig.RetryBackoffInitial, config.NetworkConfig.RetryBackoffMax)
+}
+
+// exponentialBackoff returns initial * 2^attempt with 20% jitter, capped at maxBackoff.
+func exponentialBackoff(attempt int, initial time.Duration, maxBackoff time.Duration) time.Duration {
 	// Calculate an exponential backoff: initial * 2^attempt
-	backoff := min(config.NetworkConfig.RetryBackoffInitial*time.Duration(1<<uint(attempt)), config.NetworkConfig.RetryBackoffMax)
+	backoff := min(initial*time.Duration(1<<uint(attempt)), maxBackoff)
 	// Add jitter (20%)
 	jitter := float64(backoff) * (0.8 + 0.4*rand.Float64())
 	result := time.Duration(jitter)
 	// Ensure we never exceed the configured maximum
-	return min(result, config.NetworkConfig.RetryBackoffMax)
+	return min(result, maxBackoff)
 }
 
 // validateRequest validates the given request.
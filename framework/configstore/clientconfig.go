@@ -44,6 +44,25 @@ type ClientConfig struct {
 	AllowedOrigins          []string `json:"allowed_origins,omitempty"`           // Additional allowed origins for CORS and WebSocket (localhost is always allowed)
 	MaxRequestBodySizeMB    int      `json:"max_request_body_size_mb"`            // The maximum request body size in MB
 	EnableLiteLLMFallbacks  bool     `json:"enable_litellm_fallbacks"`            // Enable litellm-specific fallbacks for text completion for Groq
+
+	DirectKeyPolicy    *schemas.DirectKeyPolicy          `json:"direct_key_policy,omitempty"`   // Constraints on requests made with direct keys, only used when AllowDirectKeys is on
+	ImageInputs        *schemas.ImageInputConfig         `json:"image_inputs,omitempty"`        // Fetching and transcoding of image inputs for providers with stricter requirements
+	Agent              *schemas.AgentConfig              `json:"agent,omitempty"`               // Tool-call loop of the agent endpoint, nil disables the endpoint
+	ContextWindow      *schemas.ContextWindowConfig      `json:"context_window,omitempty"`      // Pre-flight check of the input tokens against the context window of the model
+	PromptCompression  *schemas.PromptCompressionConfig  `json:"prompt_compression,omitempty"`  // Compression of the oldest turns of conversations exceeding the token budget of their model
+	Streaming          *schemas.StreamingConfig          `json:"streaming,omitempty"`           // Heartbeats and resumption of the Server-Sent Events streams of the HTTP transport
+	StreamFailover     *schemas.StreamFailoverConfig     `json:"stream_failover,omitempty"`     // Restart of streams failing mid-generation on the next fallback
+	StreamBackpressure *schemas.StreamBackpressureConfig `json:"stream_backpressure,omitempty"` // Bounded buffers of streams and policy applied to slow consumers
+	ResponseBuffering  *schemas.ResponseBufferingConfig  `json:"response_buffering,omitempty"`  // Non-stream requests served from a provider stream aggregated by Bifrost
+	Idempotency        *schemas.IdempotencyConfig        `json:"idempotency,omitempty"`         // Replay window of the responses of requests sent with an Idempotency-Key
+	EndpointPolicy     *schemas.EndpointPolicy           `json:"endpoint_policy,omitempty"`     // Egress policy of the custom endpoints of providers and keys
+	Chaos              *schemas.ChaosConfig              `json:"chaos,omitempty"`               // Faults injected into provider requests for resilience testing
+	ListModelsCache    *schemas.ListModelsCacheConfig    `json:"list_models_cache,omitempty"`   // Cache of the models listed by the providers, refreshed in the background
+	ResponsesState     *schemas.ResponsesStateConfig     `json:"responses_state,omitempty"`     // Storage of Responses API conversations continued with previous_response_id
+	RequestTags        *schemas.RequestTagsConfig        `json:"request_tags,omitempty"`        // Allow-list of the tags recorded on the logs and metrics of requests
+	PartialFailures    *schemas.PartialFailureConfig     `json:"partial_failures,omitempty"`    // Partial or strict results of requests of many inputs when some inputs fail
+	Diagnostics        *schemas.DiagnosticsConfig        `json:"diagnostics,omitempty"`         // Capture of the bodies of slow and large inference requests
+	SLOs               *schemas.SLOConfig                `json:"slos,omitempty"`                // Service level objectives of the providers and their error budgets
 }
 
 // ProviderConfig represents the configuration for a specific AI model provider.
@@ -55,6 +74,8 @@ type ProviderConfig struct {
 	ProxyConfig              *schemas.ProxyConfig              `json:"proxy_config,omitempty"`                // Proxy configuration
 	SendBackRawResponse      bool                              `json:"send_back_raw_response"`                // Include raw response in BifrostResponse
 	CustomProviderConfig     *schemas.CustomProviderConfig     `json:"custom_provider_config,omitempty"`      // Custom provider configuration
+	MockConfig               *schemas.MockConfig               `json:"mock_config,omitempty"`                 // Synthetic responses of the mock provider
+	Namespace                string                            `json:"namespace,omitempty"`                   // Namespace the provider belongs to (empty means the default namespace)
 	ConfigHash               string                            `json:"-"`
 }
 
@@ -103,6 +124,15 @@ func (p *ProviderConfig) GenerateConfigHash(providerName string) (string, error)
 		hash.Write(data)
 	}
 
+	// Hash MockConfig
+	if p.MockConfig != nil {
+		data, err := sonic.Marshal(p.MockConfig)
+		if err != nil {
+			return "", err
+		}
+		hash.Write(data)
+	}
+
 	// Hash SendBackRawResponse
 	if p.SendBackRawResponse {
 		hash.Write([]byte("sendBackRawResponse"))
@@ -166,6 +196,27 @@ func GenerateKeyHash(key schemas.Key) (string, error) {
 		hash.Write(data)
 	}
 
+	// Hash the proxy of the key
+	if key.ProxyConfig != nil {
+		data, err := sonic.Marshal(key.ProxyConfig)
+		if err != nil {
+			return "", err
+		}
+		hash.Write(data)
+	}
+
+	// Hash test key settings
+	if key.IsTest {
+		hash.Write([]byte("isTest"))
+	}
+	if key.SpendLimit != nil {
+		data, err := sonic.Marshal(*key.SpendLimit)
+		if err != nil {
+			return "", err
+		}
+		hash.Write(data)
+	}
+
 	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
@@ -10,6 +10,7 @@ import (
 	"github.com/maximhq/bifrost/framework/configstore/tables"
 	"github.com/maximhq/bifrost/framework/migrator"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Migrate performs the necessary database migrations.
@@ -92,6 +93,150 @@ func triggerMigrations(ctx context.Context, db *gorm.DB) error {
 	if err := migrationAddConfigHashColumn(ctx, db); err != nil {
 		return err
 	}
+	if err := migrationAddVirtualKeyDefaultParamsColumns(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddNamespaces(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddVirtualKeyDedupeWindowColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddPluginExecutionColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddVirtualKeyRotationColumns(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddDirectKeyPolicyColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddNamespacePayloadEncryptionColumns(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddTestKeyColumns(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddPipelinesTable(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddAzureEndpointTypeColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddVertexRegionsColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddBedrockRegionsColumns(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddBedrockGuardrailColumns(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddBedrockAssumeRoleColumns(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddImageInputsColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddAgentColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddTransformRulesTable(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddSystemPromptPolicyColumns(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddContextWindowColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddPromptCompressionColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddModelCapabilitiesTable(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddModelDeprecationsTable(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddModelPricingOverridesTable(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddStreamingColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddStreamFailoverColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddStreamBackpressureColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddResponseBufferingColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddIdempotencyColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddKeyProxyConfigColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddEndpointPolicyColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddProviderMockConfigColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddChaosColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddKeyLastValidationColumns(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddListModelsCacheColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddResponsesStateColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddConversationsTable(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddVirtualKeyEndUserLimitsColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddResidencyColumns(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddFallbackChainsTable(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddRequestTagsColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddLeasesTable(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddMaxOutputTokensColumns(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddTransformRuleEnforcementColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddPartialFailuresColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddDiagnosticsColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddSLOsColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddExperimentsTable(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddRevokedPayloadKeysTable(ctx, db); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -1371,3 +1516,1517 @@ func migrationAddConfigHashColumn(ctx context.Context, db *gorm.DB) error {
 	}
 	return nil
 }
+
+// migrationAddVirtualKeyDefaultParamsColumns adds the default_params and system_prompt columns to the virtual key table
+func migrationAddVirtualKeyDefaultParamsColumns(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_virtual_key_default_params_columns",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableVirtualKey{}, "default_params") {
+				if err := migrator.AddColumn(&tables.TableVirtualKey{}, "default_params"); err != nil {
+					return err
+				}
+			}
+			if !migrator.HasColumn(&tables.TableVirtualKey{}, "system_prompt") {
+				if err := migrator.AddColumn(&tables.TableVirtualKey{}, "system_prompt"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableVirtualKey{}, "default_params"); err != nil {
+				return err
+			}
+			if err := migrator.DropColumn(&tables.TableVirtualKey{}, "system_prompt"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add virtual key default params columns migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddNamespaces adds the namespaces table and the namespace column to every namespaced table.
+// Existing rows are assigned to the default namespace.
+func migrationAddNamespaces(ctx context.Context, db *gorm.DB) error {
+	namespacedTables := []any{
+		&tables.TableProvider{},
+		&tables.TableKey{},
+		&tables.TableVirtualKey{},
+		&tables.TableTeam{},
+		&tables.TableCustomer{},
+		&tables.TableBudget{},
+		&tables.TableRateLimit{},
+	}
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_namespaces",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasTable(&tables.TableNamespace{}) {
+				if err := migrator.CreateTable(&tables.TableNamespace{}); err != nil {
+					return err
+				}
+			}
+			for _, table := range namespacedTables {
+				if !migrator.HasColumn(table, "namespace") {
+					if err := migrator.AddColumn(table, "namespace"); err != nil {
+						return err
+					}
+				}
+				// UpdateColumn skips the hooks of the model, which validate the fields of a full row
+				if err := tx.Model(table).Where("namespace IS NULL OR namespace = ?", "").UpdateColumn("namespace", tables.DefaultNamespace).Error; err != nil {
+					return fmt.Errorf("failed to backfill namespace: %w", err)
+				}
+			}
+			if !migrator.HasColumn(&tables.SessionsTable{}, "namespace") {
+				if err := migrator.AddColumn(&tables.SessionsTable{}, "namespace"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			for _, table := range namespacedTables {
+				if err := migrator.DropColumn(table, "namespace"); err != nil {
+					return err
+				}
+			}
+			if err := migrator.DropColumn(&tables.SessionsTable{}, "namespace"); err != nil {
+				return err
+			}
+			if err := migrator.DropTable(&tables.TableNamespace{}); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add namespaces migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddVirtualKeyDedupeWindowColumn adds the dedupe_window column to the virtual key table
+func migrationAddVirtualKeyDedupeWindowColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_virtual_key_dedupe_window_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableVirtualKey{}, "dedupe_window") {
+				if err := migrator.AddColumn(&tables.TableVirtualKey{}, "dedupe_window"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableVirtualKey{}, "dedupe_window"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add virtual key dedupe window column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddPluginExecutionColumn adds the execution_json column to the plugin table
+func migrationAddPluginExecutionColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_plugin_execution_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TablePlugin{}, "execution_json") {
+				if err := migrator.AddColumn(&tables.TablePlugin{}, "execution_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TablePlugin{}, "execution_json"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add plugin execution column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddVirtualKeyRotationColumns adds the rotation and revocation columns to the virtual key table
+func migrationAddVirtualKeyRotationColumns(ctx context.Context, db *gorm.DB) error {
+	columns := []string{"previous_value", "previous_value_expires_at", "rotated_at", "revoked_at"}
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_virtual_key_rotation_columns",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			for _, column := range columns {
+				if !migrator.HasColumn(&tables.TableVirtualKey{}, column) {
+					if err := migrator.AddColumn(&tables.TableVirtualKey{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			for _, column := range columns {
+				if err := migrator.DropColumn(&tables.TableVirtualKey{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add virtual key rotation columns migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddDirectKeyPolicyColumn adds the direct_key_policy_json column to the client config table
+func migrationAddDirectKeyPolicyColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_direct_key_policy_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableClientConfig{}, "direct_key_policy_json") {
+				if err := migrator.AddColumn(&tables.TableClientConfig{}, "direct_key_policy_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableClientConfig{}, "direct_key_policy_json"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add direct key policy column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddNamespacePayloadEncryptionColumns adds the payload encryption key columns to the namespaces table
+func migrationAddNamespacePayloadEncryptionColumns(ctx context.Context, db *gorm.DB) error {
+	columns := []string{"payload_encryption_key_ref", "payload_key_revoked_at"}
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_namespace_payload_encryption_columns",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			for _, column := range columns {
+				if !migrator.HasColumn(&tables.TableNamespace{}, column) {
+					if err := migrator.AddColumn(&tables.TableNamespace{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			for _, column := range columns {
+				if err := migrator.DropColumn(&tables.TableNamespace{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add namespace payload encryption columns migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddTestKeyColumns adds the test key columns to the keys table and creates the test key spend table
+func migrationAddTestKeyColumns(ctx context.Context, db *gorm.DB) error {
+	columns := []string{"is_test", "spend_limit"}
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_test_key_columns",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			for _, column := range columns {
+				if !migrator.HasColumn(&tables.TableKey{}, column) {
+					if err := migrator.AddColumn(&tables.TableKey{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			if !migrator.HasTable(&tables.TableKeySpend{}) {
+				if err := migrator.CreateTable(&tables.TableKeySpend{}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropTable(&tables.TableKeySpend{}); err != nil {
+				return err
+			}
+			for _, column := range columns {
+				if err := migrator.DropColumn(&tables.TableKey{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add test key columns migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddPipelinesTable creates the transformation pipelines table
+func migrationAddPipelinesTable(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_pipelines_table",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasTable(&tables.TablePipeline{}) {
+				if err := migrator.CreateTable(&tables.TablePipeline{}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			return tx.Migrator().DropTable(&tables.TablePipeline{})
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add pipelines table migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddAzureEndpointTypeColumn adds the azure_endpoint_type column to the keys table
+func migrationAddAzureEndpointTypeColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_azure_endpoint_type_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableKey{}, "azure_endpoint_type") {
+				if err := migrator.AddColumn(&tables.TableKey{}, "azure_endpoint_type"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			return tx.Migrator().DropColumn(&tables.TableKey{}, "azure_endpoint_type")
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add azure endpoint type column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddVertexRegionsColumn adds the vertex_regions_json column to the keys table
+func migrationAddVertexRegionsColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_vertex_regions_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableKey{}, "vertex_regions_json") {
+				if err := migrator.AddColumn(&tables.TableKey{}, "vertex_regions_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			return tx.Migrator().DropColumn(&tables.TableKey{}, "vertex_regions_json")
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add vertex regions column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddBedrockRegionsColumns adds the bedrock_regions_json and bedrock_arns_json columns to the keys table
+func migrationAddBedrockRegionsColumns(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_bedrock_regions_columns",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableKey{}, "bedrock_regions_json") {
+				if err := migrator.AddColumn(&tables.TableKey{}, "bedrock_regions_json"); err != nil {
+					return err
+				}
+			}
+			if !migrator.HasColumn(&tables.TableKey{}, "bedrock_arns_json") {
+				if err := migrator.AddColumn(&tables.TableKey{}, "bedrock_arns_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableKey{}, "bedrock_regions_json"); err != nil {
+				return err
+			}
+			return migrator.DropColumn(&tables.TableKey{}, "bedrock_arns_json")
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add bedrock regions columns migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddBedrockGuardrailColumns adds the bedrock_guardrail_id and bedrock_guardrail_version columns to the keys table
+func migrationAddBedrockGuardrailColumns(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_bedrock_guardrail_columns",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableKey{}, "bedrock_guardrail_id") {
+				if err := migrator.AddColumn(&tables.TableKey{}, "bedrock_guardrail_id"); err != nil {
+					return err
+				}
+			}
+			if !migrator.HasColumn(&tables.TableKey{}, "bedrock_guardrail_version") {
+				if err := migrator.AddColumn(&tables.TableKey{}, "bedrock_guardrail_version"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableKey{}, "bedrock_guardrail_id"); err != nil {
+				return err
+			}
+			return migrator.DropColumn(&tables.TableKey{}, "bedrock_guardrail_version")
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add bedrock guardrail columns migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddBedrockAssumeRoleColumns adds the bedrock_role_arn, bedrock_external_id and bedrock_role_session_name columns to the keys table
+func migrationAddBedrockAssumeRoleColumns(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_bedrock_assume_role_columns",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			for _, column := range []string{"bedrock_role_arn", "bedrock_external_id", "bedrock_role_session_name"} {
+				if !migrator.HasColumn(&tables.TableKey{}, column) {
+					if err := migrator.AddColumn(&tables.TableKey{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			for _, column := range []string{"bedrock_role_arn", "bedrock_external_id", "bedrock_role_session_name"} {
+				if err := migrator.DropColumn(&tables.TableKey{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add bedrock assume role columns migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddImageInputsColumn adds the image_inputs_json column to the client config table
+func migrationAddImageInputsColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_image_inputs_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableClientConfig{}, "image_inputs_json") {
+				if err := migrator.AddColumn(&tables.TableClientConfig{}, "image_inputs_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableClientConfig{}, "image_inputs_json"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add image inputs column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddAgentColumn adds the agent_json column to the client config table
+func migrationAddAgentColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_agent_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableClientConfig{}, "agent_json") {
+				if err := migrator.AddColumn(&tables.TableClientConfig{}, "agent_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableClientConfig{}, "agent_json"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add agent column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddTransformRulesTable creates the transform rules table
+func migrationAddTransformRulesTable(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_transform_rules_table",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasTable(&tables.TableTransformRule{}) {
+				if err := migrator.CreateTable(&tables.TableTransformRule{}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			return tx.Migrator().DropTable(&tables.TableTransformRule{})
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add transform rules table migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddSystemPromptPolicyColumns adds the system_prompt_policy column to the virtual keys and teams tables
+func migrationAddSystemPromptPolicyColumns(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_system_prompt_policy_columns",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableVirtualKey{}, "system_prompt_policy") {
+				if err := migrator.AddColumn(&tables.TableVirtualKey{}, "system_prompt_policy"); err != nil {
+					return err
+				}
+			}
+			if !migrator.HasColumn(&tables.TableTeam{}, "system_prompt_policy") {
+				if err := migrator.AddColumn(&tables.TableTeam{}, "system_prompt_policy"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableVirtualKey{}, "system_prompt_policy"); err != nil {
+				return err
+			}
+			if err := migrator.DropColumn(&tables.TableTeam{}, "system_prompt_policy"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add system prompt policy columns migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddContextWindowColumn adds the context_window_json column to the client config table
+func migrationAddContextWindowColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_context_window_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableClientConfig{}, "context_window_json") {
+				if err := migrator.AddColumn(&tables.TableClientConfig{}, "context_window_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableClientConfig{}, "context_window_json"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add context window column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddPromptCompressionColumn adds the prompt_compression_json column to the client config table
+func migrationAddPromptCompressionColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_prompt_compression_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableClientConfig{}, "prompt_compression_json") {
+				if err := migrator.AddColumn(&tables.TableClientConfig{}, "prompt_compression_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableClientConfig{}, "prompt_compression_json"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add prompt compression column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddModelCapabilitiesTable adds the model capability overrides table
+func migrationAddModelCapabilitiesTable(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_model_capabilities_table",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasTable(&tables.TableModelCapability{}) {
+				if err := migrator.CreateTable(&tables.TableModelCapability{}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			return tx.Migrator().DropTable(&tables.TableModelCapability{})
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add model capabilities table migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddModelDeprecationsTable adds the model deprecations table
+func migrationAddModelDeprecationsTable(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_model_deprecations_table",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasTable(&tables.TableModelDeprecation{}) {
+				if err := migrator.CreateTable(&tables.TableModelDeprecation{}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			return tx.Migrator().DropTable(&tables.TableModelDeprecation{})
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add model deprecations table migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddModelPricingOverridesTable adds the model pricing overrides table
+func migrationAddModelPricingOverridesTable(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_model_pricing_overrides_table",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasTable(&tables.TableModelPricingOverride{}) {
+				if err := migrator.CreateTable(&tables.TableModelPricingOverride{}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			return tx.Migrator().DropTable(&tables.TableModelPricingOverride{})
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add model pricing overrides table migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddStreamingColumn adds the streaming_json column to the client config table
+func migrationAddStreamingColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_streaming_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableClientConfig{}, "streaming_json") {
+				if err := migrator.AddColumn(&tables.TableClientConfig{}, "streaming_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableClientConfig{}, "streaming_json"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add streaming column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddStreamFailoverColumn adds the stream_failover_json column to the client config table
+func migrationAddStreamFailoverColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_stream_failover_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableClientConfig{}, "stream_failover_json") {
+				if err := migrator.AddColumn(&tables.TableClientConfig{}, "stream_failover_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableClientConfig{}, "stream_failover_json"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add stream failover column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddStreamBackpressureColumn adds the stream_backpressure_json column to the client config table
+func migrationAddStreamBackpressureColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_stream_backpressure_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableClientConfig{}, "stream_backpressure_json") {
+				if err := migrator.AddColumn(&tables.TableClientConfig{}, "stream_backpressure_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableClientConfig{}, "stream_backpressure_json"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add stream backpressure column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddResponseBufferingColumn adds the response_buffering_json column to the client config table
+func migrationAddResponseBufferingColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_response_buffering_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableClientConfig{}, "response_buffering_json") {
+				if err := migrator.AddColumn(&tables.TableClientConfig{}, "response_buffering_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableClientConfig{}, "response_buffering_json"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add response buffering column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddIdempotencyColumn adds the idempotency_json column to the client config table
+func migrationAddIdempotencyColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_idempotency_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableClientConfig{}, "idempotency_json") {
+				if err := migrator.AddColumn(&tables.TableClientConfig{}, "idempotency_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableClientConfig{}, "idempotency_json"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add idempotency column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddKeyProxyConfigColumn adds the proxy_config_json column to the keys table
+func migrationAddKeyProxyConfigColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_key_proxy_config_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableKey{}, "proxy_config_json") {
+				if err := migrator.AddColumn(&tables.TableKey{}, "proxy_config_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableKey{}, "proxy_config_json"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add key proxy config column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddEndpointPolicyColumn adds the endpoint_policy_json column to the client config table
+func migrationAddEndpointPolicyColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_endpoint_policy_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableClientConfig{}, "endpoint_policy_json") {
+				if err := migrator.AddColumn(&tables.TableClientConfig{}, "endpoint_policy_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableClientConfig{}, "endpoint_policy_json"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add endpoint policy column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddProviderMockConfigColumn adds the mock_config_json column to the provider table
+func migrationAddProviderMockConfigColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_provider_mock_config_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableProvider{}, "mock_config_json") {
+				if err := migrator.AddColumn(&tables.TableProvider{}, "mock_config_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableProvider{}, "mock_config_json"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add provider mock config column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddChaosColumn adds the chaos_json column to the client config table
+func migrationAddChaosColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_chaos_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableClientConfig{}, "chaos_json") {
+				if err := migrator.AddColumn(&tables.TableClientConfig{}, "chaos_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableClientConfig{}, "chaos_json"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add chaos column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddKeyLastValidationColumns adds the columns of the last validation call made with a key to the key table
+func migrationAddKeyLastValidationColumns(ctx context.Context, db *gorm.DB) error {
+	columns := []string{"last_validation_status", "last_validation_error", "last_validated_at"}
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_key_last_validation_columns",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			for _, column := range columns {
+				if !migrator.HasColumn(&tables.TableKey{}, column) {
+					if err := migrator.AddColumn(&tables.TableKey{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			for _, column := range columns {
+				if err := migrator.DropColumn(&tables.TableKey{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add key last validation columns migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddListModelsCacheColumn adds the list_models_cache_json column to the client config table
+func migrationAddListModelsCacheColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_list_models_cache_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableClientConfig{}, "list_models_cache_json") {
+				if err := migrator.AddColumn(&tables.TableClientConfig{}, "list_models_cache_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableClientConfig{}, "list_models_cache_json"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add list models cache column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddResponsesStateColumn adds the responses_state_json column to the client config table
+func migrationAddResponsesStateColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_responses_state_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableClientConfig{}, "responses_state_json") {
+				if err := migrator.AddColumn(&tables.TableClientConfig{}, "responses_state_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableClientConfig{}, "responses_state_json"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add responses state column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddConversationsTable adds the conversations table
+func migrationAddConversationsTable(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_conversations_table",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasTable(&tables.TableConversation{}) {
+				if err := migrator.CreateTable(&tables.TableConversation{}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			return tx.Migrator().DropTable(&tables.TableConversation{})
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add conversations table migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddVirtualKeyEndUserLimitsColumn adds the end_user_limits column to the virtual key table
+func migrationAddVirtualKeyEndUserLimitsColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_virtual_key_end_user_limits_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableVirtualKey{}, "end_user_limits") {
+				if err := migrator.AddColumn(&tables.TableVirtualKey{}, "end_user_limits"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableVirtualKey{}, "end_user_limits"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add virtual key end user limits column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddResidencyColumns adds the region column to the keys table and the residency_policy column to the virtual key table
+func migrationAddResidencyColumns(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_residency_columns",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableKey{}, "region") {
+				if err := migrator.AddColumn(&tables.TableKey{}, "region"); err != nil {
+					return err
+				}
+			}
+			if !migrator.HasColumn(&tables.TableVirtualKey{}, "residency_policy") {
+				if err := migrator.AddColumn(&tables.TableVirtualKey{}, "residency_policy"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableVirtualKey{}, "residency_policy"); err != nil {
+				return err
+			}
+			if err := migrator.DropColumn(&tables.TableKey{}, "region"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add residency columns migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddFallbackChainsTable creates the fallback chains table
+func migrationAddFallbackChainsTable(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_fallback_chains_table",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasTable(&tables.TableFallbackChain{}) {
+				if err := migrator.CreateTable(&tables.TableFallbackChain{}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			return tx.Migrator().DropTable(&tables.TableFallbackChain{})
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add fallback chains table migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddRequestTagsColumn adds the request_tags_json column to the client config table
+func migrationAddRequestTagsColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_request_tags_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableClientConfig{}, "request_tags_json") {
+				if err := migrator.AddColumn(&tables.TableClientConfig{}, "request_tags_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableClientConfig{}, "request_tags_json"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add request tags column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddLeasesTable creates the leases table
+func migrationAddLeasesTable(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_leases_table",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasTable(&tables.TableLease{}) {
+				if err := migrator.CreateTable(&tables.TableLease{}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropTable(&tables.TableLease{}); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add leases table migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddMaxOutputTokensColumns adds the max_output_tokens column to the virtual keys and teams tables
+func migrationAddMaxOutputTokensColumns(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_max_output_tokens_columns",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableVirtualKey{}, "max_output_tokens") {
+				if err := migrator.AddColumn(&tables.TableVirtualKey{}, "max_output_tokens"); err != nil {
+					return err
+				}
+			}
+			if !migrator.HasColumn(&tables.TableTeam{}, "max_output_tokens") {
+				if err := migrator.AddColumn(&tables.TableTeam{}, "max_output_tokens"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableVirtualKey{}, "max_output_tokens"); err != nil {
+				return err
+			}
+			if err := migrator.DropColumn(&tables.TableTeam{}, "max_output_tokens"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add max output tokens columns migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddTransformRuleEnforcementColumn adds the enforcement column to the transform rules table
+func migrationAddTransformRuleEnforcementColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_transform_rule_enforcement_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableTransformRule{}, "enforcement") {
+				if err := migrator.AddColumn(&tables.TableTransformRule{}, "enforcement"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableTransformRule{}, "enforcement"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add transform rule enforcement column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddPartialFailuresColumn adds the partial_failures_json column to the client config table
+func migrationAddPartialFailuresColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_partial_failures_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableClientConfig{}, "partial_failures_json") {
+				if err := migrator.AddColumn(&tables.TableClientConfig{}, "partial_failures_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableClientConfig{}, "partial_failures_json"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add partial failures column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddDiagnosticsColumn adds the diagnostics_json column to the client config table
+func migrationAddDiagnosticsColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_diagnostics_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableClientConfig{}, "diagnostics_json") {
+				if err := migrator.AddColumn(&tables.TableClientConfig{}, "diagnostics_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableClientConfig{}, "diagnostics_json"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add diagnostics column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddSLOsColumn adds the slos_json column to the client config table
+func migrationAddSLOsColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_slos_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&tables.TableClientConfig{}, "slos_json") {
+				if err := migrator.AddColumn(&tables.TableClientConfig{}, "slos_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&tables.TableClientConfig{}, "slos_json"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add slos column migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddExperimentsTable creates the experiments table
+func migrationAddExperimentsTable(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_experiments_table",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasTable(&tables.TableExperiment{}) {
+				if err := migrator.CreateTable(&tables.TableExperiment{}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			return tx.Migrator().DropTable(&tables.TableExperiment{})
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add experiments table migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddRevokedPayloadKeysTable creates the revoked payload keys table, with the keys revoked on the namespaces
+func migrationAddRevokedPayloadKeysTable(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "add_revoked_payload_keys_table",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasTable(&tables.TableRevokedPayloadKey{}) {
+				if err := migrator.CreateTable(&tables.TableRevokedPayloadKey{}); err != nil {
+					return err
+				}
+			}
+			var namespaces []tables.TableNamespace
+			if err := tx.Where("payload_encryption_key_ref IS NOT NULL AND payload_key_revoked_at IS NOT NULL").Find(&namespaces).Error; err != nil {
+				return err
+			}
+			for _, namespace := range namespaces {
+				key := tables.TableRevokedPayloadKey{KeyRef: *namespace.PayloadEncryptionKeyRef, Namespace: namespace.Name, RevokedAt: *namespace.PayloadKeyRevokedAt}
+				if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&key).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			return tx.Migrator().DropTable(&tables.TableRevokedPayloadKey{})
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running add revoked payload keys table migration: %s", err.Error())
+	}
+	return nil
+}
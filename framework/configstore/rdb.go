@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	bifrost "github.com/maximhq/bifrost/core"
 	"github.com/maximhq/bifrost/core/schemas"
@@ -40,6 +41,24 @@ func (s *RDBConfigStore) UpdateClientConfig(ctx context.Context, config *ClientC
 		AllowedOrigins:          config.AllowedOrigins,
 		MaxRequestBodySizeMB:    config.MaxRequestBodySizeMB,
 		EnableLiteLLMFallbacks:  config.EnableLiteLLMFallbacks,
+		DirectKeyPolicy:         config.DirectKeyPolicy,
+		ImageInputs:             config.ImageInputs,
+		Agent:                   config.Agent,
+		ContextWindow:           config.ContextWindow,
+		PromptCompression:       config.PromptCompression,
+		Streaming:               config.Streaming,
+		StreamFailover:          config.StreamFailover,
+		StreamBackpressure:      config.StreamBackpressure,
+		ResponseBuffering:       config.ResponseBuffering,
+		Idempotency:             config.Idempotency,
+		EndpointPolicy:          config.EndpointPolicy,
+		Chaos:                   config.Chaos,
+		ListModelsCache:         config.ListModelsCache,
+		ResponsesState:          config.ResponsesState,
+		RequestTags:             config.RequestTags,
+		PartialFailures:         config.PartialFailures,
+		Diagnostics:             config.Diagnostics,
+		SLOs:                    config.SLOs,
 	}
 	// Delete existing client config and create new one in a transaction
 	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
@@ -194,6 +213,24 @@ func (s *RDBConfigStore) GetClientConfig(ctx context.Context) (*ClientConfig, er
 		AllowedOrigins:          dbConfig.AllowedOrigins,
 		MaxRequestBodySizeMB:    dbConfig.MaxRequestBodySizeMB,
 		EnableLiteLLMFallbacks:  dbConfig.EnableLiteLLMFallbacks,
+		DirectKeyPolicy:         dbConfig.DirectKeyPolicy,
+		ImageInputs:             dbConfig.ImageInputs,
+		Agent:                   dbConfig.Agent,
+		ContextWindow:           dbConfig.ContextWindow,
+		PromptCompression:       dbConfig.PromptCompression,
+		Streaming:               dbConfig.Streaming,
+		StreamFailover:          dbConfig.StreamFailover,
+		StreamBackpressure:      dbConfig.StreamBackpressure,
+		ResponseBuffering:       dbConfig.ResponseBuffering,
+		Idempotency:             dbConfig.Idempotency,
+		EndpointPolicy:          dbConfig.EndpointPolicy,
+		Chaos:                   dbConfig.Chaos,
+		ListModelsCache:         dbConfig.ListModelsCache,
+		ResponsesState:          dbConfig.ResponsesState,
+		RequestTags:             dbConfig.RequestTags,
+		PartialFailures:         dbConfig.PartialFailures,
+		Diagnostics:             dbConfig.Diagnostics,
+		SLOs:                    dbConfig.SLOs,
 	}, nil
 }
 
@@ -213,6 +250,8 @@ func (s *RDBConfigStore) UpdateProvidersConfig(ctx context.Context, providers ma
 			ProxyConfig:              providerConfig.ProxyConfig,
 			SendBackRawResponse:      providerConfig.SendBackRawResponse,
 			CustomProviderConfig:     providerConfig.CustomProviderConfig,
+			MockConfig:               providerConfig.MockConfig,
+			Namespace:                providerConfig.Namespace,
 			ConfigHash:               providerConfig.ConfigHash,
 		}
 
@@ -238,6 +277,7 @@ func (s *RDBConfigStore) UpdateProvidersConfig(ctx context.Context, providers ma
 			dbKey := tables.TableKey{
 				Provider:         dbProvider.Name,
 				ProviderID:       dbProvider.ID,
+				Namespace:        dbProvider.Namespace,
 				KeyID:            key.ID,
 				Name:             key.Name,
 				Value:            key.Value,
@@ -246,6 +286,11 @@ func (s *RDBConfigStore) UpdateProvidersConfig(ctx context.Context, providers ma
 				AzureKeyConfig:   key.AzureKeyConfig,
 				VertexKeyConfig:  key.VertexKeyConfig,
 				BedrockKeyConfig: key.BedrockKeyConfig,
+				IsTest:           key.IsTest,
+				SpendLimit:       key.SpendLimit,
+				Region:           key.Region,
+				ProxyConfig:      key.ProxyConfig,
+				LastValidation:   key.LastValidation,
 				ConfigHash:       keyHash,
 			}
 
@@ -333,6 +378,7 @@ func (s *RDBConfigStore) UpdateProvider(ctx context.Context, provider schemas.Mo
 	dbProvider.ProxyConfig = configCopy.ProxyConfig
 	dbProvider.SendBackRawResponse = configCopy.SendBackRawResponse
 	dbProvider.CustomProviderConfig = configCopy.CustomProviderConfig
+	dbProvider.MockConfig = configCopy.MockConfig
 	dbProvider.ConfigHash = configCopy.ConfigHash
 
 	// Save the updated provider
@@ -362,6 +408,7 @@ func (s *RDBConfigStore) UpdateProvider(ctx context.Context, provider schemas.Mo
 		dbKey := tables.TableKey{
 			Provider:         dbProvider.Name,
 			ProviderID:       dbProvider.ID,
+			Namespace:        dbProvider.Namespace,
 			KeyID:            key.ID,
 			Name:             key.Name,
 			Value:            key.Value,
@@ -370,6 +417,11 @@ func (s *RDBConfigStore) UpdateProvider(ctx context.Context, provider schemas.Mo
 			AzureKeyConfig:   key.AzureKeyConfig,
 			VertexKeyConfig:  key.VertexKeyConfig,
 			BedrockKeyConfig: key.BedrockKeyConfig,
+			IsTest:           key.IsTest,
+			SpendLimit:       key.SpendLimit,
+			Region:           key.Region,
+			ProxyConfig:      key.ProxyConfig,
+			LastValidation:   key.LastValidation,
 			ConfigHash:       keyHash,
 		}
 
@@ -450,6 +502,8 @@ func (s *RDBConfigStore) AddProvider(ctx context.Context, provider schemas.Model
 		ProxyConfig:              configCopy.ProxyConfig,
 		SendBackRawResponse:      configCopy.SendBackRawResponse,
 		CustomProviderConfig:     configCopy.CustomProviderConfig,
+		MockConfig:               configCopy.MockConfig,
+		Namespace:                configCopy.Namespace,
 		ConfigHash:               configCopy.ConfigHash,
 	}
 
@@ -468,6 +522,7 @@ func (s *RDBConfigStore) AddProvider(ctx context.Context, provider schemas.Model
 		dbKey := tables.TableKey{
 			Provider:         dbProvider.Name,
 			ProviderID:       dbProvider.ID,
+			Namespace:        dbProvider.Namespace,
 			KeyID:            key.ID,
 			Name:             key.Name,
 			Value:            key.Value,
@@ -476,6 +531,11 @@ func (s *RDBConfigStore) AddProvider(ctx context.Context, provider schemas.Model
 			AzureKeyConfig:   key.AzureKeyConfig,
 			VertexKeyConfig:  key.VertexKeyConfig,
 			BedrockKeyConfig: key.BedrockKeyConfig,
+			IsTest:           key.IsTest,
+			SpendLimit:       key.SpendLimit,
+			Region:           key.Region,
+			ProxyConfig:      key.ProxyConfig,
+			LastValidation:   key.LastValidation,
 			ConfigHash:       keyHash,
 		}
 
@@ -627,6 +687,19 @@ func (s *RDBConfigStore) GetProvidersConfig(ctx context.Context) (map[schemas.Mo
 				bedrockConfig = &bedrockConfigCopy
 			}
 
+			// Process the authentication of the proxy of the key if present
+			proxyConfig := dbKey.ProxyConfig
+			if proxyConfig != nil {
+				proxyConfigCopy := *proxyConfig
+				if processedUsername, err := envutils.ProcessEnvValue(proxyConfig.Username); err == nil {
+					proxyConfigCopy.Username = processedUsername
+				}
+				if processedPassword, err := envutils.ProcessEnvValue(proxyConfig.Password); err == nil {
+					proxyConfigCopy.Password = processedPassword
+				}
+				proxyConfig = &proxyConfigCopy
+			}
+
 			keys[i] = schemas.Key{
 				ID:               dbKey.KeyID,
 				Name:             dbKey.Name,
@@ -636,6 +709,11 @@ func (s *RDBConfigStore) GetProvidersConfig(ctx context.Context) (map[schemas.Mo
 				AzureKeyConfig:   azureConfig,
 				VertexKeyConfig:  vertexConfig,
 				BedrockKeyConfig: bedrockConfig,
+				IsTest:           dbKey.IsTest,
+				SpendLimit:       dbKey.SpendLimit,
+				Region:           dbKey.Region,
+				ProxyConfig:      proxyConfig,
+				LastValidation:   dbKey.LastValidation,
 			}
 		}
 		providerConfig := ProviderConfig{
@@ -645,6 +723,8 @@ func (s *RDBConfigStore) GetProvidersConfig(ctx context.Context) (map[schemas.Mo
 			ProxyConfig:              dbProvider.ProxyConfig,
 			SendBackRawResponse:      dbProvider.SendBackRawResponse,
 			CustomProviderConfig:     dbProvider.CustomProviderConfig,
+			MockConfig:               dbProvider.MockConfig,
+			Namespace:                dbProvider.Namespace,
 			ConfigHash:               dbProvider.ConfigHash,
 		}
 		processedProviders[provider] = providerConfig
@@ -1241,7 +1321,7 @@ func (s *RDBConfigStore) UpdateVirtualKey(ctx context.Context, virtualKey *table
 	// Update virtual key
 	// Use Select() to explicitly update all fields, including nil pointer fields
 	// This ensures TeamID gets set to NULL when switching from team to customer association
-	if err := txDB.WithContext(ctx).Select("name", "description", "value", "is_active", "team_id", "customer_id", "budget_id", "rate_limit_id", "updated_at").Updates(virtualKey).Error; err != nil {
+	if err := txDB.WithContext(ctx).Select("name", "description", "value", "is_active", "team_id", "customer_id", "budget_id", "rate_limit_id", "default_params", "system_prompt", "dedupe_window", "system_prompt_policy", "end_user_limits", "residency_policy", "max_output_tokens", "previous_value", "previous_value_expires_at", "rotated_at", "revoked_at", "updated_at").Updates(virtualKey).Error; err != nil {
 		return s.parseGormError(err)
 	}
 	return nil
@@ -1268,6 +1348,16 @@ func (s *RDBConfigStore) GetKeysByProvider(ctx context.Context, provider string)
 	return keys, nil
 }
 
+// UpdateKeyValidation records the last validation call made with a key, leaving the rest of the key untouched.
+func (s *RDBConfigStore) UpdateKeyValidation(ctx context.Context, keyID string, validation *schemas.KeyValidation) error {
+	var key tables.TableKey
+	if err := s.db.WithContext(ctx).Where("key_id = ?", keyID).First(&key).Error; err != nil {
+		return s.parseGormError(err)
+	}
+	key.LastValidation = validation
+	return s.db.WithContext(ctx).Model(&key).Select("last_validation_status", "last_validation_error", "last_validated_at").Updates(&key).Error
+}
+
 // GetAllRedactedKeys retrieves all redacted keys from the database.
 func (s *RDBConfigStore) GetAllRedactedKeys(ctx context.Context, ids []string) ([]schemas.Key, error) {
 	var keys []tables.TableKey
@@ -1690,6 +1780,34 @@ func (s *RDBConfigStore) UpdateBudget(ctx context.Context, budget *tables.TableB
 	return nil
 }
 
+// GetKeySpends retrieves the spend of all test keys from the database.
+func (s *RDBConfigStore) GetKeySpends(ctx context.Context) ([]tables.TableKeySpend, error) {
+	var spends []tables.TableKeySpend
+	if err := s.db.WithContext(ctx).Find(&spends).Error; err != nil {
+		return nil, err
+	}
+	return spends, nil
+}
+
+// UpdateKeySpend creates or updates the spend of a test key in the database.
+func (s *RDBConfigStore) UpdateKeySpend(ctx context.Context, spend *tables.TableKeySpend, tx ...*gorm.DB) error {
+	var txDB *gorm.DB
+	if len(tx) > 0 {
+		txDB = tx[0]
+	} else {
+		txDB = s.db
+	}
+	if err := txDB.WithContext(ctx).Save(spend).Error; err != nil {
+		return s.parseGormError(err)
+	}
+	return nil
+}
+
+// DeleteKeySpend deletes the spend of a test key from the database, resetting it to zero.
+func (s *RDBConfigStore) DeleteKeySpend(ctx context.Context, keyID string) error {
+	return s.db.WithContext(ctx).Delete(&tables.TableKeySpend{}, "key_id = ?", keyID).Error
+}
+
 // GetGovernanceConfig retrieves the governance configuration from the database.
 func (s *RDBConfigStore) GetGovernanceConfig(ctx context.Context) (*GovernanceConfig, error) {
 	var virtualKeys []tables.TableVirtualKey
@@ -1903,6 +2021,579 @@ func (s *RDBConfigStore) DeleteSession(ctx context.Context, token string) error
 	return s.db.WithContext(ctx).Delete(&tables.SessionsTable{}, "token = ?", token).Error
 }
 
+// AcquireLease acquires the lease for the holder until ttl from now, or renews it when the holder already holds it.
+// Returns false when another holder holds the lease and it has not expired.
+func (s *RDBConfigStore) AcquireLease(ctx context.Context, name string, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	lease := tables.TableLease{Name: name, Holder: holder, ExpiresAt: now.Add(ttl), UpdatedAt: now}
+	// Create the lease the first time, concurrent creations are settled by the conditional update below
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&lease).Error; err != nil {
+		return false, s.parseGormError(err)
+	}
+	result := s.db.WithContext(ctx).Model(&tables.TableLease{}).
+		Where("name = ? AND (holder = ? OR expires_at < ?)", name, holder, now).
+		Updates(map[string]any{"holder": holder, "expires_at": lease.ExpiresAt, "updated_at": now})
+	if result.Error != nil {
+		return false, s.parseGormError(result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ReleaseLease releases the lease when the holder holds it, so that another holder can acquire it without waiting
+// for it to expire.
+func (s *RDBConfigStore) ReleaseLease(ctx context.Context, name string, holder string) error {
+	return s.db.WithContext(ctx).Delete(&tables.TableLease{}, "name = ? AND holder = ?", name, holder).Error
+}
+
+// GetNamespaces retrieves all namespaces from the database.
+func (s *RDBConfigStore) GetNamespaces(ctx context.Context) ([]tables.TableNamespace, error) {
+	var namespaces []tables.TableNamespace
+	if err := s.db.WithContext(ctx).Order("name ASC").Find(&namespaces).Error; err != nil {
+		return nil, err
+	}
+	return namespaces, nil
+}
+
+// GetNamespace retrieves a namespace by its name.
+func (s *RDBConfigStore) GetNamespace(ctx context.Context, name string) (*tables.TableNamespace, error) {
+	var namespace tables.TableNamespace
+	if err := s.db.WithContext(ctx).First(&namespace, "name = ?", name).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &namespace, nil
+}
+
+// GetNamespaceByAdminUsername retrieves the namespace administered by the given username.
+func (s *RDBConfigStore) GetNamespaceByAdminUsername(ctx context.Context, username string) (*tables.TableNamespace, error) {
+	var namespace tables.TableNamespace
+	if err := s.db.WithContext(ctx).First(&namespace, "admin_username = ?", username).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &namespace, nil
+}
+
+// CreateNamespace creates a new namespace in the database.
+func (s *RDBConfigStore) CreateNamespace(ctx context.Context, namespace *tables.TableNamespace, tx ...*gorm.DB) error {
+	var txDB *gorm.DB
+	if len(tx) > 0 {
+		txDB = tx[0]
+	} else {
+		txDB = s.db
+	}
+	if err := txDB.WithContext(ctx).Create(namespace).Error; err != nil {
+		return s.parseGormError(err)
+	}
+	return nil
+}
+
+// UpdateNamespace updates an existing namespace in the database.
+func (s *RDBConfigStore) UpdateNamespace(ctx context.Context, namespace *tables.TableNamespace, tx ...*gorm.DB) error {
+	var txDB *gorm.DB
+	if len(tx) > 0 {
+		txDB = tx[0]
+	} else {
+		txDB = s.db
+	}
+	if err := txDB.WithContext(ctx).Save(namespace).Error; err != nil {
+		return s.parseGormError(err)
+	}
+	return nil
+}
+
+// DeleteNamespace deletes a namespace from the database.
+// The entities of the namespace are not deleted, they have to be removed or moved beforehand.
+func (s *RDBConfigStore) DeleteNamespace(ctx context.Context, name string) error {
+	result := s.db.WithContext(ctx).Delete(&tables.TableNamespace{}, "name = ?", name)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetRevokedPayloadKeys retrieves all revoked payload encryption key references from the database.
+func (s *RDBConfigStore) GetRevokedPayloadKeys(ctx context.Context) ([]tables.TableRevokedPayloadKey, error) {
+	var keys []tables.TableRevokedPayloadKey
+	if err := s.db.WithContext(ctx).Order("revoked_at ASC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// AddRevokedPayloadKey records the revocation of a payload encryption key reference.
+// Revoking a key reference that is already revoked keeps its first revocation.
+func (s *RDBConfigStore) AddRevokedPayloadKey(ctx context.Context, key *tables.TableRevokedPayloadKey, tx ...*gorm.DB) error {
+	var txDB *gorm.DB
+	if len(tx) > 0 {
+		txDB = tx[0]
+	} else {
+		txDB = s.db
+	}
+	if err := txDB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(key).Error; err != nil {
+		return s.parseGormError(err)
+	}
+	return nil
+}
+
+// GetPipelines retrieves all pipelines from the database.
+func (s *RDBConfigStore) GetPipelines(ctx context.Context) ([]tables.TablePipeline, error) {
+	var pipelines []tables.TablePipeline
+	if err := s.db.WithContext(ctx).Order("name ASC").Find(&pipelines).Error; err != nil {
+		return nil, err
+	}
+	return pipelines, nil
+}
+
+// GetPipeline retrieves a pipeline by its name.
+func (s *RDBConfigStore) GetPipeline(ctx context.Context, name string) (*tables.TablePipeline, error) {
+	var pipeline tables.TablePipeline
+	if err := s.db.WithContext(ctx).First(&pipeline, "name = ?", name).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &pipeline, nil
+}
+
+// CreatePipeline creates a new pipeline in the database.
+func (s *RDBConfigStore) CreatePipeline(ctx context.Context, pipeline *tables.TablePipeline, tx ...*gorm.DB) error {
+	var txDB *gorm.DB
+	if len(tx) > 0 {
+		txDB = tx[0]
+	} else {
+		txDB = s.db
+	}
+	if err := txDB.WithContext(ctx).Create(pipeline).Error; err != nil {
+		return s.parseGormError(err)
+	}
+	return nil
+}
+
+// UpdatePipeline updates an existing pipeline in the database.
+func (s *RDBConfigStore) UpdatePipeline(ctx context.Context, pipeline *tables.TablePipeline, tx ...*gorm.DB) error {
+	var txDB *gorm.DB
+	if len(tx) > 0 {
+		txDB = tx[0]
+	} else {
+		txDB = s.db
+	}
+	if err := txDB.WithContext(ctx).Save(pipeline).Error; err != nil {
+		return s.parseGormError(err)
+	}
+	return nil
+}
+
+// DeletePipeline deletes a pipeline from the database.
+func (s *RDBConfigStore) DeletePipeline(ctx context.Context, name string) error {
+	result := s.db.WithContext(ctx).Delete(&tables.TablePipeline{}, "name = ?", name)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetTransformRules retrieves all transform rules from the database.
+func (s *RDBConfigStore) GetTransformRules(ctx context.Context) ([]tables.TableTransformRule, error) {
+	var rules []tables.TableTransformRule
+	if err := s.db.WithContext(ctx).Order("priority ASC, name ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetTransformRule retrieves a transform rule by its name.
+func (s *RDBConfigStore) GetTransformRule(ctx context.Context, name string) (*tables.TableTransformRule, error) {
+	var rule tables.TableTransformRule
+	if err := s.db.WithContext(ctx).First(&rule, "name = ?", name).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// CreateTransformRule creates a new transform rule in the database.
+func (s *RDBConfigStore) CreateTransformRule(ctx context.Context, rule *tables.TableTransformRule, tx ...*gorm.DB) error {
+	var txDB *gorm.DB
+	if len(tx) > 0 {
+		txDB = tx[0]
+	} else {
+		txDB = s.db
+	}
+	if err := txDB.WithContext(ctx).Create(rule).Error; err != nil {
+		return s.parseGormError(err)
+	}
+	return nil
+}
+
+// UpdateTransformRule updates an existing transform rule in the database.
+func (s *RDBConfigStore) UpdateTransformRule(ctx context.Context, rule *tables.TableTransformRule, tx ...*gorm.DB) error {
+	var txDB *gorm.DB
+	if len(tx) > 0 {
+		txDB = tx[0]
+	} else {
+		txDB = s.db
+	}
+	if err := txDB.WithContext(ctx).Save(rule).Error; err != nil {
+		return s.parseGormError(err)
+	}
+	return nil
+}
+
+// DeleteTransformRule deletes a transform rule from the database.
+func (s *RDBConfigStore) DeleteTransformRule(ctx context.Context, name string) error {
+	result := s.db.WithContext(ctx).Delete(&tables.TableTransformRule{}, "name = ?", name)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetFallbackChains retrieves all fallback chains from the database.
+func (s *RDBConfigStore) GetFallbackChains(ctx context.Context) ([]tables.TableFallbackChain, error) {
+	var chains []tables.TableFallbackChain
+	if err := s.db.WithContext(ctx).Order("alias ASC").Find(&chains).Error; err != nil {
+		return nil, err
+	}
+	return chains, nil
+}
+
+// GetFallbackChain retrieves the fallback chain of a model alias.
+func (s *RDBConfigStore) GetFallbackChain(ctx context.Context, alias string) (*tables.TableFallbackChain, error) {
+	var chain tables.TableFallbackChain
+	if err := s.db.WithContext(ctx).First(&chain, "alias = ?", alias).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &chain, nil
+}
+
+// CreateFallbackChain creates a new fallback chain in the database.
+func (s *RDBConfigStore) CreateFallbackChain(ctx context.Context, chain *tables.TableFallbackChain, tx ...*gorm.DB) error {
+	var txDB *gorm.DB
+	if len(tx) > 0 {
+		txDB = tx[0]
+	} else {
+		txDB = s.db
+	}
+	if err := txDB.WithContext(ctx).Create(chain).Error; err != nil {
+		return s.parseGormError(err)
+	}
+	return nil
+}
+
+// UpdateFallbackChain updates an existing fallback chain in the database.
+func (s *RDBConfigStore) UpdateFallbackChain(ctx context.Context, chain *tables.TableFallbackChain, tx ...*gorm.DB) error {
+	var txDB *gorm.DB
+	if len(tx) > 0 {
+		txDB = tx[0]
+	} else {
+		txDB = s.db
+	}
+	if err := txDB.WithContext(ctx).Save(chain).Error; err != nil {
+		return s.parseGormError(err)
+	}
+	return nil
+}
+
+// DeleteFallbackChain deletes the fallback chain of a model alias from the database.
+func (s *RDBConfigStore) DeleteFallbackChain(ctx context.Context, alias string) error {
+	result := s.db.WithContext(ctx).Delete(&tables.TableFallbackChain{}, "alias = ?", alias)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetExperiments retrieves all experiments from the database.
+func (s *RDBConfigStore) GetExperiments(ctx context.Context) ([]tables.TableExperiment, error) {
+	var experiments []tables.TableExperiment
+	if err := s.db.WithContext(ctx).Order("name ASC").Find(&experiments).Error; err != nil {
+		return nil, err
+	}
+	return experiments, nil
+}
+
+// GetExperiment retrieves an experiment by its name.
+func (s *RDBConfigStore) GetExperiment(ctx context.Context, name string) (*tables.TableExperiment, error) {
+	var experiment tables.TableExperiment
+	if err := s.db.WithContext(ctx).First(&experiment, "name = ?", name).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &experiment, nil
+}
+
+// CreateExperiment creates a new experiment in the database.
+func (s *RDBConfigStore) CreateExperiment(ctx context.Context, experiment *tables.TableExperiment, tx ...*gorm.DB) error {
+	var txDB *gorm.DB
+	if len(tx) > 0 {
+		txDB = tx[0]
+	} else {
+		txDB = s.db
+	}
+	if err := txDB.WithContext(ctx).Create(experiment).Error; err != nil {
+		return s.parseGormError(err)
+	}
+	return nil
+}
+
+// UpdateExperiment updates an existing experiment in the database.
+func (s *RDBConfigStore) UpdateExperiment(ctx context.Context, experiment *tables.TableExperiment, tx ...*gorm.DB) error {
+	var txDB *gorm.DB
+	if len(tx) > 0 {
+		txDB = tx[0]
+	} else {
+		txDB = s.db
+	}
+	if err := txDB.WithContext(ctx).Save(experiment).Error; err != nil {
+		return s.parseGormError(err)
+	}
+	return nil
+}
+
+// DeleteExperiment deletes an experiment from the database.
+func (s *RDBConfigStore) DeleteExperiment(ctx context.Context, name string) error {
+	result := s.db.WithContext(ctx).Delete(&tables.TableExperiment{}, "name = ?", name)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetModelCapabilityOverrides retrieves all model capability overrides from the database.
+func (s *RDBConfigStore) GetModelCapabilityOverrides(ctx context.Context) ([]tables.TableModelCapability, error) {
+	var overrides []tables.TableModelCapability
+	if err := s.db.WithContext(ctx).Order("provider ASC, model ASC").Find(&overrides).Error; err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// UpsertModelCapabilityOverride creates or replaces the capability override of a model in the database.
+func (s *RDBConfigStore) UpsertModelCapabilityOverride(ctx context.Context, override *tables.TableModelCapability, tx ...*gorm.DB) error {
+	var txDB *gorm.DB
+	if len(tx) > 0 {
+		txDB = tx[0]
+	} else {
+		txDB = s.db
+	}
+	if err := txDB.WithContext(ctx).Save(override).Error; err != nil {
+		return s.parseGormError(err)
+	}
+	return nil
+}
+
+// DeleteModelCapabilityOverride deletes the capability override of a model from the database.
+func (s *RDBConfigStore) DeleteModelCapabilityOverride(ctx context.Context, provider, model string) error {
+	result := s.db.WithContext(ctx).Delete(&tables.TableModelCapability{}, "provider = ? AND model = ?", provider, model)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetModelPricingOverrides retrieves all model pricing overrides from the database.
+func (s *RDBConfigStore) GetModelPricingOverrides(ctx context.Context) ([]tables.TableModelPricingOverride, error) {
+	var overrides []tables.TableModelPricingOverride
+	if err := s.db.WithContext(ctx).Order("provider ASC, model ASC").Find(&overrides).Error; err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// UpsertModelPricingOverride creates or replaces the pricing override of a model in the database.
+func (s *RDBConfigStore) UpsertModelPricingOverride(ctx context.Context, override *tables.TableModelPricingOverride, tx ...*gorm.DB) error {
+	var txDB *gorm.DB
+	if len(tx) > 0 {
+		txDB = tx[0]
+	} else {
+		txDB = s.db
+	}
+	if err := txDB.WithContext(ctx).Save(override).Error; err != nil {
+		return s.parseGormError(err)
+	}
+	return nil
+}
+
+// DeleteModelPricingOverride deletes the pricing override of a model from the database.
+func (s *RDBConfigStore) DeleteModelPricingOverride(ctx context.Context, provider, model string) error {
+	result := s.db.WithContext(ctx).Delete(&tables.TableModelPricingOverride{}, "provider = ? AND model = ?", provider, model)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetModelDeprecations retrieves all model deprecations from the database.
+func (s *RDBConfigStore) GetModelDeprecations(ctx context.Context) ([]tables.TableModelDeprecation, error) {
+	var deprecations []tables.TableModelDeprecation
+	if err := s.db.WithContext(ctx).Order("sunset_date ASC, model ASC").Find(&deprecations).Error; err != nil {
+		return nil, err
+	}
+	return deprecations, nil
+}
+
+// GetModelDeprecation retrieves the deprecation of a model.
+func (s *RDBConfigStore) GetModelDeprecation(ctx context.Context, model string) (*tables.TableModelDeprecation, error) {
+	var deprecation tables.TableModelDeprecation
+	if err := s.db.WithContext(ctx).First(&deprecation, "model = ?", model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &deprecation, nil
+}
+
+// CreateModelDeprecation creates a new model deprecation in the database.
+func (s *RDBConfigStore) CreateModelDeprecation(ctx context.Context, deprecation *tables.TableModelDeprecation, tx ...*gorm.DB) error {
+	var txDB *gorm.DB
+	if len(tx) > 0 {
+		txDB = tx[0]
+	} else {
+		txDB = s.db
+	}
+	if err := txDB.WithContext(ctx).Create(deprecation).Error; err != nil {
+		return s.parseGormError(err)
+	}
+	return nil
+}
+
+// UpdateModelDeprecation updates an existing model deprecation in the database.
+func (s *RDBConfigStore) UpdateModelDeprecation(ctx context.Context, deprecation *tables.TableModelDeprecation, tx ...*gorm.DB) error {
+	var txDB *gorm.DB
+	if len(tx) > 0 {
+		txDB = tx[0]
+	} else {
+		txDB = s.db
+	}
+	if err := txDB.WithContext(ctx).Save(deprecation).Error; err != nil {
+		return s.parseGormError(err)
+	}
+	return nil
+}
+
+// DeleteModelDeprecation deletes the deprecation of a model from the database.
+func (s *RDBConfigStore) DeleteModelDeprecation(ctx context.Context, model string) error {
+	result := s.db.WithContext(ctx).Delete(&tables.TableModelDeprecation{}, "model = ?", model)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetConversation retrieves a conversation with its history from the database.
+func (s *RDBConfigStore) GetConversation(ctx context.Context, id string) (*tables.TableConversation, error) {
+	var conversation tables.TableConversation
+	if err := s.db.WithContext(ctx).First(&conversation, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+// GetConversations retrieves the conversations matching the filter from the database.
+func (s *RDBConfigStore) GetConversations(ctx context.Context, filter schemas.ConversationFilter) ([]tables.TableConversation, error) {
+	var conversations []tables.TableConversation
+	if err := conversationsQuery(s.db.WithContext(ctx), filter).Order("created_at ASC").Find(&conversations).Error; err != nil {
+		return nil, err
+	}
+	return conversations, nil
+}
+
+// UpsertConversation creates or replaces a conversation in the database.
+func (s *RDBConfigStore) UpsertConversation(ctx context.Context, conversation *tables.TableConversation, tx ...*gorm.DB) error {
+	var txDB *gorm.DB
+	if len(tx) > 0 {
+		txDB = tx[0]
+	} else {
+		txDB = s.db
+	}
+	if err := txDB.WithContext(ctx).Save(conversation).Error; err != nil {
+		return s.parseGormError(err)
+	}
+	return nil
+}
+
+// DeleteConversation deletes a conversation from the database.
+func (s *RDBConfigStore) DeleteConversation(ctx context.Context, id string) error {
+	result := s.db.WithContext(ctx).Delete(&tables.TableConversation{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteConversations deletes the conversations matching the filter from the database, and returns how many were
+// deleted.
+func (s *RDBConfigStore) DeleteConversations(ctx context.Context, filter schemas.ConversationFilter) (int64, error) {
+	result := conversationsQuery(s.db.WithContext(ctx), filter).Delete(&tables.TableConversation{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// conversationsQuery filters the conversations by the fields of the filter that are set
+func conversationsQuery(db *gorm.DB, filter schemas.ConversationFilter) *gorm.DB {
+	query := db.Model(&tables.TableConversation{})
+	if filter.Owner != "" {
+		query = query.Where("owner = ?", filter.Owner)
+	}
+	if filter.User != "" {
+		query = query.Where("end_user = ?", filter.User)
+	}
+	if !filter.UpdatedBefore.IsZero() {
+		query = query.Where("updated_at < ?", filter.UpdatedBefore)
+	}
+	if filter.Owner == "" && filter.User == "" && filter.UpdatedBefore.IsZero() {
+		// Deleting without conditions is refused by gorm
+		query = query.Where("1 = 1")
+	}
+	return query
+}
+
 // ExecuteTransaction executes a transaction.
 func (s *RDBConfigStore) ExecuteTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
 	return s.db.WithContext(ctx).Transaction(fn)
@@ -4,6 +4,7 @@ package configstore
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/maximhq/bifrost/core/schemas"
 	"github.com/maximhq/bifrost/framework/configstore/tables"
@@ -112,6 +113,15 @@ type ConfigStore interface {
 	UpdateBudget(ctx context.Context, budget *tables.TableBudget, tx ...*gorm.DB) error
 	UpdateBudgets(ctx context.Context, budgets []*tables.TableBudget, tx ...*gorm.DB) error
 
+	// Test key spend CRUD
+	GetKeySpends(ctx context.Context) ([]tables.TableKeySpend, error)
+	UpdateKeySpend(ctx context.Context, spend *tables.TableKeySpend, tx ...*gorm.DB) error
+	DeleteKeySpend(ctx context.Context, keyID string) error
+
+	// Leases
+	AcquireLease(ctx context.Context, name string, holder string, ttl time.Duration) (bool, error)
+	ReleaseLease(ctx context.Context, name string, holder string) error
+
 	// Governance config CRUD
 	GetGovernanceConfig(ctx context.Context) (*GovernanceConfig, error)
 
@@ -123,6 +133,70 @@ type ConfigStore interface {
 	GetProxyConfig(ctx context.Context) (*tables.GlobalProxyConfig, error)
 	UpdateProxyConfig(ctx context.Context, config *tables.GlobalProxyConfig) error
 
+	// Namespace CRUD
+	GetNamespaces(ctx context.Context) ([]tables.TableNamespace, error)
+	GetNamespace(ctx context.Context, name string) (*tables.TableNamespace, error)
+	GetNamespaceByAdminUsername(ctx context.Context, username string) (*tables.TableNamespace, error)
+	CreateNamespace(ctx context.Context, namespace *tables.TableNamespace, tx ...*gorm.DB) error
+	UpdateNamespace(ctx context.Context, namespace *tables.TableNamespace, tx ...*gorm.DB) error
+	DeleteNamespace(ctx context.Context, name string) error
+
+	// Revoked payload encryption keys
+	GetRevokedPayloadKeys(ctx context.Context) ([]tables.TableRevokedPayloadKey, error)
+	AddRevokedPayloadKey(ctx context.Context, key *tables.TableRevokedPayloadKey, tx ...*gorm.DB) error
+
+	// Pipeline CRUD
+	GetPipelines(ctx context.Context) ([]tables.TablePipeline, error)
+	GetPipeline(ctx context.Context, name string) (*tables.TablePipeline, error)
+	CreatePipeline(ctx context.Context, pipeline *tables.TablePipeline, tx ...*gorm.DB) error
+	UpdatePipeline(ctx context.Context, pipeline *tables.TablePipeline, tx ...*gorm.DB) error
+	DeletePipeline(ctx context.Context, name string) error
+
+	// Transform rule CRUD
+	GetTransformRules(ctx context.Context) ([]tables.TableTransformRule, error)
+	GetTransformRule(ctx context.Context, name string) (*tables.TableTransformRule, error)
+	CreateTransformRule(ctx context.Context, rule *tables.TableTransformRule, tx ...*gorm.DB) error
+	UpdateTransformRule(ctx context.Context, rule *tables.TableTransformRule, tx ...*gorm.DB) error
+	DeleteTransformRule(ctx context.Context, name string) error
+
+	// Fallback chain CRUD
+	GetFallbackChains(ctx context.Context) ([]tables.TableFallbackChain, error)
+	GetFallbackChain(ctx context.Context, alias string) (*tables.TableFallbackChain, error)
+	CreateFallbackChain(ctx context.Context, chain *tables.TableFallbackChain, tx ...*gorm.DB) error
+	UpdateFallbackChain(ctx context.Context, chain *tables.TableFallbackChain, tx ...*gorm.DB) error
+	DeleteFallbackChain(ctx context.Context, alias string) error
+
+	// Experiment CRUD
+	GetExperiments(ctx context.Context) ([]tables.TableExperiment, error)
+	GetExperiment(ctx context.Context, name string) (*tables.TableExperiment, error)
+	CreateExperiment(ctx context.Context, experiment *tables.TableExperiment, tx ...*gorm.DB) error
+	UpdateExperiment(ctx context.Context, experiment *tables.TableExperiment, tx ...*gorm.DB) error
+	DeleteExperiment(ctx context.Context, name string) error
+
+	// Model capability overrides CRUD
+	GetModelCapabilityOverrides(ctx context.Context) ([]tables.TableModelCapability, error)
+	UpsertModelCapabilityOverride(ctx context.Context, override *tables.TableModelCapability, tx ...*gorm.DB) error
+	DeleteModelCapabilityOverride(ctx context.Context, provider, model string) error
+
+	// Model pricing overrides CRUD
+	GetModelPricingOverrides(ctx context.Context) ([]tables.TableModelPricingOverride, error)
+	UpsertModelPricingOverride(ctx context.Context, override *tables.TableModelPricingOverride, tx ...*gorm.DB) error
+	DeleteModelPricingOverride(ctx context.Context, provider, model string) error
+
+	// Model deprecation CRUD
+	GetModelDeprecations(ctx context.Context) ([]tables.TableModelDeprecation, error)
+	GetModelDeprecation(ctx context.Context, model string) (*tables.TableModelDeprecation, error)
+	CreateModelDeprecation(ctx context.Context, deprecation *tables.TableModelDeprecation, tx ...*gorm.DB) error
+	UpdateModelDeprecation(ctx context.Context, deprecation *tables.TableModelDeprecation, tx ...*gorm.DB) error
+	DeleteModelDeprecation(ctx context.Context, model string) error
+
+	// Conversation CRUD
+	GetConversation(ctx context.Context, id string) (*tables.TableConversation, error)
+	GetConversations(ctx context.Context, filter schemas.ConversationFilter) ([]tables.TableConversation, error)
+	UpsertConversation(ctx context.Context, conversation *tables.TableConversation, tx ...*gorm.DB) error
+	DeleteConversation(ctx context.Context, id string) error
+	DeleteConversations(ctx context.Context, filter schemas.ConversationFilter) (int64, error)
+
 	// Session CRUD
 	GetSession(ctx context.Context, token string) (*tables.SessionsTable, error)
 	CreateSession(ctx context.Context, session *tables.SessionsTable) error
@@ -137,6 +211,7 @@ type ConfigStore interface {
 	GetKeysByIDs(ctx context.Context, ids []string) ([]tables.TableKey, error)
 	GetKeysByProvider(ctx context.Context, provider string) ([]tables.TableKey, error)
 	GetAllRedactedKeys(ctx context.Context, ids []string) ([]schemas.Key, error) // leave ids empty to get all
+	UpdateKeyValidation(ctx context.Context, keyID string, validation *schemas.KeyValidation) error
 
 	// Generic transaction manager
 	ExecuteTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error
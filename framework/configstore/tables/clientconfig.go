@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/maximhq/bifrost/core/schemas"
 	"gorm.io/gorm"
 )
 
@@ -15,21 +16,76 @@ type TableClientConfig struct {
 	AllowedOriginsJSON      string `gorm:"type:text" json:"-"` // JSON serialized []string
 	InitialPoolSize         int    `gorm:"default:300" json:"initial_pool_size"`
 	EnableLogging           bool   `gorm:"" json:"enable_logging"`
-	DisableContentLogging   bool   `gorm:"default:false" json:"disable_content_logging"`                // DisableContentLogging controls whether sensitive content (inputs, outputs, embeddings, etc.) is logged
-	LogRetentionDays        int    `gorm:"default:365" json:"log_retention_days" validate:"min=1"`      // Number of days to retain logs (minimum 1 day)
+	DisableContentLogging   bool   `gorm:"default:false" json:"disable_content_logging"`           // DisableContentLogging controls whether sensitive content (inputs, outputs, embeddings, etc.) is logged
+	LogRetentionDays        int    `gorm:"default:365" json:"log_retention_days" validate:"min=1"` // Number of days to retain logs (minimum 1 day)
 	EnableGovernance        bool   `gorm:"" json:"enable_governance"`
 	EnforceGovernanceHeader bool   `gorm:"" json:"enforce_governance_header"`
 	AllowDirectKeys         bool   `gorm:"" json:"allow_direct_keys"`
 	MaxRequestBodySizeMB    int    `gorm:"default:100" json:"max_request_body_size_mb"`
 	// LiteLLM fallback flag
 	EnableLiteLLMFallbacks bool `gorm:"column:enable_litellm_fallbacks;default:false" json:"enable_litellm_fallbacks"`
+	// Direct key policy
+	DirectKeyPolicyJSON string `gorm:"type:text" json:"-"` // JSON serialized schemas.DirectKeyPolicy
+	// Image input normalization
+	ImageInputsJSON string `gorm:"type:text" json:"-"` // JSON serialized schemas.ImageInputConfig
+	// Agent endpoint
+	AgentJSON string `gorm:"type:text" json:"-"` // JSON serialized schemas.AgentConfig
+	// Pre-flight context window check
+	ContextWindowJSON string `gorm:"type:text" json:"-"` // JSON serialized schemas.ContextWindowConfig
+	// Prompt compression
+	PromptCompressionJSON string `gorm:"type:text" json:"-"` // JSON serialized schemas.PromptCompressionConfig
+	// Server-Sent Events streams
+	StreamingJSON string `gorm:"type:text" json:"-"` // JSON serialized schemas.StreamingConfig
+	// Mid-stream failover
+	StreamFailoverJSON string `gorm:"type:text" json:"-"` // JSON serialized schemas.StreamFailoverConfig
+	// Stream backpressure
+	StreamBackpressureJSON string `gorm:"type:text" json:"-"` // JSON serialized schemas.StreamBackpressureConfig
+	// Response buffering
+	ResponseBufferingJSON string `gorm:"type:text" json:"-"` // JSON serialized schemas.ResponseBufferingConfig
+	// Idempotency
+	IdempotencyJSON string `gorm:"type:text" json:"-"` // JSON serialized schemas.IdempotencyConfig
+	// Endpoint policy
+	EndpointPolicyJSON string `gorm:"type:text" json:"-"` // JSON serialized schemas.EndpointPolicy
+	// Chaos
+	ChaosJSON string `gorm:"type:text" json:"-"` // JSON serialized schemas.ChaosConfig
+	// List models cache
+	ListModelsCacheJSON string `gorm:"type:text" json:"-"` // JSON serialized schemas.ListModelsCacheConfig
+	// Responses state
+	ResponsesStateJSON string `gorm:"type:text" json:"-"` // JSON serialized schemas.ResponsesStateConfig
+	// Request tags
+	RequestTagsJSON string `gorm:"type:text" json:"-"` // JSON serialized schemas.RequestTagsConfig
+	// Partial failures
+	PartialFailuresJSON string `gorm:"type:text" json:"-"` // JSON serialized schemas.PartialFailureConfig
+	// Diagnostics
+	DiagnosticsJSON string `gorm:"type:text" json:"-"` // JSON serialized schemas.DiagnosticsConfig
+	// Service level objectives
+	SLOsJSON string `gorm:"column:slos_json;type:text" json:"-"` // JSON serialized schemas.SLOConfig
 
 	CreatedAt time.Time `gorm:"index;not null" json:"created_at"`
 	UpdatedAt time.Time `gorm:"index;not null" json:"updated_at"`
 
 	// Virtual fields for runtime use (not stored in DB)
 	PrometheusLabels []string `gorm:"-" json:"prometheus_labels"`
-	AllowedOrigins   []string `gorm:"-" json:"allowed_origins,omitempty"`	
+	AllowedOrigins   []string `gorm:"-" json:"allowed_origins,omitempty"`
+
+	DirectKeyPolicy    *schemas.DirectKeyPolicy          `gorm:"-" json:"direct_key_policy,omitempty"`
+	ImageInputs        *schemas.ImageInputConfig         `gorm:"-" json:"image_inputs,omitempty"`
+	Agent              *schemas.AgentConfig              `gorm:"-" json:"agent,omitempty"`
+	ContextWindow      *schemas.ContextWindowConfig      `gorm:"-" json:"context_window,omitempty"`
+	PromptCompression  *schemas.PromptCompressionConfig  `gorm:"-" json:"prompt_compression,omitempty"`
+	Streaming          *schemas.StreamingConfig          `gorm:"-" json:"streaming,omitempty"`
+	StreamFailover     *schemas.StreamFailoverConfig     `gorm:"-" json:"stream_failover,omitempty"`
+	StreamBackpressure *schemas.StreamBackpressureConfig `gorm:"-" json:"stream_backpressure,omitempty"`
+	ResponseBuffering  *schemas.ResponseBufferingConfig  `gorm:"-" json:"response_buffering,omitempty"`
+	Idempotency        *schemas.IdempotencyConfig        `gorm:"-" json:"idempotency,omitempty"`
+	EndpointPolicy     *schemas.EndpointPolicy           `gorm:"-" json:"endpoint_policy,omitempty"`
+	Chaos              *schemas.ChaosConfig              `gorm:"-" json:"chaos,omitempty"`
+	ListModelsCache    *schemas.ListModelsCacheConfig    `gorm:"-" json:"list_models_cache,omitempty"`
+	ResponsesState     *schemas.ResponsesStateConfig     `gorm:"-" json:"responses_state,omitempty"`
+	RequestTags        *schemas.RequestTagsConfig        `gorm:"-" json:"request_tags,omitempty"`
+	PartialFailures    *schemas.PartialFailureConfig     `gorm:"-" json:"partial_failures,omitempty"`
+	Diagnostics        *schemas.DiagnosticsConfig        `gorm:"-" json:"diagnostics,omitempty"`
+	SLOs               *schemas.SLOConfig                `gorm:"-" json:"slos,omitempty"`
 }
 
 // TableName sets the table name for each model
@@ -56,6 +112,168 @@ func (cc *TableClientConfig) BeforeSave(tx *gorm.DB) error {
 		cc.AllowedOriginsJSON = "[]"
 	}
 
+	cc.DirectKeyPolicyJSON = ""
+	if cc.DirectKeyPolicy != nil {
+		data, err := json.Marshal(cc.DirectKeyPolicy)
+		if err != nil {
+			return err
+		}
+		cc.DirectKeyPolicyJSON = string(data)
+	}
+
+	cc.ImageInputsJSON = ""
+	if cc.ImageInputs != nil {
+		data, err := json.Marshal(cc.ImageInputs)
+		if err != nil {
+			return err
+		}
+		cc.ImageInputsJSON = string(data)
+	}
+
+	cc.AgentJSON = ""
+	if cc.Agent != nil {
+		data, err := json.Marshal(cc.Agent)
+		if err != nil {
+			return err
+		}
+		cc.AgentJSON = string(data)
+	}
+
+	cc.ContextWindowJSON = ""
+	if cc.ContextWindow != nil {
+		data, err := json.Marshal(cc.ContextWindow)
+		if err != nil {
+			return err
+		}
+		cc.ContextWindowJSON = string(data)
+	}
+
+	cc.PromptCompressionJSON = ""
+	if cc.PromptCompression != nil {
+		data, err := json.Marshal(cc.PromptCompression)
+		if err != nil {
+			return err
+		}
+		cc.PromptCompressionJSON = string(data)
+	}
+
+	cc.StreamingJSON = ""
+	if cc.Streaming != nil {
+		data, err := json.Marshal(cc.Streaming)
+		if err != nil {
+			return err
+		}
+		cc.StreamingJSON = string(data)
+	}
+
+	cc.StreamFailoverJSON = ""
+	if cc.StreamFailover != nil {
+		data, err := json.Marshal(cc.StreamFailover)
+		if err != nil {
+			return err
+		}
+		cc.StreamFailoverJSON = string(data)
+	}
+
+	cc.StreamBackpressureJSON = ""
+	if cc.StreamBackpressure != nil {
+		data, err := json.Marshal(cc.StreamBackpressure)
+		if err != nil {
+			return err
+		}
+		cc.StreamBackpressureJSON = string(data)
+	}
+
+	cc.ResponseBufferingJSON = ""
+	if cc.ResponseBuffering != nil {
+		data, err := json.Marshal(cc.ResponseBuffering)
+		if err != nil {
+			return err
+		}
+		cc.ResponseBufferingJSON = string(data)
+	}
+
+	cc.IdempotencyJSON = ""
+	if cc.Idempotency != nil {
+		data, err := json.Marshal(cc.Idempotency)
+		if err != nil {
+			return err
+		}
+		cc.IdempotencyJSON = string(data)
+	}
+
+	cc.EndpointPolicyJSON = ""
+	if cc.EndpointPolicy != nil {
+		data, err := json.Marshal(cc.EndpointPolicy)
+		if err != nil {
+			return err
+		}
+		cc.EndpointPolicyJSON = string(data)
+	}
+
+	cc.ChaosJSON = ""
+	if cc.Chaos != nil {
+		data, err := json.Marshal(cc.Chaos)
+		if err != nil {
+			return err
+		}
+		cc.ChaosJSON = string(data)
+	}
+
+	cc.ListModelsCacheJSON = ""
+	if cc.ListModelsCache != nil {
+		data, err := json.Marshal(cc.ListModelsCache)
+		if err != nil {
+			return err
+		}
+		cc.ListModelsCacheJSON = string(data)
+	}
+
+	cc.ResponsesStateJSON = ""
+	if cc.ResponsesState != nil {
+		data, err := json.Marshal(cc.ResponsesState)
+		if err != nil {
+			return err
+		}
+		cc.ResponsesStateJSON = string(data)
+	}
+
+	cc.RequestTagsJSON = ""
+	if cc.RequestTags != nil {
+		data, err := json.Marshal(cc.RequestTags)
+		if err != nil {
+			return err
+		}
+		cc.RequestTagsJSON = string(data)
+	}
+
+	cc.PartialFailuresJSON = ""
+	if cc.PartialFailures != nil {
+		data, err := json.Marshal(cc.PartialFailures)
+		if err != nil {
+			return err
+		}
+		cc.PartialFailuresJSON = string(data)
+	}
+
+	cc.DiagnosticsJSON = ""
+	if cc.Diagnostics != nil {
+		data, err := json.Marshal(cc.Diagnostics)
+		if err != nil {
+			return err
+		}
+		cc.DiagnosticsJSON = string(data)
+	}
+
+	cc.SLOsJSON = ""
+	if cc.SLOs != nil {
+		data, err := json.Marshal(cc.SLOs)
+		if err != nil {
+			return err
+		}
+		cc.SLOsJSON = string(data)
+	}
+
 	return nil
 }
 
@@ -73,5 +291,113 @@ func (cc *TableClientConfig) AfterFind(tx *gorm.DB) error {
 		}
 	}
 
+	if cc.DirectKeyPolicyJSON != "" {
+		if err := json.Unmarshal([]byte(cc.DirectKeyPolicyJSON), &cc.DirectKeyPolicy); err != nil {
+			return err
+		}
+	}
+
+	if cc.ImageInputsJSON != "" {
+		if err := json.Unmarshal([]byte(cc.ImageInputsJSON), &cc.ImageInputs); err != nil {
+			return err
+		}
+	}
+
+	if cc.AgentJSON != "" {
+		if err := json.Unmarshal([]byte(cc.AgentJSON), &cc.Agent); err != nil {
+			return err
+		}
+	}
+
+	if cc.ContextWindowJSON != "" {
+		if err := json.Unmarshal([]byte(cc.ContextWindowJSON), &cc.ContextWindow); err != nil {
+			return err
+		}
+	}
+
+	if cc.PromptCompressionJSON != "" {
+		if err := json.Unmarshal([]byte(cc.PromptCompressionJSON), &cc.PromptCompression); err != nil {
+			return err
+		}
+	}
+
+	if cc.StreamingJSON != "" {
+		if err := json.Unmarshal([]byte(cc.StreamingJSON), &cc.Streaming); err != nil {
+			return err
+		}
+	}
+
+	if cc.StreamFailoverJSON != "" {
+		if err := json.Unmarshal([]byte(cc.StreamFailoverJSON), &cc.StreamFailover); err != nil {
+			return err
+		}
+	}
+
+	if cc.StreamBackpressureJSON != "" {
+		if err := json.Unmarshal([]byte(cc.StreamBackpressureJSON), &cc.StreamBackpressure); err != nil {
+			return err
+		}
+	}
+
+	if cc.ResponseBufferingJSON != "" {
+		if err := json.Unmarshal([]byte(cc.ResponseBufferingJSON), &cc.ResponseBuffering); err != nil {
+			return err
+		}
+	}
+
+	if cc.IdempotencyJSON != "" {
+		if err := json.Unmarshal([]byte(cc.IdempotencyJSON), &cc.Idempotency); err != nil {
+			return err
+		}
+	}
+
+	if cc.EndpointPolicyJSON != "" {
+		if err := json.Unmarshal([]byte(cc.EndpointPolicyJSON), &cc.EndpointPolicy); err != nil {
+			return err
+		}
+	}
+
+	if cc.ChaosJSON != "" {
+		if err := json.Unmarshal([]byte(cc.ChaosJSON), &cc.Chaos); err != nil {
+			return err
+		}
+	}
+
+	if cc.ListModelsCacheJSON != "" {
+		if err := json.Unmarshal([]byte(cc.ListModelsCacheJSON), &cc.ListModelsCache); err != nil {
+			return err
+		}
+	}
+
+	if cc.ResponsesStateJSON != "" {
+		if err := json.Unmarshal([]byte(cc.ResponsesStateJSON), &cc.ResponsesState); err != nil {
+			return err
+		}
+	}
+
+	if cc.RequestTagsJSON != "" {
+		if err := json.Unmarshal([]byte(cc.RequestTagsJSON), &cc.RequestTags); err != nil {
+			return err
+		}
+	}
+
+	if cc.PartialFailuresJSON != "" {
+		if err := json.Unmarshal([]byte(cc.PartialFailuresJSON), &cc.PartialFailures); err != nil {
+			return err
+		}
+	}
+
+	if cc.DiagnosticsJSON != "" {
+		if err := json.Unmarshal([]byte(cc.DiagnosticsJSON), &cc.Diagnostics); err != nil {
+			return err
+		}
+	}
+
+	if cc.SLOsJSON != "" {
+		if err := json.Unmarshal([]byte(cc.SLOsJSON), &cc.SLOs); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,32 @@
+package tables
+
+import (
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// TableConversation represents a conversation stored by bifrost, with its whole history
+type TableConversation struct {
+	ID        string                `gorm:"primaryKey;type:varchar(255)" json:"id"`
+	Owner     string                `gorm:"type:varchar(255);index" json:"owner,omitempty"`
+	User      string                `gorm:"column:end_user;type:varchar(255);index" json:"user,omitempty"` // user is reserved by postgres
+	Messages  []schemas.ChatMessage `gorm:"type:text;serializer:json" json:"messages"`
+	CreatedAt time.Time             `gorm:"index;not null" json:"created_at"`
+	UpdatedAt time.Time             `gorm:"index;not null" json:"updated_at"`
+}
+
+// TableName sets the table name for each model
+func (TableConversation) TableName() string { return "conversations" }
+
+// ToSchema converts the table row to the conversation continued by bifrost
+func (c *TableConversation) ToSchema() schemas.Conversation {
+	return schemas.Conversation{
+		ID:        c.ID,
+		Owner:     c.Owner,
+		User:      c.User,
+		Messages:  c.Messages,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
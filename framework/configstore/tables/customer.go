@@ -1,12 +1,17 @@
 package tables
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // TableCustomer represents a customer entity with budget
 type TableCustomer struct {
-	ID       string  `gorm:"primaryKey;type:varchar(255)" json:"id"`
-	Name     string  `gorm:"type:varchar(255);not null" json:"name"`
-	BudgetID *string `gorm:"type:varchar(255);index" json:"budget_id,omitempty"`
+	ID        string  `gorm:"primaryKey;type:varchar(255)" json:"id"`
+	Name      string  `gorm:"type:varchar(255);not null" json:"name"`
+	Namespace string  `gorm:"type:varchar(255);not null;default:'default';index" json:"namespace"`
+	BudgetID  *string `gorm:"type:varchar(255);index" json:"budget_id,omitempty"`
 
 	// Relationships
 	Budget      *TableBudget      `gorm:"foreignKey:BudgetID" json:"budget,omitempty"`
@@ -19,3 +24,11 @@ type TableCustomer struct {
 
 // TableName sets the table name for each model
 func (TableCustomer) TableName() string { return "governance_customers" }
+
+// BeforeSave hook for TableCustomer to default the namespace
+func (c *TableCustomer) BeforeSave(tx *gorm.DB) error {
+	if c.Namespace == "" {
+		c.Namespace = DefaultNamespace
+	}
+	return nil
+}
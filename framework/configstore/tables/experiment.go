@@ -0,0 +1,34 @@
+package tables
+
+import (
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// TableExperiment represents an experiment splitting the requests of models between variants
+type TableExperiment struct {
+	Name        string                       `gorm:"primaryKey;type:varchar(255)" json:"name"`
+	Description string                       `gorm:"type:text" json:"description,omitempty"`
+	Enabled     bool                         `gorm:"default:false" json:"enabled"`
+	Models      []string                     `gorm:"type:text;serializer:json" json:"models"`
+	AssignBy    schemas.ExperimentAssignment `gorm:"type:varchar(20)" json:"assign_by,omitempty"`
+	Variants    []schemas.ExperimentVariant  `gorm:"type:text;serializer:json" json:"variants"`
+	CreatedAt   time.Time                    `gorm:"index;not null" json:"created_at"`
+	UpdatedAt   time.Time                    `gorm:"index;not null" json:"updated_at"`
+}
+
+// TableName sets the table name for each model
+func (TableExperiment) TableName() string { return "config_experiments" }
+
+// ToSchema converts the table row to the experiment applied by bifrost
+func (e *TableExperiment) ToSchema() schemas.Experiment {
+	return schemas.Experiment{
+		Name:        e.Name,
+		Description: e.Description,
+		Enabled:     e.Enabled,
+		Models:      e.Models,
+		AssignBy:    e.AssignBy,
+		Variants:    e.Variants,
+	}
+}
@@ -0,0 +1,28 @@
+package tables
+
+import (
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// TableFallbackChain represents the fallback chain of a model alias
+type TableFallbackChain struct {
+	Alias       string                `gorm:"primaryKey;type:varchar(255)" json:"alias"`
+	Description string                `gorm:"type:text" json:"description,omitempty"`
+	Hops        []schemas.FallbackHop `gorm:"type:text;serializer:json" json:"hops"`
+	CreatedAt   time.Time             `gorm:"index;not null" json:"created_at"`
+	UpdatedAt   time.Time             `gorm:"index;not null" json:"updated_at"`
+}
+
+// TableName sets the table name for each model
+func (TableFallbackChain) TableName() string { return "config_fallback_chains" }
+
+// ToSchema converts the table row to the fallback chain applied by bifrost
+func (c *TableFallbackChain) ToSchema() schemas.FallbackChain {
+	return schemas.FallbackChain{
+		Alias:       c.Alias,
+		Description: c.Description,
+		Hops:        c.Hops,
+	}
+}
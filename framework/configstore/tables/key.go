@@ -14,8 +14,9 @@ type TableKey struct {
 	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
 	Name       string    `gorm:"type:varchar(255);uniqueIndex:idx_key_name;not null" json:"name"`
 	ProviderID uint      `gorm:"index;not null" json:"provider_id"`
-	Provider   string    `gorm:"index;type:varchar(50)" json:"provider"`                          // ModelProvider as string
-	KeyID      string    `gorm:"type:varchar(255);uniqueIndex:idx_key_id;not null" json:"key_id"` // UUID from schemas.Key
+	Provider   string    `gorm:"index;type:varchar(50)" json:"provider"`                              // ModelProvider as string
+	Namespace  string    `gorm:"type:varchar(255);not null;default:'default';index" json:"namespace"` // Inherited from the provider
+	KeyID      string    `gorm:"type:varchar(255);uniqueIndex:idx_key_id;not null" json:"key_id"`     // UUID from schemas.Key
 	Value      string    `gorm:"type:text;not null" json:"value"`
 	ModelsJSON string    `gorm:"type:text" json:"-"` // JSON serialized []string
 	Weight     float64   `gorm:"default:1.0" json:"weight"`
@@ -25,10 +26,18 @@ type TableKey struct {
 	// Config hash is used to detect changes synced from config.json file
 	ConfigHash string `gorm:"type:varchar(255);null" json:"config_hash"`
 
+	// Test keys are disabled by governance once their spend reaches SpendLimit
+	IsTest     bool     `gorm:"default:false" json:"is_test"`
+	SpendLimit *float64 `json:"spend_limit,omitempty"`
+
+	// Residency region of the key, matched against the residency policies of virtual keys
+	Region string `gorm:"type:varchar(50)" json:"region,omitempty"`
+
 	// Azure config fields (embedded instead of separate table for simplicity)
 	AzureEndpoint        *string `gorm:"type:text" json:"azure_endpoint,omitempty"`
 	AzureAPIVersion      *string `gorm:"type:varchar(50)" json:"azure_api_version,omitempty"`
 	AzureDeploymentsJSON *string `gorm:"type:text" json:"-"` // JSON serialized map[string]string
+	AzureEndpointType    *string `gorm:"type:varchar(50)" json:"azure_endpoint_type,omitempty"`
 
 	// Vertex config fields (embedded)
 	VertexProjectID       *string `gorm:"type:varchar(255)" json:"vertex_project_id,omitempty"`
@@ -36,26 +45,47 @@ type TableKey struct {
 	VertexRegion          *string `gorm:"type:varchar(100)" json:"vertex_region,omitempty"`
 	VertexAuthCredentials *string `gorm:"type:text" json:"vertex_auth_credentials,omitempty"`
 	VertexDeploymentsJSON *string `gorm:"type:text" json:"-"` // JSON serialized map[string]string
+	VertexRegionsJSON     *string `gorm:"type:text" json:"-"` // JSON serialized []string of fallback regions
 
 	// Bedrock config fields (embedded)
-	BedrockAccessKey       *string `gorm:"type:varchar(255)" json:"bedrock_access_key,omitempty"`
-	BedrockSecretKey       *string `gorm:"type:text" json:"bedrock_secret_key,omitempty"`
-	BedrockSessionToken    *string `gorm:"type:text" json:"bedrock_session_token,omitempty"`
-	BedrockRegion          *string `gorm:"type:varchar(100)" json:"bedrock_region,omitempty"`
-	BedrockARN             *string `gorm:"type:text" json:"bedrock_arn,omitempty"`
-	BedrockDeploymentsJSON *string `gorm:"type:text" json:"-"` // JSON serialized map[string]string
+	BedrockAccessKey        *string `gorm:"type:varchar(255)" json:"bedrock_access_key,omitempty"`
+	BedrockSecretKey        *string `gorm:"type:text" json:"bedrock_secret_key,omitempty"`
+	BedrockSessionToken     *string `gorm:"type:text" json:"bedrock_session_token,omitempty"`
+	BedrockRegion           *string `gorm:"type:varchar(100)" json:"bedrock_region,omitempty"`
+	BedrockARN              *string `gorm:"type:text" json:"bedrock_arn,omitempty"`
+	BedrockDeploymentsJSON  *string `gorm:"type:text" json:"-"` // JSON serialized map[string]string
+	BedrockRegionsJSON      *string `gorm:"type:text" json:"-"` // JSON serialized []string of fallback regions
+	BedrockARNsJSON         *string `gorm:"type:text;column:bedrock_arns_json" json:"-"` // JSON serialized map[string]string of inference profile ARNs per region
+	BedrockGuardrailID      *string `gorm:"type:varchar(255)" json:"bedrock_guardrail_id,omitempty"`
+	BedrockGuardrailVersion *string `gorm:"type:varchar(50)" json:"bedrock_guardrail_version,omitempty"`
+	BedrockRoleARN          *string `gorm:"type:text" json:"bedrock_role_arn,omitempty"`
+	BedrockExternalID       *string `gorm:"type:text" json:"bedrock_external_id,omitempty"`
+	BedrockRoleSessionName  *string `gorm:"type:varchar(255)" json:"bedrock_role_session_name,omitempty"`
+
+	// Proxy overriding the proxy of the provider for this key
+	ProxyConfigJSON *string `gorm:"type:text" json:"-"` // JSON serialized schemas.ProxyConfig
+
+	// Last live validation call made with the key
+	LastValidationStatus *string    `gorm:"type:varchar(20)" json:"last_validation_status,omitempty"`
+	LastValidationError  *string    `gorm:"type:text" json:"last_validation_error,omitempty"`
+	LastValidatedAt      *time.Time `json:"last_validated_at,omitempty"`
 
 	// Virtual fields for runtime use (not stored in DB)
 	Models           []string                  `gorm:"-" json:"models"`
 	AzureKeyConfig   *schemas.AzureKeyConfig   `gorm:"-" json:"azure_key_config,omitempty"`
 	VertexKeyConfig  *schemas.VertexKeyConfig  `gorm:"-" json:"vertex_key_config,omitempty"`
 	BedrockKeyConfig *schemas.BedrockKeyConfig `gorm:"-" json:"bedrock_key_config,omitempty"`
+	ProxyConfig      *schemas.ProxyConfig      `gorm:"-" json:"proxy_config,omitempty"`
+	LastValidation   *schemas.KeyValidation    `gorm:"-" json:"last_validation,omitempty"`
 }
 
 // TableName sets the table name for each model
 func (TableKey) TableName() string { return "config_keys" }
 
 func (k *TableKey) BeforeSave(tx *gorm.DB) error {
+	if k.Namespace == "" {
+		k.Namespace = DefaultNamespace
+	}
 
 	if k.Models != nil {
 		data, err := json.Marshal(k.Models)
@@ -84,10 +114,17 @@ func (k *TableKey) BeforeSave(tx *gorm.DB) error {
 		} else {
 			k.AzureDeploymentsJSON = nil
 		}
+		if k.AzureKeyConfig.EndpointType != "" {
+			endpointType := string(k.AzureKeyConfig.EndpointType)
+			k.AzureEndpointType = &endpointType
+		} else {
+			k.AzureEndpointType = nil
+		}
 	} else {
 		k.AzureEndpoint = nil
 		k.AzureAPIVersion = nil
 		k.AzureDeploymentsJSON = nil
+		k.AzureEndpointType = nil
 	}
 
 	if k.VertexKeyConfig != nil {
@@ -121,12 +158,23 @@ func (k *TableKey) BeforeSave(tx *gorm.DB) error {
 		} else {
 			k.VertexDeploymentsJSON = nil
 		}
+		if len(k.VertexKeyConfig.Regions) > 0 {
+			data, err := json.Marshal(k.VertexKeyConfig.Regions)
+			if err != nil {
+				return err
+			}
+			s := string(data)
+			k.VertexRegionsJSON = &s
+		} else {
+			k.VertexRegionsJSON = nil
+		}
 	} else {
 		k.VertexProjectID = nil
 		k.VertexProjectNumber = nil
 		k.VertexRegion = nil
 		k.VertexAuthCredentials = nil
 		k.VertexDeploymentsJSON = nil
+		k.VertexRegionsJSON = nil
 	}
 
 	if k.BedrockKeyConfig != nil {
@@ -143,6 +191,11 @@ func (k *TableKey) BeforeSave(tx *gorm.DB) error {
 		k.BedrockSessionToken = k.BedrockKeyConfig.SessionToken
 		k.BedrockRegion = k.BedrockKeyConfig.Region
 		k.BedrockARN = k.BedrockKeyConfig.ARN
+		k.BedrockGuardrailID = k.BedrockKeyConfig.GuardrailID
+		k.BedrockGuardrailVersion = k.BedrockKeyConfig.GuardrailVersion
+		k.BedrockRoleARN = k.BedrockKeyConfig.RoleARN
+		k.BedrockExternalID = k.BedrockKeyConfig.ExternalID
+		k.BedrockRoleSessionName = k.BedrockKeyConfig.RoleSessionName
 		if k.BedrockKeyConfig.Deployments != nil {
 			data, err := sonic.Marshal(k.BedrockKeyConfig.Deployments)
 			if err != nil {
@@ -153,6 +206,26 @@ func (k *TableKey) BeforeSave(tx *gorm.DB) error {
 		} else {
 			k.BedrockDeploymentsJSON = nil
 		}
+		if len(k.BedrockKeyConfig.Regions) > 0 {
+			data, err := sonic.Marshal(k.BedrockKeyConfig.Regions)
+			if err != nil {
+				return err
+			}
+			s := string(data)
+			k.BedrockRegionsJSON = &s
+		} else {
+			k.BedrockRegionsJSON = nil
+		}
+		if len(k.BedrockKeyConfig.ARNs) > 0 {
+			data, err := sonic.Marshal(k.BedrockKeyConfig.ARNs)
+			if err != nil {
+				return err
+			}
+			s := string(data)
+			k.BedrockARNsJSON = &s
+		} else {
+			k.BedrockARNsJSON = nil
+		}
 	} else {
 		k.BedrockAccessKey = nil
 		k.BedrockSecretKey = nil
@@ -160,6 +233,38 @@ func (k *TableKey) BeforeSave(tx *gorm.DB) error {
 		k.BedrockRegion = nil
 		k.BedrockARN = nil
 		k.BedrockDeploymentsJSON = nil
+		k.BedrockRegionsJSON = nil
+		k.BedrockARNsJSON = nil
+		k.BedrockGuardrailID = nil
+		k.BedrockGuardrailVersion = nil
+		k.BedrockRoleARN = nil
+		k.BedrockExternalID = nil
+		k.BedrockRoleSessionName = nil
+	}
+
+	if k.ProxyConfig != nil {
+		data, err := sonic.Marshal(k.ProxyConfig)
+		if err != nil {
+			return err
+		}
+		s := string(data)
+		k.ProxyConfigJSON = &s
+	} else {
+		k.ProxyConfigJSON = nil
+	}
+
+	if k.LastValidation != nil {
+		status := string(k.LastValidation.Status)
+		k.LastValidationStatus = &status
+		k.LastValidationError = nil
+		if k.LastValidation.Error != "" {
+			k.LastValidationError = &k.LastValidation.Error
+		}
+		k.LastValidatedAt = &k.LastValidation.ValidatedAt
+	} else {
+		k.LastValidationStatus = nil
+		k.LastValidationError = nil
+		k.LastValidatedAt = nil
 	}
 	return nil
 }
@@ -192,6 +297,10 @@ func (k *TableKey) AfterFind(tx *gorm.DB) error {
 			azureConfig.Deployments = nil
 		}
 
+		if k.AzureEndpointType != nil {
+			azureConfig.EndpointType = schemas.AzureEndpointType(*k.AzureEndpointType)
+		}
+
 		k.AzureKeyConfig = azureConfig
 	}
 
@@ -222,12 +331,19 @@ func (k *TableKey) AfterFind(tx *gorm.DB) error {
 		} else {
 			config.Deployments = nil
 		}
+		if k.VertexRegionsJSON != nil {
+			var regions []string
+			if err := json.Unmarshal([]byte(*k.VertexRegionsJSON), &regions); err != nil {
+				return err
+			}
+			config.Regions = regions
+		}
 
 		k.VertexKeyConfig = config
 	}
 
 	// Reconstruct Bedrock config if fields are present
-	if k.BedrockAccessKey != nil || k.BedrockSecretKey != nil || k.BedrockSessionToken != nil || k.BedrockRegion != nil || k.BedrockARN != nil || (k.BedrockDeploymentsJSON != nil && *k.BedrockDeploymentsJSON != "") {
+	if k.BedrockAccessKey != nil || k.BedrockSecretKey != nil || k.BedrockSessionToken != nil || k.BedrockRegion != nil || k.BedrockARN != nil || k.BedrockRoleARN != nil || (k.BedrockDeploymentsJSON != nil && *k.BedrockDeploymentsJSON != "") {
 		bedrockConfig := &schemas.BedrockKeyConfig{}
 
 		if k.BedrockAccessKey != nil {
@@ -237,6 +353,11 @@ func (k *TableKey) AfterFind(tx *gorm.DB) error {
 		bedrockConfig.SessionToken = k.BedrockSessionToken
 		bedrockConfig.Region = k.BedrockRegion
 		bedrockConfig.ARN = k.BedrockARN
+		bedrockConfig.GuardrailID = k.BedrockGuardrailID
+		bedrockConfig.GuardrailVersion = k.BedrockGuardrailVersion
+		bedrockConfig.RoleARN = k.BedrockRoleARN
+		bedrockConfig.ExternalID = k.BedrockExternalID
+		bedrockConfig.RoleSessionName = k.BedrockRoleSessionName
 
 		if k.BedrockSecretKey != nil {
 			bedrockConfig.SecretKey = *k.BedrockSecretKey
@@ -251,9 +372,41 @@ func (k *TableKey) AfterFind(tx *gorm.DB) error {
 		} else {
 			bedrockConfig.Deployments = nil
 		}
+		if k.BedrockRegionsJSON != nil {
+			var regions []string
+			if err := json.Unmarshal([]byte(*k.BedrockRegionsJSON), &regions); err != nil {
+				return err
+			}
+			bedrockConfig.Regions = regions
+		}
+		if k.BedrockARNsJSON != nil {
+			var arns map[string]string
+			if err := json.Unmarshal([]byte(*k.BedrockARNsJSON), &arns); err != nil {
+				return err
+			}
+			bedrockConfig.ARNs = arns
+		}
 
 		k.BedrockKeyConfig = bedrockConfig
 	}
 
+	if k.ProxyConfigJSON != nil && *k.ProxyConfigJSON != "" {
+		var proxyConfig schemas.ProxyConfig
+		if err := sonic.Unmarshal([]byte(*k.ProxyConfigJSON), &proxyConfig); err != nil {
+			return err
+		}
+		k.ProxyConfig = &proxyConfig
+	}
+
+	if k.LastValidationStatus != nil && k.LastValidatedAt != nil {
+		k.LastValidation = &schemas.KeyValidation{
+			Status:      schemas.KeyValidationStatus(*k.LastValidationStatus),
+			ValidatedAt: *k.LastValidatedAt,
+		}
+		if k.LastValidationError != nil {
+			k.LastValidation.Error = *k.LastValidationError
+		}
+	}
+
 	return nil
 }
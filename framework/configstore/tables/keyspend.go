@@ -0,0 +1,15 @@
+package tables
+
+import "time"
+
+// TableKeySpend tracks the accumulated spend of a test key against its spend limit.
+// It is kept apart from config_keys so that provider config updates never reset it.
+type TableKeySpend struct {
+	KeyID      string     `gorm:"primaryKey;type:varchar(255)" json:"key_id"`
+	Spend      float64    `gorm:"default:0" json:"spend"` // Accumulated spend in dollars
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`  // Set once the spend limit is reached
+	UpdatedAt  time.Time  `gorm:"index;not null" json:"updated_at"`
+}
+
+// TableName sets the table name for each model
+func (TableKeySpend) TableName() string { return "governance_test_key_spend" }
@@ -0,0 +1,15 @@
+package tables
+
+import "time"
+
+// TableLease is a lease held by one replica at a time until it expires, used to elect the replica running the
+// background jobs of a cluster
+type TableLease struct {
+	Name      string    `gorm:"primaryKey;type:varchar(255)" json:"name"`
+	Holder    string    `gorm:"type:varchar(255);not null" json:"holder"` // Replica holding the lease
+	ExpiresAt time.Time `gorm:"index;not null" json:"expires_at"`
+	UpdatedAt time.Time `gorm:"index;not null" json:"updated_at"`
+}
+
+// TableName sets the table name for each model
+func (TableLease) TableName() string { return "leases" }
@@ -0,0 +1,43 @@
+package tables
+
+import (
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// TableModelCapability is an override of the capabilities of a model, applied on top of the bundled dataset of the
+// model catalog. Fields left nil keep their bundled value.
+type TableModelCapability struct {
+	Provider         string    `gorm:"primaryKey;type:varchar(50)" json:"provider"` // "*" for the model on every provider
+	Model            string    `gorm:"primaryKey;type:varchar(255)" json:"model"`
+	ContextWindow    *int      `gorm:"default:null" json:"context_window,omitempty"`
+	MaxOutputTokens  *int      `gorm:"default:null" json:"max_output_tokens,omitempty"`
+	SupportsVision   *bool     `gorm:"default:null" json:"supports_vision,omitempty"`
+	SupportsTools    *bool     `gorm:"default:null" json:"supports_tools,omitempty"`
+	SupportsJSONMode *bool     `gorm:"default:null" json:"supports_json_mode,omitempty"`
+	CreatedAt        time.Time `gorm:"index;not null" json:"created_at"`
+	UpdatedAt        time.Time `gorm:"index;not null" json:"updated_at"`
+}
+
+// TableName sets the table name for each model
+func (TableModelCapability) TableName() string { return "config_model_capabilities" }
+
+// ApplyTo overrides the capabilities with the fields set on the row
+func (c *TableModelCapability) ApplyTo(capabilities *schemas.ModelCapabilities) {
+	if c.ContextWindow != nil {
+		capabilities.ContextWindow = *c.ContextWindow
+	}
+	if c.MaxOutputTokens != nil {
+		capabilities.MaxOutputTokens = *c.MaxOutputTokens
+	}
+	if c.SupportsVision != nil {
+		capabilities.SupportsVision = *c.SupportsVision
+	}
+	if c.SupportsTools != nil {
+		capabilities.SupportsTools = *c.SupportsTools
+	}
+	if c.SupportsJSONMode != nil {
+		capabilities.SupportsJSONMode = *c.SupportsJSONMode
+	}
+}
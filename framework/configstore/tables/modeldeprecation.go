@@ -0,0 +1,30 @@
+package tables
+
+import (
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// TableModelDeprecation represents a deprecated model, its replacement and its sunset date
+type TableModelDeprecation struct {
+	Model       string    `gorm:"primaryKey;type:varchar(255)" json:"model"`
+	Replacement string    `gorm:"type:varchar(255);not null" json:"replacement"`
+	SunsetDate  string    `gorm:"type:varchar(10);not null" json:"sunset_date"`
+	Message     string    `gorm:"type:text" json:"message,omitempty"`
+	CreatedAt   time.Time `gorm:"index;not null" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"index;not null" json:"updated_at"`
+}
+
+// TableName sets the table name for each model
+func (TableModelDeprecation) TableName() string { return "config_model_deprecations" }
+
+// ToSchema converts the table row to the model deprecation applied by bifrost
+func (d *TableModelDeprecation) ToSchema() schemas.ModelDeprecation {
+	return schemas.ModelDeprecation{
+		Model:       d.Model,
+		Replacement: d.Replacement,
+		SunsetDate:  d.SunsetDate,
+		Message:     d.Message,
+	}
+}
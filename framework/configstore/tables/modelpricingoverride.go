@@ -0,0 +1,32 @@
+package tables
+
+import "time"
+
+// TableModelPricingOverride is an override of the token prices of a model, applied on top of the synced pricing
+// dataset for every request type. Fields left nil keep their synced value.
+type TableModelPricingOverride struct {
+	Provider                string    `gorm:"primaryKey;type:varchar(50)" json:"provider"` // "*" for the model on every provider
+	Model                   string    `gorm:"primaryKey;type:varchar(255)" json:"model"`
+	InputCostPerToken       *float64  `gorm:"default:null" json:"input_cost_per_token,omitempty"`
+	OutputCostPerToken      *float64  `gorm:"default:null" json:"output_cost_per_token,omitempty"`
+	CacheReadInputTokenCost *float64  `gorm:"default:null" json:"cache_read_input_token_cost,omitempty"`
+	CreatedAt               time.Time `gorm:"index;not null" json:"created_at"`
+	UpdatedAt               time.Time `gorm:"index;not null" json:"updated_at"`
+}
+
+// TableName sets the table name for each model
+func (TableModelPricingOverride) TableName() string { return "config_model_pricing_overrides" }
+
+// ApplyTo overrides the pricing with the prices set on the row
+func (o *TableModelPricingOverride) ApplyTo(pricing *TableModelPricing) {
+	if o.InputCostPerToken != nil {
+		pricing.InputCostPerToken = *o.InputCostPerToken
+	}
+	if o.OutputCostPerToken != nil {
+		pricing.OutputCostPerToken = *o.OutputCostPerToken
+	}
+	if o.CacheReadInputTokenCost != nil {
+		cacheReadInputTokenCost := *o.CacheReadInputTokenCost
+		pricing.CacheReadInputTokenCost = &cacheReadInputTokenCost
+	}
+}
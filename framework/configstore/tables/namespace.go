@@ -0,0 +1,48 @@
+package tables
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultNamespace is the namespace entities belong to when none is specified
+const DefaultNamespace = "default"
+
+// TableNamespace represents an isolated tenant namespace.
+// Providers, keys, virtual keys, teams, customers, budgets and rate limits belong to exactly one namespace,
+// and the namespace admin can only see and modify the entities of their own namespace.
+type TableNamespace struct {
+	ID            string `gorm:"primaryKey;type:varchar(255)" json:"id"`
+	Name          string `gorm:"type:varchar(255);uniqueIndex:idx_namespace_name;not null" json:"name"`
+	Description   string `gorm:"type:text" json:"description,omitempty"`
+	AdminUsername string `gorm:"type:varchar(255);uniqueIndex:idx_namespace_admin_username;not null" json:"admin_username"`
+	AdminPassword string `gorm:"type:text;not null" json:"-"` // Hashed password of the namespace admin
+
+	// Payload encryption, log payloads of the namespace are envelope encrypted with this KMS key reference
+	PayloadEncryptionKeyRef *string    `gorm:"type:varchar(512)" json:"payload_encryption_key_ref,omitempty"`
+	PayloadKeyRevokedAt     *time.Time `json:"payload_key_revoked_at,omitempty"`
+
+	CreatedAt time.Time `gorm:"index;not null" json:"created_at"`
+	UpdatedAt time.Time `gorm:"index;not null" json:"updated_at"`
+}
+
+// TableName sets the table name for each model
+func (TableNamespace) TableName() string { return "config_namespaces" }
+
+// BeforeSave hook for TableNamespace to validate the namespace name
+func (n *TableNamespace) BeforeSave(tx *gorm.DB) error {
+	if n.Name == "" {
+		return fmt.Errorf("namespace name is required")
+	}
+	if n.AdminUsername == "" {
+		return fmt.Errorf("namespace admin username is required")
+	}
+	return nil
+}
+
+// PayloadKeyRevoked reports whether the payload encryption key of the namespace was revoked
+func (n *TableNamespace) PayloadKeyRevoked() bool {
+	return n.PayloadKeyRevokedAt != nil
+}
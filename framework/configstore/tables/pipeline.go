@@ -0,0 +1,32 @@
+package tables
+
+import (
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// TablePipeline represents a named transformation pipeline and the models and virtual keys it is attached to
+type TablePipeline struct {
+	Name          string                  `gorm:"primaryKey;type:varchar(255)" json:"name"`
+	Description   string                  `gorm:"type:text" json:"description,omitempty"`
+	Stages        []schemas.PipelineStage `gorm:"type:text;serializer:json" json:"stages"`
+	Models        []string                `gorm:"type:text;serializer:json" json:"models"`
+	VirtualKeyIDs []string                `gorm:"type:text;serializer:json" json:"virtual_key_ids"`
+	CreatedAt     time.Time               `gorm:"index;not null" json:"created_at"`
+	UpdatedAt     time.Time               `gorm:"index;not null" json:"updated_at"`
+}
+
+// TableName sets the table name for each model
+func (TablePipeline) TableName() string { return "config_pipelines" }
+
+// ToSchema converts the table row to the pipeline applied by bifrost
+func (p *TablePipeline) ToSchema() schemas.Pipeline {
+	return schemas.Pipeline{
+		Name:          p.Name,
+		Description:   p.Description,
+		Stages:        p.Stages,
+		Models:        p.Models,
+		VirtualKeyIDs: p.VirtualKeyIDs,
+	}
+}
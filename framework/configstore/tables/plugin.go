@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/maximhq/bifrost/core/schemas"
 	"gorm.io/gorm"
 )
 
@@ -20,8 +21,11 @@ type TablePlugin struct {
 	UpdatedAt    time.Time `gorm:"index;not null" json:"updated_at"`
 	IsCustom     bool      `gorm:"not null;default:false" json:"isCustom"`	
 
+	ExecutionJSON string `gorm:"type:text" json:"-"` // JSON serialized plugin.Execution
+
 	// Virtual fields for runtime use (not stored in DB)
-	Config any `gorm:"-" json:"config,omitempty"`
+	Config    any                            `gorm:"-" json:"config,omitempty"`
+	Execution *schemas.PluginExecutionConfig `gorm:"-" json:"execution,omitempty"`
 }
 
 // TableName sets the table name for each model
@@ -38,6 +42,15 @@ func (p *TablePlugin) BeforeSave(tx *gorm.DB) error {
 	} else {
 		p.ConfigJSON = "{}"
 	}
+	if p.Execution != nil {
+		data, err := json.Marshal(p.Execution)
+		if err != nil {
+			return err
+		}
+		p.ExecutionJSON = string(data)
+	} else {
+		p.ExecutionJSON = ""
+	}
 
 	return nil
 }
@@ -51,6 +64,13 @@ func (p *TablePlugin) AfterFind(tx *gorm.DB) error {
 	} else {
 		p.Config = nil
 	}
+	if p.ExecutionJSON != "" {
+		if err := json.Unmarshal([]byte(p.ExecutionJSON), &p.Execution); err != nil {
+			return err
+		}
+	} else {
+		p.Execution = nil
+	}
 
 	return nil
 }
@@ -19,7 +19,9 @@ type TableProvider struct {
 	ConcurrencyBufferJSON    string    `gorm:"type:text" json:"-"`                                // JSON serialized schemas.ConcurrencyAndBufferSize
 	ProxyConfigJSON          string    `gorm:"type:text" json:"-"`                                // JSON serialized schemas.ProxyConfig
 	CustomProviderConfigJSON string    `gorm:"type:text" json:"-"`                                // JSON serialized schemas.CustomProviderConfig
+	MockConfigJSON           string    `gorm:"type:text" json:"-"`                                // JSON serialized schemas.MockConfig
 	SendBackRawResponse      bool      `json:"send_back_raw_response"`
+	Namespace                string    `gorm:"type:varchar(255);not null;default:'default';index" json:"namespace"`
 	CreatedAt                time.Time `gorm:"index;not null" json:"created_at"`
 	UpdatedAt                time.Time `gorm:"index;not null" json:"updated_at"`
 
@@ -34,6 +36,9 @@ type TableProvider struct {
 	// Custom provider fields
 	CustomProviderConfig *schemas.CustomProviderConfig `gorm:"-" json:"custom_provider_config,omitempty"`
 
+	// Mock provider fields
+	MockConfig *schemas.MockConfig `gorm:"-" json:"mock_config,omitempty"`
+
 	// Foreign keys
 	Models []TableModel `gorm:"foreignKey:ProviderID;constraint:OnDelete:CASCADE" json:"models"`
 
@@ -47,6 +52,9 @@ func (TableProvider) TableName() string { return "config_providers" }
 
 // BeforeSave hooks for serialization
 func (p *TableProvider) BeforeSave(tx *gorm.DB) error {
+	if p.Namespace == "" {
+		p.Namespace = DefaultNamespace
+	}
 	if p.NetworkConfig != nil {
 		data, err := json.Marshal(p.NetworkConfig)
 		if err != nil {
@@ -78,6 +86,13 @@ func (p *TableProvider) BeforeSave(tx *gorm.DB) error {
 		}
 		p.CustomProviderConfigJSON = string(data)
 	}
+	if p.MockConfig != nil {
+		data, err := json.Marshal(p.MockConfig)
+		if err != nil {
+			return err
+		}
+		p.MockConfigJSON = string(data)
+	}
 	return nil
 }
 
@@ -115,5 +130,13 @@ func (p *TableProvider) AfterFind(tx *gorm.DB) error {
 		p.CustomProviderConfig = &customConfig
 	}
 
+	if p.MockConfigJSON != "" {
+		var mockConfig schemas.MockConfig
+		if err := json.Unmarshal([]byte(p.MockConfigJSON), &mockConfig); err != nil {
+			return err
+		}
+		p.MockConfig = &mockConfig
+	}
+
 	return nil
 }
@@ -9,7 +9,8 @@ import (
 
 // TableRateLimit defines rate limiting rules for virtual keys using flexible max+reset approach
 type TableRateLimit struct {
-	ID string `gorm:"primaryKey;type:varchar(255)" json:"id"`
+	ID        string `gorm:"primaryKey;type:varchar(255)" json:"id"`
+	Namespace string `gorm:"type:varchar(255);not null;default:'default';index" json:"namespace"`
 
 	// Token limits with flexible duration
 	TokenMaxLimit      *int64    `gorm:"default:null" json:"token_max_limit,omitempty"`          // Maximum tokens allowed
@@ -32,6 +33,9 @@ func (TableRateLimit) TableName() string { return "governance_rate_limits" }
 
 // BeforeSave hook for RateLimit to validate reset duration formats
 func (rl *TableRateLimit) BeforeSave(tx *gorm.DB) error {
+	if rl.Namespace == "" {
+		rl.Namespace = DefaultNamespace
+	}
 	// Validate token reset duration if provided
 	if rl.TokenResetDuration != nil {
 		if d, err := ParseDuration(*rl.TokenResetDuration); err != nil {
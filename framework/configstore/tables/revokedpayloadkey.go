@@ -0,0 +1,14 @@
+package tables
+
+import "time"
+
+// TableRevokedPayloadKey is a payload encryption key reference revoked by an admin. Revocations are kept after the
+// key of the namespace changes, so that every replica keeps refusing the key across restarts.
+type TableRevokedPayloadKey struct {
+	KeyRef    string    `gorm:"primaryKey;type:varchar(512)" json:"key_ref"`
+	Namespace string    `gorm:"type:varchar(255);index" json:"namespace"` // Namespace the key belonged to when revoked
+	RevokedAt time.Time `gorm:"index;not null" json:"revoked_at"`
+}
+
+// TableName sets the table name for each model
+func (TableRevokedPayloadKey) TableName() string { return "config_revoked_payload_keys" }
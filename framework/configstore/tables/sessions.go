@@ -6,6 +6,7 @@ import "time"
 type SessionsTable struct {
 	ID        int       `gorm:"primaryKey;autoIncrement" json:"id"`
 	Token     string    `gorm:"type:varchar(255);not null;uniqueIndex" json:"token"`
+	Namespace string    `gorm:"type:varchar(255);index" json:"namespace,omitempty"` // Namespace of the logged in admin, empty for the root admin
 	ExpiresAt time.Time `gorm:"index;not null" json:"expires_at,omitempty"`
 	CreatedAt time.Time `gorm:"index;not null" json:"created_at"`
 	UpdatedAt time.Time `gorm:"index;not null" json:"updated_at"`
@@ -12,9 +12,13 @@ import (
 type TableTeam struct {
 	ID         string  `gorm:"primaryKey;type:varchar(255)" json:"id"`
 	Name       string  `gorm:"type:varchar(255);not null" json:"name"`
+	Namespace  string  `gorm:"type:varchar(255);not null;default:'default';index" json:"namespace"`
 	CustomerID *string `gorm:"type:varchar(255);index" json:"customer_id,omitempty"` // A team can belong to a customer
 	BudgetID   *string `gorm:"type:varchar(255);index" json:"budget_id,omitempty"`
 
+	SystemPromptPolicy *SystemPromptPolicy `gorm:"type:text;serializer:json" json:"system_prompt_policy,omitempty"` // Enforced system prompt of the virtual keys of the team without their own policy
+	MaxOutputTokens    *int                `json:"max_output_tokens,omitempty"`                                     // Cap on the output tokens of each request of the virtual keys of the team without their own cap
+
 	// Relationships
 	Customer    *TableCustomer    `gorm:"foreignKey:CustomerID" json:"customer,omitempty"`
 	Budget      *TableBudget      `gorm:"foreignKey:BudgetID" json:"budget,omitempty"`
@@ -38,6 +42,9 @@ func (TableTeam) TableName() string { return "governance_teams" }
 
 // BeforeSave hook for TableTeam to serialize JSON fields
 func (t *TableTeam) BeforeSave(tx *gorm.DB) error {
+	if t.Namespace == "" {
+		t.Namespace = DefaultNamespace
+	}
 	if t.ParsedProfile != nil {
 		data, err := json.Marshal(t.ParsedProfile)
 		if err != nil {
@@ -0,0 +1,36 @@
+package tables
+
+import (
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// TableTransformRule represents a declarative transform rule and the models and virtual keys it applies to
+type TableTransformRule struct {
+	Name          string                       `gorm:"primaryKey;type:varchar(255)" json:"name"`
+	Description   string                       `gorm:"type:text" json:"description,omitempty"`
+	Priority      int                          `gorm:"default:0" json:"priority"`
+	Models        []string                     `gorm:"type:text;serializer:json" json:"models"`
+	VirtualKeyIDs []string                     `gorm:"type:text;serializer:json" json:"virtual_key_ids"`
+	Enforcement   schemas.TransformEnforcement `gorm:"type:varchar(20)" json:"enforcement,omitempty"`
+	Actions       []schemas.TransformAction    `gorm:"type:text;serializer:json" json:"actions"`
+	CreatedAt     time.Time                    `gorm:"index;not null" json:"created_at"`
+	UpdatedAt     time.Time                    `gorm:"index;not null" json:"updated_at"`
+}
+
+// TableName sets the table name for each model
+func (TableTransformRule) TableName() string { return "config_transform_rules" }
+
+// ToSchema converts the table row to the transform rule applied by bifrost
+func (r *TableTransformRule) ToSchema() schemas.TransformRule {
+	return schemas.TransformRule{
+		Name:          r.Name,
+		Description:   r.Description,
+		Priority:      r.Priority,
+		Models:        r.Models,
+		VirtualKeyIDs: r.VirtualKeyIDs,
+		Enforcement:   r.Enforcement,
+		Actions:       r.Actions,
+	}
+}
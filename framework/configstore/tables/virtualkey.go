@@ -2,6 +2,8 @@ package tables
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -91,15 +93,147 @@ func (TableVirtualKeyMCPConfig) TableName() string {
 	return "governance_virtual_key_mcp_configs"
 }
 
+// VirtualKeyDefaultParams holds request parameters applied to requests made with a virtual key
+// when the client omits them
+type VirtualKeyDefaultParams struct {
+	Model          *string      `json:"model,omitempty"`           // Model used when the request carries none
+	MaxTemperature *float64     `json:"max_temperature,omitempty"` // Ceiling applied to the client-supplied temperature
+	ResponseFormat *interface{} `json:"response_format,omitempty"` // response_format used when the request carries none
+}
+
+// SystemPromptPolicyMode is how a system prompt policy combines its prompt with the system prompt sent by the client
+type SystemPromptPolicyMode string
+
+const (
+	SystemPromptPolicyModePrepend SystemPromptPolicyMode = "prepend" // The policy prompt goes before the system prompt of the client
+	SystemPromptPolicyModeReplace SystemPromptPolicyMode = "replace" // The policy prompt replaces the system prompt of the client
+)
+
+// SystemPromptPolicy enforces a system prompt on the requests made with a virtual key, or with the virtual keys of a team.
+// Unlike the pinned system prompt of a virtual key, it applies whether or not the client sends a system prompt.
+type SystemPromptPolicy struct {
+	Mode              SystemPromptPolicyMode `json:"mode"`
+	Prompt            string                 `json:"prompt,omitempty"`              // Empty with the replace mode strips the system prompt of the client
+	BlockClientPrompt bool                   `json:"block_client_prompt,omitempty"` // Rejects requests carrying a system prompt
+}
+
+// Validate checks the mode of the policy and that the prepend mode has a prompt
+func (p *SystemPromptPolicy) Validate() error {
+	switch p.Mode {
+	case SystemPromptPolicyModePrepend:
+		if strings.TrimSpace(p.Prompt) == "" {
+			return fmt.Errorf("system_prompt_policy prompt is required with the prepend mode")
+		}
+	case SystemPromptPolicyModeReplace:
+	default:
+		return fmt.Errorf("system_prompt_policy mode must be prepend or replace, got %q", p.Mode)
+	}
+	return nil
+}
+
+// EndUserLimits limits each end user of a virtual key, identified by the user of its requests (the x-bf-user header
+// or the user parameter). The usage of each end user is counted by the governance plugin in memory, requests without
+// an end user are not limited.
+type EndUserLimits struct {
+	TokenMaxLimit        *int64   `json:"token_max_limit,omitempty"`        // Maximum tokens of an end user per token reset duration
+	TokenResetDuration   *string  `json:"token_reset_duration,omitempty"`   // e.g., "1m", "1h", "1d"
+	RequestMaxLimit      *int64   `json:"request_max_limit,omitempty"`      // Maximum requests of an end user per request reset duration
+	RequestResetDuration *string  `json:"request_reset_duration,omitempty"` // e.g., "1m", "1h", "1d"
+	BudgetMaxLimit       *float64 `json:"budget_max_limit,omitempty"`       // Maximum spend of an end user in dollars per budget reset duration
+	BudgetResetDuration  *string  `json:"budget_reset_duration,omitempty"`  // e.g., "1d", "1M"
+}
+
+// IsEmpty reports whether no limit is set
+func (l *EndUserLimits) IsEmpty() bool {
+	return l.TokenMaxLimit == nil && l.RequestMaxLimit == nil && l.BudgetMaxLimit == nil
+}
+
+// Validate checks that every limit of the end user limits has a valid reset duration
+func (l *EndUserLimits) Validate() error {
+	limits := []struct {
+		name     string
+		set      bool
+		duration *string
+	}{
+		{"token", l.TokenMaxLimit != nil, l.TokenResetDuration},
+		{"request", l.RequestMaxLimit != nil, l.RequestResetDuration},
+		{"budget", l.BudgetMaxLimit != nil, l.BudgetResetDuration},
+	}
+	for _, limit := range limits {
+		if !limit.set {
+			continue
+		}
+		if limit.duration == nil {
+			return fmt.Errorf("end_user_limits %s reset duration is required with its max limit", limit.name)
+		}
+		if _, err := ParseDuration(*limit.duration); err != nil {
+			return fmt.Errorf("end_user_limits %s reset duration is invalid: %w", limit.name, err)
+		}
+	}
+	return nil
+}
+
+// ResidencyOnViolation is what a residency policy does with a request to a provider without keys in its regions
+type ResidencyOnViolation string
+
+const (
+	ResidencyOnViolationReject  ResidencyOnViolation = "reject"  // The request is rejected
+	ResidencyOnViolationReroute ResidencyOnViolation = "reroute" // The request is sent to another provider of the virtual key with keys in the regions
+)
+
+// ResidencyPolicy keeps the requests of a virtual key within regions: only the keys tagged with one of the regions
+// serve them, keys without a region never do.
+type ResidencyPolicy struct {
+	Regions     []string             `json:"regions"`                // e.g. ["eu"]
+	OnViolation ResidencyOnViolation `json:"on_violation,omitempty"` // Defaults to reject
+}
+
+// Allows reports whether a key of the region may serve the requests of the policy
+func (p *ResidencyPolicy) Allows(region string) bool {
+	return region != "" && slices.Contains(p.Regions, region)
+}
+
+// Validate checks that the residency policy has regions and a known violation behavior
+func (p *ResidencyPolicy) Validate() error {
+	if len(p.Regions) == 0 {
+		return fmt.Errorf("residency_policy requires at least one region")
+	}
+	for _, region := range p.Regions {
+		if region == "" {
+			return fmt.Errorf("residency_policy regions cannot be empty")
+		}
+	}
+	switch p.OnViolation {
+	case "", ResidencyOnViolationReject, ResidencyOnViolationReroute:
+		return nil
+	default:
+		return fmt.Errorf("residency_policy on_violation must be %q or %q", ResidencyOnViolationReject, ResidencyOnViolationReroute)
+	}
+}
+
 // TableVirtualKey represents a virtual key with budget, rate limits, and team/customer association
 type TableVirtualKey struct {
-	ID              string                          `gorm:"primaryKey;type:varchar(255)" json:"id"`
-	Name            string                          `gorm:"uniqueIndex:idx_virtual_key_name;type:varchar(255);not null" json:"name"`
-	Description     string                          `gorm:"type:text" json:"description,omitempty"`
-	Value           string                          `gorm:"uniqueIndex:idx_virtual_key_value;type:varchar(255);not null" json:"value"` // The virtual key value
-	IsActive        bool                            `gorm:"default:true" json:"is_active"`
-	ProviderConfigs []TableVirtualKeyProviderConfig `gorm:"foreignKey:VirtualKeyID;constraint:OnDelete:CASCADE" json:"provider_configs"` // Empty means all providers allowed
-	MCPConfigs      []TableVirtualKeyMCPConfig      `gorm:"foreignKey:VirtualKeyID;constraint:OnDelete:CASCADE" json:"mcp_configs"`
+	ID                 string                          `gorm:"primaryKey;type:varchar(255)" json:"id"`
+	Name               string                          `gorm:"uniqueIndex:idx_virtual_key_name;type:varchar(255);not null" json:"name"`
+	Description        string                          `gorm:"type:text" json:"description,omitempty"`
+	Value              string                          `gorm:"uniqueIndex:idx_virtual_key_value;type:varchar(255);not null" json:"value"` // The virtual key value
+	IsActive           bool                            `gorm:"default:true" json:"is_active"`
+	Namespace          string                          `gorm:"type:varchar(255);not null;default:'default';index" json:"namespace"`
+	ProviderConfigs    []TableVirtualKeyProviderConfig `gorm:"foreignKey:VirtualKeyID;constraint:OnDelete:CASCADE" json:"provider_configs"` // Empty means all providers allowed
+	MCPConfigs         []TableVirtualKeyMCPConfig      `gorm:"foreignKey:VirtualKeyID;constraint:OnDelete:CASCADE" json:"mcp_configs"`
+	DefaultParams      *VirtualKeyDefaultParams        `gorm:"type:text;serializer:json" json:"default_params,omitempty"`
+	SystemPrompt       *string                         `gorm:"type:text" json:"system_prompt,omitempty"`                        // Pinned system prompt, applied when the client sends none
+	DedupeWindow       *string                         `gorm:"type:varchar(50)" json:"dedupe_window,omitempty"`                 // Window in which requests repeating an x-bf-event-id are deduplicated (e.g. "10m"), nil disables deduplication
+	SystemPromptPolicy *SystemPromptPolicy             `gorm:"type:text;serializer:json" json:"system_prompt_policy,omitempty"` // Enforced system prompt, takes precedence over the policy of the team
+	EndUserLimits      *EndUserLimits                  `gorm:"type:text;serializer:json" json:"end_user_limits,omitempty"`      // Rate limits and budget of each end user of the key
+	ResidencyPolicy    *ResidencyPolicy                `gorm:"type:text;serializer:json" json:"residency_policy,omitempty"`     // Regions the requests of the key are kept in
+	MaxOutputTokens    *int                            `json:"max_output_tokens,omitempty"`                                     // Cap on the output tokens of each request, takes precedence over the cap of the team
+
+	// Rotation and revocation state
+	PreviousValue          *string    `gorm:"type:varchar(255);index" json:"-"`                 // Value replaced by the last rotation, still accepted until PreviousValueExpiresAt
+	PreviousValueExpiresAt *time.Time `gorm:"index" json:"previous_value_expires_at,omitempty"` // End of the grace period of PreviousValue
+	RotatedAt              *time.Time `json:"rotated_at,omitempty"`                             // Time of the last rotation
+	RevokedAt              *time.Time `gorm:"index" json:"revoked_at,omitempty"`                // Set once the key is revoked, a revoked key can never be reactivated
 
 	// Foreign key relationships (mutually exclusive: either TeamID or CustomerID, not both)
 	TeamID      *string `gorm:"type:varchar(255);index" json:"team_id,omitempty"`
@@ -126,5 +260,18 @@ func (vk *TableVirtualKey) BeforeSave(tx *gorm.DB) error {
 	if vk.TeamID != nil && vk.CustomerID != nil {
 		return fmt.Errorf("virtual key cannot belong to both team and customer")
 	}
+	if vk.Namespace == "" {
+		vk.Namespace = DefaultNamespace
+	}
 	return nil
 }
+
+// IsRevoked reports whether the virtual key has been revoked
+func (vk *TableVirtualKey) IsRevoked() bool {
+	return vk.RevokedAt != nil
+}
+
+// PreviousValueValid reports whether the value replaced by the last rotation is still accepted at the given time
+func (vk *TableVirtualKey) PreviousValueValid(now time.Time) bool {
+	return vk.PreviousValue != nil && *vk.PreviousValue != "" && vk.PreviousValueExpiresAt != nil && now.Before(*vk.PreviousValueExpiresAt)
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/envutils"
 )
 
 // marshalToString marshals the given value to a JSON string.
@@ -43,7 +44,8 @@ func deepCopy[T any](in T) (T, error) {
 	return out, err
 }
 
-// substituteEnvVars replaces resolved environment variable values with their original env.VAR_NAME references
+// substituteEnvVars replaces resolved environment variable values with their original env.VAR_NAME, ${env:VAR_NAME}
+// or ${file:/path} references
 func substituteEnvVars(config *ProviderConfig, provider schemas.ModelProvider, envKeys map[string][]EnvKeyInfo) {
 	// Create a map for quick lookup of env vars by provider and key ID
 	envVarMap := make(map[string]string) // key: "provider.keyID.field" -> env var name
@@ -70,6 +72,11 @@ func substituteEnvVars(config *ProviderConfig, provider schemas.ModelProvider, e
 					field := strings.TrimPrefix(keyInfo.ConfigPath, fmt.Sprintf("providers.%s.keys[%s].bedrock_key_config.", provider, keyInfo.KeyID))
 					envVarMap[fmt.Sprintf("%s.%s.bedrock.%s", provider, keyInfo.KeyID, field)] = envVar
 				}
+				// For the proxy of the key
+				if keyInfo.KeyType == "proxy_config" {
+					field := strings.TrimPrefix(keyInfo.ConfigPath, fmt.Sprintf("providers.%s.keys[%s].proxy_config.", provider, keyInfo.KeyID))
+					envVarMap[fmt.Sprintf("%s.%s.proxy.%s", provider, keyInfo.KeyID, field)] = envVar
+				}
 			}
 		}
 	}
@@ -80,16 +87,16 @@ func substituteEnvVars(config *ProviderConfig, provider schemas.ModelProvider, e
 
 		// Substitute API key value
 		if envVar, exists := envVarMap[fmt.Sprintf("%s.value", keyPrefix)]; exists {
-			config.Keys[i].Value = fmt.Sprintf("env.%s", envVar)
+			config.Keys[i].Value = envutils.FormatReference(envVar)
 		}
 
 		// Substitute Azure config
 		if key.AzureKeyConfig != nil {
 			if envVar, exists := envVarMap[fmt.Sprintf("%s.azure.endpoint", keyPrefix)]; exists {
-				config.Keys[i].AzureKeyConfig.Endpoint = fmt.Sprintf("env.%s", envVar)
+				config.Keys[i].AzureKeyConfig.Endpoint = envutils.FormatReference(envVar)
 			}
 			if envVar, exists := envVarMap[fmt.Sprintf("%s.azure.api_version", keyPrefix)]; exists {
-				apiVersion := fmt.Sprintf("env.%s", envVar)
+				apiVersion := envutils.FormatReference(envVar)
 				config.Keys[i].AzureKeyConfig.APIVersion = &apiVersion
 			}
 		}
@@ -97,35 +104,45 @@ func substituteEnvVars(config *ProviderConfig, provider schemas.ModelProvider, e
 		// Substitute Vertex config
 		if key.VertexKeyConfig != nil {
 			if envVar, exists := envVarMap[fmt.Sprintf("%s.vertex.project_id", keyPrefix)]; exists {
-				config.Keys[i].VertexKeyConfig.ProjectID = fmt.Sprintf("env.%s", envVar)
+				config.Keys[i].VertexKeyConfig.ProjectID = envutils.FormatReference(envVar)
 			}
 			if envVar, exists := envVarMap[fmt.Sprintf("%s.vertex.project_number", keyPrefix)]; exists {
-				config.Keys[i].VertexKeyConfig.ProjectNumber = fmt.Sprintf("env.%s", envVar)
+				config.Keys[i].VertexKeyConfig.ProjectNumber = envutils.FormatReference(envVar)
 			}
 			if envVar, exists := envVarMap[fmt.Sprintf("%s.vertex.region", keyPrefix)]; exists {
-				config.Keys[i].VertexKeyConfig.Region = fmt.Sprintf("env.%s", envVar)
+				config.Keys[i].VertexKeyConfig.Region = envutils.FormatReference(envVar)
 			}
 			if envVar, exists := envVarMap[fmt.Sprintf("%s.vertex.auth_credentials", keyPrefix)]; exists {
-				config.Keys[i].VertexKeyConfig.AuthCredentials = fmt.Sprintf("env.%s", envVar)
+				config.Keys[i].VertexKeyConfig.AuthCredentials = envutils.FormatReference(envVar)
 			}
 		}
 
 		// Substitute Bedrock config
 		if key.BedrockKeyConfig != nil {
 			if envVar, exists := envVarMap[fmt.Sprintf("%s.bedrock.access_key", keyPrefix)]; exists {
-				config.Keys[i].BedrockKeyConfig.AccessKey = fmt.Sprintf("env.%s", envVar)
+				config.Keys[i].BedrockKeyConfig.AccessKey = envutils.FormatReference(envVar)
 			}
 			if envVar, exists := envVarMap[fmt.Sprintf("%s.bedrock.secret_key", keyPrefix)]; exists {
-				config.Keys[i].BedrockKeyConfig.SecretKey = fmt.Sprintf("env.%s", envVar)
+				config.Keys[i].BedrockKeyConfig.SecretKey = envutils.FormatReference(envVar)
 			}
 			if envVar, exists := envVarMap[fmt.Sprintf("%s.bedrock.session_token", keyPrefix)]; exists {
-				config.Keys[i].BedrockKeyConfig.SessionToken = &[]string{fmt.Sprintf("env.%s", envVar)}[0]
+				config.Keys[i].BedrockKeyConfig.SessionToken = &[]string{envutils.FormatReference(envVar)}[0]
 			}
 			if envVar, exists := envVarMap[fmt.Sprintf("%s.bedrock.region", keyPrefix)]; exists {
-				config.Keys[i].BedrockKeyConfig.Region = &[]string{fmt.Sprintf("env.%s", envVar)}[0]
+				config.Keys[i].BedrockKeyConfig.Region = &[]string{envutils.FormatReference(envVar)}[0]
 			}
 			if envVar, exists := envVarMap[fmt.Sprintf("%s.bedrock.arn", keyPrefix)]; exists {
-				config.Keys[i].BedrockKeyConfig.ARN = &[]string{fmt.Sprintf("env.%s", envVar)}[0]
+				config.Keys[i].BedrockKeyConfig.ARN = &[]string{envutils.FormatReference(envVar)}[0]
+			}
+		}
+
+		// Substitute the authentication of the proxy of the key
+		if key.ProxyConfig != nil {
+			if envVar, exists := envVarMap[fmt.Sprintf("%s.proxy.username", keyPrefix)]; exists {
+				config.Keys[i].ProxyConfig.Username = envutils.FormatReference(envVar)
+			}
+			if envVar, exists := envVarMap[fmt.Sprintf("%s.proxy.password", keyPrefix)]; exists {
+				config.Keys[i].ProxyConfig.Password = envutils.FormatReference(envVar)
 			}
 		}
 	}
@@ -167,7 +184,7 @@ func substituteMCPEnvVars(config *schemas.MCPConfig, envKeys map[string][]EnvKey
 		// Substitute connection string
 		if clientConfig.ConnectionString != nil {
 			if envVar, exists := envVarMap[fmt.Sprintf("%s.connection_string", clientPrefix)]; exists {
-				config.ClientConfigs[i].ConnectionString = &[]string{fmt.Sprintf("env.%s", envVar)}[0]
+				config.ClientConfigs[i].ConnectionString = &[]string{envutils.FormatReference(envVar)}[0]
 			}
 		}
 
@@ -175,7 +192,7 @@ func substituteMCPEnvVars(config *schemas.MCPConfig, envKeys map[string][]EnvKey
 		if clientConfig.Headers != nil {
 			for header := range clientConfig.Headers {
 				if envVar, exists := envVarMap[fmt.Sprintf("%s.headers.%s", clientPrefix, header)]; exists {
-					clientConfig.Headers[header] = fmt.Sprintf("env.%s", envVar)
+					clientConfig.Headers[header] = envutils.FormatReference(envVar)
 				}
 			}
 		}
@@ -195,7 +212,7 @@ func substituteMCPClientEnvVars(clientConfig *schemas.MCPClientConfig, envKeys m
 					clientName := pathParts[2]
 					// If this environment variable is for the current client
 					if clientName == clientConfig.Name && clientConfig.ConnectionString != nil {
-						clientConfig.ConnectionString = &[]string{fmt.Sprintf("env.%s", envVar)}[0]
+						clientConfig.ConnectionString = &[]string{envutils.FormatReference(envVar)}[0]
 					}
 				}
 			}
@@ -208,7 +225,7 @@ func substituteMCPClientEnvVars(clientConfig *schemas.MCPClientConfig, envKeys m
 					headerName := pathParts[4]
 					// If this environment variable is for the current client
 					if clientName == clientConfig.Name && clientConfig.Headers != nil {
-						clientConfig.Headers[headerName] = fmt.Sprintf("env.%s", envVar)
+						clientConfig.Headers[headerName] = envutils.FormatReference(envVar)
 					}
 				}
 			}
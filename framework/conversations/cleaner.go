@@ -0,0 +1,99 @@
+package conversations
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/leader"
+)
+
+const (
+	cleanupInterval = 24 * time.Hour
+	maxJitter       = 30 * time.Minute
+	cleanupTimeout  = 30 * time.Minute
+)
+
+// Cleaner deletes the conversations that were not updated for their retention period, once a day.
+type Cleaner struct {
+	store         schemas.ConversationStore
+	retentionDays int
+	logger        schemas.Logger
+	elector       *leader.Elector // Elects the replica running the cleanups, nil runs them on every replica
+	stop          chan struct{}
+	mu            sync.Mutex
+}
+
+// NewCleaner creates a cleaner of the conversations of the store not updated for retentionDays.
+func NewCleaner(store schemas.ConversationStore, retentionDays int, logger schemas.Logger) *Cleaner {
+	return &Cleaner{
+		store:         store,
+		retentionDays: retentionDays,
+		logger:        logger,
+	}
+}
+
+// SetElector sets the elector of the replica running the cleanups, so that the conversations are cleaned up once
+// per cluster. Must be called before Start.
+func (c *Cleaner) SetElector(elector *leader.Elector) {
+	c.elector = elector
+}
+
+// Start runs a cleanup now and then once a day, until Stop is called.
+func (c *Cleaner) Start() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stop != nil {
+		return
+	}
+	c.stop = make(chan struct{})
+	stop := c.stop
+
+	go func() {
+		c.cleanup()
+		timer := time.NewTimer(cleanupInterval + time.Duration(rand.Int63n(int64(maxJitter))))
+		defer timer.Stop()
+		for {
+			select {
+			case <-timer.C:
+				c.cleanup()
+				timer.Reset(cleanupInterval + time.Duration(rand.Int63n(int64(maxJitter))))
+			case <-stop:
+				return
+			}
+		}
+	}()
+	c.logger.Info("conversation cleanup routine started with %d days retention", c.retentionDays)
+}
+
+// Stop stops the cleanup routine.
+func (c *Cleaner) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stop == nil {
+		return
+	}
+	close(c.stop)
+	c.stop = nil
+}
+
+// cleanup deletes the conversations last updated before the retention period
+func (c *Cleaner) cleanup() {
+	if !c.elector.IsLeader() {
+		c.logger.Debug("skipping conversation cleanup, another replica runs it")
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	cutoff := time.Now().UTC().AddDate(0, 0, -c.retentionDays)
+	deleted, err := c.store.DeleteConversations(ctx, schemas.ConversationFilter{UpdatedBefore: cutoff})
+	if err != nil {
+		c.logger.Error("failed to delete old conversations: %v", err)
+		return
+	}
+	if deleted > 0 {
+		c.logger.Info("conversation cleanup completed: deleted %d conversations not updated since %s", deleted, cutoff.Format(time.RFC3339))
+	}
+}
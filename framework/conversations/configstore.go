@@ -0,0 +1,75 @@
+package conversations
+
+import (
+	"context"
+	"errors"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	"github.com/maximhq/bifrost/framework/configstore/tables"
+)
+
+// ConfigStoreStore keeps the conversations in the conversations table of the config store.
+type ConfigStoreStore struct {
+	store configstore.ConfigStore
+}
+
+// NewConfigStoreStore creates a conversation store backed by the config store.
+func NewConfigStoreStore(store configstore.ConfigStore) *ConfigStoreStore {
+	return &ConfigStoreStore{store: store}
+}
+
+// GetConversation returns the conversation with the ID, or nil if it is unknown.
+func (s *ConfigStoreStore) GetConversation(ctx context.Context, id string) (*schemas.Conversation, error) {
+	row, err := s.store.GetConversation(ctx, id)
+	if err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	conversation := row.ToSchema()
+	return &conversation, nil
+}
+
+// SaveConversation creates or replaces the conversation.
+func (s *ConfigStoreStore) SaveConversation(ctx context.Context, conversation *schemas.Conversation) error {
+	return s.store.UpsertConversation(ctx, &tables.TableConversation{
+		ID:        conversation.ID,
+		Owner:     conversation.Owner,
+		User:      conversation.User,
+		Messages:  conversation.Messages,
+		CreatedAt: conversation.CreatedAt,
+		UpdatedAt: conversation.UpdatedAt,
+	})
+}
+
+// ListConversations returns the conversations matching the filter, oldest first.
+func (s *ConfigStoreStore) ListConversations(ctx context.Context, filter schemas.ConversationFilter) ([]schemas.Conversation, error) {
+	rows, err := s.store.GetConversations(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	conversations := make([]schemas.Conversation, 0, len(rows))
+	for i := range rows {
+		conversations = append(conversations, rows[i].ToSchema())
+	}
+	return conversations, nil
+}
+
+// DeleteConversation deletes the conversation with the ID, and reports false if it is unknown.
+func (s *ConfigStoreStore) DeleteConversation(ctx context.Context, id string) (bool, error) {
+	if err := s.store.DeleteConversation(ctx, id); err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// DeleteConversations deletes the conversations matching the filter and returns how many were deleted.
+func (s *ConfigStoreStore) DeleteConversations(ctx context.Context, filter schemas.ConversationFilter) (int, error) {
+	deleted, err := s.store.DeleteConversations(ctx, filter)
+	return int(deleted), err
+}
@@ -0,0 +1,188 @@
+package conversations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultRedisKeyPrefix is the prefix of the keys of the redis store when the config does not set it
+const DefaultRedisKeyPrefix = "bifrost:conversations:"
+
+type RedisConfig struct {
+	Addr      string `json:"addr"`                 // Redis server address (host:port) - REQUIRED
+	Username  string `json:"username,omitempty"`   // Username for Redis AUTH (optional)
+	Password  string `json:"password,omitempty"`   // Password for Redis AUTH (optional)
+	DB        int    `json:"db,omitempty"`         // Redis database number (default: 0)
+	KeyPrefix string `json:"key_prefix,omitempty"` // Prefix of the keys of the store (default: bifrost:conversations:)
+}
+
+// RedisStore keeps every conversation as a JSON value, indexed by sets of the conversations of each owner and end
+// user.
+type RedisStore struct {
+	client *redis.Client
+	config RedisConfig
+}
+
+// GetConversation returns the conversation with the ID, or nil if it is unknown.
+func (s *RedisStore) GetConversation(ctx context.Context, id string) (*schemas.Conversation, error) {
+	data, err := s.client.Get(ctx, s.conversationKey(id)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var conversation schemas.Conversation
+	if err := json.Unmarshal(data, &conversation); err != nil {
+		return nil, fmt.Errorf("failed to decode conversation %s: %w", id, err)
+	}
+	return &conversation, nil
+}
+
+// SaveConversation creates or replaces the conversation and indexes it.
+func (s *RedisStore) SaveConversation(ctx context.Context, conversation *schemas.Conversation) error {
+
+	data, err := json.Marshal(conversation)
+	if err != nil {
+		return fmt.Errorf("failed to encode conversation %s: %w", conversation.ID, err)
+	}
+	pipe := s.client.TxPipeline()
+	pipe.Set(ctx, s.conversationKey(conversation.ID), data, 0)
+	for _, index := range s.indexKeys(conversation.Owner, conversation.User) {
+		pipe.SAdd(ctx, index, conversation.ID)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// ListConversations returns the conversations matching the filter, oldest first.
+func (s *RedisStore) ListConversations(ctx context.Context, filter schemas.ConversationFilter) ([]schemas.Conversation, error) {
+	var indexes []string
+	if filter.Owner != "" {
+		indexes = append(indexes, s.config.KeyPrefix+"owner:"+filter.Owner)
+	}
+	if filter.User != "" {
+		indexes = append(indexes, s.config.KeyPrefix+"user:"+filter.User)
+	}
+	if len(indexes) == 0 {
+		indexes = append(indexes, s.config.KeyPrefix+"all")
+	}
+	ids, err := s.client.SInter(ctx, indexes...).Result()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = s.conversationKey(id)
+	}
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	conversations := make([]schemas.Conversation, 0, len(values))
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			// Deleted since it was indexed
+			continue
+		}
+		var conversation schemas.Conversation
+		if err := json.Unmarshal([]byte(data), &conversation); err != nil {
+			return nil, fmt.Errorf("failed to decode conversation %s: %w", ids[i], err)
+		}
+		if !filter.UpdatedBefore.IsZero() && !conversation.UpdatedAt.Before(filter.UpdatedBefore) {
+			continue
+		}
+		conversations = append(conversations, conversation)
+	}
+	sort.Slice(conversations, func(i, j int) bool {
+		return conversations[i].CreatedAt.Before(conversations[j].CreatedAt)
+	})
+	return conversations, nil
+}
+
+// DeleteConversation deletes the conversation with the ID and its index entries, and reports false if it is unknown.
+func (s *RedisStore) DeleteConversation(ctx context.Context, id string) (bool, error) {
+	conversation, err := s.GetConversation(ctx, id)
+	if err != nil || conversation == nil {
+		return false, err
+	}
+	pipe := s.client.TxPipeline()
+	pipe.Del(ctx, s.conversationKey(id))
+	for _, index := range s.indexKeys(conversation.Owner, conversation.User) {
+		pipe.SRem(ctx, index, id)
+	}
+	_, err = pipe.Exec(ctx)
+	return err == nil, err
+}
+
+// DeleteConversations deletes the conversations matching the filter and returns how many were deleted.
+func (s *RedisStore) DeleteConversations(ctx context.Context, filter schemas.ConversationFilter) (int, error) {
+	conversations, err := s.ListConversations(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, conversation := range conversations {
+		ok, err := s.DeleteConversation(ctx, conversation.ID)
+		if err != nil {
+			return deleted, err
+		}
+		if ok {
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// conversationKey returns the key of the value of a conversation
+func (s *RedisStore) conversationKey(id string) string {
+	return s.config.KeyPrefix + "id:" + id
+}
+
+// indexKeys returns the keys of the sets indexing a conversation of the owner and end user
+func (s *RedisStore) indexKeys(owner, user string) []string {
+	keys := []string{s.config.KeyPrefix + "all"}
+	if owner != "" {
+		keys = append(keys, s.config.KeyPrefix+"owner:"+owner)
+	}
+	if user != "" {
+		keys = append(keys, s.config.KeyPrefix+"user:"+user)
+	}
+	return keys
+}
+
+// newRedisStore creates a new redis conversation store and checks that the server is reachable.
+func newRedisStore(ctx context.Context, config RedisConfig) (*RedisStore, error) {
+	if config.Addr == "" {
+		return nil, fmt.Errorf("redis addr is required")
+	}
+	if config.KeyPrefix == "" {
+		config.KeyPrefix = DefaultRedisKeyPrefix
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:     config.Addr,
+		Username: config.Username,
+		Password: config.Password,
+		DB:       config.DB,
+	})
+	store := &RedisStore{client: client, config: config}
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	return store, nil
+}
+
+// Close closes the connection to redis.
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
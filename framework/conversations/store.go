@@ -0,0 +1,41 @@
+// Package conversations provides the stores of the conversations continued by bifrost.
+package conversations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+)
+
+type StoreType string
+
+const (
+	StoreTypeConfigStore StoreType = "configstore"
+	StoreTypeRedis       StoreType = "redis"
+)
+
+// Config represents the configuration of the conversation store.
+type Config struct {
+	Type  StoreType    `json:"type"`            // configstore (default) or redis
+	Redis *RedisConfig `json:"redis,omitempty"` // Required by the redis store
+}
+
+// NewStore creates the conversation store of the configuration. The configstore store requires configStore.
+func NewStore(ctx context.Context, config *Config, configStore configstore.ConfigStore) (schemas.ConversationStore, error) {
+	switch config.Type {
+	case "", StoreTypeConfigStore:
+		if configStore == nil {
+			return nil, fmt.Errorf("the configstore conversation store requires the config store to be enabled")
+		}
+		return NewConfigStoreStore(configStore), nil
+	case StoreTypeRedis:
+		if config.Redis == nil {
+			return nil, fmt.Errorf("the redis conversation store requires a redis config")
+		}
+		return newRedisStore(ctx, *config.Redis)
+	default:
+		return nil, fmt.Errorf("unsupported conversation store type: %s", config.Type)
+	}
+}
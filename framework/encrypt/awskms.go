@@ -0,0 +1,163 @@
+package encrypt
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+)
+
+// awsKMS is the built-in KMS for "awskms://<key id, alias or arn>" key references. Data keys are wrapped with the
+// Encrypt and Decrypt operations of AWS KMS, signed with the AWS default credential chain, so the key encryption key
+// never leaves AWS. Each namespace should reference its own KMS key, whose key policy decides who can decrypt its
+// payloads, and disabling or scheduling the deletion of the key revokes it for every replica.
+type awsKMS struct {
+	client   *http.Client
+	endpoint string // Overrides the regional endpoint, used by tests
+
+	mu      sync.Mutex
+	configs map[string]aws.Config // AWS configs per region, reused so that their credentials are cached
+}
+
+// awsKMSEncryptionContextKey binds the wrapped data keys to their key reference in the KMS audit log
+const awsKMSEncryptionContextKey = "bifrost:key_ref"
+
+// awsKMSRequest is the request body of the Encrypt and Decrypt operations
+type awsKMSRequest struct {
+	KeyId             string            `json:"KeyId"`
+	Plaintext         []byte            `json:"Plaintext,omitempty"`
+	CiphertextBlob    []byte            `json:"CiphertextBlob,omitempty"`
+	EncryptionContext map[string]string `json:"EncryptionContext"`
+}
+
+// awsKMSResponse is the response body of the Encrypt and Decrypt operations
+type awsKMSResponse struct {
+	Plaintext      []byte `json:"Plaintext,omitempty"`
+	CiphertextBlob []byte `json:"CiphertextBlob,omitempty"`
+	Type           string `json:"__type,omitempty"`
+	Message        string `json:"message,omitempty"`
+}
+
+// WrapKey encrypts the data key with the AWS KMS key
+func (k *awsKMS) WrapKey(ctx context.Context, keyRef string, dataKey []byte) ([]byte, error) {
+	resp, err := k.call(ctx, "Encrypt", keyRef, awsKMSRequest{Plaintext: dataKey})
+	if err != nil {
+		return nil, err
+	}
+	return resp.CiphertextBlob, nil
+}
+
+// UnwrapKey decrypts the data key with the AWS KMS key. It fails once the key is disabled or its grants are removed.
+func (k *awsKMS) UnwrapKey(ctx context.Context, keyRef string, wrappedKey []byte) ([]byte, error) {
+	resp, err := k.call(ctx, "Decrypt", keyRef, awsKMSRequest{CiphertextBlob: wrappedKey})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Plaintext) != 32 {
+		return nil, fmt.Errorf("aws kms returned a %d-byte data key, expected 32 bytes", len(resp.Plaintext))
+	}
+	return resp.Plaintext, nil
+}
+
+// call sends a signed request for a KMS operation on the key of the key reference
+func (k *awsKMS) call(ctx context.Context, operation string, keyRef string, body awsKMSRequest) (*awsKMSResponse, error) {
+	keyID := strings.TrimPrefix(keyRef, "awskms://")
+	cfg, err := k.config(ctx, awsKMSKeyRegion(keyID))
+	if err != nil {
+		return nil, err
+	}
+	creds, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve aws credentials: %v", err)
+	}
+
+	body.KeyId = keyID
+	body.EncryptionContext = map[string]string{awsKMSEncryptionContextKey: keyRef}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := k.endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://kms.%s.amazonaws.com/", cfg.Region)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService."+operation)
+	payloadHash := sha256.Sum256(payload)
+	if err := v4.NewSigner().SignHTTP(ctx, creds, req, hex.EncodeToString(payloadHash[:]), "kms", cfg.Region, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to sign request: %v", err)
+	}
+
+	httpResp, err := k.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("aws kms %s failed: %v", operation, err)
+	}
+	defer httpResp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(httpResp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read aws kms response: %v", err)
+	}
+	var resp awsKMSResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("aws kms %s failed with status %d: %s", operation, httpResp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		// A disabled, deleted or inaccessible key revokes the key reference
+		switch errorType := resp.Type[strings.LastIndex(resp.Type, "#")+1:]; errorType {
+		case "DisabledException", "NotFoundException", "KMSInvalidStateException", "AccessDeniedException":
+			return nil, fmt.Errorf("%w: %s: %s", ErrKeyRevoked, errorType, resp.Message)
+		default:
+			return nil, fmt.Errorf("aws kms %s failed with status %d: %s: %s", operation, httpResp.StatusCode, errorType, resp.Message)
+		}
+	}
+	return &resp, nil
+}
+
+// config returns the AWS config of the region, the region of the environment when empty
+func (k *awsKMS) config(ctx context.Context, region string) (aws.Config, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if cfg, ok := k.configs[region]; ok {
+		return cfg, nil
+	}
+	options := []func(*awsconfig.LoadOptions) error{}
+	if region != "" {
+		options = append(options, awsconfig.WithRegion(region))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to load aws config: %v", err)
+	}
+	if cfg.Region == "" {
+		return aws.Config{}, fmt.Errorf("key reference must be a key arn when the environment sets no aws region")
+	}
+	if k.configs == nil {
+		k.configs = make(map[string]aws.Config)
+	}
+	k.configs[region] = cfg
+	return cfg, nil
+}
+
+// awsKMSKeyRegion returns the region of a key or alias arn, and an empty region for key ids and alias names
+func awsKMSKeyRegion(keyID string) string {
+	parts := strings.SplitN(keyID, ":", 6)
+	if len(parts) == 6 && parts[0] == "arn" && parts[2] == "kms" {
+		return parts[3]
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+package encrypt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeAWSKMS serves the Encrypt and Decrypt operations of AWS KMS, wrapping data keys by prefixing them with the key id
+type fakeAWSKMS struct {
+	disabled map[string]bool
+}
+
+func (f *fakeAWSKMS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") || !strings.Contains(r.Header.Get("Authorization"), "/kms/aws4_request") {
+		http.Error(w, `{"__type":"MissingAuthenticationTokenException"}`, http.StatusBadRequest)
+		return
+	}
+	var req awsKMSRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.EncryptionContext[awsKMSEncryptionContextKey] != "awskms://"+req.KeyId {
+		http.Error(w, `{"__type":"InvalidCiphertextException"}`, http.StatusBadRequest)
+		return
+	}
+	if f.disabled[req.KeyId] {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"__type":"com.amazonaws.kms#DisabledException","message":"key is disabled"}`))
+		return
+	}
+	var resp awsKMSResponse
+	switch r.Header.Get("X-Amz-Target") {
+	case "TrentService.Encrypt":
+		resp.CiphertextBlob = append([]byte(req.KeyId), req.Plaintext...)
+	case "TrentService.Decrypt":
+		resp.Plaintext = []byte(strings.TrimPrefix(string(req.CiphertextBlob), req.KeyId))
+	default:
+		http.Error(w, `{"__type":"UnknownOperationException"}`, http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func TestAWSKMSWrapUnwrap(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_CONFIG_FILE", t.TempDir()+"/config")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", t.TempDir()+"/credentials")
+
+	fake := &fakeAWSKMS{disabled: map[string]bool{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	kms := &awsKMS{client: server.Client(), endpoint: server.URL}
+
+	ctx := context.Background()
+	keyRef := "awskms://arn:aws:kms:eu-west-1:111122223333:key/tenant-a"
+	dataKey := make([]byte, 32)
+	wrapped, err := kms.WrapKey(ctx, keyRef, dataKey)
+	if err != nil {
+		t.Fatalf("Failed to wrap data key: %v", err)
+	}
+	unwrapped, err := kms.UnwrapKey(ctx, keyRef, wrapped)
+	if err != nil {
+		t.Fatalf("Failed to unwrap data key: %v", err)
+	}
+	if string(unwrapped) != string(dataKey) {
+		t.Errorf("Unwrapped data key doesn't match the wrapped one")
+	}
+
+	// A data key wrapped for a tenant is not unwrapped with the key reference of another tenant
+	if _, err := kms.UnwrapKey(ctx, "awskms://arn:aws:kms:eu-west-1:111122223333:key/tenant-b", wrapped); err == nil {
+		t.Errorf("Expected the data key of another key reference to be refused")
+	}
+
+	fake.disabled["arn:aws:kms:eu-west-1:111122223333:key/tenant-a"] = true
+	if _, err := kms.UnwrapKey(ctx, keyRef, wrapped); !errors.Is(err, ErrKeyRevoked) {
+		t.Errorf("Expected ErrKeyRevoked once the key is disabled in the KMS, got: %v", err)
+	}
+}
+
+func TestAWSKMSKeyRegion(t *testing.T) {
+	testCases := map[string]string{
+		"arn:aws:kms:us-east-2:111122223333:key/1234abcd":    "us-east-2",
+		"arn:aws:kms:eu-central-1:111122223333:alias/tenant": "eu-central-1",
+		"1234abcd-12ab-34cd-56ef-1234567890ab":               "",
+		"alias/tenant":                                       "",
+	}
+	for keyID, expected := range testCases {
+		if region := awsKMSKeyRegion(keyID); region != expected {
+			t.Errorf("Expected region %q for %s, got %q", expected, keyID, region)
+		}
+	}
+}
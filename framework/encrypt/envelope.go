@@ -0,0 +1,357 @@
+package encrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// EnvelopePrefix marks values encrypted with EnvelopeEncrypt
+const EnvelopePrefix = "bfenv1:"
+
+const (
+	// dataKeyMaxAge is how long a generated data key is reused for encryption before a new one is generated
+	dataKeyMaxAge = time.Hour
+	// unwrappedKeyTTL is how long an unwrapped data key is cached, bounding how long a key revoked
+	// in the KMS keeps decrypting payloads in this process
+	unwrappedKeyTTL = 5 * time.Minute
+)
+
+var (
+	ErrKeyRevoked          = errors.New("payload encryption key is revoked")
+	ErrUnknownKMS          = errors.New("no KMS registered for key reference")
+	ErrInvalidEnvelope     = errors.New("invalid envelope encrypted value")
+	ErrInvalidKeyReference = errors.New("invalid key reference, expected <scheme>://<key>")
+)
+
+// KMS wraps and unwraps data keys with a key encryption key that never leaves the KMS.
+// Implementations are registered per key reference scheme with RegisterKMS. The "env" and "awskms" schemes are built in.
+type KMS interface {
+	// WrapKey encrypts a data key with the key encryption key identified by keyRef
+	WrapKey(ctx context.Context, keyRef string, dataKey []byte) ([]byte, error)
+	// UnwrapKey decrypts a data key wrapped by WrapKey. It must fail once the key encryption key is revoked.
+	UnwrapKey(ctx context.Context, keyRef string, wrappedKey []byte) ([]byte, error)
+}
+
+// envelope is the serialized form of an envelope encrypted value
+type envelope struct {
+	KeyRef     string `json:"k"`
+	WrappedKey []byte `json:"w"`
+	Ciphertext []byte `json:"c"`
+}
+
+// dataKey is a data key together with its wrapped form
+type dataKey struct {
+	plaintext []byte
+	wrapped   []byte
+	expiresAt time.Time
+}
+
+var (
+	kmsMu       sync.RWMutex
+	kmsRegistry = map[string]KMS{"env": envKMS{}, "awskms": &awsKMS{client: http.DefaultClient}}
+
+	// dataKeysMu only guards the caches, the KMS is called outside of it by a single caller per data key
+	dataKeysMu     sync.Mutex
+	dataKeys       = map[string]*dataKey{} // keyed by key reference, used for encryption
+	unwrappedKeys  = map[string]*dataKey{} // keyed by key reference and wrapped key, used for decryption
+	revokedKeyRefs = map[string]struct{}{}
+	wrapCalls      singleflight.Group // keyed by key reference
+	unwrapCalls    singleflight.Group // keyed by key reference and wrapped key
+
+	generateDataKey = func() ([]byte, error) {
+		key := make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, key); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+)
+
+// RegisterKMS registers the KMS handling key references of the given scheme
+func RegisterKMS(scheme string, kms KMS) {
+	kmsMu.Lock()
+	defer kmsMu.Unlock()
+	kmsRegistry[scheme] = kms
+}
+
+// getKMS returns the KMS handling the key reference
+func getKMS(keyRef string) (KMS, error) {
+	scheme, key, found := strings.Cut(keyRef, "://")
+	if !found || scheme == "" || key == "" {
+		return nil, ErrInvalidKeyReference
+	}
+	kmsMu.RLock()
+	defer kmsMu.RUnlock()
+	kms, ok := kmsRegistry[scheme]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKMS, scheme)
+	}
+	return kms, nil
+}
+
+// ValidateKeyRef checks that a key reference is well formed and handled by a registered KMS
+func ValidateKeyRef(keyRef string) error {
+	_, err := getKMS(keyRef)
+	return err
+}
+
+// RevokeKeyRef makes this process refuse to encrypt or decrypt with the key reference and drops its cached data keys.
+// Payloads only become permanently unreadable once the key is also disabled or deleted in the KMS itself.
+func RevokeKeyRef(keyRef string) {
+	dataKeysMu.Lock()
+	defer dataKeysMu.Unlock()
+	revokedKeyRefs[keyRef] = struct{}{}
+	delete(dataKeys, keyRef)
+	for cacheKey := range unwrappedKeys {
+		if strings.HasPrefix(cacheKey, keyRef+"|") {
+			delete(unwrappedKeys, cacheKey)
+		}
+	}
+}
+
+// IsKeyRefRevoked reports whether the key reference was revoked with RevokeKeyRef
+func IsKeyRefRevoked(keyRef string) bool {
+	dataKeysMu.Lock()
+	defer dataKeysMu.Unlock()
+	_, revoked := revokedKeyRefs[keyRef]
+	return revoked
+}
+
+// IsEnvelopeEncrypted reports whether the value was encrypted with EnvelopeEncrypt
+func IsEnvelopeEncrypted(value string) bool {
+	return strings.HasPrefix(value, EnvelopePrefix)
+}
+
+// EnvelopeEncrypt encrypts the plaintext with a data key that is itself wrapped by the key encryption key
+// identified by keyRef. The wrapped data key is stored alongside the ciphertext, so decryption needs the KMS.
+// Data keys are reused for up to an hour to avoid a KMS round trip per value.
+func EnvelopeEncrypt(ctx context.Context, keyRef string, plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	key, err := getDataKey(ctx, keyRef)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := sealWithKey(key.plaintext, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(envelope{KeyRef: keyRef, WrappedKey: key.wrapped, Ciphertext: ciphertext})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+	return EnvelopePrefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// EnvelopeDecrypt decrypts a value encrypted with EnvelopeEncrypt. Values without the envelope prefix are returned as is.
+// It returns ErrKeyRevoked if the key reference was revoked, and the KMS error if the data key cannot be unwrapped.
+func EnvelopeDecrypt(ctx context.Context, value string) (string, error) {
+	if !IsEnvelopeEncrypted(value) {
+		return value, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, EnvelopePrefix))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+	key, err := getUnwrappedKey(ctx, env.KeyRef, env.WrappedKey)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := openWithKey(key, env.Ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// getDataKey returns the current data key of the key reference, generating and wrapping a new one if needed
+func getDataKey(ctx context.Context, keyRef string) (*dataKey, error) {
+	kms, err := getKMS(keyRef)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := cachedDataKey(keyRef); key != nil || err != nil {
+		return key, err
+	}
+	// Concurrent callers share the wrap, which outlives the cancellation of the caller running it
+	result, err, _ := wrapCalls.Do(keyRef, func() (any, error) {
+		if key, err := cachedDataKey(keyRef); key != nil || err != nil {
+			return key, err
+		}
+		plaintext, err := generateDataKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate data key: %w", err)
+		}
+		wrapped, err := kms.WrapKey(context.WithoutCancel(ctx), keyRef, plaintext)
+		if err != nil {
+			return nil, fmt.Errorf("failed to wrap data key: %w", err)
+		}
+		key := &dataKey{plaintext: plaintext, wrapped: wrapped, expiresAt: time.Now().Add(dataKeyMaxAge)}
+		dataKeysMu.Lock()
+		defer dataKeysMu.Unlock()
+		// The key reference may have been revoked while the data key was wrapped
+		if _, revoked := revokedKeyRefs[keyRef]; revoked {
+			return nil, ErrKeyRevoked
+		}
+		dataKeys[keyRef] = key
+		return key, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*dataKey), nil
+}
+
+// cachedDataKey returns the current data key of the key reference, nil when it has to be generated
+func cachedDataKey(keyRef string) (*dataKey, error) {
+	dataKeysMu.Lock()
+	defer dataKeysMu.Unlock()
+	if _, revoked := revokedKeyRefs[keyRef]; revoked {
+		return nil, ErrKeyRevoked
+	}
+	if key, ok := dataKeys[keyRef]; ok && time.Now().Before(key.expiresAt) {
+		return key, nil
+	}
+	return nil, nil
+}
+
+// getUnwrappedKey unwraps a data key with the KMS, caching the result for a short time
+func getUnwrappedKey(ctx context.Context, keyRef string, wrapped []byte) ([]byte, error) {
+	kms, err := getKMS(keyRef)
+	if err != nil {
+		return nil, err
+	}
+	cacheKey := keyRef + "|" + base64.StdEncoding.EncodeToString(wrapped)
+	if key, err := cachedUnwrappedKey(keyRef, cacheKey); key != nil || err != nil {
+		return key, err
+	}
+	result, err, _ := unwrapCalls.Do(cacheKey, func() (any, error) {
+		if key, err := cachedUnwrappedKey(keyRef, cacheKey); key != nil || err != nil {
+			return key, err
+		}
+		plaintext, err := kms.UnwrapKey(context.WithoutCancel(ctx), keyRef, wrapped)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+		}
+		dataKeysMu.Lock()
+		defer dataKeysMu.Unlock()
+		// The key reference may have been revoked while the data key was unwrapped
+		if _, revoked := revokedKeyRefs[keyRef]; revoked {
+			return nil, ErrKeyRevoked
+		}
+		unwrappedKeys[cacheKey] = &dataKey{plaintext: plaintext, wrapped: wrapped, expiresAt: time.Now().Add(unwrappedKeyTTL)}
+		return plaintext, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]byte), nil
+}
+
+// cachedUnwrappedKey returns the cached unwrapped data key, nil when it has to be unwrapped by the KMS
+func cachedUnwrappedKey(keyRef string, cacheKey string) ([]byte, error) {
+	dataKeysMu.Lock()
+	defer dataKeysMu.Unlock()
+	if _, revoked := revokedKeyRefs[keyRef]; revoked {
+		return nil, ErrKeyRevoked
+	}
+	if key, ok := unwrappedKeys[cacheKey]; ok && time.Now().Before(key.expiresAt) {
+		return key.plaintext, nil
+	}
+	return nil, nil
+}
+
+// sealWithKey encrypts data with AES-256-GCM, prefixing the nonce
+func sealWithKey(key []byte, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aesGCM, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	nonce := make([]byte, aesGCM.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to read nonce: %w", err)
+	}
+	return aesGCM.Seal(nonce, nonce, data, nil), nil
+}
+
+// openWithKey decrypts data encrypted with sealWithKey
+func openWithKey(key []byte, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aesGCM, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	nonceSize := aesGCM.NonceSize()
+	if len(data) < nonceSize {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	plaintext, err := aesGCM.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return plaintext, nil
+}
+
+// envKMS is the built-in KMS for "env://<VAR>" key references, where the environment variable holds
+// a base64 encoded 32-byte key encryption key. Unsetting the variable revokes the key on restart.
+// The key encryption key lives in the environment of the gateway, so it offers no isolation from its operators.
+// It is meant for self-managed deployments, regulated tenants should use awskms:// or a KMS registered with RegisterKMS.
+type envKMS struct{}
+
+// keyEncryptionKey reads the key encryption key of an env:// key reference
+func (envKMS) keyEncryptionKey(keyRef string) ([]byte, error) {
+	name := strings.TrimPrefix(keyRef, "env://")
+	value := os.Getenv(name)
+	if value == "" {
+		return nil, fmt.Errorf("%w: environment variable %s is not set", ErrKeyRevoked, name)
+	}
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("environment variable %s must hold a base64 encoded 32-byte key", name)
+	}
+	return key, nil
+}
+
+// WrapKey encrypts the data key with the key encryption key from the environment
+func (k envKMS) WrapKey(ctx context.Context, keyRef string, dataKey []byte) ([]byte, error) {
+	kek, err := k.keyEncryptionKey(keyRef)
+	if err != nil {
+		return nil, err
+	}
+	return sealWithKey(kek, dataKey)
+}
+
+// UnwrapKey decrypts the data key with the key encryption key from the environment
+func (k envKMS) UnwrapKey(ctx context.Context, keyRef string, wrappedKey []byte) ([]byte, error) {
+	kek, err := k.keyEncryptionKey(keyRef)
+	if err != nil {
+		return nil, err
+	}
+	return openWithKey(kek, wrappedKey)
+}
@@ -0,0 +1,180 @@
+package encrypt
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// setTestKEK sets a random key encryption key in the environment and returns its env:// key reference
+func setTestKEK(t *testing.T, name string) string {
+	kek := make([]byte, 32)
+	if _, err := rand.Read(kek); err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	t.Setenv(name, base64.StdEncoding.EncodeToString(kek))
+	return "env://" + name
+}
+
+func TestEnvelopeEncryptDecrypt(t *testing.T) {
+	ctx := context.Background()
+	keyRef := setTestKEK(t, "BIFROST_TEST_TENANT_KEK")
+
+	plaintext := `{"role":"user","content":"tenant secret"}`
+	encrypted, err := EnvelopeEncrypt(ctx, keyRef, plaintext)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	if !IsEnvelopeEncrypted(encrypted) {
+		t.Fatalf("Expected envelope prefix, got: %s", encrypted)
+	}
+
+	decrypted, err := EnvelopeDecrypt(ctx, encrypted)
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	if decrypted != plaintext {
+		t.Errorf("Decrypted text doesn't match. Expected: %s, Got: %s", plaintext, decrypted)
+	}
+
+	// Plain values are passed through
+	if value, err := EnvelopeDecrypt(ctx, "not encrypted"); err != nil || value != "not encrypted" {
+		t.Errorf("Expected plain value to be returned as is, got: %s, %v", value, err)
+	}
+	// Empty values stay empty
+	if value, err := EnvelopeEncrypt(ctx, keyRef, ""); err != nil || value != "" {
+		t.Errorf("Expected empty string for empty input, got: %s, %v", value, err)
+	}
+}
+
+func TestEnvelopeRevokeKeyRef(t *testing.T) {
+	ctx := context.Background()
+	revokedRef := setTestKEK(t, "BIFROST_TEST_REVOKED_KEK")
+	otherRef := setTestKEK(t, "BIFROST_TEST_OTHER_KEK")
+
+	encrypted, err := EnvelopeEncrypt(ctx, revokedRef, "payload")
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	other, err := EnvelopeEncrypt(ctx, otherRef, "payload")
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+
+	RevokeKeyRef(revokedRef)
+
+	if _, err := EnvelopeDecrypt(ctx, encrypted); !errors.Is(err, ErrKeyRevoked) {
+		t.Errorf("Expected ErrKeyRevoked when decrypting with a revoked key, got: %v", err)
+	}
+	if _, err := EnvelopeEncrypt(ctx, revokedRef, "payload"); !errors.Is(err, ErrKeyRevoked) {
+		t.Errorf("Expected ErrKeyRevoked when encrypting with a revoked key, got: %v", err)
+	}
+	if _, err := EnvelopeDecrypt(ctx, other); err != nil {
+		t.Errorf("Expected other tenant payloads to stay readable, got: %v", err)
+	}
+}
+
+func TestEnvelopeKeyRemovedFromKMS(t *testing.T) {
+	ctx := context.Background()
+	keyRef := setTestKEK(t, "BIFROST_TEST_REMOVED_KEK")
+
+	encrypted, err := EnvelopeEncrypt(ctx, keyRef, "payload")
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+
+	// Drop the cached data keys, as a restart would, and remove the key from the KMS
+	dataKeysMu.Lock()
+	dataKeys = map[string]*dataKey{}
+	unwrappedKeys = map[string]*dataKey{}
+	dataKeysMu.Unlock()
+	t.Setenv("BIFROST_TEST_REMOVED_KEK", "")
+
+	if _, err := EnvelopeDecrypt(ctx, encrypted); !errors.Is(err, ErrKeyRevoked) {
+		t.Errorf("Expected ErrKeyRevoked once the key is removed from the KMS, got: %v", err)
+	}
+}
+
+func TestValidateKeyRef(t *testing.T) {
+	testCases := []struct {
+		keyRef string
+		valid  bool
+	}{
+		{"env://TENANT_KEK", true},
+		{"awskms://arn:aws:kms:eu-west-1:111122223333:key/1234abcd", true},
+		{"TENANT_KEK", false},
+		{"env://", false},
+		{"unknown://key", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.keyRef, func(t *testing.T) {
+			err := ValidateKeyRef(tc.keyRef)
+			if tc.valid && err != nil {
+				t.Errorf("Expected %s to be valid, got: %v", tc.keyRef, err)
+			}
+			if !tc.valid && err == nil {
+				t.Errorf("Expected %s to be invalid", tc.keyRef)
+			}
+		})
+	}
+}
+
+// blockingKMS wraps data keys as is, blocking until released and counting its calls
+type blockingKMS struct {
+	calls   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (k *blockingKMS) WrapKey(ctx context.Context, keyRef string, dataKey []byte) ([]byte, error) {
+	if k.calls.Add(1) == 1 {
+		close(k.started)
+	}
+	<-k.release
+	return dataKey, nil
+}
+
+func (k *blockingKMS) UnwrapKey(ctx context.Context, keyRef string, wrappedKey []byte) ([]byte, error) {
+	return wrappedKey, nil
+}
+
+func TestEnvelopeWrapOutsideLock(t *testing.T) {
+	ctx := context.Background()
+	kms := &blockingKMS{started: make(chan struct{}), release: make(chan struct{})}
+	RegisterKMS("blocking", kms)
+	otherRef := setTestKEK(t, "BIFROST_TEST_UNBLOCKED_KEK")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := EnvelopeEncrypt(ctx, "blocking://tenant", "payload")
+			errs <- err
+		}()
+	}
+	<-kms.started
+
+	// Other key references are not held up by the pending wrap
+	if _, err := EnvelopeEncrypt(ctx, otherRef, "payload"); err != nil {
+		t.Fatalf("Failed to encrypt while another key is wrapped: %v", err)
+	}
+
+	close(kms.release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Failed to encrypt: %v", err)
+		}
+	}
+	if calls := kms.calls.Load(); calls != 1 {
+		t.Errorf("Expected the concurrent encryptions to share a single wrap, got %d", calls)
+	}
+}
@@ -3,14 +3,20 @@ package envutils
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
 )
 
-// ProcessEnvValue processes a value that might be an environment variable reference
+// referencePattern matches the ${env:VAR_NAME} and ${file:/path} references interpolated in configuration values
+var referencePattern = regexp.MustCompile(`\$\{(env|file):([^}]*)\}`)
+
+// ProcessEnvValue processes a value that might reference secrets. A value of the form env.VAR_NAME is replaced by the
+// environment variable, and the ${env:VAR_NAME} and ${file:/path} references it contains are replaced by the
+// environment variable or the content of the file, without its trailing newline, so that mounted secrets can be used.
 func ProcessEnvValue(value string) (string, error) {
 	v := strings.TrimSpace(value)
 	if !strings.HasPrefix(v, "env.") {
-		return value, nil
+		return interpolateReferences(value)
 	}
 	envKey := strings.TrimSpace(strings.TrimPrefix(v, "env."))
 	if envKey == "" {
@@ -21,3 +27,73 @@ func ProcessEnvValue(value string) (string, error) {
 	}
 	return "", fmt.Errorf("environment variable %s not found", envKey)
 }
+
+// IsReference reports whether a value is an env.VAR_NAME reference or contains ${env:VAR_NAME} or ${file:/path}
+// references
+func IsReference(value string) bool {
+	return strings.HasPrefix(strings.TrimSpace(value), "env.") || referencePattern.MatchString(value)
+}
+
+// HasInterpolation reports whether a value contains ${env:VAR_NAME} or ${file:/path} references
+func HasInterpolation(value string) bool {
+	return referencePattern.MatchString(value)
+}
+
+// HasFileReference reports whether a value contains ${file:/path} references. File references can read any file of
+// the server, they are only accepted from the config file and not from the values written through the API.
+func HasFileReference(value string) bool {
+	for _, parts := range referencePattern.FindAllStringSubmatch(value, -1) {
+		if parts[1] == "file" {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatReference returns the configuration value of a tracked reference: the value itself when it contains
+// ${env:VAR_NAME} or ${file:/path} references, env.VAR_NAME for the name of an environment variable
+func FormatReference(name string) string {
+	if referencePattern.MatchString(name) {
+		return name
+	}
+	return "env." + name
+}
+
+// interpolateReferences replaces the ${env:VAR_NAME} and ${file:/path} references of a value
+func interpolateReferences(value string) (string, error) {
+	if !strings.Contains(value, "${") {
+		return value, nil
+	}
+	var resolveErr error
+	resolved := referencePattern.ReplaceAllStringFunc(value, func(match string) string {
+		if resolveErr != nil {
+			return match
+		}
+		parts := referencePattern.FindStringSubmatch(match)
+		source, name := parts[1], strings.TrimSpace(parts[2])
+		if name == "" {
+			resolveErr = fmt.Errorf("%s reference missing a name in %q", source, value)
+			return match
+		}
+		switch source {
+		case "env":
+			envValue, ok := os.LookupEnv(name)
+			if !ok {
+				resolveErr = fmt.Errorf("environment variable %s not found", name)
+				return match
+			}
+			return envValue
+		default:
+			content, err := os.ReadFile(name)
+			if err != nil {
+				resolveErr = fmt.Errorf("failed to read secret file %s: %w", name, err)
+				return match
+			}
+			return strings.TrimRight(string(content), "\r\n")
+		}
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return resolved, nil
+}
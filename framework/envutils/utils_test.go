@@ -0,0 +1,65 @@
+package envutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestProcessEnvValue tests the resolution of env.VAR_NAME values and of ${env:VAR_NAME} and ${file:/path} references
+func TestProcessEnvValue(t *testing.T) {
+	t.Setenv("BIFROST_TEST_KEY", "sk-env")
+	secretFile := filepath.Join(t.TempDir(), "api-key")
+	if err := os.WriteFile(secretFile, []byte("sk-file\n"), 0o600); err != nil {
+		t.Fatalf("failed to write secret file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "plain value", value: "sk-plain", want: "sk-plain"},
+		{name: "env prefix", value: "env.BIFROST_TEST_KEY", want: "sk-env"},
+		{name: "env reference", value: "${env:BIFROST_TEST_KEY}", want: "sk-env"},
+		{name: "file reference", value: "${file:" + secretFile + "}", want: "sk-file"},
+		{name: "interpolated references", value: "Bearer ${env:BIFROST_TEST_KEY}:${file:" + secretFile + "}", want: "Bearer sk-env:sk-file"},
+		{name: "unknown source left as is", value: "${vault:secret}", want: "${vault:secret}"},
+		{name: "missing env var", value: "${env:BIFROST_TEST_MISSING}", wantErr: true},
+		{name: "missing file", value: "${file:" + secretFile + ".missing}", wantErr: true},
+		{name: "empty reference", value: "${file:}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ProcessEnvValue(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error=%v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestFormatReference tests that tracked references are written back as they were configured
+func TestFormatReference(t *testing.T) {
+	if got := FormatReference("OPENAI_API_KEY"); got != "env.OPENAI_API_KEY" {
+		t.Errorf("expected env.OPENAI_API_KEY, got %q", got)
+	}
+	if got := FormatReference("${file:/var/run/secrets/openai}"); got != "${file:/var/run/secrets/openai}" {
+		t.Errorf("expected the file reference, got %q", got)
+	}
+	for value, want := range map[string]bool{"env.KEY": true, "${env:KEY}": true, "x-${file:/a}": true, "sk-123": false, "${KEY}": false} {
+		if IsReference(value) != want {
+			t.Errorf("IsReference(%q): expected %v", value, want)
+		}
+	}
+	for value, want := range map[string]bool{"${file:/etc/passwd}": true, "Bearer ${env:KEY}:${file:/a}": true, "${env:KEY}": false, "env.KEY": false} {
+		if HasFileReference(value) != want {
+			t.Errorf("HasFileReference(%q): expected %v", value, want)
+		}
+	}
+}
@@ -3,6 +3,8 @@ module github.com/maximhq/bifrost/framework
 go 1.24.3
 
 require (
+	github.com/aws/aws-sdk-go-v2 v1.40.1
+	github.com/aws/aws-sdk-go-v2/config v1.31.13
 	github.com/google/uuid v1.6.0
 	github.com/maximhq/bifrost/core v1.2.35
 	github.com/qdrant/go-client v1.16.1
@@ -11,6 +13,7 @@ require (
 	github.com/weaviate/weaviate v1.33.1
 	github.com/weaviate/weaviate-go-client/v5 v5.5.0
 	golang.org/x/crypto v0.45.0
+	golang.org/x/sync v0.18.0
 	gorm.io/driver/sqlite v1.6.0
 	gorm.io/gorm v1.31.1
 )
@@ -42,15 +45,12 @@ require (
 	go.opentelemetry.io/otel/sdk/metric v1.38.0 // indirect
 	go.opentelemetry.io/otel/trace v1.38.0 // indirect
 	go.yaml.in/yaml/v3 v3.0.4 // indirect
-	golang.org/x/sync v0.18.0 // indirect
 )
 
 require (
 	cloud.google.com/go/compute/metadata v0.9.0 // indirect
 	github.com/andybalholm/brotli v1.2.0 // indirect
 	github.com/asaskevich/govalidator v0.0.0-20230301143203-a9d515a09cc2 // indirect
-	github.com/aws/aws-sdk-go-v2 v1.40.1 // indirect
-	github.com/aws/aws-sdk-go-v2/config v1.31.13 // indirect
 	github.com/aws/aws-sdk-go-v2/credentials v1.18.17 // indirect
 	github.com/aws/aws-sdk-go-v2/feature/ec2/imds v1.18.10 // indirect
 	github.com/aws/aws-sdk-go-v2/internal/configsources v1.4.15 // indirect
@@ -0,0 +1,170 @@
+// Package leader elects the replica running the background jobs of a cluster, so that jobs like budget resets,
+// pricing syncs and log cleanups run once per cluster instead of once per replica
+package leader
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+const (
+	// DefaultLeaseName is the name of the lease of the background jobs
+	DefaultLeaseName = "background-jobs"
+	// DefaultLeaseDuration is how long the leader holds the lease without renewing it, and so how long the jobs stop
+	// when the leader dies before another replica takes over
+	DefaultLeaseDuration = 15 * time.Second
+)
+
+// LeaseStore is a store of leases shared by the replicas, such as the config store
+type LeaseStore interface {
+	AcquireLease(ctx context.Context, name string, holder string, ttl time.Duration) (bool, error)
+	ReleaseLease(ctx context.Context, name string, holder string) error
+}
+
+// Elector holds or waits for the lease of the background jobs. The lease is renewed three times per lease duration,
+// and leadership lapses once the lease duration passes without a renewal, so that two replicas never both consider
+// themselves leader for longer than the clock skew between them. A nil Elector is always leader, for deployments with
+// a single replica.
+type Elector struct {
+	store         LeaseStore
+	name          string
+	holder        string
+	leaseDuration time.Duration
+	logger        schemas.Logger
+
+	leader    atomic.Bool
+	renewedAt atomic.Int64 // Unix nanoseconds of the start of the last successful acquisition
+
+	mu   sync.Mutex
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewElector creates an elector for the lease of the background jobs. A lease duration of zero or less uses
+// DefaultLeaseDuration.
+func NewElector(store LeaseStore, leaseDuration time.Duration, logger schemas.Logger) *Elector {
+	if leaseDuration <= 0 {
+		leaseDuration = DefaultLeaseDuration
+	}
+	return &Elector{
+		store:         store,
+		name:          DefaultLeaseName,
+		holder:        newHolderID(),
+		leaseDuration: leaseDuration,
+		logger:        logger,
+	}
+}
+
+// newHolderID returns an ID unique to this process, readable in the leases table
+func newHolderID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+// Holder returns the ID the elector holds the lease under
+func (e *Elector) Holder() string {
+	if e == nil {
+		return ""
+	}
+	return e.holder
+}
+
+// IsLeader reports whether this replica holds the lease and should run the background jobs
+func (e *Elector) IsLeader() bool {
+	if e == nil {
+		return true
+	}
+	return e.leader.Load() && time.Since(time.Unix(0, e.renewedAt.Load())) < e.leaseDuration
+}
+
+// Start tries to acquire the lease right away, so that a single replica leads as soon as Start returns, and then
+// keeps renewing or waiting for it in the background
+func (e *Elector) Start() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// Return early if already running
+	if e.stop != nil {
+		return
+	}
+	e.stop = make(chan struct{})
+	stopCh := e.stop
+	e.campaign()
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ticker := time.NewTicker(e.leaseDuration / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				e.campaign()
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops renewing the lease and releases it, so that another replica takes over without waiting for it to expire
+func (e *Elector) Stop() {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	if e.stop == nil {
+		e.mu.Unlock()
+		return
+	}
+	close(e.stop)
+	e.stop = nil
+	e.mu.Unlock()
+	e.wg.Wait()
+
+	if e.leader.Swap(false) {
+		ctx, cancel := context.WithTimeout(context.Background(), e.leaseDuration/3)
+		defer cancel()
+		if err := e.store.ReleaseLease(ctx, e.name, e.holder); err != nil {
+			e.logger.Warn("failed to release the %s lease: %v", e.name, err)
+			return
+		}
+		e.logger.Info("released the %s lease", e.name)
+	}
+}
+
+// campaign acquires or renews the lease. A failure to reach the store keeps the current leadership until it lapses.
+func (e *Elector) campaign() {
+	startedAt := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), e.leaseDuration/3)
+	defer cancel()
+	acquired, err := e.store.AcquireLease(ctx, e.name, e.holder, e.leaseDuration)
+	if err != nil {
+		e.logger.Warn("failed to acquire the %s lease: %v", e.name, err)
+		return
+	}
+	if acquired {
+		e.renewedAt.Store(startedAt.UnixNano())
+		if !e.leader.Swap(true) {
+			e.logger.Info("acquired the %s lease as %s, running the background jobs of the cluster", e.name, e.holder)
+		}
+		return
+	}
+	if e.leader.Swap(false) {
+		e.logger.Warn("lost the %s lease, another replica runs the background jobs of the cluster", e.name)
+	}
+}
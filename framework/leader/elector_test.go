@@ -0,0 +1,86 @@
+package leader
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// memoryLeaseStore is an in-memory LeaseStore
+type memoryLeaseStore struct {
+	mu     sync.Mutex
+	leases map[string]memoryLease
+}
+
+type memoryLease struct {
+	holder    string
+	expiresAt time.Time
+}
+
+func (s *memoryLeaseStore) AcquireLease(ctx context.Context, name string, holder string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if lease, ok := s.leases[name]; ok && lease.holder != holder && now.Before(lease.expiresAt) {
+		return false, nil
+	}
+	s.leases[name] = memoryLease{holder: holder, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+func (s *memoryLeaseStore) ReleaseLease(ctx context.Context, name string, holder string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if lease, ok := s.leases[name]; ok && lease.holder == holder {
+		delete(s.leases, name)
+	}
+	return nil
+}
+
+// TestElector_Failover tests that a single replica leads at a time, and that another replica takes over once the
+// leader releases the lease
+func TestElector_Failover(t *testing.T) {
+	store := &memoryLeaseStore{leases: make(map[string]memoryLease)}
+	logger := bifrost.NewDefaultLogger(schemas.LogLevelError)
+	first := NewElector(store, time.Minute, logger)
+	second := NewElector(store, time.Minute, logger)
+
+	first.Start()
+	second.Start()
+	defer second.Stop()
+	if !first.IsLeader() || second.IsLeader() {
+		t.Fatalf("expected only the first replica to lead, got first=%v second=%v", first.IsLeader(), second.IsLeader())
+	}
+
+	first.Stop()
+	if first.IsLeader() {
+		t.Error("expected a stopped replica to stop leading")
+	}
+	second.campaign()
+	if !second.IsLeader() {
+		t.Error("expected the second replica to take over the released lease")
+	}
+
+	var nilElector *Elector
+	if !nilElector.IsLeader() {
+		t.Error("expected a nil elector to always lead")
+	}
+}
+
+// TestElector_LeadershipLapses tests that a leader that cannot renew its lease stops leading once the lease expires
+func TestElector_LeadershipLapses(t *testing.T) {
+	store := &memoryLeaseStore{leases: make(map[string]memoryLease)}
+	elector := NewElector(store, 100*time.Millisecond, bifrost.NewDefaultLogger(schemas.LogLevelError))
+	elector.campaign()
+	if !elector.IsLeader() {
+		t.Fatal("expected the elector to lead after acquiring the lease")
+	}
+	time.Sleep(150 * time.Millisecond)
+	if elector.IsLeader() {
+		t.Error("expected the leadership to lapse without renewals")
+	}
+}
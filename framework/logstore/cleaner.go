@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/leader"
 )
 
 const (
@@ -20,11 +21,15 @@ const (
 // LogRetentionManager defines the interface for managing log retention and deletion
 type LogRetentionManager interface {
 	DeleteLogsBatch(ctx context.Context, cutoff time.Time, batchSize int) (deletedCount int64, err error)
+	PurgePayloadsBatch(ctx context.Context, cutoff time.Time, batchSize int) (purgedCount int64, err error)
 }
 
 // CleanerConfig holds configuration for the log cleaner
 type CleanerConfig struct {
 	RetentionDays int
+	// PayloadRetentionDays clears the request and response payloads of the logs older than it, keeping their
+	// usage and cost for the rest of RetentionDays. 0 keeps the payloads as long as the logs.
+	PayloadRetentionDays int
 }
 
 // LogsCleaner manages the cleanup of old logs
@@ -32,6 +37,7 @@ type LogsCleaner struct {
 	manager     LogRetentionManager
 	config      CleanerConfig
 	logger      schemas.Logger
+	elector     *leader.Elector // Elects the replica running the cleanups, nil runs them on every replica
 	stopCleanup chan struct{}
 	mu          sync.Mutex
 }
@@ -45,6 +51,12 @@ func NewLogsCleaner(manager LogRetentionManager, config CleanerConfig, logger sc
 	}
 }
 
+// SetElector sets the elector of the replica running the cleanups, so that the logs are cleaned up once per
+// cluster. Must be called before StartCleanupRoutine.
+func (c *LogsCleaner) SetElector(elector *leader.Elector) {
+	c.elector = elector
+}
+
 // StartCleanupRoutine starts a goroutine that periodically cleans up old logs
 func (c *LogsCleaner) StartCleanupRoutine() {
 	c.mu.Lock()
@@ -61,9 +73,7 @@ func (c *LogsCleaner) StartCleanupRoutine() {
 
 	go func() {
 		// At the beginning, we will cleanup the logs
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
-		c.cleanupOldLogs(ctx)
-		cancel()
+		c.cleanup()
 		// Calculate initial delay with jitter
 		timer := time.NewTimer(calculateNextRunDuration())
 		defer timer.Stop()
@@ -71,9 +81,7 @@ func (c *LogsCleaner) StartCleanupRoutine() {
 			select {
 			case <-timer.C:
 				// Run cleanup
-				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
-				c.cleanupOldLogs(ctx)
-				cancel()
+				c.cleanup()
 
 				// Reset timer with new jitter for next run
 				timer.Reset(calculateNextRunDuration())
@@ -102,6 +110,18 @@ func (c *LogsCleaner) StopCleanupRoutine() {
 	c.stopCleanup = nil
 }
 
+// cleanup deletes the logs and purges the payloads past their retention, on the leader replica only
+func (c *LogsCleaner) cleanup() {
+	if !c.elector.IsLeader() {
+		c.logger.Debug("skipping log cleanup, another replica runs it")
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+	c.cleanupOldLogs(ctx)
+	c.purgeOldPayloads(ctx)
+}
+
 // cleanupOldLogs deletes logs older than the retention period in batches
 func (c *LogsCleaner) cleanupOldLogs(ctx context.Context) {
 	retentionDays := c.config.RetentionDays
@@ -154,6 +174,43 @@ func (c *LogsCleaner) cleanupOldLogs(ctx context.Context) {
 	}
 }
 
+// purgeOldPayloads clears the payloads of the logs older than the payload retention period in batches
+func (c *LogsCleaner) purgeOldPayloads(ctx context.Context) {
+	retentionDays := c.config.PayloadRetentionDays
+	if retentionDays < 1 {
+		return
+	}
+
+	cutoff := time.Now().UTC().AddDate(0, 0, -retentionDays)
+	c.logger.Info("starting log payload purge: clearing payloads older than %s (retention: %d days)", cutoff.Format(time.RFC3339), retentionDays)
+
+	totalPurged := int64(0)
+	for {
+		select {
+		case <-ctx.Done():
+			c.logger.Warn("log payload purge cancelled: %v", ctx.Err())
+			return
+		default:
+		}
+
+		purged, err := c.manager.PurgePayloadsBatch(ctx, cutoff, batchSize)
+		if err != nil {
+			c.logger.Error("failed to purge old log payloads: %v", err)
+			return
+		}
+		totalPurged += purged
+		if purged < int64(batchSize) {
+			break
+		}
+	}
+
+	if totalPurged > 0 {
+		c.logger.Info("log payload purge completed: cleared %d payloads", totalPurged)
+	} else {
+		c.logger.Debug("log payload purge completed: no old payloads to clear")
+	}
+}
+
 // calculateNextRunDuration returns 24 hours plus a random jitter between 15-30 minutes
 func calculateNextRunDuration() time.Duration {
 	jitter := minJitter + time.Duration(rand.Int63n(int64(maxJitter-minJitter)))
@@ -4,7 +4,6 @@ package logstore
 import (
 	"encoding/json"
 	"fmt"
-	"strings"
 
 	"github.com/maximhq/bifrost/framework/envutils"
 )
@@ -59,37 +58,37 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 			return fmt.Errorf("failed to unmarshal postgres config: %w", err)
 		}
 		// Checking if any of the values start with env. If so, we need to process them.
-		if postgresConfig.DBName != "" && strings.HasPrefix(postgresConfig.DBName, "env.") {
+		if postgresConfig.DBName != "" && envutils.IsReference(postgresConfig.DBName) {
 			postgresConfig.DBName, err = envutils.ProcessEnvValue(postgresConfig.DBName)
 			if err != nil {
 				return fmt.Errorf("failed to process env value for db name: %w", err)
 			}
 		}
-		if postgresConfig.Password != "" && strings.HasPrefix(postgresConfig.Password, "env.") {
+		if postgresConfig.Password != "" && envutils.IsReference(postgresConfig.Password) {
 			postgresConfig.Password, err = envutils.ProcessEnvValue(postgresConfig.Password)
 			if err != nil {
 				return fmt.Errorf("failed to process env value for password: %w", err)
 			}
 		}
-		if postgresConfig.User != "" && strings.HasPrefix(postgresConfig.User, "env.") {
+		if postgresConfig.User != "" && envutils.IsReference(postgresConfig.User) {
 			postgresConfig.User, err = envutils.ProcessEnvValue(postgresConfig.User)
 			if err != nil {
 				return fmt.Errorf("failed to process env value for user: %w", err)
 			}
 		}
-		if postgresConfig.Host != "" && strings.HasPrefix(postgresConfig.Host, "env.") {
+		if postgresConfig.Host != "" && envutils.IsReference(postgresConfig.Host) {
 			postgresConfig.Host, err = envutils.ProcessEnvValue(postgresConfig.Host)
 			if err != nil {
 				return fmt.Errorf("failed to process env value for host: %w", err)
 			}
 		}
-		if postgresConfig.Port != "" && strings.HasPrefix(postgresConfig.Port, "env.") {
+		if postgresConfig.Port != "" && envutils.IsReference(postgresConfig.Port) {
 			postgresConfig.Port, err = envutils.ProcessEnvValue(postgresConfig.Port)
 			if err != nil {
 				return fmt.Errorf("failed to process env value for port: %w", err)
 			}
 		}
-		if postgresConfig.SSLMode != "" && strings.HasPrefix(postgresConfig.SSLMode, "env.") {
+		if postgresConfig.SSLMode != "" && envutils.IsReference(postgresConfig.SSLMode) {
 			postgresConfig.SSLMode, err = envutils.ProcessEnvValue(postgresConfig.SSLMode)
 			if err != nil {
 				return fmt.Errorf("failed to process env value for ssl mode: %w", err)
@@ -40,6 +40,24 @@ func triggerMigrations(ctx context.Context, db *gorm.DB) error {
 	if err := migrationUpdateTimestampFormat(ctx, db); err != nil {
 		return err
 	}
+	if err := migrationAddNamespaceAndPayloadEncryptedColumns(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddIsTestKeyColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddProviderRequestColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddEndUserAndPayloadPurgedColumns(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddTagsColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrationAddFeedbackTable(ctx, db); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -638,3 +656,229 @@ func migrationUpdateTimestampFormat(ctx context.Context, db *gorm.DB) error {
 	}
 	return nil
 }
+
+// migrationAddNamespaceAndPayloadEncryptedColumns adds the namespace and payload_encrypted columns to the logs table
+func migrationAddNamespaceAndPayloadEncryptedColumns(ctx context.Context, db *gorm.DB) error {
+	opts := *migrator.DefaultOptions
+	opts.UseTransaction = true
+	m := migrator.New(db, &opts, []*migrator.Migration{{
+		ID: "logs_add_namespace_and_payload_encrypted_columns",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&Log{}, "namespace") {
+				if err := migrator.AddColumn(&Log{}, "namespace"); err != nil {
+					return err
+				}
+			}
+			if !migrator.HasIndex(&Log{}, "idx_logs_namespace") {
+				if err := migrator.CreateIndex(&Log{}, "idx_logs_namespace"); err != nil {
+					return err
+				}
+			}
+			if !migrator.HasColumn(&Log{}, "payload_encrypted") {
+				if err := migrator.AddColumn(&Log{}, "payload_encrypted"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropIndex(&Log{}, "idx_logs_namespace"); err != nil {
+				return err
+			}
+			if err := migrator.DropColumn(&Log{}, "namespace"); err != nil {
+				return err
+			}
+			if err := migrator.DropColumn(&Log{}, "payload_encrypted"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while adding namespace and payload_encrypted columns: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddIsTestKeyColumn adds the is_test_key column to the logs table
+func migrationAddIsTestKeyColumn(ctx context.Context, db *gorm.DB) error {
+	opts := *migrator.DefaultOptions
+	opts.UseTransaction = true
+	m := migrator.New(db, &opts, []*migrator.Migration{{
+		ID: "logs_add_is_test_key_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&Log{}, "is_test_key") {
+				if err := migrator.AddColumn(&Log{}, "is_test_key"); err != nil {
+					return err
+				}
+			}
+			if !migrator.HasIndex(&Log{}, "idx_logs_is_test_key") {
+				if err := migrator.CreateIndex(&Log{}, "idx_logs_is_test_key"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropIndex(&Log{}, "idx_logs_is_test_key"); err != nil {
+				return err
+			}
+			if err := migrator.DropColumn(&Log{}, "is_test_key"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while adding is_test_key column: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddProviderRequestColumn adds the provider_request column holding the captured provider requests
+func migrationAddProviderRequestColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "logs_add_provider_request_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&Log{}, "provider_request") {
+				if err := migrator.AddColumn(&Log{}, "provider_request"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&Log{}, "provider_request"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while adding provider_request column: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddEndUserAndPayloadPurgedColumns adds the end_user and payload_purged columns to the logs table
+func migrationAddEndUserAndPayloadPurgedColumns(ctx context.Context, db *gorm.DB) error {
+	opts := *migrator.DefaultOptions
+	opts.UseTransaction = true
+	m := migrator.New(db, &opts, []*migrator.Migration{{
+		ID: "logs_add_end_user_and_payload_purged_columns",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&Log{}, "end_user") {
+				if err := migrator.AddColumn(&Log{}, "end_user"); err != nil {
+					return err
+				}
+			}
+			if !migrator.HasIndex(&Log{}, "idx_logs_end_user") {
+				if err := migrator.CreateIndex(&Log{}, "idx_logs_end_user"); err != nil {
+					return err
+				}
+			}
+			if !migrator.HasColumn(&Log{}, "payload_purged") {
+				if err := migrator.AddColumn(&Log{}, "payload_purged"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropIndex(&Log{}, "idx_logs_end_user"); err != nil {
+				return err
+			}
+			if err := migrator.DropColumn(&Log{}, "end_user"); err != nil {
+				return err
+			}
+			if err := migrator.DropColumn(&Log{}, "payload_purged"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while adding end_user and payload_purged columns: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddTagsColumn adds the tags column holding the tags of the requests
+func migrationAddTagsColumn(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "logs_add_tags_column",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(&Log{}, "tags") {
+				if err := migrator.AddColumn(&Log{}, "tags"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropColumn(&Log{}, "tags"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while adding tags column: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddFeedbackTable adds the feedback table holding the ratings of the responses of requests
+func migrationAddFeedbackTable(ctx context.Context, db *gorm.DB) error {
+	m := migrator.New(db, migrator.DefaultOptions, []*migrator.Migration{{
+		ID: "logs_add_feedback_table",
+		Migrate: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if !migrator.HasTable(&Feedback{}) {
+				if err := migrator.CreateTable(&Feedback{}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			migrator := tx.Migrator()
+			if err := migrator.DropTable(&Feedback{}); err != nil {
+				return err
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while adding feedback table: %s", err.Error())
+	}
+	return nil
+}
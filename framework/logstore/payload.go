@@ -0,0 +1,134 @@
+package logstore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/maximhq/bifrost/framework/encrypt"
+)
+
+// PayloadColumns are the columns holding request and response content.
+// They are envelope encrypted for namespaces with a payload encryption key.
+var PayloadColumns = []string{
+	"input_history",
+	"responses_input_history",
+	"output_message",
+	"responses_output",
+	"embedding_output",
+	"params",
+	"tools",
+	"tool_calls",
+	"speech_input",
+	"transcription_input",
+	"speech_output",
+	"transcription_output",
+	"raw_response",
+	"provider_request",
+}
+
+// payloadFields returns the serialized fields backing PayloadColumns
+func (l *Log) payloadFields() []*string {
+	return []*string{
+		&l.InputHistory,
+		&l.ResponsesInputHistory,
+		&l.OutputMessage,
+		&l.ResponsesOutput,
+		&l.EmbeddingOutput,
+		&l.Params,
+		&l.Tools,
+		&l.ToolCalls,
+		&l.SpeechInput,
+		&l.TranscriptionInput,
+		&l.SpeechOutput,
+		&l.TranscriptionOutput,
+		&l.RawResponse,
+		&l.ProviderRequest,
+	}
+}
+
+// EncryptPayload serializes the log and envelope encrypts its payload columns with keyRef.
+// The content summary is dropped since it cannot be searched once encrypted, and the parsed
+// fields are cleared so that the GORM save hooks do not serialize the plaintext again.
+// If encryption fails the payload is dropped rather than stored in plaintext, and the error is returned.
+func (l *Log) EncryptPayload(ctx context.Context, keyRef string) error {
+	if err := l.SerializeFields(); err != nil {
+		return err
+	}
+	l.RedactPayload()
+	l.PayloadEncrypted = true
+	l.ContentSummary = ""
+	for _, field := range l.payloadFields() {
+		if *field == "" {
+			continue
+		}
+		encrypted, err := encrypt.EnvelopeEncrypt(ctx, keyRef, *field)
+		if err != nil {
+			for _, field := range l.payloadFields() {
+				*field = ""
+			}
+			return fmt.Errorf("failed to encrypt log payload: %w", err)
+		}
+		*field = encrypted
+	}
+	return nil
+}
+
+// EncryptPayloadUpdates envelope encrypts the payload columns of a log update map with keyRef.
+// Like EncryptPayload, it drops the content summary and, if encryption fails, the payload columns.
+func EncryptPayloadUpdates(ctx context.Context, keyRef string, updates map[string]interface{}) error {
+	if _, ok := updates["content_summary"]; ok {
+		updates["content_summary"] = ""
+	}
+	for _, column := range PayloadColumns {
+		value, ok := updates[column].(string)
+		if !ok || value == "" {
+			continue
+		}
+		encrypted, err := encrypt.EnvelopeEncrypt(ctx, keyRef, value)
+		if err != nil {
+			for _, column := range PayloadColumns {
+				delete(updates, column)
+			}
+			return fmt.Errorf("failed to encrypt log payload: %w", err)
+		}
+		updates[column] = encrypted
+	}
+	return nil
+}
+
+// DecryptPayload decrypts the payload columns of an encrypted log and populates the parsed fields.
+// It returns encrypt.ErrKeyRevoked if the namespace key was revoked, in which case the payload stays redacted.
+func (l *Log) DecryptPayload(ctx context.Context) error {
+	if !l.PayloadEncrypted {
+		return nil
+	}
+	for _, field := range l.payloadFields() {
+		decrypted, err := encrypt.EnvelopeDecrypt(ctx, *field)
+		if err != nil {
+			l.RedactPayload()
+			return err
+		}
+		*field = decrypted
+	}
+	return l.DeserializeFields()
+}
+
+// RedactPayload clears the parsed payload fields and the raw response, which is returned as is
+func (l *Log) RedactPayload() {
+	l.InputHistoryParsed = nil
+	l.ResponsesInputHistoryParsed = nil
+	l.OutputMessageParsed = nil
+	l.ResponsesOutputParsed = nil
+	l.EmbeddingOutputParsed = nil
+	l.ParamsParsed = nil
+	l.ToolsParsed = nil
+	l.ToolCallsParsed = nil
+	l.SpeechInputParsed = nil
+	l.TranscriptionInputParsed = nil
+	l.SpeechOutputParsed = nil
+	l.TranscriptionOutputParsed = nil
+	l.ProviderRequestParsed = nil
+	if l.PayloadEncrypted {
+		l.RawResponse = ""
+	}
+}
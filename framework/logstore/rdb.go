@@ -3,8 +3,10 @@ package logstore
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/maximhq/bifrost/core/schemas"
@@ -38,6 +40,23 @@ func (s *RDBLogStore) applyFilters(baseQuery *gorm.DB, filters SearchFilters) *g
 	if len(filters.VirtualKeyIDs) > 0 {
 		baseQuery = baseQuery.Where("virtual_key_id IN ?", filters.VirtualKeyIDs)
 	}
+	if len(filters.EndUsers) > 0 {
+		baseQuery = baseQuery.Where("end_user IN ?", filters.EndUsers)
+	}
+	if len(filters.Namespaces) > 0 {
+		baseQuery = baseQuery.Where("namespace IN ?", filters.Namespaces)
+	}
+	for name, value := range filters.Tags {
+		// Tags are stored as a JSON object with sorted keys, a tag is matched on its serialized pair
+		pair, err := json.Marshal(map[string]string{name: value})
+		if err != nil {
+			continue
+		}
+		baseQuery = baseQuery.Where("tags LIKE ?", "%"+strings.Trim(string(pair), "{}")+"%")
+	}
+	if filters.IsTestKey != nil {
+		baseQuery = baseQuery.Where("is_test_key = ?", *filters.IsTestKey)
+	}
 	if filters.StartTime != nil {
 		baseQuery = baseQuery.Where("timestamp >= ?", *filters.StartTime)
 	}
@@ -248,6 +267,100 @@ func (s *RDBLogStore) GetStats(ctx context.Context, filters SearchFilters) (*Sea
 	return stats, nil
 }
 
+// GetKeyModelUsage aggregates the completed requests of the logs matching the filters by provider key and model,
+// ordered by decreasing cost.
+func (s *RDBLogStore) GetKeyModelUsage(ctx context.Context, filters SearchFilters) ([]KeyModelUsage, error) {
+	var rows []struct {
+		SelectedKeyID    string
+		SelectedKeyName  string
+		Provider         string
+		Model            string
+		Requests         int64
+		Errors           int64
+		PromptTokens     sql.NullInt64
+		CompletionTokens sql.NullInt64
+		TotalTokens      sql.NullInt64
+		Cost             sql.NullFloat64
+	}
+	query := s.db.WithContext(ctx).Model(&Log{})
+	query = s.applyFilters(query, filters)
+	err := query.Where("status IN ?", []string{"success", "error"}).
+		Select("selected_key_id, MAX(selected_key_name) as selected_key_name, provider, model, " +
+			"COUNT(*) as requests, SUM(CASE WHEN status = 'error' THEN 1 ELSE 0 END) as errors, " +
+			"SUM(prompt_tokens) as prompt_tokens, SUM(completion_tokens) as completion_tokens, " +
+			"SUM(total_tokens) as total_tokens, SUM(cost) as cost").
+		Group("selected_key_id, provider, model").
+		Order("cost DESC, requests DESC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make([]KeyModelUsage, len(rows))
+	for i, row := range rows {
+		usage[i] = KeyModelUsage{
+			SelectedKeyID:    row.SelectedKeyID,
+			SelectedKeyName:  row.SelectedKeyName,
+			Provider:         row.Provider,
+			Model:            row.Model,
+			Requests:         row.Requests,
+			Errors:           row.Errors,
+			PromptTokens:     row.PromptTokens.Int64,
+			CompletionTokens: row.CompletionTokens.Int64,
+			TotalTokens:      row.TotalTokens.Int64,
+			Cost:             row.Cost.Float64,
+		}
+		if row.Requests > 0 {
+			usage[i].ErrorRate = float64(row.Errors) / float64(row.Requests) * 100
+		}
+	}
+	return usage, nil
+}
+
+// CreateFeedback stores the feedback of a request.
+func (s *RDBLogStore) CreateFeedback(ctx context.Context, feedback *Feedback) error {
+	return s.db.WithContext(ctx).Create(feedback).Error
+}
+
+// GetFeedbackSummaries aggregates the feedback of the requests of an experiment by variant, ordered by variant.
+func (s *RDBLogStore) GetFeedbackSummaries(ctx context.Context, experiment string) ([]FeedbackSummary, error) {
+	var rows []struct {
+		Variant      string
+		Feedback     int64
+		ThumbsUp     int64
+		ThumbsDown   int64
+		Scores       int64
+		AverageScore sql.NullFloat64
+	}
+	err := s.db.WithContext(ctx).Model(&Feedback{}).
+		Where("experiment = ?", experiment).
+		Select("variant, COUNT(*) as feedback, " +
+			"SUM(CASE WHEN rating > 0 THEN 1 ELSE 0 END) as thumbs_up, " +
+			"SUM(CASE WHEN rating < 0 THEN 1 ELSE 0 END) as thumbs_down, " +
+			"COUNT(score) as scores, AVG(score) as average_score").
+		Group("variant").
+		Order("variant").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]FeedbackSummary, len(rows))
+	for i, row := range rows {
+		summaries[i] = FeedbackSummary{
+			Variant:    row.Variant,
+			Feedback:   row.Feedback,
+			ThumbsUp:   row.ThumbsUp,
+			ThumbsDown: row.ThumbsDown,
+			Scores:     row.Scores,
+		}
+		if row.AverageScore.Valid {
+			summaries[i].AverageScore = &row.AverageScore.Float64
+		}
+	}
+	return summaries, nil
+}
+
 // HasLogs checks if there are any logs in the database.
 func (s *RDBLogStore) HasLogs(ctx context.Context) (bool, error) {
 	var log Log
@@ -320,6 +433,79 @@ func (s *RDBLogStore) DeleteLogsBatch(ctx context.Context, cutoff time.Time, bat
 	return result.RowsAffected, nil
 }
 
+// PurgePayloadsBatch clears the payload columns and content summary of logs older than the cutoff time in batches,
+// keeping the rest of the log with its usage and cost.
+func (s *RDBLogStore) PurgePayloadsBatch(ctx context.Context, cutoff time.Time, batchSize int) (purgedCount int64, err error) {
+	var ids []string
+	if err := s.db.WithContext(ctx).
+		Model(&Log{}).
+		Select("id").
+		Where("created_at < ? AND payload_purged = ?", cutoff, false).
+		Limit(batchSize).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	updates := map[string]interface{}{
+		"content_summary": "",
+		"payload_purged":  true,
+	}
+	for _, column := range PayloadColumns {
+		updates[column] = ""
+	}
+	result := s.db.WithContext(ctx).Model(&Log{}).Where("id IN ?", ids).Updates(updates)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// FindLogRowsBatch returns the rows of the oldest logs created before the cutoff time, with their columns as they are
+// stored: payloads are left serialized, and encrypted when they are.
+func (s *RDBLogStore) FindLogRowsBatch(ctx context.Context, cutoff time.Time, batchSize int) ([]map[string]interface{}, error) {
+	var rows []map[string]interface{}
+	if err := s.db.WithContext(ctx).
+		Model(&Log{}).
+		Where("created_at < ?", cutoff).
+		Order("created_at ASC, id ASC").
+		Limit(batchSize).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	// Some drivers scan text columns as bytes
+	for _, row := range rows {
+		for column, value := range row {
+			if b, ok := value.([]byte); ok {
+				row[column] = string(b)
+			}
+		}
+	}
+	return rows, nil
+}
+
+// DeleteLogsBySubject deletes the logs of a virtual key and/or an end user, for the deletion requests of their
+// data subjects. At least one of them must be set.
+func (s *RDBLogStore) DeleteLogsBySubject(ctx context.Context, virtualKeyID string, endUser string) (deletedCount int64, err error) {
+	if virtualKeyID == "" && endUser == "" {
+		return 0, fmt.Errorf("a virtual key or an end user is required to delete logs")
+	}
+	query := s.db.WithContext(ctx).Model(&Log{})
+	if virtualKeyID != "" {
+		query = query.Where("virtual_key_id = ?", virtualKeyID)
+	}
+	if endUser != "" {
+		query = query.Where("end_user = ?", endUser)
+	}
+	result := query.Delete(&Log{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
 // Close closes the log store.
 func (s *RDBLogStore) Close(ctx context.Context) error {
 	sqlDB, err := s.db.WithContext(ctx).DB()
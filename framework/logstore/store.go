@@ -27,12 +27,18 @@ type LogStore interface {
 	HasLogs(ctx context.Context) (bool, error)
 	SearchLogs(ctx context.Context, filters SearchFilters, pagination PaginationOptions) (*SearchResult, error)
 	GetStats(ctx context.Context, filters SearchFilters) (*SearchStats, error)
+	GetKeyModelUsage(ctx context.Context, filters SearchFilters) ([]KeyModelUsage, error)
 	Update(ctx context.Context, id string, entry any) error
 	Flush(ctx context.Context, since time.Time) error	
 	Close(ctx context.Context) error
 	DeleteLog(ctx context.Context, id string) error
 	DeleteLogs(ctx context.Context, ids []string) error
 	DeleteLogsBatch(ctx context.Context, cutoff time.Time, batchSize int) (deletedCount int64, err error)
+	PurgePayloadsBatch(ctx context.Context, cutoff time.Time, batchSize int) (purgedCount int64, err error)
+	FindLogRowsBatch(ctx context.Context, cutoff time.Time, batchSize int) ([]map[string]interface{}, error)
+	DeleteLogsBySubject(ctx context.Context, virtualKeyID string, endUser string) (deletedCount int64, err error)
+	CreateFeedback(ctx context.Context, feedback *Feedback) error
+	GetFeedbackSummaries(ctx context.Context, experiment string) ([]FeedbackSummary, error)
 }
 
 // NewLogStore creates a new log store based on the configuration.
@@ -28,21 +28,25 @@ const (
 
 // SearchFilters represents the available filters for log searches
 type SearchFilters struct {
-	Providers      []string   `json:"providers,omitempty"`
-	Models         []string   `json:"models,omitempty"`
-	Status         []string   `json:"status,omitempty"`
-	Objects        []string   `json:"objects,omitempty"` // For filtering by request type (chat.completion, text.completion, embedding)
-	SelectedKeyIDs []string   `json:"selected_key_ids,omitempty"`
-	VirtualKeyIDs  []string   `json:"virtual_key_ids,omitempty"`
-	StartTime      *time.Time `json:"start_time,omitempty"`
-	EndTime        *time.Time `json:"end_time,omitempty"`
-	MinLatency     *float64   `json:"min_latency,omitempty"`
-	MaxLatency     *float64   `json:"max_latency,omitempty"`
-	MinTokens      *int       `json:"min_tokens,omitempty"`
-	MaxTokens      *int       `json:"max_tokens,omitempty"`
-	MinCost        *float64   `json:"min_cost,omitempty"`
-	MaxCost        *float64   `json:"max_cost,omitempty"`
-	ContentSearch  string     `json:"content_search,omitempty"`
+	Providers      []string          `json:"providers,omitempty"`
+	Models         []string          `json:"models,omitempty"`
+	Status         []string          `json:"status,omitempty"`
+	Objects        []string          `json:"objects,omitempty"` // For filtering by request type (chat.completion, text.completion, embedding)
+	SelectedKeyIDs []string          `json:"selected_key_ids,omitempty"`
+	VirtualKeyIDs  []string          `json:"virtual_key_ids,omitempty"`
+	EndUsers       []string          `json:"end_users,omitempty"`
+	Tags           map[string]string `json:"tags,omitempty"` // Logs carrying all of the tags
+	Namespaces     []string          `json:"namespaces,omitempty"`
+	IsTestKey      *bool             `json:"is_test_key,omitempty"`
+	StartTime      *time.Time        `json:"start_time,omitempty"`
+	EndTime        *time.Time        `json:"end_time,omitempty"`
+	MinLatency     *float64          `json:"min_latency,omitempty"`
+	MaxLatency     *float64          `json:"max_latency,omitempty"`
+	MinTokens      *int              `json:"min_tokens,omitempty"`
+	MaxTokens      *int              `json:"max_tokens,omitempty"`
+	MinCost        *float64          `json:"min_cost,omitempty"`
+	MaxCost        *float64          `json:"max_cost,omitempty"`
+	ContentSearch  string            `json:"content_search,omitempty"`
 }
 
 // PaginationOptions represents pagination parameters
@@ -69,6 +73,57 @@ type SearchStats struct {
 	TotalCost      float64 `json:"total_cost"`      // Total cost in dollars
 }
 
+// KeyModelUsage is the usage of a provider key with a model, aggregated over the completed requests of the logs
+type KeyModelUsage struct {
+	SelectedKeyID    string  `json:"selected_key_id"`
+	SelectedKeyName  string  `json:"selected_key_name"`
+	Provider         string  `json:"provider"`
+	Model            string  `json:"model"`
+	Requests         int64   `json:"requests"`
+	Errors           int64   `json:"errors"`
+	ErrorRate        float64 `json:"error_rate"` // Percentage of the requests that failed
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	Cost             float64 `json:"cost"` // in dollars
+}
+
+// Feedback is the rating a client gave to the response of a request, with the experiment and variant the request was
+// assigned to
+type Feedback struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	RequestID  string    `gorm:"type:varchar(255);index;not null" json:"request_id"`
+	Experiment string    `gorm:"type:varchar(255);index" json:"experiment,omitempty"`
+	Variant    string    `gorm:"type:varchar(255)" json:"variant,omitempty"`
+	Rating     *int      `json:"rating,omitempty"` // 1 for thumbs up, -1 for thumbs down
+	Score      *float64  `json:"score,omitempty"`  // Score on the scale of the client, e.g. 1 to 5
+	Comment    string    `gorm:"type:text" json:"comment,omitempty"`
+	CreatedAt  time.Time `gorm:"index;not null" json:"created_at"`
+}
+
+// TableName sets the table name for GORM
+func (Feedback) TableName() string {
+	return "feedback"
+}
+
+// BeforeCreate GORM hook to set created_at
+func (f *Feedback) BeforeCreate(tx *gorm.DB) error {
+	if f.CreatedAt.IsZero() {
+		f.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// FeedbackSummary is the feedback of the requests of a variant of an experiment
+type FeedbackSummary struct {
+	Variant      string   `json:"variant"`
+	Feedback     int64    `json:"feedback"` // Number of feedback entries
+	ThumbsUp     int64    `json:"thumbs_up"`
+	ThumbsDown   int64    `json:"thumbs_down"`
+	Scores       int64    `json:"scores"`                  // Number of feedback entries with a score
+	AverageScore *float64 `json:"average_score,omitempty"` // Only set when the variant has scores
+}
+
 // Log represents a complete log entry for a request/response cycle
 // This is the GORM model with appropriate tags
 type Log struct {
@@ -84,19 +139,20 @@ type Log struct {
 	SelectedKeyName       string    `gorm:"type:varchar(255)" json:"selected_key_name"`
 	VirtualKeyID          *string   `gorm:"type:varchar(255);index:idx_logs_virtual_key_id" json:"virtual_key_id"`
 	VirtualKeyName        *string   `gorm:"type:varchar(255)" json:"virtual_key_name"`
-	InputHistory          string    `gorm:"type:text" json:"-"` // JSON serialized []schemas.ChatMessage
-	ResponsesInputHistory string    `gorm:"type:text" json:"-"` // JSON serialized []schemas.ResponsesMessage
-	OutputMessage         string    `gorm:"type:text" json:"-"` // JSON serialized *schemas.ChatMessage
-	ResponsesOutput       string    `gorm:"type:text" json:"-"` // JSON serialized *schemas.ResponsesMessage
-	EmbeddingOutput       string    `gorm:"type:text" json:"-"` // JSON serialized [][]float32
-	Params                string    `gorm:"type:text" json:"-"` // JSON serialized *schemas.ModelParameters
-	Tools                 string    `gorm:"type:text" json:"-"` // JSON serialized []schemas.Tool
-	ToolCalls             string    `gorm:"type:text" json:"-"` // JSON serialized []schemas.ToolCall (For backward compatibility, tool calls are now in the content)
-	SpeechInput           string    `gorm:"type:text" json:"-"` // JSON serialized *schemas.SpeechInput
-	TranscriptionInput    string    `gorm:"type:text" json:"-"` // JSON serialized *schemas.TranscriptionInput
-	SpeechOutput          string    `gorm:"type:text" json:"-"` // JSON serialized *schemas.BifrostSpeech
-	TranscriptionOutput   string    `gorm:"type:text" json:"-"` // JSON serialized *schemas.BifrostTranscribe
-	CacheDebug            string    `gorm:"type:text" json:"-"` // JSON serialized *schemas.BifrostCacheDebug
+	EndUser               *string   `gorm:"type:varchar(255);index:idx_logs_end_user" json:"end_user,omitempty"` // End user of the request, from the x-bf-user header or the user parameter
+	InputHistory          string    `gorm:"type:text" json:"-"`                                                  // JSON serialized []schemas.ChatMessage
+	ResponsesInputHistory string    `gorm:"type:text" json:"-"`                                                  // JSON serialized []schemas.ResponsesMessage
+	OutputMessage         string    `gorm:"type:text" json:"-"`                                                  // JSON serialized *schemas.ChatMessage
+	ResponsesOutput       string    `gorm:"type:text" json:"-"`                                                  // JSON serialized *schemas.ResponsesMessage
+	EmbeddingOutput       string    `gorm:"type:text" json:"-"`                                                  // JSON serialized [][]float32
+	Params                string    `gorm:"type:text" json:"-"`                                                  // JSON serialized *schemas.ModelParameters
+	Tools                 string    `gorm:"type:text" json:"-"`                                                  // JSON serialized []schemas.Tool
+	ToolCalls             string    `gorm:"type:text" json:"-"`                                                  // JSON serialized []schemas.ToolCall (For backward compatibility, tool calls are now in the content)
+	SpeechInput           string    `gorm:"type:text" json:"-"`                                                  // JSON serialized *schemas.SpeechInput
+	TranscriptionInput    string    `gorm:"type:text" json:"-"`                                                  // JSON serialized *schemas.TranscriptionInput
+	SpeechOutput          string    `gorm:"type:text" json:"-"`                                                  // JSON serialized *schemas.BifrostSpeech
+	TranscriptionOutput   string    `gorm:"type:text" json:"-"`                                                  // JSON serialized *schemas.BifrostTranscribe
+	CacheDebug            string    `gorm:"type:text" json:"-"`                                                  // JSON serialized *schemas.BifrostCacheDebug
 	Latency               *float64  `gorm:"index:idx_logs_latency" json:"latency,omitempty"`
 	TokenUsage            string    `gorm:"type:text" json:"-"`                            // JSON serialized *schemas.LLMUsage
 	Cost                  *float64  `gorm:"index" json:"cost,omitempty"`                   // Cost in dollars (total cost of the request - includes cache lookup cost)
@@ -105,6 +161,8 @@ type Log struct {
 	Stream                bool      `gorm:"default:false" json:"stream"`                   // true if this was a streaming response
 	ContentSummary        string    `gorm:"type:text" json:"-"`                            // For content search
 	RawResponse           string    `gorm:"type:text" json:"raw_response"`                 // Populated when `send-back-raw-response` is on
+	ProviderRequest       string    `gorm:"type:text" json:"-"`                            // JSON serialized *schemas.BifrostProviderRequest, populated when the request was captured
+	Tags                  string    `gorm:"type:text" json:"-"`                            // JSON serialized map[string]string, tags of the request in the allow-list and its experiment
 
 	// Denormalized token fields for easier querying
 	PromptTokens     int `gorm:"default:0" json:"-"`
@@ -113,6 +171,15 @@ type Log struct {
 
 	CreatedAt time.Time `gorm:"index;not null" json:"created_at"`
 
+	// Namespace of the virtual key the request was made with, and whether the payload columns
+	// are envelope encrypted with the namespace key (see EncryptPayload)
+	Namespace        string `gorm:"type:varchar(255);index:idx_logs_namespace" json:"namespace,omitempty"`
+	PayloadEncrypted bool   `gorm:"default:false" json:"payload_encrypted"`
+	PayloadPurged    bool   `gorm:"default:false" json:"payload_purged"` // Set once the payload was cleared past its retention
+
+	// Whether the request was served with a test key, to tell sandbox traffic apart in analytics
+	IsTestKey bool `gorm:"index:idx_logs_is_test_key;default:false" json:"is_test_key"`
+
 	// Virtual fields for JSON output - these will be populated when needed
 	InputHistoryParsed          []schemas.ChatMessage                  `gorm:"-" json:"input_history,omitempty"`
 	ResponsesInputHistoryParsed []schemas.ResponsesMessage             `gorm:"-" json:"responses_input_history,omitempty"`
@@ -129,6 +196,8 @@ type Log struct {
 	SpeechOutputParsed          *schemas.BifrostSpeechResponse         `gorm:"-" json:"speech_output,omitempty"`
 	TranscriptionOutputParsed   *schemas.BifrostTranscriptionResponse  `gorm:"-" json:"transcription_output,omitempty"`
 	CacheDebugParsed            *schemas.BifrostCacheDebug             `gorm:"-" json:"cache_debug,omitempty"`
+	ProviderRequestParsed       *schemas.BifrostProviderRequest        `gorm:"-" json:"provider_request,omitempty"`
+	TagsParsed                  map[string]string                      `gorm:"-" json:"tags,omitempty"`
 
 	// Populated in handlers after find using the virtual key id and key id
 	VirtualKey  *tables.TableVirtualKey `gorm:"-" json:"virtual_key,omitempty"`  // redacted
@@ -284,6 +353,22 @@ func (l *Log) SerializeFields() error {
 		}
 	}
 
+	if l.ProviderRequestParsed != nil {
+		if data, err := json.Marshal(l.ProviderRequestParsed); err != nil {
+			return err
+		} else {
+			l.ProviderRequest = string(data)
+		}
+	}
+
+	if l.TagsParsed != nil {
+		if data, err := json.Marshal(l.TagsParsed); err != nil {
+			return err
+		} else {
+			l.Tags = string(data)
+		}
+	}
+
 	// Build content summary for search
 	l.ContentSummary = l.BuildContentSummary()
 
@@ -398,6 +483,20 @@ func (l *Log) DeserializeFields() error {
 		}
 	}
 
+	if l.ProviderRequest != "" {
+		if err := json.Unmarshal([]byte(l.ProviderRequest), &l.ProviderRequestParsed); err != nil {
+			// Log error but don't fail the operation - initialize as nil
+			l.ProviderRequestParsed = nil
+		}
+	}
+
+	if l.Tags != "" {
+		if err := json.Unmarshal([]byte(l.Tags), &l.TagsParsed); err != nil {
+			// Log error but don't fail the operation - initialize as nil
+			l.TagsParsed = nil
+		}
+	}
+
 	return nil
 }
 
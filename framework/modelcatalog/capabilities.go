@@ -0,0 +1,97 @@
+package modelcatalog
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+)
+
+// bundledCapabilitiesData is the bundled dataset of model capabilities, keyed by model family prefixes
+//
+//go:embed capabilities.json
+var bundledCapabilitiesData []byte
+
+// bundledModelCapabilities are the capabilities of the models whose names start with Model
+type bundledModelCapabilities struct {
+	Model string `json:"model"`
+	schemas.ModelCapabilities
+}
+
+// loadModelCapabilities loads the bundled dataset and the overrides of the config store
+func (mc *ModelCatalog) loadModelCapabilities(ctx context.Context) error {
+	var bundled []bundledModelCapabilities
+	if err := json.Unmarshal(bundledCapabilitiesData, &bundled); err != nil {
+		return fmt.Errorf("failed to parse bundled model capabilities: %w", err)
+	}
+	mc.capabilitiesMu.Lock()
+	mc.bundledCapabilities = bundled
+	mc.capabilitiesMu.Unlock()
+	return mc.ReloadModelCapabilities(ctx)
+}
+
+// ReloadModelCapabilities reloads the capability overrides from the config store
+func (mc *ModelCatalog) ReloadModelCapabilities(ctx context.Context) error {
+	overrides := make(map[string]configstoreTables.TableModelCapability)
+	if mc.configStore != nil {
+		rows, err := mc.configStore.GetModelCapabilityOverrides(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load model capability overrides: %w", err)
+		}
+		for _, row := range rows {
+			overrides[row.Provider+"/"+row.Model] = row
+		}
+	}
+	mc.capabilitiesMu.Lock()
+	mc.capabilityOverrides = overrides
+	mc.capabilitiesMu.Unlock()
+	return nil
+}
+
+// GetModelCapabilities returns the capabilities of the model of the provider, nil when the model is unknown (thread-safe).
+// The bundled entry with the longest prefix of the model name is used, then overrides of the model on every provider
+// ("*") and on the provider are applied. Overrides of models missing from the bundled dataset describe them entirely,
+// their unset capabilities are unsupported.
+func (mc *ModelCatalog) GetModelCapabilities(provider schemas.ModelProvider, model string) *schemas.ModelCapabilities {
+	mc.capabilitiesMu.RLock()
+	defer mc.capabilitiesMu.RUnlock()
+
+	var capabilities *schemas.ModelCapabilities
+	name := normalizeCapabilityModel(model)
+	matched := ""
+	for _, entry := range mc.bundledCapabilities {
+		if strings.HasPrefix(name, entry.Model) && len(entry.Model) > len(matched) {
+			matched = entry.Model
+			entryCapabilities := entry.ModelCapabilities
+			capabilities = &entryCapabilities
+		}
+	}
+	for _, key := range []string{"*/" + model, string(provider) + "/" + model} {
+		if override, ok := mc.capabilityOverrides[key]; ok {
+			if capabilities == nil {
+				capabilities = &schemas.ModelCapabilities{}
+			}
+			override.ApplyTo(capabilities)
+		}
+	}
+	return capabilities
+}
+
+// normalizeCapabilityModel strips the provider and vendor prefixes of a model name,
+// e.g. "us.anthropic.claude-3-5-sonnet" becomes "claude-3-5-sonnet"
+func normalizeCapabilityModel(model string) string {
+	name := strings.ToLower(model)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	for _, vendor := range []string{"anthropic.", "meta.", "mistral.", "cohere."} {
+		if i := strings.Index(name, vendor); i >= 0 {
+			name = name[i+len(vendor):]
+		}
+	}
+	return name
+}
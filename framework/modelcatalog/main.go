@@ -7,11 +7,13 @@ import (
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/maximhq/bifrost/core/schemas"
 	"github.com/maximhq/bifrost/framework/configstore"
 	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+	"github.com/maximhq/bifrost/framework/leader"
 )
 
 // Default sync interval and config key
@@ -43,7 +45,17 @@ type ModelCatalog struct {
 
 	modelPool map[schemas.ModelProvider][]string
 
-	// Background sync worker
+	// Capabilities of models: the bundled dataset and the overrides of the config store keyed by "provider/model"
+	bundledCapabilities []bundledModelCapabilities
+	capabilityOverrides map[string]configstoreTables.TableModelCapability
+	capabilitiesMu      sync.RWMutex
+
+	// Token price overrides of the config store keyed by "provider/model"
+	pricingOverrides   map[string]configstoreTables.TableModelPricingOverride
+	pricingOverridesMu sync.RWMutex
+
+	// Background sync worker, the pricing is synced by the leader replica and reloaded from the database by the others
+	elector    atomic.Pointer[leader.Elector]
 	syncTicker *time.Ticker
 	done       chan struct{}
 	wg         sync.WaitGroup
@@ -121,6 +133,16 @@ func Init(ctx context.Context, config *Config, configStore configstore.ConfigSto
 	// Populate model pool with normalized providers from pricing data
 	mc.populateModelPoolFromPricingData()
 
+	// Load the bundled model capabilities and their overrides
+	if err := mc.loadModelCapabilities(ctx); err != nil {
+		return nil, err
+	}
+
+	// Load the pricing overrides
+	if err := mc.ReloadPricingOverrides(ctx); err != nil {
+		return nil, err
+	}
+
 	// Start background sync worker
 	mc.syncCtx, mc.syncCancel = context.WithCancel(ctx)
 	mc.startSyncWorker(mc.syncCtx)
@@ -130,6 +152,12 @@ func Init(ctx context.Context, config *Config, configStore configstore.ConfigSto
 	return mc, nil
 }
 
+// SetElector sets the elector of the replica syncing the pricing, the other replicas reload the pricing it synced
+// from the database. Without an elector every replica syncs the pricing.
+func (mc *ModelCatalog) SetElector(elector *leader.Elector) {
+	mc.elector.Store(elector)
+}
+
 // ReloadPricing reloads the pricing manager from config
 func (mc *ModelCatalog) ReloadPricing(ctx context.Context, config *Config) error {
 	// Acquire pricing mutex to update configuration atomically
@@ -197,9 +225,15 @@ func (mc *ModelCatalog) GetPricingEntryForModel(model string, provider schemas.M
 		key := makeKey(model, string(provider), normalizeRequestType(mode))
 		pricing, ok := mc.pricingData[key]
 		if ok {
+			mc.applyPricingOverrides(&pricing, model, string(provider))
 			return convertTableModelPricingToPricingData(&pricing)
 		}
 	}
+	// Models priced by overrides only
+	pricing := configstoreTables.TableModelPricing{Model: model, Provider: string(provider), Mode: normalizeRequestType(schemas.ChatCompletionRequest)}
+	if mc.applyPricingOverrides(&pricing, model, string(provider)) {
+		return convertTableModelPricingToPricingData(&pricing)
+	}
 	return nil
 }
 
@@ -0,0 +1,42 @@
+package modelcatalog
+
+import (
+	"context"
+	"fmt"
+
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+)
+
+// ReloadPricingOverrides reloads the token price overrides from the config store
+func (mc *ModelCatalog) ReloadPricingOverrides(ctx context.Context) error {
+	overrides := make(map[string]configstoreTables.TableModelPricingOverride)
+	if mc.configStore != nil {
+		rows, err := mc.configStore.GetModelPricingOverrides(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load model pricing overrides: %w", err)
+		}
+		for _, row := range rows {
+			overrides[row.Provider+"/"+row.Model] = row
+		}
+	}
+	mc.pricingOverridesMu.Lock()
+	mc.pricingOverrides = overrides
+	mc.pricingOverridesMu.Unlock()
+	return nil
+}
+
+// applyPricingOverrides applies the overrides of the model on every provider ("*") and then on the provider to the
+// pricing, and reports whether any override was applied (thread-safe)
+func (mc *ModelCatalog) applyPricingOverrides(pricing *configstoreTables.TableModelPricing, model, provider string) bool {
+	mc.pricingOverridesMu.RLock()
+	defer mc.pricingOverridesMu.RUnlock()
+
+	applied := false
+	for _, key := range []string{"*/" + model, provider + "/" + model} {
+		if override, ok := mc.pricingOverrides[key]; ok {
+			override.ApplyTo(pricing)
+			applied = true
+		}
+	}
+	return applied
+}
@@ -274,8 +274,24 @@ func (mc *ModelCatalog) CalculateCostFromUsage(provider string, model string, de
 	return totalCost
 }
 
-// getPricing returns pricing information for a model (thread-safe)
+// getPricing returns pricing information for a model with the pricing overrides applied (thread-safe).
+// Models missing from the pricing data are priced by their overrides when they have any.
 func (mc *ModelCatalog) getPricing(model, provider string, requestType schemas.RequestType) (*configstoreTables.TableModelPricing, bool) {
+	pricing, ok := mc.lookupPricing(model, provider, requestType)
+	if !ok {
+		pricing = &configstoreTables.TableModelPricing{Model: model, Provider: provider, Mode: normalizeRequestType(requestType)}
+	}
+	if mc.applyPricingOverrides(pricing, model, provider) {
+		return pricing, true
+	}
+	if !ok {
+		return nil, false
+	}
+	return pricing, true
+}
+
+// lookupPricing returns a copy of the pricing information of the pricing data for a model (thread-safe)
+func (mc *ModelCatalog) lookupPricing(model, provider string, requestType schemas.RequestType) (*configstoreTables.TableModelPricing, bool) {
 	mc.mu.RLock()
 	defer mc.mu.RUnlock()
 
@@ -2,6 +2,7 @@ package modelcatalog
 
 import (
 	"context"
+	_ "embed"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,6 +13,11 @@ import (
 	"gorm.io/gorm"
 )
 
+// bundledPricingData is the pricing dataset bundled with bifrost, in the format of the pricing URL
+//
+//go:embed pricing.json
+var bundledPricingData []byte
+
 // checkAndSyncPricing determines if pricing data needs to be synced and performs the sync if needed.
 // It syncs pricing data in the following scenarios:
 //   - No config store available (returns early with no error)
@@ -59,6 +65,7 @@ func (mc *ModelCatalog) syncPricing(ctx context.Context) error {
 	mc.logger.Debug("starting pricing data synchronization for governance")
 
 	// Load pricing data from URL
+	bundled := false
 	pricingData, err := mc.loadPricingFromURL(ctx)
 	if err != nil {
 		// Check if we have existing data in database
@@ -69,9 +76,14 @@ func (mc *ModelCatalog) syncPricing(ctx context.Context) error {
 		if len(pricingRecords) > 0 {
 			mc.logger.Error("failed to load pricing data from URL, but existing data found in database: %v", err)
 			return nil
-		} else {
-			return fmt.Errorf("failed to load pricing data from URL and no existing data in database: %w", err)
 		}
+		// Fall back to the bundled dataset, the URL is tried again on the next sync
+		mc.logger.Warn("failed to load pricing data from URL and no existing data in database, using the bundled pricing dataset: %v", err)
+		pricingData, err = loadBundledPricing()
+		if err != nil {
+			return err
+		}
+		bundled = true
 	}
 
 	// Update database in transaction
@@ -112,14 +124,15 @@ func (mc *ModelCatalog) syncPricing(ctx context.Context) error {
 		return fmt.Errorf("failed to sync pricing data to database: %w", err)
 	}
 
-	config := &configstoreTables.TableGovernanceConfig{
-		Key:   ConfigLastPricingSyncKey,
-		Value: time.Now().Format(time.RFC3339),
-	}
-
-	// Update last sync time
-	if err := mc.configStore.UpdateConfig(ctx, config); err != nil {
-		mc.logger.Warn("Failed to update last sync time: %v", err)
+	// Update last sync time, unless the bundled dataset was used
+	if !bundled {
+		config := &configstoreTables.TableGovernanceConfig{
+			Key:   ConfigLastPricingSyncKey,
+			Value: time.Now().Format(time.RFC3339),
+		}
+		if err := mc.configStore.UpdateConfig(ctx, config); err != nil {
+			mc.logger.Warn("Failed to update last sync time: %v", err)
+		}
 	}
 
 	// Reload cache from database
@@ -169,11 +182,24 @@ func (mc *ModelCatalog) loadPricingFromURL(ctx context.Context) (map[string]Pric
 	return pricingData, nil
 }
 
-// loadPricingIntoMemory loads pricing data from URL into memory cache
+// loadBundledPricing loads the pricing dataset bundled with bifrost
+func loadBundledPricing() (map[string]PricingEntry, error) {
+	var pricingData map[string]PricingEntry
+	if err := json.Unmarshal(bundledPricingData, &pricingData); err != nil {
+		return nil, fmt.Errorf("failed to parse bundled pricing data: %w", err)
+	}
+	return pricingData, nil
+}
+
+// loadPricingIntoMemory loads pricing data from URL into memory cache, or the bundled dataset when the URL cannot be reached
 func (mc *ModelCatalog) loadPricingIntoMemory(ctx context.Context) error {
 	pricingData, err := mc.loadPricingFromURL(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to load pricing data from URL: %w", err)
+		mc.logger.Warn("failed to load pricing data from URL, using the bundled pricing dataset: %v", err)
+		pricingData, err = loadBundledPricing()
+		if err != nil {
+			return err
+		}
 	}
 
 	mc.mu.Lock()
@@ -233,6 +259,15 @@ func (mc *ModelCatalog) syncWorker(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-mc.syncTicker.C:
+			// Only the leader syncs, the other replicas pick up the pricing it synced
+			if !mc.elector.Load().IsLeader() {
+				if mc.configStore != nil {
+					if err := mc.loadPricingFromDatabase(ctx); err != nil {
+						mc.logger.Error("background pricing reload failed: %v", err)
+					}
+				}
+				continue
+			}
 			// Check and sync pricing data - this handles the sync internally
 			if err := mc.checkAndSyncPricing(ctx); err != nil {
 				mc.logger.Error("background pricing sync failed: %v", err)
@@ -41,6 +41,23 @@ func (a *Accumulator) buildCompleteMessageFromChatStreamChunks(chunks []*ChatStr
 				*completeMessage.ChatAssistantMessage.Refusal += *chunk.Delta.Refusal
 			}
 		}
+		// Accumulate reasoning
+		if chunk.Delta.Thought != nil && *chunk.Delta.Thought != "" {
+			if completeMessage.ChatAssistantMessage == nil {
+				completeMessage.ChatAssistantMessage = &schemas.ChatAssistantMessage{}
+			}
+			if completeMessage.ChatAssistantMessage.Thought == nil {
+				completeMessage.ChatAssistantMessage.Thought = schemas.Ptr(*chunk.Delta.Thought)
+			} else {
+				*completeMessage.ChatAssistantMessage.Thought += *chunk.Delta.Thought
+			}
+		}
+		if chunk.Delta.ThoughtSignature != nil {
+			if completeMessage.ChatAssistantMessage == nil {
+				completeMessage.ChatAssistantMessage = &schemas.ChatAssistantMessage{}
+			}
+			completeMessage.ChatAssistantMessage.ThoughtSignature = chunk.Delta.ThoughtSignature
+		}
 		// Accumulate tool calls
 		if len(chunk.Delta.ToolCalls) > 0 {
 			a.accumulateToolCallsInMessage(completeMessage, chunk.Delta.ToolCalls)
@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/envutils"
 )
 
 type VectorStoreType string
@@ -162,6 +163,19 @@ func NewVectorStore(ctx context.Context, config *Config, logger schemas.Logger)
 		if !ok {
 			return nil, fmt.Errorf("invalid weaviate config")
 		}
+		if err := resolveCredentials(&weaviateConfig.APIKey); err != nil {
+			return nil, err
+		}
+		if len(weaviateConfig.Headers) > 0 {
+			headers := make(map[string]string, len(weaviateConfig.Headers))
+			for name, value := range weaviateConfig.Headers {
+				if err := resolveCredentials(&value); err != nil {
+					return nil, err
+				}
+				headers[name] = value
+			}
+			weaviateConfig.Headers = headers
+		}
 		return newWeaviateStore(ctx, &weaviateConfig, logger)
 	case VectorStoreTypeRedis:
 		if config.Config == nil {
@@ -171,6 +185,9 @@ func NewVectorStore(ctx context.Context, config *Config, logger schemas.Logger)
 		if !ok {
 			return nil, fmt.Errorf("invalid redis config")
 		}
+		if err := resolveCredentials(&redisConfig.Username, &redisConfig.Password); err != nil {
+			return nil, err
+		}
 		return newRedisStore(ctx, redisConfig, logger)
 	case VectorStoreTypeQdrant:
 		if config.Config == nil {
@@ -180,7 +197,23 @@ func NewVectorStore(ctx context.Context, config *Config, logger schemas.Logger)
 		if !ok {
 			return nil, fmt.Errorf("invalid qdrant config")
 		}
+		if err := resolveCredentials(&qdrantConfig.APIKey); err != nil {
+			return nil, err
+		}
 		return newQdrantStore(ctx, &qdrantConfig, logger)
 	}
 	return nil, fmt.Errorf("invalid vector store type: %s", config.Type)
 }
+
+// resolveCredentials resolves the env.VAR_NAME, ${env:VAR_NAME} and ${file:/path} references of credentials when the
+// store connects, so that the saved config keeps the references instead of the secrets
+func resolveCredentials(values ...*string) error {
+	for _, value := range values {
+		resolved, err := envutils.ProcessEnvValue(*value)
+		if err != nil {
+			return fmt.Errorf("failed to resolve vector store credentials: %w", err)
+		}
+		*value = resolved
+	}
+	return nil
+}
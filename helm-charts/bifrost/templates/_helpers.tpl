@@ -378,5 +378,16 @@ false
 {{- if $plugins }}
 {{- $_ := set $config "plugins" $plugins }}
 {{- end }}
+{{- /* Kubernetes Operator */ -}}
+{{- if .Values.bifrost.kubernetesOperator.enabled }}
+{{- $operatorConfig := dict "enabled" true }}
+{{- if .Values.bifrost.kubernetesOperator.namespace }}
+{{- $_ := set $operatorConfig "namespace" .Values.bifrost.kubernetesOperator.namespace }}
+{{- end }}
+{{- if .Values.bifrost.kubernetesOperator.resyncInterval }}
+{{- $_ := set $operatorConfig "resync_interval" .Values.bifrost.kubernetesOperator.resyncInterval }}
+{{- end }}
+{{- $_ := set $config "kubernetes_operator" $operatorConfig }}
+{{- end }}
 {{- $config | toJson }}
 {{- end }}
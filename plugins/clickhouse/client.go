@@ -0,0 +1,168 @@
+package clickhouse
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// column is a column of the events table
+type column struct {
+	name     string
+	dataType string
+}
+
+// columns are the columns of the events table, named after the JSON fields of Event. Columns added later are added
+// to the existing tables by EnsureSchema, with the default value of their type for the events inserted before.
+var columns = []column{
+	{"request_id", "String"},
+	{"timestamp", "DateTime64(3, 'UTC')"},
+	{"provider", "LowCardinality(String)"},
+	{"model", "LowCardinality(String)"},
+	{"request_type", "LowCardinality(String)"},
+	{"status", "LowCardinality(String)"},
+	{"stream", "Bool"},
+	{"latency_ms", "Float64"},
+	{"number_of_retries", "UInt16"},
+	{"fallback_index", "UInt16"},
+	{"virtual_key_id", "String"},
+	{"virtual_key_name", "String"},
+	{"selected_key_id", "String"},
+	{"selected_key_name", "String"},
+	{"team_id", "String"},
+	{"team_name", "String"},
+	{"customer_id", "String"},
+	{"customer_name", "String"},
+	{"end_user", "String"},
+	{"is_test_key", "Bool"},
+	{"input_tokens", "UInt64"},
+	{"output_tokens", "UInt64"},
+	{"total_tokens", "UInt64"},
+	{"cost", "Float64"},
+	{"cache_hit", "Bool"},
+	{"error_status_code", "UInt16"},
+	{"error_code", "LowCardinality(String)"},
+	{"error_message", "String"},
+	{"tags", "Map(String, String)"},
+}
+
+// Client sends queries to the HTTP interface of ClickHouse
+type Client struct {
+	url        string
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+// NewClient creates a client of the HTTP interface at url, authenticated when username is set
+func NewClient(url string, username string, password string) *Client {
+	return &Client{
+		url:        url,
+		username:   username,
+		password:   password,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// EnsureSchema creates the database and the events table when they do not exist, and adds the columns missing from
+// a table created by an older version. The table is partitioned by month and ordered by provider, model and time,
+// the usual filters of the dashboards. ttlDays sets the TTL of the created table, 0 keeps the events.
+func (c *Client) EnsureSchema(ctx context.Context, database string, table string, ttlDays int) error {
+	if err := c.exec(ctx, fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", quoteIdentifier(database))); err != nil {
+		return err
+	}
+	definitions := make([]string, 0, len(columns))
+	for _, col := range columns {
+		definitions = append(definitions, fmt.Sprintf("%s %s", quoteIdentifier(col.name), col.dataType))
+	}
+	create := fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (%s) ENGINE = MergeTree PARTITION BY toYYYYMM(timestamp) ORDER BY (provider, model, timestamp)",
+		tableName(database, table), strings.Join(definitions, ", "),
+	)
+	if ttlDays > 0 {
+		create += fmt.Sprintf(" TTL toDateTime(timestamp) + INTERVAL %d DAY", ttlDays)
+	}
+	if err := c.exec(ctx, create); err != nil {
+		return err
+	}
+	for _, col := range columns {
+		if err := c.exec(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s", tableName(database, table), quoteIdentifier(col.name), col.dataType)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Insert inserts the events in the table in a single gzipped JSONEachRow insert. With asyncInsert, ClickHouse
+// buffers the insert with the inserts of the other clients instead of writing a part per insert.
+func (c *Client) Insert(ctx context.Context, database string, table string, events []*Event, asyncInsert bool) error {
+	var body bytes.Buffer
+	writer := gzip.NewWriter(&body)
+	encoder := json.NewEncoder(writer)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return fmt.Errorf("failed to encode event %s: %v", event.RequestID, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	params := url.Values{}
+	params.Set("query", fmt.Sprintf("INSERT INTO %s FORMAT JSONEachRow", tableName(database, table)))
+	if asyncInsert {
+		params.Set("async_insert", "1")
+		params.Set("wait_for_async_insert", "0")
+	}
+	return c.send(ctx, params, &body, "gzip")
+}
+
+// exec runs a statement without result
+func (c *Client) exec(ctx context.Context, query string) error {
+	return c.send(ctx, url.Values{}, strings.NewReader(query), "")
+}
+
+// send posts a body with the params of the query, and returns the error of ClickHouse when it is not accepted
+func (c *Client) send(ctx context.Context, params url.Values, body io.Reader, contentEncoding string) error {
+	endpoint := c.url + "/"
+	if encoded := params.Encode(); encoded != "" {
+		endpoint += "?" + encoded
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return err
+	}
+	if contentEncoding != "" {
+		req.Header.Set("Content-Encoding", contentEncoding)
+	}
+	if c.username != "" {
+		req.Header.Set("X-ClickHouse-User", c.username)
+		req.Header.Set("X-ClickHouse-Key", c.password)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("clickhouse returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// tableName returns the quoted name of a table of a database
+func tableName(database string, table string) string {
+	return quoteIdentifier(database) + "." + quoteIdentifier(table)
+}
+
+// quoteIdentifier quotes a database, table or column name with backticks
+func quoteIdentifier(name string) string {
+	return "`" + strings.NewReplacer("\\", "\\\\", "`", "\\`").Replace(name) + "`"
+}
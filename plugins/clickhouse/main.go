@@ -0,0 +1,390 @@
+// Package clickhouse exports the request and usage events of Bifrost to ClickHouse, in batched inserts over its HTTP
+// interface, for real-time analytics on high-volume deployments.
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/modelcatalog"
+)
+
+const (
+	PluginName = "clickhouse"
+)
+
+const (
+	startTimeKey schemas.BifrostContextKey = "bf-clickhouse-start-time"
+)
+
+// Defaults of the config
+const (
+	DefaultDatabase             = "default"
+	DefaultTable                = "bifrost_requests"
+	DefaultBatchSize            = 1000
+	DefaultFlushIntervalSeconds = 5
+	DefaultBufferSize           = 100000
+)
+
+// Config is the config of the ClickHouse plugin
+type Config struct {
+	URL                  string `json:"url"`                              // HTTP interface of ClickHouse, e.g. http://clickhouse:8123
+	Database             string `json:"database,omitempty"`               // Defaults to default
+	Table                string `json:"table,omitempty"`                  // Defaults to bifrost_requests
+	Username             string `json:"username,omitempty"`               // Supports env.VAR_NAME
+	Password             string `json:"password,omitempty"`               // Supports env.VAR_NAME
+	BatchSize            int    `json:"batch_size,omitempty"`             // Events inserted at once, defaults to 1000
+	FlushIntervalSeconds int    `json:"flush_interval_seconds,omitempty"` // Longest wait before the events are inserted, defaults to 5 seconds
+	BufferSize           int    `json:"buffer_size,omitempty"`            // Events waiting for their insert, defaults to 100000. Events are dropped once it is full
+	AsyncInsert          bool   `json:"async_insert,omitempty"`           // Use the asynchronous inserts of ClickHouse, batching the inserts of all replicas server side
+	ManageSchema         *bool  `json:"manage_schema,omitempty"`          // Create the database and the table, and add the missing columns. Defaults to true
+	TTLDays              int    `json:"ttl_days,omitempty"`               // Days the events are kept by ClickHouse when the table is created, 0 keeps them
+}
+
+// Event is a request as it is inserted in ClickHouse, a row of the table
+type Event struct {
+	RequestID       string            `json:"request_id"`
+	Timestamp       string            `json:"timestamp"` // UTC time the request started, in the DateTime64 format of ClickHouse
+	Provider        string            `json:"provider"`
+	Model           string            `json:"model"`
+	RequestType     string            `json:"request_type"`
+	Status          string            `json:"status"` // "success" or "error"
+	Stream          bool              `json:"stream"`
+	LatencyMs       float64           `json:"latency_ms"`
+	NumberOfRetries int               `json:"number_of_retries"`
+	FallbackIndex   int               `json:"fallback_index"`
+	VirtualKeyID    string            `json:"virtual_key_id"`
+	VirtualKeyName  string            `json:"virtual_key_name"`
+	SelectedKeyID   string            `json:"selected_key_id"`
+	SelectedKeyName string            `json:"selected_key_name"`
+	TeamID          string            `json:"team_id"`
+	TeamName        string            `json:"team_name"`
+	CustomerID      string            `json:"customer_id"`
+	CustomerName    string            `json:"customer_name"`
+	EndUser         string            `json:"end_user"`
+	IsTestKey       bool              `json:"is_test_key"`
+	InputTokens     int               `json:"input_tokens"`
+	OutputTokens    int               `json:"output_tokens"`
+	TotalTokens     int               `json:"total_tokens"`
+	Cost            float64           `json:"cost"`
+	CacheHit        bool              `json:"cache_hit"`
+	ErrorStatusCode int               `json:"error_status_code"`
+	ErrorCode       string            `json:"error_code"`
+	ErrorMessage    string            `json:"error_message"`
+	Tags            map[string]string `json:"tags"`
+}
+
+// ClickHousePlugin exports the events of the requests to ClickHouse. Events are queued by the hooks and inserted in
+// batches by a background routine, so that requests never wait for ClickHouse.
+type ClickHousePlugin struct {
+	config         Config
+	client         *Client
+	pricingManager *modelcatalog.ModelCatalog
+	logger         schemas.Logger
+
+	events  chan *Event
+	pending sync.WaitGroup // Events being completed with their usage and cost before they are queued
+	dropped atomic.Int64   // Events dropped since the last warning, because the buffer was full
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Init creates the ClickHouse plugin, manages the schema of its table and starts inserting the events
+func Init(ctx context.Context, config *Config, logger schemas.Logger, pricingManager *modelcatalog.ModelCatalog) (*ClickHousePlugin, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config is required")
+	}
+	resolved, err := resolveConfig(*config)
+	if err != nil {
+		return nil, err
+	}
+	if pricingManager == nil {
+		logger.Warn("clickhouse plugin requires model catalog to calculate cost, all cost calculations will be skipped.")
+	}
+	client := NewClient(resolved.URL, resolved.Username, resolved.Password)
+	if *resolved.ManageSchema {
+		schemaCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		if err := client.EnsureSchema(schemaCtx, resolved.Database, resolved.Table, resolved.TTLDays); err != nil {
+			return nil, fmt.Errorf("failed to manage the clickhouse schema: %v", err)
+		}
+	}
+	p := &ClickHousePlugin{
+		config:         resolved,
+		client:         client,
+		pricingManager: pricingManager,
+		logger:         logger,
+		events:         make(chan *Event, resolved.BufferSize),
+	}
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	p.wg.Add(1)
+	go p.flushLoop()
+	return p, nil
+}
+
+// resolveConfig validates the config, sets its defaults and reads its credentials from the environment
+func resolveConfig(config Config) (Config, error) {
+	if config.URL == "" {
+		return config, fmt.Errorf("url is required")
+	}
+	if config.BatchSize < 0 || config.FlushIntervalSeconds < 0 || config.BufferSize < 0 || config.TTLDays < 0 {
+		return config, fmt.Errorf("batch_size, flush_interval_seconds, buffer_size and ttl_days cannot be negative")
+	}
+	config.URL = strings.TrimRight(config.URL, "/")
+	if config.Database == "" {
+		config.Database = DefaultDatabase
+	}
+	if config.Table == "" {
+		config.Table = DefaultTable
+	}
+	if config.BatchSize == 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	if config.FlushIntervalSeconds == 0 {
+		config.FlushIntervalSeconds = DefaultFlushIntervalSeconds
+	}
+	if config.BufferSize == 0 {
+		config.BufferSize = DefaultBufferSize
+	}
+	if config.ManageSchema == nil {
+		manageSchema := true
+		config.ManageSchema = &manageSchema
+	}
+	for _, value := range []*string{&config.Username, &config.Password} {
+		if name, ok := strings.CutPrefix(*value, "env."); ok {
+			*value = os.Getenv(name)
+			if *value == "" {
+				return config, fmt.Errorf("environment variable %s not found", name)
+			}
+		}
+	}
+	return config, nil
+}
+
+// GetName returns the name of the plugin.
+func (p *ClickHousePlugin) GetName() string {
+	return PluginName
+}
+
+// TransportInterceptor is not used for this plugin
+func (p *ClickHousePlugin) TransportInterceptor(ctx *schemas.BifrostContext, url string, headers map[string]string, body map[string]any) (map[string]string, map[string]any, error) {
+	return headers, body, nil
+}
+
+// PreHook records the start time of the request in the context
+func (p *ClickHousePlugin) PreHook(ctx *schemas.BifrostContext, req *schemas.BifrostRequest) (*schemas.BifrostRequest, *schemas.PluginShortCircuit, error) {
+	ctx.SetValue(startTimeKey, time.Now())
+	return req, nil, nil
+}
+
+// PostHook queues the event of the request, once per request: streams are exported on their last chunk
+func (p *ClickHousePlugin) PostHook(ctx *schemas.BifrostContext, result *schemas.BifrostResponse, bifrostErr *schemas.BifrostError) (*schemas.BifrostResponse, *schemas.BifrostError, error) {
+	requestType, provider, model := bifrost.GetResponseFields(result, bifrostErr)
+	stream := bifrost.IsStreamRequestType(requestType)
+	if stream {
+		if isFinalChunk, ok := ctx.Value(schemas.BifrostContextKeyStreamEndIndicator).(bool); !ok || !isFinalChunk {
+			return result, bifrostErr, nil
+		}
+	}
+	startTime, ok := ctx.Value(startTimeKey).(time.Time)
+	if !ok {
+		p.logger.Warn("start time not found in context for clickhouse PostHook")
+		return result, bifrostErr, nil
+	}
+
+	requestID := getStringFromContext(ctx, schemas.BifrostContextKeyRequestID)
+	if fallbackRequestID := getStringFromContext(ctx, schemas.BifrostContextKeyFallbackRequestID); fallbackRequestID != "" {
+		requestID = fallbackRequestID
+	}
+	event := &Event{
+		RequestID:       requestID,
+		Timestamp:       startTime.UTC().Format("2006-01-02 15:04:05.000"),
+		Provider:        string(provider),
+		Model:           model,
+		RequestType:     string(requestType),
+		Status:          "success",
+		Stream:          stream,
+		LatencyMs:       float64(time.Since(startTime).Microseconds()) / 1000,
+		NumberOfRetries: getIntFromContext(ctx, schemas.BifrostContextKeyNumberOfRetries),
+		FallbackIndex:   getIntFromContext(ctx, schemas.BifrostContextKeyFallbackIndex),
+		VirtualKeyID:    getStringFromContext(ctx, schemas.BifrostContextKey("bf-governance-virtual-key-id")),
+		VirtualKeyName:  getStringFromContext(ctx, schemas.BifrostContextKey("bf-governance-virtual-key-name")),
+		SelectedKeyID:   getStringFromContext(ctx, schemas.BifrostContextKeySelectedKeyID),
+		SelectedKeyName: getStringFromContext(ctx, schemas.BifrostContextKeySelectedKeyName),
+		TeamID:          getStringFromContext(ctx, schemas.BifrostContextKey("bf-governance-team-id")),
+		TeamName:        getStringFromContext(ctx, schemas.BifrostContextKey("bf-governance-team-name")),
+		CustomerID:      getStringFromContext(ctx, schemas.BifrostContextKey("bf-governance-customer-id")),
+		CustomerName:    getStringFromContext(ctx, schemas.BifrostContextKey("bf-governance-customer-name")),
+		EndUser:         getStringFromContext(ctx, schemas.BifrostContextKeyEndUser),
+		Tags:            map[string]string{},
+	}
+	event.IsTestKey, _ = ctx.Value(schemas.BifrostContextKeySelectedKeyIsTest).(bool)
+	if tags, ok := ctx.Value(schemas.BifrostContextKeyRequestTags).(map[string]string); ok {
+		for key, value := range tags {
+			event.Tags[key] = value
+		}
+	}
+	if bifrostErr != nil {
+		event.Status = "error"
+		if bifrostErr.StatusCode != nil {
+			event.ErrorStatusCode = *bifrostErr.StatusCode
+		}
+		event.ErrorCode = string(bifrostErr.ErrorCode)
+		if bifrostErr.Error != nil {
+			event.ErrorMessage = bifrostErr.Error.Message
+		}
+	}
+
+	// Cost and usage are read in the background, the response is not modified
+	p.pending.Add(1)
+	go func() {
+		defer p.pending.Done()
+		if result != nil {
+			event.InputTokens, event.OutputTokens = getTokens(result)
+			event.TotalTokens = event.InputTokens + event.OutputTokens
+			if p.pricingManager != nil {
+				event.Cost = p.pricingManager.CalculateCostWithCacheDebug(result)
+			}
+			if cacheDebug := result.GetExtraFields().CacheDebug; cacheDebug != nil {
+				event.CacheHit = cacheDebug.CacheHit
+			}
+		}
+		p.enqueue(event)
+	}()
+	return result, bifrostErr, nil
+}
+
+// enqueue queues an event for its insert, dropping it when the buffer is full so that requests never block on
+// ClickHouse
+func (p *ClickHousePlugin) enqueue(event *Event) {
+	select {
+	case <-p.ctx.Done():
+		return
+	default:
+	}
+	select {
+	case p.events <- event:
+	default:
+		p.dropped.Add(1)
+	}
+}
+
+// flushLoop inserts the queued events when a batch is full or the flush interval elapsed, and the remaining events
+// once the plugin is cleaned up
+func (p *ClickHousePlugin) flushLoop() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(time.Duration(p.config.FlushIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+	batch := make([]*Event, 0, p.config.BatchSize)
+	for {
+		select {
+		case event := <-p.events:
+			batch = append(batch, event)
+			if len(batch) >= p.config.BatchSize {
+				batch = p.flush(batch)
+			}
+		case <-ticker.C:
+			batch = p.flush(batch)
+		case <-p.ctx.Done():
+			for {
+				select {
+				case event := <-p.events:
+					batch = append(batch, event)
+					if len(batch) >= p.config.BatchSize {
+						batch = p.flush(batch)
+					}
+				default:
+					p.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush inserts a batch of events and returns the emptied batch. A batch that cannot be inserted is dropped, so that
+// the buffer keeps up with the traffic.
+func (p *ClickHousePlugin) flush(batch []*Event) []*Event {
+	if dropped := p.dropped.Swap(0); dropped > 0 {
+		p.logger.Warn("clickhouse buffer full, dropped %d events", dropped)
+	}
+	if len(batch) == 0 {
+		return batch
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := p.client.Insert(ctx, p.config.Database, p.config.Table, batch, p.config.AsyncInsert); err != nil {
+		p.logger.Error("failed to insert %d events in clickhouse: %v", len(batch), err)
+	}
+	return batch[:0]
+}
+
+// Cleanup inserts the queued events and stops the plugin
+func (p *ClickHousePlugin) Cleanup() error {
+	p.pending.Wait()
+	p.cancel()
+	p.wg.Wait()
+	return nil
+}
+
+// getTokens returns the input and output tokens of a response
+func getTokens(result *schemas.BifrostResponse) (inputTokens int, outputTokens int) {
+	switch {
+	case result.TextCompletionResponse != nil && result.TextCompletionResponse.Usage != nil:
+		return result.TextCompletionResponse.Usage.PromptTokens, result.TextCompletionResponse.Usage.CompletionTokens
+	case result.ChatResponse != nil && result.ChatResponse.Usage != nil:
+		return result.ChatResponse.Usage.PromptTokens, result.ChatResponse.Usage.CompletionTokens
+	case result.ResponsesResponse != nil && result.ResponsesResponse.Usage != nil:
+		return result.ResponsesResponse.Usage.InputTokens, result.ResponsesResponse.Usage.OutputTokens
+	case result.ResponsesStreamResponse != nil && result.ResponsesStreamResponse.Response != nil && result.ResponsesStreamResponse.Response.Usage != nil:
+		return result.ResponsesStreamResponse.Response.Usage.InputTokens, result.ResponsesStreamResponse.Response.Usage.OutputTokens
+	case result.EmbeddingResponse != nil && result.EmbeddingResponse.Usage != nil:
+		return result.EmbeddingResponse.Usage.PromptTokens, result.EmbeddingResponse.Usage.CompletionTokens
+	case result.SpeechStreamResponse != nil && result.SpeechStreamResponse.Usage != nil:
+		return result.SpeechStreamResponse.Usage.InputTokens, result.SpeechStreamResponse.Usage.OutputTokens
+	case result.TranscriptionResponse != nil && result.TranscriptionResponse.Usage != nil:
+		if result.TranscriptionResponse.Usage.InputTokens != nil {
+			inputTokens = *result.TranscriptionResponse.Usage.InputTokens
+		}
+		if result.TranscriptionResponse.Usage.OutputTokens != nil {
+			outputTokens = *result.TranscriptionResponse.Usage.OutputTokens
+		}
+	case result.TranscriptionStreamResponse != nil && result.TranscriptionStreamResponse.Usage != nil:
+		if result.TranscriptionStreamResponse.Usage.InputTokens != nil {
+			inputTokens = *result.TranscriptionStreamResponse.Usage.InputTokens
+		}
+		if result.TranscriptionStreamResponse.Usage.OutputTokens != nil {
+			outputTokens = *result.TranscriptionStreamResponse.Usage.OutputTokens
+		}
+	}
+	return inputTokens, outputTokens
+}
+
+// getStringFromContext safely extracts a string value from context
+func getStringFromContext(ctx *schemas.BifrostContext, key any) string {
+	if value := ctx.Value(key); value != nil {
+		if str, ok := value.(string); ok {
+			return str
+		}
+	}
+	return ""
+}
+
+// getIntFromContext safely extracts an int value from context
+func getIntFromContext(ctx *schemas.BifrostContext, key any) int {
+	if value := ctx.Value(key); value != nil {
+		if intValue, ok := value.(int); ok {
+			return intValue
+		}
+	}
+	return 0
+}
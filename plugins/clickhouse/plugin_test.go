@@ -0,0 +1,182 @@
+package clickhouse
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// fakeClickHouse records the statements and the inserted rows sent to its HTTP interface
+type fakeClickHouse struct {
+	mu         sync.Mutex
+	statements []string
+	inserts    []string // Queries of the inserts
+	rows       []map[string]any
+}
+
+func (f *fakeClickHouse) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if query := r.URL.Query().Get("query"); query != "" {
+		f.inserts = append(f.inserts, query)
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			var row map[string]any
+			if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			f.rows = append(f.rows, row)
+		}
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	f.statements = append(f.statements, string(body))
+}
+
+func newTestPlugin(t *testing.T, config Config) (*ClickHousePlugin, *fakeClickHouse) {
+	t.Helper()
+	fake := &fakeClickHouse{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	config.URL = server.URL
+	plugin, err := Init(context.Background(), &config, bifrost.NewDefaultLogger(schemas.LogLevelError), nil)
+	if err != nil {
+		t.Fatalf("failed to init plugin: %v", err)
+	}
+	return plugin, fake
+}
+
+// runRequest runs the hooks of the plugin for a request
+func runRequest(t *testing.T, plugin *ClickHousePlugin, ctx *schemas.BifrostContext, result *schemas.BifrostResponse, bifrostErr *schemas.BifrostError) {
+	t.Helper()
+	if _, _, err := plugin.PreHook(ctx, &schemas.BifrostRequest{}); err != nil {
+		t.Fatalf("PreHook failed: %v", err)
+	}
+	if _, _, err := plugin.PostHook(ctx, result, bifrostErr); err != nil {
+		t.Fatalf("PostHook failed: %v", err)
+	}
+}
+
+// TestInit_ManagesSchema tests that the database and table are created and their columns added
+func TestInit_ManagesSchema(t *testing.T) {
+	plugin, fake := newTestPlugin(t, Config{Database: "analytics", TTLDays: 90})
+	plugin.Cleanup()
+
+	if len(fake.statements) != 2+len(columns) {
+		t.Fatalf("expected the database, the table and its columns to be created, got %v", fake.statements)
+	}
+	if fake.statements[0] != "CREATE DATABASE IF NOT EXISTS `analytics`" {
+		t.Errorf("unexpected database statement %q", fake.statements[0])
+	}
+	create := fake.statements[1]
+	for _, expected := range []string{"CREATE TABLE IF NOT EXISTS `analytics`.`bifrost_requests`", "`tags` Map(String, String)", "ENGINE = MergeTree", "TTL toDateTime(timestamp) + INTERVAL 90 DAY"} {
+		if !strings.Contains(create, expected) {
+			t.Errorf("expected %q in the table statement, got %q", expected, create)
+		}
+	}
+	if !strings.HasPrefix(fake.statements[2], "ALTER TABLE `analytics`.`bifrost_requests` ADD COLUMN IF NOT EXISTS `request_id`") {
+		t.Errorf("unexpected column statement %q", fake.statements[2])
+	}
+
+	manageSchema := false
+	plugin, fake = newTestPlugin(t, Config{ManageSchema: &manageSchema})
+	plugin.Cleanup()
+	if len(fake.statements) != 0 {
+		t.Errorf("expected no schema statements, got %v", fake.statements)
+	}
+}
+
+// TestPostHook_InsertsEvents tests that requests are inserted once, streams on their last chunk, with their usage and
+// errors
+func TestPostHook_InsertsEvents(t *testing.T) {
+	plugin, fake := newTestPlugin(t, Config{})
+
+	// The request ID and the stream end indicator are reserved keys, set by bifrost on the parent context
+	ctx := schemas.NewBifrostContext(context.WithValue(context.Background(), schemas.BifrostContextKeyRequestID, "req-1"), schemas.NoDeadline)
+	ctx.SetValue(schemas.BifrostContextKeyEndUser, "customer-17")
+	ctx.SetValue(schemas.BifrostContextKeyRequestTags, map[string]string{"team": "search"})
+	runRequest(t, plugin, ctx, &schemas.BifrostResponse{ChatResponse: &schemas.BifrostChatResponse{
+		Usage:       &schemas.BifrostLLMUsage{PromptTokens: 12, CompletionTokens: 30},
+		ExtraFields: schemas.BifrostResponseExtraFields{RequestType: schemas.ChatCompletionRequest, Provider: schemas.OpenAI, ModelRequested: "gpt-4o"},
+	}}, nil)
+
+	// Intermediate chunks of a stream are skipped
+	streamCtx := schemas.NewBifrostContext(context.WithValue(context.Background(), schemas.BifrostContextKeyRequestID, "req-2"), schemas.NoDeadline)
+	chunk := &schemas.BifrostResponse{ChatResponse: &schemas.BifrostChatResponse{
+		ExtraFields: schemas.BifrostResponseExtraFields{RequestType: schemas.ChatCompletionStreamRequest, Provider: schemas.OpenAI, ModelRequested: "gpt-4o"},
+	}}
+	runRequest(t, plugin, streamCtx, chunk, nil)
+	finalChunkCtx := schemas.NewBifrostContext(context.WithValue(streamCtx, schemas.BifrostContextKeyStreamEndIndicator, true), schemas.NoDeadline)
+	if _, _, err := plugin.PostHook(finalChunkCtx, chunk, nil); err != nil {
+		t.Fatalf("PostHook failed: %v", err)
+	}
+
+	errorCtx := schemas.NewBifrostContext(context.WithValue(context.Background(), schemas.BifrostContextKeyRequestID, "req-3"), schemas.NoDeadline)
+	statusCode := 429
+	runRequest(t, plugin, errorCtx, nil, &schemas.BifrostError{
+		StatusCode:  &statusCode,
+		ErrorCode:   schemas.ErrorCodeRateLimited,
+		Error:       &schemas.ErrorField{Message: "rate limit exceeded"},
+		ExtraFields: schemas.BifrostErrorExtraFields{RequestType: schemas.ChatCompletionRequest, Provider: schemas.OpenAI, ModelRequested: "gpt-4o"},
+	})
+
+	// Cleanup inserts the queued events
+	plugin.Cleanup()
+
+	if len(fake.inserts) != 1 || fake.inserts[0] != "INSERT INTO `default`.`bifrost_requests` FORMAT JSONEachRow" {
+		t.Fatalf("expected a single insert of the batch, got %v", fake.inserts)
+	}
+	rows := make(map[string]map[string]any)
+	for _, row := range fake.rows {
+		rows[row["request_id"].(string)] = row
+	}
+	if len(fake.rows) != 3 || len(rows) != 3 {
+		t.Fatalf("expected one row per request, got %v", fake.rows)
+	}
+	chat := rows["req-1"]
+	if chat["status"] != "success" || chat["provider"] != "openai" || chat["model"] != "gpt-4o" || chat["total_tokens"] != float64(42) ||
+		chat["end_user"] != "customer-17" || chat["tags"].(map[string]any)["team"] != "search" {
+		t.Errorf("unexpected chat row %v", chat)
+	}
+	if rows["req-2"]["stream"] != true {
+		t.Errorf("expected the stream row, got %v", rows["req-2"])
+	}
+	failed := rows["req-3"]
+	if failed["status"] != "error" || failed["error_status_code"] != float64(429) || failed["error_code"] != "rate_limited" || failed["error_message"] != "rate limit exceeded" {
+		t.Errorf("unexpected error row %v", failed)
+	}
+}
+
+// TestResolveConfig tests the defaults of the config and its credentials from the environment
+func TestResolveConfig(t *testing.T) {
+	t.Setenv("TEST_CLICKHOUSE_PASSWORD", "secret")
+	config, err := resolveConfig(Config{URL: "http://clickhouse:8123/", Username: "bifrost", Password: "env.TEST_CLICKHOUSE_PASSWORD"})
+	if err != nil {
+		t.Fatalf("failed to resolve config: %v", err)
+	}
+	if config.URL != "http://clickhouse:8123" || config.Password != "secret" || config.Table != DefaultTable || config.BatchSize != DefaultBatchSize || !*config.ManageSchema {
+		t.Errorf("unexpected config %+v", config)
+	}
+	if _, err := resolveConfig(Config{}); err == nil {
+		t.Error("expected an error without url")
+	}
+	if _, err := resolveConfig(Config{URL: "http://clickhouse:8123", Password: "env.TEST_CLICKHOUSE_MISSING"}); err == nil {
+		t.Error("expected an error for a missing environment variable")
+	}
+}
@@ -0,0 +1,243 @@
+// Package governance provides the usage counters shared by the replicas of a gateway
+package governance
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+)
+
+const (
+	// DefaultSharedCountersSyncInterval is how often the usage of the other replicas is pulled into the local cache
+	DefaultSharedCountersSyncInterval = time.Second
+	// DefaultSharedCountersTimeout bounds each operation on the shared store, so that a slow store never stalls usage tracking
+	DefaultSharedCountersTimeout = 100 * time.Millisecond
+	// sharedCountersRetryInterval is how long the shared store is skipped after a failure before it is tried again
+	sharedCountersRetryInterval = 5 * time.Second
+)
+
+// CounterValue is the state of a shared usage counter
+type CounterValue struct {
+	Usage     float64
+	LastReset time.Time
+}
+
+// current reports whether a counter is still within its reset duration, counters past it restart from zero on their
+// next addition
+func (v CounterValue) current(resetDuration time.Duration, now time.Time) bool {
+	return resetDuration <= 0 || now.Sub(v.LastReset) < resetDuration
+}
+
+// CounterStore is a store of usage counters and plugin circuit breakers shared by the replicas of a gateway. A counter
+// restarts from zero once its reset duration has passed since its last reset, a reset duration of zero never resets it.
+type CounterStore interface {
+	// Add adds delta to a counter and returns its state after the addition. A counter missing from the store starts
+	// from seed, the local state of the replica, so that usage counted before the store was used is kept.
+	Add(ctx context.Context, key string, delta float64, resetDuration time.Duration, seed CounterValue) (CounterValue, error)
+	// Get returns the state of the counters, counters never added to are missing from the result
+	Get(ctx context.Context, keys []string) (map[string]CounterValue, error)
+	// OpenCircuit records that the circuit of a plugin is open until the given time, keeping the latest time
+	OpenCircuit(ctx context.Context, pluginName string, until time.Time) error
+	// OpenCircuits returns the circuits still open on any replica with the time until which they are open
+	OpenCircuits(ctx context.Context) (map[string]time.Time, error)
+	// Close releases the connections of the store
+	Close() error
+}
+
+// SharedCountersConfig configures the shared store of the rate limit and budget usage of the replicas of a gateway
+type SharedCountersConfig struct {
+	Redis *RedisCountersConfig `json:"redis,omitempty"`
+
+	SyncIntervalMs int `json:"sync_interval_ms,omitempty"` // How often the usage of the other replicas is pulled, 1000 by default
+	TimeoutMs      int `json:"timeout_ms,omitempty"`       // Timeout of each operation on the store, 100 by default
+}
+
+// sharedCounters counts the usage of the rate limits and budgets in a CounterStore, and degrades to the local
+// counters of the replica while the store is unavailable. It also shares the circuit breakers of the plugins as a
+// schemas.PluginCircuitStore, whose open circuits are cached locally and pulled with the usage.
+type sharedCounters struct {
+	store        CounterStore
+	timeout      time.Duration
+	syncInterval time.Duration
+	logger       schemas.Logger
+
+	unavailableUntil atomic.Int64 // Unix nanoseconds until which the store is skipped after a failure
+	degraded         atomic.Bool  // Whether the last operation failed, to log once per outage
+
+	circuitsMu sync.RWMutex
+	circuits   map[string]time.Time // Time until which the circuit of each plugin is open on any replica
+}
+
+// newSharedCounters creates the shared counters of the configured store. A store that cannot be reached yet is
+// retried later, the replica counting its usage locally meanwhile.
+func newSharedCounters(ctx context.Context, config *SharedCountersConfig, logger schemas.Logger) (*sharedCounters, error) {
+	if config.Redis == nil {
+		return nil, fmt.Errorf("shared counters require a redis config")
+	}
+	counters := &sharedCounters{
+		timeout:      time.Duration(config.TimeoutMs) * time.Millisecond,
+		syncInterval: time.Duration(config.SyncIntervalMs) * time.Millisecond,
+		logger:       logger,
+		circuits:     make(map[string]time.Time),
+	}
+	if counters.timeout <= 0 {
+		counters.timeout = DefaultSharedCountersTimeout
+	}
+	if counters.syncInterval <= 0 {
+		counters.syncInterval = DefaultSharedCountersSyncInterval
+	}
+	store, err := NewRedisCounterStore(ctx, *config.Redis)
+	if store == nil {
+		return nil, err
+	}
+	counters.store = store
+	counters.observe(err)
+	return counters, nil
+}
+
+// available reports whether the store should be tried
+func (c *sharedCounters) available() bool {
+	return time.Now().UnixNano() >= c.unavailableUntil.Load()
+}
+
+// observe records the outcome of an operation on the store, skipping the store for a while after a failure
+func (c *sharedCounters) observe(err error) {
+	if err != nil {
+		c.unavailableUntil.Store(time.Now().Add(sharedCountersRetryInterval).UnixNano())
+		if !c.degraded.Swap(true) {
+			c.logger.Warn("governance shared counters unavailable, counting usage locally until they recover: %v", err)
+		}
+		return
+	}
+	if c.degraded.Swap(false) {
+		c.logger.Info("governance shared counters recovered")
+	}
+}
+
+// add adds delta to a counter, and returns its shared state and whether the store could be reached
+func (c *sharedCounters) add(key string, delta float64, resetDuration time.Duration, seed CounterValue) (CounterValue, bool) {
+	if !c.available() {
+		return CounterValue{}, false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	value, err := c.store.Add(ctx, key, delta, resetDuration, seed)
+	c.observe(err)
+	return value, err == nil
+}
+
+// get returns the shared state of counters, and whether the store could be reached
+func (c *sharedCounters) get(keys []string) (map[string]CounterValue, bool) {
+	if !c.available() {
+		return nil, false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	values, err := c.store.Get(ctx, keys)
+	c.observe(err)
+	return values, err == nil
+}
+
+// OpenUntil returns the time until which the circuit of a plugin was opened by any replica, as of the last sync
+func (c *sharedCounters) OpenUntil(pluginName string) time.Time {
+	c.circuitsMu.RLock()
+	defer c.circuitsMu.RUnlock()
+	return c.circuits[pluginName]
+}
+
+// Open shares that the circuit of a plugin is open. The store is written in the background, so that the request
+// opening the circuit never waits for it.
+func (c *sharedCounters) Open(pluginName string, until time.Time) {
+	c.circuitsMu.Lock()
+	if until.After(c.circuits[pluginName]) {
+		c.circuits[pluginName] = until
+	}
+	c.circuitsMu.Unlock()
+	if !c.available() {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+		defer cancel()
+		c.observe(c.store.OpenCircuit(ctx, pluginName, until))
+	}()
+}
+
+// syncCircuits pulls the circuits opened by the other replicas into the local cache
+func (c *sharedCounters) syncCircuits() {
+	if !c.available() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	circuits, err := c.store.OpenCircuits(ctx)
+	c.observe(err)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	c.circuitsMu.Lock()
+	defer c.circuitsMu.Unlock()
+	for pluginName, until := range circuits {
+		if until.After(c.circuits[pluginName]) {
+			c.circuits[pluginName] = until
+		}
+	}
+	for pluginName, until := range c.circuits {
+		if !until.After(now) {
+			delete(c.circuits, pluginName)
+		}
+	}
+}
+
+// close releases the connections of the store
+func (c *sharedCounters) close() error {
+	return c.store.Close()
+}
+
+// rateLimitTokensKey is the key of the shared token counter of a rate limit
+func rateLimitTokensKey(rateLimitID string) string {
+	return "ratelimit:" + rateLimitID + ":tokens"
+}
+
+// rateLimitRequestsKey is the key of the shared request counter of a rate limit
+func rateLimitRequestsKey(rateLimitID string) string {
+	return "ratelimit:" + rateLimitID + ":requests"
+}
+
+// budgetKey is the key of the shared usage counter of a budget
+func budgetKey(budgetID string) string {
+	return "budget:" + budgetID
+}
+
+// endUserTokensKey is the key of the shared token counter of an end user, keyed by endUserKey
+func endUserTokensKey(key string) string {
+	return "enduser:" + key + ":tokens"
+}
+
+// endUserRequestsKey is the key of the shared request counter of an end user, keyed by endUserKey
+func endUserRequestsKey(key string) string {
+	return "enduser:" + key + ":requests"
+}
+
+// endUserCostKey is the key of the shared cost counter of an end user, keyed by endUserKey
+func endUserCostKey(key string) string {
+	return "enduser:" + key + ":cost"
+}
+
+// resetDuration parses the reset duration of a counter, zero when it has none or it is invalid
+func resetDuration(duration *string) time.Duration {
+	if duration == nil {
+		return 0
+	}
+	parsed, err := configstoreTables.ParseDuration(*duration)
+	if err != nil {
+		return 0
+	}
+	return parsed
+}
@@ -0,0 +1,174 @@
+package governance
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// memoryCounterStore is an in-memory CounterStore, failing every operation while err is set
+type memoryCounterStore struct {
+	mu       sync.Mutex
+	err      error
+	calls    int
+	counters map[string]CounterValue
+	circuits map[string]time.Time
+}
+
+func newMemoryCounterStore() *memoryCounterStore {
+	return &memoryCounterStore{counters: make(map[string]CounterValue), circuits: make(map[string]time.Time)}
+}
+
+func (s *memoryCounterStore) Add(ctx context.Context, key string, delta float64, resetDuration time.Duration, seed CounterValue) (CounterValue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.err != nil {
+		return CounterValue{}, s.err
+	}
+	value, ok := s.counters[key]
+	if !ok {
+		value = seed
+	}
+	value.Usage += delta
+	s.counters[key] = value
+	return value, nil
+}
+
+func (s *memoryCounterStore) Get(ctx context.Context, keys []string) (map[string]CounterValue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	values := make(map[string]CounterValue)
+	for _, key := range keys {
+		if value, ok := s.counters[key]; ok {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+func (s *memoryCounterStore) OpenCircuit(ctx context.Context, pluginName string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.err != nil {
+		return s.err
+	}
+	if until.After(s.circuits[pluginName]) {
+		s.circuits[pluginName] = until
+	}
+	return nil
+}
+
+func (s *memoryCounterStore) OpenCircuits(ctx context.Context) (map[string]time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	circuits := make(map[string]time.Time, len(s.circuits))
+	for pluginName, until := range s.circuits {
+		circuits[pluginName] = until
+	}
+	return circuits, nil
+}
+
+func (s *memoryCounterStore) Close() error { return nil }
+
+func newTestSharedCounters(store CounterStore) *sharedCounters {
+	return &sharedCounters{
+		store:    store,
+		timeout:  DefaultSharedCountersTimeout,
+		logger:   bifrost.NewDefaultLogger(schemas.LogLevelError),
+		circuits: make(map[string]time.Time),
+	}
+}
+
+// TestSharedCounters_Degrades tests that the store is skipped for a while after a failure, the replica counting its
+// usage locally meanwhile
+func TestSharedCounters_Degrades(t *testing.T) {
+	store := newMemoryCounterStore()
+	counters := newTestSharedCounters(store)
+
+	if value, ok := counters.add("budget:b1", 2, time.Hour, CounterValue{Usage: 3}); !ok || value.Usage != 5 {
+		t.Fatalf("Expected the addition to the shared counter, got %+v, %v", value, ok)
+	}
+
+	store.err = errors.New("connection refused")
+	if _, ok := counters.add("budget:b1", 1, time.Hour, CounterValue{}); ok {
+		t.Fatal("Expected the failed addition to be reported")
+	}
+	calls := store.calls
+	if _, ok := counters.get([]string{"budget:b1"}); ok {
+		t.Error("Expected the store to be skipped after a failure")
+	}
+	if store.calls != calls {
+		t.Errorf("Expected no call to the store during the retry interval, got %d", store.calls-calls)
+	}
+
+	// Once the retry interval has passed the store is tried again
+	store.err = nil
+	counters.unavailableUntil.Store(time.Now().Add(-time.Millisecond).UnixNano())
+	values, ok := counters.get([]string{"budget:b1"})
+	if !ok || values["budget:b1"].Usage != 5 {
+		t.Errorf("Expected the shared counter after the recovery, got %+v, %v", values, ok)
+	}
+	if counters.degraded.Load() {
+		t.Error("Expected the counters to be recovered")
+	}
+}
+
+// TestSharedCounters_Circuits tests that a circuit opened by a replica is shared with the others on their next sync
+func TestSharedCounters_Circuits(t *testing.T) {
+	store := newMemoryCounterStore()
+	replicaA := newTestSharedCounters(store)
+	replicaB := newTestSharedCounters(store)
+	var _ schemas.PluginCircuitStore = replicaA
+
+	until := time.Now().Add(time.Minute)
+	replicaA.Open("guardrail", until)
+	if !replicaA.OpenUntil("guardrail").Equal(until) {
+		t.Errorf("Expected the circuit to be open locally right away, got %v", replicaA.OpenUntil("guardrail"))
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		store.mu.Lock()
+		shared := store.circuits["guardrail"]
+		store.mu.Unlock()
+		if shared.Equal(until) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the circuit to be written to the store")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if !replicaB.OpenUntil("guardrail").IsZero() {
+		t.Error("Expected the circuit to be unknown to the other replica before its sync")
+	}
+	replicaB.syncCircuits()
+	if !replicaB.OpenUntil("guardrail").Equal(until) {
+		t.Errorf("Expected the circuit to be open on the other replica after its sync, got %v", replicaB.OpenUntil("guardrail"))
+	}
+
+	// Circuits whose time has passed are dropped from the cache
+	store.mu.Lock()
+	store.circuits["guardrail"] = time.Now().Add(-time.Second)
+	store.mu.Unlock()
+	replicaB.circuits["guardrail"] = time.Now().Add(-time.Second)
+	replicaB.syncCircuits()
+	if !replicaB.OpenUntil("guardrail").IsZero() {
+		t.Errorf("Expected the closed circuit to be dropped, got %v", replicaB.OpenUntil("guardrail"))
+	}
+}
@@ -0,0 +1,125 @@
+// Package governance provides inbound request deduplication keyed by client-supplied event IDs
+package governance
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// dedupeSweepInterval is the minimum interval between two sweeps of expired dedupe entries
+const dedupeSweepInterval = time.Minute
+
+// dedupeEntry tracks a single event ID seen for a virtual key
+type dedupeEntry struct {
+	expiresAt time.Time
+	completed bool
+	response  []byte // JSON of the response of the original request, nil for streams and while in flight
+}
+
+// replayResponse returns a copy of the response of the original request, nil if there is none to replay.
+// Every duplicate gets a copy of its own, so that the plugins running after governance cannot alter the response
+// replayed to the next duplicates.
+func (e dedupeEntry) replayResponse() *schemas.BifrostResponse {
+	if e.response == nil {
+		return nil
+	}
+	var response schemas.BifrostResponse
+	if err := json.Unmarshal(e.response, &response); err != nil {
+		return nil
+	}
+	return &response
+}
+
+// EventDeduplicator remembers the event IDs seen per virtual key, so events redelivered by
+// at-least-once upstream systems (queues, webhooks) are not sent to the providers twice.
+// Events are only remembered in memory: they are forgotten on restart, and each replica of a
+// multi-node deployment deduplicates the requests it serves only, even when governance shares
+// its counters through Redis.
+type EventDeduplicator struct {
+	mu        sync.Mutex
+	entries   map[string]*dedupeEntry
+	lastSweep time.Time
+}
+
+// NewEventDeduplicator creates a new, empty event deduplicator
+func NewEventDeduplicator() *EventDeduplicator {
+	return &EventDeduplicator{
+		entries:   make(map[string]*dedupeEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+// dedupeKey builds the key an event is tracked under, event IDs are scoped to their virtual key
+func dedupeKey(virtualKeyID, eventID string) string {
+	return virtualKeyID + ":" + eventID
+}
+
+// Begin registers the event as in flight for the given window.
+// If the event was already seen within its window, the existing entry is returned with true
+// and nothing is registered.
+func (d *EventDeduplicator) Begin(key string, window time.Duration) (dedupeEntry, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(d.lastSweep) >= dedupeSweepInterval {
+		d.sweep(now)
+	}
+	if entry, ok := d.entries[key]; ok && now.Before(entry.expiresAt) {
+		return *entry, true
+	}
+	d.entries[key] = &dedupeEntry{expiresAt: now.Add(window)}
+	return dedupeEntry{}, false
+}
+
+// Reserve marks the event as in flight again without checking for duplicates.
+// It is used by the request already owning the event, e.g. when falling back to another provider.
+func (d *EventDeduplicator) Reserve(key string, window time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if entry, ok := d.entries[key]; ok {
+		entry.completed = false
+		entry.response = nil
+		return
+	}
+	d.entries[key] = &dedupeEntry{expiresAt: time.Now().Add(window)}
+}
+
+// Complete marks the event as processed and stores a copy of the response to replay to duplicates.
+// Duplicates of an event whose response cannot be copied are rejected as for streams.
+func (d *EventDeduplicator) Complete(key string, response *schemas.BifrostResponse) {
+	var data []byte
+	if response != nil {
+		data, _ = json.Marshal(response)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if entry, ok := d.entries[key]; ok {
+		entry.completed = true
+		entry.response = data
+	}
+}
+
+// Release forgets the event, so a redelivery is processed again (used when the original request failed)
+func (d *EventDeduplicator) Release(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.entries, key)
+}
+
+// sweep removes expired entries, the caller must hold the lock
+func (d *EventDeduplicator) sweep(now time.Time) {
+	for key, entry := range d.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(d.entries, key)
+		}
+	}
+	d.lastSweep = now
+}
@@ -0,0 +1,115 @@
+package governance
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// TestEventDeduplicator_Window tests that an event is a duplicate within its window, in flight until completed,
+// and processed again once released
+func TestEventDeduplicator_Window(t *testing.T) {
+	deduplicator := NewEventDeduplicator()
+	key := dedupeKey("vk1", "evt_1")
+
+	if _, duplicate := deduplicator.Begin(key, time.Minute); duplicate {
+		t.Fatal("Expected the first request of the event not to be a duplicate")
+	}
+	entry, duplicate := deduplicator.Begin(key, time.Minute)
+	if !duplicate || entry.completed {
+		t.Fatalf("Expected a duplicate of the event in flight, got %v, %+v", duplicate, entry)
+	}
+	if _, duplicate := deduplicator.Begin(dedupeKey("vk2", "evt_1"), time.Minute); duplicate {
+		t.Error("Expected event IDs to be scoped to their virtual key")
+	}
+
+	deduplicator.Complete(key, nil)
+	if entry, duplicate := deduplicator.Begin(key, time.Minute); !duplicate || !entry.completed || entry.replayResponse() != nil {
+		t.Errorf("Expected a duplicate of the completed stream without response, got %v, %+v", duplicate, entry)
+	}
+
+	deduplicator.Release(key)
+	if _, duplicate := deduplicator.Begin(key, time.Minute); duplicate {
+		t.Error("Expected the released event to be processed again")
+	}
+}
+
+// TestEventDeduplicator_Expiry tests that an event is processed again once its window has passed, and that the
+// sweep drops the expired events
+func TestEventDeduplicator_Expiry(t *testing.T) {
+	deduplicator := NewEventDeduplicator()
+	key := dedupeKey("vk1", "evt_1")
+
+	deduplicator.Begin(key, 20*time.Millisecond)
+	deduplicator.Complete(key, nil)
+	deduplicator.Begin(dedupeKey("vk1", "evt_2"), 20*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+
+	if _, duplicate := deduplicator.Begin(key, time.Minute); duplicate {
+		t.Error("Expected the event to be processed again after its window")
+	}
+
+	deduplicator.mu.Lock()
+	deduplicator.lastSweep = time.Now().Add(-dedupeSweepInterval)
+	deduplicator.mu.Unlock()
+	deduplicator.Begin(dedupeKey("vk1", "evt_3"), time.Minute)
+	deduplicator.mu.Lock()
+	defer deduplicator.mu.Unlock()
+	if _, ok := deduplicator.entries[dedupeKey("vk1", "evt_2")]; ok || len(deduplicator.entries) != 2 {
+		t.Errorf("Expected the sweep to drop the expired event only, got %d entries", len(deduplicator.entries))
+	}
+}
+
+// TestEventDeduplicator_ConcurrentDuplicates tests that a single one of concurrent requests of an event is processed
+func TestEventDeduplicator_ConcurrentDuplicates(t *testing.T) {
+	deduplicator := NewEventDeduplicator()
+	key := dedupeKey("vk1", "evt_1")
+
+	var processed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, duplicate := deduplicator.Begin(key, time.Minute); !duplicate {
+				processed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if processed.Load() != 1 {
+		t.Errorf("Expected a single request of the event to be processed, got %d", processed.Load())
+	}
+}
+
+// TestEventDeduplicator_ReplaysCopies tests that every duplicate gets a copy of the original response, unaffected
+// by the changes made to the original response or to the other copies
+func TestEventDeduplicator_ReplaysCopies(t *testing.T) {
+	deduplicator := NewEventDeduplicator()
+	key := dedupeKey("vk1", "evt_1")
+
+	response := &schemas.BifrostResponse{ChatResponse: &schemas.BifrostChatResponse{
+		ID:          "chatcmpl-1",
+		Model:       "gpt-4o",
+		ExtraFields: schemas.BifrostResponseExtraFields{Provider: schemas.OpenAI},
+	}}
+	deduplicator.Begin(key, time.Minute)
+	deduplicator.Complete(key, response)
+	response.ChatResponse.ID = "changed"
+
+	entry, _ := deduplicator.Begin(key, time.Minute)
+	first := entry.replayResponse()
+	if first == nil || first.ChatResponse == nil || first.ChatResponse.ID != "chatcmpl-1" || first.ChatResponse.ExtraFields.Provider != schemas.OpenAI {
+		t.Fatalf("Expected the original response, got %+v", first)
+	}
+	first.ChatResponse.ID = "changed"
+	first.ChatResponse.ExtraFields.Provider = schemas.Anthropic
+
+	entry, _ = deduplicator.Begin(key, time.Minute)
+	if second := entry.replayResponse(); second == first || second.ChatResponse.ID != "chatcmpl-1" || second.ChatResponse.ExtraFields.Provider != schemas.OpenAI {
+		t.Errorf("Expected a copy of the original response, got %+v", second.ChatResponse)
+	}
+}
@@ -0,0 +1,275 @@
+// Package governance provides the rate limits and budgets of the end users of virtual keys
+package governance
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+)
+
+// endUserSweepInterval is the minimum interval between two sweeps of end users without usage in their windows
+const endUserSweepInterval = time.Minute
+
+// endUserCounter counts the usage of an end user within a window
+type endUserCounter struct {
+	value   float64
+	resetAt time.Time     // End of the window, the counter restarts from zero after it
+	window  time.Duration // Reset duration of the counter, zero when it has no limit
+}
+
+// add adds to the counter, starting a new window first if the current one is over. It returns the state of the
+// counter before the addition, the seed of its shared counter.
+func (c *endUserCounter) add(value float64, window time.Duration, now time.Time) CounterValue {
+	c.window = window
+	if !now.Before(c.resetAt) {
+		c.value = 0
+		c.resetAt = now.Add(window)
+	}
+	seed := c.shared()
+	c.value += value
+	return seed
+}
+
+// current returns the usage of the current window
+func (c *endUserCounter) current(now time.Time) float64 {
+	if !now.Before(c.resetAt) {
+		return 0
+	}
+	return c.value
+}
+
+// shared returns the state of the counter as a shared counter
+func (c *endUserCounter) shared() CounterValue {
+	return CounterValue{Usage: c.value, LastReset: c.resetAt.Add(-c.window)}
+}
+
+// adopt sets the counter to the state of its shared counter
+func (c *endUserCounter) adopt(value CounterValue) {
+	c.value = value.Usage
+	c.resetAt = value.LastReset.Add(c.window)
+}
+
+// endUserUsage is the usage of an end user of a virtual key
+type endUserUsage struct {
+	tokens   endUserCounter
+	requests endUserCounter
+	cost     endUserCounter
+}
+
+// newEndUserUsage creates the usage of an end user with the windows of its limits
+func newEndUserUsage(limits *configstoreTables.EndUserLimits) *endUserUsage {
+	return &endUserUsage{
+		tokens:   endUserCounter{window: endUserWindow(limits.TokenResetDuration)},
+		requests: endUserCounter{window: endUserWindow(limits.RequestResetDuration)},
+		cost:     endUserCounter{window: endUserWindow(limits.BudgetResetDuration)},
+	}
+}
+
+// counters returns the counters of the usage by the key of their shared counter
+func (u *endUserUsage) counters(key string) map[string]*endUserCounter {
+	return map[string]*endUserCounter{
+		endUserTokensKey(key):   &u.tokens,
+		endUserRequestsKey(key): &u.requests,
+		endUserCostKey(key):     &u.cost,
+	}
+}
+
+// EndUserLimiter enforces the end user limits of the virtual keys. The usage of each end user is kept in memory,
+// and starts over on restart. With shared counters, the usage is also added to the shared store and the usage of
+// the other replicas is pulled with the rate limits and budgets, otherwise it is not shared between Bifrost instances.
+type EndUserLimiter struct {
+	mu        sync.Mutex
+	usage     map[string]*endUserUsage
+	lastSweep time.Time
+	counters  *sharedCounters // nil when the usage is only counted locally
+}
+
+// NewEndUserLimiter creates a new end user limiter without usage, sharing it through counters when they are not nil
+func NewEndUserLimiter(counters *sharedCounters) *EndUserLimiter {
+	return &EndUserLimiter{
+		usage:     make(map[string]*endUserUsage),
+		lastSweep: time.Now(),
+		counters:  counters,
+	}
+}
+
+// endUserKey builds the key the usage of an end user is tracked under, end users are scoped to their virtual key
+func endUserKey(virtualKeyID, endUser string) string {
+	return virtualKeyID + ":" + endUser
+}
+
+// Admit checks the limits of the end user of a virtual key and counts the request when it is admitted. It returns
+// the decision and the reason of the rejection.
+func (l *EndUserLimiter) Admit(virtualKeyID, endUser string, limits *configstoreTables.EndUserLimits) (Decision, string) {
+	key := endUserKey(virtualKeyID, endUser)
+	usage, tracked := l.load(key, limits)
+	if !tracked {
+		// The end user may have usage on the other replicas before its first request to this one
+		l.pull(map[string]*endUserUsage{key: usage})
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if limits.TokenMaxLimit != nil && int64(usage.tokens.current(now)) >= *limits.TokenMaxLimit {
+		l.mu.Unlock()
+		return DecisionTokenLimited, fmt.Sprintf("end user %s token limit exceeded (%d/%d, resets every %s)",
+			endUser, int64(usage.tokens.current(now)), *limits.TokenMaxLimit, *limits.TokenResetDuration)
+	}
+	if limits.RequestMaxLimit != nil && int64(usage.requests.current(now)) >= *limits.RequestMaxLimit {
+		l.mu.Unlock()
+		return DecisionRequestLimited, fmt.Sprintf("end user %s request limit exceeded (%d/%d, resets every %s)",
+			endUser, int64(usage.requests.current(now)), *limits.RequestMaxLimit, *limits.RequestResetDuration)
+	}
+	if limits.BudgetMaxLimit != nil && usage.cost.current(now) >= *limits.BudgetMaxLimit {
+		l.mu.Unlock()
+		return DecisionBudgetExceeded, fmt.Sprintf("end user %s budget exceeded ($%.4f/$%.4f, resets every %s)",
+			endUser, usage.cost.current(now), *limits.BudgetMaxLimit, *limits.BudgetResetDuration)
+	}
+	if limits.RequestMaxLimit == nil {
+		l.mu.Unlock()
+		return DecisionAllow, ""
+	}
+	seed := usage.requests.add(1, endUserWindow(limits.RequestResetDuration), now)
+	l.mu.Unlock()
+
+	l.push(endUserRequestsKey(key), &usage.requests, 1, seed)
+	return DecisionAllow, ""
+}
+
+// Record adds the tokens and cost of a completed request to the usage of the end user of a virtual key
+func (l *EndUserLimiter) Record(virtualKeyID, endUser string, limits *configstoreTables.EndUserLimits, tokens int64, cost float64) {
+	key := endUserKey(virtualKeyID, endUser)
+	// The usage is created again when it was swept since the request was admitted
+	usage, _ := l.load(key, limits)
+
+	l.mu.Lock()
+	now := time.Now()
+	var tokensSeed, costSeed CounterValue
+	recordTokens := limits.TokenMaxLimit != nil && tokens > 0
+	if recordTokens {
+		tokensSeed = usage.tokens.add(float64(tokens), endUserWindow(limits.TokenResetDuration), now)
+	}
+	recordCost := limits.BudgetMaxLimit != nil && cost > 0
+	if recordCost {
+		costSeed = usage.cost.add(cost, endUserWindow(limits.BudgetResetDuration), now)
+	}
+	l.mu.Unlock()
+
+	if recordTokens {
+		l.push(endUserTokensKey(key), &usage.tokens, float64(tokens), tokensSeed)
+	}
+	if recordCost {
+		l.push(endUserCostKey(key), &usage.cost, cost, costSeed)
+	}
+}
+
+// load returns the usage of an end user, creating it if it is not tracked yet, and whether it was tracked
+func (l *EndUserLimiter) load(key string, limits *configstoreTables.EndUserLimits) (*endUserUsage, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) >= endUserSweepInterval {
+		l.sweep(now)
+	}
+	usage, ok := l.usage[key]
+	if !ok {
+		usage = newEndUserUsage(limits)
+		l.usage[key] = usage
+	}
+	return usage, ok
+}
+
+// push adds usage to the shared counter of a counter and adopts its state across the replicas. The local usage is
+// kept when the counters are not shared or the shared store cannot be reached.
+func (l *EndUserLimiter) push(sharedKey string, counter *endUserCounter, delta float64, seed CounterValue) {
+	if l.counters == nil {
+		return
+	}
+	l.mu.Lock()
+	window := counter.window
+	l.mu.Unlock()
+	value, ok := l.counters.add(sharedKey, delta, window, seed)
+	if !ok {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	counter.adopt(value)
+}
+
+// pull adopts the usage the replicas counted for end users, for the counters with a limit within their window
+func (l *EndUserLimiter) pull(usage map[string]*endUserUsage) {
+	if l.counters == nil || len(usage) == 0 {
+		return
+	}
+	counters := make(map[string]*endUserCounter, 3*len(usage))
+	l.mu.Lock()
+	for key, u := range usage {
+		for sharedKey, counter := range u.counters(key) {
+			if counter.window > 0 {
+				counters[sharedKey] = counter
+			}
+		}
+	}
+	l.mu.Unlock()
+	if len(counters) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(counters))
+	for sharedKey := range counters {
+		keys = append(keys, sharedKey)
+	}
+	values, ok := l.counters.get(keys)
+	if !ok {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	for sharedKey, value := range values {
+		if counter, ok := counters[sharedKey]; ok && value.current(counter.window, now) {
+			counter.adopt(value)
+		}
+	}
+}
+
+// SyncSharedCounters pulls the usage counted by the other replicas for the end users tracked by this replica. Does
+// nothing when the counters are not shared or the shared store cannot be reached.
+func (l *EndUserLimiter) SyncSharedCounters() {
+	if l.counters == nil {
+		return
+	}
+	l.mu.Lock()
+	usage := make(map[string]*endUserUsage, len(l.usage))
+	for key, u := range l.usage {
+		usage[key] = u
+	}
+	l.mu.Unlock()
+	l.pull(usage)
+}
+
+// sweep drops the end users whose windows are all over. Must be called with the lock held.
+func (l *EndUserLimiter) sweep(now time.Time) {
+	for key, usage := range l.usage {
+		if !now.Before(usage.tokens.resetAt) && !now.Before(usage.requests.resetAt) && !now.Before(usage.cost.resetAt) {
+			delete(l.usage, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// endUserWindow parses a reset duration validated with the end user limits
+func endUserWindow(duration *string) time.Duration {
+	if duration == nil {
+		return 0
+	}
+	window, err := configstoreTables.ParseDuration(*duration)
+	if err != nil {
+		return 0
+	}
+	return window
+}
@@ -0,0 +1,140 @@
+package governance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+)
+
+// TestEndUserLimiter_Limits tests that the requests of an end user are rejected once one of its limits is exhausted,
+// without affecting the other end users, and admitted again once the window of the limit is over
+func TestEndUserLimiter_Limits(t *testing.T) {
+	tests := map[string]struct {
+		limits   configstoreTables.EndUserLimits
+		tokens   int64
+		cost     float64
+		expected Decision
+	}{
+		"requests": {
+			limits:   configstoreTables.EndUserLimits{RequestMaxLimit: bifrost.Ptr(int64(1)), RequestResetDuration: bifrost.Ptr("1m")},
+			expected: DecisionRequestLimited,
+		},
+		"tokens": {
+			limits:   configstoreTables.EndUserLimits{TokenMaxLimit: bifrost.Ptr(int64(100)), TokenResetDuration: bifrost.Ptr("1m")},
+			tokens:   120,
+			expected: DecisionTokenLimited,
+		},
+		"budget": {
+			limits:   configstoreTables.EndUserLimits{BudgetMaxLimit: bifrost.Ptr(0.5), BudgetResetDuration: bifrost.Ptr("1d")},
+			cost:     0.5,
+			expected: DecisionBudgetExceeded,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			limiter := NewEndUserLimiter(nil)
+			if decision, _ := limiter.Admit("vk1", "alice", &test.limits); decision != DecisionAllow {
+				t.Fatalf("Expected the first request to be allowed, got %s", decision)
+			}
+			limiter.Record("vk1", "alice", &test.limits, test.tokens, test.cost)
+
+			if decision, reason := limiter.Admit("vk1", "alice", &test.limits); decision != test.expected || reason == "" {
+				t.Errorf("Expected %s once the limit is exhausted, got %s (%s)", test.expected, decision, reason)
+			}
+			if decision, _ := limiter.Admit("vk1", "bob", &test.limits); decision != DecisionAllow {
+				t.Errorf("Expected the other end users to be allowed, got %s", decision)
+			}
+			if decision, _ := limiter.Admit("vk2", "alice", &test.limits); decision != DecisionAllow {
+				t.Errorf("Expected the end user of another virtual key to be allowed, got %s", decision)
+			}
+
+			usage := limiter.usage[endUserKey("vk1", "alice")]
+			for _, counter := range []*endUserCounter{&usage.tokens, &usage.requests, &usage.cost} {
+				counter.resetAt = time.Now().Add(-time.Second)
+			}
+			if decision, _ := limiter.Admit("vk1", "alice", &test.limits); decision != DecisionAllow {
+				t.Errorf("Expected the end user to be allowed after the window, got %s", decision)
+			}
+		})
+	}
+}
+
+// TestEndUserLimiter_SharedCounters tests that the usage of an end user counted by a replica is enforced by the
+// others, on the first request of the end user to a replica and after a sync
+func TestEndUserLimiter_SharedCounters(t *testing.T) {
+	store := newMemoryCounterStore()
+	first := NewEndUserLimiter(newTestSharedCounters(store))
+	second := NewEndUserLimiter(newTestSharedCounters(store))
+	limits := &configstoreTables.EndUserLimits{
+		RequestMaxLimit:      bifrost.Ptr(int64(2)),
+		RequestResetDuration: bifrost.Ptr("1h"),
+		BudgetMaxLimit:       bifrost.Ptr(1.0),
+		BudgetResetDuration:  bifrost.Ptr("1h"),
+	}
+
+	if decision, _ := first.Admit("vk1", "alice", limits); decision != DecisionAllow {
+		t.Fatalf("Expected the first request to be allowed, got %s", decision)
+	}
+	if decision, _ := second.Admit("vk1", "alice", limits); decision != DecisionAllow {
+		t.Fatalf("Expected the second request to be allowed, got %s", decision)
+	}
+	if decision, _ := second.Admit("vk1", "alice", limits); decision != DecisionRequestLimited {
+		t.Errorf("Expected the requests of both replicas to be counted, got %s", decision)
+	}
+	first.SyncSharedCounters()
+	if decision, _ := first.Admit("vk1", "alice", limits); decision != DecisionRequestLimited {
+		t.Errorf("Expected the requests of the other replica to be pulled, got %s", decision)
+	}
+
+	first.Admit("vk1", "bob", limits)
+	first.Record("vk1", "bob", limits, 0, 1.5)
+	if decision, _ := second.Admit("vk1", "bob", limits); decision != DecisionBudgetExceeded {
+		t.Errorf("Expected the budget spent on the other replica to be enforced, got %s", decision)
+	}
+}
+
+// TestGovernancePlugin_EndUserUsage tests that the tokens of the responses are recorded in the usage of the end user
+// of the request, whose next requests are rejected once its token limit is exhausted
+func TestGovernancePlugin_EndUserUsage(t *testing.T) {
+	vk := configstoreTables.TableVirtualKey{ID: "vk1", Name: "app", Value: "sk-bf-app", IsActive: true,
+		EndUserLimits: &configstoreTables.EndUserLimits{TokenMaxLimit: bifrost.Ptr(int64(100)), TokenResetDuration: bifrost.Ptr("1h")}}
+	plugin, err := Init(context.Background(), &Config{}, bifrost.NewDefaultLogger(schemas.LogLevelError), nil,
+		&configstore.GovernanceConfig{VirtualKeys: []configstoreTables.TableVirtualKey{vk}}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to init governance plugin: %v", err)
+	}
+	defer plugin.Cleanup()
+
+	request := func(endUser string) *schemas.PluginShortCircuit {
+		parent := context.WithValue(context.Background(), schemas.BifrostContextKeyVirtualKey, "sk-bf-app")
+		ctx := schemas.NewBifrostContext(context.WithValue(parent, schemas.BifrostContextKeyEndUser, endUser), schemas.NoDeadline)
+		defer ctx.Cancel()
+		req := &schemas.BifrostRequest{RequestType: schemas.ChatCompletionRequest, ChatRequest: &schemas.BifrostChatRequest{Provider: schemas.OpenAI, Model: "gpt-4o"}}
+		_, shortCircuit, _ := plugin.PreHook(ctx, req)
+		if shortCircuit != nil {
+			return shortCircuit
+		}
+		result := &schemas.BifrostResponse{ChatResponse: &schemas.BifrostChatResponse{
+			Usage:       &schemas.BifrostLLMUsage{TotalTokens: 120},
+			ExtraFields: schemas.BifrostResponseExtraFields{RequestType: schemas.ChatCompletionRequest, Provider: schemas.OpenAI, ModelRequested: "gpt-4o"},
+		}}
+		plugin.PostHook(ctx, result, nil)
+		plugin.wg.Wait()
+		return nil
+	}
+
+	if shortCircuit := request("alice"); shortCircuit != nil {
+		t.Fatalf("Expected the first request to be allowed, got %+v", shortCircuit.Error)
+	}
+	if shortCircuit := request("alice"); shortCircuit == nil || shortCircuit.Error.Type == nil || *shortCircuit.Error.Type != string(DecisionTokenLimited) {
+		t.Errorf("Expected the tokens of the end user to be recorded and limited, got %+v", shortCircuit)
+	}
+	if shortCircuit := request("bob"); shortCircuit != nil {
+		t.Errorf("Expected the other end users to be allowed, got %+v", shortCircuit.Error)
+	}
+}
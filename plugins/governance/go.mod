@@ -7,6 +7,7 @@ require gorm.io/gorm v1.31.1
 require (
 	github.com/maximhq/bifrost/core v1.2.35
 	github.com/maximhq/bifrost/framework v1.1.44
+	github.com/redis/go-redis/v9 v9.14.0
 )
 
 require (
@@ -77,7 +78,6 @@ require (
 	github.com/oklog/ulid v1.3.1 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/qdrant/go-client v1.16.1 // indirect
-	github.com/redis/go-redis/v9 v9.14.0 // indirect
 	github.com/rs/zerolog v1.34.0 // indirect
 	github.com/spf13/cast v1.10.0 // indirect
 	github.com/twitchyliquid64/golang-asm v0.15.1 // indirect
@@ -14,6 +14,7 @@ import (
 	"github.com/maximhq/bifrost/core/schemas"
 	"github.com/maximhq/bifrost/framework/configstore"
 	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+	"github.com/maximhq/bifrost/framework/leader"
 	"github.com/maximhq/bifrost/framework/modelcatalog"
 )
 
@@ -24,13 +25,16 @@ const (
 	governanceRejectedContextKey    schemas.BifrostContextKey = "bf-governance-rejected"
 	governanceIsCacheReadContextKey schemas.BifrostContextKey = "bf-governance-is-cache-read"
 	governanceIsBatchContextKey     schemas.BifrostContextKey = "bf-governance-is-batch"
+	governanceDedupeKeyContextKey   schemas.BifrostContextKey = "bf-governance-dedupe-key"
+	governanceIsDuplicateContextKey schemas.BifrostContextKey = "bf-governance-is-duplicate"
 
 	VirtualKeyPrefix = "sk-bf-"
 )
 
 // Config is the configuration for the governance plugin
 type Config struct {
-	IsVkMandatory *bool `json:"is_vk_mandatory"`
+	IsVkMandatory  *bool                 `json:"is_vk_mandatory"`
+	SharedCounters *SharedCountersConfig `json:"shared_counters,omitempty"` // Shares the rate limit and budget usage between the replicas of a gateway
 }
 
 type InMemoryStore interface {
@@ -48,6 +52,9 @@ type GovernancePlugin struct {
 	resolver *BudgetResolver  // Pure decision engine for hierarchical governance
 	tracker  *UsageTracker    // Business logic owner (updates, resets, persistence)
 
+	deduplicator *EventDeduplicator // Event IDs seen per virtual key
+	endUsers     *EndUserLimiter    // Usage of the end users of virtual keys
+
 	// Dependencies
 	configStore  configstore.ConfigStore
 	modelCatalog *modelcatalog.ModelCatalog
@@ -118,12 +125,21 @@ func Init(
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize governance store: %w", err)
 	}
+	if config != nil && config.SharedCounters != nil {
+		counters, err := newSharedCounters(ctx, config.SharedCounters, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize shared counters: %w", err)
+		}
+		governanceStore.counters = counters
+	}
 	// Initialize components in dependency order with fixed, optimal settings
 	// Resolver (pure decision engine for hierarchical governance, depends only on store)
 	resolver := NewBudgetResolver(governanceStore, logger)
 
 	// 3. Tracker (business logic owner, depends on store and resolver)
-	tracker := NewUsageTracker(ctx, governanceStore, resolver, store, logger)
+	// End user limits, sharing their usage through the shared counters too
+	endUsers := NewEndUserLimiter(governanceStore.counters)
+	tracker := NewUsageTracker(ctx, governanceStore, resolver, endUsers, store, logger)
 
 	// 4. Perform startup reset check for any expired limits from downtime
 	if store != nil {
@@ -139,6 +155,8 @@ func Init(
 		store:         governanceStore,
 		resolver:      resolver,
 		tracker:       tracker,
+		deduplicator:  NewEventDeduplicator(),
+		endUsers:      endUsers,
 		configStore:   store,
 		modelCatalog:  modelCatalog,
 		logger:        logger,
@@ -148,11 +166,50 @@ func Init(
 	return plugin, nil
 }
 
+// SetElector sets the elector of the replica persisting the periodic rate limit and budget resets to the database.
+// Without an elector every replica persists them.
+func (p *GovernancePlugin) SetElector(elector *leader.Elector) {
+	p.store.elector.Store(elector)
+}
+
+// PluginCircuits returns the store sharing the circuit breakers of the plugins with the other replicas, nil when the
+// counters are not shared
+func (p *GovernancePlugin) PluginCircuits() schemas.PluginCircuitStore {
+	if p.store.counters == nil {
+		return nil
+	}
+	return p.store.counters
+}
+
 // GetName returns the name of the plugin
 func (p *GovernancePlugin) GetName() string {
 	return PluginName
 }
 
+// virtualKeyFromHeaders returns the virtual key of the request headers, from the x-bf-vk header or a virtual key
+// sent as the Authorization bearer token, x-api-key or x-goog-api-key header
+func virtualKeyFromHeaders(headers map[string]string) string {
+	for header, value := range headers {
+		headerStr := strings.ToLower(header)
+		if headerStr == string(schemas.BifrostContextKeyVirtualKey) {
+			return value
+		}
+		if headerStr == "authorization" {
+			// Only accept Bearer token format: "Bearer ..."
+			if strings.HasPrefix(strings.ToLower(value), "bearer ") {
+				authHeaderValue := strings.TrimSpace(value[7:]) // Remove "Bearer " prefix
+				if authHeaderValue != "" && strings.HasPrefix(strings.ToLower(authHeaderValue), VirtualKeyPrefix) {
+					return authHeaderValue
+				}
+			}
+		}
+		if (headerStr == "x-api-key" || headerStr == "x-goog-api-key") && strings.HasPrefix(strings.ToLower(value), VirtualKeyPrefix) {
+			return value
+		}
+	}
+	return ""
+}
+
 // TransportInterceptor intercepts requests before they are processed (governance decision point)
 // Parameters:
 //   - ctx: The Bifrost context
@@ -165,44 +222,27 @@ func (p *GovernancePlugin) GetName() string {
 //   - map[string]any: The updated request body
 //   - error: Any error that occurred during processing
 func (p *GovernancePlugin) TransportInterceptor(ctx *schemas.BifrostContext, url string, headers map[string]string, body map[string]any) (map[string]string, map[string]any, error) {
-	var virtualKeyValue string
 	var err error
 
-	for header, value := range headers {
-		headerStr := strings.ToLower(header)
-		if headerStr == string(schemas.BifrostContextKeyVirtualKey) {
-			virtualKeyValue = string(value)
-			break
-		}
-		if headerStr == "authorization" {
-			valueStr := string(value)
-			// Only accept Bearer token format: "Bearer ..."
-			if strings.HasPrefix(strings.ToLower(valueStr), "bearer ") {
-				authHeaderValue := strings.TrimSpace(valueStr[7:]) // Remove "Bearer " prefix
-				if authHeaderValue != "" && strings.HasPrefix(strings.ToLower(authHeaderValue), VirtualKeyPrefix) {
-					virtualKeyValue = authHeaderValue
-					break
-				}
-			}
-		}
-		if (headerStr == "x-api-key" || headerStr == "x-goog-api-key") && strings.HasPrefix(strings.ToLower(string(value)), VirtualKeyPrefix) {
-			virtualKeyValue = string(value)
-			break
-		}
-	}
+	virtualKeyValue := virtualKeyFromHeaders(headers)
+	hints := parseRoutingHints(headers)
 	if virtualKeyValue == "" {
-		return headers, body, nil
+		return headers, p.applyRoutingHints(body, hints), nil
 	}
 
 	virtualKey, ok := p.store.GetVirtualKey(virtualKeyValue)
 	if !ok || virtualKey == nil || !virtualKey.IsActive {
-		return headers, body, nil
+		return headers, p.applyRoutingHints(body, hints), nil
 	}
 
-	body, err = p.loadBalanceProvider(body, virtualKey)
+	body = p.applyVirtualKeyDefaults(url, body, virtualKey)
+	body = p.rerouteResidency(body, virtualKey)
+
+	body, err = p.loadBalanceProvider(body, virtualKey, hints)
 	if err != nil {
 		return headers, body, err
 	}
+	body = p.applyRoutingHints(body, hints)
 
 	headers, err = p.addMCPIncludeTools(headers, virtualKey)
 	if err != nil {
@@ -212,15 +252,88 @@ func (p *GovernancePlugin) TransportInterceptor(ctx *schemas.BifrostContext, url
 	return headers, body, nil
 }
 
+// applyVirtualKeyDefaults applies the default parameters and the pinned system prompt of the virtual key
+// to the request body. Defaults only fill in what the client omitted, except the temperature ceiling which
+// clamps any client-supplied temperature above it.
+// Parameters:
+//   - url: The URL of the request
+//   - body: The request body
+//   - virtualKey: The virtual key configuration
+//
+// Returns:
+//   - map[string]any: The updated request body
+func (p *GovernancePlugin) applyVirtualKeyDefaults(url string, body map[string]any, virtualKey *configstoreTables.TableVirtualKey) map[string]any {
+	if virtualKey.DefaultParams == nil && virtualKey.SystemPrompt == nil {
+		return body
+	}
+	if body == nil {
+		body = make(map[string]any)
+	}
+	if params := virtualKey.DefaultParams; params != nil {
+		if params.Model != nil {
+			if modelStr, _ := body["model"].(string); modelStr == "" {
+				body["model"] = *params.Model
+			}
+		}
+		if params.MaxTemperature != nil {
+			if temperature, ok := body["temperature"].(float64); ok && temperature > *params.MaxTemperature {
+				body["temperature"] = *params.MaxTemperature
+			}
+		}
+		if params.ResponseFormat != nil {
+			if _, hasResponseFormat := body["response_format"]; !hasResponseFormat {
+				body["response_format"] = *params.ResponseFormat
+			}
+		}
+	}
+	// A system prompt policy supersedes the pinned system prompt, it is enforced in the PreHook
+	if policy, _ := p.systemPromptPolicy(virtualKey); policy == nil && virtualKey.SystemPrompt != nil && *virtualKey.SystemPrompt != "" {
+		body = applySystemPrompt(url, body, *virtualKey.SystemPrompt)
+	}
+	return body
+}
+
+// applySystemPrompt pins the system prompt on the request body when the client did not send one.
+// Anthropic-style bodies carry it in the top level "system" field, OpenAI-style chat bodies as the
+// first message and Responses API bodies in "instructions".
+func applySystemPrompt(url string, body map[string]any, systemPrompt string) map[string]any {
+	if _, hasSystem := body["system"]; hasSystem {
+		return body
+	}
+	messages, hasMessages := body["messages"].([]any)
+	if !hasMessages {
+		if _, hasInput := body["input"]; hasInput {
+			if instructions, _ := body["instructions"].(string); instructions == "" {
+				body["instructions"] = systemPrompt
+			}
+		}
+		return body
+	}
+	if strings.Contains(url, "/anthropic/") {
+		body["system"] = systemPrompt
+		return body
+	}
+	for _, message := range messages {
+		if messageMap, ok := message.(map[string]any); ok {
+			if role, _ := messageMap["role"].(string); role == "system" || role == "developer" {
+				return body
+			}
+		}
+	}
+	body["messages"] = append([]any{map[string]any{"role": "system", "content": systemPrompt}}, messages...)
+	return body
+}
+
 // loadBalanceProvider loads balances the provider for the request
 // Parameters:
 //   - body: The request body
 //   - virtualKey: The virtual key configuration
+//   - hints: The routing hints of the request headers, may be nil
 //
 // Returns:
 //   - map[string]any: The updated request body
 //   - error: Any error that occurred during processing
-func (p *GovernancePlugin) loadBalanceProvider(body map[string]any, virtualKey *configstoreTables.TableVirtualKey) (map[string]any, error) {
+func (p *GovernancePlugin) loadBalanceProvider(body map[string]any, virtualKey *configstoreTables.TableVirtualKey, hints *routingHints) (map[string]any, error) {
 	// Check if the request has a model field
 	modelValue, hasModel := body["model"]
 	if !hasModel {
@@ -263,6 +376,14 @@ func (p *GovernancePlugin) loadBalanceProvider(body map[string]any, virtualKey *
 		} else {
 			isProviderAllowed = slices.Contains(config.AllowedModels, modelStr)
 		}
+		if isProviderAllowed && virtualKey.ResidencyPolicy != nil && !p.hasResidentKeys(schemas.ModelProvider(config.Provider), virtualKey.ResidencyPolicy) {
+			// Provider has no keys in the residency regions, skip this provider
+			continue
+		}
+		if isProviderAllowed && (!hints.allows(schemas.ModelProvider(config.Provider)) || !p.withinMaxCost(hints, schemas.ModelProvider(config.Provider), modelStr, body)) {
+			// Provider is excluded by the routing hints of the request, skip this provider
+			continue
+		}
 		if isProviderAllowed {
 			// Check if the provider's budget or rate limits are violated using resolver helper methods
 			if p.resolver.isProviderBudgetViolated(config) || p.resolver.isProviderRateLimitViolated(config) {
@@ -276,21 +397,27 @@ func (p *GovernancePlugin) loadBalanceProvider(body map[string]any, virtualKey *
 		// No allowed provider configs, continue without modification
 		return body, nil
 	}
-	// Weighted random selection from allowed providers for the main model
-	totalWeight := 0.0
-	for _, config := range allowedProviderConfigs {
-		totalWeight += config.Weight
-	}
-	// Generate random number between 0 and totalWeight
-	randomValue := rand.Float64() * totalWeight
-	// Select provider based on weighted random selection
+	// Providers ordered by the routing strategy of the request are tried in that order
+	ordered := p.orderByRoutingStrategy(allowedProviderConfigs, hints, modelStr, body)
 	var selectedProvider schemas.ModelProvider
-	currentWeight := 0.0
-	for _, config := range allowedProviderConfigs {
-		currentWeight += config.Weight
-		if randomValue <= currentWeight {
-			selectedProvider = schemas.ModelProvider(config.Provider)
-			break
+	if ordered {
+		selectedProvider = schemas.ModelProvider(allowedProviderConfigs[0].Provider)
+	} else {
+		// Weighted random selection from allowed providers for the main model
+		totalWeight := 0.0
+		for _, config := range allowedProviderConfigs {
+			totalWeight += config.Weight
+		}
+		// Generate random number between 0 and totalWeight
+		randomValue := rand.Float64() * totalWeight
+		// Select provider based on weighted random selection
+		currentWeight := 0.0
+		for _, config := range allowedProviderConfigs {
+			currentWeight += config.Weight
+			if randomValue <= currentWeight {
+				selectedProvider = schemas.ModelProvider(config.Provider)
+				break
+			}
 		}
 	}
 	// Fallback: if no provider was selected (shouldn't happen but guard against FP issues)
@@ -303,10 +430,12 @@ func (p *GovernancePlugin) loadBalanceProvider(body map[string]any, virtualKey *
 	// Check if fallbacks field is already present
 	_, hasFallbacks := body["fallbacks"]
 	if !hasFallbacks && len(allowedProviderConfigs) > 1 {
-		// Sort allowed provider configs by weight (descending)
-		sort.Slice(allowedProviderConfigs, func(i, j int) bool {
-			return allowedProviderConfigs[i].Weight > allowedProviderConfigs[j].Weight
-		})
+		// Sort allowed provider configs by weight (descending), unless ordered by the routing strategy
+		if !ordered {
+			sort.Slice(allowedProviderConfigs, func(i, j int) bool {
+				return allowedProviderConfigs[i].Weight > allowedProviderConfigs[j].Weight
+			})
+		}
 
 		// Filter out the selected provider and create fallbacks array
 		fallbacks := make([]string, 0, len(allowedProviderConfigs)-1)
@@ -375,6 +504,13 @@ func (p *GovernancePlugin) addMCPIncludeTools(headers map[string]string, virtual
 //   - *schemas.PluginShortCircuit: The plugin short circuit if the request is not allowed
 //   - error: Any error that occurred during processing
 func (p *GovernancePlugin) PreHook(ctx *schemas.BifrostContext, req *schemas.BifrostRequest) (*schemas.BifrostRequest, *schemas.PluginShortCircuit, error) {
+	p.excludeExhaustedTestKeys(ctx)
+
+	// Routing hints apply to every request and fallback, with or without a virtual key
+	if shortCircuit := p.enforceRoutingHints(ctx, req); shortCircuit != nil {
+		return req, shortCircuit, nil
+	}
+
 	// Extract governance headers and virtual key using utility functions
 	virtualKeyValue := getStringFromContext(ctx, schemas.BifrostContextKeyVirtualKey)
 	requestID := getStringFromContext(ctx, schemas.BifrostContextKeyRequestID)
@@ -394,6 +530,11 @@ func (p *GovernancePlugin) PreHook(ctx *schemas.BifrostContext, req *schemas.Bif
 		}
 	}
 
+	// Deduplicate redelivered events before evaluating limits, so a replay is neither charged nor rate limited
+	if shortCircuit := p.deduplicateEvent(ctx, req, virtualKeyValue); shortCircuit != nil {
+		return req, shortCircuit, nil
+	}
+
 	provider, model, _ := req.GetRequestFields()
 
 	// Create request context for evaluation
@@ -407,20 +548,39 @@ func (p *GovernancePlugin) PreHook(ctx *schemas.BifrostContext, req *schemas.Bif
 	// Use resolver to make governance decision (pure decision engine)
 	result := p.resolver.EvaluateRequest(ctx, evaluationRequest)
 
+	// Requests of a virtual key with a residency policy never leave its regions
+	if result.Decision == DecisionAllow && result.VirtualKey != nil {
+		result.Decision, result.Reason = p.enforceResidency(ctx, provider, result.VirtualKey)
+	}
+	// The end user limits of the virtual key apply on top of its own limits
+	if result.Decision == DecisionAllow && result.VirtualKey != nil && result.VirtualKey.EndUserLimits != nil {
+		if endUser := getStringFromContext(ctx, schemas.BifrostContextKeyEndUser); endUser != "" {
+			result.Decision, result.Reason = p.endUsers.Admit(result.VirtualKey.ID, endUser, result.VirtualKey.EndUserLimits)
+		}
+	}
+
 	if result.Decision != DecisionAllow {
 		if ctx != nil {
 			if _, ok := (*ctx).Value(governanceRejectedContextKey).(bool); !ok {
 				ctx.SetValue(governanceRejectedContextKey, true)
 			}
+			// A rejected event was never processed, so its redelivery must go through
+			if key, ok := (*ctx).Value(governanceDedupeKeyContextKey).(string); ok {
+				p.deduplicator.Release(key)
+			}
 		}
 	}
 
 	// Handle decision
 	switch result.Decision {
 	case DecisionAllow:
-		return req, nil, nil
+		req, shortCircuit := p.enforceSystemPromptPolicy(ctx, req, virtualKeyValue)
+		if shortCircuit != nil {
+			return req, shortCircuit, nil
+		}
+		return p.clampMaxOutputTokens(req, virtualKeyValue), nil, nil
 
-	case DecisionVirtualKeyNotFound, DecisionVirtualKeyBlocked, DecisionModelBlocked, DecisionProviderBlocked:
+	case DecisionVirtualKeyNotFound, DecisionVirtualKeyBlocked, DecisionModelBlocked, DecisionProviderBlocked, DecisionResidencyViolation:
 		return req, &schemas.PluginShortCircuit{
 			Error: &schemas.BifrostError{
 				Type:       bifrost.Ptr(string(result.Decision)),
@@ -466,6 +626,60 @@ func (p *GovernancePlugin) PreHook(ctx *schemas.BifrostContext, req *schemas.Bif
 	}
 }
 
+// deduplicateEvent short-circuits requests repeating an x-bf-event-id already seen for the virtual key
+// within its dedupe window. Duplicates of a completed non-streaming request get the original response,
+// all other duplicates are rejected with 409.
+func (p *GovernancePlugin) deduplicateEvent(ctx *schemas.BifrostContext, req *schemas.BifrostRequest, virtualKeyValue string) *schemas.PluginShortCircuit {
+	if ctx == nil {
+		return nil
+	}
+	eventID := getStringFromContext(ctx, schemas.BifrostContextKeyEventID)
+	if eventID == "" {
+		return nil
+	}
+	vk, exists := p.store.GetVirtualKey(virtualKeyValue)
+	if !exists || !vk.IsActive || vk.DedupeWindow == nil || *vk.DedupeWindow == "" {
+		return nil
+	}
+	window, err := configstoreTables.ParseDuration(*vk.DedupeWindow)
+	if err != nil || window <= 0 {
+		p.logger.Warn("invalid dedupe window %s for virtual key %s, skipping deduplication", *vk.DedupeWindow, vk.ID)
+		return nil
+	}
+	key := dedupeKey(vk.ID, eventID)
+	// Fallback attempts of the request owning the event are not duplicates
+	if ownedKey, ok := (*ctx).Value(governanceDedupeKeyContextKey).(string); ok && ownedKey == key {
+		p.deduplicator.Reserve(key, window)
+		return nil
+	}
+	entry, duplicate := p.deduplicator.Begin(key, window)
+	if !duplicate {
+		ctx.SetValue(governanceDedupeKeyContextKey, key)
+		return nil
+	}
+	ctx.SetValue(governanceIsDuplicateContextKey, true)
+	if entry.completed && !bifrost.IsStreamRequestType(req.RequestType) {
+		if response := entry.replayResponse(); response != nil {
+			return &schemas.PluginShortCircuit{
+				Response: response,
+			}
+		}
+	}
+	message := fmt.Sprintf("event %s is already being processed", eventID)
+	if entry.completed {
+		message = fmt.Sprintf("event %s has already been processed", eventID)
+	}
+	return &schemas.PluginShortCircuit{
+		Error: &schemas.BifrostError{
+			Type:       bifrost.Ptr("duplicate_event"),
+			StatusCode: bifrost.Ptr(409),
+			Error: &schemas.ErrorField{
+				Message: message,
+			},
+		},
+	}
+}
+
 // PostHook processes the response and updates usage tracking (business logic execution)
 // Parameters:
 //   - ctx: The Bifrost context
@@ -480,10 +694,22 @@ func (p *GovernancePlugin) PostHook(ctx *schemas.BifrostContext, result *schemas
 	if _, ok := ctx.Value(governanceRejectedContextKey).(bool); ok {
 		return result, err, nil
 	}
+	// Replayed duplicates were already accounted for by the original request
+	if _, ok := ctx.Value(governanceIsDuplicateContextKey).(bool); ok {
+		return result, err, nil
+	}
+
+	if policy, ok := ctx.Value(governanceSystemPromptPolicyContextKey).(*schemas.BifrostSystemPromptPolicy); ok && result != nil {
+		result.GetExtraFields().SystemPromptPolicy = policy
+	}
+
+	// Test key spend is tracked with or without a virtual key
+	p.trackTestKeySpend(ctx, result)
 
 	// Extract governance information
 	virtualKey := getStringFromContext(ctx, schemas.BifrostContextKeyVirtualKey)
 	requestID := getStringFromContext(ctx, schemas.BifrostContextKeyRequestID)
+	endUser := getStringFromContext(ctx, schemas.BifrostContextKeyEndUser)
 
 	// Skip if no virtual key
 	if virtualKey == "" {
@@ -493,6 +719,18 @@ func (p *GovernancePlugin) PostHook(ctx *schemas.BifrostContext, result *schemas
 	// Extract request type, provider, and model
 	requestType, provider, model := bifrost.GetResponseFields(result, err)
 
+	if key, ok := ctx.Value(governanceDedupeKeyContextKey).(string); ok {
+		if err != nil {
+			// Failed events are forgotten so that the upstream redelivery is processed
+			p.deduplicator.Release(key)
+		} else if !bifrost.IsStreamRequestType(requestType) {
+			p.deduplicator.Complete(key, result)
+		} else if bifrost.IsFinalChunk(ctx) {
+			// Streams cannot be replayed, duplicates of a completed stream are rejected
+			p.deduplicator.Complete(key, nil)
+		}
+	}
+
 	// Extract cache and batch flags from context
 	isCacheRead := false
 	isBatch := false
@@ -510,7 +748,7 @@ func (p *GovernancePlugin) PostHook(ctx *schemas.BifrostContext, result *schemas
 	p.wg.Add(1)
 	go func() {
 		defer p.wg.Done()
-		p.postHookWorker(result, provider, model, requestType, virtualKey, requestID, isCacheRead, isBatch, bifrost.IsFinalChunk(ctx))
+		p.postHookWorker(result, provider, model, requestType, virtualKey, requestID, endUser, isCacheRead, isBatch, bifrost.IsFinalChunk(ctx))
 	}()
 
 	return result, err, nil
@@ -525,6 +763,11 @@ func (p *GovernancePlugin) Cleanup() error {
 	if err := p.tracker.Cleanup(); err != nil {
 		return err
 	}
+	if p.store.counters != nil {
+		if err := p.store.counters.close(); err != nil {
+			return fmt.Errorf("failed to close shared counters: %w", err)
+		}
+	}
 
 	return nil
 }
@@ -538,10 +781,11 @@ func (p *GovernancePlugin) Cleanup() error {
 //   - requestType: The type of the request
 //   - virtualKey: The virtual key of the request
 //   - requestID: The request ID
+//   - endUser: The end user of the request, empty without x-bf-user or user parameter
 //   - isCacheRead: Whether the request is a cache read
 //   - isBatch: Whether the request is a batch request
 //   - isFinalChunk: Whether the request is the final chunk
-func (p *GovernancePlugin) postHookWorker(result *schemas.BifrostResponse, provider schemas.ModelProvider, model string, requestType schemas.RequestType, virtualKey, requestID string, _, _, isFinalChunk bool) {
+func (p *GovernancePlugin) postHookWorker(result *schemas.BifrostResponse, provider schemas.ModelProvider, model string, requestType schemas.RequestType, virtualKey, requestID, endUser string, _, _, isFinalChunk bool) {
 	// Determine if request was successful
 	success := (result != nil)
 
@@ -592,6 +836,12 @@ func (p *GovernancePlugin) postHookWorker(result *schemas.BifrostResponse, provi
 
 		// Queue usage update asynchronously using tracker
 		p.tracker.UpdateUsage(p.ctx, usageUpdate)
+
+		if endUser != "" {
+			if vk, ok := p.store.GetVirtualKey(virtualKey); ok && vk.EndUserLimits != nil {
+				p.endUsers.Record(vk.ID, endUser, vk.EndUserLimits, int64(tokensUsed), cost)
+			}
+		}
 	}
 }
 
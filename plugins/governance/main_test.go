@@ -0,0 +1,142 @@
+package governance
+
+import (
+	"reflect"
+	"testing"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+)
+
+// TestApplyVirtualKeyDefaults tests that the default params only fill in what the client omitted, except the
+// temperature ceiling clamping the temperature of the client
+func TestApplyVirtualKeyDefaults(t *testing.T) {
+	var responseFormat interface{} = map[string]any{"type": "json_object"}
+	params := &configstoreTables.VirtualKeyDefaultParams{
+		Model:          bifrost.Ptr("openai/gpt-4o-mini"),
+		MaxTemperature: bifrost.Ptr(0.5),
+		ResponseFormat: &responseFormat,
+	}
+
+	tests := map[string]struct {
+		params   *configstoreTables.VirtualKeyDefaultParams
+		body     map[string]any
+		expected map[string]any
+	}{
+		"empty body": {
+			params:   params,
+			body:     nil,
+			expected: map[string]any{"model": "openai/gpt-4o-mini", "response_format": responseFormat},
+		},
+		"client values kept": {
+			params:   params,
+			body:     map[string]any{"model": "anthropic/claude-3-5-sonnet", "temperature": 0.2, "response_format": map[string]any{"type": "text"}},
+			expected: map[string]any{"model": "anthropic/claude-3-5-sonnet", "temperature": 0.2, "response_format": map[string]any{"type": "text"}},
+		},
+		"temperature clamped": {
+			params:   params,
+			body:     map[string]any{"model": "openai/gpt-4o", "temperature": 1.2},
+			expected: map[string]any{"model": "openai/gpt-4o", "temperature": 0.5, "response_format": responseFormat},
+		},
+		"empty model filled in": {
+			params:   &configstoreTables.VirtualKeyDefaultParams{Model: bifrost.Ptr("openai/gpt-4o-mini")},
+			body:     map[string]any{"model": "", "temperature": 1.2},
+			expected: map[string]any{"model": "openai/gpt-4o-mini", "temperature": 1.2},
+		},
+		"no default params": {
+			params:   nil,
+			body:     map[string]any{"temperature": 1.2},
+			expected: map[string]any{"temperature": 1.2},
+		},
+	}
+	p := &GovernancePlugin{}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := p.applyVirtualKeyDefaults("/v1/chat/completions", test.body, &configstoreTables.TableVirtualKey{DefaultParams: test.params})
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+// TestApplyVirtualKeyDefaults_SystemPrompt tests that the pinned system prompt is applied, unless a system prompt
+// policy supersedes it
+func TestApplyVirtualKeyDefaults_SystemPrompt(t *testing.T) {
+	p := &GovernancePlugin{}
+	body := func() map[string]any {
+		return map[string]any{"messages": []any{map[string]any{"role": "user", "content": "hi"}}}
+	}
+
+	vk := &configstoreTables.TableVirtualKey{SystemPrompt: bifrost.Ptr("Be brief.")}
+	messages := p.applyVirtualKeyDefaults("/v1/chat/completions", body(), vk)["messages"].([]any)
+	if len(messages) != 2 || messages[0].(map[string]any)["content"] != "Be brief." {
+		t.Errorf("Expected the pinned system prompt first, got %v", messages)
+	}
+
+	vk.SystemPromptPolicy = &configstoreTables.SystemPromptPolicy{Mode: configstoreTables.SystemPromptPolicyModePrepend, Prompt: "Policy."}
+	if messages := p.applyVirtualKeyDefaults("/v1/chat/completions", body(), vk)["messages"].([]any); len(messages) != 1 {
+		t.Errorf("Expected the policy to supersede the pinned system prompt, got %v", messages)
+	}
+}
+
+// TestApplySystemPrompt tests that the pinned system prompt is set where each API carries it, and only when the
+// client sent none
+func TestApplySystemPrompt(t *testing.T) {
+	user := map[string]any{"role": "user", "content": "hi"}
+	pinned := map[string]any{"role": "system", "content": "Be brief."}
+
+	tests := map[string]struct {
+		url      string
+		body     map[string]any
+		expected map[string]any
+	}{
+		"chat": {
+			url:      "/v1/chat/completions",
+			body:     map[string]any{"messages": []any{user}},
+			expected: map[string]any{"messages": []any{pinned, user}},
+		},
+		"chat with system message": {
+			url:      "/v1/chat/completions",
+			body:     map[string]any{"messages": []any{map[string]any{"role": "system", "content": "Be verbose."}, user}},
+			expected: map[string]any{"messages": []any{map[string]any{"role": "system", "content": "Be verbose."}, user}},
+		},
+		"chat with developer message": {
+			url:      "/v1/chat/completions",
+			body:     map[string]any{"messages": []any{map[string]any{"role": "developer", "content": "Be verbose."}, user}},
+			expected: map[string]any{"messages": []any{map[string]any{"role": "developer", "content": "Be verbose."}, user}},
+		},
+		"anthropic": {
+			url:      "/anthropic/v1/messages",
+			body:     map[string]any{"messages": []any{user}},
+			expected: map[string]any{"messages": []any{user}, "system": "Be brief."},
+		},
+		"anthropic with system": {
+			url:      "/anthropic/v1/messages",
+			body:     map[string]any{"messages": []any{user}, "system": "Be verbose."},
+			expected: map[string]any{"messages": []any{user}, "system": "Be verbose."},
+		},
+		"responses": {
+			url:      "/v1/responses",
+			body:     map[string]any{"input": "hi"},
+			expected: map[string]any{"input": "hi", "instructions": "Be brief."},
+		},
+		"responses with instructions": {
+			url:      "/v1/responses",
+			body:     map[string]any{"input": "hi", "instructions": "Be verbose."},
+			expected: map[string]any{"input": "hi", "instructions": "Be verbose."},
+		},
+		"embedding": {
+			url:      "/v1/embeddings",
+			body:     map[string]any{"model": "openai/text-embedding-3-small"},
+			expected: map[string]any{"model": "openai/text-embedding-3-small"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := applySystemPrompt(test.url, test.body, "Be brief."); !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
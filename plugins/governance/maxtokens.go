@@ -0,0 +1,85 @@
+package governance
+
+import (
+	"github.com/maximhq/bifrost/core/schemas"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+)
+
+// maxOutputTokens returns the output token cap of the virtual key, falling back to the cap of its team, nil when the
+// requests of the key are not capped
+func (p *GovernancePlugin) maxOutputTokens(vk *configstoreTables.TableVirtualKey) *int {
+	if vk.MaxOutputTokens != nil {
+		return vk.MaxOutputTokens
+	}
+	if vk.TeamID == nil {
+		return nil
+	}
+	team, ok := p.store.GetTeam(*vk.TeamID)
+	if !ok {
+		return nil
+	}
+	return team.MaxOutputTokens
+}
+
+// clampMaxOutputTokens caps the output tokens of text completion, chat and responses requests of the virtual key.
+// Requests asking for more tokens than the cap, or not bounding them, are sent with the cap. The request is copied,
+// so that fallbacks running the PreHook again start from the parameters sent by the client.
+func (p *GovernancePlugin) clampMaxOutputTokens(req *schemas.BifrostRequest, virtualKeyValue string) *schemas.BifrostRequest {
+	vk, ok := p.store.GetVirtualKey(virtualKeyValue)
+	if !ok {
+		return req
+	}
+	limit := p.maxOutputTokens(vk)
+	if limit == nil || *limit <= 0 {
+		return req
+	}
+
+	clamped := *req
+	switch {
+	case req.TextCompletionRequest != nil:
+		params := schemas.TextCompletionParameters{}
+		if req.TextCompletionRequest.Params != nil {
+			params = *req.TextCompletionRequest.Params
+		}
+		if !clampTokens(&params.MaxTokens, *limit) {
+			return req
+		}
+		textReq := *req.TextCompletionRequest
+		textReq.Params = &params
+		clamped.TextCompletionRequest = &textReq
+	case req.ChatRequest != nil:
+		params := schemas.ChatParameters{}
+		if req.ChatRequest.Params != nil {
+			params = *req.ChatRequest.Params
+		}
+		if !clampTokens(&params.MaxCompletionTokens, *limit) {
+			return req
+		}
+		chatReq := *req.ChatRequest
+		chatReq.Params = &params
+		clamped.ChatRequest = &chatReq
+	case req.ResponsesRequest != nil:
+		params := schemas.ResponsesParameters{}
+		if req.ResponsesRequest.Params != nil {
+			params = *req.ResponsesRequest.Params
+		}
+		if !clampTokens(&params.MaxOutputTokens, *limit) {
+			return req
+		}
+		responsesReq := *req.ResponsesRequest
+		responsesReq.Params = &params
+		clamped.ResponsesRequest = &responsesReq
+	default:
+		return req
+	}
+	return &clamped
+}
+
+// clampTokens sets the token parameter to the limit when it is unset or above it, and reports whether it changed
+func clampTokens(tokens **int, limit int) bool {
+	if *tokens != nil && **tokens <= limit {
+		return false
+	}
+	*tokens = &limit
+	return true
+}
@@ -0,0 +1,133 @@
+package governance
+
+import (
+	"context"
+	"testing"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+)
+
+// newMaxTokensTestPlugin creates a governance plugin over a team capped at 500 output tokens, with a virtual key
+// capped at 100 tokens in the team and one without a cap of its own in the team
+func newMaxTokensTestPlugin(t *testing.T) *GovernancePlugin {
+	t.Helper()
+	store, err := NewGovernanceStore(context.Background(), bifrost.NewDefaultLogger(schemas.LogLevelError), nil, &configstore.GovernanceConfig{
+		Teams: []configstoreTables.TableTeam{{ID: "team1", Name: "search", MaxOutputTokens: bifrost.Ptr(500)}},
+		VirtualKeys: []configstoreTables.TableVirtualKey{
+			{ID: "vk1", Name: "capped", Value: "sk-bf-capped", IsActive: true, TeamID: bifrost.Ptr("team1"), MaxOutputTokens: bifrost.Ptr(100)},
+			{ID: "vk2", Name: "team", Value: "sk-bf-team", IsActive: true, TeamID: bifrost.Ptr("team1")},
+			{ID: "vk3", Name: "uncapped", Value: "sk-bf-uncapped", IsActive: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create governance store: %v", err)
+	}
+	return &GovernancePlugin{store: store}
+}
+
+// TestMaxOutputTokens tests that the cap of the virtual key takes precedence over the cap of its team
+func TestMaxOutputTokens(t *testing.T) {
+	p := newMaxTokensTestPlugin(t)
+	tests := map[string]struct {
+		virtualKey string
+		expected   *int
+	}{
+		"virtual key cap":   {virtualKey: "sk-bf-capped", expected: bifrost.Ptr(100)},
+		"team cap fallback": {virtualKey: "sk-bf-team", expected: bifrost.Ptr(500)},
+		"no cap":            {virtualKey: "sk-bf-uncapped", expected: nil},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			vk, _ := p.store.GetVirtualKey(test.virtualKey)
+			got := p.maxOutputTokens(vk)
+			if (got == nil) != (test.expected == nil) || (got != nil && *got != *test.expected) {
+				t.Errorf("Expected cap %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+// TestClampTokens tests that the token parameter is set to the limit when it is unset or above it only
+func TestClampTokens(t *testing.T) {
+	tests := map[string]struct {
+		tokens   *int
+		expected int
+		changed  bool
+	}{
+		"unset":  {tokens: nil, expected: 100, changed: true},
+		"lower":  {tokens: bifrost.Ptr(50), expected: 50, changed: false},
+		"equal":  {tokens: bifrost.Ptr(100), expected: 100, changed: false},
+		"higher": {tokens: bifrost.Ptr(4096), expected: 100, changed: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tokens := test.tokens
+			if changed := clampTokens(&tokens, 100); changed != test.changed {
+				t.Errorf("Expected changed %v, got %v", test.changed, changed)
+			}
+			if tokens == nil || *tokens != test.expected {
+				t.Errorf("Expected %d tokens, got %v", test.expected, tokens)
+			}
+		})
+	}
+}
+
+// TestClampMaxOutputTokens tests that the output tokens of text completion, chat and responses requests are capped
+// on a copy of the request, leaving the request of the client untouched
+func TestClampMaxOutputTokens(t *testing.T) {
+	p := newMaxTokensTestPlugin(t)
+	textRequest := func(tokens *int) *schemas.BifrostRequest {
+		return &schemas.BifrostRequest{TextCompletionRequest: &schemas.BifrostTextCompletionRequest{Params: &schemas.TextCompletionParameters{MaxTokens: tokens}}}
+	}
+	chatRequest := func(tokens *int) *schemas.BifrostRequest {
+		return &schemas.BifrostRequest{ChatRequest: &schemas.BifrostChatRequest{Params: &schemas.ChatParameters{MaxCompletionTokens: tokens}}}
+	}
+	responsesRequest := func(tokens *int) *schemas.BifrostRequest {
+		return &schemas.BifrostRequest{ResponsesRequest: &schemas.BifrostResponsesRequest{Params: &schemas.ResponsesParameters{MaxOutputTokens: tokens}}}
+	}
+	outputTokens := func(req *schemas.BifrostRequest) *int {
+		switch {
+		case req.TextCompletionRequest != nil:
+			return req.TextCompletionRequest.Params.MaxTokens
+		case req.ChatRequest != nil:
+			return req.ChatRequest.Params.MaxCompletionTokens
+		default:
+			return req.ResponsesRequest.Params.MaxOutputTokens
+		}
+	}
+
+	tests := map[string]struct {
+		request    func(tokens *int) *schemas.BifrostRequest
+		virtualKey string
+		tokens     *int
+		expected   *int
+	}{
+		"text completion unset":  {request: textRequest, virtualKey: "sk-bf-capped", tokens: nil, expected: bifrost.Ptr(100)},
+		"text completion lower":  {request: textRequest, virtualKey: "sk-bf-capped", tokens: bifrost.Ptr(50), expected: bifrost.Ptr(50)},
+		"text completion higher": {request: textRequest, virtualKey: "sk-bf-capped", tokens: bifrost.Ptr(4096), expected: bifrost.Ptr(100)},
+		"chat unset":             {request: chatRequest, virtualKey: "sk-bf-capped", tokens: nil, expected: bifrost.Ptr(100)},
+		"chat lower":             {request: chatRequest, virtualKey: "sk-bf-capped", tokens: bifrost.Ptr(50), expected: bifrost.Ptr(50)},
+		"chat higher":            {request: chatRequest, virtualKey: "sk-bf-capped", tokens: bifrost.Ptr(4096), expected: bifrost.Ptr(100)},
+		"responses unset":        {request: responsesRequest, virtualKey: "sk-bf-capped", tokens: nil, expected: bifrost.Ptr(100)},
+		"responses lower":        {request: responsesRequest, virtualKey: "sk-bf-capped", tokens: bifrost.Ptr(50), expected: bifrost.Ptr(50)},
+		"responses higher":       {request: responsesRequest, virtualKey: "sk-bf-capped", tokens: bifrost.Ptr(4096), expected: bifrost.Ptr(100)},
+		"team cap":               {request: chatRequest, virtualKey: "sk-bf-team", tokens: bifrost.Ptr(4096), expected: bifrost.Ptr(500)},
+		"no cap":                 {request: chatRequest, virtualKey: "sk-bf-uncapped", tokens: bifrost.Ptr(4096), expected: bifrost.Ptr(4096)},
+		"unknown virtual key":    {request: chatRequest, virtualKey: "sk-bf-unknown", tokens: nil, expected: nil},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := test.request(test.tokens)
+			got := outputTokens(p.clampMaxOutputTokens(req, test.virtualKey))
+			if (got == nil) != (test.expected == nil) || (got != nil && *got != *test.expected) {
+				t.Errorf("Expected %v output tokens, got %v", test.expected, got)
+			}
+			if original := outputTokens(req); original != test.tokens {
+				t.Errorf("Expected the request of the client to be left untouched, got %v", original)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Package governance provides the rate limit response headers of the governance plugin
+package governance
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+)
+
+// rateLimitWindow is the state of one limit of a virtual key, reported by the rate limit headers
+type rateLimitWindow struct {
+	Limit     float64
+	Remaining float64
+	Reset     time.Duration // Time until the limit resets, zero when unknown or already due
+}
+
+// newRateLimitWindow returns the window of a limit with its usage since its last reset. A limit past its reset
+// duration but not yet reset by the store is reported as unused.
+func newRateLimitWindow(limit, usage float64, resetDuration string, lastReset time.Time) rateLimitWindow {
+	window := rateLimitWindow{Limit: limit, Remaining: math.Max(limit-usage, 0)}
+	duration, err := configstoreTables.ParseDuration(resetDuration)
+	if err != nil {
+		return window
+	}
+	if reset := time.Until(lastReset.Add(duration)); reset > 0 {
+		window.Reset = reset.Round(time.Second)
+	} else {
+		window.Remaining = limit
+	}
+	return window
+}
+
+// tightest returns the window with the least remaining of the two, the other when one is nil
+func tightest(current *rateLimitWindow, candidate rateLimitWindow) *rateLimitWindow {
+	if current == nil || candidate.Remaining < current.Remaining {
+		return &candidate
+	}
+	return current
+}
+
+// RateLimitHeaders returns the x-ratelimit-* response headers of a request, from the request and token limits of
+// its virtual key and of the provider config of the request, and the budgets of the virtual key, its team and its
+// customer. Each header reports the limit with the least remaining, like the headers of the providers, so that
+// clients can throttle themselves before being rejected. The x-ratelimit-limit, x-ratelimit-remaining and
+// x-ratelimit-reset headers repeat the request limit, or the token limit when there is none, with the reset in
+// seconds. Returns nil for requests without a virtual key or whose virtual key has no limits.
+// The headers report the in-memory usage the limits are enforced on: with shared counters, the usage of the other
+// replicas is included as of the last sync, so it may lag by up to the sync interval.
+// Parameters:
+//   - headers: The headers of the request
+//   - body: The body of the request, after the transport interceptors
+func (p *GovernancePlugin) RateLimitHeaders(headers map[string]string, body map[string]any) map[string]string {
+	virtualKeyValue := virtualKeyFromHeaders(headers)
+	if virtualKeyValue == "" {
+		return nil
+	}
+	vk, ok := p.store.GetVirtualKey(virtualKeyValue)
+	if !ok || vk == nil {
+		return nil
+	}
+	var provider schemas.ModelProvider
+	if modelStr, ok := body["model"].(string); ok {
+		provider, _ = schemas.ParseModelString(modelStr, "")
+	}
+
+	var requests, tokens, budget *rateLimitWindow
+	rateLimits := []*configstoreTables.TableRateLimit{vk.RateLimit}
+	for _, pc := range vk.ProviderConfigs {
+		if schemas.ModelProvider(pc.Provider) == provider {
+			rateLimits = append(rateLimits, pc.RateLimit)
+		}
+	}
+	for _, rateLimit := range rateLimits {
+		if rateLimit == nil {
+			continue
+		}
+		if rateLimit.RequestMaxLimit != nil && rateLimit.RequestResetDuration != nil {
+			requests = tightest(requests, newRateLimitWindow(float64(*rateLimit.RequestMaxLimit), float64(rateLimit.RequestCurrentUsage), *rateLimit.RequestResetDuration, rateLimit.RequestLastReset))
+		}
+		if rateLimit.TokenMaxLimit != nil && rateLimit.TokenResetDuration != nil {
+			tokens = tightest(tokens, newRateLimitWindow(float64(*rateLimit.TokenMaxLimit), float64(rateLimit.TokenCurrentUsage), *rateLimit.TokenResetDuration, rateLimit.TokenLastReset))
+		}
+	}
+	// Budgets of provider configs are named after their provider, only the budget of the request provider applies
+	budgets, budgetNames := p.store.collectBudgetsFromHierarchy(context.Background(), vk)
+	for i, b := range budgets {
+		switch budgetNames[i] {
+		case "VK", "Team", "Customer", string(provider):
+			budget = tightest(budget, newRateLimitWindow(b.MaxLimit, b.CurrentUsage, b.ResetDuration, b.LastReset))
+		}
+	}
+
+	if requests == nil && tokens == nil && budget == nil {
+		return nil
+	}
+	rateLimitHeaders := make(map[string]string)
+	setWindowHeaders := func(suffix string, window *rateLimitWindow) {
+		rateLimitHeaders["x-ratelimit-limit-"+suffix] = strconv.FormatFloat(window.Limit, 'f', -1, 64)
+		rateLimitHeaders["x-ratelimit-remaining-"+suffix] = strconv.FormatFloat(window.Remaining, 'f', -1, 64)
+		rateLimitHeaders["x-ratelimit-reset-"+suffix] = window.Reset.String()
+	}
+	if requests != nil {
+		setWindowHeaders("requests", requests)
+	}
+	if tokens != nil {
+		setWindowHeaders("tokens", tokens)
+	}
+	if budget != nil {
+		setWindowHeaders("budget", budget)
+	}
+	primary := requests
+	if primary == nil {
+		primary = tokens
+	}
+	if primary != nil {
+		rateLimitHeaders["x-ratelimit-limit"] = strconv.FormatFloat(primary.Limit, 'f', -1, 64)
+		rateLimitHeaders["x-ratelimit-remaining"] = strconv.FormatFloat(primary.Remaining, 'f', -1, 64)
+		rateLimitHeaders["x-ratelimit-reset"] = strconv.FormatInt(int64(math.Ceil(primary.Reset.Seconds())), 10)
+	}
+	return rateLimitHeaders
+}
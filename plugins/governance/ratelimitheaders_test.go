@@ -0,0 +1,128 @@
+package governance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+)
+
+// TestRateLimitHeaders tests that each header reports the limit with the least remaining among the virtual key, the
+// provider config of the request and the budgets of the hierarchy, with its remaining and reset
+func TestRateLimitHeaders(t *testing.T) {
+	now := time.Now()
+	vk := configstoreTables.TableVirtualKey{
+		ID: "vk1", Name: "app", Value: "sk-bf-app", IsActive: true,
+		TeamID:   bifrost.Ptr("team1"),
+		BudgetID: bifrost.Ptr("vk-budget"),
+		RateLimit: &configstoreTables.TableRateLimit{
+			ID:                   "vk-limit",
+			RequestMaxLimit:      bifrost.Ptr(int64(100)),
+			RequestResetDuration: bifrost.Ptr("1m"),
+			RequestCurrentUsage:  10,
+			RequestLastReset:     now.Add(-30 * time.Second),
+			TokenMaxLimit:        bifrost.Ptr(int64(1000)),
+			TokenResetDuration:   bifrost.Ptr("1m"),
+			TokenCurrentUsage:    1200,
+			TokenLastReset:       now.Add(-50 * time.Second),
+		},
+		ProviderConfigs: []configstoreTables.TableVirtualKeyProviderConfig{{
+			Provider: string(schemas.OpenAI),
+			BudgetID: bifrost.Ptr("openai-budget"),
+			RateLimit: &configstoreTables.TableRateLimit{
+				ID:                   "openai-limit",
+				RequestMaxLimit:      bifrost.Ptr(int64(20)),
+				RequestResetDuration: bifrost.Ptr("1h"),
+				RequestCurrentUsage:  15,
+				RequestLastReset:     now.Add(-10 * time.Minute),
+			},
+		}},
+	}
+	store, err := NewGovernanceStore(context.Background(), bifrost.NewDefaultLogger(schemas.LogLevelError), nil, &configstore.GovernanceConfig{
+		Teams: []configstoreTables.TableTeam{{ID: "team1", Name: "search", BudgetID: bifrost.Ptr("team-budget")}},
+		Budgets: []configstoreTables.TableBudget{
+			{ID: "vk-budget", MaxLimit: 10, ResetDuration: "1d", CurrentUsage: 4, LastReset: now},
+			{ID: "team-budget", MaxLimit: 5, ResetDuration: "1d", CurrentUsage: 3, LastReset: now},
+			{ID: "openai-budget", MaxLimit: 2, ResetDuration: "1d", CurrentUsage: 1.5, LastReset: now},
+		},
+		VirtualKeys: []configstoreTables.TableVirtualKey{vk},
+	})
+	if err != nil {
+		t.Fatalf("failed to create governance store: %v", err)
+	}
+	p := &GovernancePlugin{store: store}
+
+	tests := map[string]struct {
+		model    string
+		expected map[string]string
+	}{
+		"provider config tighter": {
+			model: "openai/gpt-4o",
+			expected: map[string]string{
+				"x-ratelimit-limit-requests":     "20",
+				"x-ratelimit-remaining-requests": "5",
+				"x-ratelimit-reset-requests":     "50m0s",
+				"x-ratelimit-limit-tokens":       "1000",
+				"x-ratelimit-remaining-tokens":   "0",
+				"x-ratelimit-reset-tokens":       "10s",
+				"x-ratelimit-limit-budget":       "2",
+				"x-ratelimit-remaining-budget":   "0.5",
+				"x-ratelimit-limit":              "20",
+				"x-ratelimit-remaining":          "5",
+				"x-ratelimit-reset":              "3000",
+			},
+		},
+		"virtual key tighter": {
+			model: "anthropic/claude-3-5-sonnet",
+			expected: map[string]string{
+				"x-ratelimit-limit-requests":     "100",
+				"x-ratelimit-remaining-requests": "90",
+				"x-ratelimit-reset-requests":     "30s",
+				"x-ratelimit-limit-budget":       "5",
+				"x-ratelimit-remaining-budget":   "2",
+				"x-ratelimit-limit":              "100",
+				"x-ratelimit-remaining":          "90",
+				"x-ratelimit-reset":              "30",
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			headers := p.RateLimitHeaders(map[string]string{"x-bf-vk": "sk-bf-app"}, map[string]any{"model": test.model})
+			for header, expected := range test.expected {
+				if headers[header] != expected {
+					t.Errorf("Expected %s to be %s, got %q", header, expected, headers[header])
+				}
+			}
+		})
+	}
+
+	if headers := p.RateLimitHeaders(map[string]string{}, map[string]any{"model": "openai/gpt-4o"}); headers != nil {
+		t.Errorf("Expected no headers without virtual key, got %v", headers)
+	}
+}
+
+// TestNewRateLimitWindow tests the remaining and reset of a limit, and that a limit past its reset duration is
+// reported as unused until the store resets it
+func TestNewRateLimitWindow(t *testing.T) {
+	tests := map[string]struct {
+		usage     float64
+		lastReset time.Time
+		expected  rateLimitWindow
+	}{
+		"within window": {usage: 40, lastReset: time.Now().Add(-15 * time.Second), expected: rateLimitWindow{Limit: 100, Remaining: 60, Reset: 45 * time.Second}},
+		"exhausted":     {usage: 150, lastReset: time.Now().Add(-15 * time.Second), expected: rateLimitWindow{Limit: 100, Remaining: 0, Reset: 45 * time.Second}},
+		"past reset":    {usage: 150, lastReset: time.Now().Add(-2 * time.Minute), expected: rateLimitWindow{Limit: 100, Remaining: 100}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if window := newRateLimitWindow(100, test.usage, "1m", test.lastReset); window != test.expected {
+				t.Errorf("Expected %+v, got %+v", test.expected, window)
+			}
+		})
+	}
+}
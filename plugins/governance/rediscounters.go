@@ -0,0 +1,190 @@
+// Package governance provides the Redis store of the shared usage counters
+package governance
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultRedisCountersKeyPrefix is the prefix of the keys of the counters when none is configured
+const defaultRedisCountersKeyPrefix = "bifrost:governance:"
+
+// RedisCountersConfig configures the Redis store of the shared usage counters
+type RedisCountersConfig struct {
+	Addr      string `json:"addr"`                 // Redis server address (host:port) - REQUIRED
+	Username  string `json:"username,omitempty"`   // Username for Redis AUTH (optional)
+	Password  string `json:"password,omitempty"`   // Password for Redis AUTH (optional)
+	DB        int    `json:"db,omitempty"`         // Redis database number (default: 0)
+	PoolSize  int    `json:"pool_size,omitempty"`  // Maximum number of socket connections (optional)
+	KeyPrefix string `json:"key_prefix,omitempty"` // Prefix of the keys of the counters (default: "bifrost:governance:")
+}
+
+// redisAddScript adds to a counter hash holding its usage and the time of its last reset in milliseconds. A missing
+// counter starts from the seed, and a counter past its reset duration restarts from zero before the addition.
+// Values are returned as strings since Redis truncates Lua numbers to integers.
+var redisAddScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local delta = tonumber(ARGV[2])
+local duration = tonumber(ARGV[3])
+local usage = tonumber(redis.call('HGET', KEYS[1], 'usage') or ARGV[4])
+local reset = tonumber(redis.call('HGET', KEYS[1], 'reset') or ARGV[5])
+if duration > 0 and now - reset >= duration then
+	usage = 0
+	reset = now
+end
+usage = usage + delta
+redis.call('HSET', KEYS[1], 'usage', tostring(usage), 'reset', tostring(reset))
+if duration > 0 then
+	redis.call('PEXPIRE', KEYS[1], reset + duration - now + 60000)
+end
+return {tostring(usage), tostring(reset)}
+`)
+
+// redisOpenCircuitScript sets the time until which the circuit of a plugin is open in the circuits hash, unless a
+// replica already opened it for longer
+var redisOpenCircuitScript = redis.NewScript(`
+local current = tonumber(redis.call('HGET', KEYS[1], ARGV[1]) or '0')
+if tonumber(ARGV[2]) > current then
+	redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+end
+return 0
+`)
+
+// redisCircuitsKey is the key of the hash of the open circuits, relative to the key prefix
+const redisCircuitsKey = "circuits"
+
+// RedisCounterStore keeps the shared usage counters in Redis, each counter in a hash updated by a script so that
+// the replicas never lose an addition or reset a counter twice
+type RedisCounterStore struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+// NewRedisCounterStore creates a Redis counter store and checks that the server is reachable
+func NewRedisCounterStore(ctx context.Context, config RedisCountersConfig) (*RedisCounterStore, error) {
+	if config.Addr == "" {
+		return nil, fmt.Errorf("redis addr is required")
+	}
+	keyPrefix := config.KeyPrefix
+	if keyPrefix == "" {
+		keyPrefix = defaultRedisCountersKeyPrefix
+	}
+	store := &RedisCounterStore{
+		client: redis.NewClient(&redis.Options{
+			Addr:     config.Addr,
+			Username: config.Username,
+			Password: config.Password,
+			DB:       config.DB,
+			PoolSize: config.PoolSize,
+		}),
+		keyPrefix: keyPrefix,
+	}
+	if err := store.client.Ping(ctx).Err(); err != nil {
+		return store, fmt.Errorf("failed to ping redis: %w", err)
+	}
+	return store, nil
+}
+
+// Add adds delta to a counter and returns its state after the addition. A seed that was never reset starts its
+// reset duration now, so that its usage is kept instead of being reset right away.
+func (s *RedisCounterStore) Add(ctx context.Context, key string, delta float64, resetDuration time.Duration, seed CounterValue) (CounterValue, error) {
+	now := time.Now()
+	seedReset := seed.LastReset.UnixMilli()
+	if seed.LastReset.IsZero() {
+		seedReset = now.UnixMilli()
+	}
+	result, err := redisAddScript.Run(ctx, s.client, []string{s.keyPrefix + key},
+		now.UnixMilli(),
+		strconv.FormatFloat(delta, 'f', -1, 64),
+		resetDuration.Milliseconds(),
+		strconv.FormatFloat(seed.Usage, 'f', -1, 64),
+		seedReset,
+	).StringSlice()
+	if err != nil {
+		return CounterValue{}, fmt.Errorf("failed to add to counter %s: %w", key, err)
+	}
+	if len(result) != 2 {
+		return CounterValue{}, fmt.Errorf("unexpected result for counter %s: %v", key, result)
+	}
+	return parseRedisCounter(result[0], result[1])
+}
+
+// Get returns the state of the counters, counters never added to are missing from the result
+func (s *RedisCounterStore) Get(ctx context.Context, keys []string) (map[string]CounterValue, error) {
+	pipe := s.client.Pipeline()
+	commands := make([]*redis.SliceCmd, len(keys))
+	for i, key := range keys {
+		commands[i] = pipe.HMGet(ctx, s.keyPrefix+key, "usage", "reset")
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to get counters: %w", err)
+	}
+	values := make(map[string]CounterValue, len(keys))
+	for i, command := range commands {
+		fields := command.Val()
+		if len(fields) != 2 {
+			continue
+		}
+		usage, usageOK := fields[0].(string)
+		reset, resetOK := fields[1].(string)
+		if !usageOK || !resetOK {
+			continue
+		}
+		value, err := parseRedisCounter(usage, reset)
+		if err != nil {
+			return nil, err
+		}
+		values[keys[i]] = value
+	}
+	return values, nil
+}
+
+// OpenCircuit records that the circuit of a plugin is open until the given time, keeping the latest time
+func (s *RedisCounterStore) OpenCircuit(ctx context.Context, pluginName string, until time.Time) error {
+	if err := redisOpenCircuitScript.Run(ctx, s.client, []string{s.keyPrefix + redisCircuitsKey}, pluginName, until.UnixMilli()).Err(); err != nil {
+		return fmt.Errorf("failed to open circuit of %s: %w", pluginName, err)
+	}
+	return nil
+}
+
+// OpenCircuits returns the circuits still open on any replica with the time until which they are open
+func (s *RedisCounterStore) OpenCircuits(ctx context.Context) (map[string]time.Time, error) {
+	fields, err := s.client.HGetAll(ctx, s.keyPrefix+redisCircuitsKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get circuits: %w", err)
+	}
+	now := time.Now()
+	circuits := make(map[string]time.Time, len(fields))
+	for pluginName, field := range fields {
+		untilValue, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid circuit of %s %q: %w", pluginName, field, err)
+		}
+		if until := time.UnixMilli(untilValue); until.After(now) {
+			circuits[pluginName] = until
+		}
+	}
+	return circuits, nil
+}
+
+// Close closes the connections to Redis
+func (s *RedisCounterStore) Close() error {
+	return s.client.Close()
+}
+
+// parseRedisCounter parses the usage and last reset in milliseconds of a counter hash
+func parseRedisCounter(usage, reset string) (CounterValue, error) {
+	usageValue, err := strconv.ParseFloat(usage, 64)
+	if err != nil {
+		return CounterValue{}, fmt.Errorf("invalid counter usage %q: %w", usage, err)
+	}
+	resetValue, err := strconv.ParseFloat(reset, 64)
+	if err != nil {
+		return CounterValue{}, fmt.Errorf("invalid counter reset %q: %w", reset, err)
+	}
+	return CounterValue{Usage: usageValue, LastReset: time.UnixMilli(int64(resetValue))}, nil
+}
@@ -0,0 +1,145 @@
+package governance
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+// newTestRedisCounterStore creates a Redis counter store under a key prefix of its own, on the server of REDIS_ADDR
+// (localhost:6379 by default). The test is skipped when the server cannot be reached.
+func newTestRedisCounterStore(t *testing.T) *RedisCounterStore {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+	ctx := context.Background()
+	store, err := NewRedisCounterStore(ctx, RedisCountersConfig{
+		Addr:      addr,
+		Username:  os.Getenv("REDIS_USERNAME"),
+		Password:  os.Getenv("REDIS_PASSWORD"),
+		KeyPrefix: fmt.Sprintf("bifrost:governance:test:%d:", time.Now().UnixNano()),
+	})
+	if err != nil {
+		if store != nil {
+			store.Close()
+		}
+		t.Skipf("Could not connect to Redis: %v", err)
+	}
+	t.Cleanup(func() {
+		if keys, err := store.client.Keys(ctx, store.keyPrefix+"*").Result(); err == nil && len(keys) > 0 {
+			store.client.Del(ctx, keys...)
+		}
+		store.Close()
+	})
+	return store
+}
+
+// TestRedisCounterStore_Add tests that additions accumulate on the seed of a missing counter, and that a counter
+// past its reset duration restarts from zero
+func TestRedisCounterStore_Add(t *testing.T) {
+	store := newTestRedisCounterStore(t)
+	ctx := context.Background()
+
+	lastReset := time.Now().Add(-time.Second).Truncate(time.Millisecond)
+	value, err := store.Add(ctx, "budget:b1", 1.5, time.Hour, CounterValue{Usage: 10, LastReset: lastReset})
+	if err != nil {
+		t.Fatalf("Failed to add to counter: %v", err)
+	}
+	if value.Usage != 11.5 || !value.LastReset.Equal(lastReset) {
+		t.Errorf("Expected the addition to the seed, got %+v", value)
+	}
+
+	// The seed only applies to missing counters
+	value, err = store.Add(ctx, "budget:b1", 0.25, time.Hour, CounterValue{Usage: 100, LastReset: lastReset})
+	if err != nil {
+		t.Fatalf("Failed to add to counter: %v", err)
+	}
+	if value.Usage != 11.75 {
+		t.Errorf("Expected the addition to the stored usage, got %+v", value)
+	}
+
+	values, err := store.Get(ctx, []string{"budget:b1", "budget:missing"})
+	if err != nil {
+		t.Fatalf("Failed to get counters: %v", err)
+	}
+	if len(values) != 1 || values["budget:b1"].Usage != 11.75 || !values["budget:b1"].LastReset.Equal(lastReset) {
+		t.Errorf("Expected the stored counter only, got %+v", values)
+	}
+}
+
+// TestRedisCounterStore_Reset tests that a counter past its reset duration restarts from zero at the time of the
+// addition, and that a counter without reset duration is never reset
+func TestRedisCounterStore_Reset(t *testing.T) {
+	store := newTestRedisCounterStore(t)
+	ctx := context.Background()
+
+	expired := CounterValue{Usage: 40, LastReset: time.Now().Add(-2 * time.Minute)}
+	before := time.Now().Truncate(time.Millisecond)
+	value, err := store.Add(ctx, "ratelimit:r1:requests", 1, time.Minute, expired)
+	if err != nil {
+		t.Fatalf("Failed to add to counter: %v", err)
+	}
+	if value.Usage != 1 || value.LastReset.Before(before) {
+		t.Errorf("Expected the expired counter to restart from zero, got %+v", value)
+	}
+
+	value, err = store.Add(ctx, "ratelimit:r1:tokens", 5, 0, expired)
+	if err != nil {
+		t.Fatalf("Failed to add to counter: %v", err)
+	}
+	if value.Usage != 45 {
+		t.Errorf("Expected a counter without reset duration to keep its usage, got %+v", value)
+	}
+}
+
+// TestRedisCounterStore_SeedWithoutReset tests that the usage of a seed that was never reset is kept, its reset
+// duration starting at the time of the addition
+func TestRedisCounterStore_SeedWithoutReset(t *testing.T) {
+	store := newTestRedisCounterStore(t)
+	ctx := context.Background()
+
+	before := time.Now().Truncate(time.Millisecond)
+	value, err := store.Add(ctx, "budget:b2", 2, time.Hour, CounterValue{Usage: 30})
+	if err != nil {
+		t.Fatalf("Failed to add to counter: %v", err)
+	}
+	if value.Usage != 32 {
+		t.Errorf("Expected the usage of the seed to be kept, got %+v", value)
+	}
+	if value.LastReset.Before(before) || value.LastReset.After(time.Now()) {
+		t.Errorf("Expected the reset duration to start at the addition, got %v", value.LastReset)
+	}
+}
+
+// TestRedisCounterStore_Circuits tests that the latest time an open circuit is shared until is kept, and that
+// closed circuits are left out
+func TestRedisCounterStore_Circuits(t *testing.T) {
+	store := newTestRedisCounterStore(t)
+	ctx := context.Background()
+
+	until := time.Now().Add(time.Minute).Truncate(time.Millisecond)
+	if err := store.OpenCircuit(ctx, "guardrail", until); err != nil {
+		t.Fatalf("Failed to open circuit: %v", err)
+	}
+	if err := store.OpenCircuit(ctx, "guardrail", until.Add(-30*time.Second)); err != nil {
+		t.Fatalf("Failed to open circuit: %v", err)
+	}
+	if err := store.OpenCircuit(ctx, "semanticcache", time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("Failed to open circuit: %v", err)
+	}
+
+	circuits, err := store.OpenCircuits(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get circuits: %v", err)
+	}
+	if len(circuits) != 1 || !circuits["guardrail"].Equal(until) {
+		t.Errorf("Expected the guardrail circuit open until %v, got %v", until, circuits)
+	}
+}
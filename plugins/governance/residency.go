@@ -0,0 +1,75 @@
+// Package governance provides the data residency enforcement of virtual keys
+package governance
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+)
+
+// governanceAllowedRegionsContextKey holds the regions of the residency policy of the virtual key,
+// the account only selects the keys of these regions
+const governanceAllowedRegionsContextKey schemas.BifrostContextKey = "bf-governance-allowed-regions"
+
+// hasResidentKeys reports whether the provider has a key in the regions of the policy
+func (p *GovernancePlugin) hasResidentKeys(provider schemas.ModelProvider, policy *configstoreTables.ResidencyPolicy) bool {
+	if p.inMemoryStore == nil {
+		// Keys are unknown without the transport, the account still filters them
+		return true
+	}
+	config, ok := p.inMemoryStore.GetConfiguredProviders()[provider]
+	if !ok {
+		return false
+	}
+	for _, key := range config.Keys {
+		if policy.Allows(key.Region) {
+			return true
+		}
+	}
+	return false
+}
+
+// rerouteResidency rewrites a request body naming a provider without keys in the regions of the residency policy of
+// the virtual key, so that the load balancing of the virtual key picks a provider with keys in the regions. Fallbacks
+// to providers without keys in the regions are dropped.
+func (p *GovernancePlugin) rerouteResidency(body map[string]any, virtualKey *configstoreTables.TableVirtualKey) map[string]any {
+	policy := virtualKey.ResidencyPolicy
+	if policy == nil || policy.OnViolation != configstoreTables.ResidencyOnViolationReroute || body == nil {
+		return body
+	}
+	if modelStr, _ := body["model"].(string); strings.Contains(modelStr, "/") {
+		provider, model := schemas.ParseModelString(modelStr, "")
+		if provider != "" && !p.hasResidentKeys(provider, policy) {
+			body["model"] = model
+		}
+	}
+	if fallbacks, ok := body["fallbacks"].([]any); ok {
+		resident := make([]any, 0, len(fallbacks))
+		for _, fallback := range fallbacks {
+			if fallbackStr, ok := fallback.(string); ok {
+				if provider, _ := schemas.ParseModelString(fallbackStr, ""); provider != "" && !p.hasResidentKeys(provider, policy) {
+					continue
+				}
+			}
+			resident = append(resident, fallback)
+		}
+		body["fallbacks"] = resident
+	}
+	return body
+}
+
+// enforceResidency restricts key selection to the regions of the residency policy of the virtual key, and rejects
+// requests to a provider without keys in the regions. Rejected requests still go through their fallbacks.
+func (p *GovernancePlugin) enforceResidency(ctx *schemas.BifrostContext, provider schemas.ModelProvider, virtualKey *configstoreTables.TableVirtualKey) (Decision, string) {
+	policy := virtualKey.ResidencyPolicy
+	if policy == nil {
+		return DecisionAllow, ""
+	}
+	ctx.SetValue(governanceAllowedRegionsContextKey, policy.Regions)
+	if !p.hasResidentKeys(provider, policy) {
+		return DecisionResidencyViolation, fmt.Sprintf("provider %s has no keys in the residency regions of the virtual key (%s)", provider, strings.Join(policy.Regions, ", "))
+	}
+	return DecisionAllow, ""
+}
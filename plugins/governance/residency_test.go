@@ -0,0 +1,122 @@
+package governance
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+)
+
+// routingTestProviders are the providers configured in the transport: OpenAI with a key in the US, Anthropic and
+// Gemini with keys in the EU
+type routingTestProviders struct{}
+
+func (routingTestProviders) GetConfiguredProviders() map[schemas.ModelProvider]configstore.ProviderConfig {
+	return map[schemas.ModelProvider]configstore.ProviderConfig{
+		schemas.OpenAI:    {Keys: []schemas.Key{{ID: "openai-us", Region: "us"}}},
+		schemas.Anthropic: {Keys: []schemas.Key{{ID: "anthropic-eu", Region: "eu"}}},
+		schemas.Gemini:    {Keys: []schemas.Key{{ID: "gemini-eu", Region: "eu"}}},
+	}
+}
+
+// newRoutingTestPlugin creates a governance plugin over the virtual keys, with the providers of routingTestProviders
+func newRoutingTestPlugin(t *testing.T, virtualKeys ...configstoreTables.TableVirtualKey) *GovernancePlugin {
+	t.Helper()
+	logger := bifrost.NewDefaultLogger(schemas.LogLevelError)
+	store, err := NewGovernanceStore(context.Background(), logger, nil, &configstore.GovernanceConfig{VirtualKeys: virtualKeys})
+	if err != nil {
+		t.Fatalf("failed to create governance store: %v", err)
+	}
+	return &GovernancePlugin{store: store, resolver: NewBudgetResolver(store, logger), logger: logger, inMemoryStore: routingTestProviders{}}
+}
+
+// routingTestProviderConfigs returns provider configs of a virtual key for the providers, weighted in their order
+func routingTestProviderConfigs(providers ...schemas.ModelProvider) []configstoreTables.TableVirtualKeyProviderConfig {
+	configs := make([]configstoreTables.TableVirtualKeyProviderConfig, len(providers))
+	for i, provider := range providers {
+		configs[i] = configstoreTables.TableVirtualKeyProviderConfig{Provider: string(provider), Weight: float64(len(providers) - i)}
+	}
+	return configs
+}
+
+// TestEnforceResidency tests that the requests of an EU-only virtual key are rejected on a provider with US keys only,
+// and that the key selection of the others is restricted to the EU
+func TestEnforceResidency(t *testing.T) {
+	vk := configstoreTables.TableVirtualKey{ID: "vk1", Name: "eu", Value: "sk-bf-eu", IsActive: true,
+		ResidencyPolicy: &configstoreTables.ResidencyPolicy{Regions: []string{"eu"}}}
+	p := newRoutingTestPlugin(t, vk)
+
+	tests := map[string]struct {
+		provider schemas.ModelProvider
+		expected Decision
+	}{
+		"us provider": {provider: schemas.OpenAI, expected: DecisionResidencyViolation},
+		"eu provider": {provider: schemas.Anthropic, expected: DecisionAllow},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := schemas.NewBifrostContext(context.Background(), schemas.NoDeadline)
+			defer ctx.Cancel()
+			if decision, _ := p.enforceResidency(ctx, test.provider, &vk); decision != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, decision)
+			}
+			if regions, _ := ctx.Value(governanceAllowedRegionsContextKey).([]string); !slices.Equal(regions, []string{"eu"}) {
+				t.Errorf("Expected the key selection to be restricted to the EU, got %v", regions)
+			}
+		})
+	}
+}
+
+// TestRerouteResidency tests that the requests of an EU-only virtual key rerouting violations are sent to a provider
+// of the key with EU keys, without the fallbacks to providers with US keys only, and that the requests of a key
+// rejecting violations are left for the PreHook to reject
+func TestRerouteResidency(t *testing.T) {
+	reroute := configstoreTables.TableVirtualKey{ID: "vk1", Name: "reroute", Value: "sk-bf-reroute", IsActive: true,
+		ProviderConfigs: routingTestProviderConfigs(schemas.OpenAI, schemas.Anthropic),
+		ResidencyPolicy: &configstoreTables.ResidencyPolicy{Regions: []string{"eu"}, OnViolation: configstoreTables.ResidencyOnViolationReroute}}
+	reject := configstoreTables.TableVirtualKey{ID: "vk2", Name: "reject", Value: "sk-bf-reject", IsActive: true,
+		ProviderConfigs: routingTestProviderConfigs(schemas.OpenAI, schemas.Anthropic),
+		ResidencyPolicy: &configstoreTables.ResidencyPolicy{Regions: []string{"eu"}}}
+	p := newRoutingTestPlugin(t, reroute, reject)
+
+	tests := map[string]struct {
+		virtualKey string
+		model      string
+		expected   map[string]any
+	}{
+		"rerouted": {
+			virtualKey: "sk-bf-reroute",
+			model:      "openai/gpt-4o",
+			expected:   map[string]any{"model": "anthropic/gpt-4o", "fallbacks": []any{"gemini/gemini-1.5-pro"}},
+		},
+		"load balanced within the regions": {
+			virtualKey: "sk-bf-reroute",
+			model:      "gpt-4o",
+			expected:   map[string]any{"model": "anthropic/gpt-4o", "fallbacks": []any{"gemini/gemini-1.5-pro"}},
+		},
+		"rejected": {
+			virtualKey: "sk-bf-reject",
+			model:      "openai/gpt-4o",
+			expected:   map[string]any{"model": "openai/gpt-4o", "fallbacks": []any{"openai/gpt-4o-mini", "gemini/gemini-1.5-pro"}},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := schemas.NewBifrostContext(context.Background(), schemas.NoDeadline)
+			defer ctx.Cancel()
+			body := map[string]any{"model": test.model, "fallbacks": []any{"openai/gpt-4o-mini", "gemini/gemini-1.5-pro"}}
+			_, body, err := p.TransportInterceptor(ctx, "/v1/chat/completions", map[string]string{"x-bf-vk": test.virtualKey}, body)
+			if err != nil {
+				t.Fatalf("failed to intercept request: %v", err)
+			}
+			if !reflect.DeepEqual(body, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, body)
+			}
+		})
+	}
+}
@@ -25,6 +25,7 @@ const (
 	DecisionRequestLimited     Decision = "request_limited"
 	DecisionModelBlocked       Decision = "model_blocked"
 	DecisionProviderBlocked    Decision = "provider_blocked"
+	DecisionResidencyViolation Decision = "residency_violation"
 )
 
 // EvaluationRequest contains the context for evaluating a request
@@ -89,6 +90,8 @@ func (r *BudgetResolver) EvaluateRequest(ctx *schemas.BifrostContext, evaluation
 	// Set virtual key id and name in context
 	ctx.SetValue(schemas.BifrostContextKey("bf-governance-virtual-key-id"), vk.ID)
 	ctx.SetValue(schemas.BifrostContextKey("bf-governance-virtual-key-name"), vk.Name)
+	// The account only selects the keys of the providers of the namespace of the virtual key
+	ctx.SetValue(schemas.BifrostContextKey("bf-governance-namespace"), vk.Namespace)
 	if vk.Team != nil {
 		ctx.SetValue(schemas.BifrostContextKey("bf-governance-team-id"), vk.Team.ID)
 		ctx.SetValue(schemas.BifrostContextKey("bf-governance-team-name"), vk.Team.Name)
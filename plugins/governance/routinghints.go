@@ -0,0 +1,289 @@
+// Package governance provides the per-request routing hints of the governance plugin
+package governance
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+)
+
+// RoutingStrategy is how the provider of a request is picked among the providers allowed by its virtual key
+type RoutingStrategy string
+
+const (
+	RoutingStrategyWeighted RoutingStrategy = "weighted" // Random by the weights of the providers of the virtual key (default)
+	RoutingStrategyPriority RoutingStrategy = "priority" // Provider with the highest weight, the others as fallbacks by weight
+	RoutingStrategyCheapest RoutingStrategy = "cheapest" // Provider with the lowest estimated cost, the others as fallbacks by cost
+)
+
+// charsPerToken is the number of characters of a prompt counted as a token when estimating its cost
+const charsPerToken = 4
+
+// routingHints are the constraints a request puts on the providers it is sent to, through the routing headers
+type routingHints struct {
+	Provider         schemas.ModelProvider   // x-bf-provider
+	ExcludeProviders []schemas.ModelProvider // x-bf-exclude-providers
+	Strategy         RoutingStrategy         // x-bf-routing-strategy
+	MaxCost          *float64                // x-bf-max-cost
+}
+
+// parseRoutingHints returns the routing hints of the request headers, or nil if it has none
+func parseRoutingHints(headers map[string]string) *routingHints {
+	hints := &routingHints{}
+	found := false
+	for header, value := range headers {
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(header) {
+		case string(schemas.BifrostContextKeyRoutingProvider):
+			if value != "" {
+				hints.Provider = schemas.ModelProvider(value)
+				found = true
+			}
+		case string(schemas.BifrostContextKeyRoutingExcludeProviders):
+			for _, provider := range strings.Split(value, ",") {
+				if trimmed := strings.TrimSpace(provider); trimmed != "" {
+					hints.ExcludeProviders = append(hints.ExcludeProviders, schemas.ModelProvider(trimmed))
+					found = true
+				}
+			}
+		case string(schemas.BifrostContextKeyRoutingStrategy):
+			if value != "" {
+				hints.Strategy = RoutingStrategy(value)
+				found = true
+			}
+		case string(schemas.BifrostContextKeyRoutingMaxCost):
+			if maxCost, err := strconv.ParseFloat(value, 64); err == nil && maxCost >= 0 {
+				hints.MaxCost = &maxCost
+				found = true
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+	return hints
+}
+
+// routingHintsFromContext returns the routing hints set on the context by the transport, or nil if it has none
+func routingHintsFromContext(ctx *schemas.BifrostContext) *routingHints {
+	if ctx == nil {
+		return nil
+	}
+	hints := &routingHints{}
+	hints.Provider, _ = ctx.Value(schemas.BifrostContextKeyRoutingProvider).(schemas.ModelProvider)
+	hints.ExcludeProviders, _ = ctx.Value(schemas.BifrostContextKeyRoutingExcludeProviders).([]schemas.ModelProvider)
+	if maxCost, ok := ctx.Value(schemas.BifrostContextKeyRoutingMaxCost).(float64); ok {
+		hints.MaxCost = &maxCost
+	}
+	if hints.Provider == "" && len(hints.ExcludeProviders) == 0 && hints.MaxCost == nil {
+		return nil
+	}
+	return hints
+}
+
+// allows reports whether the hints let the request be sent to the provider
+func (h *routingHints) allows(provider schemas.ModelProvider) bool {
+	if h == nil {
+		return true
+	}
+	if h.Provider != "" && provider != h.Provider {
+		return false
+	}
+	return !slices.Contains(h.ExcludeProviders, provider)
+}
+
+// estimateCost returns the estimated cost of a request to the model of the provider, from the characters of its
+// prompt and its maximum output tokens. It returns false when the model has no pricing.
+func (p *GovernancePlugin) estimateCost(provider schemas.ModelProvider, model string, promptChars, maxOutputTokens int) (float64, bool) {
+	if p.modelCatalog == nil {
+		return 0, false
+	}
+	pricing := p.modelCatalog.GetPricingEntryForModel(model, provider)
+	if pricing == nil {
+		return 0, false
+	}
+	return float64(promptChars/charsPerToken)*pricing.InputCostPerToken + float64(maxOutputTokens)*pricing.OutputCostPerToken, true
+}
+
+// bodyCostInputs returns the characters of the prompt and the maximum output tokens of a request body
+func bodyCostInputs(body map[string]any) (int, int) {
+	promptChars := 0
+	for _, field := range []string{"messages", "input", "prompt", "system", "instructions"} {
+		if value, ok := body[field]; ok {
+			if data, err := json.Marshal(value); err == nil {
+				promptChars += len(data)
+			}
+		}
+	}
+	for _, field := range []string{"max_completion_tokens", "max_tokens", "max_output_tokens"} {
+		if maxTokens, ok := body[field].(float64); ok {
+			return promptChars, int(maxTokens)
+		}
+	}
+	return promptChars, 0
+}
+
+// requestCostInputs returns the characters of the prompt and the maximum output tokens of a request
+func requestCostInputs(req *schemas.BifrostRequest) (int, int) {
+	var input any
+	maxOutputTokens := 0
+	switch {
+	case req.ChatRequest != nil:
+		input = req.ChatRequest.Input
+		if req.ChatRequest.Params != nil && req.ChatRequest.Params.MaxCompletionTokens != nil {
+			maxOutputTokens = *req.ChatRequest.Params.MaxCompletionTokens
+		}
+	case req.ResponsesRequest != nil:
+		input = req.ResponsesRequest.Input
+		if req.ResponsesRequest.Params != nil && req.ResponsesRequest.Params.MaxOutputTokens != nil {
+			maxOutputTokens = *req.ResponsesRequest.Params.MaxOutputTokens
+		}
+	case req.TextCompletionRequest != nil:
+		input = req.TextCompletionRequest.Input
+		if req.TextCompletionRequest.Params != nil && req.TextCompletionRequest.Params.MaxTokens != nil {
+			maxOutputTokens = *req.TextCompletionRequest.Params.MaxTokens
+		}
+	case req.EmbeddingRequest != nil:
+		input = req.EmbeddingRequest.Input
+	default:
+		return 0, 0
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return 0, maxOutputTokens
+	}
+	return len(data), maxOutputTokens
+}
+
+// orderByRoutingStrategy orders the allowed provider configs of the virtual key by the strategy of the hints, the
+// first config being the provider of the request. Returns false for the weighted strategy, which picks the provider
+// at random. Providers without pricing come last with the cheapest strategy.
+func (p *GovernancePlugin) orderByRoutingStrategy(configs []configstoreTables.TableVirtualKeyProviderConfig, hints *routingHints, model string, body map[string]any) bool {
+	if hints == nil {
+		return false
+	}
+	switch hints.Strategy {
+	case RoutingStrategyPriority:
+		sort.SliceStable(configs, func(i, j int) bool {
+			return configs[i].Weight > configs[j].Weight
+		})
+		return true
+	case RoutingStrategyCheapest:
+		promptChars, maxOutputTokens := bodyCostInputs(body)
+		costs := make(map[string]float64, len(configs))
+		for _, config := range configs {
+			cost, ok := p.estimateCost(schemas.ModelProvider(config.Provider), model, promptChars, maxOutputTokens)
+			if !ok {
+				cost = math.Inf(1)
+			}
+			costs[config.Provider] = cost
+		}
+		sort.SliceStable(configs, func(i, j int) bool {
+			return costs[configs[i].Provider] < costs[configs[j].Provider]
+		})
+		return true
+	case "", RoutingStrategyWeighted:
+		return false
+	default:
+		p.logger.Warn("unknown routing strategy %s, using weighted routing", hints.Strategy)
+		return false
+	}
+}
+
+// withinMaxCost reports whether the estimated cost of the request body on the provider fits the max cost of the
+// hints. Requests to models without pricing always fit.
+func (p *GovernancePlugin) withinMaxCost(hints *routingHints, provider schemas.ModelProvider, model string, body map[string]any) bool {
+	if hints == nil || hints.MaxCost == nil {
+		return true
+	}
+	promptChars, maxOutputTokens := bodyCostInputs(body)
+	cost, ok := p.estimateCost(provider, model, promptChars, maxOutputTokens)
+	return !ok || cost <= *hints.MaxCost
+}
+
+// applyRoutingHints pins a request body without a provider to the provider of the hints, and drops the fallbacks
+// to providers the hints do not allow. Bodies naming a provider the hints do not allow are left for the PreHook
+// to reject.
+func (p *GovernancePlugin) applyRoutingHints(body map[string]any, hints *routingHints) map[string]any {
+	if hints == nil || body == nil {
+		return body
+	}
+	if modelStr, _ := body["model"].(string); modelStr != "" && hints.Provider != "" && !p.hasConfiguredProviderPrefix(modelStr) {
+		body["model"] = string(hints.Provider) + "/" + modelStr
+	}
+	if fallbacks, ok := body["fallbacks"].([]any); ok {
+		allowed := make([]any, 0, len(fallbacks))
+		for _, fallback := range fallbacks {
+			if fallbackStr, ok := fallback.(string); ok {
+				if provider, _ := schemas.ParseModelString(fallbackStr, ""); provider != "" && !hints.allows(provider) {
+					continue
+				}
+			}
+			allowed = append(allowed, fallback)
+		}
+		body["fallbacks"] = allowed
+	}
+	return body
+}
+
+// hasConfiguredProviderPrefix reports whether the model string is prefixed with a provider, as "provider/model".
+// Without the transport any prefix is taken as a provider.
+func (p *GovernancePlugin) hasConfiguredProviderPrefix(modelStr string) bool {
+	if !strings.Contains(modelStr, "/") {
+		return false
+	}
+	provider, _ := schemas.ParseModelString(modelStr, "")
+	if p.inMemoryStore == nil {
+		return provider != ""
+	}
+	_, ok := p.inMemoryStore.GetConfiguredProviders()[provider]
+	return ok
+}
+
+// enforceRoutingHints rejects a request, or a fallback of a request, to a provider its routing hints do not allow or
+// whose estimated cost exceeds their max cost. Rejected requests still go through their fallbacks.
+func (p *GovernancePlugin) enforceRoutingHints(ctx *schemas.BifrostContext, req *schemas.BifrostRequest) *schemas.PluginShortCircuit {
+	hints := routingHintsFromContext(ctx)
+	if hints == nil {
+		return nil
+	}
+	provider, model, _ := req.GetRequestFields()
+	if !hints.allows(provider) {
+		reason := fmt.Sprintf("provider %s is excluded by the x-bf-exclude-providers header", provider)
+		if hints.Provider != "" && provider != hints.Provider {
+			reason = fmt.Sprintf("request is pinned to provider %s by the x-bf-provider header", hints.Provider)
+		}
+		return &schemas.PluginShortCircuit{
+			Error: &schemas.BifrostError{
+				Type:       bifrost.Ptr("routing_hint_violation"),
+				StatusCode: bifrost.Ptr(403),
+				Error: &schemas.ErrorField{
+					Message: reason,
+				},
+			},
+		}
+	}
+	if hints.MaxCost != nil {
+		promptChars, maxOutputTokens := requestCostInputs(req)
+		if cost, ok := p.estimateCost(provider, model, promptChars, maxOutputTokens); ok && cost > *hints.MaxCost {
+			return &schemas.PluginShortCircuit{
+				Error: &schemas.BifrostError{
+					Type:       bifrost.Ptr("max_cost_exceeded"),
+					StatusCode: bifrost.Ptr(402),
+					Error: &schemas.ErrorField{
+						Message: fmt.Sprintf("estimated cost %.6f of the request on %s/%s exceeds the x-bf-max-cost header (%.6f)", cost, provider, model, *hints.MaxCost),
+					},
+				},
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package governance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+	"github.com/maximhq/bifrost/framework/modelcatalog"
+)
+
+// newRoutingHintsTestPlugin creates a governance plugin over a virtual key allowing OpenAI and Anthropic, with a
+// model catalog pricing gpt-4o at a hundred times the price on OpenAI than on Anthropic
+func newRoutingHintsTestPlugin(t *testing.T) *GovernancePlugin {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"openai/gpt-4o": {"provider": "openai", "mode": "chat", "input_cost_per_token": 0.001, "output_cost_per_token": 0.002},
+			"anthropic/gpt-4o": {"provider": "anthropic", "mode": "chat", "input_cost_per_token": 0.00001, "output_cost_per_token": 0.00002}
+		}`))
+	}))
+	t.Cleanup(server.Close)
+	catalog, err := modelcatalog.Init(context.Background(), &modelcatalog.Config{PricingURL: bifrost.Ptr(server.URL)}, nil, bifrost.NewDefaultLogger(schemas.LogLevelError))
+	if err != nil {
+		t.Fatalf("failed to init model catalog: %v", err)
+	}
+	t.Cleanup(func() { catalog.Cleanup() })
+
+	p := newRoutingTestPlugin(t, configstoreTables.TableVirtualKey{ID: "vk1", Name: "app", Value: "sk-bf-app", IsActive: true,
+		ProviderConfigs: routingTestProviderConfigs(schemas.OpenAI, schemas.Anthropic)})
+	p.modelCatalog = catalog
+	return p
+}
+
+// TestParseRoutingHints tests that the routing headers are parsed case-insensitively, and that invalid max costs
+// are ignored
+func TestParseRoutingHints(t *testing.T) {
+	tests := map[string]struct {
+		headers  map[string]string
+		expected *routingHints
+	}{
+		"provider": {
+			headers:  map[string]string{"X-Bf-Provider": " anthropic "},
+			expected: &routingHints{Provider: schemas.Anthropic},
+		},
+		"excluded providers": {
+			headers:  map[string]string{"x-bf-exclude-providers": "openai, ,gemini"},
+			expected: &routingHints{ExcludeProviders: []schemas.ModelProvider{schemas.OpenAI, schemas.Gemini}},
+		},
+		"max cost": {
+			headers:  map[string]string{"x-bf-max-cost": "0.05", "x-bf-routing-strategy": "cheapest"},
+			expected: &routingHints{MaxCost: bifrost.Ptr(0.05), Strategy: RoutingStrategyCheapest},
+		},
+		"negative max cost": {
+			headers:  map[string]string{"x-bf-max-cost": "-1"},
+			expected: nil,
+		},
+		"invalid max cost": {
+			headers:  map[string]string{"x-bf-max-cost": "cheap"},
+			expected: nil,
+		},
+		"no hints": {
+			headers:  map[string]string{"x-bf-vk": "sk-bf-app"},
+			expected: nil,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := parseRoutingHints(test.headers); !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("Expected %+v, got %+v", test.expected, got)
+			}
+		})
+	}
+}
+
+// TestRoutingHints_LoadBalancing tests that the routing hints narrow the providers the virtual key load balances
+// over, and that a provider outside of the virtual key is rejected by governance
+func TestRoutingHints_LoadBalancing(t *testing.T) {
+	p := newRoutingHintsTestPlugin(t)
+	messages := []any{map[string]any{"role": "user", "content": "hi"}}
+
+	tests := map[string]struct {
+		headers  map[string]string
+		body     map[string]any
+		expected map[string]any
+	}{
+		"pinned provider": {
+			headers:  map[string]string{"x-bf-provider": "anthropic"},
+			body:     map[string]any{"model": "gpt-4o"},
+			expected: map[string]any{"model": "anthropic/gpt-4o"},
+		},
+		"excluded provider": {
+			headers:  map[string]string{"x-bf-exclude-providers": "openai"},
+			body:     map[string]any{"model": "gpt-4o", "fallbacks": []any{"openai/gpt-4o-mini", "anthropic/claude-3-5-sonnet"}},
+			expected: map[string]any{"model": "anthropic/gpt-4o", "fallbacks": []any{"anthropic/claude-3-5-sonnet"}},
+		},
+		"max cost": {
+			headers:  map[string]string{"x-bf-max-cost": "0.1"},
+			body:     map[string]any{"model": "gpt-4o", "messages": messages, "max_completion_tokens": float64(100)},
+			expected: map[string]any{"model": "anthropic/gpt-4o", "messages": messages, "max_completion_tokens": float64(100)},
+		},
+		"pinned provider outside of the virtual key": {
+			headers:  map[string]string{"x-bf-provider": "gemini"},
+			body:     map[string]any{"model": "gpt-4o"},
+			expected: map[string]any{"model": "gemini/gpt-4o"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := schemas.NewBifrostContext(context.Background(), schemas.NoDeadline)
+			defer ctx.Cancel()
+			test.headers["x-bf-vk"] = "sk-bf-app"
+			_, body, err := p.TransportInterceptor(ctx, "/v1/chat/completions", test.headers, test.body)
+			if err != nil {
+				t.Fatalf("failed to intercept request: %v", err)
+			}
+			if !reflect.DeepEqual(body, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, body)
+			}
+		})
+	}
+
+	ctx := schemas.NewBifrostContext(context.Background(), schemas.NoDeadline)
+	defer ctx.Cancel()
+	result := p.resolver.EvaluateRequest(ctx, &EvaluationRequest{VirtualKey: "sk-bf-app", Provider: schemas.Gemini, Model: "gpt-4o"})
+	if result.Decision != DecisionProviderBlocked {
+		t.Errorf("Expected the provider outside of the virtual key to be blocked, got %s", result.Decision)
+	}
+}
+
+// TestEnforceRoutingHints tests that requests and fallbacks to providers the routing hints do not allow, or over
+// their max cost, are rejected
+func TestEnforceRoutingHints(t *testing.T) {
+	p := newRoutingHintsTestPlugin(t)
+
+	tests := map[string]struct {
+		provider schemas.ModelProvider
+		hints    map[schemas.BifrostContextKey]any
+		status   int
+	}{
+		"pinned provider": {
+			provider: schemas.Anthropic,
+			hints:    map[schemas.BifrostContextKey]any{schemas.BifrostContextKeyRoutingProvider: schemas.Anthropic},
+		},
+		"other than the pinned provider": {
+			provider: schemas.OpenAI,
+			hints:    map[schemas.BifrostContextKey]any{schemas.BifrostContextKeyRoutingProvider: schemas.Anthropic},
+			status:   403,
+		},
+		"excluded provider": {
+			provider: schemas.OpenAI,
+			hints:    map[schemas.BifrostContextKey]any{schemas.BifrostContextKeyRoutingExcludeProviders: []schemas.ModelProvider{schemas.OpenAI}},
+			status:   403,
+		},
+		"within max cost": {
+			provider: schemas.Anthropic,
+			hints:    map[schemas.BifrostContextKey]any{schemas.BifrostContextKeyRoutingMaxCost: 0.1},
+		},
+		"over max cost": {
+			provider: schemas.OpenAI,
+			hints:    map[schemas.BifrostContextKey]any{schemas.BifrostContextKeyRoutingMaxCost: 0.1},
+			status:   402,
+		},
+		"no hints": {
+			provider: schemas.OpenAI,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := schemas.NewBifrostContext(context.Background(), schemas.NoDeadline)
+			defer ctx.Cancel()
+			for key, value := range test.hints {
+				ctx.SetValue(key, value)
+			}
+			req := &schemas.BifrostRequest{RequestType: schemas.ChatCompletionRequest, ChatRequest: &schemas.BifrostChatRequest{
+				Provider: test.provider,
+				Model:    "gpt-4o",
+				Input:    []schemas.ChatMessage{{Role: schemas.ChatMessageRoleUser, Content: &schemas.ChatMessageContent{ContentStr: bifrost.Ptr("hi")}}},
+				Params:   &schemas.ChatParameters{MaxCompletionTokens: bifrost.Ptr(100)},
+			}}
+			shortCircuit := p.enforceRoutingHints(ctx, req)
+			if test.status == 0 {
+				if shortCircuit != nil {
+					t.Errorf("Expected the request to be allowed, got %+v", shortCircuit.Error)
+				}
+				return
+			}
+			if shortCircuit == nil || shortCircuit.Error.StatusCode == nil || *shortCircuit.Error.StatusCode != test.status {
+				t.Errorf("Expected the request to be rejected with %d, got %+v", test.status, shortCircuit)
+			}
+		})
+	}
+}
@@ -5,11 +5,13 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/maximhq/bifrost/core/schemas"
 	"github.com/maximhq/bifrost/framework/configstore"
 	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+	"github.com/maximhq/bifrost/framework/leader"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -22,9 +24,19 @@ type GovernanceStore struct {
 	customers   sync.Map // string -> *Customer (Customer ID -> Customer)
 	budgets     sync.Map // string -> *Budget (Budget ID -> Budget)
 
+	// Test key spend, updates are serialized so that concurrent costs are not lost
+	testKeySpends  sync.Map // string -> *TableKeySpend (Key ID -> spend)
+	testKeySpendMu sync.Mutex
+
 	// Config store for refresh operations
 	configStore configstore.ConfigStore
 
+	// Usage counters shared with the other replicas, nil when the usage is only counted locally
+	counters *sharedCounters
+
+	// Elector of the replica persisting the periodic resets, every replica resets its in-memory counters
+	elector atomic.Pointer[leader.Elector]
+
 	// Logger
 	logger schemas.Logger
 }
@@ -51,7 +63,8 @@ func NewGovernanceStore(ctx context.Context, logger schemas.Logger, configStore
 	return store, nil
 }
 
-// GetVirtualKey retrieves a virtual key by its value (lock-free) with all relationships preloaded
+// GetVirtualKey retrieves a virtual key by its value (lock-free) with all relationships preloaded.
+// The value replaced by the last rotation resolves to the same virtual key until its grace period ends.
 func (gs *GovernanceStore) GetVirtualKey(vkValue string) (*configstoreTables.TableVirtualKey, bool) {
 	value, exists := gs.virtualKeys.Load(vkValue)
 	if !exists || value == nil {
@@ -62,9 +75,30 @@ func (gs *GovernanceStore) GetVirtualKey(vkValue string) (*configstoreTables.Tab
 	if !ok || vk == nil {
 		return nil, false
 	}
+	if vk.Value != vkValue && !vk.PreviousValueValid(time.Now()) {
+		// Grace period of the rotated value is over
+		gs.virtualKeys.CompareAndDelete(vkValue, value)
+		return nil, false
+	}
 	return vk, true
 }
 
+// GetVirtualKeyForTeam retrieves the oldest active virtual key belonging to the given team (lock-free)
+func (gs *GovernanceStore) GetVirtualKeyForTeam(teamID string) (*configstoreTables.TableVirtualKey, bool) {
+	var result *configstoreTables.TableVirtualKey
+	gs.virtualKeys.Range(func(key, value interface{}) bool {
+		vk, ok := value.(*configstoreTables.TableVirtualKey)
+		if !ok || vk == nil || !vk.IsActive || vk.TeamID == nil || *vk.TeamID != teamID {
+			return true
+		}
+		if result == nil || vk.CreatedAt.Before(result.CreatedAt) {
+			result = vk
+		}
+		return true
+	})
+	return result, result != nil
+}
+
 // GetAllBudgets returns all budgets (for background reset operations)
 func (gs *GovernanceStore) GetAllBudgets() map[string]*configstoreTables.TableBudget {
 	result := make(map[string]*configstoreTables.TableBudget)
@@ -129,6 +163,7 @@ func (gs *GovernanceStore) UpdateBudget(ctx context.Context, vk *configstoreTabl
 				if cachedBudget, ok := cachedBudgetValue.(*configstoreTables.TableBudget); ok && cachedBudget != nil {
 					clone := *cachedBudget
 					clone.CurrentUsage += cost
+					gs.adoptSharedBudgetUsage(&clone, cost, CounterValue{Usage: cachedBudget.CurrentUsage, LastReset: cachedBudget.LastReset})
 					gs.budgets.Store(budgetID, &clone)
 				}
 			}
@@ -164,6 +199,7 @@ func (gs *GovernanceStore) UpdateBudget(ctx context.Context, vk *configstoreTabl
 					clone := *cachedBudget
 					clone.CurrentUsage += cost
 					clone.LastReset = budget.LastReset
+					gs.adoptSharedBudgetUsage(&clone, cost, CounterValue{Usage: budget.CurrentUsage - cost, LastReset: budget.LastReset})
 					gs.budgets.Store(budgetID, &clone)
 				}
 			}
@@ -179,16 +215,11 @@ func (gs *GovernanceStore) UpdateRateLimitUsage(ctx context.Context, vkValue str
 		return fmt.Errorf("virtual key value cannot be empty")
 	}
 
-	vkValue_, exists := gs.virtualKeys.Load(vkValue)
-	if !exists || vkValue_ == nil {
+	vk, exists := gs.GetVirtualKey(vkValue)
+	if !exists {
 		return fmt.Errorf("virtual key not found: %s", vkValue)
 	}
 
-	vk, ok := vkValue_.(*configstoreTables.TableVirtualKey)
-	if !ok || vk == nil {
-		return fmt.Errorf("invalid virtual key type for: %s", vkValue)
-	}
-
 	var rateLimitsToUpdate []*configstoreTables.TableRateLimit
 
 	// First, update provider-level rate limits if they exist
@@ -249,18 +280,121 @@ func (gs *GovernanceStore) updateSingleRateLimit(rateLimit *configstoreTables.Ta
 
 	// Update usage counters based on flags
 	if shouldUpdateTokens && tokensUsed > 0 {
+		seed := CounterValue{Usage: float64(rateLimit.TokenCurrentUsage), LastReset: rateLimit.TokenLastReset}
 		rateLimit.TokenCurrentUsage += tokensUsed
+		if value, ok := gs.addSharedUsage(rateLimitTokensKey(rateLimit.ID), float64(tokensUsed), rateLimit.TokenResetDuration, seed); ok {
+			rateLimit.TokenCurrentUsage = int64(value.Usage)
+			rateLimit.TokenLastReset = value.LastReset
+		}
 		updated = true
 	}
 
 	if shouldUpdateRequests {
+		seed := CounterValue{Usage: float64(rateLimit.RequestCurrentUsage), LastReset: rateLimit.RequestLastReset}
 		rateLimit.RequestCurrentUsage += 1
+		if value, ok := gs.addSharedUsage(rateLimitRequestsKey(rateLimit.ID), 1, rateLimit.RequestResetDuration, seed); ok {
+			rateLimit.RequestCurrentUsage = int64(value.Usage)
+			rateLimit.RequestLastReset = value.LastReset
+		}
 		updated = true
 	}
 
 	return updated
 }
 
+// addSharedUsage adds usage to a shared counter, and returns its state across the replicas. Returns false when the
+// counters are not shared or the shared store cannot be reached, the local usage is then kept.
+func (gs *GovernanceStore) addSharedUsage(key string, delta float64, duration *string, seed CounterValue) (CounterValue, bool) {
+	if gs.counters == nil {
+		return CounterValue{}, false
+	}
+	return gs.counters.add(key, delta, resetDuration(duration), seed)
+}
+
+// adoptSharedBudgetUsage adds the cost to the shared counter of a budget, and adopts the usage across the replicas
+func (gs *GovernanceStore) adoptSharedBudgetUsage(budget *configstoreTables.TableBudget, cost float64, seed CounterValue) {
+	if value, ok := gs.addSharedUsage(budgetKey(budget.ID), cost, &budget.ResetDuration, seed); ok {
+		budget.CurrentUsage = value.Usage
+		budget.LastReset = value.LastReset
+	}
+}
+
+// SyncSharedCounters pulls the usage counted by the other replicas into the in-memory cache, so that the limits and
+// budgets are enforced on the usage of all the replicas. Counters past their reset duration are left to the local
+// resets. Does nothing when the counters are not shared or the shared store cannot be reached.
+func (gs *GovernanceStore) SyncSharedCounters() {
+	if gs.counters == nil {
+		return
+	}
+
+	rateLimits := make(map[string]*configstoreTables.TableRateLimit)
+	gs.virtualKeys.Range(func(key, value interface{}) bool {
+		vk, ok := value.(*configstoreTables.TableVirtualKey)
+		if !ok || vk == nil {
+			return true
+		}
+		if vk.RateLimit != nil {
+			rateLimits[vk.RateLimit.ID] = vk.RateLimit
+		}
+		for _, pc := range vk.ProviderConfigs {
+			if pc.RateLimit != nil {
+				rateLimits[pc.RateLimit.ID] = pc.RateLimit
+			}
+		}
+		return true
+	})
+	var budgetIDs []string
+	gs.budgets.Range(func(key, value interface{}) bool {
+		if budgetID, ok := key.(string); ok {
+			budgetIDs = append(budgetIDs, budgetID)
+		}
+		return true
+	})
+
+	keys := make([]string, 0, 2*len(rateLimits)+len(budgetIDs))
+	for id := range rateLimits {
+		keys = append(keys, rateLimitTokensKey(id), rateLimitRequestsKey(id))
+	}
+	for _, budgetID := range budgetIDs {
+		keys = append(keys, budgetKey(budgetID))
+	}
+	if len(keys) == 0 {
+		return
+	}
+	values, ok := gs.counters.get(keys)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	for id, rateLimit := range rateLimits {
+		if value, ok := values[rateLimitTokensKey(id)]; ok && value.current(resetDuration(rateLimit.TokenResetDuration), now) {
+			rateLimit.TokenCurrentUsage = int64(value.Usage)
+			rateLimit.TokenLastReset = value.LastReset
+		}
+		if value, ok := values[rateLimitRequestsKey(id)]; ok && value.current(resetDuration(rateLimit.RequestResetDuration), now) {
+			rateLimit.RequestCurrentUsage = int64(value.Usage)
+			rateLimit.RequestLastReset = value.LastReset
+		}
+	}
+	for _, budgetID := range budgetIDs {
+		value, ok := values[budgetKey(budgetID)]
+		if !ok {
+			continue
+		}
+		cachedBudgetValue, exists := gs.budgets.Load(budgetID)
+		if !exists {
+			continue
+		}
+		if cachedBudget, ok := cachedBudgetValue.(*configstoreTables.TableBudget); ok && cachedBudget != nil && value.current(resetDuration(&cachedBudget.ResetDuration), now) {
+			clone := *cachedBudget
+			clone.CurrentUsage = value.Usage
+			clone.LastReset = value.LastReset
+			gs.budgets.Store(budgetID, &clone)
+		}
+	}
+}
+
 // checkAndResetSingleRateLimit checks and resets a single rate limit's counters if expired
 func (gs *GovernanceStore) checkAndResetSingleRateLimit(ctx context.Context, rateLimit *configstoreTables.TableRateLimit, now time.Time) bool {
 	updated := false
@@ -298,8 +432,8 @@ func (gs *GovernanceStore) ResetExpiredRateLimits(ctx context.Context) error {
 	gs.virtualKeys.Range(func(key, value interface{}) bool {
 		// Type-safe conversion
 		vk, ok := value.(*configstoreTables.TableVirtualKey)
-		if !ok || vk == nil {
-			return true // continue
+		if !ok || vk == nil || key != vk.Value {
+			return true // continue, entries of rotated values point to an already visited VK
 		}
 
 		// Check provider-level rate limits
@@ -323,8 +457,8 @@ func (gs *GovernanceStore) ResetExpiredRateLimits(ctx context.Context) error {
 		return true // continue
 	})
 
-	// Persist reset rate limits to database
-	if len(resetRateLimits) > 0 && gs.configStore != nil {
+	// Persist reset rate limits to database, once per cluster
+	if len(resetRateLimits) > 0 && gs.configStore != nil && gs.elector.Load().IsLeader() {
 		if err := gs.configStore.UpdateRateLimits(ctx, resetRateLimits); err != nil {
 			return fmt.Errorf("failed to persist rate limit resets to database: %w", err)
 		}
@@ -363,8 +497,8 @@ func (gs *GovernanceStore) ResetExpiredBudgets(ctx context.Context) error {
 		return true // continue
 	})
 
-	// Persist to database if any resets occurred
-	if len(resetBudgets) > 0 && gs.configStore != nil {
+	// Persist to database if any resets occurred, once per cluster
+	if len(resetBudgets) > 0 && gs.configStore != nil && gs.elector.Load().IsLeader() {
 		if err := gs.configStore.UpdateBudgets(ctx, resetBudgets); err != nil {
 			return fmt.Errorf("failed to persist budget resets to database: %w", err)
 		}
@@ -401,8 +535,15 @@ func (gs *GovernanceStore) loadFromDatabase(ctx context.Context) error {
 		return fmt.Errorf("failed to load budgets: %w", err)
 	}
 
+	// Load test key spend
+	testKeySpends, err := gs.configStore.GetKeySpends(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load test key spend: %w", err)
+	}
+
 	// Rebuild in-memory structures (lock-free)
 	gs.rebuildInMemoryStructures(ctx, customers, teams, virtualKeys, budgets)
+	gs.loadTestKeySpends(testKeySpends)
 
 	return nil
 }
@@ -494,7 +635,7 @@ func (gs *GovernanceStore) rebuildInMemoryStructures(ctx context.Context, custom
 	// Build virtual keys map and track active VKs
 	for i := range virtualKeys {
 		vk := &virtualKeys[i]
-		gs.virtualKeys.Store(vk.Value, vk)
+		gs.storeVirtualKey(vk)
 	}
 }
 
@@ -621,7 +762,7 @@ func (gs *GovernanceStore) CreateVirtualKeyInMemory(vk *configstoreTables.TableV
 	if vk == nil {
 		return // Nothing to create
 	}
-	gs.virtualKeys.Store(vk.Value, vk)
+	gs.storeVirtualKey(vk)
 }
 
 // UpdateVirtualKeyInMemory updates an existing virtual key in the in-memory store (lock-free)
@@ -629,7 +770,27 @@ func (gs *GovernanceStore) UpdateVirtualKeyInMemory(vk *configstoreTables.TableV
 	if vk == nil {
 		return // Nothing to update
 	}
+	// Drop entries of values that no longer resolve to this VK (rotated or expired)
+	now := time.Now()
+	gs.virtualKeys.Range(func(key, value interface{}) bool {
+		existing, ok := value.(*configstoreTables.TableVirtualKey)
+		if !ok || existing == nil || existing.ID != vk.ID {
+			return true // continue iteration
+		}
+		if key != vk.Value && (!vk.PreviousValueValid(now) || key != *vk.PreviousValue) {
+			gs.virtualKeys.Delete(key)
+		}
+		return true // continue iteration
+	})
+	gs.storeVirtualKey(vk)
+}
+
+// storeVirtualKey stores a VK under its value and, during the rotation grace period, under its previous value
+func (gs *GovernanceStore) storeVirtualKey(vk *configstoreTables.TableVirtualKey) {
 	gs.virtualKeys.Store(vk.Value, vk)
+	if vk.PreviousValueValid(time.Now()) {
+		gs.virtualKeys.Store(*vk.PreviousValue, vk)
+	}
 }
 
 // DeleteVirtualKeyInMemory removes a virtual key from the in-memory store
@@ -647,8 +808,8 @@ func (gs *GovernanceStore) DeleteVirtualKeyInMemory(vkID string) {
 		}
 
 		if vk.ID == vkID {
+			// A rotated VK can be stored under its previous value as well
 			gs.virtualKeys.Delete(key)
-			return false // stop iteration
 		}
 		return true // continue iteration
 	})
@@ -0,0 +1,35 @@
+package governance
+
+import (
+	"testing"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+)
+
+// TestStoreVirtualKey_PreviousValue tests that the previous value of a rotated virtual key is only stored during its
+// grace period, and is dropped once an update no longer carries it
+func TestStoreVirtualKey_PreviousValue(t *testing.T) {
+	store := &GovernanceStore{}
+	expired := time.Now().Add(-time.Second)
+	store.storeVirtualKey(&configstoreTables.TableVirtualKey{ID: "vk1", Value: "sk-bf-new", PreviousValue: bifrost.Ptr("sk-bf-old"), PreviousValueExpiresAt: &expired})
+	if _, ok := store.virtualKeys.Load("sk-bf-old"); ok {
+		t.Error("Expected the previous value to be left out after its grace period")
+	}
+
+	expiresAt := time.Now().Add(time.Hour)
+	store.UpdateVirtualKeyInMemory(&configstoreTables.TableVirtualKey{ID: "vk1", Value: "sk-bf-new", PreviousValue: bifrost.Ptr("sk-bf-old"), PreviousValueExpiresAt: &expiresAt})
+	if vk, ok := store.GetVirtualKey("sk-bf-old"); !ok || vk.Value != "sk-bf-new" {
+		t.Errorf("Expected the previous value to resolve to the virtual key during its grace period, got %v", vk)
+	}
+
+	revokedAt := time.Now()
+	store.UpdateVirtualKeyInMemory(&configstoreTables.TableVirtualKey{ID: "vk1", Value: "sk-bf-new", RevokedAt: &revokedAt})
+	if _, ok := store.virtualKeys.Load("sk-bf-old"); ok {
+		t.Error("Expected the previous value to be dropped once the virtual key no longer carries it")
+	}
+	if vk, ok := store.GetVirtualKey("sk-bf-new"); !ok || !vk.IsRevoked() {
+		t.Errorf("Expected the revoked virtual key under its value, got %v", vk)
+	}
+}
@@ -0,0 +1,151 @@
+package governance
+
+import (
+	"fmt"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+)
+
+// governanceSystemPromptPolicyContextKey holds the system prompt policy enforced on the request, recorded in the response extra fields
+const governanceSystemPromptPolicyContextKey schemas.BifrostContextKey = "bf-governance-system-prompt-policy"
+
+// GetTeam retrieves a team by its ID (lock-free)
+func (gs *GovernanceStore) GetTeam(teamID string) (*configstoreTables.TableTeam, bool) {
+	value, exists := gs.teams.Load(teamID)
+	if !exists || value == nil {
+		return nil, false
+	}
+	team, ok := value.(*configstoreTables.TableTeam)
+	if !ok || team == nil {
+		return nil, false
+	}
+	return team, true
+}
+
+// systemPromptPolicy returns the system prompt policy of the virtual key, falling back to the policy of its team
+func (p *GovernancePlugin) systemPromptPolicy(vk *configstoreTables.TableVirtualKey) (*configstoreTables.SystemPromptPolicy, *schemas.BifrostSystemPromptPolicy) {
+	if vk.SystemPromptPolicy != nil {
+		return vk.SystemPromptPolicy, &schemas.BifrostSystemPromptPolicy{
+			Source:   "virtual_key",
+			SourceID: vk.ID,
+			Mode:     string(vk.SystemPromptPolicy.Mode),
+		}
+	}
+	if vk.TeamID == nil {
+		return nil, nil
+	}
+	team, ok := p.store.GetTeam(*vk.TeamID)
+	if !ok || team.SystemPromptPolicy == nil {
+		return nil, nil
+	}
+	return team.SystemPromptPolicy, &schemas.BifrostSystemPromptPolicy{
+		Source:   "team",
+		SourceID: team.ID,
+		Mode:     string(team.SystemPromptPolicy.Mode),
+	}
+}
+
+// enforceSystemPromptPolicy applies the system prompt policy of the virtual key to chat and responses requests.
+// The request is copied, so that fallbacks running the PreHook again start from the prompt sent by the client.
+// Requests carrying a system prompt are rejected when the policy blocks client prompts.
+func (p *GovernancePlugin) enforceSystemPromptPolicy(ctx *schemas.BifrostContext, req *schemas.BifrostRequest, virtualKeyValue string) (*schemas.BifrostRequest, *schemas.PluginShortCircuit) {
+	vk, ok := p.store.GetVirtualKey(virtualKeyValue)
+	if !ok {
+		return req, nil
+	}
+	policy, applied := p.systemPromptPolicy(vk)
+	if policy == nil || (req.ChatRequest == nil && req.ResponsesRequest == nil) {
+		return req, nil
+	}
+
+	enforced := *req
+	var hasClientPrompt bool
+	switch {
+	case req.ChatRequest != nil:
+		chatReq := *req.ChatRequest
+		hasClientPrompt = enforceChatSystemPrompt(&chatReq, policy)
+		enforced.ChatRequest = &chatReq
+	case req.ResponsesRequest != nil:
+		responsesReq := *req.ResponsesRequest
+		hasClientPrompt = enforceResponsesSystemPrompt(&responsesReq, policy)
+		enforced.ResponsesRequest = &responsesReq
+	}
+	if hasClientPrompt && policy.BlockClientPrompt {
+		return req, &schemas.PluginShortCircuit{
+			Error: &schemas.BifrostError{
+				Type:       bifrost.Ptr("system_prompt_blocked"),
+				StatusCode: bifrost.Ptr(400),
+				Error: &schemas.ErrorField{
+					Message: fmt.Sprintf("system prompts are not allowed by the policy of %s %s", applied.Source, applied.SourceID),
+				},
+			},
+		}
+	}
+	applied.ClientPromptReplaced = hasClientPrompt && policy.Mode == configstoreTables.SystemPromptPolicyModeReplace
+	ctx.SetValue(governanceSystemPromptPolicyContextKey, applied)
+	return &enforced, nil
+}
+
+// isSystemRole reports whether the role carries instructions rather than conversation turns
+func isSystemRole(role string) bool {
+	return role == string(schemas.ChatMessageRoleSystem) || role == string(schemas.ChatMessageRoleDeveloper)
+}
+
+// enforceChatSystemPrompt places the policy prompt as the first message of the chat request, dropping the
+// system and developer messages of the client with the replace mode. It reports whether the client sent any.
+func enforceChatSystemPrompt(req *schemas.BifrostChatRequest, policy *configstoreTables.SystemPromptPolicy) bool {
+	hasClientPrompt := false
+	input := make([]schemas.ChatMessage, 0, len(req.Input)+1)
+	if policy.Prompt != "" {
+		input = append(input, schemas.ChatMessage{
+			Role:    schemas.ChatMessageRoleSystem,
+			Content: &schemas.ChatMessageContent{ContentStr: bifrost.Ptr(policy.Prompt)},
+		})
+	}
+	for _, message := range req.Input {
+		if isSystemRole(string(message.Role)) {
+			hasClientPrompt = true
+			if policy.Mode == configstoreTables.SystemPromptPolicyModeReplace {
+				continue
+			}
+		}
+		input = append(input, message)
+	}
+	req.Input = input
+	return hasClientPrompt
+}
+
+// enforceResponsesSystemPrompt places the policy prompt in the instructions of the responses request, dropping the
+// instructions and the system and developer messages of the client with the replace mode. It reports whether the client sent any.
+func enforceResponsesSystemPrompt(req *schemas.BifrostResponsesRequest, policy *configstoreTables.SystemPromptPolicy) bool {
+	params := schemas.ResponsesParameters{}
+	if req.Params != nil {
+		params = *req.Params
+	}
+	hasClientPrompt := params.Instructions != nil && *params.Instructions != ""
+	input := make([]schemas.ResponsesMessage, 0, len(req.Input))
+	for _, message := range req.Input {
+		if message.Role != nil && isSystemRole(string(*message.Role)) {
+			hasClientPrompt = true
+			if policy.Mode == configstoreTables.SystemPromptPolicyModeReplace {
+				continue
+			}
+		}
+		input = append(input, message)
+	}
+	req.Input = input
+
+	instructions := policy.Prompt
+	if policy.Mode == configstoreTables.SystemPromptPolicyModePrepend && params.Instructions != nil && *params.Instructions != "" {
+		instructions = instructions + "\n\n" + *params.Instructions
+	}
+	if instructions == "" {
+		params.Instructions = nil
+	} else {
+		params.Instructions = &instructions
+	}
+	req.Params = &params
+	return hasClientPrompt
+}
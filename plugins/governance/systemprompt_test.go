@@ -0,0 +1,209 @@
+package governance
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+)
+
+// chatRoles returns the role and text of the messages of a chat request
+func chatRoles(req *schemas.BifrostChatRequest) []string {
+	var roles []string
+	for _, message := range req.Input {
+		roles = append(roles, string(message.Role)+":"+*message.Content.ContentStr)
+	}
+	return roles
+}
+
+// TestEnforceChatSystemPrompt tests that the policy prompt goes first, before the system prompt of the client with
+// the prepend mode and instead of it with the replace mode
+func TestEnforceChatSystemPrompt(t *testing.T) {
+	message := func(role schemas.ChatMessageRole, text string) schemas.ChatMessage {
+		return schemas.ChatMessage{Role: role, Content: &schemas.ChatMessageContent{ContentStr: bifrost.Ptr(text)}}
+	}
+	withClientPrompt := []schemas.ChatMessage{message(schemas.ChatMessageRoleSystem, "client"), message(schemas.ChatMessageRoleUser, "hi")}
+
+	tests := map[string]struct {
+		policy          configstoreTables.SystemPromptPolicy
+		input           []schemas.ChatMessage
+		expected        []string
+		hasClientPrompt bool
+	}{
+		"prepend": {
+			policy:          configstoreTables.SystemPromptPolicy{Mode: configstoreTables.SystemPromptPolicyModePrepend, Prompt: "policy"},
+			input:           withClientPrompt,
+			expected:        []string{"system:policy", "system:client", "user:hi"},
+			hasClientPrompt: true,
+		},
+		"replace": {
+			policy:          configstoreTables.SystemPromptPolicy{Mode: configstoreTables.SystemPromptPolicyModeReplace, Prompt: "policy"},
+			input:           []schemas.ChatMessage{message(schemas.ChatMessageRoleDeveloper, "client"), message(schemas.ChatMessageRoleUser, "hi")},
+			expected:        []string{"system:policy", "user:hi"},
+			hasClientPrompt: true,
+		},
+		"replace with empty prompt": {
+			policy:          configstoreTables.SystemPromptPolicy{Mode: configstoreTables.SystemPromptPolicyModeReplace},
+			input:           withClientPrompt,
+			expected:        []string{"user:hi"},
+			hasClientPrompt: true,
+		},
+		"no client prompt": {
+			policy:   configstoreTables.SystemPromptPolicy{Mode: configstoreTables.SystemPromptPolicyModeReplace, Prompt: "policy"},
+			input:    []schemas.ChatMessage{message(schemas.ChatMessageRoleUser, "hi")},
+			expected: []string{"system:policy", "user:hi"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := &schemas.BifrostChatRequest{Input: test.input}
+			hasClientPrompt := enforceChatSystemPrompt(req, &test.policy)
+			if hasClientPrompt != test.hasClientPrompt {
+				t.Errorf("Expected client prompt %v, got %v", test.hasClientPrompt, hasClientPrompt)
+			}
+			if got := chatRoles(req); !slices.Equal(got, test.expected) {
+				t.Errorf("Expected messages %v, got %v", test.expected, got)
+			}
+		})
+	}
+	if len(withClientPrompt) != 2 || *withClientPrompt[0].Content.ContentStr != "client" {
+		t.Errorf("Expected the messages of the client to be left untouched, got %v", withClientPrompt)
+	}
+}
+
+// TestEnforceResponsesSystemPrompt tests that the policy prompt is placed in the instructions, before those of the
+// client with the prepend mode and instead of them and of the system messages with the replace mode
+func TestEnforceResponsesSystemPrompt(t *testing.T) {
+	systemRole := schemas.ResponsesInputMessageRoleSystem
+	userRole := schemas.ResponsesInputMessageRoleUser
+	input := []schemas.ResponsesMessage{{Role: &systemRole}, {Role: &userRole}}
+
+	tests := map[string]struct {
+		policy       configstoreTables.SystemPromptPolicy
+		instructions *string
+		expected     *string
+		inputLen     int
+	}{
+		"prepend": {
+			policy:       configstoreTables.SystemPromptPolicy{Mode: configstoreTables.SystemPromptPolicyModePrepend, Prompt: "policy"},
+			instructions: bifrost.Ptr("client"),
+			expected:     bifrost.Ptr("policy\n\nclient"),
+			inputLen:     2,
+		},
+		"replace": {
+			policy:       configstoreTables.SystemPromptPolicy{Mode: configstoreTables.SystemPromptPolicyModeReplace, Prompt: "policy"},
+			instructions: bifrost.Ptr("client"),
+			expected:     bifrost.Ptr("policy"),
+			inputLen:     1,
+		},
+		"replace with empty prompt": {
+			policy:       configstoreTables.SystemPromptPolicy{Mode: configstoreTables.SystemPromptPolicyModeReplace},
+			instructions: bifrost.Ptr("client"),
+			expected:     nil,
+			inputLen:     1,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := &schemas.BifrostResponsesRequest{Input: input, Params: &schemas.ResponsesParameters{Instructions: test.instructions}}
+			if !enforceResponsesSystemPrompt(req, &test.policy) {
+				t.Error("Expected the client prompt to be reported")
+			}
+			if (req.Params.Instructions == nil) != (test.expected == nil) || (test.expected != nil && *req.Params.Instructions != *test.expected) {
+				t.Errorf("Expected instructions %v, got %v", test.expected, req.Params.Instructions)
+			}
+			if len(req.Input) != test.inputLen {
+				t.Errorf("Expected %d input messages, got %d", test.inputLen, len(req.Input))
+			}
+			if *test.instructions != "client" {
+				t.Errorf("Expected the instructions of the client to be left untouched, got %s", *test.instructions)
+			}
+		})
+	}
+}
+
+// TestEnforceSystemPromptPolicy tests that the policy of the virtual key, or of its team, is enforced on a copy of the
+// request and recorded in the response extra fields, and that system prompts are rejected when the policy blocks them
+func TestEnforceSystemPromptPolicy(t *testing.T) {
+	store, err := NewGovernanceStore(context.Background(), bifrost.NewDefaultLogger(schemas.LogLevelError), nil, &configstore.GovernanceConfig{
+		Teams: []configstoreTables.TableTeam{{ID: "team1", Name: "support",
+			SystemPromptPolicy: &configstoreTables.SystemPromptPolicy{Mode: configstoreTables.SystemPromptPolicyModePrepend, Prompt: "team"}}},
+		VirtualKeys: []configstoreTables.TableVirtualKey{
+			{ID: "vk-prepend", Name: "prepend", Value: "sk-bf-prepend", IsActive: true, TeamID: bifrost.Ptr("team1")},
+			{ID: "vk-replace", Name: "replace", Value: "sk-bf-replace", IsActive: true, TeamID: bifrost.Ptr("team1"),
+				SystemPromptPolicy: &configstoreTables.SystemPromptPolicy{Mode: configstoreTables.SystemPromptPolicyModeReplace, Prompt: "policy"}},
+			{ID: "vk-block", Name: "block", Value: "sk-bf-block", IsActive: true,
+				SystemPromptPolicy: &configstoreTables.SystemPromptPolicy{Mode: configstoreTables.SystemPromptPolicyModePrepend, Prompt: "policy", BlockClientPrompt: true}},
+			{ID: "vk-none", Name: "none", Value: "sk-bf-none", IsActive: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create governance store: %v", err)
+	}
+	p := &GovernancePlugin{store: store}
+
+	tests := map[string]struct {
+		virtualKey string
+		expected   []string
+		policy     *schemas.BifrostSystemPromptPolicy
+		blocked    bool
+	}{
+		"team prepend": {
+			virtualKey: "sk-bf-prepend",
+			expected:   []string{"system:team", "system:client", "user:hi"},
+			policy:     &schemas.BifrostSystemPromptPolicy{Source: "team", SourceID: "team1", Mode: "prepend"},
+		},
+		"virtual key replace": {
+			virtualKey: "sk-bf-replace",
+			expected:   []string{"system:policy", "user:hi"},
+			policy:     &schemas.BifrostSystemPromptPolicy{Source: "virtual_key", SourceID: "vk-replace", Mode: "replace", ClientPromptReplaced: true},
+		},
+		"block": {
+			virtualKey: "sk-bf-block",
+			expected:   []string{"system:client", "user:hi"},
+			blocked:    true,
+		},
+		"no policy": {
+			virtualKey: "sk-bf-none",
+			expected:   []string{"system:client", "user:hi"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := schemas.NewBifrostContext(context.Background(), schemas.NoDeadline)
+			defer ctx.Cancel()
+			req := &schemas.BifrostRequest{RequestType: schemas.ChatCompletionRequest, ChatRequest: &schemas.BifrostChatRequest{Input: []schemas.ChatMessage{
+				{Role: schemas.ChatMessageRoleSystem, Content: &schemas.ChatMessageContent{ContentStr: bifrost.Ptr("client")}},
+				{Role: schemas.ChatMessageRoleUser, Content: &schemas.ChatMessageContent{ContentStr: bifrost.Ptr("hi")}},
+			}}}
+
+			enforced, shortCircuit := p.enforceSystemPromptPolicy(ctx, req, test.virtualKey)
+			if (shortCircuit != nil) != test.blocked {
+				t.Fatalf("Expected blocked %v, got %+v", test.blocked, shortCircuit)
+			}
+			if test.blocked {
+				if shortCircuit.Error.StatusCode == nil || *shortCircuit.Error.StatusCode != 400 {
+					t.Errorf("Expected a 400 error, got %+v", shortCircuit.Error)
+				}
+				return
+			}
+			if got := chatRoles(enforced.ChatRequest); !slices.Equal(got, test.expected) {
+				t.Errorf("Expected messages %v, got %v", test.expected, got)
+			}
+			if got := chatRoles(req.ChatRequest); !slices.Equal(got, []string{"system:client", "user:hi"}) {
+				t.Errorf("Expected the request of the client to be left untouched, got %v", got)
+			}
+
+			result := &schemas.BifrostResponse{ChatResponse: &schemas.BifrostChatResponse{}}
+			p.PostHook(ctx, result, nil)
+			policy := result.GetExtraFields().SystemPromptPolicy
+			if (policy == nil) != (test.policy == nil) || (policy != nil && *policy != *test.policy) {
+				t.Errorf("Expected the policy %+v in the extra fields, got %+v", test.policy, policy)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package governance
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+)
+
+// governanceExcludeKeysContextKey holds the IDs of the test keys that reached their spend limit,
+// which the account leaves out of key selection
+const governanceExcludeKeysContextKey schemas.BifrostContextKey = "bf-governance-exclude-keys"
+
+// loadTestKeySpends loads the spend of all test keys into memory
+func (gs *GovernanceStore) loadTestKeySpends(spends []configstoreTables.TableKeySpend) {
+	for i := range spends {
+		spend := spends[i]
+		gs.testKeySpends.Store(spend.KeyID, &spend)
+	}
+}
+
+// GetTestKeySpends returns the spend of all test keys that served a request, sorted by key ID
+func (gs *GovernanceStore) GetTestKeySpends() []configstoreTables.TableKeySpend {
+	var spends []configstoreTables.TableKeySpend
+	gs.testKeySpends.Range(func(key, value interface{}) bool {
+		if spend, ok := value.(*configstoreTables.TableKeySpend); ok && spend != nil {
+			spends = append(spends, *spend)
+		}
+		return true
+	})
+	sort.Slice(spends, func(i, j int) bool { return spends[i].KeyID < spends[j].KeyID })
+	return spends
+}
+
+// GetExhaustedTestKeyIDs returns the IDs of the test keys disabled for reaching their spend limit (lock-free)
+func (gs *GovernanceStore) GetExhaustedTestKeyIDs() []string {
+	var keyIDs []string
+	gs.testKeySpends.Range(func(key, value interface{}) bool {
+		if spend, ok := value.(*configstoreTables.TableKeySpend); ok && spend != nil && spend.DisabledAt != nil {
+			keyIDs = append(keyIDs, spend.KeyID)
+		}
+		return true
+	})
+	return keyIDs
+}
+
+// AddTestKeySpend adds cost to the spend of a test key, disabling the key once its spend reaches spendLimit.
+// The in-memory spend is updated even if persisting it fails, so the hard-stop holds until restart.
+func (gs *GovernanceStore) AddTestKeySpend(ctx context.Context, keyID string, cost float64, spendLimit float64) (*configstoreTables.TableKeySpend, error) {
+	gs.testKeySpendMu.Lock()
+	defer gs.testKeySpendMu.Unlock()
+
+	spend := configstoreTables.TableKeySpend{KeyID: keyID}
+	if value, exists := gs.testKeySpends.Load(keyID); exists && value != nil {
+		if cached, ok := value.(*configstoreTables.TableKeySpend); ok && cached != nil {
+			spend = *cached
+		}
+	}
+	spend.Spend += cost
+	spend.UpdatedAt = time.Now()
+	if spend.DisabledAt == nil && spend.Spend >= spendLimit {
+		disabledAt := spend.UpdatedAt
+		spend.DisabledAt = &disabledAt
+	}
+	gs.testKeySpends.Store(keyID, &spend)
+
+	if gs.configStore != nil {
+		persisted := spend
+		if err := gs.configStore.UpdateKeySpend(ctx, &persisted); err != nil {
+			return &spend, fmt.Errorf("failed to save spend of test key %s: %w", keyID, err)
+		}
+	}
+	return &spend, nil
+}
+
+// ResetTestKeySpend resets the spend of a test key in memory, re-enabling it if it was disabled
+func (gs *GovernanceStore) ResetTestKeySpend(keyID string) {
+	gs.testKeySpendMu.Lock()
+	defer gs.testKeySpendMu.Unlock()
+	gs.testKeySpends.Delete(keyID)
+}
+
+// excludeExhaustedTestKeys marks the test keys that reached their spend limit for exclusion from key selection.
+// It applies to every request, with or without a virtual key.
+func (p *GovernancePlugin) excludeExhaustedTestKeys(ctx *schemas.BifrostContext) {
+	if ctx == nil {
+		return
+	}
+	if keyIDs := p.store.GetExhaustedTestKeyIDs(); len(keyIDs) > 0 {
+		ctx.SetValue(governanceExcludeKeysContextKey, keyIDs)
+	}
+}
+
+// trackTestKeySpend adds the cost of a response served with a test key to the key's spend.
+// Spend is only known once a request completes, so requests in flight when the limit is reached
+// can overshoot it; no new requests are routed to the key afterwards.
+func (p *GovernancePlugin) trackTestKeySpend(ctx *schemas.BifrostContext, result *schemas.BifrostResponse) {
+	if result == nil || p.modelCatalog == nil {
+		return
+	}
+	if isTest, ok := ctx.Value(schemas.BifrostContextKeySelectedKeyIsTest).(bool); !ok || !isTest {
+		return
+	}
+	keyID := getStringFromContext(ctx, schemas.BifrostContextKeySelectedKeyID)
+	spendLimit, ok := ctx.Value(schemas.BifrostContextKeySelectedKeySpendLimit).(float64)
+	if keyID == "" || !ok {
+		return
+	}
+	requestType := result.GetExtraFields().RequestType
+	if bifrost.IsStreamRequestType(requestType) && !bifrost.IsFinalChunk(ctx) {
+		return
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		cost := p.modelCatalog.CalculateCostWithCacheDebug(result)
+		if cost <= 0 {
+			return
+		}
+		spend, err := p.store.AddTestKeySpend(p.ctx, keyID, cost, spendLimit)
+		if err != nil {
+			p.logger.Warn("%v", err)
+		}
+		if spend.DisabledAt != nil && spend.Spend-cost < spendLimit {
+			p.logger.Warn("test key %s reached its spend limit of %.4f dollars (spent %.4f) and was disabled", keyID, spendLimit, spend.Spend)
+		}
+	}()
+}
@@ -32,6 +32,7 @@ type UsageUpdate struct {
 type UsageTracker struct {
 	store       *GovernanceStore
 	resolver    *BudgetResolver
+	endUsers    *EndUserLimiter
 	configStore configstore.ConfigStore
 	logger      schemas.Logger
 
@@ -39,15 +40,17 @@ type UsageTracker struct {
 	trackerCtx    context.Context
 	trackerCancel context.CancelFunc
 	resetTicker   *time.Ticker
+	syncTicker    *time.Ticker
 	done          chan struct{}
 	wg            sync.WaitGroup
 }
 
 // NewUsageTracker creates a new usage tracker for the hierarchical budget system
-func NewUsageTracker(ctx context.Context, store *GovernanceStore, resolver *BudgetResolver, configStore configstore.ConfigStore, logger schemas.Logger) *UsageTracker {
+func NewUsageTracker(ctx context.Context, store *GovernanceStore, resolver *BudgetResolver, endUsers *EndUserLimiter, configStore configstore.ConfigStore, logger schemas.Logger) *UsageTracker {
 	tracker := &UsageTracker{
 		store:       store,
 		resolver:    resolver,
+		endUsers:    endUsers,
 		configStore: configStore,
 		logger:      logger,
 		done:        make(chan struct{}),
@@ -108,6 +111,33 @@ func (t *UsageTracker) startWorkers(ctx context.Context) {
 	t.resetTicker = time.NewTicker(1 * time.Minute)
 	t.wg.Add(1)
 	go t.resetWorker(ctx)
+
+	// Shared counters sync, pulls the usage of the other replicas
+	if t.store.counters != nil {
+		t.syncTicker = time.NewTicker(t.store.counters.syncInterval)
+		t.wg.Add(1)
+		go t.syncWorker()
+	}
+}
+
+// syncWorker periodically pulls the usage counted, including that of the end users, and the plugin circuits opened by
+// the other replicas into the in-memory cache
+func (t *UsageTracker) syncWorker() {
+	defer t.wg.Done()
+
+	for {
+		select {
+		case <-t.syncTicker.C:
+			t.store.SyncSharedCounters()
+			if t.endUsers != nil {
+				t.endUsers.SyncSharedCounters()
+			}
+			t.store.counters.syncCircuits()
+
+		case <-t.done:
+			return
+		}
+	}
 }
 
 // resetWorker manages periodic resets of rate limit and usage counters
@@ -240,6 +270,9 @@ func (t *UsageTracker) Cleanup() error {
 	if t.resetTicker != nil {
 		t.resetTicker.Stop()
 	}
+	if t.syncTicker != nil {
+		t.syncTicker.Stop()
+	}
 	// Wait for workers to finish
 	t.wg.Wait()
 
@@ -307,10 +307,11 @@ func (p *JsonParserPlugin) deepCopyChatStreamResponseChoiceDelta(original *schem
 	}
 
 	result := &schemas.ChatStreamResponseChoiceDelta{
-		Role:      original.Role,
-		Thought:   original.Thought,   // Shallow copy
-		Refusal:   original.Refusal,   // Shallow copy
-		ToolCalls: original.ToolCalls, // Shallow copy - we don't modify tool calls
+		Role:             original.Role,
+		Thought:          original.Thought,          // Shallow copy
+		ThoughtSignature: original.ThoughtSignature, // Shallow copy
+		Refusal:          original.Refusal,          // Shallow copy
+		ToolCalls:        original.ToolCalls,        // Shallow copy - we don't modify tool calls
 	}
 
 	// Deep copy Content pointer if it exists (this is what we modify)
@@ -22,6 +22,11 @@ const (
 	PluginName = "logging"
 )
 
+const (
+	namespaceContextKey     schemas.BifrostContextKey = "bf-logging-namespace"
+	payloadKeyRefContextKey schemas.BifrostContextKey = "bf-logging-payload-key-ref"
+)
+
 // LogOperation represents the type of logging operation
 type LogOperation string
 
@@ -43,6 +48,7 @@ type UpdateLogData struct {
 	SpeechOutput        *schemas.BifrostSpeechResponse        // For non-streaming speech responses
 	TranscriptionOutput *schemas.BifrostTranscriptionResponse // For non-streaming transcription responses
 	RawResponse         interface{}
+	ProviderRequest     *schemas.BifrostProviderRequest // Set when the HTTP request sent to the provider was captured
 }
 
 // LogMessage represents a message in the logging queue
@@ -51,11 +57,13 @@ type LogMessage struct {
 	RequestID          string                             // Unique ID for the request
 	ParentRequestID    string                             // Unique ID for the parent request
 	NumberOfRetries    int                                // Number of retries
+	IsTestKey          bool                               // Whether the request was served with a test key
 	FallbackIndex      int                                // Fallback index
 	SelectedKeyID      string                             // Selected key ID
 	SelectedKeyName    string                             // Selected key name
 	VirtualKeyID       string                             // Virtual key ID
 	VirtualKeyName     string                             // Virtual key name
+	PayloadKeyRef      string                             // Key reference the payload is encrypted with, empty for plaintext
 	Timestamp          time.Time                          // Of the preHook/postHook call
 	Latency            int64                              // For latency updates
 	InitialData        *InitialLogData                    // For create operations
@@ -75,11 +83,19 @@ type InitialLogData struct {
 	SpeechInput           *schemas.SpeechInput
 	TranscriptionInput    *schemas.TranscriptionInput
 	Tools                 []schemas.ChatTool
+	Namespace             string
+	PayloadKeyRef         string
+	EndUser               string            // End user of the request, kept even without content logging to honor deletion requests
+	Tags                  map[string]string // Tags of the request in the allow-list, kept even without content logging for cost attribution
 }
 
 // LogCallback is a function that gets called when a new log entry is created
 type LogCallback func(*logstore.Log)
 
+// PayloadKeyResolver returns the namespace of a request and the key reference its log payloads are
+// envelope encrypted with. An empty key reference stores the payloads in plaintext.
+type PayloadKeyResolver func(ctx context.Context) (namespace string, keyRef string)
+
 type Config struct {
 	DisableContentLogging *bool `json:"disable_content_logging"`
 }
@@ -95,6 +111,7 @@ type LoggerPlugin struct {
 	wg                    sync.WaitGroup
 	logger                schemas.Logger
 	logCallback           LogCallback
+	payloadKeyResolver    atomic.Pointer[PayloadKeyResolver]
 	droppedRequests       atomic.Int64
 	cleanupTicker         *time.Ticker           // Ticker for cleaning up old processing logs
 	logMsgPool            sync.Pool              // Pool for reusing LogMessage structs
@@ -178,6 +195,11 @@ func (p *LoggerPlugin) SetLogCallback(callback LogCallback) {
 	p.logCallback = callback
 }
 
+// SetPayloadKeyResolver sets the function resolving the namespace and payload encryption key of each request
+func (p *LoggerPlugin) SetPayloadKeyResolver(resolver PayloadKeyResolver) {
+	p.payloadKeyResolver.Store(&resolver)
+}
+
 // GetName returns the name of the plugin
 func (p *LoggerPlugin) GetName() string {
 	return PluginName
@@ -235,6 +257,15 @@ func (p *LoggerPlugin) PreHook(ctx *schemas.BifrostContext, req *schemas.Bifrost
 		Provider: string(provider),
 		Model:    model,
 		Object:   string(req.RequestType),
+		EndUser:  getStringFromContext(ctx, schemas.BifrostContextKeyEndUser),
+	}
+	initialData.Tags, _ = ctx.Value(schemas.BifrostContextKeyRequestTags).(map[string]string)
+
+	// Resolve the namespace and payload key once, the PostHook reuses them from the context
+	if resolver := p.payloadKeyResolver.Load(); resolver != nil && *resolver != nil {
+		initialData.Namespace, initialData.PayloadKeyRef = (*resolver)(ctx)
+		ctx.SetValue(namespaceContextKey, initialData.Namespace)
+		ctx.SetValue(payloadKeyRefContextKey, initialData.PayloadKeyRef)
 	}
 
 	if p.disableContentLogging == nil || !*p.disableContentLogging {
@@ -317,6 +348,15 @@ func (p *LoggerPlugin) PreHook(ctx *schemas.BifrostContext, req *schemas.Bifrost
 					Status:                      "processing",
 					Stream:                      false, // Initially false, will be updated if streaming
 					CreatedAt:                   msg.Timestamp,
+					Namespace:                   msg.InitialData.Namespace,
+					PayloadEncrypted:            msg.InitialData.PayloadKeyRef != "",
+				}
+				if msg.InitialData.EndUser != "" {
+					initialEntry.EndUser = &msg.InitialData.EndUser
+				}
+				initialEntry.TagsParsed = msg.InitialData.Tags
+				if initialEntry.PayloadEncrypted {
+					initialEntry.RedactPayload()
 				}
 				p.logCallback(initialEntry)
 			}
@@ -357,6 +397,8 @@ func (p *LoggerPlugin) PostHook(ctx *schemas.BifrostContext, result *schemas.Bif
 	virtualKeyID := getStringFromContext(ctx, schemas.BifrostContextKey("bf-governance-virtual-key-id"))
 	virtualKeyName := getStringFromContext(ctx, schemas.BifrostContextKey("bf-governance-virtual-key-name"))
 	numberOfRetries := getIntFromContext(ctx, schemas.BifrostContextKeyNumberOfRetries)
+	isTestKey, _ := ctx.Value(schemas.BifrostContextKeySelectedKeyIsTest).(bool)
+	payloadKeyRef := getStringFromContext(ctx, payloadKeyRefContextKey)
 
 	go func() {
 		requestType, _, _ := bifrost.GetResponseFields(result, bifrostErr)
@@ -368,6 +410,8 @@ func (p *LoggerPlugin) PostHook(ctx *schemas.BifrostContext, result *schemas.Bif
 		logMsg.SelectedKeyName = selectedKeyName
 		logMsg.VirtualKeyName = virtualKeyName
 		logMsg.NumberOfRetries = numberOfRetries
+		logMsg.IsTestKey = isTestKey
+		logMsg.PayloadKeyRef = payloadKeyRef
 		defer p.putLogMessage(logMsg) // Return to pool when done
 
 		if result != nil {
@@ -397,8 +441,10 @@ func (p *LoggerPlugin) PostHook(ctx *schemas.BifrostContext, result *schemas.Bif
 					logMsg.VirtualKeyID,
 					logMsg.VirtualKeyName,
 					logMsg.NumberOfRetries,
+					logMsg.IsTestKey,
 					logMsg.SemanticCacheDebug,
 					logMsg.UpdateData,
+					logMsg.PayloadKeyRef,
 				)
 			})
 			if processingErr != nil {
@@ -436,9 +482,11 @@ func (p *LoggerPlugin) PostHook(ctx *schemas.BifrostContext, result *schemas.Bif
 						logMsg.VirtualKeyID,
 						logMsg.VirtualKeyName,
 						logMsg.NumberOfRetries,
+						logMsg.IsTestKey,
 						logMsg.SemanticCacheDebug,
 						logMsg.StreamResponse,
 						streamResponse.Type == streaming.StreamResponseTypeFinal,
+						logMsg.PayloadKeyRef,
 					)
 				})
 				if processingErr != nil {
@@ -499,6 +547,7 @@ func (p *LoggerPlugin) PostHook(ctx *schemas.BifrostContext, result *schemas.Bif
 					if extraFields.RawResponse != nil {
 						updateData.RawResponse = extraFields.RawResponse
 					}
+					updateData.ProviderRequest = extraFields.ProviderRequest
 					if result.TextCompletionResponse != nil {
 						if len(result.TextCompletionResponse.Choices) > 0 {
 							choice := result.TextCompletionResponse.Choices[0]
@@ -563,8 +612,10 @@ func (p *LoggerPlugin) PostHook(ctx *schemas.BifrostContext, result *schemas.Bif
 					logMsg.VirtualKeyID,
 					logMsg.VirtualKeyName,
 					logMsg.NumberOfRetries,
+					logMsg.IsTestKey,
 					logMsg.SemanticCacheDebug,
 					logMsg.UpdateData,
+					logMsg.PayloadKeyRef,
 				)
 			})
 			if processingErr != nil {
@@ -3,11 +3,13 @@ package logging
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/bytedance/sonic"
 	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/encrypt"
 	"github.com/maximhq/bifrost/framework/logstore"
 	"github.com/maximhq/bifrost/framework/streaming"
 )
@@ -38,10 +40,21 @@ func (p *LoggerPlugin) insertInitialLogEntry(
 		ToolsParsed:                 data.Tools,
 		SpeechInputParsed:           data.SpeechInput,
 		TranscriptionInputParsed:    data.TranscriptionInput,
+		Namespace:                   data.Namespace,
 	}
 	if parentRequestID != "" {
 		entry.ParentRequestID = &parentRequestID
 	}
+	if data.EndUser != "" {
+		entry.EndUser = &data.EndUser
+	}
+	entry.TagsParsed = data.Tags
+	if data.PayloadKeyRef != "" {
+		// The payload is dropped if it cannot be encrypted, the rest of the entry is still logged
+		if err := entry.EncryptPayload(ctx, data.PayloadKeyRef); err != nil {
+			p.logPayloadEncryptionError(requestID, err)
+		}
+	}
 	return p.store.CreateIfNotExists(ctx, entry)
 }
 
@@ -55,8 +68,10 @@ func (p *LoggerPlugin) updateLogEntry(
 	virtualKeyID string,
 	virtualKeyName string,
 	numberOfRetries int,
+	isTestKey bool,
 	cacheDebug *schemas.BifrostCacheDebug,
 	data *UpdateLogData,
+	payloadKeyRef string,
 ) error {
 	updates := make(map[string]interface{})
 	updates["selected_key_id"] = selectedKeyID
@@ -74,6 +89,9 @@ func (p *LoggerPlugin) updateLogEntry(
 	if numberOfRetries != 0 {
 		updates["number_of_retries"] = numberOfRetries
 	}
+	if isTestKey {
+		updates["is_test_key"] = true
+	}
 	// Handle JSON fields by setting them on a temporary entry and serializing
 	tempEntry := &logstore.Log{}
 	if data.ChatOutput != nil {
@@ -169,6 +187,21 @@ func (p *LoggerPlugin) updateLogEntry(
 		}
 	}
 
+	if data.ProviderRequest != nil {
+		tempEntry.ProviderRequestParsed = data.ProviderRequest
+		if err := tempEntry.SerializeFields(); err != nil {
+			p.logger.Error("failed to serialize provider request: %v", err)
+		} else {
+			updates["provider_request"] = tempEntry.ProviderRequest
+		}
+	}
+
+	if payloadKeyRef != "" {
+		if err := logstore.EncryptPayloadUpdates(ctx, payloadKeyRef, updates); err != nil {
+			p.logPayloadEncryptionError(requestID, err)
+		}
+	}
+
 	return p.store.Update(ctx, requestID, updates)
 }
 
@@ -181,9 +214,11 @@ func (p *LoggerPlugin) updateStreamingLogEntry(
 	virtualKeyID string,
 	virtualKeyName string,
 	numberOfRetries int,
+	isTestKey bool,
 	cacheDebug *schemas.BifrostCacheDebug,
 	streamResponse *streaming.ProcessedStreamResponse,
 	isFinalChunk bool,
+	payloadKeyRef string,
 ) error {
 	p.logger.Debug("[logging] updating streaming log entry %s", requestID)
 	updates := make(map[string]interface{})
@@ -198,6 +233,9 @@ func (p *LoggerPlugin) updateStreamingLogEntry(
 	if numberOfRetries != 0 {
 		updates["number_of_retries"] = numberOfRetries
 	}
+	if isTestKey {
+		updates["is_test_key"] = true
+	}
 	// Handle error case first
 	if streamResponse.Data.ErrorDetails != nil {
 		tempEntry := &logstore.Log{}
@@ -294,6 +332,11 @@ func (p *LoggerPlugin) updateStreamingLogEntry(
 			}
 		}
 	}
+	if payloadKeyRef != "" {
+		if err := logstore.EncryptPayloadUpdates(ctx, payloadKeyRef, updates); err != nil {
+			p.logPayloadEncryptionError(requestID, err)
+		}
+	}
 	// Only perform update if there's something to update
 	if len(updates) > 0 {
 		return p.store.Update(ctx, requestID, updates)
@@ -301,12 +344,26 @@ func (p *LoggerPlugin) updateStreamingLogEntry(
 	return nil
 }
 
-// getLogEntry retrieves a log entry by ID using GORM
+// logPayloadEncryptionError logs a failure to encrypt a log payload, which is then dropped.
+// Revoked keys are expected to fail on every request of the namespace, so they are only logged at debug level.
+func (p *LoggerPlugin) logPayloadEncryptionError(requestID string, err error) {
+	if errors.Is(err, encrypt.ErrKeyRevoked) {
+		p.logger.Debug("dropped payload of log entry %s: %v", requestID, err)
+		return
+	}
+	p.logger.Warn("dropped payload of log entry %s: %v", requestID, err)
+}
+
+// getLogEntry retrieves a log entry by ID using GORM.
+// Encrypted payloads are redacted since the entry is only used for the log callback.
 func (p *LoggerPlugin) getLogEntry(ctx context.Context, requestID string) (*logstore.Log, error) {
 	entry, err := p.store.FindFirst(ctx, map[string]interface{}{"id": requestID})
 	if err != nil {
 		return nil, err
 	}
+	if entry.PayloadEncrypted {
+		entry.RedactPayload()
+	}
 	return entry, nil
 }
 
@@ -331,6 +388,11 @@ func (p *LoggerPlugin) GetStats(ctx context.Context, filters logstore.SearchFilt
 	return p.store.GetStats(ctx, filters)
 }
 
+// GetKeyModelUsage aggregates the logs matching the given filters by provider key and model
+func (p *LoggerPlugin) GetKeyModelUsage(ctx context.Context, filters logstore.SearchFilters) ([]logstore.KeyModelUsage, error) {
+	return p.store.GetKeyModelUsage(ctx, filters)
+}
+
 // GetAvailableModels returns all unique models from logs
 func (p *LoggerPlugin) GetAvailableModels(ctx context.Context) []string {
 	result, err := p.store.FindAll(ctx, "model IS NOT NULL AND model != ''", "model")
@@ -42,5 +42,6 @@ func (p *LoggerPlugin) putUpdateLogData(data *UpdateLogData) {
 	data.TranscriptionOutput = nil
 	data.EmbeddingOutput = nil
 	data.Cost = nil
+	data.ProviderRequest = nil
 	p.updateDataPool.Put(data)
 }
@@ -26,6 +26,9 @@ type LogManager interface {
 	// GetStats calculates statistics for logs matching the given filters
 	GetStats(ctx context.Context, filters *logstore.SearchFilters) (*logstore.SearchStats, error)
 
+	// GetKeyModelUsage aggregates the logs matching the filters by provider key and model
+	GetKeyModelUsage(ctx context.Context, filters *logstore.SearchFilters) ([]logstore.KeyModelUsage, error)
+
 	// Get the number of dropped requests
 	GetDroppedRequests(ctx context.Context) int64
 
@@ -38,11 +41,20 @@ type LogManager interface {
 	// GetAvailableVirtualKeys returns all unique virtual key ID-Name pairs from logs
 	GetAvailableVirtualKeys(ctx context.Context) []KeyPair
 
+	// GetLog returns a log entry by its ID, with its payload decrypted
+	GetLog(ctx context.Context, id string) (*logstore.Log, error)
+
 	// DeleteLog deletes a log entry by its ID
 	DeleteLog(ctx context.Context, id string) error
 
 	// DeleteLogs deletes multiple log entries by their IDs
 	DeleteLogs(ctx context.Context, ids []string) error
+
+	// AddFeedback stores the feedback of the request of a log, with the experiment and variant of the request
+	AddFeedback(ctx context.Context, feedback *logstore.Feedback) error
+
+	// GetFeedbackSummaries aggregates the feedback of the requests of an experiment by variant
+	GetFeedbackSummaries(ctx context.Context, experiment string) ([]logstore.FeedbackSummary, error)
 }
 
 // PluginLogManager implements LogManager interface wrapping the plugin
@@ -64,6 +76,13 @@ func (p *PluginLogManager) GetStats(ctx context.Context, filters *logstore.Searc
 	return p.plugin.GetStats(ctx, *filters)
 }
 
+func (p *PluginLogManager) GetKeyModelUsage(ctx context.Context, filters *logstore.SearchFilters) ([]logstore.KeyModelUsage, error) {
+	if filters == nil {
+		return nil, fmt.Errorf("filters cannot be nil")
+	}
+	return p.plugin.GetKeyModelUsage(ctx, *filters)
+}
+
 func (p *PluginLogManager) GetDroppedRequests(ctx context.Context) int64 {
 	return p.plugin.droppedRequests.Load()
 }
@@ -83,6 +102,21 @@ func (p *PluginLogManager) GetAvailableVirtualKeys(ctx context.Context) []KeyPai
 	return p.plugin.GetAvailableVirtualKeys(ctx)
 }
 
+// GetLog returns a log from the log store, with its payload decrypted
+func (p *PluginLogManager) GetLog(ctx context.Context, id string) (*logstore.Log, error) {
+	if p.plugin == nil || p.plugin.store == nil {
+		return nil, fmt.Errorf("log store not initialized")
+	}
+	entry, err := p.plugin.store.FindFirst(ctx, map[string]interface{}{"id": id})
+	if err != nil {
+		return nil, err
+	}
+	if err := entry.DecryptPayload(ctx); err != nil {
+		return nil, fmt.Errorf("failed to decrypt the payload of log %s: %w", id, err)
+	}
+	return entry, nil
+}
+
 // DeleteLog deletes a log from the log store
 func (p *PluginLogManager) DeleteLog(ctx context.Context, id string) error {
 	if p.plugin == nil || p.plugin.store == nil {
@@ -99,6 +133,35 @@ func (p *PluginLogManager) DeleteLogs(ctx context.Context, ids []string) error {
 	return p.plugin.store.DeleteLogs(ctx, ids)
 }
 
+// AddFeedback stores the feedback of a request, attributed to the experiment and variant recorded in the tags of its
+// log. The log is written asynchronously, a request answered just before its feedback is retried for a few seconds.
+// Returns logstore.ErrNotFound if the request has no log.
+func (p *PluginLogManager) AddFeedback(ctx context.Context, feedback *logstore.Feedback) error {
+	if p.plugin == nil || p.plugin.store == nil {
+		return fmt.Errorf("log store not initialized")
+	}
+	var entry *logstore.Log
+	err := retryOnNotFound(ctx, func() error {
+		var err error
+		entry, err = p.plugin.store.FindFirst(ctx, map[string]interface{}{"id": feedback.RequestID}, "id", "tags")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	feedback.Experiment = entry.TagsParsed[schemas.ExperimentTag]
+	feedback.Variant = entry.TagsParsed[schemas.ExperimentVariantTag]
+	return p.plugin.store.CreateFeedback(ctx, feedback)
+}
+
+// GetFeedbackSummaries aggregates the feedback of the requests of an experiment by variant
+func (p *PluginLogManager) GetFeedbackSummaries(ctx context.Context, experiment string) ([]logstore.FeedbackSummary, error) {
+	if p.plugin == nil || p.plugin.store == nil {
+		return nil, fmt.Errorf("log store not initialized")
+	}
+	return p.plugin.store.GetFeedbackSummaries(ctx, experiment)
+}
+
 // GetPluginLogManager returns a LogManager interface for this plugin
 func (p *LoggerPlugin) GetPluginLogManager() *PluginLogManager {
 	return &PluginLogManager{
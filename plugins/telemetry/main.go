@@ -314,6 +314,7 @@ func (p *PrometheusPlugin) PostHook(ctx *schemas.BifrostContext, result *schemas
 	teamName := getStringFromContext(ctx, schemas.BifrostContextKey("bf-governance-team-name"))
 	customerID := getStringFromContext(ctx, schemas.BifrostContextKey("bf-governance-customer-id"))
 	customerName := getStringFromContext(ctx, schemas.BifrostContextKey("bf-governance-customer-name"))
+	requestID := getStringFromContext(ctx, schemas.BifrostContextKeyRequestID)
 
 	// Calculate cost and record metrics in a separate goroutine to avoid blocking the main thread
 	go func() {
@@ -341,6 +342,14 @@ func (p *PrometheusPlugin) PostHook(ctx *schemas.BifrostContext, result *schemas
 				}
 			}
 		}
+		// Tags of the request fill the custom labels of the same name, their values are bounded by the allow-list of tags
+		if tags, ok := (*ctx).Value(schemas.BifrostContextKeyRequestTags).(map[string]string); ok {
+			for _, key := range p.customLabels {
+				if value, ok := tags[key]; ok {
+					labelValues[key] = value
+				}
+			}
+		}
 
 		// Get label values in the correct order (cache_type will be handled separately for cache hits)
 		promLabelValues := getPrometheusLabelValues(append(p.defaultBifrostLabels, p.customLabels...), labelValues)
@@ -358,9 +367,9 @@ func (p *PrometheusPlugin) PostHook(ctx *schemas.BifrostContext, result *schemas
 				if result != nil {
 					extraFields := result.GetExtraFields()
 					if extraFields.ChunkIndex == 0 {
-						p.StreamFirstTokenLatencySeconds.WithLabelValues(promLabelValues...).Observe(float64(extraFields.Latency) / 1000.0)
+						observeWithRequestID(p.StreamFirstTokenLatencySeconds.WithLabelValues(promLabelValues...), float64(extraFields.Latency)/1000.0, requestID)
 					} else {
-						p.StreamInterTokenLatencySeconds.WithLabelValues(promLabelValues...).Observe(float64(extraFields.Latency) / 1000.0)
+						observeWithRequestID(p.StreamInterTokenLatencySeconds.WithLabelValues(promLabelValues...), float64(extraFields.Latency)/1000.0, requestID)
 					}
 				}
 				return // Exit goroutine for intermediate chunks
@@ -380,7 +389,7 @@ func (p *PrometheusPlugin) PostHook(ctx *schemas.BifrostContext, result *schemas
 		latencyLabelValues = append(latencyLabelValues, promLabelValues[:len(p.defaultBifrostLabels)]...) // all default labels
 		latencyLabelValues = append(latencyLabelValues, strconv.FormatBool(bifrostErr == nil))            // is_success
 		latencyLabelValues = append(latencyLabelValues, promLabelValues[len(p.defaultBifrostLabels):]...) // then custom labels
-		p.UpstreamLatencySeconds.WithLabelValues(latencyLabelValues...).Observe(duration)
+		observeWithRequestID(p.UpstreamLatencySeconds.WithLabelValues(latencyLabelValues...), duration, requestID)
 
 		// Record cost using the dedicated cost counter
 		if cost > 0 {
@@ -495,7 +504,7 @@ func (p *PrometheusPlugin) HTTPMiddleware(handler fasthttp.RequestHandler) fasth
 
 		// Record all metrics with prometheus labels
 		p.HTTPRequestsTotal.WithLabelValues(promLabelValues...).Inc()
-		p.HTTPRequestDuration.WithLabelValues(promLabelValues...).Observe(duration)
+		observeWithRequestID(p.HTTPRequestDuration.WithLabelValues(promLabelValues...), duration, string(ctx.Response.Header.Peek("x-request-id")))
 		if reqSize >= 0 {
 			safeObserve(p.HTTPRequestSizeBytes, reqSize, promLabelValues...)
 		}
@@ -8,6 +8,7 @@ import (
 	"log"
 	"math"
 	"strings"
+	"unicode/utf8"
 
 	schemas "github.com/maximhq/bifrost/core/schemas"
 	"github.com/prometheus/client_golang/prometheus"
@@ -69,6 +70,20 @@ func safeObserve(histogram *prometheus.HistogramVec, value float64, labels ...st
 	}
 }
 
+// maxExemplarRequestIDLength bounds the request IDs attached to exemplars, whose labels are limited to 128 runes
+const maxExemplarRequestIDLength = 64
+
+// observeWithRequestID records a value in a histogram with the ID of its request as exemplar, so that an observation
+// can be followed to the logs of its request. The value is recorded without exemplar when the ID cannot be attached.
+func observeWithRequestID(observer prometheus.Observer, value float64, requestID string) {
+	exemplarObserver, ok := observer.(prometheus.ExemplarObserver)
+	if !ok || requestID == "" || !utf8.ValidString(requestID) || utf8.RuneCountInString(requestID) > maxExemplarRequestIDLength {
+		observer.Observe(value)
+		return
+	}
+	exemplarObserver.ObserveWithExemplar(value, prometheus.Labels{"request_id": requestID})
+}
+
 // getStringFromContext safely extracts a string value from context
 func getStringFromContext(ctx *schemas.BifrostContext, key any) string {
 	if value := ctx.Value(key); value != nil {
@@ -31,6 +31,13 @@ type ConfigManager interface {
 	ReloadProxyConfig(ctx context.Context, config *configstoreTables.GlobalProxyConfig) error
 }
 
+// UpdateConfigRequest is the request body for updating the client, framework and auth configs
+type UpdateConfigRequest struct {
+	ClientConfig    configstore.ClientConfig               `json:"client_config"`
+	FrameworkConfig configstoreTables.TableFrameworkConfig `json:"framework_config"`
+	AuthConfig      *configstore.AuthConfig                `json:"auth_config"`
+}
+
 // ConfigHandler manages runtime configuration updates for Bifrost.
 // It provides endpoints to update and retrieve settings persisted via the ConfigStore backed by sql database.
 type ConfigHandler struct {
@@ -167,12 +174,10 @@ func (h *ConfigHandler) updateConfig(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
-	payload := struct {
-		ClientConfig    configstore.ClientConfig               `json:"client_config"`
-		FrameworkConfig configstoreTables.TableFrameworkConfig `json:"framework_config"`
-		AuthConfig      *configstore.AuthConfig                `json:"auth_config"`
-	}{}
-
+	if !validateConfigPayload(ctx, "config") {
+		return
+	}
+	var payload UpdateConfigRequest
 	if err := json.Unmarshal(ctx.PostBody(), &payload); err != nil {
 		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid request format: %v", err))
 		return
@@ -202,6 +207,156 @@ func (h *ConfigHandler) updateConfig(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	// Checking the direct key policy
+	if policy := payload.ClientConfig.DirectKeyPolicy; policy != nil &&
+		(slices.Contains(policy.AllowedProviders, "") || slices.Contains(policy.BlockedModels, "") || slices.Contains(policy.RequiredPlugins, "")) {
+		logger.Warn("direct key policy entries cannot be empty")
+		SendError(ctx, fasthttp.StatusBadRequest, "direct key policy entries cannot be empty")
+		return
+	}
+
+	// Checking the image input limits
+	if imageInputs := payload.ClientConfig.ImageInputs; imageInputs != nil && (imageInputs.MaxImageBytes < 0 || imageInputs.FetchTimeoutSeconds < 0) {
+		logger.Warn("image input limits cannot be negative")
+		SendError(ctx, fasthttp.StatusBadRequest, "image input limits cannot be negative")
+		return
+	}
+
+	// Checking the agent config
+	if agent := payload.ClientConfig.Agent; agent != nil {
+		if err := agent.Validate(); err != nil {
+			logger.Warn(fmt.Sprintf("invalid agent config: %v", err))
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("invalid agent config: %v", err))
+			return
+		}
+	}
+
+	// Checking the context window config
+	if contextWindow := payload.ClientConfig.ContextWindow; contextWindow != nil {
+		if err := contextWindow.Validate(); err != nil {
+			logger.Warn(fmt.Sprintf("invalid context window config: %v", err))
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("invalid context window config: %v", err))
+			return
+		}
+	}
+
+	// Checking the prompt compression config
+	if promptCompression := payload.ClientConfig.PromptCompression; promptCompression != nil {
+		if err := promptCompression.Validate(); err != nil {
+			logger.Warn(fmt.Sprintf("invalid prompt compression config: %v", err))
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("invalid prompt compression config: %v", err))
+			return
+		}
+	}
+
+	// Checking the stream failover config
+	if streamFailover := payload.ClientConfig.StreamFailover; streamFailover != nil {
+		if err := streamFailover.Validate(); err != nil {
+			logger.Warn(fmt.Sprintf("invalid stream failover config: %v", err))
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("invalid stream failover config: %v", err))
+			return
+		}
+	}
+
+	// Checking the stream backpressure config
+	if streamBackpressure := payload.ClientConfig.StreamBackpressure; streamBackpressure != nil {
+		if err := streamBackpressure.Validate(); err != nil {
+			logger.Warn(fmt.Sprintf("invalid stream backpressure config: %v", err))
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("invalid stream backpressure config: %v", err))
+			return
+		}
+	}
+
+	// Checking the idempotency config
+	if idempotency := payload.ClientConfig.Idempotency; idempotency != nil {
+		if err := idempotency.Validate(); err != nil {
+			logger.Warn(fmt.Sprintf("invalid idempotency config: %v", err))
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("invalid idempotency config: %v", err))
+			return
+		}
+	}
+
+	// Checking the endpoint policy
+	if endpointPolicy := payload.ClientConfig.EndpointPolicy; endpointPolicy != nil {
+		if err := endpointPolicy.Validate(); err != nil {
+			logger.Warn(fmt.Sprintf("invalid endpoint policy: %v", err))
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("invalid endpoint policy: %v", err))
+			return
+		}
+	}
+
+	// Checking the chaos config
+	if chaos := payload.ClientConfig.Chaos; chaos != nil {
+		if err := chaos.Validate(); err != nil {
+			logger.Warn(fmt.Sprintf("invalid chaos config: %v", err))
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("invalid chaos config: %v", err))
+			return
+		}
+	}
+
+	// Checking the list models cache config
+	if listModelsCache := payload.ClientConfig.ListModelsCache; listModelsCache != nil {
+		if err := listModelsCache.Validate(); err != nil {
+			logger.Warn(fmt.Sprintf("invalid list models cache config: %v", err))
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("invalid list models cache config: %v", err))
+			return
+		}
+	}
+
+	// Checking the responses state config
+	if responsesState := payload.ClientConfig.ResponsesState; responsesState != nil {
+		if err := responsesState.Validate(); err != nil {
+			logger.Warn(fmt.Sprintf("invalid responses state config: %v", err))
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("invalid responses state config: %v", err))
+			return
+		}
+	}
+
+	// Checking the request tags config
+	if requestTags := payload.ClientConfig.RequestTags; requestTags != nil {
+		if err := requestTags.Validate(); err != nil {
+			logger.Warn(fmt.Sprintf("invalid request tags config: %v", err))
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("invalid request tags config: %v", err))
+			return
+		}
+	}
+
+	// Checking the partial failures config
+	if partialFailures := payload.ClientConfig.PartialFailures; partialFailures != nil {
+		if err := partialFailures.Validate(); err != nil {
+			logger.Warn(fmt.Sprintf("invalid partial failures config: %v", err))
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("invalid partial failures config: %v", err))
+			return
+		}
+	}
+
+	// Checking the diagnostics config
+	if diagnostics := payload.ClientConfig.Diagnostics; diagnostics != nil {
+		if err := diagnostics.Validate(); err != nil {
+			logger.Warn(fmt.Sprintf("invalid diagnostics config: %v", err))
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("invalid diagnostics config: %v", err))
+			return
+		}
+	}
+
+	// Checking the SLO config
+	if slos := payload.ClientConfig.SLOs; slos != nil {
+		if err := slos.Validate(); err != nil {
+			logger.Warn(fmt.Sprintf("invalid slo config: %v", err))
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("invalid slo config: %v", err))
+			return
+		}
+	}
+
+	// Checking the streaming config
+	if streaming := payload.ClientConfig.Streaming; streaming != nil {
+		if err := streaming.Validate(); err != nil {
+			logger.Warn(fmt.Sprintf("invalid streaming config: %v", err))
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("invalid streaming config: %v", err))
+			return
+		}
+	}
+
 	// Get current config with proper locking
 	currentConfig := h.store.ClientConfig
 	updatedConfig := currentConfig
@@ -228,6 +383,24 @@ func (h *ConfigHandler) updateConfig(ctx *fasthttp.RequestCtx) {
 	updatedConfig.EnableGovernance = payload.ClientConfig.EnableGovernance
 	updatedConfig.EnforceGovernanceHeader = payload.ClientConfig.EnforceGovernanceHeader
 	updatedConfig.AllowDirectKeys = payload.ClientConfig.AllowDirectKeys
+	updatedConfig.DirectKeyPolicy = payload.ClientConfig.DirectKeyPolicy
+	updatedConfig.ImageInputs = payload.ClientConfig.ImageInputs
+	updatedConfig.Agent = payload.ClientConfig.Agent
+	updatedConfig.ContextWindow = payload.ClientConfig.ContextWindow
+	updatedConfig.PromptCompression = payload.ClientConfig.PromptCompression
+	updatedConfig.Streaming = payload.ClientConfig.Streaming
+	updatedConfig.StreamFailover = payload.ClientConfig.StreamFailover
+	updatedConfig.StreamBackpressure = payload.ClientConfig.StreamBackpressure
+	updatedConfig.ResponseBuffering = payload.ClientConfig.ResponseBuffering
+	updatedConfig.Idempotency = payload.ClientConfig.Idempotency
+	updatedConfig.EndpointPolicy = payload.ClientConfig.EndpointPolicy
+	updatedConfig.Chaos = payload.ClientConfig.Chaos
+	updatedConfig.ListModelsCache = payload.ClientConfig.ListModelsCache
+	updatedConfig.ResponsesState = payload.ClientConfig.ResponsesState
+	updatedConfig.RequestTags = payload.ClientConfig.RequestTags
+	updatedConfig.PartialFailures = payload.ClientConfig.PartialFailures
+	updatedConfig.Diagnostics = payload.ClientConfig.Diagnostics
+	updatedConfig.SLOs = payload.ClientConfig.SLOs
 	updatedConfig.MaxRequestBodySizeMB = payload.ClientConfig.MaxRequestBodySizeMB
 	updatedConfig.EnableLiteLLMFallbacks = payload.ClientConfig.EnableLiteLLMFallbacks
 
@@ -0,0 +1,130 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the JSON Schemas of the config API payloads.
+package handlers
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/fasthttp/router"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// configSchemaTypes are the payloads of the config API with a published JSON Schema, by schema name
+var configSchemaTypes = map[string]reflect.Type{
+	"config":             reflect.TypeOf(UpdateConfigRequest{}),
+	"provider":           reflect.TypeOf(AddProviderRequest{}),
+	"provider_update":    reflect.TypeOf(UpdateProviderRequest{}),
+	"key":                reflect.TypeOf(schemas.Key{}),
+	"virtual_key":        reflect.TypeOf(CreateVirtualKeyRequest{}),
+	"virtual_key_update": reflect.TypeOf(UpdateVirtualKeyRequest{}),
+	"team":               reflect.TypeOf(CreateTeamRequest{}),
+	"team_update":        reflect.TypeOf(UpdateTeamRequest{}),
+	"customer":           reflect.TypeOf(CreateCustomerRequest{}),
+	"customer_update":    reflect.TypeOf(UpdateCustomerRequest{}),
+	"plugin":             reflect.TypeOf(CreatePluginRequest{}),
+	"plugin_update":      reflect.TypeOf(UpdatePluginRequest{}),
+}
+
+var (
+	configSchemas     map[string]lib.JSONSchema
+	configSchemasOnce sync.Once
+)
+
+// getConfigSchemas returns the JSON Schemas of the config API payloads, generated on first use
+func getConfigSchemas() map[string]lib.JSONSchema {
+	configSchemasOnce.Do(func() {
+		configSchemas = make(map[string]lib.JSONSchema, len(configSchemaTypes))
+		for name, t := range configSchemaTypes {
+			schema := lib.GenerateJSONSchema(t)
+			schema["$id"] = "/api/schemas/" + name
+			schema["title"] = name
+			configSchemas[name] = schema
+		}
+	})
+	return configSchemas
+}
+
+// validateConfigPayload validates the body of a config API request against the JSON Schema of its payload, and sends
+// the fields that do not match it as a 400 error. The field errors are listed in the param of the error. Returns
+// false when the error was sent.
+func validateConfigPayload(ctx *fasthttp.RequestCtx, name string) bool {
+	schema, ok := getConfigSchemas()[name]
+	if !ok {
+		return true
+	}
+	fieldErrors, err := lib.ValidateJSONSchema(schema, ctx.PostBody())
+	if err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid JSON: %v", err))
+		return false
+	}
+	if len(fieldErrors) == 0 {
+		return true
+	}
+	message := fmt.Sprintf("invalid %s: %s %s", name, fieldErrors[0].Path, fieldErrors[0].Message)
+	if fieldErrors[0].Path == "" {
+		message = fmt.Sprintf("invalid %s: %s", name, fieldErrors[0].Message)
+	}
+	if len(fieldErrors) > 1 {
+		message += fmt.Sprintf(" (and %d more)", len(fieldErrors)-1)
+	}
+	statusCode := fasthttp.StatusBadRequest
+	errorType := "invalid_request_error"
+	SendBifrostError(ctx, &schemas.BifrostError{
+		IsBifrostError: false,
+		StatusCode:     &statusCode,
+		Error: &schemas.ErrorField{
+			Type:    &errorType,
+			Message: message,
+			Param:   fieldErrors,
+		},
+	})
+	return false
+}
+
+// ConfigSchemasHandler publishes the JSON Schemas of the config API payloads
+type ConfigSchemasHandler struct{}
+
+// NewConfigSchemasHandler creates a new config schemas handler instance
+func NewConfigSchemasHandler() *ConfigSchemasHandler {
+	return &ConfigSchemasHandler{}
+}
+
+// RegisterRoutes registers the config schemas routes
+func (h *ConfigSchemasHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
+	r.GET("/api/schemas", lib.ChainMiddlewares(h.listSchemas, middlewares...))
+	r.GET("/api/schemas/{name}", lib.ChainMiddlewares(h.getSchema, middlewares...))
+}
+
+// listSchemas handles GET /api/schemas - List the names and URLs of the config schemas
+func (h *ConfigSchemasHandler) listSchemas(ctx *fasthttp.RequestCtx) {
+	names := make([]string, 0, len(configSchemaTypes))
+	for name := range configSchemaTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	schemaURLs := make(map[string]string, len(names))
+	for _, name := range names {
+		schemaURLs[name] = "/api/schemas/" + name
+	}
+	SendJSON(ctx, map[string]any{
+		"schemas": schemaURLs,
+		"count":   len(names),
+	})
+}
+
+// getSchema handles GET /api/schemas/{name} - Get the JSON Schema of a config payload
+func (h *ConfigSchemasHandler) getSchema(ctx *fasthttp.RequestCtx) {
+	name, _ := ctx.UserValue("name").(string)
+	schema, ok := getConfigSchemas()[name]
+	if !ok {
+		SendError(ctx, fasthttp.StatusNotFound, fmt.Sprintf("Schema not found: %s", name))
+		return
+	}
+	ctx.Response.Header.Set("Cache-Control", "public, max-age=3600")
+	SendJSON(ctx, schema)
+}
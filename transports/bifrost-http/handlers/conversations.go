@@ -0,0 +1,137 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the handlers exporting and deleting the conversations stored by Bifrost.
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fasthttp/router"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// ConversationsHandler exports and deletes stored conversations, e.g. for the data requests of their end users
+type ConversationsHandler struct {
+	store schemas.ConversationStore
+}
+
+// NewConversationsHandler creates a new conversations handler instance
+func NewConversationsHandler(store schemas.ConversationStore) *ConversationsHandler {
+	return &ConversationsHandler{
+		store: store,
+	}
+}
+
+// conversationSummary is a conversation listed without its messages
+type conversationSummary struct {
+	ID           string    `json:"id"`
+	Owner        string    `json:"owner,omitempty"`
+	User         string    `json:"user,omitempty"`
+	MessageCount int       `json:"message_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// RegisterRoutes registers the conversation routes
+func (h *ConversationsHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
+	r.GET("/api/conversations", lib.ChainMiddlewares(h.listConversations, middlewares...))
+	r.DELETE("/api/conversations", lib.ChainMiddlewares(h.deleteConversations, middlewares...))
+	r.GET("/api/conversations/{conversation_id}", lib.ChainMiddlewares(h.getConversation, middlewares...))
+	r.DELETE("/api/conversations/{conversation_id}", lib.ChainMiddlewares(h.deleteConversation, middlewares...))
+}
+
+// conversationFilter reads the filter of the user and virtual_key query parameters
+func conversationFilter(ctx *fasthttp.RequestCtx) schemas.ConversationFilter {
+	filter := schemas.ConversationFilter{User: string(ctx.QueryArgs().Peek("user"))}
+	if virtualKey := string(ctx.QueryArgs().Peek("virtual_key")); virtualKey != "" {
+		filter.Owner = "vk:" + virtualKey
+	}
+	return filter
+}
+
+// listConversations handles GET /api/conversations?user=<user>&virtual_key=<virtual key>&messages=true - List the
+// conversations of an end user or a virtual key. With messages=true the conversations are exported with their history.
+func (h *ConversationsHandler) listConversations(ctx *fasthttp.RequestCtx) {
+	conversations, err := h.store.ListConversations(ctx, conversationFilter(ctx))
+	if err != nil {
+		SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to list conversations: %v", err))
+		return
+	}
+	if string(ctx.QueryArgs().Peek("messages")) == "true" {
+		SendJSON(ctx, map[string]any{
+			"conversations": conversations,
+			"count":         len(conversations),
+		})
+		return
+	}
+	summaries := make([]conversationSummary, 0, len(conversations))
+	for _, conversation := range conversations {
+		summaries = append(summaries, conversationSummary{
+			ID:           conversation.ID,
+			Owner:        conversation.Owner,
+			User:         conversation.User,
+			MessageCount: len(conversation.Messages),
+			CreatedAt:    conversation.CreatedAt,
+			UpdatedAt:    conversation.UpdatedAt,
+		})
+	}
+	SendJSON(ctx, map[string]any{
+		"conversations": summaries,
+		"count":         len(summaries),
+	})
+}
+
+// getConversation handles GET /api/conversations/{conversation_id} - Export a conversation with its history
+func (h *ConversationsHandler) getConversation(ctx *fasthttp.RequestCtx) {
+	conversationID := ctx.UserValue("conversation_id").(string)
+	conversation, err := h.store.GetConversation(ctx, conversationID)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to get conversation: %v", err))
+		return
+	}
+	if conversation == nil {
+		SendError(ctx, fasthttp.StatusNotFound, "Conversation not found")
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"conversation": conversation,
+	})
+}
+
+// deleteConversation handles DELETE /api/conversations/{conversation_id} - Delete a conversation
+func (h *ConversationsHandler) deleteConversation(ctx *fasthttp.RequestCtx) {
+	conversationID := ctx.UserValue("conversation_id").(string)
+	deleted, err := h.store.DeleteConversation(ctx, conversationID)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to delete conversation: %v", err))
+		return
+	}
+	if !deleted {
+		SendError(ctx, fasthttp.StatusNotFound, "Conversation not found")
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message": "Conversation deleted",
+	})
+}
+
+// deleteConversations handles DELETE /api/conversations?user=<user>&virtual_key=<virtual key> - Delete every
+// conversation of an end user or a virtual key
+func (h *ConversationsHandler) deleteConversations(ctx *fasthttp.RequestCtx) {
+	filter := conversationFilter(ctx)
+	if filter.User == "" && filter.Owner == "" {
+		SendError(ctx, fasthttp.StatusBadRequest, "user or virtual_key query parameter is required")
+		return
+	}
+	deleted, err := h.store.DeleteConversations(ctx, filter)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to delete conversations: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message": fmt.Sprintf("%d conversations deleted", deleted),
+		"deleted": deleted,
+	})
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/bytedance/sonic"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/modelcatalog"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// CostEstimateRequest is a request estimating the cost of a chat or text completion on each provider it can be routed to
+type CostEstimateRequest struct {
+	Model               string                `json:"model"`               // Model in provider/model format
+	Fallbacks           []string              `json:"fallbacks,omitempty"` // Fallback providers and models in provider/model format
+	Messages            []schemas.ChatMessage `json:"messages,omitempty"`
+	Prompt              *string               `json:"prompt,omitempty"`
+	Tools               []schemas.ChatTool    `json:"tools,omitempty"`
+	MaxTokens           *int                  `json:"max_tokens,omitempty"`            // Output limit of text completions
+	MaxCompletionTokens *int                  `json:"max_completion_tokens,omitempty"` // Output limit of chat completions, takes precedence over max_tokens
+}
+
+// CostEstimateCandidate is the estimated cost of the request on a provider and model of its routing chain
+type CostEstimateCandidate struct {
+	Provider             schemas.ModelProvider `json:"provider"`
+	Model                string                `json:"model"`
+	InputTokens          int                   `json:"input_tokens"`
+	MaxOutputTokens      *int                  `json:"max_output_tokens,omitempty"`
+	ContextWindow        int                   `json:"context_window,omitempty"`
+	ExceedsContextWindow bool                  `json:"exceeds_context_window,omitempty"`
+	Priced               bool                  `json:"priced"`                    // false when the model has no pricing
+	InputCost            float64               `json:"input_cost"`                // in dollars
+	MaxOutputCost        *float64              `json:"max_output_cost,omitempty"` // cost of max_output_tokens, unset without an output limit
+	OutputCostPerToken   float64               `json:"output_cost_per_token"`
+	MaxTotalCost         float64               `json:"max_total_cost"` // input cost plus max output cost
+}
+
+// CostEstimateResponse lists the candidates of the routing chain in the order they are tried
+type CostEstimateResponse struct {
+	FallbackChain string                  `json:"fallback_chain,omitempty"` // Alias of the fallback chain replacing the fallbacks of the request
+	Candidates    []CostEstimateCandidate `json:"candidates"`
+}
+
+// costPricer prices token usage, implemented by the model catalog
+type costPricer interface {
+	GetPricingEntryForModel(model string, provider schemas.ModelProvider) *modelcatalog.PricingEntry
+	CalculateCostFromUsage(provider string, model string, deployment string, usage *schemas.BifrostLLMUsage, requestType schemas.RequestType, isBatch bool, audioSeconds *int, audioTokenDetails *schemas.TranscriptionUsageInputTokenDetails) float64
+}
+
+// routingCandidates returns the providers and models the request is tried on: the hops of the fallback chain of its
+// model in their configured order, otherwise the model followed by the fallbacks of the request
+func routingCandidates(chain *schemas.FallbackChain, provider schemas.ModelProvider, model string, fallbacks []schemas.Fallback) []schemas.Fallback {
+	if chain != nil {
+		candidates := make([]schemas.Fallback, 0, len(chain.Hops))
+		for _, hop := range chain.Hops {
+			candidates = append(candidates, schemas.Fallback{Provider: hop.Provider, Model: hop.Model})
+		}
+		return candidates
+	}
+	return append([]schemas.Fallback{{Provider: provider, Model: model}}, fallbacks...)
+}
+
+// priceCandidate sets the costs of the candidate from its token counts and the pricing of its model
+func priceCandidate(pricer costPricer, candidate *CostEstimateCandidate, requestType schemas.RequestType) {
+	if pricer == nil {
+		return
+	}
+	pricing := pricer.GetPricingEntryForModel(candidate.Model, candidate.Provider)
+	if pricing == nil {
+		return
+	}
+	candidate.Priced = true
+	candidate.OutputCostPerToken = pricing.OutputCostPerToken
+	candidate.InputCost = pricer.CalculateCostFromUsage(string(candidate.Provider), candidate.Model, "", &schemas.BifrostLLMUsage{
+		PromptTokens: candidate.InputTokens,
+		TotalTokens:  candidate.InputTokens,
+	}, requestType, false, nil, nil)
+	candidate.MaxTotalCost = candidate.InputCost
+	if candidate.MaxOutputTokens == nil {
+		return
+	}
+	total := pricer.CalculateCostFromUsage(string(candidate.Provider), candidate.Model, "", &schemas.BifrostLLMUsage{
+		PromptTokens:     candidate.InputTokens,
+		CompletionTokens: *candidate.MaxOutputTokens,
+		TotalTokens:      candidate.InputTokens + *candidate.MaxOutputTokens,
+	}, requestType, false, nil, nil)
+	candidate.MaxOutputCost = schemas.Ptr(max(total-candidate.InputCost, 0))
+	candidate.MaxTotalCost = total
+}
+
+// estimateCost handles POST /v1/cost-estimate - Estimates the input tokens and the cost of a request on each provider
+// and model of its routing chain with their tokenizer and pricing, without calling the providers
+func (h *CompletionHandler) estimateCost(ctx *fasthttp.RequestCtx) {
+	var req CostEstimateRequest
+	if err := sonic.Unmarshal(ctx.PostBody(), &req); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid request format: %v", err))
+		return
+	}
+
+	provider, modelName := schemas.ParseModelString(req.Model, "")
+	if provider == "" || modelName == "" {
+		SendError(ctx, fasthttp.StatusBadRequest, "model should be in provider/model format")
+		return
+	}
+	if len(req.Messages) == 0 && req.Prompt == nil {
+		SendError(ctx, fasthttp.StatusBadRequest, "messages or prompt is required for cost estimate")
+		return
+	}
+	fallbacks, err := parseFallbacks(req.Fallbacks)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	requestType := schemas.ChatCompletionRequest
+	if len(req.Messages) == 0 {
+		requestType = schemas.TextCompletionRequest
+	}
+	maxOutputTokens := req.MaxCompletionTokens
+	if maxOutputTokens == nil {
+		maxOutputTokens = req.MaxTokens
+	}
+
+	bifrostCtx, cancel := lib.ConvertToBifrostContext(ctx, h.handlerStore.ShouldAllowDirectKeys())
+	if bifrostCtx == nil {
+		SendError(ctx, fasthttp.StatusInternalServerError, "Failed to convert context")
+		return
+	}
+	defer cancel()
+
+	var pricer costPricer
+	if h.config.PricingManager != nil {
+		pricer = h.config.PricingManager
+	}
+	resp := CostEstimateResponse{}
+	chain := h.client.GetFallbackChain(schemas.ModelProvider(provider), modelName)
+	if chain != nil {
+		resp.FallbackChain = chain.Alias
+	}
+	for _, candidate := range routingCandidates(chain, schemas.ModelProvider(provider), modelName, fallbacks) {
+		count, bifrostErr := h.client.CountTokens(*bifrostCtx, &schemas.BifrostTokenCountRequest{
+			Provider: candidate.Provider,
+			Model:    candidate.Model,
+			Messages: req.Messages,
+			Prompt:   req.Prompt,
+			Tools:    req.Tools,
+		})
+		if bifrostErr != nil {
+			SendBifrostError(ctx, bifrostErr)
+			return
+		}
+		estimate := CostEstimateCandidate{
+			Provider:             candidate.Provider,
+			Model:                candidate.Model,
+			InputTokens:          count.InputTokens,
+			MaxOutputTokens:      maxOutputTokens,
+			ContextWindow:        count.ContextWindow,
+			ExceedsContextWindow: count.ExceedsContextWindow,
+		}
+		priceCandidate(pricer, &estimate, requestType)
+		resp.Candidates = append(resp.Candidates, estimate)
+	}
+
+	SendJSON(ctx, resp)
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"math"
+	"testing"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/modelcatalog"
+)
+
+// staticPricer prices tokens with flat per-token costs by model
+type staticPricer map[string]*modelcatalog.PricingEntry
+
+func (p staticPricer) GetPricingEntryForModel(model string, provider schemas.ModelProvider) *modelcatalog.PricingEntry {
+	return p[model]
+}
+
+func (p staticPricer) CalculateCostFromUsage(provider string, model string, deployment string, usage *schemas.BifrostLLMUsage, requestType schemas.RequestType, isBatch bool, audioSeconds *int, audioTokenDetails *schemas.TranscriptionUsageInputTokenDetails) float64 {
+	pricing := p[model]
+	return float64(usage.PromptTokens)*pricing.InputCostPerToken + float64(usage.CompletionTokens)*pricing.OutputCostPerToken
+}
+
+// TestRoutingCandidates tests that fallback chains replace the model and fallbacks of the request
+func TestRoutingCandidates(t *testing.T) {
+	fallbacks := []schemas.Fallback{{Provider: schemas.Anthropic, Model: "claude-sonnet-4"}}
+	candidates := routingCandidates(nil, schemas.OpenAI, "gpt-4o", fallbacks)
+	if len(candidates) != 2 || candidates[0].Model != "gpt-4o" || candidates[1].Provider != schemas.Anthropic {
+		t.Errorf("expected the model then its fallbacks, got %+v", candidates)
+	}
+
+	chain := &schemas.FallbackChain{Alias: "smart", Hops: []schemas.FallbackHop{
+		{Provider: schemas.Azure, Model: "gpt-4o", Weight: 1},
+		{Provider: schemas.Bedrock, Model: "claude-sonnet-4"},
+	}}
+	candidates = routingCandidates(chain, schemas.OpenAI, "smart", fallbacks)
+	if len(candidates) != 2 || candidates[0].Provider != schemas.Azure || candidates[1].Provider != schemas.Bedrock {
+		t.Errorf("expected the hops of the chain, got %+v", candidates)
+	}
+}
+
+// TestPriceCandidate tests the input and maximum output costs of a candidate
+func TestPriceCandidate(t *testing.T) {
+	pricer := staticPricer{"gpt-4o": {InputCostPerToken: 0.000002, OutputCostPerToken: 0.00001}}
+
+	candidate := CostEstimateCandidate{Provider: schemas.OpenAI, Model: "gpt-4o", InputTokens: 1000, MaxOutputTokens: schemas.Ptr(500)}
+	priceCandidate(pricer, &candidate, schemas.ChatCompletionRequest)
+	if !candidate.Priced || candidate.MaxOutputCost == nil {
+		t.Fatalf("unexpected estimate %+v", candidate)
+	}
+	if math.Abs(candidate.InputCost-0.002) > 1e-12 || math.Abs(candidate.MaxTotalCost-0.007) > 1e-12 {
+		t.Errorf("expected an input cost of 0.002 and a max total cost of 0.007, got %+v", candidate)
+	}
+
+	candidate = CostEstimateCandidate{Provider: schemas.OpenAI, Model: "gpt-4o", InputTokens: 1000}
+	priceCandidate(pricer, &candidate, schemas.ChatCompletionRequest)
+	if candidate.MaxOutputCost != nil || candidate.MaxTotalCost != candidate.InputCost {
+		t.Errorf("expected only the input cost without an output limit, got %+v", candidate)
+	}
+
+	candidate = CostEstimateCandidate{Provider: schemas.Ollama, Model: "llama3", InputTokens: 1000}
+	priceCandidate(pricer, &candidate, schemas.ChatCompletionRequest)
+	if candidate.Priced || candidate.MaxTotalCost != 0 {
+		t.Errorf("expected models without pricing to be unpriced, got %+v", candidate)
+	}
+}
@@ -0,0 +1,106 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the handler deleting the data Bifrost keeps about a virtual key or an end user.
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fasthttp/router"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// DataHandler deletes the request logs and conversations of a virtual key or an end user, to honor the deletion
+// requests of their data subjects
+type DataHandler struct {
+	config            *lib.Config
+	conversationStore schemas.ConversationStore
+}
+
+// NewDataHandler creates a new data handler instance. The conversation store is nil when conversations are disabled.
+func NewDataHandler(config *lib.Config, conversationStore schemas.ConversationStore) *DataHandler {
+	return &DataHandler{
+		config:            config,
+		conversationStore: conversationStore,
+	}
+}
+
+// RegisterRoutes registers the data routes
+func (h *DataHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
+	r.DELETE("/api/data", lib.ChainMiddlewares(h.deleteData, middlewares...))
+}
+
+// deleteData handles DELETE /api/data?virtual_key_id=<virtual key ID>&user=<user> - Delete the request logs and
+// conversations of a virtual key, of an end user, or of an end user of a virtual key when both are set
+func (h *DataHandler) deleteData(ctx *fasthttp.RequestCtx) {
+	virtualKeyID := string(ctx.QueryArgs().Peek("virtual_key_id"))
+	user := string(ctx.QueryArgs().Peek("user"))
+	if virtualKeyID == "" && user == "" {
+		SendError(ctx, fasthttp.StatusBadRequest, "virtual_key_id or user query parameter is required")
+		return
+	}
+
+	filter := schemas.ConversationFilter{User: user}
+	if virtualKeyID != "" {
+		// Conversations belong to the value of their virtual key, logs to its ID
+		value, err := h.virtualKeyValue(ctx, virtualKeyID)
+		if err != nil {
+			if errors.Is(err, configstore.ErrNotFound) {
+				SendError(ctx, fasthttp.StatusNotFound, "Virtual key not found")
+				return
+			}
+			SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to get virtual key: %v", err))
+			return
+		}
+		filter.Owner = "vk:" + value
+	}
+
+	var deletedLogs int64
+	if h.config.LogsStore != nil {
+		deleted, err := h.config.LogsStore.DeleteLogsBySubject(ctx, virtualKeyID, user)
+		if err != nil {
+			SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to delete logs: %v", err))
+			return
+		}
+		deletedLogs = deleted
+	}
+	var deletedConversations int
+	if h.conversationStore != nil {
+		deleted, err := h.conversationStore.DeleteConversations(ctx, filter)
+		if err != nil {
+			SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to delete conversations: %v", err))
+			return
+		}
+		deletedConversations = deleted
+	}
+
+	logger.Info("deleted the data of virtual key %q and user %q: %d logs, %d conversations", virtualKeyID, user, deletedLogs, deletedConversations)
+	SendJSON(ctx, map[string]any{
+		"message":               "Data deleted",
+		"deleted_logs":          deletedLogs,
+		"deleted_conversations": deletedConversations,
+	})
+}
+
+// virtualKeyValue returns the value of the virtual key with the ID, from the config store or the config file
+func (h *DataHandler) virtualKeyValue(ctx context.Context, id string) (string, error) {
+	if h.config.ConfigStore != nil {
+		virtualKey, err := h.config.ConfigStore.GetVirtualKey(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		return virtualKey.Value, nil
+	}
+	if h.config.GovernanceConfig != nil {
+		for _, virtualKey := range h.config.GovernanceConfig.VirtualKeys {
+			if virtualKey.ID == id {
+				return virtualKey.Value, nil
+			}
+		}
+	}
+	return "", configstore.ErrNotFound
+}
@@ -0,0 +1,146 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the fine-tuning dataset export handlers.
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/fasthttp/router"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// DatasetExportsHandler manages the exports of logged conversations as fine-tuning datasets
+type DatasetExportsHandler struct {
+	manager *lib.DatasetExportManager
+}
+
+// NewDatasetExportsHandler creates a new dataset exports handler instance
+func NewDatasetExportsHandler(manager *lib.DatasetExportManager) *DatasetExportsHandler {
+	return &DatasetExportsHandler{
+		manager: manager,
+	}
+}
+
+// RegisterRoutes registers the dataset export routes
+func (h *DatasetExportsHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
+	r.POST("/api/dataset-exports", lib.ChainMiddlewares(h.createExport, middlewares...))
+	r.GET("/api/dataset-exports", lib.ChainMiddlewares(h.listExports, middlewares...))
+	r.GET("/api/dataset-exports/{export_id}", lib.ChainMiddlewares(h.getExport, middlewares...))
+	r.GET("/api/dataset-exports/{export_id}/download", lib.ChainMiddlewares(h.downloadExport, middlewares...))
+	r.POST("/api/dataset-exports/{export_id}/cancel", lib.ChainMiddlewares(h.cancelExport, middlewares...))
+}
+
+// createExport handles POST /api/dataset-exports - Start exporting the logs matching the filters as a dataset.
+// Namespace admins export the logs of their namespace, with their encrypted payloads.
+func (h *DatasetExportsHandler) createExport(ctx *fasthttp.RequestCtx) {
+	var req lib.DatasetExportRequest
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if namespace, scoped := getRequestNamespace(ctx); scoped {
+		req.Filters.Namespaces = []string{namespace}
+		req.Namespace = namespace
+	}
+	export, err := h.manager.StartExport(req)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Failed to start dataset export: %v", err))
+		return
+	}
+	SendJSONWithStatus(ctx, map[string]any{
+		"message": "Dataset export started",
+		"export":  export,
+	}, fasthttp.StatusAccepted)
+}
+
+// listExports handles GET /api/dataset-exports - List recent dataset exports with their progress
+func (h *DatasetExportsHandler) listExports(ctx *fasthttp.RequestCtx) {
+	exports := make([]lib.DatasetExport, 0)
+	for _, export := range h.manager.ListExports() {
+		if h.canAccess(ctx, &export) {
+			exports = append(exports, export)
+		}
+	}
+	SendJSON(ctx, map[string]any{
+		"exports": exports,
+		"count":   len(exports),
+	})
+}
+
+// getExport handles GET /api/dataset-exports/{export_id} - Get the progress of a dataset export
+func (h *DatasetExportsHandler) getExport(ctx *fasthttp.RequestCtx) {
+	export, err := h.manager.GetExport(ctx.UserValue("export_id").(string))
+	if err != nil || !h.canAccess(ctx, export) {
+		h.sendExportError(ctx, err)
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"export": export,
+	})
+}
+
+// downloadExport handles GET /api/dataset-exports/{export_id}/download - Download the JSONL dataset of a completed
+// export
+func (h *DatasetExportsHandler) downloadExport(ctx *fasthttp.RequestCtx) {
+	file, export, err := h.manager.OpenExport(ctx.UserValue("export_id").(string))
+	if export != nil && !h.canAccess(ctx, export) {
+		if file != nil {
+			file.Close()
+		}
+		h.sendExportError(ctx, lib.ErrNotFound)
+		return
+	}
+	if err != nil {
+		if errors.Is(err, lib.ErrNotFound) {
+			h.sendExportError(ctx, err)
+			return
+		}
+		SendError(ctx, fasthttp.StatusConflict, err.Error())
+		return
+	}
+	ctx.SetContentType("application/jsonl")
+	ctx.Response.Header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=dataset-%s-%s.jsonl", export.Format, export.ID))
+	// The file is closed once the body is sent
+	ctx.SetBodyStream(file, int(export.Size))
+}
+
+// cancelExport handles POST /api/dataset-exports/{export_id}/cancel - Cancel a dataset export
+func (h *DatasetExportsHandler) cancelExport(ctx *fasthttp.RequestCtx) {
+	exportID := ctx.UserValue("export_id").(string)
+	export, err := h.manager.GetExport(exportID)
+	if err != nil || !h.canAccess(ctx, export) {
+		h.sendExportError(ctx, err)
+		return
+	}
+	export, err = h.manager.CancelExport(exportID)
+	if err != nil {
+		h.sendExportError(ctx, err)
+		return
+	}
+	message := "Dataset export cancelled"
+	if export.Status != lib.DatasetExportRunning && export.Status != lib.DatasetExportPending {
+		message = fmt.Sprintf("Dataset export is %s", export.Status)
+	}
+	SendJSON(ctx, map[string]any{
+		"message": message,
+		"export":  export,
+	})
+}
+
+// canAccess checks that namespace admins only see the exports of their namespace
+func (h *DatasetExportsHandler) canAccess(ctx *fasthttp.RequestCtx, export *lib.DatasetExport) bool {
+	namespace, scoped := getRequestNamespace(ctx)
+	return !scoped || export.Namespace == namespace
+}
+
+// sendExportError sends the error of an export lookup, exports of other namespaces are not found
+func (h *DatasetExportsHandler) sendExportError(ctx *fasthttp.RequestCtx, err error) {
+	if err == nil || errors.Is(err, lib.ErrNotFound) {
+		SendError(ctx, fasthttp.StatusNotFound, "Dataset export not found")
+		return
+	}
+	SendError(ctx, fasthttp.StatusInternalServerError, err.Error())
+}
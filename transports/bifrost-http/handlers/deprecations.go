@@ -0,0 +1,199 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the model deprecation management handlers.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fasthttp/router"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// ModelDeprecationManager applies the model deprecations of the config store to the bifrost client
+type ModelDeprecationManager interface {
+	ReloadModelDeprecations(ctx context.Context) error
+}
+
+// ModelDeprecationsHandler manages HTTP requests for model deprecation operations
+type ModelDeprecationsHandler struct {
+	configStore             configstore.ConfigStore
+	modelDeprecationManager ModelDeprecationManager
+}
+
+// NewModelDeprecationsHandler creates a new model deprecations handler instance
+func NewModelDeprecationsHandler(manager ModelDeprecationManager, configStore configstore.ConfigStore) (*ModelDeprecationsHandler, error) {
+	if configStore == nil {
+		return nil, fmt.Errorf("config store is required")
+	}
+	return &ModelDeprecationsHandler{
+		configStore:             configStore,
+		modelDeprecationManager: manager,
+	}, nil
+}
+
+// UpsertModelDeprecationRequest represents the request body for creating or updating a model deprecation
+type UpsertModelDeprecationRequest struct {
+	Model       string `json:"model,omitempty"` // Only read on create, the model of a deprecation cannot change
+	Replacement string `json:"replacement"`
+	SunsetDate  string `json:"sunset_date"`
+	Message     string `json:"message,omitempty"`
+}
+
+// RegisterRoutes registers all model deprecation management routes.
+// Models may be in the "provider/model" format, so the model is the remainder of the path.
+func (h *ModelDeprecationsHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
+	r.GET("/api/model-deprecations", lib.ChainMiddlewares(h.getModelDeprecations, middlewares...))
+	r.POST("/api/model-deprecations", lib.ChainMiddlewares(h.createModelDeprecation, middlewares...))
+	r.GET("/api/model-deprecations/{model:*}", lib.ChainMiddlewares(h.getModelDeprecation, middlewares...))
+	r.PUT("/api/model-deprecations/{model:*}", lib.ChainMiddlewares(h.updateModelDeprecation, middlewares...))
+	r.DELETE("/api/model-deprecations/{model:*}", lib.ChainMiddlewares(h.deleteModelDeprecation, middlewares...))
+}
+
+// getModelDeprecations handles GET /api/model-deprecations - Get all model deprecations by sunset date
+func (h *ModelDeprecationsHandler) getModelDeprecations(ctx *fasthttp.RequestCtx) {
+	deprecations, err := h.configStore.GetModelDeprecations(ctx)
+	if err != nil {
+		logger.Error("failed to retrieve model deprecations: %v", err)
+		SendError(ctx, 500, "Failed to retrieve model deprecations")
+		return
+	}
+	SendJSON(ctx, map[string]interface{}{
+		"model_deprecations": deprecations,
+		"count":              len(deprecations),
+	})
+}
+
+// createModelDeprecation handles POST /api/model-deprecations - Deprecate a model
+func (h *ModelDeprecationsHandler) createModelDeprecation(ctx *fasthttp.RequestCtx) {
+	var req UpsertModelDeprecationRequest
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		SendError(ctx, 400, "Invalid JSON")
+		return
+	}
+	deprecation := configstoreTables.TableModelDeprecation{
+		Model:       req.Model,
+		Replacement: req.Replacement,
+		SunsetDate:  req.SunsetDate,
+		Message:     req.Message,
+	}
+	if err := h.validateModelDeprecation(ctx, &deprecation); err != nil {
+		SendError(ctx, 400, err.Error())
+		return
+	}
+	if err := h.configStore.CreateModelDeprecation(ctx, &deprecation); err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			SendError(ctx, 409, err.Error())
+			return
+		}
+		SendError(ctx, 500, fmt.Sprintf("Failed to create model deprecation: %v", err))
+		return
+	}
+	if err := h.modelDeprecationManager.ReloadModelDeprecations(ctx); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to reload model deprecations: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message":           "Model deprecation created successfully",
+		"model_deprecation": deprecation,
+	})
+}
+
+// getModelDeprecation handles GET /api/model-deprecations/{model} - Get the deprecation of a model
+func (h *ModelDeprecationsHandler) getModelDeprecation(ctx *fasthttp.RequestCtx) {
+	model := ctx.UserValue("model").(string)
+	deprecation, err := h.configStore.GetModelDeprecation(ctx, model)
+	if err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Model deprecation not found")
+			return
+		}
+		SendError(ctx, 500, "Failed to retrieve model deprecation")
+		return
+	}
+	SendJSON(ctx, map[string]interface{}{
+		"model_deprecation": deprecation,
+	})
+}
+
+// updateModelDeprecation handles PUT /api/model-deprecations/{model} - Replace the replacement, sunset date and message of a deprecation
+func (h *ModelDeprecationsHandler) updateModelDeprecation(ctx *fasthttp.RequestCtx) {
+	model := ctx.UserValue("model").(string)
+	var req UpsertModelDeprecationRequest
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		SendError(ctx, 400, "Invalid JSON")
+		return
+	}
+	deprecation, err := h.configStore.GetModelDeprecation(ctx, model)
+	if err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Model deprecation not found")
+			return
+		}
+		SendError(ctx, 500, "Failed to retrieve model deprecation")
+		return
+	}
+	deprecation.Replacement = req.Replacement
+	deprecation.SunsetDate = req.SunsetDate
+	deprecation.Message = req.Message
+	if err := h.validateModelDeprecation(ctx, deprecation); err != nil {
+		SendError(ctx, 400, err.Error())
+		return
+	}
+	if err := h.configStore.UpdateModelDeprecation(ctx, deprecation); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to update model deprecation: %v", err))
+		return
+	}
+	if err := h.modelDeprecationManager.ReloadModelDeprecations(ctx); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to reload model deprecations: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message":           "Model deprecation updated successfully",
+		"model_deprecation": deprecation,
+	})
+}
+
+// deleteModelDeprecation handles DELETE /api/model-deprecations/{model} - Remove the deprecation of a model
+func (h *ModelDeprecationsHandler) deleteModelDeprecation(ctx *fasthttp.RequestCtx) {
+	model := ctx.UserValue("model").(string)
+	if err := h.configStore.DeleteModelDeprecation(ctx, model); err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Model deprecation not found")
+			return
+		}
+		SendError(ctx, 500, fmt.Sprintf("Failed to delete model deprecation: %v", err))
+		return
+	}
+	if err := h.modelDeprecationManager.ReloadModelDeprecations(ctx); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to reload model deprecations: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message": "Model deprecation deleted successfully",
+	})
+}
+
+// validateModelDeprecation validates the deprecation together with the stored ones, so that models are not
+// deprecated twice and replacements are not deprecated themselves
+func (h *ModelDeprecationsHandler) validateModelDeprecation(ctx context.Context, deprecation *configstoreTables.TableModelDeprecation) error {
+	stored, err := h.configStore.GetModelDeprecations(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve model deprecations: %v", err)
+	}
+	deprecations := make([]schemas.ModelDeprecation, 0, len(stored)+1)
+	for i := range stored {
+		if stored[i].Model != deprecation.Model {
+			deprecations = append(deprecations, stored[i].ToSchema())
+		}
+	}
+	deprecations = append(deprecations, deprecation.ToSchema())
+	return schemas.ValidateModelDeprecations(deprecations)
+}
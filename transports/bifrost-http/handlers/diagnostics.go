@@ -0,0 +1,56 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the diagnostics handlers.
+package handlers
+
+import (
+	"github.com/fasthttp/router"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// DiagnosticsHandler serves the captures of the slow and large inference requests
+type DiagnosticsHandler struct {
+	diagnostics *lib.Diagnostics
+}
+
+// NewDiagnosticsHandler creates a new diagnostics handler instance
+func NewDiagnosticsHandler(diagnostics *lib.Diagnostics) *DiagnosticsHandler {
+	return &DiagnosticsHandler{
+		diagnostics: diagnostics,
+	}
+}
+
+// RegisterRoutes registers the diagnostics routes
+func (h *DiagnosticsHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
+	r.GET("/api/diagnostics/captures", lib.ChainMiddlewares(h.listCaptures, middlewares...))
+	r.GET("/api/diagnostics/captures/{id}", lib.ChainMiddlewares(h.getCapture, middlewares...))
+	r.DELETE("/api/diagnostics/captures", lib.ChainMiddlewares(h.clearCaptures, middlewares...))
+}
+
+// listCaptures handles GET /api/diagnostics/captures - List the captures without their bodies, the latest first
+func (h *DiagnosticsHandler) listCaptures(ctx *fasthttp.RequestCtx) {
+	captures := h.diagnostics.List()
+	SendJSON(ctx, map[string]any{
+		"captures": captures,
+		"count":    len(captures),
+	})
+}
+
+// getCapture handles GET /api/diagnostics/captures/{id} - Get a capture with its bodies, by its ID or request ID
+func (h *DiagnosticsHandler) getCapture(ctx *fasthttp.RequestCtx) {
+	id, _ := ctx.UserValue("id").(string)
+	capture, ok := h.diagnostics.Get(id)
+	if !ok {
+		SendError(ctx, fasthttp.StatusNotFound, "diagnostic capture not found")
+		return
+	}
+	SendJSON(ctx, capture)
+}
+
+// clearCaptures handles DELETE /api/diagnostics/captures - Drop all the captures
+func (h *DiagnosticsHandler) clearCaptures(ctx *fasthttp.RequestCtx) {
+	h.diagnostics.Clear()
+	SendJSON(ctx, map[string]any{
+		"message": "diagnostic captures cleared",
+	})
+}
@@ -0,0 +1,56 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the drain handlers.
+package handlers
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/fasthttp/router"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// DrainHandler starts the drain of the server and reports its progress
+type DrainHandler struct {
+	drain *lib.Drain
+}
+
+// NewDrainHandler creates a new drain handler instance
+func NewDrainHandler(drain *lib.Drain) *DrainHandler {
+	return &DrainHandler{
+		drain: drain,
+	}
+}
+
+// StartDrainRequest represents the request body for starting a drain
+type StartDrainRequest struct {
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"` // How long in-flight requests and streams can take to finish, 30 seconds by default
+}
+
+// RegisterRoutes registers the drain routes
+func (h *DrainHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
+	r.GET("/api/drain", lib.ChainMiddlewares(h.getDrain, middlewares...))
+	r.POST("/api/drain", lib.ChainMiddlewares(h.startDrain, middlewares...))
+}
+
+// getDrain handles GET /api/drain - Get the progress of the drain
+func (h *DrainHandler) getDrain(ctx *fasthttp.RequestCtx) {
+	SendJSON(ctx, h.drain.Status())
+}
+
+// startDrain handles POST /api/drain - Refuse new inference requests and let the in-flight ones finish
+func (h *DrainHandler) startDrain(ctx *fasthttp.RequestCtx) {
+	var req StartDrainRequest
+	if body := ctx.PostBody(); len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			SendError(ctx, fasthttp.StatusBadRequest, "Invalid JSON")
+			return
+		}
+	}
+	if req.TimeoutSeconds < 0 {
+		SendError(ctx, fasthttp.StatusBadRequest, "timeout_seconds cannot be negative")
+		return
+	}
+	SendJSON(ctx, h.drain.Start(time.Duration(req.TimeoutSeconds)*time.Second))
+}
@@ -0,0 +1,86 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the ETags and conditional requests of the management API resources.
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// errPreconditionFailed is returned when a resource changed between its conditional request and its write
+var errPreconditionFailed = errors.New("the resource was changed since it was read")
+
+// resourceETag returns the strong ETag of a resource from the parts of its state
+func resourceETag(parts ...any) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		data, err := json.Marshal(part)
+		if err != nil {
+			data = []byte(err.Error())
+		}
+		hash.Write(data)
+		hash.Write([]byte{0})
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// setETag sets the ETag of the resource of the response
+func setETag(ctx *fasthttp.RequestCtx, etag string) {
+	if etag != "" {
+		ctx.Response.Header.Set("ETag", etag)
+	}
+}
+
+// etagListMatches reports whether an If-Match or If-None-Match header matches the ETag of an existing resource.
+// Weak ETags are compared by their value.
+func etagListMatches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNotModified answers a read with 304 Not Modified when its If-None-Match header matches the ETag of the
+// resource. Returns true when the response was sent.
+func checkNotModified(ctx *fasthttp.RequestCtx, etag string) bool {
+	header := string(ctx.Request.Header.Peek("If-None-Match"))
+	if header == "" || !etagListMatches(header, etag) {
+		return false
+	}
+	setETag(ctx, etag)
+	ctx.SetStatusCode(fasthttp.StatusNotModified)
+	return true
+}
+
+// checkPreconditions checks the If-Match and If-None-Match headers of a write against the current ETag of the
+// resource, empty when it does not exist, and sends 412 Precondition Failed when they do not hold. If-Match: * only
+// writes an existing resource, and If-None-Match: * only creates a missing one. Returns false when the error was sent.
+func checkPreconditions(ctx *fasthttp.RequestCtx, etag string) bool {
+	if header := string(ctx.Request.Header.Peek("If-Match")); header != "" {
+		if etag == "" || !etagListMatches(header, etag) {
+			sendPreconditionFailed(ctx, etag)
+			return false
+		}
+	}
+	if header := string(ctx.Request.Header.Peek("If-None-Match")); header != "" {
+		if etag != "" && etagListMatches(header, etag) {
+			sendPreconditionFailed(ctx, etag)
+			return false
+		}
+	}
+	return true
+}
+
+// sendPreconditionFailed sends 412 Precondition Failed with the current ETag of the resource
+func sendPreconditionFailed(ctx *fasthttp.RequestCtx, etag string) {
+	setETag(ctx, etag)
+	SendError(ctx, fasthttp.StatusPreconditionFailed, "Precondition failed: "+errPreconditionFailed.Error())
+}
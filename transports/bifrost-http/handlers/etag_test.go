@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+// TestCheckPreconditions tests that conditional writes only go through while their preconditions hold
+func TestCheckPreconditions(t *testing.T) {
+	etag := resourceETag("openai", map[string]any{"weight": 1})
+	otherETag := resourceETag("openai", map[string]any{"weight": 2})
+	if etag == otherETag {
+		t.Fatal("expected different states to have different ETags")
+	}
+
+	tests := []struct {
+		name        string
+		ifMatch     string
+		ifNoneMatch string
+		current     string
+		want        bool
+	}{
+		{name: "unconditional write", current: etag, want: true},
+		{name: "matching If-Match", ifMatch: etag, current: etag, want: true},
+		{name: "If-Match in a list", ifMatch: otherETag + ", " + etag, current: etag, want: true},
+		{name: "weak If-Match", ifMatch: "W/" + etag, current: etag, want: true},
+		{name: "stale If-Match", ifMatch: otherETag, current: etag, want: false},
+		{name: "If-Match on a missing resource", ifMatch: "*", current: "", want: false},
+		{name: "If-Match * on an existing resource", ifMatch: "*", current: etag, want: true},
+		{name: "If-None-Match * on a missing resource", ifNoneMatch: "*", current: "", want: true},
+		{name: "If-None-Match * on an existing resource", ifNoneMatch: "*", current: etag, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			if tt.ifMatch != "" {
+				ctx.Request.Header.Set("If-Match", tt.ifMatch)
+			}
+			if tt.ifNoneMatch != "" {
+				ctx.Request.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			if got := checkPreconditions(ctx, tt.current); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+			if !tt.want && ctx.Response.StatusCode() != fasthttp.StatusPreconditionFailed {
+				t.Errorf("expected status %d, got %d", fasthttp.StatusPreconditionFailed, ctx.Response.StatusCode())
+			}
+		})
+	}
+}
+
+// TestCheckNotModified tests that reads with the current ETag are answered with 304 Not Modified
+func TestCheckNotModified(t *testing.T) {
+	etag := resourceETag("vk-1", int64(1))
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.Set("If-None-Match", etag)
+	if !checkNotModified(ctx, etag) || ctx.Response.StatusCode() != fasthttp.StatusNotModified {
+		t.Errorf("expected 304 for the current ETag, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx = &fasthttp.RequestCtx{}
+	ctx.Request.Header.Set("If-None-Match", resourceETag("vk-1", int64(2)))
+	if checkNotModified(ctx, etag) {
+		t.Error("expected a stale ETag to read the resource")
+	}
+}
+
+// TestAPIVersionMiddleware tests that the versioned management API routes to the unversioned routes
+func TestAPIVersionMiddleware(t *testing.T) {
+	var routedPath string
+	handler := APIVersionMiddleware()(func(ctx *fasthttp.RequestCtx) {
+		routedPath = string(ctx.Path())
+	})
+
+	for path, want := range map[string]string{
+		"/api/v1/providers/openai": "/api/providers/openai",
+		"/api/providers/openai":    "/api/providers/openai",
+		"/v1/chat/completions":     "/v1/chat/completions",
+	} {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI(path)
+		handler(ctx)
+		if routedPath != want {
+			t.Errorf("expected %s to route to %s, got %s", path, want, routedPath)
+		}
+		version := string(ctx.Response.Header.Peek("X-Bifrost-API-Version"))
+		if (want != "/v1/chat/completions") != (version == ManagementAPIVersion) {
+			t.Errorf("unexpected API version header %q for %s", version, path)
+		}
+	}
+}
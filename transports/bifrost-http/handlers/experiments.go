@@ -0,0 +1,278 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the experiment management handlers.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fasthttp/router"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+	"github.com/maximhq/bifrost/framework/logstore"
+	"github.com/maximhq/bifrost/plugins/logging"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// ExperimentManager applies the experiments of the config store to the bifrost client
+type ExperimentManager interface {
+	ReloadExperiments(ctx context.Context) error
+}
+
+// ExperimentsHandler manages HTTP requests for experiment operations
+type ExperimentsHandler struct {
+	configStore       configstore.ConfigStore
+	experimentManager ExperimentManager
+	logManager        logging.LogManager // nil when the logging plugin is not loaded, results are then unavailable
+}
+
+// NewExperimentsHandler creates a new experiments handler instance
+func NewExperimentsHandler(manager ExperimentManager, configStore configstore.ConfigStore, logManager logging.LogManager) (*ExperimentsHandler, error) {
+	if configStore == nil {
+		return nil, fmt.Errorf("config store is required")
+	}
+	return &ExperimentsHandler{
+		configStore:       configStore,
+		experimentManager: manager,
+		logManager:        logManager,
+	}, nil
+}
+
+// UpsertExperimentRequest represents the request body for creating or updating an experiment
+type UpsertExperimentRequest struct {
+	Name        string                       `json:"name,omitempty"` // Only read on create, the name of an experiment cannot change
+	Description string                       `json:"description,omitempty"`
+	Enabled     bool                         `json:"enabled"`
+	Models      []string                     `json:"models"`
+	AssignBy    schemas.ExperimentAssignment `json:"assign_by,omitempty"`
+	Variants    []schemas.ExperimentVariant  `json:"variants"`
+}
+
+// ExperimentVariantResult is the traffic and feedback of the requests assigned to a variant of an experiment
+type ExperimentVariantResult struct {
+	Variant  string                   `json:"variant"`
+	Stats    logstore.SearchStats     `json:"stats"`
+	Feedback logstore.FeedbackSummary `json:"feedback"`
+}
+
+// RegisterRoutes registers all experiment management routes
+func (h *ExperimentsHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
+	r.GET("/api/experiments", lib.ChainMiddlewares(h.getExperiments, middlewares...))
+	r.POST("/api/experiments", lib.ChainMiddlewares(h.createExperiment, middlewares...))
+	r.GET("/api/experiments/{name}", lib.ChainMiddlewares(h.getExperiment, middlewares...))
+	r.PUT("/api/experiments/{name}", lib.ChainMiddlewares(h.updateExperiment, middlewares...))
+	r.DELETE("/api/experiments/{name}", lib.ChainMiddlewares(h.deleteExperiment, middlewares...))
+	r.GET("/api/experiments/{name}/results", lib.ChainMiddlewares(h.getExperimentResults, middlewares...))
+}
+
+// getExperiments handles GET /api/experiments - Get all experiments
+func (h *ExperimentsHandler) getExperiments(ctx *fasthttp.RequestCtx) {
+	experiments, err := h.configStore.GetExperiments(ctx)
+	if err != nil {
+		logger.Error("failed to retrieve experiments: %v", err)
+		SendError(ctx, 500, "Failed to retrieve experiments")
+		return
+	}
+	SendJSON(ctx, map[string]interface{}{
+		"experiments": experiments,
+		"count":       len(experiments),
+	})
+}
+
+// createExperiment handles POST /api/experiments - Create an experiment
+func (h *ExperimentsHandler) createExperiment(ctx *fasthttp.RequestCtx) {
+	var req UpsertExperimentRequest
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		SendError(ctx, 400, "Invalid JSON")
+		return
+	}
+	experiment := configstoreTables.TableExperiment{
+		Name:        req.Name,
+		Description: req.Description,
+		Enabled:     req.Enabled,
+		Models:      req.Models,
+		AssignBy:    req.AssignBy,
+		Variants:    req.Variants,
+	}
+	schemaExperiment := experiment.ToSchema()
+	if err := schemaExperiment.Validate(); err != nil {
+		SendError(ctx, 400, err.Error())
+		return
+	}
+	if err := h.configStore.CreateExperiment(ctx, &experiment); err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			SendError(ctx, 409, err.Error())
+			return
+		}
+		SendError(ctx, 500, fmt.Sprintf("Failed to create experiment: %v", err))
+		return
+	}
+	if err := h.experimentManager.ReloadExperiments(ctx); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to reload experiments: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message":    "Experiment created successfully",
+		"experiment": experiment,
+	})
+}
+
+// getExperiment handles GET /api/experiments/{name} - Get an experiment
+func (h *ExperimentsHandler) getExperiment(ctx *fasthttp.RequestCtx) {
+	name := ctx.UserValue("name").(string)
+	experiment, err := h.configStore.GetExperiment(ctx, name)
+	if err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Experiment not found")
+			return
+		}
+		SendError(ctx, 500, "Failed to retrieve experiment")
+		return
+	}
+	SendJSON(ctx, map[string]interface{}{
+		"experiment": experiment,
+	})
+}
+
+// updateExperiment handles PUT /api/experiments/{name} - Replace the models and variants of an experiment.
+// Changing the variants or their weights reassigns part of the users or conversations to other variants.
+func (h *ExperimentsHandler) updateExperiment(ctx *fasthttp.RequestCtx) {
+	name := ctx.UserValue("name").(string)
+	var req UpsertExperimentRequest
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		SendError(ctx, 400, "Invalid JSON")
+		return
+	}
+	experiment, err := h.configStore.GetExperiment(ctx, name)
+	if err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Experiment not found")
+			return
+		}
+		SendError(ctx, 500, "Failed to retrieve experiment")
+		return
+	}
+	experiment.Description = req.Description
+	experiment.Enabled = req.Enabled
+	experiment.Models = req.Models
+	experiment.AssignBy = req.AssignBy
+	experiment.Variants = req.Variants
+	schemaExperiment := experiment.ToSchema()
+	if err := schemaExperiment.Validate(); err != nil {
+		SendError(ctx, 400, err.Error())
+		return
+	}
+	if err := h.configStore.UpdateExperiment(ctx, experiment); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to update experiment: %v", err))
+		return
+	}
+	if err := h.experimentManager.ReloadExperiments(ctx); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to reload experiments: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message":    "Experiment updated successfully",
+		"experiment": experiment,
+	})
+}
+
+// deleteExperiment handles DELETE /api/experiments/{name} - Delete an experiment, its logs and feedback are kept
+func (h *ExperimentsHandler) deleteExperiment(ctx *fasthttp.RequestCtx) {
+	name := ctx.UserValue("name").(string)
+	if err := h.configStore.DeleteExperiment(ctx, name); err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Experiment not found")
+			return
+		}
+		SendError(ctx, 500, fmt.Sprintf("Failed to delete experiment: %v", err))
+		return
+	}
+	if err := h.experimentManager.ReloadExperiments(ctx); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to reload experiments: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message": "Experiment deleted successfully",
+	})
+}
+
+// getExperimentResults handles GET /api/experiments/{name}/results - Get the traffic and feedback of each variant of an
+// experiment, from the logs tagged with the experiment and variant. Supports the start_time and end_time filters of
+// the logs, the feedback is not filtered by time.
+func (h *ExperimentsHandler) getExperimentResults(ctx *fasthttp.RequestCtx) {
+	if h.logManager == nil {
+		SendError(ctx, fasthttp.StatusServiceUnavailable, "Experiment results require the logging plugin")
+		return
+	}
+	name := ctx.UserValue("name").(string)
+	experiment, err := h.configStore.GetExperiment(ctx, name)
+	if err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Experiment not found")
+			return
+		}
+		SendError(ctx, 500, "Failed to retrieve experiment")
+		return
+	}
+
+	var startTime, endTime *time.Time
+	if value := string(ctx.QueryArgs().Peek("start_time")); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid start_time, expected RFC3339: %v", err))
+			return
+		}
+		startTime = &t
+	}
+	if value := string(ctx.QueryArgs().Peek("end_time")); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid end_time, expected RFC3339: %v", err))
+			return
+		}
+		endTime = &t
+	}
+
+	summaries, err := h.logManager.GetFeedbackSummaries(ctx, name)
+	if err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to retrieve experiment feedback: %v", err))
+		return
+	}
+	feedback := make(map[string]logstore.FeedbackSummary, len(summaries))
+	for _, summary := range summaries {
+		feedback[summary.Variant] = summary
+	}
+
+	results := make([]ExperimentVariantResult, 0, len(experiment.Variants))
+	for _, variant := range experiment.Variants {
+		stats, err := h.logManager.GetStats(ctx, &logstore.SearchFilters{
+			Tags: map[string]string{
+				schemas.ExperimentTag:        name,
+				schemas.ExperimentVariantTag: variant.Name,
+			},
+			StartTime: startTime,
+			EndTime:   endTime,
+		})
+		if err != nil {
+			SendError(ctx, 500, fmt.Sprintf("Failed to retrieve experiment stats: %v", err))
+			return
+		}
+		result := ExperimentVariantResult{
+			Variant:  variant.Name,
+			Stats:    *stats,
+			Feedback: feedback[variant.Name],
+		}
+		result.Feedback.Variant = variant.Name
+		results = append(results, result)
+	}
+	SendJSON(ctx, map[string]any{
+		"experiment": name,
+		"variants":   results,
+	})
+}
@@ -0,0 +1,180 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the fallback chain management handlers.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fasthttp/router"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// FallbackChainManager applies the fallback chains of the config store to the bifrost client
+type FallbackChainManager interface {
+	ReloadFallbackChains(ctx context.Context) error
+}
+
+// FallbackChainsHandler manages HTTP requests for fallback chain operations
+type FallbackChainsHandler struct {
+	configStore          configstore.ConfigStore
+	fallbackChainManager FallbackChainManager
+}
+
+// NewFallbackChainsHandler creates a new fallback chains handler instance
+func NewFallbackChainsHandler(manager FallbackChainManager, configStore configstore.ConfigStore) (*FallbackChainsHandler, error) {
+	if configStore == nil {
+		return nil, fmt.Errorf("config store is required")
+	}
+	return &FallbackChainsHandler{
+		configStore:          configStore,
+		fallbackChainManager: manager,
+	}, nil
+}
+
+// UpsertFallbackChainRequest represents the request body for creating or updating a fallback chain
+type UpsertFallbackChainRequest struct {
+	Alias       string                `json:"alias,omitempty"` // Only read on create, the alias of a chain cannot change
+	Description string                `json:"description,omitempty"`
+	Hops        []schemas.FallbackHop `json:"hops"`
+}
+
+// RegisterRoutes registers all fallback chain management routes. Aliases can contain a slash, as in "provider/model".
+func (h *FallbackChainsHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
+	r.GET("/api/fallback-chains", lib.ChainMiddlewares(h.getFallbackChains, middlewares...))
+	r.POST("/api/fallback-chains", lib.ChainMiddlewares(h.createFallbackChain, middlewares...))
+	r.GET("/api/fallback-chains/{alias:*}", lib.ChainMiddlewares(h.getFallbackChain, middlewares...))
+	r.PUT("/api/fallback-chains/{alias:*}", lib.ChainMiddlewares(h.updateFallbackChain, middlewares...))
+	r.DELETE("/api/fallback-chains/{alias:*}", lib.ChainMiddlewares(h.deleteFallbackChain, middlewares...))
+}
+
+// getFallbackChains handles GET /api/fallback-chains - Get all fallback chains
+func (h *FallbackChainsHandler) getFallbackChains(ctx *fasthttp.RequestCtx) {
+	chains, err := h.configStore.GetFallbackChains(ctx)
+	if err != nil {
+		logger.Error("failed to retrieve fallback chains: %v", err)
+		SendError(ctx, 500, "Failed to retrieve fallback chains")
+		return
+	}
+	SendJSON(ctx, map[string]interface{}{
+		"fallback_chains": chains,
+		"count":           len(chains),
+	})
+}
+
+// createFallbackChain handles POST /api/fallback-chains - Create the fallback chain of a model alias
+func (h *FallbackChainsHandler) createFallbackChain(ctx *fasthttp.RequestCtx) {
+	var req UpsertFallbackChainRequest
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		SendError(ctx, 400, "Invalid JSON")
+		return
+	}
+	chain := configstoreTables.TableFallbackChain{
+		Alias:       req.Alias,
+		Description: req.Description,
+		Hops:        req.Hops,
+	}
+	schemaChain := chain.ToSchema()
+	if err := schemaChain.Validate(); err != nil {
+		SendError(ctx, 400, err.Error())
+		return
+	}
+	if err := h.configStore.CreateFallbackChain(ctx, &chain); err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			SendError(ctx, 409, err.Error())
+			return
+		}
+		SendError(ctx, 500, fmt.Sprintf("Failed to create fallback chain: %v", err))
+		return
+	}
+	if err := h.fallbackChainManager.ReloadFallbackChains(ctx); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to reload fallback chains: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message":        "Fallback chain created successfully",
+		"fallback_chain": chain,
+	})
+}
+
+// getFallbackChain handles GET /api/fallback-chains/{alias} - Get the fallback chain of a model alias
+func (h *FallbackChainsHandler) getFallbackChain(ctx *fasthttp.RequestCtx) {
+	alias := ctx.UserValue("alias").(string)
+	chain, err := h.configStore.GetFallbackChain(ctx, alias)
+	if err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Fallback chain not found")
+			return
+		}
+		SendError(ctx, 500, "Failed to retrieve fallback chain")
+		return
+	}
+	SendJSON(ctx, map[string]interface{}{
+		"fallback_chain": chain,
+	})
+}
+
+// updateFallbackChain handles PUT /api/fallback-chains/{alias} - Replace the hops of a fallback chain
+func (h *FallbackChainsHandler) updateFallbackChain(ctx *fasthttp.RequestCtx) {
+	alias := ctx.UserValue("alias").(string)
+	var req UpsertFallbackChainRequest
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		SendError(ctx, 400, "Invalid JSON")
+		return
+	}
+	chain, err := h.configStore.GetFallbackChain(ctx, alias)
+	if err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Fallback chain not found")
+			return
+		}
+		SendError(ctx, 500, "Failed to retrieve fallback chain")
+		return
+	}
+	chain.Description = req.Description
+	chain.Hops = req.Hops
+	schemaChain := chain.ToSchema()
+	if err := schemaChain.Validate(); err != nil {
+		SendError(ctx, 400, err.Error())
+		return
+	}
+	if err := h.configStore.UpdateFallbackChain(ctx, chain); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to update fallback chain: %v", err))
+		return
+	}
+	if err := h.fallbackChainManager.ReloadFallbackChains(ctx); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to reload fallback chains: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message":        "Fallback chain updated successfully",
+		"fallback_chain": chain,
+	})
+}
+
+// deleteFallbackChain handles DELETE /api/fallback-chains/{alias} - Delete the fallback chain of a model alias
+func (h *FallbackChainsHandler) deleteFallbackChain(ctx *fasthttp.RequestCtx) {
+	alias := ctx.UserValue("alias").(string)
+	if err := h.configStore.DeleteFallbackChain(ctx, alias); err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Fallback chain not found")
+			return
+		}
+		SendError(ctx, 500, fmt.Sprintf("Failed to delete fallback chain: %v", err))
+		return
+	}
+	if err := h.fallbackChainManager.ReloadFallbackChains(ctx); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to reload fallback chains: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message": "Fallback chain deleted successfully",
+	})
+}
@@ -0,0 +1,81 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the response feedback handler.
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/fasthttp/router"
+	"github.com/maximhq/bifrost/framework/logstore"
+	"github.com/maximhq/bifrost/plugins/logging"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// FeedbackHandler accepts the feedback of clients on the responses of their requests
+type FeedbackHandler struct {
+	logManager logging.LogManager
+}
+
+// NewFeedbackHandler creates a new feedback handler instance
+func NewFeedbackHandler(logManager logging.LogManager) *FeedbackHandler {
+	return &FeedbackHandler{
+		logManager: logManager,
+	}
+}
+
+// FeedbackRequest is the feedback of a client on the response of a request, with a rating, a score or both
+type FeedbackRequest struct {
+	RequestID string   `json:"request_id"`        // Returned in the x-request-id response header
+	Rating    *int     `json:"rating,omitempty"`  // 1 for thumbs up, -1 for thumbs down
+	Score     *float64 `json:"score,omitempty"`   // Score on the scale of the client, e.g. 1 to 5
+	Comment   string   `json:"comment,omitempty"` // Free text, stored as sent
+}
+
+// RegisterRoutes registers the feedback route next to the inference routes, so that it accepts the credentials of the
+// clients sending the requests
+func (h *FeedbackHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
+	r.POST("/v1/feedback", lib.ChainMiddlewares(h.createFeedback, middlewares...))
+}
+
+// createFeedback handles POST /v1/feedback - Record the feedback of a client on the response of a request
+func (h *FeedbackHandler) createFeedback(ctx *fasthttp.RequestCtx) {
+	var req FeedbackRequest
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if req.RequestID == "" {
+		SendError(ctx, fasthttp.StatusBadRequest, "request_id is required")
+		return
+	}
+	if req.Rating == nil && req.Score == nil {
+		SendError(ctx, fasthttp.StatusBadRequest, "rating or score is required")
+		return
+	}
+	if req.Rating != nil && *req.Rating != 1 && *req.Rating != -1 {
+		SendError(ctx, fasthttp.StatusBadRequest, "rating must be 1 (thumbs up) or -1 (thumbs down)")
+		return
+	}
+
+	feedback := &logstore.Feedback{
+		RequestID: req.RequestID,
+		Rating:    req.Rating,
+		Score:     req.Score,
+		Comment:   req.Comment,
+	}
+	if err := h.logManager.AddFeedback(ctx, feedback); err != nil {
+		if errors.Is(err, logstore.ErrNotFound) {
+			SendError(ctx, fasthttp.StatusNotFound, fmt.Sprintf("Request %s not found", req.RequestID))
+			return
+		}
+		SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to record feedback: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message":  "Feedback recorded successfully",
+		"feedback": feedback,
+	})
+}
@@ -28,6 +28,7 @@ type GovernanceManager interface {
 	RemoveTeam(ctx context.Context, id string) error
 	ReloadCustomer(ctx context.Context, id string) (*configstoreTables.TableCustomer, error)
 	RemoveCustomer(ctx context.Context, id string) error
+	ResetTestKeySpend(ctx context.Context, keyID string) error
 }
 
 // GovernanceHandler manages HTTP requests for governance operations
@@ -63,11 +64,19 @@ type CreateVirtualKeyRequest struct {
 		MCPClientName  string   `json:"mcp_client_name" validate:"required"`
 		ToolsToExecute []string `json:"tools_to_execute,omitempty"`
 	} `json:"mcp_configs,omitempty"` // Empty means all MCP clients allowed
-	TeamID     *string                 `json:"team_id,omitempty"`     // Mutually exclusive with CustomerID
-	CustomerID *string                 `json:"customer_id,omitempty"` // Mutually exclusive with TeamID
-	Budget     *CreateBudgetRequest    `json:"budget,omitempty"`
-	RateLimit  *CreateRateLimitRequest `json:"rate_limit,omitempty"`
-	IsActive   *bool                   `json:"is_active,omitempty"`
+	TeamID             *string                                    `json:"team_id,omitempty"`     // Mutually exclusive with CustomerID
+	CustomerID         *string                                    `json:"customer_id,omitempty"` // Mutually exclusive with TeamID
+	Budget             *CreateBudgetRequest                       `json:"budget,omitempty"`
+	RateLimit          *CreateRateLimitRequest                    `json:"rate_limit,omitempty"`
+	IsActive           *bool                                      `json:"is_active,omitempty"`
+	DefaultParams      *configstoreTables.VirtualKeyDefaultParams `json:"default_params,omitempty"`       // Applied when the client omits them
+	SystemPrompt       *string                                    `json:"system_prompt,omitempty"`        // Pinned system prompt, applied when the client sends none
+	DedupeWindow       *string                                    `json:"dedupe_window,omitempty"`        // Window for x-bf-event-id deduplication, e.g. "10m"
+	SystemPromptPolicy *configstoreTables.SystemPromptPolicy      `json:"system_prompt_policy,omitempty"` // Enforced system prompt, supersedes the pinned system prompt
+	EndUserLimits      *configstoreTables.EndUserLimits           `json:"end_user_limits,omitempty"`      // Rate limits and budget of each end user
+	ResidencyPolicy    *configstoreTables.ResidencyPolicy         `json:"residency_policy,omitempty"`     // Regions the requests are kept in
+	MaxOutputTokens    *int                                       `json:"max_output_tokens,omitempty"`    // Cap on the output tokens of each request, supersedes the cap of the team
+	Namespace          *string                                    `json:"namespace,omitempty"`            // Only honoured for the root admin
 }
 
 // UpdateVirtualKeyRequest represents the request body for updating a virtual key
@@ -88,11 +97,23 @@ type UpdateVirtualKeyRequest struct {
 		MCPClientName  string   `json:"mcp_client_name" validate:"required"`
 		ToolsToExecute []string `json:"tools_to_execute,omitempty"`
 	} `json:"mcp_configs,omitempty"`
-	TeamID     *string                 `json:"team_id,omitempty"`
-	CustomerID *string                 `json:"customer_id,omitempty"`
-	Budget     *UpdateBudgetRequest    `json:"budget,omitempty"`
-	RateLimit  *UpdateRateLimitRequest `json:"rate_limit,omitempty"`
-	IsActive   *bool                   `json:"is_active,omitempty"`
+	TeamID             *string                                    `json:"team_id,omitempty"`
+	CustomerID         *string                                    `json:"customer_id,omitempty"`
+	Budget             *UpdateBudgetRequest                       `json:"budget,omitempty"`
+	RateLimit          *UpdateRateLimitRequest                    `json:"rate_limit,omitempty"`
+	IsActive           *bool                                      `json:"is_active,omitempty"`
+	DefaultParams      *configstoreTables.VirtualKeyDefaultParams `json:"default_params,omitempty"` // Empty default params remove the default params
+	SystemPrompt       *string                                    `json:"system_prompt,omitempty"`
+	DedupeWindow       *string                                    `json:"dedupe_window,omitempty"`
+	SystemPromptPolicy *configstoreTables.SystemPromptPolicy      `json:"system_prompt_policy,omitempty"` // A policy with an empty mode removes the policy
+	EndUserLimits      *configstoreTables.EndUserLimits           `json:"end_user_limits,omitempty"`      // Limits without any max limit remove the end user limits
+	ResidencyPolicy    *configstoreTables.ResidencyPolicy         `json:"residency_policy,omitempty"`     // A policy without regions removes the policy
+	MaxOutputTokens    *int                                       `json:"max_output_tokens,omitempty"`    // 0 removes the cap
+}
+
+// RotateVirtualKeyRequest represents the request body for rotating a virtual key
+type RotateVirtualKeyRequest struct {
+	GracePeriod *string `json:"grace_period,omitempty"` // Time the replaced value keeps working, e.g. "1h". Empty invalidates it immediately
 }
 
 // CreateBudgetRequest represents the request body for creating a budget
@@ -125,22 +146,28 @@ type UpdateRateLimitRequest struct {
 
 // CreateTeamRequest represents the request body for creating a team
 type CreateTeamRequest struct {
-	Name       string               `json:"name" validate:"required"`
-	CustomerID *string              `json:"customer_id,omitempty"` // Team can belong to a customer
-	Budget     *CreateBudgetRequest `json:"budget,omitempty"`      // Team can have its own budget
+	Name               string                                `json:"name" validate:"required"`
+	CustomerID         *string                               `json:"customer_id,omitempty"`          // Team can belong to a customer
+	Budget             *CreateBudgetRequest                  `json:"budget,omitempty"`               // Team can have its own budget
+	Namespace          *string                               `json:"namespace,omitempty"`            // Only honoured for the root admin
+	SystemPromptPolicy *configstoreTables.SystemPromptPolicy `json:"system_prompt_policy,omitempty"` // Enforced system prompt of the virtual keys of the team
+	MaxOutputTokens    *int                                  `json:"max_output_tokens,omitempty"`    // Cap on the output tokens of each request of the virtual keys of the team
 }
 
 // UpdateTeamRequest represents the request body for updating a team
 type UpdateTeamRequest struct {
-	Name       *string              `json:"name,omitempty"`
-	CustomerID *string              `json:"customer_id,omitempty"`
-	Budget     *UpdateBudgetRequest `json:"budget,omitempty"`
+	Name               *string                               `json:"name,omitempty"`
+	CustomerID         *string                               `json:"customer_id,omitempty"`
+	Budget             *UpdateBudgetRequest                  `json:"budget,omitempty"`
+	SystemPromptPolicy *configstoreTables.SystemPromptPolicy `json:"system_prompt_policy,omitempty"` // A policy with an empty mode removes the policy
+	MaxOutputTokens    *int                                  `json:"max_output_tokens,omitempty"`    // 0 removes the cap
 }
 
 // CreateCustomerRequest represents the request body for creating a customer
 type CreateCustomerRequest struct {
-	Name   string               `json:"name" validate:"required"`
-	Budget *CreateBudgetRequest `json:"budget,omitempty"`
+	Name      string               `json:"name" validate:"required"`
+	Budget    *CreateBudgetRequest `json:"budget,omitempty"`
+	Namespace *string              `json:"namespace,omitempty"` // Only honoured for the root admin
 }
 
 // UpdateCustomerRequest represents the request body for updating a customer
@@ -157,6 +184,8 @@ func (h *GovernanceHandler) RegisterRoutes(r *router.Router, middlewares ...lib.
 	r.GET("/api/governance/virtual-keys/{vk_id}", lib.ChainMiddlewares(h.getVirtualKey, middlewares...))
 	r.PUT("/api/governance/virtual-keys/{vk_id}", lib.ChainMiddlewares(h.updateVirtualKey, middlewares...))
 	r.DELETE("/api/governance/virtual-keys/{vk_id}", lib.ChainMiddlewares(h.deleteVirtualKey, middlewares...))
+	r.POST("/api/governance/virtual-keys/{vk_id}/rotate", lib.ChainMiddlewares(h.rotateVirtualKey, middlewares...))
+	r.POST("/api/governance/virtual-keys/{vk_id}/revoke", lib.ChainMiddlewares(h.revokeVirtualKey, middlewares...))
 
 	// Team CRUD operations
 	r.GET("/api/governance/teams", lib.ChainMiddlewares(h.getTeams, middlewares...))
@@ -171,6 +200,10 @@ func (h *GovernanceHandler) RegisterRoutes(r *router.Router, middlewares ...lib.
 	r.GET("/api/governance/customers/{customer_id}", lib.ChainMiddlewares(h.getCustomer, middlewares...))
 	r.PUT("/api/governance/customers/{customer_id}", lib.ChainMiddlewares(h.updateCustomer, middlewares...))
 	r.DELETE("/api/governance/customers/{customer_id}", lib.ChainMiddlewares(h.deleteCustomer, middlewares...))
+
+	// Test key spend operations
+	r.GET("/api/governance/test-keys", lib.ChainMiddlewares(h.getTestKeySpends, middlewares...))
+	r.POST("/api/governance/test-keys/{key_id}/reset", lib.ChainMiddlewares(h.resetTestKeySpend, middlewares...))
 }
 
 // Virtual Key CRUD Operations
@@ -184,6 +217,15 @@ func (h *GovernanceHandler) getVirtualKeys(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, 500, "Failed to retrieve virtual keys")
 		return
 	}
+	if _, scoped := getRequestNamespace(ctx); scoped {
+		filtered := make([]configstoreTables.TableVirtualKey, 0, len(virtualKeys))
+		for _, vk := range virtualKeys {
+			if canAccessNamespace(ctx, vk.Namespace) {
+				filtered = append(filtered, vk)
+			}
+		}
+		virtualKeys = filtered
+	}
 	SendJSON(ctx, map[string]interface{}{
 		"virtual_keys": virtualKeys,
 		"count":        len(virtualKeys),
@@ -192,6 +234,9 @@ func (h *GovernanceHandler) getVirtualKeys(ctx *fasthttp.RequestCtx) {
 
 // createVirtualKey handles POST /api/governance/virtual-keys - Create a new virtual key
 func (h *GovernanceHandler) createVirtualKey(ctx *fasthttp.RequestCtx) {
+	if !validateConfigPayload(ctx, "virtual_key") {
+		return
+	}
 	var req CreateVirtualKeyRequest
 	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
 		SendError(ctx, 400, "Invalid JSON")
@@ -219,6 +264,47 @@ func (h *GovernanceHandler) createVirtualKey(ctx *fasthttp.RequestCtx) {
 			return
 		}
 	}
+	if err := validateVirtualKeyDefaultParams(req.DefaultParams); err != nil {
+		SendError(ctx, 400, err.Error())
+		return
+	}
+	if req.DedupeWindow != nil {
+		if _, err := configstoreTables.ParseDuration(*req.DedupeWindow); err != nil {
+			SendError(ctx, 400, fmt.Sprintf("Invalid dedupe window format: %s", *req.DedupeWindow))
+			return
+		}
+	}
+	if req.SystemPromptPolicy != nil {
+		if err := req.SystemPromptPolicy.Validate(); err != nil {
+			SendError(ctx, 400, err.Error())
+			return
+		}
+	}
+	if req.EndUserLimits != nil {
+		if err := req.EndUserLimits.Validate(); err != nil {
+			SendError(ctx, 400, err.Error())
+			return
+		}
+	}
+	if req.ResidencyPolicy != nil {
+		if err := req.ResidencyPolicy.Validate(); err != nil {
+			SendError(ctx, 400, err.Error())
+			return
+		}
+	}
+	if req.MaxOutputTokens != nil && *req.MaxOutputTokens <= 0 {
+		SendError(ctx, 400, "max_output_tokens must be greater than 0")
+		return
+	}
+	namespace, err := resolveNamespaceForCreate(ctx, req.Namespace)
+	if err != nil {
+		SendError(ctx, 403, err.Error())
+		return
+	}
+	if err := h.validateNamespaceReferences(ctx, namespace, req.TeamID, req.CustomerID); err != nil {
+		SendError(ctx, 400, err.Error())
+		return
+	}
 	// Set defaults
 	isActive := true
 	if req.IsActive != nil {
@@ -227,17 +313,26 @@ func (h *GovernanceHandler) createVirtualKey(ctx *fasthttp.RequestCtx) {
 	var vk configstoreTables.TableVirtualKey
 	if err := h.configStore.ExecuteTransaction(ctx, func(tx *gorm.DB) error {
 		vk = configstoreTables.TableVirtualKey{
-			ID:          uuid.NewString(),
-			Name:        req.Name,
-			Value:       governance.VirtualKeyPrefix + uuid.NewString(),
-			Description: req.Description,
-			TeamID:      req.TeamID,
-			CustomerID:  req.CustomerID,
-			IsActive:    isActive,
+			ID:                 uuid.NewString(),
+			Name:               req.Name,
+			Value:              governance.VirtualKeyPrefix + uuid.NewString(),
+			Description:        req.Description,
+			Namespace:          namespace,
+			TeamID:             req.TeamID,
+			CustomerID:         req.CustomerID,
+			IsActive:           isActive,
+			DefaultParams:      req.DefaultParams,
+			SystemPrompt:       req.SystemPrompt,
+			DedupeWindow:       req.DedupeWindow,
+			SystemPromptPolicy: req.SystemPromptPolicy,
+			EndUserLimits:      req.EndUserLimits,
+			ResidencyPolicy:    req.ResidencyPolicy,
+			MaxOutputTokens:    req.MaxOutputTokens,
 		}
 		if req.Budget != nil {
 			budget := configstoreTables.TableBudget{
 				ID:            uuid.NewString(),
+				Namespace:     vk.Namespace,
 				MaxLimit:      req.Budget.MaxLimit,
 				ResetDuration: req.Budget.ResetDuration,
 				LastReset:     time.Now(),
@@ -254,6 +349,7 @@ func (h *GovernanceHandler) createVirtualKey(ctx *fasthttp.RequestCtx) {
 		if req.RateLimit != nil {
 			rateLimit := configstoreTables.TableRateLimit{
 				ID:                   uuid.NewString(),
+				Namespace:            vk.Namespace,
 				TokenMaxLimit:        req.RateLimit.TokenMaxLimit,
 				TokenResetDuration:   req.RateLimit.TokenResetDuration,
 				RequestMaxLimit:      req.RateLimit.RequestMaxLimit,
@@ -296,6 +392,11 @@ func (h *GovernanceHandler) createVirtualKey(ctx *fasthttp.RequestCtx) {
 				if len(keys) != len(pc.KeyIDs) {
 					return fmt.Errorf("some keys not found for provider %s: expected %d, found %d", pc.Provider, len(pc.KeyIDs), len(keys))
 				}
+				for _, key := range keys {
+					if key.Namespace != vk.Namespace {
+						return fmt.Errorf("key %s does not belong to namespace %s", key.KeyID, vk.Namespace)
+					}
+				}
 			}
 
 			providerConfig := &configstoreTables.TableVirtualKeyProviderConfig{
@@ -310,6 +411,7 @@ func (h *GovernanceHandler) createVirtualKey(ctx *fasthttp.RequestCtx) {
 			if pc.Budget != nil {
 					budget := configstoreTables.TableBudget{
 						ID:            uuid.NewString(),
+						Namespace:     vk.Namespace,
 						MaxLimit:      pc.Budget.MaxLimit,
 						ResetDuration: pc.Budget.ResetDuration,
 						LastReset:     time.Now(),
@@ -327,6 +429,7 @@ func (h *GovernanceHandler) createVirtualKey(ctx *fasthttp.RequestCtx) {
 				if pc.RateLimit != nil {
 					rateLimit := configstoreTables.TableRateLimit{
 						ID:                   uuid.NewString(),
+						Namespace:            vk.Namespace,
 						TokenMaxLimit:        pc.RateLimit.TokenMaxLimit,
 						TokenResetDuration:   pc.RateLimit.TokenResetDuration,
 						RequestMaxLimit:      pc.RateLimit.RequestMaxLimit,
@@ -406,7 +509,16 @@ func (h *GovernanceHandler) getVirtualKey(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, 500, "Failed to retrieve virtual key")
 		return
 	}
+	if !canAccessNamespace(ctx, vk.Namespace) {
+		SendError(ctx, 404, "Virtual key not found")
+		return
+	}
+	etag := virtualKeyETag(vk.ID, vk.UpdatedAt)
+	if checkNotModified(ctx, etag) {
+		return
+	}
 
+	setETag(ctx, etag)
 	SendJSON(ctx, map[string]interface{}{
 		"virtual_key": vk,
 	})
@@ -415,6 +527,9 @@ func (h *GovernanceHandler) getVirtualKey(ctx *fasthttp.RequestCtx) {
 // updateVirtualKey handles PUT /api/governance/virtual-keys/{vk_id} - Update a virtual key
 func (h *GovernanceHandler) updateVirtualKey(ctx *fasthttp.RequestCtx) {
 	vkID := ctx.UserValue("vk_id").(string)
+	if !validateConfigPayload(ctx, "virtual_key_update") {
+		return
+	}
 	var req UpdateVirtualKeyRequest
 	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
 		SendError(ctx, 400, "Invalid JSON")
@@ -425,6 +540,38 @@ func (h *GovernanceHandler) updateVirtualKey(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, 400, "VirtualKey cannot be attached to both Team and Customer")
 		return
 	}
+	if err := validateVirtualKeyDefaultParams(req.DefaultParams); err != nil {
+		SendError(ctx, 400, err.Error())
+		return
+	}
+	if req.DedupeWindow != nil && *req.DedupeWindow != "" {
+		if _, err := configstoreTables.ParseDuration(*req.DedupeWindow); err != nil {
+			SendError(ctx, 400, fmt.Sprintf("Invalid dedupe window format: %s", *req.DedupeWindow))
+			return
+		}
+	}
+	if req.SystemPromptPolicy != nil && req.SystemPromptPolicy.Mode != "" {
+		if err := req.SystemPromptPolicy.Validate(); err != nil {
+			SendError(ctx, 400, err.Error())
+			return
+		}
+	}
+	if req.EndUserLimits != nil {
+		if err := req.EndUserLimits.Validate(); err != nil {
+			SendError(ctx, 400, err.Error())
+			return
+		}
+	}
+	if req.ResidencyPolicy != nil && len(req.ResidencyPolicy.Regions) > 0 {
+		if err := req.ResidencyPolicy.Validate(); err != nil {
+			SendError(ctx, 400, err.Error())
+			return
+		}
+	}
+	if req.MaxOutputTokens != nil && *req.MaxOutputTokens < 0 {
+		SendError(ctx, 400, "max_output_tokens cannot be negative")
+		return
+	}
 	vk, err := h.configStore.GetVirtualKey(ctx, vkID)
 	if err != nil {
 		if errors.Is(err, configstore.ErrNotFound) {
@@ -434,7 +581,34 @@ func (h *GovernanceHandler) updateVirtualKey(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, 500, "Failed to retrieve virtual key")
 		return
 	}
+	if !canAccessNamespace(ctx, vk.Namespace) {
+		SendError(ctx, 404, "Virtual key not found")
+		return
+	}
+	etag := virtualKeyETag(vk.ID, vk.UpdatedAt)
+	if !checkPreconditions(ctx, etag) {
+		return
+	}
+	conditional := len(ctx.Request.Header.Peek("If-Match")) > 0
+	if vk.IsRevoked() && req.IsActive != nil && *req.IsActive {
+		SendError(ctx, 400, "Revoked virtual key cannot be reactivated")
+		return
+	}
+	if err := h.validateNamespaceReferences(ctx, vk.Namespace, req.TeamID, req.CustomerID); err != nil {
+		SendError(ctx, 400, err.Error())
+		return
+	}
 	if err := h.configStore.ExecuteTransaction(ctx, func(tx *gorm.DB) error {
+		// A conditional update fails when another replica updated the virtual key since it was read
+		if conditional {
+			var current configstoreTables.TableVirtualKey
+			if err := tx.Select("updated_at").First(&current, "id = ?", vk.ID).Error; err != nil {
+				return err
+			}
+			if virtualKeyETag(vk.ID, current.UpdatedAt) != etag {
+				return errPreconditionFailed
+			}
+		}
 		// Update fields if provided
 		if req.Name != nil {
 			vk.Name = *req.Name
@@ -458,6 +632,61 @@ func (h *GovernanceHandler) updateVirtualKey(ctx *fasthttp.RequestCtx) {
 		if req.IsActive != nil {
 			vk.IsActive = *req.IsActive
 		}
+		if req.DefaultParams != nil {
+			// Empty default params remove them
+			if *req.DefaultParams == (configstoreTables.VirtualKeyDefaultParams{}) {
+				vk.DefaultParams = nil
+			} else {
+				vk.DefaultParams = req.DefaultParams
+			}
+		}
+		if req.SystemPrompt != nil {
+			// An empty string unpins the system prompt
+			if *req.SystemPrompt == "" {
+				vk.SystemPrompt = nil
+			} else {
+				vk.SystemPrompt = req.SystemPrompt
+			}
+		}
+		if req.DedupeWindow != nil {
+			// An empty string disables deduplication
+			if *req.DedupeWindow == "" {
+				vk.DedupeWindow = nil
+			} else {
+				vk.DedupeWindow = req.DedupeWindow
+			}
+		}
+		if req.SystemPromptPolicy != nil {
+			// An empty mode removes the policy
+			if req.SystemPromptPolicy.Mode == "" {
+				vk.SystemPromptPolicy = nil
+			} else {
+				vk.SystemPromptPolicy = req.SystemPromptPolicy
+			}
+		}
+		if req.EndUserLimits != nil {
+			if req.EndUserLimits.IsEmpty() {
+				vk.EndUserLimits = nil
+			} else {
+				vk.EndUserLimits = req.EndUserLimits
+			}
+		}
+		if req.ResidencyPolicy != nil {
+			// A policy without regions removes the policy
+			if len(req.ResidencyPolicy.Regions) == 0 {
+				vk.ResidencyPolicy = nil
+			} else {
+				vk.ResidencyPolicy = req.ResidencyPolicy
+			}
+		}
+		if req.MaxOutputTokens != nil {
+			// 0 removes the cap
+			if *req.MaxOutputTokens == 0 {
+				vk.MaxOutputTokens = nil
+			} else {
+				vk.MaxOutputTokens = req.MaxOutputTokens
+			}
+		}
 		// Handle budget updates
 		if req.Budget != nil {
 			if vk.BudgetID != nil {
@@ -494,6 +723,7 @@ func (h *GovernanceHandler) updateVirtualKey(ctx *fasthttp.RequestCtx) {
 				// Storing now
 				budget := configstoreTables.TableBudget{
 					ID:            uuid.NewString(),
+					Namespace:     vk.Namespace,
 					MaxLimit:      *req.Budget.MaxLimit,
 					ResetDuration: *req.Budget.ResetDuration,
 					LastReset:     time.Now(),
@@ -538,6 +768,7 @@ func (h *GovernanceHandler) updateVirtualKey(ctx *fasthttp.RequestCtx) {
 				// Create new rate limit
 				rateLimit := configstoreTables.TableRateLimit{
 					ID:                   uuid.NewString(),
+					Namespace:            vk.Namespace,
 					TokenMaxLimit:        req.RateLimit.TokenMaxLimit,
 					TokenResetDuration:   req.RateLimit.TokenResetDuration,
 					RequestMaxLimit:      req.RateLimit.RequestMaxLimit,
@@ -599,6 +830,11 @@ func (h *GovernanceHandler) updateVirtualKey(ctx *fasthttp.RequestCtx) {
 				if len(keys) != len(pc.KeyIDs) {
 					return fmt.Errorf("some keys not found for provider %s: expected %d, found %d", pc.Provider, len(pc.KeyIDs), len(keys))
 				}
+				for _, key := range keys {
+					if key.Namespace != vk.Namespace {
+						return fmt.Errorf("key %s does not belong to namespace %s", key.KeyID, vk.Namespace)
+					}
+				}
 			}
 
 				// Create new provider config
@@ -613,6 +849,7 @@ func (h *GovernanceHandler) updateVirtualKey(ctx *fasthttp.RequestCtx) {
 				if pc.Budget != nil {
 						budget := configstoreTables.TableBudget{
 							ID:            uuid.NewString(),
+							Namespace:     vk.Namespace,
 							MaxLimit:      *pc.Budget.MaxLimit,
 							ResetDuration: *pc.Budget.ResetDuration,
 							LastReset:     time.Now(),
@@ -630,6 +867,7 @@ func (h *GovernanceHandler) updateVirtualKey(ctx *fasthttp.RequestCtx) {
 					if pc.RateLimit != nil {
 						rateLimit := configstoreTables.TableRateLimit{
 							ID:                   uuid.NewString(),
+							Namespace:            vk.Namespace,
 							TokenMaxLimit:        pc.RateLimit.TokenMaxLimit,
 							TokenResetDuration:   pc.RateLimit.TokenResetDuration,
 							RequestMaxLimit:      pc.RateLimit.RequestMaxLimit,
@@ -670,6 +908,11 @@ func (h *GovernanceHandler) updateVirtualKey(ctx *fasthttp.RequestCtx) {
 					if len(keys) != len(pc.KeyIDs) {
 						return fmt.Errorf("some keys not found for provider %s: expected %d, found %d", pc.Provider, len(pc.KeyIDs), len(keys))
 					}
+					for _, key := range keys {
+						if key.Namespace != vk.Namespace {
+							return fmt.Errorf("key %s does not belong to namespace %s", key.KeyID, vk.Namespace)
+						}
+					}
 				}
 				existing.Keys = keys
 
@@ -706,6 +949,7 @@ func (h *GovernanceHandler) updateVirtualKey(ctx *fasthttp.RequestCtx) {
 							}
 							budget := configstoreTables.TableBudget{
 								ID:            uuid.NewString(),
+								Namespace:     vk.Namespace,
 								MaxLimit:      *pc.Budget.MaxLimit,
 								ResetDuration: *pc.Budget.ResetDuration,
 								LastReset:     time.Now(),
@@ -747,6 +991,7 @@ func (h *GovernanceHandler) updateVirtualKey(ctx *fasthttp.RequestCtx) {
 							// Create new rate limit for existing provider config
 							rateLimit := configstoreTables.TableRateLimit{
 								ID:                   uuid.NewString(),
+								Namespace:            vk.Namespace,
 								TokenMaxLimit:        pc.RateLimit.TokenMaxLimit,
 								TokenResetDuration:   pc.RateLimit.TokenResetDuration,
 								RequestMaxLimit:      pc.RateLimit.RequestMaxLimit,
@@ -836,6 +1081,10 @@ func (h *GovernanceHandler) updateVirtualKey(ctx *fasthttp.RequestCtx) {
 		}
 		return nil
 	}); err != nil {
+		if errors.Is(err, errPreconditionFailed) {
+			sendPreconditionFailed(ctx, "")
+			return
+		}
 		errMsg := err.Error()
 		// Check if this is a duplicate MCPClientName error and return 400 instead of 500
 		if strings.Contains(errMsg, "duplicate mcp_client_name:") ||
@@ -852,6 +1101,8 @@ func (h *GovernanceHandler) updateVirtualKey(ctx *fasthttp.RequestCtx) {
 	if err != nil {
 		logger.Error("failed to load relationships for updated VK: %v", err)
 		preloadedVk = vk
+	} else {
+		setETag(ctx, virtualKeyETag(preloadedVk.ID, preloadedVk.UpdatedAt))
 	}
 	h.governanceManager.ReloadVirtualKey(ctx, vk.ID)
 	SendJSON(ctx, map[string]interface{}{
@@ -873,6 +1124,13 @@ func (h *GovernanceHandler) deleteVirtualKey(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, 500, "Failed to retrieve virtual key")
 		return
 	}
+	if !canAccessNamespace(ctx, vk.Namespace) {
+		SendError(ctx, 404, "Virtual key not found")
+		return
+	}
+	if !checkPreconditions(ctx, virtualKeyETag(vk.ID, vk.UpdatedAt)) {
+		return
+	}
 	// Removing key from in-memory store
 	err = h.governanceManager.RemoveVirtualKey(ctx, vk.ID)
 	if err != nil {
@@ -893,6 +1151,115 @@ func (h *GovernanceHandler) deleteVirtualKey(ctx *fasthttp.RequestCtx) {
 	})
 }
 
+// virtualKeyETag returns the ETag of a virtual key, which changes on every update of the virtual key but not with
+// the usage of its budgets and rate limits
+func virtualKeyETag(id string, updatedAt time.Time) string {
+	return resourceETag(id, updatedAt.UnixNano())
+}
+
+// rotateVirtualKey handles POST /api/governance/virtual-keys/{vk_id}/rotate - Replace the value of a virtual key
+// Its budgets, rate limits and allow-lists are kept. The replaced value keeps working for the optional grace period.
+func (h *GovernanceHandler) rotateVirtualKey(ctx *fasthttp.RequestCtx) {
+	vkID := ctx.UserValue("vk_id").(string)
+	var req RotateVirtualKeyRequest
+	if len(ctx.PostBody()) > 0 {
+		if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+			SendError(ctx, 400, "Invalid JSON")
+			return
+		}
+	}
+	var gracePeriod time.Duration
+	if req.GracePeriod != nil && *req.GracePeriod != "" {
+		var err error
+		gracePeriod, err = configstoreTables.ParseDuration(*req.GracePeriod)
+		if err != nil || gracePeriod < 0 {
+			SendError(ctx, 400, fmt.Sprintf("Invalid grace period format: %s", *req.GracePeriod))
+			return
+		}
+	}
+	vk, err := h.configStore.GetVirtualKey(ctx, vkID)
+	if err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Virtual key not found")
+			return
+		}
+		SendError(ctx, 500, "Failed to retrieve virtual key")
+		return
+	}
+	if !canAccessNamespace(ctx, vk.Namespace) {
+		SendError(ctx, 404, "Virtual key not found")
+		return
+	}
+	if vk.IsRevoked() {
+		SendError(ctx, 400, "Revoked virtual key cannot be rotated")
+		return
+	}
+	now := time.Now()
+	vk.PreviousValue = nil
+	vk.PreviousValueExpiresAt = nil
+	if gracePeriod > 0 {
+		previousValue := vk.Value
+		expiresAt := now.Add(gracePeriod)
+		vk.PreviousValue = &previousValue
+		vk.PreviousValueExpiresAt = &expiresAt
+	}
+	vk.Value = governance.VirtualKeyPrefix + uuid.NewString()
+	vk.RotatedAt = &now
+	if err := h.configStore.UpdateVirtualKey(ctx, vk); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to rotate virtual key: %v", err))
+		return
+	}
+	preloadedVk, err := h.governanceManager.ReloadVirtualKey(ctx, vk.ID)
+	if err != nil {
+		logger.Error("failed to reload rotated virtual key: %v", err)
+		preloadedVk = vk
+	}
+	SendJSON(ctx, map[string]interface{}{
+		"message":     "Virtual key rotated successfully",
+		"virtual_key": preloadedVk,
+	})
+}
+
+// revokeVirtualKey handles POST /api/governance/virtual-keys/{vk_id}/revoke - Permanently disable a virtual key
+// Unlike deletion, the key and its usage history are kept for auditing, and unlike deactivation it cannot be undone.
+func (h *GovernanceHandler) revokeVirtualKey(ctx *fasthttp.RequestCtx) {
+	vkID := ctx.UserValue("vk_id").(string)
+	vk, err := h.configStore.GetVirtualKey(ctx, vkID)
+	if err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Virtual key not found")
+			return
+		}
+		SendError(ctx, 500, "Failed to retrieve virtual key")
+		return
+	}
+	if !canAccessNamespace(ctx, vk.Namespace) {
+		SendError(ctx, 404, "Virtual key not found")
+		return
+	}
+	if !vk.IsRevoked() {
+		now := time.Now()
+		vk.IsActive = false
+		vk.RevokedAt = &now
+		// A value still in its rotation grace period is revoked as well
+		vk.PreviousValue = nil
+		vk.PreviousValueExpiresAt = nil
+		if err := h.configStore.UpdateVirtualKey(ctx, vk); err != nil {
+			SendError(ctx, 500, fmt.Sprintf("Failed to revoke virtual key: %v", err))
+			return
+		}
+	}
+	preloadedVk, err := h.governanceManager.ReloadVirtualKey(ctx, vk.ID)
+	if err != nil {
+		logger.Error("failed to reload revoked virtual key: %v", err)
+		preloadedVk = vk
+	}
+	SendJSON(ctx, map[string]interface{}{
+		"message":     "Virtual key revoked successfully",
+		"virtual_key": preloadedVk,
+	})
+}
+
 // Team CRUD Operations
 
 // getTeams handles GET /api/governance/teams - Get all teams
@@ -905,6 +1272,15 @@ func (h *GovernanceHandler) getTeams(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, 500, fmt.Sprintf("Failed to retrieve teams: %v", err))
 		return
 	}
+	if _, scoped := getRequestNamespace(ctx); scoped {
+		filtered := make([]configstoreTables.TableTeam, 0, len(teams))
+		for _, team := range teams {
+			if canAccessNamespace(ctx, team.Namespace) {
+				filtered = append(filtered, team)
+			}
+		}
+		teams = filtered
+	}
 	SendJSON(ctx, map[string]interface{}{
 		"teams": teams,
 		"count": len(teams),
@@ -913,6 +1289,9 @@ func (h *GovernanceHandler) getTeams(ctx *fasthttp.RequestCtx) {
 
 // createTeam handles POST /api/governance/teams - Create a new team
 func (h *GovernanceHandler) createTeam(ctx *fasthttp.RequestCtx) {
+	if !validateConfigPayload(ctx, "team") {
+		return
+	}
 	var req CreateTeamRequest
 	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
 		SendError(ctx, 400, "Invalid JSON")
@@ -935,17 +1314,40 @@ func (h *GovernanceHandler) createTeam(ctx *fasthttp.RequestCtx) {
 			return
 		}
 	}
+	if req.SystemPromptPolicy != nil {
+		if err := req.SystemPromptPolicy.Validate(); err != nil {
+			SendError(ctx, 400, err.Error())
+			return
+		}
+	}
+	if req.MaxOutputTokens != nil && *req.MaxOutputTokens <= 0 {
+		SendError(ctx, 400, "max_output_tokens must be greater than 0")
+		return
+	}
+	namespace, err := resolveNamespaceForCreate(ctx, req.Namespace)
+	if err != nil {
+		SendError(ctx, 403, err.Error())
+		return
+	}
+	if err := h.validateNamespaceReferences(ctx, namespace, nil, req.CustomerID); err != nil {
+		SendError(ctx, 400, err.Error())
+		return
+	}
 	// Creating team in database
 	var team configstoreTables.TableTeam
 	if err := h.configStore.ExecuteTransaction(ctx, func(tx *gorm.DB) error {
 		team = configstoreTables.TableTeam{
-			ID:         uuid.NewString(),
-			Name:       req.Name,
-			CustomerID: req.CustomerID,
+			ID:                 uuid.NewString(),
+			Name:               req.Name,
+			Namespace:          namespace,
+			CustomerID:         req.CustomerID,
+			SystemPromptPolicy: req.SystemPromptPolicy,
+			MaxOutputTokens:    req.MaxOutputTokens,
 		}
 		if req.Budget != nil {
 			budget := configstoreTables.TableBudget{
 				ID:            uuid.NewString(),
+				Namespace:     team.Namespace,
 				MaxLimit:      req.Budget.MaxLimit,
 				ResetDuration: req.Budget.ResetDuration,
 				LastReset:     time.Now(),
@@ -989,6 +1391,10 @@ func (h *GovernanceHandler) getTeam(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, 500, "Failed to retrieve team")
 		return
 	}
+	if !canAccessNamespace(ctx, team.Namespace) {
+		SendError(ctx, 404, "Team not found")
+		return
+	}
 	SendJSON(ctx, map[string]interface{}{
 		"team": team,
 	})
@@ -998,6 +1404,9 @@ func (h *GovernanceHandler) getTeam(ctx *fasthttp.RequestCtx) {
 func (h *GovernanceHandler) updateTeam(ctx *fasthttp.RequestCtx) {
 	teamID := ctx.UserValue("team_id").(string)
 
+	if !validateConfigPayload(ctx, "team_update") {
+		return
+	}
 	var req UpdateTeamRequest
 	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
 		SendError(ctx, 400, "Invalid JSON")
@@ -1013,6 +1422,24 @@ func (h *GovernanceHandler) updateTeam(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, 500, "Failed to retrieve team")
 		return
 	}
+	if !canAccessNamespace(ctx, team.Namespace) {
+		SendError(ctx, 404, "Team not found")
+		return
+	}
+	if err := h.validateNamespaceReferences(ctx, team.Namespace, nil, req.CustomerID); err != nil {
+		SendError(ctx, 400, err.Error())
+		return
+	}
+	if req.SystemPromptPolicy != nil && req.SystemPromptPolicy.Mode != "" {
+		if err := req.SystemPromptPolicy.Validate(); err != nil {
+			SendError(ctx, 400, err.Error())
+			return
+		}
+	}
+	if req.MaxOutputTokens != nil && *req.MaxOutputTokens < 0 {
+		SendError(ctx, 400, "max_output_tokens cannot be negative")
+		return
+	}
 	// Updating team in database
 	if err := h.configStore.ExecuteTransaction(ctx, func(tx *gorm.DB) error {
 		// Update fields if provided
@@ -1022,6 +1449,22 @@ func (h *GovernanceHandler) updateTeam(ctx *fasthttp.RequestCtx) {
 		if req.CustomerID != nil {
 			team.CustomerID = req.CustomerID
 		}
+		if req.SystemPromptPolicy != nil {
+			// An empty mode removes the policy
+			if req.SystemPromptPolicy.Mode == "" {
+				team.SystemPromptPolicy = nil
+			} else {
+				team.SystemPromptPolicy = req.SystemPromptPolicy
+			}
+		}
+		if req.MaxOutputTokens != nil {
+			// 0 removes the cap
+			if *req.MaxOutputTokens == 0 {
+				team.MaxOutputTokens = nil
+			} else {
+				team.MaxOutputTokens = req.MaxOutputTokens
+			}
+		}
 		// Handle budget updates
 		if req.Budget != nil {
 			if team.BudgetID != nil {
@@ -1045,6 +1488,7 @@ func (h *GovernanceHandler) updateTeam(ctx *fasthttp.RequestCtx) {
 				// Create new budget
 				budget := configstoreTables.TableBudget{
 					ID:            uuid.NewString(),
+					Namespace:     team.Namespace,
 					MaxLimit:      *req.Budget.MaxLimit,
 					ResetDuration: *req.Budget.ResetDuration,
 					LastReset:     time.Now(),
@@ -1089,6 +1533,10 @@ func (h *GovernanceHandler) deleteTeam(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, 500, "Failed to retrieve team")
 		return
 	}
+	if !canAccessNamespace(ctx, team.Namespace) {
+		SendError(ctx, 404, "Team not found")
+		return
+	}
 	// Removing team from in-memory store
 	err = h.governanceManager.RemoveTeam(ctx, team.ID)
 	if err != nil {
@@ -1118,6 +1566,15 @@ func (h *GovernanceHandler) getCustomers(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, 500, "failed to retrieve customers")
 		return
 	}
+	if _, scoped := getRequestNamespace(ctx); scoped {
+		filtered := make([]configstoreTables.TableCustomer, 0, len(customers))
+		for _, customer := range customers {
+			if canAccessNamespace(ctx, customer.Namespace) {
+				filtered = append(filtered, customer)
+			}
+		}
+		customers = filtered
+	}
 	SendJSON(ctx, map[string]interface{}{
 		"customers": customers,
 		"count":     len(customers),
@@ -1126,6 +1583,9 @@ func (h *GovernanceHandler) getCustomers(ctx *fasthttp.RequestCtx) {
 
 // createCustomer handles POST /api/governance/customers - Create a new customer
 func (h *GovernanceHandler) createCustomer(ctx *fasthttp.RequestCtx) {
+	if !validateConfigPayload(ctx, "customer") {
+		return
+	}
 	var req CreateCustomerRequest
 	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
 		SendError(ctx, 400, "Invalid JSON")
@@ -1148,16 +1608,23 @@ func (h *GovernanceHandler) createCustomer(ctx *fasthttp.RequestCtx) {
 			return
 		}
 	}
+	namespace, err := resolveNamespaceForCreate(ctx, req.Namespace)
+	if err != nil {
+		SendError(ctx, 403, err.Error())
+		return
+	}
 	var customer configstoreTables.TableCustomer
 	if err := h.configStore.ExecuteTransaction(ctx, func(tx *gorm.DB) error {
 		customer = configstoreTables.TableCustomer{
-			ID:   uuid.NewString(),
-			Name: req.Name,
+			ID:        uuid.NewString(),
+			Name:      req.Name,
+			Namespace: namespace,
 		}
 
 		if req.Budget != nil {
 			budget := configstoreTables.TableBudget{
 				ID:            uuid.NewString(),
+				Namespace:     customer.Namespace,
 				MaxLimit:      req.Budget.MaxLimit,
 				ResetDuration: req.Budget.ResetDuration,
 				LastReset:     time.Now(),
@@ -1199,6 +1666,10 @@ func (h *GovernanceHandler) getCustomer(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, 500, "Failed to retrieve customer")
 		return
 	}
+	if !canAccessNamespace(ctx, customer.Namespace) {
+		SendError(ctx, 404, "Customer not found")
+		return
+	}
 	SendJSON(ctx, map[string]interface{}{
 		"customer": customer,
 	})
@@ -1207,6 +1678,9 @@ func (h *GovernanceHandler) getCustomer(ctx *fasthttp.RequestCtx) {
 // updateCustomer handles PUT /api/governance/customers/{customer_id} - Update a customer
 func (h *GovernanceHandler) updateCustomer(ctx *fasthttp.RequestCtx) {
 	customerID := ctx.UserValue("customer_id").(string)
+	if !validateConfigPayload(ctx, "customer_update") {
+		return
+	}
 	var req UpdateCustomerRequest
 	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
 		SendError(ctx, 400, "Invalid JSON")
@@ -1222,6 +1696,10 @@ func (h *GovernanceHandler) updateCustomer(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, 500, "Failed to retrieve customer")
 		return
 	}
+	if !canAccessNamespace(ctx, customer.Namespace) {
+		SendError(ctx, 404, "Customer not found")
+		return
+	}
 	// Updating customer in database
 	if err := h.configStore.ExecuteTransaction(ctx, func(tx *gorm.DB) error {
 		// Update fields if provided
@@ -1252,6 +1730,7 @@ func (h *GovernanceHandler) updateCustomer(ctx *fasthttp.RequestCtx) {
 				// Create new budget
 				budget := configstoreTables.TableBudget{
 					ID:            uuid.NewString(),
+					Namespace:     customer.Namespace,
 					MaxLimit:      *req.Budget.MaxLimit,
 					ResetDuration: *req.Budget.ResetDuration,
 					LastReset:     time.Now(),
@@ -1298,6 +1777,10 @@ func (h *GovernanceHandler) deleteCustomer(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, 500, "Failed to retrieve customer")
 		return
 	}
+	if !canAccessNamespace(ctx, customer.Namespace) {
+		SendError(ctx, 404, "Customer not found")
+		return
+	}
 	err = h.governanceManager.RemoveCustomer(ctx, customer.ID)
 	if err != nil {
 		// But we ignore this error because its not
@@ -1316,6 +1799,76 @@ func (h *GovernanceHandler) deleteCustomer(ctx *fasthttp.RequestCtx) {
 	})
 }
 
+
+// Test Key Operations
+
+// getTestKeySpends handles GET /api/governance/test-keys - Get the spend of all test keys
+func (h *GovernanceHandler) getTestKeySpends(ctx *fasthttp.RequestCtx) {
+	if !requireRootAdmin(ctx) {
+		return
+	}
+	spends, err := h.configStore.GetKeySpends(ctx)
+	if err != nil {
+		logger.Error("failed to retrieve test key spend: %v", err)
+		SendError(ctx, 500, "Failed to retrieve test key spend")
+		return
+	}
+	SendJSON(ctx, map[string]interface{}{
+		"test_keys": spends,
+		"count":     len(spends),
+	})
+}
+
+// resetTestKeySpend handles POST /api/governance/test-keys/{key_id}/reset - Reset the spend of a test key
+// A test key disabled for reaching its spend limit is re-enabled, raising the limit alone does not re-enable it.
+func (h *GovernanceHandler) resetTestKeySpend(ctx *fasthttp.RequestCtx) {
+	if !requireRootAdmin(ctx) {
+		return
+	}
+	keyID := ctx.UserValue("key_id").(string)
+	if err := h.configStore.DeleteKeySpend(ctx, keyID); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to reset test key spend: %v", err))
+		return
+	}
+	if err := h.governanceManager.ResetTestKeySpend(ctx, keyID); err != nil {
+		logger.Error("failed to reset test key spend in memory: %v", err)
+		SendError(ctx, 500, "Failed to reset test key spend")
+		return
+	}
+	SendJSON(ctx, map[string]interface{}{
+		"message": "Test key spend reset successfully",
+	})
+}
+
+// validateNamespaceReferences checks that the team and customer an entity is attached to live in the same namespace
+func (h *GovernanceHandler) validateNamespaceReferences(ctx *fasthttp.RequestCtx, namespace string, teamID, customerID *string) error {
+	if teamID != nil && *teamID != "" {
+		team, err := h.configStore.GetTeam(ctx, *teamID)
+		if err != nil {
+			if errors.Is(err, configstore.ErrNotFound) {
+				return fmt.Errorf("team %s not found", *teamID)
+			}
+			return fmt.Errorf("failed to retrieve team: %w", err)
+		}
+		if team.Namespace != namespace {
+			return fmt.Errorf("team %s does not belong to namespace %s", *teamID, namespace)
+		}
+	}
+	if customerID != nil && *customerID != "" {
+		customer, err := h.configStore.GetCustomer(ctx, *customerID)
+		if err != nil {
+			if errors.Is(err, configstore.ErrNotFound) {
+				return fmt.Errorf("customer %s not found", *customerID)
+			}
+			return fmt.Errorf("failed to retrieve customer: %w", err)
+		}
+		if customer.Namespace != namespace {
+			return fmt.Errorf("customer %s does not belong to namespace %s", *customerID, namespace)
+		}
+	}
+	return nil
+}
+
 // validateRateLimit validates the rate limit
 func validateRateLimit(rateLimit *configstoreTables.TableRateLimit) error {
 	if rateLimit.TokenMaxLimit != nil && (*rateLimit.TokenMaxLimit < 0 || *rateLimit.TokenMaxLimit == 0) {
@@ -1358,3 +1911,17 @@ func validateBudget(budget *configstoreTables.TableBudget) error {
 	}
 	return nil
 }
+
+// validateVirtualKeyDefaultParams validates the default request parameters of a virtual key
+func validateVirtualKeyDefaultParams(params *configstoreTables.VirtualKeyDefaultParams) error {
+	if params == nil {
+		return nil
+	}
+	if params.MaxTemperature != nil && *params.MaxTemperature < 0 {
+		return fmt.Errorf("default_params max_temperature cannot be negative: %.2f", *params.MaxTemperature)
+	}
+	if params.Model != nil && strings.TrimSpace(*params.Model) == "" {
+		return fmt.Errorf("default_params model cannot be empty")
+	}
+	return nil
+}
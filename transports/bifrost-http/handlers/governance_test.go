@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+	"github.com/maximhq/bifrost/plugins/governance"
+	"github.com/valyala/fasthttp"
+	"gorm.io/gorm"
+)
+
+// rotationTestStore keeps the virtual keys of the rotation tests in memory
+type rotationTestStore struct {
+	configstore.ConfigStore
+	virtualKeys map[string]configstoreTables.TableVirtualKey
+}
+
+func (s *rotationTestStore) GetVirtualKey(ctx context.Context, id string) (*configstoreTables.TableVirtualKey, error) {
+	vk, ok := s.virtualKeys[id]
+	if !ok {
+		return nil, configstore.ErrNotFound
+	}
+	return &vk, nil
+}
+
+func (s *rotationTestStore) UpdateVirtualKey(ctx context.Context, virtualKey *configstoreTables.TableVirtualKey, tx ...*gorm.DB) error {
+	s.virtualKeys[virtualKey.ID] = *virtualKey
+	return nil
+}
+
+// rotationTestManager reloads the virtual keys of the config store into a governance store, as the governance
+// plugin does
+type rotationTestManager struct {
+	GovernanceManager
+	configStore *rotationTestStore
+	store       *governance.GovernanceStore
+}
+
+func (m *rotationTestManager) ReloadVirtualKey(ctx context.Context, id string) (*configstoreTables.TableVirtualKey, error) {
+	vk, err := m.configStore.GetVirtualKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	m.store.UpdateVirtualKeyInMemory(vk)
+	return vk, nil
+}
+
+// newRotationTestHandler creates a governance handler over a single virtual key, with the resolver governance
+// evaluates its requests with
+func newRotationTestHandler(t *testing.T, value string) (*GovernanceHandler, *governance.BudgetResolver) {
+	t.Helper()
+	logger := bifrost.NewDefaultLogger(schemas.LogLevelError)
+	store, err := governance.NewGovernanceStore(context.Background(), logger, nil, &configstore.GovernanceConfig{})
+	if err != nil {
+		t.Fatalf("failed to create governance store: %v", err)
+	}
+	vk := configstoreTables.TableVirtualKey{ID: "vk1", Name: "calls", Value: value, IsActive: true}
+	store.CreateVirtualKeyInMemory(&vk)
+	configStore := &rotationTestStore{virtualKeys: map[string]configstoreTables.TableVirtualKey{vk.ID: vk}}
+	handler, err := NewGovernanceHandler(&rotationTestManager{configStore: configStore, store: store}, configStore)
+	if err != nil {
+		t.Fatalf("failed to create governance handler: %v", err)
+	}
+	return handler, governance.NewBudgetResolver(store, logger)
+}
+
+// evaluateVirtualKey returns the decision of governance on a request made with the virtual key value
+func evaluateVirtualKey(resolver *governance.BudgetResolver, value string) governance.Decision {
+	ctx := schemas.NewBifrostContext(context.Background(), time.Time{})
+	defer ctx.Cancel()
+	return resolver.EvaluateRequest(ctx, &governance.EvaluationRequest{VirtualKey: value, Provider: schemas.OpenAI, Model: "gpt-4o"}).Decision
+}
+
+// rotateTestVirtualKey rotates the virtual key with the grace period and returns its new value
+func rotateTestVirtualKey(t *testing.T, handler *GovernanceHandler, gracePeriod string) string {
+	t.Helper()
+	ctx := &fasthttp.RequestCtx{}
+	ctx.SetUserValue("vk_id", "vk1")
+	if gracePeriod != "" {
+		ctx.Request.SetBodyString(`{"grace_period": "` + gracePeriod + `"}`)
+	}
+	handler.rotateVirtualKey(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("failed to rotate virtual key: %s", ctx.Response.Body())
+	}
+	var response struct {
+		VirtualKey configstoreTables.TableVirtualKey `json:"virtual_key"`
+	}
+	if err := json.Unmarshal(ctx.Response.Body(), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return response.VirtualKey.Value
+}
+
+// TestRotateVirtualKey_GracePeriod tests that the replaced value keeps working during the grace period of the
+// rotation only, and that a rotation without grace period replaces the value right away
+func TestRotateVirtualKey_GracePeriod(t *testing.T) {
+	handler, resolver := newRotationTestHandler(t, "sk-bf-original")
+
+	rotated := rotateTestVirtualKey(t, handler, "200ms")
+	if rotated == "sk-bf-original" {
+		t.Fatal("Expected the rotation to replace the value")
+	}
+	if decision := evaluateVirtualKey(resolver, rotated); decision != governance.DecisionAllow {
+		t.Errorf("Expected the new value to be allowed, got %s", decision)
+	}
+	if decision := evaluateVirtualKey(resolver, "sk-bf-original"); decision != governance.DecisionAllow {
+		t.Errorf("Expected the replaced value to be allowed during the grace period, got %s", decision)
+	}
+
+	time.Sleep(250 * time.Millisecond)
+	if decision := evaluateVirtualKey(resolver, "sk-bf-original"); decision != governance.DecisionVirtualKeyNotFound {
+		t.Errorf("Expected the replaced value to be rejected after the grace period, got %s", decision)
+	}
+	if decision := evaluateVirtualKey(resolver, rotated); decision != governance.DecisionAllow {
+		t.Errorf("Expected the new value to be allowed after the grace period, got %s", decision)
+	}
+
+	// Without grace period, the previous value stops working on rotation, including a value still in its grace period
+	second := rotateTestVirtualKey(t, handler, "1h")
+	third := rotateTestVirtualKey(t, handler, "")
+	for _, value := range []string{rotated, second} {
+		if decision := evaluateVirtualKey(resolver, value); decision != governance.DecisionVirtualKeyNotFound {
+			t.Errorf("Expected the replaced value %s to be rejected right away, got %s", value, decision)
+		}
+	}
+	if decision := evaluateVirtualKey(resolver, third); decision != governance.DecisionAllow {
+		t.Errorf("Expected the new value to be allowed, got %s", decision)
+	}
+}
+
+// TestRevokeVirtualKey tests that revoking a virtual key rejects its value and the value still in its rotation
+// grace period, and that a revoked virtual key cannot be rotated
+func TestRevokeVirtualKey(t *testing.T) {
+	handler, resolver := newRotationTestHandler(t, "sk-bf-original")
+	rotated := rotateTestVirtualKey(t, handler, "1h")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.SetUserValue("vk_id", "vk1")
+	handler.revokeVirtualKey(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("failed to revoke virtual key: %s", ctx.Response.Body())
+	}
+
+	if decision := evaluateVirtualKey(resolver, rotated); decision != governance.DecisionVirtualKeyBlocked {
+		t.Errorf("Expected the revoked value to be blocked, got %s", decision)
+	}
+	if decision := evaluateVirtualKey(resolver, "sk-bf-original"); decision != governance.DecisionVirtualKeyNotFound {
+		t.Errorf("Expected the value in its grace period to be rejected after the revocation, got %s", decision)
+	}
+
+	ctx = &fasthttp.RequestCtx{}
+	ctx.SetUserValue("vk_id", "vk1")
+	handler.rotateVirtualKey(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusBadRequest {
+		t.Errorf("Expected the rotation of the revoked virtual key to be refused, got %d", ctx.Response.StatusCode())
+	}
+}
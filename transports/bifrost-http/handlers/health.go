@@ -25,6 +25,17 @@ func NewHealthHandler(config *lib.Config) *HealthHandler {
 // RegisterRoutes registers the health-related routes.
 func (h *HealthHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
 	r.GET("/health", lib.ChainMiddlewares(h.getHealth, middlewares...))
+	r.GET("/health/ready", lib.ChainMiddlewares(h.getReadiness, middlewares...))
+}
+
+// getReadiness handles GET /health/ready - Get whether the server accepts inference requests. Draining servers
+// are not ready so that load balancers take them out of rotation, while /health keeps them alive.
+func (h *HealthHandler) getReadiness(ctx *fasthttp.RequestCtx) {
+	if h.config.GetDrain().IsDraining() {
+		SendJSONWithStatus(ctx, map[string]any{"status": "draining"}, fasthttp.StatusServiceUnavailable)
+		return
+	}
+	h.getHealth(ctx)
 }
 
 // getHealth handles GET /api/health - Get the health status of the server.
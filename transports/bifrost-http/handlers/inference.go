@@ -4,7 +4,9 @@ package handlers
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -13,6 +15,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/bytedance/sonic"
 	"github.com/fasthttp/router"
@@ -27,6 +30,7 @@ type CompletionHandler struct {
 	client       *bifrost.Bifrost
 	handlerStore lib.HandlerStore
 	config       *lib.Config
+	streams      *streamResumeBuffer
 }
 
 // NewInferenceHandler creates a new completion handler instance
@@ -35,6 +39,7 @@ func NewInferenceHandler(client *bifrost.Bifrost, config *lib.Config) *Completio
 		client:       client,
 		handlerStore: config,
 		config:       config,
+		streams:      newStreamResumeBuffer(),
 	}
 }
 
@@ -123,6 +128,7 @@ var embeddingParamsKnownFields = map[string]bool{
 	"fallbacks":       true,
 	"encoding_format": true,
 	"dimensions":      true,
+	"normalize":       true,
 }
 
 var speechParamsKnownFields = map[string]bool{
@@ -133,6 +139,7 @@ var speechParamsKnownFields = map[string]bool{
 	"voice":           true,
 	"instructions":    true,
 	"response_format": true,
+	"sample_rate":     true,
 	"speed":           true,
 }
 
@@ -166,6 +173,20 @@ type ChatRequest struct {
 	*schemas.ChatParameters
 }
 
+// AgentRequest is a chat request whose tool calls are executed by Bifrost
+type AgentRequest struct {
+	ChatRequest
+	MaxIterations int `json:"max_iterations,omitempty"` // Lower iteration limit than the configured one
+}
+
+// TokenCountRequest is a request counting the input tokens of messages or of a prompt for a model
+type TokenCountRequest struct {
+	Model    string                `json:"model"` // Model in provider/model format
+	Messages []schemas.ChatMessage `json:"messages,omitempty"`
+	Prompt   *string               `json:"prompt,omitempty"`
+	Tools    []schemas.ChatTool    `json:"tools,omitempty"`
+}
+
 // ResponsesRequestInput is a union of string and array of responses messages
 type ResponsesRequestInput struct {
 	ResponsesRequestInputStr   *string
@@ -232,6 +253,25 @@ func parseFallbacks(fallbackStrings []string) ([]schemas.Fallback, error) {
 	return fallbacks, nil
 }
 
+// setModelDeprecationHeaders warns clients of deprecated models with the Deprecation and Sunset headers and
+// the replacement of the model. Past the sunset date requests are remapped by bifrost, which is reported instead.
+func (h *CompletionHandler) setModelDeprecationHeaders(ctx *fasthttp.RequestCtx, provider schemas.ModelProvider, model string) {
+	deprecation := h.client.GetModelDeprecation(provider, model)
+	if deprecation == nil {
+		return
+	}
+	ctx.Response.Header.Set("x-bf-model-replacement", deprecation.Replacement)
+	if !time.Now().Before(deprecation.Sunset()) {
+		ctx.Response.Header.Set("x-bf-model-remapped-from", string(provider)+"/"+model)
+		return
+	}
+	ctx.Response.Header.Set("Deprecation", "true")
+	ctx.Response.Header.Set("Sunset", deprecation.Sunset().Format(http.TimeFormat))
+	if deprecation.Message != "" {
+		ctx.Response.Header.Set("x-bf-model-deprecation-message", deprecation.Message)
+	}
+}
+
 // extractExtraParams processes unknown fields from JSON data into ExtraParams
 func extractExtraParams(data []byte, knownFields map[string]bool) (map[string]interface{}, error) {
 	// Parse JSON to extract unknown fields
@@ -278,18 +318,35 @@ func (h *CompletionHandler) RegisterRoutes(r *router.Router, middlewares ...lib.
 	// Completion endpoints
 	r.POST("/v1/completions", lib.ChainMiddlewares(h.textCompletion, middlewares...))
 	r.POST("/v1/chat/completions", lib.ChainMiddlewares(h.chatCompletion, middlewares...))
+	r.POST("/v1/agent/completions", lib.ChainMiddlewares(h.agentCompletion, middlewares...))
+	r.POST("/v1/token-count", lib.ChainMiddlewares(h.countTokens, middlewares...))
+	r.POST("/v1/cost-estimate", lib.ChainMiddlewares(h.estimateCost, middlewares...))
 	r.POST("/v1/responses", lib.ChainMiddlewares(h.responses, middlewares...))
 	r.POST("/v1/embeddings", lib.ChainMiddlewares(h.embeddings, middlewares...))
 	r.POST("/v1/audio/speech", lib.ChainMiddlewares(h.speech, middlewares...))
+	r.GET("/v1/audio/voices", lib.ChainMiddlewares(h.listSpeechVoices, middlewares...))
 	r.POST("/v1/audio/transcriptions", lib.ChainMiddlewares(h.transcription, middlewares...))
 }
 
 // listModels handles GET /v1/models - Process list models requests
-// If provider is not specified, lists all models from all configured providers
+// If provider is not specified, lists all models from all configured providers.
+// With catalog=true or a capability filter, the models of all providers are merged into one entry per model with their
+// capabilities and pricing.
 func (h *CompletionHandler) listModels(ctx *fasthttp.RequestCtx) {
 	// Get provider from query parameters
 	provider := string(ctx.QueryArgs().Peek("provider"))
 
+	filter, err := parseModelCatalogFilter(ctx)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	catalog := string(ctx.QueryArgs().Peek("catalog")) == "true" || !filter.empty()
+	if catalog && provider != "" {
+		SendError(ctx, fasthttp.StatusBadRequest, "The model catalog lists the models of all providers, provider cannot be set")
+		return
+	}
+
 	// Convert context
 	bifrostCtx, cancel := lib.ConvertToBifrostContext(ctx, h.handlerStore.ShouldAllowDirectKeys())
 	defer cancel() // Ensure cleanup on function exit
@@ -319,7 +376,7 @@ func (h *CompletionHandler) listModels(ctx *fasthttp.RequestCtx) {
 	extraParams := map[string]interface{}{}
 	for k, v := range ctx.QueryArgs().All() {
 		s := string(k)
-		if s != "provider" && s != "page_size" && s != "page_token" {
+		if s != "provider" && s != "page_size" && s != "page_token" && s != "catalog" && s != "capabilities" && s != "min_context_window" {
 			extraParams[s] = string(v)
 		}
 	}
@@ -327,6 +384,12 @@ func (h *CompletionHandler) listModels(ctx *fasthttp.RequestCtx) {
 		bifrostListModelsReq.ExtraParams = extraParams
 	}
 
+	// The catalog is paginated once merged
+	if catalog {
+		bifrostListModelsReq.PageSize = 0
+		bifrostListModelsReq.PageToken = ""
+	}
+
 	// If provider is empty, list all models from all providers
 	if provider == "" {
 		resp, bifrostErr = h.client.ListAllModels(*bifrostCtx, bifrostListModelsReq)
@@ -364,6 +427,15 @@ func (h *CompletionHandler) listModels(ctx *fasthttp.RequestCtx) {
 		}
 	}
 
+	if catalog {
+		var registry schemas.ModelCapabilityRegistry
+		if h.config.PricingManager != nil {
+			registry = h.config.PricingManager
+		}
+		resp.Data = buildModelCatalog(resp.Data, registry, filter)
+		resp = resp.ApplyPagination(pageSize, pageToken)
+	}
+
 	// Send successful response
 	SendJSON(ctx, resp)
 }
@@ -381,6 +453,7 @@ func (h *CompletionHandler) textCompletion(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, fasthttp.StatusBadRequest, "model should be in provider/model format")
 		return
 	}
+	h.setModelDeprecationHeaders(ctx, provider, modelName)
 	// Parse fallbacks using helper function
 	fallbacks, err := parseFallbacks(req.Fallbacks)
 	if err != nil {
@@ -441,8 +514,19 @@ func (h *CompletionHandler) textCompletion(ctx *fasthttp.RequestCtx) {
 
 // chatCompletion handles POST /v1/chat/completions - Process chat completion requests
 func (h *CompletionHandler) chatCompletion(ctx *fasthttp.RequestCtx) {
+	body := ctx.PostBody()
+	var audioBlocks []schemas.ChatContentBlock
+	if bytes.HasPrefix(ctx.Request.Header.ContentType(), []byte("multipart/form-data")) {
+		var err error
+		body, audioBlocks, err = parseChatMultipartRequest(ctx)
+		if err != nil {
+			SendError(ctx, fasthttp.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
 	var req ChatRequest
-	if err := sonic.Unmarshal(ctx.PostBody(), &req); err != nil {
+	if err := sonic.Unmarshal(body, &req); err != nil {
 		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid request format: %v", err))
 		return
 	}
@@ -453,6 +537,7 @@ func (h *CompletionHandler) chatCompletion(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, fasthttp.StatusBadRequest, "model should be in provider/model format")
 		return
 	}
+	h.setModelDeprecationHeaders(ctx, provider, modelName)
 
 	// Parse fallbacks using helper function
 	fallbacks, err := parseFallbacks(req.Fallbacks)
@@ -466,12 +551,20 @@ func (h *CompletionHandler) chatCompletion(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	// Uploaded audio files are sent with the last user message
+	if len(audioBlocks) > 0 {
+		if err := appendAudioToLastUserMessage(req.Messages, audioBlocks); err != nil {
+			SendError(ctx, fasthttp.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
 	// Extract extra params
 	if req.ChatParameters == nil {
 		req.ChatParameters = &schemas.ChatParameters{}
 	}
 
-	extraParams, err := extractExtraParams(ctx.PostBody(), chatParamsKnownFields)
+	extraParams, err := extractExtraParams(body, chatParamsKnownFields)
 	if err != nil {
 		logger.Warn(fmt.Sprintf("Failed to extract extra params: %v", err))
 	} else {
@@ -511,6 +604,189 @@ func (h *CompletionHandler) chatCompletion(ctx *fasthttp.RequestCtx) {
 	SendJSON(ctx, resp)
 }
 
+// parseChatMultipartRequest parses a multipart/form-data chat completion request, whose "request" field holds the
+// JSON chat request and whose "audio" files are returned as input_audio blocks. The size of the request is bounded
+// by the max request body size of the server, like JSON requests with base64 audio.
+func parseChatMultipartRequest(ctx *fasthttp.RequestCtx) ([]byte, []schemas.ChatContentBlock, error) {
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to parse multipart form: %v", err)
+	}
+
+	requestValues := form.Value["request"]
+	if len(requestValues) == 0 || requestValues[0] == "" {
+		return nil, nil, fmt.Errorf("request field with the JSON chat request is required")
+	}
+
+	var audioBlocks []schemas.ChatContentBlock
+	for _, fileHeader := range form.File["audio"] {
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed to open uploaded file: %v", err)
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed to read uploaded file: %v", err)
+		}
+
+		audio := &schemas.ChatInputAudio{Data: base64.StdEncoding.EncodeToString(data)}
+		format := schemas.AudioFormatFromMediaType(fileHeader.Header.Get("Content-Type"))
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+			if format == "mpeg" || format == "mpga" {
+				format = "mp3"
+			}
+		}
+		// Without a format, it is detected from the audio header
+		if format != "" {
+			audio.Format = &format
+		}
+		audioBlocks = append(audioBlocks, schemas.ChatContentBlock{
+			Type:       schemas.ChatContentBlockTypeInputAudio,
+			InputAudio: audio,
+		})
+	}
+
+	return []byte(requestValues[0]), audioBlocks, nil
+}
+
+// appendAudioToLastUserMessage adds the audio blocks to the content of the last user message
+func appendAudioToLastUserMessage(messages []schemas.ChatMessage, audioBlocks []schemas.ChatContentBlock) error {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role != schemas.ChatMessageRoleUser {
+			continue
+		}
+		content := messages[i].Content
+		if content == nil {
+			content = &schemas.ChatMessageContent{}
+			messages[i].Content = content
+		}
+		if content.ContentStr != nil {
+			content.ContentBlocks = []schemas.ChatContentBlock{{Type: schemas.ChatContentBlockTypeText, Text: content.ContentStr}}
+			content.ContentStr = nil
+		}
+		content.ContentBlocks = append(content.ContentBlocks, audioBlocks...)
+		return nil
+	}
+	return fmt.Errorf("audio files require a user message to be sent with")
+}
+
+// agentCompletion handles POST /v1/agent/completions - Runs the tool-call loop of a chat completion request,
+// executing the registered HTTP tools and the MCP tools, and returns the final answer with the trace of the loop
+func (h *CompletionHandler) agentCompletion(ctx *fasthttp.RequestCtx) {
+	var req AgentRequest
+	if err := sonic.Unmarshal(ctx.PostBody(), &req); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid request format: %v", err))
+		return
+	}
+
+	provider, modelName := schemas.ParseModelString(req.Model, "")
+	if provider == "" || modelName == "" {
+		SendError(ctx, fasthttp.StatusBadRequest, "model should be in provider/model format")
+		return
+	}
+	h.setModelDeprecationHeaders(ctx, provider, modelName)
+
+	fallbacks, err := parseFallbacks(req.Fallbacks)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	if len(req.Messages) == 0 {
+		SendError(ctx, fasthttp.StatusBadRequest, "Messages is required for agent completion")
+		return
+	}
+	if req.Stream != nil && *req.Stream {
+		SendError(ctx, fasthttp.StatusBadRequest, "streaming is not supported for agent completion")
+		return
+	}
+	if req.MaxIterations < 0 {
+		SendError(ctx, fasthttp.StatusBadRequest, "max_iterations cannot be negative")
+		return
+	}
+
+	if req.ChatParameters == nil {
+		req.ChatParameters = &schemas.ChatParameters{}
+	}
+
+	extraParams, err := extractExtraParams(ctx.PostBody(), chatParamsKnownFields)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Failed to extract extra params: %v", err))
+	} else {
+		delete(extraParams, "max_iterations")
+		req.ChatParameters.ExtraParams = extraParams
+	}
+
+	bifrostAgentReq := &schemas.BifrostAgentRequest{
+		ChatRequest: &schemas.BifrostChatRequest{
+			Provider:  schemas.ModelProvider(provider),
+			Model:     modelName,
+			Input:     req.Messages,
+			Params:    req.ChatParameters,
+			Fallbacks: fallbacks,
+		},
+		MaxIterations: req.MaxIterations,
+	}
+
+	bifrostCtx, cancel := lib.ConvertToBifrostContext(ctx, h.handlerStore.ShouldAllowDirectKeys())
+	if bifrostCtx == nil {
+		SendError(ctx, fasthttp.StatusInternalServerError, "Failed to convert context")
+		return
+	}
+	defer cancel()
+
+	resp, bifrostErr := h.client.AgentRequest(*bifrostCtx, bifrostAgentReq)
+	if bifrostErr != nil {
+		SendBifrostError(ctx, bifrostErr)
+		return
+	}
+
+	SendJSON(ctx, resp)
+}
+
+// countTokens handles POST /v1/token-count - Counts the input tokens of messages or of a prompt with the tokenizer
+// of the model and compares them to its context window, without calling the provider
+func (h *CompletionHandler) countTokens(ctx *fasthttp.RequestCtx) {
+	var req TokenCountRequest
+	if err := sonic.Unmarshal(ctx.PostBody(), &req); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid request format: %v", err))
+		return
+	}
+
+	provider, modelName := schemas.ParseModelString(req.Model, "")
+	if provider == "" || modelName == "" {
+		SendError(ctx, fasthttp.StatusBadRequest, "model should be in provider/model format")
+		return
+	}
+	if len(req.Messages) == 0 && req.Prompt == nil {
+		SendError(ctx, fasthttp.StatusBadRequest, "messages or prompt is required for token count")
+		return
+	}
+
+	bifrostCtx, cancel := lib.ConvertToBifrostContext(ctx, h.handlerStore.ShouldAllowDirectKeys())
+	if bifrostCtx == nil {
+		SendError(ctx, fasthttp.StatusInternalServerError, "Failed to convert context")
+		return
+	}
+	defer cancel()
+
+	resp, bifrostErr := h.client.CountTokens(*bifrostCtx, &schemas.BifrostTokenCountRequest{
+		Provider: schemas.ModelProvider(provider),
+		Model:    modelName,
+		Messages: req.Messages,
+		Prompt:   req.Prompt,
+		Tools:    req.Tools,
+	})
+	if bifrostErr != nil {
+		SendBifrostError(ctx, bifrostErr)
+		return
+	}
+
+	SendJSON(ctx, resp)
+}
+
 // responses handles POST /v1/responses - Process responses requests
 func (h *CompletionHandler) responses(ctx *fasthttp.RequestCtx) {
 	var req ResponsesRequest
@@ -525,6 +801,7 @@ func (h *CompletionHandler) responses(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, fasthttp.StatusBadRequest, "model should be in provider/model format")
 		return
 	}
+	h.setModelDeprecationHeaders(ctx, provider, modelName)
 
 	// Parse fallbacks using helper function
 	fallbacks, err := parseFallbacks(req.Fallbacks)
@@ -607,6 +884,7 @@ func (h *CompletionHandler) embeddings(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, fasthttp.StatusBadRequest, "model should be in provider/model format")
 		return
 	}
+	h.setModelDeprecationHeaders(ctx, provider, modelName)
 
 	// Parse fallbacks using helper function
 	fallbacks, err := parseFallbacks(req.Fallbacks)
@@ -673,6 +951,7 @@ func (h *CompletionHandler) speech(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, fasthttp.StatusBadRequest, "model should be in provider/model format")
 		return
 	}
+	h.setModelDeprecationHeaders(ctx, provider, modelName)
 
 	// Parse fallbacks using helper function
 	fallbacks, err := parseFallbacks(req.Fallbacks)
@@ -752,12 +1031,26 @@ func (h *CompletionHandler) speech(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
-	ctx.Response.Header.Set("Content-Type", "audio/mpeg")
-	ctx.Response.Header.Set("Content-Disposition", "attachment; filename=speech.mp3")
+	format := resp.Format
+	if format == "" {
+		format = schemas.SpeechFormatMP3
+	}
+	ctx.Response.Header.Set("Content-Type", schemas.SpeechFormatContentType(format))
+	ctx.Response.Header.Set("Content-Disposition", "attachment; filename=speech."+format)
+	if resp.SampleRate > 0 {
+		ctx.Response.Header.Set("x-bf-sample-rate", strconv.Itoa(resp.SampleRate))
+	}
 	ctx.Response.Header.Set("Content-Length", strconv.Itoa(len(resp.Audio)))
 	ctx.Response.SetBody(resp.Audio)
 }
 
+// listSpeechVoices handles GET /v1/audio/voices - List the voices of the unified voice catalog
+func (h *CompletionHandler) listSpeechVoices(ctx *fasthttp.RequestCtx) {
+	SendJSON(ctx, map[string]interface{}{
+		"voices": schemas.SpeechVoices,
+	})
+}
+
 // transcription handles POST /v1/audio/transcriptions - Process transcription requests
 func (h *CompletionHandler) transcription(ctx *fasthttp.RequestCtx) {
 	// Parse multipart form
@@ -779,6 +1072,7 @@ func (h *CompletionHandler) transcription(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, fasthttp.StatusBadRequest, "model should be in provider/model format")
 		return
 	}
+	h.setModelDeprecationHeaders(ctx, provider, modelName)
 
 	// Extract file (required)
 	fileHeaders := form.File["file"]
@@ -888,7 +1182,7 @@ func (h *CompletionHandler) handleStreamingTextCompletion(ctx *fasthttp.RequestC
 		return h.client.TextCompletionStreamRequest(streamCtx, req)
 	}
 
-	h.handleStreamingResponse(ctx, getStream, cancel)
+	h.handleStreamingResponse(ctx, bifrostCtx, getStream, cancel)
 }
 
 // handleStreamingChatCompletion handles streaming chat completion requests using Server-Sent Events (SSE)
@@ -901,7 +1195,7 @@ func (h *CompletionHandler) handleStreamingChatCompletion(ctx *fasthttp.RequestC
 		return h.client.ChatCompletionStreamRequest(streamCtx, req)
 	}
 
-	h.handleStreamingResponse(ctx, getStream, cancel)
+	h.handleStreamingResponse(ctx, bifrostCtx, getStream, cancel)
 }
 
 // handleStreamingResponses handles streaming responses requests using Server-Sent Events (SSE)
@@ -914,7 +1208,7 @@ func (h *CompletionHandler) handleStreamingResponses(ctx *fasthttp.RequestCtx, r
 		return h.client.ResponsesStreamRequest(streamCtx, req)
 	}
 
-	h.handleStreamingResponse(ctx, getStream, cancel)
+	h.handleStreamingResponse(ctx, bifrostCtx, getStream, cancel)
 }
 
 // handleStreamingSpeech handles streaming speech requests using Server-Sent Events (SSE)
@@ -927,7 +1221,7 @@ func (h *CompletionHandler) handleStreamingSpeech(ctx *fasthttp.RequestCtx, req
 		return h.client.SpeechStreamRequest(streamCtx, req)
 	}
 
-	h.handleStreamingResponse(ctx, getStream, cancel)
+	h.handleStreamingResponse(ctx, bifrostCtx, getStream, cancel)
 }
 
 // handleStreamingTranscriptionRequest handles streaming transcription requests using Server-Sent Events (SSE)
@@ -940,20 +1234,43 @@ func (h *CompletionHandler) handleStreamingTranscriptionRequest(ctx *fasthttp.Re
 		return h.client.TranscriptionStreamRequest(streamCtx, req)
 	}
 
-	h.handleStreamingResponse(ctx, getStream, cancel)
+	h.handleStreamingResponse(ctx, bifrostCtx, getStream, cancel)
 }
 
 // handleStreamingResponse is a generic function to handle streaming responses using Server-Sent Events (SSE)
 // The cancel function is called ONLY when client disconnects are detected via write errors.
 // Bifrost handles cleanup internally for normal completion and errors, so we only cancel
 // upstream streams when write errors indicate the client has disconnected.
-func (h *CompletionHandler) handleStreamingResponse(ctx *fasthttp.RequestCtx, getStream func() (chan *schemas.BifrostStream, *schemas.BifrostError), cancel context.CancelFunc) {
+// When stream resumption is enabled, streams are buffered instead and clients reconnecting
+// with the Last-Event-ID header continue them rather than starting a new generation.
+func (h *CompletionHandler) handleStreamingResponse(ctx *fasthttp.RequestCtx, bifrostCtx *context.Context, getStream func() (chan *schemas.BifrostStream, *schemas.BifrostError), cancel context.CancelFunc) {
 	// Set SSE headers
 	ctx.SetContentType("text/event-stream")
 	ctx.Response.Header.Set("Cache-Control", "no-cache")
 	ctx.Response.Header.Set("Connection", "keep-alive")
 	ctx.Response.Header.Set("Access-Control-Allow-Origin", "*")
 
+	var heartbeatInterval, resumeBuffer time.Duration
+	if streaming := h.config.ClientConfig.Streaming; streaming != nil {
+		heartbeatInterval = time.Duration(streaming.HeartbeatIntervalSeconds) * time.Second
+		resumeBuffer = time.Duration(streaming.ResumeBufferSeconds) * time.Second
+	}
+
+	if resumeBuffer > 0 {
+		// Continue the buffered stream of a reconnecting client, unknown or expired streams are started again
+		if lastEventID := string(ctx.Request.Header.Peek("Last-Event-ID")); lastEventID != "" {
+			if buffered, next, ok := h.streams.resume(lastEventID); ok {
+				// Cancel stream context since no new generation is started
+				cancel()
+				ctx.Response.Header.Set(StreamIDHeader, buffered.id)
+				ctx.Response.SetBodyStreamWriter(h.handlerStore.GetDrain().TrackStream(func(w *bufio.Writer) {
+					writeResumableStream(w, buffered, next, heartbeatInterval)
+				}))
+				return
+			}
+		}
+	}
+
 	// Get the streaming channel
 	stream, bifrostErr := getStream()
 	if bifrostErr != nil {
@@ -963,78 +1280,76 @@ func (h *CompletionHandler) handleStreamingResponse(ctx *fasthttp.RequestCtx, ge
 		return
 	}
 
-	var includeEventType bool
+	if resumeBuffer > 0 {
+		buffered := h.streams.start(stream, resumeBuffer)
+		ctx.Response.Header.Set(StreamIDHeader, buffered.id)
+		ctx.Response.SetBodyStreamWriter(h.handlerStore.GetDrain().TrackStream(func(w *bufio.Writer) {
+			writeResumableStream(w, buffered, 0, heartbeatInterval)
+		}))
+		return
+	}
+
+	// Client disconnects cancel the upstream stream, and are accounted with what the stream generated so far
+	requestID, _ := (*bifrostCtx).Value(schemas.BifrostContextKeyRequestID).(string)
+	usage := lib.NewStreamUsage(string(ctx.Path()))
+	clientDisconnected := func() {
+		cancel()
+		h.handlerStore.GetStreamCancellations().RecordClientDisconnect(requestID, usage)
+	}
 
 	// Use streaming response writer
-	ctx.Response.SetBodyStreamWriter(func(w *bufio.Writer) {
+	ctx.Response.SetBodyStreamWriter(h.handlerStore.GetDrain().TrackStream(func(w *bufio.Writer) {
 		defer w.Flush()
 
-		// Process streaming responses
-		for chunk := range stream {
-			if chunk == nil {
-				continue
-			}
-
-			includeEventType = false
-			if chunk.BifrostResponsesStreamResponse != nil ||
-				(chunk.BifrostError != nil && chunk.BifrostError.ExtraFields.RequestType == schemas.ResponsesStreamRequest) {
-				includeEventType = true
-			}
+		heartbeat := newSSEHeartbeat(heartbeatInterval)
+		defer heartbeat.stop()
 
-			// Convert response to JSON
-			chunkJSON, err := sonic.Marshal(chunk)
-			if err != nil {
-				logger.Warn(fmt.Sprintf("Failed to marshal streaming response: %v", err))
-				continue
-			}
+		var lastEventType string
 
-			// Send as SSE data
-			if includeEventType {
-				// For responses API, use OpenAI-compatible format with event line
-				eventType := ""
-				if chunk.BifrostResponsesStreamResponse != nil {
-					eventType = string(chunk.BifrostResponsesStreamResponse.Type)
-				} else if chunk.BifrostError != nil {
-					eventType = string(schemas.ResponsesStreamResponseTypeError)
-				}
-
-				if eventType != "" {
-					if _, err := fmt.Fprintf(w, "event: %s\n", eventType); err != nil {
-						cancel() // Client disconnected (write error), cancel upstream stream
-						return
+		// Process streaming responses
+		for {
+			select {
+			case chunk, ok := <-stream:
+				if !ok {
+					// Note: OpenAI responses API doesn't use [DONE] marker, it ends when the stream closes
+					if lastEventType == "" {
+						// Send the [DONE] marker to indicate the end of the stream (only for non-responses APIs)
+						if err := writeStreamEvent(w, "", doneStreamEvent); err != nil {
+							logger.Warn(fmt.Sprintf("Failed to write SSE [DONE] marker: %v", err))
+							cancel() // The generation is complete, so the disconnect wasted nothing
+						}
 					}
+					// Stream completed normally, Bifrost handles cleanup internally
+					return
 				}
 
-				if _, err := fmt.Fprintf(w, "data: %s\n\n", chunkJSON); err != nil {
-					cancel() // Client disconnected (write error), cancel upstream stream
+				// Send as SSE data and flush immediately to send the chunk
+				eventType, ok, err := writeStreamChunk(w, chunk)
+				if !ok {
+					continue
+				}
+				lastEventType = eventType
+				usage.Add(chunk)
+				// The handler is the last reader of the chunk
+				schemas.ReleaseBifrostStream(chunk)
+				if err != nil {
+					clientDisconnected() // Client disconnected (write error), cancel upstream stream
 					return
 				}
-			} else {
-				// For other APIs, use standard format
-				if _, err := fmt.Fprintf(w, "data: %s\n\n", chunkJSON); err != nil {
-					cancel() // Client disconnected (write error), cancel upstream stream
+				if err := w.Flush(); err != nil {
+					clientDisconnected() // Client disconnected (write error), cancel upstream stream
 					return
 				}
-			}
-
-			// Flush immediately to send the chunk
-			if err := w.Flush(); err != nil {
-				cancel() // Client disconnected (write error), cancel upstream stream
-				return
-			}
-		}
-
-		if !includeEventType {
-			// Send the [DONE] marker to indicate the end of the stream (only for non-responses APIs)
-			if _, err := fmt.Fprint(w, "data: [DONE]\n\n"); err != nil {
-				logger.Warn(fmt.Sprintf("Failed to write SSE [DONE] marker: %v", err))
-				cancel() // Client disconnected (write error), cancel upstream stream
-				return
+				heartbeat.reset()
+			case <-heartbeat.C():
+				if err := writeKeepAlive(w); err != nil {
+					clientDisconnected() // Client disconnected (write error), cancel upstream stream
+					return
+				}
+				heartbeat.reset()
 			}
 		}
-		// Note: OpenAI responses API doesn't use [DONE] marker, it ends when the stream closes
-		// Stream completed normally, Bifrost handles cleanup internally
-	})
+	}))
 }
 
 // validateAudioFile checks if the file size and format are valid
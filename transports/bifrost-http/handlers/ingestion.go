@@ -0,0 +1,148 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the batch embedding ingestion handlers.
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/bytedance/sonic"
+	"github.com/fasthttp/router"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// maxIngestionLineSize is the maximum size of a single NDJSON record
+const maxIngestionLineSize = 1024 * 1024
+
+// IngestionHandler manages batch embedding ingestion jobs
+type IngestionHandler struct {
+	manager *lib.IngestionManager
+}
+
+// NewIngestionHandler creates a new ingestion handler instance
+func NewIngestionHandler(manager *lib.IngestionManager) *IngestionHandler {
+	return &IngestionHandler{
+		manager: manager,
+	}
+}
+
+// RegisterRoutes registers the ingestion routes
+func (h *IngestionHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
+	r.POST("/api/ingestion/jobs", lib.ChainMiddlewares(h.createJob, middlewares...))
+	r.GET("/api/ingestion/jobs", lib.ChainMiddlewares(h.listJobs, middlewares...))
+	r.GET("/api/ingestion/jobs/{job_id}", lib.ChainMiddlewares(h.getJob, middlewares...))
+	r.POST("/api/ingestion/jobs/{job_id}/cancel", lib.ChainMiddlewares(h.cancelJob, middlewares...))
+}
+
+// createJob handles POST /api/ingestion/jobs?alias=<alias>&collection=<collection> - Start an ingestion job.
+// The body is newline delimited JSON, one {"id", "text", "metadata"} record per line, decoded as it is read.
+func (h *IngestionHandler) createJob(ctx *fasthttp.RequestCtx) {
+	alias := string(ctx.QueryArgs().Peek("alias"))
+	collection := string(ctx.QueryArgs().Peek("collection"))
+	if alias == "" || collection == "" {
+		SendError(ctx, fasthttp.StatusBadRequest, "alias and collection query parameters are required")
+		return
+	}
+	// The collection is checked before the body is read, jobs are refused without decoding their records
+	if err := h.manager.CheckCollection(collection); err != nil {
+		SendError(ctx, fasthttp.StatusForbidden, err.Error())
+		return
+	}
+
+	body := ctx.RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(ctx.PostBody())
+	}
+	records, err := decodeIngestionRecords(body)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	job, err := h.manager.StartJob(alias, collection, records)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Failed to start ingestion job: %v", err))
+		return
+	}
+	SendJSONWithStatus(ctx, map[string]any{
+		"message": "Ingestion job started",
+		"job":     job,
+	}, fasthttp.StatusAccepted)
+}
+
+// decodeIngestionRecords decodes the NDJSON records of the reader line by line
+func decodeIngestionRecords(reader io.Reader) ([]lib.IngestionRecord, error) {
+	var records []lib.IngestionRecord
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxIngestionLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var record lib.IngestionRecord
+		if err := sonic.Unmarshal(data, &record); err != nil {
+			return nil, fmt.Errorf("invalid record on line %d: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("invalid request body: %v", err)
+	}
+	return records, nil
+}
+
+// listJobs handles GET /api/ingestion/jobs - List recent ingestion jobs with their progress
+func (h *IngestionHandler) listJobs(ctx *fasthttp.RequestCtx) {
+	jobs := h.manager.ListJobs()
+	SendJSON(ctx, map[string]any{
+		"jobs":  jobs,
+		"count": len(jobs),
+	})
+}
+
+// getJob handles GET /api/ingestion/jobs/{job_id} - Get the progress of an ingestion job
+func (h *IngestionHandler) getJob(ctx *fasthttp.RequestCtx) {
+	jobID := ctx.UserValue("job_id").(string)
+	job, err := h.manager.GetJob(jobID)
+	if err != nil {
+		h.sendJobError(ctx, err)
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"job": job,
+	})
+}
+
+// cancelJob handles POST /api/ingestion/jobs/{job_id}/cancel - Cancel an ingestion job, stored records are kept
+func (h *IngestionHandler) cancelJob(ctx *fasthttp.RequestCtx) {
+	jobID := ctx.UserValue("job_id").(string)
+	job, err := h.manager.CancelJob(jobID)
+	if err != nil {
+		h.sendJobError(ctx, err)
+		return
+	}
+	message := "Ingestion job cancelled"
+	if job.Status != lib.IngestionJobRunning && job.Status != lib.IngestionJobPending {
+		message = fmt.Sprintf("Ingestion job is %s", job.Status)
+	}
+	SendJSON(ctx, map[string]any{
+		"message": message,
+		"job":     job,
+	})
+}
+
+// sendJobError sends the error of a job lookup
+func (h *IngestionHandler) sendJobError(ctx *fasthttp.RequestCtx, err error) {
+	if errors.Is(err, lib.ErrNotFound) {
+		SendError(ctx, fasthttp.StatusNotFound, "Ingestion job not found")
+		return
+	}
+	SendError(ctx, fasthttp.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maximhq/bifrost/framework/vectorstore"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// unusedEmbeddingClient and unusedVectorStore let ingestion managers be created for requests refused before any job
+// starts
+type unusedEmbeddingClient struct {
+	lib.EmbeddingClient
+}
+
+type unusedVectorStore struct {
+	vectorstore.VectorStore
+}
+
+func TestDecodeIngestionRecords(t *testing.T) {
+	records, err := decodeIngestionRecords(strings.NewReader("{\"id\": \"a\", \"text\": \"first\"}\n\n  {\"text\": \"second\", \"metadata\": {\"page\": 2}}  \n"))
+	if err != nil {
+		t.Fatalf("failed to decode records: %v", err)
+	}
+	if len(records) != 2 || records[0].ID != "a" || records[1].Text != "second" || records[1].Metadata["page"] == nil {
+		t.Errorf("expected the two records, got %+v", records)
+	}
+
+	if _, err := decodeIngestionRecords(strings.NewReader("{\"text\": \"first\"}\n{\"text\":\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected the invalid line to be reported, got %v", err)
+	}
+	if _, err := decodeIngestionRecords(strings.NewReader("{\"text\": \"" + strings.Repeat("a", maxIngestionLineSize) + "\"}\n")); err == nil {
+		t.Error("expected records over the line size limit to be rejected")
+	}
+}
+
+// TestCreateIngestionJob_CollectionPrefix tests that jobs writing outside of the collection prefix are refused
+func TestCreateIngestionJob_CollectionPrefix(t *testing.T) {
+	manager, err := lib.NewIngestionManager(&lib.IngestionConfig{
+		Enabled:          true,
+		EmbeddingAliases: map[string]lib.EmbeddingAliasConfig{"docs": {Model: "openai/text-embedding-3-small", Dimension: 4}},
+		CollectionPrefix: "Kb",
+	}, unusedEmbeddingClient{}, unusedVectorStore{})
+	if err != nil {
+		t.Fatalf("failed to create ingestion manager: %v", err)
+	}
+	defer manager.Stop()
+	handler := NewIngestionHandler(manager)
+
+	tests := map[string]struct {
+		collection string
+		expected   int
+	}{
+		"semantic cache collection": {collection: "BifrostSemanticCachePlugin", expected: fasthttp.StatusForbidden},
+		"default prefix":            {collection: "IngestionDocs", expected: fasthttp.StatusForbidden},
+		"prefix only":               {collection: "Kb", expected: fasthttp.StatusForbidden},
+		"invalid characters":        {collection: "Kb/docs", expected: fasthttp.StatusForbidden},
+		"prefixed collection":       {collection: "KbDocs", expected: fasthttp.StatusBadRequest}, // refused for its invalid body only
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.QueryArgs().Set("alias", "docs")
+			ctx.QueryArgs().Set("collection", test.collection)
+			ctx.Request.SetBodyString("not json\n")
+			handler.createJob(ctx)
+			if ctx.Response.StatusCode() != test.expected {
+				t.Errorf("expected status %d, got %d: %s", test.expected, ctx.Response.StatusCode(), ctx.Response.Body())
+			}
+		})
+	}
+}
@@ -0,0 +1,372 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the bulk import of provider keys from JSON or CSV.
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// KeyImportConflictPolicy is applied to the imported keys named like a key of their provider
+type KeyImportConflictPolicy string
+
+const (
+	KeyImportSkip      KeyImportConflictPolicy = "skip"      // Keep the existing key
+	KeyImportOverwrite KeyImportConflictPolicy = "overwrite" // Replace the existing key, keeping its ID
+)
+
+// KeyImportAction is what the import did, or would do in a dry run, with a key
+type KeyImportAction string
+
+const (
+	KeyImportCreated KeyImportAction = "created"
+	KeyImportUpdated KeyImportAction = "updated"
+	KeyImportSkipped KeyImportAction = "skipped"
+	KeyImportFailed  KeyImportAction = "failed"
+)
+
+// ImportedKey is a key of a bulk import and the provider it belongs to
+type ImportedKey struct {
+	Provider schemas.ModelProvider `json:"provider"`
+	schemas.Key
+}
+
+// KeyImportResult is the outcome of the import of a key
+type KeyImportResult struct {
+	Provider schemas.ModelProvider `json:"provider"`
+	Name     string                `json:"name"`
+	Action   KeyImportAction       `json:"action"`
+	Error    string                `json:"error,omitempty"`
+}
+
+// KeyImportReport lists what the import changed, in the order of the imported keys
+type KeyImportReport struct {
+	DryRun  bool              `json:"dry_run"`
+	Created int               `json:"created"`
+	Updated int               `json:"updated"`
+	Skipped int               `json:"skipped"`
+	Failed  int               `json:"failed"`
+	Keys    []KeyImportResult `json:"keys"`
+}
+
+// keyImportCSVColumns are the columns accepted in CSV imports, models are separated by semicolons
+var keyImportCSVColumns = []string{"provider", "name", "value", "models", "weight"}
+
+// importKeys handles POST /api/keys/import - Import keys into their providers from JSON or CSV
+// Query parameters:
+//   - dry_run: Report what the import would change without saving anything
+//   - on_conflict: skip (default) or overwrite the keys named like an existing key of their provider
+//   - validate: Validate the created and updated keys with a live call, keys rejected by their provider fail
+//
+// JSON bodies are {"keys": [{"provider": "openai", "name": ..., "value": ...}]}, text/csv bodies have a header row
+// with the provider, name, value, models and weight columns. Keys with provider-specific configs need JSON.
+func (h *ProviderHandler) importKeys(ctx *fasthttp.RequestCtx) {
+	dryRun := ctx.QueryArgs().GetBool("dry_run")
+	validate := ctx.QueryArgs().GetBool("validate")
+	policy := KeyImportConflictPolicy(ctx.QueryArgs().Peek("on_conflict"))
+	if policy == "" {
+		policy = KeyImportSkip
+	}
+	if policy != KeyImportSkip && policy != KeyImportOverwrite {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid on_conflict %q, expected skip or overwrite", policy))
+		return
+	}
+
+	var keys []ImportedKey
+	var err error
+	if strings.HasPrefix(string(ctx.Request.Header.ContentType()), "text/csv") {
+		keys, err = parseKeyImportCSV(ctx.PostBody())
+	} else {
+		var payload struct {
+			Keys []ImportedKey `json:"keys"`
+		}
+		err = json.Unmarshal(ctx.PostBody(), &payload)
+		keys = payload.Keys
+	}
+	if err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid import: %v", err))
+		return
+	}
+	if len(keys) == 0 {
+		SendError(ctx, fasthttp.StatusBadRequest, "No keys to import")
+		return
+	}
+
+	report := KeyImportReport{DryRun: dryRun, Keys: make([]KeyImportResult, len(keys))}
+	providers := make(map[schemas.ModelProvider][]int)
+	var providerOrder []schemas.ModelProvider
+	for i, key := range keys {
+		report.Keys[i] = KeyImportResult{Provider: key.Provider, Name: key.Name}
+		if _, ok := providers[key.Provider]; !ok {
+			providerOrder = append(providerOrder, key.Provider)
+		}
+		providers[key.Provider] = append(providers[key.Provider], i)
+	}
+	keyProviders, err := h.keyNameProviders(ctx)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to get keys: %v", err))
+		return
+	}
+
+	for _, provider := range providerOrder {
+		h.importProviderKeys(ctx, provider, keys, providers[provider], keyProviders, policy, validate, dryRun, report.Keys)
+	}
+
+	for _, result := range report.Keys {
+		switch result.Action {
+		case KeyImportCreated:
+			report.Created++
+		case KeyImportUpdated:
+			report.Updated++
+		case KeyImportSkipped:
+			report.Skipped++
+		case KeyImportFailed:
+			report.Failed++
+		}
+	}
+	SendJSON(ctx, report)
+}
+
+// importProviderKeys imports the keys at the indices into their provider and records the outcome in results.
+// The keys of a provider are saved together, the keys failing are left out.
+func (h *ProviderHandler) importProviderKeys(
+	ctx *fasthttp.RequestCtx,
+	provider schemas.ModelProvider,
+	keys []ImportedKey,
+	indices []int,
+	keyProviders map[string]schemas.ModelProvider,
+	policy KeyImportConflictPolicy,
+	validate bool,
+	dryRun bool,
+	results []KeyImportResult,
+) {
+	fail := func(i int, message string) {
+		results[i].Action = KeyImportFailed
+		results[i].Error = message
+	}
+
+	oldConfig, err := h.store.GetProviderConfigRaw(provider)
+	if err != nil || !canAccessNamespace(ctx, oldConfig.Namespace) {
+		for _, i := range indices {
+			fail(i, fmt.Sprintf("provider %s is not configured, add it before importing its keys", provider))
+		}
+		return
+	}
+
+	config := *oldConfig
+	config.Keys = slices.Clone(oldConfig.Keys)
+	indexOf := func(name string) int {
+		return slices.IndexFunc(config.Keys, func(k schemas.Key) bool { return k.Name == name })
+	}
+	var written []int // Indices of the imported keys created or updated
+	var keysToUpdate []schemas.Key
+	seen := make(map[string]bool)
+	for _, i := range indices {
+		key := keys[i].Key
+		key.LastValidation = nil
+		if key.Weight == 0 {
+			key.Weight = 1.0
+		}
+		switch {
+		case key.Name == "":
+			fail(i, "missing key name")
+			continue
+		case seen[key.Name]:
+			fail(i, "duplicate key name in the import")
+			continue
+		}
+		seen[key.Name] = true
+		if owner, ok := keyProviders[key.Name]; ok && owner != provider {
+			if owner == "" {
+				fail(i, "key name already used by another key")
+			} else {
+				fail(i, fmt.Sprintf("key name already used by provider %s", owner))
+			}
+			continue
+		}
+		if err := lib.ValidateKeys([]schemas.Key{key}); err != nil {
+			fail(i, err.Error())
+			continue
+		}
+		// Imported keys replace the existing keys, their file references are never kept
+		if err := lib.ValidateSecretReferences(configstore.ProviderConfig{Keys: []schemas.Key{key}}, nil); err != nil {
+			fail(i, err.Error())
+			continue
+		}
+		if err := lib.ValidateEndpoints(ctx, h.store.ClientConfig.EndpointPolicy, nil, []schemas.Key{key}); err != nil {
+			fail(i, fmt.Sprintf("endpoint not allowed: %v", err))
+			continue
+		}
+
+		existing := indexOf(key.Name)
+		switch {
+		case existing < 0:
+			key.ID = ""
+			results[i].Action = KeyImportCreated
+			config.Keys = append(config.Keys, key)
+		case policy == KeyImportSkip:
+			results[i].Action = KeyImportSkipped
+			continue
+		default:
+			key.ID = config.Keys[existing].ID
+			results[i].Action = KeyImportUpdated
+			config.Keys[existing] = key
+			keysToUpdate = append(keysToUpdate, key)
+		}
+		written = append(written, i)
+	}
+
+	if validate && len(written) > 0 {
+		// Only the created and updated keys are validated
+		candidates := config
+		candidates.Keys = make([]schemas.Key, 0, len(written))
+		for _, i := range written {
+			candidates.Keys = append(candidates.Keys, config.Keys[indexOf(keys[i].Name)])
+		}
+		h.validateKeys(ctx, provider, candidates)
+		var rejected []string
+		for j, i := range written {
+			validation := candidates.Keys[j].LastValidation
+			if validation.Status == schemas.KeyValidationInvalid {
+				fail(i, validation.Error)
+				rejected = append(rejected, keys[i].Name)
+				continue
+			}
+			config.Keys[indexOf(keys[i].Name)].LastValidation = validation
+		}
+		if len(rejected) > 0 {
+			config.Keys = restoreRejectedKeys(config.Keys, oldConfig.Keys, rejected)
+			keysToUpdate = slices.DeleteFunc(keysToUpdate, func(k schemas.Key) bool { return slices.Contains(rejected, k.Name) })
+			written = slices.DeleteFunc(written, func(i int) bool { return results[i].Action == KeyImportFailed })
+		}
+	}
+	if dryRun || len(written) == 0 {
+		return
+	}
+
+	h.store.CleanupEnvKeysForUpdatedKeys(provider, keysToUpdate, oldConfig.Keys, config.Keys)
+	if err := h.store.UpdateProviderConfig(ctx, provider, config); err != nil {
+		logger.Warn(fmt.Sprintf("Failed to import keys of provider %s: %v", provider, err))
+		for _, i := range written {
+			fail(i, fmt.Sprintf("failed to save the keys of provider %s: %v", provider, err))
+		}
+		return
+	}
+	go func() {
+		if err := h.modelsManager.RefetchModelsForProvider(context.Background(), provider); err != nil {
+			logger.Warn(fmt.Sprintf("Failed to refetch models for provider %s: %v", provider, err))
+		}
+	}()
+}
+
+// keyNameProviders maps the names of the saved keys to their provider, key names are unique across providers.
+// Keys of the providers of the namespaces the request cannot access map to an empty provider, so that their names
+// still conflict without revealing their provider.
+func (h *ProviderHandler) keyNameProviders(ctx *fasthttp.RequestCtx) (map[string]schemas.ModelProvider, error) {
+	providers, err := h.store.GetAllProviders()
+	if err != nil {
+		return nil, err
+	}
+	keyProviders := make(map[string]schemas.ModelProvider)
+	for _, provider := range providers {
+		config, err := h.store.GetProviderConfigRaw(provider)
+		if err != nil {
+			if errors.Is(err, lib.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		owner := provider
+		if !canAccessNamespace(ctx, config.Namespace) {
+			owner = ""
+		}
+		for _, key := range config.Keys {
+			keyProviders[key.Name] = owner
+		}
+	}
+	return keyProviders, nil
+}
+
+// restoreRejectedKeys puts back the saved version of the rejected keys, and drops the rejected keys not saved before
+func restoreRejectedKeys(keys []schemas.Key, savedKeys []schemas.Key, rejected []string) []schemas.Key {
+	restored := make([]schemas.Key, 0, len(keys))
+	for _, key := range keys {
+		if !slices.Contains(rejected, key.Name) {
+			restored = append(restored, key)
+			continue
+		}
+		if saved := slices.IndexFunc(savedKeys, func(k schemas.Key) bool { return k.Name == key.Name }); saved >= 0 {
+			restored = append(restored, savedKeys[saved])
+		}
+	}
+	return restored
+}
+
+// parseKeyImportCSV parses the keys of a CSV import
+func parseKeyImportCSV(body []byte) ([]ImportedKey, error) {
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the header row: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if !slices.Contains(keyImportCSVColumns, column) {
+			return nil, fmt.Errorf("unknown column %q, expected %s", column, strings.Join(keyImportCSVColumns, ", "))
+		}
+		columns[column] = i
+	}
+	for _, column := range []string{"provider", "name", "value"} {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("missing %s column", column)
+		}
+	}
+
+	var keys []ImportedKey
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(column string) string {
+			if i, ok := columns[column]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		key := ImportedKey{
+			Provider: schemas.ModelProvider(field("provider")),
+			Key:      schemas.Key{Name: field("name"), Value: field("value")},
+		}
+		for _, model := range strings.Split(field("models"), ";") {
+			if model = strings.TrimSpace(model); model != "" {
+				key.Models = append(key.Models, model)
+			}
+		}
+		if weight := field("weight"); weight != "" {
+			if key.Weight, err = strconv.ParseFloat(weight, 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid weight %q", line, weight)
+			}
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+)
+
+func TestParseKeyImportCSV(t *testing.T) {
+	body := "provider,name,value,models,weight\n" +
+		"openai,team-a,env.TEAM_A_OPENAI_KEY,gpt-4o; gpt-4o-mini,0.5\n" +
+		"anthropic,team-a-claude,sk-ant-123,,\n"
+
+	keys, err := parseKeyImportCSV([]byte(body))
+	if err != nil {
+		t.Fatalf("failed to parse the import: %v", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(keys))
+	}
+	if keys[0].Provider != schemas.OpenAI || keys[0].Name != "team-a" || keys[0].Value != "env.TEAM_A_OPENAI_KEY" || keys[0].Weight != 0.5 {
+		t.Errorf("unexpected first key %+v", keys[0])
+	}
+	if !slices.Equal(keys[0].Models, []string{"gpt-4o", "gpt-4o-mini"}) {
+		t.Errorf("expected the models separated by semicolons, got %v", keys[0].Models)
+	}
+	if keys[1].Models != nil || keys[1].Weight != 0 {
+		t.Errorf("expected empty models and weight to be left unset, got %+v", keys[1])
+	}
+
+	if _, err := parseKeyImportCSV([]byte("provider,name,secret\nopenai,a,b\n")); err == nil {
+		t.Error("expected unknown columns to be rejected")
+	}
+	if _, err := parseKeyImportCSV([]byte("provider,name,value,weight\nopenai,a,b,heavy\n")); err == nil {
+		t.Error("expected invalid weights to be rejected")
+	}
+}
+
+func TestRestoreRejectedKeys(t *testing.T) {
+	saved := []schemas.Key{{ID: "1", Name: "kept", Value: "old"}, {ID: "2", Name: "overwritten", Value: "old"}}
+	imported := []schemas.Key{
+		{ID: "1", Name: "kept", Value: "old"},
+		{ID: "2", Name: "overwritten", Value: "new"},
+		{Name: "created", Value: "new"},
+	}
+
+	restored := restoreRejectedKeys(imported, saved, []string{"overwritten", "created"})
+	if len(restored) != 2 || restored[1].Value != "old" {
+		t.Errorf("expected the overwritten key to be restored and the created key dropped, got %+v", restored)
+	}
+}
+
+// TestKeyNameProviders tests that the names of the keys of other namespaces conflict without naming their provider
+func TestKeyNameProviders(t *testing.T) {
+	handler := NewProviderHandler(staticModelsManager{}, &lib.Config{Providers: map[schemas.ModelProvider]configstore.ProviderConfig{
+		"openai":        {Keys: []schemas.Key{{Name: "default"}}},
+		"acme.openai":   {Namespace: "acme", Keys: []schemas.Key{{Name: "acme"}}},
+		"globex.openai": {Namespace: "globex", Keys: []schemas.Key{{Name: "globex"}}},
+	}}, nil)
+
+	tests := map[string]struct {
+		requestNamespace string
+		expected         map[string]schemas.ModelProvider
+	}{
+		"root admin":      {expected: map[string]schemas.ModelProvider{"default": "openai", "acme": "acme.openai", "globex": "globex.openai"}},
+		"namespace admin": {requestNamespace: "acme", expected: map[string]schemas.ModelProvider{"default": "", "acme": "acme.openai", "globex": ""}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			keyProviders, err := handler.keyNameProviders(newNamespaceRequestCtx(test.requestNamespace))
+			if err != nil {
+				t.Fatalf("failed to list the key names: %v", err)
+			}
+			if !maps.Equal(keyProviders, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, keyProviders)
+			}
+		})
+	}
+}
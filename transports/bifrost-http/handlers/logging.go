@@ -3,8 +3,11 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 	"time"
@@ -43,6 +46,7 @@ func (h *LoggingHandler) RegisterRoutes(r *router.Router, middlewares ...lib.Bif
 	// Log retrieval with filtering, search, and pagination
 	r.GET("/api/logs", lib.ChainMiddlewares(h.getLogs, middlewares...))
 	r.GET("/api/logs/stats", lib.ChainMiddlewares(h.getLogsStats, middlewares...))
+	r.GET("/api/logs/usage/keys", lib.ChainMiddlewares(h.getKeyModelUsage, middlewares...))
 	r.GET("/api/logs/dropped", lib.ChainMiddlewares(h.getDroppedRequests, middlewares...))
 	r.GET("/api/logs/filterdata", lib.ChainMiddlewares(h.getAvailableFilterData, middlewares...))
 	r.DELETE("/api/logs", lib.ChainMiddlewares(h.deleteLogs, middlewares...))
@@ -73,6 +77,24 @@ func (h *LoggingHandler) getLogs(ctx *fasthttp.RequestCtx) {
 	if virtualKeyIDs := string(ctx.QueryArgs().Peek("virtual_key_ids")); virtualKeyIDs != "" {
 		filters.VirtualKeyIDs = parseCommaSeparated(virtualKeyIDs)
 	}
+	if endUsers := string(ctx.QueryArgs().Peek("end_users")); endUsers != "" {
+		filters.EndUsers = parseCommaSeparated(endUsers)
+	}
+	if tags := string(ctx.QueryArgs().Peek("tags")); tags != "" {
+		filters.Tags = parseTagFilters(tags)
+	}
+	if namespaces := string(ctx.QueryArgs().Peek("namespaces")); namespaces != "" {
+		filters.Namespaces = parseCommaSeparated(namespaces)
+	}
+	// Namespace admins only see the logs of their own namespace
+	if namespace, scoped := getRequestNamespace(ctx); scoped {
+		filters.Namespaces = []string{namespace}
+	}
+	if isTestKey := string(ctx.QueryArgs().Peek("is_test_key")); isTestKey != "" {
+		if b, err := strconv.ParseBool(isTestKey); err == nil {
+			filters.IsTestKey = &b
+		}
+	}
 	if startTime := string(ctx.QueryArgs().Peek("start_time")); startTime != "" {
 		if t, err := time.Parse(time.RFC3339, startTime); err == nil {
 			filters.StartTime = &t
@@ -191,6 +213,21 @@ func (h *LoggingHandler) getLogs(ctx *fasthttp.RequestCtx) {
 	redactedKeys := h.redactedKeysManager.GetAllRedactedKeys(ctx, toSlice(selectedKeyIDs))
 	redactedVirtualKeys := h.redactedKeysManager.GetAllRedactedVirtualKeys(ctx, toSlice(virtualKeyIDs))
 
+	// Encrypted payloads are only readable by the admin of the namespace they belong to
+	_, scoped := getRequestNamespace(ctx)
+	for i := range result.Logs {
+		if !result.Logs[i].PayloadEncrypted {
+			continue
+		}
+		if !scoped {
+			result.Logs[i].RedactPayload()
+			continue
+		}
+		if err := result.Logs[i].DecryptPayload(ctx); err != nil {
+			logger.Debug("failed to decrypt payload of log %s: %v", result.Logs[i].ID, err)
+		}
+	}
+
 	// Add selected key and virtual key to the result
 	for i, log := range result.Logs {
 		if log.SelectedKeyID != "" && log.SelectedKeyName != "" {
@@ -228,6 +265,24 @@ func (h *LoggingHandler) getLogsStats(ctx *fasthttp.RequestCtx) {
 	if virtualKeyIDs := string(ctx.QueryArgs().Peek("virtual_key_ids")); virtualKeyIDs != "" {
 		filters.VirtualKeyIDs = parseCommaSeparated(virtualKeyIDs)
 	}
+	if endUsers := string(ctx.QueryArgs().Peek("end_users")); endUsers != "" {
+		filters.EndUsers = parseCommaSeparated(endUsers)
+	}
+	if tags := string(ctx.QueryArgs().Peek("tags")); tags != "" {
+		filters.Tags = parseTagFilters(tags)
+	}
+	if namespaces := string(ctx.QueryArgs().Peek("namespaces")); namespaces != "" {
+		filters.Namespaces = parseCommaSeparated(namespaces)
+	}
+	// Namespace admins only see the logs of their own namespace
+	if namespace, scoped := getRequestNamespace(ctx); scoped {
+		filters.Namespaces = []string{namespace}
+	}
+	if isTestKey := string(ctx.QueryArgs().Peek("is_test_key")); isTestKey != "" {
+		if b, err := strconv.ParseBool(isTestKey); err == nil {
+			filters.IsTestKey = &b
+		}
+	}
 	if startTime := string(ctx.QueryArgs().Peek("start_time")); startTime != "" {
 		if t, err := time.Parse(time.RFC3339, startTime); err == nil {
 			filters.StartTime = &t
@@ -282,6 +337,99 @@ func (h *LoggingHandler) getLogsStats(ctx *fasthttp.RequestCtx) {
 	SendJSON(ctx, stats)
 }
 
+// getKeyModelUsage handles GET /api/logs/usage/keys - Get the usage of each provider key by model over a time range,
+// as JSON or as CSV with format=csv
+func (h *LoggingHandler) getKeyModelUsage(ctx *fasthttp.RequestCtx) {
+	filters := &logstore.SearchFilters{}
+
+	if providers := string(ctx.QueryArgs().Peek("providers")); providers != "" {
+		filters.Providers = parseCommaSeparated(providers)
+	}
+	if models := string(ctx.QueryArgs().Peek("models")); models != "" {
+		filters.Models = parseCommaSeparated(models)
+	}
+	if selectedKeyIDs := string(ctx.QueryArgs().Peek("selected_key_ids")); selectedKeyIDs != "" {
+		filters.SelectedKeyIDs = parseCommaSeparated(selectedKeyIDs)
+	}
+	if namespaces := string(ctx.QueryArgs().Peek("namespaces")); namespaces != "" {
+		filters.Namespaces = parseCommaSeparated(namespaces)
+	}
+	// Namespace admins only see the usage of their own namespace
+	if namespace, scoped := getRequestNamespace(ctx); scoped {
+		filters.Namespaces = []string{namespace}
+	}
+	if startTime := string(ctx.QueryArgs().Peek("start_time")); startTime != "" {
+		t, err := time.Parse(time.RFC3339, startTime)
+		if err != nil {
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid start_time, expected RFC3339: %v", err))
+			return
+		}
+		filters.StartTime = &t
+	}
+	if endTime := string(ctx.QueryArgs().Peek("end_time")); endTime != "" {
+		t, err := time.Parse(time.RFC3339, endTime)
+		if err != nil {
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid end_time, expected RFC3339: %v", err))
+			return
+		}
+		filters.EndTime = &t
+	}
+
+	format := string(ctx.QueryArgs().Peek("format"))
+	if format != "" && format != "json" && format != "csv" {
+		SendError(ctx, fasthttp.StatusBadRequest, "Invalid format, must be json or csv")
+		return
+	}
+
+	usage, err := h.logManager.GetKeyModelUsage(ctx, filters)
+	if err != nil {
+		logger.Error("failed to get key usage: %v", err)
+		SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Key usage calculation failed: %v", err))
+		return
+	}
+
+	if format != "csv" {
+		SendJSON(ctx, map[string]interface{}{"usage": usage})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := writeKeyModelUsageCSV(&buf, usage); err != nil {
+		SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to write the key usage: %v", err))
+		return
+	}
+	ctx.SetContentType("text/csv; charset=utf-8")
+	ctx.Response.Header.Set("Content-Disposition", "attachment; filename=key-usage.csv")
+	ctx.SetBody(buf.Bytes())
+}
+
+// writeKeyModelUsageCSV writes the key usage as CSV, one row per key and model
+func writeKeyModelUsageCSV(w io.Writer, usage []logstore.KeyModelUsage) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"key_id", "key_name", "provider", "model", "requests", "errors", "error_rate", "prompt_tokens", "completion_tokens", "total_tokens", "cost"}); err != nil {
+		return err
+	}
+	for _, u := range usage {
+		if err := writer.Write([]string{
+			u.SelectedKeyID,
+			u.SelectedKeyName,
+			u.Provider,
+			u.Model,
+			strconv.FormatInt(u.Requests, 10),
+			strconv.FormatInt(u.Errors, 10),
+			strconv.FormatFloat(u.ErrorRate, 'f', 2, 64),
+			strconv.FormatInt(u.PromptTokens, 10),
+			strconv.FormatInt(u.CompletionTokens, 10),
+			strconv.FormatInt(u.TotalTokens, 10),
+			strconv.FormatFloat(u.Cost, 'f', -1, 64),
+		}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
 // getDroppedRequests handles GET /api/logs/dropped - Get the number of dropped requests
 func (h *LoggingHandler) getDroppedRequests(ctx *fasthttp.RequestCtx) {
 	droppedRequests := h.logManager.GetDroppedRequests(ctx)
@@ -351,6 +499,9 @@ func (h *LoggingHandler) getAvailableFilterData(ctx *fasthttp.RequestCtx) {
 
 // deleteLogs handles DELETE /api/logs - Delete logs by their IDs
 func (h *LoggingHandler) deleteLogs(ctx *fasthttp.RequestCtx) {
+	if !requireRootAdmin(ctx) {
+		return
+	}
 	var req struct {
 		IDs []string `json:"ids"`
 	}
@@ -452,3 +603,17 @@ func parseCommaSeparated(s string) []string {
 
 	return result
 }
+
+// parseTagFilters parses a comma-separated list of name:value tags into a map
+func parseTagFilters(s string) map[string]string {
+	tags := make(map[string]string)
+	for _, item := range parseCommaSeparated(s) {
+		if name, value, ok := strings.Cut(item, ":"); ok && name != "" && value != "" {
+			tags[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/maximhq/bifrost/framework/logstore"
+)
+
+func TestWriteKeyModelUsageCSV(t *testing.T) {
+	usage := []logstore.KeyModelUsage{{
+		SelectedKeyID:    "key-1",
+		SelectedKeyName:  "team, a",
+		Provider:         "openai",
+		Model:            "gpt-4o",
+		Requests:         8,
+		Errors:           2,
+		ErrorRate:        25,
+		PromptTokens:     1000,
+		CompletionTokens: 200,
+		TotalTokens:      1200,
+		Cost:             0.0125,
+	}}
+
+	var buf bytes.Buffer
+	if err := writeKeyModelUsageCSV(&buf, usage); err != nil {
+		t.Fatalf("failed to write the usage: %v", err)
+	}
+	expected := "key_id,key_name,provider,model,requests,errors,error_rate,prompt_tokens,completion_tokens,total_tokens,cost\n" +
+		"key-1,\"team, a\",openai,gpt-4o,8,2,25.00,1000,200,1200,0.0125\n"
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+}
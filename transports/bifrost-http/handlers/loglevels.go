@@ -0,0 +1,88 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the log levels handlers.
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/fasthttp/router"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// LogLevelsHandler reads and changes the log levels of the modules of the gateway at runtime
+type LogLevelsHandler struct {
+	logger schemas.StructuredLogger
+}
+
+// NewLogLevelsHandler creates a new log levels handler instance
+func NewLogLevelsHandler(logger schemas.StructuredLogger) *LogLevelsHandler {
+	return &LogLevelsHandler{
+		logger: logger,
+	}
+}
+
+// LogLevelsResponse represents the log levels of the gateway
+type LogLevelsResponse struct {
+	Level        schemas.LogLevel            `json:"level"`         // Level of the modules without a level of their own
+	ModuleLevels map[string]schemas.LogLevel `json:"module_levels"` // Levels by module
+	Modules      []string                    `json:"modules"`       // Modules that log
+}
+
+// UpdateLogLevelsRequest represents the request body for changing the log levels
+type UpdateLogLevelsRequest struct {
+	Level        schemas.LogLevel            `json:"level,omitempty"`         // Kept when empty
+	ModuleLevels map[string]schemas.LogLevel `json:"module_levels,omitempty"` // Replaces the levels by module when set, {} clears them
+}
+
+// RegisterRoutes registers the log levels routes
+func (h *LogLevelsHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
+	r.GET("/api/log-levels", lib.ChainMiddlewares(h.getLogLevels, middlewares...))
+	r.PUT("/api/log-levels", lib.ChainMiddlewares(h.updateLogLevels, middlewares...))
+}
+
+// getLogLevels handles GET /api/log-levels - Get the log levels of the modules
+func (h *LogLevelsHandler) getLogLevels(ctx *fasthttp.RequestCtx) {
+	SendJSON(ctx, h.logLevels())
+}
+
+// updateLogLevels handles PUT /api/log-levels - Change the log levels until the next restart
+func (h *LogLevelsHandler) updateLogLevels(ctx *fasthttp.RequestCtx) {
+	var req UpdateLogLevelsRequest
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if req.Level != "" && !req.Level.IsValid() {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("invalid log level %q", req.Level))
+		return
+	}
+	for module, level := range req.ModuleLevels {
+		if !level.IsValid() {
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("invalid log level %q for module %s", level, module))
+			return
+		}
+	}
+	level, moduleLevels := h.logger.GetLevels()
+	if req.Level != "" {
+		level = req.Level
+	}
+	if req.ModuleLevels != nil {
+		moduleLevels = req.ModuleLevels
+	}
+	h.logger.SetLevels(level, moduleLevels)
+	logger.Info("log levels changed: level=%s, module levels=%v", level, moduleLevels)
+	SendJSON(ctx, h.logLevels())
+}
+
+// logLevels returns the current log levels
+func (h *LogLevelsHandler) logLevels() LogLevelsResponse {
+	level, moduleLevels := h.logger.GetLevels()
+	return LogLevelsResponse{
+		Level:        level,
+		ModuleLevels: moduleLevels,
+		Modules:      h.logger.Modules(),
+	}
+}
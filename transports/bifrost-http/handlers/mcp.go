@@ -189,6 +189,10 @@ func (h *MCPHandler) addMCPClient(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid tools_to_execute: %v", err))
 		return
 	}
+	if err := lib.ValidateMCPSecretReferences(req, nil); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid MCP client config: %v", err))
+		return
+	}
 	if err := h.mcpManager.AddMCPClient(ctx, req); err != nil {
 		SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to add MCP client: %v", err))
 		return
@@ -219,6 +223,17 @@ func (h *MCPHandler) editMCPClient(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	// File references are kept when they are unchanged from the config file
+	var existing *schemas.MCPClientConfig
+	if oldConfig, err := h.store.GetMCPClient(id); err == nil {
+		redacted := h.store.RedactMCPClientConfig(*oldConfig)
+		existing = &redacted
+	}
+	if err := lib.ValidateMCPSecretReferences(req, existing); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid MCP client config: %v", err))
+		return
+	}
+
 	if err := h.mcpManager.EditMCPClient(ctx, id, req); err != nil {
 		SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to edit MCP client: %v", err))
 		return
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"slices"
 	"strings"
 	"time"
@@ -27,7 +29,8 @@ func CorsMiddleware(config *lib.Config) lib.BifrostHTTPMiddleware {
 			if allowed {
 				ctx.Response.Header.Set("Access-Control-Allow-Origin", origin)
 				ctx.Response.Header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, PATCH, OPTIONS")
-				ctx.Response.Header.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
+				ctx.Response.Header.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, If-Match, If-None-Match")
+				ctx.Response.Header.Set("Access-Control-Expose-Headers", "ETag, X-Bifrost-API-Version, X-Request-Id")
 				ctx.Response.Header.Set("Access-Control-Allow-Credentials", "true")
 				ctx.Response.Header.Set("Access-Control-Max-Age", "86400")
 			}
@@ -45,6 +48,29 @@ func CorsMiddleware(config *lib.Config) lib.BifrostHTTPMiddleware {
 	}
 }
 
+// ManagementAPIVersion is the version of the management API, served under /api/v1 as well as /api
+const ManagementAPIVersion = "v1"
+
+// APIVersionMiddleware serves the management API under its versioned prefix by routing /api/v1/... to /api/..., so
+// that clients such as the Terraform provider can pin the version they were built against. The version is returned
+// in the X-Bifrost-API-Version header of the management API responses.
+func APIVersionMiddleware() lib.BifrostHTTPMiddleware {
+	prefix := "/api/" + ManagementAPIVersion + "/"
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			path := string(ctx.Path())
+			if strings.HasPrefix(path, prefix) {
+				ctx.URI().SetPath("/api/" + strings.TrimPrefix(path, prefix))
+				path = string(ctx.Path())
+			}
+			if strings.HasPrefix(path, "/api/") {
+				ctx.Response.Header.Set("X-Bifrost-API-Version", ManagementAPIVersion)
+			}
+			next(ctx)
+		}
+	}
+}
+
 // TransportInterceptorMiddleware collects all plugin interceptors and calls them one by one
 func TransportInterceptorMiddleware(config *lib.Config) lib.BifrostHTTPMiddleware {
 	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
@@ -133,11 +159,93 @@ func TransportInterceptorMiddleware(config *lib.Config) lib.BifrostHTTPMiddlewar
 				ctx.Request.Header.Set(key, value)
 			}
 			next(ctx)
+
+			// Report the limits of the virtual key of the request
+			for _, plugin := range plugins {
+				if governancePlugin, ok := plugin.(*governance.GovernancePlugin); ok {
+					for key, value := range governancePlugin.RateLimitHeaders(headers, requestBody) {
+						ctx.Response.Header.Set(key, value)
+					}
+					break
+				}
+			}
+		}
+	}
+}
+
+// DrainMiddleware refuses new inference requests with 503 while the server is draining, and tracks the admitted ones
+// until their handler returns so that the drain waits for them
+func DrainMiddleware(drain *lib.Drain) lib.BifrostHTTPMiddleware {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			if !drain.Admit() {
+				// Clients reconnect elsewhere instead of reusing the connection to the draining instance
+				ctx.Response.Header.Set("Connection", "close")
+				ctx.Response.Header.Set("Retry-After", "1")
+				SendError(ctx, fasthttp.StatusServiceUnavailable, "server is draining, retry on another instance")
+				return
+			}
+			defer drain.Release()
+			next(ctx)
+		}
+	}
+}
+
+// DiagnosticsMiddleware captures the bodies of the inference requests exceeding the latency or size thresholds of the
+// diagnostics config
+func DiagnosticsMiddleware(config *lib.Config) lib.BifrostHTTPMiddleware {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			diagnostics := config.ClientConfig.Diagnostics
+			if diagnostics == nil || !diagnostics.Enabled {
+				next(ctx)
+				return
+			}
+			start := time.Now()
+			next(ctx)
+			config.Diagnostics.Capture(diagnostics, ctx, time.Since(start))
+		}
+	}
+}
+
+// RequestBodyLimitMiddleware enforces the request body size limit of a server streaming its request bodies, which
+// fasthttp no longer applies itself. Bodies declaring a larger size are refused with 413, and chunked bodies are
+// read up to the limit, so that handlers reading the whole body never hold more than the limit while handlers
+// reading the stream (see IngestionHandler.createJob) do not have to buffer the body.
+func RequestBodyLimitMiddleware(maxBodySize int) lib.BifrostHTTPMiddleware {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			stream := ctx.RequestBodyStream()
+			if stream == nil || maxBodySize <= 0 {
+				next(ctx)
+				return
+			}
+			contentLength := ctx.Request.Header.ContentLength()
+			if contentLength > maxBodySize {
+				ctx.Response.Header.Set("Connection", "close")
+				SendError(ctx, fasthttp.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", maxBodySize))
+				return
+			}
+			if contentLength < 0 {
+				body, err := io.ReadAll(io.LimitReader(stream, int64(maxBodySize)+1))
+				if err != nil {
+					SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("failed to read request body: %v", err))
+					return
+				}
+				if len(body) > maxBodySize {
+					ctx.Response.Header.Set("Connection", "close")
+					SendError(ctx, fasthttp.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", maxBodySize))
+					return
+				}
+				ctx.Request.SetBody(body)
+			}
+			next(ctx)
 		}
 	}
 }
 
 // validateSession checks if a session token is valid
+// On success the namespace of the session (if any) is attached to the request
 func validateSession(ctx *fasthttp.RequestCtx, store configstore.ConfigStore, token string) bool {
 	session, err := store.GetSession(context.Background(), token)
 	if err != nil || session == nil {
@@ -146,9 +254,36 @@ func validateSession(ctx *fasthttp.RequestCtx, store configstore.ConfigStore, to
 	if session.ExpiresAt.Before(time.Now()) {
 		return false
 	}
+	if session.Namespace != "" {
+		ctx.SetUserValue(namespaceUserValueKey, session.Namespace)
+	}
 	return true
 }
 
+// authenticateAdmin verifies admin credentials against the root admin and the namespace admins.
+// It returns the namespace of the admin, empty for the root admin.
+func authenticateAdmin(ctx context.Context, store configstore.ConfigStore, authConfig *configstore.AuthConfig, username, password string) (string, bool, error) {
+	if username == authConfig.AdminUserName {
+		compare, err := encrypt.CompareHash(authConfig.AdminPassword, password)
+		if err != nil {
+			return "", false, err
+		}
+		return "", compare, nil
+	}
+	namespace, err := store.GetNamespaceByAdminUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	compare, err := encrypt.CompareHash(namespace.AdminPassword, password)
+	if err != nil {
+		return "", false, err
+	}
+	return namespace.Name, compare, nil
+}
+
 // AuthMiddleware if authConfig is set, it will verify the auth cookie in the header
 // This uses basic auth style username + password based authentication
 // No session tracking is used, so this is not suitable for production environments
@@ -179,6 +314,11 @@ func AuthMiddleware(store configstore.ConfigStore) lib.BifrostHTTPMiddleware {
 				next(ctx)
 				return
 			}
+			// Requests authenticated by the JWT auth of the inference routes carry no admin credentials
+			if authenticated, _ := ctx.UserValue(jwtAuthenticatedUserValueKey).(bool); authenticated {
+				next(ctx)
+				return
+			}
 			// Get the authorization header
 			authorization := string(ctx.Request.Header.Peek("Authorization"))
 			if authorization == "" {
@@ -195,6 +335,10 @@ func AuthMiddleware(store configstore.ConfigStore) lib.BifrostHTTPMiddleware {
 						SendError(ctx, fasthttp.StatusUnauthorized, "Unauthorized")
 						return
 					}
+					if !isRequestAllowedForNamespace(ctx) {
+						SendError(ctx, fasthttp.StatusForbidden, "Forbidden")
+						return
+					}
 					// Continue with the next handler
 					next(ctx)
 					return
@@ -223,11 +367,7 @@ func AuthMiddleware(store configstore.ConfigStore) lib.BifrostHTTPMiddleware {
 					return
 				}
 				// Verify the username and password
-				if username != authConfig.AdminUserName {
-					SendError(ctx, fasthttp.StatusUnauthorized, "Unauthorized")
-					return
-				}
-				compare, err := encrypt.CompareHash(authConfig.AdminPassword, password)
+				namespace, compare, err := authenticateAdmin(ctx, store, authConfig, username, password)
 				if err != nil {
 					SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to compare password: %v", err))
 					return
@@ -236,6 +376,13 @@ func AuthMiddleware(store configstore.ConfigStore) lib.BifrostHTTPMiddleware {
 					SendError(ctx, fasthttp.StatusUnauthorized, "Unauthorized")
 					return
 				}
+				if namespace != "" {
+					ctx.SetUserValue(namespaceUserValueKey, namespace)
+				}
+				if !isRequestAllowedForNamespace(ctx) {
+					SendError(ctx, fasthttp.StatusForbidden, "Forbidden")
+					return
+				}
 				// Continue with the next handler
 				next(ctx)
 				return
@@ -247,6 +394,10 @@ func AuthMiddleware(store configstore.ConfigStore) lib.BifrostHTTPMiddleware {
 					SendError(ctx, fasthttp.StatusUnauthorized, "Unauthorized")
 					return
 				}
+				if !isRequestAllowedForNamespace(ctx) {
+					SendError(ctx, fasthttp.StatusForbidden, "Forbidden")
+					return
+				}
 				// Continue with the next handler
 				next(ctx)
 				return
@@ -255,3 +406,112 @@ func AuthMiddleware(store configstore.ConfigStore) lib.BifrostHTTPMiddleware {
 		}
 	}
 }
+
+// isRequestAllowedForNamespace checks if the authenticated admin may call the requested route.
+// The root admin may call every route, namespace admins only the namespace scoped ones.
+func isRequestAllowedForNamespace(ctx *fasthttp.RequestCtx) bool {
+	if _, scoped := getRequestNamespace(ctx); !scoped {
+		return true
+	}
+	return isRouteAllowedForNamespaceAdmin(string(ctx.Path()))
+}
+
+// jwtSubjectUserValueKey is the request user value holding the subject of a verified JWT
+const jwtSubjectUserValueKey = "bf-jwt-subject"
+
+// jwtAuthenticatedUserValueKey is the request user value set once a request is authenticated by a verified JWT,
+// so that the admin auth chained after the JWT auth lets it through
+const jwtAuthenticatedUserValueKey = "bf-jwt-authenticated"
+
+// JWTAuthMiddleware authenticates inference requests carrying an OIDC/JWT bearer token.
+// The verified claims are mapped to a virtual key, which is set as the x-bf-vk header so that
+// governance applies as if the client had sent the virtual key itself.
+// Bearer values that are virtual keys are left untouched, requests without a JWT are only
+// rejected when the config requires one. Otherwise they go on to the admin auth chained after this
+// middleware, which only skips the requests authenticated by a JWT.
+func JWTAuthMiddleware(config *lib.Config, verifier *lib.JWTVerifier) lib.BifrostHTTPMiddleware {
+	jwtConfig := config.JWTAuthConfig
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			token := getJWTFromRequest(ctx)
+			if token == "" {
+				if jwtConfig.Required {
+					SendError(ctx, fasthttp.StatusUnauthorized, "Unauthorized")
+					return
+				}
+				next(ctx)
+				return
+			}
+			claims, err := verifier.Verify(ctx, token)
+			if err != nil {
+				logger.Debug("jwt verification failed: %v", err)
+				SendError(ctx, fasthttp.StatusUnauthorized, "Unauthorized")
+				return
+			}
+			virtualKey, ok := resolveJWTVirtualKey(config, jwtConfig, claims)
+			if !ok {
+				SendError(ctx, fasthttp.StatusForbidden, "No virtual key is mapped to the token claims")
+				return
+			}
+			// The token must never reach a provider as a direct key
+			ctx.Request.Header.Del("Authorization")
+			ctx.Request.Header.Set(string(schemas.BifrostContextKeyVirtualKey), virtualKey)
+			if subject, _ := claims["sub"].(string); subject != "" {
+				ctx.SetUserValue(jwtSubjectUserValueKey, subject)
+			}
+			ctx.SetUserValue(jwtAuthenticatedUserValueKey, true)
+			next(ctx)
+		}
+	}
+}
+
+// getJWTFromRequest returns the bearer token of the request if it is shaped like a JWT
+func getJWTFromRequest(ctx *fasthttp.RequestCtx) string {
+	scheme, token, ok := strings.Cut(string(ctx.Request.Header.Peek("Authorization")), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	token = strings.TrimSpace(token)
+	if strings.HasPrefix(strings.ToLower(token), governance.VirtualKeyPrefix) || strings.Count(token, ".") != 2 {
+		return ""
+	}
+	return token
+}
+
+// resolveJWTVirtualKey maps verified claims to a virtual key value.
+// The virtual key claim is checked first, then the claim mappings in order.
+func resolveJWTVirtualKey(config *lib.Config, jwtConfig *lib.JWTAuthConfig, claims map[string]any) (string, bool) {
+	if jwtConfig.VirtualKeyClaim != "" {
+		if values := lib.ClaimStrings(claims, jwtConfig.VirtualKeyClaim); len(values) > 0 && values[0] != "" {
+			return values[0], true
+		}
+	}
+	for _, mapping := range jwtConfig.ClaimMappings {
+		if !slices.Contains(lib.ClaimStrings(claims, mapping.Claim), mapping.Value) {
+			continue
+		}
+		if mapping.VirtualKey != "" {
+			return mapping.VirtualKey, true
+		}
+		if vk, ok := getVirtualKeyForTeam(config, mapping.TeamID); ok {
+			return vk, true
+		}
+	}
+	return "", false
+}
+
+// getVirtualKeyForTeam looks up the virtual key of a team in the governance plugin
+func getVirtualKeyForTeam(config *lib.Config, teamID string) (string, bool) {
+	for _, plugin := range config.GetLoadedPlugins() {
+		governancePlugin, ok := plugin.(*governance.GovernancePlugin)
+		if !ok {
+			continue
+		}
+		vk, ok := governancePlugin.GetGovernanceStore().GetVirtualKeyForTeam(teamID)
+		if !ok {
+			return "", false
+		}
+		return vk.Value, true
+	}
+	return "", false
+}
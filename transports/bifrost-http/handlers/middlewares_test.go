@@ -1,11 +1,25 @@
 package handlers
 
 import (
+	"bufio"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/maximhq/bifrost/framework/configstore"
+	"github.com/maximhq/bifrost/framework/encrypt"
 	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
 	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
 )
 
 // TestCorsMiddleware_LocalhostOrigins tests that localhost origins are always allowed
@@ -45,7 +59,7 @@ func TestCorsMiddleware_LocalhostOrigins(t *testing.T) {
 			if string(ctx.Response.Header.Peek("Access-Control-Allow-Methods")) != "GET, POST, PUT, DELETE, PATCH, OPTIONS" {
 				t.Errorf("Access-Control-Allow-Methods header not set correctly")
 			}
-			if string(ctx.Response.Header.Peek("Access-Control-Allow-Headers")) != "Content-Type, Authorization, X-Requested-With" {
+			if string(ctx.Response.Header.Peek("Access-Control-Allow-Headers")) != "Content-Type, Authorization, X-Requested-With, If-Match, If-None-Match" {
 				t.Errorf("Access-Control-Allow-Headers header not set correctly")
 			}
 			if string(ctx.Response.Header.Peek("Access-Control-Allow-Credentials")) != "true" {
@@ -511,3 +525,134 @@ func TestChainMiddlewares_ShortCircuitMiddlePosition(t *testing.T) {
 		t.Errorf("Expected body 'Unauthorized', got '%s'", string(ctx.Response.Body()))
 	}
 }
+
+// authTestStore serves the auth config to the admin auth, the other methods of the store are not used
+type authTestStore struct {
+	configstore.ConfigStore
+	authConfig *configstore.AuthConfig
+}
+
+func (s *authTestStore) GetAuthConfig(ctx context.Context) (*configstore.AuthConfig, error) {
+	return s.authConfig, nil
+}
+
+// TestJWTAuthMiddleware_OptionalChainsAdminAuth tests that with an optional JWT, the inference requests without a
+// JWT still go through the admin auth, while the requests with a verified JWT skip it
+func TestJWTAuthMiddleware_OptionalChainsAdminAuth(t *testing.T) {
+	signingKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key-1",
+			"n":   base64.RawURLEncoding.EncodeToString(signingKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(signingKey.E)).Bytes()),
+		}}})
+	}))
+	defer jwks.Close()
+
+	config := &lib.Config{JWTAuthConfig: &lib.JWTAuthConfig{
+		Enabled:         true,
+		Issuer:          "https://issuer.example.com",
+		JWKSURL:         jwks.URL,
+		Required:        false,
+		VirtualKeyClaim: "vk",
+	}}
+	verifier, err := lib.NewJWTVerifier(config.JWTAuthConfig)
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+	adminPassword, err := encrypt.Hash("secret")
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	store := &authTestStore{authConfig: &configstore.AuthConfig{AdminUserName: "admin", AdminPassword: adminPassword, IsEnabled: true}}
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "key-1"})
+	claims, _ := json.Marshal(map[string]any{"iss": "https://issuer.example.com", "exp": time.Now().Add(time.Hour).Unix(), "vk": "sk-bf-team"})
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, signingKey, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	token := signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+
+	tests := map[string]struct {
+		authorization string
+		wantStatus    int
+	}{
+		"no credentials":       {"", fasthttp.StatusUnauthorized},
+		"wrong admin password": {"Basic " + base64.StdEncoding.EncodeToString([]byte("admin:wrong")), fasthttp.StatusUnauthorized},
+		"admin credentials":    {"Basic " + base64.StdEncoding.EncodeToString([]byte("admin:secret")), fasthttp.StatusOK},
+		"verified jwt":         {"Bearer " + token, fasthttp.StatusOK},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.SetRequestURI("/v1/chat/completions")
+			if tt.authorization != "" {
+				ctx.Request.Header.Set("Authorization", tt.authorization)
+			}
+			handler := lib.ChainMiddlewares(func(ctx *fasthttp.RequestCtx) {
+				ctx.SetStatusCode(fasthttp.StatusOK)
+			}, JWTAuthMiddleware(config, verifier), AuthMiddleware(store))
+			handler(ctx)
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, ctx.Response.StatusCode())
+			}
+		})
+	}
+}
+
+// TestRequestBodyLimitMiddleware tests that a server streaming its request bodies refuses the bodies over the limit,
+// whether their size is declared or they are chunked
+func TestRequestBodyLimitMiddleware(t *testing.T) {
+	const maxBodySize = 16
+	listener := fasthttputil.NewInmemoryListener()
+	defer listener.Close()
+	server := &fasthttp.Server{
+		Handler: RequestBodyLimitMiddleware(maxBodySize)(func(ctx *fasthttp.RequestCtx) {
+			ctx.SetBody(ctx.PostBody())
+		}),
+		MaxRequestBodySize: maxBodySize,
+		StreamRequestBody:  true,
+	}
+	go server.Serve(listener) //nolint:errcheck
+
+	tests := map[string]struct {
+		request    string
+		wantStatus int
+		wantBody   string
+	}{
+		"declared size within limit":  {request: "Content-Length: 5\r\n\r\nhello", wantStatus: fasthttp.StatusOK, wantBody: "hello"},
+		"declared size over limit":    {request: "Content-Length: 20\r\n\r\nhello hello hello !!", wantStatus: fasthttp.StatusRequestEntityTooLarge},
+		"chunked body within limit":   {request: "Transfer-Encoding: chunked\r\n\r\n5\r\nhello\r\n6\r\n world\r\n0\r\n\r\n", wantStatus: fasthttp.StatusOK, wantBody: "hello world"},
+		"chunked body over the limit": {request: "Transfer-Encoding: chunked\r\n\r\nb\r\nhello world\r\nb\r\nhello world\r\n0\r\n\r\n", wantStatus: fasthttp.StatusRequestEntityTooLarge},
+		"chunked body at exact limit": {request: "Transfer-Encoding: chunked\r\n\r\n10\r\n0123456789abcdef\r\n0\r\n\r\n", wantStatus: fasthttp.StatusOK, wantBody: "0123456789abcdef"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			conn, err := listener.Dial()
+			if err != nil {
+				t.Fatalf("failed to dial server: %v", err)
+			}
+			defer conn.Close()
+			if _, err := conn.Write([]byte("POST /api/ingestion/jobs HTTP/1.1\r\nHost: localhost\r\n" + tt.request)); err != nil {
+				t.Fatalf("failed to write request: %v", err)
+			}
+			var resp fasthttp.Response
+			if err := resp.Read(bufio.NewReader(conn)); err != nil {
+				t.Fatalf("failed to read response: %v", err)
+			}
+			if resp.StatusCode() != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, resp.StatusCode(), resp.Body())
+			}
+			if tt.wantBody != "" && string(resp.Body()) != tt.wantBody {
+				t.Errorf("expected body %q, got %q", tt.wantBody, resp.Body())
+			}
+		})
+	}
+}
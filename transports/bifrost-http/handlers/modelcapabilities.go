@@ -0,0 +1,139 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the model capability registry handlers.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/fasthttp/router"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// ModelCapabilityManager looks up the capabilities of models and reloads their overrides from the config store
+type ModelCapabilityManager interface {
+	GetModelCapabilities(provider schemas.ModelProvider, model string) *schemas.ModelCapabilities
+	ReloadModelCapabilities(ctx context.Context) error
+}
+
+// ModelCapabilitiesHandler manages HTTP requests for the model capability registry
+type ModelCapabilitiesHandler struct {
+	configStore            configstore.ConfigStore
+	modelCapabilityManager ModelCapabilityManager
+}
+
+// NewModelCapabilitiesHandler creates a new model capabilities handler instance
+func NewModelCapabilitiesHandler(manager ModelCapabilityManager, configStore configstore.ConfigStore) (*ModelCapabilitiesHandler, error) {
+	if configStore == nil {
+		return nil, fmt.Errorf("config store is required")
+	}
+	if manager == nil {
+		return nil, fmt.Errorf("model capability manager is required")
+	}
+	return &ModelCapabilitiesHandler{
+		configStore:            configStore,
+		modelCapabilityManager: manager,
+	}, nil
+}
+
+// RegisterRoutes registers all model capability routes
+func (h *ModelCapabilitiesHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
+	r.GET("/api/model-capabilities", lib.ChainMiddlewares(h.getModelCapabilities, middlewares...))
+	r.GET("/api/model-capabilities/overrides", lib.ChainMiddlewares(h.getOverrides, middlewares...))
+	r.PUT("/api/model-capabilities/overrides", lib.ChainMiddlewares(h.upsertOverride, middlewares...))
+	r.DELETE("/api/model-capabilities/overrides", lib.ChainMiddlewares(h.deleteOverride, middlewares...))
+}
+
+// getModelCapabilities handles GET /api/model-capabilities?provider=&model= - Get the capabilities requests to a model are validated against
+func (h *ModelCapabilitiesHandler) getModelCapabilities(ctx *fasthttp.RequestCtx) {
+	provider := string(ctx.QueryArgs().Peek("provider"))
+	model := string(ctx.QueryArgs().Peek("model"))
+	if provider == "" || model == "" {
+		SendError(ctx, 400, "provider and model are required")
+		return
+	}
+	capabilities := h.modelCapabilityManager.GetModelCapabilities(schemas.ModelProvider(provider), model)
+	if capabilities == nil {
+		SendError(ctx, 404, fmt.Sprintf("no capabilities are known for model %s of provider %s", model, provider))
+		return
+	}
+	SendJSON(ctx, map[string]interface{}{
+		"provider":     provider,
+		"model":        model,
+		"capabilities": capabilities,
+	})
+}
+
+// getOverrides handles GET /api/model-capabilities/overrides - Get all capability overrides
+func (h *ModelCapabilitiesHandler) getOverrides(ctx *fasthttp.RequestCtx) {
+	overrides, err := h.configStore.GetModelCapabilityOverrides(ctx)
+	if err != nil {
+		logger.Error("failed to retrieve model capability overrides: %v", err)
+		SendError(ctx, 500, "Failed to retrieve model capability overrides")
+		return
+	}
+	SendJSON(ctx, map[string]interface{}{
+		"overrides": overrides,
+		"count":     len(overrides),
+	})
+}
+
+// upsertOverride handles PUT /api/model-capabilities/overrides - Create or replace the capability override of a model
+func (h *ModelCapabilitiesHandler) upsertOverride(ctx *fasthttp.RequestCtx) {
+	var override configstoreTables.TableModelCapability
+	if err := json.Unmarshal(ctx.PostBody(), &override); err != nil {
+		SendError(ctx, 400, "Invalid JSON")
+		return
+	}
+	if override.Provider == "" || override.Model == "" {
+		SendError(ctx, 400, "provider and model are required, use * as the provider to override the model on every provider")
+		return
+	}
+	if (override.ContextWindow != nil && *override.ContextWindow <= 0) || (override.MaxOutputTokens != nil && *override.MaxOutputTokens <= 0) {
+		SendError(ctx, 400, "context window and max output tokens must be positive")
+		return
+	}
+	if err := h.configStore.UpsertModelCapabilityOverride(ctx, &override); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to save model capability override: %v", err))
+		return
+	}
+	if err := h.modelCapabilityManager.ReloadModelCapabilities(ctx); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to reload model capabilities: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message":  "Model capability override saved successfully",
+		"override": override,
+	})
+}
+
+// deleteOverride handles DELETE /api/model-capabilities/overrides?provider=&model= - Delete the capability override of a model
+func (h *ModelCapabilitiesHandler) deleteOverride(ctx *fasthttp.RequestCtx) {
+	provider := string(ctx.QueryArgs().Peek("provider"))
+	model := string(ctx.QueryArgs().Peek("model"))
+	if provider == "" || model == "" {
+		SendError(ctx, 400, "provider and model are required")
+		return
+	}
+	if err := h.configStore.DeleteModelCapabilityOverride(ctx, provider, model); err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Model capability override not found")
+			return
+		}
+		SendError(ctx, 500, fmt.Sprintf("Failed to delete model capability override: %v", err))
+		return
+	}
+	if err := h.modelCapabilityManager.ReloadModelCapabilities(ctx); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to reload model capabilities: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message": "Model capability override deleted successfully",
+	})
+}
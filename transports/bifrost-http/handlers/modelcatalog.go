@@ -0,0 +1,127 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the unified model catalog listed across all providers.
+package handlers
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+// Capabilities the model catalog can be filtered by
+const (
+	catalogCapabilityVision   = "vision"
+	catalogCapabilityTools    = "tools"
+	catalogCapabilityJSONMode = "json_mode"
+)
+
+// modelCatalogFilter selects the models of the catalog by capability
+type modelCatalogFilter struct {
+	Capabilities     []string // Capabilities the models must all support
+	MinContextWindow int      // Minimum context window of the models, 0 for any
+}
+
+// parseModelCatalogFilter parses the capabilities and min_context_window query parameters
+func parseModelCatalogFilter(ctx *fasthttp.RequestCtx) (modelCatalogFilter, error) {
+	var filter modelCatalogFilter
+	if capabilities := string(ctx.QueryArgs().Peek("capabilities")); capabilities != "" {
+		for _, capability := range parseCommaSeparated(capabilities) {
+			switch capability {
+			case catalogCapabilityVision, catalogCapabilityTools, catalogCapabilityJSONMode:
+				filter.Capabilities = append(filter.Capabilities, capability)
+			default:
+				return filter, fmt.Errorf("unknown capability %q, must be one of %s, %s or %s", capability, catalogCapabilityVision, catalogCapabilityTools, catalogCapabilityJSONMode)
+			}
+		}
+	}
+	if minContextWindow := string(ctx.QueryArgs().Peek("min_context_window")); minContextWindow != "" {
+		n, err := strconv.Atoi(minContextWindow)
+		if err != nil || n < 0 {
+			return filter, fmt.Errorf("invalid min_context_window %q", minContextWindow)
+		}
+		filter.MinContextWindow = n
+	}
+	return filter, nil
+}
+
+// empty reports whether the filter selects every model
+func (filter modelCatalogFilter) empty() bool {
+	return len(filter.Capabilities) == 0 && filter.MinContextWindow == 0
+}
+
+// matches reports whether a model with the capabilities is selected by the filter,
+// models with unknown capabilities only match an empty filter
+func (filter modelCatalogFilter) matches(capabilities *schemas.ModelCapabilities) bool {
+	if filter.empty() {
+		return true
+	}
+	if capabilities == nil {
+		return false
+	}
+	for _, capability := range filter.Capabilities {
+		switch capability {
+		case catalogCapabilityVision:
+			if !capabilities.SupportsVision {
+				return false
+			}
+		case catalogCapabilityTools:
+			if !capabilities.SupportsTools {
+				return false
+			}
+		case catalogCapabilityJSONMode:
+			if !capabilities.SupportsJSONMode {
+				return false
+			}
+		}
+	}
+	return filter.MinContextWindow == 0 || capabilities.ContextWindow >= filter.MinContextWindow
+}
+
+// buildModelCatalog merges the models listed by the providers into one entry per model, sorted by ID.
+// Models are identified by their name without the provider prefix: the entry of a model served by several providers
+// is the entry with the lowest ID, its canonical slug is the model name and its providers are the providers serving it.
+// Capabilities are looked up in the registry, which may be nil, and the models not selected by the filter are dropped.
+func buildModelCatalog(models []schemas.Model, registry schemas.ModelCapabilityRegistry, filter modelCatalogFilter) []schemas.Model {
+	sorted := slices.Clone(models)
+	slices.SortFunc(sorted, func(a, b schemas.Model) int { return strings.Compare(a.ID, b.ID) })
+
+	catalog := make([]schemas.Model, 0, len(sorted))
+	indexes := make(map[string]int)
+	for _, model := range sorted {
+		provider, name := schemas.ParseModelString(model.ID, "")
+		slug := strings.ToLower(name)
+		if i, ok := indexes[slug]; ok {
+			if provider != "" && !slices.Contains(catalog[i].Providers, provider) {
+				catalog[i].Providers = append(catalog[i].Providers, provider)
+			}
+			if catalog[i].Capabilities == nil && registry != nil {
+				catalog[i].Capabilities = registry.GetModelCapabilities(provider, name)
+			}
+			continue
+		}
+
+		entry := model
+		entry.CanonicalSlug = schemas.Ptr(slug)
+		entry.Providers = nil
+		if provider != "" {
+			entry.Providers = []schemas.ModelProvider{provider}
+		}
+		if entry.Capabilities == nil && registry != nil {
+			entry.Capabilities = registry.GetModelCapabilities(provider, name)
+		}
+		indexes[slug] = len(catalog)
+		catalog = append(catalog, entry)
+	}
+
+	selected := catalog[:0]
+	for _, entry := range catalog {
+		if filter.matches(entry.Capabilities) {
+			selected = append(selected, entry)
+		}
+	}
+	return selected
+}
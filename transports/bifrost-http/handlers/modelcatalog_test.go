@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+type staticCapabilityRegistry map[string]*schemas.ModelCapabilities
+
+func (r staticCapabilityRegistry) GetModelCapabilities(provider schemas.ModelProvider, model string) *schemas.ModelCapabilities {
+	return r[model]
+}
+
+func TestBuildModelCatalog(t *testing.T) {
+	models := []schemas.Model{
+		{ID: "openai/gpt-4o"},
+		{ID: "azure/gpt-4o", Pricing: &schemas.Pricing{Prompt: schemas.Ptr("0.000002")}},
+		{ID: "openai/gpt-3.5-turbo"},
+		{ID: "ollama/llama3"},
+	}
+	registry := staticCapabilityRegistry{
+		"gpt-4o":        {ContextWindow: 128000, SupportsVision: true, SupportsTools: true},
+		"gpt-3.5-turbo": {ContextWindow: 16385, SupportsTools: true},
+	}
+
+	catalog := buildModelCatalog(models, registry, modelCatalogFilter{})
+	if len(catalog) != 3 {
+		t.Fatalf("expected the models served by several providers to be merged, got %d entries", len(catalog))
+	}
+	gpt4o := catalog[0]
+	if gpt4o.ID != "azure/gpt-4o" || gpt4o.Pricing == nil || *gpt4o.CanonicalSlug != "gpt-4o" {
+		t.Errorf("expected the entry with the lowest ID to be kept, got %+v", gpt4o)
+	}
+	if !slices.Equal(gpt4o.Providers, []schemas.ModelProvider{schemas.Azure, schemas.OpenAI}) {
+		t.Errorf("expected both providers to serve gpt-4o, got %v", gpt4o.Providers)
+	}
+	if gpt4o.Capabilities == nil || !gpt4o.Capabilities.SupportsVision {
+		t.Errorf("expected the capabilities of the registry, got %+v", gpt4o.Capabilities)
+	}
+
+	catalog = buildModelCatalog(models, registry, modelCatalogFilter{Capabilities: []string{catalogCapabilityTools}, MinContextWindow: 32000})
+	if len(catalog) != 1 || catalog[0].ID != "azure/gpt-4o" {
+		t.Errorf("expected only gpt-4o to support tools with a large context window, got %+v", catalog)
+	}
+
+	catalog = buildModelCatalog(models, nil, modelCatalogFilter{Capabilities: []string{catalogCapabilityVision}})
+	if len(catalog) != 0 {
+		t.Errorf("expected models with unknown capabilities to be filtered out, got %+v", catalog)
+	}
+}
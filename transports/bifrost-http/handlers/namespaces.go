@@ -0,0 +1,432 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains namespace (tenant) management and the namespace authorization helpers.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fasthttp/router"
+	"github.com/google/uuid"
+	"github.com/maximhq/bifrost/framework/configstore"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+	"github.com/maximhq/bifrost/framework/encrypt"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+	"gorm.io/gorm"
+)
+
+// namespaceUserValueKey is the request user value holding the namespace of an authenticated namespace admin.
+// It is not set for the root admin or when auth is disabled, in which case all namespaces are accessible.
+const namespaceUserValueKey = "bf-namespace"
+
+// namespaceProviderSeparator separates the namespace from the name of the providers created by namespace admins,
+// it cannot appear in the name of a namespace
+const namespaceProviderSeparator = "."
+
+// namespaceAdminAllowedRoutePrefixes are the API routes a namespace admin may call.
+// Everything else under /api (global config, plugins, MCP, logs, namespaces) is reserved to the root admin.
+var namespaceAdminAllowedRoutePrefixes = []string{
+	"/api/providers",
+	"/api/keys",
+	"/api/models",
+	"/api/governance/",
+	"/api/session/",
+	"/api/version",
+}
+
+// namespaceAdminAllowedRoutes are the exact API routes a namespace admin may call in addition to the prefixes above.
+// Logs are filtered to the namespace of the admin, who is the only one reading payloads encrypted with the namespace key.
+var namespaceAdminAllowedRoutes = []string{
+	"/api/logs",
+	"/api/logs/stats",
+}
+
+// getRequestNamespace returns the namespace the request is scoped to.
+// The second return value is false when the request is not scoped (root admin or auth disabled).
+func getRequestNamespace(ctx *fasthttp.RequestCtx) (string, bool) {
+	namespace, ok := ctx.UserValue(namespaceUserValueKey).(string)
+	if !ok || namespace == "" {
+		return "", false
+	}
+	return namespace, true
+}
+
+// canAccessNamespace checks if the request is allowed to see and modify entities of the given namespace
+func canAccessNamespace(ctx *fasthttp.RequestCtx, namespace string) bool {
+	requestNamespace, scoped := getRequestNamespace(ctx)
+	if !scoped {
+		return true
+	}
+	if namespace == "" {
+		namespace = configstoreTables.DefaultNamespace
+	}
+	return requestNamespace == namespace
+}
+
+// resolveNamespaceForCreate returns the namespace a new entity is created in.
+// Namespace admins always create in their own namespace, the root admin may pick any namespace.
+func resolveNamespaceForCreate(ctx *fasthttp.RequestCtx, requested *string) (string, error) {
+	requestNamespace, scoped := getRequestNamespace(ctx)
+	if scoped {
+		if requested != nil && *requested != "" && *requested != requestNamespace {
+			return "", fmt.Errorf("cannot create entities outside of namespace %s", requestNamespace)
+		}
+		return requestNamespace, nil
+	}
+	if requested != nil && *requested != "" {
+		return *requested, nil
+	}
+	return configstoreTables.DefaultNamespace, nil
+}
+
+// checkProviderNameForCreate checks that namespace admins name the providers they create after their namespace, as in
+// "acme.openai". Provider names are global, so a namespace admin could otherwise take the name of a provider another
+// namespace needs, such as the standard providers.
+func checkProviderNameForCreate(ctx *fasthttp.RequestCtx, provider string) error {
+	requestNamespace, scoped := getRequestNamespace(ctx)
+	if !scoped {
+		return nil
+	}
+	prefix := requestNamespace + namespaceProviderSeparator
+	if !strings.HasPrefix(provider, prefix) || len(provider) == len(prefix) {
+		return fmt.Errorf("providers of namespace %s must be custom providers named %s<name>", requestNamespace, prefix)
+	}
+	return nil
+}
+
+// requireRootAdmin rejects requests of namespace admins, for handlers sharing a route with namespace scoped ones.
+// Returns false if the request was rejected.
+func requireRootAdmin(ctx *fasthttp.RequestCtx) bool {
+	if _, scoped := getRequestNamespace(ctx); scoped {
+		SendError(ctx, fasthttp.StatusForbidden, "Forbidden")
+		return false
+	}
+	return true
+}
+
+// isRouteAllowedForNamespaceAdmin checks if a namespace admin may call the given route
+func isRouteAllowedForNamespaceAdmin(path string) bool {
+	if !strings.HasPrefix(path, "/api/") {
+		// Inference and health routes are not namespace restricted, websocket log streaming is
+		return !strings.HasPrefix(path, "/ws")
+	}
+	for _, prefix := range namespaceAdminAllowedRoutePrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	for _, route := range namespaceAdminAllowedRoutes {
+		if path == route {
+			return true
+		}
+	}
+	return false
+}
+
+// NamespaceManager keeps the in-memory namespace state, such as payload encryption keys, in sync with the config store
+type NamespaceManager interface {
+	ReloadNamespace(ctx context.Context, name string) error
+	RemoveNamespace(ctx context.Context, name string) error
+}
+
+// NamespaceHandler manages HTTP requests for namespace operations
+type NamespaceHandler struct {
+	configStore      configstore.ConfigStore
+	namespaceManager NamespaceManager
+}
+
+// NewNamespaceHandler creates a new namespace handler instance
+func NewNamespaceHandler(manager NamespaceManager, configStore configstore.ConfigStore) (*NamespaceHandler, error) {
+	if configStore == nil {
+		return nil, fmt.Errorf("config store is required")
+	}
+	return &NamespaceHandler{
+		configStore:      configStore,
+		namespaceManager: manager,
+	}, nil
+}
+
+// CreateNamespaceRequest represents the request body for creating a namespace
+type CreateNamespaceRequest struct {
+	Name          string `json:"name" validate:"required"`
+	Description   string `json:"description,omitempty"`
+	AdminUsername string `json:"admin_username" validate:"required"`
+	AdminPassword string `json:"admin_password" validate:"required"`
+
+	PayloadEncryptionKeyRef *string `json:"payload_encryption_key_ref,omitempty"` // e.g. env://TENANT_KEK
+}
+
+// UpdateNamespaceRequest represents the request body for updating a namespace
+type UpdateNamespaceRequest struct {
+	Description   *string `json:"description,omitempty"`
+	AdminUsername *string `json:"admin_username,omitempty"`
+	AdminPassword *string `json:"admin_password,omitempty"`
+
+	PayloadEncryptionKeyRef *string `json:"payload_encryption_key_ref,omitempty"` // Empty string stops encrypting new payloads
+}
+
+// RegisterRoutes registers all namespace management routes
+func (h *NamespaceHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
+	r.GET("/api/namespaces", lib.ChainMiddlewares(h.getNamespaces, middlewares...))
+	r.POST("/api/namespaces", lib.ChainMiddlewares(h.createNamespace, middlewares...))
+	r.GET("/api/namespaces/{name}", lib.ChainMiddlewares(h.getNamespace, middlewares...))
+	r.PUT("/api/namespaces/{name}", lib.ChainMiddlewares(h.updateNamespace, middlewares...))
+	r.DELETE("/api/namespaces/{name}", lib.ChainMiddlewares(h.deleteNamespace, middlewares...))
+	r.POST("/api/namespaces/{name}/revoke-payload-key", lib.ChainMiddlewares(h.revokePayloadKey, middlewares...))
+}
+
+// getNamespaces handles GET /api/namespaces - Get all namespaces
+func (h *NamespaceHandler) getNamespaces(ctx *fasthttp.RequestCtx) {
+	namespaces, err := h.configStore.GetNamespaces(ctx)
+	if err != nil {
+		logger.Error("failed to retrieve namespaces: %v", err)
+		SendError(ctx, 500, "Failed to retrieve namespaces")
+		return
+	}
+	SendJSON(ctx, map[string]interface{}{
+		"namespaces": namespaces,
+		"count":      len(namespaces),
+	})
+}
+
+// createNamespace handles POST /api/namespaces - Create a new namespace
+func (h *NamespaceHandler) createNamespace(ctx *fasthttp.RequestCtx) {
+	var req CreateNamespaceRequest
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		SendError(ctx, 400, "Invalid JSON")
+		return
+	}
+	if req.Name == "" {
+		SendError(ctx, 400, "Namespace name is required")
+		return
+	}
+	if strings.ContainsAny(req.Name, namespaceProviderSeparator+"/") {
+		SendError(ctx, 400, fmt.Sprintf("Namespace name cannot contain %q or \"/\"", namespaceProviderSeparator))
+		return
+	}
+	if req.AdminUsername == "" || req.AdminPassword == "" {
+		SendError(ctx, 400, "Namespace admin username and password are required")
+		return
+	}
+	if req.PayloadEncryptionKeyRef != nil && *req.PayloadEncryptionKeyRef == "" {
+		req.PayloadEncryptionKeyRef = nil
+	}
+	if req.PayloadEncryptionKeyRef != nil {
+		if err := encrypt.ValidateKeyRef(*req.PayloadEncryptionKeyRef); err != nil {
+			SendError(ctx, 400, fmt.Sprintf("Invalid payload encryption key reference: %v", err))
+			return
+		}
+		if encrypt.IsKeyRefRevoked(*req.PayloadEncryptionKeyRef) {
+			SendError(ctx, 400, "Payload encryption key reference is revoked")
+			return
+		}
+	}
+	hashedPassword, err := encrypt.Hash(req.AdminPassword)
+	if err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to hash admin password: %v", err))
+		return
+	}
+	namespace := configstoreTables.TableNamespace{
+		ID:            uuid.NewString(),
+		Name:          req.Name,
+		Description:   req.Description,
+		AdminUsername: req.AdminUsername,
+		AdminPassword: hashedPassword,
+
+		PayloadEncryptionKeyRef: req.PayloadEncryptionKeyRef,
+	}
+	if err := h.configStore.CreateNamespace(ctx, &namespace); err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			SendError(ctx, 409, err.Error())
+			return
+		}
+		SendError(ctx, 500, fmt.Sprintf("Failed to create namespace: %v", err))
+		return
+	}
+	if err := h.reloadNamespace(ctx, namespace.Name); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to reload namespace: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message":   "Namespace created successfully",
+		"namespace": namespace,
+	})
+}
+
+// getNamespace handles GET /api/namespaces/{name} - Get a specific namespace
+func (h *NamespaceHandler) getNamespace(ctx *fasthttp.RequestCtx) {
+	name := ctx.UserValue("name").(string)
+	namespace, err := h.configStore.GetNamespace(ctx, name)
+	if err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Namespace not found")
+			return
+		}
+		SendError(ctx, 500, "Failed to retrieve namespace")
+		return
+	}
+	SendJSON(ctx, map[string]interface{}{
+		"namespace": namespace,
+	})
+}
+
+// updateNamespace handles PUT /api/namespaces/{name} - Update a namespace
+func (h *NamespaceHandler) updateNamespace(ctx *fasthttp.RequestCtx) {
+	name := ctx.UserValue("name").(string)
+	var req UpdateNamespaceRequest
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		SendError(ctx, 400, "Invalid JSON")
+		return
+	}
+	namespace, err := h.configStore.GetNamespace(ctx, name)
+	if err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Namespace not found")
+			return
+		}
+		SendError(ctx, 500, "Failed to retrieve namespace")
+		return
+	}
+	if req.Description != nil {
+		namespace.Description = *req.Description
+	}
+	if req.AdminUsername != nil {
+		if *req.AdminUsername == "" {
+			SendError(ctx, 400, "Namespace admin username cannot be empty")
+			return
+		}
+		namespace.AdminUsername = *req.AdminUsername
+	}
+	if req.AdminPassword != nil && *req.AdminPassword != "" {
+		hashedPassword, err := encrypt.Hash(*req.AdminPassword)
+		if err != nil {
+			SendError(ctx, 500, fmt.Sprintf("Failed to hash admin password: %v", err))
+			return
+		}
+		namespace.AdminPassword = hashedPassword
+	}
+	if req.PayloadEncryptionKeyRef != nil {
+		keyRef := *req.PayloadEncryptionKeyRef
+		currentKeyRef := ""
+		if namespace.PayloadEncryptionKeyRef != nil {
+			currentKeyRef = *namespace.PayloadEncryptionKeyRef
+		}
+		if keyRef != currentKeyRef {
+			if keyRef == "" {
+				namespace.PayloadEncryptionKeyRef = nil
+			} else {
+				if err := encrypt.ValidateKeyRef(keyRef); err != nil {
+					SendError(ctx, 400, fmt.Sprintf("Invalid payload encryption key reference: %v", err))
+					return
+				}
+				if encrypt.IsKeyRefRevoked(keyRef) {
+					SendError(ctx, 400, "Payload encryption key reference is revoked")
+					return
+				}
+				namespace.PayloadEncryptionKeyRef = &keyRef
+			}
+			// A new key starts unrevoked, payloads encrypted with the revoked key stay unreadable
+			namespace.PayloadKeyRevokedAt = nil
+		}
+	}
+	if err := h.configStore.UpdateNamespace(ctx, namespace); err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			SendError(ctx, 409, err.Error())
+			return
+		}
+		SendError(ctx, 500, fmt.Sprintf("Failed to update namespace: %v", err))
+		return
+	}
+	if err := h.reloadNamespace(ctx, namespace.Name); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to reload namespace: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message":   "Namespace updated successfully",
+		"namespace": namespace,
+	})
+}
+
+// deleteNamespace handles DELETE /api/namespaces/{name} - Delete a namespace
+func (h *NamespaceHandler) deleteNamespace(ctx *fasthttp.RequestCtx) {
+	name := ctx.UserValue("name").(string)
+	if err := h.configStore.DeleteNamespace(ctx, name); err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Namespace not found")
+			return
+		}
+		SendError(ctx, 500, "Failed to delete namespace")
+		return
+	}
+	if h.namespaceManager != nil {
+		if err := h.namespaceManager.RemoveNamespace(ctx, name); err != nil {
+			logger.Warn("failed to remove namespace %s from memory: %v", name, err)
+		}
+	}
+	SendJSON(ctx, map[string]interface{}{
+		"message": "Namespace deleted successfully",
+	})
+}
+
+// revokePayloadKey handles POST /api/namespaces/{name}/revoke-payload-key - Revoke the payload encryption key of a namespace.
+// Stored payloads encrypted with the key become unreadable and new payloads of the namespace are no longer stored.
+// The other replicas pick the revocation up from the config store within a minute. The key should also be disabled
+// in the KMS, so that payloads copied out of the logs store cannot be decrypted with it either.
+func (h *NamespaceHandler) revokePayloadKey(ctx *fasthttp.RequestCtx) {
+	name := ctx.UserValue("name").(string)
+	namespace, err := h.configStore.GetNamespace(ctx, name)
+	if err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Namespace not found")
+			return
+		}
+		SendError(ctx, 500, "Failed to retrieve namespace")
+		return
+	}
+	if namespace.PayloadEncryptionKeyRef == nil {
+		SendError(ctx, 400, "Namespace has no payload encryption key")
+		return
+	}
+	if namespace.PayloadKeyRevoked() {
+		SendError(ctx, 400, "Payload encryption key is already revoked")
+		return
+	}
+	now := time.Now()
+	namespace.PayloadKeyRevokedAt = &now
+	// The revoked key reference is recorded on its own, so that it stays revoked on every replica once the
+	// namespace moves to another key
+	if err := h.configStore.ExecuteTransaction(ctx, func(tx *gorm.DB) error {
+		if err := h.configStore.UpdateNamespace(ctx, namespace, tx); err != nil {
+			return err
+		}
+		return h.configStore.AddRevokedPayloadKey(ctx, &configstoreTables.TableRevokedPayloadKey{
+			KeyRef:    *namespace.PayloadEncryptionKeyRef,
+			Namespace: namespace.Name,
+			RevokedAt: now,
+		}, tx)
+	}); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to revoke payload encryption key: %v", err))
+		return
+	}
+	if err := h.reloadNamespace(ctx, namespace.Name); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to reload namespace: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message":   "Payload encryption key revoked successfully",
+		"namespace": namespace,
+	})
+}
+
+// reloadNamespace syncs the in-memory state of a namespace after it was written to the config store
+func (h *NamespaceHandler) reloadNamespace(ctx context.Context, name string) error {
+	if h.namespaceManager == nil {
+		return nil
+	}
+	return h.namespaceManager.ReloadNamespace(ctx, name)
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// newNamespaceRequestCtx creates a request of the admin of a namespace, or of the root admin when namespace is empty
+func newNamespaceRequestCtx(namespace string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	if namespace != "" {
+		ctx.SetUserValue(namespaceUserValueKey, namespace)
+	}
+	return ctx
+}
+
+func TestCanAccessNamespace(t *testing.T) {
+	tests := map[string]struct {
+		requestNamespace string
+		namespace        string
+		expected         bool
+	}{
+		"root admin, other namespace":       {requestNamespace: "", namespace: "acme", expected: true},
+		"root admin, default namespace":     {requestNamespace: "", namespace: "", expected: true},
+		"namespace admin, own namespace":    {requestNamespace: "acme", namespace: "acme", expected: true},
+		"namespace admin, other namespace":  {requestNamespace: "acme", namespace: "globex", expected: false},
+		"namespace admin, default":          {requestNamespace: "acme", namespace: "default", expected: false},
+		"namespace admin, unset namespace":  {requestNamespace: "acme", namespace: "", expected: false},
+		"default admin, unset namespace":    {requestNamespace: "default", namespace: "", expected: true},
+		"namespace admin, prefix namespace": {requestNamespace: "acme", namespace: "acme-eu", expected: false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := canAccessNamespace(newNamespaceRequestCtx(test.requestNamespace), test.namespace); got != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestResolveNamespaceForCreate(t *testing.T) {
+	namespace := func(name string) *string { return &name }
+
+	tests := map[string]struct {
+		requestNamespace string
+		requested        *string
+		expected         string
+		expectErr        bool
+	}{
+		"root admin, no namespace":         {requested: nil, expected: "default"},
+		"root admin, empty namespace":      {requested: namespace(""), expected: "default"},
+		"root admin, any namespace":        {requested: namespace("globex"), expected: "globex"},
+		"namespace admin, no namespace":    {requestNamespace: "acme", requested: nil, expected: "acme"},
+		"namespace admin, own namespace":   {requestNamespace: "acme", requested: namespace("acme"), expected: "acme"},
+		"namespace admin, other namespace": {requestNamespace: "acme", requested: namespace("globex"), expectErr: true},
+		"namespace admin, default":         {requestNamespace: "acme", requested: namespace("default"), expectErr: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := resolveNamespaceForCreate(newNamespaceRequestCtx(test.requestNamespace), test.requested)
+			if test.expectErr {
+				if err == nil {
+					t.Errorf("expected an error, got namespace %q", got)
+				}
+				return
+			}
+			if err != nil || got != test.expected {
+				t.Errorf("expected namespace %q, got %q (%v)", test.expected, got, err)
+			}
+		})
+	}
+}
+
+func TestIsRouteAllowedForNamespaceAdmin(t *testing.T) {
+	tests := map[string]bool{
+		"/api/providers":                  true,
+		"/api/providers/acme.openai":      true,
+		"/api/providers/acme.openai/keys": true,
+		"/api/keys":                       true,
+		"/api/keys/import":                true,
+		"/api/models":                     true,
+		"/api/governance/virtual-keys":    true,
+		"/api/session/logout":             true,
+		"/api/version":                    true,
+		"/api/logs":                       true,
+		"/api/logs/stats":                 true,
+		"/v1/chat/completions":            true,
+		"/health":                         true,
+		"/api/logs/replay":                false,
+		"/api/logs/export":                false,
+		"/api/config":                     false,
+		"/api/plugins":                    false,
+		"/api/mcp/clients":                false,
+		"/api/namespaces":                 false,
+		"/api/namespaces/acme":            false,
+		"/api/governance":                 false,
+		"/ws":                             false,
+		"/ws/logs":                        false,
+	}
+	for path, expected := range tests {
+		t.Run(path, func(t *testing.T) {
+			if got := isRouteAllowedForNamespaceAdmin(path); got != expected {
+				t.Errorf("expected %v, got %v", expected, got)
+			}
+		})
+	}
+}
+
+func TestCheckProviderNameForCreate(t *testing.T) {
+	tests := map[string]struct {
+		requestNamespace string
+		provider         string
+		expectErr        bool
+	}{
+		"root admin, standard provider":       {provider: "openai"},
+		"root admin, custom provider":         {provider: "acme.openai"},
+		"namespace admin, own prefix":         {requestNamespace: "acme", provider: "acme.openai"},
+		"namespace admin, standard provider":  {requestNamespace: "acme", provider: "openai", expectErr: true},
+		"namespace admin, other prefix":       {requestNamespace: "acme", provider: "globex.openai", expectErr: true},
+		"namespace admin, prefix only":        {requestNamespace: "acme", provider: "acme.", expectErr: true},
+		"namespace admin, unseparated prefix": {requestNamespace: "acme", provider: "acmeopenai", expectErr: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := checkProviderNameForCreate(newNamespaceRequestCtx(test.requestNamespace), test.provider)
+			if (err != nil) != test.expectErr {
+				t.Errorf("expected error %v, got %v", test.expectErr, err)
+			}
+		})
+	}
+}
+
+// staticModelsManager lists the same models for every provider
+type staticModelsManager struct {
+	ModelsManager
+}
+
+func (staticModelsManager) GetModelsForProvider(provider schemas.ModelProvider) []string {
+	return []string{"gpt-4o"}
+}
+
+// TestListModels_FiltersNamespace tests that namespace admins only list the models of the providers of their namespace
+func TestListModels_FiltersNamespace(t *testing.T) {
+	handler := NewProviderHandler(staticModelsManager{}, &lib.Config{Providers: map[schemas.ModelProvider]configstore.ProviderConfig{
+		"openai":        {},
+		"acme.openai":   {Namespace: "acme"},
+		"globex.openai": {Namespace: "globex"},
+	}}, nil)
+
+	tests := map[string]struct {
+		requestNamespace string
+		provider         string
+		expected         []string
+	}{
+		"root admin":                        {expected: []string{"acme.openai", "globex.openai", "openai"}},
+		"namespace admin":                   {requestNamespace: "acme", expected: []string{"acme.openai"}},
+		"namespace admin, own provider":     {requestNamespace: "acme", provider: "acme.openai", expected: []string{"acme.openai"}},
+		"namespace admin, other provider":   {requestNamespace: "acme", provider: "globex.openai"},
+		"namespace admin, default provider": {requestNamespace: "acme", provider: "openai"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := newNamespaceRequestCtx(test.requestNamespace)
+			ctx.QueryArgs().Set("limit", "0")
+			if test.provider != "" {
+				ctx.QueryArgs().Set("provider", test.provider)
+			}
+			handler.listModels(ctx)
+
+			var response ListModelsResponse
+			if err := json.Unmarshal(ctx.Response.Body(), &response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			providers := make(map[string]bool)
+			for _, model := range response.Models {
+				providers[model.Provider] = true
+			}
+			if len(providers) != len(test.expected) {
+				t.Fatalf("expected the models of %v, got %v", test.expected, response.Models)
+			}
+			for _, provider := range test.expected {
+				if !providers[provider] {
+					t.Errorf("expected the models of %s, got %v", provider, response.Models)
+				}
+			}
+		})
+	}
+}
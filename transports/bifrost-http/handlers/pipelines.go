@@ -0,0 +1,278 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the transformation pipeline management handlers.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fasthttp/router"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// PipelineManager applies the pipelines of the config store to the bifrost client
+type PipelineManager interface {
+	ReloadPipelines(ctx context.Context) error
+	GetEffectivePlugins(provider schemas.ModelProvider, model string, virtualKeyID string) ([]string, string, error)
+}
+
+// PipelinesHandler manages HTTP requests for transformation pipeline operations
+type PipelinesHandler struct {
+	configStore     configstore.ConfigStore
+	pipelineManager PipelineManager
+}
+
+// NewPipelinesHandler creates a new pipelines handler instance
+func NewPipelinesHandler(manager PipelineManager, configStore configstore.ConfigStore) (*PipelinesHandler, error) {
+	if configStore == nil {
+		return nil, fmt.Errorf("config store is required")
+	}
+	return &PipelinesHandler{
+		configStore:     configStore,
+		pipelineManager: manager,
+	}, nil
+}
+
+// UpsertPipelineRequest represents the request body for creating or updating a pipeline
+type UpsertPipelineRequest struct {
+	Name          string                  `json:"name,omitempty"` // Only read on create, the name of a pipeline cannot change
+	Description   string                  `json:"description,omitempty"`
+	Stages        []schemas.PipelineStage `json:"stages"`
+	Models        []string                `json:"models,omitempty"`
+	VirtualKeyIDs []string                `json:"virtual_key_ids,omitempty"`
+}
+
+// PipelineRoute is the effective pipeline of a route, i.e. a virtual key or requested model a pipeline is attached to
+type PipelineRoute struct {
+	Route        string   `json:"route"` // "virtual_key:<id>", "model:<model>" or "default"
+	Pipeline     string   `json:"pipeline,omitempty"`
+	Plugins      []string `json:"plugins"`         // Plugins running for the route, in PreHook order
+	Error        string   `json:"error,omitempty"` // Set when requests of the route are rejected
+	VirtualKeyID string   `json:"virtual_key_id,omitempty"`
+	Model        string   `json:"model,omitempty"`
+}
+
+// RegisterRoutes registers all pipeline management routes
+func (h *PipelinesHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
+	r.GET("/api/pipelines", lib.ChainMiddlewares(h.getPipelines, middlewares...))
+	r.POST("/api/pipelines", lib.ChainMiddlewares(h.createPipeline, middlewares...))
+	r.GET("/api/pipelines/routes", lib.ChainMiddlewares(h.getPipelineRoutes, middlewares...))
+	r.GET("/api/pipelines/{name}", lib.ChainMiddlewares(h.getPipeline, middlewares...))
+	r.PUT("/api/pipelines/{name}", lib.ChainMiddlewares(h.updatePipeline, middlewares...))
+	r.DELETE("/api/pipelines/{name}", lib.ChainMiddlewares(h.deletePipeline, middlewares...))
+}
+
+// getPipelines handles GET /api/pipelines - Get all pipelines
+func (h *PipelinesHandler) getPipelines(ctx *fasthttp.RequestCtx) {
+	pipelines, err := h.configStore.GetPipelines(ctx)
+	if err != nil {
+		logger.Error("failed to retrieve pipelines: %v", err)
+		SendError(ctx, 500, "Failed to retrieve pipelines")
+		return
+	}
+	SendJSON(ctx, map[string]interface{}{
+		"pipelines": pipelines,
+		"count":     len(pipelines),
+	})
+}
+
+// createPipeline handles POST /api/pipelines - Create a new pipeline
+func (h *PipelinesHandler) createPipeline(ctx *fasthttp.RequestCtx) {
+	var req UpsertPipelineRequest
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		SendError(ctx, 400, "Invalid JSON")
+		return
+	}
+	pipeline := configstoreTables.TablePipeline{
+		Name:          req.Name,
+		Description:   req.Description,
+		Stages:        req.Stages,
+		Models:        req.Models,
+		VirtualKeyIDs: req.VirtualKeyIDs,
+	}
+	if pipeline.Name == "routes" {
+		SendError(ctx, 400, "Pipeline name routes is reserved")
+		return
+	}
+	if err := h.validatePipeline(ctx, &pipeline); err != nil {
+		SendError(ctx, 400, err.Error())
+		return
+	}
+	if err := h.configStore.CreatePipeline(ctx, &pipeline); err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			SendError(ctx, 409, err.Error())
+			return
+		}
+		SendError(ctx, 500, fmt.Sprintf("Failed to create pipeline: %v", err))
+		return
+	}
+	if err := h.pipelineManager.ReloadPipelines(ctx); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to reload pipelines: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message":  "Pipeline created successfully",
+		"pipeline": pipeline,
+	})
+}
+
+// getPipeline handles GET /api/pipelines/{name} - Get a specific pipeline
+func (h *PipelinesHandler) getPipeline(ctx *fasthttp.RequestCtx) {
+	name := ctx.UserValue("name").(string)
+	pipeline, err := h.configStore.GetPipeline(ctx, name)
+	if err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Pipeline not found")
+			return
+		}
+		SendError(ctx, 500, "Failed to retrieve pipeline")
+		return
+	}
+	SendJSON(ctx, map[string]interface{}{
+		"pipeline": pipeline,
+	})
+}
+
+// updatePipeline handles PUT /api/pipelines/{name} - Replace the stages and attachments of a pipeline
+func (h *PipelinesHandler) updatePipeline(ctx *fasthttp.RequestCtx) {
+	name := ctx.UserValue("name").(string)
+	var req UpsertPipelineRequest
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		SendError(ctx, 400, "Invalid JSON")
+		return
+	}
+	pipeline, err := h.configStore.GetPipeline(ctx, name)
+	if err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Pipeline not found")
+			return
+		}
+		SendError(ctx, 500, "Failed to retrieve pipeline")
+		return
+	}
+	pipeline.Description = req.Description
+	pipeline.Stages = req.Stages
+	pipeline.Models = req.Models
+	pipeline.VirtualKeyIDs = req.VirtualKeyIDs
+	if err := h.validatePipeline(ctx, pipeline); err != nil {
+		SendError(ctx, 400, err.Error())
+		return
+	}
+	if err := h.configStore.UpdatePipeline(ctx, pipeline); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to update pipeline: %v", err))
+		return
+	}
+	if err := h.pipelineManager.ReloadPipelines(ctx); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to reload pipelines: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message":  "Pipeline updated successfully",
+		"pipeline": pipeline,
+	})
+}
+
+// deletePipeline handles DELETE /api/pipelines/{name} - Delete a pipeline, its routes run all plugins again
+func (h *PipelinesHandler) deletePipeline(ctx *fasthttp.RequestCtx) {
+	name := ctx.UserValue("name").(string)
+	if err := h.configStore.DeletePipeline(ctx, name); err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Pipeline not found")
+			return
+		}
+		SendError(ctx, 500, fmt.Sprintf("Failed to delete pipeline: %v", err))
+		return
+	}
+	if err := h.pipelineManager.ReloadPipelines(ctx); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to reload pipelines: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message": "Pipeline deleted successfully",
+	})
+}
+
+// getPipelineRoutes handles GET /api/pipelines/routes - Get the effective plugins of every route a pipeline is attached to.
+// The model and virtual_key_id query parameters evaluate a single route instead.
+func (h *PipelinesHandler) getPipelineRoutes(ctx *fasthttp.RequestCtx) {
+	model := string(ctx.QueryArgs().Peek("model"))
+	virtualKeyID := string(ctx.QueryArgs().Peek("virtual_key_id"))
+	if model != "" || virtualKeyID != "" {
+		SendJSON(ctx, map[string]any{
+			"route": h.getPipelineRoute(virtualKeyID, model),
+		})
+		return
+	}
+
+	pipelines, err := h.configStore.GetPipelines(ctx)
+	if err != nil {
+		logger.Error("failed to retrieve pipelines: %v", err)
+		SendError(ctx, 500, "Failed to retrieve pipelines")
+		return
+	}
+	routes := []PipelineRoute{h.getPipelineRoute("", "")}
+	for _, pipeline := range pipelines {
+		for _, vkID := range pipeline.VirtualKeyIDs {
+			routes = append(routes, h.getPipelineRoute(vkID, ""))
+		}
+		for _, model := range pipeline.Models {
+			routes = append(routes, h.getPipelineRoute("", model))
+		}
+	}
+	SendJSON(ctx, map[string]any{
+		"routes": routes,
+		"count":  len(routes),
+	})
+}
+
+// getPipelineRoute returns the effective pipeline of a route
+func (h *PipelinesHandler) getPipelineRoute(virtualKeyID string, model string) PipelineRoute {
+	route := PipelineRoute{Route: "default", VirtualKeyID: virtualKeyID, Model: model}
+	if virtualKeyID != "" {
+		route.Route = "virtual_key:" + virtualKeyID
+	} else if model != "" {
+		route.Route = "model:" + model
+	}
+	provider, modelName := schemas.ParseModelString(model, "")
+	plugins, pipeline, err := h.pipelineManager.GetEffectivePlugins(provider, modelName, virtualKeyID)
+	route.Plugins = plugins
+	route.Pipeline = pipeline
+	if err != nil {
+		route.Error = err.Error()
+	}
+	return route
+}
+
+// validatePipeline validates the pipeline on its own and together with the other pipelines,
+// so that no model or virtual key ends up attached to two pipelines
+func (h *PipelinesHandler) validatePipeline(ctx context.Context, pipeline *configstoreTables.TablePipeline) error {
+	existing, err := h.configStore.GetPipelines(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve pipelines: %v", err)
+	}
+	pipelines := []schemas.Pipeline{pipeline.ToSchema()}
+	for i := range existing {
+		if existing[i].Name != pipeline.Name {
+			pipelines = append(pipelines, existing[i].ToSchema())
+		}
+	}
+	if err := schemas.ValidatePipelines(pipelines); err != nil {
+		return err
+	}
+	for _, vkID := range pipeline.VirtualKeyIDs {
+		if _, err := h.configStore.GetVirtualKey(ctx, vkID); err != nil {
+			if errors.Is(err, configstore.ErrNotFound) {
+				return fmt.Errorf("virtual key %s not found", vkID)
+			}
+			return fmt.Errorf("failed to retrieve virtual key %s: %v", vkID, err)
+		}
+	}
+	return nil
+}
@@ -17,6 +17,7 @@ import (
 type PluginsLoader interface {
 	ReloadPlugin(ctx context.Context, name string, path *string, pluginConfig any) error
 	RemovePlugin(ctx context.Context, name string) error
+	UpdatePluginExecution(ctx context.Context, name string, execution *schemas.PluginExecutionConfig) error
 	GetPluginStatus(ctx context.Context) []schemas.PluginStatus
 }
 
@@ -36,17 +37,19 @@ func NewPluginsHandler(pluginsLoader PluginsLoader, configStore configstore.Conf
 
 // CreatePluginRequest is the request body for creating a plugin
 type CreatePluginRequest struct {
-	Name    string         `json:"name"`
-	Enabled bool           `json:"enabled"`
-	Config  map[string]any `json:"config"`
-	Path    *string        `json:"path"`
+	Name      string                         `json:"name" validate:"required"`
+	Enabled   bool                           `json:"enabled"`
+	Config    map[string]any                 `json:"config"`
+	Path      *string                        `json:"path"`
+	Execution *schemas.PluginExecutionConfig `json:"execution,omitempty"`
 }
 
 // UpdatePluginRequest is the request body for updating a plugin
 type UpdatePluginRequest struct {
-	Enabled bool           `json:"enabled"`
-	Path    *string        `json:"path"`
-	Config  map[string]any `json:"config"`
+	Enabled   bool                           `json:"enabled"`
+	Path      *string                        `json:"path"`
+	Config    map[string]any                 `json:"config"`
+	Execution *schemas.PluginExecutionConfig `json:"execution,omitempty"`
 }
 
 // RegisterRoutes registers the routes for the PluginsHandler
@@ -103,12 +106,13 @@ func (h *PluginsHandler) getPlugins(ctx *fasthttp.RequestCtx) {
 	pluginStatus := h.pluginsLoader.GetPluginStatus(ctx)
 	// Creating ephemeral struct for the plugins
 	finalPlugins := []struct {
-		Name     string               `json:"name"`
-		Enabled  bool                 `json:"enabled"`
-		Config   any                  `json:"config"`
-		IsCustom bool                 `json:"isCustom"`
-		Path     *string              `json:"path"`
-		Status   schemas.PluginStatus `json:"status"`
+		Name      string                         `json:"name"`
+		Enabled   bool                           `json:"enabled"`
+		Config    any                            `json:"config"`
+		IsCustom  bool                           `json:"isCustom"`
+		Path      *string                        `json:"path"`
+		Execution *schemas.PluginExecutionConfig `json:"execution,omitempty"`
+		Status    schemas.PluginStatus           `json:"status"`
 	}{}
 	// Iterating over plugin status to get the plugin info
 	for _, pluginStatus := range pluginStatus {
@@ -123,19 +127,21 @@ func (h *PluginsHandler) getPlugins(ctx *fasthttp.RequestCtx) {
 			continue
 		}
 		finalPlugins = append(finalPlugins, struct {
-			Name     string               `json:"name"`
-			Enabled  bool                 `json:"enabled"`
-			Config   any                  `json:"config"`
-			IsCustom bool                 `json:"isCustom"`
-			Path     *string              `json:"path"`
-			Status   schemas.PluginStatus `json:"status"`
+			Name      string                         `json:"name"`
+			Enabled   bool                           `json:"enabled"`
+			Config    any                            `json:"config"`
+			IsCustom  bool                           `json:"isCustom"`
+			Path      *string                        `json:"path"`
+			Execution *schemas.PluginExecutionConfig `json:"execution,omitempty"`
+			Status    schemas.PluginStatus           `json:"status"`
 		}{
-			Name:     pluginInfo.Name,
-			Enabled:  pluginInfo.Enabled,
-			Config:   pluginInfo.Config,
-			IsCustom: pluginInfo.IsCustom,
-			Path:     pluginInfo.Path,
-			Status:   pluginStatus,
+			Name:      pluginInfo.Name,
+			Enabled:   pluginInfo.Enabled,
+			Config:    pluginInfo.Config,
+			IsCustom:  pluginInfo.IsCustom,
+			Path:      pluginInfo.Path,
+			Execution: pluginInfo.Execution,
+			Status:    pluginStatus,
 		})
 	}
 	// Creating ephemeral struct
@@ -220,6 +226,9 @@ func (h *PluginsHandler) createPlugin(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, 400, "Plugins creation is  not supported when configstore is disabled")
 		return
 	}
+	if !validateConfigPayload(ctx, "plugin") {
+		return
+	}
 	var request CreatePluginRequest
 	if err := json.Unmarshal(ctx.PostBody(), &request); err != nil {
 		logger.Error("failed to unmarshal create plugin request: %v", err)
@@ -231,6 +240,10 @@ func (h *PluginsHandler) createPlugin(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, fasthttp.StatusBadRequest, "Plugin name is required")
 		return
 	}
+	if err := validatePluginExecutionConfig(request.Execution); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
 	// Check if plugin already exists
 	existingPlugin, err := h.configStore.GetPlugin(ctx, request.Name)
 	if err == nil && existingPlugin != nil {
@@ -238,11 +251,12 @@ func (h *PluginsHandler) createPlugin(ctx *fasthttp.RequestCtx) {
 		return
 	}
 	if err := h.configStore.CreatePlugin(ctx, &configstoreTables.TablePlugin{
-		Name:     request.Name,
-		Enabled:  request.Enabled,
-		Config:   request.Config,
-		Path:     request.Path,
-		IsCustom: true,
+		Name:      request.Name,
+		Enabled:   request.Enabled,
+		Config:    request.Config,
+		Path:      request.Path,
+		IsCustom:  true,
+		Execution: request.Execution,
 	}); err != nil {
 		logger.Error("failed to create plugin: %v", err)
 		SendError(ctx, 500, "Failed to create plugin")
@@ -258,6 +272,9 @@ func (h *PluginsHandler) createPlugin(ctx *fasthttp.RequestCtx) {
 
 	// We reload the plugin if its enabled
 	if request.Enabled {
+		if err := h.pluginsLoader.UpdatePluginExecution(ctx, request.Name, request.Execution); err != nil {
+			logger.Error("failed to update plugin execution limits: %v", err)
+		}
 		if err := h.pluginsLoader.ReloadPlugin(ctx, request.Name, request.Path, request.Config); err != nil {
 			logger.Error("failed to load plugin: %v", err)
 			SendJSON(ctx, map[string]any{
@@ -281,6 +298,9 @@ func (h *PluginsHandler) updatePlugin(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, 400, "Plugins update is not supported when configstore is disabled")
 		return
 	}
+	if !validateConfigPayload(ctx, "plugin_update") {
+		return
+	}
 	// Safely validate the "name" parameter
 	nameValue := ctx.UserValue("name")
 	if nameValue == nil {
@@ -334,14 +354,19 @@ func (h *PluginsHandler) updatePlugin(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, 400, "Invalid request body")
 		return
 	}
+	if err := validatePluginExecutionConfig(request.Execution); err != nil {
+		SendError(ctx, 400, err.Error())
+		return
+	}
 
 	// Updating the plugin
 	if err := h.configStore.UpdatePlugin(ctx, &configstoreTables.TablePlugin{
-		Name:     name,
-		Enabled:  request.Enabled,
-		Config:   request.Config,
-		Path:     request.Path,
-		IsCustom: plugin.IsCustom,
+		Name:      name,
+		Enabled:   request.Enabled,
+		Config:    request.Config,
+		Path:      request.Path,
+		IsCustom:  plugin.IsCustom,
+		Execution: request.Execution,
 	}); err != nil {
 		logger.Error("failed to update plugin: %v", err)
 		SendError(ctx, 500, "Failed to update plugin")
@@ -358,6 +383,10 @@ func (h *PluginsHandler) updatePlugin(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, 500, "Failed to retrieve plugin")
 		return
 	}
+	// Execution limits are applied before the plugin is reloaded, so the new instance never runs unbounded
+	if err := h.pluginsLoader.UpdatePluginExecution(ctx, name, request.Execution); err != nil {
+		logger.Error("failed to update plugin execution limits: %v", err)
+	}
 	// We reload the plugin if its enabled, otherwise we stop it
 	if request.Enabled {
 		if err := h.pluginsLoader.ReloadPlugin(ctx, name, request.Path, request.Config); err != nil {
@@ -423,6 +452,9 @@ func (h *PluginsHandler) deletePlugin(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	if err := h.pluginsLoader.UpdatePluginExecution(ctx, name, nil); err != nil {
+		logger.Error("failed to remove plugin execution limits: %v", err)
+	}
 	if err := h.pluginsLoader.RemovePlugin(ctx, name); err != nil {
 		logger.Error("failed to stop plugin: %v", err)
 		SendJSON(ctx, map[string]any{
@@ -436,3 +468,19 @@ func (h *PluginsHandler) deletePlugin(ctx *fasthttp.RequestCtx) {
 		"message": "Plugin deleted successfully",
 	})
 }
+
+// validatePluginExecutionConfig validates the execution limits of a plugin
+func validatePluginExecutionConfig(execution *schemas.PluginExecutionConfig) error {
+	if execution == nil {
+		return nil
+	}
+	if execution.MaxConcurrency < 0 || execution.TimeoutMs < 0 || execution.FailureThreshold < 0 || execution.CooldownMs < 0 {
+		return fmt.Errorf("plugin execution limits cannot be negative")
+	}
+	switch execution.FailureMode {
+	case "", schemas.PluginFailureModeOpen, schemas.PluginFailureModeClosed:
+		return nil
+	default:
+		return fmt.Errorf("invalid plugin failure mode %q, must be %s or %s", execution.FailureMode, schemas.PluginFailureModeOpen, schemas.PluginFailureModeClosed)
+	}
+}
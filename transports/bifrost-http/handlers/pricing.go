@@ -0,0 +1,142 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the model pricing handlers.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/fasthttp/router"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+	"github.com/maximhq/bifrost/framework/modelcatalog"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// PricingManager looks up the prices of models and reloads their overrides from the config store
+type PricingManager interface {
+	GetPricingEntryForModel(model string, provider schemas.ModelProvider) *modelcatalog.PricingEntry
+	ReloadPricingOverrides(ctx context.Context) error
+}
+
+// PricingHandler manages HTTP requests for model pricing
+type PricingHandler struct {
+	configStore    configstore.ConfigStore
+	pricingManager PricingManager
+}
+
+// NewPricingHandler creates a new pricing handler instance
+func NewPricingHandler(manager PricingManager, configStore configstore.ConfigStore) (*PricingHandler, error) {
+	if configStore == nil {
+		return nil, fmt.Errorf("config store is required")
+	}
+	if manager == nil {
+		return nil, fmt.Errorf("pricing manager is required")
+	}
+	return &PricingHandler{
+		configStore:    configStore,
+		pricingManager: manager,
+	}, nil
+}
+
+// RegisterRoutes registers all model pricing routes
+func (h *PricingHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
+	r.GET("/api/pricing", lib.ChainMiddlewares(h.getPricing, middlewares...))
+	r.GET("/api/pricing/overrides", lib.ChainMiddlewares(h.getOverrides, middlewares...))
+	r.PUT("/api/pricing/overrides", lib.ChainMiddlewares(h.upsertOverride, middlewares...))
+	r.DELETE("/api/pricing/overrides", lib.ChainMiddlewares(h.deleteOverride, middlewares...))
+}
+
+// getPricing handles GET /api/pricing?provider=&model= - Get the prices the cost of requests to a model is calculated with
+func (h *PricingHandler) getPricing(ctx *fasthttp.RequestCtx) {
+	provider := string(ctx.QueryArgs().Peek("provider"))
+	model := string(ctx.QueryArgs().Peek("model"))
+	if provider == "" || model == "" {
+		SendError(ctx, 400, "provider and model are required")
+		return
+	}
+	pricing := h.pricingManager.GetPricingEntryForModel(model, schemas.ModelProvider(provider))
+	if pricing == nil {
+		SendError(ctx, 404, fmt.Sprintf("no pricing is known for model %s of provider %s", model, provider))
+		return
+	}
+	SendJSON(ctx, map[string]interface{}{
+		"provider": provider,
+		"model":    model,
+		"pricing":  pricing,
+	})
+}
+
+// getOverrides handles GET /api/pricing/overrides - Get all pricing overrides
+func (h *PricingHandler) getOverrides(ctx *fasthttp.RequestCtx) {
+	overrides, err := h.configStore.GetModelPricingOverrides(ctx)
+	if err != nil {
+		logger.Error("failed to retrieve model pricing overrides: %v", err)
+		SendError(ctx, 500, "Failed to retrieve model pricing overrides")
+		return
+	}
+	SendJSON(ctx, map[string]interface{}{
+		"overrides": overrides,
+		"count":     len(overrides),
+	})
+}
+
+// upsertOverride handles PUT /api/pricing/overrides - Create or replace the pricing override of a model
+func (h *PricingHandler) upsertOverride(ctx *fasthttp.RequestCtx) {
+	var override configstoreTables.TableModelPricingOverride
+	if err := json.Unmarshal(ctx.PostBody(), &override); err != nil {
+		SendError(ctx, 400, "Invalid JSON")
+		return
+	}
+	if override.Provider == "" || override.Model == "" {
+		SendError(ctx, 400, "provider and model are required, use * as the provider to override the model on every provider")
+		return
+	}
+	for _, price := range []*float64{override.InputCostPerToken, override.OutputCostPerToken, override.CacheReadInputTokenCost} {
+		if price != nil && *price < 0 {
+			SendError(ctx, 400, "prices cannot be negative")
+			return
+		}
+	}
+	if err := h.configStore.UpsertModelPricingOverride(ctx, &override); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to save model pricing override: %v", err))
+		return
+	}
+	if err := h.pricingManager.ReloadPricingOverrides(ctx); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to reload model pricing overrides: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message":  "Model pricing override saved successfully",
+		"override": override,
+	})
+}
+
+// deleteOverride handles DELETE /api/pricing/overrides?provider=&model= - Delete the pricing override of a model
+func (h *PricingHandler) deleteOverride(ctx *fasthttp.RequestCtx) {
+	provider := string(ctx.QueryArgs().Peek("provider"))
+	model := string(ctx.QueryArgs().Peek("model"))
+	if provider == "" || model == "" {
+		SendError(ctx, 400, "provider and model are required")
+		return
+	}
+	if err := h.configStore.DeleteModelPricingOverride(ctx, provider, model); err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Model pricing override not found")
+			return
+		}
+		SendError(ctx, 500, fmt.Sprintf("Failed to delete model pricing override: %v", err))
+		return
+	}
+	if err := h.pricingManager.ReloadPricingOverrides(ctx); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to reload model pricing overrides: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message": "Model pricing override deleted successfully",
+	})
+}
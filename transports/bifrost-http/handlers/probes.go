@@ -0,0 +1,62 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the synthetic monitoring probes status handler.
+package handlers
+
+import (
+	"github.com/fasthttp/router"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// ProbesHandler exposes the status of the synthetic monitoring probes
+type ProbesHandler struct {
+	runner *lib.ProbeRunner
+}
+
+// NewProbesHandler creates a new probes handler instance
+func NewProbesHandler(runner *lib.ProbeRunner) *ProbesHandler {
+	return &ProbesHandler{
+		runner: runner,
+	}
+}
+
+// RegisterRoutes registers the probes routes
+func (h *ProbesHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
+	r.GET("/api/probes", lib.ChainMiddlewares(h.getProbes, middlewares...))
+	r.GET("/api/probes/{name}/results", lib.ChainMiddlewares(h.getProbeResults, middlewares...))
+}
+
+// getProbes handles GET /api/probes - Get the SLO, alerting and degradation status of all probes
+func (h *ProbesHandler) getProbes(ctx *fasthttp.RequestCtx) {
+	statuses := h.runner.GetStatuses()
+	alerting, degraded := 0, 0
+	for _, status := range statuses {
+		if status.Alerting {
+			alerting++
+		}
+		if status.Degraded {
+			degraded++
+		}
+	}
+	SendJSON(ctx, map[string]any{
+		"probes":   statuses,
+		"count":    len(statuses),
+		"alerting": alerting,
+		"degraded": degraded,
+	})
+}
+
+// getProbeResults handles GET /api/probes/{name}/results - Get the results of the runs of a probe within its SLO window,
+// by model. The model query parameter selects the results of a single model.
+func (h *ProbesHandler) getProbeResults(ctx *fasthttp.RequestCtx) {
+	name, _ := ctx.UserValue("name").(string)
+	results, ok := h.runner.GetResults(name, string(ctx.QueryArgs().Peek("model")))
+	if !ok {
+		SendError(ctx, fasthttp.StatusNotFound, "probe not found")
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"name":    name,
+		"results": results,
+	})
+}
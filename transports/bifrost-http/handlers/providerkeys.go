@@ -0,0 +1,253 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the management of the keys of a provider as individual resources.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// getProviderKey handles GET /api/providers/{provider}/keys/{key_id} - Get a key of a provider, redacted
+func (h *ProviderHandler) getProviderKey(ctx *fasthttp.RequestCtx) {
+	provider, keyID, ok := getProviderKeyFromCtx(ctx)
+	if !ok {
+		return
+	}
+	config, index, ok := h.findProviderKey(ctx, provider, keyID)
+	if !ok {
+		return
+	}
+	etag := resourceETag(provider, config.Keys[index])
+	if checkNotModified(ctx, etag) {
+		return
+	}
+	key, ok := h.redactedProviderKey(ctx, provider, keyID)
+	if !ok {
+		return
+	}
+	setETag(ctx, etag)
+	SendJSON(ctx, key)
+}
+
+// addProviderKey handles POST /api/providers/{provider}/keys - Add a key to a provider
+func (h *ProviderHandler) addProviderKey(ctx *fasthttp.RequestCtx) {
+	provider, err := getProviderFromCtx(ctx)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid provider: %v", err))
+		return
+	}
+	if !validateConfigPayload(ctx, "key") {
+		return
+	}
+	var key schemas.Key
+	if err := json.Unmarshal(ctx.PostBody(), &key); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid JSON: %v", err))
+		return
+	}
+	if key.ID == "" {
+		key.ID = uuid.NewString()
+	}
+
+	h.writeMu.Lock()
+	defer h.writeMu.Unlock()
+
+	config, err := h.store.GetProviderConfigRaw(provider)
+	if err != nil || !canAccessNamespace(ctx, config.Namespace) {
+		SendError(ctx, fasthttp.StatusNotFound, "Provider not found")
+		return
+	}
+	if slices.ContainsFunc(config.Keys, func(k schemas.Key) bool { return k.ID == key.ID }) {
+		SendError(ctx, fasthttp.StatusConflict, fmt.Sprintf("Key %s already exists", key.ID))
+		return
+	}
+	if err := lib.ValidateSecretReferences(configstore.ProviderConfig{Keys: []schemas.Key{key}}, nil); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid keys: %v", err))
+		return
+	}
+	keys, err := h.mergeKeys(provider, config.Keys, nil, []schemas.Key{key}, nil, nil)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid keys: %v", err))
+		return
+	}
+	if !h.saveProviderKeys(ctx, provider, *config, keys) {
+		return
+	}
+	h.sendProviderKey(ctx, provider, key.ID)
+}
+
+// updateProviderKey handles PUT /api/providers/{provider}/keys/{key_id} - Update a key of a provider
+// Redacted values left unchanged keep the saved values, like when updating the provider.
+func (h *ProviderHandler) updateProviderKey(ctx *fasthttp.RequestCtx) {
+	provider, keyID, ok := getProviderKeyFromCtx(ctx)
+	if !ok {
+		return
+	}
+	if !validateConfigPayload(ctx, "key") {
+		return
+	}
+	var key schemas.Key
+	if err := json.Unmarshal(ctx.PostBody(), &key); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid JSON: %v", err))
+		return
+	}
+	if key.ID != "" && key.ID != keyID {
+		SendError(ctx, fasthttp.StatusBadRequest, "Key id cannot be changed")
+		return
+	}
+	key.ID = keyID
+
+	h.writeMu.Lock()
+	defer h.writeMu.Unlock()
+
+	config, index, ok := h.findProviderKey(ctx, provider, keyID)
+	if !ok {
+		return
+	}
+	if !checkPreconditions(ctx, resourceETag(provider, config.Keys[index])) {
+		return
+	}
+	redactedConfig, err := h.store.GetProviderConfigRedacted(provider)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to get provider config: %v", err))
+		return
+	}
+	if err := lib.ValidateSecretReferences(configstore.ProviderConfig{Keys: []schemas.Key{key}}, redactedConfig); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid keys: %v", err))
+		return
+	}
+	keys, err := h.mergeKeys(provider, config.Keys, redactedConfig.Keys, nil, nil, []schemas.Key{key})
+	if err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid keys: %v", err))
+		return
+	}
+	if !h.saveProviderKeys(ctx, provider, *config, keys) {
+		return
+	}
+	h.sendProviderKey(ctx, provider, keyID)
+}
+
+// deleteProviderKey handles DELETE /api/providers/{provider}/keys/{key_id} - Remove a key from a provider
+func (h *ProviderHandler) deleteProviderKey(ctx *fasthttp.RequestCtx) {
+	provider, keyID, ok := getProviderKeyFromCtx(ctx)
+	if !ok {
+		return
+	}
+
+	h.writeMu.Lock()
+	defer h.writeMu.Unlock()
+
+	config, index, ok := h.findProviderKey(ctx, provider, keyID)
+	if !ok {
+		return
+	}
+	if !checkPreconditions(ctx, resourceETag(provider, config.Keys[index])) {
+		return
+	}
+	keys, err := h.mergeKeys(provider, config.Keys, nil, nil, []schemas.Key{config.Keys[index]}, nil)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid keys: %v", err))
+		return
+	}
+	if !h.saveProviderKeys(ctx, provider, *config, keys) {
+		return
+	}
+
+	SendJSON(ctx, map[string]any{
+		"message": "Key deleted successfully",
+	})
+}
+
+// findProviderKey returns the raw config of a provider and the index of one of its keys, and sends 404 when the
+// provider or the key does not exist or is outside the namespace of the caller
+func (h *ProviderHandler) findProviderKey(ctx *fasthttp.RequestCtx, provider schemas.ModelProvider, keyID string) (*configstore.ProviderConfig, int, bool) {
+	config, err := h.store.GetProviderConfigRaw(provider)
+	if err != nil || !canAccessNamespace(ctx, config.Namespace) {
+		SendError(ctx, fasthttp.StatusNotFound, "Provider not found")
+		return nil, 0, false
+	}
+	index := slices.IndexFunc(config.Keys, func(key schemas.Key) bool { return key.ID == keyID })
+	if index < 0 {
+		SendError(ctx, fasthttp.StatusNotFound, "Key not found")
+		return nil, 0, false
+	}
+	return config, index, true
+}
+
+// redactedProviderKey returns a key of a provider with its secrets redacted
+func (h *ProviderHandler) redactedProviderKey(ctx *fasthttp.RequestCtx, provider schemas.ModelProvider, keyID string) (schemas.Key, bool) {
+	config, err := h.store.GetProviderConfigRedacted(provider)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to get provider config: %v", err))
+		return schemas.Key{}, false
+	}
+	index := slices.IndexFunc(config.Keys, func(key schemas.Key) bool { return key.ID == keyID })
+	if index < 0 {
+		SendError(ctx, fasthttp.StatusNotFound, "Key not found")
+		return schemas.Key{}, false
+	}
+	return config.Keys[index], true
+}
+
+// sendProviderKey sends a saved key of a provider, redacted, with its ETag
+func (h *ProviderHandler) sendProviderKey(ctx *fasthttp.RequestCtx, provider schemas.ModelProvider, keyID string) {
+	key, ok := h.redactedProviderKey(ctx, provider, keyID)
+	if !ok {
+		return
+	}
+	if config, err := h.store.GetProviderConfigRaw(provider); err == nil {
+		if index := slices.IndexFunc(config.Keys, func(k schemas.Key) bool { return k.ID == keyID }); index >= 0 {
+			setETag(ctx, resourceETag(provider, config.Keys[index]))
+		}
+	}
+	SendJSON(ctx, key)
+}
+
+// saveProviderKeys saves the keys of a provider and refreshes its models, and sends the error when they cannot be
+// saved. Returns false when the error was sent.
+func (h *ProviderHandler) saveProviderKeys(ctx *fasthttp.RequestCtx, provider schemas.ModelProvider, config configstore.ProviderConfig, keys []schemas.Key) bool {
+	if err := lib.ValidateKeys(keys); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid keys: %v", err))
+		return false
+	}
+	if err := lib.ValidateEndpoints(ctx, h.store.ClientConfig.EndpointPolicy, config.NetworkConfig, keys); err != nil {
+		SendError(ctx, fasthttp.StatusForbidden, fmt.Sprintf("Endpoint not allowed: %v", err))
+		return false
+	}
+	config.Keys = keys
+	if err := h.store.UpdateProviderConfig(ctx, provider, config); err != nil {
+		logger.Warn(fmt.Sprintf("Failed to update the keys of provider %s: %v", provider, err))
+		SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to update provider: %v", err))
+		return false
+	}
+	if len(keys) > 0 {
+		go func() {
+			if err := h.modelsManager.RefetchModelsForProvider(context.Background(), provider); err != nil {
+				logger.Warn(fmt.Sprintf("Failed to refetch models for provider %s: %v", provider, err))
+			}
+		}()
+	}
+	return true
+}
+
+// getProviderKeyFromCtx returns the provider and key id of the path, and sends 400 when they are invalid
+func getProviderKeyFromCtx(ctx *fasthttp.RequestCtx) (schemas.ModelProvider, string, bool) {
+	provider, err := getProviderFromCtx(ctx)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid provider: %v", err))
+		return "", "", false
+	}
+	keyID, ok := ctx.UserValue("key_id").(string)
+	if !ok || keyID == "" {
+		SendError(ctx, fasthttp.StatusBadRequest, "Missing key_id parameter")
+		return "", "", false
+	}
+	return provider, keyID, true
+}
@@ -11,11 +11,15 @@ import (
 	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/fasthttp/router"
 	bifrost "github.com/maximhq/bifrost/core"
 	"github.com/maximhq/bifrost/core/schemas"
 	"github.com/maximhq/bifrost/framework/configstore"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+	"github.com/maximhq/bifrost/framework/envutils"
 	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
 	"github.com/valyala/fasthttp"
 )
@@ -32,6 +36,7 @@ type ProviderHandler struct {
 	store         *lib.Config
 	client        *bifrost.Bifrost
 	modelsManager ModelsManager
+	writeMu       sync.Mutex // Serializes the provider and key writes so that their preconditions hold until applied
 }
 
 // NewProviderHandler creates a new provider handler instance
@@ -60,6 +65,8 @@ type ProviderResponse struct {
 	ProxyConfig              *schemas.ProxyConfig             `json:"proxy_config"`                     // Proxy configuration
 	SendBackRawResponse      bool                             `json:"send_back_raw_response"`           // Include raw response in BifrostResponse
 	CustomProviderConfig     *schemas.CustomProviderConfig    `json:"custom_provider_config,omitempty"` // Custom provider configuration
+	MockConfig               *schemas.MockConfig              `json:"mock_config,omitempty"`            // Synthetic responses of the mock provider
+	Namespace                string                           `json:"namespace,omitempty"`              // Namespace owning the provider
 	Status                   ProviderStatus                   `json:"status"`                           // Status of the provider
 }
 
@@ -75,6 +82,32 @@ type ErrorResponse struct {
 	Message string `json:"message,omitempty"`
 }
 
+// AddProviderRequest is the request body for adding a provider
+type AddProviderRequest struct {
+	Provider                 schemas.ModelProvider             `json:"provider" validate:"required"`
+	Keys                     []schemas.Key                     `json:"keys"`                                  // API keys for the provider
+	NetworkConfig            *schemas.NetworkConfig            `json:"network_config,omitempty"`              // Network-related settings
+	ConcurrencyAndBufferSize *schemas.ConcurrencyAndBufferSize `json:"concurrency_and_buffer_size,omitempty"` // Concurrency settings
+	ProxyConfig              *schemas.ProxyConfig              `json:"proxy_config,omitempty"`                // Proxy configuration
+	SendBackRawResponse      *bool                             `json:"send_back_raw_response,omitempty"`      // Include raw response in BifrostResponse
+	CustomProviderConfig     *schemas.CustomProviderConfig     `json:"custom_provider_config,omitempty"`      // Custom provider configuration
+	MockConfig               *schemas.MockConfig               `json:"mock_config,omitempty"`                 // Synthetic responses of the mock provider
+	Namespace                *string                           `json:"namespace,omitempty"`                   // Namespace owning the provider, only honoured for the root admin
+	ValidateKeys             bool                              `json:"validate_keys,omitempty"`               // Validate the keys with a live call before saving them
+}
+
+// UpdateProviderRequest is the request body for updating a provider, with its complete configuration
+type UpdateProviderRequest struct {
+	Keys                     []schemas.Key                    `json:"keys"`                             // API keys for the provider
+	NetworkConfig            schemas.NetworkConfig            `json:"network_config"`                   // Network-related settings
+	ConcurrencyAndBufferSize schemas.ConcurrencyAndBufferSize `json:"concurrency_and_buffer_size"`      // Concurrency settings
+	ProxyConfig              *schemas.ProxyConfig             `json:"proxy_config,omitempty"`           // Proxy configuration
+	SendBackRawResponse      *bool                            `json:"send_back_raw_response,omitempty"` // Include raw response in BifrostResponse
+	CustomProviderConfig     *schemas.CustomProviderConfig    `json:"custom_provider_config,omitempty"` // Custom provider configuration
+	MockConfig               *schemas.MockConfig              `json:"mock_config,omitempty"`            // Synthetic responses of the mock provider
+	ValidateKeys             bool                             `json:"validate_keys,omitempty"`          // Validate the new and changed keys with a live call before saving them
+}
+
 // RegisterRoutes registers all provider management routes
 func (h *ProviderHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
 	// Provider CRUD operations
@@ -83,7 +116,13 @@ func (h *ProviderHandler) RegisterRoutes(r *router.Router, middlewares ...lib.Bi
 	r.POST("/api/providers", lib.ChainMiddlewares(h.addProvider, middlewares...))
 	r.PUT("/api/providers/{provider}", lib.ChainMiddlewares(h.updateProvider, middlewares...))
 	r.DELETE("/api/providers/{provider}", lib.ChainMiddlewares(h.deleteProvider, middlewares...))
+	r.POST("/api/providers/{provider}/keys", lib.ChainMiddlewares(h.addProviderKey, middlewares...))
+	r.GET("/api/providers/{provider}/keys/{key_id}", lib.ChainMiddlewares(h.getProviderKey, middlewares...))
+	r.PUT("/api/providers/{provider}/keys/{key_id}", lib.ChainMiddlewares(h.updateProviderKey, middlewares...))
+	r.DELETE("/api/providers/{provider}/keys/{key_id}", lib.ChainMiddlewares(h.deleteProviderKey, middlewares...))
+	r.POST("/api/providers/{provider}/keys/{key_id}/validate", lib.ChainMiddlewares(h.validateProviderKey, middlewares...))
 	r.GET("/api/keys", lib.ChainMiddlewares(h.listKeys, middlewares...))
+	r.POST("/api/keys/import", lib.ChainMiddlewares(h.importKeys, middlewares...))
 	r.GET("/api/models", lib.ChainMiddlewares(h.listModels, middlewares...))
 }
 
@@ -119,6 +158,9 @@ func (h *ProviderHandler) listProviders(ctx *fasthttp.RequestCtx) {
 			})
 			continue
 		}
+		if !canAccessNamespace(ctx, config.Namespace) {
+			continue
+		}
 
 		providerStatus := ProviderStatusError
 		if slices.Contains(providersInClient, provider) {
@@ -155,6 +197,15 @@ func (h *ProviderHandler) getProvider(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, fasthttp.StatusNotFound, fmt.Sprintf("Provider not found: %v", err))
 		return
 	}
+	if !canAccessNamespace(ctx, config.Namespace) {
+		SendError(ctx, fasthttp.StatusNotFound, "Provider not found")
+		return
+	}
+	etag := h.providerETag(provider)
+	if checkNotModified(ctx, etag) {
+		return
+	}
+	setETag(ctx, etag)
 
 	providerStatus := ProviderStatusError
 	if slices.Contains(providersInClient, provider) {
@@ -168,17 +219,10 @@ func (h *ProviderHandler) getProvider(ctx *fasthttp.RequestCtx) {
 
 // addProvider handles POST /api/providers - Add a new provider
 func (h *ProviderHandler) addProvider(ctx *fasthttp.RequestCtx) {
-	// Payload structure
-	var payload = struct {
-		Provider                 schemas.ModelProvider             `json:"provider"`
-		Keys                     []schemas.Key                     `json:"keys"`                                  // API keys for the provider
-		NetworkConfig            *schemas.NetworkConfig            `json:"network_config,omitempty"`              // Network-related settings
-		ConcurrencyAndBufferSize *schemas.ConcurrencyAndBufferSize `json:"concurrency_and_buffer_size,omitempty"` // Concurrency settings
-		ProxyConfig              *schemas.ProxyConfig              `json:"proxy_config,omitempty"`                // Proxy configuration
-		SendBackRawResponse      *bool                             `json:"send_back_raw_response,omitempty"`      // Include raw response in BifrostResponse
-		CustomProviderConfig     *schemas.CustomProviderConfig     `json:"custom_provider_config,omitempty"`      // Custom provider configuration
-	}{}
-
+	if !validateConfigPayload(ctx, "provider") {
+		return
+	}
+	var payload AddProviderRequest
 	if err := json.Unmarshal(ctx.PostBody(), &payload); err != nil {
 		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid JSON: %v", err))
 		return
@@ -223,6 +267,10 @@ func (h *ProviderHandler) addProvider(ctx *fasthttp.RequestCtx) {
 			SendError(ctx, fasthttp.StatusBadRequest, "Concurrency must be less than or equal to buffer size")
 			return
 		}
+		if err := payload.ConcurrencyAndBufferSize.ValidatePriorityPools(); err != nil {
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid priority pools: %v", err))
+			return
+		}
 	}
 
 	// Validate retry backoff values if NetworkConfig is provided
@@ -233,6 +281,21 @@ func (h *ProviderHandler) addProvider(ctx *fasthttp.RequestCtx) {
 		}
 	}
 
+	if err := validateMockConfig(payload.Provider, payload.MockConfig); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid mock config: %v", err))
+		return
+	}
+
+	namespace, err := resolveNamespaceForCreate(ctx, payload.Namespace)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusForbidden, err.Error())
+		return
+	}
+	if err := checkProviderNameForCreate(ctx, string(payload.Provider)); err != nil {
+		SendError(ctx, fasthttp.StatusForbidden, err.Error())
+		return
+	}
+
 	// Check if provider already exists
 	if _, err := h.store.GetProviderConfigRedacted(payload.Provider); err == nil {
 		SendError(ctx, fasthttp.StatusConflict, fmt.Sprintf("Provider %s already exists", payload.Provider))
@@ -247,6 +310,8 @@ func (h *ProviderHandler) addProvider(ctx *fasthttp.RequestCtx) {
 		ConcurrencyAndBufferSize: payload.ConcurrencyAndBufferSize,
 		SendBackRawResponse:      payload.SendBackRawResponse != nil && *payload.SendBackRawResponse,
 		CustomProviderConfig:     payload.CustomProviderConfig,
+		MockConfig:               payload.MockConfig,
+		Namespace:                namespace,
 	}
 
 	// Validate custom provider configuration before persisting
@@ -254,6 +319,29 @@ func (h *ProviderHandler) addProvider(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid custom provider config: %v", err))
 		return
 	}
+	if err := lib.ValidateKeys(config.Keys); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid keys: %v", err))
+		return
+	}
+	if err := lib.ValidateSecretReferences(config, nil); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid keys: %v", err))
+		return
+	}
+	if err := lib.ValidateEndpoints(ctx, h.store.ClientConfig.EndpointPolicy, config.NetworkConfig, config.Keys); err != nil {
+		SendError(ctx, fasthttp.StatusForbidden, fmt.Sprintf("Endpoint not allowed: %v", err))
+		return
+	}
+
+	// Key validations are only recorded by the validation calls of the management API
+	for i := range config.Keys {
+		config.Keys[i].LastValidation = nil
+	}
+	if payload.ValidateKeys {
+		if failures := h.validateKeys(ctx, payload.Provider, config); len(failures) > 0 {
+			SendError(ctx, fasthttp.StatusUnprocessableEntity, fmt.Sprintf("Key validation failed, the provider was not saved: %s", strings.Join(failures, "; ")))
+			return
+		}
+	}
 
 	// Add provider to store (env vars will be processed by store)
 	if err := h.store.AddProvider(ctx, payload.Provider, config); err != nil {
@@ -275,6 +363,8 @@ func (h *ProviderHandler) addProvider(ctx *fasthttp.RequestCtx) {
 			ProxyConfig:              config.ProxyConfig,
 			SendBackRawResponse:      config.SendBackRawResponse,
 			CustomProviderConfig:     config.CustomProviderConfig,
+			MockConfig:               config.MockConfig,
+			Namespace:                config.Namespace,
 		}, ProviderStatusActive)
 		SendJSON(ctx, response)
 		return
@@ -308,20 +398,18 @@ func (h *ProviderHandler) updateProvider(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
-	var payload = struct {
-		Keys                     []schemas.Key                    `json:"keys"`                             // API keys for the provider
-		NetworkConfig            schemas.NetworkConfig            `json:"network_config"`                   // Network-related settings
-		ConcurrencyAndBufferSize schemas.ConcurrencyAndBufferSize `json:"concurrency_and_buffer_size"`      // Concurrency settings
-		ProxyConfig              *schemas.ProxyConfig             `json:"proxy_config,omitempty"`           // Proxy configuration
-		SendBackRawResponse      *bool                            `json:"send_back_raw_response,omitempty"` // Include raw response in BifrostResponse
-		CustomProviderConfig     *schemas.CustomProviderConfig    `json:"custom_provider_config,omitempty"` // Custom provider configuration
-	}{}
-
+	if !validateConfigPayload(ctx, "provider_update") {
+		return
+	}
+	var payload UpdateProviderRequest
 	if err := json.Unmarshal(ctx.PostBody(), &payload); err != nil {
 		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid JSON: %v", err))
 		return
 	}
 
+	h.writeMu.Lock()
+	defer h.writeMu.Unlock()
+
 	// Get the raw config to access actual values for merging with redacted request values
 	oldConfigRaw, err := h.store.GetProviderConfigRaw(provider)
 	if err != nil {
@@ -331,9 +419,26 @@ func (h *ProviderHandler) updateProvider(ctx *fasthttp.RequestCtx) {
 			return
 		}
 	}
+	if oldConfigRaw != nil && !canAccessNamespace(ctx, oldConfigRaw.Namespace) {
+		SendError(ctx, fasthttp.StatusNotFound, "Provider not found")
+		return
+	}
+	if !checkPreconditions(ctx, h.providerETag(provider)) {
+		return
+	}
 
 	if oldConfigRaw == nil {
-		oldConfigRaw = &configstore.ProviderConfig{}
+		// The provider is created by this upsert, so it goes to the caller's namespace
+		namespace, err := resolveNamespaceForCreate(ctx, nil)
+		if err != nil {
+			SendError(ctx, fasthttp.StatusForbidden, err.Error())
+			return
+		}
+		if err := checkProviderNameForCreate(ctx, string(provider)); err != nil {
+			SendError(ctx, fasthttp.StatusForbidden, err.Error())
+			return
+		}
+		oldConfigRaw = &configstore.ProviderConfig{Namespace: namespace}
 	}
 
 	oldConfigRedacted, err := h.store.GetProviderConfigRedacted(provider)
@@ -356,6 +461,8 @@ func (h *ProviderHandler) updateProvider(ctx *fasthttp.RequestCtx) {
 		ConcurrencyAndBufferSize: oldConfigRaw.ConcurrencyAndBufferSize,
 		ProxyConfig:              oldConfigRaw.ProxyConfig,
 		CustomProviderConfig:     oldConfigRaw.CustomProviderConfig,
+		MockConfig:               oldConfigRaw.MockConfig,
+		Namespace:                oldConfigRaw.Namespace,
 	}
 
 	// Environment variable cleanup is now handled automatically by mergeKeys function
@@ -382,11 +489,20 @@ func (h *ProviderHandler) updateProvider(ctx *fasthttp.RequestCtx) {
 		}
 	}
 
+	if err := lib.ValidateSecretReferences(configstore.ProviderConfig{Keys: payload.Keys, ProxyConfig: payload.ProxyConfig}, oldConfigRedacted); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid keys: %v", err))
+		return
+	}
+
 	keys, err := h.mergeKeys(provider, oldConfigRaw.Keys, oldConfigRedacted.Keys, keysToAdd, keysToDelete, keysToUpdate)
 	if err != nil {
 		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid keys: %v", err))
 		return
 	}
+	if err := lib.ValidateKeys(keys); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid keys: %v", err))
+		return
+	}
 	config.Keys = keys
 
 	if payload.ConcurrencyAndBufferSize.Concurrency == 0 {
@@ -402,6 +518,10 @@ func (h *ProviderHandler) updateProvider(ctx *fasthttp.RequestCtx) {
 		SendError(ctx, fasthttp.StatusBadRequest, "Concurrency must be less than or equal to buffer size")
 		return
 	}
+	if err := payload.ConcurrencyAndBufferSize.ValidatePriorityPools(); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid priority pools: %v", err))
+		return
+	}
 
 	// Build a prospective config with the requested CustomProviderConfig (including nil)
 	prospective := config
@@ -419,14 +539,32 @@ func (h *ProviderHandler) updateProvider(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	if err := validateMockConfig(provider, payload.MockConfig); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid mock config: %v", err))
+		return
+	}
+
+	if err := lib.ValidateEndpoints(ctx, h.store.ClientConfig.EndpointPolicy, &nc, config.Keys); err != nil {
+		SendError(ctx, fasthttp.StatusForbidden, fmt.Sprintf("Endpoint not allowed: %v", err))
+		return
+	}
+
 	config.ConcurrencyAndBufferSize = &payload.ConcurrencyAndBufferSize
 	config.NetworkConfig = &nc
 	config.ProxyConfig = payload.ProxyConfig
 	config.CustomProviderConfig = payload.CustomProviderConfig
+	config.MockConfig = payload.MockConfig
 	if payload.SendBackRawResponse != nil {
 		config.SendBackRawResponse = *payload.SendBackRawResponse
 	}
 
+	if payload.ValidateKeys {
+		if failures := h.validateKeys(ctx, provider, config); len(failures) > 0 {
+			SendError(ctx, fasthttp.StatusUnprocessableEntity, fmt.Sprintf("Key validation failed, the provider was not saved: %s", strings.Join(failures, "; ")))
+			return
+		}
+	}
+
 	// Update provider config in store (env vars will be processed by store)
 	if err := h.store.UpdateProviderConfig(ctx, provider, config); err != nil {
 		if !errors.Is(err, lib.ErrNotFound) {
@@ -453,6 +591,8 @@ func (h *ProviderHandler) updateProvider(ctx *fasthttp.RequestCtx) {
 			ProxyConfig:              config.ProxyConfig,
 			SendBackRawResponse:      config.SendBackRawResponse,
 			CustomProviderConfig:     config.CustomProviderConfig,
+			MockConfig:               config.MockConfig,
+			Namespace:                config.Namespace,
 		}, ProviderStatusActive)
 		SendJSON(ctx, response)
 		return
@@ -475,6 +615,7 @@ func (h *ProviderHandler) updateProvider(ctx *fasthttp.RequestCtx) {
 
 	response := h.getProviderResponseFromConfig(provider, *redactedConfig, ProviderStatusActive)
 
+	setETag(ctx, h.providerETag(provider))
 	SendJSON(ctx, response)
 }
 
@@ -487,10 +628,21 @@ func (h *ProviderHandler) deleteProvider(ctx *fasthttp.RequestCtx) {
 	}
 
 	// Check if provider exists
-	if _, err := h.store.GetProviderConfigRedacted(provider); err != nil {
+	config, err := h.store.GetProviderConfigRedacted(provider)
+	if err != nil {
 		SendError(ctx, fasthttp.StatusNotFound, fmt.Sprintf("Provider not found: %v", err))
 		return
 	}
+	if !canAccessNamespace(ctx, config.Namespace) {
+		SendError(ctx, fasthttp.StatusNotFound, "Provider not found")
+		return
+	}
+
+	h.writeMu.Lock()
+	defer h.writeMu.Unlock()
+	if !checkPreconditions(ctx, h.providerETag(provider)) {
+		return
+	}
 
 	// Remove provider from store
 	if err := h.store.RemoveProvider(ctx, provider); err != nil {
@@ -520,6 +672,16 @@ func (h *ProviderHandler) listKeys(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	if _, scoped := getRequestNamespace(ctx); scoped {
+		filtered := make([]configstoreTables.TableKey, 0, len(keys))
+		for _, key := range keys {
+			if canAccessNamespace(ctx, key.Namespace) {
+				filtered = append(filtered, key)
+			}
+		}
+		keys = filtered
+	}
+
 	SendJSON(ctx, keys)
 }
 
@@ -562,7 +724,10 @@ func (h *ProviderHandler) listModels(ctx *fasthttp.RequestCtx) {
 	// If provider is specified, get models for that provider only
 	if providerParam != "" {
 		provider := schemas.ModelProvider(providerParam)
-		models := h.modelsManager.GetModelsForProvider(provider)
+		var models []string
+		if h.isProviderInRequestNamespace(ctx, provider) {
+			models = h.modelsManager.GetModelsForProvider(provider)
+		}
 
 		// Filter by keys if specified
 		if keysParam != "" {
@@ -584,8 +749,11 @@ func (h *ProviderHandler) listModels(ctx *fasthttp.RequestCtx) {
 			return
 		}
 
-		// Collect models from all providers
+		// Collect models from all providers of the namespace of the caller
 		for _, provider := range providers {
+			if !h.isProviderInRequestNamespace(ctx, provider) {
+				continue
+			}
 			models := h.modelsManager.GetModelsForProvider(provider)
 
 			// Filter by keys if specified
@@ -642,6 +810,16 @@ func (h *ProviderHandler) listModels(ctx *fasthttp.RequestCtx) {
 	SendJSON(ctx, response)
 }
 
+// isProviderInRequestNamespace checks if a namespace admin may see the provider, which must be configured in their
+// namespace. Requests not scoped to a namespace see every provider.
+func (h *ProviderHandler) isProviderInRequestNamespace(ctx *fasthttp.RequestCtx, provider schemas.ModelProvider) bool {
+	if _, scoped := getRequestNamespace(ctx); !scoped {
+		return true
+	}
+	config, err := h.store.GetProviderConfigRaw(provider)
+	return err == nil && canAccessNamespace(ctx, config.Namespace)
+}
+
 // filterModelsByKeys filters models based on key-level model restrictions
 func (h *ProviderHandler) filterModelsByKeys(provider schemas.ModelProvider, models []string, keyIDs []string) []string {
 	// Get provider config to access keys
@@ -797,7 +975,7 @@ func (h *ProviderHandler) mergeKeys(provider schemas.ModelProvider, oldRawKeys [
 				}
 				if updateKey.BedrockKeyConfig.Region != nil {
 					if lib.IsRedacted(*updateKey.BedrockKeyConfig.Region) &&
-						(!strings.HasPrefix(*updateKey.BedrockKeyConfig.Region, "env.") ||
+						(!envutils.IsReference(*updateKey.BedrockKeyConfig.Region) ||
 							(oldRedactedKey.BedrockKeyConfig.Region != nil &&
 								!strings.EqualFold(*updateKey.BedrockKeyConfig.Region, *oldRedactedKey.BedrockKeyConfig.Region))) {
 						mergedKey.BedrockKeyConfig.Region = oldRawKey.BedrockKeyConfig.Region
@@ -805,7 +983,7 @@ func (h *ProviderHandler) mergeKeys(provider schemas.ModelProvider, oldRawKeys [
 				}
 				if updateKey.BedrockKeyConfig.ARN != nil {
 					if lib.IsRedacted(*updateKey.BedrockKeyConfig.ARN) &&
-						(!strings.HasPrefix(*updateKey.BedrockKeyConfig.ARN, "env.") ||
+						(!envutils.IsReference(*updateKey.BedrockKeyConfig.ARN) ||
 							(oldRedactedKey.BedrockKeyConfig.ARN != nil &&
 								!strings.EqualFold(*updateKey.BedrockKeyConfig.ARN, *oldRedactedKey.BedrockKeyConfig.ARN))) {
 						mergedKey.BedrockKeyConfig.ARN = oldRawKey.BedrockKeyConfig.ARN
@@ -813,6 +991,27 @@ func (h *ProviderHandler) mergeKeys(provider schemas.ModelProvider, oldRawKeys [
 				}
 			}
 
+			// Handle the redacted authentication of the proxy of the key
+			if updateKey.ProxyConfig != nil && oldRedactedKey.ProxyConfig != nil && oldRawKey.ProxyConfig != nil {
+				proxyConfig := *updateKey.ProxyConfig
+				if envutils.IsReference(updateKey.ProxyConfig.Username) &&
+					updateKey.ProxyConfig.Username == oldRedactedKey.ProxyConfig.Username {
+					proxyConfig.Username = oldRawKey.ProxyConfig.Username
+				}
+				if lib.IsRedacted(updateKey.ProxyConfig.Password) &&
+					strings.EqualFold(updateKey.ProxyConfig.Password, oldRedactedKey.ProxyConfig.Password) {
+					proxyConfig.Password = oldRawKey.ProxyConfig.Password
+				}
+				mergedKey.ProxyConfig = &proxyConfig
+			}
+
+			// Key validations are only recorded by the validation calls of the management API,
+			// and dropped once the key changes
+			mergedKey.LastValidation = nil
+			if keyUnchanged(mergedKey, oldRawKey) {
+				mergedKey.LastValidation = oldRawKey.LastValidation
+			}
+
 			resultKeys = append(resultKeys, mergedKey)
 		} else {
 			// Keep unchanged key
@@ -821,7 +1020,10 @@ func (h *ProviderHandler) mergeKeys(provider schemas.ModelProvider, oldRawKeys [
 	}
 
 	// Add new keys
-	resultKeys = append(resultKeys, keysToAdd...)
+	for _, key := range keysToAdd {
+		key.LastValidation = nil
+		resultKeys = append(resultKeys, key)
+	}
 
 	// Clean up environment variables for updated keys after merge
 	// This allows us to compare the final merged values with the original values
@@ -846,10 +1048,21 @@ func (h *ProviderHandler) getProviderResponseFromConfig(provider schemas.ModelPr
 		ProxyConfig:              config.ProxyConfig,
 		SendBackRawResponse:      config.SendBackRawResponse,
 		CustomProviderConfig:     config.CustomProviderConfig,
+		MockConfig:               config.MockConfig,
+		Namespace:                config.Namespace,
 		Status:                   status,
 	}
 }
 
+// providerETag returns the ETag of the config of a provider, empty when the provider does not exist
+func (h *ProviderHandler) providerETag(provider schemas.ModelProvider) string {
+	config, err := h.store.GetProviderConfigRaw(provider)
+	if err != nil || config == nil {
+		return ""
+	}
+	return resourceETag(provider, config)
+}
+
 func getProviderFromCtx(ctx *fasthttp.RequestCtx) (schemas.ModelProvider, error) {
 	providerValue := ctx.UserValue("provider")
 	if providerValue == nil {
@@ -868,6 +1081,133 @@ func getProviderFromCtx(ctx *fasthttp.RequestCtx) (schemas.ModelProvider, error)
 	return schemas.ModelProvider(decoded), nil
 }
 
+// validateProviderKey handles POST /api/providers/{provider}/keys/{key_id}/validate - Validate a saved key with a live call.
+// The result is recorded on the key, and returned whether the provider accepted the key or not.
+func (h *ProviderHandler) validateProviderKey(ctx *fasthttp.RequestCtx) {
+	provider, err := getProviderFromCtx(ctx)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid provider: %v", err))
+		return
+	}
+	keyID, ok := ctx.UserValue("key_id").(string)
+	if !ok || keyID == "" {
+		SendError(ctx, fasthttp.StatusBadRequest, "Missing key_id parameter")
+		return
+	}
+
+	config, err := h.store.GetProviderConfigRaw(provider)
+	if err != nil || !canAccessNamespace(ctx, config.Namespace) {
+		SendError(ctx, fasthttp.StatusNotFound, "Provider not found")
+		return
+	}
+	index := slices.IndexFunc(config.Keys, func(key schemas.Key) bool { return key.ID == keyID })
+	if index < 0 {
+		SendError(ctx, fasthttp.StatusNotFound, "Key not found")
+		return
+	}
+
+	validation := h.validateKey(ctx, provider, *lib.BifrostProviderConfig(config), config.Keys[index])
+	if err := h.store.UpdateKeyValidation(ctx, provider, keyID, validation); err != nil {
+		logger.Warn(fmt.Sprintf("Failed to record the validation of key %s of provider %s: %v", keyID, provider, err))
+		SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to record key validation: %v", err))
+		return
+	}
+
+	SendJSON(ctx, validation)
+}
+
+// validateKeys validates the keys of the config without a validation with live calls to the provider, in parallel,
+// and records the result on each key. It returns the errors of the keys rejected by the provider.
+func (h *ProviderHandler) validateKeys(ctx context.Context, provider schemas.ModelProvider, config configstore.ProviderConfig) []string {
+	providerConfig := lib.BifrostProviderConfig(&config)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var failures []string
+	for i := range config.Keys {
+		if config.Keys[i].LastValidation != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(key *schemas.Key) {
+			defer wg.Done()
+			key.LastValidation = h.validateKey(ctx, provider, *providerConfig, *key)
+			if key.LastValidation.Status == schemas.KeyValidationInvalid {
+				mu.Lock()
+				failures = append(failures, fmt.Sprintf("key %s: %s", key.Name, key.LastValidation.Error))
+				mu.Unlock()
+			}
+		}(&config.Keys[i])
+	}
+	wg.Wait()
+
+	sort.Strings(failures)
+	return failures
+}
+
+// validateKey makes the live validation call of a key
+func (h *ProviderHandler) validateKey(ctx context.Context, provider schemas.ModelProvider, config schemas.ProviderConfig, key schemas.Key) *schemas.KeyValidation {
+	validation := &schemas.KeyValidation{Status: schemas.KeyValidationValid, ValidatedAt: time.Now().UTC()}
+	resolvedKey, err := h.store.ResolveKeyEnvVars(key)
+	if err != nil {
+		validation.Status = schemas.KeyValidationInvalid
+		validation.Error = fmt.Sprintf("The key could not be read: %v", err)
+		return validation
+	}
+	if bifrostErr := h.client.ValidateKey(ctx, provider, config, resolvedKey); bifrostErr != nil {
+		validation.Status = schemas.KeyValidationInvalid
+		validation.Error = keyValidationError(bifrostErr)
+	}
+	return validation
+}
+
+// keyValidationError describes what to fix for a key rejected by its validation call
+func keyValidationError(bifrostErr *schemas.BifrostError) string {
+	message := "unknown error"
+	if bifrostErr.Error != nil {
+		message = bifrostErr.Error.Message
+	}
+	if bifrostErr.StatusCode == nil {
+		if bifrostErr.Error != nil && bifrostErr.Error.Message == schemas.ErrProviderDoRequest && bifrostErr.Error.Error != nil {
+			return fmt.Sprintf("The provider could not be reached (%v), check the base URL, the proxy and the network access of Bifrost", bifrostErr.Error.Error)
+		}
+		return message
+	}
+	switch statusCode := *bifrostErr.StatusCode; statusCode {
+	case fasthttp.StatusUnauthorized:
+		return fmt.Sprintf("The provider rejected the credentials (%d: %s), check the value of the key", statusCode, message)
+	case fasthttp.StatusForbidden:
+		return fmt.Sprintf("The provider denied access to the key (%d: %s), check the permissions, project and region of the key", statusCode, message)
+	case fasthttp.StatusNotFound:
+		return fmt.Sprintf("The provider endpoint or model was not found (%d: %s), check the base URL, the deployments and the models of the key", statusCode, message)
+	case fasthttp.StatusTooManyRequests:
+		return fmt.Sprintf("The key is rate limited or out of quota (%d: %s), check the plan and billing of the account", statusCode, message)
+	default:
+		return fmt.Sprintf("The provider failed the validation call (%d: %s)", statusCode, message)
+	}
+}
+
+// keyUnchanged reports whether an updated key sends the same requests as before the update
+func keyUnchanged(updatedKey, oldKey schemas.Key) bool {
+	updatedHash, err := configstore.GenerateKeyHash(updatedKey)
+	if err != nil {
+		return false
+	}
+	oldHash, err := configstore.GenerateKeyHash(oldKey)
+	return err == nil && updatedHash == oldHash
+}
+
+// validateMockConfig checks the mock config, only the mock provider accepts one
+func validateMockConfig(provider schemas.ModelProvider, mockConfig *schemas.MockConfig) error {
+	if mockConfig == nil {
+		return nil
+	}
+	if provider != schemas.Mock {
+		return fmt.Errorf("mock_config is only supported by the %s provider", schemas.Mock)
+	}
+	return mockConfig.Validate()
+}
+
 func validateRetryBackoff(networkConfig *schemas.NetworkConfig) error {
 	if networkConfig != nil {
 		if networkConfig.RetryBackoffInitial > 0 {
@@ -891,6 +1231,19 @@ func validateRetryBackoff(networkConfig *schemas.NetworkConfig) error {
 				return fmt.Errorf("retry backoff initial must be less than or equal to retry backoff max")
 			}
 		}
+		if networkConfig.RetryPolicy != nil {
+			if err := networkConfig.RetryPolicy.Validate(); err != nil {
+				return fmt.Errorf("invalid retry policy: %v", err)
+			}
+		}
+		if networkConfig.FallbackPolicy != nil {
+			if err := networkConfig.FallbackPolicy.Validate(); err != nil {
+				return fmt.Errorf("invalid fallback policy: %v", err)
+			}
+		}
+		if err := networkConfig.HTTPClient.Validate(); err != nil {
+			return fmt.Errorf("invalid http client config: %v", err)
+		}
 	}
 	return nil
 }
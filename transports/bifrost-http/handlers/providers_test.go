@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestKeyValidationError(t *testing.T) {
+	status := func(code int) *int { return &code }
+
+	tests := map[string]struct {
+		err      *schemas.BifrostError
+		expected string
+	}{
+		"unauthorized":   {err: &schemas.BifrostError{StatusCode: status(401), Error: &schemas.ErrorField{Message: "Incorrect API key provided"}}, expected: "check the value of the key"},
+		"not found":      {err: &schemas.BifrostError{StatusCode: status(404), Error: &schemas.ErrorField{Message: "deployment not found"}}, expected: "check the base URL"},
+		"unreachable":    {err: &schemas.BifrostError{Error: &schemas.ErrorField{Message: schemas.ErrProviderDoRequest, Error: errors.New("no such host")}}, expected: "could not be reached (no such host)"},
+		"provider error": {err: &schemas.BifrostError{StatusCode: status(500), Error: &schemas.ErrorField{Message: "overloaded"}}, expected: "(500: overloaded)"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := keyValidationError(test.err); !strings.Contains(got, test.expected) {
+				t.Errorf("expected %q to contain %q", got, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the replay handler, re-sending logged requests to debug provider behavior differences.
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/fasthttp/router"
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/logstore"
+	"github.com/maximhq/bifrost/plugins/logging"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// ReplayHandler re-sends logged requests against the same or a different provider and model
+type ReplayHandler struct {
+	logManager logging.LogManager
+	client     *bifrost.Bifrost
+}
+
+// ReplayRequest is the payload of a replay, the provider and model of the logged request are used when empty
+type ReplayRequest struct {
+	Provider schemas.ModelProvider `json:"provider,omitempty"`
+	Model    string                `json:"model,omitempty"`
+}
+
+// ReplayResult is one side of a replay: the provider request sent and the output or error it got
+type ReplayResult struct {
+	Provider        string                          `json:"provider"`
+	Model           string                          `json:"model"`
+	ProviderRequest *schemas.BifrostProviderRequest `json:"provider_request,omitempty"` // Set on the original side only when its request was captured
+	Output          interface{}                     `json:"output,omitempty"`
+	Error           *schemas.BifrostError           `json:"error,omitempty"`
+	Latency         *float64                        `json:"latency,omitempty"` // in milliseconds
+}
+
+// ReplayDifference is a value of the outputs differing between the original request and its replay.
+// Path is the JSON path of the value, a missing value on one side is null.
+type ReplayDifference struct {
+	Path     string      `json:"path"`
+	Original interface{} `json:"original"`
+	Replay   interface{} `json:"replay"`
+}
+
+// ReplayResponse is the result of a replay
+type ReplayResponse struct {
+	Original    ReplayResult       `json:"original"`
+	Replay      ReplayResult       `json:"replay"`
+	Differences []ReplayDifference `json:"differences"`
+}
+
+// NewReplayHandler creates a new replay handler instance
+func NewReplayHandler(logManager logging.LogManager, client *bifrost.Bifrost) *ReplayHandler {
+	return &ReplayHandler{
+		logManager: logManager,
+		client:     client,
+	}
+}
+
+// RegisterRoutes registers the replay routes
+func (h *ReplayHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
+	r.POST("/api/logs/{id}/replay", lib.ChainMiddlewares(h.replayLog, middlewares...))
+}
+
+// replayLog handles POST /api/logs/{id}/replay - Re-send a logged chat or responses request and diff the outputs
+func (h *ReplayHandler) replayLog(ctx *fasthttp.RequestCtx) {
+	id, ok := ctx.UserValue("id").(string)
+	if !ok || id == "" {
+		SendError(ctx, fasthttp.StatusBadRequest, "Missing log id")
+		return
+	}
+	var payload ReplayRequest
+	if body := ctx.PostBody(); len(body) > 0 {
+		if err := sonic.Unmarshal(body, &payload); err != nil {
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid JSON: %v", err))
+			return
+		}
+	}
+
+	entry, err := h.logManager.GetLog(ctx, id)
+	if err != nil {
+		if errors.Is(err, logstore.ErrNotFound) {
+			SendError(ctx, fasthttp.StatusNotFound, fmt.Sprintf("Log %s not found", id))
+			return
+		}
+		SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to get log: %v", err))
+		return
+	}
+	// Namespace admins can only replay the requests of their own namespace
+	if !canAccessNamespace(ctx, entry.Namespace) {
+		SendError(ctx, fasthttp.StatusNotFound, fmt.Sprintf("Log %s not found", id))
+		return
+	}
+	// Encrypted payloads are only readable by the admin of the namespace they belong to, as in the log search
+	if _, scoped := getRequestNamespace(ctx); entry.PayloadEncrypted && !scoped {
+		SendError(ctx, fasthttp.StatusForbidden, fmt.Sprintf("Log %s has an encrypted payload, it can only be replayed by the admin of its namespace", id))
+		return
+	}
+
+	provider := schemas.ModelProvider(entry.Provider)
+	if payload.Provider != "" {
+		provider = payload.Provider
+	}
+	model := entry.Model
+	if payload.Model != "" {
+		model = payload.Model
+	}
+
+	bifrostCtx, cancel := lib.ConvertToBifrostContext(ctx, false)
+	defer cancel()
+	replayCtx := context.WithValue(*bifrostCtx, schemas.BifrostContextKeyCaptureProviderRequest, true)
+
+	response := ReplayResponse{
+		Original: ReplayResult{
+			Provider:        entry.Provider,
+			Model:           entry.Model,
+			ProviderRequest: entry.ProviderRequestParsed,
+			Error:           entry.ErrorDetailsParsed,
+			Latency:         entry.Latency,
+		},
+		Replay: ReplayResult{
+			Provider: string(provider),
+			Model:    model,
+		},
+	}
+
+	startTime := time.Now()
+	var extraFields *schemas.BifrostResponseExtraFields
+	switch schemas.RequestType(entry.Object) {
+	case schemas.ChatCompletionRequest, schemas.ChatCompletionStreamRequest:
+		var params *schemas.ChatParameters
+		if entry.Params != "" {
+			if err := sonic.Unmarshal([]byte(entry.Params), &params); err != nil {
+				SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to parse the params of the log: %v", err))
+				return
+			}
+		}
+		if params != nil {
+			params.StreamOptions = nil
+		}
+		if entry.OutputMessageParsed != nil {
+			response.Original.Output = entry.OutputMessageParsed
+		}
+		resp, bifrostErr := h.client.ChatCompletionRequest(replayCtx, &schemas.BifrostChatRequest{
+			Provider: provider,
+			Model:    model,
+			Input:    entry.InputHistoryParsed,
+			Params:   params,
+		})
+		response.Replay.Error = bifrostErr
+		if resp != nil {
+			extraFields = &resp.ExtraFields
+			if len(resp.Choices) > 0 && resp.Choices[0].ChatNonStreamResponseChoice != nil {
+				response.Replay.Output = resp.Choices[0].ChatNonStreamResponseChoice.Message
+			}
+		}
+	case schemas.ResponsesRequest, schemas.ResponsesStreamRequest:
+		var params *schemas.ResponsesParameters
+		if entry.Params != "" {
+			if err := sonic.Unmarshal([]byte(entry.Params), &params); err != nil {
+				SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to parse the params of the log: %v", err))
+				return
+			}
+		}
+		if params != nil {
+			params.StreamOptions = nil
+		}
+		if entry.ResponsesOutputParsed != nil {
+			response.Original.Output = entry.ResponsesOutputParsed
+		}
+		resp, bifrostErr := h.client.ResponsesRequest(replayCtx, &schemas.BifrostResponsesRequest{
+			Provider: provider,
+			Model:    model,
+			Input:    entry.ResponsesInputHistoryParsed,
+			Params:   params,
+		})
+		response.Replay.Error = bifrostErr
+		if resp != nil {
+			extraFields = &resp.ExtraFields
+			response.Replay.Output = resp.Output
+		}
+	default:
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Replay is only supported for chat completion and responses requests, log %s is a %s request", id, entry.Object))
+		return
+	}
+	latency := float64(time.Since(startTime).Milliseconds())
+	response.Replay.Latency = &latency
+	if extraFields != nil {
+		response.Replay.ProviderRequest = extraFields.ProviderRequest
+	}
+
+	response.Differences, err = diffReplayOutputs(response.Original.Output, response.Replay.Output)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to diff the outputs: %v", err))
+		return
+	}
+	SendJSON(ctx, response)
+}
+
+// diffReplayOutputs returns the values differing between the JSON representations of the outputs, sorted by path
+func diffReplayOutputs(original, replay interface{}) ([]ReplayDifference, error) {
+	originalValues, err := flattenReplayOutput(original)
+	if err != nil {
+		return nil, err
+	}
+	replayValues, err := flattenReplayOutput(replay)
+	if err != nil {
+		return nil, err
+	}
+
+	differences := []ReplayDifference{}
+	for path, originalValue := range originalValues {
+		if replayValue, ok := replayValues[path]; !ok || !reflect.DeepEqual(originalValue, replayValue) {
+			differences = append(differences, ReplayDifference{Path: path, Original: originalValue, Replay: replayValue})
+		}
+	}
+	for path, replayValue := range replayValues {
+		if _, ok := originalValues[path]; !ok {
+			differences = append(differences, ReplayDifference{Path: path, Replay: replayValue})
+		}
+	}
+	slices.SortFunc(differences, func(a, b ReplayDifference) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return differences, nil
+}
+
+// flattenReplayOutput returns the leaf values of the JSON representation of the output by their path
+func flattenReplayOutput(output interface{}) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	if output == nil {
+		return values, nil
+	}
+	data, err := sonic.Marshal(output)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := sonic.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	if decoded == nil {
+		return values, nil
+	}
+
+	var flatten func(path string, value interface{})
+	flatten = func(path string, value interface{}) {
+		switch typed := value.(type) {
+		case map[string]interface{}:
+			for key, child := range typed {
+				flatten(path+"."+key, child)
+			}
+		case []interface{}:
+			for i, child := range typed {
+				flatten(path+"["+strconv.Itoa(i)+"]", child)
+			}
+		default:
+			values[path] = typed
+		}
+	}
+	flatten("$", decoded)
+	return values, nil
+}
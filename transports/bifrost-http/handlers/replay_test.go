@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/logstore"
+	"github.com/maximhq/bifrost/plugins/logging"
+	"github.com/valyala/fasthttp"
+)
+
+func TestDiffReplayOutputs(t *testing.T) {
+	original := &schemas.ChatMessage{
+		Role:    schemas.ChatMessageRoleAssistant,
+		Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr("Paris")},
+	}
+	replay := &schemas.ChatMessage{
+		Role:    schemas.ChatMessageRoleAssistant,
+		Content: &schemas.ChatMessageContent{ContentStr: schemas.Ptr("Paris, France")},
+	}
+
+	differences, err := diffReplayOutputs(original, replay)
+	if err != nil {
+		t.Fatalf("failed to diff the outputs: %v", err)
+	}
+	if len(differences) != 1 || differences[0].Path != "$.content" || differences[0].Original != "Paris" || differences[0].Replay != "Paris, France" {
+		t.Errorf("expected the content to differ, got %+v", differences)
+	}
+
+	differences, err = diffReplayOutputs(original, original)
+	if err != nil || len(differences) != 0 {
+		t.Errorf("expected no differences between identical outputs, got %+v, %v", differences, err)
+	}
+
+	differences, err = diffReplayOutputs(nil, replay)
+	if err != nil || len(differences) != 2 || differences[0].Original != nil {
+		t.Errorf("expected every value of the replay to differ from a missing output, got %+v, %v", differences, err)
+	}
+}
+
+// replayTestLogManager serves a single log to the replay handler, the other methods are not used
+type replayTestLogManager struct {
+	logging.LogManager
+	log *logstore.Log
+}
+
+func (m *replayTestLogManager) GetLog(ctx context.Context, id string) (*logstore.Log, error) {
+	return m.log, nil
+}
+
+// TestReplayLog_EncryptedPayload tests that the root admin cannot replay the decrypted payload of an encrypted log
+func TestReplayLog_EncryptedPayload(t *testing.T) {
+	handler := NewReplayHandler(&replayTestLogManager{log: &logstore.Log{
+		ID:               "log-1",
+		Namespace:        "acme",
+		PayloadEncrypted: true,
+	}}, nil)
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.SetUserValue("id", "log-1")
+	handler.replayLog(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+		t.Errorf("expected status %d for the root admin, got %d", fasthttp.StatusForbidden, ctx.Response.StatusCode())
+	}
+
+	ctx = &fasthttp.RequestCtx{}
+	ctx.SetUserValue("id", "log-1")
+	ctx.SetUserValue(namespaceUserValueKey, "other")
+	handler.replayLog(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusNotFound {
+		t.Errorf("expected status %d for the admin of another namespace, got %d", fasthttp.StatusNotFound, ctx.Response.StatusCode())
+	}
+}
@@ -0,0 +1,120 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the runtime diagnostics handlers.
+package handlers
+
+import (
+	"runtime"
+	"runtime/pprof"
+	"time"
+
+	"github.com/fasthttp/router"
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/pprofhandler"
+)
+
+// RuntimeStats is a snapshot of the internals of the gateway
+type RuntimeStats struct {
+	StartedAt     time.Time                      `json:"started_at"`
+	UptimeSeconds int64                          `json:"uptime_seconds"`
+	GoVersion     string                         `json:"go_version"`
+	NumCPU        int                            `json:"num_cpu"`
+	Goroutines    int                            `json:"goroutines"`
+	Memory        RuntimeMemoryStats             `json:"memory"`
+	HTTP          RuntimeHTTPStats               `json:"http"`
+	Providers     []schemas.ProviderRuntimeStats `json:"providers"` // Workers and queues of each priority pool of the providers
+}
+
+// RuntimeMemoryStats is the subset of the memory stats of the Go runtime useful to size the gateway
+type RuntimeMemoryStats struct {
+	HeapAllocBytes  uint64  `json:"heap_alloc_bytes"`
+	HeapInuseBytes  uint64  `json:"heap_inuse_bytes"`
+	HeapObjects     uint64  `json:"heap_objects"`
+	StackInuseBytes uint64  `json:"stack_inuse_bytes"`
+	SysBytes        uint64  `json:"sys_bytes"`
+	NumGC           uint32  `json:"num_gc"`
+	LastGCPauseNs   uint64  `json:"last_gc_pause_ns"`
+	GCCPUFraction   float64 `json:"gc_cpu_fraction"`
+}
+
+// RuntimeHTTPStats is the load of the HTTP server
+type RuntimeHTTPStats struct {
+	OpenConnections  int32  `json:"open_connections"`
+	ActiveRequests   uint32 `json:"active_requests"`    // Connections being served, of all the routes
+	InFlightRequests int64  `json:"in_flight_requests"` // Inference requests being handled
+	InFlightStreams  int64  `json:"in_flight_streams"`  // Inference streams being written
+	Draining         bool   `json:"draining"`
+}
+
+// RuntimeHandler serves the runtime diagnostics of the gateway: its internals, goroutine dumps and pprof profiles.
+// They expose the internals of the process, so the routes are only registered behind the admin auth.
+type RuntimeHandler struct {
+	client     *bifrost.Bifrost
+	drain      *lib.Drain
+	httpServer func() *fasthttp.Server // The server is created once the routes are registered
+	startedAt  time.Time
+}
+
+// NewRuntimeHandler creates a new runtime diagnostics handler instance
+func NewRuntimeHandler(client *bifrost.Bifrost, drain *lib.Drain, httpServer func() *fasthttp.Server) *RuntimeHandler {
+	return &RuntimeHandler{
+		client:     client,
+		drain:      drain,
+		httpServer: httpServer,
+		startedAt:  time.Now(),
+	}
+}
+
+// RegisterRoutes registers the runtime diagnostics routes
+func (h *RuntimeHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
+	r.GET("/api/runtime", lib.ChainMiddlewares(h.getRuntimeStats, middlewares...))
+	r.GET("/api/runtime/goroutines", lib.ChainMiddlewares(h.getGoroutines, middlewares...))
+	// The pprof handler expects the paths of net/http/pprof, e.g. /debug/pprof/heap
+	r.GET("/debug/pprof/{profile:*}", lib.ChainMiddlewares(pprofhandler.PprofHandler, middlewares...))
+}
+
+// getRuntimeStats handles GET /api/runtime - Get a snapshot of the internals of the gateway
+func (h *RuntimeHandler) getRuntimeStats(ctx *fasthttp.RequestCtx) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	stats := RuntimeStats{
+		StartedAt:     h.startedAt,
+		UptimeSeconds: int64(time.Since(h.startedAt).Seconds()),
+		GoVersion:     runtime.Version(),
+		NumCPU:        runtime.NumCPU(),
+		Goroutines:    runtime.NumGoroutine(),
+		Memory: RuntimeMemoryStats{
+			HeapAllocBytes:  memStats.HeapAlloc,
+			HeapInuseBytes:  memStats.HeapInuse,
+			HeapObjects:     memStats.HeapObjects,
+			StackInuseBytes: memStats.StackInuse,
+			SysBytes:        memStats.Sys,
+			NumGC:           memStats.NumGC,
+			LastGCPauseNs:   memStats.PauseNs[(memStats.NumGC+255)%256],
+			GCCPUFraction:   memStats.GCCPUFraction,
+		},
+		Providers: []schemas.ProviderRuntimeStats{},
+	}
+	drainStatus := h.drain.Status()
+	stats.HTTP.InFlightRequests = drainStatus.InFlightRequests
+	stats.HTTP.InFlightStreams = drainStatus.InFlightStreams
+	stats.HTTP.Draining = drainStatus.Draining
+	if server := h.httpServer(); server != nil {
+		stats.HTTP.OpenConnections = server.GetOpenConnectionsCount()
+		stats.HTTP.ActiveRequests = server.GetCurrentConcurrency()
+	}
+	if h.client != nil {
+		stats.Providers = h.client.GetProviderRuntimeStats()
+	}
+	SendJSON(ctx, stats)
+}
+
+// getGoroutines handles GET /api/runtime/goroutines - Dump the stacks of all the goroutines as plain text
+func (h *RuntimeHandler) getGoroutines(ctx *fasthttp.RequestCtx) {
+	ctx.SetContentType("text/plain; charset=utf-8")
+	if err := pprof.Lookup("goroutine").WriteTo(ctx, 2); err != nil {
+		SendError(ctx, fasthttp.StatusInternalServerError, "failed to dump goroutines: "+err.Error())
+	}
+}
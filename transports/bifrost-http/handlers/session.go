@@ -10,7 +10,6 @@ import (
 	"github.com/google/uuid"
 	"github.com/maximhq/bifrost/framework/configstore"
 	"github.com/maximhq/bifrost/framework/configstore/tables"
-	"github.com/maximhq/bifrost/framework/encrypt"
 	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
 	"github.com/valyala/fasthttp"
 )
@@ -97,12 +96,8 @@ func (h *SessionHandler) login(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
-	// Verify credentials
-	if payload.Username != authConfig.AdminUserName {
-		SendError(ctx, fasthttp.StatusUnauthorized, "Invalid username or password")
-		return
-	}
-	compare, err := encrypt.CompareHash(authConfig.AdminPassword, payload.Password)
+	// Verify credentials against the root admin and the namespace admins
+	namespace, compare, err := authenticateAdmin(ctx, h.configStore, authConfig, payload.Username, payload.Password)
 	if err != nil {
 		SendError(ctx, fasthttp.StatusUnauthorized, "Unauthorized")
 		return
@@ -116,6 +111,7 @@ func (h *SessionHandler) login(ctx *fasthttp.RequestCtx) {
 	token := uuid.New().String()
 	session := &tables.SessionsTable{
 		Token:     token,
+		Namespace: namespace,
 		ExpiresAt: time.Now().Add(time.Hour * 24 * 30), // 30 days
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -142,8 +138,9 @@ func (h *SessionHandler) login(ctx *fasthttp.RequestCtx) {
 	ctx.Response.Header.SetCookie(cookie)
 
 	SendJSON(ctx, map[string]any{
-		"message": "Login successful",
-		"token":   token,
+		"message":   "Login successful",
+		"token":     token,
+		"namespace": namespace,
 	})
 }
 
@@ -0,0 +1,36 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the SLO handlers.
+package handlers
+
+import (
+	"github.com/fasthttp/router"
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// SLOsHandler serves the compliance and error budgets of the SLO objectives of the providers
+type SLOsHandler struct {
+	client *bifrost.Bifrost
+}
+
+// NewSLOsHandler creates a new SLO handler instance
+func NewSLOsHandler(client *bifrost.Bifrost) *SLOsHandler {
+	return &SLOsHandler{
+		client: client,
+	}
+}
+
+// RegisterRoutes registers the SLO routes
+func (h *SLOsHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
+	r.GET("/api/slos", lib.ChainMiddlewares(h.listSLOs, middlewares...))
+}
+
+// listSLOs handles GET /api/slos - List the compliance of the objectives over their rolling window, in config order
+func (h *SLOsHandler) listSLOs(ctx *fasthttp.RequestCtx) {
+	statuses := h.client.GetSLOStatus()
+	SendJSON(ctx, map[string]any{
+		"slos":  statuses,
+		"count": len(statuses),
+	})
+}
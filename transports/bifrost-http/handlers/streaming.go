@@ -0,0 +1,322 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the keep-alive comments and resumption buffer of the Server-Sent Events streams.
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic/encoder"
+	"github.com/google/uuid"
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// StreamIDHeader is the response header carrying the ID of a resumable stream
+const StreamIDHeader = "x-bf-stream-id"
+
+// streamEvent is a Server-Sent Event, with the event type of the responses API
+type streamEvent struct {
+	eventType string
+	data      []byte
+}
+
+// doneStreamEvent marks the end of the streams of the non-responses APIs
+var doneStreamEvent = streamEvent{data: []byte("[DONE]")}
+
+// maxPooledStreamBufferSize is the capacity above which chunk buffers are not returned to the pool,
+// so that a few large chunks do not keep large buffers alive
+const maxPooledStreamBufferSize = 64 * 1024
+
+// streamBufferPool holds the buffers chunks are marshalled into before being written to their stream
+var streamBufferPool = sync.Pool{
+	New: func() any {
+		buf := make([]byte, 0, 4096)
+		return &buf
+	},
+}
+
+// streamEventType returns the event type of a chunk: the type of the responses API events, empty otherwise
+func streamEventType(chunk *schemas.BifrostStream) string {
+	// For responses API, use OpenAI-compatible format with event line
+	if chunk.BifrostResponsesStreamResponse != nil {
+		return string(chunk.BifrostResponsesStreamResponse.Type)
+	} else if chunk.BifrostError != nil && chunk.BifrostError.ExtraFields.RequestType == schemas.ResponsesStreamRequest {
+		return string(schemas.ResponsesStreamResponseTypeError)
+	}
+	return ""
+}
+
+// marshalStreamChunk appends the JSON of the chunk to buf. The payload of the chunk is encoded directly,
+// rather than through BifrostStream.MarshalJSON which marshals it into an intermediate buffer first.
+func marshalStreamChunk(buf *[]byte, chunk *schemas.BifrostStream) error {
+	payload := chunk.Payload()
+	if payload == nil {
+		*buf = append(*buf, "{}"...)
+		return nil
+	}
+	return encoder.EncodeInto(buf, payload, 0)
+}
+
+// newStreamEvent converts a stream chunk to a Server-Sent Event owning its data, for events that are kept.
+// It returns false when the chunk is nil or cannot be marshalled.
+func newStreamEvent(chunk *schemas.BifrostStream) (streamEvent, bool) {
+	if chunk == nil {
+		return streamEvent{}, false
+	}
+	var data []byte
+	if err := marshalStreamChunk(&data, chunk); err != nil {
+		logger.Warn(fmt.Sprintf("Failed to marshal streaming response: %v", err))
+		return streamEvent{}, false
+	}
+	return streamEvent{eventType: streamEventType(chunk), data: data}, true
+}
+
+// writeStreamChunk marshals the chunk into a pooled buffer and writes it as an event, without flushing it.
+// It returns the event type of the chunk, and false when the chunk is nil or cannot be marshalled, in which
+// case nothing is written.
+func writeStreamChunk(w *bufio.Writer, chunk *schemas.BifrostStream) (string, bool, error) {
+	if chunk == nil {
+		return "", false, nil
+	}
+	buf := streamBufferPool.Get().(*[]byte)
+	defer func() {
+		if cap(*buf) <= maxPooledStreamBufferSize {
+			*buf = (*buf)[:0]
+			streamBufferPool.Put(buf)
+		}
+	}()
+	if err := marshalStreamChunk(buf, chunk); err != nil {
+		logger.Warn(fmt.Sprintf("Failed to marshal streaming response: %v", err))
+		return "", false, nil
+	}
+	event := streamEvent{eventType: streamEventType(chunk), data: *buf}
+	return event.eventType, true, writeStreamEvent(w, "", event)
+}
+
+// isResponsesEvent reports whether the event belongs to a responses API stream, which doesn't use the [DONE] marker
+func (e streamEvent) isResponsesEvent() bool {
+	return e.eventType != ""
+}
+
+// writeStreamEvent writes an event with its optional ID, without flushing it.
+// The lines are written piece by piece, as formatting them would allocate for every chunk.
+func writeStreamEvent(w *bufio.Writer, id string, event streamEvent) error {
+	if id != "" {
+		w.WriteString("id: ")
+		w.WriteString(id)
+		w.WriteByte('\n')
+	}
+	if event.eventType != "" {
+		w.WriteString("event: ")
+		w.WriteString(event.eventType)
+		w.WriteByte('\n')
+	}
+	w.WriteString("data: ")
+	w.Write(event.data)
+	_, err := w.WriteString("\n\n")
+	return err
+}
+
+// writeKeepAlive writes and flushes a comment, which clients ignore but which keeps idle connections open
+func writeKeepAlive(w *bufio.Writer) error {
+	if _, err := w.WriteString(": keep-alive\n\n"); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// sseHeartbeat fires once a stream has been idle for the heartbeat interval.
+// A zero interval disables it, its channel then never fires.
+type sseHeartbeat struct {
+	interval time.Duration
+	timer    *time.Timer
+}
+
+// newSSEHeartbeat creates a heartbeat for the given interval
+func newSSEHeartbeat(interval time.Duration) *sseHeartbeat {
+	if interval <= 0 {
+		return &sseHeartbeat{}
+	}
+	return &sseHeartbeat{interval: interval, timer: time.NewTimer(interval)}
+}
+
+// C returns the channel receiving the heartbeats
+func (h *sseHeartbeat) C() <-chan time.Time {
+	if h.timer == nil {
+		return nil
+	}
+	return h.timer.C
+}
+
+// reset restarts the idle interval, after anything was written to the stream
+func (h *sseHeartbeat) reset() {
+	if h.timer != nil {
+		h.timer.Reset(h.interval)
+	}
+}
+
+// stop releases the timer of the heartbeat
+func (h *sseHeartbeat) stop() {
+	if h.timer != nil {
+		h.timer.Stop()
+	}
+}
+
+// resumableStream buffers the events of a stream so that they can be replayed to reconnecting clients
+type resumableStream struct {
+	id      string
+	mu      sync.Mutex
+	events  []streamEvent
+	done    bool
+	updated chan struct{} // Closed on every new event and when the stream ends
+}
+
+// append adds an event to the stream and wakes up its readers
+func (s *resumableStream) append(event streamEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	close(s.updated)
+	s.updated = make(chan struct{})
+}
+
+// finish marks the end of the stream and wakes up its readers
+func (s *resumableStream) finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = true
+	close(s.updated)
+}
+
+// eventsFrom returns the events from the given index, whether the stream has ended and
+// a channel closed on the next update of the stream
+func (s *resumableStream) eventsFrom(index int) ([]streamEvent, bool, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var events []streamEvent
+	if index < len(s.events) {
+		events = s.events[index:]
+	}
+	return events, s.done, s.updated
+}
+
+// eventID returns the ID of the event at the given index, as sent in the Last-Event-ID header of reconnecting clients
+func (s *resumableStream) eventID(index int) string {
+	return s.id + ":" + strconv.Itoa(index+1)
+}
+
+// streamResumeBuffer keeps the resumable streams while they run and for a short time after they end
+type streamResumeBuffer struct {
+	mu      sync.Mutex
+	streams map[string]*resumableStream
+}
+
+// newStreamResumeBuffer creates an empty resume buffer
+func newStreamResumeBuffer() *streamResumeBuffer {
+	return &streamResumeBuffer{streams: make(map[string]*resumableStream)}
+}
+
+// start buffers the chunks of the stream in the background until it ends. The stream is then kept
+// for the given duration. Upstream streams are not cancelled when clients disconnect, so that the
+// generation can be continued by reconnecting.
+func (b *streamResumeBuffer) start(stream chan *schemas.BifrostStream, keepFor time.Duration) *resumableStream {
+	buffered := &resumableStream{
+		id:      uuid.New().String(),
+		updated: make(chan struct{}),
+	}
+	b.mu.Lock()
+	b.streams[buffered.id] = buffered
+	b.mu.Unlock()
+
+	go func() {
+		var last streamEvent
+		for chunk := range stream {
+			event, ok := newStreamEvent(chunk)
+			schemas.ReleaseBifrostStream(chunk)
+			if !ok {
+				continue
+			}
+			buffered.append(event)
+			last = event
+		}
+		// Note: OpenAI responses API doesn't use [DONE] marker, it ends when the stream closes
+		if !last.isResponsesEvent() {
+			buffered.append(doneStreamEvent)
+		}
+		buffered.finish()
+
+		time.AfterFunc(keepFor, func() {
+			b.mu.Lock()
+			delete(b.streams, buffered.id)
+			b.mu.Unlock()
+		})
+	}()
+
+	return buffered
+}
+
+// resume looks up the stream of a Last-Event-ID header ("<stream id>:<event number>").
+// It returns the stream and the index of the first event to replay, or false when the
+// header is malformed or the stream is unknown or expired.
+func (b *streamResumeBuffer) resume(lastEventID string) (*resumableStream, int, bool) {
+	id, number, ok := strings.Cut(lastEventID, ":")
+	if !ok {
+		return nil, 0, false
+	}
+	next, err := strconv.Atoi(number)
+	if err != nil || next < 0 {
+		return nil, 0, false
+	}
+	b.mu.Lock()
+	stream, ok := b.streams[id]
+	b.mu.Unlock()
+	if !ok {
+		return nil, 0, false
+	}
+	// Clients cannot have received events that were not buffered yet
+	if events, _, _ := stream.eventsFrom(0); next > len(events) {
+		return nil, 0, false
+	}
+	return stream, next, true
+}
+
+// writeResumableStream writes the events of a buffered stream from the given index, and then follows the stream until it ends.
+// Write errors end the writer only, the stream keeps being buffered for the reconnection of the client.
+func writeResumableStream(w *bufio.Writer, stream *resumableStream, next int, heartbeatInterval time.Duration) {
+	defer w.Flush()
+
+	heartbeat := newSSEHeartbeat(heartbeatInterval)
+	defer heartbeat.stop()
+
+	for {
+		events, done, updated := stream.eventsFrom(next)
+		for _, event := range events {
+			if err := writeStreamEvent(w, stream.eventID(next), event); err != nil {
+				return
+			}
+			next++
+		}
+		if len(events) > 0 {
+			if err := w.Flush(); err != nil {
+				return
+			}
+			heartbeat.reset()
+		}
+		if done {
+			return
+		}
+
+		select {
+		case <-updated:
+		case <-heartbeat.C():
+			if err := writeKeepAlive(w); err != nil {
+				return
+			}
+			heartbeat.reset()
+		}
+	}
+}
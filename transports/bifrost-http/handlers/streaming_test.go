@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// bufferTestStream buffers a finished stream of two chat chunks
+func bufferTestStream(t *testing.T, b *streamResumeBuffer) *resumableStream {
+	t.Helper()
+	stream := make(chan *schemas.BifrostStream, 2)
+	stream <- &schemas.BifrostStream{BifrostChatResponse: &schemas.BifrostChatResponse{ID: "first"}}
+	stream <- &schemas.BifrostStream{BifrostChatResponse: &schemas.BifrostChatResponse{ID: "second"}}
+	close(stream)
+
+	buffered := b.start(stream, time.Minute)
+	select {
+	case <-waitForStreamEnd(buffered):
+	case <-time.After(time.Second):
+		t.Fatal("stream was not buffered")
+	}
+	return buffered
+}
+
+// waitForStreamEnd returns a channel closed once the stream has ended
+func waitForStreamEnd(s *resumableStream) <-chan struct{} {
+	ended := make(chan struct{})
+	go func() {
+		for {
+			_, done, updated := s.eventsFrom(0)
+			if done {
+				close(ended)
+				return
+			}
+			<-updated
+		}
+	}()
+	return ended
+}
+
+// TestStreamResumeBuffer_ReplaysFromLastEventID tests that reconnecting clients only receive the events they missed
+func TestStreamResumeBuffer_ReplaysFromLastEventID(t *testing.T) {
+	b := newStreamResumeBuffer()
+	buffered := bufferTestStream(t, b)
+
+	var out bytes.Buffer
+	writeResumableStream(bufio.NewWriter(&out), buffered, 0, 0)
+	full := out.String()
+	for _, expected := range []string{"id: " + buffered.id + ":1\n", `"first"`, "id: " + buffered.id + ":3\ndata: [DONE]\n\n"} {
+		if !strings.Contains(full, expected) {
+			t.Errorf("expected full stream to contain %q, got %q", expected, full)
+		}
+	}
+
+	resumed, next, ok := b.resume(buffered.id + ":1")
+	if !ok {
+		t.Fatal("expected stream to be resumable")
+	}
+	out.Reset()
+	writeResumableStream(bufio.NewWriter(&out), resumed, next, 0)
+	replayed := out.String()
+	if strings.Contains(replayed, `"first"`) {
+		t.Errorf("expected already received event not to be replayed, got %q", replayed)
+	}
+	if !strings.Contains(replayed, `"second"`) || !strings.Contains(replayed, "data: [DONE]") {
+		t.Errorf("expected missed events to be replayed, got %q", replayed)
+	}
+}
+
+// TestStreamResumeBuffer_RejectsUnknownEventIDs tests that malformed, unknown and future event IDs are not resumed
+func TestStreamResumeBuffer_RejectsUnknownEventIDs(t *testing.T) {
+	b := newStreamResumeBuffer()
+	buffered := bufferTestStream(t, b)
+
+	for _, lastEventID := range []string{buffered.id, buffered.id + ":x", buffered.id + ":-1", buffered.id + ":4", "unknown:1"} {
+		if _, _, ok := b.resume(lastEventID); ok {
+			t.Errorf("expected %q not to be resumable", lastEventID)
+		}
+	}
+}
+
+// TestWriteResumableStream_SendsKeepAliveOnIdleStreams tests that heartbeats are written while no event arrives
+func TestWriteResumableStream_SendsKeepAliveOnIdleStreams(t *testing.T) {
+	stream := make(chan *schemas.BifrostStream)
+	buffered := newStreamResumeBuffer().start(stream, time.Minute)
+
+	var out bytes.Buffer
+	written := make(chan struct{})
+	go func() {
+		writeResumableStream(bufio.NewWriter(&out), buffered, 0, 10*time.Millisecond)
+		close(written)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(stream)
+	<-written
+
+	if !strings.HasPrefix(out.String(), ": keep-alive\n\n") {
+		t.Errorf("expected keep-alive comments before the end of the stream, got %q", out.String())
+	}
+}
+
+// TestWriteStreamChunk_MatchesStreamEvent tests that chunks written from pooled buffers are the events kept for resumes
+func TestWriteStreamChunk_MatchesStreamEvent(t *testing.T) {
+	chunks := []*schemas.BifrostStream{
+		{BifrostChatResponse: &schemas.BifrostChatResponse{ID: "chat"}},
+		{BifrostResponsesStreamResponse: &schemas.BifrostResponsesStreamResponse{Type: schemas.ResponsesStreamResponseTypeCompleted}},
+	}
+	for _, chunk := range chunks {
+		var written, expected bytes.Buffer
+		w := bufio.NewWriter(&written)
+		eventType, ok, err := writeStreamChunk(w, chunk)
+		if !ok || err != nil {
+			t.Fatalf("expected the chunk to be written, got %v, %v", ok, err)
+		}
+		w.Flush()
+
+		event, _ := newStreamEvent(chunk)
+		e := bufio.NewWriter(&expected)
+		writeStreamEvent(e, "", event)
+		e.Flush()
+		if written.String() != expected.String() || eventType != event.eventType {
+			t.Errorf("expected %q, got %q", expected.String(), written.String())
+		}
+	}
+	if _, ok, _ := writeStreamChunk(bufio.NewWriter(&bytes.Buffer{}), nil); ok {
+		t.Error("expected nil chunks to be skipped")
+	}
+}
@@ -0,0 +1,245 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the async transcription job handlers.
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/fasthttp/router"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// TranscriptionJobsHandler manages uploads and async transcription jobs
+type TranscriptionJobsHandler struct {
+	manager *lib.TranscriptionJobManager
+}
+
+// NewTranscriptionJobsHandler creates a new transcription jobs handler instance
+func NewTranscriptionJobsHandler(manager *lib.TranscriptionJobManager) *TranscriptionJobsHandler {
+	return &TranscriptionJobsHandler{
+		manager: manager,
+	}
+}
+
+// RegisterRoutes registers the transcription job routes
+func (h *TranscriptionJobsHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
+	r.POST("/api/transcriptions/uploads", lib.ChainMiddlewares(h.createUpload, middlewares...))
+	r.PUT("/api/transcriptions/uploads/{upload_id}", lib.ChainMiddlewares(h.appendUpload, middlewares...))
+	r.DELETE("/api/transcriptions/uploads/{upload_id}", lib.ChainMiddlewares(h.deleteUpload, middlewares...))
+	r.POST("/api/transcriptions/jobs", lib.ChainMiddlewares(h.createJob, middlewares...))
+	r.GET("/api/transcriptions/jobs", lib.ChainMiddlewares(h.listJobs, middlewares...))
+	r.GET("/api/transcriptions/jobs/{job_id}", lib.ChainMiddlewares(h.getJob, middlewares...))
+	r.POST("/api/transcriptions/jobs/{job_id}/cancel", lib.ChainMiddlewares(h.cancelJob, middlewares...))
+}
+
+// createUpload handles POST /api/transcriptions/uploads - Start uploading a file in parts
+func (h *TranscriptionJobsHandler) createUpload(ctx *fasthttp.RequestCtx) {
+	upload, err := h.manager.CreateUpload()
+	if err != nil {
+		SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to create upload: %v", err))
+		return
+	}
+	SendJSONWithStatus(ctx, map[string]any{
+		"upload": upload,
+	}, fasthttp.StatusCreated)
+}
+
+// appendUpload handles PUT /api/transcriptions/uploads/{upload_id}?offset=<offset> - Append the body to an upload.
+// The offset is the size uploaded so far, a part sent at a stale offset is rejected with 409 and the current size.
+func (h *TranscriptionJobsHandler) appendUpload(ctx *fasthttp.RequestCtx) {
+	uploadID := ctx.UserValue("upload_id").(string)
+	offset, err := strconv.ParseInt(string(ctx.QueryArgs().Peek("offset")), 10, 64)
+	if err != nil || offset < 0 {
+		SendError(ctx, fasthttp.StatusBadRequest, "offset query parameter must be a non-negative integer")
+		return
+	}
+	upload, err := h.manager.AppendUpload(uploadID, offset, bytes.NewReader(ctx.PostBody()))
+	if err != nil {
+		switch {
+		case errors.Is(err, lib.ErrNotFound):
+			SendError(ctx, fasthttp.StatusNotFound, "Upload not found")
+		case errors.Is(err, lib.ErrUploadOffsetMismatch):
+			SendError(ctx, fasthttp.StatusConflict, err.Error())
+		default:
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Failed to append to upload: %v", err))
+		}
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"upload": upload,
+	})
+}
+
+// deleteUpload handles DELETE /api/transcriptions/uploads/{upload_id} - Discard an upload
+func (h *TranscriptionJobsHandler) deleteUpload(ctx *fasthttp.RequestCtx) {
+	uploadID := ctx.UserValue("upload_id").(string)
+	if err := h.manager.DeleteUpload(uploadID); err != nil {
+		if errors.Is(err, lib.ErrNotFound) {
+			SendError(ctx, fasthttp.StatusNotFound, "Upload not found")
+			return
+		}
+		SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to delete upload: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message": "Upload deleted",
+	})
+}
+
+// createJob handles POST /api/transcriptions/jobs - Start transcribing a file in the background.
+// The multipart form takes the fields of /v1/audio/transcriptions, with either the file or the upload_id of a
+// completed upload.
+func (h *TranscriptionJobsHandler) createJob(ctx *fasthttp.RequestCtx) {
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Failed to parse multipart form: %v", err))
+		return
+	}
+	value := func(key string) string {
+		if values := form.Value[key]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	provider, model := schemas.ParseModelString(value("model"), "")
+	if provider == "" || model == "" {
+		SendError(ctx, fasthttp.StatusBadRequest, "model should be in provider/model format")
+		return
+	}
+	fallbacks, err := parseFallbacks(form.Value["fallbacks"])
+	if err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	params := &schemas.TranscriptionParameters{
+		ExtraParams: make(map[string]interface{}),
+	}
+	if language := value("language"); language != "" {
+		params.Language = &language
+	}
+	if prompt := value("prompt"); prompt != "" {
+		params.Prompt = &prompt
+	}
+	if responseFormat := value("response_format"); responseFormat != "" {
+		params.ResponseFormat = &responseFormat
+	}
+	for key, values := range form.Value {
+		if len(values) > 0 && values[0] != "" && !transcriptionParamsKnownFields[key] && key != "upload_id" {
+			params.ExtraParams[key] = values[0]
+		}
+	}
+
+	uploadID := value("upload_id")
+	if fileHeaders := form.File["file"]; len(fileHeaders) > 0 {
+		if uploadID != "" {
+			SendError(ctx, fasthttp.StatusBadRequest, "Send either a file or an upload_id, not both")
+			return
+		}
+		file, err := fileHeaders[0].Open()
+		if err != nil {
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Failed to open uploaded file: %v", err))
+			return
+		}
+		defer file.Close()
+		upload, err := h.manager.CreateUpload()
+		if err != nil {
+			SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to store uploaded file: %v", err))
+			return
+		}
+		if _, err := h.manager.AppendUpload(upload.ID, 0, file); err != nil {
+			h.manager.DeleteUpload(upload.ID)
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Failed to store uploaded file: %v", err))
+			return
+		}
+		uploadID = upload.ID
+	}
+	if uploadID == "" {
+		SendError(ctx, fasthttp.StatusBadRequest, "file or upload_id is required")
+		return
+	}
+
+	request := lib.TranscriptionJobRequest{
+		Provider:  provider,
+		Model:     model,
+		Params:    params,
+		Fallbacks: fallbacks,
+	}
+	// Chunks are transcribed after the request returned, so the virtual key of the request is carried over
+	if bifrostCtx, cancel := lib.ConvertToBifrostContext(ctx, false); bifrostCtx != nil {
+		if virtualKey, ok := (*bifrostCtx).Value(schemas.BifrostContextKeyVirtualKey).(string); ok {
+			request.VirtualKey = virtualKey
+		}
+		cancel()
+	}
+
+	job, err := h.manager.StartJob(uploadID, request)
+	if err != nil {
+		if errors.Is(err, lib.ErrNotFound) {
+			SendError(ctx, fasthttp.StatusNotFound, "Upload not found")
+			return
+		}
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Failed to start transcription job: %v", err))
+		return
+	}
+	SendJSONWithStatus(ctx, map[string]any{
+		"message": "Transcription job started",
+		"job":     job,
+	}, fasthttp.StatusAccepted)
+}
+
+// listJobs handles GET /api/transcriptions/jobs - List recent transcription jobs with their progress
+func (h *TranscriptionJobsHandler) listJobs(ctx *fasthttp.RequestCtx) {
+	jobs := h.manager.ListJobs()
+	SendJSON(ctx, map[string]any{
+		"jobs":  jobs,
+		"count": len(jobs),
+	})
+}
+
+// getJob handles GET /api/transcriptions/jobs/{job_id} - Get the progress of a transcription job, with its
+// transcript once it completed
+func (h *TranscriptionJobsHandler) getJob(ctx *fasthttp.RequestCtx) {
+	jobID := ctx.UserValue("job_id").(string)
+	job, err := h.manager.GetJob(jobID)
+	if err != nil {
+		h.sendJobError(ctx, err)
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"job": job,
+	})
+}
+
+// cancelJob handles POST /api/transcriptions/jobs/{job_id}/cancel - Cancel a transcription job
+func (h *TranscriptionJobsHandler) cancelJob(ctx *fasthttp.RequestCtx) {
+	jobID := ctx.UserValue("job_id").(string)
+	job, err := h.manager.CancelJob(jobID)
+	if err != nil {
+		h.sendJobError(ctx, err)
+		return
+	}
+	message := "Transcription job cancelled"
+	if job.Status != lib.TranscriptionJobRunning && job.Status != lib.TranscriptionJobPending {
+		message = fmt.Sprintf("Transcription job is %s", job.Status)
+	}
+	SendJSON(ctx, map[string]any{
+		"message": message,
+		"job":     job,
+	})
+}
+
+// sendJobError sends the error of a job lookup
+func (h *TranscriptionJobsHandler) sendJobError(ctx *fasthttp.RequestCtx, err error) {
+	if errors.Is(err, lib.ErrNotFound) {
+		SendError(ctx, fasthttp.StatusNotFound, "Transcription job not found")
+		return
+	}
+	SendError(ctx, fasthttp.StatusInternalServerError, err.Error())
+}
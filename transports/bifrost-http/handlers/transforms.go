@@ -0,0 +1,207 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the transform rule management handlers.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fasthttp/router"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// TransformRuleManager applies the transform rules of the config store to the bifrost client
+type TransformRuleManager interface {
+	ReloadTransformRules(ctx context.Context) error
+}
+
+// TransformRulesHandler manages HTTP requests for transform rule operations
+type TransformRulesHandler struct {
+	configStore          configstore.ConfigStore
+	transformRuleManager TransformRuleManager
+}
+
+// NewTransformRulesHandler creates a new transform rules handler instance
+func NewTransformRulesHandler(manager TransformRuleManager, configStore configstore.ConfigStore) (*TransformRulesHandler, error) {
+	if configStore == nil {
+		return nil, fmt.Errorf("config store is required")
+	}
+	return &TransformRulesHandler{
+		configStore:          configStore,
+		transformRuleManager: manager,
+	}, nil
+}
+
+// UpsertTransformRuleRequest represents the request body for creating or updating a transform rule
+type UpsertTransformRuleRequest struct {
+	Name          string                       `json:"name,omitempty"` // Only read on create, the name of a rule cannot change
+	Description   string                       `json:"description,omitempty"`
+	Priority      int                          `json:"priority,omitempty"`
+	Models        []string                     `json:"models,omitempty"`
+	VirtualKeyIDs []string                     `json:"virtual_key_ids,omitempty"`
+	Enforcement   schemas.TransformEnforcement `json:"enforcement,omitempty"`
+	Actions       []schemas.TransformAction    `json:"actions"`
+}
+
+// RegisterRoutes registers all transform rule management routes
+func (h *TransformRulesHandler) RegisterRoutes(r *router.Router, middlewares ...lib.BifrostHTTPMiddleware) {
+	r.GET("/api/transform-rules", lib.ChainMiddlewares(h.getTransformRules, middlewares...))
+	r.POST("/api/transform-rules", lib.ChainMiddlewares(h.createTransformRule, middlewares...))
+	r.GET("/api/transform-rules/{name}", lib.ChainMiddlewares(h.getTransformRule, middlewares...))
+	r.PUT("/api/transform-rules/{name}", lib.ChainMiddlewares(h.updateTransformRule, middlewares...))
+	r.DELETE("/api/transform-rules/{name}", lib.ChainMiddlewares(h.deleteTransformRule, middlewares...))
+}
+
+// getTransformRules handles GET /api/transform-rules - Get all transform rules in the order they apply
+func (h *TransformRulesHandler) getTransformRules(ctx *fasthttp.RequestCtx) {
+	rules, err := h.configStore.GetTransformRules(ctx)
+	if err != nil {
+		logger.Error("failed to retrieve transform rules: %v", err)
+		SendError(ctx, 500, "Failed to retrieve transform rules")
+		return
+	}
+	SendJSON(ctx, map[string]interface{}{
+		"transform_rules": rules,
+		"count":           len(rules),
+	})
+}
+
+// createTransformRule handles POST /api/transform-rules - Create a new transform rule
+func (h *TransformRulesHandler) createTransformRule(ctx *fasthttp.RequestCtx) {
+	var req UpsertTransformRuleRequest
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		SendError(ctx, 400, "Invalid JSON")
+		return
+	}
+	rule := configstoreTables.TableTransformRule{
+		Name:          req.Name,
+		Description:   req.Description,
+		Priority:      req.Priority,
+		Models:        req.Models,
+		VirtualKeyIDs: req.VirtualKeyIDs,
+		Enforcement:   req.Enforcement,
+		Actions:       req.Actions,
+	}
+	if err := h.validateTransformRule(ctx, &rule); err != nil {
+		SendError(ctx, 400, err.Error())
+		return
+	}
+	if err := h.configStore.CreateTransformRule(ctx, &rule); err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			SendError(ctx, 409, err.Error())
+			return
+		}
+		SendError(ctx, 500, fmt.Sprintf("Failed to create transform rule: %v", err))
+		return
+	}
+	if err := h.transformRuleManager.ReloadTransformRules(ctx); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to reload transform rules: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message":        "Transform rule created successfully",
+		"transform_rule": rule,
+	})
+}
+
+// getTransformRule handles GET /api/transform-rules/{name} - Get a specific transform rule
+func (h *TransformRulesHandler) getTransformRule(ctx *fasthttp.RequestCtx) {
+	name := ctx.UserValue("name").(string)
+	rule, err := h.configStore.GetTransformRule(ctx, name)
+	if err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Transform rule not found")
+			return
+		}
+		SendError(ctx, 500, "Failed to retrieve transform rule")
+		return
+	}
+	SendJSON(ctx, map[string]interface{}{
+		"transform_rule": rule,
+	})
+}
+
+// updateTransformRule handles PUT /api/transform-rules/{name} - Replace the actions and attachments of a transform rule
+func (h *TransformRulesHandler) updateTransformRule(ctx *fasthttp.RequestCtx) {
+	name := ctx.UserValue("name").(string)
+	var req UpsertTransformRuleRequest
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		SendError(ctx, 400, "Invalid JSON")
+		return
+	}
+	rule, err := h.configStore.GetTransformRule(ctx, name)
+	if err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Transform rule not found")
+			return
+		}
+		SendError(ctx, 500, "Failed to retrieve transform rule")
+		return
+	}
+	rule.Description = req.Description
+	rule.Priority = req.Priority
+	rule.Models = req.Models
+	rule.VirtualKeyIDs = req.VirtualKeyIDs
+	rule.Enforcement = req.Enforcement
+	rule.Actions = req.Actions
+	if err := h.validateTransformRule(ctx, rule); err != nil {
+		SendError(ctx, 400, err.Error())
+		return
+	}
+	if err := h.configStore.UpdateTransformRule(ctx, rule); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to update transform rule: %v", err))
+		return
+	}
+	if err := h.transformRuleManager.ReloadTransformRules(ctx); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to reload transform rules: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message":        "Transform rule updated successfully",
+		"transform_rule": rule,
+	})
+}
+
+// deleteTransformRule handles DELETE /api/transform-rules/{name} - Delete a transform rule
+func (h *TransformRulesHandler) deleteTransformRule(ctx *fasthttp.RequestCtx) {
+	name := ctx.UserValue("name").(string)
+	if err := h.configStore.DeleteTransformRule(ctx, name); err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			SendError(ctx, 404, "Transform rule not found")
+			return
+		}
+		SendError(ctx, 500, fmt.Sprintf("Failed to delete transform rule: %v", err))
+		return
+	}
+	if err := h.transformRuleManager.ReloadTransformRules(ctx); err != nil {
+		SendError(ctx, 500, fmt.Sprintf("Failed to reload transform rules: %v", err))
+		return
+	}
+	SendJSON(ctx, map[string]any{
+		"message": "Transform rule deleted successfully",
+	})
+}
+
+// validateTransformRule validates the rule on its own and checks that the virtual keys it applies to exist
+func (h *TransformRulesHandler) validateTransformRule(ctx context.Context, rule *configstoreTables.TableTransformRule) error {
+	schemaRule := rule.ToSchema()
+	if err := schemaRule.Validate(); err != nil {
+		return err
+	}
+	for _, vkID := range rule.VirtualKeyIDs {
+		if _, err := h.configStore.GetVirtualKey(ctx, vkID); err != nil {
+			if errors.Is(err, configstore.ErrNotFound) {
+				return fmt.Errorf("virtual key %s not found", vkID)
+			}
+			return fmt.Errorf("failed to retrieve virtual key %s: %v", vkID, err)
+		}
+	}
+	return nil
+}
@@ -693,8 +693,16 @@ func (g *GenericRouter) handleStreamingRequest(ctx *fasthttp.RequestCtx, config
 // Bifrost handles cleanup internally for normal completion and errors, so we only cancel
 // upstream streams when write errors indicate the client has disconnected.
 func (g *GenericRouter) handleStreaming(ctx *fasthttp.RequestCtx, bifrostCtx *context.Context, config RouteConfig, streamChan chan *schemas.BifrostStream, cancel context.CancelFunc) {
-	// Use streaming response writer
-	ctx.Response.SetBodyStreamWriter(func(w *bufio.Writer) {
+	// Client disconnects cancel the upstream stream, and are accounted with what the stream generated so far
+	requestID, _ := (*bifrostCtx).Value(schemas.BifrostContextKeyRequestID).(string)
+	usage := lib.NewStreamUsage(config.Path)
+	clientDisconnected := func() {
+		cancel()
+		g.handlerStore.GetStreamCancellations().RecordClientDisconnect(requestID, usage)
+	}
+
+	// Use streaming response writer, tracked so that a drain waits for the end of the stream
+	ctx.Response.SetBodyStreamWriter(g.handlerStore.GetDrain().TrackStream(func(w *bufio.Writer) {
 		defer w.Flush()
 
 		// Create encoder for AWS Event Stream if needed
@@ -713,7 +721,7 @@ func (g *GenericRouter) handleStreaming(ctx *fasthttp.RequestCtx, bifrostCtx *co
 
 			// Note: We no longer check ctx.Done() here because fasthttp.RequestCtx.Done()
 			// only closes when the whole server shuts down, not when an individual client disconnects.
-			// Client disconnects are detected via write errors, which trigger the defer cancel() above.
+			// Client disconnects are detected via write errors, which cancel the upstream stream below.
 
 			// Handle errors
 			if chunk.BifrostError != nil {
@@ -778,6 +786,8 @@ func (g *GenericRouter) handleStreaming(ctx *fasthttp.RequestCtx, bifrostCtx *co
 				}
 				return // End stream on error, Bifrost handles cleanup internally
 			} else {
+				usage.Add(chunk)
+
 				// Handle successful responses
 				// Convert response to integration-specific streaming format
 				var eventType string
@@ -818,7 +828,7 @@ func (g *GenericRouter) handleStreaming(ctx *fasthttp.RequestCtx, bifrostCtx *co
 				if eventType != "" {
 					// OPENAI RESPONSES FORMAT: Use event: and data: lines for OpenAI responses API compatibility
 					if _, err := fmt.Fprintf(w, "event: %s\n", eventType); err != nil {
-						cancel() // Client disconnected (write error), cancel upstream stream
+						clientDisconnected() // Client disconnected (write error), cancel upstream stream
 						return
 					}
 				}
@@ -860,14 +870,14 @@ func (g *GenericRouter) handleStreaming(ctx *fasthttp.RequestCtx, bifrostCtx *co
 
 							if err := eventStreamEncoder.Encode(w, message); err != nil {
 								log.Printf("[Bedrock Stream] Failed to encode message: %v", err)
-								cancel()
+								clientDisconnected()
 								return
 							}
 
 							// Flush each message to ensure proper delivery
 							if err := w.Flush(); err != nil {
 								log.Printf("[Bedrock Stream] Failed to flush writer: %v", err)
-								cancel()
+								clientDisconnected()
 								return
 							}
 						}
@@ -882,7 +892,7 @@ func (g *GenericRouter) handleStreaming(ctx *fasthttp.RequestCtx, bifrostCtx *co
 						sseString = fmt.Sprintf("data: %s\n\n", sseString)
 					}
 					if _, err := fmt.Fprint(w, sseString); err != nil {
-						cancel() // Client disconnected (write error), cancel upstream stream
+						clientDisconnected() // Client disconnected (write error), cancel upstream stream
 						return
 					}
 				} else {
@@ -898,14 +908,14 @@ func (g *GenericRouter) handleStreaming(ctx *fasthttp.RequestCtx, bifrostCtx *co
 
 					// Send as SSE data
 					if _, err := fmt.Fprintf(w, "data: %s\n\n", responseJSON); err != nil {
-						cancel() // Client disconnected (write error), cancel upstream stream
+						clientDisconnected() // Client disconnected (write error), cancel upstream stream
 						return
 					}
 				}
 
 				// Flush immediately to send the chunk
 				if err := w.Flush(); err != nil {
-					cancel() // Client disconnected (write error), cancel upstream stream
+					clientDisconnected() // Client disconnected (write error), cancel upstream stream
 					return
 				}
 			}
@@ -923,5 +933,5 @@ func (g *GenericRouter) handleStreaming(ctx *fasthttp.RequestCtx, bifrostCtx *co
 				return // End stream on error, Bifrost handles cleanup internally
 			}
 		}
-	})
+	}))
 }
@@ -5,8 +5,12 @@ package lib
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+	"github.com/maximhq/bifrost/framework/envutils"
 )
 
 // BaseAccount implements the Account interface for Bifrost.
@@ -49,6 +53,16 @@ func (baseAccount *BaseAccount) GetKeysForProvider(ctx *context.Context, provide
 	keys := config.Keys
 
 	if baseAccount.store.ClientConfig.EnableGovernance {
+		if namespace, ok := (*ctx).Value(schemas.BifrostContextKey("bf-governance-namespace")).(string); ok && namespace != "" {
+			// virtual keys only use the providers of their namespace
+			providerNamespace := config.Namespace
+			if providerNamespace == "" {
+				providerNamespace = configstoreTables.DefaultNamespace
+			}
+			if providerNamespace != namespace {
+				return nil, fmt.Errorf("provider %s is not in namespace %s of the virtual key", providerKey, namespace)
+			}
+		}
 		if v := (*ctx).Value(schemas.BifrostContextKey("bf-governance-include-only-keys")); v != nil {
 			if includeOnlyKeys, ok := v.([]string); ok {
 				if len(includeOnlyKeys) == 0 {
@@ -69,6 +83,39 @@ func (baseAccount *BaseAccount) GetKeysForProvider(ctx *context.Context, provide
 				}
 			}
 		}
+		if v := (*ctx).Value(schemas.BifrostContextKey("bf-governance-exclude-keys")); v != nil {
+			if excludeKeys, ok := v.([]string); ok && len(excludeKeys) > 0 {
+				// test keys that reached their spend limit
+				filtered := make([]schemas.Key, 0, len(keys))
+				for _, key := range keys {
+					if !slices.Contains(excludeKeys, key.ID) {
+						filtered = append(filtered, key)
+					}
+				}
+				keys = filtered
+			}
+		}
+		if v := (*ctx).Value(schemas.BifrostContextKey("bf-governance-allowed-regions")); v != nil {
+			if allowedRegions, ok := v.([]string); ok {
+				// residency policy of the virtual key, keys without a region are never selected
+				filtered := make([]schemas.Key, 0, len(keys))
+				for _, key := range keys {
+					if key.Region != "" && slices.Contains(allowedRegions, key.Region) {
+						filtered = append(filtered, key)
+					}
+				}
+				keys = filtered
+			}
+		}
+	} else {
+		// test key spend is only tracked by governance, so test keys are never used without it
+		filtered := make([]schemas.Key, 0, len(keys))
+		for _, key := range keys {
+			if !key.IsTest {
+				filtered = append(filtered, key)
+			}
+		}
+		keys = filtered
 	}
 
 	return keys, nil
@@ -87,10 +134,26 @@ func (baseAccount *BaseAccount) GetConfigForProvider(providerKey schemas.ModelPr
 		return nil, err
 	}
 
+	return BifrostProviderConfig(config), nil
+}
+
+// BifrostProviderConfig converts a stored provider config to the config of the provider in Bifrost,
+// applying the default network, concurrency and buffer settings
+func BifrostProviderConfig(config *configstore.ProviderConfig) *schemas.ProviderConfig {
 	providerConfig := &schemas.ProviderConfig{}
 
 	if config.ProxyConfig != nil {
-		providerConfig.ProxyConfig = config.ProxyConfig
+		// The credentials of the proxy can reference environment variables and secret files,
+		// resolved whenever the provider is (re)loaded
+		proxyConfig := *config.ProxyConfig
+		var err error
+		if proxyConfig.Username, err = envutils.ProcessEnvValue(proxyConfig.Username); err == nil {
+			proxyConfig.Password, err = envutils.ProcessEnvValue(proxyConfig.Password)
+		}
+		if err != nil {
+			logger.Warn("failed to resolve the proxy credentials: %v", err)
+		}
+		providerConfig.ProxyConfig = &proxyConfig
 	}
 
 	if config.NetworkConfig != nil {
@@ -111,5 +174,7 @@ func (baseAccount *BaseAccount) GetConfigForProvider(providerKey schemas.ModelPr
 		providerConfig.CustomProviderConfig = config.CustomProviderConfig
 	}
 
-	return providerConfig, nil
+	providerConfig.MockConfig = config.MockConfig
+
+	return providerConfig
 }
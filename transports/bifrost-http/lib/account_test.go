@@ -0,0 +1,51 @@
+package lib
+
+import (
+	"context"
+	"testing"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+)
+
+// TestGetKeysForProvider_Namespace tests that virtual keys only get the keys of the providers of their namespace
+func TestGetKeysForProvider_Namespace(t *testing.T) {
+	account := NewBaseAccount(&Config{
+		ClientConfig: configstore.ClientConfig{EnableGovernance: true},
+		Providers: map[schemas.ModelProvider]configstore.ProviderConfig{
+			"openai":      {Keys: []schemas.Key{{ID: "default-key", Value: "sk-default"}}},
+			"acme.openai": {Namespace: "acme", Keys: []schemas.Key{{ID: "acme-key", Value: "sk-acme"}}},
+		},
+	})
+
+	tests := map[string]struct {
+		namespace string
+		provider  schemas.ModelProvider
+		expected  string
+	}{
+		"no virtual key, default provider":    {provider: "openai", expected: "default-key"},
+		"no virtual key, namespace provider":  {provider: "acme.openai", expected: "acme-key"},
+		"default virtual key, default":        {namespace: "default", provider: "openai", expected: "default-key"},
+		"default virtual key, other":          {namespace: "default", provider: "acme.openai"},
+		"namespace virtual key, own provider": {namespace: "acme", provider: "acme.openai", expected: "acme-key"},
+		"namespace virtual key, default":      {namespace: "acme", provider: "openai"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.namespace != "" {
+				ctx = context.WithValue(ctx, schemas.BifrostContextKey("bf-governance-namespace"), test.namespace)
+			}
+			keys, err := account.GetKeysForProvider(&ctx, test.provider)
+			if test.expected == "" {
+				if err == nil {
+					t.Errorf("expected the keys of another namespace to be refused, got %v", keys)
+				}
+				return
+			}
+			if err != nil || len(keys) != 1 || keys[0].ID != test.expected {
+				t.Errorf("expected key %s, got %v (%v)", test.expected, keys, err)
+			}
+		})
+	}
+}
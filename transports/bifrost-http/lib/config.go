@@ -23,6 +23,7 @@ import (
 	"github.com/maximhq/bifrost/framework/configstore"
 	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
 	"github.com/maximhq/bifrost/framework/encrypt"
+	"github.com/maximhq/bifrost/framework/envutils"
 	"github.com/maximhq/bifrost/framework/logstore"
 	"github.com/maximhq/bifrost/framework/modelcatalog"
 	"github.com/maximhq/bifrost/framework/vectorstore"
@@ -36,6 +37,10 @@ import (
 type HandlerStore interface {
 	// ShouldAllowDirectKeys returns whether direct API keys in headers are allowed
 	ShouldAllowDirectKeys() bool
+	// GetStreamCancellations returns the accounting of the streams cancelled because their client disconnected
+	GetStreamCancellations() *StreamCancellations
+	// GetDrain returns the tracking of the in-flight inference requests and streams
+	GetDrain() *Drain
 }
 
 // Retry backoff constants for validation
@@ -48,17 +53,27 @@ const (
 // It contains the client configuration, provider configurations, MCP configuration,
 // vector store configuration, config store configuration, and logs store configuration.
 type ConfigData struct {
-	Client            *configstore.ClientConfig             `json:"client"`
-	EncryptionKey     string                                `json:"encryption_key"`
-	AuthConfig        *configstore.AuthConfig               `json:"auth_config,omitempty"`
-	Providers         map[string]configstore.ProviderConfig `json:"providers"`
-	FrameworkConfig   *framework.FrameworkConfig            `json:"framework,omitempty"`
-	MCP               *schemas.MCPConfig                    `json:"mcp,omitempty"`
-	Governance        *configstore.GovernanceConfig         `json:"governance,omitempty"`
-	VectorStoreConfig *vectorstore.Config                   `json:"vector_store,omitempty"`
-	ConfigStoreConfig *configstore.Config                   `json:"config_store,omitempty"`
-	LogsStoreConfig   *logstore.Config                      `json:"logs_store,omitempty"`
-	Plugins           []*schemas.PluginConfig               `json:"plugins,omitempty"`
+	Client             *configstore.ClientConfig             `json:"client"`
+	EncryptionKey      string                                `json:"encryption_key"`
+	AuthConfig         *configstore.AuthConfig               `json:"auth_config,omitempty"`
+	JWTAuth            *JWTAuthConfig                        `json:"jwt_auth,omitempty"`
+	Probes             *ProbesConfig                         `json:"probes,omitempty"`
+	Ingestion          *IngestionConfig                      `json:"ingestion,omitempty"`
+	TranscriptionJobs  *TranscriptionJobsConfig              `json:"transcription_jobs,omitempty"`
+	DatasetExports     *DatasetExportsConfig                 `json:"dataset_exports,omitempty"`
+	LogArchive         *LogArchiveConfig                     `json:"log_archive,omitempty"`
+	Conversations      *ConversationsConfig                  `json:"conversations,omitempty"`
+	DataRetention      *DataRetentionConfig                  `json:"data_retention,omitempty"`
+	KubernetesOperator *KubernetesOperatorConfig             `json:"kubernetes_operator,omitempty"`
+	Logging            *schemas.LoggingConfig                `json:"logging,omitempty"`
+	Providers          map[string]configstore.ProviderConfig `json:"providers"`
+	FrameworkConfig    *framework.FrameworkConfig            `json:"framework,omitempty"`
+	MCP                *schemas.MCPConfig                    `json:"mcp,omitempty"`
+	Governance         *configstore.GovernanceConfig         `json:"governance,omitempty"`
+	VectorStoreConfig  *vectorstore.Config                   `json:"vector_store,omitempty"`
+	ConfigStoreConfig  *configstore.Config                   `json:"config_store,omitempty"`
+	LogsStoreConfig    *logstore.Config                      `json:"logs_store,omitempty"`
+	Plugins            []*schemas.PluginConfig               `json:"plugins,omitempty"`
 }
 
 // UnmarshalJSON umarshals the ConfigData from JSON using internal unmarshallers
@@ -67,17 +82,27 @@ type ConfigData struct {
 func (cd *ConfigData) UnmarshalJSON(data []byte) error {
 	// First, unmarshal into a temporary struct to get all fields except the complex configs
 	type TempConfigData struct {
-		FrameworkConfig   json.RawMessage                       `json:"framework,omitempty"`
-		Client            *configstore.ClientConfig             `json:"client"`
-		EncryptionKey     string                                `json:"encryption_key"`
-		AuthConfig        *configstore.AuthConfig               `json:"auth_config,omitempty"`
-		Providers         map[string]configstore.ProviderConfig `json:"providers"`
-		MCP               *schemas.MCPConfig                    `json:"mcp,omitempty"`
-		Governance        *configstore.GovernanceConfig         `json:"governance,omitempty"`
-		VectorStoreConfig json.RawMessage                       `json:"vector_store,omitempty"`
-		ConfigStoreConfig json.RawMessage                       `json:"config_store,omitempty"`
-		LogsStoreConfig   json.RawMessage                       `json:"logs_store,omitempty"`
-		Plugins           []*schemas.PluginConfig               `json:"plugins,omitempty"`
+		FrameworkConfig    json.RawMessage                       `json:"framework,omitempty"`
+		Client             *configstore.ClientConfig             `json:"client"`
+		EncryptionKey      string                                `json:"encryption_key"`
+		AuthConfig         *configstore.AuthConfig               `json:"auth_config,omitempty"`
+		JWTAuth            *JWTAuthConfig                        `json:"jwt_auth,omitempty"`
+		Probes             *ProbesConfig                         `json:"probes,omitempty"`
+		Ingestion          *IngestionConfig                      `json:"ingestion,omitempty"`
+		TranscriptionJobs  *TranscriptionJobsConfig              `json:"transcription_jobs,omitempty"`
+		DatasetExports     *DatasetExportsConfig                 `json:"dataset_exports,omitempty"`
+		LogArchive         *LogArchiveConfig                     `json:"log_archive,omitempty"`
+		Conversations      *ConversationsConfig                  `json:"conversations,omitempty"`
+		DataRetention      *DataRetentionConfig                  `json:"data_retention,omitempty"`
+		KubernetesOperator *KubernetesOperatorConfig             `json:"kubernetes_operator,omitempty"`
+		Logging            *schemas.LoggingConfig                `json:"logging,omitempty"`
+		Providers          map[string]configstore.ProviderConfig `json:"providers"`
+		MCP                *schemas.MCPConfig                    `json:"mcp,omitempty"`
+		Governance         *configstore.GovernanceConfig         `json:"governance,omitempty"`
+		VectorStoreConfig  json.RawMessage                       `json:"vector_store,omitempty"`
+		ConfigStoreConfig  json.RawMessage                       `json:"config_store,omitempty"`
+		LogsStoreConfig    json.RawMessage                       `json:"logs_store,omitempty"`
+		Plugins            []*schemas.PluginConfig               `json:"plugins,omitempty"`
 	}
 
 	var temp TempConfigData
@@ -89,6 +114,16 @@ func (cd *ConfigData) UnmarshalJSON(data []byte) error {
 	cd.Client = temp.Client
 	cd.EncryptionKey = temp.EncryptionKey
 	cd.AuthConfig = temp.AuthConfig
+	cd.JWTAuth = temp.JWTAuth
+	cd.Probes = temp.Probes
+	cd.Ingestion = temp.Ingestion
+	cd.TranscriptionJobs = temp.TranscriptionJobs
+	cd.DatasetExports = temp.DatasetExports
+	cd.LogArchive = temp.LogArchive
+	cd.Conversations = temp.Conversations
+	cd.DataRetention = temp.DataRetention
+	cd.KubernetesOperator = temp.KubernetesOperator
+	cd.Logging = temp.Logging
 	cd.Providers = temp.Providers
 	cd.MCP = temp.MCP
 	cd.Governance = temp.Governance
@@ -156,12 +191,22 @@ type Config struct {
 	LogsStore   logstore.LogStore
 
 	// In-memory storage
-	ClientConfig     configstore.ClientConfig
-	Providers        map[schemas.ModelProvider]configstore.ProviderConfig
-	MCPConfig        *schemas.MCPConfig
-	GovernanceConfig *configstore.GovernanceConfig
-	FrameworkConfig  *framework.FrameworkConfig
-	ProxyConfig      *configstoreTables.GlobalProxyConfig
+	ClientConfig             configstore.ClientConfig
+	Providers                map[schemas.ModelProvider]configstore.ProviderConfig
+	MCPConfig                *schemas.MCPConfig
+	GovernanceConfig         *configstore.GovernanceConfig
+	FrameworkConfig          *framework.FrameworkConfig
+	ProxyConfig              *configstoreTables.GlobalProxyConfig
+	JWTAuthConfig            *JWTAuthConfig            // Only read from the config file
+	ProbesConfig             *ProbesConfig             // Only read from the config file
+	IngestionConfig          *IngestionConfig          // Only read from the config file
+	TranscriptionJobsConfig  *TranscriptionJobsConfig  // Only read from the config file
+	DatasetExportsConfig     *DatasetExportsConfig     // Only read from the config file
+	LogArchiveConfig         *LogArchiveConfig         // Only read from the config file
+	ConversationsConfig      *ConversationsConfig      // Only read from the config file
+	DataRetentionConfig      *DataRetentionConfig      // Only read from the config file
+	KubernetesOperatorConfig *KubernetesOperatorConfig // Only read from the config file
+	LoggingConfig            *schemas.LoggingConfig    // Only read from the config file
 
 	// Track which keys come from environment variables
 	EnvKeys map[string][]configstore.EnvKeyInfo
@@ -174,6 +219,15 @@ type Config struct {
 
 	// Pricing manager
 	PricingManager *modelcatalog.ModelCatalog
+
+	// Accounting of the streams cancelled because their client disconnected
+	StreamCancellations *StreamCancellations
+
+	// In-flight inference requests and streams, and whether new ones are refused
+	Drain *Drain
+
+	// Captures of the slow and large inference requests
+	Diagnostics *Diagnostics
 }
 
 var DefaultClientConfig = configstore.ClientConfig{
@@ -194,7 +248,7 @@ var DefaultClientConfig = configstore.ClientConfig{
 func (c *Config) initializeEncryption(configKey string) error {
 	encryptionKey := ""
 	if configKey != "" {
-		if strings.HasPrefix(configKey, "env.") {
+		if envutils.IsReference(configKey) {
 			var err error
 			if encryptionKey, _, err = c.processEnvValue(configKey); err != nil {
 				return fmt.Errorf("failed to process encryption key: %w", err)
@@ -350,6 +404,11 @@ func LoadConfig(ctx context.Context, configDirPath string) (*Config, error) {
 							AzureKeyConfig:   dbKey.AzureKeyConfig,
 							VertexKeyConfig:  dbKey.VertexKeyConfig,
 							BedrockKeyConfig: dbKey.BedrockKeyConfig,
+							IsTest:           dbKey.IsTest,
+							SpendLimit:       dbKey.SpendLimit,
+							Region:           dbKey.Region,
+							ProxyConfig:      dbKey.ProxyConfig,
+							LastValidation:   dbKey.LastValidation,
 						}
 
 					}
@@ -360,11 +419,16 @@ func LoadConfig(ctx context.Context, configDirPath string) (*Config, error) {
 						ProxyConfig:              dbProvider.ProxyConfig,
 						SendBackRawResponse:      dbProvider.SendBackRawResponse,
 						CustomProviderConfig:     dbProvider.CustomProviderConfig,
+						MockConfig:               dbProvider.MockConfig,
 					}
 					if err := ValidateCustomProvider(providerConfig, provider); err != nil {
 						logger.Warn("invalid custom provider config for %s: %v", provider, err)
 						continue
 					}
+					if err := ValidateKeys(providerConfig.Keys); err != nil {
+						logger.Warn("invalid keys for %s: %v", provider, err)
+						continue
+					}
 					processedProviders[provider] = providerConfig
 				}
 				config.Providers = processedProviders
@@ -418,10 +482,11 @@ func LoadConfig(ctx context.Context, configDirPath string) (*Config, error) {
 				config.PluginConfigs = make([]*schemas.PluginConfig, len(plugins))
 				for i, plugin := range plugins {
 					pluginConfig := &schemas.PluginConfig{
-						Name:    plugin.Name,
-						Enabled: plugin.Enabled,
-						Config:  plugin.Config,
-						Path:    plugin.Path,
+						Name:      plugin.Name,
+						Enabled:   plugin.Enabled,
+						Config:    plugin.Config,
+						Path:      plugin.Path,
+						Execution: plugin.Execution,
 					}
 					if plugin.Name == semanticcache.PluginName {
 						if err := config.AddProviderKeysToSemanticCacheConfig(pluginConfig); err != nil {
@@ -520,6 +585,16 @@ func LoadConfig(ctx context.Context, configDirPath string) (*Config, error) {
 	if err := json.Unmarshal(data, &configData); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	config.JWTAuthConfig = configData.JWTAuth
+	config.ProbesConfig = configData.Probes
+	config.IngestionConfig = configData.Ingestion
+	config.TranscriptionJobsConfig = configData.TranscriptionJobs
+	config.DatasetExportsConfig = configData.DatasetExports
+	config.LogArchiveConfig = configData.LogArchive
+	config.ConversationsConfig = configData.Conversations
+	config.DataRetentionConfig = configData.DataRetention
+	config.KubernetesOperatorConfig = configData.KubernetesOperator
+	config.LoggingConfig = configData.Logging
 
 	// Initializing config store
 	if configData.ConfigStoreConfig != nil && configData.ConfigStoreConfig.Enabled {
@@ -615,6 +690,60 @@ func LoadConfig(ctx context.Context, configDirPath string) (*Config, error) {
 			if !config.ClientConfig.EnableLiteLLMFallbacks && configData.Client.EnableLiteLLMFallbacks {
 				config.ClientConfig.EnableLiteLLMFallbacks = configData.Client.EnableLiteLLMFallbacks
 			}
+			if config.ClientConfig.DirectKeyPolicy == nil && configData.Client.DirectKeyPolicy != nil {
+				config.ClientConfig.DirectKeyPolicy = configData.Client.DirectKeyPolicy
+			}
+			if config.ClientConfig.ImageInputs == nil && configData.Client.ImageInputs != nil {
+				config.ClientConfig.ImageInputs = configData.Client.ImageInputs
+			}
+			if config.ClientConfig.Agent == nil && configData.Client.Agent != nil {
+				config.ClientConfig.Agent = configData.Client.Agent
+			}
+			if config.ClientConfig.ContextWindow == nil && configData.Client.ContextWindow != nil {
+				config.ClientConfig.ContextWindow = configData.Client.ContextWindow
+			}
+			if config.ClientConfig.PromptCompression == nil && configData.Client.PromptCompression != nil {
+				config.ClientConfig.PromptCompression = configData.Client.PromptCompression
+			}
+			if config.ClientConfig.Streaming == nil && configData.Client.Streaming != nil {
+				config.ClientConfig.Streaming = configData.Client.Streaming
+			}
+			if config.ClientConfig.StreamFailover == nil && configData.Client.StreamFailover != nil {
+				config.ClientConfig.StreamFailover = configData.Client.StreamFailover
+			}
+			if config.ClientConfig.StreamBackpressure == nil && configData.Client.StreamBackpressure != nil {
+				config.ClientConfig.StreamBackpressure = configData.Client.StreamBackpressure
+			}
+			if config.ClientConfig.ResponseBuffering == nil && configData.Client.ResponseBuffering != nil {
+				config.ClientConfig.ResponseBuffering = configData.Client.ResponseBuffering
+			}
+			if config.ClientConfig.Idempotency == nil && configData.Client.Idempotency != nil {
+				config.ClientConfig.Idempotency = configData.Client.Idempotency
+			}
+			if config.ClientConfig.EndpointPolicy == nil && configData.Client.EndpointPolicy != nil {
+				config.ClientConfig.EndpointPolicy = configData.Client.EndpointPolicy
+			}
+			if config.ClientConfig.Chaos == nil && configData.Client.Chaos != nil {
+				config.ClientConfig.Chaos = configData.Client.Chaos
+			}
+			if config.ClientConfig.ListModelsCache == nil && configData.Client.ListModelsCache != nil {
+				config.ClientConfig.ListModelsCache = configData.Client.ListModelsCache
+			}
+			if config.ClientConfig.ResponsesState == nil && configData.Client.ResponsesState != nil {
+				config.ClientConfig.ResponsesState = configData.Client.ResponsesState
+			}
+			if config.ClientConfig.RequestTags == nil && configData.Client.RequestTags != nil {
+				config.ClientConfig.RequestTags = configData.Client.RequestTags
+			}
+			if config.ClientConfig.PartialFailures == nil && configData.Client.PartialFailures != nil {
+				config.ClientConfig.PartialFailures = configData.Client.PartialFailures
+			}
+			if config.ClientConfig.Diagnostics == nil && configData.Client.Diagnostics != nil {
+				config.ClientConfig.Diagnostics = configData.Client.Diagnostics
+			}
+			if config.ClientConfig.SLOs == nil && configData.Client.SLOs != nil {
+				config.ClientConfig.SLOs = configData.Client.SLOs
+			}
 
 			// Update store with merged config
 			if config.ConfigStore != nil {
@@ -666,6 +795,10 @@ func LoadConfig(ctx context.Context, configDirPath string) (*Config, error) {
 		for providerName, cfg := range configData.Providers {
 			newEnvKeys := make(map[string]struct{})
 			provider := schemas.ModelProvider(strings.ToLower(providerName))
+			if err := ValidateKeys(cfg.Keys); err != nil {
+				logger.Warn("invalid keys for %s: %v", provider, err)
+				continue
+			}
 			// Process environment variables in keys (including key-level configs)
 			for i, key := range cfg.Keys {
 				if key.ID == "" {
@@ -718,6 +851,15 @@ func LoadConfig(ctx context.Context, configDirPath string) (*Config, error) {
 						continue
 					}
 				}
+
+				// Process the authentication of the proxy of the key if present
+				if key.ProxyConfig != nil {
+					if err := config.processProxyConfigEnvVars(&cfg.Keys[i], provider, newEnvKeys); err != nil {
+						config.cleanupEnvKeys(provider, "", newEnvKeys)
+						logger.Warn("failed to process proxy config env vars for %s: %v", provider, err)
+						continue
+					}
+				}
 			}
 			// Generate hash from config.json provider config
 			fileConfigHash, err := cfg.GenerateConfigHash(string(provider))
@@ -759,6 +901,10 @@ func LoadConfig(ctx context.Context, configDirPath string) (*Config, error) {
 								AzureKeyConfig:   dbKey.AzureKeyConfig,
 								VertexKeyConfig:  dbKey.VertexKeyConfig,
 								BedrockKeyConfig: dbKey.BedrockKeyConfig,
+								IsTest:           dbKey.IsTest,
+								SpendLimit:       dbKey.SpendLimit,
+								Region:           dbKey.Region,
+								ProxyConfig:      dbKey.ProxyConfig,
 							})
 							if err != nil {
 								logger.Fatal("failed to generate key hash for %s (%s): %v", dbKey.Name, provider, err)
@@ -1099,10 +1245,11 @@ func LoadConfig(ctx context.Context, configDirPath string) (*Config, error) {
 			config.PluginConfigs = make([]*schemas.PluginConfig, len(plugins))
 			for i, plugin := range plugins {
 				pluginConfig := &schemas.PluginConfig{
-					Name:    plugin.Name,
-					Enabled: plugin.Enabled,
-					Config:  plugin.Config,
-					Path:    plugin.Path,
+					Name:      plugin.Name,
+					Enabled:   plugin.Enabled,
+					Config:    plugin.Config,
+					Path:      plugin.Path,
+					Execution: plugin.Execution,
 				}
 				if plugin.Name == semanticcache.PluginName {
 					if err := config.AddProviderKeysToSemanticCacheConfig(pluginConfig); err != nil {
@@ -1169,11 +1316,12 @@ func LoadConfig(ctx context.Context, configDirPath string) (*Config, error) {
 					plugin.Version = bifrost.Ptr(int16(1))
 				}
 				pluginConfig := &configstoreTables.TablePlugin{
-					Name:    plugin.Name,
-					Enabled: plugin.Enabled,
-					Config:  pluginConfigCopy,
-					Path:    plugin.Path,
-					Version: *plugin.Version,
+					Name:      plugin.Name,
+					Enabled:   plugin.Enabled,
+					Config:    pluginConfigCopy,
+					Path:      plugin.Path,
+					Version:   *plugin.Version,
+					Execution: plugin.Execution,
 				}
 				if plugin.Name == semanticcache.PluginName {
 					if err := config.RemoveProviderKeysFromSemanticCacheConfig(pluginConfig); err != nil {
@@ -1235,7 +1383,7 @@ func LoadConfig(ctx context.Context, configDirPath string) (*Config, error) {
 	// Initializing encryption
 	var encryptionKey string
 	if configData.EncryptionKey != "" {
-		if strings.HasPrefix(configData.EncryptionKey, "env.") {
+		if envutils.IsReference(configData.EncryptionKey) {
 			if encryptionKey, _, err = config.processEnvValue(configData.EncryptionKey); err != nil {
 				return nil, fmt.Errorf("failed to process encryption key: %w", err)
 			}
@@ -1266,16 +1414,22 @@ func (c *Config) GetRawConfigString() string {
 	return string(data)
 }
 
-// processEnvValue checks and replaces environment variable references in configuration values.
-// Returns the processed value and the environment variable name if it was an env reference.
-// Supports the "env.VARIABLE_NAME" syntax for referencing environment variables.
+// processEnvValue checks and replaces environment variable and secret file references in configuration values.
+// Returns the processed value and the reference it was resolved from, tracked in the env keys so that the reference
+// is saved instead of the secret. Supports the "env.VARIABLE_NAME" syntax for referencing environment variables, and
+// "${env:VARIABLE_NAME}" and "${file:/path}" references anywhere in the value, e.g. for Kubernetes secret mounts.
 // This enables secure configuration management without hardcoding sensitive values.
 //
 // Examples:
-//   - "env.OPENAI_API_KEY" -> actual value from OPENAI_API_KEY environment variable
-//   - "sk-1234567890" -> returned as-is (no env prefix)
+//   - "env.OPENAI_API_KEY" -> actual value from OPENAI_API_KEY environment variable, tracked as OPENAI_API_KEY
+//   - "${file:/var/run/secrets/openai/api-key}" -> content of the file, tracked as the value itself
+//   - "sk-1234567890" -> returned as-is (no reference)
 func (c *Config) processEnvValue(value string) (string, string, error) {
 	v := strings.TrimSpace(value)
+	if envutils.HasInterpolation(v) {
+		processedValue, err := envutils.ProcessEnvValue(v)
+		return processedValue, v, err
+	}
 	if !strings.HasPrefix(v, "env.") {
 		return value, "", nil // do not trim non-env values
 	}
@@ -1322,6 +1476,18 @@ func (c *Config) ShouldAllowDirectKeys() bool {
 	return c.ClientConfig.AllowDirectKeys
 }
 
+// GetStreamCancellations returns the accounting of the streams cancelled because their client disconnected.
+// It is nil until the server is bootstrapped, cancellations are then only logged.
+func (c *Config) GetStreamCancellations() *StreamCancellations {
+	return c.StreamCancellations
+}
+
+// GetDrain returns the tracking of the in-flight inference requests and streams.
+// It is nil until the server is bootstrapped, requests are then never refused nor tracked.
+func (c *Config) GetDrain() *Drain {
+	return c.Drain
+}
+
 // GetLoadedPlugins returns the current snapshot of loaded plugins.
 // This method is lock-free and safe for concurrent access from hot paths.
 // It returns the plugin slice from the atomic pointer, which is safe to iterate
@@ -1333,6 +1499,18 @@ func (c *Config) GetLoadedPlugins() []schemas.Plugin {
 	return nil
 }
 
+// GetPluginExecutionConfigs returns the execution limits of the enabled plugins, keyed by plugin name.
+// Plugins without execution limits are not part of the map.
+func (c *Config) GetPluginExecutionConfigs() map[string]schemas.PluginExecutionConfig {
+	configs := make(map[string]schemas.PluginExecutionConfig)
+	for _, plugin := range c.PluginConfigs {
+		if plugin.Enabled && plugin.Execution != nil {
+			configs[plugin.Name] = *plugin.Execution
+		}
+	}
+	return configs
+}
+
 // AddLoadedPlugin adds a plugin to the loaded plugins list.
 // This method is lock-free and safe for concurrent access from hot paths.
 // It iterates through the plugin slice (typically 5-10 plugins, ~50ns overhead).
@@ -1416,37 +1594,44 @@ func (c *Config) GetProviderConfigRedacted(provider schemas.ModelProvider) (*con
 		ProxyConfig:              config.ProxyConfig,
 		SendBackRawResponse:      config.SendBackRawResponse,
 		CustomProviderConfig:     config.CustomProviderConfig,
+		MockConfig:               config.MockConfig,
+		Namespace:                config.Namespace,
 	}
 
 	// Create redacted keys
 	redactedConfig.Keys = make([]schemas.Key, len(config.Keys))
 	for i, key := range config.Keys {
 		redactedConfig.Keys[i] = schemas.Key{
-			ID:     key.ID,
-			Name:   key.Name,
-			Models: key.Models, // Copy slice reference - read-only so safe
-			Weight: key.Weight,
+			ID:             key.ID,
+			Name:           key.Name,
+			Models:         key.Models, // Copy slice reference - read-only so safe
+			Weight:         key.Weight,
+			IsTest:         key.IsTest,
+			SpendLimit:     key.SpendLimit,
+			Region:         key.Region,
+			LastValidation: key.LastValidation,
 		}
 
 		// Redact API key value
 		path := fmt.Sprintf("providers.%s.keys[%s]", provider, key.ID)
 		if envVar, ok := envVarsByPath[path]; ok {
-			redactedConfig.Keys[i].Value = "env." + envVar
-		} else if !strings.HasPrefix(key.Value, "env.") {
+			redactedConfig.Keys[i].Value = envutils.FormatReference(envVar)
+		} else if !envutils.IsReference(key.Value) {
 			redactedConfig.Keys[i].Value = RedactKey(key.Value)
 		}
 
 		// Redact Azure key config if present
 		if key.AzureKeyConfig != nil {
 			azureConfig := &schemas.AzureKeyConfig{
-				Deployments: key.AzureKeyConfig.Deployments,
+				Deployments:  key.AzureKeyConfig.Deployments,
+				EndpointType: key.AzureKeyConfig.EndpointType,
 			}
 
 			// Redact Endpoint
 			path = fmt.Sprintf("providers.%s.keys[%s].azure_key_config.endpoint", provider, key.ID)
 			if envVar, ok := envVarsByPath[path]; ok {
-				azureConfig.Endpoint = "env." + envVar
-			} else if !strings.HasPrefix(key.AzureKeyConfig.Endpoint, "env.") {
+				azureConfig.Endpoint = envutils.FormatReference(envVar)
+			} else if !envutils.IsReference(key.AzureKeyConfig.Endpoint) {
 				azureConfig.Endpoint = key.AzureKeyConfig.Endpoint
 			}
 
@@ -1454,7 +1639,7 @@ func (c *Config) GetProviderConfigRedacted(provider schemas.ModelProvider) (*con
 			if key.AzureKeyConfig.APIVersion != nil {
 				path = fmt.Sprintf("providers.%s.keys[%s].azure_key_config.api_version", provider, key.ID)
 				if envVar, ok := envVarsByPath[path]; ok {
-					azureConfig.APIVersion = bifrost.Ptr("env." + envVar)
+					azureConfig.APIVersion = bifrost.Ptr(envutils.FormatReference(envVar))
 				} else {
 					// APIVersion is not sensitive, keep as-is
 					azureConfig.APIVersion = key.AzureKeyConfig.APIVersion
@@ -1468,28 +1653,29 @@ func (c *Config) GetProviderConfigRedacted(provider schemas.ModelProvider) (*con
 		if key.VertexKeyConfig != nil {
 			vertexConfig := &schemas.VertexKeyConfig{
 				Deployments: key.VertexKeyConfig.Deployments,
+				Regions:     key.VertexKeyConfig.Regions,
 			}
 
 			// Redact ProjectID
 			path = fmt.Sprintf("providers.%s.keys[%s].vertex_key_config.project_id", provider, key.ID)
 			if envVar, ok := envVarsByPath[path]; ok {
-				vertexConfig.ProjectID = "env." + envVar
-			} else if !strings.HasPrefix(key.VertexKeyConfig.ProjectID, "env.") {
+				vertexConfig.ProjectID = envutils.FormatReference(envVar)
+			} else if !envutils.IsReference(key.VertexKeyConfig.ProjectID) {
 				vertexConfig.ProjectID = RedactKey(key.VertexKeyConfig.ProjectID)
 			}
 
 			// Redact ProjectNumber
 			path = fmt.Sprintf("providers.%s.keys[%s].vertex_key_config.project_number", provider, key.ID)
 			if envVar, ok := envVarsByPath[path]; ok {
-				vertexConfig.ProjectNumber = "env." + envVar
-			} else if !strings.HasPrefix(key.VertexKeyConfig.ProjectNumber, "env.") {
+				vertexConfig.ProjectNumber = envutils.FormatReference(envVar)
+			} else if !envutils.IsReference(key.VertexKeyConfig.ProjectNumber) {
 				vertexConfig.ProjectNumber = RedactKey(key.VertexKeyConfig.ProjectNumber)
 			}
 
 			// Region is not sensitive, handle env vars only
 			path = fmt.Sprintf("providers.%s.keys[%s].vertex_key_config.region", provider, key.ID)
 			if envVar, ok := envVarsByPath[path]; ok {
-				vertexConfig.Region = "env." + envVar
+				vertexConfig.Region = envutils.FormatReference(envVar)
 			} else {
 				vertexConfig.Region = key.VertexKeyConfig.Region
 			}
@@ -1497,8 +1683,8 @@ func (c *Config) GetProviderConfigRedacted(provider schemas.ModelProvider) (*con
 			// Redact AuthCredentials
 			path = fmt.Sprintf("providers.%s.keys[%s].vertex_key_config.auth_credentials", provider, key.ID)
 			if envVar, ok := envVarsByPath[path]; ok {
-				vertexConfig.AuthCredentials = "env." + envVar
-			} else if !strings.HasPrefix(key.VertexKeyConfig.AuthCredentials, "env.") {
+				vertexConfig.AuthCredentials = envutils.FormatReference(envVar)
+			} else if !envutils.IsReference(key.VertexKeyConfig.AuthCredentials) {
 				vertexConfig.AuthCredentials = RedactKey(key.VertexKeyConfig.AuthCredentials)
 			}
 
@@ -1508,29 +1694,34 @@ func (c *Config) GetProviderConfigRedacted(provider schemas.ModelProvider) (*con
 		// Redact Bedrock key config if present
 		if key.BedrockKeyConfig != nil {
 			bedrockConfig := &schemas.BedrockKeyConfig{
-				Deployments: key.BedrockKeyConfig.Deployments,
+				Deployments:      key.BedrockKeyConfig.Deployments,
+				Regions:          key.BedrockKeyConfig.Regions,
+				ARNs:             key.BedrockKeyConfig.ARNs,
+				GuardrailID:      key.BedrockKeyConfig.GuardrailID,
+				GuardrailVersion: key.BedrockKeyConfig.GuardrailVersion,
+				RoleSessionName:  key.BedrockKeyConfig.RoleSessionName,
 			}
 
 			// Redact AccessKey
 			path = fmt.Sprintf("providers.%s.keys[%s].bedrock_key_config.access_key", provider, key.ID)
 			if envVar, ok := envVarsByPath[path]; ok {
-				bedrockConfig.AccessKey = "env." + envVar
-			} else if !strings.HasPrefix(key.BedrockKeyConfig.AccessKey, "env.") {
+				bedrockConfig.AccessKey = envutils.FormatReference(envVar)
+			} else if !envutils.IsReference(key.BedrockKeyConfig.AccessKey) {
 				bedrockConfig.AccessKey = RedactKey(key.BedrockKeyConfig.AccessKey)
 			}
 
 			// Redact SecretKey
 			path = fmt.Sprintf("providers.%s.keys[%s].bedrock_key_config.secret_key", provider, key.ID)
 			if envVar, ok := envVarsByPath[path]; ok {
-				bedrockConfig.SecretKey = "env." + envVar
-			} else if !strings.HasPrefix(key.BedrockKeyConfig.SecretKey, "env.") {
+				bedrockConfig.SecretKey = envutils.FormatReference(envVar)
+			} else if !envutils.IsReference(key.BedrockKeyConfig.SecretKey) {
 				bedrockConfig.SecretKey = RedactKey(key.BedrockKeyConfig.SecretKey)
 			}
 
 			// Redact SessionToken
 			path = fmt.Sprintf("providers.%s.keys[%s].bedrock_key_config.session_token", provider, key.ID)
 			if envVar, ok := envVarsByPath[path]; ok {
-				bedrockConfig.SessionToken = bifrost.Ptr("env." + envVar)
+				bedrockConfig.SessionToken = bifrost.Ptr(envutils.FormatReference(envVar))
 			} else {
 				bedrockConfig.SessionToken = key.BedrockKeyConfig.SessionToken
 			}
@@ -1538,7 +1729,7 @@ func (c *Config) GetProviderConfigRedacted(provider schemas.ModelProvider) (*con
 			// Redact Region
 			path = fmt.Sprintf("providers.%s.keys[%s].bedrock_key_config.region", provider, key.ID)
 			if envVar, ok := envVarsByPath[path]; ok {
-				bedrockConfig.Region = bifrost.Ptr("env." + envVar)
+				bedrockConfig.Region = bifrost.Ptr(envutils.FormatReference(envVar))
 			} else {
 				bedrockConfig.Region = key.BedrockKeyConfig.Region
 			}
@@ -1546,13 +1737,45 @@ func (c *Config) GetProviderConfigRedacted(provider schemas.ModelProvider) (*con
 			// Redact ARN
 			path = fmt.Sprintf("providers.%s.keys[%s].bedrock_key_config.arn", provider, key.ID)
 			if envVar, ok := envVarsByPath[path]; ok {
-				bedrockConfig.ARN = bifrost.Ptr("env." + envVar)
+				bedrockConfig.ARN = bifrost.Ptr(envutils.FormatReference(envVar))
 			} else {
 				bedrockConfig.ARN = key.BedrockKeyConfig.ARN
 			}
 
+			// Redact RoleARN
+			path = fmt.Sprintf("providers.%s.keys[%s].bedrock_key_config.role_arn", provider, key.ID)
+			if envVar, ok := envVarsByPath[path]; ok {
+				bedrockConfig.RoleARN = bifrost.Ptr(envutils.FormatReference(envVar))
+			} else {
+				bedrockConfig.RoleARN = key.BedrockKeyConfig.RoleARN
+			}
+
+			// Redact ExternalID
+			path = fmt.Sprintf("providers.%s.keys[%s].bedrock_key_config.external_id", provider, key.ID)
+			if envVar, ok := envVarsByPath[path]; ok {
+				bedrockConfig.ExternalID = bifrost.Ptr(envutils.FormatReference(envVar))
+			} else {
+				bedrockConfig.ExternalID = key.BedrockKeyConfig.ExternalID
+			}
+
 			redactedConfig.Keys[i].BedrockKeyConfig = bedrockConfig
 		}
+
+		// Redact the password of the proxy of the key
+		if key.ProxyConfig != nil {
+			proxyConfig := *key.ProxyConfig
+			path = fmt.Sprintf("providers.%s.keys[%s].proxy_config.username", provider, key.ID)
+			if envVar, ok := envVarsByPath[path]; ok {
+				proxyConfig.Username = envutils.FormatReference(envVar)
+			}
+			path = fmt.Sprintf("providers.%s.keys[%s].proxy_config.password", provider, key.ID)
+			if envVar, ok := envVarsByPath[path]; ok {
+				proxyConfig.Password = envutils.FormatReference(envVar)
+			} else if proxyConfig.Password != "" && !envutils.IsReference(proxyConfig.Password) {
+				proxyConfig.Password = RedactKey(proxyConfig.Password)
+			}
+			redactedConfig.Keys[i].ProxyConfig = &proxyConfig
+		}
 	}
 
 	return &redactedConfig, nil
@@ -1592,6 +1815,9 @@ func (c *Config) AddProvider(ctx context.Context, provider schemas.ModelProvider
 	if err := ValidateCustomProvider(config, provider); err != nil {
 		return err
 	}
+	if err := ValidateKeys(config.Keys); err != nil {
+		return err
+	}
 	newEnvKeys := make(map[string]struct{})
 
 	// Process environment variables in keys (including key-level configs)
@@ -1643,6 +1869,14 @@ func (c *Config) AddProvider(ctx context.Context, provider schemas.ModelProvider
 				return fmt.Errorf("failed to process Bedrock key config env vars: %w", err)
 			}
 		}
+
+		// Process the authentication of the proxy of the key if present
+		if key.ProxyConfig != nil {
+			if err := c.processProxyConfigEnvVars(&config.Keys[i], provider, newEnvKeys); err != nil {
+				c.cleanupEnvKeys(provider, "", newEnvKeys)
+				return fmt.Errorf("failed to process proxy config env vars: %w", err)
+			}
+		}
 	}
 
 	// First add the provider to the store
@@ -1697,6 +1931,9 @@ func (c *Config) UpdateProviderConfig(ctx context.Context, provider schemas.Mode
 	if err := ValidateCustomProviderUpdate(config, existingConfig, provider); err != nil {
 		return err
 	}
+	if err := ValidateKeys(config.Keys); err != nil {
+		return err
+	}
 	// Track new environment variables being added
 	newEnvKeys := make(map[string]struct{})
 
@@ -1749,6 +1986,14 @@ func (c *Config) UpdateProviderConfig(ctx context.Context, provider schemas.Mode
 				return fmt.Errorf("failed to process Bedrock key config env vars: %w", err)
 			}
 		}
+
+		// Process the authentication of the proxy of the key if present
+		if key.ProxyConfig != nil {
+			if err := c.processProxyConfigEnvVars(&config.Keys[i], provider, newEnvKeys); err != nil {
+				c.cleanupEnvKeys(provider, "", newEnvKeys)
+				return fmt.Errorf("failed to process proxy config env vars: %w", err)
+			}
+		}
 	}
 
 	// Update in-memory configuration first (so client can read updated config)
@@ -1803,6 +2048,38 @@ func (c *Config) UpdateProviderConfig(ctx context.Context, provider schemas.Mode
 	return nil
 }
 
+// UpdateKeyValidation records the last validation call made with a key of a provider in memory and in the store.
+// The provider is not reloaded in the client, since its keys are unchanged.
+func (c *Config) UpdateKeyValidation(ctx context.Context, provider schemas.ModelProvider, keyID string, validation *schemas.KeyValidation) error {
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+
+	config, exists := c.Providers[provider]
+	if !exists {
+		return ErrNotFound
+	}
+	index := slices.IndexFunc(config.Keys, func(key schemas.Key) bool { return key.ID == keyID })
+	if index < 0 {
+		return ErrNotFound
+	}
+
+	if c.ConfigStore != nil {
+		if err := c.ConfigStore.UpdateKeyValidation(ctx, keyID, validation); err != nil {
+			if errors.Is(err, configstore.ErrNotFound) {
+				return ErrNotFound
+			}
+			return fmt.Errorf("failed to update key validation in store: %w", err)
+		}
+	}
+
+	// Readers hold copies of the keys slice, so it is replaced rather than updated in place
+	keys := slices.Clone(config.Keys)
+	keys[index].LastValidation = validation
+	config.Keys = keys
+	c.Providers[provider] = config
+	return nil
+}
+
 // RemoveProvider removes a provider configuration from memory.
 func (c *Config) RemoveProvider(ctx context.Context, provider schemas.ModelProvider) error {
 	c.Mu.Lock()
@@ -1837,12 +2114,13 @@ func (c *Config) GetAllKeys() ([]configstoreTables.TableKey, error) {
 	for providerKey, provider := range c.Providers {
 		for _, key := range provider.Keys {
 			keys = append(keys, configstoreTables.TableKey{
-				KeyID:    key.ID,
-				Name:     key.Name,
-				Value:    "",
-				Models:   key.Models,
-				Weight:   key.Weight,
-				Provider: string(providerKey),
+				KeyID:     key.ID,
+				Name:      key.Name,
+				Value:     "",
+				Models:    key.Models,
+				Weight:    key.Weight,
+				Provider:  string(providerKey),
+				Namespace: provider.Namespace,
 			})
 		}
 	}
@@ -2227,14 +2505,14 @@ func (c *Config) RedactMCPClientConfig(config schemas.MCPClientConfig) schemas.M
 		for envVar, infos := range c.EnvKeys {
 			for _, info := range infos {
 				if info.Provider == "" && info.KeyType == "connection_string" && info.ConfigPath == fmt.Sprintf("mcp.client_configs.%s.connection_string", config.ID) {
-					connStr = "env." + envVar
+					connStr = envutils.FormatReference(envVar)
 					break
 				}
 			}
 		}
 
 		// If not from env var, redact it
-		if !strings.HasPrefix(connStr, "env.") {
+		if !envutils.IsReference(connStr) {
 			connStr = RedactKey(connStr)
 		}
 		configCopy.ConnectionString = &connStr
@@ -2251,14 +2529,14 @@ func (c *Config) RedactMCPClientConfig(config schemas.MCPClientConfig) schemas.M
 			for envVar, infos := range c.EnvKeys {
 				for _, info := range infos {
 					if info.Provider == "" && info.KeyType == "mcp_header" && info.ConfigPath == fmt.Sprintf("mcp.client_configs.%s.headers.%s", config.ID, header) {
-						headerValue = "env." + envVar
+						headerValue = envutils.FormatReference(envVar)
 						break
 					}
 				}
 			}
 
 			// If not from env var, redact it
-			if !strings.HasPrefix(headerValue, "env.") {
+			if !envutils.IsReference(headerValue) {
 				headerValue = RedactKey(headerValue)
 			}
 			configCopy.Headers[header] = headerValue
@@ -2295,7 +2573,7 @@ func IsRedacted(key string) bool {
 	}
 
 	// Check if it's an environment variable reference
-	if strings.HasPrefix(key, "env.") {
+	if envutils.IsReference(key) {
 		return true
 	}
 
@@ -2514,8 +2792,8 @@ func (c *Config) isEnvVarReferenceChanging(mergedKey, oldKey schemas.Key, config
 	mergedValue := c.getFieldValue(mergedKey, fieldName)
 
 	// If either value is an env var reference, check if they're different
-	oldIsEnvVar := strings.HasPrefix(oldValue, "env.")
-	mergedIsEnvVar := strings.HasPrefix(mergedValue, "env.")
+	oldIsEnvVar := envutils.IsReference(oldValue)
+	mergedIsEnvVar := envutils.IsReference(mergedValue)
 
 	// If both are env vars, check if they reference the same variable
 	if oldIsEnvVar && mergedIsEnvVar {
@@ -2565,6 +2843,14 @@ func (c *Config) getFieldValue(key schemas.Key, fieldName string) string {
 		if key.BedrockKeyConfig != nil && key.BedrockKeyConfig.SessionToken != nil {
 			return *key.BedrockKeyConfig.SessionToken
 		}
+	case "username":
+		if key.ProxyConfig != nil {
+			return key.ProxyConfig.Username
+		}
+	case "password":
+		if key.ProxyConfig != nil {
+			return key.ProxyConfig.Password
+		}
 	default:
 		// For the main API key value
 		if fieldName == "value" || strings.Contains(fieldName, "key") {
@@ -2859,6 +3145,259 @@ func (c *Config) processBedrockKeyConfigEnvVars(key *schemas.Key, provider schem
 		bedrockConfig.ARN = &processedARN
 	}
 
+	// Process RoleARN if present
+	if bedrockConfig.RoleARN != nil {
+		processedRoleARN, envVar, err := c.processEnvValue(*bedrockConfig.RoleARN)
+		if err != nil {
+			return err
+		}
+		if envVar != "" {
+			newEnvKeys[envVar] = struct{}{}
+			c.EnvKeys[envVar] = append(c.EnvKeys[envVar], configstore.EnvKeyInfo{
+				EnvVar:     envVar,
+				Provider:   provider,
+				KeyType:    "bedrock_config",
+				ConfigPath: fmt.Sprintf("providers.%s.keys[%s].bedrock_key_config.role_arn", provider, key.ID),
+				KeyID:      key.ID,
+			})
+		}
+		bedrockConfig.RoleARN = &processedRoleARN
+	}
+
+	// Process ExternalID if present
+	if bedrockConfig.ExternalID != nil {
+		processedExternalID, envVar, err := c.processEnvValue(*bedrockConfig.ExternalID)
+		if err != nil {
+			return err
+		}
+		if envVar != "" {
+			newEnvKeys[envVar] = struct{}{}
+			c.EnvKeys[envVar] = append(c.EnvKeys[envVar], configstore.EnvKeyInfo{
+				EnvVar:     envVar,
+				Provider:   provider,
+				KeyType:    "bedrock_config",
+				ConfigPath: fmt.Sprintf("providers.%s.keys[%s].bedrock_key_config.external_id", provider, key.ID),
+				KeyID:      key.ID,
+			})
+		}
+		bedrockConfig.ExternalID = &processedExternalID
+	}
+
+	return nil
+}
+
+// processProxyConfigEnvVars processes environment variables in the authentication of the proxy of a key
+func (c *Config) processProxyConfigEnvVars(key *schemas.Key, provider schemas.ModelProvider, newEnvKeys map[string]struct{}) error {
+	proxyConfig := *key.ProxyConfig
+	for field, value := range map[string]*string{"username": &proxyConfig.Username, "password": &proxyConfig.Password} {
+		processedValue, envVar, err := c.processEnvValue(*value)
+		if err != nil {
+			return err
+		}
+		if envVar != "" {
+			newEnvKeys[envVar] = struct{}{}
+			c.EnvKeys[envVar] = append(c.EnvKeys[envVar], configstore.EnvKeyInfo{
+				EnvVar:     envVar,
+				Provider:   provider,
+				KeyType:    "proxy_config",
+				ConfigPath: fmt.Sprintf("providers.%s.keys[%s].proxy_config.%s", provider, key.ID, field),
+				KeyID:      key.ID,
+			})
+		}
+		*value = processedValue
+	}
+	key.ProxyConfig = &proxyConfig
+	return nil
+}
+
+// ResolveKeyEnvVars returns a copy of the key with the environment variables of its value and key configs resolved,
+// to send requests with a key before it is added to the store. The env keys of the store are left untouched.
+func (c *Config) ResolveKeyEnvVars(key schemas.Key) (schemas.Key, error) {
+	var err error
+	resolve := func(value *string) {
+		if err == nil && value != nil {
+			*value, _, err = c.processEnvValue(*value)
+		}
+	}
+	resolveOptional := func(value **string) {
+		if *value != nil {
+			resolved := **value
+			resolve(&resolved)
+			*value = &resolved
+		}
+	}
+
+	resolve(&key.Value)
+	if key.AzureKeyConfig != nil {
+		azureConfig := *key.AzureKeyConfig
+		resolve(&azureConfig.Endpoint)
+		resolveOptional(&azureConfig.APIVersion)
+		key.AzureKeyConfig = &azureConfig
+	}
+	if key.VertexKeyConfig != nil {
+		vertexConfig := *key.VertexKeyConfig
+		resolve(&vertexConfig.ProjectID)
+		resolve(&vertexConfig.ProjectNumber)
+		resolve(&vertexConfig.Region)
+		resolve(&vertexConfig.AuthCredentials)
+		key.VertexKeyConfig = &vertexConfig
+	}
+	if key.BedrockKeyConfig != nil {
+		bedrockConfig := *key.BedrockKeyConfig
+		resolve(&bedrockConfig.AccessKey)
+		resolve(&bedrockConfig.SecretKey)
+		resolveOptional(&bedrockConfig.SessionToken)
+		resolveOptional(&bedrockConfig.Region)
+		resolveOptional(&bedrockConfig.ARN)
+		resolveOptional(&bedrockConfig.RoleARN)
+		resolveOptional(&bedrockConfig.ExternalID)
+		key.BedrockKeyConfig = &bedrockConfig
+	}
+	if key.ProxyConfig != nil {
+		proxyConfig := *key.ProxyConfig
+		resolve(&proxyConfig.Username)
+		resolve(&proxyConfig.Password)
+		key.ProxyConfig = &proxyConfig
+	}
+	if err != nil {
+		return schemas.Key{}, err
+	}
+	return key, nil
+}
+
+// RefreshSecretReferences resolves the env.VAR_NAME, ${env:VAR_NAME} and ${file:/path} references of the provider
+// keys again, e.g. after a mounted secret was rotated, and reloads the providers whose keys changed or whose proxy
+// uses references. The store is left untouched since it only holds the references.
+func (c *Config) RefreshSecretReferences(ctx context.Context) error {
+	c.Mu.Lock()
+	var errs []error
+	reload := make(map[schemas.ModelProvider]bool)
+	for envVar, infos := range c.EnvKeys {
+		value, err := envutils.ProcessEnvValue(envutils.FormatReference(envVar))
+		for _, info := range infos {
+			if info.Provider == "" || info.KeyID == "" {
+				continue
+			}
+			config, exists := c.Providers[info.Provider]
+			if !exists {
+				continue
+			}
+			index := slices.IndexFunc(config.Keys, func(key schemas.Key) bool { return key.ID == info.KeyID })
+			if index < 0 {
+				continue
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", info.ConfigPath, err))
+				continue
+			}
+			// Keys are copied on write since the previous config may still be read
+			keys := slices.Clone(config.Keys)
+			field := keyFieldRef(&keys[index], string(info.KeyType), info.ConfigPath[strings.LastIndex(info.ConfigPath, ".")+1:])
+			if field == nil || *field == value {
+				continue
+			}
+			*field = value
+			config.Keys = keys
+			c.Providers[info.Provider] = config
+			reload[info.Provider] = true
+		}
+	}
+	for provider, config := range c.Providers {
+		if config.ProxyConfig != nil && (envutils.IsReference(config.ProxyConfig.Username) || envutils.IsReference(config.ProxyConfig.Password)) {
+			reload[provider] = true
+		}
+	}
+	c.Mu.Unlock()
+
+	// The client reads the provider configs, so the lock is released first
+	if c.client != nil {
+		for provider := range reload {
+			if err := c.client.UpdateProvider(provider); err != nil {
+				errs = append(errs, fmt.Errorf("failed to reload provider %s: %w", provider, err))
+			}
+		}
+	}
+	logger.Info("refreshed secret references, %d providers reloaded", len(reload))
+	return errors.Join(errs...)
+}
+
+// keyFieldRef returns the field of a key tracked by an env key, cloning the key config holding it so that it can be
+// set without changing the previous key
+func keyFieldRef(key *schemas.Key, keyType string, field string) *string {
+	cloneOptional := func(value **string) *string {
+		if *value == nil {
+			return nil
+		}
+		clone := **value
+		*value = &clone
+		return *value
+	}
+	switch keyType {
+	case "api_key":
+		return &key.Value
+	case "azure_config":
+		if key.AzureKeyConfig == nil {
+			return nil
+		}
+		azureConfig := *key.AzureKeyConfig
+		key.AzureKeyConfig = &azureConfig
+		switch field {
+		case "endpoint":
+			return &azureConfig.Endpoint
+		case "api_version":
+			return cloneOptional(&azureConfig.APIVersion)
+		}
+	case "vertex_config":
+		if key.VertexKeyConfig == nil {
+			return nil
+		}
+		vertexConfig := *key.VertexKeyConfig
+		key.VertexKeyConfig = &vertexConfig
+		switch field {
+		case "project_id":
+			return &vertexConfig.ProjectID
+		case "project_number":
+			return &vertexConfig.ProjectNumber
+		case "region":
+			return &vertexConfig.Region
+		case "auth_credentials":
+			return &vertexConfig.AuthCredentials
+		}
+	case "bedrock_config":
+		if key.BedrockKeyConfig == nil {
+			return nil
+		}
+		bedrockConfig := *key.BedrockKeyConfig
+		key.BedrockKeyConfig = &bedrockConfig
+		switch field {
+		case "access_key":
+			return &bedrockConfig.AccessKey
+		case "secret_key":
+			return &bedrockConfig.SecretKey
+		case "session_token":
+			return cloneOptional(&bedrockConfig.SessionToken)
+		case "region":
+			return cloneOptional(&bedrockConfig.Region)
+		case "arn":
+			return cloneOptional(&bedrockConfig.ARN)
+		case "role_arn":
+			return cloneOptional(&bedrockConfig.RoleARN)
+		case "external_id":
+			return cloneOptional(&bedrockConfig.ExternalID)
+		}
+	case "proxy_config":
+		if key.ProxyConfig == nil {
+			return nil
+		}
+		proxyConfig := *key.ProxyConfig
+		key.ProxyConfig = &proxyConfig
+		switch field {
+		case "username":
+			return &proxyConfig.Username
+		case "password":
+			return &proxyConfig.Password
+		}
+	}
 	return nil
 }
 
@@ -2923,6 +3462,147 @@ func ValidateCustomProvider(config configstore.ProviderConfig, provider schemas.
 	return nil
 }
 
+// ValidateKeys validates the test key settings and the proxies of the keys
+func ValidateKeys(keys []schemas.Key) error {
+	if err := ValidateTestKeys(keys); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if key.ProxyConfig == nil {
+			continue
+		}
+		switch key.ProxyConfig.Type {
+		case schemas.NoProxy, schemas.EnvProxy:
+		case schemas.HTTPProxy, schemas.Socks5Proxy:
+			if key.ProxyConfig.URL == "" {
+				return fmt.Errorf("key %s has a %s proxy without a url", key.Name, key.ProxyConfig.Type)
+			}
+		default:
+			return fmt.Errorf("key %s has an unsupported proxy type %s", key.Name, key.ProxyConfig.Type)
+		}
+	}
+	return nil
+}
+
+// ValidateSecretReferences rejects the ${file:/path} references of a provider config written through the API, since
+// they would let an admin read any file of the server through the keys. File references are only read from the config
+// file: a reference is accepted when the existing (redacted) config already has it in the same field of the same key,
+// so that providers configured in the config file can still be edited.
+func ValidateSecretReferences(config configstore.ProviderConfig, existing *configstore.ProviderConfig) error {
+	var existingKeys []schemas.Key
+	var existingProxy *schemas.ProxyConfig
+	if existing != nil {
+		existingKeys = existing.Keys
+		existingProxy = existing.ProxyConfig
+	}
+	existingFields := make(map[string]map[string]string, len(existingKeys))
+	for _, key := range existingKeys {
+		existingFields[key.ID] = keySecretFields(key)
+	}
+	for _, key := range config.Keys {
+		for field, value := range keySecretFields(key) {
+			if envutils.HasFileReference(value) && (key.ID == "" || existingFields[key.ID][field] != value) {
+				return fmt.Errorf("key %s: file references are only supported in the config file (%s)", key.Name, field)
+			}
+		}
+	}
+	if config.ProxyConfig != nil {
+		for field, value := range map[string]string{"username": config.ProxyConfig.Username, "password": config.ProxyConfig.Password} {
+			if !envutils.HasFileReference(value) {
+				continue
+			}
+			if existingProxy == nil || (field == "username" && existingProxy.Username != value) || (field == "password" && existingProxy.Password != value) {
+				return fmt.Errorf("proxy_config: file references are only supported in the config file (%s)", field)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateMCPSecretReferences rejects the ${file:/path} references of an MCP client config written through the API,
+// unless the existing (redacted) config of the client already has them, as ValidateSecretReferences does for keys
+func ValidateMCPSecretReferences(config schemas.MCPClientConfig, existing *schemas.MCPClientConfig) error {
+	if config.ConnectionString != nil && envutils.HasFileReference(*config.ConnectionString) &&
+		(existing == nil || existing.ConnectionString == nil || *existing.ConnectionString != *config.ConnectionString) {
+		return fmt.Errorf("connection_string: file references are only supported in the config file")
+	}
+	for header, value := range config.Headers {
+		if envutils.HasFileReference(value) && (existing == nil || existing.Headers[header] != value) {
+			return fmt.Errorf("header %s: file references are only supported in the config file", header)
+		}
+	}
+	return nil
+}
+
+// keySecretFields returns the values of a key that are resolved from references, by field
+func keySecretFields(key schemas.Key) map[string]string {
+	fields := map[string]string{"value": key.Value}
+	optional := func(field string, value *string) {
+		if value != nil {
+			fields[field] = *value
+		}
+	}
+	if key.AzureKeyConfig != nil {
+		fields["azure_key_config.endpoint"] = key.AzureKeyConfig.Endpoint
+		optional("azure_key_config.api_version", key.AzureKeyConfig.APIVersion)
+	}
+	if key.VertexKeyConfig != nil {
+		fields["vertex_key_config.project_id"] = key.VertexKeyConfig.ProjectID
+		fields["vertex_key_config.project_number"] = key.VertexKeyConfig.ProjectNumber
+		fields["vertex_key_config.region"] = key.VertexKeyConfig.Region
+		fields["vertex_key_config.auth_credentials"] = key.VertexKeyConfig.AuthCredentials
+	}
+	if key.BedrockKeyConfig != nil {
+		fields["bedrock_key_config.access_key"] = key.BedrockKeyConfig.AccessKey
+		fields["bedrock_key_config.secret_key"] = key.BedrockKeyConfig.SecretKey
+		optional("bedrock_key_config.session_token", key.BedrockKeyConfig.SessionToken)
+		optional("bedrock_key_config.region", key.BedrockKeyConfig.Region)
+		optional("bedrock_key_config.arn", key.BedrockKeyConfig.ARN)
+		optional("bedrock_key_config.role_arn", key.BedrockKeyConfig.RoleARN)
+		optional("bedrock_key_config.external_id", key.BedrockKeyConfig.ExternalID)
+	}
+	if key.ProxyConfig != nil {
+		fields["proxy_config.username"] = key.ProxyConfig.Username
+		fields["proxy_config.password"] = key.ProxyConfig.Password
+	}
+	return fields
+}
+
+// ValidateEndpoints checks the base URL of the network config and the endpoints of the Azure keys against the
+// endpoint policy. Endpoints read from environment variables are set by the operator and not checked.
+func ValidateEndpoints(ctx context.Context, policy *schemas.EndpointPolicy, networkConfig *schemas.NetworkConfig, keys []schemas.Key) error {
+	if policy == nil {
+		return nil
+	}
+	if networkConfig != nil && networkConfig.BaseURL != "" && !envutils.IsReference(networkConfig.BaseURL) {
+		if err := policy.CheckEndpoint(ctx, networkConfig.BaseURL); err != nil {
+			return fmt.Errorf("base url: %w", err)
+		}
+	}
+	for _, key := range keys {
+		if key.AzureKeyConfig == nil || key.AzureKeyConfig.Endpoint == "" || envutils.IsReference(key.AzureKeyConfig.Endpoint) {
+			continue
+		}
+		if err := policy.CheckEndpoint(ctx, key.AzureKeyConfig.Endpoint); err != nil {
+			return fmt.Errorf("key %s: %w", key.Name, err)
+		}
+	}
+	return nil
+}
+
+// ValidateTestKeys validates that test keys have a positive spend limit, and that only test keys have one
+func ValidateTestKeys(keys []schemas.Key) error {
+	for _, key := range keys {
+		if key.SpendLimit != nil && !key.IsTest {
+			return fmt.Errorf("key %s has a spend_limit but is not a test key", key.Name)
+		}
+		if key.IsTest && (key.SpendLimit == nil || *key.SpendLimit <= 0) {
+			return fmt.Errorf("test key %s requires a spend_limit greater than 0", key.Name)
+		}
+	}
+	return nil
+}
+
 // ValidateCustomProviderUpdate validates that immutable fields in CustomProviderConfig are not changed during updates
 func ValidateCustomProviderUpdate(newConfig, existingConfig configstore.ProviderConfig, provider schemas.ModelProvider) error {
 	// If neither config has CustomProviderConfig, no validation needed
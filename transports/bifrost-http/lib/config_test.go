@@ -16,6 +16,7 @@ HASH GENERATION TESTS
 |                                        | different fields → different hash        |
 | TestGenerateKeyHash                    | Key hash generation, ID skipped,         |
 |                                        | content changes detected                 |
+| TestValidateTestKeys                   | Test keys require a positive spend limit |
 
 COMPARISON LOGIC TESTS
 -------------------------------------------------------------------------------------
@@ -101,6 +102,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/maximhq/bifrost/core/schemas"
@@ -359,6 +361,28 @@ func (m *MockConfigStore) GetVirtualKeyByValue(ctx context.Context, value string
 	return nil, nil
 }
 
+// Test key spend
+func (m *MockConfigStore) GetKeySpends(ctx context.Context) ([]tables.TableKeySpend, error) {
+	return nil, nil
+}
+
+func (m *MockConfigStore) UpdateKeySpend(ctx context.Context, spend *tables.TableKeySpend, tx ...*gorm.DB) error {
+	return nil
+}
+
+func (m *MockConfigStore) DeleteKeySpend(ctx context.Context, keyID string) error {
+	return nil
+}
+
+// Leases
+func (m *MockConfigStore) AcquireLease(ctx context.Context, name string, holder string, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
+func (m *MockConfigStore) ReleaseLease(ctx context.Context, name string, holder string) error {
+	return nil
+}
+
 // Virtual key provider config
 func (m *MockConfigStore) GetVirtualKeyProviderConfigs(ctx context.Context, virtualKeyID string) ([]tables.TableVirtualKeyProviderConfig, error) {
 	return nil, nil
@@ -488,6 +512,10 @@ func (m *MockConfigStore) GetAllRedactedKeys(ctx context.Context, ids []string)
 	return nil, nil
 }
 
+func (m *MockConfigStore) UpdateKeyValidation(ctx context.Context, keyID string, validation *schemas.KeyValidation) error {
+	return nil
+}
+
 // Session
 func (m *MockConfigStore) GetSession(ctx context.Context, token string) (*tables.SessionsTable, error) {
 	return nil, nil
@@ -501,6 +529,191 @@ func (m *MockConfigStore) DeleteSession(ctx context.Context, token string) error
 	return nil
 }
 
+// Namespace
+func (m *MockConfigStore) GetNamespaces(ctx context.Context) ([]tables.TableNamespace, error) {
+	return nil, nil
+}
+
+func (m *MockConfigStore) GetNamespace(ctx context.Context, name string) (*tables.TableNamespace, error) {
+	return nil, nil
+}
+
+func (m *MockConfigStore) GetNamespaceByAdminUsername(ctx context.Context, username string) (*tables.TableNamespace, error) {
+	return nil, nil
+}
+
+func (m *MockConfigStore) CreateNamespace(ctx context.Context, namespace *tables.TableNamespace, tx ...*gorm.DB) error {
+	return nil
+}
+
+func (m *MockConfigStore) UpdateNamespace(ctx context.Context, namespace *tables.TableNamespace, tx ...*gorm.DB) error {
+	return nil
+}
+
+func (m *MockConfigStore) DeleteNamespace(ctx context.Context, name string) error {
+	return nil
+}
+
+// Revoked payload keys
+func (m *MockConfigStore) GetRevokedPayloadKeys(ctx context.Context) ([]tables.TableRevokedPayloadKey, error) {
+	return nil, nil
+}
+
+func (m *MockConfigStore) AddRevokedPayloadKey(ctx context.Context, key *tables.TableRevokedPayloadKey, tx ...*gorm.DB) error {
+	return nil
+}
+
+// Pipeline
+func (m *MockConfigStore) GetPipelines(ctx context.Context) ([]tables.TablePipeline, error) {
+	return nil, nil
+}
+
+func (m *MockConfigStore) GetPipeline(ctx context.Context, name string) (*tables.TablePipeline, error) {
+	return nil, nil
+}
+
+func (m *MockConfigStore) CreatePipeline(ctx context.Context, pipeline *tables.TablePipeline, tx ...*gorm.DB) error {
+	return nil
+}
+
+func (m *MockConfigStore) UpdatePipeline(ctx context.Context, pipeline *tables.TablePipeline, tx ...*gorm.DB) error {
+	return nil
+}
+
+func (m *MockConfigStore) DeletePipeline(ctx context.Context, name string) error {
+	return nil
+}
+
+// Transform rule
+func (m *MockConfigStore) GetTransformRules(ctx context.Context) ([]tables.TableTransformRule, error) {
+	return nil, nil
+}
+
+func (m *MockConfigStore) GetTransformRule(ctx context.Context, name string) (*tables.TableTransformRule, error) {
+	return nil, nil
+}
+
+func (m *MockConfigStore) CreateTransformRule(ctx context.Context, rule *tables.TableTransformRule, tx ...*gorm.DB) error {
+	return nil
+}
+
+func (m *MockConfigStore) UpdateTransformRule(ctx context.Context, rule *tables.TableTransformRule, tx ...*gorm.DB) error {
+	return nil
+}
+
+func (m *MockConfigStore) DeleteTransformRule(ctx context.Context, name string) error {
+	return nil
+}
+
+// Fallback chain
+func (m *MockConfigStore) GetFallbackChains(ctx context.Context) ([]tables.TableFallbackChain, error) {
+	return nil, nil
+}
+
+func (m *MockConfigStore) GetFallbackChain(ctx context.Context, alias string) (*tables.TableFallbackChain, error) {
+	return nil, nil
+}
+
+func (m *MockConfigStore) CreateFallbackChain(ctx context.Context, chain *tables.TableFallbackChain, tx ...*gorm.DB) error {
+	return nil
+}
+
+func (m *MockConfigStore) UpdateFallbackChain(ctx context.Context, chain *tables.TableFallbackChain, tx ...*gorm.DB) error {
+	return nil
+}
+
+func (m *MockConfigStore) DeleteFallbackChain(ctx context.Context, alias string) error {
+	return nil
+}
+
+// Experiment
+func (m *MockConfigStore) GetExperiments(ctx context.Context) ([]tables.TableExperiment, error) {
+	return nil, nil
+}
+
+func (m *MockConfigStore) GetExperiment(ctx context.Context, name string) (*tables.TableExperiment, error) {
+	return nil, nil
+}
+
+func (m *MockConfigStore) CreateExperiment(ctx context.Context, experiment *tables.TableExperiment, tx ...*gorm.DB) error {
+	return nil
+}
+
+func (m *MockConfigStore) UpdateExperiment(ctx context.Context, experiment *tables.TableExperiment, tx ...*gorm.DB) error {
+	return nil
+}
+
+func (m *MockConfigStore) DeleteExperiment(ctx context.Context, name string) error {
+	return nil
+}
+
+// Model capability overrides
+func (m *MockConfigStore) GetModelCapabilityOverrides(ctx context.Context) ([]tables.TableModelCapability, error) {
+	return nil, nil
+}
+
+func (m *MockConfigStore) UpsertModelCapabilityOverride(ctx context.Context, override *tables.TableModelCapability, tx ...*gorm.DB) error {
+	return nil
+}
+
+func (m *MockConfigStore) DeleteModelCapabilityOverride(ctx context.Context, provider, model string) error {
+	return nil
+}
+
+// Model pricing overrides
+func (m *MockConfigStore) GetModelPricingOverrides(ctx context.Context) ([]tables.TableModelPricingOverride, error) {
+	return nil, nil
+}
+
+func (m *MockConfigStore) UpsertModelPricingOverride(ctx context.Context, override *tables.TableModelPricingOverride, tx ...*gorm.DB) error {
+	return nil
+}
+
+func (m *MockConfigStore) DeleteModelPricingOverride(ctx context.Context, provider, model string) error {
+	return nil
+}
+
+// Model deprecation
+func (m *MockConfigStore) GetModelDeprecations(ctx context.Context) ([]tables.TableModelDeprecation, error) {
+	return nil, nil
+}
+
+func (m *MockConfigStore) GetModelDeprecation(ctx context.Context, model string) (*tables.TableModelDeprecation, error) {
+	return nil, nil
+}
+
+func (m *MockConfigStore) CreateModelDeprecation(ctx context.Context, deprecation *tables.TableModelDeprecation, tx ...*gorm.DB) error {
+	return nil
+}
+
+func (m *MockConfigStore) UpdateModelDeprecation(ctx context.Context, deprecation *tables.TableModelDeprecation, tx ...*gorm.DB) error {
+	return nil
+}
+
+func (m *MockConfigStore) DeleteModelDeprecation(ctx context.Context, model string) error {
+	return nil
+}
+
+func (m *MockConfigStore) GetConversation(ctx context.Context, id string) (*tables.TableConversation, error) {
+	return nil, nil
+}
+
+func (m *MockConfigStore) GetConversations(ctx context.Context, filter schemas.ConversationFilter) ([]tables.TableConversation, error) {
+	return nil, nil
+}
+
+func (m *MockConfigStore) UpsertConversation(ctx context.Context, conversation *tables.TableConversation, tx ...*gorm.DB) error {
+	return nil
+}
+
+func (m *MockConfigStore) DeleteConversation(ctx context.Context, id string) error {
+	return nil
+}
+
+func (m *MockConfigStore) DeleteConversations(ctx context.Context, filter schemas.ConversationFilter) (int64, error) {
+	return 0, nil
+}
+
 // Model pricing
 func (m *MockConfigStore) GetModelPrices(ctx context.Context) ([]tables.TableModelPricing, error) {
 	return nil, nil
@@ -1174,6 +1387,82 @@ func TestGenerateKeyHash(t *testing.T) {
 	if hash1 == hash5 {
 		t.Error("Expected different hash for keys with different Weight")
 	}
+
+	// Test key settings should produce different hash
+	key6 := key1
+	key6.IsTest = true
+	key6.SpendLimit = schemas.Ptr(5.0)
+
+	hash6, err := configstore.GenerateKeyHash(key6)
+	if err != nil {
+		t.Fatalf("Failed to generate hash: %v", err)
+	}
+
+	if hash1 == hash6 {
+		t.Error("Expected different hash for keys with different test key settings")
+	}
+}
+
+// TestValidateTestKeys tests that test keys require a positive spend limit
+func TestValidateTestKeys(t *testing.T) {
+	tests := map[string]struct {
+		key   schemas.Key
+		valid bool
+	}{
+		"regular key":                  {schemas.Key{Name: "prod"}, true},
+		"test key with spend limit":    {schemas.Key{Name: "staging", IsTest: true, SpendLimit: schemas.Ptr(5.0)}, true},
+		"test key without spend limit": {schemas.Key{Name: "staging", IsTest: true}, false},
+		"test key with zero limit":     {schemas.Key{Name: "staging", IsTest: true, SpendLimit: schemas.Ptr(0.0)}, false},
+		"spend limit on a regular key": {schemas.Key{Name: "prod", SpendLimit: schemas.Ptr(5.0)}, false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateTestKeys([]schemas.Key{tt.key})
+			if tt.valid && err != nil {
+				t.Errorf("Expected key to be valid, got: %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("Expected key to be invalid")
+			}
+		})
+	}
+}
+
+// TestValidateSecretReferences tests that file references are rejected on API writes unless they are unchanged
+// from the existing config
+func TestValidateSecretReferences(t *testing.T) {
+	existing := &configstore.ProviderConfig{
+		Keys: []schemas.Key{
+			{ID: "key-1", Name: "from-file", Value: "${file:/var/run/secrets/openai}"},
+		},
+		ProxyConfig: &schemas.ProxyConfig{Password: "${file:/var/run/secrets/proxy}"},
+	}
+	tests := map[string]struct {
+		config configstore.ProviderConfig
+		valid  bool
+	}{
+		"env reference":                       {configstore.ProviderConfig{Keys: []schemas.Key{{ID: "key-2", Value: "${env:OPENAI_API_KEY}"}}}, true},
+		"unchanged file reference":            {configstore.ProviderConfig{Keys: existing.Keys, ProxyConfig: existing.ProxyConfig}, true},
+		"new file reference":                  {configstore.ProviderConfig{Keys: []schemas.Key{{ID: "key-2", Value: "${file:/etc/passwd}"}}}, false},
+		"file reference moved to another key": {configstore.ProviderConfig{Keys: []schemas.Key{{ID: "key-2", Value: "${file:/var/run/secrets/openai}"}}}, false},
+		"file reference in another field":     {configstore.ProviderConfig{Keys: []schemas.Key{{ID: "key-1", Value: "${file:/var/run/secrets/openai}", AzureKeyConfig: &schemas.AzureKeyConfig{Endpoint: "https://x/${file:/etc/hostname}"}}}}, false},
+		"new proxy file reference":            {configstore.ProviderConfig{ProxyConfig: &schemas.ProxyConfig{Username: "${file:/etc/passwd}"}}, false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateSecretReferences(tt.config, existing)
+			if tt.valid && err != nil {
+				t.Errorf("Expected config to be valid, got: %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("Expected config to be invalid")
+			}
+		})
+	}
+
+	if err := ValidateMCPSecretReferences(schemas.MCPClientConfig{Headers: map[string]string{"Authorization": "${file:/etc/passwd}"}}, nil); err == nil {
+		t.Error("Expected the file reference of a new MCP client to be rejected")
+	}
 }
 
 // TestProviderHashComparison_MatchingHash tests that DB config is kept when hashes match
@@ -0,0 +1,31 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	"github.com/maximhq/bifrost/framework/conversations"
+)
+
+// ConversationsConfig configures the conversations stored by Bifrost.
+// Chat requests sent with the x-bf-conversation-id header only carry their new messages, Bifrost stores the history
+// of the conversation and sends it with them, truncated to the limits of the config.
+type ConversationsConfig struct {
+	Enabled bool `json:"enabled"`
+	schemas.ConversationConfig
+	Store conversations.Config `json:"store"` // Where the conversations are stored, defaults to the config store
+}
+
+// NewConversationStore validates the conversations config and creates the store of the conversations
+func NewConversationStore(ctx context.Context, config *ConversationsConfig, configStore configstore.ConfigStore) (schemas.ConversationStore, error) {
+	if err := config.ConversationConfig.Validate(); err != nil {
+		return nil, err
+	}
+	store, err := conversations.NewStore(ctx, &config.Store, configStore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create conversation store: %w", err)
+	}
+	return store, nil
+}
@@ -41,6 +41,9 @@ import (
 //
 // 4. Governance Headers:
 //   - x-bf-vk: Virtual key for governance (required for governance to work)
+//   - x-bf-event-id: Client-supplied event ID, deduplicated per virtual key within its dedupe window
+//   - Idempotency-Key: Client-supplied key, duplicates of a non-stream request share its result
+//   - x-bf-priority: Priority pool of the provider the request is queued in, e.g. "batch"
 //
 // 5. API Key Headers:
 //   - Authorization: Bearer token format only (e.g., "Bearer sk-...") - OpenAI style
@@ -53,6 +56,34 @@ import (
 //   - Creates a cancellable context that can be used to cancel upstream requests when clients disconnect
 //   - This is critical for streaming requests where write errors indicate client disconnects
 //   - Also useful for non-streaming requests to allow provider-level cancellation
+//
+// 7. Structured Output Headers:
+//   - x-bf-structured-output-retries: Validates json_schema responses and retries up to this many times to repair them
+//
+// 8. Debugging Headers:
+//   - x-bf-capture-provider-request: Records the HTTP request sent to the provider in the response and its log
+//
+// 9. Embedding Headers:
+//   - x-bf-allow-mixed-embeddings: Lets embedding requests fall back to other embedding models than the primary model
+//
+// 10. Conversation Headers:
+//   - x-bf-conversation-id: Stored conversation continued by a chat request, which only carries its new messages
+//
+// 11. End User Headers:
+//   - x-bf-user: End user of the request, sent to the provider as the user parameter and limited by the end user limits of the virtual key
+//
+// 12. Routing Headers:
+//   - x-bf-provider: Provider the request is pinned to, within the providers allowed by the virtual key
+//   - x-bf-exclude-providers: Comma-separated providers the request and its fallbacks are never sent to
+//   - x-bf-routing-strategy: How the provider is picked among the providers of the virtual key (weighted, priority or cheapest)
+//   - x-bf-max-cost: Highest estimated cost in dollars of the request, providers costing more are skipped
+//
+// 13. Tagging Headers:
+//   - x-bf-tags: Comma-separated name=value tags of the request, recorded on its logs and metrics when in the allow-list of request tags
+//
+// 14. Request ID Header:
+//   - x-request-id: ID of the request, generated when absent, returned in the x-request-id response header and
+//     forwarded to the providers accepting a client request ID
 
 // Parameters:
 //   - ctx: The FastHTTP request context containing the original headers
@@ -81,6 +112,8 @@ func ConvertToBifrostContext(ctx *fasthttp.RequestCtx, allowDirectKeys bool) (*c
 		requestID = uuid.New().String()
 	}
 	bifrostCtx = context.WithValue(bifrostCtx, schemas.BifrostContextKeyRequestID, requestID)
+	// The ID is returned to the client, so that the request can be found in the logs of the gateway and of the provider
+	ctx.Response.Header.Set("x-request-id", requestID)
 	// Populating all user values from the request context
 	ctx.VisitUserValuesAll(func(key, value any) {
 		bifrostCtx = context.WithValue(bifrostCtx, key, value)
@@ -215,6 +248,122 @@ func ConvertToBifrostContext(ctx *fasthttp.RequestCtx, allowDirectKeys bool) (*c
 			}
 			return true
 		}
+		// Event ID header (x-bf-event-id) used for request deduplication
+		if keyStr == "x-bf-event-id" {
+			if valueStr := strings.TrimSpace(string(value)); valueStr != "" {
+				bifrostCtx = context.WithValue(bifrostCtx, schemas.BifrostContextKeyEventID, valueStr)
+			}
+			return true
+		}
+		// Idempotency key header (Idempotency-Key) coalesces retries of a non-stream request onto a single provider call
+		if keyStr == "idempotency-key" {
+			if valueStr := strings.TrimSpace(string(value)); valueStr != "" {
+				bifrostCtx = context.WithValue(bifrostCtx, schemas.BifrostContextKeyIdempotencyKey, valueStr)
+			}
+			return true
+		}
+		// Priority header (x-bf-priority) selects the priority pool of the provider the request is queued in
+		if keyStr == "x-bf-priority" {
+			if valueStr := strings.TrimSpace(string(value)); valueStr != "" {
+				bifrostCtx = context.WithValue(bifrostCtx, schemas.BifrostContextKeyPriority, valueStr)
+			}
+			return true
+		}
+		// Structured output retries header (x-bf-structured-output-retries) enables json_schema validation of responses
+		if keyStr == "x-bf-structured-output-retries" {
+			if retries, err := strconv.Atoi(strings.TrimSpace(string(value))); err == nil && retries >= 0 {
+				bifrostCtx = context.WithValue(bifrostCtx, schemas.BifrostContextKeyStructuredOutputRetries, retries)
+			}
+			return true
+		}
+		// Stream upstream header (x-bf-stream-upstream) serves a non-stream request from a provider stream, or not
+		if keyStr == "x-bf-stream-upstream" {
+			if streamUpstream, err := strconv.ParseBool(strings.TrimSpace(string(value))); err == nil {
+				bifrostCtx = context.WithValue(bifrostCtx, schemas.BifrostContextKeyStreamUpstream, streamUpstream)
+			}
+			return true
+		}
+		// Capture provider request header (x-bf-capture-provider-request) records the request rendered by the provider
+		if keyStr == "x-bf-capture-provider-request" {
+			if capture, err := strconv.ParseBool(strings.TrimSpace(string(value))); err == nil && capture {
+				bifrostCtx = context.WithValue(bifrostCtx, schemas.BifrostContextKeyCaptureProviderRequest, true)
+			}
+			return true
+		}
+		// Allow mixed embeddings header (x-bf-allow-mixed-embeddings) lets embedding requests fall back to other models
+		if keyStr == "x-bf-allow-mixed-embeddings" {
+			if allow, err := strconv.ParseBool(strings.TrimSpace(string(value))); err == nil && allow {
+				bifrostCtx = context.WithValue(bifrostCtx, schemas.BifrostContextKeyAllowMixedEmbeddings, true)
+			}
+			return true
+		}
+		// Conversation ID header (x-bf-conversation-id) continues a conversation stored by Bifrost
+		if keyStr == "x-bf-conversation-id" {
+			if valueStr := strings.TrimSpace(string(value)); valueStr != "" {
+				bifrostCtx = context.WithValue(bifrostCtx, schemas.BifrostContextKeyConversationID, valueStr)
+			}
+			return true
+		}
+		// End user header (x-bf-user) identifies the end user of the request
+		if keyStr == "x-bf-user" {
+			if valueStr := strings.TrimSpace(string(value)); valueStr != "" {
+				bifrostCtx = context.WithValue(bifrostCtx, schemas.BifrostContextKeyEndUser, valueStr)
+			}
+			return true
+		}
+		// Routing headers constrain the providers the request is sent to, within the bounds of governance
+		if keyStr == "x-bf-provider" {
+			if valueStr := strings.TrimSpace(string(value)); valueStr != "" {
+				bifrostCtx = context.WithValue(bifrostCtx, schemas.BifrostContextKeyRoutingProvider, schemas.ModelProvider(valueStr))
+			}
+			return true
+		}
+		if keyStr == "x-bf-exclude-providers" {
+			var excluded []schemas.ModelProvider
+			for _, v := range strings.Split(string(value), ",") {
+				if trimmed := strings.TrimSpace(v); trimmed != "" {
+					excluded = append(excluded, schemas.ModelProvider(trimmed))
+				}
+			}
+			if len(excluded) > 0 {
+				bifrostCtx = context.WithValue(bifrostCtx, schemas.BifrostContextKeyRoutingExcludeProviders, excluded)
+			}
+			return true
+		}
+		if keyStr == "x-bf-routing-strategy" {
+			if valueStr := strings.TrimSpace(string(value)); valueStr != "" {
+				bifrostCtx = context.WithValue(bifrostCtx, schemas.BifrostContextKeyRoutingStrategy, valueStr)
+			}
+			return true
+		}
+		if keyStr == "x-bf-max-cost" {
+			if maxCost, err := strconv.ParseFloat(strings.TrimSpace(string(value)), 64); err == nil && maxCost >= 0 {
+				bifrostCtx = context.WithValue(bifrostCtx, schemas.BifrostContextKeyRoutingMaxCost, maxCost)
+			}
+			return true
+		}
+		// Tags header (x-bf-tags) attaches name=value tags to the request, validated by bifrost against the allow-list
+		if keyStr == "x-bf-tags" {
+			tags := make(map[string]string)
+			for _, pair := range strings.Split(string(value), ",") {
+				name, tagValue, ok := strings.Cut(pair, "=")
+				if name, tagValue = strings.TrimSpace(name), strings.TrimSpace(tagValue); ok && name != "" && tagValue != "" {
+					tags[name] = tagValue
+				}
+			}
+			if len(tags) > 0 {
+				bifrostCtx = context.WithValue(bifrostCtx, schemas.BifrostContextKeyRequestTags, tags)
+			}
+			return true
+		}
+		// Partial failures header (x-bf-partial-failures) returns partial results or fails requests of many inputs
+		if keyStr == "x-bf-partial-failures" {
+			mode := schemas.PartialFailureMode(strings.ToLower(strings.TrimSpace(string(value))))
+			if mode == schemas.PartialFailureModePartial || mode == schemas.PartialFailureModeStrict {
+				bifrostCtx = context.WithValue(bifrostCtx, schemas.BifrostContextKeyPartialFailureMode, mode)
+			}
+			return true
+		}
 		// Send back raw response header
 		if keyStr == "x-bf-send-back-raw-response" {
 			if valueStr := string(value); valueStr == "true" {
@@ -0,0 +1,21 @@
+package lib
+
+import "fmt"
+
+// DataRetentionConfig configures how long Bifrost keeps the data of its requests, next to the log retention of the
+// client config which deletes the logs with their usage and cost records.
+type DataRetentionConfig struct {
+	LogPayloadDays   int `json:"log_payload_days,omitempty"`  // Days after which the request and response payloads of the logs are cleared, 0 keeps them as long as the logs
+	ConversationDays int `json:"conversation_days,omitempty"` // Days after which the conversations not updated since are deleted, 0 keeps them
+}
+
+// Validate checks the retention periods of the config
+func (c *DataRetentionConfig) Validate() error {
+	if c.LogPayloadDays < 0 {
+		return fmt.Errorf("data retention log payload days cannot be negative, got %d", c.LogPayloadDays)
+	}
+	if c.ConversationDays < 0 {
+		return fmt.Errorf("data retention conversation days cannot be negative, got %d", c.ConversationDays)
+	}
+	return nil
+}
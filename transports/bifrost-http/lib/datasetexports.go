@@ -0,0 +1,738 @@
+package lib
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/logstore"
+)
+
+const (
+	// DefaultDatasetExportMaxRecords is the maximum number of examples of an export when none is configured
+	DefaultDatasetExportMaxRecords = 100000
+	// maxFinishedDatasetExports is the number of finished exports kept in memory with their files
+	maxFinishedDatasetExports = 20
+	// datasetExportPageSize is the number of logs read from the log store at once
+	datasetExportPageSize = 500
+)
+
+// Dataset formats
+const (
+	DatasetFormatOpenAI   = "openai"   // OpenAI chat fine-tuning JSONL, one {"messages": [...], "tools": [...]} per line
+	DatasetFormatShareGPT = "sharegpt" // ShareGPT JSONL, one {"conversations": [{"from": ..., "value": ...}], "system": ..., "tools": ...} per line
+)
+
+// Dataset destinations
+const (
+	DatasetDestinationS3  = ObjectStorageS3
+	DatasetDestinationGCS = ObjectStorageGCS
+)
+
+// Dataset export statuses
+const (
+	DatasetExportPending   = "pending"
+	DatasetExportRunning   = "running"
+	DatasetExportCompleted = "completed"
+	DatasetExportFailed    = "failed"
+	DatasetExportCancelled = "cancelled"
+)
+
+// datasetObjects are the request types of the logs exported, the other requests have no conversation to learn from
+var datasetObjects = []string{
+	string(schemas.ChatCompletionRequest),
+	string(schemas.ChatCompletionStreamRequest),
+	string(schemas.ResponsesRequest),
+	string(schemas.ResponsesStreamRequest),
+}
+
+// DatasetExportsConfig configures the export of logged conversations as fine-tuning datasets
+type DatasetExportsConfig struct {
+	Enabled     bool              `json:"enabled"`
+	MaxRecords  int               `json:"max_records,omitempty"`  // Maximum number of examples of an export, defaults to DefaultDatasetExportMaxRecords
+	TempDir     string            `json:"temp_dir,omitempty"`     // Directory the datasets are written to, defaults to the system temp directory
+	PIIPatterns map[string]string `json:"pii_patterns,omitempty"` // Regular expressions redacted next to the default ones, by the label they are replaced with
+}
+
+// Validate checks the dataset exports config for invalid fields
+func (c *DatasetExportsConfig) Validate() error {
+	if c.MaxRecords < 0 {
+		return fmt.Errorf("dataset exports max_records cannot be negative")
+	}
+	if _, err := NewPIIRedactor(c.PIIPatterns); err != nil {
+		return fmt.Errorf("dataset exports: %v", err)
+	}
+	return nil
+}
+
+// DatasetDestination is the bucket a dataset is uploaded to once written, with the credentials of the environment:
+// the AWS default credential chain for S3, and the application default credentials for Google Cloud Storage
+type DatasetDestination struct {
+	Type   string `json:"type"` // One of the DatasetDestination* constants
+	Bucket string `json:"bucket"`
+	Key    string `json:"key,omitempty"`    // Object name, defaults to bifrost-datasets/<export id>.jsonl
+	Region string `json:"region,omitempty"` // Region of the S3 bucket, defaults to the region of the AWS environment
+}
+
+// DatasetExportRequest is the dataset an export writes
+type DatasetExportRequest struct {
+	Filters     logstore.SearchFilters `json:"filters"`               // Logs exported, only successful chat and responses requests are
+	Format      string                 `json:"format"`                // One of the DatasetFormat* constants
+	RedactPII   *bool                  `json:"redact_pii,omitempty"`  // Redact personal information from the messages, defaults to true
+	MaxRecords  int                    `json:"max_records,omitempty"` // Maximum number of examples, capped by the config
+	Destination *DatasetDestination    `json:"destination,omitempty"` // Bucket the dataset is uploaded to, it is also downloadable from the gateway
+	Namespace   string                 `json:"-"`                     // Namespace of the admin starting the export, whose encrypted payloads are decrypted
+}
+
+// Validate checks the format and destination of the export
+func (r *DatasetExportRequest) Validate() error {
+	switch r.Format {
+	case DatasetFormatOpenAI, DatasetFormatShareGPT:
+	default:
+		return fmt.Errorf("format must be %q or %q", DatasetFormatOpenAI, DatasetFormatShareGPT)
+	}
+	if r.MaxRecords < 0 {
+		return fmt.Errorf("max_records cannot be negative")
+	}
+	if r.Destination != nil {
+		switch r.Destination.Type {
+		case DatasetDestinationS3, DatasetDestinationGCS:
+		default:
+			return fmt.Errorf("destination type must be %q or %q", DatasetDestinationS3, DatasetDestinationGCS)
+		}
+		if r.Destination.Bucket == "" {
+			return fmt.Errorf("destination bucket is required")
+		}
+	}
+	return nil
+}
+
+// DatasetExport is the progress of a dataset export
+type DatasetExport struct {
+	ID          string              `json:"id"`
+	Format      string              `json:"format"`
+	Status      string              `json:"status"` // One of the DatasetExport* constants
+	RedactPII   bool                `json:"redact_pii"`
+	Namespace   string              `json:"namespace,omitempty"`
+	Scanned     int                 `json:"scanned"` // Logs read
+	Records     int                 `json:"records"` // Examples written
+	Skipped     int                 `json:"skipped"` // Logs without a conversation to export, or with a payload that could not be read
+	Size        int64               `json:"size"`    // Bytes written
+	Destination *DatasetDestination `json:"destination,omitempty"`
+	Location    string              `json:"location,omitempty"` // URL of the uploaded dataset, set once the export completed
+	Error       string              `json:"error,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+	StartedAt   *time.Time          `json:"started_at,omitempty"`
+	CompletedAt *time.Time          `json:"completed_at,omitempty"`
+}
+
+// DatasetLogSource is the subset of the log manager the exports read the logs from
+type DatasetLogSource interface {
+	Search(ctx context.Context, filters *logstore.SearchFilters, pagination *logstore.PaginationOptions) (*logstore.SearchResult, error)
+}
+
+// datasetUploader uploads the dataset file to its destination and returns its location
+type datasetUploader func(ctx context.Context, destination *DatasetDestination, file *os.File, size int64) (string, error)
+
+// datasetExport is an export with its request, file and cancellation
+type datasetExport struct {
+	export  DatasetExport
+	request DatasetExportRequest
+	path    string
+	cancel  context.CancelFunc
+}
+
+// DatasetExportManager runs dataset exports in the background and keeps the files of the recent ones
+type DatasetExportManager struct {
+	config   DatasetExportsConfig
+	source   DatasetLogSource
+	redactor *PIIRedactor
+	upload   datasetUploader
+	ctx      context.Context
+	cancel   context.CancelFunc
+
+	mu       sync.RWMutex
+	exports  map[string]*datasetExport
+	finished []string // IDs of finished exports, oldest first
+	wg       sync.WaitGroup
+}
+
+// NewDatasetExportManager creates a dataset export manager reading the logs of the source
+func NewDatasetExportManager(config *DatasetExportsConfig, source DatasetLogSource) (*DatasetExportManager, error) {
+	if config == nil {
+		return nil, fmt.Errorf("dataset exports config is required")
+	}
+	if source == nil {
+		return nil, fmt.Errorf("log store is required")
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	resolved := *config
+	if resolved.MaxRecords == 0 {
+		resolved.MaxRecords = DefaultDatasetExportMaxRecords
+	}
+	redactor, err := NewPIIRedactor(resolved.PIIPatterns)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &DatasetExportManager{
+		config:   resolved,
+		source:   source,
+		redactor: redactor,
+		upload:   uploadDataset,
+		ctx:      ctx,
+		cancel:   cancel,
+		exports:  make(map[string]*datasetExport),
+	}, nil
+}
+
+// StartExport starts writing a dataset in the background
+func (m *DatasetExportManager) StartExport(request DatasetExportRequest) (*DatasetExport, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	if err := m.ctx.Err(); err != nil {
+		return nil, fmt.Errorf("dataset export manager is stopped")
+	}
+	if request.MaxRecords == 0 || request.MaxRecords > m.config.MaxRecords {
+		request.MaxRecords = m.config.MaxRecords
+	}
+	file, err := os.CreateTemp(m.config.TempDir, "bifrost-dataset-*.jsonl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dataset file: %w", err)
+	}
+	file.Close()
+
+	id := uuid.NewString()
+	if request.Destination != nil {
+		destination := *request.Destination
+		if destination.Key == "" {
+			destination.Key = "bifrost-datasets/" + id + ".jsonl"
+		}
+		request.Destination = &destination
+	}
+	exportCtx, cancel := context.WithCancel(m.ctx)
+	export := &datasetExport{
+		export: DatasetExport{
+			ID:          id,
+			Format:      request.Format,
+			Status:      DatasetExportPending,
+			RedactPII:   request.RedactPII == nil || *request.RedactPII,
+			Namespace:   request.Namespace,
+			Destination: request.Destination,
+			CreatedAt:   time.Now(),
+		},
+		request: request,
+		path:    file.Name(),
+		cancel:  cancel,
+	}
+	m.mu.Lock()
+	m.exports[export.export.ID] = export
+	snapshot := export.export
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer cancel()
+		m.runExport(exportCtx, export)
+	}()
+	return &snapshot, nil
+}
+
+// GetExport returns the progress of an export
+func (m *DatasetExportManager) GetExport(id string) (*DatasetExport, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	export, ok := m.exports[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	snapshot := export.export
+	return &snapshot, nil
+}
+
+// ListExports returns the progress of all exports, most recent first
+func (m *DatasetExportManager) ListExports() []DatasetExport {
+	m.mu.RLock()
+	exports := make([]DatasetExport, 0, len(m.exports))
+	for _, export := range m.exports {
+		exports = append(exports, export.export)
+	}
+	m.mu.RUnlock()
+	sort.Slice(exports, func(i, j int) bool { return exports[i].CreatedAt.After(exports[j].CreatedAt) })
+	return exports
+}
+
+// CancelExport cancels a pending or running export
+func (m *DatasetExportManager) CancelExport(id string) (*DatasetExport, error) {
+	m.mu.RLock()
+	export, ok := m.exports[id]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	export.cancel()
+	return m.GetExport(id)
+}
+
+// OpenExport opens the dataset file of a completed export for download, the caller closes the file
+func (m *DatasetExportManager) OpenExport(id string) (*os.File, *DatasetExport, error) {
+	m.mu.RLock()
+	export, ok := m.exports[id]
+	if !ok {
+		m.mu.RUnlock()
+		return nil, nil, ErrNotFound
+	}
+	snapshot := export.export
+	m.mu.RUnlock()
+	if snapshot.Status != DatasetExportCompleted {
+		return nil, &snapshot, fmt.Errorf("dataset export is %s", snapshot.Status)
+	}
+	// The file stays readable if the export is evicted during the download
+	file, err := os.Open(export.path)
+	if err != nil {
+		return nil, &snapshot, fmt.Errorf("failed to open dataset file: %v", err)
+	}
+	return file, &snapshot, nil
+}
+
+// Stop cancels all exports, waits for them to finish and removes their files
+func (m *DatasetExportManager) Stop() {
+	m.cancel()
+	m.wg.Wait()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, export := range m.exports {
+		os.Remove(export.path)
+		delete(m.exports, id)
+	}
+}
+
+// runExport reads the logs matching the filters of the export page by page, writes their conversations in the format
+// of the export and uploads the dataset to its destination
+func (m *DatasetExportManager) runExport(ctx context.Context, export *datasetExport) {
+	startedAt := time.Now()
+	m.mu.Lock()
+	export.export.Status = DatasetExportRunning
+	export.export.StartedAt = &startedAt
+	m.mu.Unlock()
+
+	file, err := os.OpenFile(export.path, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		m.finishExport(export, DatasetExportFailed, "", fmt.Sprintf("failed to open dataset file: %v", err))
+		return
+	}
+	writer := bufio.NewWriter(file)
+	err = m.writeDataset(ctx, export, writer)
+	if err == nil {
+		err = writer.Flush()
+	}
+	file.Close()
+	if ctx.Err() != nil {
+		m.finishExport(export, DatasetExportCancelled, "", "")
+		return
+	}
+	if err != nil {
+		m.finishExport(export, DatasetExportFailed, "", err.Error())
+		return
+	}
+
+	location := ""
+	if destination := export.request.Destination; destination != nil {
+		file, err := os.Open(export.path)
+		if err != nil {
+			m.finishExport(export, DatasetExportFailed, "", fmt.Sprintf("failed to open dataset file: %v", err))
+			return
+		}
+		m.mu.RLock()
+		size := export.export.Size
+		m.mu.RUnlock()
+		location, err = m.upload(ctx, destination, file, size)
+		file.Close()
+		if err != nil {
+			if ctx.Err() != nil {
+				m.finishExport(export, DatasetExportCancelled, "", "")
+				return
+			}
+			m.finishExport(export, DatasetExportFailed, "", fmt.Sprintf("failed to upload dataset: %v", err))
+			return
+		}
+	}
+	m.finishExport(export, DatasetExportCompleted, location, "")
+}
+
+// writeDataset writes the examples of the logs of the export, until the logs or the records of the export run out
+func (m *DatasetExportManager) writeDataset(ctx context.Context, export *datasetExport, writer io.Writer) error {
+	filters := export.request.Filters
+	filters.Status = []string{"success"}
+	if len(filters.Objects) == 0 {
+		filters.Objects = datasetObjects
+	}
+	// Logs written during the export are left out, so that pages do not shift under the export
+	if filters.EndTime == nil || filters.EndTime.After(export.export.CreatedAt) {
+		createdAt := export.export.CreatedAt
+		filters.EndTime = &createdAt
+	}
+	var redactor *PIIRedactor
+	if export.export.RedactPII {
+		redactor = m.redactor
+	}
+
+	records := 0
+	for offset := 0; ; offset += datasetExportPageSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		result, err := m.source.Search(ctx, &filters, &logstore.PaginationOptions{
+			Limit:  datasetExportPageSize,
+			Offset: offset,
+			SortBy: "timestamp",
+			Order:  "asc",
+		})
+		if err != nil {
+			return fmt.Errorf("failed to read logs: %v", err)
+		}
+
+		var written, skipped int
+		var size int64
+		for i := range result.Logs {
+			if records+written >= export.request.MaxRecords {
+				break
+			}
+			log := &result.Logs[i]
+			if log.PayloadEncrypted {
+				// Encrypted payloads are only exported by the admins of their namespace
+				if export.request.Namespace == "" || log.Namespace != export.request.Namespace || log.DecryptPayload(ctx) != nil {
+					skipped++
+					continue
+				}
+			}
+			example := newDatasetExample(log)
+			if example == nil {
+				skipped++
+				continue
+			}
+			if redactor != nil {
+				example.redact(redactor)
+			}
+			line, err := example.encode(export.request.Format)
+			if err != nil {
+				skipped++
+				continue
+			}
+			n, err := writer.Write(append(line, '\n'))
+			if err != nil {
+				return fmt.Errorf("failed to write dataset: %v", err)
+			}
+			written++
+			size += int64(n)
+		}
+		records += written
+
+		m.mu.Lock()
+		export.export.Scanned += written + skipped
+		export.export.Records += written
+		export.export.Skipped += skipped
+		export.export.Size += size
+		m.mu.Unlock()
+
+		if len(result.Logs) < datasetExportPageSize || records >= export.request.MaxRecords {
+			return nil
+		}
+	}
+}
+
+// finishExport sets the final status of an export and evicts the oldest finished exports with their files
+func (m *DatasetExportManager) finishExport(export *datasetExport, status string, location string, message string) {
+	completedAt := time.Now()
+	m.mu.Lock()
+	export.export.Status = status
+	export.export.Location = location
+	export.export.Error = message
+	export.export.CompletedAt = &completedAt
+	if status != DatasetExportCompleted {
+		os.Remove(export.path)
+	}
+	m.finished = append(m.finished, export.export.ID)
+	for len(m.finished) > maxFinishedDatasetExports {
+		if evicted, ok := m.exports[m.finished[0]]; ok {
+			os.Remove(evicted.path)
+			delete(m.exports, m.finished[0])
+		}
+		m.finished = m.finished[1:]
+	}
+	snapshot := export.export
+	m.mu.Unlock()
+
+	logger.Info("dataset export %s %s: %d records written from %d logs, %d skipped", snapshot.ID, status, snapshot.Records, snapshot.Scanned, snapshot.Skipped)
+}
+
+// datasetExample is a logged conversation: the messages sent to the model followed by its answer, with the tools it
+// was offered
+type datasetExample struct {
+	messages []schemas.ChatMessage
+	tools    []schemas.ChatTool
+}
+
+// newDatasetExample returns the conversation of a chat or responses log, or nil when the log has no input or answer,
+// or has content other than text which the datasets cannot carry
+func newDatasetExample(log *logstore.Log) *datasetExample {
+	var input, output []schemas.ChatMessage
+	switch {
+	case len(log.InputHistoryParsed) > 0 && log.OutputMessageParsed != nil:
+		input = log.InputHistoryParsed
+		output = []schemas.ChatMessage{*log.OutputMessageParsed}
+	case len(log.ResponsesInputHistoryParsed) > 0 && len(log.ResponsesOutputParsed) > 0:
+		input = schemas.ToChatMessages(log.ResponsesInputHistoryParsed)
+		output = schemas.ToChatMessages(log.ResponsesOutputParsed)
+	default:
+		return nil
+	}
+
+	example := &datasetExample{messages: make([]schemas.ChatMessage, 0, len(input)+len(output))}
+	for _, message := range append(append([]schemas.ChatMessage(nil), input...), output...) {
+		text, ok := chatMessageText(message.Content)
+		if !ok {
+			return nil
+		}
+		// Messages are rebuilt with their text and tool calls only, dropping reasoning, annotations and cache controls
+		clean := schemas.ChatMessage{Role: message.Role, Name: message.Name}
+		if text != "" {
+			clean.Content = &schemas.ChatMessageContent{ContentStr: schemas.Ptr(text)}
+		}
+		if message.ChatToolMessage != nil {
+			clean.ChatToolMessage = &schemas.ChatToolMessage{ToolCallID: message.ChatToolMessage.ToolCallID}
+		}
+		if message.ChatAssistantMessage != nil && len(message.ChatAssistantMessage.ToolCalls) > 0 {
+			clean.ChatAssistantMessage = &schemas.ChatAssistantMessage{ToolCalls: message.ChatAssistantMessage.ToolCalls}
+		}
+		if clean.Content == nil && clean.ChatAssistantMessage == nil {
+			continue
+		}
+		example.messages = append(example.messages, clean)
+	}
+	if n := len(example.messages); n < 2 || example.messages[n-1].Role != schemas.ChatMessageRoleAssistant {
+		return nil
+	}
+	for _, tool := range log.ToolsParsed {
+		if tool.Function != nil {
+			example.tools = append(example.tools, schemas.ChatTool{Type: tool.Type, Function: tool.Function})
+		}
+	}
+	return example
+}
+
+// chatMessageText returns the text of the content of a message, and false if it has blocks other than text
+func chatMessageText(content *schemas.ChatMessageContent) (string, bool) {
+	if content == nil {
+		return "", true
+	}
+	if content.ContentStr != nil {
+		return *content.ContentStr, true
+	}
+	var parts []string
+	for _, block := range content.ContentBlocks {
+		switch {
+		case block.Text != nil:
+			parts = append(parts, *block.Text)
+		case block.Refusal != nil:
+			parts = append(parts, *block.Refusal)
+		default:
+			return "", false
+		}
+	}
+	return strings.Join(parts, "\n"), true
+}
+
+// redact replaces the personal information in the text of the messages and the arguments of the tool calls
+func (e *datasetExample) redact(redactor *PIIRedactor) {
+	for i := range e.messages {
+		message := &e.messages[i]
+		if message.Content != nil && message.Content.ContentStr != nil {
+			message.Content.ContentStr = schemas.Ptr(redactor.Redact(*message.Content.ContentStr))
+		}
+		if message.ChatAssistantMessage != nil {
+			toolCalls := make([]schemas.ChatAssistantMessageToolCall, len(message.ToolCalls))
+			for j, toolCall := range message.ToolCalls {
+				toolCall.Function.Arguments = redactor.Redact(toolCall.Function.Arguments)
+				toolCalls[j] = toolCall
+			}
+			message.ChatAssistantMessage = &schemas.ChatAssistantMessage{ToolCalls: toolCalls}
+		}
+	}
+}
+
+// datasetToolCall is a tool call of an assistant message in the OpenAI format
+type datasetToolCall struct {
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// datasetMessage is a message of an OpenAI fine-tuning example
+type datasetMessage struct {
+	Role       string            `json:"role"`
+	Content    *string           `json:"content"` // null on assistant messages only calling tools
+	Name       string            `json:"name,omitempty"`
+	ToolCalls  []datasetToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string            `json:"tool_call_id,omitempty"`
+}
+
+// shareGPTTurn is a turn of a ShareGPT conversation
+type shareGPTTurn struct {
+	From  string `json:"from"` // human, gpt, function_call or observation
+	Value string `json:"value"`
+}
+
+// encode returns the JSON line of the example in the format
+func (e *datasetExample) encode(format string) ([]byte, error) {
+	if format == DatasetFormatShareGPT {
+		return e.encodeShareGPT()
+	}
+	return e.encodeOpenAI()
+}
+
+// encodeOpenAI encodes the example in the OpenAI chat fine-tuning format
+func (e *datasetExample) encodeOpenAI() ([]byte, error) {
+	messages := make([]datasetMessage, 0, len(e.messages))
+	for _, message := range e.messages {
+		m := datasetMessage{Role: string(message.Role)}
+		if message.Content != nil {
+			m.Content = message.Content.ContentStr
+		}
+		if message.Name != nil {
+			m.Name = *message.Name
+		}
+		if message.ChatToolMessage != nil && message.ToolCallID != nil {
+			m.ToolCallID = *message.ToolCallID
+		}
+		if message.ChatAssistantMessage != nil {
+			m.ToolCalls = datasetToolCalls(message.ToolCalls)
+		}
+		messages = append(messages, m)
+	}
+	return json.Marshal(struct {
+		Messages []datasetMessage   `json:"messages"`
+		Tools    []schemas.ChatTool `json:"tools,omitempty"`
+	}{messages, e.tools})
+}
+
+// encodeShareGPT encodes the example in the ShareGPT format: system prompts are joined in the system field, tool
+// calls are function_call turns and tool results observation turns, several calls or results being JSON lists
+func (e *datasetExample) encodeShareGPT() ([]byte, error) {
+	var system []string
+	var turns []shareGPTTurn
+	var observations []string
+	flushObservations := func() {
+		switch len(observations) {
+		case 0:
+			return
+		case 1:
+			turns = append(turns, shareGPTTurn{From: "observation", Value: observations[0]})
+		default:
+			value, _ := json.Marshal(observations)
+			turns = append(turns, shareGPTTurn{From: "observation", Value: string(value)})
+		}
+		observations = nil
+	}
+	for _, message := range e.messages {
+		text := ""
+		if message.Content != nil && message.Content.ContentStr != nil {
+			text = *message.Content.ContentStr
+		}
+		if message.Role == schemas.ChatMessageRoleTool {
+			observations = append(observations, text)
+			continue
+		}
+		flushObservations()
+		switch message.Role {
+		case schemas.ChatMessageRoleSystem, schemas.ChatMessageRoleDeveloper:
+			system = append(system, text)
+		case schemas.ChatMessageRoleAssistant:
+			if message.ChatAssistantMessage == nil || len(message.ToolCalls) == 0 {
+				turns = append(turns, shareGPTTurn{From: "gpt", Value: text})
+				continue
+			}
+			calls := make([]map[string]any, 0, len(message.ToolCalls))
+			for _, toolCall := range datasetToolCalls(message.ToolCalls) {
+				var arguments any = toolCall.Function.Arguments
+				var parsed map[string]any
+				if json.Unmarshal([]byte(toolCall.Function.Arguments), &parsed) == nil {
+					arguments = parsed
+				}
+				calls = append(calls, map[string]any{"name": toolCall.Function.Name, "arguments": arguments})
+			}
+			var value []byte
+			if len(calls) == 1 {
+				value, _ = json.Marshal(calls[0])
+			} else {
+				value, _ = json.Marshal(calls)
+			}
+			turns = append(turns, shareGPTTurn{From: "function_call", Value: string(value)})
+		default:
+			turns = append(turns, shareGPTTurn{From: "human", Value: text})
+		}
+	}
+	flushObservations()
+
+	var tools string
+	if len(e.tools) > 0 {
+		functions := make([]*schemas.ChatToolFunction, 0, len(e.tools))
+		for _, tool := range e.tools {
+			functions = append(functions, tool.Function)
+		}
+		encoded, err := json.Marshal(functions)
+		if err != nil {
+			return nil, err
+		}
+		tools = string(encoded)
+	}
+	return json.Marshal(struct {
+		Conversations []shareGPTTurn `json:"conversations"`
+		System        string         `json:"system,omitempty"`
+		Tools         string         `json:"tools,omitempty"`
+	}{turns, strings.Join(system, "\n\n"), tools})
+}
+
+// datasetToolCalls converts the tool calls of a message to the OpenAI format
+func datasetToolCalls(toolCalls []schemas.ChatAssistantMessageToolCall) []datasetToolCall {
+	calls := make([]datasetToolCall, 0, len(toolCalls))
+	for _, toolCall := range toolCalls {
+		call := datasetToolCall{Type: "function"}
+		if toolCall.ID != nil {
+			call.ID = *toolCall.ID
+		}
+		if toolCall.Function.Name != nil {
+			call.Function.Name = *toolCall.Function.Name
+		}
+		call.Function.Arguments = toolCall.Function.Arguments
+		calls = append(calls, call)
+	}
+	return calls
+}
+
+// uploadDataset uploads the dataset file to its S3 or Google Cloud Storage bucket
+func uploadDataset(ctx context.Context, destination *DatasetDestination, file *os.File, size int64) (string, error) {
+	return uploadObject(ctx, objectLocation{
+		storage:     destination.Type,
+		bucket:      destination.Bucket,
+		key:         destination.Key,
+		region:      destination.Region,
+		contentType: "application/jsonl",
+	}, file, size)
+}
@@ -0,0 +1,257 @@
+package lib
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/logstore"
+)
+
+// fakeLogSource serves its logs page by page and records the filters of the searches
+type fakeLogSource struct {
+	logs    []logstore.Log
+	filters []logstore.SearchFilters
+}
+
+func (s *fakeLogSource) Search(ctx context.Context, filters *logstore.SearchFilters, pagination *logstore.PaginationOptions) (*logstore.SearchResult, error) {
+	s.filters = append(s.filters, *filters)
+	end := min(pagination.Offset+pagination.Limit, len(s.logs))
+	if pagination.Offset >= end {
+		return &logstore.SearchResult{}, nil
+	}
+	return &logstore.SearchResult{Logs: s.logs[pagination.Offset:end]}, nil
+}
+
+// testDatasetLogs returns a chat log with a tool call, a responses log, an embedding log and an image chat log
+func testDatasetLogs() []logstore.Log {
+	text := func(s string) *schemas.ChatMessageContent {
+		return &schemas.ChatMessageContent{ContentStr: schemas.Ptr(s)}
+	}
+	return []logstore.Log{
+		{
+			ID: "chat",
+			InputHistoryParsed: []schemas.ChatMessage{
+				{Role: schemas.ChatMessageRoleSystem, Content: text("You are a support agent.")},
+				{Role: schemas.ChatMessageRoleUser, Content: text("My email is jane.doe@example.com, where is order 42?")},
+				{Role: schemas.ChatMessageRoleAssistant, ChatAssistantMessage: &schemas.ChatAssistantMessage{
+					ToolCalls: []schemas.ChatAssistantMessageToolCall{{
+						ID:       schemas.Ptr("call_1"),
+						Function: schemas.ChatAssistantMessageToolCallFunction{Name: schemas.Ptr("track_order"), Arguments: `{"order":42,"phone":"+1 415 555 2671"}`},
+					}},
+				}},
+				{Role: schemas.ChatMessageRoleTool, Content: text("shipped"), ChatToolMessage: &schemas.ChatToolMessage{ToolCallID: schemas.Ptr("call_1")}},
+			},
+			OutputMessageParsed: &schemas.ChatMessage{
+				Role:                 schemas.ChatMessageRoleAssistant,
+				Content:              text("Your order shipped, we will write to jane.doe@example.com."),
+				ChatAssistantMessage: &schemas.ChatAssistantMessage{Thought: schemas.Ptr("the user wants the status")},
+			},
+			ToolsParsed: []schemas.ChatTool{{Type: schemas.ChatToolTypeFunction, Function: &schemas.ChatToolFunction{Name: "track_order"}}},
+		},
+		{
+			ID: "responses",
+			ResponsesInputHistoryParsed: []schemas.ResponsesMessage{{
+				Role:    schemas.Ptr(schemas.ResponsesInputMessageRoleUser),
+				Content: &schemas.ResponsesMessageContent{ContentStr: schemas.Ptr("Card 4111 1111 1111 1111 was declined")},
+			}},
+			ResponsesOutputParsed: []schemas.ResponsesMessage{{
+				Type:    schemas.Ptr(schemas.ResponsesMessageTypeMessage),
+				Role:    schemas.Ptr(schemas.ResponsesInputMessageRoleAssistant),
+				Content: &schemas.ResponsesMessageContent{ContentStr: schemas.Ptr("Please try another card.")},
+			}},
+		},
+		{ID: "embedding", EmbeddingOutputParsed: []schemas.EmbeddingData{{}}},
+		{
+			ID: "image",
+			InputHistoryParsed: []schemas.ChatMessage{{Role: schemas.ChatMessageRoleUser, Content: &schemas.ChatMessageContent{
+				ContentBlocks: []schemas.ChatContentBlock{{Type: schemas.ChatContentBlockTypeImage, ImageURLStruct: &schemas.ChatInputImage{URL: "https://example.com/a.png"}}},
+			}}},
+			OutputMessageParsed: &schemas.ChatMessage{Role: schemas.ChatMessageRoleAssistant, Content: text("A cat.")},
+		},
+	}
+}
+
+func newTestDatasetExportManager(t *testing.T, source DatasetLogSource) *DatasetExportManager {
+	t.Helper()
+	return newTestJobManager(t, func() (*DatasetExportManager, error) {
+		return NewDatasetExportManager(&DatasetExportsConfig{
+			Enabled:     true,
+			TempDir:     t.TempDir(),
+			PIIPatterns: map[string]string{"order_id": `order \d+`},
+		}, source)
+	})
+}
+
+// waitForDatasetExport waits until the export leaves the pending and running statuses
+func waitForDatasetExport(t *testing.T, manager *DatasetExportManager, id string) *DatasetExport {
+	t.Helper()
+	return waitForJob(t, id, manager.GetExport, func(export *DatasetExport) bool {
+		return export.Status != DatasetExportPending && export.Status != DatasetExportRunning
+	})
+}
+
+// readDataset starts an export and returns the lines of its dataset
+func readDataset(t *testing.T, manager *DatasetExportManager, request DatasetExportRequest) (*DatasetExport, []map[string]any) {
+	t.Helper()
+	started, err := manager.StartExport(request)
+	if err != nil {
+		t.Fatalf("failed to start export: %v", err)
+	}
+	export := waitForDatasetExport(t, manager, started.ID)
+	if export.Status != DatasetExportCompleted {
+		t.Fatalf("expected the export to complete, got %s: %s", export.Status, export.Error)
+	}
+	file, _, err := manager.OpenExport(export.ID)
+	if err != nil {
+		t.Fatalf("failed to open export: %v", err)
+	}
+	defer file.Close()
+	var lines []map[string]any
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var line map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("expected a JSON line, got %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	return export, lines
+}
+
+// TestDatasetExportManager_OpenAIFormat tests that chat and responses logs are exported as redacted OpenAI examples,
+// and that logs without a text conversation are skipped
+func TestDatasetExportManager_OpenAIFormat(t *testing.T) {
+	source := &fakeLogSource{logs: testDatasetLogs()}
+	manager := newTestDatasetExportManager(t, source)
+
+	export, lines := readDataset(t, manager, DatasetExportRequest{Format: DatasetFormatOpenAI})
+	if export.Scanned != 4 || export.Records != 2 || export.Skipped != 2 || len(lines) != 2 {
+		t.Fatalf("expected 2 of 4 logs exported, got scanned %d records %d skipped %d", export.Scanned, export.Records, export.Skipped)
+	}
+	filters := source.filters[0]
+	if len(filters.Status) != 1 || filters.Status[0] != "success" || len(filters.Objects) != 4 || filters.EndTime == nil {
+		t.Errorf("expected successful chat and responses logs up to the export, got %+v", filters)
+	}
+
+	encoded, _ := json.Marshal(lines[0])
+	chat := string(encoded)
+	for _, unexpected := range []string{"jane.doe@example.com", "415 555 2671", "order 42", "thought"} {
+		if strings.Contains(chat, unexpected) {
+			t.Errorf("expected %q to be removed from the example, got %s", unexpected, chat)
+		}
+	}
+	for _, expected := range []string{"[EMAIL]", "[PHONE]", "[ORDER_ID]", `"tool_call_id":"call_1"`, `"name":"track_order"`, `"tools":[`} {
+		if !strings.Contains(chat, expected) {
+			t.Errorf("expected %q in the example, got %s", expected, chat)
+		}
+	}
+	messages := lines[0]["messages"].([]any)
+	if len(messages) != 5 || messages[4].(map[string]any)["role"] != "assistant" || messages[2].(map[string]any)["content"] != nil {
+		t.Errorf("expected the conversation to end with the answer, with a null content on the tool call, got %v", messages)
+	}
+	responses, _ := json.Marshal(lines[1])
+	if !strings.Contains(string(responses), "Card [CREDIT_CARD] was declined") {
+		t.Errorf("expected the responses log converted to chat messages with the card redacted, got %s", responses)
+	}
+}
+
+// TestDatasetExportManager_ShareGPTFormat tests the ShareGPT turns of an example, without redaction
+func TestDatasetExportManager_ShareGPTFormat(t *testing.T) {
+	manager := newTestDatasetExportManager(t, &fakeLogSource{logs: testDatasetLogs()[:1]})
+
+	_, lines := readDataset(t, manager, DatasetExportRequest{Format: DatasetFormatShareGPT, RedactPII: schemas.Ptr(false)})
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 example, got %d", len(lines))
+	}
+	line := lines[0]
+	if line["system"] != "You are a support agent." || !strings.Contains(line["tools"].(string), "track_order") {
+		t.Errorf("expected the system prompt and tools next to the conversation, got %v", line)
+	}
+	var froms []string
+	for _, turn := range line["conversations"].([]any) {
+		froms = append(froms, turn.(map[string]any)["from"].(string))
+	}
+	if strings.Join(froms, ",") != "human,function_call,observation,gpt" {
+		t.Errorf("expected human, function_call, observation and gpt turns, got %v", froms)
+	}
+	call := line["conversations"].([]any)[1].(map[string]any)["value"].(string)
+	if call != `{"arguments":{"order":42,"phone":"+1 415 555 2671"},"name":"track_order"}` {
+		t.Errorf("expected the tool call with its parsed arguments, unredacted, got %s", call)
+	}
+}
+
+// TestDatasetExportManager_Upload tests that the dataset is uploaded to its destination with a default key, and that
+// a failed upload fails the export
+func TestDatasetExportManager_Upload(t *testing.T) {
+	manager := newTestDatasetExportManager(t, &fakeLogSource{logs: testDatasetLogs()})
+	var uploaded string
+	manager.upload = func(ctx context.Context, destination *DatasetDestination, file *os.File, size int64) (string, error) {
+		data, _ := io.ReadAll(file)
+		if int64(len(data)) != size {
+			t.Errorf("expected %d bytes uploaded, got %d", size, len(data))
+		}
+		uploaded = string(data)
+		if destination.Bucket == "broken" {
+			return "", io.ErrUnexpectedEOF
+		}
+		return "s3://" + destination.Bucket + "/" + destination.Key, nil
+	}
+
+	started, err := manager.StartExport(DatasetExportRequest{Format: DatasetFormatOpenAI, MaxRecords: 1, Destination: &DatasetDestination{Type: DatasetDestinationS3, Bucket: "datasets"}})
+	if err != nil {
+		t.Fatalf("failed to start export: %v", err)
+	}
+	export := waitForDatasetExport(t, manager, started.ID)
+	if export.Status != DatasetExportCompleted || export.Location != "s3://datasets/bifrost-datasets/"+export.ID+".jsonl" {
+		t.Fatalf("expected the dataset uploaded with the default key, got %s %s: %s", export.Status, export.Location, export.Error)
+	}
+	if export.Records != 1 || strings.Count(uploaded, "\n") != 1 {
+		t.Errorf("expected the export to stop at max_records, got %d records", export.Records)
+	}
+
+	started, err = manager.StartExport(DatasetExportRequest{Format: DatasetFormatOpenAI, Destination: &DatasetDestination{Type: DatasetDestinationGCS, Bucket: "broken"}})
+	if err != nil {
+		t.Fatalf("failed to start export: %v", err)
+	}
+	if export := waitForDatasetExport(t, manager, started.ID); export.Status != DatasetExportFailed || !strings.Contains(export.Error, "failed to upload") {
+		t.Errorf("expected the export to fail on the upload, got %s: %s", export.Status, export.Error)
+	}
+	if _, _, err := manager.OpenExport(started.ID); err == nil {
+		t.Errorf("expected failed exports not to be downloadable")
+	}
+
+	if _, err := manager.StartExport(DatasetExportRequest{Format: "alpaca"}); err == nil {
+		t.Errorf("expected unknown formats to be rejected")
+	}
+}
+
+// TestPIIRedactor tests the default patterns and their false positives
+func TestPIIRedactor(t *testing.T) {
+	redactor, err := NewPIIRedactor(nil)
+	if err != nil {
+		t.Fatalf("failed to create redactor: %v", err)
+	}
+	tests := map[string]string{
+		"mail bob@corp.io now":              "mail [EMAIL] now",
+		"ssn 123-45-6789":                   "ssn [SSN]",
+		"server 10.0.0.12 is down":          "server [IP_ADDRESS] is down",
+		"call (415) 555-2671":               "call [PHONE]",
+		"card 4111-1111-1111-1111":          "card [CREDIT_CARD]",
+		"tracking 1234567890123456":         "tracking 1234567890123456", // fails the Luhn check
+		"released on 2024-10-16, version 3": "released on 2024-10-16, version 3",
+	}
+	for input, expected := range tests {
+		if got := redactor.Redact(input); got != expected {
+			t.Errorf("Redact(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+	if _, err := NewPIIRedactor(map[string]string{"bad": "("}); err == nil {
+		t.Errorf("expected invalid patterns to be rejected")
+	}
+}
@@ -0,0 +1,229 @@
+package lib
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+// Reasons of the diagnostic captures
+const (
+	DiagnosticReasonLatency = "latency" // The request took longer than the latency threshold
+	DiagnosticReasonSize    = "size"    // The request or response body was larger than the size threshold
+)
+
+// DiagnosticCapture is an inference request captured for exceeding a threshold of the diagnostics config
+type DiagnosticCapture struct {
+	ID                    string    `json:"id"`
+	RequestID             string    `json:"request_id,omitempty"`
+	CapturedAt            time.Time `json:"captured_at"`
+	Method                string    `json:"method"`
+	Path                  string    `json:"path"`
+	StatusCode            int       `json:"status_code"`
+	LatencyMs             int64     `json:"latency_ms"`
+	RequestSize           int       `json:"request_size"`  // in bytes
+	ResponseSize          int       `json:"response_size"` // in bytes, 0 for streams
+	Stream                bool      `json:"stream"`        // Streamed response, its latency is the time until the stream started and its body is not captured
+	Reasons               []string  `json:"reasons"`       // Thresholds exceeded by the request
+	RequestBody           string    `json:"request_body,omitempty"`
+	ResponseBody          string    `json:"response_body,omitempty"`
+	RequestBodyTruncated  bool      `json:"request_body_truncated,omitempty"`
+	ResponseBodyTruncated bool      `json:"response_body_truncated,omitempty"`
+}
+
+// DiagnosticCaptureSummary is a capture without its bodies, as listed by the diagnostics endpoint
+type DiagnosticCaptureSummary struct {
+	ID           string    `json:"id"`
+	RequestID    string    `json:"request_id,omitempty"`
+	CapturedAt   time.Time `json:"captured_at"`
+	Method       string    `json:"method"`
+	Path         string    `json:"path"`
+	StatusCode   int       `json:"status_code"`
+	LatencyMs    int64     `json:"latency_ms"`
+	RequestSize  int       `json:"request_size"`
+	ResponseSize int       `json:"response_size"`
+	Stream       bool      `json:"stream"`
+	Reasons      []string  `json:"reasons"`
+}
+
+// Diagnostics keeps the latest diagnostic captures in a ring buffer sized after the capacity of the diagnostics
+// config. A nil Diagnostics captures nothing.
+type Diagnostics struct {
+	mu       sync.Mutex
+	captures []DiagnosticCapture // Ring buffer, start is the index of the oldest capture
+	start    int
+	count    int
+
+	sample func() float64 // Draws the sampling of the captures, rand.Float64 by default
+}
+
+// NewDiagnostics creates an empty diagnostics ring buffer
+func NewDiagnostics() *Diagnostics {
+	return &Diagnostics{sample: rand.Float64}
+}
+
+// diagnosticReasons returns the thresholds of the config exceeded by a request
+func diagnosticReasons(config *schemas.DiagnosticsConfig, latency time.Duration, requestSize, responseSize int) []string {
+	var reasons []string
+	if config.LatencyThresholdMs > 0 && latency.Milliseconds() > config.LatencyThresholdMs {
+		reasons = append(reasons, DiagnosticReasonLatency)
+	}
+	if config.SizeThresholdBytes > 0 && (requestSize > config.SizeThresholdBytes || responseSize > config.SizeThresholdBytes) {
+		reasons = append(reasons, DiagnosticReasonSize)
+	}
+	return reasons
+}
+
+// Capture records the request of the context when it exceeded a threshold of the config and is sampled. It is
+// called once the handler of the request returned.
+func (d *Diagnostics) Capture(config *schemas.DiagnosticsConfig, ctx *fasthttp.RequestCtx, latency time.Duration) {
+	if d == nil || config == nil || !config.Enabled {
+		return
+	}
+	stream := ctx.Response.IsBodyStream()
+	requestBody := ctx.Request.Body()
+	var responseBody []byte
+	if !stream {
+		responseBody = ctx.Response.Body()
+	}
+	reasons := diagnosticReasons(config, latency, len(requestBody), len(responseBody))
+	if len(reasons) == 0 {
+		return
+	}
+	if config.SampleRate > 0 && d.sample() >= config.SampleRate {
+		return
+	}
+
+	// Bodies are copied, the request context is reused once the handler returned
+	maxBodyBytes := config.GetMaxBodyBytes()
+	capture := DiagnosticCapture{
+		ID:           uuid.NewString(),
+		RequestID:    string(ctx.Response.Header.Peek("x-request-id")),
+		CapturedAt:   time.Now(),
+		Method:       string(ctx.Method()),
+		Path:         string(ctx.Path()),
+		StatusCode:   ctx.Response.StatusCode(),
+		LatencyMs:    latency.Milliseconds(),
+		RequestSize:  len(requestBody),
+		ResponseSize: len(responseBody),
+		Stream:       stream,
+		Reasons:      reasons,
+	}
+	capture.RequestBody, capture.RequestBodyTruncated = diagnosticBody(requestBody, maxBodyBytes)
+	capture.ResponseBody, capture.ResponseBodyTruncated = diagnosticBody(responseBody, maxBodyBytes)
+	d.add(capture, config.GetCapacity())
+}
+
+// diagnosticBody returns the body truncated to the max size, binary bodies are not captured
+func diagnosticBody(body []byte, maxBytes int) (string, bool) {
+	truncated := false
+	if len(body) > maxBytes {
+		body = body[:maxBytes]
+		truncated = true
+		// Drops the bytes of the last rune when the truncation cut it
+		for i := 1; i < utf8.UTFMax && len(body) > 0 && !utf8.Valid(body); i++ {
+			body = body[:len(body)-1]
+		}
+	}
+	if !utf8.Valid(body) {
+		return "", false
+	}
+	return string(body), truncated
+}
+
+// add appends a capture, dropping the oldest ones beyond the capacity
+func (d *Diagnostics) add(capture DiagnosticCapture, capacity int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.captures) != capacity {
+		d.resize(capacity)
+	}
+	if d.count < capacity {
+		d.captures[(d.start+d.count)%capacity] = capture
+		d.count++
+		return
+	}
+	d.captures[d.start] = capture
+	d.start = (d.start + 1) % capacity
+}
+
+// resize moves the latest captures to a ring buffer of the capacity, the caller holds the lock
+func (d *Diagnostics) resize(capacity int) {
+	latest := d.ordered()
+	if len(latest) > capacity {
+		latest = latest[len(latest)-capacity:]
+	}
+	d.captures = make([]DiagnosticCapture, capacity)
+	copy(d.captures, latest)
+	d.start = 0
+	d.count = len(latest)
+}
+
+// ordered returns the captures from the oldest to the latest, the caller holds the lock
+func (d *Diagnostics) ordered() []DiagnosticCapture {
+	captures := make([]DiagnosticCapture, 0, d.count)
+	for i := 0; i < d.count; i++ {
+		captures = append(captures, d.captures[(d.start+i)%len(d.captures)])
+	}
+	return captures
+}
+
+// List returns the summaries of the captures, the latest first
+func (d *Diagnostics) List() []DiagnosticCaptureSummary {
+	if d == nil {
+		return []DiagnosticCaptureSummary{}
+	}
+	d.mu.Lock()
+	captures := d.ordered()
+	d.mu.Unlock()
+	summaries := make([]DiagnosticCaptureSummary, 0, len(captures))
+	for i := len(captures) - 1; i >= 0; i-- {
+		capture := captures[i]
+		summaries = append(summaries, DiagnosticCaptureSummary{
+			ID:           capture.ID,
+			RequestID:    capture.RequestID,
+			CapturedAt:   capture.CapturedAt,
+			Method:       capture.Method,
+			Path:         capture.Path,
+			StatusCode:   capture.StatusCode,
+			LatencyMs:    capture.LatencyMs,
+			RequestSize:  capture.RequestSize,
+			ResponseSize: capture.ResponseSize,
+			Stream:       capture.Stream,
+			Reasons:      capture.Reasons,
+		})
+	}
+	return summaries
+}
+
+// Get returns the capture of the ID, or of the request ID
+func (d *Diagnostics) Get(id string) (*DiagnosticCapture, bool) {
+	if d == nil {
+		return nil, false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, capture := range d.ordered() {
+		if capture.ID == id || (capture.RequestID != "" && capture.RequestID == id) {
+			return &capture, true
+		}
+	}
+	return nil, false
+}
+
+// Clear drops all the captures
+func (d *Diagnostics) Clear() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.captures = nil
+	d.start = 0
+	d.count = 0
+}
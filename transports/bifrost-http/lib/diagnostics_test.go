@@ -0,0 +1,89 @@
+package lib
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+func newDiagnosticsRequest(path string, requestBody, responseBody string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(fasthttp.MethodPost)
+	ctx.Request.SetRequestURI(path)
+	ctx.Request.SetBodyString(requestBody)
+	ctx.Response.SetBodyString(responseBody)
+	return ctx
+}
+
+// TestDiagnostics_Thresholds tests that only the requests exceeding the latency or size threshold are captured, with
+// the thresholds they exceeded
+func TestDiagnostics_Thresholds(t *testing.T) {
+	config := &schemas.DiagnosticsConfig{Enabled: true, LatencyThresholdMs: 1000, SizeThresholdBytes: 10}
+	diagnostics := NewDiagnostics()
+
+	diagnostics.Capture(config, newDiagnosticsRequest("/v1/fast", "{}", "{}"), 10*time.Millisecond)
+	diagnostics.Capture(config, newDiagnosticsRequest("/v1/slow", "{}", "{}"), 2*time.Second)
+	diagnostics.Capture(config, newDiagnosticsRequest("/v1/large", `{"input": "a large prompt"}`, "{}"), 2*time.Second)
+
+	captures := diagnostics.List()
+	if len(captures) != 2 {
+		t.Fatalf("expected 2 captures, got %+v", captures)
+	}
+	if captures[0].Path != "/v1/large" || len(captures[0].Reasons) != 2 {
+		t.Errorf("expected the large request first with both reasons, got %+v", captures[0])
+	}
+	if captures[1].Path != "/v1/slow" || len(captures[1].Reasons) != 1 || captures[1].Reasons[0] != DiagnosticReasonLatency {
+		t.Errorf("expected the slow request with the latency reason, got %+v", captures[1])
+	}
+	capture, ok := diagnostics.Get(captures[0].ID)
+	if !ok || capture.RequestBody != `{"input": "a large prompt"}` || capture.ResponseBody != "{}" {
+		t.Errorf("expected the bodies of the large request, got %+v", capture)
+	}
+}
+
+// TestDiagnostics_RingBuffer tests that the oldest captures are dropped beyond the capacity, and that bodies are
+// truncated to the max body size
+func TestDiagnostics_RingBuffer(t *testing.T) {
+	config := &schemas.DiagnosticsConfig{Enabled: true, LatencyThresholdMs: 1, Capacity: 3, MaxBodyBytes: 4}
+	diagnostics := NewDiagnostics()
+	for i := 0; i < 5; i++ {
+		diagnostics.Capture(config, newDiagnosticsRequest(fmt.Sprintf("/v1/%d", i), "abcdef", ""), time.Second)
+	}
+
+	captures := diagnostics.List()
+	if len(captures) != 3 || captures[0].Path != "/v1/4" || captures[2].Path != "/v1/2" {
+		t.Fatalf("expected the 3 latest captures, latest first, got %+v", captures)
+	}
+	capture, _ := diagnostics.Get(captures[0].ID)
+	if capture.RequestBody != "abcd" || !capture.RequestBodyTruncated {
+		t.Errorf("expected the request body to be truncated, got %q", capture.RequestBody)
+	}
+
+	// Shrinking the capacity keeps the latest captures
+	config.Capacity = 1
+	diagnostics.Capture(config, newDiagnosticsRequest("/v1/5", "", ""), time.Second)
+	if captures := diagnostics.List(); len(captures) != 1 || captures[0].Path != "/v1/5" {
+		t.Errorf("expected only the latest capture, got %+v", captures)
+	}
+}
+
+// TestDiagnostics_Sampling tests that the requests exceeding a threshold are captured at the sample rate
+func TestDiagnostics_Sampling(t *testing.T) {
+	config := &schemas.DiagnosticsConfig{Enabled: true, LatencyThresholdMs: 1, SampleRate: 0.5}
+	draws := []float64{0.7, 0.2}
+	diagnostics := NewDiagnostics()
+	diagnostics.sample = func() float64 {
+		draw := draws[0]
+		draws = draws[1:]
+		return draw
+	}
+	diagnostics.Capture(config, newDiagnosticsRequest("/v1/skipped", "", ""), time.Second)
+	diagnostics.Capture(config, newDiagnosticsRequest("/v1/sampled", "", ""), time.Second)
+
+	if captures := diagnostics.List(); len(captures) != 1 || captures[0].Path != "/v1/sampled" {
+		t.Errorf("expected only the sampled request to be captured, got %+v", captures)
+	}
+}
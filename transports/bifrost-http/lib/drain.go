@@ -0,0 +1,145 @@
+package lib
+
+import (
+	"bufio"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultDrainTimeout is how long a drain waits for the in-flight requests and streams when no timeout is given
+const DefaultDrainTimeout = 30 * time.Second
+
+// drainPollInterval is how often Wait checks whether the in-flight requests and streams are done
+const drainPollInterval = 100 * time.Millisecond
+
+// DrainStatus is the progress of a drain
+type DrainStatus struct {
+	Draining         bool       `json:"draining"`
+	StartedAt        *time.Time `json:"started_at,omitempty"`
+	Deadline         *time.Time `json:"deadline,omitempty"`
+	InFlightRequests int64      `json:"in_flight_requests"`
+	InFlightStreams  int64      `json:"in_flight_streams"`
+	Drained          bool       `json:"drained"` // No request or stream is left in flight
+}
+
+// Drain tracks the in-flight inference requests and streams of the server. Once draining, new inference requests are
+// refused so that the instance can be taken out of rotation, while the in-flight ones finish up to the deadline of
+// the drain. A nil Drain admits every request and tracks nothing.
+type Drain struct {
+	requests atomic.Int64
+	streams  atomic.Int64
+
+	mu        sync.RWMutex
+	draining  bool
+	startedAt time.Time
+	deadline  time.Time
+}
+
+// NewDrain creates the drain tracking of a server that is not draining
+func NewDrain() *Drain {
+	return &Drain{}
+}
+
+// Start stops the admission of new requests, and returns the progress of the drain. Draining an already draining
+// server keeps the deadline of the first drain. A timeout of zero or less uses DefaultDrainTimeout.
+func (d *Drain) Start(timeout time.Duration) DrainStatus {
+	if timeout <= 0 {
+		timeout = DefaultDrainTimeout
+	}
+	d.mu.Lock()
+	if !d.draining {
+		d.draining = true
+		d.startedAt = time.Now()
+		d.deadline = d.startedAt.Add(timeout)
+		logger.Info("draining: refusing new inference requests, waiting up to %s for %d requests and %d streams in flight", timeout, d.requests.Load(), d.streams.Load())
+	}
+	d.mu.Unlock()
+	return d.Status()
+}
+
+// Status returns the progress of the drain
+func (d *Drain) Status() DrainStatus {
+	if d == nil {
+		return DrainStatus{Drained: true}
+	}
+	status := DrainStatus{
+		InFlightRequests: d.requests.Load(),
+		InFlightStreams:  d.streams.Load(),
+	}
+	status.Drained = status.InFlightRequests == 0 && status.InFlightStreams == 0
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.draining {
+		startedAt, deadline := d.startedAt, d.deadline
+		status.Draining = true
+		status.StartedAt = &startedAt
+		status.Deadline = &deadline
+	}
+	return status
+}
+
+// IsDraining reports whether new requests are refused
+func (d *Drain) IsDraining() bool {
+	if d == nil {
+		return false
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.draining
+}
+
+// Admit counts a new request in flight, and returns false without counting it when draining. Admitted requests are
+// released with Release once their handler returns.
+func (d *Drain) Admit() bool {
+	if d == nil {
+		return true
+	}
+	// The request is counted before checking the state so that Wait never misses a request admitted concurrently
+	d.requests.Add(1)
+	if d.IsDraining() {
+		d.requests.Add(-1)
+		return false
+	}
+	return true
+}
+
+// Release counts an admitted request as done
+func (d *Drain) Release() {
+	if d == nil {
+		return
+	}
+	d.requests.Add(-1)
+}
+
+// TrackStream counts a stream in flight until its writer returns. It must be called from the handler of the request,
+// before the request is released, so that the drain never sees the request done before its stream.
+func (d *Drain) TrackStream(writer func(w *bufio.Writer)) func(w *bufio.Writer) {
+	if d == nil {
+		return writer
+	}
+	d.streams.Add(1)
+	return func(w *bufio.Writer) {
+		defer d.streams.Add(-1)
+		writer(w)
+	}
+}
+
+// Wait blocks until the in-flight requests and streams are done, the deadline of the drain passes or the context is
+// done, and returns the progress of the drain.
+func (d *Drain) Wait(ctx context.Context) DrainStatus {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for {
+		status := d.Status()
+		if status.Drained || (status.Deadline != nil && time.Now().After(*status.Deadline)) {
+			return status
+		}
+		select {
+		case <-ctx.Done():
+			return status
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package lib
+
+import (
+	"bufio"
+	"context"
+	"testing"
+	"time"
+)
+
+// TestDrain_WaitsForRequestsAndStreams tests that a drain refuses new requests and completes once the in-flight
+// requests and their streams are done
+func TestDrain_WaitsForRequestsAndStreams(t *testing.T) {
+	drain := NewDrain()
+	if !drain.Admit() {
+		t.Fatal("expected requests to be admitted before the drain")
+	}
+	release := make(chan struct{})
+	writer := drain.TrackStream(func(w *bufio.Writer) { <-release })
+	drain.Release()
+
+	status := drain.Start(time.Minute)
+	if !status.Draining || status.InFlightRequests != 0 || status.InFlightStreams != 1 || status.Drained {
+		t.Errorf("expected the stream to keep the drain going, got %+v", status)
+	}
+	if drain.Admit() {
+		t.Error("expected new requests to be refused while draining")
+	}
+
+	go writer(nil)
+	close(release)
+	if status := drain.Wait(context.Background()); !status.Drained {
+		t.Errorf("expected the drain to complete once the stream ended, got %+v", status)
+	}
+}
+
+// TestDrain_Deadline tests that waiting for a drain stops at its deadline
+func TestDrain_Deadline(t *testing.T) {
+	drain := NewDrain()
+	drain.Admit()
+	drain.Start(200 * time.Millisecond)
+	if status := drain.Wait(context.Background()); status.Drained || status.InFlightRequests != 1 {
+		t.Errorf("expected the drain to end at its deadline with the request in flight, got %+v", status)
+	}
+
+	var nilDrain *Drain
+	if !nilDrain.Admit() || nilDrain.IsDraining() || !nilDrain.Status().Drained {
+		t.Error("expected a nil drain to admit every request")
+	}
+}
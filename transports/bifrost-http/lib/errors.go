@@ -3,3 +3,6 @@ package lib
 import "errors"
 
 var ErrNotFound = errors.New("not found")
+
+// ErrIngestionCollectionNotAllowed is returned for collections outside of the collection prefix of the ingestion config
+var ErrIngestionCollectionNotAllowed = errors.New("collection is not allowed")
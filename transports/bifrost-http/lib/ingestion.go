@@ -0,0 +1,522 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/vectorstore"
+)
+
+const (
+	// DefaultIngestionBatchSize is the number of texts embedded per request when none is configured
+	DefaultIngestionBatchSize = 64
+	// DefaultIngestionMaxRetries is the number of times a rate limited batch is retried when none is configured
+	DefaultIngestionMaxRetries = 5
+	// DefaultIngestionCollectionPrefix is the prefix of the collections ingestion jobs may write to when none is
+	// configured, keeping jobs away from the collections of the semantic cache sharing the vector store
+	DefaultIngestionCollectionPrefix = "Ingestion"
+	// maxIngestionJobErrors caps the errors kept on a job, the failed count keeps the full total
+	maxIngestionJobErrors = 20
+	// maxFinishedIngestionJobs is the number of finished jobs kept in memory for progress reporting
+	maxFinishedIngestionJobs = 100
+	// ingestionMaxBackoff caps the delay between two retries of a rate limited batch
+	ingestionMaxBackoff = 30 * time.Second
+	// ingestionCreateNamespaceTimeout bounds the creation of the target collection
+	ingestionCreateNamespaceTimeout = 30 * time.Second
+)
+
+// ingestionCollectionNamePattern matches the collection names after the prefix, valid in every vector store
+var ingestionCollectionNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// ingestionBackoffBase is the delay before the first retry of a rate limited batch, doubled on every retry
+var ingestionBackoffBase = time.Second
+
+// Ingestion job statuses
+const (
+	IngestionJobPending   = "pending"
+	IngestionJobRunning   = "running"
+	IngestionJobCompleted = "completed"
+	IngestionJobFailed    = "failed"
+	IngestionJobCancelled = "cancelled"
+)
+
+// IngestionConfig configures batch embedding ingestion.
+// Ingestion jobs embed texts through the gateway with a configured embedding alias and write the
+// vectors to a vector store collection, so no separate ETL scripts are needed around the gateway.
+type IngestionConfig struct {
+	Enabled          bool                            `json:"enabled"`
+	EmbeddingAliases map[string]EmbeddingAliasConfig `json:"embedding_aliases"`
+	CollectionPrefix string                          `json:"collection_prefix,omitempty"` // Prefix of the collections jobs may write to, defaults to DefaultIngestionCollectionPrefix
+}
+
+// EmbeddingAliasConfig defines an embedding model ingestion jobs can refer to by name
+type EmbeddingAliasConfig struct {
+	Model             string `json:"model"`                         // Embedding model in provider/model format
+	Dimension         int    `json:"dimension"`                     // Dimension of the embeddings, also used to create collections
+	BatchSize         int    `json:"batch_size,omitempty"`          // Texts embedded per request, defaults to DefaultIngestionBatchSize
+	RequestsPerMinute int    `json:"requests_per_minute,omitempty"` // Embedding requests per minute shared by all jobs of the alias, 0 is unlimited
+	MaxRetries        int    `json:"max_retries,omitempty"`         // Retries of a rate limited batch, defaults to DefaultIngestionMaxRetries
+	VirtualKey        string `json:"virtual_key,omitempty"`         // Virtual key embedding requests are sent with, so governance limits apply
+}
+
+// Validate checks the ingestion config for missing or invalid fields
+func (c *IngestionConfig) Validate() error {
+	if c.CollectionPrefix != "" && !ingestionCollectionNamePattern.MatchString(c.CollectionPrefix) {
+		return fmt.Errorf("collection_prefix may only contain letters, digits and underscores")
+	}
+	for name, alias := range c.EmbeddingAliases {
+		if name == "" {
+			return fmt.Errorf("embedding alias names cannot be empty")
+		}
+		if provider, model := schemas.ParseModelString(alias.Model, ""); provider == "" || model == "" {
+			return fmt.Errorf("embedding alias %s requires a model in provider/model format", name)
+		}
+		if alias.Dimension <= 0 {
+			return fmt.Errorf("embedding alias %s requires a positive dimension", name)
+		}
+		if alias.BatchSize < 0 || alias.RequestsPerMinute < 0 || alias.MaxRetries < 0 {
+			return fmt.Errorf("embedding alias %s batch_size, requests_per_minute and max_retries cannot be negative", name)
+		}
+	}
+	return nil
+}
+
+// IngestionRecord is a single text to embed and store
+type IngestionRecord struct {
+	ID       string         `json:"id,omitempty"`       // Vector ID, a UUID is derived from it if it is not one already
+	Text     string         `json:"text"`               // Text to embed, stored in the "text" metadata field
+	Metadata map[string]any `json:"metadata,omitempty"` // Extra metadata stored with the vector
+}
+
+// IngestionJob is the progress of an ingestion job
+type IngestionJob struct {
+	ID          string     `json:"id"`
+	Alias       string     `json:"alias"`
+	Collection  string     `json:"collection"`
+	Status      string     `json:"status"` // One of the IngestionJob* constants
+	Total       int        `json:"total"`
+	Processed   int        `json:"processed"` // Records embedded and stored
+	Failed      int        `json:"failed"`
+	Errors      []string   `json:"errors,omitempty"` // First errors encountered, capped at 20
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// EmbeddingClient is the subset of the Bifrost client used to embed ingested texts
+type EmbeddingClient interface {
+	EmbeddingRequest(ctx context.Context, req *schemas.BifrostEmbeddingRequest) (*schemas.BifrostEmbeddingResponse, *schemas.BifrostError)
+}
+
+// embeddingAlias is a validated embedding alias with its parsed settings
+type embeddingAlias struct {
+	config   EmbeddingAliasConfig
+	provider schemas.ModelProvider
+	model    string
+	pacer    *embeddingPacer
+}
+
+// embeddingPacer spaces out the embedding requests of an alias across all of its jobs,
+// and holds them back while the provider is rate limiting
+type embeddingPacer struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// wait blocks until the next request slot of the alias, or until ctx is done
+func (p *embeddingPacer) wait(ctx context.Context) error {
+	p.mu.Lock()
+	now := time.Now()
+	at := p.next
+	if at.Before(now) {
+		at = now
+	}
+	p.next = at.Add(p.interval)
+	p.mu.Unlock()
+
+	if delay := time.Until(at); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return ctx.Err()
+}
+
+// backoff holds back all requests of the alias for delay
+func (p *embeddingPacer) backoff(delay time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if until := time.Now().Add(delay); p.next.Before(until) {
+		p.next = until
+	}
+}
+
+// ingestionJob is a job with its records and cancellation
+type ingestionJob struct {
+	job     IngestionJob
+	alias   *embeddingAlias
+	records []IngestionRecord
+	cancel  context.CancelFunc
+}
+
+// IngestionManager runs ingestion jobs in the background and tracks their progress
+type IngestionManager struct {
+	client  EmbeddingClient
+	store   vectorstore.VectorStore
+	aliases map[string]*embeddingAlias
+	prefix  string // Prefix of the collections jobs may write to
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	mu       sync.RWMutex
+	jobs     map[string]*ingestionJob
+	finished []string // IDs of finished jobs, oldest first
+	wg       sync.WaitGroup
+}
+
+// NewIngestionManager creates an ingestion manager writing to the given vector store
+func NewIngestionManager(config *IngestionConfig, client EmbeddingClient, store vectorstore.VectorStore) (*IngestionManager, error) {
+	if config == nil {
+		return nil, fmt.Errorf("ingestion config is required")
+	}
+	if client == nil {
+		return nil, fmt.Errorf("bifrost client is required")
+	}
+	if store == nil {
+		return nil, fmt.Errorf("ingestion requires a vector store")
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	manager := &IngestionManager{
+		client:  client,
+		store:   store,
+		aliases: make(map[string]*embeddingAlias, len(config.EmbeddingAliases)),
+		prefix:  config.CollectionPrefix,
+		ctx:     ctx,
+		cancel:  cancel,
+		jobs:    make(map[string]*ingestionJob),
+	}
+	if manager.prefix == "" {
+		manager.prefix = DefaultIngestionCollectionPrefix
+	}
+	for name, aliasConfig := range config.EmbeddingAliases {
+		if aliasConfig.BatchSize == 0 {
+			aliasConfig.BatchSize = DefaultIngestionBatchSize
+		}
+		if aliasConfig.MaxRetries == 0 {
+			aliasConfig.MaxRetries = DefaultIngestionMaxRetries
+		}
+		alias := &embeddingAlias{
+			config: aliasConfig,
+			pacer:  &embeddingPacer{},
+		}
+		alias.provider, alias.model = schemas.ParseModelString(aliasConfig.Model, "")
+		if aliasConfig.RequestsPerMinute > 0 {
+			alias.pacer.interval = time.Minute / time.Duration(aliasConfig.RequestsPerMinute)
+		}
+		manager.aliases[name] = alias
+	}
+	return manager, nil
+}
+
+// CheckCollection returns ErrIngestionCollectionNotAllowed unless the collection is named after the collection prefix
+func (m *IngestionManager) CheckCollection(collection string) error {
+	if collection == "" {
+		return fmt.Errorf("collection is required")
+	}
+	name, ok := strings.CutPrefix(collection, m.prefix)
+	if !ok || !ingestionCollectionNamePattern.MatchString(name) {
+		return fmt.Errorf("%w: collections must be named %s followed by letters, digits or underscores", ErrIngestionCollectionNotAllowed, m.prefix)
+	}
+	return nil
+}
+
+// StartJob validates the records and starts embedding them into the collection in the background
+func (m *IngestionManager) StartJob(aliasName string, collection string, records []IngestionRecord) (*IngestionJob, error) {
+	alias, ok := m.aliases[aliasName]
+	if !ok {
+		return nil, fmt.Errorf("unknown embedding alias: %s", aliasName)
+	}
+	if err := m.CheckCollection(collection); err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("at least one record is required")
+	}
+	for i, record := range records {
+		if record.Text == "" {
+			return nil, fmt.Errorf("record %d has no text", i)
+		}
+	}
+	if err := m.ctx.Err(); err != nil {
+		return nil, fmt.Errorf("ingestion manager is stopped")
+	}
+
+	jobCtx, cancel := context.WithCancel(m.ctx)
+	job := &ingestionJob{
+		job: IngestionJob{
+			ID:         uuid.NewString(),
+			Alias:      aliasName,
+			Collection: collection,
+			Status:     IngestionJobPending,
+			Total:      len(records),
+			CreatedAt:  time.Now(),
+		},
+		alias:   alias,
+		records: records,
+		cancel:  cancel,
+	}
+	m.mu.Lock()
+	m.jobs[job.job.ID] = job
+	snapshot := job.snapshot()
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer cancel()
+		m.runJob(jobCtx, job)
+	}()
+	return &snapshot, nil
+}
+
+// GetJob returns the progress of a job
+func (m *IngestionManager) GetJob(id string) (*IngestionJob, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	snapshot := job.snapshot()
+	return &snapshot, nil
+}
+
+// ListJobs returns the progress of all jobs, most recent first
+func (m *IngestionManager) ListJobs() []IngestionJob {
+	m.mu.RLock()
+	jobs := make([]IngestionJob, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, job.snapshot())
+	}
+	m.mu.RUnlock()
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.After(jobs[j].CreatedAt) })
+	return jobs
+}
+
+// CancelJob cancels a pending or running job, records already stored are kept
+func (m *IngestionManager) CancelJob(id string) (*IngestionJob, error) {
+	m.mu.RLock()
+	job, ok := m.jobs[id]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	job.cancel()
+	return m.GetJob(id)
+}
+
+// Stop cancels all jobs and waits for them to finish
+func (m *IngestionManager) Stop() {
+	m.cancel()
+	m.wg.Wait()
+}
+
+// snapshot returns a copy of the job progress, the caller must hold the manager lock
+func (j *ingestionJob) snapshot() IngestionJob {
+	snapshot := j.job
+	snapshot.Errors = append([]string(nil), j.job.Errors...)
+	return snapshot
+}
+
+// runJob creates the collection if needed, then embeds and stores the records batch by batch
+func (m *IngestionManager) runJob(ctx context.Context, job *ingestionJob) {
+	startedAt := time.Now()
+	m.mu.Lock()
+	job.job.Status = IngestionJobRunning
+	job.job.StartedAt = &startedAt
+	m.mu.Unlock()
+
+	createCtx, cancel := context.WithTimeout(ctx, ingestionCreateNamespaceTimeout)
+	err := m.store.CreateNamespace(createCtx, job.job.Collection, job.alias.config.Dimension, map[string]vectorstore.VectorStoreProperties{
+		"text":      {DataType: vectorstore.VectorStorePropertyTypeString, Description: "Embedded text"},
+		"source_id": {DataType: vectorstore.VectorStorePropertyTypeString, Description: "Record ID the vector ID was derived from"},
+	})
+	cancel()
+	if err != nil {
+		m.recordFailure(job, len(job.records), fmt.Sprintf("failed to create collection: %v", err))
+		m.finishJob(job, IngestionJobFailed)
+		return
+	}
+
+	batchSize := job.alias.config.BatchSize
+	for start := 0; start < len(job.records); start += batchSize {
+		if ctx.Err() != nil {
+			break
+		}
+		batch := job.records[start:min(start+batchSize, len(job.records))]
+		m.processBatch(ctx, job, batch)
+	}
+
+	status := IngestionJobCompleted
+	if ctx.Err() != nil {
+		status = IngestionJobCancelled
+	}
+	m.finishJob(job, status)
+}
+
+// processBatch embeds a batch of records, retrying while rate limited, and stores the vectors
+func (m *IngestionManager) processBatch(ctx context.Context, job *ingestionJob, batch []IngestionRecord) {
+	embeddings, err := m.embedBatch(ctx, job.alias, batch)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		m.recordFailure(job, len(batch), err.Error())
+		return
+	}
+
+	for i, record := range batch {
+		if ctx.Err() != nil {
+			return
+		}
+		if len(embeddings[i]) != job.alias.config.Dimension {
+			m.recordFailure(job, 1, fmt.Sprintf("record %s: expected %d dimensions, got %d", record.ID, job.alias.config.Dimension, len(embeddings[i])))
+			continue
+		}
+		id, metadata := ingestionVector(record)
+		if err := m.store.Add(ctx, job.job.Collection, id, embeddings[i], metadata); err != nil {
+			m.recordFailure(job, 1, fmt.Sprintf("record %s: failed to store vector: %v", record.ID, err))
+			continue
+		}
+		m.mu.Lock()
+		job.job.Processed++
+		m.mu.Unlock()
+	}
+}
+
+// embedBatch embeds the texts of a batch, backing off the whole alias while the provider returns 429
+func (m *IngestionManager) embedBatch(ctx context.Context, alias *embeddingAlias, batch []IngestionRecord) ([][]float32, error) {
+	texts := make([]string, len(batch))
+	for i, record := range batch {
+		texts[i] = record.Text
+	}
+	requestCtx := ctx
+	if alias.config.VirtualKey != "" {
+		requestCtx = context.WithValue(ctx, schemas.BifrostContextKeyVirtualKey, alias.config.VirtualKey)
+	}
+	request := &schemas.BifrostEmbeddingRequest{
+		Provider: alias.provider,
+		Model:    alias.model,
+		Input:    &schemas.EmbeddingInput{Texts: texts},
+		Params:   &schemas.EmbeddingParameters{Dimensions: bifrost.Ptr(alias.config.Dimension)},
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := alias.pacer.wait(ctx); err != nil {
+			return nil, err
+		}
+		resp, bifrostErr := m.client.EmbeddingRequest(requestCtx, request)
+		if bifrostErr == nil {
+			return embeddingsFromResponse(resp, len(batch))
+		}
+		rateLimited := bifrostErr.StatusCode != nil && *bifrostErr.StatusCode == 429
+		if !rateLimited || attempt >= alias.config.MaxRetries {
+			return nil, fmt.Errorf("failed to embed batch: %s", bifrost.GetErrorMessage(bifrostErr))
+		}
+		delay := min(ingestionBackoffBase<<attempt, ingestionMaxBackoff)
+		logger.Debug("embedding alias %s is rate limited, retrying in %s", alias.config.Model, delay)
+		alias.pacer.backoff(delay)
+	}
+}
+
+// embeddingsFromResponse returns the embeddings of a response ordered by input index
+func embeddingsFromResponse(resp *schemas.BifrostEmbeddingResponse, count int) ([][]float32, error) {
+	if resp == nil || len(resp.Data) != count {
+		return nil, fmt.Errorf("expected %d embeddings from provider", count)
+	}
+	embeddings := make([][]float32, count)
+	for _, data := range resp.Data {
+		if data.Index < 0 || data.Index >= count {
+			return nil, fmt.Errorf("embedding index %d out of range", data.Index)
+		}
+		switch {
+		case data.Embedding.EmbeddingStr != nil:
+			var values []float32
+			if err := json.Unmarshal([]byte(*data.Embedding.EmbeddingStr), &values); err != nil {
+				return nil, fmt.Errorf("failed to parse string embedding: %w", err)
+			}
+			embeddings[data.Index] = values
+		case data.Embedding.EmbeddingArray != nil:
+			embeddings[data.Index] = data.Embedding.EmbeddingArray
+		default:
+			for _, values := range data.Embedding.Embedding2DArray {
+				embeddings[data.Index] = append(embeddings[data.Index], values...)
+			}
+		}
+	}
+	return embeddings, nil
+}
+
+// ingestionVector returns the vector ID and metadata of a record.
+// Vector stores such as Qdrant only accept UUIDs, so other IDs are mapped to a stable UUID
+// and kept in the "source_id" field. Records without an ID get a random one.
+func ingestionVector(record IngestionRecord) (string, map[string]interface{}) {
+	metadata := make(map[string]interface{}, len(record.Metadata)+2)
+	for key, value := range record.Metadata {
+		metadata[key] = value
+	}
+	metadata["text"] = record.Text
+	if record.ID == "" {
+		return uuid.NewString(), metadata
+	}
+	if _, err := uuid.Parse(record.ID); err == nil {
+		return record.ID, metadata
+	}
+	metadata["source_id"] = record.ID
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(record.ID)).String(), metadata
+}
+
+// recordFailure adds failed records and their error to a job
+func (m *IngestionManager) recordFailure(job *ingestionJob, count int, message string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job.job.Failed += count
+	if len(job.job.Errors) < maxIngestionJobErrors {
+		job.job.Errors = append(job.job.Errors, message)
+	}
+}
+
+// finishJob sets the final status of a job and evicts the oldest finished jobs
+func (m *IngestionManager) finishJob(job *ingestionJob, status string) {
+	completedAt := time.Now()
+	m.mu.Lock()
+	job.job.Status = status
+	job.job.CompletedAt = &completedAt
+	job.records = nil
+	m.finished = append(m.finished, job.job.ID)
+	for len(m.finished) > maxFinishedIngestionJobs {
+		delete(m.jobs, m.finished[0])
+		m.finished = m.finished[1:]
+	}
+	snapshot := job.snapshot()
+	m.mu.Unlock()
+
+	logger.Info("ingestion job %s into %s %s: %d of %d records stored, %d failed", snapshot.ID, snapshot.Collection, status, snapshot.Processed, snapshot.Total, snapshot.Failed)
+}
@@ -0,0 +1,178 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/vectorstore"
+)
+
+// fakeEmbeddingClient returns constant embeddings, answering the first rateLimited requests with a 429
+type fakeEmbeddingClient struct {
+	mu          sync.Mutex
+	dimension   int
+	rateLimited int
+	batches     [][]string
+	vks         []any
+}
+
+func (c *fakeEmbeddingClient) EmbeddingRequest(ctx context.Context, req *schemas.BifrostEmbeddingRequest) (*schemas.BifrostEmbeddingResponse, *schemas.BifrostError) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.vks = append(c.vks, ctx.Value(schemas.BifrostContextKeyVirtualKey))
+	if c.rateLimited > 0 {
+		c.rateLimited--
+		return nil, &schemas.BifrostError{StatusCode: bifrost.Ptr(429), Error: &schemas.ErrorField{Message: "rate limit exceeded"}}
+	}
+	c.batches = append(c.batches, req.Input.Texts)
+	resp := &schemas.BifrostEmbeddingResponse{}
+	for i := range req.Input.Texts {
+		resp.Data = append(resp.Data, schemas.EmbeddingData{
+			Index:     i,
+			Embedding: schemas.EmbeddingStruct{EmbeddingArray: make([]float32, c.dimension)},
+		})
+	}
+	return resp, nil
+}
+
+// fakeVectorStore records the namespaces and vectors written by ingestion jobs
+type fakeVectorStore struct {
+	vectorstore.VectorStore
+	mu         sync.Mutex
+	namespaces map[string]int
+	vectors    map[string]map[string]interface{}
+}
+
+func (s *fakeVectorStore) CreateNamespace(ctx context.Context, namespace string, dimension int, properties map[string]vectorstore.VectorStoreProperties) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.namespaces[namespace] = dimension
+	return nil
+}
+
+func (s *fakeVectorStore) Add(ctx context.Context, namespace string, id string, embedding []float32, metadata map[string]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vectors[id] = metadata
+	return nil
+}
+
+func newTestIngestionManager(t *testing.T, client EmbeddingClient, store vectorstore.VectorStore) *IngestionManager {
+	t.Helper()
+	return newTestJobManager(t, func() (*IngestionManager, error) {
+		return NewIngestionManager(&IngestionConfig{
+			Enabled: true,
+			EmbeddingAliases: map[string]EmbeddingAliasConfig{
+				"docs": {Model: "openai/text-embedding-3-small", Dimension: 4, BatchSize: 2, MaxRetries: 2, VirtualKey: "sk-bf-ingest"},
+			},
+		}, client, store)
+	})
+}
+
+// waitForIngestionJob waits until the job leaves the pending and running statuses
+func waitForIngestionJob(t *testing.T, manager *IngestionManager, id string) *IngestionJob {
+	t.Helper()
+	return waitForJob(t, id, manager.GetJob, func(job *IngestionJob) bool {
+		return job.Status != IngestionJobPending && job.Status != IngestionJobRunning
+	})
+}
+
+// TestIngestionManager_BatchesAndStoresRecords tests that records are embedded in batches and written to the collection
+func TestIngestionManager_BatchesAndStoresRecords(t *testing.T) {
+	client := &fakeEmbeddingClient{dimension: 4}
+	store := &fakeVectorStore{namespaces: map[string]int{}, vectors: map[string]map[string]interface{}{}}
+	manager := newTestIngestionManager(t, client, store)
+
+	records := []IngestionRecord{
+		{ID: "doc-1", Text: "first", Metadata: map[string]any{"source": "wiki"}},
+		{ID: "7f3c2a9e-1b4d-4c8e-9f2a-3d5e6b7c8a90", Text: "second"},
+		{Text: "third"},
+	}
+	started, err := manager.StartJob("docs", "IngestionKnowledgeBase", records)
+	if err != nil {
+		t.Fatalf("failed to start job: %v", err)
+	}
+	job := waitForIngestionJob(t, manager, started.ID)
+
+	if job.Status != IngestionJobCompleted || job.Processed != 3 || job.Failed != 0 {
+		t.Fatalf("expected 3 records stored, got status %s processed %d failed %d: %v", job.Status, job.Processed, job.Failed, job.Errors)
+	}
+	if len(client.batches) != 2 || len(client.batches[0]) != 2 || len(client.batches[1]) != 1 {
+		t.Fatalf("expected batches of 2 and 1 texts, got %v", client.batches)
+	}
+	if client.vks[0] != "sk-bf-ingest" {
+		t.Errorf("expected requests to carry the alias virtual key, got %v", client.vks[0])
+	}
+	if store.namespaces["IngestionKnowledgeBase"] != 4 {
+		t.Errorf("expected collection to be created with dimension 4, got %v", store.namespaces)
+	}
+	if _, ok := store.vectors["7f3c2a9e-1b4d-4c8e-9f2a-3d5e6b7c8a90"]; !ok {
+		t.Errorf("expected UUID record IDs to be used as is")
+	}
+	found := false
+	for _, metadata := range store.vectors {
+		if metadata["source_id"] == "doc-1" {
+			found = metadata["text"] == "first" && metadata["source"] == "wiki"
+		}
+	}
+	if !found {
+		t.Errorf("expected non UUID record ID to be kept in source_id with the text and metadata, got %v", store.vectors)
+	}
+}
+
+// TestIngestionManager_RetriesRateLimitedBatches tests that rate limited batches are retried and fail once retries are exhausted
+func TestIngestionManager_RetriesRateLimitedBatches(t *testing.T) {
+	backoffBase := ingestionBackoffBase
+	ingestionBackoffBase = time.Millisecond
+	defer func() { ingestionBackoffBase = backoffBase }()
+
+	client := &fakeEmbeddingClient{dimension: 4, rateLimited: 2}
+	store := &fakeVectorStore{namespaces: map[string]int{}, vectors: map[string]map[string]interface{}{}}
+	manager := newTestIngestionManager(t, client, store)
+
+	started, err := manager.StartJob("docs", "IngestionKb", []IngestionRecord{{Text: "a"}, {Text: "b"}})
+	if err != nil {
+		t.Fatalf("failed to start job: %v", err)
+	}
+	if job := waitForIngestionJob(t, manager, started.ID); job.Processed != 2 || job.Failed != 0 {
+		t.Fatalf("expected batch to succeed after 2 rate limited attempts, got processed %d failed %d: %v", job.Processed, job.Failed, job.Errors)
+	}
+
+	client.rateLimited = 3
+	started, err = manager.StartJob("docs", "IngestionKb", []IngestionRecord{{Text: "a"}, {Text: "b"}})
+	if err != nil {
+		t.Fatalf("failed to start job: %v", err)
+	}
+	if job := waitForIngestionJob(t, manager, started.ID); job.Processed != 0 || job.Failed != 2 || len(job.Errors) != 1 {
+		t.Fatalf("expected batch to fail once retries are exhausted, got processed %d failed %d: %v", job.Processed, job.Failed, job.Errors)
+	}
+}
+
+// TestIngestionManager_StartJobValidation tests that invalid jobs are rejected before they start
+func TestIngestionManager_StartJobValidation(t *testing.T) {
+	store := &fakeVectorStore{namespaces: map[string]int{}, vectors: map[string]map[string]interface{}{}}
+	manager := newTestIngestionManager(t, &fakeEmbeddingClient{dimension: 4}, store)
+
+	if _, err := manager.StartJob("unknown", "IngestionKb", []IngestionRecord{{Text: "a"}}); err == nil {
+		t.Error("expected unknown alias to be rejected")
+	}
+	if _, err := manager.StartJob("docs", "", []IngestionRecord{{Text: "a"}}); err == nil {
+		t.Error("expected missing collection to be rejected")
+	}
+	for _, collection := range []string{"kb", "BifrostSemanticCachePlugin", "Ingestion", "Ingestion-kb", "xIngestionKb"} {
+		if _, err := manager.StartJob("docs", collection, []IngestionRecord{{Text: "a"}}); !errors.Is(err, ErrIngestionCollectionNotAllowed) {
+			t.Errorf("expected collection %s outside of the prefix to be rejected, got %v", collection, err)
+		}
+	}
+	if _, err := manager.StartJob("docs", "IngestionKb", []IngestionRecord{{ID: "empty"}}); err == nil {
+		t.Error("expected records without text to be rejected")
+	}
+	if _, err := manager.GetJob("missing"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound for unknown job, got %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package lib
+
+import (
+	"testing"
+	"time"
+)
+
+// newTestJobManager creates a background job manager with create, stopped when the test ends
+func newTestJobManager[M interface{ Stop() }](t *testing.T, create func() (M, error)) M {
+	t.Helper()
+	manager, err := create()
+	if err != nil {
+		t.Fatalf("failed to create job manager: %v", err)
+	}
+	t.Cleanup(manager.Stop)
+	return manager
+}
+
+// waitForJob polls the job of id with get until it is finished
+func waitForJob[J any](t *testing.T, id string, get func(id string) (*J, error), finished func(job *J) bool) *J {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := get(id)
+		if err != nil {
+			t.Fatalf("failed to get job %s: %v", id, err)
+		}
+		if finished(job) {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", id)
+	return nil
+}
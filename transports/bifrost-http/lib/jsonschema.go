@@ -0,0 +1,340 @@
+package lib
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// JSONSchemaDraft is the JSON Schema dialect of the generated schemas
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is a JSON Schema document
+type JSONSchema map[string]any
+
+// SchemaFieldError is a field of a payload that does not match its JSON Schema
+type SchemaFieldError struct {
+	Path    string `json:"path"` // Path of the field, e.g. keys[0].weight, empty for the payload itself
+	Message string `json:"message"`
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// structuredUnmarshalers are the types with a custom JSON decoding whose JSON shape still matches their fields, the
+// other types with a custom decoding accept any value
+var structuredUnmarshalers = map[reflect.Type]bool{
+	reflect.TypeOf(schemas.NetworkConfig{}): true,
+}
+
+// GenerateJSONSchema generates the JSON Schema of the JSON encoding of a Go type, the named structs it uses being
+// defined under $defs. Properties come from the json tags of the fields, fields tagged validate:"required" are
+// required, and unknown properties are allowed like when decoding the payloads.
+func GenerateJSONSchema(t reflect.Type) JSONSchema {
+	g := &schemaGenerator{
+		names: make(map[reflect.Type]string),
+		defs:  make(map[string]any),
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	schema := JSONSchema{"$schema": JSONSchemaDraft}
+	var root map[string]any
+	if t.Kind() == reflect.Struct && !g.customDecoding(t) {
+		root = g.structSchema(t)
+	} else {
+		root = g.schema(t)
+	}
+	for key, value := range root {
+		schema[key] = value
+	}
+	if len(g.defs) > 0 {
+		schema["$defs"] = g.defs
+	}
+	return schema
+}
+
+// schemaGenerator generates the schema of a type and the definitions of the named structs it uses
+type schemaGenerator struct {
+	names map[reflect.Type]string
+	defs  map[string]any
+}
+
+// customDecoding reports whether the JSON decoding of a type is custom and its shape unknown
+func (g *schemaGenerator) customDecoding(t reflect.Type) bool {
+	if structuredUnmarshalers[t] {
+		return false
+	}
+	pointer := reflect.PointerTo(t)
+	return pointer.Implements(jsonUnmarshalerType) || pointer.Implements(textUnmarshalerType)
+}
+
+// schema returns the schema of a type
+func (g *schemaGenerator) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	if g.customDecoding(t) {
+		return map[string]any{}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return map[string]any{"type": "object"}
+		}
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/$defs/" + g.define(t)}
+	default:
+		return map[string]any{}
+	}
+}
+
+// define adds the definition of a named struct, and returns its name under $defs. Structs of different packages
+// sharing a name are told apart by their package.
+func (g *schemaGenerator) define(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := g.defs[name]; taken {
+		name = path.Base(t.PkgPath()) + "." + name
+	}
+	g.names[t] = name
+	// Registered before generating the fields so that recursive structs refer to themselves
+	g.defs[name] = map[string]any{}
+	g.defs[name] = g.structSchema(t)
+	return name
+}
+
+// structSchema returns the object schema of the fields of a struct
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+	g.addFields(t, properties, &required)
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// addFields adds the properties of the fields of a struct, flattening its embedded structs like the JSON encoding
+func (g *schemaGenerator) addFields(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(embedded, properties, required)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if strings.Contains(","+options+",", ",string,") {
+			properties[name] = map[string]any{"type": "string"}
+		} else {
+			properties[name] = g.schema(field.Type)
+		}
+		for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+			if rule == "required" {
+				*required = append(*required, name)
+			}
+		}
+	}
+}
+
+// ValidateJSONSchema validates a JSON payload against a schema generated by GenerateJSONSchema, and returns the
+// fields that do not match it sorted by path. Null is accepted for every field, like when decoding the payloads. Returns an error
+// when the payload is not valid JSON.
+func ValidateJSONSchema(schema JSONSchema, payload []byte) ([]SchemaFieldError, error) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	defs, _ := schema["$defs"].(map[string]any)
+	validator := &schemaValidator{defs: defs}
+	validator.validate(schema, value, "")
+	sort.SliceStable(validator.errors, func(i, j int) bool {
+		return validator.errors[i].Path < validator.errors[j].Path
+	})
+	return validator.errors, nil
+}
+
+// schemaValidator collects the fields of a payload that do not match a schema
+type schemaValidator struct {
+	defs   map[string]any
+	errors []SchemaFieldError
+}
+
+// fail records a field that does not match the schema
+func (v *schemaValidator) fail(path string, format string, args ...any) {
+	v.errors = append(v.errors, SchemaFieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// validate validates a value against a schema
+func (v *schemaValidator) validate(schema map[string]any, value any, path string) {
+	if value == nil {
+		return
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		def, ok := v.defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+		if !ok {
+			return
+		}
+		v.validate(def, value, path)
+		return
+	}
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			v.fail(path, "expected an object, got %s", jsonTypeName(value))
+			return
+		}
+		required, _ := schema["required"].([]string)
+		for _, name := range required {
+			if _, ok := object[name]; !ok {
+				v.fail(joinFieldPath(path, name), "is required")
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		additional, _ := schema["additionalProperties"].(map[string]any)
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := properties[name].(map[string]any); ok {
+				v.validate(property, object[name], joinFieldPath(path, name))
+			} else if additional != nil {
+				v.validate(additional, object[name], joinFieldPath(path, name))
+			}
+		}
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			v.fail(path, "expected an array, got %s", jsonTypeName(value))
+			return
+		}
+		items, _ := schema["items"].(map[string]any)
+		if items == nil {
+			return
+		}
+		for i, item := range array {
+			v.validate(items, item, path+"["+strconv.Itoa(i)+"]")
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			v.fail(path, "expected a string, got %s", jsonTypeName(value))
+			return
+		}
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+				v.fail(path, "expected an RFC 3339 date-time, got %q", str)
+			}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.fail(path, "expected a boolean, got %s", jsonTypeName(value))
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			v.fail(path, "expected a number, got %s", jsonTypeName(value))
+		}
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			v.fail(path, "expected an integer, got %s", jsonTypeName(value))
+			return
+		}
+		integer, err := strconv.ParseInt(number.String(), 10, 64)
+		if err != nil {
+			// Unsigned integers above the int64 range are still integers
+			if _, err := strconv.ParseUint(number.String(), 10, 64); err != nil {
+				v.fail(path, "expected an integer, got %s", number)
+			}
+			return
+		}
+		if minimum, ok := schema["minimum"].(int); ok && integer < int64(minimum) {
+			v.fail(path, "must be at least %d, got %d", minimum, integer)
+		}
+	}
+}
+
+// joinFieldPath returns the path of a property of the field at path
+func joinFieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// jsonTypeName returns the JSON type of a decoded value
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "an object"
+	case []any:
+		return "an array"
+	case string:
+		return "a string"
+	case json.Number:
+		return "a number"
+	case bool:
+		return "a boolean"
+	default:
+		return "null"
+	}
+}
@@ -0,0 +1,106 @@
+package lib
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type schemaTestKey struct {
+	Value  string   `json:"value" validate:"required"`
+	Weight float64  `json:"weight"`
+	Models []string `json:"models,omitempty"`
+}
+
+type schemaTestPayload struct {
+	Name      string            `json:"name" validate:"required"`
+	Keys      []schemaTestKey   `json:"keys"`
+	Limit     *int              `json:"limit,omitempty"`
+	Size      uint              `json:"size,omitempty"`
+	ExpiresAt *time.Time        `json:"expires_at,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	Internal  string            `json:"-"`
+}
+
+// TestGenerateJSONSchema tests that the schema of a struct lists its JSON properties, its required fields and the
+// named structs it uses under $defs
+func TestGenerateJSONSchema(t *testing.T) {
+	schema := GenerateJSONSchema(reflect.TypeOf(&schemaTestPayload{}))
+	if schema["$schema"] != JSONSchemaDraft || schema["type"] != "object" {
+		t.Fatalf("expected an object schema of draft %s, got %v", JSONSchemaDraft, schema)
+	}
+	properties := schema["properties"].(map[string]any)
+	for _, name := range []string{"name", "keys", "limit", "size", "expires_at", "metadata"} {
+		if _, ok := properties[name]; !ok {
+			t.Errorf("expected property %s", name)
+		}
+	}
+	if _, ok := properties["Internal"]; ok {
+		t.Error("expected fields tagged json:\"-\" to be skipped")
+	}
+	if !reflect.DeepEqual(schema["required"], []string{"name"}) {
+		t.Errorf("expected name to be required, got %v", schema["required"])
+	}
+	keys := properties["keys"].(map[string]any)
+	if !reflect.DeepEqual(keys["items"], map[string]any{"$ref": "#/$defs/schemaTestKey"}) {
+		t.Errorf("expected the keys to refer to the schemaTestKey definition, got %v", keys["items"])
+	}
+	defs := schema["$defs"].(map[string]any)
+	if _, ok := defs["schemaTestKey"]; !ok {
+		t.Errorf("expected the schemaTestKey definition, got %v", defs)
+	}
+}
+
+// TestValidateJSONSchema tests that the fields of a payload not matching its schema are returned with their paths
+func TestValidateJSONSchema(t *testing.T) {
+	schema := GenerateJSONSchema(reflect.TypeOf(schemaTestPayload{}))
+
+	tests := []struct {
+		name    string
+		payload string
+		want    []SchemaFieldError
+	}{
+		{
+			name:    "valid payload",
+			payload: `{"name": "prod", "keys": [{"value": "sk-1", "weight": 0.5}], "limit": 10, "expires_at": "2026-01-02T15:04:05Z", "unknown": true}`,
+		},
+		{
+			name:    "null fields",
+			payload: `{"name": "prod", "keys": null, "limit": null}`,
+		},
+		{
+			name:    "field errors",
+			payload: `{"keys": [{"value": "sk-1", "weight": "high"}, {"weight": 1}], "limit": 1.5, "size": -1, "expires_at": "tomorrow", "metadata": {"team": 1}}`,
+			want: []SchemaFieldError{
+				{Path: "expires_at", Message: `expected an RFC 3339 date-time, got "tomorrow"`},
+				{Path: "keys[0].weight", Message: "expected a number, got a string"},
+				{Path: "keys[1].value", Message: "is required"},
+				{Path: "limit", Message: "expected an integer, got 1.5"},
+				{Path: "metadata.team", Message: "expected a string, got a number"},
+				{Path: "name", Message: "is required"},
+				{Path: "size", Message: "must be at least 0, got -1"},
+			},
+		},
+		{
+			name:    "payload of the wrong type",
+			payload: `["prod"]`,
+			want:    []SchemaFieldError{{Path: "", Message: "expected an object, got an array"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateJSONSchema(schema, []byte(tt.payload))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := ValidateJSONSchema(schema, []byte(`{"name": `)); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
@@ -0,0 +1,433 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// DefaultJWKSRefreshInterval is the interval after which the JWKS is refetched
+	DefaultJWKSRefreshInterval = time.Hour
+	// jwksMinRefreshInterval rate limits JWKS refetches triggered by tokens signed with an unknown key ID
+	jwksMinRefreshInterval = 30 * time.Second
+	// jwtClockSkew is the leeway applied to the exp and nbf claims
+	jwtClockSkew = time.Minute
+)
+
+// ErrInvalidJWT is returned when a token fails verification
+var ErrInvalidJWT = errors.New("invalid jwt")
+
+// JWTAuthConfig configures OIDC/JWT authentication of inbound inference requests.
+// Verified tokens are mapped to a virtual key, which is then used for governance.
+type JWTAuthConfig struct {
+	Enabled             bool              `json:"enabled"`
+	Issuer              string            `json:"issuer"`                          // Expected iss claim
+	Audience            []string          `json:"audience,omitempty"`              // Accepted aud values, empty skips the audience check
+	JWKSURL             string            `json:"jwks_url"`                        // JWKS endpoint of the identity provider
+	JWKSRefreshInterval string            `json:"jwks_refresh_interval,omitempty"` // e.g. "1h", defaults to DefaultJWKSRefreshInterval
+	Required            bool              `json:"required"`                        // Reject inference requests that carry no JWT
+	VirtualKeyClaim     string            `json:"virtual_key_claim,omitempty"`     // Claim holding a virtual key value, checked before the claim mappings
+	ClaimMappings       []JWTClaimMapping `json:"claim_mappings,omitempty"`        // Evaluated in order, the first match wins
+}
+
+// JWTClaimMapping maps a claim value to a virtual key or to a team.
+// Exactly one of VirtualKey and TeamID must be set. A team resolves to its oldest active virtual key.
+type JWTClaimMapping struct {
+	Claim      string `json:"claim"`                 // Claim name, e.g. "groups" or "org_id"
+	Value      string `json:"value"`                 // Value to match, array claims match if any element matches
+	VirtualKey string `json:"virtual_key,omitempty"` // Virtual key value used for matching tokens
+	TeamID     string `json:"team_id,omitempty"`     // Team whose virtual key is used for matching tokens
+}
+
+// Validate checks the JWT auth config for missing or inconsistent fields
+func (c *JWTAuthConfig) Validate() error {
+	if c.Issuer == "" {
+		return fmt.Errorf("jwt auth issuer is required")
+	}
+	if c.JWKSURL == "" {
+		return fmt.Errorf("jwt auth jwks_url is required")
+	}
+	if c.JWKSRefreshInterval != "" {
+		if d, err := time.ParseDuration(c.JWKSRefreshInterval); err != nil || d <= 0 {
+			return fmt.Errorf("invalid jwt auth jwks_refresh_interval: %s", c.JWKSRefreshInterval)
+		}
+	}
+	for i, mapping := range c.ClaimMappings {
+		if mapping.Claim == "" || mapping.Value == "" {
+			return fmt.Errorf("jwt auth claim mapping %d requires claim and value", i)
+		}
+		if (mapping.VirtualKey == "") == (mapping.TeamID == "") {
+			return fmt.Errorf("jwt auth claim mapping %d requires exactly one of virtual_key and team_id", i)
+		}
+	}
+	return nil
+}
+
+// JWTVerifier verifies JWTs against the keys published by the configured JWKS endpoint.
+// The key set is cached and refetched periodically, or earlier when a token references an unknown key ID,
+// so identity provider key rotations are picked up without a restart.
+type JWTVerifier struct {
+	config          *JWTAuthConfig
+	httpClient      *http.Client
+	refreshInterval time.Duration
+
+	mu        sync.RWMutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+	refresh   singleflight.Group // Concurrent refreshes share a single fetch of the JWKS
+}
+
+// NewJWTVerifier creates a new verifier for the given config
+func NewJWTVerifier(config *JWTAuthConfig) (*JWTVerifier, error) {
+	if config == nil {
+		return nil, fmt.Errorf("jwt auth config is required")
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	refreshInterval := DefaultJWKSRefreshInterval
+	if config.JWKSRefreshInterval != "" {
+		refreshInterval, _ = time.ParseDuration(config.JWKSRefreshInterval)
+	}
+	return &JWTVerifier{
+		config:          config,
+		httpClient:      &http.Client{Timeout: 10 * time.Second},
+		refreshInterval: refreshInterval,
+		keys:            make(map[string]crypto.PublicKey),
+	}, nil
+}
+
+// jwtHeader is the JOSE header of a token
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// Verify checks the signature, issuer, audience and validity window of the token and returns its claims
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidJWT)
+	}
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidJWT)
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidJWT)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidJWT)
+	}
+	key, err := v.getKey(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyJWTSignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	claimsBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidJWT)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(claimsBytes))
+	decoder.UseNumber()
+	var claims map[string]any
+	if err := decoder.Decode(&claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidJWT)
+	}
+	if err := v.validateClaims(claims, time.Now()); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// validateClaims checks the registered claims of a token
+func (v *JWTVerifier) validateClaims(claims map[string]any, now time.Time) error {
+	if iss, _ := claims["iss"].(string); iss != v.config.Issuer {
+		return fmt.Errorf("%w: unexpected issuer %q", ErrInvalidJWT, iss)
+	}
+	exp, ok := numericDateClaim(claims, "exp")
+	if !ok {
+		return fmt.Errorf("%w: missing exp claim", ErrInvalidJWT)
+	}
+	if now.After(exp.Add(jwtClockSkew)) {
+		return fmt.Errorf("%w: token expired", ErrInvalidJWT)
+	}
+	if nbf, ok := numericDateClaim(claims, "nbf"); ok && now.Add(jwtClockSkew).Before(nbf) {
+		return fmt.Errorf("%w: token not valid yet", ErrInvalidJWT)
+	}
+	if len(v.config.Audience) > 0 {
+		audiences := ClaimStrings(claims, "aud")
+		if !slices.ContainsFunc(audiences, func(aud string) bool {
+			return slices.Contains(v.config.Audience, aud)
+		}) {
+			return fmt.Errorf("%w: unexpected audience", ErrInvalidJWT)
+		}
+	}
+	return nil
+}
+
+// ClaimStrings returns a claim as a list of strings, single string claims are returned as a one element list
+func ClaimStrings(claims map[string]any, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		return []string{value}
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if str, ok := item.(string); ok {
+				values = append(values, str)
+			}
+		}
+		return values
+	case json.Number:
+		return []string{value.String()}
+	case bool:
+		return []string{fmt.Sprintf("%t", value)}
+	}
+	return nil
+}
+
+// numericDateClaim reads a NumericDate claim (seconds since epoch)
+func numericDateClaim(claims map[string]any, name string) (time.Time, bool) {
+	number, ok := claims[name].(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(seconds), 0), true
+}
+
+// getKey returns the public key with the given key ID, refetching the JWKS when it is stale or the key is unknown
+func (v *JWTVerifier) getKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.RLock()
+	key, found := v.lookupKey(kid)
+	fetchedAt := v.fetchedAt
+	v.mu.RUnlock()
+	stale := time.Since(fetchedAt) > v.refreshInterval
+	canRefetch := time.Since(fetchedAt) > jwksMinRefreshInterval
+	if found && !stale {
+		return key, nil
+	}
+	if !stale && !canRefetch {
+		return nil, fmt.Errorf("%w: unknown key id %q", ErrInvalidJWT, kid)
+	}
+	_, err, _ := v.refresh.Do(v.config.JWKSURL, func() (any, error) {
+		v.mu.RLock()
+		refreshed := v.fetchedAt.After(fetchedAt)
+		v.mu.RUnlock()
+		if refreshed {
+			// Another request refreshed the key set since it was read
+			return nil, nil
+		}
+		// The fetch is shared by the waiting requests, it must not be cancelled with the first one
+		return nil, v.refreshKeys(context.WithoutCancel(ctx))
+	})
+	if err != nil {
+		if found {
+			// Keep serving the cached key if the identity provider is unreachable
+			return key, nil
+		}
+		return nil, err
+	}
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if key, found := v.lookupKey(kid); found {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: unknown key id %q", ErrInvalidJWT, kid)
+}
+
+// lookupKey finds a cached key, tokens without a key ID match a key set holding a single key.
+// The caller must hold the lock.
+func (v *JWTVerifier) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+// jsonWebKey is a single key of a JWKS document
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// refreshKeys fetches the JWKS and replaces the cached key set
+func (v *JWTVerifier) refreshKeys(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.config.JWKSURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create jwks request: %w", err)
+	}
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch jwks: unexpected status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read jwks: %w", err)
+	}
+	var document struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return fmt.Errorf("failed to parse jwks: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(document.Keys))
+	for _, jwk := range document.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			logger.Warn("skipping jwks key %s: %v", jwk.Kid, err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	v.mu.Lock()
+	v.keys = keys
+	v.fetchedAt = time.Now()
+	v.mu.Unlock()
+	return nil
+}
+
+// publicKey converts the JWK to an RSA or ECDSA public key
+func (jwk jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBase64BigInt(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeBase64BigInt(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", jwk.Crv)
+		}
+		x, err := decodeBase64BigInt(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := decodeBase64BigInt(jwk.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", jwk.Kty)
+}
+
+// decodeBase64BigInt decodes a base64url encoded big-endian integer
+func decodeBase64BigInt(value string) (*big.Int, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) == 0 {
+		return nil, fmt.Errorf("empty value")
+	}
+	return new(big.Int).SetBytes(decoded), nil
+}
+
+// verifyJWTSignature verifies the JWS signature of the signing input with the given algorithm.
+// Only asymmetric algorithms are accepted, so a token can never be verified with a shared secret.
+func verifyJWTSignature(alg string, key crypto.PublicKey, signingInput, signature []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "PS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "PS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "PS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidJWT, alg)
+	}
+	hasher := hash.New()
+	hasher.Write(signingInput)
+	digest := hasher.Sum(nil)
+
+	switch alg[0] {
+	case 'R':
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: key type does not match algorithm %s", ErrInvalidJWT, alg)
+		}
+		if err := rsa.VerifyPKCS1v15(rsaKey, hash, digest, signature); err != nil {
+			return fmt.Errorf("%w: invalid signature", ErrInvalidJWT)
+		}
+	case 'P':
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: key type does not match algorithm %s", ErrInvalidJWT, alg)
+		}
+		if err := rsa.VerifyPSS(rsaKey, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}); err != nil {
+			return fmt.Errorf("%w: invalid signature", ErrInvalidJWT)
+		}
+	case 'E':
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: key type does not match algorithm %s", ErrInvalidJWT, alg)
+		}
+		size := (ecKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("%w: invalid signature", ErrInvalidJWT)
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(ecKey, digest, r, s) {
+			return fmt.Errorf("%w: invalid signature", ErrInvalidJWT)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package lib
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testJWKSServer serves the public keys of the given signing keys as a JWKS document
+func testJWKSServer(t *testing.T, keys map[string]*rsa.PrivateKey, fetches *atomic.Int32) (*httptest.Server, *atomic.Pointer[map[string]*rsa.PrivateKey]) {
+	t.Helper()
+	keySet := &atomic.Pointer[map[string]*rsa.PrivateKey]{}
+	keySet.Store(&keys)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fetches != nil {
+			fetches.Add(1)
+		}
+		jwks := struct {
+			Keys []map[string]string `json:"keys"`
+		}{}
+		for kid, key := range *keySet.Load() {
+			jwks.Keys = append(jwks.Keys, map[string]string{
+				"kty": "RSA",
+				"kid": kid,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(jwks)
+	})), keySet
+}
+
+// signTestJWT creates an RS256 token with the given key ID and claims
+func signTestJWT(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("failed to marshal claims: %v", err)
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func generateTestRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	return key
+}
+
+func validTestClaims() map[string]any {
+	return map[string]any{
+		"iss": "https://issuer.example.com",
+		"aud": []string{"bifrost"},
+		"sub": "user-1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+// TestJWTVerifier_ValidToken tests that a correctly signed token with valid claims is accepted
+func TestJWTVerifier_ValidToken(t *testing.T) {
+	key := generateTestRSAKey(t)
+	server, _ := testJWKSServer(t, map[string]*rsa.PrivateKey{"key-1": key}, nil)
+	defer server.Close()
+
+	verifier, err := NewJWTVerifier(&JWTAuthConfig{
+		Enabled:  true,
+		Issuer:   "https://issuer.example.com",
+		Audience: []string{"bifrost"},
+		JWKSURL:  server.URL,
+	})
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+
+	claims, err := verifier.Verify(context.Background(), signTestJWT(t, key, "key-1", validTestClaims()))
+	if err != nil {
+		t.Fatalf("expected token to be valid, got: %v", err)
+	}
+	if claims["sub"] != "user-1" {
+		t.Errorf("expected sub claim user-1, got %v", claims["sub"])
+	}
+}
+
+// TestJWTVerifier_RejectsInvalidTokens tests that tampered, expired and foreign tokens are rejected
+func TestJWTVerifier_RejectsInvalidTokens(t *testing.T) {
+	key := generateTestRSAKey(t)
+	otherKey := generateTestRSAKey(t)
+	server, _ := testJWKSServer(t, map[string]*rsa.PrivateKey{"key-1": key}, nil)
+	defer server.Close()
+
+	verifier, err := NewJWTVerifier(&JWTAuthConfig{
+		Enabled:  true,
+		Issuer:   "https://issuer.example.com",
+		Audience: []string{"bifrost"},
+		JWKSURL:  server.URL,
+	})
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+
+	expired := validTestClaims()
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	wrongIssuer := validTestClaims()
+	wrongIssuer["iss"] = "https://evil.example.com"
+	wrongAudience := validTestClaims()
+	wrongAudience["aud"] = "someone-else"
+	missingExp := validTestClaims()
+	delete(missingExp, "exp")
+
+	tests := map[string]string{
+		"expired":        signTestJWT(t, key, "key-1", expired),
+		"wrong issuer":   signTestJWT(t, key, "key-1", wrongIssuer),
+		"wrong audience": signTestJWT(t, key, "key-1", wrongAudience),
+		"missing exp":    signTestJWT(t, key, "key-1", missingExp),
+		"foreign key":    signTestJWT(t, otherKey, "key-1", validTestClaims()),
+		"malformed":      "not-a-token",
+		"alg none":       base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"key-1"}`)) + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"https://issuer.example.com"}`)) + ".",
+	}
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := verifier.Verify(context.Background(), token); !errors.Is(err, ErrInvalidJWT) {
+				t.Errorf("expected ErrInvalidJWT, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestJWTVerifier_KeyRotation tests that a token signed with a new key ID triggers a JWKS refetch
+func TestJWTVerifier_KeyRotation(t *testing.T) {
+	oldKey := generateTestRSAKey(t)
+	newKey := generateTestRSAKey(t)
+	var fetches atomic.Int32
+	server, keySet := testJWKSServer(t, map[string]*rsa.PrivateKey{"old": oldKey}, &fetches)
+	defer server.Close()
+
+	verifier, err := NewJWTVerifier(&JWTAuthConfig{
+		Enabled: true,
+		Issuer:  "https://issuer.example.com",
+		JWKSURL: server.URL,
+	})
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+	if _, err := verifier.Verify(context.Background(), signTestJWT(t, oldKey, "old", validTestClaims())); err != nil {
+		t.Fatalf("expected token signed with the old key to be valid, got: %v", err)
+	}
+
+	// Rotate the key at the identity provider and allow an immediate refetch
+	keySet.Store(&map[string]*rsa.PrivateKey{"old": oldKey, "new": newKey})
+	verifier.mu.Lock()
+	verifier.fetchedAt = time.Now().Add(-2 * jwksMinRefreshInterval)
+	verifier.mu.Unlock()
+
+	if _, err := verifier.Verify(context.Background(), signTestJWT(t, newKey, "new", validTestClaims())); err != nil {
+		t.Fatalf("expected token signed with the rotated key to be valid, got: %v", err)
+	}
+	if fetches.Load() != 2 {
+		t.Errorf("expected 2 jwks fetches, got %d", fetches.Load())
+	}
+}
+
+// TestJWTVerifier_ConcurrentRefresh tests that concurrent requests missing the key set share a single JWKS fetch
+func TestJWTVerifier_ConcurrentRefresh(t *testing.T) {
+	key := generateTestRSAKey(t)
+	var fetches atomic.Int32
+	server, _ := testJWKSServer(t, map[string]*rsa.PrivateKey{"key-1": key}, &fetches)
+	defer server.Close()
+
+	verifier, err := NewJWTVerifier(&JWTAuthConfig{
+		Enabled: true,
+		Issuer:  "https://issuer.example.com",
+		JWKSURL: server.URL,
+	})
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+	token := signTestJWT(t, key, "key-1", validTestClaims())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := verifier.Verify(context.Background(), token); err != nil {
+				t.Errorf("expected token to be valid, got: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if fetches.Load() != 1 {
+		t.Errorf("expected a single jwks fetch, got %d", fetches.Load())
+	}
+}
+
+// TestJWTAuthConfig_Validate tests the validation of claim mappings
+func TestJWTAuthConfig_Validate(t *testing.T) {
+	config := &JWTAuthConfig{
+		Issuer:  "https://issuer.example.com",
+		JWKSURL: "https://issuer.example.com/jwks",
+		ClaimMappings: []JWTClaimMapping{
+			{Claim: "groups", Value: "admins", VirtualKey: "sk-bf-1", TeamID: "team-1"},
+		},
+	}
+	if err := config.Validate(); err == nil {
+		t.Error("expected mapping with both virtual_key and team_id to be rejected")
+	}
+	config.ClaimMappings[0].TeamID = ""
+	if err := config.Validate(); err != nil {
+		t.Errorf("expected valid config, got: %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+package lib
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Files the service account of a pod is mounted at
+const (
+	kubeServiceAccountDir  = "/var/run/secrets/kubernetes.io/serviceaccount"
+	kubeTokenFile          = kubeServiceAccountDir + "/token"
+	kubeCACertFile         = kubeServiceAccountDir + "/ca.crt"
+	kubeNamespaceFile      = kubeServiceAccountDir + "/namespace"
+	kubeWatchTimeoutSecond = 300
+)
+
+// kubeClient is a minimal client of the Kubernetes API, reading custom resources and secrets with the service account
+// of the pod
+type kubeClient struct {
+	baseURL    string
+	tokenFile  string // Re-read on every request since projected service account tokens are rotated
+	httpClient *http.Client
+}
+
+// kubeObjectMeta is the metadata of a Kubernetes object
+type kubeObjectMeta struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace"`
+	UID             string `json:"uid"`
+	ResourceVersion string `json:"resourceVersion"`
+}
+
+// kubeList is a list of Kubernetes objects
+type kubeList[T any] struct {
+	Metadata struct {
+		ResourceVersion string `json:"resourceVersion"`
+	} `json:"metadata"`
+	Items []T `json:"items"`
+}
+
+// kubeSecret is a Kubernetes secret, its data being decoded from base64 by the JSON decoding
+type kubeSecret struct {
+	Data map[string][]byte `json:"data"`
+}
+
+// kubeStatusError is an error status returned by the Kubernetes API
+type kubeStatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *kubeStatusError) Error() string {
+	return fmt.Sprintf("kubernetes api returned %d: %s", e.StatusCode, e.Message)
+}
+
+// newInClusterKubeClient creates a client of the Kubernetes API of the cluster the pod runs in
+func newInClusterKubeClient() (*kubeClient, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("not running in a kubernetes cluster: KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are not set")
+	}
+	caCert, err := os.ReadFile(kubeCACertFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the service account ca certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("invalid service account ca certificate")
+	}
+	return &kubeClient{
+		baseURL:   "https://" + net.JoinHostPort(host, port),
+		tokenFile: kubeTokenFile,
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig:     &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+				TLSHandshakeTimeout: 10 * time.Second,
+			},
+		},
+	}, nil
+}
+
+// inClusterNamespace returns the namespace of the pod, empty when it cannot be read
+func inClusterNamespace() string {
+	namespace, err := os.ReadFile(kubeNamespaceFile)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(namespace))
+}
+
+// request sends a GET request to a path of the Kubernetes API
+func (c *kubeClient) request(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.tokenFile != "" {
+		token, err := os.ReadFile(c.tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the service account token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, &kubeStatusError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+	}
+	return resp, nil
+}
+
+// get decodes the object at a path of the Kubernetes API
+func (c *kubeClient) get(ctx context.Context, path string, out any) error {
+	resp, err := c.request(ctx, path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return nil
+}
+
+// watch watches the objects of a collection from a resource version, and calls onEvent on every change until the
+// watch times out, fails or the context is cancelled
+func (c *kubeClient) watch(ctx context.Context, path string, resourceVersion string, onEvent func()) error {
+	query := url.Values{}
+	query.Set("watch", "true")
+	query.Set("allowWatchBookmarks", "true")
+	query.Set("timeoutSeconds", fmt.Sprint(kubeWatchTimeoutSecond))
+	if resourceVersion != "" {
+		query.Set("resourceVersion", resourceVersion)
+	}
+	resp, err := c.request(ctx, path, query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var event struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return fmt.Errorf("failed to decode watch event: %w", err)
+		}
+		switch event.Type {
+		case "BOOKMARK":
+			continue
+		case "ERROR":
+			// Mostly an expired resource version, the caller lists again
+			return fmt.Errorf("watch of %s ended with an error event", path)
+		}
+		onEvent()
+	}
+	return scanner.Err()
+}
+
+// getSecret returns the data of a secret
+func (c *kubeClient) getSecret(ctx context.Context, namespace, name string) (map[string][]byte, error) {
+	var secret kubeSecret
+	if err := c.get(ctx, "/api/v1/namespaces/"+url.PathEscape(namespace)+"/secrets/"+url.PathEscape(name), &secret); err != nil {
+		return nil, err
+	}
+	return secret.Data, nil
+}
+
+// listKubeObjects lists the objects of a collection of the Kubernetes API, and returns the resource version to watch
+// them from
+func listKubeObjects[T any](ctx context.Context, c *kubeClient, path string) ([]T, string, error) {
+	var list kubeList[T]
+	if err := c.get(ctx, path, &list); err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Metadata.ResourceVersion, nil
+}
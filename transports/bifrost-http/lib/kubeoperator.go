@@ -0,0 +1,679 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	configstoreTables "github.com/maximhq/bifrost/framework/configstore/tables"
+	"github.com/maximhq/bifrost/framework/leader"
+	"github.com/maximhq/bifrost/plugins/governance"
+	"gorm.io/gorm"
+)
+
+const (
+	// KubernetesOperatorGroupVersion is the API group and version of the custom resources of the operator
+	KubernetesOperatorGroupVersion = "bifrost.dev/v1alpha1"
+	// DefaultKubernetesOperatorResync is the interval between two full reconciliations when none is configured
+	DefaultKubernetesOperatorResync = 5 * time.Minute
+	// kubernetesOperatorStateKey is the config store entry of the configuration owned by the operator
+	kubernetesOperatorStateKey = "kubernetes_operator_state"
+	// kubernetesOperatorRetryInterval is the delay before watching a collection again after a failure
+	kubernetesOperatorRetryInterval = 5 * time.Second
+)
+
+// Plural names of the custom resources of the operator
+const (
+	kubeProvidersResource   = "bifrostproviders"
+	kubeKeysResource        = "bifrostkeys"
+	kubeVirtualKeysResource = "bifrostvirtualkeys"
+)
+
+// KubernetesOperatorConfig configures the operator mode, in which BifrostProvider, BifrostKey and BifrostVirtualKey
+// custom resources are synced into the config store so that the configuration can be managed with GitOps tools
+type KubernetesOperatorConfig struct {
+	Enabled        bool   `json:"enabled"`
+	Namespace      string `json:"namespace,omitempty"`       // Namespace of the watched resources, defaults to the namespace of the pod, "*" watches all namespaces
+	ResyncInterval string `json:"resync_interval,omitempty"` // Interval between two full reconciliations, e.g. "5m", defaults to DefaultKubernetesOperatorResync
+}
+
+// Validate checks the operator config for invalid fields
+func (c *KubernetesOperatorConfig) Validate() error {
+	if c.ResyncInterval != "" {
+		interval, err := time.ParseDuration(c.ResyncInterval)
+		if err != nil || interval <= 0 {
+			return fmt.Errorf("kubernetes_operator resync_interval must be a positive duration, got %q", c.ResyncInterval)
+		}
+	}
+	return nil
+}
+
+// KubeSecretKeyReference refers to a key of a Kubernetes secret in the namespace of the resource
+type KubeSecretKeyReference struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// BifrostProviderSpec is the spec of a BifrostProvider resource, the settings of a provider other than its keys
+type BifrostProviderSpec struct {
+	Provider                 string                            `json:"provider,omitempty"` // Defaults to the name of the resource
+	NetworkConfig            *schemas.NetworkConfig            `json:"network_config,omitempty"`
+	ConcurrencyAndBufferSize *schemas.ConcurrencyAndBufferSize `json:"concurrency_and_buffer_size,omitempty"`
+	ProxyConfig              *schemas.ProxyConfig              `json:"proxy_config,omitempty"`
+	SendBackRawResponse      bool                              `json:"send_back_raw_response,omitempty"`
+	CustomProviderConfig     *schemas.CustomProviderConfig     `json:"custom_provider_config,omitempty"`
+}
+
+// BifrostKeySpec is the spec of a BifrostKey resource, a key of a provider whose value can come from a secret
+type BifrostKeySpec struct {
+	Provider    string                  `json:"provider"`
+	ValueFrom   *KubeSecretKeyReference `json:"value_from,omitempty"` // Secret holding the value of the key, takes precedence over value
+	schemas.Key                         // Fields of the key, its id is derived from the resource
+}
+
+// BifrostVirtualKeySpec is the spec of a BifrostVirtualKey resource
+type BifrostVirtualKeySpec struct {
+	Name            string                                `json:"name,omitempty"` // Defaults to the name of the resource
+	Description     string                                `json:"description,omitempty"`
+	Value           string                                `json:"value,omitempty"`      // Generated when neither value nor value_from is set
+	ValueFrom       *KubeSecretKeyReference               `json:"value_from,omitempty"` // Secret holding the value of the virtual key
+	IsActive        *bool                                 `json:"is_active,omitempty"`  // Defaults to true
+	TeamID          *string                               `json:"team_id,omitempty"`
+	CustomerID      *string                               `json:"customer_id,omitempty"`
+	ProviderConfigs []BifrostVirtualKeyProviderConfigSpec `json:"provider_configs,omitempty"` // Empty allows all providers
+}
+
+// BifrostVirtualKeyProviderConfigSpec is a provider a virtual key can use
+type BifrostVirtualKeyProviderConfigSpec struct {
+	Provider      string   `json:"provider"`
+	Weight        *float64 `json:"weight,omitempty"`         // Defaults to 1
+	AllowedModels []string `json:"allowed_models,omitempty"` // Empty allows all models
+}
+
+type kubeBifrostProvider struct {
+	Metadata kubeObjectMeta      `json:"metadata"`
+	Spec     BifrostProviderSpec `json:"spec"`
+}
+
+type kubeBifrostKey struct {
+	Metadata kubeObjectMeta `json:"metadata"`
+	Spec     BifrostKeySpec `json:"spec"`
+}
+
+type kubeBifrostVirtualKey struct {
+	Metadata kubeObjectMeta        `json:"metadata"`
+	Spec     BifrostVirtualKeySpec `json:"spec"`
+}
+
+// kubernetesOperatorState is the configuration owned by the operator. It is persisted so that resources deleted while
+// no replica runs the operator are still removed.
+type kubernetesOperatorState struct {
+	Providers   []string          `json:"providers"`
+	Keys        map[string]string `json:"keys"` // Provider of each key, by key id
+	VirtualKeys []string          `json:"virtual_keys"`
+}
+
+// VirtualKeyReloader reloads the virtual keys changed in the config store into governance
+type VirtualKeyReloader interface {
+	ReloadVirtualKey(ctx context.Context, id string) (*configstoreTables.TableVirtualKey, error)
+	RemoveVirtualKey(ctx context.Context, id string) error
+}
+
+// KubernetesOperator watches the Bifrost custom resources of a cluster and syncs them into the config store. The
+// resources are the source of truth of the configuration they own: changes made to it through the API are reverted on
+// the next reconciliation, while the providers, keys and virtual keys created otherwise are left untouched.
+type KubernetesOperator struct {
+	client      *kubeClient
+	config      *Config
+	virtualKeys VirtualKeyReloader
+	namespace   string // Empty for all namespaces
+	resync      time.Duration
+	elector     *leader.Elector
+
+	trigger chan struct{}
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewKubernetesOperator creates an operator syncing the custom resources of the cluster the server runs in
+func NewKubernetesOperator(operatorConfig *KubernetesOperatorConfig, config *Config, virtualKeys VirtualKeyReloader) (*KubernetesOperator, error) {
+	if config.ConfigStore == nil {
+		return nil, fmt.Errorf("the kubernetes operator requires a config store")
+	}
+	if err := operatorConfig.Validate(); err != nil {
+		return nil, err
+	}
+	client, err := newInClusterKubeClient()
+	if err != nil {
+		return nil, err
+	}
+	namespace := operatorConfig.Namespace
+	if namespace == "" {
+		namespace = inClusterNamespace()
+	} else if namespace == "*" {
+		namespace = ""
+	}
+	resync := DefaultKubernetesOperatorResync
+	if operatorConfig.ResyncInterval != "" {
+		resync, _ = time.ParseDuration(operatorConfig.ResyncInterval)
+	}
+	return &KubernetesOperator{
+		client:      client,
+		config:      config,
+		virtualKeys: virtualKeys,
+		namespace:   namespace,
+		resync:      resync,
+		trigger:     make(chan struct{}, 1),
+	}, nil
+}
+
+// SetElector sets the elector of the replica running the operator, so that the resources are synced once per cluster.
+// Must be called before Start.
+func (o *KubernetesOperator) SetElector(elector *leader.Elector) {
+	o.elector = elector
+}
+
+// Start watches the custom resources and reconciles the configuration right away, on every change of a resource and
+// on every resync interval
+func (o *KubernetesOperator) Start() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// Return early if already running
+	if o.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	o.cancel = cancel
+	for _, resource := range []string{kubeProvidersResource, kubeKeysResource, kubeVirtualKeysResource} {
+		o.wg.Add(1)
+		go func(resource string) {
+			defer o.wg.Done()
+			o.watchResource(ctx, resource)
+		}(resource)
+	}
+	o.wg.Add(1)
+	go func() {
+		defer o.wg.Done()
+		ticker := time.NewTicker(o.resync)
+		defer ticker.Stop()
+		for {
+			if o.elector.IsLeader() {
+				if err := o.reconcile(ctx); err != nil && ctx.Err() == nil {
+					logger.Warn("kubernetes operator failed to reconcile the configuration: %v", err)
+				}
+			}
+			select {
+			case <-o.trigger:
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	if o.namespace == "" {
+		logger.Info("kubernetes operator watching %s resources in all namespaces", KubernetesOperatorGroupVersion)
+	} else {
+		logger.Info("kubernetes operator watching %s resources in namespace %s", KubernetesOperatorGroupVersion, o.namespace)
+	}
+}
+
+// Stop stops watching the resources and waits for the running reconciliation to finish
+func (o *KubernetesOperator) Stop() {
+	o.mu.Lock()
+	if o.cancel == nil {
+		o.mu.Unlock()
+		return
+	}
+	o.cancel()
+	o.cancel = nil
+	o.mu.Unlock()
+	o.wg.Wait()
+}
+
+// requestReconcile schedules a reconciliation, the changes made meanwhile being reconciled together
+func (o *KubernetesOperator) requestReconcile() {
+	select {
+	case o.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// watchResource watches a collection of custom resources and requests a reconciliation on every change
+func (o *KubernetesOperator) watchResource(ctx context.Context, resource string) {
+	path := o.collectionPath(resource)
+	for ctx.Err() == nil {
+		_, resourceVersion, err := listKubeObjects[json.RawMessage](ctx, o.client, path)
+		if err == nil {
+			err = o.client.watch(ctx, path, resourceVersion, o.requestReconcile)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logger.Warn("kubernetes operator failed to watch %s: %v", resource, err)
+			select {
+			case <-time.After(kubernetesOperatorRetryInterval):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// collectionPath returns the API path of a collection of custom resources in the watched namespace
+func (o *KubernetesOperator) collectionPath(resource string) string {
+	if o.namespace == "" {
+		return "/apis/" + KubernetesOperatorGroupVersion + "/" + resource
+	}
+	return "/apis/" + KubernetesOperatorGroupVersion + "/namespaces/" + o.namespace + "/" + resource
+}
+
+// reconcile syncs the custom resources into the config store, and removes the configuration owned by the resources
+// deleted since the last reconciliation. A resource that cannot be synced does not stop the others.
+func (o *KubernetesOperator) reconcile(ctx context.Context) error {
+	providers, _, err := listKubeObjects[kubeBifrostProvider](ctx, o.client, o.collectionPath(kubeProvidersResource))
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", kubeProvidersResource, err)
+	}
+	keys, _, err := listKubeObjects[kubeBifrostKey](ctx, o.client, o.collectionPath(kubeKeysResource))
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", kubeKeysResource, err)
+	}
+	virtualKeys, _, err := listKubeObjects[kubeBifrostVirtualKey](ctx, o.client, o.collectionPath(kubeVirtualKeysResource))
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", kubeVirtualKeysResource, err)
+	}
+	state, err := o.loadState(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	next := kubernetesOperatorState{Keys: make(map[string]string)}
+
+	// Keys whose value cannot be resolved keep their current value until it can
+	desiredKeys := make(map[schemas.ModelProvider][]schemas.Key)
+	keptKeys := make(map[string]bool)
+	for _, resource := range keys {
+		id := kubeResourceID(resource.Metadata)
+		key, err := o.desiredKey(ctx, resource)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("BifrostKey %s/%s: %w", resource.Metadata.Namespace, resource.Metadata.Name, err))
+			if provider, ok := state.Keys[id]; ok {
+				next.Keys[id] = provider
+				keptKeys[id] = true
+			}
+			continue
+		}
+		provider := schemas.ModelProvider(resource.Spec.Provider)
+		desiredKeys[provider] = append(desiredKeys[provider], key)
+		next.Keys[id] = string(provider)
+	}
+	managedKey := func(id string) bool {
+		_, previous := state.Keys[id]
+		_, current := next.Keys[id]
+		return (previous || current) && !keptKeys[id]
+	}
+
+	specs := make(map[schemas.ModelProvider]*BifrostProviderSpec)
+	for i := range providers {
+		name := schemas.ModelProvider(providers[i].Spec.Provider)
+		if name == "" {
+			name = schemas.ModelProvider(providers[i].Metadata.Name)
+		}
+		specs[name] = &providers[i].Spec
+		next.Providers = append(next.Providers, string(name))
+	}
+
+	// Every provider owning or having owned a resource is synced, the providers of deleted BifrostProviders removed
+	names := make(map[schemas.ModelProvider]bool)
+	for name := range specs {
+		names[name] = true
+	}
+	for name := range desiredKeys {
+		names[name] = true
+	}
+	for _, name := range state.Keys {
+		names[schemas.ModelProvider(name)] = true
+	}
+	for _, name := range state.Providers {
+		names[schemas.ModelProvider(name)] = true
+	}
+	sortedNames := make([]schemas.ModelProvider, 0, len(names))
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Slice(sortedNames, func(i, j int) bool { return sortedNames[i] < sortedNames[j] })
+	for _, name := range sortedNames {
+		remove := specs[name] == nil && slices.Contains(state.Providers, string(name))
+		if err := o.syncProvider(ctx, name, specs[name], desiredKeys[name], managedKey, remove); err != nil {
+			errs = append(errs, fmt.Errorf("provider %s: %w", name, err))
+			if remove {
+				next.Providers = append(next.Providers, string(name))
+			}
+		}
+	}
+
+	for _, resource := range virtualKeys {
+		id := kubeResourceID(resource.Metadata)
+		next.VirtualKeys = append(next.VirtualKeys, id)
+		if err := o.syncVirtualKey(ctx, id, resource); err != nil {
+			errs = append(errs, fmt.Errorf("BifrostVirtualKey %s/%s: %w", resource.Metadata.Namespace, resource.Metadata.Name, err))
+		}
+	}
+	for _, id := range state.VirtualKeys {
+		if slices.Contains(next.VirtualKeys, id) {
+			continue
+		}
+		if err := o.deleteVirtualKey(ctx, id); err != nil {
+			errs = append(errs, fmt.Errorf("virtual key %s: %w", id, err))
+			next.VirtualKeys = append(next.VirtualKeys, id)
+		}
+	}
+
+	if err := o.saveState(ctx, next); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// kubeResourceID returns the id of the configuration owned by a resource. Namespaces cannot contain dots, so the id is
+// unique across namespaces.
+func kubeResourceID(metadata kubeObjectMeta) string {
+	return "k8s." + metadata.Namespace + "." + metadata.Name
+}
+
+// secretValue returns the value of a key of a secret
+func (o *KubernetesOperator) secretValue(ctx context.Context, namespace string, ref *KubeSecretKeyReference) (string, error) {
+	if ref.Name == "" || ref.Key == "" {
+		return "", fmt.Errorf("value_from requires a secret name and key")
+	}
+	data, err := o.client.getSecret(ctx, namespace, ref.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %s: %w", ref.Name, err)
+	}
+	value, ok := data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no key %s", ref.Name, ref.Key)
+	}
+	return strings.TrimSpace(string(value)), nil
+}
+
+// desiredKey returns the provider key of a BifrostKey resource
+func (o *KubernetesOperator) desiredKey(ctx context.Context, resource kubeBifrostKey) (schemas.Key, error) {
+	if resource.Spec.Provider == "" {
+		return schemas.Key{}, fmt.Errorf("spec.provider is required")
+	}
+	key := resource.Spec.Key
+	key.ID = kubeResourceID(resource.Metadata)
+	key.LastValidation = nil
+	if key.Name == "" {
+		key.Name = resource.Metadata.Name
+	}
+	if key.Weight == 0 {
+		key.Weight = 1
+	}
+	if key.Models == nil {
+		key.Models = []string{}
+	}
+	if resource.Spec.ValueFrom != nil {
+		value, err := o.secretValue(ctx, resource.Metadata.Namespace, resource.Spec.ValueFrom)
+		if err != nil {
+			return schemas.Key{}, err
+		}
+		key.Value = value
+	}
+	return key, nil
+}
+
+// syncProvider syncs the settings and the owned keys of a provider, keeping the keys it does not own. A provider
+// whose BifrostProvider was deleted is removed.
+func (o *KubernetesOperator) syncProvider(ctx context.Context, name schemas.ModelProvider, spec *BifrostProviderSpec, keys []schemas.Key, managedKey func(string) bool, remove bool) error {
+	current, err := o.config.GetProviderConfigRaw(name)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if remove {
+		if current == nil {
+			return nil
+		}
+		if err := o.config.RemoveProvider(ctx, name); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		logger.Info("kubernetes operator removed provider %s", name)
+		return nil
+	}
+
+	var desired configstore.ProviderConfig
+	if current != nil {
+		desired = *current
+	}
+	if spec != nil {
+		desired.NetworkConfig = spec.NetworkConfig
+		desired.ConcurrencyAndBufferSize = spec.ConcurrencyAndBufferSize
+		desired.ProxyConfig = spec.ProxyConfig
+		desired.SendBackRawResponse = spec.SendBackRawResponse
+		desired.CustomProviderConfig = spec.CustomProviderConfig
+	}
+	desired.Keys = nil
+	if current != nil {
+		for _, key := range current.Keys {
+			if !managedKey(key.ID) {
+				desired.Keys = append(desired.Keys, key)
+			}
+		}
+	}
+	for _, key := range keys {
+		// The validation recorded through the management API is kept while the key is unchanged
+		if current != nil {
+			if index := slices.IndexFunc(current.Keys, func(k schemas.Key) bool { return k.ID == key.ID }); index >= 0 {
+				key.LastValidation = current.Keys[index].LastValidation
+				if !sameJSON(key, current.Keys[index]) {
+					key.LastValidation = nil
+				}
+			}
+		}
+		desired.Keys = append(desired.Keys, key)
+	}
+	if err := ValidateKeys(desired.Keys); err != nil {
+		return fmt.Errorf("invalid keys: %w", err)
+	}
+
+	if current == nil {
+		if spec == nil && len(desired.Keys) == 0 {
+			return nil
+		}
+		if err := o.config.AddProvider(ctx, name, desired); err != nil {
+			return err
+		}
+		logger.Info("kubernetes operator added provider %s", name)
+		return nil
+	}
+	if sameJSON(*current, desired) {
+		return nil
+	}
+	if err := o.config.UpdateProviderConfig(ctx, name, desired); err != nil {
+		return err
+	}
+	logger.Info("kubernetes operator updated provider %s", name)
+	return nil
+}
+
+// syncVirtualKey creates or updates the virtual key of a BifrostVirtualKey resource
+func (o *KubernetesOperator) syncVirtualKey(ctx context.Context, id string, resource kubeBifrostVirtualKey) error {
+	spec := resource.Spec
+	store := o.config.ConfigStore
+	if spec.TeamID != nil && spec.CustomerID != nil {
+		return fmt.Errorf("a virtual key cannot be attached to both a team and a customer")
+	}
+	existing, err := store.GetVirtualKey(ctx, id)
+	if err != nil && !errors.Is(err, configstore.ErrNotFound) {
+		return err
+	}
+
+	value := spec.Value
+	if spec.ValueFrom != nil {
+		if value, err = o.secretValue(ctx, resource.Metadata.Namespace, spec.ValueFrom); err != nil {
+			return err
+		}
+	}
+	vk := configstoreTables.TableVirtualKey{ID: id, Namespace: configstoreTables.DefaultNamespace}
+	if existing != nil {
+		vk = *existing
+	}
+	if value == "" {
+		value = vk.Value
+	}
+	if value == "" {
+		value = governance.VirtualKeyPrefix + uuid.NewString()
+	}
+	name := spec.Name
+	if name == "" {
+		name = resource.Metadata.Name
+	}
+	// A revoked virtual key can never be reactivated
+	isActive := (spec.IsActive == nil || *spec.IsActive) && !vk.IsRevoked()
+	providerConfigs := make([]configstoreTables.TableVirtualKeyProviderConfig, 0, len(spec.ProviderConfigs))
+	for _, pc := range spec.ProviderConfigs {
+		weight := 1.0
+		if pc.Weight != nil {
+			weight = *pc.Weight
+		}
+		providerConfigs = append(providerConfigs, configstoreTables.TableVirtualKeyProviderConfig{
+			VirtualKeyID:  id,
+			Provider:      pc.Provider,
+			Weight:        weight,
+			AllowedModels: pc.AllowedModels,
+		})
+	}
+
+	if existing != nil &&
+		vk.Name == name && vk.Description == spec.Description && vk.Value == value && vk.IsActive == isActive &&
+		sameJSON(vk.TeamID, spec.TeamID) && sameJSON(vk.CustomerID, spec.CustomerID) &&
+		sameVirtualKeyProviders(vk.ProviderConfigs, providerConfigs) {
+		return nil
+	}
+
+	vk.Name = name
+	vk.Description = spec.Description
+	vk.Value = value
+	vk.IsActive = isActive
+	vk.TeamID = spec.TeamID
+	vk.CustomerID = spec.CustomerID
+	if err := store.ExecuteTransaction(ctx, func(tx *gorm.DB) error {
+		if existing == nil {
+			vk.ProviderConfigs = nil
+			vk.MCPConfigs = nil
+			if err := store.CreateVirtualKey(ctx, &vk, tx); err != nil {
+				return err
+			}
+		} else {
+			if err := store.UpdateVirtualKey(ctx, &vk, tx); err != nil {
+				return err
+			}
+			for _, pc := range existing.ProviderConfigs {
+				if err := store.DeleteVirtualKeyProviderConfig(ctx, pc.ID, tx); err != nil {
+					return err
+				}
+			}
+		}
+		for i := range providerConfigs {
+			if err := store.CreateVirtualKeyProviderConfig(ctx, &providerConfigs[i], tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if o.virtualKeys != nil {
+		if _, err := o.virtualKeys.ReloadVirtualKey(ctx, id); err != nil {
+			logger.Warn("kubernetes operator failed to reload virtual key %s: %v", id, err)
+		}
+	}
+	logger.Info("kubernetes operator synced virtual key %s", name)
+	return nil
+}
+
+// deleteVirtualKey deletes the virtual key of a deleted BifrostVirtualKey resource
+func (o *KubernetesOperator) deleteVirtualKey(ctx context.Context, id string) error {
+	if o.virtualKeys != nil {
+		if err := o.virtualKeys.RemoveVirtualKey(ctx, id); err != nil {
+			logger.Warn("kubernetes operator failed to remove virtual key %s from governance: %v", id, err)
+		}
+	}
+	if err := o.config.ConfigStore.DeleteVirtualKey(ctx, id); err != nil && !errors.Is(err, configstore.ErrNotFound) {
+		return err
+	}
+	logger.Info("kubernetes operator deleted virtual key %s", id)
+	return nil
+}
+
+// loadState returns the configuration owned by the operator
+func (o *KubernetesOperator) loadState(ctx context.Context) (kubernetesOperatorState, error) {
+	state := kubernetesOperatorState{Keys: make(map[string]string)}
+	entry, err := o.config.ConfigStore.GetConfig(ctx, kubernetesOperatorStateKey)
+	if err != nil {
+		if errors.Is(err, configstore.ErrNotFound) {
+			return state, nil
+		}
+		return state, fmt.Errorf("failed to load the kubernetes operator state: %w", err)
+	}
+	if err := json.Unmarshal([]byte(entry.Value), &state); err != nil {
+		return state, fmt.Errorf("failed to decode the kubernetes operator state: %w", err)
+	}
+	if state.Keys == nil {
+		state.Keys = make(map[string]string)
+	}
+	return state, nil
+}
+
+// saveState persists the configuration owned by the operator
+func (o *KubernetesOperator) saveState(ctx context.Context, state kubernetesOperatorState) error {
+	sort.Strings(state.Providers)
+	sort.Strings(state.VirtualKeys)
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := o.config.ConfigStore.UpdateConfig(ctx, &configstoreTables.TableGovernanceConfig{
+		Key:   kubernetesOperatorStateKey,
+		Value: string(data),
+	}); err != nil {
+		return fmt.Errorf("failed to save the kubernetes operator state: %w", err)
+	}
+	return nil
+}
+
+// sameVirtualKeyProviders reports whether the providers of a virtual key match the desired ones, ignoring their ids
+func sameVirtualKeyProviders(current, desired []configstoreTables.TableVirtualKeyProviderConfig) bool {
+	if len(current) != len(desired) {
+		return false
+	}
+	for i := range current {
+		if current[i].Provider != desired[i].Provider || current[i].Weight != desired[i].Weight ||
+			!slices.Equal(current[i].AllowedModels, desired[i].AllowedModels) ||
+			current[i].BudgetID != nil || current[i].RateLimitID != nil || len(current[i].Keys) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// sameJSON reports whether two values have the same JSON encoding
+func sameJSON(a, b any) bool {
+	aData, aErr := json.Marshal(a)
+	bData, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && string(aData) == string(bData)
+}
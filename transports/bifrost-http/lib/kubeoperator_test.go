@@ -0,0 +1,80 @@
+package lib
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestKubeOperator returns an operator reading from a fake Kubernetes API serving the given paths
+func newTestKubeOperator(t *testing.T, responses map[string]string) *KubernetesOperator {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.Error(w, `{"kind":"Status","reason":"NotFound"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return &KubernetesOperator{
+		client:    &kubeClient{baseURL: server.URL, httpClient: server.Client()},
+		namespace: "ai",
+	}
+}
+
+// TestKubernetesOperatorDesiredKey tests that BifrostKey resources become provider keys with their values read from
+// secrets
+func TestKubernetesOperatorDesiredKey(t *testing.T) {
+	secret := fmt.Sprintf(`{"data":{"api-key":%q}}`, base64.StdEncoding.EncodeToString([]byte("sk-test\n")))
+	keys := `{"metadata":{"resourceVersion":"42"},"items":[
+		{"metadata":{"name":"openai-primary","namespace":"ai"},"spec":{"provider":"openai","value_from":{"name":"openai","key":"api-key"},"models":["gpt-4o"]}},
+		{"metadata":{"name":"openai-missing","namespace":"ai"},"spec":{"provider":"openai","value_from":{"name":"missing","key":"api-key"}}},
+		{"metadata":{"name":"anthropic","namespace":"ai"},"spec":{"value":"env.ANTHROPIC_API_KEY"}}
+	]}`
+	operator := newTestKubeOperator(t, map[string]string{
+		"/apis/" + KubernetesOperatorGroupVersion + "/namespaces/ai/" + kubeKeysResource: keys,
+		"/api/v1/namespaces/ai/secrets/openai":                                           secret,
+	})
+
+	resources, resourceVersion, err := listKubeObjects[kubeBifrostKey](context.Background(), operator.client, operator.collectionPath(kubeKeysResource))
+	if err != nil {
+		t.Fatalf("failed to list keys: %v", err)
+	}
+	if len(resources) != 3 || resourceVersion != "42" {
+		t.Fatalf("expected 3 keys at resource version 42, got %d at %q", len(resources), resourceVersion)
+	}
+
+	key, err := operator.desiredKey(context.Background(), resources[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key.ID != "k8s.ai.openai-primary" || key.Name != "openai-primary" {
+		t.Errorf("expected the key to be named after its resource, got id %q and name %q", key.ID, key.Name)
+	}
+	if key.Value != "sk-test" || key.Weight != 1 || len(key.Models) != 1 {
+		t.Errorf("unexpected key %+v", key)
+	}
+
+	if _, err := operator.desiredKey(context.Background(), resources[1]); err == nil {
+		t.Error("expected an error for a missing secret")
+	}
+	if _, err := operator.desiredKey(context.Background(), resources[2]); err == nil {
+		t.Error("expected an error for a key without provider")
+	}
+}
+
+// TestKubernetesOperatorConfigValidate tests the validation of the operator configuration
+func TestKubernetesOperatorConfigValidate(t *testing.T) {
+	for interval, valid := range map[string]bool{"": true, "30s": true, "0s": false, "-1m": false, "soon": false} {
+		err := (&KubernetesOperatorConfig{Enabled: true, ResyncInterval: interval}).Validate()
+		if (err == nil) != valid {
+			t.Errorf("resync_interval %q: expected valid=%v, got error %v", interval, valid, err)
+		}
+	}
+}
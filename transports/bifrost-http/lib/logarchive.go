@@ -0,0 +1,341 @@
+package lib
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/leader"
+)
+
+const (
+	// DefaultLogArchiveAfterDays is the age of the logs archived when none is configured
+	DefaultLogArchiveAfterDays = 30
+	// DefaultLogArchiveIntervalMinutes is the interval between archive runs when none is configured
+	DefaultLogArchiveIntervalMinutes = 60
+	// DefaultLogArchiveBatchSize is the number of logs read, archived and deleted at once when none is configured
+	DefaultLogArchiveBatchSize = 1000
+	// DefaultLogArchivePrefix is the prefix of the archive objects when none is configured
+	DefaultLogArchivePrefix = "bifrost-archive"
+	// logArchiveRunTimeout bounds an archive run, the logs left are archived by the next run
+	logArchiveRunTimeout = 30 * time.Minute
+	// logArchiveNoNamespace is the namespace partition of the logs without a namespace
+	logArchiveNoNamespace = "_none"
+)
+
+// logArchiveUsageColumns are the columns of the usage records, the light copy of the logs kept for billing and
+// analytics without their payloads
+var logArchiveUsageColumns = []string{
+	"id", "parent_request_id", "timestamp", "created_at", "object_type", "provider", "model", "status", "stream",
+	"number_of_retries", "fallback_index", "selected_key_id", "selected_key_name", "virtual_key_id", "virtual_key_name",
+	"end_user", "namespace", "is_test_key", "latency", "prompt_tokens", "completion_tokens", "total_tokens",
+	"token_usage", "cost", "tags",
+}
+
+// LogArchiveConfig configures the archival of the logs to object storage. Logs older than ArchiveAfterDays are
+// written to gzipped JSONL files partitioned by date and namespace, along with their usage records, then deleted from
+// the log store.
+type LogArchiveConfig struct {
+	Enabled          bool   `json:"enabled"`
+	Type             string `json:"type"` // One of the ObjectStorage* constants
+	Bucket           string `json:"bucket"`
+	Prefix           string `json:"prefix,omitempty"`             // Prefix of the object names, defaults to bifrost-archive
+	Region           string `json:"region,omitempty"`             // Region of the S3 bucket, defaults to the region of the AWS environment
+	ArchiveAfterDays int    `json:"archive_after_days,omitempty"` // Age of the logs archived, defaults to 30 days
+	IntervalMinutes  int    `json:"interval_minutes,omitempty"`   // Interval between archive runs, defaults to 60 minutes
+	BatchSize        int    `json:"batch_size,omitempty"`         // Logs archived at once, defaults to 1000
+	TempDir          string `json:"temp_dir,omitempty"`           // Directory the files are written to before their upload, defaults to the system temp directory
+}
+
+// Validate checks the log archive config for invalid fields
+func (c *LogArchiveConfig) Validate() error {
+	switch c.Type {
+	case ObjectStorageS3, ObjectStorageGCS:
+	default:
+		return fmt.Errorf("log archive type must be %q or %q", ObjectStorageS3, ObjectStorageGCS)
+	}
+	if c.Bucket == "" {
+		return fmt.Errorf("log archive bucket is required")
+	}
+	if c.ArchiveAfterDays < 0 || c.IntervalMinutes < 0 || c.BatchSize < 0 {
+		return fmt.Errorf("log archive archive_after_days, interval_minutes and batch_size cannot be negative")
+	}
+	return nil
+}
+
+// LogArchiveStore is the subset of the log store the archiver reads and deletes the logs of
+type LogArchiveStore interface {
+	FindLogRowsBatch(ctx context.Context, cutoff time.Time, batchSize int) ([]map[string]interface{}, error)
+	DeleteLogs(ctx context.Context, ids []string) error
+}
+
+// LogArchiver moves the old logs of the log store to object storage, keeping the log store small while preserving
+// the full history. A batch of logs is deleted once all of its files are uploaded: a run interrupted in between
+// archives the batch again, the records are unique by their id.
+type LogArchiver struct {
+	config  LogArchiveConfig
+	store   LogArchiveStore
+	logger  schemas.Logger
+	upload  objectUploader
+	elector *leader.Elector // Elects the replica running the archive, nil runs it on every replica
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewLogArchiver creates an archiver of the logs of the store
+func NewLogArchiver(config *LogArchiveConfig, store LogArchiveStore, logger schemas.Logger) (*LogArchiver, error) {
+	if config == nil {
+		return nil, fmt.Errorf("log archive config is required")
+	}
+	if store == nil {
+		return nil, fmt.Errorf("log store is required")
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	resolved := *config
+	if resolved.Prefix == "" {
+		resolved.Prefix = DefaultLogArchivePrefix
+	}
+	resolved.Prefix = strings.Trim(resolved.Prefix, "/")
+	if resolved.ArchiveAfterDays == 0 {
+		resolved.ArchiveAfterDays = DefaultLogArchiveAfterDays
+	}
+	if resolved.IntervalMinutes == 0 {
+		resolved.IntervalMinutes = DefaultLogArchiveIntervalMinutes
+	}
+	if resolved.BatchSize == 0 {
+		resolved.BatchSize = DefaultLogArchiveBatchSize
+	}
+	return &LogArchiver{
+		config: resolved,
+		store:  store,
+		logger: logger,
+		upload: uploadObject,
+	}, nil
+}
+
+// ArchiveAfterDays returns the age of the logs archived
+func (a *LogArchiver) ArchiveAfterDays() int {
+	return a.config.ArchiveAfterDays
+}
+
+// SetElector sets the elector of the replica running the archive, so that the logs are archived once per cluster.
+// Must be called before Start.
+func (a *LogArchiver) SetElector(elector *leader.Elector) {
+	a.elector = elector
+}
+
+// Start runs an archive now and then every interval, until Stop is called
+func (a *LogArchiver) Start() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+
+	interval := time.Duration(a.config.IntervalMinutes) * time.Minute
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		a.run(ctx)
+		// Jitter spreads the runs of the replicas taking over from each other
+		timer := time.NewTimer(interval + time.Duration(rand.Int63n(int64(interval/10)+1)))
+		defer timer.Stop()
+		for {
+			select {
+			case <-timer.C:
+				a.run(ctx)
+				timer.Reset(interval + time.Duration(rand.Int63n(int64(interval/10)+1)))
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	a.logger.Info("log archive routine started, archiving logs older than %d days to %s://%s/%s", a.config.ArchiveAfterDays, a.config.Type, a.config.Bucket, a.config.Prefix)
+}
+
+// Stop stops the archive routine and waits for the batch being archived, which is left in the log store
+func (a *LogArchiver) Stop() {
+	a.mu.Lock()
+	cancel := a.cancel
+	a.cancel = nil
+	a.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	a.wg.Wait()
+}
+
+// run archives the logs past their age on the leader replica only
+func (a *LogArchiver) run(ctx context.Context) {
+	if !a.elector.IsLeader() {
+		a.logger.Debug("skipping log archive, another replica runs it")
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, logArchiveRunTimeout)
+	defer cancel()
+	archived, err := a.archive(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			a.logger.Warn("log archive interrupted after %d logs: %v", archived, err)
+			return
+		}
+		a.logger.Error("failed to archive logs after %d logs: %v", archived, err)
+		return
+	}
+	if archived > 0 {
+		a.logger.Info("log archive completed: archived %d logs", archived)
+	} else {
+		a.logger.Debug("log archive completed: no logs to archive")
+	}
+}
+
+// archive archives the logs older than the archive age batch by batch, and returns the number of logs archived
+func (a *LogArchiver) archive(ctx context.Context) (int, error) {
+	cutoff := time.Now().UTC().AddDate(0, 0, -a.config.ArchiveAfterDays)
+	runID := time.Now().UTC().Format("20060102T150405Z")
+	archived := 0
+	for batch := 0; ; batch++ {
+		if err := ctx.Err(); err != nil {
+			return archived, err
+		}
+		rows, err := a.store.FindLogRowsBatch(ctx, cutoff, a.config.BatchSize)
+		if err != nil {
+			return archived, fmt.Errorf("failed to read logs: %v", err)
+		}
+		if len(rows) == 0 {
+			return archived, nil
+		}
+		if err := a.archiveBatch(ctx, rows, fmt.Sprintf("%s-%04d", runID, batch)); err != nil {
+			return archived, err
+		}
+		archived += len(rows)
+		if len(rows) < a.config.BatchSize {
+			return archived, nil
+		}
+	}
+}
+
+// archiveBatch uploads the logs and usage records of a batch, one file per partition, and deletes the logs from the
+// log store once all the files are uploaded
+func (a *LogArchiver) archiveBatch(ctx context.Context, rows []map[string]interface{}, part string) error {
+	partitions := make(map[string][]map[string]interface{})
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		id, _ := row["id"].(string)
+		if id == "" {
+			return fmt.Errorf("log row without an id")
+		}
+		ids = append(ids, id)
+		partition := logArchivePartition(row)
+		partitions[partition] = append(partitions[partition], row)
+	}
+	names := make([]string, 0, len(partitions))
+	for partition := range partitions {
+		names = append(names, partition)
+	}
+	sort.Strings(names)
+
+	for _, partition := range names {
+		logs := partitions[partition]
+		usage := make([]map[string]interface{}, 0, len(logs))
+		for _, row := range logs {
+			record := make(map[string]interface{}, len(logArchiveUsageColumns))
+			for _, column := range logArchiveUsageColumns {
+				if value, ok := row[column]; ok {
+					record[column] = value
+				}
+			}
+			usage = append(usage, record)
+		}
+		if err := a.uploadRecords(ctx, path.Join(a.config.Prefix, "logs", partition, "part-"+part+".jsonl.gz"), logs); err != nil {
+			return err
+		}
+		if err := a.uploadRecords(ctx, path.Join(a.config.Prefix, "usage", partition, "part-"+part+".jsonl.gz"), usage); err != nil {
+			return err
+		}
+	}
+	if err := a.store.DeleteLogs(ctx, ids); err != nil {
+		return fmt.Errorf("failed to delete archived logs: %v", err)
+	}
+	return nil
+}
+
+// uploadRecords writes the records to a gzipped JSONL file and uploads it to the key
+func (a *LogArchiver) uploadRecords(ctx context.Context, key string, records []map[string]interface{}) error {
+	file, err := os.CreateTemp(a.config.TempDir, "bifrost-archive-*.jsonl.gz")
+	if err != nil {
+		return fmt.Errorf("failed to create archive file: %v", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	writer := gzip.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to encode log %v: %v", record["id"], err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to write archive file: %v", err)
+	}
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := a.upload(ctx, objectLocation{
+		storage:     a.config.Type,
+		bucket:      a.config.Bucket,
+		key:         key,
+		region:      a.config.Region,
+		contentType: "application/gzip",
+	}, file, size); err != nil {
+		return fmt.Errorf("failed to upload %s: %v", key, err)
+	}
+	return nil
+}
+
+// logArchivePartition returns the Hive style partition of a log row: the UTC date of its creation and its namespace
+func logArchivePartition(row map[string]interface{}) string {
+	namespace, _ := row["namespace"].(string)
+	if namespace == "" {
+		namespace = logArchiveNoNamespace
+	}
+	return fmt.Sprintf("date=%s/namespace=%s", logRowTime(row["created_at"]).UTC().Format(time.DateOnly), url.PathEscape(namespace))
+}
+
+// logRowTime reads a time column of a log row, scanned as a time or as text depending on the driver
+func logRowTime(value interface{}) time.Time {
+	switch v := value.(type) {
+	case time.Time:
+		return v
+	case string:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999-07:00", "2006-01-02 15:04:05.999999999"} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
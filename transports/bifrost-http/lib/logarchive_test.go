@@ -0,0 +1,197 @@
+package lib
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// fakeLogArchiveStore serves the rows created before the cutoff, oldest first, and deletes them
+type fakeLogArchiveStore struct {
+	rows []map[string]interface{}
+}
+
+func (s *fakeLogArchiveStore) FindLogRowsBatch(ctx context.Context, cutoff time.Time, batchSize int) ([]map[string]interface{}, error) {
+	var rows []map[string]interface{}
+	for _, row := range s.rows {
+		if len(rows) < batchSize && row["created_at"].(time.Time).Before(cutoff) {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func (s *fakeLogArchiveStore) DeleteLogs(ctx context.Context, ids []string) error {
+	deleted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		deleted[id] = true
+	}
+	kept := s.rows[:0]
+	for _, row := range s.rows {
+		if !deleted[row["id"].(string)] {
+			kept = append(kept, row)
+		}
+	}
+	s.rows = kept
+	return nil
+}
+
+// testLogArchiveRows returns two old logs of the acme namespace on the same day, an old log without a namespace and
+// a recent log
+func testLogArchiveRows() []map[string]interface{} {
+	day := time.Date(2026, 3, 14, 10, 0, 0, 0, time.UTC)
+	row := func(id, namespace string, createdAt time.Time) map[string]interface{} {
+		return map[string]interface{}{
+			"id":            id,
+			"created_at":    createdAt,
+			"timestamp":     createdAt,
+			"provider":      "openai",
+			"model":         "gpt-4o",
+			"namespace":     namespace,
+			"total_tokens":  42,
+			"cost":          0.01,
+			"input_history": `[{"role":"user","content":"hello"}]`,
+		}
+	}
+	return []map[string]interface{}{
+		row("a", "acme", day),
+		row("b", "acme", day.Add(time.Hour)),
+		row("c", "", day.AddDate(0, 0, 1)),
+		row("recent", "acme", time.Now().UTC()),
+	}
+}
+
+// newTestLogArchiver returns an archiver whose uploads are decompressed into the objects map, by key
+func newTestLogArchiver(t *testing.T, store LogArchiveStore, batchSize int, objects map[string][]map[string]interface{}) *LogArchiver {
+	t.Helper()
+	archiver, err := NewLogArchiver(&LogArchiveConfig{
+		Enabled:   true,
+		Type:      ObjectStorageS3,
+		Bucket:    "archive",
+		Prefix:    "/history/",
+		BatchSize: batchSize,
+		TempDir:   t.TempDir(),
+	}, store, bifrost.NewDefaultLogger(schemas.LogLevelError))
+	if err != nil {
+		t.Fatalf("failed to create log archiver: %v", err)
+	}
+	archiver.upload = func(ctx context.Context, object objectLocation, file *os.File, size int64) (string, error) {
+		if object.bucket != "archive" || object.contentType != "application/gzip" {
+			t.Errorf("unexpected upload to %+v", object)
+		}
+		reader, err := gzip.NewReader(file)
+		if err != nil {
+			return "", err
+		}
+		var records []map[string]interface{}
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			var record map[string]interface{}
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				return "", err
+			}
+			records = append(records, record)
+		}
+		objects[object.key] = records
+		return "s3://archive/" + object.key, nil
+	}
+	return archiver
+}
+
+// TestLogArchiver_Archive tests that the old logs are uploaded with their usage records, partitioned by date and
+// namespace, then deleted from the log store
+func TestLogArchiver_Archive(t *testing.T) {
+	store := &fakeLogArchiveStore{rows: testLogArchiveRows()}
+	objects := make(map[string][]map[string]interface{})
+	archiver := newTestLogArchiver(t, store, 2, objects)
+
+	archived, err := archiver.archive(context.Background())
+	if err != nil {
+		t.Fatalf("failed to archive logs: %v", err)
+	}
+	if archived != 3 || len(store.rows) != 1 || store.rows[0]["id"] != "recent" {
+		t.Fatalf("expected the 3 old logs archived and deleted, got %d archived and %d left", archived, len(store.rows))
+	}
+
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	// The first batch holds the two acme logs, the second batch the log without a namespace
+	if len(keys) != 4 {
+		t.Fatalf("expected a logs and a usage file per batch, got %v", keys)
+	}
+	for _, prefix := range []string{"history/logs/", "history/usage/"} {
+		acme, other := fmt.Sprintf("%sdate=2026-03-14/namespace=acme/part-", prefix), fmt.Sprintf("%sdate=2026-03-15/namespace=_none/part-", prefix)
+		var acmeRecords, otherRecords int
+		for key, records := range objects {
+			switch {
+			case strings.HasPrefix(key, acme):
+				acmeRecords += len(records)
+			case strings.HasPrefix(key, other):
+				otherRecords += len(records)
+			}
+		}
+		if acmeRecords != 2 || otherRecords != 1 {
+			t.Errorf("expected 2 acme and 1 other records under %s, got %d and %d in %v", prefix, acmeRecords, otherRecords, keys)
+		}
+	}
+
+	for key, records := range objects {
+		_, hasPayload := records[0]["input_history"]
+		if strings.HasPrefix(key, "history/usage/") == hasPayload {
+			t.Errorf("expected the payloads in the logs files only, got %v in %s", records[0], key)
+		}
+		if records[0]["total_tokens"] != float64(42) || records[0]["cost"] != 0.01 {
+			t.Errorf("expected the usage of the logs in %s, got %v", key, records[0])
+		}
+	}
+}
+
+// TestLogArchiver_UploadFailure tests that logs are kept in the log store when their upload fails
+func TestLogArchiver_UploadFailure(t *testing.T) {
+	store := &fakeLogArchiveStore{rows: testLogArchiveRows()}
+	archiver := newTestLogArchiver(t, store, 10, make(map[string][]map[string]interface{}))
+	archiver.upload = func(ctx context.Context, object objectLocation, file *os.File, size int64) (string, error) {
+		return "", fmt.Errorf("access denied")
+	}
+
+	if _, err := archiver.archive(context.Background()); err == nil {
+		t.Fatal("expected the archive to fail")
+	}
+	if len(store.rows) != 4 {
+		t.Errorf("expected the logs kept in the log store, got %d left", len(store.rows))
+	}
+}
+
+// TestLogArchiveConfig_Validate tests the validation of the log archive config
+func TestLogArchiveConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  LogArchiveConfig
+		wantErr bool
+	}{
+		{"valid", LogArchiveConfig{Type: ObjectStorageGCS, Bucket: "archive"}, false},
+		{"unknown type", LogArchiveConfig{Type: "azure", Bucket: "archive"}, true},
+		{"missing bucket", LogArchiveConfig{Type: ObjectStorageS3}, true},
+		{"negative days", LogArchiveConfig{Type: ObjectStorageS3, Bucket: "archive", ArchiveAfterDays: -1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package lib
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// Object storages files are uploaded to, with the credentials of the environment: the AWS default credential chain
+// for S3, and the application default credentials for Google Cloud Storage
+const (
+	ObjectStorageS3  = "s3"
+	ObjectStorageGCS = "gcs"
+)
+
+// gcsReadWriteScope is the OAuth scope of the uploads to Google Cloud Storage
+const gcsReadWriteScope = "https://www.googleapis.com/auth/devstorage.read_write"
+
+// objectLocation is the object of a bucket a file is uploaded to
+type objectLocation struct {
+	storage     string // One of the ObjectStorage* constants
+	bucket      string
+	key         string
+	region      string // Region of the S3 bucket, defaults to the region of the AWS environment
+	contentType string
+}
+
+// objectUploader uploads a file to its object and returns the URL of the object
+type objectUploader func(ctx context.Context, object objectLocation, file *os.File, size int64) (string, error)
+
+// uploadObject uploads the file to its S3 or Google Cloud Storage object
+func uploadObject(ctx context.Context, object objectLocation, file *os.File, size int64) (string, error) {
+	switch object.storage {
+	case ObjectStorageS3:
+		return uploadObjectS3(ctx, object, file, size)
+	case ObjectStorageGCS:
+		return uploadObjectGCS(ctx, object, file, size)
+	default:
+		return "", fmt.Errorf("unsupported object storage %s", object.storage)
+	}
+}
+
+// uploadObjectS3 puts the file in an S3 bucket with a request signed with the AWS default credential chain.
+// A single put is limited to 5GB by S3.
+func uploadObjectS3(ctx context.Context, object objectLocation, file *os.File, size int64) (string, error) {
+	options := []func(*awsconfig.LoadOptions) error{}
+	if object.region != "" {
+		options = append(options, awsconfig.WithRegion(object.region))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return "", fmt.Errorf("failed to load aws config: %v", err)
+	}
+	if cfg.Region == "" {
+		return "", fmt.Errorf("region is required when the environment sets no aws region")
+	}
+	creds, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve aws credentials: %v", err)
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to hash file: %v", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	payloadHash := hex.EncodeToString(hash.Sum(nil))
+
+	objectURL := fmt.Sprintf("https://%s.s3.%s.amazonaws.com%s", object.bucket, cfg.Region, (&url.URL{Path: "/" + object.key}).EscapedPath())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL, file)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", object.contentType)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if err := v4.NewSigner().SignHTTP(ctx, creds, req, payloadHash, "s3", cfg.Region, time.Now()); err != nil {
+		return "", fmt.Errorf("failed to sign request: %v", err)
+	}
+	if err := sendObjectUpload(http.DefaultClient, req); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("s3://%s/%s", object.bucket, object.key), nil
+}
+
+// uploadObjectGCS uploads the file to a Google Cloud Storage bucket with the application default credentials
+func uploadObjectGCS(ctx context.Context, object objectLocation, file *os.File, size int64) (string, error) {
+	tokenSource, err := google.DefaultTokenSource(ctx, gcsReadWriteScope)
+	if err != nil {
+		return "", fmt.Errorf("failed to find google credentials: %v", err)
+	}
+	uploadURL := fmt.Sprintf("https://storage.googleapis.com/upload/storage/v1/b/%s/o?uploadType=media&name=%s", url.PathEscape(object.bucket), url.QueryEscape(object.key))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, file)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", object.contentType)
+	if err := sendObjectUpload(oauth2.NewClient(ctx, tokenSource), req); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("gs://%s/%s", object.bucket, object.key), nil
+}
+
+// sendObjectUpload sends an upload request and returns the error of the bucket when it is not accepted
+func sendObjectUpload(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("upload failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
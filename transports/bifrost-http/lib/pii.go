@@ -0,0 +1,96 @@
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// piiPattern is a kind of personal information and the expression matching it
+type piiPattern struct {
+	label   string
+	pattern *regexp.Regexp
+	check   func(match string) bool // Optional check of the matches, rejecting the false positives of the expression
+}
+
+// defaultPIIPatterns are the personal information redacted by default, in the order they are applied so that card
+// numbers are not redacted as phone numbers
+var defaultPIIPatterns = []piiPattern{
+	{label: "EMAIL", pattern: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)},
+	{label: "CREDIT_CARD", pattern: regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`), check: luhnValid},
+	{label: "SSN", pattern: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)},
+	{label: "IP_ADDRESS", pattern: regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`)},
+	{label: "PHONE", pattern: regexp.MustCompile(`(?:\+\d{1,3}[ .\-]?)?(?:\(\d{2,4}\)[ .\-]?|\b\d{2,4}[ .\-])\d{3,4}[ .\-]?\d{3,4}\b`)},
+}
+
+// PIIRedactor replaces the personal information found in text with the label of its kind, e.g. "[EMAIL]"
+type PIIRedactor struct {
+	patterns []piiPattern
+}
+
+// NewPIIRedactor creates a redactor of the default patterns (emails, card numbers, social security numbers, IP
+// addresses and phone numbers) and of the extra regular expressions, keyed by the label they are replaced with.
+// The extra patterns are applied after the default ones, in label order.
+func NewPIIRedactor(extraPatterns map[string]string) (*PIIRedactor, error) {
+	redactor := &PIIRedactor{patterns: append([]piiPattern(nil), defaultPIIPatterns...)}
+	labels := make([]string, 0, len(extraPatterns))
+	for label := range extraPatterns {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		if label == "" {
+			return nil, fmt.Errorf("pii pattern label cannot be empty")
+		}
+		pattern, err := regexp.Compile(extraPatterns[label])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pii pattern %s: %v", label, err)
+		}
+		redactor.patterns = append(redactor.patterns, piiPattern{label: strings.ToUpper(label), pattern: pattern})
+	}
+	return redactor, nil
+}
+
+// Redact returns the text with its personal information replaced
+func (r *PIIRedactor) Redact(text string) string {
+	if text == "" {
+		return text
+	}
+	for _, p := range r.patterns {
+		replacement := "[" + p.label + "]"
+		if p.check == nil {
+			text = p.pattern.ReplaceAllString(text, replacement)
+			continue
+		}
+		text = p.pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if p.check(match) {
+				return replacement
+			}
+			return match
+		})
+	}
+	return text
+}
+
+// luhnValid checks the Luhn checksum of the digits of a card number, ignoring its separators
+func luhnValid(number string) bool {
+	sum := 0
+	digits := 0
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		digit := int(c - '0')
+		if digits%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		digits++
+	}
+	return digits >= 13 && sum%10 == 0
+}
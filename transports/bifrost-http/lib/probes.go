@@ -0,0 +1,730 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/leader"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultProbeSchedule is the interval between two runs of a probe when none is configured
+	DefaultProbeSchedule = 5 * time.Minute
+	// DefaultProbeTimeout is the maximum duration of a probe request when none is configured
+	DefaultProbeTimeout = 30 * time.Second
+	// DefaultProbeSLOTarget is the success ratio probes are expected to meet
+	DefaultProbeSLOTarget = 0.99
+	// DefaultProbeSLOWindow is the number of most recent runs the success ratio is computed over
+	DefaultProbeSLOWindow = 100
+	// DefaultProbeAlertAfter is the number of consecutive failures after which a probe alerts
+	DefaultProbeAlertAfter = 3
+	// DefaultProbeMinSimilarity is the similarity to the expected answer a response must reach
+	DefaultProbeMinSimilarity = 0.8
+	// DefaultProbeTrendRuns is the number of most recent runs compared with the earlier runs of the SLO window
+	DefaultProbeTrendRuns = 10
+	// DefaultProbeLatencyDegradation is the ratio of the recent latency over the earlier one above which a probe degrades
+	DefaultProbeLatencyDegradation = 1.5
+	// DefaultProbeSimilarityDegradation is the drop of the recent similarity from the earlier one above which a probe degrades
+	DefaultProbeSimilarityDegradation = 0.1
+	// defaultProbeMaxTokens keeps probe requests cheap
+	defaultProbeMaxTokens = 16
+)
+
+// Probe failure reasons reported in results and metrics
+const (
+	ProbeFailureRequest = "request_error"
+	ProbeFailureLatency = "latency_exceeded"
+	ProbeFailureContent = "content_mismatch"
+	// ProbeFailureSimilarity is reported when the response is not similar enough to the expected answer
+	ProbeFailureSimilarity = "similarity_below_threshold"
+)
+
+// ProbesConfig configures synthetic monitoring probes.
+// Probes periodically send a small chat completion through the full request path (governance,
+// key selection, fallbacks) so broken models or exhausted keys are detected before users hit them.
+// The latency and similarity of the most recent runs are compared with the earlier runs of the SLO
+// window, so that a provider answering slower or worse without failing is reported as degraded.
+type ProbesConfig struct {
+	Enabled               bool          `json:"enabled"`
+	SLOTarget             float64       `json:"slo_target,omitempty"`             // Expected success ratio, defaults to DefaultProbeSLOTarget
+	SLOWindow             int           `json:"slo_window,omitempty"`             // Number of most recent runs the success ratio is computed over, defaults to DefaultProbeSLOWindow
+	AlertAfter            int           `json:"alert_after,omitempty"`            // Consecutive failures after which a probe alerts, defaults to DefaultProbeAlertAfter
+	TrendRuns             int           `json:"trend_runs,omitempty"`             // Recent runs compared with the earlier runs of the SLO window, defaults to DefaultProbeTrendRuns
+	LatencyDegradation    float64       `json:"latency_degradation,omitempty"`    // Recent over earlier latency ratio above which a probe degrades, defaults to DefaultProbeLatencyDegradation
+	SimilarityDegradation float64       `json:"similarity_degradation,omitempty"` // Drop of the similarity above which a probe degrades, defaults to DefaultProbeSimilarityDegradation
+	Probes                []ProbeConfig `json:"probes"`
+}
+
+// ProbeConfig defines a single synthetic probe
+type ProbeConfig struct {
+	Name           string   `json:"name"`
+	Model          string   `json:"model,omitempty"`           // Model to probe in provider/model format
+	Models         []string `json:"models,omitempty"`          // Models to probe in provider/model format instead of model, each run separately
+	Prompt         string   `json:"prompt"`                    // User message sent to the model
+	VirtualKey     string   `json:"virtual_key,omitempty"`     // Virtual key the probe is sent with, so governance limits are exercised too
+	Schedule       string   `json:"schedule,omitempty"`        // Interval between two runs, e.g. "5m", defaults to DefaultProbeSchedule
+	Timeout        string   `json:"timeout,omitempty"`         // Maximum duration of the probe request, e.g. "30s", defaults to DefaultProbeTimeout
+	MaxLatencyMs   int      `json:"max_latency_ms,omitempty"`  // Runs slower than this fail, 0 disables the latency check
+	ExpectContains []string `json:"expect_contains,omitempty"` // Substrings the response must contain (case-insensitive)
+	ExpectRegex    string   `json:"expect_regex,omitempty"`    // Regular expression the response must match
+	MaxTokens      int      `json:"max_tokens,omitempty"`      // Maximum completion tokens, defaults to 16
+
+	ExpectSimilarTo string  `json:"expect_similar_to,omitempty"` // Expected answer the response is compared with by embedding similarity
+	MinSimilarity   float64 `json:"min_similarity,omitempty"`    // Cosine similarity to the expected answer the response must reach, defaults to DefaultProbeMinSimilarity
+	EmbeddingModel  string  `json:"embedding_model,omitempty"`   // Embedding model in provider/model format, required by expect_similar_to
+}
+
+// models returns the models the probe is sent to
+func (c *ProbeConfig) models() []string {
+	if len(c.Models) > 0 {
+		return c.Models
+	}
+	return []string{c.Model}
+}
+
+// Validate checks the probes config for missing or invalid fields
+func (c *ProbesConfig) Validate() error {
+	if c.SLOTarget < 0 || c.SLOTarget > 1 {
+		return fmt.Errorf("probes slo_target must be between 0 and 1")
+	}
+	if c.SLOWindow < 0 || c.AlertAfter < 0 || c.TrendRuns < 0 {
+		return fmt.Errorf("probes slo_window, alert_after and trend_runs cannot be negative")
+	}
+	if c.LatencyDegradation < 0 || c.SimilarityDegradation < 0 {
+		return fmt.Errorf("probes latency_degradation and similarity_degradation cannot be negative")
+	}
+	names := make(map[string]bool, len(c.Probes))
+	for i, probe := range c.Probes {
+		if probe.Name == "" {
+			return fmt.Errorf("probe %d requires a name", i)
+		}
+		if names[probe.Name] {
+			return fmt.Errorf("duplicate probe name %s", probe.Name)
+		}
+		names[probe.Name] = true
+		if probe.Model != "" && len(probe.Models) > 0 {
+			return fmt.Errorf("probe %s cannot set both model and models", probe.Name)
+		}
+		models := make(map[string]bool, len(probe.Models))
+		for _, model := range probe.models() {
+			if provider, model := schemas.ParseModelString(model, ""); provider == "" || model == "" {
+				return fmt.Errorf("probe %s requires models in provider/model format", probe.Name)
+			}
+			if models[model] {
+				return fmt.Errorf("duplicate model %s for probe %s", model, probe.Name)
+			}
+			models[model] = true
+		}
+		if probe.Prompt == "" {
+			return fmt.Errorf("probe %s requires a prompt", probe.Name)
+		}
+		if probe.Schedule != "" {
+			if d, err := time.ParseDuration(probe.Schedule); err != nil || d <= 0 {
+				return fmt.Errorf("invalid schedule for probe %s: %s", probe.Name, probe.Schedule)
+			}
+		}
+		if probe.Timeout != "" {
+			if d, err := time.ParseDuration(probe.Timeout); err != nil || d <= 0 {
+				return fmt.Errorf("invalid timeout for probe %s: %s", probe.Name, probe.Timeout)
+			}
+		}
+		if probe.MaxLatencyMs < 0 || probe.MaxTokens < 0 {
+			return fmt.Errorf("probe %s max_latency_ms and max_tokens cannot be negative", probe.Name)
+		}
+		if probe.ExpectRegex != "" {
+			if _, err := regexp.Compile(probe.ExpectRegex); err != nil {
+				return fmt.Errorf("invalid expect_regex for probe %s: %v", probe.Name, err)
+			}
+		}
+		if probe.MinSimilarity < 0 || probe.MinSimilarity > 1 {
+			return fmt.Errorf("probe %s min_similarity must be between 0 and 1", probe.Name)
+		}
+		if probe.ExpectSimilarTo != "" {
+			if provider, model := schemas.ParseModelString(probe.EmbeddingModel, ""); provider == "" || model == "" {
+				return fmt.Errorf("probe %s requires an embedding_model in provider/model format to check expect_similar_to", probe.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// ProbeResult is the outcome of a single probe run
+type ProbeResult struct {
+	Success       bool      `json:"success"`
+	FailureReason string    `json:"failure_reason,omitempty"` // One of the ProbeFailure* constants
+	Error         string    `json:"error,omitempty"`
+	LatencyMs     int64     `json:"latency_ms"`
+	Similarity    *float64  `json:"similarity,omitempty"` // Cosine similarity of the response to the expected answer
+	StartedAt     time.Time `json:"started_at"`
+}
+
+// ProbeTrend compares the most recent runs of a probe with the earlier runs of its SLO window
+type ProbeTrend struct {
+	RecentRuns         int      `json:"recent_runs"`
+	BaselineRuns       int      `json:"baseline_runs"`
+	RecentLatencyMs    float64  `json:"recent_latency_ms"`             // Mean latency of the recent successful runs
+	BaselineLatencyMs  float64  `json:"baseline_latency_ms"`           // Mean latency of the earlier successful runs
+	RecentSimilarity   *float64 `json:"recent_similarity,omitempty"`   // Mean similarity of the recent runs to the expected answer
+	BaselineSimilarity *float64 `json:"baseline_similarity,omitempty"` // Mean similarity of the earlier runs to the expected answer
+}
+
+// ProbeStatus is the current state of a probe, including its SLO and alerting state
+type ProbeStatus struct {
+	Name                string       `json:"name"`
+	Model               string       `json:"model"`
+	Runs                int          `json:"runs"`          // Runs within the SLO window
+	SuccessRatio        float64      `json:"success_ratio"` // Success ratio within the SLO window
+	SLOTarget           float64      `json:"slo_target"`
+	SLOMet              bool         `json:"slo_met"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	Alerting            bool         `json:"alerting"`
+	Trend               *ProbeTrend  `json:"trend,omitempty"`           // Set once the SLO window has enough runs to compare
+	Degraded            bool         `json:"degraded"`                  // The recent runs are slower or less similar than the earlier ones
+	DegradedReason      string       `json:"degraded_reason,omitempty"` // What degraded, e.g. "latency 2400ms over 900ms"
+	LastResult          *ProbeResult `json:"last_result,omitempty"`
+}
+
+// ProbeClient is the subset of the Bifrost client used to send probe requests, and to embed their responses
+type ProbeClient interface {
+	ChatCompletionRequest(ctx context.Context, req *schemas.BifrostChatRequest) (*schemas.BifrostChatResponse, *schemas.BifrostError)
+	EmbeddingClient
+}
+
+// probe is a validated probe definition with its parsed settings and run history
+type probe struct {
+	config   ProbeConfig
+	provider schemas.ModelProvider
+	model    string
+	schedule time.Duration
+	timeout  time.Duration
+	regex    *regexp.Regexp
+
+	embeddingProvider schemas.ModelProvider
+	embeddingModel    string
+	minSimilarity     float64
+
+	history             []ProbeResult // Ring buffer of the results within the SLO window
+	next                int
+	runs                int
+	consecutiveFailures int
+	alerting            bool
+	degraded            bool
+	degradedReason      string
+	lastResult          *ProbeResult
+}
+
+// probeMetrics are the Prometheus metrics probe results are exported as, for SLO dashboards and alert rules
+type probeMetrics struct {
+	runsTotal    *prometheus.CounterVec
+	latency      *prometheus.HistogramVec
+	up           *prometheus.GaugeVec
+	successRatio *prometheus.GaugeVec
+	similarity   *prometheus.GaugeVec
+	degraded     *prometheus.GaugeVec
+}
+
+// ProbeRunner runs the configured probes on their schedules and tracks their SLO and alerting state
+type ProbeRunner struct {
+	client                ProbeClient
+	sloTarget             float64
+	sloWindow             int
+	alertAfter            int
+	trendRuns             int
+	latencyDegradation    float64
+	similarityDegradation float64
+	metrics               *probeMetrics
+	elector               *leader.Elector // Elects the replica running the probes, nil runs them on every replica
+
+	mu     sync.RWMutex
+	probes []*probe
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewProbeRunner creates a probe runner for the given config.
+// If registerer is not nil, probe results are also exported as Prometheus metrics.
+func NewProbeRunner(config *ProbesConfig, client ProbeClient, registerer prometheus.Registerer) (*ProbeRunner, error) {
+	if config == nil {
+		return nil, fmt.Errorf("probes config is required")
+	}
+	if client == nil {
+		return nil, fmt.Errorf("bifrost client is required")
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	runner := &ProbeRunner{
+		client:                client,
+		sloTarget:             config.SLOTarget,
+		sloWindow:             config.SLOWindow,
+		alertAfter:            config.AlertAfter,
+		trendRuns:             config.TrendRuns,
+		latencyDegradation:    config.LatencyDegradation,
+		similarityDegradation: config.SimilarityDegradation,
+	}
+	if runner.sloTarget == 0 {
+		runner.sloTarget = DefaultProbeSLOTarget
+	}
+	if runner.sloWindow == 0 {
+		runner.sloWindow = DefaultProbeSLOWindow
+	}
+	if runner.alertAfter == 0 {
+		runner.alertAfter = DefaultProbeAlertAfter
+	}
+	if runner.trendRuns == 0 {
+		runner.trendRuns = DefaultProbeTrendRuns
+	}
+	if runner.latencyDegradation == 0 {
+		runner.latencyDegradation = DefaultProbeLatencyDegradation
+	}
+	if runner.similarityDegradation == 0 {
+		runner.similarityDegradation = DefaultProbeSimilarityDegradation
+	}
+	for _, probeConfig := range config.Probes {
+		// A probe of several models runs as one probe per model, with its own history
+		for _, model := range probeConfig.models() {
+			p := &probe{
+				config:        probeConfig,
+				schedule:      DefaultProbeSchedule,
+				timeout:       DefaultProbeTimeout,
+				minSimilarity: DefaultProbeMinSimilarity,
+				history:       make([]ProbeResult, runner.sloWindow),
+			}
+			p.config.Model = model
+			p.config.Models = nil
+			p.provider, p.model = schemas.ParseModelString(model, "")
+			if probeConfig.Schedule != "" {
+				p.schedule, _ = time.ParseDuration(probeConfig.Schedule)
+			}
+			if probeConfig.Timeout != "" {
+				p.timeout, _ = time.ParseDuration(probeConfig.Timeout)
+			}
+			if probeConfig.ExpectRegex != "" {
+				p.regex = regexp.MustCompile(probeConfig.ExpectRegex)
+			}
+			if probeConfig.ExpectSimilarTo != "" {
+				p.embeddingProvider, p.embeddingModel = schemas.ParseModelString(probeConfig.EmbeddingModel, "")
+			}
+			if probeConfig.MinSimilarity > 0 {
+				p.minSimilarity = probeConfig.MinSimilarity
+			}
+			runner.probes = append(runner.probes, p)
+		}
+	}
+	if registerer != nil {
+		metrics, err := newProbeMetrics(registerer)
+		if err != nil {
+			return nil, err
+		}
+		runner.metrics = metrics
+	}
+	return runner, nil
+}
+
+// newProbeMetrics creates and registers the probe metrics
+func newProbeMetrics(registerer prometheus.Registerer) (*probeMetrics, error) {
+	labels := []string{"probe", "provider", "model"}
+	metrics := &probeMetrics{
+		runsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "bifrost_probe_runs_total",
+			Help: "Total number of synthetic probe runs by result.",
+		}, append(labels, "result")),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "bifrost_probe_latency_seconds",
+			Help:    "Latency of synthetic probe runs.",
+			Buckets: []float64{.1, .25, .5, 1, 2.5, 5, 10, 15, 30, 60},
+		}, labels),
+		up: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "bifrost_probe_up",
+			Help: "Whether the last run of the synthetic probe succeeded (1) or failed (0).",
+		}, labels),
+		successRatio: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "bifrost_probe_success_ratio",
+			Help: "Success ratio of the synthetic probe within its SLO window.",
+		}, labels),
+		similarity: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "bifrost_probe_similarity",
+			Help: "Cosine similarity of the last response of the synthetic probe to its expected answer.",
+		}, labels),
+		degraded: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "bifrost_probe_degraded",
+			Help: "Whether the recent runs of the synthetic probe are slower or less similar than the earlier ones (1) or not (0).",
+		}, labels),
+	}
+	for _, collector := range []prometheus.Collector{metrics.runsTotal, metrics.latency, metrics.up, metrics.successRatio, metrics.similarity, metrics.degraded} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, fmt.Errorf("failed to register probe metrics: %v", err)
+		}
+	}
+	return metrics, nil
+}
+
+// SetElector sets the elector of the replica running the probes, so that the providers are probed once per cluster.
+// Must be called before Start.
+func (r *ProbeRunner) SetElector(elector *leader.Elector) {
+	r.elector = elector
+}
+
+// Start starts one goroutine per probe, each probe runs immediately and then on its schedule
+func (r *ProbeRunner) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Return early if already running
+	if r.stop != nil {
+		return
+	}
+	r.stop = make(chan struct{})
+	stopCh := r.stop
+	for _, p := range r.probes {
+		r.wg.Add(1)
+		go func(p *probe) {
+			defer r.wg.Done()
+			ticker := time.NewTicker(p.schedule)
+			defer ticker.Stop()
+			for {
+				if r.elector.IsLeader() {
+					r.runProbe(p)
+				}
+				select {
+				case <-ticker.C:
+				case <-stopCh:
+					return
+				}
+			}
+		}(p)
+	}
+	logger.Info("started %d synthetic probes", len(r.probes))
+}
+
+// Stop stops all probe goroutines and waits for in flight runs to finish
+func (r *ProbeRunner) Stop() {
+	r.mu.Lock()
+	if r.stop == nil {
+		r.mu.Unlock()
+		return
+	}
+	close(r.stop)
+	r.stop = nil
+	r.mu.Unlock()
+	r.wg.Wait()
+}
+
+// GetStatuses returns the current status of all probes
+func (r *ProbeRunner) GetStatuses() []ProbeStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	statuses := make([]ProbeStatus, 0, len(r.probes))
+	for _, p := range r.probes {
+		status := ProbeStatus{
+			Name:                p.config.Name,
+			Model:               p.config.Model,
+			Runs:                min(p.runs, len(p.history)),
+			SuccessRatio:        p.successRatio(),
+			SLOTarget:           r.sloTarget,
+			ConsecutiveFailures: p.consecutiveFailures,
+			Alerting:            p.alerting,
+			Trend:               p.trend(r.trendRuns),
+			Degraded:            p.degraded,
+			DegradedReason:      p.degradedReason,
+		}
+		status.SLOMet = status.Runs == 0 || status.SuccessRatio >= r.sloTarget
+		if p.lastResult != nil {
+			result := *p.lastResult
+			status.LastResult = &result
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// GetResults returns the results within the SLO window of the probes of the name, from the oldest to the latest.
+// A probe of several models has one list of results per model, model selects one of them when not empty.
+// The second return value is false when no probe matches.
+func (r *ProbeRunner) GetResults(name, model string) (map[string][]ProbeResult, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	results := make(map[string][]ProbeResult)
+	for _, p := range r.probes {
+		if p.config.Name == name && (model == "" || p.config.Model == model) {
+			results[p.config.Model] = p.results()
+		}
+	}
+	return results, len(results) > 0
+}
+
+// runProbe executes a single run of the probe and records its result
+func (r *ProbeRunner) runProbe(p *probe) {
+	result := r.executeProbe(p)
+
+	r.mu.Lock()
+	p.record(result)
+	p.lastResult = &result
+	wasAlerting := p.alerting
+	p.alerting = p.consecutiveFailures >= r.alertAfter
+	successRatio := p.successRatio()
+	wasDegraded := p.degraded
+	p.degradedReason = r.degradation(p.trend(r.trendRuns))
+	p.degraded = p.degradedReason != ""
+	r.mu.Unlock()
+
+	if p.alerting && !wasAlerting {
+		logger.Error("synthetic probe %s (%s) is failing: %d consecutive failures, last failure: %s %s", p.config.Name, p.config.Model, p.consecutiveFailures, result.FailureReason, result.Error)
+	} else if !p.alerting && wasAlerting {
+		logger.Info("synthetic probe %s (%s) recovered", p.config.Name, p.config.Model)
+	} else if !result.Success {
+		logger.Warn("synthetic probe %s (%s) failed: %s %s", p.config.Name, p.config.Model, result.FailureReason, result.Error)
+	}
+	if p.degraded && !wasDegraded {
+		logger.Error("synthetic probe %s (%s) is degrading: %s", p.config.Name, p.config.Model, p.degradedReason)
+	} else if !p.degraded && wasDegraded {
+		logger.Info("synthetic probe %s (%s) is no longer degrading", p.config.Name, p.config.Model)
+	}
+
+	if r.metrics != nil {
+		labels := []string{p.config.Name, string(p.provider), p.model}
+		outcome := "success"
+		up := 1.0
+		if !result.Success {
+			outcome = result.FailureReason
+			up = 0
+		}
+		r.metrics.runsTotal.WithLabelValues(append(labels, outcome)...).Inc()
+		r.metrics.latency.WithLabelValues(labels...).Observe(float64(result.LatencyMs) / 1000)
+		r.metrics.up.WithLabelValues(labels...).Set(up)
+		r.metrics.successRatio.WithLabelValues(labels...).Set(successRatio)
+		if result.Similarity != nil {
+			r.metrics.similarity.WithLabelValues(labels...).Set(*result.Similarity)
+		}
+		degraded := 0.0
+		if p.degraded {
+			degraded = 1
+		}
+		r.metrics.degraded.WithLabelValues(labels...).Set(degraded)
+	}
+}
+
+// executeProbe sends the probe request and checks the response against the probe's expectations
+func (r *ProbeRunner) executeProbe(p *probe) ProbeResult {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+	if p.config.VirtualKey != "" {
+		ctx = context.WithValue(ctx, schemas.BifrostContextKeyVirtualKey, p.config.VirtualKey)
+	}
+	maxTokens := p.config.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = defaultProbeMaxTokens
+	}
+
+	result := ProbeResult{StartedAt: time.Now()}
+	resp, bifrostErr := r.client.ChatCompletionRequest(ctx, &schemas.BifrostChatRequest{
+		Provider: p.provider,
+		Model:    p.model,
+		Input: []schemas.ChatMessage{
+			{
+				Role:    schemas.ChatMessageRoleUser,
+				Content: &schemas.ChatMessageContent{ContentStr: bifrost.Ptr(p.config.Prompt)},
+			},
+		},
+		Params: &schemas.ChatParameters{MaxCompletionTokens: bifrost.Ptr(maxTokens)},
+	})
+	latency := time.Since(result.StartedAt)
+	result.LatencyMs = latency.Milliseconds()
+
+	if bifrostErr != nil {
+		result.FailureReason = ProbeFailureRequest
+		result.Error = bifrost.GetErrorMessage(bifrostErr)
+		return result
+	}
+	if p.config.MaxLatencyMs > 0 && latency > time.Duration(p.config.MaxLatencyMs)*time.Millisecond {
+		result.FailureReason = ProbeFailureLatency
+		result.Error = fmt.Sprintf("latency %dms exceeds %dms", result.LatencyMs, p.config.MaxLatencyMs)
+		return result
+	}
+	content := strings.ToLower(getChatResponseText(resp))
+	for _, expected := range p.config.ExpectContains {
+		if !strings.Contains(content, strings.ToLower(expected)) {
+			result.FailureReason = ProbeFailureContent
+			result.Error = fmt.Sprintf("response does not contain %q", expected)
+			return result
+		}
+	}
+	if p.regex != nil && !p.regex.MatchString(getChatResponseText(resp)) {
+		result.FailureReason = ProbeFailureContent
+		result.Error = fmt.Sprintf("response does not match %q", p.config.ExpectRegex)
+		return result
+	}
+	if p.config.ExpectSimilarTo != "" {
+		similarity, err := r.similarity(ctx, p, getChatResponseText(resp))
+		if err != nil {
+			result.FailureReason = ProbeFailureRequest
+			result.Error = err.Error()
+			return result
+		}
+		result.Similarity = &similarity
+		if similarity < p.minSimilarity {
+			result.FailureReason = ProbeFailureSimilarity
+			result.Error = fmt.Sprintf("similarity %.3f to the expected answer is below %.3f", similarity, p.minSimilarity)
+			return result
+		}
+	}
+	result.Success = true
+	return result
+}
+
+// similarity embeds the response with the expected answer of the probe, and returns their cosine similarity
+func (r *ProbeRunner) similarity(ctx context.Context, p *probe, response string) (float64, error) {
+	resp, bifrostErr := r.client.EmbeddingRequest(ctx, &schemas.BifrostEmbeddingRequest{
+		Provider: p.embeddingProvider,
+		Model:    p.embeddingModel,
+		Input:    &schemas.EmbeddingInput{Texts: []string{p.config.ExpectSimilarTo, response}},
+	})
+	if bifrostErr != nil {
+		return 0, fmt.Errorf("failed to embed the response: %s", bifrost.GetErrorMessage(bifrostErr))
+	}
+	embeddings, err := embeddingsFromResponse(resp, 2)
+	if err != nil {
+		return 0, fmt.Errorf("failed to embed the response: %v", err)
+	}
+	return cosineSimilarity(embeddings[0], embeddings[1]), nil
+}
+
+// cosineSimilarity returns the cosine similarity of two embeddings, 0 when they differ in size or one is empty
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// record adds a result to the probe's SLO window, the caller must hold the runner lock
+func (p *probe) record(result ProbeResult) {
+	p.history[p.next] = result
+	p.next = (p.next + 1) % len(p.history)
+	p.runs++
+	if result.Success {
+		p.consecutiveFailures = 0
+	} else {
+		p.consecutiveFailures++
+	}
+}
+
+// successRatio returns the success ratio within the SLO window, the caller must hold the runner lock
+func (p *probe) successRatio() float64 {
+	runs := min(p.runs, len(p.history))
+	if runs == 0 {
+		return 0
+	}
+	successes := 0
+	for i := 0; i < runs; i++ {
+		if p.history[i].Success {
+			successes++
+		}
+	}
+	return float64(successes) / float64(runs)
+}
+
+// results returns the results within the SLO window from the oldest to the latest, the caller must hold the runner lock
+func (p *probe) results() []ProbeResult {
+	runs := min(p.runs, len(p.history))
+	results := make([]ProbeResult, 0, runs)
+	for i := p.next - runs; i < p.next; i++ {
+		results = append(results, p.history[(i+len(p.history))%len(p.history)])
+	}
+	return results
+}
+
+// trend compares the latest recentRuns runs with the earlier runs of the SLO window. It returns nil until the window
+// has at least recentRuns earlier runs. The caller must hold the runner lock.
+func (p *probe) trend(recentRuns int) *ProbeTrend {
+	results := p.results()
+	if len(results) < 2*recentRuns {
+		return nil
+	}
+	baseline, recent := results[:len(results)-recentRuns], results[len(results)-recentRuns:]
+	trend := &ProbeTrend{RecentRuns: len(recent), BaselineRuns: len(baseline)}
+	trend.RecentLatencyMs, trend.RecentSimilarity = probeAverages(recent)
+	trend.BaselineLatencyMs, trend.BaselineSimilarity = probeAverages(baseline)
+	return trend
+}
+
+// probeAverages returns the mean latency of the successful results, and the mean similarity of the results that have one
+func probeAverages(results []ProbeResult) (float64, *float64) {
+	var latency, similarity float64
+	var successes, similarities int
+	for _, result := range results {
+		if result.Success {
+			latency += float64(result.LatencyMs)
+			successes++
+		}
+		if result.Similarity != nil {
+			similarity += *result.Similarity
+			similarities++
+		}
+	}
+	if successes > 0 {
+		latency /= float64(successes)
+	}
+	if similarities == 0 {
+		return latency, nil
+	}
+	similarity /= float64(similarities)
+	return latency, &similarity
+}
+
+// degradation returns why the recent runs of a trend are slower or less similar than the earlier ones, empty when
+// they are not or the trend is nil
+func (r *ProbeRunner) degradation(trend *ProbeTrend) string {
+	if trend == nil {
+		return ""
+	}
+	var reasons []string
+	if trend.BaselineLatencyMs > 0 && trend.RecentLatencyMs > trend.BaselineLatencyMs*r.latencyDegradation {
+		reasons = append(reasons, fmt.Sprintf("latency %.0fms over %.0fms", trend.RecentLatencyMs, trend.BaselineLatencyMs))
+	}
+	if trend.RecentSimilarity != nil && trend.BaselineSimilarity != nil && *trend.BaselineSimilarity-*trend.RecentSimilarity > r.similarityDegradation {
+		reasons = append(reasons, fmt.Sprintf("similarity %.3f under %.3f", *trend.RecentSimilarity, *trend.BaselineSimilarity))
+	}
+	return strings.Join(reasons, ", ")
+}
+
+// getChatResponseText returns the text content of the first choice of a chat response
+func getChatResponseText(resp *schemas.BifrostChatResponse) string {
+	if resp == nil || len(resp.Choices) == 0 {
+		return ""
+	}
+	choice := resp.Choices[0]
+	if choice.ChatNonStreamResponseChoice == nil || choice.ChatNonStreamResponseChoice.Message == nil || choice.ChatNonStreamResponseChoice.Message.Content == nil {
+		return ""
+	}
+	content := choice.ChatNonStreamResponseChoice.Message.Content
+	if content.ContentStr != nil {
+		return *content.ContentStr
+	}
+	var builder strings.Builder
+	for _, block := range content.ContentBlocks {
+		if block.Text != nil {
+			builder.WriteString(*block.Text)
+		}
+	}
+	return builder.String()
+}
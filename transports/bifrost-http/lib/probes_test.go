@@ -0,0 +1,266 @@
+package lib
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+func init() {
+	// Setup test logger, probe runs log their failures and alerts
+	if logger == nil {
+		SetLogger(bifrost.NewDefaultLogger(schemas.LogLevelError))
+	}
+}
+
+// fakeProbeClient answers probe requests with a fixed text or error after an optional delay, and embeds texts with
+// the vectors of its embeddings, [1, 0] by default
+type fakeProbeClient struct {
+	mu         sync.Mutex
+	text       string
+	err        *schemas.BifrostError
+	delay      time.Duration
+	embeddings map[string][]float32
+	requests   []*schemas.BifrostChatRequest
+	vks        []any
+}
+
+func (c *fakeProbeClient) ChatCompletionRequest(ctx context.Context, req *schemas.BifrostChatRequest) (*schemas.BifrostChatResponse, *schemas.BifrostError) {
+	c.mu.Lock()
+	c.requests = append(c.requests, req)
+	c.vks = append(c.vks, ctx.Value(schemas.BifrostContextKeyVirtualKey))
+	text, err, delay := c.text, c.err, c.delay
+	c.mu.Unlock()
+	time.Sleep(delay)
+	if err != nil {
+		return nil, err
+	}
+	return &schemas.BifrostChatResponse{
+		Choices: []schemas.BifrostResponseChoice{
+			{
+				ChatNonStreamResponseChoice: &schemas.ChatNonStreamResponseChoice{
+					Message: &schemas.ChatMessage{
+						Role:    schemas.ChatMessageRoleAssistant,
+						Content: &schemas.ChatMessageContent{ContentStr: bifrost.Ptr(text)},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func (c *fakeProbeClient) EmbeddingRequest(ctx context.Context, req *schemas.BifrostEmbeddingRequest) (*schemas.BifrostEmbeddingResponse, *schemas.BifrostError) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	resp := &schemas.BifrostEmbeddingResponse{}
+	for i, text := range req.Input.Texts {
+		embedding, ok := c.embeddings[text]
+		if !ok {
+			embedding = []float32{1, 0}
+		}
+		resp.Data = append(resp.Data, schemas.EmbeddingData{Index: i, Embedding: schemas.EmbeddingStruct{EmbeddingArray: embedding}})
+	}
+	return resp, nil
+}
+
+func newTestProbeRunner(t *testing.T, client ProbeClient, probe ProbeConfig) *ProbeRunner {
+	t.Helper()
+	runner, err := NewProbeRunner(&ProbesConfig{Enabled: true, AlertAfter: 2, SLOWindow: 4, Probes: []ProbeConfig{probe}}, client, nil)
+	if err != nil {
+		t.Fatalf("failed to create probe runner: %v", err)
+	}
+	return runner
+}
+
+// TestProbeRunner_ContentAndLatencyChecks tests that probe responses are checked against their expectations
+func TestProbeRunner_ContentAndLatencyChecks(t *testing.T) {
+	client := &fakeProbeClient{text: "The answer is PONG."}
+	probe := ProbeConfig{
+		Name:           "openai-ping",
+		Model:          "openai/gpt-4o-mini",
+		Prompt:         "Reply with pong",
+		VirtualKey:     "sk-bf-probe",
+		ExpectContains: []string{"pong"},
+		ExpectRegex:    `(?i)\bpong\b`,
+		MaxLatencyMs:   1000,
+	}
+	runner := newTestProbeRunner(t, client, probe)
+
+	result := runner.executeProbe(runner.probes[0])
+	if !result.Success {
+		t.Fatalf("expected probe to succeed, got %s: %s", result.FailureReason, result.Error)
+	}
+	if req := client.requests[0]; req.Provider != schemas.OpenAI || req.Model != "gpt-4o-mini" {
+		t.Errorf("expected request to openai/gpt-4o-mini, got %s/%s", req.Provider, req.Model)
+	}
+	if client.vks[0] != "sk-bf-probe" {
+		t.Errorf("expected probe to be sent with its virtual key, got %v", client.vks[0])
+	}
+
+	client.text = "something else"
+	if result := runner.executeProbe(runner.probes[0]); result.FailureReason != ProbeFailureContent {
+		t.Errorf("expected content mismatch, got %+v", result)
+	}
+
+	client.text = "pong"
+	client.delay = 20 * time.Millisecond
+	runner.probes[0].config.MaxLatencyMs = 5
+	if result := runner.executeProbe(runner.probes[0]); result.FailureReason != ProbeFailureLatency {
+		t.Errorf("expected latency failure, got %+v", result)
+	}
+
+	client.delay = 0
+	client.err = &schemas.BifrostError{Error: &schemas.ErrorField{Message: "budget exceeded"}}
+	if result := runner.executeProbe(runner.probes[0]); result.FailureReason != ProbeFailureRequest || result.Error != "budget exceeded" {
+		t.Errorf("expected request failure, got %+v", result)
+	}
+}
+
+// TestProbeRunner_SLOAndAlerting tests the SLO window and the alerting state transitions
+func TestProbeRunner_SLOAndAlerting(t *testing.T) {
+	client := &fakeProbeClient{text: "pong"}
+	runner := newTestProbeRunner(t, client, ProbeConfig{Name: "ping", Model: "openai/gpt-4o-mini", Prompt: "ping"})
+	probe := runner.probes[0]
+
+	runner.runProbe(probe)
+	client.err = &schemas.BifrostError{Error: &schemas.ErrorField{Message: "no keys available"}}
+	runner.runProbe(probe)
+
+	status := runner.GetStatuses()[0]
+	if status.Alerting {
+		t.Error("expected no alert after a single failure")
+	}
+	if status.SuccessRatio != 0.5 || status.SLOMet {
+		t.Errorf("expected success ratio 0.5 below the SLO, got %v (met: %v)", status.SuccessRatio, status.SLOMet)
+	}
+
+	runner.runProbe(probe)
+	status = runner.GetStatuses()[0]
+	if !status.Alerting || status.ConsecutiveFailures != 2 {
+		t.Errorf("expected alert after 2 consecutive failures, got %+v", status)
+	}
+
+	// The SLO window only keeps the 4 most recent runs
+	client.err = nil
+	for range 4 {
+		runner.runProbe(probe)
+	}
+	status = runner.GetStatuses()[0]
+	if status.Alerting || status.Runs != 4 || status.SuccessRatio != 1 {
+		t.Errorf("expected recovered probe with a full window of successes, got %+v", status)
+	}
+}
+
+// TestProbesConfig_Validate tests the validation of probe definitions
+func TestProbesConfig_Validate(t *testing.T) {
+	tests := map[string]ProbeConfig{
+		"missing provider": {Name: "a", Model: "gpt-4o", Prompt: "ping"},
+		"missing prompt":   {Name: "a", Model: "openai/gpt-4o"},
+		"invalid schedule": {Name: "a", Model: "openai/gpt-4o", Prompt: "ping", Schedule: "often"},
+		"invalid regex":    {Name: "a", Model: "openai/gpt-4o", Prompt: "ping", ExpectRegex: "("},
+	}
+	for name, probe := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := &ProbesConfig{Probes: []ProbeConfig{probe}}
+			if err := config.Validate(); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+	valid := &ProbesConfig{Probes: []ProbeConfig{{Name: "a", Model: "openai/gpt-4o", Prompt: "ping", Schedule: "1m"}}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("expected valid config, got: %v", err)
+	}
+}
+
+// TestProbeRunner_SimilarityCheck tests that responses are compared with the expected answer by embedding similarity
+func TestProbeRunner_SimilarityCheck(t *testing.T) {
+	client := &fakeProbeClient{
+		text:       "Paris",
+		embeddings: map[string][]float32{"Paris": {1, 0.1}, "Lyon": {0, 1}},
+	}
+	runner := newTestProbeRunner(t, client, ProbeConfig{
+		Name:            "capital",
+		Model:           "openai/gpt-4o-mini",
+		Prompt:          "What is the capital of France?",
+		ExpectSimilarTo: "The capital of France is Paris.",
+		EmbeddingModel:  "openai/text-embedding-3-small",
+		MinSimilarity:   0.9,
+	})
+
+	result := runner.executeProbe(runner.probes[0])
+	if !result.Success || result.Similarity == nil || *result.Similarity < 0.9 {
+		t.Fatalf("expected similar response to succeed, got %+v", result)
+	}
+
+	client.text = "Lyon"
+	result = runner.executeProbe(runner.probes[0])
+	if result.FailureReason != ProbeFailureSimilarity || result.Similarity == nil || *result.Similarity != 0 {
+		t.Errorf("expected similarity failure, got %+v", result)
+	}
+}
+
+// TestProbeRunner_Degradation tests that probes whose recent runs are slower or less similar than the earlier runs
+// are reported as degraded while they keep succeeding
+func TestProbeRunner_Degradation(t *testing.T) {
+	client := &fakeProbeClient{text: "pong"}
+	runner, err := NewProbeRunner(&ProbesConfig{
+		SLOWindow: 6,
+		TrendRuns: 2,
+		Probes: []ProbeConfig{{
+			Name:   "ping",
+			Models: []string{"openai/gpt-4o-mini", "anthropic/claude-3-5-haiku"},
+			Prompt: "ping",
+		}},
+	}, client, nil)
+	if err != nil {
+		t.Fatalf("failed to create probe runner: %v", err)
+	}
+	if len(runner.probes) != 2 || runner.probes[1].provider != schemas.Anthropic {
+		t.Fatalf("expected one probe per model, got %d", len(runner.probes))
+	}
+	probe := runner.probes[0]
+
+	record := func(latencyMs int64, similarity float64) {
+		result := ProbeResult{Success: true, LatencyMs: latencyMs, Similarity: bifrost.Ptr(similarity)}
+		probe.record(result)
+		probe.degradedReason = runner.degradation(probe.trend(runner.trendRuns))
+		probe.degraded = probe.degradedReason != ""
+	}
+	for range 3 {
+		record(100, 0.95)
+	}
+	if status := runner.GetStatuses()[0]; status.Trend != nil || status.Degraded {
+		t.Fatalf("expected no trend before the window holds enough runs, got %+v", status)
+	}
+
+	record(110, 0.94)
+	status := runner.GetStatuses()[0]
+	if status.Trend == nil || status.Degraded {
+		t.Fatalf("expected a stable trend, got %+v", status)
+	}
+
+	record(300, 0.94)
+	record(300, 0.94)
+	status = runner.GetStatuses()[0]
+	if !status.Degraded || status.Trend.RecentLatencyMs != 300 {
+		t.Errorf("expected latency degradation, got %+v", status)
+	}
+
+	for range 4 {
+		record(300, 0.5)
+	}
+	status = runner.GetStatuses()[0]
+	if !status.Degraded || status.DegradedReason == "" || *status.Trend.RecentSimilarity != 0.5 {
+		t.Errorf("expected similarity degradation, got %+v", status)
+	}
+
+	results, ok := runner.GetResults("ping", "openai/gpt-4o-mini")
+	if !ok || len(results["openai/gpt-4o-mini"]) != 6 || results["openai/gpt-4o-mini"][5].Similarity == nil {
+		t.Errorf("expected the 6 results of the window, got %+v", results)
+	}
+}
@@ -0,0 +1,80 @@
+package lib
+
+import (
+	"fmt"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// sloCollector exports the compliance of the SLO objectives of the core, computed when the registry is scraped
+type sloCollector struct {
+	status          func() []schemas.SLOStatus
+	requests        *prometheus.Desc
+	compliance      *prometheus.Desc
+	burnRate        *prometheus.Desc
+	budgetRemaining *prometheus.Desc
+	burning         *prometheus.Desc
+}
+
+// RegisterSLOMetrics exports the compliance, burn rate and remaining error budget of the SLO objectives returned by
+// status on the registerer. The gauges of an objective are labelled with its name, and the availability and latency
+// gauges with the sli they measure.
+func RegisterSLOMetrics(status func() []schemas.SLOStatus, registerer prometheus.Registerer) error {
+	if registerer == nil {
+		return nil
+	}
+	sliLabels := []string{"objective", "provider", "model", "sli"}
+	collector := &sloCollector{
+		status: status,
+		requests: prometheus.NewDesc("bifrost_slo_window_requests",
+			"Requests counted by the objective over its rolling window.", []string{"objective", "provider", "model"}, nil),
+		compliance: prometheus.NewDesc("bifrost_slo_compliance_ratio",
+			"Share of the requests of the rolling window meeting the objective.", sliLabels, nil),
+		burnRate: prometheus.NewDesc("bifrost_slo_error_budget_burn_rate",
+			"Rate at which the requests of the rolling window consume the error budget of the objective, 1 uses it up exactly.", sliLabels, nil),
+		budgetRemaining: prometheus.NewDesc("bifrost_slo_error_budget_remaining_ratio",
+			"Share of the error budget of the objective left over the rolling window, negative once exceeded.", sliLabels, nil),
+		burning: prometheus.NewDesc("bifrost_slo_burning",
+			"1 when the objective burns its error budget faster than the burn rate threshold.", []string{"objective", "provider", "model"}, nil),
+	}
+	if err := registerer.Register(collector); err != nil {
+		return fmt.Errorf("failed to register slo metrics: %v", err)
+	}
+	return nil
+}
+
+// Describe implements prometheus.Collector
+func (c *sloCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.requests
+	ch <- c.compliance
+	ch <- c.burnRate
+	ch <- c.budgetRemaining
+	ch <- c.burning
+}
+
+// Collect implements prometheus.Collector
+func (c *sloCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, status := range c.status() {
+		objective := status.Objective
+		labels := []string{objective.Name, string(objective.Provider), objective.Model}
+		ch <- prometheus.MustNewConstMetric(c.requests, prometheus.GaugeValue, float64(status.Requests), labels...)
+		burning := 0.0
+		if status.Burning {
+			burning = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.burning, prometheus.GaugeValue, burning, labels...)
+		c.collectSLI(ch, append(labels, "availability"), status.AvailabilityCompliance, status.AvailabilityBurnRate, status.AvailabilityBudgetRemaining)
+		c.collectSLI(ch, append(labels, "latency"), status.LatencyCompliance, status.LatencyBurnRate, status.LatencyBudgetRemaining)
+	}
+}
+
+// collectSLI exports the gauges of an sli of an objective, which are nil until the window has requests for it
+func (c *sloCollector) collectSLI(ch chan<- prometheus.Metric, labels []string, compliance, burnRate, budgetRemaining *float64) {
+	if compliance == nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.compliance, prometheus.GaugeValue, *compliance, labels...)
+	ch <- prometheus.MustNewConstMetric(c.burnRate, prometheus.GaugeValue, *burnRate, labels...)
+	ch <- prometheus.MustNewConstMetric(c.budgetRemaining, prometheus.GaugeValue, *budgetRemaining, labels...)
+}
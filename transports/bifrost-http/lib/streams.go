@@ -0,0 +1,131 @@
+package lib
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// charsPerToken is the average number of characters of a token, used to estimate the tokens of streamed text
+const charsPerToken = 4
+
+// StreamUsage tracks what a stream delivered to its client, to account for the generation wasted when the client disconnects
+type StreamUsage struct {
+	Route                 string
+	Provider              schemas.ModelProvider
+	Model                 string
+	Chunks                int
+	EstimatedOutputTokens int // Output tokens of the delivered chunks, from the usage of the provider when it was sent, estimated from the text otherwise
+	StartedAt             time.Time
+
+	chars int
+}
+
+// NewStreamUsage starts tracking a stream of the given route
+func NewStreamUsage(route string) *StreamUsage {
+	return &StreamUsage{Route: route, StartedAt: time.Now()}
+}
+
+// Add accounts for a chunk delivered to the client
+func (u *StreamUsage) Add(chunk *schemas.BifrostStream) {
+	if chunk == nil {
+		return
+	}
+	u.Chunks++
+
+	var extraFields *schemas.BifrostResponseExtraFields
+	var usage *schemas.BifrostLLMUsage
+	switch {
+	case chunk.BifrostChatResponse != nil:
+		extraFields = &chunk.BifrostChatResponse.ExtraFields
+		usage = chunk.BifrostChatResponse.Usage
+		for _, choice := range chunk.BifrostChatResponse.Choices {
+			if choice.ChatStreamResponseChoice == nil || choice.Delta == nil {
+				continue
+			}
+			for _, text := range []*string{choice.Delta.Content, choice.Delta.Thought, choice.Delta.Refusal} {
+				if text != nil {
+					u.chars += len(*text)
+				}
+			}
+			for _, toolCall := range choice.Delta.ToolCalls {
+				u.chars += len(toolCall.Function.Arguments)
+			}
+		}
+	case chunk.BifrostTextCompletionResponse != nil:
+		extraFields = &chunk.BifrostTextCompletionResponse.ExtraFields
+		usage = chunk.BifrostTextCompletionResponse.Usage
+		for _, choice := range chunk.BifrostTextCompletionResponse.Choices {
+			if choice.TextCompletionResponseChoice != nil && choice.Text != nil {
+				u.chars += len(*choice.Text)
+			}
+		}
+	case chunk.BifrostResponsesStreamResponse != nil:
+		extraFields = &chunk.BifrostResponsesStreamResponse.ExtraFields
+		if chunk.BifrostResponsesStreamResponse.Delta != nil {
+			u.chars += len(*chunk.BifrostResponsesStreamResponse.Delta)
+		}
+	case chunk.BifrostTranscriptionStreamResponse != nil:
+		extraFields = &chunk.BifrostTranscriptionStreamResponse.ExtraFields
+		if chunk.BifrostTranscriptionStreamResponse.Delta != nil {
+			u.chars += len(*chunk.BifrostTranscriptionStreamResponse.Delta)
+		}
+	case chunk.BifrostSpeechStreamResponse != nil:
+		extraFields = &chunk.BifrostSpeechStreamResponse.ExtraFields
+	}
+	if extraFields != nil && extraFields.Provider != "" {
+		u.Provider, u.Model = extraFields.Provider, extraFields.ModelRequested
+	}
+
+	if usage != nil && usage.CompletionTokens > 0 {
+		u.EstimatedOutputTokens = usage.CompletionTokens
+		return
+	}
+	u.EstimatedOutputTokens = (u.chars + charsPerToken - 1) / charsPerToken
+}
+
+// StreamCancellations accounts for the streams cancelled because their client disconnected.
+// Each cancellation is logged, and counted in Prometheus metrics when a registerer is given.
+type StreamCancellations struct {
+	streamsTotal *prometheus.CounterVec
+	tokensTotal  *prometheus.CounterVec
+}
+
+// NewStreamCancellations creates the cancellation accounting.
+// If registerer is not nil, cancellations are also exported as Prometheus metrics.
+func NewStreamCancellations(registerer prometheus.Registerer) (*StreamCancellations, error) {
+	c := &StreamCancellations{}
+	if registerer == nil {
+		return c, nil
+	}
+	labels := []string{"route", "provider", "model"}
+	c.streamsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "bifrost_client_cancelled_streams_total",
+		Help: "Total number of streams cancelled because the client disconnected before the end of the generation.",
+	}, labels)
+	c.tokensTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "bifrost_client_cancelled_stream_output_tokens_total",
+		Help: "Estimated output tokens generated for streams whose client disconnected before the end of the generation.",
+	}, labels)
+	for _, collector := range []prometheus.Collector{c.streamsTotal, c.tokensTotal} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, fmt.Errorf("failed to register stream cancellation metrics: %v", err)
+		}
+	}
+	return c, nil
+}
+
+// RecordClientDisconnect accounts for a stream whose upstream request was cancelled because its client disconnected.
+// It is safe to call on a nil StreamCancellations, the cancellation is then only logged.
+func (c *StreamCancellations) RecordClientDisconnect(requestID string, usage *StreamUsage) {
+	logger.Info("client disconnected from stream %s on %s (%s/%s) after %d chunks and %s, cancelled upstream request with ~%d output tokens generated",
+		requestID, usage.Route, usage.Provider, usage.Model, usage.Chunks, time.Since(usage.StartedAt).Round(time.Millisecond), usage.EstimatedOutputTokens)
+	if c == nil || c.streamsTotal == nil {
+		return
+	}
+	labelValues := []string{usage.Route, string(usage.Provider), usage.Model}
+	c.streamsTotal.WithLabelValues(labelValues...).Inc()
+	c.tokensTotal.WithLabelValues(labelValues...).Add(float64(usage.EstimatedOutputTokens))
+}
@@ -0,0 +1,63 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// chatChunk creates a chat stream chunk with the given content
+func chatChunk(content string, usage *schemas.BifrostLLMUsage) *schemas.BifrostStream {
+	return &schemas.BifrostStream{BifrostChatResponse: &schemas.BifrostChatResponse{
+		Choices: []schemas.BifrostResponseChoice{{
+			ChatStreamResponseChoice: &schemas.ChatStreamResponseChoice{Delta: &schemas.ChatStreamResponseChoiceDelta{Content: &content}},
+		}},
+		Usage:       usage,
+		ExtraFields: schemas.BifrostResponseExtraFields{Provider: schemas.OpenAI, ModelRequested: "gpt-4o"},
+	}}
+}
+
+// TestStreamUsage_EstimatesOutputTokens tests that output tokens are estimated from the text until the provider sends its usage
+func TestStreamUsage_EstimatesOutputTokens(t *testing.T) {
+	usage := NewStreamUsage("/v1/chat/completions")
+	usage.Add(chatChunk("Hello", nil))
+	usage.Add(chatChunk(" world!", nil))
+	if usage.Chunks != 2 || usage.EstimatedOutputTokens != 3 {
+		t.Errorf("expected 2 chunks and 3 estimated tokens, got %d chunks and %d tokens", usage.Chunks, usage.EstimatedOutputTokens)
+	}
+	if usage.Provider != schemas.OpenAI || usage.Model != "gpt-4o" {
+		t.Errorf("expected openai/gpt-4o, got %s/%s", usage.Provider, usage.Model)
+	}
+
+	usage.Add(chatChunk("", &schemas.BifrostLLMUsage{CompletionTokens: 5}))
+	if usage.EstimatedOutputTokens != 5 {
+		t.Errorf("expected the usage of the provider to replace the estimate, got %d tokens", usage.EstimatedOutputTokens)
+	}
+}
+
+// TestStreamCancellations_RecordClientDisconnect tests that cancelled streams and their tokens are counted
+func TestStreamCancellations_RecordClientDisconnect(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	cancellations, err := NewStreamCancellations(registry)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	usage := NewStreamUsage("/v1/chat/completions")
+	usage.Add(chatChunk("Once upon a time", nil))
+	cancellations.RecordClientDisconnect("request-1", usage)
+	cancellations.RecordClientDisconnect("request-2", usage)
+
+	if got := testutil.ToFloat64(cancellations.streamsTotal.WithLabelValues("/v1/chat/completions", "openai", "gpt-4o")); got != 2 {
+		t.Errorf("expected 2 cancelled streams, got %v", got)
+	}
+	if got := testutil.ToFloat64(cancellations.tokensTotal.WithLabelValues("/v1/chat/completions", "openai", "gpt-4o")); got != 8 {
+		t.Errorf("expected 8 wasted tokens, got %v", got)
+	}
+
+	// Without metrics, cancellations are only logged
+	var unregistered *StreamCancellations
+	unregistered.RecordClientDisconnect("request-3", usage)
+}
@@ -0,0 +1,787 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	bifrost "github.com/maximhq/bifrost/core"
+	providerUtils "github.com/maximhq/bifrost/core/providers/utils"
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+const (
+	// DefaultTranscriptionChunkSeconds is the duration of audio sent per transcription request when none is configured,
+	// 10 minutes of 16kHz mono audio stays under the 25MB upload limit of OpenAI
+	DefaultTranscriptionChunkSeconds = 600
+	// DefaultTranscriptionJobConcurrency is the number of chunks of a job transcribed in parallel when none is configured
+	DefaultTranscriptionJobConcurrency = 4
+	// DefaultTranscriptionMaxFileSizeMB is the maximum size of an uploaded file when none is configured
+	DefaultTranscriptionMaxFileSizeMB = 2048
+	// maxTranscriptionJobErrors caps the errors kept on a job, the failed count keeps the full total
+	maxTranscriptionJobErrors = 20
+	// maxFinishedTranscriptionJobs is the number of finished jobs kept in memory with their transcripts
+	maxFinishedTranscriptionJobs = 100
+	// transcriptionUploadTTL is how long an upload no job was started from is kept after its last part
+	transcriptionUploadTTL = time.Hour
+	// transcriptionCutSearch is how far before a chunk boundary the quietest point is searched for,
+	// so that chunks are not cut in the middle of a word
+	transcriptionCutSearch = 5 * time.Second
+	// transcriptionCutFrame is the length of the frames compared when searching for the quietest point
+	transcriptionCutFrame = 50 * time.Millisecond
+)
+
+// Transcription job statuses
+const (
+	TranscriptionJobPending   = "pending"
+	TranscriptionJobRunning   = "running"
+	TranscriptionJobCompleted = "completed"
+	TranscriptionJobFailed    = "failed"
+	TranscriptionJobCancelled = "cancelled"
+)
+
+// ErrUploadOffsetMismatch is returned when an upload part does not start at the current size of the upload
+var ErrUploadOffsetMismatch = errors.New("upload offset does not match the uploaded size")
+
+// TranscriptionJobsConfig configures async transcription jobs.
+// Jobs accept files larger than the limits of the providers, split them into chunks transcribed in parallel
+// and stitch the transcripts back together with their timestamps.
+type TranscriptionJobsConfig struct {
+	Enabled       bool   `json:"enabled"`
+	ChunkSeconds  int    `json:"chunk_seconds,omitempty"`    // Audio per transcription request, defaults to DefaultTranscriptionChunkSeconds
+	Concurrency   int    `json:"concurrency,omitempty"`      // Chunks of a job transcribed in parallel, defaults to DefaultTranscriptionJobConcurrency
+	MaxFileSizeMB int    `json:"max_file_size_mb,omitempty"` // Maximum size of an uploaded file, defaults to DefaultTranscriptionMaxFileSizeMB
+	FFmpegPath    string `json:"ffmpeg_path,omitempty"`      // ffmpeg binary converting audio that is not 16-bit PCM WAV, defaults to ffmpeg on the PATH
+	TempDir       string `json:"temp_dir,omitempty"`         // Directory uploads are stored in, defaults to the system temp directory
+}
+
+// Validate checks the transcription jobs config for invalid fields
+func (c *TranscriptionJobsConfig) Validate() error {
+	if c.ChunkSeconds < 0 || c.Concurrency < 0 || c.MaxFileSizeMB < 0 {
+		return fmt.Errorf("transcription jobs chunk_seconds, concurrency and max_file_size_mb cannot be negative")
+	}
+	if c.ChunkSeconds > 0 && c.ChunkSeconds < 30 {
+		return fmt.Errorf("transcription jobs chunk_seconds must be at least 30")
+	}
+	return nil
+}
+
+// TranscriptionJobRequest is the transcription a job performs on an uploaded file
+type TranscriptionJobRequest struct {
+	Provider   schemas.ModelProvider
+	Model      string
+	Params     *schemas.TranscriptionParameters
+	Fallbacks  []schemas.Fallback
+	VirtualKey string // Virtual key chunks are transcribed with, so governance limits apply
+}
+
+// TranscriptionUpload is a file being uploaded in parts
+type TranscriptionUpload struct {
+	ID        string    `json:"id"`
+	Size      int64     `json:"size"` // Bytes uploaded so far, the offset of the next part
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// TranscriptionJob is the progress of a transcription job
+type TranscriptionJob struct {
+	ID          string                                `json:"id"`
+	Model       string                                `json:"model"`
+	Status      string                                `json:"status"`   // One of the TranscriptionJob* constants
+	Duration    float64                               `json:"duration"` // Seconds of audio, known once the file is decoded
+	Chunks      int                                   `json:"chunks"`
+	Processed   int                                   `json:"processed"` // Chunks transcribed
+	Failed      int                                   `json:"failed"`
+	Errors      []string                              `json:"errors,omitempty"` // First errors encountered, capped at 20
+	Result      *schemas.BifrostTranscriptionResponse `json:"result,omitempty"` // Stitched transcript, set once the job completed
+	CreatedAt   time.Time                             `json:"created_at"`
+	StartedAt   *time.Time                            `json:"started_at,omitempty"`
+	CompletedAt *time.Time                            `json:"completed_at,omitempty"`
+}
+
+// TranscriptionClient is the subset of the Bifrost client used to transcribe chunks
+type TranscriptionClient interface {
+	TranscriptionRequest(ctx context.Context, req *schemas.BifrostTranscriptionRequest) (*schemas.BifrostTranscriptionResponse, *schemas.BifrostError)
+}
+
+// transcriptionUpload is an upload with the file it is written to
+type transcriptionUpload struct {
+	mu        sync.Mutex // Serializes the parts of the upload
+	id        string
+	path      string
+	size      int64
+	updatedAt time.Time
+	started   bool // A job took over the file
+}
+
+// transcriptionJob is a job with its request, audio file and cancellation
+type transcriptionJob struct {
+	job     TranscriptionJob
+	request TranscriptionJobRequest
+	path    string
+	cancel  context.CancelFunc
+}
+
+// TranscriptionJobManager stores uploads and runs transcription jobs in the background
+type TranscriptionJobManager struct {
+	config TranscriptionJobsConfig
+	client TranscriptionClient
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.RWMutex
+	uploads  map[string]*transcriptionUpload
+	jobs     map[string]*transcriptionJob
+	finished []string // IDs of finished jobs, oldest first
+	wg       sync.WaitGroup
+}
+
+// NewTranscriptionJobManager creates a transcription job manager transcribing chunks with the client
+func NewTranscriptionJobManager(config *TranscriptionJobsConfig, client TranscriptionClient) (*TranscriptionJobManager, error) {
+	if config == nil {
+		return nil, fmt.Errorf("transcription jobs config is required")
+	}
+	if client == nil {
+		return nil, fmt.Errorf("bifrost client is required")
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	resolved := *config
+	if resolved.ChunkSeconds == 0 {
+		resolved.ChunkSeconds = DefaultTranscriptionChunkSeconds
+	}
+	if resolved.Concurrency == 0 {
+		resolved.Concurrency = DefaultTranscriptionJobConcurrency
+	}
+	if resolved.MaxFileSizeMB == 0 {
+		resolved.MaxFileSizeMB = DefaultTranscriptionMaxFileSizeMB
+	}
+	if resolved.FFmpegPath == "" {
+		resolved.FFmpegPath = "ffmpeg"
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TranscriptionJobManager{
+		config:  resolved,
+		client:  client,
+		ctx:     ctx,
+		cancel:  cancel,
+		uploads: make(map[string]*transcriptionUpload),
+		jobs:    make(map[string]*transcriptionJob),
+	}, nil
+}
+
+// CreateUpload starts an upload, parts are then appended with AppendUpload. Expired uploads are removed.
+func (m *TranscriptionJobManager) CreateUpload() (*TranscriptionUpload, error) {
+	if err := m.ctx.Err(); err != nil {
+		return nil, fmt.Errorf("transcription job manager is stopped")
+	}
+	m.removeExpiredUploads()
+
+	file, err := os.CreateTemp(m.config.TempDir, "bifrost-transcription-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create upload file: %w", err)
+	}
+	file.Close()
+	upload := &transcriptionUpload{
+		id:        uuid.NewString(),
+		path:      file.Name(),
+		updatedAt: time.Now(),
+	}
+	m.mu.Lock()
+	m.uploads[upload.id] = upload
+	m.mu.Unlock()
+	return upload.snapshot(), nil
+}
+
+// AppendUpload appends a part to an upload. The part must start at offset, the size uploaded so far, so that a
+// part whose response was lost can be sent again safely.
+func (m *TranscriptionJobManager) AppendUpload(id string, offset int64, part io.Reader) (*TranscriptionUpload, error) {
+	m.mu.RLock()
+	upload, ok := m.uploads[id]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	upload.mu.Lock()
+	defer upload.mu.Unlock()
+	if upload.started {
+		return nil, fmt.Errorf("a job was already started from the upload")
+	}
+	if offset != upload.size {
+		return nil, ErrUploadOffsetMismatch
+	}
+	file, err := os.OpenFile(upload.path, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open upload file: %w", err)
+	}
+	defer file.Close()
+
+	maxSize := int64(m.config.MaxFileSizeMB) * 1024 * 1024
+	written, err := io.Copy(io.NewOffsetWriter(file, offset), io.LimitReader(part, maxSize-offset+1))
+	if err == nil && offset+written > maxSize {
+		err = fmt.Errorf("file exceeds the maximum size of %d MB", m.config.MaxFileSizeMB)
+	}
+	if err != nil {
+		// Drop the partial part so that it can be sent again at the same offset
+		file.Truncate(offset)
+		return nil, err
+	}
+	upload.size += written
+	upload.updatedAt = time.Now()
+	return upload.snapshot(), nil
+}
+
+// DeleteUpload removes an upload no job was started from
+func (m *TranscriptionJobManager) DeleteUpload(id string) error {
+	m.mu.Lock()
+	upload, ok := m.uploads[id]
+	delete(m.uploads, id)
+	m.mu.Unlock()
+	if !ok {
+		return ErrNotFound
+	}
+	upload.mu.Lock()
+	defer upload.mu.Unlock()
+	return os.Remove(upload.path)
+}
+
+// StartJob starts transcribing an upload in the background, the job takes over the file of the upload
+func (m *TranscriptionJobManager) StartJob(uploadID string, request TranscriptionJobRequest) (*TranscriptionJob, error) {
+	if request.Provider == "" || request.Model == "" {
+		return nil, fmt.Errorf("model should be in provider/model format")
+	}
+	if request.Params != nil && request.Params.ResponseFormat != nil {
+		switch *request.Params.ResponseFormat {
+		case "json", "verbose_json":
+		default:
+			return nil, fmt.Errorf("response_format %s cannot be stitched, use json or verbose_json", *request.Params.ResponseFormat)
+		}
+	}
+	if err := m.ctx.Err(); err != nil {
+		return nil, fmt.Errorf("transcription job manager is stopped")
+	}
+
+	m.mu.Lock()
+	upload, ok := m.uploads[uploadID]
+	delete(m.uploads, uploadID)
+	m.mu.Unlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	upload.mu.Lock()
+	upload.started = true
+	size := upload.size
+	upload.mu.Unlock()
+	if size == 0 {
+		os.Remove(upload.path)
+		return nil, fmt.Errorf("upload is empty")
+	}
+
+	jobCtx, cancel := context.WithCancel(m.ctx)
+	job := &transcriptionJob{
+		job: TranscriptionJob{
+			ID:        uuid.NewString(),
+			Model:     string(request.Provider) + "/" + request.Model,
+			Status:    TranscriptionJobPending,
+			CreatedAt: time.Now(),
+		},
+		request: request,
+		path:    upload.path,
+		cancel:  cancel,
+	}
+	m.mu.Lock()
+	m.jobs[job.job.ID] = job
+	snapshot := job.snapshot()
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer cancel()
+		m.runJob(jobCtx, job)
+	}()
+	return &snapshot, nil
+}
+
+// GetJob returns the progress of a job, with its transcript once it completed
+func (m *TranscriptionJobManager) GetJob(id string) (*TranscriptionJob, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	snapshot := job.snapshot()
+	return &snapshot, nil
+}
+
+// ListJobs returns the progress of all jobs without their transcripts, most recent first
+func (m *TranscriptionJobManager) ListJobs() []TranscriptionJob {
+	m.mu.RLock()
+	jobs := make([]TranscriptionJob, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		snapshot := job.snapshot()
+		snapshot.Result = nil
+		jobs = append(jobs, snapshot)
+	}
+	m.mu.RUnlock()
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.After(jobs[j].CreatedAt) })
+	return jobs
+}
+
+// CancelJob cancels a pending or running job, chunks in flight are abandoned
+func (m *TranscriptionJobManager) CancelJob(id string) (*TranscriptionJob, error) {
+	m.mu.RLock()
+	job, ok := m.jobs[id]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	job.cancel()
+	return m.GetJob(id)
+}
+
+// Stop cancels all jobs, waits for them to finish and removes the pending uploads
+func (m *TranscriptionJobManager) Stop() {
+	m.cancel()
+	m.wg.Wait()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, upload := range m.uploads {
+		os.Remove(upload.path)
+		delete(m.uploads, id)
+	}
+}
+
+// removeExpiredUploads removes the uploads no part was appended to for transcriptionUploadTTL
+func (m *TranscriptionJobManager) removeExpiredUploads() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, upload := range m.uploads {
+		if upload.mu.TryLock() {
+			if time.Since(upload.updatedAt) > transcriptionUploadTTL {
+				os.Remove(upload.path)
+				delete(m.uploads, id)
+			}
+			upload.mu.Unlock()
+		}
+	}
+}
+
+// snapshot returns the state of the upload, the caller must hold the upload lock
+func (u *transcriptionUpload) snapshot() *TranscriptionUpload {
+	return &TranscriptionUpload{
+		ID:        u.id,
+		Size:      u.size,
+		ExpiresAt: u.updatedAt.Add(transcriptionUploadTTL),
+	}
+}
+
+// snapshot returns a copy of the job progress, the caller must hold the manager lock
+func (j *transcriptionJob) snapshot() TranscriptionJob {
+	snapshot := j.job
+	snapshot.Errors = append([]string(nil), j.job.Errors...)
+	return snapshot
+}
+
+// transcriptionChunk is a range of the PCM data of a job, transcribed in its own request
+type transcriptionChunk struct {
+	start    int64   // Offset of the chunk in the PCM data
+	end      int64   // Offset of the end of the chunk in the PCM data
+	offset   float64 // Seconds of audio before the chunk
+	response *schemas.BifrostTranscriptionResponse
+}
+
+// runJob converts the file of the job to WAV if needed, transcribes its chunks in parallel and stitches the transcripts
+func (m *TranscriptionJobManager) runJob(ctx context.Context, job *transcriptionJob) {
+	startedAt := time.Now()
+	m.mu.Lock()
+	job.job.Status = TranscriptionJobRunning
+	job.job.StartedAt = &startedAt
+	m.mu.Unlock()
+	defer func() { os.Remove(job.path) }()
+
+	file, audio, err := m.openAudio(ctx, job)
+	if err != nil {
+		m.recordFailure(job, 0, err.Error())
+		m.finishJob(job, TranscriptionJobFailed, nil)
+		return
+	}
+	defer file.Close()
+
+	chunks, err := splitTranscriptionAudio(file, audio, m.config.ChunkSeconds)
+	if err != nil {
+		m.recordFailure(job, 0, err.Error())
+		m.finishJob(job, TranscriptionJobFailed, nil)
+		return
+	}
+	m.mu.Lock()
+	job.job.Chunks = len(chunks)
+	job.job.Duration = audio.duration()
+	m.mu.Unlock()
+
+	semaphore := make(chan struct{}, m.config.Concurrency)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk *transcriptionChunk) {
+			defer wg.Done()
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-semaphore }()
+			m.transcribeChunk(ctx, job, file, audio, i, chunk)
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		m.finishJob(job, TranscriptionJobCancelled, nil)
+		return
+	}
+	result := stitchTranscriptions(chunks, audio.duration())
+	if result == nil {
+		m.finishJob(job, TranscriptionJobFailed, nil)
+		return
+	}
+	m.finishJob(job, TranscriptionJobCompleted, result)
+}
+
+// openAudio opens the file of the job as 16-bit PCM WAV, converting it with ffmpeg when it is in another format
+func (m *TranscriptionJobManager) openAudio(ctx context.Context, job *transcriptionJob) (*os.File, *wavAudio, error) {
+	file, err := os.Open(job.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open uploaded file: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("failed to read uploaded file: %v", err)
+	}
+	if audio, err := parseWAV(file, info.Size()); err == nil {
+		return file, audio, nil
+	}
+	file.Close()
+
+	// Downmix to 16kHz mono, which is what speech to text models work with and keeps chunks small
+	converted := job.path + ".wav"
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, m.config.FFmpegPath, "-nostdin", "-y", "-i", job.path, "-ac", "1", "-ar", "16000", "-c:a", "pcm_s16le", "-f", "wav", converted)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		os.Remove(converted)
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, nil, fmt.Errorf("file is not 16-bit PCM WAV and ffmpeg is not available to convert it")
+		}
+		return nil, nil, fmt.Errorf("failed to convert audio: %v: %s", err, lastLine(stderr.String()))
+	}
+	if err := os.Rename(converted, job.path); err != nil {
+		os.Remove(converted)
+		return nil, nil, fmt.Errorf("failed to store converted audio: %v", err)
+	}
+	if file, err = os.Open(job.path); err != nil {
+		return nil, nil, fmt.Errorf("failed to open converted audio: %v", err)
+	}
+	if info, err = file.Stat(); err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("failed to read converted audio: %v", err)
+	}
+	audio, err := parseWAV(file, info.Size())
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("failed to decode converted audio: %v", err)
+	}
+	return file, audio, nil
+}
+
+// transcribeChunk transcribes a chunk as a WAV file of its own
+func (m *TranscriptionJobManager) transcribeChunk(ctx context.Context, job *transcriptionJob, file io.ReaderAt, audio *wavAudio, index int, chunk *transcriptionChunk) {
+	pcm := make([]byte, chunk.end-chunk.start)
+	if _, err := file.ReadAt(pcm, audio.dataOffset+chunk.start); err != nil && !errors.Is(err, io.EOF) {
+		m.recordFailure(job, 1, fmt.Sprintf("chunk %d: failed to read audio: %v", index, err))
+		return
+	}
+	wav, err := providerUtils.ConvertPCMToWAV(pcm, audio.config)
+	if err != nil {
+		m.recordFailure(job, 1, fmt.Sprintf("chunk %d: failed to encode audio: %v", index, err))
+		return
+	}
+
+	params := schemas.TranscriptionParameters{}
+	if job.request.Params != nil {
+		params = *job.request.Params
+	}
+	params.Format = bifrost.Ptr("wav")
+	requestCtx := ctx
+	if job.request.VirtualKey != "" {
+		requestCtx = context.WithValue(ctx, schemas.BifrostContextKeyVirtualKey, job.request.VirtualKey)
+	}
+	response, bifrostErr := m.client.TranscriptionRequest(requestCtx, &schemas.BifrostTranscriptionRequest{
+		Provider:  job.request.Provider,
+		Model:     job.request.Model,
+		Input:     &schemas.TranscriptionInput{File: wav},
+		Params:    &params,
+		Fallbacks: job.request.Fallbacks,
+	})
+	if bifrostErr != nil {
+		if ctx.Err() == nil {
+			m.recordFailure(job, 1, fmt.Sprintf("chunk %d (%.0fs): %s", index, chunk.offset, bifrost.GetErrorMessage(bifrostErr)))
+		}
+		return
+	}
+	m.mu.Lock()
+	chunk.response = response
+	job.job.Processed++
+	m.mu.Unlock()
+}
+
+// recordFailure adds failed chunks and their error to a job
+func (m *TranscriptionJobManager) recordFailure(job *transcriptionJob, count int, message string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job.job.Failed += count
+	if len(job.job.Errors) < maxTranscriptionJobErrors {
+		job.job.Errors = append(job.job.Errors, message)
+	}
+}
+
+// finishJob sets the final status and transcript of a job and evicts the oldest finished jobs
+func (m *TranscriptionJobManager) finishJob(job *transcriptionJob, status string, result *schemas.BifrostTranscriptionResponse) {
+	completedAt := time.Now()
+	m.mu.Lock()
+	job.job.Status = status
+	job.job.Result = result
+	job.job.CompletedAt = &completedAt
+	m.finished = append(m.finished, job.job.ID)
+	for len(m.finished) > maxFinishedTranscriptionJobs {
+		delete(m.jobs, m.finished[0])
+		m.finished = m.finished[1:]
+	}
+	snapshot := job.snapshot()
+	m.mu.Unlock()
+
+	logger.Info("transcription job %s %s: %d of %d chunks transcribed, %d failed", snapshot.ID, status, snapshot.Processed, snapshot.Chunks, snapshot.Failed)
+}
+
+// stitchTranscriptions joins the transcripts of the chunks in order, shifting their segments and words by the
+// audio before each chunk. Failed chunks leave a gap. It returns nil when no chunk was transcribed.
+func stitchTranscriptions(chunks []*transcriptionChunk, duration float64) *schemas.BifrostTranscriptionResponse {
+	var result *schemas.BifrostTranscriptionResponse
+	var texts []string
+	for _, chunk := range chunks {
+		response := chunk.response
+		if response == nil {
+			continue
+		}
+		if result == nil {
+			result = &schemas.BifrostTranscriptionResponse{
+				Duration:    bifrost.Ptr(duration),
+				Language:    response.Language,
+				Task:        response.Task,
+				ExtraFields: response.ExtraFields,
+			}
+			result.ExtraFields.RawResponse = nil
+		}
+		if text := strings.TrimSpace(response.Text); text != "" {
+			texts = append(texts, text)
+		}
+		for _, segment := range response.Segments {
+			segment.ID = len(result.Segments)
+			segment.Seek += int(chunk.offset * 100)
+			segment.Start += chunk.offset
+			segment.End += chunk.offset
+			result.Segments = append(result.Segments, segment)
+		}
+		for _, word := range response.Words {
+			word.Start += chunk.offset
+			word.End += chunk.offset
+			result.Words = append(result.Words, word)
+		}
+		result.LogProbs = append(result.LogProbs, response.LogProbs...)
+		result.Usage = addTranscriptionUsage(result.Usage, response.Usage)
+	}
+	if result != nil {
+		result.Text = strings.Join(texts, " ")
+	}
+	return result
+}
+
+// addTranscriptionUsage adds the usage of a chunk to the usage of the job
+func addTranscriptionUsage(total *schemas.TranscriptionUsage, usage *schemas.TranscriptionUsage) *schemas.TranscriptionUsage {
+	if usage == nil {
+		return total
+	}
+	if total == nil {
+		total = &schemas.TranscriptionUsage{Type: usage.Type}
+	}
+	add := func(total **int, value *int) {
+		if value == nil {
+			return
+		}
+		if *total == nil {
+			*total = bifrost.Ptr(0)
+		}
+		**total += *value
+	}
+	add(&total.InputTokens, usage.InputTokens)
+	add(&total.OutputTokens, usage.OutputTokens)
+	add(&total.TotalTokens, usage.TotalTokens)
+	add(&total.Seconds, usage.Seconds)
+	if details := usage.InputTokenDetails; details != nil {
+		if total.InputTokenDetails == nil {
+			total.InputTokenDetails = &schemas.TranscriptionUsageInputTokenDetails{}
+		}
+		total.InputTokenDetails.TextTokens += details.TextTokens
+		total.InputTokenDetails.AudioTokens += details.AudioTokens
+	}
+	return total
+}
+
+// wavAudio is the PCM data of a 16-bit PCM WAV file
+type wavAudio struct {
+	config     providerUtils.PCMConfig
+	dataOffset int64 // Offset of the PCM data in the file
+	dataSize   int64
+}
+
+// bytesPerSecond returns the size of a second of audio
+func (a *wavAudio) bytesPerSecond() int64 {
+	return int64(a.config.SampleRate * a.config.NumChannels * a.config.BitsPerSample / 8)
+}
+
+// blockAlign returns the size of a sample of all channels, chunks are cut at multiples of it
+func (a *wavAudio) blockAlign() int64 {
+	return int64(a.config.NumChannels * a.config.BitsPerSample / 8)
+}
+
+// duration returns the duration of the audio in seconds
+func (a *wavAudio) duration() float64 {
+	return float64(a.dataSize) / float64(a.bytesPerSecond())
+}
+
+// parseWAV reads the format and locates the PCM data of a WAV file, only 16-bit PCM is accepted
+func parseWAV(r io.ReaderAt, size int64) (*wavAudio, error) {
+	header := make([]byte, 12)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, fmt.Errorf("file is not a WAV file")
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("file is not a WAV file")
+	}
+	audio := &wavAudio{}
+	hasFormat := false
+	for pos := int64(12); pos+8 <= size; {
+		chunkHeader := make([]byte, 8)
+		if _, err := r.ReadAt(chunkHeader, pos); err != nil {
+			return nil, fmt.Errorf("failed to read WAV chunk: %w", err)
+		}
+		chunkSize := int64(binary.LittleEndian.Uint32(chunkHeader[4:8]))
+		switch string(chunkHeader[0:4]) {
+		case "fmt ":
+			format := make([]byte, 16)
+			if _, err := r.ReadAt(format, pos+8); err != nil {
+				return nil, fmt.Errorf("failed to read WAV format: %w", err)
+			}
+			audioFormat := binary.LittleEndian.Uint16(format[0:2])
+			audio.config = providerUtils.PCMConfig{
+				NumChannels:   int(binary.LittleEndian.Uint16(format[2:4])),
+				SampleRate:    int(binary.LittleEndian.Uint32(format[4:8])),
+				BitsPerSample: int(binary.LittleEndian.Uint16(format[14:16])),
+			}
+			// 0xFFFE is WAVE_FORMAT_EXTENSIBLE, which ffmpeg writes for multichannel PCM
+			if (audioFormat != 1 && audioFormat != 0xFFFE) || audio.config.BitsPerSample != 16 || audio.config.NumChannels == 0 || audio.config.SampleRate == 0 {
+				return nil, fmt.Errorf("WAV file is not 16-bit PCM")
+			}
+			hasFormat = true
+		case "data":
+			if !hasFormat {
+				return nil, fmt.Errorf("WAV file has no format before its data")
+			}
+			audio.dataOffset = pos + 8
+			// Streamed WAV files do not know the size of their data
+			audio.dataSize = min(chunkSize, size-audio.dataOffset)
+			audio.dataSize -= audio.dataSize % audio.blockAlign()
+			if audio.dataSize == 0 {
+				return nil, fmt.Errorf("WAV file has no audio")
+			}
+			return audio, nil
+		}
+		pos += 8 + chunkSize + chunkSize%2
+	}
+	return nil, fmt.Errorf("WAV file has no data")
+}
+
+// splitTranscriptionAudio splits the PCM data into chunks of about chunkSeconds. Each chunk is cut at the quietest
+// frame of the last seconds before its nominal end, so that words are not split between two chunks.
+func splitTranscriptionAudio(r io.ReaderAt, audio *wavAudio, chunkSeconds int) ([]*transcriptionChunk, error) {
+	bytesPerSecond := audio.bytesPerSecond()
+	blockAlign := audio.blockAlign()
+	chunkSize := int64(chunkSeconds) * bytesPerSecond
+	frameSize := max(blockAlign, int64(transcriptionCutFrame.Seconds()*float64(bytesPerSecond))/blockAlign*blockAlign)
+	searchSize := min(int64(transcriptionCutSearch.Seconds()*float64(bytesPerSecond)), chunkSize/4) / frameSize * frameSize
+
+	var chunks []*transcriptionChunk
+	for start := int64(0); start < audio.dataSize; {
+		end := start + chunkSize
+		if end >= audio.dataSize {
+			end = audio.dataSize
+		} else if searchSize > 0 {
+			window := make([]byte, searchSize)
+			if _, err := r.ReadAt(window, audio.dataOffset+end-searchSize); err != nil && !errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("failed to read audio: %w", err)
+			}
+			end = end - searchSize + quietestFrame(window, frameSize, blockAlign)
+		}
+		chunks = append(chunks, &transcriptionChunk{
+			start:  start,
+			end:    end,
+			offset: float64(start) / float64(bytesPerSecond),
+		})
+		start = end
+	}
+	return chunks, nil
+}
+
+// quietestFrame returns the offset of the middle of the frame of 16-bit samples with the lowest energy in the window,
+// aligned to blockAlign, the last one on ties so that chunks stay as long as possible
+func quietestFrame(window []byte, frameSize int64, blockAlign int64) int64 {
+	best := int64(len(window))
+	var bestEnergy uint64
+	for frame := int64(0); frame+frameSize <= int64(len(window)); frame += frameSize {
+		var energy uint64
+		for i := frame; i+1 < frame+frameSize; i += 2 {
+			sample := int64(int16(binary.LittleEndian.Uint16(window[i:])))
+			if sample < 0 {
+				sample = -sample
+			}
+			energy += uint64(sample)
+		}
+		if best == int64(len(window)) || energy <= bestEnergy {
+			best = frame
+			bestEnergy = energy
+		}
+	}
+	if best == int64(len(window)) {
+		return best
+	}
+	return best + frameSize/2/blockAlign*blockAlign
+}
+
+// lastLine returns the last non empty line of the output of a command, where ffmpeg reports its error
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return lines[len(lines)-1]
+}
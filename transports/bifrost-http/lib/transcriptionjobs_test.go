@@ -0,0 +1,206 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	providerUtils "github.com/maximhq/bifrost/core/providers/utils"
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// fakeTranscriptionClient transcribes a chunk as one segment spanning its audio, failing the chunks whose first
+// sample is failOn
+type fakeTranscriptionClient struct {
+	mu      sync.Mutex
+	failOn  int16
+	formats []string
+	vks     []any
+}
+
+func (c *fakeTranscriptionClient) TranscriptionRequest(ctx context.Context, req *schemas.BifrostTranscriptionRequest) (*schemas.BifrostTranscriptionResponse, *schemas.BifrostError) {
+	audio, err := parseWAV(bytes.NewReader(req.Input.File), int64(len(req.Input.File)))
+	if err != nil {
+		return nil, &schemas.BifrostError{Error: &schemas.ErrorField{Message: err.Error()}}
+	}
+	c.mu.Lock()
+	c.formats = append(c.formats, *req.Params.Format)
+	c.vks = append(c.vks, ctx.Value(schemas.BifrostContextKeyVirtualKey))
+	c.mu.Unlock()
+
+	first := int16(binary.LittleEndian.Uint16(req.Input.File[audio.dataOffset:]))
+	if c.failOn != 0 && first == c.failOn {
+		return nil, &schemas.BifrostError{StatusCode: bifrost.Ptr(500), Error: &schemas.ErrorField{Message: "provider error"}}
+	}
+	duration := audio.duration()
+	return &schemas.BifrostTranscriptionResponse{
+		Text:     fmt.Sprintf(" %.0f seconds ", duration),
+		Segments: []schemas.TranscriptionSegment{{Start: 0, End: duration}},
+		Words:    []schemas.TranscriptionWord{{Word: "hello", Start: 1, End: 2}},
+		Usage:    &schemas.TranscriptionUsage{Type: "duration", Seconds: bifrost.Ptr(10)},
+	}, nil
+}
+
+// testWAV returns seconds of 8kHz mono 16-bit WAV audio with the samples returned by sample
+func testWAV(t *testing.T, seconds int, sample func(at float64) int16) []byte {
+	t.Helper()
+	const rate = 8000
+	pcm := make([]byte, seconds*rate*2)
+	for i := 0; i < seconds*rate; i++ {
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(sample(float64(i)/rate)))
+	}
+	wav, err := providerUtils.ConvertPCMToWAV(pcm, providerUtils.PCMConfig{SampleRate: rate, NumChannels: 1, BitsPerSample: 16})
+	if err != nil {
+		t.Fatalf("failed to encode wav: %v", err)
+	}
+	return wav
+}
+
+func newTestTranscriptionJobManager(t *testing.T, client TranscriptionClient) *TranscriptionJobManager {
+	t.Helper()
+	return newTestJobManager(t, func() (*TranscriptionJobManager, error) {
+		return NewTranscriptionJobManager(&TranscriptionJobsConfig{
+			Enabled:       true,
+			ChunkSeconds:  30,
+			Concurrency:   2,
+			MaxFileSizeMB: 2,
+			TempDir:       t.TempDir(),
+		}, client)
+	})
+}
+
+// uploadTestFile uploads the data in two parts
+func uploadTestFile(t *testing.T, manager *TranscriptionJobManager, data []byte) string {
+	t.Helper()
+	upload, err := manager.CreateUpload()
+	if err != nil {
+		t.Fatalf("failed to create upload: %v", err)
+	}
+	half := int64(len(data) / 2)
+	if _, err := manager.AppendUpload(upload.ID, 0, bytes.NewReader(data[:half])); err != nil {
+		t.Fatalf("failed to append first part: %v", err)
+	}
+	if _, err := manager.AppendUpload(upload.ID, half, bytes.NewReader(data[half:])); err != nil {
+		t.Fatalf("failed to append second part: %v", err)
+	}
+	return upload.ID
+}
+
+// waitForTranscriptionJob waits until the job leaves the pending and running statuses
+func waitForTranscriptionJob(t *testing.T, manager *TranscriptionJobManager, id string) *TranscriptionJob {
+	t.Helper()
+	return waitForJob(t, id, manager.GetJob, func(job *TranscriptionJob) bool {
+		return job.Status != TranscriptionJobPending && job.Status != TranscriptionJobRunning
+	})
+}
+
+// TestTranscriptionJobManager_ChunksAndStitches tests that a long file is split at quiet points, its chunks are
+// transcribed in parallel and the transcripts are stitched with shifted timestamps
+func TestTranscriptionJobManager_ChunksAndStitches(t *testing.T) {
+	client := &fakeTranscriptionClient{}
+	manager := newTestTranscriptionJobManager(t, client)
+
+	// A tone, silent between 27s and 28s
+	uploadID := uploadTestFile(t, manager, testWAV(t, 75, func(at float64) int16 {
+		if at >= 27 && at < 28 {
+			return 0
+		}
+		return int16(8000 * math.Sin(2*math.Pi*440*at))
+	}))
+	started, err := manager.StartJob(uploadID, TranscriptionJobRequest{Provider: schemas.OpenAI, Model: "whisper-1", VirtualKey: "sk-bf-calls"})
+	if err != nil {
+		t.Fatalf("failed to start job: %v", err)
+	}
+	job := waitForTranscriptionJob(t, manager, started.ID)
+
+	if job.Status != TranscriptionJobCompleted || job.Chunks != 3 || job.Processed != 3 || job.Failed != 0 {
+		t.Fatalf("expected 3 chunks transcribed, got status %s chunks %d processed %d failed %d: %v", job.Status, job.Chunks, job.Processed, job.Failed, job.Errors)
+	}
+	result := job.Result
+	if result == nil || len(result.Segments) != 3 || len(result.Words) != 3 {
+		t.Fatalf("expected 3 stitched segments and words, got %+v", result)
+	}
+	// The first chunk is cut in the silence before its nominal end at 30s
+	cut := result.Segments[1].Start
+	if cut < 27 || cut > 28 || result.Segments[0].End != cut {
+		t.Errorf("expected the first chunk to be cut in the silence between 27s and 28s, got %v", cut)
+	}
+	if result.Segments[2].ID != 2 || math.Abs(result.Segments[2].End-75) > 1e-9 || result.Words[1].Start != cut+1 {
+		t.Errorf("expected segments and words to be shifted by the audio before their chunk, got %+v %+v", result.Segments, result.Words)
+	}
+	if result.Duration == nil || *result.Duration != 75 || *result.Usage.Seconds != 30 {
+		t.Errorf("expected the duration of the whole file and the usage of all chunks, got %v %+v", result.Duration, result.Usage)
+	}
+	if !strings.HasPrefix(result.Text, "28 seconds ") || strings.Count(result.Text, "seconds") != 3 {
+		t.Errorf("expected the transcripts to be joined in order, got %q", result.Text)
+	}
+	if client.formats[0] != "wav" || client.vks[0] != "sk-bf-calls" {
+		t.Errorf("expected chunks to be sent as wav with the virtual key of the job, got %v %v", client.formats[0], client.vks[0])
+	}
+}
+
+// TestTranscriptionJobManager_FailedChunks tests that failed chunks leave a gap in the transcript and are reported
+func TestTranscriptionJobManager_FailedChunks(t *testing.T) {
+	// The samples of each second are the number of the second, so chunks are recognized by their first sample
+	wav := testWAV(t, 75, func(at float64) int16 { return int16(at) + 1 })
+	audio, err := parseWAV(bytes.NewReader(wav), int64(len(wav)))
+	if err != nil {
+		t.Fatalf("failed to parse wav: %v", err)
+	}
+	chunks, err := splitTranscriptionAudio(bytes.NewReader(wav), audio, 30)
+	if err != nil || len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d: %v", len(chunks), err)
+	}
+	// Fail the second chunk, recognized by its first sample
+	client := &fakeTranscriptionClient{failOn: int16(binary.LittleEndian.Uint16(wav[audio.dataOffset+chunks[1].start:]))}
+	manager := newTestTranscriptionJobManager(t, client)
+
+	started, err := manager.StartJob(uploadTestFile(t, manager, wav), TranscriptionJobRequest{Provider: schemas.OpenAI, Model: "whisper-1"})
+	if err != nil {
+		t.Fatalf("failed to start job: %v", err)
+	}
+	job := waitForTranscriptionJob(t, manager, started.ID)
+	if job.Status != TranscriptionJobCompleted || job.Processed != 2 || job.Failed != 1 || len(job.Errors) != 1 {
+		t.Fatalf("expected 2 chunks transcribed and 1 failed, got status %s processed %d failed %d: %v", job.Status, job.Processed, job.Failed, job.Errors)
+	}
+	if len(job.Result.Segments) != 2 || job.Result.Segments[1].Start != chunks[2].offset {
+		t.Errorf("expected the failed chunk to leave a gap, got %+v", job.Result.Segments)
+	}
+}
+
+// TestTranscriptionJobManager_Uploads tests that upload parts must follow each other and jobs validate their upload
+func TestTranscriptionJobManager_Uploads(t *testing.T) {
+	manager := newTestTranscriptionJobManager(t, &fakeTranscriptionClient{})
+
+	upload, err := manager.CreateUpload()
+	if err != nil {
+		t.Fatalf("failed to create upload: %v", err)
+	}
+	if _, err := manager.AppendUpload(upload.ID, 0, bytes.NewReader([]byte("RIFF"))); err != nil {
+		t.Fatalf("failed to append part: %v", err)
+	}
+	if _, err := manager.AppendUpload(upload.ID, 0, bytes.NewReader([]byte("RIFF"))); err != ErrUploadOffsetMismatch {
+		t.Errorf("expected a part sent again at a stale offset to be rejected, got %v", err)
+	}
+	if _, err := manager.AppendUpload(upload.ID, 4, bytes.NewReader(make([]byte, 2*1024*1024))); err == nil {
+		t.Error("expected parts exceeding the maximum file size to be rejected")
+	}
+	if current, err := manager.AppendUpload(upload.ID, 4, bytes.NewReader(nil)); err != nil || current.Size != 4 {
+		t.Errorf("expected the rejected part to be dropped, got %+v %v", current, err)
+	}
+	if _, err := manager.StartJob(upload.ID, TranscriptionJobRequest{Provider: schemas.OpenAI, Model: "whisper-1", Params: &schemas.TranscriptionParameters{ResponseFormat: bifrost.Ptr("srt")}}); err == nil {
+		t.Error("expected response formats that cannot be stitched to be rejected")
+	}
+	if _, err := manager.StartJob("missing", TranscriptionJobRequest{Provider: schemas.OpenAI, Model: "whisper-1"}); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound for unknown upload, got %v", err)
+	}
+	if err := manager.DeleteUpload(upload.ID); err != nil {
+		t.Errorf("failed to delete upload: %v", err)
+	}
+}
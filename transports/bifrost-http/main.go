@@ -5,11 +5,14 @@
 //   - /v1/completions: For text completion requests
 //   - /v1/chat/completions: For chat completion requests
 //   - /v1/mcp/tool/execute: For MCP tool execution requests
+//   - /v1/agent/completions: For chat completion requests whose tool calls are executed by Bifrost
+//   - /v1/token-count: For counting the input tokens of messages against the context window of a model
 //   - /providers/*: For provider configuration management
 //
 // Configuration is handled through a JSON config file, high-performance ConfigStore, and environment variables:
 //   - Use -app-dir flag to specify the application data directory (contains config.json and logs)
 //   - Use -port flag to specify the server port (default: 8080)
+//   - Use -drain-timeout flag to specify how long in-flight requests can finish on SIGTERM (default: 30s)
 //   - When no config file exists, common environment variables are auto-detected (OPENAI_API_KEY, ANTHROPIC_API_KEY, MISTRAL_API_KEY)
 //
 // ConfigStore Features:
@@ -100,6 +103,7 @@ func init() {
 	flag.StringVar(&server.AppDir, "app-dir", bifrostServer.DefaultAppDir, "Application data directory (contains config.json and logs)")
 	flag.StringVar(&server.LogLevel, "log-level", bifrostServer.DefaultLogLevel, "Logger level (debug, info, warn, error). Default is info.")
 	flag.StringVar(&server.LogOutputStyle, "log-style", bifrostServer.DefaultLogOutputStyle, "Logger output type (json or pretty). Default is JSON.")
+	flag.DurationVar(&server.DrainTimeout, "drain-timeout", lib.DefaultDrainTimeout, "How long in-flight requests and streams can take to finish on SIGTERM before shutdown. Default is 30s.")
 }
 
 // main is the entry point of the application.
@@ -133,21 +137,25 @@ func main() {
 	// Configure logger from flags
 	logger.SetOutputType(schemas.LoggerOutputType(server.LogOutputStyle))
 	logger.SetLevel(schemas.LogLevel(server.LogLevel))
-	// Setting up logger
-	lib.SetLogger(logger)
-	bifrostServer.SetLogger(logger)
-	handlers.SetLogger(logger)
+	// Setting up logger, each package logs as a module whose level can be set on its own
+	lib.SetLogger(logger.WithModule("lib"))
+	bifrostServer.SetLogger(logger.WithModule("server"))
+	handlers.SetLogger(logger.WithModule("handlers"))
 
 	ctx := context.Background()
 	err := server.Bootstrap(ctx)
 	if err != nil {
 		logger.Error("failed to bootstrap server: %v", err)
+		logger.Close()
 		os.Exit(1)
 	}
 	err = server.Start()
 	if err != nil {
 		logger.Error("failed to start server: %v", err)
+		logger.Close()
 		os.Exit(1)
 	}
 	logger.Info("🏁 server stopped")
+	// Flushing the log sinks
+	logger.Close()
 }
@@ -6,6 +6,7 @@ import (
 	"embed"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"os/signal"
@@ -22,8 +23,12 @@ import (
 	"github.com/maximhq/bifrost/core/schemas"
 	"github.com/maximhq/bifrost/framework/configstore"
 	"github.com/maximhq/bifrost/framework/configstore/tables"
+	"github.com/maximhq/bifrost/framework/conversations"
+	"github.com/maximhq/bifrost/framework/encrypt"
+	"github.com/maximhq/bifrost/framework/leader"
 	"github.com/maximhq/bifrost/framework/logstore"
 	dynamicPlugins "github.com/maximhq/bifrost/framework/plugins"
+	"github.com/maximhq/bifrost/plugins/clickhouse"
 	"github.com/maximhq/bifrost/plugins/governance"
 	"github.com/maximhq/bifrost/plugins/logging"
 	"github.com/maximhq/bifrost/plugins/maxim"
@@ -32,6 +37,7 @@ import (
 	"github.com/maximhq/bifrost/plugins/telemetry"
 	"github.com/maximhq/bifrost/transports/bifrost-http/handlers"
 	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/valyala/fasthttp"
 	"github.com/valyala/fasthttp/fasthttpadaptor"
@@ -46,6 +52,9 @@ const (
 	DefaultLogOutputStyle = string(schemas.LoggerOutputTypeJSON)
 )
 
+// revokedPayloadKeysSyncInterval is how often the payload encryption keys revoked through other replicas are loaded
+const revokedPayloadKeysSyncInterval = time.Minute
+
 var enterprisePlugins = []string{
 	"datadog",
 }
@@ -54,6 +63,7 @@ var enterprisePlugins = []string{
 type ServerCallbacks interface {
 	ReloadPlugin(ctx context.Context, name string, path *string, pluginConfig any) error
 	RemovePlugin(ctx context.Context, name string) error
+	UpdatePluginExecution(ctx context.Context, name string, execution *schemas.PluginExecutionConfig) error
 	GetPluginStatus(ctx context.Context) []schemas.PluginStatus
 	RefetchModelsForProvider(ctx context.Context, provider schemas.ModelProvider) error
 	DeleteModelsForProvider(ctx context.Context, provider schemas.ModelProvider) error
@@ -67,8 +77,17 @@ type ServerCallbacks interface {
 	RemoveTeam(ctx context.Context, id string) error
 	ReloadCustomer(ctx context.Context, id string) (*tables.TableCustomer, error)
 	RemoveCustomer(ctx context.Context, id string) error
+	ResetTestKeySpend(ctx context.Context, keyID string) error
 	ReloadVirtualKey(ctx context.Context, id string) (*tables.TableVirtualKey, error)
 	RemoveVirtualKey(ctx context.Context, id string) error
+	ReloadNamespace(ctx context.Context, name string) error
+	RemoveNamespace(ctx context.Context, name string) error
+	ReloadPipelines(ctx context.Context) error
+	GetEffectivePlugins(provider schemas.ModelProvider, model string, virtualKeyID string) ([]string, string, error)
+	ReloadTransformRules(ctx context.Context) error
+	ReloadFallbackChains(ctx context.Context) error
+	ReloadExperiments(ctx context.Context) error
+	ReloadModelDeprecations(ctx context.Context) error
 	AddMCPClient(ctx context.Context, clientConfig schemas.MCPClientConfig) error
 	RemoveMCPClient(ctx context.Context, id string) error
 	EditMCPClient(ctx context.Context, id string, updatedConfig schemas.MCPClientConfig) error
@@ -93,6 +112,8 @@ type BifrostHTTPServer struct {
 
 	LogLevel       string
 	LogOutputStyle string
+	// How long in-flight requests and streams can take to finish once a termination signal is received
+	DrainTimeout time.Duration
 
 	PluginsMutex      sync.RWMutex
 	Plugins           []schemas.Plugin
@@ -102,10 +123,24 @@ type BifrostHTTPServer struct {
 	Client *bifrost.Bifrost
 	Config *lib.Config
 
-	Server           *fasthttp.Server
-	Router           *router.Router
-	WebSocketHandler *handlers.WebSocketHandler
-	LogsCleaner      *logstore.LogsCleaner
+	Server            *fasthttp.Server
+	Router            *router.Router
+	WebSocketHandler  *handlers.WebSocketHandler
+	LogsCleaner       *logstore.LogsCleaner
+	Elector           *leader.Elector // Elects the replica running the background jobs, nil without a config store
+	ProbeRunner       *lib.ProbeRunner
+	IngestionManager  *lib.IngestionManager
+	TranscriptionJobs *lib.TranscriptionJobManager
+	DatasetExports    *lib.DatasetExportManager
+	// Moves the old logs to object storage
+	LogArchiver       *lib.LogArchiver
+	ConversationStore schemas.ConversationStore
+	// Deletes the conversations past their data retention
+	ConversationsCleaner *conversations.Cleaner
+	// Syncs the Bifrost custom resources of the cluster into the config store
+	KubernetesOperator *lib.KubernetesOperator
+
+	namespacePayloadKeys sync.Map // namespace name -> payload encryption key reference
 }
 
 var logger schemas.Logger
@@ -210,6 +245,8 @@ func (s *GovernanceInMemoryStore) GetConfiguredProviders() map[schemas.ModelProv
 // LoadPlugin loads a plugin by name and returns it as type T.
 func LoadPlugin[T schemas.Plugin](ctx context.Context, name string, path *string, pluginConfig any, bifrostConfig *lib.Config) (T, error) {
 	var zero T
+	// Plugins log as a module named after them, so that their level can be set on its own
+	pluginLogger := schemas.ModuleLogger(logger, name)
 	if path != nil {
 		logger.Info("loading dynamic plugin %s from path %s", name, *path)
 		// Load dynamic plugin
@@ -238,7 +275,7 @@ func LoadPlugin[T schemas.Plugin](ctx context.Context, name string, path *string
 	case telemetry.PluginName:
 		plugin, err := telemetry.Init(&telemetry.Config{
 			CustomLabels: bifrostConfig.ClientConfig.PrometheusLabels,
-		}, bifrostConfig.PricingManager, logger)
+		}, bifrostConfig.PricingManager, pluginLogger)
 		if err != nil {
 			return zero, err
 		}
@@ -251,7 +288,7 @@ func LoadPlugin[T schemas.Plugin](ctx context.Context, name string, path *string
 		if err != nil {
 			return zero, fmt.Errorf("failed to marshal logging plugin config: %v", err)
 		}
-		plugin, err := logging.Init(ctx, loggingConfig, logger, bifrostConfig.LogsStore, bifrostConfig.PricingManager)
+		plugin, err := logging.Init(ctx, loggingConfig, pluginLogger, bifrostConfig.LogsStore, bifrostConfig.PricingManager)
 		if err != nil {
 			return zero, err
 		}
@@ -267,7 +304,7 @@ func LoadPlugin[T schemas.Plugin](ctx context.Context, name string, path *string
 		inMemoryStore := &GovernanceInMemoryStore{
 			config: bifrostConfig,
 		}
-		plugin, err := governance.Init(ctx, governanceConfig, logger, bifrostConfig.ConfigStore, bifrostConfig.GovernanceConfig, bifrostConfig.PricingManager, inMemoryStore)
+		plugin, err := governance.Init(ctx, governanceConfig, pluginLogger, bifrostConfig.ConfigStore, bifrostConfig.GovernanceConfig, bifrostConfig.PricingManager, inMemoryStore)
 		if err != nil {
 			return zero, err
 		}
@@ -281,7 +318,7 @@ func LoadPlugin[T schemas.Plugin](ctx context.Context, name string, path *string
 		if err != nil {
 			return zero, fmt.Errorf("failed to marshal maxim plugin config: %v", err)
 		}
-		plugin, err := maxim.Init(maximConfig, logger)
+		plugin, err := maxim.Init(maximConfig, pluginLogger)
 		if err != nil {
 			return zero, err
 		}
@@ -294,7 +331,7 @@ func LoadPlugin[T schemas.Plugin](ctx context.Context, name string, path *string
 		if err != nil {
 			return zero, fmt.Errorf("failed to marshal semantic cache plugin config: %v", err)
 		}
-		plugin, err := semanticcache.Init(ctx, semanticcacheConfig, logger, bifrostConfig.VectorStore)
+		plugin, err := semanticcache.Init(ctx, semanticcacheConfig, pluginLogger, bifrostConfig.VectorStore)
 		if err != nil {
 			return zero, err
 		}
@@ -307,7 +344,7 @@ func LoadPlugin[T schemas.Plugin](ctx context.Context, name string, path *string
 		if err != nil {
 			return zero, fmt.Errorf("failed to marshal otel plugin config: %v", err)
 		}
-		plugin, err := otel.Init(ctx, otelConfig, logger, bifrostConfig.PricingManager, handlers.GetVersion())
+		plugin, err := otel.Init(ctx, otelConfig, pluginLogger, bifrostConfig.PricingManager, handlers.GetVersion())
 		if err != nil {
 			return zero, err
 		}
@@ -315,10 +352,39 @@ func LoadPlugin[T schemas.Plugin](ctx context.Context, name string, path *string
 			return p, nil
 		}
 		return zero, fmt.Errorf("otel plugin type mismatch")
+	case clickhouse.PluginName:
+		clickhouseConfig, err := MarshalPluginConfig[clickhouse.Config](pluginConfig)
+		if err != nil {
+			return zero, fmt.Errorf("failed to marshal clickhouse plugin config: %v", err)
+		}
+		plugin, err := clickhouse.Init(ctx, clickhouseConfig, pluginLogger, bifrostConfig.PricingManager)
+		if err != nil {
+			return zero, err
+		}
+		if p, ok := any(plugin).(T); ok {
+			return p, nil
+		}
+		return zero, fmt.Errorf("clickhouse plugin type mismatch")
 	}
 	return zero, fmt.Errorf("plugin %s not found", name)
 }
 
+// governancePluginConfig returns the config of the governance plugin, from its entry in the plugins if any, with the
+// virtual key enforcement of the client config
+func governancePluginConfig(config *lib.Config) (*governance.Config, error) {
+	governanceConfig := &governance.Config{}
+	for _, plugin := range config.PluginConfigs {
+		if plugin.Name == governance.PluginName && plugin.Config != nil {
+			var err error
+			if governanceConfig, err = MarshalPluginConfig[governance.Config](plugin.Config); err != nil {
+				return nil, fmt.Errorf("failed to marshal governance plugin config: %v", err)
+			}
+		}
+	}
+	governanceConfig.IsVkMandatory = &config.ClientConfig.EnforceGovernanceHeader
+	return governanceConfig, nil
+}
+
 // LoadPlugins loads the plugins for the server.
 func LoadPlugins(ctx context.Context, config *lib.Config) ([]schemas.Plugin, []schemas.PluginStatus, error) {
 	var err error
@@ -374,9 +440,11 @@ func LoadPlugins(ctx context.Context, config *lib.Config) ([]schemas.Plugin, []s
 	var governancePlugin *governance.GovernancePlugin
 	if config.ClientConfig.EnableGovernance {
 		// Initialize governance plugin
-		governancePlugin, err = LoadPlugin[*governance.GovernancePlugin](ctx, governance.PluginName, nil, &governance.Config{
-			IsVkMandatory: &config.ClientConfig.EnforceGovernanceHeader,
-		}, config)
+		var governanceConfig *governance.Config
+		governanceConfig, err = governancePluginConfig(config)
+		if err == nil {
+			governancePlugin, err = LoadPlugin[*governance.GovernancePlugin](ctx, governance.PluginName, nil, governanceConfig, config)
+		}
 		if err != nil {
 			logger.Error("failed to initialize governance plugin: %s", err.Error())
 			pluginStatus = append(pluginStatus, schemas.PluginStatus{
@@ -651,6 +719,205 @@ func (s *BifrostHTTPServer) RemoveCustomer(ctx context.Context, id string) error
 	return nil
 }
 
+// ResetTestKeySpend resets the spend of a test key in the in-memory store
+func (s *BifrostHTTPServer) ResetTestKeySpend(ctx context.Context, keyID string) error {
+	governancePlugin, err := FindPluginByName[*governance.GovernancePlugin](s.Plugins, governance.PluginName)
+	if err != nil {
+		return err
+	}
+	if governancePlugin == nil {
+		return fmt.Errorf("governance plugin not found")
+	}
+	governancePlugin.GetGovernanceStore().ResetTestKeySpend(keyID)
+	return nil
+}
+
+// ReloadNamespace reloads the payload encryption key of a namespace from the config store
+func (s *BifrostHTTPServer) ReloadNamespace(ctx context.Context, name string) error {
+	if s.Config == nil || s.Config.ConfigStore == nil {
+		return fmt.Errorf("config store not found")
+	}
+	namespace, err := s.Config.ConfigStore.GetNamespace(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to get namespace: %v", err)
+	}
+	s.storeNamespacePayloadKey(namespace)
+	return nil
+}
+
+// RemoveNamespace removes the payload encryption key of a deleted namespace from memory
+func (s *BifrostHTTPServer) RemoveNamespace(ctx context.Context, name string) error {
+	s.namespacePayloadKeys.Delete(name)
+	return nil
+}
+
+// ReloadPipelines applies the transformation pipelines of the config store to the bifrost client
+func (s *BifrostHTTPServer) ReloadPipelines(ctx context.Context) error {
+	if s.Config == nil || s.Config.ConfigStore == nil {
+		return fmt.Errorf("config store not found")
+	}
+	tablePipelines, err := s.Config.ConfigStore.GetPipelines(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get pipelines: %v", err)
+	}
+	pipelines := make([]schemas.Pipeline, 0, len(tablePipelines))
+	for i := range tablePipelines {
+		pipelines = append(pipelines, tablePipelines[i].ToSchema())
+	}
+	return s.Client.UpdatePipelines(pipelines)
+}
+
+// ReloadTransformRules applies the transform rules of the config store to the bifrost client
+func (s *BifrostHTTPServer) ReloadTransformRules(ctx context.Context) error {
+	if s.Config == nil || s.Config.ConfigStore == nil {
+		return fmt.Errorf("config store not found")
+	}
+	tableRules, err := s.Config.ConfigStore.GetTransformRules(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get transform rules: %v", err)
+	}
+	rules := make([]schemas.TransformRule, 0, len(tableRules))
+	for i := range tableRules {
+		rules = append(rules, tableRules[i].ToSchema())
+	}
+	return s.Client.UpdateTransformRules(rules)
+}
+
+// ReloadFallbackChains applies the fallback chains of the config store to the bifrost client
+func (s *BifrostHTTPServer) ReloadFallbackChains(ctx context.Context) error {
+	if s.Config == nil || s.Config.ConfigStore == nil {
+		return fmt.Errorf("config store not found")
+	}
+	tableChains, err := s.Config.ConfigStore.GetFallbackChains(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get fallback chains: %v", err)
+	}
+	chains := make([]schemas.FallbackChain, 0, len(tableChains))
+	for i := range tableChains {
+		chains = append(chains, tableChains[i].ToSchema())
+	}
+	return s.Client.UpdateFallbackChains(chains)
+}
+
+// ReloadExperiments applies the experiments of the config store to the bifrost client
+func (s *BifrostHTTPServer) ReloadExperiments(ctx context.Context) error {
+	if s.Config == nil || s.Config.ConfigStore == nil {
+		return fmt.Errorf("config store not found")
+	}
+	tableExperiments, err := s.Config.ConfigStore.GetExperiments(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get experiments: %v", err)
+	}
+	experiments := make([]schemas.Experiment, 0, len(tableExperiments))
+	for i := range tableExperiments {
+		experiments = append(experiments, tableExperiments[i].ToSchema())
+	}
+	return s.Client.UpdateExperiments(experiments)
+}
+
+// ReloadModelDeprecations applies the model deprecations of the config store to the bifrost client
+func (s *BifrostHTTPServer) ReloadModelDeprecations(ctx context.Context) error {
+	if s.Config == nil || s.Config.ConfigStore == nil {
+		return fmt.Errorf("config store not found")
+	}
+	tableDeprecations, err := s.Config.ConfigStore.GetModelDeprecations(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get model deprecations: %v", err)
+	}
+	deprecations := make([]schemas.ModelDeprecation, 0, len(tableDeprecations))
+	for i := range tableDeprecations {
+		deprecations = append(deprecations, tableDeprecations[i].ToSchema())
+	}
+	return s.Client.UpdateModelDeprecations(deprecations)
+}
+
+// GetEffectivePlugins returns the plugins running for a request to the model with the virtual key, and the pipeline applied to it
+func (s *BifrostHTTPServer) GetEffectivePlugins(provider schemas.ModelProvider, model string, virtualKeyID string) ([]string, string, error) {
+	return s.Client.GetEffectivePlugins(provider, model, virtualKeyID)
+}
+
+// loadNamespacePayloadKeys loads the payload encryption keys of all namespaces and the revoked key references
+// from the config store
+func (s *BifrostHTTPServer) loadNamespacePayloadKeys(ctx context.Context) error {
+	if err := s.loadRevokedPayloadKeys(ctx); err != nil {
+		return err
+	}
+	namespaces, err := s.Config.ConfigStore.GetNamespaces(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range namespaces {
+		s.storeNamespacePayloadKey(&namespaces[i])
+	}
+	return nil
+}
+
+// loadRevokedPayloadKeys revokes the payload encryption key references revoked in the config store in this process
+func (s *BifrostHTTPServer) loadRevokedPayloadKeys(ctx context.Context) error {
+	keys, err := s.Config.ConfigStore.GetRevokedPayloadKeys(ctx)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		encrypt.RevokeKeyRef(key.KeyRef)
+	}
+	return nil
+}
+
+// syncRevokedPayloadKeys periodically loads the revoked payload encryption key references, so that a key revoked
+// through another replica is refused by this one as well
+func (s *BifrostHTTPServer) syncRevokedPayloadKeys(ctx context.Context) {
+	ticker := time.NewTicker(revokedPayloadKeysSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.loadRevokedPayloadKeys(ctx); err != nil {
+				logger.Warn("failed to sync revoked payload encryption keys: %v", err)
+			}
+		}
+	}
+}
+
+// storeNamespacePayloadKey keeps the payload encryption key of a namespace in memory.
+// A revoked key is kept, so that payloads of the namespace are dropped instead of being logged in plaintext.
+func (s *BifrostHTTPServer) storeNamespacePayloadKey(namespace *tables.TableNamespace) {
+	if namespace.PayloadEncryptionKeyRef == nil {
+		s.namespacePayloadKeys.Delete(namespace.Name)
+		return
+	}
+	if namespace.PayloadKeyRevoked() {
+		encrypt.RevokeKeyRef(*namespace.PayloadEncryptionKeyRef)
+	}
+	s.namespacePayloadKeys.Store(namespace.Name, *namespace.PayloadEncryptionKeyRef)
+}
+
+// resolvePayloadKey resolves the namespace of a request from its virtual key, along with the
+// payload encryption key of the namespace. It is used by the logging plugin.
+func (s *BifrostHTTPServer) resolvePayloadKey(ctx context.Context) (string, string) {
+	virtualKeyValue, ok := ctx.Value(schemas.BifrostContextKeyVirtualKey).(string)
+	if !ok || virtualKeyValue == "" {
+		return "", ""
+	}
+	governancePlugin, err := FindPluginByName[*governance.GovernancePlugin](s.Plugins, governance.PluginName)
+	if err != nil {
+		return "", ""
+	}
+	vk, ok := governancePlugin.GetGovernanceStore().GetVirtualKey(virtualKeyValue)
+	if !ok {
+		return "", ""
+	}
+	namespace := vk.Namespace
+	if namespace == "" {
+		namespace = tables.DefaultNamespace
+	}
+	keyRef, _ := s.namespacePayloadKeys.Load(namespace)
+	keyRefStr, _ := keyRef.(string)
+	return namespace, keyRefStr
+}
+
 // ReloadClientConfigFromConfigStore reloads the client config from config store
 func (s *BifrostHTTPServer) ReloadClientConfigFromConfigStore(ctx context.Context) error {
 	if s.Config == nil || s.Config.ConfigStore == nil {
@@ -670,12 +937,36 @@ func (s *BifrostHTTPServer) ReloadClientConfigFromConfigStore(ctx context.Contex
 			DropExcessRequests: s.Config.ClientConfig.DropExcessRequests,
 			Plugins:            s.Config.GetLoadedPlugins(),
 			MCPConfig:          s.Config.MCPConfig,
-			Logger:             logger,
+			Logger:             schemas.ModuleLogger(logger, "core"),
+			DirectKeyPolicy:    s.Config.ClientConfig.DirectKeyPolicy,
+			ImageInputs:        s.Config.ClientConfig.ImageInputs,
+			Agent:              s.Config.ClientConfig.Agent,
+			ContextWindow:      s.Config.ClientConfig.ContextWindow,
+			PromptCompression:  s.Config.ClientConfig.PromptCompression,
+			StreamFailover:     s.Config.ClientConfig.StreamFailover,
+			StreamBackpressure: s.Config.ClientConfig.StreamBackpressure,
+			ResponseBuffering:  s.Config.ClientConfig.ResponseBuffering,
+			Idempotency:        s.Config.ClientConfig.Idempotency,
+			Chaos:              s.Config.ClientConfig.Chaos,
+			ListModelsCache:    s.Config.ClientConfig.ListModelsCache,
+			ResponsesState:     s.Config.ClientConfig.ResponsesState,
+			RequestTags:        s.Config.ClientConfig.RequestTags,
+			PartialFailures:    s.Config.ClientConfig.PartialFailures,
+			SLOs:               s.Config.ClientConfig.SLOs,
+			Conversations:      s.conversationsConfig(),
 		})
 	}
 	return nil
 }
 
+// conversationsConfig returns the history truncation of the stored conversations, nil when they are disabled
+func (s *BifrostHTTPServer) conversationsConfig() *schemas.ConversationConfig {
+	if s.ConversationStore == nil {
+		return nil
+	}
+	return &s.Config.ConversationsConfig.ConversationConfig
+}
+
 // UpdateAuthConfig updates auth config
 func (s *BifrostHTTPServer) UpdateAuthConfig(ctx context.Context, authConfig *configstore.AuthConfig) error {
 	if authConfig == nil {
@@ -865,6 +1156,16 @@ func (s *BifrostHTTPServer) GetModelsForProvider(provider schemas.ModelProvider)
 	return s.Config.PricingManager.GetModelsForProvider(provider)
 }
 
+// UpdatePluginExecution updates the execution limits (concurrency, timeout, circuit breaker) of a plugin in Bifrost core.
+// A nil execution config removes the limits, the plugin then runs inline.
+func (s *BifrostHTTPServer) UpdatePluginExecution(ctx context.Context, name string, execution *schemas.PluginExecutionConfig) error {
+	if s.Client == nil {
+		return fmt.Errorf("bifrost client not found")
+	}
+	s.Client.UpdatePluginExecutionConfig(name, execution)
+	return nil
+}
+
 // RemovePlugin removes a plugin from the server.
 // Uses atomic CompareAndSwap with retry loop to handle concurrent updates safely.
 func (s *BifrostHTTPServer) RemovePlugin(ctx context.Context, name string) error {
@@ -912,6 +1213,10 @@ func (s *BifrostHTTPServer) RegisterInferenceRoutes(ctx context.Context, middlew
 
 	integrationHandler.RegisterRoutes(s.Router, middlewares...)
 	inferenceHandler.RegisterRoutes(s.Router, middlewares...)
+	// Feedback is sent by the clients of the inference routes, and stored next to the logs of their requests
+	if loggerPlugin, _ := FindPluginByName[*logging.LoggerPlugin](s.Plugins, logging.PluginName); loggerPlugin != nil {
+		handlers.NewFeedbackHandler(loggerPlugin.GetPluginLogManager()).RegisterRoutes(s.Router, middlewares...)
+	}
 	return nil
 }
 
@@ -920,9 +1225,11 @@ func (s *BifrostHTTPServer) RegisterAPIRoutes(ctx context.Context, callbacks Ser
 	var err error
 	// Initializing plugin specific handlers
 	var loggingHandler *handlers.LoggingHandler
+	var replayHandler *handlers.ReplayHandler
 	loggerPlugin, _ := FindPluginByName[*logging.LoggerPlugin](s.Plugins, logging.PluginName)
 	if loggerPlugin != nil {
 		loggingHandler = handlers.NewLoggingHandler(loggerPlugin.GetPluginLogManager(), s)
+		replayHandler = handlers.NewReplayHandler(loggerPlugin.GetPluginLogManager(), s.Client)
 	}
 	var governanceHandler *handlers.GovernanceHandler
 	governancePlugin, _ := FindPluginByName[*governance.GovernancePlugin](s.Plugins, governance.PluginName)
@@ -932,6 +1239,67 @@ func (s *BifrostHTTPServer) RegisterAPIRoutes(ctx context.Context, callbacks Ser
 			return fmt.Errorf("failed to initialize governance handler: %v", err)
 		}
 	}
+	var namespaceHandler *handlers.NamespaceHandler
+	if s.Config.ConfigStore != nil {
+		namespaceHandler, err = handlers.NewNamespaceHandler(callbacks, s.Config.ConfigStore)
+		if err != nil {
+			return fmt.Errorf("failed to initialize namespace handler: %v", err)
+		}
+	}
+	var pipelinesHandler *handlers.PipelinesHandler
+	if s.Config.ConfigStore != nil {
+		pipelinesHandler, err = handlers.NewPipelinesHandler(callbacks, s.Config.ConfigStore)
+		if err != nil {
+			return fmt.Errorf("failed to initialize pipelines handler: %v", err)
+		}
+	}
+	var transformRulesHandler *handlers.TransformRulesHandler
+	if s.Config.ConfigStore != nil {
+		transformRulesHandler, err = handlers.NewTransformRulesHandler(callbacks, s.Config.ConfigStore)
+		if err != nil {
+			return fmt.Errorf("failed to initialize transform rules handler: %v", err)
+		}
+	}
+	var fallbackChainsHandler *handlers.FallbackChainsHandler
+	if s.Config.ConfigStore != nil {
+		fallbackChainsHandler, err = handlers.NewFallbackChainsHandler(callbacks, s.Config.ConfigStore)
+		if err != nil {
+			return fmt.Errorf("failed to initialize fallback chains handler: %v", err)
+		}
+	}
+	var experimentsHandler *handlers.ExperimentsHandler
+	if s.Config.ConfigStore != nil {
+		// Results are read from the logs, they are only served when the logging plugin is loaded
+		var logManager logging.LogManager
+		if loggerPlugin != nil {
+			logManager = loggerPlugin.GetPluginLogManager()
+		}
+		experimentsHandler, err = handlers.NewExperimentsHandler(callbacks, s.Config.ConfigStore, logManager)
+		if err != nil {
+			return fmt.Errorf("failed to initialize experiments handler: %v", err)
+		}
+	}
+	var modelDeprecationsHandler *handlers.ModelDeprecationsHandler
+	if s.Config.ConfigStore != nil {
+		modelDeprecationsHandler, err = handlers.NewModelDeprecationsHandler(callbacks, s.Config.ConfigStore)
+		if err != nil {
+			return fmt.Errorf("failed to initialize model deprecations handler: %v", err)
+		}
+	}
+	var modelCapabilitiesHandler *handlers.ModelCapabilitiesHandler
+	if s.Config.ConfigStore != nil && s.Config.PricingManager != nil {
+		modelCapabilitiesHandler, err = handlers.NewModelCapabilitiesHandler(s.Config.PricingManager, s.Config.ConfigStore)
+		if err != nil {
+			return fmt.Errorf("failed to initialize model capabilities handler: %v", err)
+		}
+	}
+	var pricingHandler *handlers.PricingHandler
+	if s.Config.ConfigStore != nil && s.Config.PricingManager != nil {
+		pricingHandler, err = handlers.NewPricingHandler(s.Config.PricingManager, s.Config.ConfigStore)
+		if err != nil {
+			return fmt.Errorf("failed to initialize pricing handler: %v", err)
+		}
+	}
 	var cacheHandler *handlers.CacheHandler
 	semanticCachePlugin, _ := FindPluginByName[*semanticcache.Plugin](s.Plugins, semanticcache.PluginName)
 	if semanticCachePlugin != nil {
@@ -942,6 +1310,13 @@ func (s *BifrostHTTPServer) RegisterAPIRoutes(ctx context.Context, callbacks Ser
 	if loggerPlugin != nil {
 		s.WebSocketHandler = handlers.NewWebSocketHandler(ctx, loggerPlugin.GetPluginLogManager(), s.Config.ClientConfig.AllowedOrigins)
 		loggerPlugin.SetLogCallback(s.WebSocketHandler.BroadcastLogUpdate)
+		if s.Config.ConfigStore != nil {
+			if err := s.loadNamespacePayloadKeys(ctx); err != nil {
+				return fmt.Errorf("failed to load namespace payload encryption keys: %v", err)
+			}
+			go s.syncRevokedPayloadKeys(ctx)
+			loggerPlugin.SetPayloadKeyResolver(s.resolvePayloadKey)
+		}
 	} else {
 		s.WebSocketHandler = handlers.NewWebSocketHandler(ctx, nil, s.Config.ClientConfig.AllowedOrigins)
 	}
@@ -970,20 +1345,72 @@ func (s *BifrostHTTPServer) RegisterAPIRoutes(ctx context.Context, callbacks Ser
 	if cacheHandler != nil {
 		cacheHandler.RegisterRoutes(s.Router, middlewares...)
 	}
+	if s.ProbeRunner != nil {
+		handlers.NewProbesHandler(s.ProbeRunner).RegisterRoutes(s.Router, middlewares...)
+	}
+	handlers.NewDrainHandler(s.Config.Drain).RegisterRoutes(s.Router, middlewares...)
+	handlers.NewDiagnosticsHandler(s.Config.Diagnostics).RegisterRoutes(s.Router, middlewares...)
+	handlers.NewSLOsHandler(s.Client).RegisterRoutes(s.Router, middlewares...)
+	if structuredLogger, ok := logger.(schemas.StructuredLogger); ok {
+		handlers.NewLogLevelsHandler(structuredLogger).RegisterRoutes(s.Router, middlewares...)
+	}
+	handlers.NewConfigSchemasHandler().RegisterRoutes(s.Router, middlewares...)
+	if s.IngestionManager != nil {
+		handlers.NewIngestionHandler(s.IngestionManager).RegisterRoutes(s.Router, middlewares...)
+	}
+	if s.TranscriptionJobs != nil {
+		handlers.NewTranscriptionJobsHandler(s.TranscriptionJobs).RegisterRoutes(s.Router, middlewares...)
+	}
+	if s.DatasetExports != nil {
+		handlers.NewDatasetExportsHandler(s.DatasetExports).RegisterRoutes(s.Router, middlewares...)
+	}
+	if s.ConversationStore != nil {
+		handlers.NewConversationsHandler(s.ConversationStore).RegisterRoutes(s.Router, middlewares...)
+	}
+	if s.Config.LogsStore != nil || s.ConversationStore != nil {
+		handlers.NewDataHandler(s.Config, s.ConversationStore).RegisterRoutes(s.Router, middlewares...)
+	}
 	if governanceHandler != nil {
 		governanceHandler.RegisterRoutes(s.Router, middlewares...)
 	}
+	if namespaceHandler != nil {
+		namespaceHandler.RegisterRoutes(s.Router, middlewares...)
+	}
+	if pipelinesHandler != nil {
+		pipelinesHandler.RegisterRoutes(s.Router, middlewares...)
+	}
+	if transformRulesHandler != nil {
+		transformRulesHandler.RegisterRoutes(s.Router, middlewares...)
+	}
+	if fallbackChainsHandler != nil {
+		fallbackChainsHandler.RegisterRoutes(s.Router, middlewares...)
+	}
+	if experimentsHandler != nil {
+		experimentsHandler.RegisterRoutes(s.Router, middlewares...)
+	}
+	if modelDeprecationsHandler != nil {
+		modelDeprecationsHandler.RegisterRoutes(s.Router, middlewares...)
+	}
+	if modelCapabilitiesHandler != nil {
+		modelCapabilitiesHandler.RegisterRoutes(s.Router, middlewares...)
+	}
+	if pricingHandler != nil {
+		pricingHandler.RegisterRoutes(s.Router, middlewares...)
+	}
 	if loggingHandler != nil {
 		loggingHandler.RegisterRoutes(s.Router, middlewares...)
 	}
+	if replayHandler != nil {
+		replayHandler.RegisterRoutes(s.Router, middlewares...)
+	}
 	if s.WebSocketHandler != nil {
 		s.WebSocketHandler.RegisterRoutes(s.Router, middlewares...)
 	}
 	// Add Prometheus /metrics endpoint
 	prometheusPlugin, err := FindPluginByName[*telemetry.PrometheusPlugin](s.Plugins, telemetry.PluginName)
 	if err == nil && prometheusPlugin.GetRegistry() != nil {
-		// Use the plugin's dedicated registry if available
-		metricsHandler := fasthttpadaptor.NewFastHTTPHandler(promhttp.HandlerFor(prometheusPlugin.GetRegistry(), promhttp.HandlerOpts{}))
+		// Use the plugin's dedicated registry if available, OpenMetrics exposes the request IDs of the latency exemplars
+		metricsHandler := fasthttpadaptor.NewFastHTTPHandler(promhttp.HandlerFor(prometheusPlugin.GetRegistry(), promhttp.HandlerOpts{EnableOpenMetrics: true}))
 		s.Router.GET("/metrics", metricsHandler)
 	} else {
 		logger.Warn("prometheus plugin not found or registry is nil, skipping metrics endpoint")
@@ -1068,6 +1495,28 @@ func (s *BifrostHTTPServer) Bootstrap(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to load config %v", err)
 	}
+	if s.Config.DataRetentionConfig != nil {
+		if err := s.Config.DataRetentionConfig.Validate(); err != nil {
+			return fmt.Errorf("invalid data retention config: %v", err)
+		}
+	}
+	// The sinks and levels of the logging config replace the log level and style of the flags
+	if s.Config.LoggingConfig != nil {
+		structuredLogger, ok := logger.(schemas.StructuredLogger)
+		if !ok {
+			logger.Warn("logging config ignored, the logger does not support sinks and module levels")
+		} else if err := structuredLogger.Configure(s.Config.LoggingConfig); err != nil {
+			return fmt.Errorf("invalid logging config: %v", err)
+		}
+	}
+	// Replicas sharing the config store elect the one running the background jobs of the cluster
+	if s.Config.ConfigStore != nil {
+		s.Elector = leader.NewElector(s.Config.ConfigStore, leader.DefaultLeaseDuration, logger)
+		s.Elector.Start()
+		if s.Config.PricingManager != nil {
+			s.Config.PricingManager.SetElector(s.Elector)
+		}
+	}
 	// Initialize log retention cleaner if log store is configured
 	if s.Config.LogsStore != nil {
 		// If log retention days remains 0, then we wont be initializing the log retention cleaner
@@ -1093,12 +1542,29 @@ func (s *BifrostHTTPServer) Bootstrap(ctx context.Context) error {
 				cleanerConfig := logstore.CleanerConfig{
 					RetentionDays: logRetentionDays,
 				}
+				if s.Config.DataRetentionConfig != nil {
+					cleanerConfig.PayloadRetentionDays = s.Config.DataRetentionConfig.LogPayloadDays
+				}
 				s.LogsCleaner = logstore.NewLogsCleaner(rdbStore, cleanerConfig, logger)
+				s.LogsCleaner.SetElector(s.Elector)
 				s.LogsCleaner.StartCleanupRoutine()
 				logger.Info("log retention cleaner initialized with %d days retention",
 					logRetentionDays)
 			}
 		}
+		if s.Config.LogArchiveConfig != nil && s.Config.LogArchiveConfig.Enabled {
+			s.LogArchiver, err = lib.NewLogArchiver(s.Config.LogArchiveConfig, s.Config.LogsStore, logger)
+			if err != nil {
+				return fmt.Errorf("failed to initialize log archive: %v", err)
+			}
+			if logRetentionDays > 0 && logRetentionDays <= s.LogArchiver.ArchiveAfterDays() {
+				logger.Warn("log retention of %d days deletes the logs before they are archived after %d days", logRetentionDays, s.LogArchiver.ArchiveAfterDays())
+			}
+			s.LogArchiver.SetElector(s.Elector)
+			s.LogArchiver.Start()
+		}
+	} else if s.Config.LogArchiveConfig != nil && s.Config.LogArchiveConfig.Enabled {
+		logger.Warn("log archive is disabled, it requires a logs store")
 	}
 	// Load plugins
 	s.pluginStatusMutex.Lock()
@@ -1107,17 +1573,60 @@ func (s *BifrostHTTPServer) Bootstrap(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to load plugins %v", err)
 	}
+	// Circuit breakers of the plugins are shared with the other replicas through the shared counters of governance
+	var pluginCircuits schemas.PluginCircuitStore
+	if governancePlugin, err := FindPluginByName[*governance.GovernancePlugin](s.Plugins, governance.PluginName); err == nil {
+		governancePlugin.SetElector(s.Elector)
+		pluginCircuits = governancePlugin.PluginCircuits()
+	}
 	// Initialize bifrost client
 	// Create account backed by the high-performance store (all processing is done in LoadFromDatabase)
 	// The account interface now benefits from ultra-fast config access times via in-memory storage
 	account := lib.NewBaseAccount(s.Config)
+	// Requests are validated against the capabilities of the model catalog
+	var modelCapabilities schemas.ModelCapabilityRegistry
+	if s.Config.PricingManager != nil {
+		modelCapabilities = s.Config.PricingManager
+	}
+	// Conversations continued with the x-bf-conversation-id header
+	if s.Config.ConversationsConfig != nil && s.Config.ConversationsConfig.Enabled {
+		s.ConversationStore, err = lib.NewConversationStore(ctx, s.Config.ConversationsConfig, s.Config.ConfigStore)
+		if err != nil {
+			return fmt.Errorf("failed to initialize conversations: %v", err)
+		}
+		if s.Config.DataRetentionConfig != nil && s.Config.DataRetentionConfig.ConversationDays > 0 {
+			s.ConversationsCleaner = conversations.NewCleaner(s.ConversationStore, s.Config.DataRetentionConfig.ConversationDays, logger)
+			s.ConversationsCleaner.SetElector(s.Elector)
+			s.ConversationsCleaner.Start()
+		}
+	}
 	s.Client, err = bifrost.Init(ctx, schemas.BifrostConfig{
 		Account:            account,
 		InitialPoolSize:    s.Config.ClientConfig.InitialPoolSize,
 		DropExcessRequests: s.Config.ClientConfig.DropExcessRequests,
 		Plugins:            s.Plugins,
+		PluginExecution:    s.Config.GetPluginExecutionConfigs(),
+		PluginCircuits:     pluginCircuits,
+		DirectKeyPolicy:    s.Config.ClientConfig.DirectKeyPolicy,
+		ImageInputs:        s.Config.ClientConfig.ImageInputs,
+		Agent:              s.Config.ClientConfig.Agent,
+		ContextWindow:      s.Config.ClientConfig.ContextWindow,
+		PromptCompression:  s.Config.ClientConfig.PromptCompression,
+		StreamFailover:     s.Config.ClientConfig.StreamFailover,
+		StreamBackpressure: s.Config.ClientConfig.StreamBackpressure,
+		ResponseBuffering:  s.Config.ClientConfig.ResponseBuffering,
+		Idempotency:        s.Config.ClientConfig.Idempotency,
+		Chaos:              s.Config.ClientConfig.Chaos,
+		ListModelsCache:    s.Config.ClientConfig.ListModelsCache,
+		ResponsesState:     s.Config.ClientConfig.ResponsesState,
+		RequestTags:        s.Config.ClientConfig.RequestTags,
+		PartialFailures:    s.Config.ClientConfig.PartialFailures,
+		SLOs:               s.Config.ClientConfig.SLOs,
+		Conversations:      s.conversationsConfig(),
+		ConversationStore:  s.ConversationStore,
+		ModelCapabilities:  modelCapabilities,
 		MCPConfig:          s.Config.MCPConfig,
-		Logger:             logger,
+		Logger:             schemas.ModuleLogger(logger, "core"),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to initialize bifrost: %v", err)
@@ -1138,6 +1647,89 @@ func (s *BifrostHTTPServer) Bootstrap(ctx context.Context) error {
 	// Add pricing data to the client
 	logger.Info("models added to catalog")
 	s.Config.SetBifrostClient(s.Client)
+	// Applying the transformation pipelines of the config store
+	if s.Config.ConfigStore != nil {
+		if err := s.ReloadPipelines(ctx); err != nil {
+			logger.Error("failed to load pipelines, all plugins run for every request: %v", err)
+		}
+		if err := s.ReloadTransformRules(ctx); err != nil {
+			logger.Error("failed to load transform rules, requests are sent as they are: %v", err)
+		}
+		if err := s.ReloadFallbackChains(ctx); err != nil {
+			logger.Error("failed to load fallback chains, requests are sent with their own fallbacks: %v", err)
+		}
+		if err := s.ReloadExperiments(ctx); err != nil {
+			logger.Error("failed to load experiments, requests are not enrolled: %v", err)
+		}
+		if err := s.ReloadModelDeprecations(ctx); err != nil {
+			logger.Error("failed to load model deprecations, requests are sent to the models they name: %v", err)
+		}
+	}
+	// Streams cancelled by their client are logged, and counted on the telemetry registry when available
+	var streamCancellationsRegisterer prometheus.Registerer
+	if prometheusPlugin, err := FindPluginByName[*telemetry.PrometheusPlugin](s.Plugins, telemetry.PluginName); err == nil && prometheusPlugin.GetRegistry() != nil {
+		streamCancellationsRegisterer = prometheusPlugin.GetRegistry()
+	}
+	s.Config.StreamCancellations, err = lib.NewStreamCancellations(streamCancellationsRegisterer)
+	if err != nil {
+		return fmt.Errorf("failed to initialize stream cancellation metrics: %v", err)
+	}
+	// The compliance of the SLO objectives is exported on the telemetry registry when available
+	if prometheusPlugin, err := FindPluginByName[*telemetry.PrometheusPlugin](s.Plugins, telemetry.PluginName); err == nil && prometheusPlugin.GetRegistry() != nil {
+		if err := lib.RegisterSLOMetrics(s.Client.GetSLOStatus, prometheusPlugin.GetRegistry()); err != nil {
+			return fmt.Errorf("failed to initialize slo metrics: %v", err)
+		}
+	}
+	s.Config.Drain = lib.NewDrain()
+	s.Config.Diagnostics = lib.NewDiagnostics()
+	// Starting synthetic probes, their results are exported on the telemetry registry when available
+	if s.Config.ProbesConfig != nil && s.Config.ProbesConfig.Enabled {
+		var registerer prometheus.Registerer
+		if prometheusPlugin, err := FindPluginByName[*telemetry.PrometheusPlugin](s.Plugins, telemetry.PluginName); err == nil && prometheusPlugin.GetRegistry() != nil {
+			registerer = prometheusPlugin.GetRegistry()
+		}
+		s.ProbeRunner, err = lib.NewProbeRunner(s.Config.ProbesConfig, s.Client, registerer)
+		if err != nil {
+			return fmt.Errorf("failed to initialize synthetic probes: %v", err)
+		}
+		s.ProbeRunner.SetElector(s.Elector)
+		s.ProbeRunner.Start()
+	}
+	// Syncing the configuration from the Kubernetes custom resources, once per cluster
+	if s.Config.KubernetesOperatorConfig != nil && s.Config.KubernetesOperatorConfig.Enabled {
+		s.KubernetesOperator, err = lib.NewKubernetesOperator(s.Config.KubernetesOperatorConfig, s.Config, s)
+		if err != nil {
+			return fmt.Errorf("failed to initialize kubernetes operator: %v", err)
+		}
+		s.KubernetesOperator.SetElector(s.Elector)
+		s.KubernetesOperator.Start()
+	}
+	// Batch embedding ingestion writes to the configured vector store
+	if s.Config.IngestionConfig != nil && s.Config.IngestionConfig.Enabled {
+		s.IngestionManager, err = lib.NewIngestionManager(s.Config.IngestionConfig, s.Client, s.Config.VectorStore)
+		if err != nil {
+			return fmt.Errorf("failed to initialize ingestion: %v", err)
+		}
+	}
+	// Async transcription jobs for files larger than the provider limits
+	if s.Config.TranscriptionJobsConfig != nil && s.Config.TranscriptionJobsConfig.Enabled {
+		s.TranscriptionJobs, err = lib.NewTranscriptionJobManager(s.Config.TranscriptionJobsConfig, s.Client)
+		if err != nil {
+			return fmt.Errorf("failed to initialize transcription jobs: %v", err)
+		}
+	}
+	// Fine-tuning datasets are exported from the logs of the logging plugin
+	if s.Config.DatasetExportsConfig != nil && s.Config.DatasetExportsConfig.Enabled {
+		loggerPlugin, _ := FindPluginByName[*logging.LoggerPlugin](s.Plugins, logging.PluginName)
+		if loggerPlugin == nil {
+			logger.Warn("dataset exports are disabled, they require the logging plugin")
+		} else {
+			s.DatasetExports, err = lib.NewDatasetExportManager(s.Config.DatasetExportsConfig, loggerPlugin.GetPluginLogManager())
+			if err != nil {
+				return fmt.Errorf("failed to initialize dataset exports: %v", err)
+			}
+		}
+	}
 	// Initialize routes
 	s.Router = router.New()
 	commonMiddlewares := s.PrepareCommonMiddlewares()
@@ -1153,7 +1745,8 @@ func (s *BifrostHTTPServer) Bootstrap(ctx context.Context) error {
 	} else if s.Config.GovernanceConfig != nil && s.Config.GovernanceConfig.AuthConfig != nil {
 		authConfig = s.Config.GovernanceConfig.AuthConfig
 	}
-	if ctx.Value("isEnterprise") == nil && authConfig != nil && authConfig.IsEnabled {
+	adminAuthEnabled := ctx.Value("isEnterprise") == nil && authConfig != nil && authConfig.IsEnabled
+	if adminAuthEnabled {
 		apiMiddlewares = append(apiMiddlewares, handlers.AuthMiddleware(s.Config.ConfigStore))
 	}
 	// Register routes
@@ -1161,12 +1754,35 @@ func (s *BifrostHTTPServer) Bootstrap(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to initialize routes: %v", err)
 	}
+	// Runtime diagnostics expose the internals of the process, they are only served behind the admin auth
+	if adminAuthEnabled {
+		handlers.NewRuntimeHandler(s.Client, s.Config.Drain, func() *fasthttp.Server { return s.Server }).RegisterRoutes(s.Router, apiMiddlewares...)
+	} else {
+		logger.Info("runtime diagnostics and pprof endpoints are disabled, they require admin auth to be enabled")
+	}
+	// Setting up JWT auth for inference routes, requests without a JWT still go through the admin auth
+	var jwtVerifier *lib.JWTVerifier
+	if s.Config.JWTAuthConfig != nil && s.Config.JWTAuthConfig.Enabled {
+		jwtVerifier, err = lib.NewJWTVerifier(s.Config.JWTAuthConfig)
+		if err != nil {
+			return fmt.Errorf("failed to initialize jwt auth: %v", err)
+		}
+		logger.Info("jwt auth enabled for inference routes, issuer: %s", s.Config.JWTAuthConfig.Issuer)
+	}
 	// Registering inference routes
-	if ctx.Value("isEnterprise") == nil && authConfig != nil && authConfig.IsEnabled && !authConfig.DisableAuthOnInference {
+	if adminAuthEnabled && !authConfig.DisableAuthOnInference {
 		inferenceMiddlewares = append(inferenceMiddlewares, handlers.AuthMiddleware(s.Config.ConfigStore))
 	}
 	// Registering inference middlewares
 	inferenceMiddlewares = append([]lib.BifrostHTTPMiddleware{handlers.TransportInterceptorMiddleware(s.Config)}, inferenceMiddlewares...)
+	if jwtVerifier != nil {
+		// JWT auth runs first so that the virtual key it resolves is seen by the transport interceptors
+		inferenceMiddlewares = append([]lib.BifrostHTTPMiddleware{handlers.JWTAuthMiddleware(s.Config, jwtVerifier)}, inferenceMiddlewares...)
+	}
+	// Diagnostics time the inference requests through all their middlewares
+	inferenceMiddlewares = append([]lib.BifrostHTTPMiddleware{handlers.DiagnosticsMiddleware(s.Config)}, inferenceMiddlewares...)
+	// Draining refuses new inference requests before any other middleware runs
+	inferenceMiddlewares = append([]lib.BifrostHTTPMiddleware{handlers.DrainMiddleware(s.Config.Drain)}, inferenceMiddlewares...)
 	err = s.RegisterInferenceRoutes(s.ctx, inferenceMiddlewares...)
 	if err != nil {
 		return fmt.Errorf("failed to initialize inference routes: %v", err)
@@ -1174,10 +1790,15 @@ func (s *BifrostHTTPServer) Bootstrap(ctx context.Context) error {
 	// Register UI handler
 	s.RegisterUIRoutes()
 	// Create fasthttp server instance
+	// Request bodies are streamed so that ingestion jobs are decoded as they are read, the body size limit is
+	// enforced by RequestBodyLimitMiddleware instead. Multipart forms are parsed by their handlers within the limit.
+	maxRequestBodySize := s.Config.ClientConfig.MaxRequestBodySizeMB * 1024 * 1024
 	s.Server = &fasthttp.Server{
-		Handler:            handlers.CorsMiddleware(s.Config)(s.Router.Handler),
-		MaxRequestBodySize: s.Config.ClientConfig.MaxRequestBodySizeMB * 1024 * 1024,
-		ReadBufferSize:     1024 * 16, // 16kb
+		Handler:                      handlers.RequestBodyLimitMiddleware(maxRequestBodySize)(handlers.CorsMiddleware(s.Config)(handlers.APIVersionMiddleware()(s.Router.Handler))),
+		MaxRequestBodySize:           maxRequestBodySize,
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
+		ReadBufferSize:               1024 * 16, // 16kb
 	}
 	return nil
 }
@@ -1190,6 +1811,18 @@ func (s *BifrostHTTPServer) Start() error {
 	errChan := make(chan error, 1)
 	// Watching for signals
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	// SIGHUP resolves the secret references of the config again, e.g. after a mounted secret was rotated
+	reloadChan := make(chan os.Signal, 1)
+	signal.Notify(reloadChan, syscall.SIGHUP)
+	defer signal.Stop(reloadChan)
+	go func() {
+		for range reloadChan {
+			logger.Info("received SIGHUP, refreshing secret references...")
+			if err := s.Config.RefreshSecretReferences(s.ctx); err != nil {
+				logger.Warn("failed to refresh some secret references: %v", err)
+			}
+		}
+	}()
 	// Start server in a goroutine
 	serverAddr := net.JoinHostPort(s.Host, s.Port)
 	ln, err := net.Listen("tcp", serverAddr)
@@ -1205,7 +1838,24 @@ func (s *BifrostHTTPServer) Start() error {
 	// Wait for either termination signal or server error
 	select {
 	case sig := <-sigChan:
-		logger.Info("received signal %v, initiating graceful shutdown...", sig)
+		logger.Info("received signal %v, draining before graceful shutdown...", sig)
+		// Refuse new inference requests and let the in-flight ones finish, a second signal skips the drain
+		drainCtx, cancelDrain := context.WithCancel(context.Background())
+		go func() {
+			select {
+			case sig := <-sigChan:
+				logger.Warn("received signal %v while draining, shutting down without waiting for in-flight requests", sig)
+				cancelDrain()
+			case <-drainCtx.Done():
+			}
+		}()
+		s.Config.Drain.Start(s.DrainTimeout)
+		if status := s.Config.Drain.Wait(drainCtx); !status.Drained {
+			logger.Warn("drain ended with %d requests and %d streams in flight", status.InFlightRequests, status.InFlightStreams)
+		} else {
+			logger.Info("drain completed")
+		}
+		cancelDrain()
 		// Create shutdown context with timeout
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
@@ -1223,14 +1873,48 @@ func (s *BifrostHTTPServer) Start() error {
 		done := make(chan struct{})
 		go func() {
 			defer close(done)
+			if s.ProbeRunner != nil {
+				logger.Info("stopping synthetic probes...")
+				s.ProbeRunner.Stop()
+			}
+			if s.KubernetesOperator != nil {
+				logger.Info("stopping kubernetes operator...")
+				s.KubernetesOperator.Stop()
+			}
+			if s.IngestionManager != nil {
+				logger.Info("stopping ingestion jobs...")
+				s.IngestionManager.Stop()
+			}
+			if s.TranscriptionJobs != nil {
+				logger.Info("stopping transcription jobs...")
+				s.TranscriptionJobs.Stop()
+			}
+			if s.DatasetExports != nil {
+				logger.Info("stopping dataset exports...")
+				s.DatasetExports.Stop()
+			}
+			if s.LogArchiver != nil {
+				logger.Info("stopping log archive...")
+				s.LogArchiver.Stop()
+			}
 			logger.Info("shutting down bifrost client...")
 			s.Client.Shutdown()
 			logger.Info("bifrost client shutdown completed")
+			if s.ConversationsCleaner != nil {
+				logger.Info("stopping conversation retention cleaner...")
+				s.ConversationsCleaner.Stop()
+			}
+			// Closed after the client so that the last requests can save their conversation
+			if closer, ok := s.ConversationStore.(io.Closer); ok {
+				closer.Close()
+			}
 			logger.Info("cleaning up storage engines...")
 			// Cleaning up storage engines
 			if s.Config != nil && s.Config.PricingManager != nil {
 				s.Config.PricingManager.Cleanup()
 			}
+			// Released before closing the config store, so that another replica takes over the background jobs
+			s.Elector.Stop()
 			if s.Config != nil && s.Config.ConfigStore != nil {
 				s.Config.ConfigStore.Close(shutdownCtx)
 			}
@@ -1,7 +1,17 @@
 package server
 
 import (
+	"context"
+	"path/filepath"
 	"testing"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	"github.com/maximhq/bifrost/framework/configstore/tables"
+	"github.com/maximhq/bifrost/framework/encrypt"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
 )
 
 // TestConfig is a sample config struct for testing
@@ -278,3 +288,41 @@ func TestMarshalPluginConfig_WithComplexType(t *testing.T) {
 		t.Errorf("Expected nested name=nested-config, got %s", result.Nested.Name)
 	}
 }
+
+// TestLoadRevokedPayloadKeys tests that the payload encryption keys revoked in the config store are refused after a
+// restart, including the keys a namespace moved away from
+func TestLoadRevokedPayloadKeys(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "config.db")
+	storeConfig := &configstore.Config{Enabled: true, Type: configstore.ConfigStoreTypeSQLite, Config: &configstore.SQLiteConfig{Path: dbPath}}
+	store, err := configstore.NewConfigStore(ctx, storeConfig, bifrost.NewDefaultLogger(schemas.LogLevelError))
+	if err != nil {
+		t.Fatalf("failed to create config store: %v", err)
+	}
+	oldKeyRef := "env://BIFROST_TEST_SERVER_OLD_KEK"
+	if err := store.AddRevokedPayloadKey(ctx, &tables.TableRevokedPayloadKey{KeyRef: oldKeyRef, Namespace: "tenant", RevokedAt: time.Now()}); err != nil {
+		t.Fatalf("failed to revoke key: %v", err)
+	}
+	// Revoking a key twice keeps it revoked
+	if err := store.AddRevokedPayloadKey(ctx, &tables.TableRevokedPayloadKey{KeyRef: oldKeyRef, Namespace: "tenant", RevokedAt: time.Now()}); err != nil {
+		t.Fatalf("failed to revoke key again: %v", err)
+	}
+	store.Close(ctx)
+
+	// A restarted replica loads the revocation from the config store
+	store, err = configstore.NewConfigStore(ctx, storeConfig, bifrost.NewDefaultLogger(schemas.LogLevelError))
+	if err != nil {
+		t.Fatalf("failed to reopen config store: %v", err)
+	}
+	defer store.Close(ctx)
+	s := &BifrostHTTPServer{Config: &lib.Config{ConfigStore: store}}
+	if encrypt.IsKeyRefRevoked(oldKeyRef) {
+		t.Fatal("expected the key to be unknown to the process before loading")
+	}
+	if err := s.loadRevokedPayloadKeys(ctx); err != nil {
+		t.Fatalf("failed to load revoked keys: %v", err)
+	}
+	if !encrypt.IsKeyRefRevoked(oldKeyRef) {
+		t.Error("expected the key revoked in the config store to be revoked in the process")
+	}
+}
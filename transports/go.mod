@@ -3,13 +3,16 @@ module github.com/maximhq/bifrost/transports
 go 1.24.3
 
 require (
+	github.com/aws/aws-sdk-go-v2 v1.40.1
 	github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream v1.7.4
+	github.com/aws/aws-sdk-go-v2/config v1.31.13
 	github.com/bytedance/sonic v1.14.1
 	github.com/fasthttp/router v1.5.4
 	github.com/fasthttp/websocket v1.5.12
 	github.com/google/uuid v1.6.0
 	github.com/maximhq/bifrost/core v1.2.35
 	github.com/maximhq/bifrost/framework v1.1.44
+	github.com/maximhq/bifrost/plugins/clickhouse v1.0.0
 	github.com/maximhq/bifrost/plugins/governance v1.3.45
 	github.com/maximhq/bifrost/plugins/logging v1.3.45
 	github.com/maximhq/bifrost/plugins/maxim v1.4.45
@@ -18,6 +21,8 @@ require (
 	github.com/maximhq/bifrost/plugins/telemetry v1.3.44
 	github.com/prometheus/client_golang v1.23.0
 	github.com/valyala/fasthttp v1.67.0
+	golang.org/x/oauth2 v0.32.0
+	golang.org/x/sync v0.18.0
 	gorm.io/gorm v1.31.1
 )
 
@@ -25,8 +30,6 @@ require (
 	cloud.google.com/go/compute/metadata v0.9.0 // indirect
 	github.com/andybalholm/brotli v1.2.0 // indirect
 	github.com/asaskevich/govalidator v0.0.0-20230301143203-a9d515a09cc2 // indirect
-	github.com/aws/aws-sdk-go-v2 v1.40.1 // indirect
-	github.com/aws/aws-sdk-go-v2/config v1.31.13 // indirect
 	github.com/aws/aws-sdk-go-v2/credentials v1.18.17 // indirect
 	github.com/aws/aws-sdk-go-v2/feature/ec2/imds v1.18.10 // indirect
 	github.com/aws/aws-sdk-go-v2/internal/configsources v1.4.15 // indirect
@@ -115,8 +118,6 @@ require (
 	golang.org/x/arch v0.22.0 // indirect
 	golang.org/x/crypto v0.45.0 // indirect
 	golang.org/x/net v0.47.0 // indirect
-	golang.org/x/oauth2 v0.32.0 // indirect
-	golang.org/x/sync v0.18.0 // indirect
 	golang.org/x/sys v0.38.0 // indirect
 	golang.org/x/text v0.31.0 // indirect
 	google.golang.org/genproto/googleapis/api v0.0.0-20250825161204-c5933d9347a5 // indirect
@@ -3,6 +3,7 @@
 import { Alert, AlertDescription, AlertTitle } from "@/components/ui/alert";
 import { FormControl, FormDescription, FormField, FormItem, FormLabel, FormMessage } from "@/components/ui/form";
 import { Input } from "@/components/ui/input";
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@/components/ui/select";
 import { Separator } from "@/components/ui/separator";
 import { TagInput } from "@/components/ui/tagInput";
 import { Textarea } from "@/components/ui/textarea";
@@ -22,6 +23,7 @@ export function ApiKeyFormFragment({ control, providerName, form }: Props) {
 	const isBedrock = providerName === "bedrock";
 	const isVertex = providerName === "vertex";
 	const isAzure = providerName === "azure";
+	const isAzureServerless = isAzure && form.watch("key.azure_key_config.endpoint_type") === "serverless";
 	const modelsPlaceholder = ModelPlaceholders[providerName as keyof typeof ModelPlaceholders] ?? ModelPlaceholders.default;
 	return (
 		<div data-tab="api-keys" className="space-y-4 overflow-hidden">
@@ -152,6 +154,28 @@ export function ApiKeyFormFragment({ control, providerName, form }: Props) {
 			/>
 			{isAzure && (
 				<div className="space-y-4">
+					<FormField
+						control={control}
+						name={`key.azure_key_config.endpoint_type`}
+						render={({ field }) => (
+							<FormItem>
+								<FormLabel>Endpoint Type</FormLabel>
+								<Select onValueChange={field.onChange} value={field.value || "openai"}>
+									<FormControl>
+										<SelectTrigger>
+											<SelectValue placeholder="Select endpoint type" />
+										</SelectTrigger>
+									</FormControl>
+									<SelectContent>
+										<SelectItem value="openai">Azure OpenAI</SelectItem>
+										<SelectItem value="serverless">Azure AI Foundry (serverless)</SelectItem>
+									</SelectContent>
+								</Select>
+								<FormDescription>Serverless endpoints serve models such as Llama, Mistral and Phi, deployments are optional for them</FormDescription>
+								<FormMessage />
+							</FormItem>
+						)}
+					/>
 					<FormField
 						control={control}
 						name={`key.azure_key_config.endpoint`}
@@ -183,7 +207,7 @@ export function ApiKeyFormFragment({ control, providerName, form }: Props) {
 						name={`key.azure_key_config.deployments`}
 						render={({ field }) => (
 							<FormItem>
-								<FormLabel>Deployments (Required)</FormLabel>
+								<FormLabel>Deployments {isAzureServerless ? "(Optional)" : "(Required)"}</FormLabel>
 								<FormDescription>JSON object mapping model names to deployment names</FormDescription>
 								<FormControl>
 									<Textarea
@@ -261,6 +285,20 @@ export function ApiKeyFormFragment({ control, providerName, form }: Props) {
 							</FormItem>
 						)}
 					/>
+					<FormField
+						control={control}
+						name={`key.vertex_key_config.regions`}
+						render={({ field }) => (
+							<FormItem>
+								<FormLabel>Fallback Regions (Optional)</FormLabel>
+								<FormDescription>Regions tried in order when a request fails in the region above with a 404, 429 or 5xx error</FormDescription>
+								<FormControl>
+									<TagInput placeholder="europe-west1, global" value={field.value || []} onValueChange={field.onChange} />
+								</FormControl>
+								<FormMessage />
+							</FormItem>
+						)}
+					/>
 					<Alert variant="default" className="-z-10">
 						<Info className="mt-0.5 h-4 w-4 flex-shrink-0 text-blue-600" />
 						<AlertTitle>Service Account Authentication</AlertTitle>
@@ -419,6 +457,46 @@ export function ApiKeyFormFragment({ control, providerName, form }: Props) {
 							</FormItem>
 						)}
 					/>
+					<FormField
+						control={control}
+						name={`key.bedrock_key_config.role_arn`}
+						render={({ field }) => (
+							<FormItem>
+								<FormLabel>Role ARN (Optional)</FormLabel>
+								<FormDescription>IAM role assumed with the credentials above, or with the environment credentials (IRSA, EKS Pod Identity, instance role) when they are empty</FormDescription>
+								<FormControl>
+									<Input placeholder="arn:aws:iam::123456789012:role/BedrockRole or env.AWS_ROLE_ARN" {...field} value={field.value ?? ""} />
+								</FormControl>
+								<FormMessage />
+							</FormItem>
+						)}
+					/>
+					<FormField
+						control={control}
+						name={`key.bedrock_key_config.external_id`}
+						render={({ field }) => (
+							<FormItem>
+								<FormLabel>External ID (Optional)</FormLabel>
+								<FormControl>
+									<Input placeholder="env.AWS_EXTERNAL_ID" {...field} value={field.value ?? ""} />
+								</FormControl>
+								<FormMessage />
+							</FormItem>
+						)}
+					/>
+					<FormField
+						control={control}
+						name={`key.bedrock_key_config.role_session_name`}
+						render={({ field }) => (
+							<FormItem>
+								<FormLabel>Role Session Name (Optional)</FormLabel>
+								<FormControl>
+									<Input placeholder="bifrost" {...field} value={field.value ?? ""} />
+								</FormControl>
+								<FormMessage />
+							</FormItem>
+						)}
+					/>
 					<FormField
 						control={control}
 						name={`key.bedrock_key_config.region`}
@@ -439,6 +517,20 @@ export function ApiKeyFormFragment({ control, providerName, form }: Props) {
 							</FormItem>
 						)}
 					/>
+					<FormField
+						control={control}
+						name={`key.bedrock_key_config.regions`}
+						render={({ field }) => (
+							<FormItem>
+								<FormLabel>Fallback Regions (Optional)</FormLabel>
+								<FormDescription>Regions tried in order when a request is throttled or fails in the region above with a 5xx error</FormDescription>
+								<FormControl>
+									<TagInput placeholder="us-west-2, eu-central-1" value={field.value || []} onValueChange={field.onChange} />
+								</FormControl>
+								<FormMessage />
+							</FormItem>
+						)}
+					/>
 					<FormField
 						control={control}
 						name={`key.bedrock_key_config.arn`}
@@ -459,6 +551,71 @@ export function ApiKeyFormFragment({ control, providerName, form }: Props) {
 							</FormItem>
 						)}
 					/>
+					<FormField
+						control={control}
+						name={`key.bedrock_key_config.arns`}
+						render={({ field }) => (
+							<FormItem>
+								<FormLabel>Regional ARNs (Optional)</FormLabel>
+								<FormDescription>JSON object mapping regions to the inference profile ARN used in that region instead of the ARN above</FormDescription>
+								<FormControl>
+									<Textarea
+										placeholder='{"eu-central-1": "arn:aws:bedrock:eu-central-1:123:inference-profile"}'
+										value={typeof field.value === "string" ? field.value : JSON.stringify(field.value || {}, null, 2)}
+										onChange={(e) => {
+											// Store as string during editing to allow intermediate invalid states
+											field.onChange(e.target.value);
+										}}
+										onBlur={(e) => {
+											// Try to parse as JSON on blur, but keep as string if invalid
+											const value = e.target.value.trim();
+											if (value) {
+												try {
+													const parsed = JSON.parse(value);
+													if (typeof parsed === "object" && parsed !== null) {
+														field.onChange(parsed);
+													}
+												} catch {
+													// Keep as string for validation on submit
+												}
+											}
+											field.onBlur();
+										}}
+										rows={3}
+										className="max-w-full font-mono text-sm wrap-anywhere"
+									/>
+								</FormControl>
+								<FormMessage />
+							</FormItem>
+						)}
+					/>
+					<FormField
+						control={control}
+						name={`key.bedrock_key_config.guardrail_id`}
+						render={({ field }) => (
+							<FormItem>
+								<FormLabel>Guardrail ID (Optional)</FormLabel>
+								<FormDescription>Bedrock Guardrail applied to requests that don't set a guardrailConfig of their own</FormDescription>
+								<FormControl>
+									<Input placeholder="gr-abc123 or arn:aws:bedrock:us-east-1:123:guardrail/gr-abc123" {...field} value={field.value ?? ""} />
+								</FormControl>
+								<FormMessage />
+							</FormItem>
+						)}
+					/>
+					<FormField
+						control={control}
+						name={`key.bedrock_key_config.guardrail_version`}
+						render={({ field }) => (
+							<FormItem>
+								<FormLabel>Guardrail Version (Optional)</FormLabel>
+								<FormControl>
+									<Input placeholder="1 or DRAFT" {...field} value={field.value ?? ""} />
+								</FormControl>
+								<FormMessage />
+							</FormItem>
+						)}
+					/>
 					<FormField
 						control={control}
 						name={`key.bedrock_key_config.deployments`}
@@ -35,9 +35,12 @@ export const ModelPlaceholders = {
 	bedrock: "e.g. claude-v2, titan-text-express-v1, ai21-j2-mid",
 	cerebras: "e.g. cerebras-2, cerebras-2-vision",
 	cohere: "e.g. command-r, command-r-plus",
+	fireworks: "e.g. accounts/fireworks/models/llama-v3p3-70b-instruct",
 	gemini: "e.g. gemini-1.5-pro, gemini-1.5-flash",
 	groq: "e.g. llama3-70b-8192, mixtral-8x7b-32768",
+	huggingface: "e.g. tgi, meta-llama/Llama-3.1-8B-Instruct",
 	mistral: "e.g. mistral-7b-instruct, mixtral-8x7b",
+	mock: "e.g. mock-model, any model name is accepted",
 	openrouter: "e.g. openai/gpt-4, anthropic/claude-3-haiku",
 	sgl: "e.g. sgl-2, sgl-vision",
 	parasail: "e.g. parasail-2, parasail-vision",
@@ -46,6 +49,7 @@ export const ModelPlaceholders = {
 	ollama: "e.g. llama3.1, llama2",
 	openai: "e.g. gpt-4, gpt-4o, gpt-4o-mini, gpt-3.5-turbo",
 	vertex: "e.g. gemini-1.5-pro, text-bison, chat-bison",
+	xai: "e.g. grok-4, grok-3, grok-3-mini",
 };
 
 export const isKeyRequiredByProvider: Record<ProviderName, boolean> = {
@@ -54,9 +58,12 @@ export const isKeyRequiredByProvider: Record<ProviderName, boolean> = {
 	bedrock: true,
 	cerebras: true,
 	cohere: true,
+	fireworks: true,
 	gemini: true,
 	groq: true,
+	huggingface: false,
 	mistral: true,
+	mock: false,
 	openrouter: true,
 	sgl: false,
 	parasail: true,
@@ -65,6 +72,7 @@ export const isKeyRequiredByProvider: Record<ProviderName, boolean> = {
 	openai: true,
 	vertex: true,
 	perplexity: true,
+	xai: true,
 };
 
 export const DefaultNetworkConfig = {
@@ -5,9 +5,12 @@ export const KnownProvidersNames = [
 	"bedrock",
 	"cerebras",
 	"cohere",
+	"fireworks",
 	"gemini",
 	"groq",
+	"huggingface",
 	"mistral",
+	"mock",
 	"ollama",
 	"openai",
 	"openrouter",
@@ -16,6 +19,7 @@ export const KnownProvidersNames = [
 	"perplexity",
 	"sgl",
 	"vertex",
+	"xai",
 ] as const;
 
 // Local Provider type derived from KNOWN_PROVIDERS constant
@@ -54,8 +58,12 @@ export const ProviderLabels: Record<ProviderName, string> = {
 	perplexity: "Perplexity",
 	sgl: "SGLang",
 	cerebras: "Cerebras",
+	fireworks: "Fireworks AI",
+	xai: "xAI",
+	huggingface: "Hugging Face",
 	gemini: "Gemini",
 	openrouter: "OpenRouter",
+	mock: "Mock",
 } as const;
 
 // Helper function to get provider label, supporting custom providers
@@ -73,12 +73,14 @@ const AzureKeyConfigSchema = z.object({
 		.optional()
 		.refine((value) => !value || isValidDeployments(value), { message: "Valid Deployments (JSON object) are required for Azure keys" }),
 	api_version: z.string().optional(),
+	endpoint_type: z.enum(["openai", "serverless"]).optional(),
 });
 
 const VertexKeyConfigSchema = z.object({
 	project_id: z.string().min(1, "Project ID is required for Vertex AI keys"),
 	project_number: z.string().optional(),
 	region: z.string().min(1, "Region is required for Vertex AI keys"),
+	regions: z.array(z.string()).optional(),
 	auth_credentials: z
 		.string()
 		.optional()
@@ -104,6 +106,18 @@ const BedrockKeyConfigSchema = z
 			.refine((value) => !value || Object.keys(value).length === 0 || isValidDeployments(value), {
 				message: "Valid Deployments (JSON object) are required for Bedrock keys",
 			}),
+		regions: z.array(z.string()).optional(),
+		arns: z
+			.union([z.record(z.string(), z.string()), z.string()])
+			.optional()
+			.refine((value) => !value || Object.keys(value).length === 0 || isValidDeployments(value), {
+				message: "Valid ARNs (JSON object mapping regions to ARNs) are required for Bedrock keys",
+			}),
+		guardrail_id: z.string().optional(),
+		guardrail_version: z.string().optional(),
+		role_arn: z.string().optional(),
+		external_id: z.string().optional(),
+		role_session_name: z.string().optional(),
 	})
 	.refine(
 		(data) => {
@@ -24,12 +24,17 @@ export interface AzureKeyConfig {
 	endpoint: string;
 	deployments?: Record<string, string> | string; // Allow string during editing
 	api_version?: string;
+	endpoint_type?: AzureEndpointType;
 }
 
+// AzureEndpointType matching Go's schemas.AzureEndpointType
+export type AzureEndpointType = "openai" | "serverless";
+
 export const DefaultAzureKeyConfig: AzureKeyConfig = {
 	endpoint: "",
 	deployments: {},
 	api_version: "2024-02-01",
+	endpoint_type: "openai",
 } as const satisfies Required<AzureKeyConfig>;
 
 // VertexKeyConfig matching Go's schemas.VertexKeyConfig
@@ -37,6 +42,7 @@ export interface VertexKeyConfig {
 	project_id: string;
 	project_number?: string;
 	region: string;
+	regions?: string[]; // Fallback regions tried in order on regional errors
 	auth_credentials?: string; // Always string - JSON string or env var
 	deployments?: Record<string, string> | string; // Allow string during editing
 }
@@ -45,6 +51,7 @@ export const DefaultVertexKeyConfig: VertexKeyConfig = {
 	project_id: "",
 	project_number: "",
 	region: "",
+	regions: [],
 	auth_credentials: "",
 	deployments: {},
 } as const satisfies Required<VertexKeyConfig>;
@@ -57,6 +64,13 @@ export interface BedrockKeyConfig {
 	region: string;
 	arn?: string;
 	deployments?: Record<string, string> | string; // Allow string during editing
+	regions?: string[]; // Fallback regions tried in order on throttling or regional errors
+	arns?: Record<string, string> | string; // Inference profile ARNs per region, allow string during editing
+	guardrail_id?: string;
+	guardrail_version?: string;
+	role_arn?: string;
+	external_id?: string;
+	role_session_name?: string;
 }
 
 // Default BedrockKeyConfig
@@ -67,6 +81,13 @@ export const DefaultBedrockKeyConfig: BedrockKeyConfig = {
 	region: "us-east-1",
 	arn: undefined as unknown as string,
 	deployments: {},
+	regions: [],
+	arns: {},
+	guardrail_id: undefined as unknown as string,
+	guardrail_version: undefined as unknown as string,
+	role_arn: undefined as unknown as string,
+	external_id: undefined as unknown as string,
+	role_session_name: undefined as unknown as string,
 } as const satisfies Required<BedrockKeyConfig>;
 
 // Key structure matching Go's schemas.Key
@@ -79,6 +100,14 @@ export interface ModelProviderKey {
 	azure_key_config?: AzureKeyConfig;
 	vertex_key_config?: VertexKeyConfig;
 	bedrock_key_config?: BedrockKeyConfig;
+	last_validation?: KeyValidation;
+}
+
+// KeyValidation matching Go's schemas.KeyValidation, set by the validation calls of the management API
+export interface KeyValidation {
+	status: "valid" | "invalid";
+	error?: string;
+	validated_at: string;
 }
 
 // Default ModelProviderKey
@@ -157,6 +186,17 @@ export interface CustomProviderConfig {
 	request_path_overrides?: Record<string, string>;
 }
 
+// MockConfig matching Go's schemas.MockConfig
+export interface MockConfig {
+	response?: string;
+	echo_input?: boolean;
+	models?: string[];
+	latency_ms?: number;
+	tokens_per_second?: number;
+	error_rate?: number;
+	error_status_code?: number;
+}
+
 // ProviderConfig matching Go's lib.ProviderConfig
 export interface ModelProviderConfig {
 	keys: ModelProviderKey[];
@@ -165,6 +205,7 @@ export interface ModelProviderConfig {
 	proxy_config?: ProxyConfig;
 	send_back_raw_response?: boolean;
 	custom_provider_config?: CustomProviderConfig;
+	mock_config?: MockConfig;
 }
 
 // ProviderResponse matching Go's ProviderResponse
@@ -188,6 +229,7 @@ export interface AddProviderRequest {
 	proxy_config?: ProxyConfig;
 	send_back_raw_response?: boolean;
 	custom_provider_config?: CustomProviderConfig;
+	mock_config?: MockConfig;
 }
 
 // UpdateProviderRequest matching Go's UpdateProviderRequest
@@ -198,6 +240,7 @@ export interface UpdateProviderRequest {
 	proxy_config: ProxyConfig;
 	send_back_raw_response?: boolean;
 	custom_provider_config?: CustomProviderConfig;
+	mock_config?: MockConfig;
 }
 
 // BifrostErrorResponse matching Go's schemas.BifrostError
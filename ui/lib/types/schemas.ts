@@ -18,6 +18,7 @@ export const azureKeyConfigSchema = z
 		endpoint: z.url("Must be a valid URL"),
 		deployments: z.union([z.record(z.string(), z.string()), z.string()]).optional(),
 		api_version: z.string().optional(),
+		endpoint_type: z.enum(["openai", "serverless"]).optional(),
 	})
 	.refine(
 		(data) => {
@@ -54,6 +55,7 @@ export const vertexKeyConfigSchema = z
 		project_id: z.string().min(1, "Project ID is required"),
 		project_number: z.string().optional(),
 		region: z.string().min(1, "Region is required"),
+		regions: z.array(z.string()).optional(),
 		auth_credentials: z.string().optional(),
 		deployments: z.union([z.record(z.string(), z.string()), z.string()]).optional(),
 	})
@@ -95,6 +97,13 @@ export const bedrockKeyConfigSchema = z
 		region: z.string().min(1, "Region is required"),
 		arn: z.string().optional(),
 		deployments: z.union([z.record(z.string(), z.string()), z.string()]).optional(),
+		regions: z.array(z.string()).optional(),
+		arns: z.union([z.record(z.string(), z.string()), z.string()]).optional(),
+		guardrail_id: z.string().optional(),
+		guardrail_version: z.string().optional(),
+		role_arn: z.string().optional(),
+		external_id: z.string().optional(),
+		role_session_name: z.string().optional(),
 	})
 	.refine(
 		(data) => {